@@ -34,6 +34,9 @@ type Common interface {
 
 	LogList(context.Context) ([]string, error)         //perm:write
 	LogSetLevel(context.Context, string, string) error //perm:write
+	// LogResetLevels removes the log levels persisted by LogSetLevel, and sets
+	// the levels of the node config again
+	LogResetLevels(context.Context) error //perm:write
 
 	// LogAlerts returns list of all, active and inactive alerts tracked by the
 	// node
@@ -57,6 +60,10 @@ type Common interface {
 	Session(context.Context) (uuid.UUID, error) //perm:read
 
 	Closing(context.Context) (<-chan struct{}, error) //perm:read
+
+	// RepoLockStatus returns the status of the node repo lock, identifying the
+	// process holding it
+	RepoLockStatus(context.Context) (RepoLockStatus, error) //perm:read
 }
 
 // APIVersion provides various build-time information
@@ -78,3 +85,22 @@ type APIVersion struct {
 func (v APIVersion) String() string {
 	return fmt.Sprintf("%s+api%s", v.Version, v.APIVersion.String())
 }
+
+type RepoLockStatus struct {
+	Path   string
+	Locked bool
+	// Owner is the recorded holder of the lock, nil when unknown
+	Owner *RepoLockOwner
+	// OwnerAlive is only checked for holders on the same host
+	OwnerAlive bool
+	// Stale locks aren't held by a live process, and can be broken
+	Stale  bool
+	Reason string
+}
+
+type RepoLockOwner struct {
+	PID      int
+	Hostname string
+	RepoType string
+	Since    time.Time
+}
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"reflect"
 
 	"github.com/filecoin-project/go-jsonrpc"
@@ -10,6 +13,9 @@ import (
 const (
 	EOutOfGas = iota + jsonrpc.FirstUserCode
 	EActorNotFound
+	EReadOnly
+	EExecutionReverted
+	EExecutionLimit
 )
 
 type ErrOutOfGas struct{}
@@ -24,6 +30,304 @@ func (e *ErrActorNotFound) Error() string {
 	return "actor not found"
 }
 
+// ErrReadOnly is returned by methods which were disabled because the node
+// serves its API in read-only mode.
+type ErrReadOnly struct{}
+
+func (e *ErrReadOnly) Error() string {
+	return "method not available: node is running in read-only mode"
+}
+
+// ErrExecutionReverted is returned by the Ethereum API when a call reverts in
+// an EVM actor. Reason is the decoded Error(string) message or Panic(uint256)
+// name, when the revert data is a standard Solidity payload, and Data is the
+// raw revert data. Both are sent to JSON-RPC clients in the error meta field,
+// and EthErrorsHandler sends them to Ethereum clients in the format of geth.
+type ErrExecutionReverted struct {
+	Message string `json:"message"`
+	Reason  string `json:"reason,omitempty"`
+	Data    string `json:"data"`
+}
+
+func (e *ErrExecutionReverted) Error() string {
+	return e.Message
+}
+
+func (e *ErrExecutionReverted) MarshalJSON() ([]byte, error) {
+	type meta ErrExecutionReverted
+	return json.Marshal((*meta)(e))
+}
+
+func (e *ErrExecutionReverted) UnmarshalJSON(b []byte) error {
+	type meta ErrExecutionReverted
+	return json.Unmarshal(b, (*meta)(e))
+}
+
+// ErrExecutionLimit is returned when a call is aborted for exceeding an
+// execution limit set by the node, such as the number of state reads. Limit
+// names the limit and Max is its value. Both are sent to JSON-RPC clients in
+// the error meta field.
+type ErrExecutionLimit struct {
+	Limit string `json:"limit"`
+	Max   int64  `json:"max"`
+}
+
+func (e *ErrExecutionLimit) Error() string {
+	return fmt.Sprintf("call exceeded the %s execution limit (%d)", e.Limit, e.Max)
+}
+
+func (e *ErrExecutionLimit) MarshalJSON() ([]byte, error) {
+	type meta ErrExecutionLimit
+	return json.Marshal((*meta)(e))
+}
+
+func (e *ErrExecutionLimit) UnmarshalJSON(b []byte) error {
+	type meta ErrExecutionLimit
+	return json.Unmarshal(b, (*meta)(e))
+}
+
+// Codes of the structured API errors. They are the EIP-1474 JSON-RPC error
+// codes, which Ethereum clients already handle, and are used by both the
+// Filecoin and the Ethereum API.
+const (
+	EInvalidParams       = -32602
+	EResourceNotFound    = -32001
+	EResourceUnavailable = -32002
+	ETransactionRejected = -32003
+	EMethodNotSupported  = -32004
+	ELimitExceeded       = -32005
+)
+
+// StructuredError holds the fields of the structured API errors, sent to
+// JSON-RPC clients in the error meta field. Reason is a machine-readable
+// identifier of the error, more precise than its code, such as
+// "nonce_too_low". Retryable tells whether the same request may succeed when
+// made again later, and Details holds the values the error relates to.
+type StructuredError struct {
+	Message   string                 `json:"message"`
+	Reason    string                 `json:"reason"`
+	Retryable bool                   `json:"retryable"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+
+	// cause is the error wrapped with %w in the message, on the node side.
+	cause error
+}
+
+func (e *StructuredError) Error() string {
+	return e.Message
+}
+
+func (e *StructuredError) Unwrap() error {
+	return e.cause
+}
+
+func (e *StructuredError) MarshalJSON() ([]byte, error) {
+	type meta StructuredError
+	return json.Marshal((*meta)(e))
+}
+
+func (e *StructuredError) UnmarshalJSON(b []byte) error {
+	type meta StructuredError
+	return json.Unmarshal(b, (*meta)(e))
+}
+
+func (e *StructuredError) structured() *StructuredError {
+	return e
+}
+
+// ErrInvalidParams is returned when the parameters of a call are invalid.
+type ErrInvalidParams struct{ StructuredError }
+
+func (e *ErrInvalidParams) ErrorCode() jsonrpc.ErrorCode { return EInvalidParams }
+
+// ErrNotFound is returned when an object a call refers to doesn't exist, such
+// as an actor or a tipset.
+type ErrNotFound struct{ StructuredError }
+
+func (e *ErrNotFound) ErrorCode() jsonrpc.ErrorCode { return EResourceNotFound }
+
+// ErrUnavailable is returned when the node can't serve a call for now, for
+// example because it timed out.
+type ErrUnavailable struct{ StructuredError }
+
+func (e *ErrUnavailable) ErrorCode() jsonrpc.ErrorCode { return EResourceUnavailable }
+
+// ErrTransactionRejected is returned when the message pool rejects a message.
+type ErrTransactionRejected struct{ StructuredError }
+
+func (e *ErrTransactionRejected) ErrorCode() jsonrpc.ErrorCode { return ETransactionRejected }
+
+// ErrMethodNotSupported is returned by methods which the node doesn't support
+// in its configuration.
+type ErrMethodNotSupported struct{ StructuredError }
+
+func (e *ErrMethodNotSupported) ErrorCode() jsonrpc.ErrorCode { return EMethodNotSupported }
+
+// ErrLimitExceeded is returned when a call exceeds a limit set by the node,
+// such as the number of results of a query.
+type ErrLimitExceeded struct{ StructuredError }
+
+func (e *ErrLimitExceeded) ErrorCode() jsonrpc.ErrorCode { return ELimitExceeded }
+
+// NewErrInvalidParams returns an ErrInvalidParams error.
+func NewErrInvalidParams(reason, format string, args ...interface{}) *ErrInvalidParams {
+	return &ErrInvalidParams{newStructuredError(reason, false, format, args...)}
+}
+
+// NewErrNotFound returns an ErrNotFound error.
+func NewErrNotFound(reason string, retryable bool, format string, args ...interface{}) *ErrNotFound {
+	return &ErrNotFound{newStructuredError(reason, retryable, format, args...)}
+}
+
+// NewErrUnavailable returns a retryable ErrUnavailable error.
+func NewErrUnavailable(reason, format string, args ...interface{}) *ErrUnavailable {
+	return &ErrUnavailable{newStructuredError(reason, true, format, args...)}
+}
+
+// NewErrTransactionRejected returns an ErrTransactionRejected error.
+func NewErrTransactionRejected(reason string, retryable bool, format string, args ...interface{}) *ErrTransactionRejected {
+	return &ErrTransactionRejected{newStructuredError(reason, retryable, format, args...)}
+}
+
+// NewErrMethodNotSupported returns an ErrMethodNotSupported error.
+func NewErrMethodNotSupported(reason, format string, args ...interface{}) *ErrMethodNotSupported {
+	return &ErrMethodNotSupported{newStructuredError(reason, false, format, args...)}
+}
+
+// NewErrLimitExceeded returns an ErrLimitExceeded error.
+func NewErrLimitExceeded(reason string, retryable bool, format string, args ...interface{}) *ErrLimitExceeded {
+	return &ErrLimitExceeded{newStructuredError(reason, retryable, format, args...)}
+}
+
+func newStructuredError(reason string, retryable bool, format string, args ...interface{}) StructuredError {
+	err := fmt.Errorf(format, args...)
+	return StructuredError{
+		Message:   err.Error(),
+		Reason:    reason,
+		Retryable: retryable,
+		cause:     errors.Unwrap(err),
+	}
+}
+
+// AsStructuredError returns the fields and the code of the structured error in
+// the chain of err, if any. It works on both the errors returned by the node
+// and those received by JSON-RPC clients.
+func AsStructuredError(err error) (*StructuredError, jsonrpc.ErrorCode, bool) {
+	se, ok := findStructuredError(err)
+	if !ok {
+		return nil, 0, false
+	}
+	return se.structured(), se.ErrorCode(), true
+}
+
+// findStructuredError returns the first error embedding StructuredError in the
+// chain of err. The interface of these errors is not named, as the API proxy
+// generator would otherwise generate a proxy for it.
+func findStructuredError(err error) (interface {
+	error
+	ErrorCode() jsonrpc.ErrorCode
+	structured() *StructuredError
+}, bool) {
+	var se interface {
+		error
+		ErrorCode() jsonrpc.ErrorCode
+		structured() *StructuredError
+	}
+	ok := errors.As(err, &se)
+	return se, ok
+}
+
+// ToStructuredError returns the error sent to JSON-RPC clients for err.
+// JSON-RPC only sends the code and details of the errors returned by methods
+// as-is, so structured errors wrapped in err are returned in its place, with
+// its message. Errors of the node which clients may need to tell apart are
+// classified too: ErrNotSupported, wrapped ErrActorNotFound and timeouts.
+// Other errors are returned unchanged.
+func ToStructuredError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if se, ok := findStructuredError(err); ok {
+		if error(se) == err {
+			return err
+		}
+		// copy the error, keeping its type
+		out := reflect.New(reflect.TypeOf(se).Elem())
+		out.Elem().Set(reflect.ValueOf(se).Elem())
+		cp, _ := findStructuredError(out.Interface().(error))
+		cp.structured().Message = err.Error()
+		cp.structured().cause = err
+		return cp
+	}
+
+	for _, target := range []error{new(ErrOutOfGas), new(ErrReadOnly), new(ErrExecutionReverted), new(ErrExecutionLimit)} {
+		tmp := reflect.New(reflect.TypeOf(target))
+		if errors.As(err, tmp.Interface()) {
+			return tmp.Elem().Interface().(error)
+		}
+	}
+
+	switch {
+	case errors.Is(err, ErrNotSupported):
+		return &ErrMethodNotSupported{StructuredError{Message: err.Error(), Reason: "not_supported", cause: err}}
+	case errors.As(err, new(*ErrActorNotFound)):
+		return &ErrNotFound{StructuredError{Message: err.Error(), Reason: "actor_not_found", cause: err}}
+	case errors.Is(err, context.DeadlineExceeded):
+		return &ErrUnavailable{StructuredError{Message: err.Error(), Reason: "timeout", Retryable: true, cause: err}}
+	}
+	return err
+}
+
+// StructuredErrorsFullAPI returns a FullNode API which returns the errors of
+// the methods of a as converted by ToStructuredError, for JSON-RPC clients to
+// get their codes and details.
+func StructuredErrorsFullAPI(a FullNode) FullNode {
+	var out FullNodeStruct
+	StructuredErrorsProxy(a, &out)
+	return &out
+}
+
+// StructuredErrorsGatewayAPI is StructuredErrorsFullAPI for the Gateway API.
+func StructuredErrorsGatewayAPI(a Gateway) Gateway {
+	var out GatewayStruct
+	StructuredErrorsProxy(a, &out)
+	return &out
+}
+
+// StructuredErrorsStorMinerAPI is StructuredErrorsFullAPI for the StorageMiner
+// API.
+func StructuredErrorsStorMinerAPI(a StorageMiner) StorageMiner {
+	var out StorageMinerStruct
+	StructuredErrorsProxy(a, &out)
+	return &out
+}
+
+// StructuredErrorsProxy sets the methods of the API proxy struct out to call
+// the methods of in, converting the errors they return with ToStructuredError.
+func StructuredErrorsProxy(in, out interface{}) {
+	ra := reflect.ValueOf(in)
+
+	for _, o := range GetInternalStructs(out) {
+		rint := reflect.ValueOf(o).Elem()
+
+		for f := 0; f < rint.NumField(); f++ {
+			field := rint.Type().Field(f)
+			fn := ra.MethodByName(field.Name)
+
+			rint.Field(f).Set(reflect.MakeFunc(field.Type, func(args []reflect.Value) []reflect.Value {
+				res := fn.Call(args)
+				last := len(res) - 1
+				if err, ok := res[last].Interface().(error); ok && err != nil {
+					err = ToStructuredError(err)
+					res[last] = reflect.ValueOf(&err).Elem()
+				}
+				return res
+			}))
+		}
+	}
+}
+
 var RPCErrors = jsonrpc.NewErrors()
 
 func ErrorIsIn(err error, errorTypes []error) bool {
@@ -39,4 +343,14 @@ func ErrorIsIn(err error, errorTypes []error) bool {
 func init() {
 	RPCErrors.Register(EOutOfGas, new(*ErrOutOfGas))
 	RPCErrors.Register(EActorNotFound, new(*ErrActorNotFound))
+	RPCErrors.Register(EReadOnly, new(*ErrReadOnly))
+	RPCErrors.Register(EExecutionReverted, new(*ErrExecutionReverted))
+	RPCErrors.Register(EExecutionLimit, new(*ErrExecutionLimit))
+
+	RPCErrors.Register(EInvalidParams, new(*ErrInvalidParams))
+	RPCErrors.Register(EResourceNotFound, new(*ErrNotFound))
+	RPCErrors.Register(EResourceUnavailable, new(*ErrUnavailable))
+	RPCErrors.Register(ETransactionRejected, new(*ErrTransactionRejected))
+	RPCErrors.Register(EMethodNotSupported, new(*ErrMethodNotSupported))
+	RPCErrors.Register(ELimitExceeded, new(*ErrLimitExceeded))
 }
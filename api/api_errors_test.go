@@ -0,0 +1,112 @@
+// stm: #unit
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestToStructuredError(t *testing.T) {
+	sentinel := xerrors.New("nonce too low")
+	rejected := NewErrTransactionRejected("nonce_too_low", false, "push: %w", sentinel)
+	rejected.Details = map[string]interface{}{"nonce": 1}
+
+	// wrapped structured errors are returned as-is, with the outer message
+	err := ToStructuredError(xerrors.Errorf("pushing message: %w", rejected))
+	var txErr *ErrTransactionRejected
+	require.ErrorAs(t, err, &txErr)
+	require.Equal(t, err, txErr)
+	require.Equal(t, "pushing message: push: nonce too low", txErr.Message)
+	require.Equal(t, "nonce_too_low", txErr.Reason)
+	require.Equal(t, 1, txErr.Details["nonce"])
+	require.ErrorIs(t, err, sentinel)
+	require.Equal(t, "push: nonce too low", rejected.Message)
+
+	se, code, ok := AsStructuredError(err)
+	require.True(t, ok)
+	require.EqualValues(t, ETransactionRejected, code)
+	require.False(t, se.Retryable)
+
+	// errors of the node are classified
+	err = ToStructuredError(xerrors.Errorf("eth_newFilter: %w", ErrNotSupported))
+	_, code, ok = AsStructuredError(err)
+	require.True(t, ok)
+	require.EqualValues(t, EMethodNotSupported, code)
+
+	err = ToStructuredError(xerrors.Errorf("resolving: %w", &ErrActorNotFound{}))
+	se, code, ok = AsStructuredError(err)
+	require.True(t, ok)
+	require.EqualValues(t, EResourceNotFound, code)
+	require.Equal(t, "actor_not_found", se.Reason)
+	require.ErrorAs(t, err, new(*ErrActorNotFound))
+
+	err = ToStructuredError(xerrors.Errorf("call: %w", context.DeadlineExceeded))
+	se, code, ok = AsStructuredError(err)
+	require.True(t, ok)
+	require.EqualValues(t, EResourceUnavailable, code)
+	require.True(t, se.Retryable)
+
+	// registered errors are unwrapped
+	limit := &ErrExecutionLimit{Limit: "gas", Max: 10}
+	require.Equal(t, error(limit), ToStructuredError(xerrors.Errorf("call: %w", limit)))
+
+	other := xerrors.New("other")
+	require.Equal(t, other, ToStructuredError(other))
+	require.NoError(t, ToStructuredError(nil))
+}
+
+func TestStructuredErrorMeta(t *testing.T) {
+	in := NewErrLimitExceeded("too_many_results", false, "too many results")
+	in.Details = map[string]interface{}{"max": 10}
+
+	b, err := json.Marshal(in)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"message":"too many results","reason":"too_many_results","retryable":false,"details":{"max":10}}`, string(b))
+
+	var out ErrLimitExceeded
+	require.NoError(t, json.Unmarshal(b, &out))
+	require.Equal(t, "too many results", out.Error())
+	require.Equal(t, "too_many_results", out.Reason)
+	require.EqualValues(t, 10, out.Details["max"])
+}
+
+func TestStructuredErrorsFullAPI(t *testing.T) {
+	ctx := context.Background()
+
+	var in FullNodeStruct
+	in.Internal.ChainHead = func(context.Context) (*types.TipSet, error) {
+		return nil, nil
+	}
+	in.Internal.StateLookupID = func(context.Context, address.Address, types.TipSetKey) (address.Address, error) {
+		return address.Undef, xerrors.Errorf("looking up: %w", &ErrActorNotFound{})
+	}
+
+	a := StructuredErrorsFullAPI(&in)
+
+	_, err := a.ChainHead(ctx)
+	require.NoError(t, err)
+
+	_, err = a.StateLookupID(ctx, address.Undef, types.EmptyTSK)
+	var nf *ErrNotFound
+	require.ErrorAs(t, err, &nf)
+	require.Equal(t, err, nf)
+	require.Equal(t, "looking up: actor not found", nf.Message)
+
+	// the miner API is wrapped the same way
+	var min StorageMinerStruct
+	min.Internal.ActorAddress = func(context.Context) (address.Address, error) {
+		return address.Undef, xerrors.Errorf("actor address: %w", ErrNotSupported)
+	}
+	_, err = StructuredErrorsStorMinerAPI(&min).ActorAddress(ctx)
+	var ns *ErrMethodNotSupported
+	require.ErrorAs(t, err, &ns)
+	require.Equal(t, err, ns)
+}
@@ -25,6 +25,7 @@ import (
 	verifregtypes "github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/dline"
+	"github.com/filecoin-project/go-state-types/exitcode"
 	abinetwork "github.com/filecoin-project/go-state-types/network"
 
 	apitypes "github.com/filecoin-project/lotus/api/types"
@@ -75,6 +76,21 @@ type FullNode interface {
 	// First message is guaranteed to be of len == 1, and type == 'current'.
 	ChainNotify(context.Context) (<-chan []*HeadChange, error) //perm:read
 
+	// ChainNotifyAtConfidence is like ChainNotify, but only notifies of tipsets
+	// once they are depth epochs below the head. If applied tipsets are later
+	// reorged out, they are reverted before the tipsets replacing them are applied.
+	// First message is guaranteed to be of len == 1, and type == 'current'.
+	ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*HeadChange, error) //perm:read
+
+	// ChainNotifyResume is like ChainNotify, but each update comes with a
+	// cursor, which can be passed back to resume the notifications after the
+	// update, for example when reconnecting. The first update of a resumed
+	// subscription reverts and applies the tipsets changed since the cursor,
+	// without gaps or duplicates. With an empty cursor, the first update is the
+	// current head, as with ChainNotify. Cursors further below the head than
+	// the resume window of the node are rejected.
+	ChainNotifyResume(ctx context.Context, cursor string) (<-chan HeadChangeBatch, error) //perm:read
+
 	// ChainHead returns the current head of the chain.
 	ChainHead(context.Context) (*types.TipSet, error) //perm:read
 
@@ -82,6 +98,12 @@ type FullNode interface {
 	ChainGetBlock(context.Context, cid.Cid) (*types.BlockHeader, error) //perm:read
 	// ChainGetTipSet returns the tipset specified by the given TipSetKey.
 	ChainGetTipSet(context.Context, types.TipSetKey) (*types.TipSet, error) //perm:read
+	// ChainGetTipSetProof returns the serialized block headers of the tipset
+	// specified by the given TipSetKey, the head when empty. The headers hash
+	// to the tipset key and commit to the parent state root, so that a light
+	// client trusting the key can verify state proofs against the tipset
+	// without trusting the node (see StateGetProof).
+	ChainGetTipSetProof(context.Context, types.TipSetKey) (*TipSetProof, error) //perm:read
 
 	// ChainGetBlockMessages returns messages stored in the specified block.
 	//
@@ -137,6 +159,22 @@ type FullNode interface {
 	// between the two objects.
 	ChainStatObj(ctx context.Context, obj cid.Cid, base cid.Cid) (ObjStat, error) //perm:read
 
+	// ChainWalkObj walks the graph referenced by the object at the given path,
+	// as accepted by ChainGetNode, breadth first within the limits of the
+	// options, and breaks its blocks and size down by codec.
+	ChainWalkObj(ctx context.Context, p string, opts DagWalkOpts) (*DagWalk, error) //perm:read
+
+	// ChainFindObjPath finds the shortest chain of links from one object to
+	// another within the limits of the options. Joining the links of the steps
+	// gives a path of the target accepted by ChainGetNode. It returns nil when
+	// the target isn't reachable.
+	ChainFindObjPath(ctx context.Context, from, to cid.Cid, opts DagWalkOpts) ([]DagPathStep, error) //perm:read
+
+	// ChainExportObj exports the graph referenced by the object at the given
+	// path, as accepted by ChainGetNode, as a CAR file rooted at the object,
+	// within the limits of the options.
+	ChainExportObj(ctx context.Context, p string, opts DagWalkOpts) (<-chan []byte, error) //perm:read
+
 	// ChainSetHead forcefully sets current chain head. Use with caution.
 	ChainSetHead(context.Context, types.TipSetKey) error //perm:admin
 
@@ -173,6 +211,14 @@ type FullNode interface {
 	// If oldmsgskip is set, messages from before the requested roots are also not included.
 	ChainExport(ctx context.Context, nroots abi.ChainEpoch, oldmsgskip bool, tsk types.TipSetKey) (<-chan []byte, error) //perm:read
 
+	// ChainExportStream is a resumable variant of ChainExport. The CAR stream is
+	// sent in chunks, each carrying its offset in the stream and a resume token
+	// which can be used to continue an interrupted export from that point.
+	// When params.Tail is set, an epoch-range snapshot is exported instead,
+	// containing messages from the tail to the given tipset and the state at
+	// the tail only.
+	ChainExportStream(ctx context.Context, tsk types.TipSetKey, params ChainExportStreamParams) (<-chan ChainExportChunk, error) //perm:read
+
 	// ChainExportRangeInternal triggers the export of a chain
 	// CAR-snapshot directly to disk. It is similar to ChainExport,
 	// except, depending on options, the snapshot can include receipts,
@@ -201,9 +247,23 @@ type FullNode interface {
 	// ChainGetEvents returns the events under an event AMT root CID.
 	ChainGetEvents(context.Context, cid.Cid) ([]types.Event, error) //perm:read
 
+	// ChainIndexQuery runs a read-only SQL query over the sqlite database of an
+	// index: "events" (actor events), "txhash" (Ethereum transaction hashes) or
+	// "msgindex" (message inclusion). Only reads are allowed. Queries return at
+	// most Index.SQLQueryMaxRows rows, and are interrupted after
+	// Index.SQLQueryTimeout. The API is only served when enabled with
+	// Index.EnableSQLQuery.
+	ChainIndexQuery(ctx context.Context, index string, query string) (*IndexQueryResult, error) //perm:read
+
 	// GasEstimateFeeCap estimates gas fee cap
 	GasEstimateFeeCap(context.Context, *types.Message, int64, types.TipSetKey) (types.BigInt, error) //perm:read
 
+	// GasForecastBaseFee forecasts the base fee of the given number of epochs
+	// after the tipset, 20 by default, assuming blocks stay as full as they
+	// recently were and the pending messages are included as soon as they fit.
+	// Low and High bound the base fee with empty and full blocks.
+	GasForecastBaseFee(ctx context.Context, epochs int, tsk types.TipSetKey) (*BaseFeeForecast, error) //perm:read
+
 	// GasEstimateGasLimit estimates gas used by the message and returns it.
 	// It fails if message fails to execute.
 	GasEstimateGasLimit(context.Context, *types.Message, types.TipSetKey) (int64, error) //perm:read
@@ -259,6 +319,13 @@ type FullNode interface {
 	// MpoolPending returns pending mempool messages.
 	MpoolPending(context.Context, types.TipSetKey) ([]*types.SignedMessage, error) //perm:read
 
+	// MpoolPredictInclusion estimates the epochs until a message is included on
+	// chain and the probability of its inclusion within the given number of epochs,
+	// from the depth of the message pool, the premiums of recently included
+	// messages and the recent base fee trend. The message is either pending in
+	// the message pool, or described by its gas fee cap, premium and limit.
+	MpoolPredictInclusion(context.Context, InclusionSpec) (*InclusionPrediction, error) //perm:read
+
 	// MpoolSelect returns a list of pending messages for inclusion in the next block
 	MpoolSelect(context.Context, types.TipSetKey, float64) ([]*types.SignedMessage, error) //perm:read
 
@@ -307,11 +374,83 @@ type FullNode interface {
 	// MpoolSetConfig sets the mpool config to (a copy of) the supplied config
 	MpoolSetConfig(context.Context, *types.MpoolConfig) error //perm:admin
 
+	// MpoolSpendBudget returns the state of the budget of the FIL the node's
+	// wallets spend on gas, in messages pushed with MpoolPushMessage.
+	MpoolSpendBudget(context.Context) (*SpendBudget, error) //perm:read
+	// MpoolSpendBudgetOverride sets an allowance which messages exceeding the
+	// spend budget can be pushed with, until it's used up. Zero revokes it.
+	MpoolSpendBudgetOverride(ctx context.Context, allowance abi.TokenAmount) error //perm:admin
+
+	// MpoolScheduleMessage schedules a message to be pushed, like with
+	// MpoolPushMessage, once the conditions of the schedule hold. The nonce and
+	// gas of the message are only assigned when it's pushed. Scheduled messages
+	// are persisted, and the conditions are checked at every new head. Pushes
+	// which fail are retried at the next heads, up to a few times.
+	MpoolScheduleMessage(ctx context.Context, msg *types.Message, schedule MessageSchedule, spec *MessageSendSpec) (*ScheduledMessage, error) //perm:sign
+	// MpoolListScheduled lists the scheduled messages, in the order they were
+	// scheduled. Messages which are no longer pending are kept for a week.
+	MpoolListScheduled(ctx context.Context) ([]*ScheduledMessage, error) //perm:read
+	// MpoolCancelScheduled cancels a pending scheduled message.
+	MpoolCancelScheduled(ctx context.Context, id uuid.UUID) error //perm:sign
+
 	// MethodGroup: Miner
 
 	MinerGetBaseInfo(context.Context, address.Address, abi.ChainEpoch, types.TipSetKey) (*MiningBaseInfo, error) //perm:read
 	MinerCreateBlock(context.Context, *BlockTemplate) (*types.BlockMsg, error)                                   //perm:write
 
+	// MinerCreateBlockDryRun assembles the block the miner would produce in the
+	// current round on top of the given tipset (the head when empty), without
+	// broadcasting it. It runs the eligibility and election checks, computes the
+	// ticket with the worker key held by the node wallet, and selects messages
+	// the way block production does, so that message selection settings can be
+	// tuned and block production checked after upgrades. The block is assembled
+	// even when the miner doesn't win the round, and carries no winning PoSt
+	// proof, which only the miner can compute.
+	MinerCreateBlockDryRun(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (*MinerBlockDryRun, error) //perm:write
+
+	// MineOne mines a block on top of the current head, and returns the new
+	// head. It's only available on devnets running with mock proofs (see
+	// lotus devnet), where blocks are mined on request.
+	MineOne(context.Context) (*types.TipSet, error) //perm:admin
+	// AdvanceEpochs moves the chain forward by the given number of epochs,
+	// inserting null rounds and mining a block at the last epoch, and returns
+	// the new head. The block lands at a later epoch in the rare case the
+	// devnet miner doesn't win the election. It's only available on devnets
+	// running with mock proofs.
+	AdvanceEpochs(ctx context.Context, epochs abi.ChainEpoch) (*types.TipSet, error) //perm:admin
+	// ChainFork sets the head of a devnet back to the given tipset, and
+	// returns it. The next blocks are mined on top of it, after the highest
+	// epoch mined so far since the devnet miner never mines twice at the same
+	// height. It's only available on devnets running with mock proofs.
+	ChainFork(context.Context, types.TipSetKey) (*types.TipSet, error) //perm:admin
+	// StatePatch changes the state of actors on a devnet, outside of message
+	// execution, and mines a block. The changes are visible in the state of
+	// the returned head. It's only available on devnets running with mock
+	// proofs.
+	StatePatch(context.Context, []ActorPatch) (*types.TipSet, error) //perm:admin
+
+	// MethodGroup: Actor
+	// The Actor methods install and instantiate user WASM actors. They are
+	// experimental, disabled unless UserActors.Enable is set in the node
+	// config, and only work on networks whose init actor supports installing
+	// actor code, which no public network does yet.
+
+	// ActorValidateCode checks user actor code and returns the CID it would be
+	// installed under.
+	ActorValidateCode(ctx context.Context, code []byte) (cid.Cid, error) //perm:read
+	// ActorEstimateInstall estimates the gas and cost of installing user actor
+	// code from the given address on top of the current head.
+	ActorEstimateInstall(ctx context.Context, from address.Address, code []byte) (*ActorInstallEstimate, error) //perm:read
+	// ActorInstall creates a message installing user actor code, which is
+	// installed under the CID returned by ActorValidateCode.
+	ActorInstall(ctx context.Context, from address.Address, code []byte) (*MessagePrototype, error) //perm:sign
+	// ActorCreate creates a message instantiating an installed user actor with
+	// the given constructor parameters and value.
+	ActorCreate(ctx context.Context, from address.Address, code cid.Cid, params []byte, value types.BigInt) (*MessagePrototype, error) //perm:sign
+	// StateInstalledActors returns the code CIDs of the user actors installed
+	// at the given tipset.
+	StateInstalledActors(ctx context.Context, tsk types.TipSetKey) ([]cid.Cid, error) //perm:read
+
 	// // UX ?
 
 	// MethodGroup: WalletF
@@ -345,6 +484,12 @@ type FullNode interface {
 	WalletDelete(context.Context, address.Address) error //perm:admin
 	// WalletValidateAddress validates whether a given string can be decoded as a well-formed address
 	WalletValidateAddress(context.Context, string) (address.Address, error) //perm:read
+	// WalletPendingApprovals lists the approval requests of the wallet signing
+	// policy, and the approved messages which weren't sent again yet.
+	WalletPendingApprovals(context.Context) ([]WalletApproval, error) //perm:admin
+	// WalletApprove approves or rejects an approval request. Approved messages
+	// are signed when they are sent again, before the request expires.
+	WalletApprove(ctx context.Context, id uuid.UUID, approve bool) error //perm:admin
 
 	// Other
 
@@ -445,12 +590,33 @@ type FullNode interface {
 	// different signature, but with all other parameters matching (source/destination,
 	// nonce, params, etc.)
 	StateReplay(context.Context, types.TipSetKey, cid.Cid) (*InvocResult, error) //perm:read
+	// StateReplayCached is like StateReplay, but serves recently replayed
+	// messages from a cache instead of executing them again. Results are cached
+	// by message and the tipset it's replayed on, see the StateReplayCache
+	// config for the size and expiry of the cache.
+	StateReplayCached(context.Context, types.TipSetKey, cid.Cid) (*InvocResult, error) //perm:read
 	// StateGetActor returns the indicated actor's nonce and balance.
 	StateGetActor(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*types.Actor, error) //perm:read
+	// StateGetProof returns the actor at the given address in the parent state
+	// of the given tipset, with the IPLD blocks proving it against the state
+	// root: the HAMT nodes on the path to the actor and, for addresses other
+	// than ID addresses, the nodes resolving the address. The proof can be
+	// checked with state.VerifyActorProof. When the actor doesn't exist, Actor
+	// is nil and the blocks prove its absence.
+	StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*ActorProof, error) //perm:read
 	// StateReadState returns the indicated actor's state.
 	StateReadState(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*ActorState, error) //perm:read
 	// StateListMessages looks back and returns all messages with a matching to or from address, stopping at the given height.
 	StateListMessages(ctx context.Context, match *MessageMatch, tsk types.TipSetKey, toht abi.ChainEpoch) ([]cid.Cid, error) //perm:read
+	// StateListAddressMessages returns the messages sent or received by an
+	// address, newest first, using the address index instead of walking the
+	// chain. Both the ID and robust address of the actor are matched.
+	// The query can select the calls to a function of a contract, by the
+	// selector of the function, e.g. all transfer() calls received by a token.
+	// The index is only maintained when enabled with Index.EnableAddrIndex, from
+	// the tipsets applied since it was enabled; messages indexed before the
+	// upgrade of the index to selectors have no selector nor recipient.
+	StateListAddressMessages(ctx context.Context, addr address.Address, query *AddressMessagesQuery) (*AddressMessages, error) //perm:read
 	// StateDecodeParams attempts to decode the provided params, based on the recipient actor address and method number.
 	StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk types.TipSetKey) (interface{}, error) //perm:read
 	// StateEncodeParams attempts to encode the provided json params to the binary from
@@ -465,10 +631,25 @@ type FullNode interface {
 	// StateMinerProvingDeadline calculates the deadline at some epoch for a proving period
 	// and returns the deadline-related calculations.
 	StateMinerProvingDeadline(context.Context, address.Address, types.TipSetKey) (*dline.Info, error) //perm:read
+	// StateMinerProvingSchedule returns the next challenge window of each
+	// proving deadline of the miner, with wall-clock times derived from the
+	// network epoch timing, and the partitions proven in each deadline.
+	StateMinerProvingSchedule(context.Context, address.Address, types.TipSetKey) (*ProvingSchedule, error) //perm:read
 	// StateMinerPower returns the power of the indicated miner
 	StateMinerPower(context.Context, address.Address, types.TipSetKey) (*MinerPower, error) //perm:read
 	// StateMinerInfo returns info about the indicated miner
 	StateMinerInfo(context.Context, address.Address, types.TipSetKey) (MinerInfo, error) //perm:read
+	// StateMinerPendingChanges returns the decoded owner, worker and beneficiary
+	// changes of the miner awaiting confirmation, along with the current
+	// beneficiary term
+	StateMinerPendingChanges(context.Context, address.Address, types.TipSetKey) (*MinerPendingChanges, error) //perm:read
+	// StateMinerCheckBeneficiaryChange checks a beneficiary change proposal
+	// against the miner state, reporting proposals which the actor would reject
+	// and ones which are likely mistakes
+	StateMinerCheckBeneficiaryChange(ctx context.Context, maddr address.Address, params miner.ChangeBeneficiaryParams, tsk types.TipSetKey) (*AddressChangeCheck, error) //perm:read
+	// StateMinerCheckOwnerChange checks an owner change proposal against the
+	// miner state
+	StateMinerCheckOwnerChange(ctx context.Context, maddr address.Address, newOwner address.Address, tsk types.TipSetKey) (*AddressChangeCheck, error) //perm:read
 	// StateMinerDeadlines returns all the proving deadlines for the given miner
 	StateMinerDeadlines(context.Context, address.Address, types.TipSetKey) ([]Deadline, error) //perm:read
 	// StateMinerPartitions returns all partitions in the specified deadline
@@ -483,6 +664,10 @@ type FullNode interface {
 	StateMinerPreCommitDepositForPower(context.Context, address.Address, miner.SectorPreCommitInfo, types.TipSetKey) (types.BigInt, error) //perm:read
 	// StateMinerInitialPledgeCollateral returns the initial pledge collateral for the specified miner's sector
 	StateMinerInitialPledgeCollateral(context.Context, address.Address, miner.SectorPreCommitInfo, types.TipSetKey) (types.BigInt, error) //perm:read
+	// StatePledgeForPowerBatch returns the precommit deposit and initial pledge of
+	// a set of prospective sectors, applying the rules of the network version
+	// scheduled at the activation of each sector
+	StatePledgeForPowerBatch(ctx context.Context, sectors []ProspectiveSector, tsk types.TipSetKey) (*PledgeRequirements, error) //perm:read
 	// StateMinerAvailableBalance returns the portion of a miner's balance that can be withdrawn or spent
 	StateMinerAvailableBalance(context.Context, address.Address, types.TipSetKey) (types.BigInt, error) //perm:read
 	// StateMinerSectorAllocated checks if a sector number is marked as allocated.
@@ -519,6 +704,13 @@ type FullNode interface {
 	// different signature, but with all other parameters matching (source/destination,
 	// nonce, params, etc.)
 	StateSearchMsg(ctx context.Context, from types.TipSetKey, msg cid.Cid, limit abi.ChainEpoch, allowReplaced bool) (*MsgLookup, error) //perm:read
+	// StateWatchBalances notifies of balance changes of the given addresses. Eth
+	// addresses can be watched through their f410 address. Changes are
+	// computed for every applied and reverted tipset, from the state before
+	// and after its messages were executed; a batch is sent only when any of
+	// the balances changed. Tipsets whose changes can't be computed are sent
+	// as an item with the Error field set.
+	StateWatchBalances(ctx context.Context, addrs []address.Address) (<-chan []*BalanceChange, error) //perm:read
 	// StateWaitMsg looks back up to limit epochs in the chain for a message.
 	// If not found, it blocks until the message arrives on chain, and gets to the
 	// indicated confidence depth.
@@ -640,15 +832,40 @@ type FullNode interface {
 	StateGetRandomnessFromTickets(ctx context.Context, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (abi.Randomness, error) //perm:read
 	// StateGetRandomnessFromBeacon is used to sample the beacon for randomness.
 	StateGetRandomnessFromBeacon(ctx context.Context, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (abi.Randomness, error) //perm:read
+	// StateGetRandomnessWithProof samples the tickets or the beacon for randomness like
+	// StateGetRandomnessFromTickets and StateGetRandomnessFromBeacon, and also returns
+	// the chain data the randomness is derived from, so that it can be verified
+	// without a Filecoin node.
+	StateGetRandomnessWithProof(ctx context.Context, source RandomnessSource, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (*RandomnessProof, error) //perm:read
 
 	// StateGetBeaconEntry returns the beacon entry for the given filecoin epoch. If
 	// the entry has not yet been produced, the call will block until the entry
 	// becomes available
 	StateGetBeaconEntry(ctx context.Context, epoch abi.ChainEpoch) (*types.BeaconEntry, error) //perm:read
 
+	// BeaconStatus returns the health of the random beacons of the beacon schedule,
+	// including the latency and failures of each drand server and the number of
+	// rounds which couldn't be fetched from any server.
+	BeaconStatus(ctx context.Context) ([]BeaconStatus, error) //perm:read
+
+	// StateGasStats returns the gas used by the messages executed over the given
+	// number of recent epochs, aggregated per actor code and method, sorted by gas
+	// used. A lookback of 0 returns the aggregates over the whole tracked window.
+	// Gas stats are only tracked when enabled with Index.EnableGasStats, from the
+	// tipsets executed since the node started.
+	StateGasStats(ctx context.Context, lookback abi.ChainEpoch) (*GasStats, error) //perm:read
+
 	// StateGetNetworkParams return current network params
 	StateGetNetworkParams(ctx context.Context) (*NetworkParams, error) //perm:read
 
+	// StateUpgradeSchedule returns the upcoming network upgrades, when they're
+	// expected, and whether this binary is ready to perform them. These are the
+	// upgrades of the schedule built into the binary, checked for missing
+	// actors bundles or state migrations, and the upgrades announced for the
+	// network with Upgrades in the node config, which the binary can't perform
+	// when it doesn't know about them.
+	StateUpgradeSchedule(ctx context.Context) ([]UpgradeInfo, error) //perm:read
+
 	// MethodGroup: Msig
 	// The Msig methods are used to interact with multisig wallets on the
 	// filecoin network
@@ -779,6 +996,20 @@ type FullNode interface {
 	// These methods are general node management and status commands
 
 	NodeStatus(ctx context.Context, inclChainStatus bool) (NodeStatus, error) //perm:read
+	// NodeAPISpec describes the methods of the API served by the node, with
+	// their Ethereum aliases, whether the optional features they need are
+	// enabled on the node, and their deprecation notices.
+	NodeAPISpec(ctx context.Context) (*APISpec, error) //perm:read
+
+	// NodeMaintenanceStatus returns the state of the maintenance windows
+	// during which expensive background activities, such as splitstore
+	// compaction and snapshot exports, are allowed, and of the tasks run in
+	// them.
+	NodeMaintenanceStatus(ctx context.Context) (*MaintenanceStatus, error) //perm:read
+	// NodeMaintenanceOverride forces the maintenance windows open or closed
+	// until the time of the override. A nil override restores the configured
+	// schedule.
+	NodeMaintenanceOverride(ctx context.Context, override *MaintenanceOverride) error //perm:admin
 
 	// MethodGroup: Eth
 	// These methods are used for Ethereum-compatible JSON-RPC calls
@@ -808,6 +1039,16 @@ type FullNode interface {
 	EthGetTransactionByBlockHashAndIndex(ctx context.Context, blkHash ethtypes.EthHash, txIndex ethtypes.EthUint64) (ethtypes.EthTx, error)    //perm:read
 	EthGetTransactionByBlockNumberAndIndex(ctx context.Context, blkNum ethtypes.EthUint64, txIndex ethtypes.EthUint64) (ethtypes.EthTx, error) //perm:read
 
+	// EthGetTransactionReceiptProof returns the receipt and events of a
+	// transaction with the data needed to verify them against a trusted
+	// checkpoint tipset without trusting the node: the AMT blocks proving the
+	// receipt and its events, a chain of block headers from the checkpoint
+	// down to the tipset committing to the receipts, and the blocks proving the
+	// message is executed at the index of the receipt. The checkpoint is a
+	// block param, "finalized" when empty. See state.VerifyHeaderChain,
+	// state.VerifyMessageInclusion and state.VerifyReceiptProof.
+	EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*EthReceiptProof, error) //perm:read
+
 	EthGetCode(ctx context.Context, address ethtypes.EthAddress, blkOpt string) (ethtypes.EthBytes, error)                                    //perm:read
 	EthGetStorageAt(ctx context.Context, address ethtypes.EthAddress, position ethtypes.EthBytes, blkParam string) (ethtypes.EthBytes, error) //perm:read
 	EthGetBalance(ctx context.Context, address ethtypes.EthAddress, blkParam string) (ethtypes.EthBigInt, error)                              //perm:read
@@ -823,11 +1064,74 @@ type FullNode interface {
 	EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthUint64, error)          //perm:read
 	EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam string) (ethtypes.EthBytes, error) //perm:read
 
+	// EthBatchStateRead evaluates a list of balance, nonce, storage slot and
+	// eth_call reads against the state of a single block. The tipset state is
+	// resolved once for the whole batch, and every read fails independently
+	// with the error returned in its result.
+	EthBatchStateRead(ctx context.Context, reads []EthStateRead, blkParam string) ([]EthStateReadResult, error) //perm:read
+	// EthCallBundle simulates an ordered bundle of transactions atop the state
+	// of a block, after its messages, without sending them. Each transaction
+	// sees the changes of the previous ones. Overrides replace the balance or
+	// nonce of accounts before the bundle is applied, accounts which don't
+	// exist are left out. The results of the
+	// transactions are returned with the balance and nonce changes of the
+	// actors the bundle touched.
+	EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []EthAccountOverride) (*EthBundleResult, error) //perm:read
+
 	EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error) //perm:read
+	// EthDecodeRawTransaction decodes a raw legacy, EIP-2930 or EIP-1559 transaction
+	// and shows the Filecoin message EthSendRawTransaction would translate it to,
+	// without submitting it.
+	EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*EthRawTxDecoding, error) //perm:read
+
+	// EthSignTypedData signs EIP-712 typed data with a delegated (f410) key held by the
+	// node wallet, returning the 65 byte r || s || v signature with v being 27 or 28.
+	EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error) //perm:sign
+
+	// EthSponsoredCallTypedData returns the EIP-712 typed data the account of a
+	// sponsored call signs to authorize it, with the nonce of the account in
+	// the forwarder when the call doesn't set it.
+	EthSponsoredCallTypedData(ctx context.Context, call EthSponsoredCall) (*ethtypes.EthTypedData, error) //perm:read
+	// EthSendSponsoredCall relays a contract call signed by an account through
+	// an ERC-2771 forwarder contract, in a message sent and paid for by a
+	// sponsor address of the node wallet. The call is signed with the node
+	// wallet when it has no signature. Sponsored calls are disabled unless
+	// Fevm.SponsoredCalls is configured, which restricts the sponsors,
+	// forwarders, target contracts, value and rate of the calls.
+	EthSendSponsoredCall(ctx context.Context, call EthSponsoredCall) (*EthSponsoredCallResult, error) //perm:sign
+
+	// EthTxPoolContent returns the messages in the message pool grouped by sender
+	// and nonce, split into pending (executable) and queued (waiting for a nonce
+	// gap to be filled) ones. Messages which aren't Ethereum transactions are
+	// returned in the Filecoin fields.
+	EthTxPoolContent(ctx context.Context) (EthTxPoolContent, error) //perm:read
+	// EthTxPoolInspect is like EthTxPoolContent, but summarizes each message in
+	// a single line of text.
+	EthTxPoolInspect(ctx context.Context) (EthTxPoolInspect, error) //perm:read
+	// EthTxPoolStatus returns the number of pending and queued messages in the
+	// message pool.
+	EthTxPoolStatus(ctx context.Context) (EthTxPoolStatus, error) //perm:read
+
+	// EthTraceTransactionGasProfile replays a transaction and attributes the gas
+	// it used to call frames and gas charges, see EthGasProfile.
+	EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*EthGasProfile, error) //perm:read
 
 	// Returns event logs matching given filter spec.
 	EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error) //perm:read
 
+	// EthGetLogsPage returns a page of the event logs matching the filter spec.
+	// Unlike EthGetLogs, the block range isn't limited: it's split into
+	// sub-ranges read until the page holds about as many logs as a filter can
+	// collect. Pass the continuation of a page, along with the same filter
+	// spec, to get the next page. Requires the historic event index.
+	EthGetLogsPage(ctx context.Context, filter *ethtypes.EthFilterSpec, continuation string) (*EthLogsPage, error) //perm:read
+
+	// EthGetLogsStream sends the pages of the event logs matching the filter
+	// spec, as returned by EthGetLogsPage, until all the logs have been sent.
+	// The channel is closed after the last page. Only available over
+	// websockets.
+	EthGetLogsStream(ctx context.Context, filter *ethtypes.EthFilterSpec) (<-chan EthLogsPage, error) //perm:read
+
 	// Polling method for a filter, returns event logs which occurred since last poll.
 	// (requires write perm since timestamp of last filter execution will be written)
 	EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error) //perm:read
@@ -857,6 +1161,13 @@ type FullNode interface {
 	// The client will receive a stream of EthSubscriptionResponse values until EthUnsubscribe is called.
 	EthSubscribe(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) //perm:read
 
+	// Subscribe to changes of contract storage slots using websockets. params
+	// is a list of EthStorageSlotsSpec, each selecting slots of one contract.
+	// Every time the state of a watched contract changes in a new tipset, its
+	// slots are read and an EthStorageSlotChange is sent for each slot whose
+	// value changed. Use EthUnsubscribe to stop the subscription.
+	EthSubscribeStorageSlots(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) //perm:read
+
 	// Unsubscribe from a websocket subscription
 	EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error) //perm:read
 
@@ -868,6 +1179,12 @@ type FullNode interface {
 	// LOTUS_BACKUP_BASE_PATH environment variable set to some path, and that
 	// the path specified when calling CreateBackup is within the base path
 	CreateBackup(ctx context.Context, fpath string) error //perm:admin
+	// BackupCreate writes an archive of the node repo under the specified file
+	// name, holding the selected sections: keystore, identity, config and
+	// metadata, or all of them when none are selected. The archive is encrypted
+	// when a passphrase is set. As with CreateBackup, the path must be within
+	// LOTUS_BACKUP_BASE_PATH.
+	BackupCreate(ctx context.Context, fpath string, opts BackupOptions) (*BackupInfo, error) //perm:admin
 
 	RaftState(ctx context.Context) (*RaftStateData, error) //perm:read
 	RaftLeader(ctx context.Context) (peer.ID, error)       //perm:read
@@ -1263,6 +1580,16 @@ type CirculatingSupply struct {
 	FilReserveDisbursed abi.TokenAmount
 }
 
+// ActorPatch sets the fields of an existing actor in the state of a devnet,
+// fields left nil are kept. New state objects referenced by Head must be
+// added to the blockstore first, e.g. with ChainPutObj.
+type ActorPatch struct {
+	Address address.Address
+	Balance *types.BigInt
+	Nonce   *uint64
+	Head    *cid.Cid
+}
+
 type MiningBaseInfo struct {
 	MinerPower        types.BigInt
 	NetworkPower      types.BigInt
@@ -1286,6 +1613,28 @@ type BlockTemplate struct {
 	WinningPoStProof []builtin.PoStProof
 }
 
+// MinerBlockDryRun is the block assembled by MinerCreateBlockDryRun.
+type MinerBlockDryRun struct {
+	// Round the block is assembled for, after the null rounds since the base
+	// tipset.
+	Round abi.ChainEpoch
+	// EligibleForMining is false when the miner has no power or is slashed,
+	// no block is assembled then.
+	EligibleForMining bool
+	// WinCount is the number of blocks the miner wins in the round, 0 when it
+	// doesn't win.
+	WinCount int64
+	Block    *types.BlockMsg
+	// GasLimit is the sum of the gas limits of the selected messages.
+	GasLimit int64
+	// BlockReward is the reward of the block, of a block winning once when the
+	// miner doesn't win the round.
+	BlockReward abi.TokenAmount
+	// GasReward is the sum of the premiums the selected messages pay on their
+	// gas limit, capped by their fee cap at the block base fee.
+	GasReward abi.TokenAmount
+}
+
 type DataSize struct {
 	PayloadSize int64
 	PieceSize   abi.PaddedPieceSize
@@ -1306,6 +1655,48 @@ type HeadChange struct {
 	Val  *types.TipSet
 }
 
+// HeadChangeBatch is an update of ChainNotifyResume. Cursor resumes the
+// notifications after it.
+type HeadChangeBatch struct {
+	Changes []*HeadChange
+	Cursor  string
+}
+
+// IndexQueryResult is the result of ChainIndexQuery. Rows holds the values of
+// the columns of each row; Truncated is set when rows beyond the row limit
+// were dropped.
+type IndexQueryResult struct {
+	Columns   []string
+	Rows      [][]interface{}
+	Truncated bool
+}
+
+// BalanceChange is sent by StateWatchBalances when the balance of a watched
+// address changed by executing a tipset. Type is "apply" or "revert", like in
+// HeadChange; for reverted tipsets the balances are swapped, so that Delta is
+// the change caused by the revert.
+type BalanceChange struct {
+	Type    string
+	TipSet  types.TipSetKey
+	Height  abi.ChainEpoch
+	Address address.Address
+	// ID is undefined when the actor doesn't exist
+	ID address.Address
+
+	OldBalance types.BigInt
+	NewBalance types.BigInt
+	Delta      types.BigInt
+
+	// Messages executed in the tipset and sent directly from or to the
+	// address. Transfers made by other actors, e.g. multisigs or contracts,
+	// aren't listed, but are included in the delta.
+	Messages []cid.Cid
+
+	// Error is set on the items sent in place of the changes of a tipset
+	// which couldn't be computed, which only have Type and TipSet set.
+	Error string
+}
+
 type MsigProposeResponse int
 
 const (
@@ -1326,6 +1717,37 @@ type Partition struct {
 	ActiveSectors     bitfield.BitField
 }
 
+// ProvingSchedule maps the proving deadlines of a miner to epochs and
+// wall-clock times
+type ProvingSchedule struct {
+	Epoch           abi.ChainEpoch
+	EpochTime       time.Time
+	PeriodStart     abi.ChainEpoch
+	CurrentDeadline uint64
+	Deadlines       []ProvingDeadline
+}
+
+// ProvingDeadline describes the currently open, or the next, challenge window
+// of a deadline
+type ProvingDeadline struct {
+	Index   uint64
+	Current bool
+
+	Open        abi.ChainEpoch
+	Close       abi.ChainEpoch
+	Challenge   abi.ChainEpoch // epoch the challenge randomness is drawn from
+	FaultCutoff abi.ChainEpoch // faults and recoveries must be declared before this epoch
+
+	OpenTime        time.Time
+	CloseTime       time.Time
+	ChallengeTime   time.Time
+	FaultCutoffTime time.Time
+
+	// Partitions proven in the current, or last, challenge window
+	PostSubmissions bitfield.BitField
+	Partitions      []Partition
+}
+
 type Fault struct {
 	Miner address.Address
 	Epoch abi.ChainEpoch
@@ -1369,6 +1791,240 @@ type HotGCOpts struct {
 	Moving    bool
 }
 
+// EthTxPoolContent is returned by txpool_content. Ethereum transactions are
+// keyed by sender Ethereum address and nonce, other messages by Filecoin
+// sender address and nonce.
+type EthTxPoolContent struct {
+	Pending map[string]map[ethtypes.EthUint64]ethtypes.EthTx `json:"pending"`
+	Queued  map[string]map[ethtypes.EthUint64]ethtypes.EthTx `json:"queued"`
+
+	FilecoinPending map[string]map[ethtypes.EthUint64]*types.SignedMessage `json:"filecoinPending"`
+	FilecoinQueued  map[string]map[ethtypes.EthUint64]*types.SignedMessage `json:"filecoinQueued"`
+}
+
+// EthTxPoolInspect is returned by txpool_inspect, it has the same layout as
+// EthTxPoolContent.
+type EthTxPoolInspect struct {
+	Pending map[string]map[ethtypes.EthUint64]string `json:"pending"`
+	Queued  map[string]map[ethtypes.EthUint64]string `json:"queued"`
+
+	FilecoinPending map[string]map[ethtypes.EthUint64]string `json:"filecoinPending"`
+	FilecoinQueued  map[string]map[ethtypes.EthUint64]string `json:"filecoinQueued"`
+}
+
+// EthTxPoolStatus is returned by txpool_status. Pending and Queued count all
+// messages, including the ones listed in the Filecoin fields of
+// EthTxPoolContent.
+type EthTxPoolStatus struct {
+	Pending ethtypes.EthUint64 `json:"pending"`
+	Queued  ethtypes.EthUint64 `json:"queued"`
+}
+
+// EthGasProfile attributes the gas used by a transaction to the call frames of
+// its execution trace. Root can be loaded directly into d3-flame-graph style
+// viewers, and Folded is in the collapsed stack format read by flamegraph.pl.
+//
+// The FVM doesn't trace individual EVM opcodes, so gas within a frame is
+// broken down by gas charge (wasm execution, syscalls, IPLD reads and writes)
+// rather than by opcode.
+type EthGasProfile struct {
+	TransactionHash ethtypes.EthHash `json:"transactionHash"`
+	Message         cid.Cid          `json:"message"`
+	// GasUsed is the gas used according to the message receipt
+	GasUsed int64 `json:"gasUsed"`
+
+	Root   GasProfileFrame `json:"root"`
+	Folded []string        `json:"folded"`
+}
+
+// APISpec describes the methods of an API, for clients to discover the
+// features a node supports.
+type APISpec struct {
+	Features []APIFeature
+	Methods  []APIMethod
+}
+
+// APIFeature is an optional feature of the API, and whether it's enabled.
+type APIFeature struct {
+	Name    string
+	Enabled bool
+}
+
+// APIMethod describes a method of the API. Aliases are its Ethereum JSON-RPC
+// names, Feature the optional feature it needs, if any, and Subscription is
+// true for methods returning a channel.
+type APIMethod struct {
+	Name         string
+	Aliases      []string `json:",omitempty"`
+	Permission   string
+	Feature      string `json:",omitempty"`
+	Enabled      bool
+	Deprecated   string `json:",omitempty"`
+	Subscription bool
+}
+
+// EthLogsPage is a page of the event logs matching a filter.
+type EthLogsPage struct {
+	Logs []ethtypes.EthLog `json:"logs"`
+	// FromBlock and ToBlock are the range of blocks covered by the page
+	FromBlock ethtypes.EthUint64 `json:"fromBlock"`
+	ToBlock   ethtypes.EthUint64 `json:"toBlock"`
+	// Continuation gets the next page, it's empty on the last page
+	Continuation string `json:"continuation,omitempty"`
+	// Error is set on the last page sent by EthGetLogsStream when reading the
+	// logs failed
+	Error string `json:"error,omitempty"`
+}
+
+// GasProfileFrame is a single call frame in an EthGasProfile. Value is the
+// total gas charged in the frame and all its subcalls, SelfGas only the gas
+// charged in the frame itself.
+type GasProfileFrame struct {
+	Name     string             `json:"name"`
+	Value    int64              `json:"value"`
+	SelfGas  int64              `json:"selfGas"`
+	From     address.Address    `json:"from"`
+	To       address.Address    `json:"to"`
+	Method   abi.MethodNum      `json:"method"`
+	ExitCode exitcode.ExitCode  `json:"exitCode"`
+	Charges  []GasProfileCharge `json:"charges,omitempty"`
+	Children []GasProfileFrame  `json:"children,omitempty"`
+}
+
+// GasProfileCharge sums the gas charges with the same name within a frame
+type GasProfileCharge struct {
+	Name       string `json:"name"`
+	Count      int    `json:"count"`
+	TotalGas   int64  `json:"totalGas"`
+	ComputeGas int64  `json:"computeGas"`
+	StorageGas int64  `json:"storageGas"`
+}
+
+// EthStateReadKind selects the kind of read in an EthStateRead.
+type EthStateReadKind string
+
+const (
+	// EthStateReadBalance reads the balance of Address, like eth_getBalance
+	EthStateReadBalance EthStateReadKind = "balance"
+	// EthStateReadNonce reads the nonce of Address, like eth_getTransactionCount
+	EthStateReadNonce EthStateReadKind = "nonce"
+	// EthStateReadStorage reads the Position storage slot of Address, like eth_getStorageAt
+	EthStateReadStorage EthStateReadKind = "storage"
+	// EthStateReadCall executes Call, like eth_call
+	EthStateReadCall EthStateReadKind = "call"
+)
+
+// EthStateRead is a single read of an EthBatchStateRead batch.
+type EthStateRead struct {
+	Kind     EthStateReadKind    `json:"kind"`
+	Address  ethtypes.EthAddress `json:"address"`
+	Position ethtypes.EthBytes   `json:"position,omitempty"`
+	Call     *ethtypes.EthCall   `json:"call,omitempty"`
+}
+
+// EthStateReadResult is the result of the EthStateRead at the same index.
+// Balance is set for balance reads, Nonce for nonce reads and Data for storage
+// and call reads, unless the read failed with Error.
+type EthStateReadResult struct {
+	Balance *ethtypes.EthBigInt `json:"balance,omitempty"`
+	Nonce   *ethtypes.EthUint64 `json:"nonce,omitempty"`
+	Data    ethtypes.EthBytes   `json:"data,omitempty"`
+	Error   string              `json:"error,omitempty"`
+}
+
+// EthAccountOverride replaces the balance or nonce of an account in the state
+// an EthCallBundle bundle is simulated on. Nil fields are left unchanged.
+type EthAccountOverride struct {
+	Address ethtypes.EthAddress `json:"address"`
+	Balance *ethtypes.EthBigInt `json:"balance,omitempty"`
+	Nonce   *ethtypes.EthUint64 `json:"nonce,omitempty"`
+}
+
+// EthBundleResult is the result of EthCallBundle.
+type EthBundleResult struct {
+	// BlockNumber is the height of the block the bundle was simulated on,
+	// which is an earlier block than requested when the requested one is at a
+	// network upgrade
+	BlockNumber  ethtypes.EthUint64     `json:"blockNumber"`
+	Results      []EthBundleTxResult    `json:"results"`
+	StateChanges []EthBundleStateChange `json:"stateChanges"`
+	TotalGasUsed ethtypes.EthUint64     `json:"totalGasUsed"`
+}
+
+// EthBundleTxResult is the result of a single transaction of a bundle. A
+// failed transaction doesn't abort the bundle.
+type EthBundleTxResult struct {
+	From       ethtypes.EthAddress  `json:"from"`
+	To         *ethtypes.EthAddress `json:"to"`
+	GasUsed    ethtypes.EthUint64   `json:"gasUsed"`
+	ReturnData ethtypes.EthBytes    `json:"returnData"`
+	// Error is set when the transaction failed, and Revert to the revert
+	// reason when it reverted
+	Error  string `json:"error,omitempty"`
+	Revert string `json:"revert,omitempty"`
+}
+
+// EthBundleStateChange is the change of an actor by a bundle, compared to the
+// state with the overrides applied.
+type EthBundleStateChange struct {
+	Address       ethtypes.EthAddress `json:"address"`
+	BalanceBefore ethtypes.EthBigInt  `json:"balanceBefore"`
+	BalanceAfter  ethtypes.EthBigInt  `json:"balanceAfter"`
+	NonceBefore   ethtypes.EthUint64  `json:"nonceBefore"`
+	NonceAfter    ethtypes.EthUint64  `json:"nonceAfter"`
+	Created       bool                `json:"created,omitempty"`
+	Deleted       bool                `json:"deleted,omitempty"`
+}
+
+// EthSponsoredCall is a contract call made on behalf of From, with the gas
+// paid by Sponsor.
+type EthSponsoredCall struct {
+	// Sponsor is the wallet address sending the message, the default sponsor
+	// of the node when empty
+	Sponsor   address.Address     `json:"sponsor"`
+	Forwarder ethtypes.EthAddress `json:"forwarder"`
+
+	From  ethtypes.EthAddress `json:"from"`
+	To    ethtypes.EthAddress `json:"to"`
+	Value ethtypes.EthBigInt  `json:"value"`
+	Gas   ethtypes.EthUint64  `json:"gas"`
+	Data  ethtypes.EthBytes   `json:"data"`
+
+	// Nonce is the nonce of From in the forwarder, read from the forwarder
+	// when nil
+	Nonce *ethtypes.EthUint64 `json:"nonce,omitempty"`
+	// Signature is the EIP-712 signature of the forward request by From
+	Signature ethtypes.EthBytes `json:"signature,omitempty"`
+}
+
+type EthSponsoredCallResult struct {
+	Sponsor    address.Address    `json:"sponsor"`
+	MessageCid cid.Cid            `json:"messageCid"`
+	Nonce      ethtypes.EthUint64 `json:"nonce"`
+	Signature  ethtypes.EthBytes  `json:"signature"`
+}
+
+// EthRawTxDecoding is returned by EthDecodeRawTransaction. When the transaction
+// can't be translated to a Filecoin message, Message is nil and TranslationError
+// holds the reason EthSendRawTransaction would reject it.
+type EthRawTxDecoding struct {
+	Tx ethtypes.EthRawTx `json:"tx"`
+
+	// From and Sender are the recovered sender, unset if recovery failed
+	From   *ethtypes.EthAddress `json:"from"`
+	Sender address.Address      `json:"sender"`
+
+	// GasLimit, GasFeeCap and GasPremium are the Filecoin gas parameters the
+	// transaction fees map to
+	GasLimit   int64           `json:"gasLimit"`
+	GasFeeCap  abi.TokenAmount `json:"gasFeeCap"`
+	GasPremium abi.TokenAmount `json:"gasPremium"`
+
+	Message          *types.Message `json:"message,omitempty"`
+	MessageCid       *cid.Cid       `json:"messageCid,omitempty"`
+	TranslationError string         `json:"translationError,omitempty"`
+}
+
 type EthTxReceipt struct {
 	TransactionHash   ethtypes.EthHash     `json:"transactionHash"`
 	TransactionIndex  ethtypes.EthUint64   `json:"transactionIndex"`
@@ -1386,3 +2042,113 @@ type EthTxReceipt struct {
 	Logs              []ethtypes.EthLog    `json:"logs"`
 	Type              ethtypes.EthUint64   `json:"type"`
 }
+
+// ActorProof proves the state of an actor against a state root.
+type ActorProof struct {
+	Address address.Address
+	TipSet  types.TipSetKey
+	// StateRoot is the parent state root of the tipset
+	StateRoot cid.Cid
+	// Actor is nil when the actor doesn't exist
+	Actor  *types.Actor
+	Blocks []ProofBlock
+}
+
+// ProofBlock is an IPLD block of a proof.
+type ProofBlock struct {
+	Cid  cid.Cid
+	Data []byte
+}
+
+// TipSetProof holds the block headers of a tipset.
+type TipSetProof struct {
+	Key             types.TipSetKey
+	Height          abi.ChainEpoch
+	ParentStateRoot cid.Cid
+	// Headers are the CBOR serialized block headers, in tipset key order
+	Headers [][]byte
+}
+
+// EthReceiptProof proves the receipt and events of a transaction against a
+// checkpoint tipset.
+type EthReceiptProof struct {
+	TransactionHash ethtypes.EthHash
+	Message         cid.Cid
+
+	Checkpoint       types.TipSetKey
+	CheckpointHeight abi.ChainEpoch
+	// ExecutionTipSet is the tipset committing to the receipt, the child of
+	// the tipset including the message
+	ExecutionTipSet types.TipSetKey
+	ExecutionHeight abi.ChainEpoch
+	// Headers are CBOR serialized block headers, one per tipset, from the
+	// checkpoint down to the execution tipset
+	Headers [][]byte
+
+	// IncludingHeaders are the CBOR serialized block headers of the tipset
+	// including the message, the parent of the execution tipset, in tipset
+	// order
+	IncludingHeaders [][]byte
+	// MessageBlocks are the blocks proving the message is executed at Index in
+	// the including tipset: its message AMTs and the messages up to this one
+	MessageBlocks []ProofBlock
+
+	// ReceiptsRoot is the ParentMessageReceipts of the execution tipset
+	ReceiptsRoot cid.Cid
+	// Index is the index of the receipt in the receipts AMT, the execution
+	// order of the message in the including tipset
+	Index   uint64
+	Receipt types.MessageReceipt
+	Events  []types.Event
+	// Blocks are the AMT blocks proving the receipt and its events
+	Blocks []ProofBlock
+}
+
+// ActorInstallEstimate is the estimated cost of installing user actor code.
+type ActorInstallEstimate struct {
+	// CodeCid is the CID the code is installed under
+	CodeCid  cid.Cid
+	CodeSize int
+	// AlreadyInstalled is true when the code is already installed; installing
+	// it again only costs the gas of the message
+	AlreadyInstalled bool
+
+	GasLimit   int64
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+	// MaxCost is the most the message can cost, GasFeeCap * GasLimit
+	MaxCost abi.TokenAmount
+}
+
+// ProspectiveSector describes a sector to compute the collateral requirements of.
+type ProspectiveSector struct {
+	SectorSize abi.SectorSize
+	// Duration is the lifetime of the sector from its activation
+	Duration abi.ChainEpoch
+	// DealWeight and VerifiedDealWeight are the deal space-time of the sector,
+	// in byte-epochs
+	DealWeight         abi.DealWeight
+	VerifiedDealWeight abi.DealWeight
+	// Activation is the epoch the sector is expected to be activated at,
+	// defaulting to the epoch after the tipset
+	Activation abi.ChainEpoch
+}
+
+type SectorPledge struct {
+	// NetworkVersion is the network version scheduled at the activation
+	NetworkVersion   abinetwork.Version
+	Activation       abi.ChainEpoch
+	QAPower          abi.StoragePower
+	PreCommitDeposit abi.TokenAmount
+	InitialPledge    abi.TokenAmount
+}
+
+type PledgeRequirements struct {
+	Height         abi.ChainEpoch
+	NetworkVersion abinetwork.Version
+
+	Sectors []SectorPledge
+
+	PreCommitDeposit abi.TokenAmount
+	InitialPledge    abi.TokenAmount
+}
@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"time"
 
 	blocks "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-cid"
@@ -10,6 +11,7 @@ import (
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/dline"
 
 	apitypes "github.com/filecoin-project/lotus/api/types"
@@ -48,6 +50,7 @@ type Gateway interface {
 	ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error)
 	ChainGetTipSetAfterHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error)
 	ChainNotify(context.Context) (<-chan []*HeadChange, error)
+	ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*HeadChange, error)
 	ChainReadObj(context.Context, cid.Cid) ([]byte, error)
 	ChainGetGenesis(context.Context) (*types.TipSet, error)
 	GasEstimateMessageGas(ctx context.Context, msg *types.Message, spec *MessageSendSpec, tsk types.TipSetKey) (*types.Message, error)
@@ -62,6 +65,7 @@ type Gateway interface {
 	StateDealProviderCollateralBounds(ctx context.Context, size abi.PaddedPieceSize, verified bool, tsk types.TipSetKey) (DealCollateralBounds, error)
 	StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk types.TipSetKey) (interface{}, error)
 	StateGetActor(ctx context.Context, actor address.Address, ts types.TipSetKey) (*types.Actor, error)
+	StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*ActorProof, error)
 	StateReadState(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*ActorState, error)
 	StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
 	StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
@@ -94,6 +98,7 @@ type Gateway interface {
 	EthGetTransactionCount(ctx context.Context, sender ethtypes.EthAddress, blkOpt string) (ethtypes.EthUint64, error)
 	EthGetTransactionReceipt(ctx context.Context, txHash ethtypes.EthHash) (*EthTxReceipt, error)
 	EthGetTransactionReceiptLimited(ctx context.Context, txHash ethtypes.EthHash, limit abi.ChainEpoch) (*EthTxReceipt, error)
+	EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*EthReceiptProof, error)
 	EthGetCode(ctx context.Context, address ethtypes.EthAddress, blkOpt string) (ethtypes.EthBytes, error)
 	EthGetStorageAt(ctx context.Context, address ethtypes.EthAddress, position ethtypes.EthBytes, blkParam string) (ethtypes.EthBytes, error)
 	EthGetBalance(ctx context.Context, address ethtypes.EthAddress, blkParam string) (ethtypes.EthBigInt, error)
@@ -107,7 +112,14 @@ type Gateway interface {
 	EthMaxPriorityFeePerGas(ctx context.Context) (ethtypes.EthBigInt, error)
 	EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthUint64, error)
 	EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam string) (ethtypes.EthBytes, error)
+	EthBatchStateRead(ctx context.Context, reads []EthStateRead, blkParam string) ([]EthStateReadResult, error)
+	EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []EthAccountOverride) (*EthBundleResult, error)
 	EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error)
+	EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*EthRawTxDecoding, error)
+	EthTxPoolContent(ctx context.Context) (EthTxPoolContent, error)
+	EthTxPoolInspect(ctx context.Context) (EthTxPoolInspect, error)
+	EthTxPoolStatus(ctx context.Context) (EthTxPoolStatus, error)
+	EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*EthGasProfile, error)
 	EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error)
 	EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
 	EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
@@ -116,6 +128,46 @@ type Gateway interface {
 	EthNewPendingTransactionFilter(ctx context.Context) (ethtypes.EthFilterID, error)
 	EthUninstallFilter(ctx context.Context, id ethtypes.EthFilterID) (bool, error)
 	EthSubscribe(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
+	EthSubscribeStorageSlots(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
 	EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error)
 	Web3ClientVersion(ctx context.Context) (string, error)
+
+	// GatewayUsage returns the resource usage and quotas of the tenant making
+	// the call, identified by its API token.
+	GatewayUsage(ctx context.Context) (*GatewayUsage, error)
+
+	StateGetRandomnessWithProof(ctx context.Context, source RandomnessSource, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (*RandomnessProof, error)
+}
+
+// GatewayQuota limits the resources a gateway tenant can use, zero values
+// mean no limit.
+type GatewayQuota struct {
+	// Maximum number of installed eth filters
+	MaxFilters int
+	// Maximum number of active subscriptions
+	MaxSubscriptions int
+	// Maximum number of logs returned per quota period, by eth_getLogs,
+	// filters and subscriptions
+	MaxLogs int64
+	// Maximum number of epochs scanned by eth_getLogs per quota period
+	MaxLogsScanEpochs int64
+}
+
+type GatewayUsage struct {
+	// Hex encoded sha256 hash of the API token of a configured tenant, or
+	// "ip:<address>" for the other clients
+	Tenant string
+
+	Filters       int
+	Subscriptions int
+
+	// Counters for the current quota period
+	PeriodStart          time.Time
+	Period               time.Duration
+	Requests             int64
+	LogsReturned         int64
+	LogsScanEpochs       int64
+	SubscriptionMessages int64
+
+	Quota GatewayQuota
 }
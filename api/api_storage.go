@@ -85,6 +85,12 @@ type StorageMiner interface {
 	// Get summary info of sectors
 	SectorsSummary(ctx context.Context) (map[SectorState]int, error) //perm:read
 
+	// SectorsSummaryStream streams a record of every sector known locally or on
+	// chain, in chunks of length-prefixed CBOR which can be decoded with
+	// DecodeSectorRecords. Records are sent as they're built; if the stream
+	// fails, its last record carries the error.
+	SectorsSummaryStream(ctx context.Context) (<-chan []byte, error) //perm:read
+
 	// List sectors in particular states
 	SectorsListInStates(context.Context, []SectorState) ([]abi.SectorNumber, error) //perm:read
 
@@ -103,7 +109,16 @@ type StorageMiner interface {
 	SectorSetExpectedSealDuration(context.Context, time.Duration) error //perm:write
 	// SectorGetExpectedSealDuration gets the expected time for a sector to seal
 	SectorGetExpectedSealDuration(context.Context) (time.Duration, error) //perm:read
-	SectorsUpdate(context.Context, abi.SectorNumber, SectorState) error   //perm:admin
+	// SealingCostEstimate projects the FIL cost of onboarding sectors at the current
+	// base fee, following the configured batching policy
+	SealingCostEstimate(ctx context.Context, params SealingCostEstimateParams) (*SealingCostEstimate, error) //perm:read
+	// SealingPipelineStats returns the number of sectors which completed each
+	// stage of the sealing pipeline (ap, pc1, pc2, c2 and submit) over the last
+	// 24 hours, the number of sectors in, or waiting for, each stage, and the
+	// number of sectors which completed each stage on each of the last days
+	// (UTC), oldest first. The history is kept for 90 days.
+	SealingPipelineStats(ctx context.Context, days int) (*SealingPipelineStats, error) //perm:read
+	SectorsUpdate(context.Context, abi.SectorNumber, SectorState) error                //perm:admin
 	// SectorRemove removes the sector from storage. It doesn't terminate it on-chain, which can
 	// be done with SectorTerminate. Removing and not terminating live sectors will cause additional penalties.
 	SectorRemove(context.Context, abi.SectorNumber) error                           //perm:admin
@@ -131,6 +146,26 @@ type StorageMiner interface {
 	SectorAbortUpgrade(context.Context, abi.SectorNumber) error //perm:admin
 	// SectorUnseal unseals the provided sector
 	SectorUnseal(ctx context.Context, number abi.SectorNumber) error //perm:admin
+	// ProofParamsStatus reports the proof parameter files of the given sector
+	// sizes, or of all sector sizes when none are given. With verify set the
+	// digest of every present file is checked, which reads all of them.
+	ProofParamsStatus(ctx context.Context, sizes []abi.SectorSize, verify bool) (ProofParamsStatus, error) //perm:read
+	// ProofParamsFetch downloads missing or corrupted proof parameters of the
+	// given sector sizes, from the mirror gateway URL when one is given.
+	ProofParamsFetch(ctx context.Context, sizes []abi.SectorSize, mirror string) error //perm:admin
+
+	// PiecesHotness returns the retrieval read statistics of pieces, hottest
+	// first. The score of pieces is used by unsealed copy policies with a
+	// minimum retrieval score.
+	PiecesHotness(ctx context.Context) ([]PieceHotness, error) //perm:read
+
+	// SectorsReconcileUnsealed checks that the unsealed copies of the given
+	// proving sectors, or of all proving sectors when none are given, comply with
+	// the unsealed copy policies from the sealing config, returning the actions
+	// needed to bring them into compliance. The piece ranges covered by each
+	// unsealed file are checked, so a sector can need both a trim and an unseal.
+	// When apply is set the actions are also executed.
+	SectorsReconcileUnsealed(ctx context.Context, sectors []abi.SectorNumber, apply bool) ([]UnsealedReconcileAction, error) //perm:admin
 
 	// SectorNumAssignerMeta returns sector number assigner metadata - reserved/allocated
 	SectorNumAssignerMeta(ctx context.Context) (NumAssignerMeta, error) //perm:read
@@ -154,6 +189,17 @@ type StorageMiner interface {
 	WorkerStats(context.Context) (map[uuid.UUID]storiface.WorkerStats, error) //perm:admin
 	WorkerJobs(context.Context) (map[uuid.UUID][]storiface.WorkerJob, error)  //perm:admin
 
+	// WorkerTaskHistory lists finished task executions matching the filter,
+	// newest first
+	WorkerTaskHistory(ctx context.Context, filter storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) //perm:admin
+	// WorkerTaskStats aggregates task executions finished since the given time
+	// per worker and task type, including P50/P95 durations of successful
+	// executions
+	WorkerTaskStats(ctx context.Context, since time.Time) ([]storiface.WorkerTaskStats, error) //perm:admin
+	// WorkerGPUStats returns the GPU model, memory, utilization and the running
+	// GPU tasks of all connected workers
+	WorkerGPUStats(ctx context.Context) ([]storiface.WorkerGPUStats, error) //perm:admin
+
 	// storiface.WorkerReturn
 	ReturnDataCid(ctx context.Context, callID storiface.CallID, pi abi.PieceInfo, err *storiface.CallError) error                                         //perm:admin retry:true
 	ReturnAddPiece(ctx context.Context, callID storiface.CallID, pi abi.PieceInfo, err *storiface.CallError) error                                        //perm:admin retry:true
@@ -200,10 +246,18 @@ type StorageMiner interface {
 	// Paths with more weight and more % of free space are preferred.
 	// Note: This method doesn't filter paths based on AllowTypes/DenyTypes.
 	StorageBestAlloc(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType) ([]storiface.StorageInfo, error) //perm:admin
-	StorageLock(ctx context.Context, sector abi.SectorID, read storiface.SectorFileType, write storiface.SectorFileType) error                                   //perm:admin
-	StorageTryLock(ctx context.Context, sector abi.SectorID, read storiface.SectorFileType, write storiface.SectorFileType) (bool, error)                        //perm:admin
-	StorageList(ctx context.Context) (map[storiface.ID][]storiface.Decl, error)                                                                                  //perm:admin
-	StorageGetLocks(ctx context.Context) (storiface.SectorLocks, error)                                                                                          //perm:admin
+	// StorageBestAllocSector is like StorageBestAlloc, for files of a sector. If
+	// the sector has placement groups set, only paths in at least one of those
+	// groups are returned.
+	StorageBestAllocSector(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType, sector abi.SectorID) ([]storiface.StorageInfo, error) //perm:admin
+	// StorageSetSectorPlacement restricts new allocations of files for the
+	// sector to paths which belong to at least one of the given groups. An
+	// empty list clears the restriction.
+	StorageSetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error                                   //perm:admin
+	StorageLock(ctx context.Context, sector abi.SectorID, read storiface.SectorFileType, write storiface.SectorFileType) error            //perm:admin
+	StorageTryLock(ctx context.Context, sector abi.SectorID, read storiface.SectorFileType, write storiface.SectorFileType) (bool, error) //perm:admin
+	StorageList(ctx context.Context) (map[storiface.ID][]storiface.Decl, error)                                                           //perm:admin
+	StorageGetLocks(ctx context.Context) (storiface.SectorLocks, error)                                                                   //perm:admin
 
 	StorageLocal(ctx context.Context) (map[storiface.ID]string, error)       //perm:admin
 	StorageStat(ctx context.Context, id storiface.ID) (fsutil.FsStat, error) //perm:admin
@@ -312,6 +366,9 @@ type StorageMiner interface {
 	DealsSetConsiderVerifiedStorageDeals(context.Context, bool) error            //perm:admin
 	DealsConsiderUnverifiedStorageDeals(context.Context) (bool, error)           //perm:admin
 	DealsSetConsiderUnverifiedStorageDeals(context.Context, bool) error          //perm:admin
+	// DealsFilterDecisions returns the last decisions of the storage deal
+	// filter, newest first, with the reasons deals were rejected.
+	DealsFilterDecisions(ctx context.Context, limit int) ([]DealFilterDecision, error) //perm:read
 
 	PiecesListPieces(ctx context.Context) ([]cid.Cid, error)                                 //perm:read
 	PiecesListCidInfos(ctx context.Context) ([]cid.Cid, error)                               //perm:read
@@ -323,6 +380,12 @@ type StorageMiner interface {
 	// LOTUS_BACKUP_BASE_PATH environment variable set to some path, and that
 	// the path specified when calling CreateBackup is within the base path
 	CreateBackup(ctx context.Context, fpath string) error //perm:admin
+	// BackupCreate writes an archive of the node repo under the specified file
+	// name, holding the selected sections: keystore, identity, config and
+	// metadata, or all of them when none are selected. The archive is encrypted
+	// when a passphrase is set. As with CreateBackup, the path must be within
+	// LOTUS_BACKUP_BASE_PATH.
+	BackupCreate(ctx context.Context, fpath string, opts BackupOptions) (*BackupInfo, error) //perm:admin
 
 	CheckProvable(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storiface.SectorRef) (map[abi.SectorNumber]string, error) //perm:admin
 
@@ -332,6 +395,12 @@ type StorageMiner interface {
 	// to the miner actor with details of recovered sectors and returns the CID of messages. It honors the
 	// maxPartitionsPerRecoveryMessage from the config
 	RecoverFault(ctx context.Context, sectors []abi.SectorNumber) ([]cid.Cid, error) //perm:admin
+
+	// ProvingSimulate projects the power loss, fault fees and recovery of the
+	// sectors of the miner across its upcoming deadlines, were the given
+	// sectors, or the sectors stored on the given storage paths, unavailable
+	// for an outage starting now. Nothing is changed or sent to the chain.
+	ProvingSimulate(ctx context.Context, params ProvingSimulateParams) (*ProvingSimulation, error) //perm:read
 }
 
 var _ storiface.WorkerReturn = *new(StorageMiner)
@@ -372,6 +441,9 @@ type SectorInfo struct {
 	Retries              uint64
 	ToUpgrade            bool
 	ReplicaUpdateMessage *cid.Cid
+	PlacementGroups      []string
+	RetryClass           string
+	RetryAttempts        uint64
 
 	LastErr string
 
@@ -441,6 +513,7 @@ type AddressConfig struct {
 	CommitControl      []address.Address
 	TerminateControl   []address.Address
 	DealPublishControl []address.Address
+	WindowPoStControl  []address.Address
 
 	DisableOwnerFallback  bool
 	DisableWorkerFallback bool
@@ -466,6 +539,11 @@ type PieceDealInfo struct {
 	DealProposal *market.DealProposal
 	DealSchedule DealSchedule
 	KeepUnsealed bool
+
+	// PlacementGroups optionally restricts the sectors the deal can be put
+	// into to sectors stored in paths belonging to one of the listed groups.
+	// When empty, client placement rules from the sealing config apply.
+	PlacementGroups []string
 }
 
 // DealSchedule communicates the time interval of a storage deal. The deal must
@@ -507,6 +585,242 @@ type DagstoreInitializeAllEvent struct {
 	Current int
 }
 
+// SectorRecord is the summary of a sector streamed by SectorsSummaryStream.
+type SectorRecord struct {
+	Number abi.SectorNumber
+	// State is empty for sectors only known on chain
+	State     SectorState
+	SealProof abi.RegisteredSealProof
+	DealIDs   []abi.DealID
+
+	OnChain       bool
+	Activation    abi.ChainEpoch
+	Expiration    abi.ChainEpoch
+	InitialPledge abi.TokenAmount
+	Deadline      uint64
+	Partition     uint64
+
+	// Error is only set on the last record of a stream which failed, the
+	// other fields are empty
+	Error string
+}
+
+// ProofParamsStatus describes the proof parameter cache of a node.
+type ProofParamsStatus struct {
+	Dir string
+	// DiskUsage is the size of all files in the parameter directory
+	DiskUsage int64
+
+	Files     []ProofParamFile
+	Missing   int
+	Corrupted int
+}
+
+type ProofParamFile struct {
+	Name       string
+	SectorSize abi.SectorSize
+	Size       int64
+	Present    bool
+	// Verified is set when the digest of the file was checked
+	Verified bool
+	Error    string
+}
+
+// PieceHotness describes how often a piece was read for retrievals.
+type PieceHotness struct {
+	Sector abi.SectorID
+	Offset abi.UnpaddedPieceSize
+	Size   abi.UnpaddedPieceSize
+
+	Reads uint64
+	// Unseals counts the reads which required unsealing the piece
+	Unseals   uint64
+	FirstRead time.Time
+	LastRead  time.Time
+
+	// Score is the number of reads with exponential decay applied, each read
+	// counting half as much after each week
+	Score float64
+}
+
+const (
+	// UnsealedActionRemove removes the unsealed copy of a sector
+	UnsealedActionRemove = "remove"
+	// UnsealedActionTrim frees the ranges of the unsealed copy which aren't kept
+	UnsealedActionTrim = "trim"
+	// UnsealedActionUnseal unseals the ranges which must be kept
+	UnsealedActionUnseal = "unseal"
+)
+
+// UnsealedReconcileAction is an action bringing the unsealed copy of a sector
+// into compliance with the unsealed copy policies.
+type UnsealedReconcileAction struct {
+	Sector abi.SectorNumber
+	Action string
+	// Keep are the ranges of sector data which must be kept unsealed
+	Keep []storiface.Range
+	// Missing are the kept ranges the unsealed copy doesn't cover, set on
+	// unseal actions
+	Missing []storiface.Range
+
+	Applied bool
+	Error   string
+}
+
+// SealingCostEstimateParams describes the sectors to estimate the onboarding cost of.
+type SealingCostEstimateParams struct {
+	Sectors int
+
+	// SectorSize defaults to the sector size of the miner
+	SectorSize abi.SectorSize
+	// Duration is the lifetime of the sectors, defaulting to the configured
+	// committed capacity sector lifetime
+	Duration abi.ChainEpoch
+
+	// DealSpace and VerifiedDealSpace are the space of each sector filled with
+	// deals lasting the whole lifetime of the sector
+	DealSpace         abi.PaddedPieceSize
+	VerifiedDealSpace abi.PaddedPieceSize
+}
+
+type SealingCostEstimate struct {
+	Sectors        int
+	SealProof      abi.RegisteredSealProof
+	Height         abi.ChainEpoch
+	Expiration     abi.ChainEpoch
+	NetworkVersion abinetwork.Version
+	BaseFee        abi.TokenAmount
+
+	// QAPower, PreCommitDeposit and InitialPledge are per sector
+	QAPower          abi.StoragePower
+	PreCommitDeposit abi.TokenAmount
+	InitialPledge    abi.TokenAmount
+
+	PreCommit OnboardingMessagesCost
+	Commit    OnboardingMessagesCost
+
+	// Pledge is the collateral locked by all sectors once proven
+	Pledge abi.TokenAmount
+	// Fees are the gas and network fees burnt by all messages
+	Fees abi.TokenAmount
+	// Total is Pledge plus Fees
+	Total abi.TokenAmount
+}
+
+// OnboardingMessagesCost is the cost of the messages sending one step of
+// onboarding for all sectors.
+type OnboardingMessagesCost struct {
+	// Batched is set when sectors are batched or aggregated
+	Batched  bool
+	Messages int
+	// GasUsed is an approximation of the gas used by all messages
+	GasUsed    int64
+	GasFee     abi.TokenAmount
+	NetworkFee abi.TokenAmount
+}
+
+// ProvingSimulateParams describe a hypothetical outage of sectors.
+type ProvingSimulateParams struct {
+	// Sectors are unavailable
+	Sectors []abi.SectorNumber
+	// StoragePaths are missing: the sectors without a sealed replica and cache
+	// on the other storage paths are unavailable
+	StoragePaths []storiface.ID
+	// Outage is the number of epochs the sectors are unavailable for
+	Outage abi.ChainEpoch
+}
+
+// ProvingSimulation is the projected outcome of an outage of sectors. The
+// sectors are assumed not to have been proven yet in a challenge window open
+// at the start of the outage. Termination fees of sectors faulty for longer
+// than the maximum fault age are not estimated.
+type ProvingSimulation struct {
+	Height abi.ChainEpoch
+	Outage abi.ChainEpoch
+
+	// Sectors are the active sectors unavailable during the outage
+	Sectors int
+	// Skipped sectors are unavailable but already faulty, or not active
+	Skipped []abi.SectorNumber
+
+	// RawPower and QAPower are the power lost by the faulty sectors
+	RawPower abi.StoragePower
+	QAPower  abi.StoragePower
+	// Penalty is the total of the fault fees charged
+	Penalty abi.TokenAmount
+	// Recovered is the epoch by which all sectors regained their power, or
+	// were terminated
+	Recovered abi.ChainEpoch
+	// Terminated is the number of sectors terminated for being faulty for
+	// longer than the maximum fault age
+	Terminated int
+
+	Deadlines []ProvingSimulationDeadline
+}
+
+// ProvingSimulationDeadline is the projected outcome of an outage for the
+// sectors of a deadline.
+type ProvingSimulationDeadline struct {
+	Index   uint64
+	Sectors int
+
+	RawPower abi.StoragePower
+	QAPower  abi.StoragePower
+
+	// Faulted is the close of the first challenge window missed by the
+	// sectors, when they are marked faulty and lose their power, or zero when
+	// they don't miss any window
+	Faulted abi.ChainEpoch
+	// FaultFee is charged at the close of each window the sectors remain
+	// faulty through
+	FaultFee  abi.TokenAmount
+	FaultFees int
+	Penalty   abi.TokenAmount
+
+	// Recovered is the close of the window the sectors are proven in again,
+	// after declaring their recovery, or zero when they are terminated
+	Recovered abi.ChainEpoch
+	// Terminated is the epoch the sectors are terminated at, or zero
+	Terminated abi.ChainEpoch
+}
+
+// DealFilterDecision is a decision of the storage deal filter, recorded in its
+// audit log.
+type DealFilterDecision struct {
+	Time                 time.Time
+	ProposalCid          cid.Cid
+	Client               address.Address
+	PieceCid             cid.Cid
+	PieceSize            abi.PaddedPieceSize
+	Verified             bool
+	StoragePricePerEpoch abi.TokenAmount
+	Accepted             bool
+	Reason               string
+}
+
+// SealingPipelineStats describes the throughput of the sealing pipeline.
+type SealingPipelineStats struct {
+	Stages  []SealingStageStats
+	History []SealingPipelineDay
+}
+
+// SealingStageStats describes a stage of the sealing pipeline.
+type SealingStageStats struct {
+	Stage string
+	// PerDay is the number of sectors which completed the stage over the last
+	// 24 hours
+	PerDay int64
+	// Queued is the number of sectors in, or waiting for, the stage
+	Queued int64
+}
+
+// SealingPipelineDay is the number of sectors which completed each stage of
+// the sealing pipeline on a day, formatted as YYYY-MM-DD.
+type SealingPipelineDay struct {
+	Day       string
+	Completed map[string]int64
+}
+
 type NumAssignerMeta struct {
 	Reserved  bitfield.BitField
 	Allocated bitfield.BitField
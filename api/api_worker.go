@@ -32,6 +32,9 @@ type Worker interface {
 	Paths(context.Context) ([]storiface.StoragePath, error)             //perm:admin
 	Info(context.Context) (storiface.WorkerInfo, error)                 //perm:admin
 
+	// GPUStats returns the usage of the GPUs of the worker
+	GPUStats(context.Context) ([]storiface.GPUStats, error) //perm:admin
+
 	// storiface.WorkerCalls
 	DataCid(ctx context.Context, pieceSize abi.UnpaddedPieceSize, pieceData storiface.Data) (storiface.CallID, error)                                                                                        //perm:admin
 	AddPiece(ctx context.Context, sector storiface.SectorRef, pieceSizes []abi.UnpaddedPieceSize, newPieceSize abi.UnpaddedPieceSize, pieceData storiface.Data) (storiface.CallID, error)                    //perm:admin
@@ -782,7 +782,7 @@ func (t *PieceDealInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{165}); err != nil {
+	if _, err := cw.Write([]byte{166}); err != nil {
 		return err
 	}
 
@@ -871,6 +871,38 @@ func (t *PieceDealInfo) MarshalCBOR(w io.Writer) error {
 	if err := cbg.WriteBool(w, t.KeepUnsealed); err != nil {
 		return err
 	}
+
+	// t.PlacementGroups ([]string) (slice)
+	if len("PlacementGroups") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"PlacementGroups\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("PlacementGroups"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("PlacementGroups")); err != nil {
+		return err
+	}
+
+	if len(t.PlacementGroups) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.PlacementGroups was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.PlacementGroups))); err != nil {
+		return err
+	}
+	for _, v := range t.PlacementGroups {
+		if len(v) > cbg.MaxLength {
+			return xerrors.Errorf("Value in field v was too long")
+		}
+
+		if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(v))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(v)); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -998,6 +1030,37 @@ func (t *PieceDealInfo) UnmarshalCBOR(r io.Reader) (err error) {
 			default:
 				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
 			}
+			// t.PlacementGroups ([]string) (slice)
+		case "PlacementGroups":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.PlacementGroups: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.PlacementGroups = make([]string, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				{
+					sval, err := cbg.ReadString(cr)
+					if err != nil {
+						return err
+					}
+
+					t.PlacementGroups[i] = string(sval)
+				}
+			}
 
 		default:
 			// Field doesn't exist on this type, so ignore it
@@ -1287,3 +1350,488 @@ func (t *DealSchedule) UnmarshalCBOR(r io.Reader) (err error) {
 
 	return nil
 }
+func (t *SectorRecord) MarshalCBOR(w io.Writer) error {
+	if t == nil {
+		_, err := w.Write(cbg.CborNull)
+		return err
+	}
+
+	cw := cbg.NewCborWriter(w)
+
+	if _, err := cw.Write([]byte{171}); err != nil {
+		return err
+	}
+
+	// t.Error (string) (string)
+	if len("Error") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Error\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Error"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Error")); err != nil {
+		return err
+	}
+
+	if len(t.Error) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.Error was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.Error))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.Error)); err != nil {
+		return err
+	}
+
+	// t.State (api.SectorState) (string)
+	if len("State") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"State\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("State"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("State")); err != nil {
+		return err
+	}
+
+	if len(t.State) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.State was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.State))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.State)); err != nil {
+		return err
+	}
+
+	// t.Number (abi.SectorNumber) (uint64)
+	if len("Number") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Number\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Number"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Number")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Number)); err != nil {
+		return err
+	}
+
+	// t.DealIDs ([]abi.DealID) (slice)
+	if len("DealIDs") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"DealIDs\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("DealIDs"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("DealIDs")); err != nil {
+		return err
+	}
+
+	if len(t.DealIDs) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.DealIDs was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.DealIDs))); err != nil {
+		return err
+	}
+	for _, v := range t.DealIDs {
+		if err := cw.CborWriteHeader(cbg.MajUnsignedInt, uint64(v)); err != nil {
+			return err
+		}
+	}
+
+	// t.OnChain (bool) (bool)
+	if len("OnChain") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"OnChain\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("OnChain"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("OnChain")); err != nil {
+		return err
+	}
+
+	if err := cbg.WriteBool(w, t.OnChain); err != nil {
+		return err
+	}
+
+	// t.Deadline (uint64) (uint64)
+	if len("Deadline") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Deadline\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Deadline"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Deadline")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Deadline)); err != nil {
+		return err
+	}
+
+	// t.Partition (uint64) (uint64)
+	if len("Partition") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Partition\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Partition"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Partition")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Partition)); err != nil {
+		return err
+	}
+
+	// t.SealProof (abi.RegisteredSealProof) (int64)
+	if len("SealProof") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"SealProof\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("SealProof"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("SealProof")); err != nil {
+		return err
+	}
+
+	if t.SealProof >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.SealProof)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.SealProof-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Activation (abi.ChainEpoch) (int64)
+	if len("Activation") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Activation\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Activation"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Activation")); err != nil {
+		return err
+	}
+
+	if t.Activation >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Activation)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Activation-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.Expiration (abi.ChainEpoch) (int64)
+	if len("Expiration") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"Expiration\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("Expiration"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("Expiration")); err != nil {
+		return err
+	}
+
+	if t.Expiration >= 0 {
+		if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.Expiration)); err != nil {
+			return err
+		}
+	} else {
+		if err := cw.WriteMajorTypeHeader(cbg.MajNegativeInt, uint64(-t.Expiration-1)); err != nil {
+			return err
+		}
+	}
+
+	// t.InitialPledge (big.Int) (struct)
+	if len("InitialPledge") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"InitialPledge\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("InitialPledge"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("InitialPledge")); err != nil {
+		return err
+	}
+
+	if err := t.InitialPledge.MarshalCBOR(cw); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (t *SectorRecord) UnmarshalCBOR(r io.Reader) (err error) {
+	*t = SectorRecord{}
+
+	cr := cbg.NewCborReader(r)
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+	}()
+
+	if maj != cbg.MajMap {
+		return fmt.Errorf("cbor input should be of type map")
+	}
+
+	if extra > cbg.MaxLength {
+		return fmt.Errorf("SectorRecord: map struct too large (%d)", extra)
+	}
+
+	var name string
+	n := extra
+
+	for i := uint64(0); i < n; i++ {
+
+		{
+			sval, err := cbg.ReadString(cr)
+			if err != nil {
+				return err
+			}
+
+			name = string(sval)
+		}
+
+		switch name {
+		// t.Error (string) (string)
+		case "Error":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.Error = string(sval)
+			}
+			// t.State (api.SectorState) (string)
+		case "State":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.State = SectorState(sval)
+			}
+			// t.Number (abi.SectorNumber) (uint64)
+		case "Number":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Number = abi.SectorNumber(extra)
+
+			}
+			// t.DealIDs ([]abi.DealID) (slice)
+		case "DealIDs":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.DealIDs: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.DealIDs = make([]abi.DealID, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				maj, val, err := cr.ReadHeader()
+				if err != nil {
+					return xerrors.Errorf("failed to read uint64 for t.DealIDs slice: %w", err)
+				}
+
+				if maj != cbg.MajUnsignedInt {
+					return xerrors.Errorf("value read for array t.DealIDs was not a uint, instead got %d", maj)
+				}
+
+				t.DealIDs[i] = abi.DealID(val)
+			}
+
+			// t.OnChain (bool) (bool)
+		case "OnChain":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+			if maj != cbg.MajOther {
+				return fmt.Errorf("booleans must be major type 7")
+			}
+			switch extra {
+			case 20:
+				t.OnChain = false
+			case 21:
+				t.OnChain = true
+			default:
+				return fmt.Errorf("booleans are either major type 7, value 20 or 21 (got %d)", extra)
+			}
+			// t.Deadline (uint64) (uint64)
+		case "Deadline":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Deadline = uint64(extra)
+
+			}
+			// t.Partition (uint64) (uint64)
+		case "Partition":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.Partition = uint64(extra)
+
+			}
+			// t.SealProof (abi.RegisteredSealProof) (int64)
+		case "SealProof":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.SealProof = abi.RegisteredSealProof(extraI)
+			}
+			// t.Activation (abi.ChainEpoch) (int64)
+		case "Activation":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Activation = abi.ChainEpoch(extraI)
+			}
+			// t.Expiration (abi.ChainEpoch) (int64)
+		case "Expiration":
+			{
+				maj, extra, err := cr.ReadHeader()
+				var extraI int64
+				if err != nil {
+					return err
+				}
+				switch maj {
+				case cbg.MajUnsignedInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 positive overflow")
+					}
+				case cbg.MajNegativeInt:
+					extraI = int64(extra)
+					if extraI < 0 {
+						return fmt.Errorf("int64 negative overflow")
+					}
+					extraI = -1 - extraI
+				default:
+					return fmt.Errorf("wrong type for int64 field: %d", maj)
+				}
+
+				t.Expiration = abi.ChainEpoch(extraI)
+			}
+			// t.InitialPledge (big.Int) (struct)
+		case "InitialPledge":
+
+			{
+
+				if err := t.InitialPledge.UnmarshalCBOR(cr); err != nil {
+					return xerrors.Errorf("unmarshaling t.InitialPledge: %w", err)
+				}
+
+			}
+
+		default:
+			// Field doesn't exist on this type, so ignore it
+			cbg.ScanForLinks(r, func(cid.Cid) {})
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package api
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Optional features of the full node API, which the configuration of a node
+// may disable.
+const (
+	// FeatureEthRPC is the Ethereum JSON-RPC API (Fevm.EnableEthRPC).
+	FeatureEthRPC = "eth-rpc"
+	// FeatureEthTxHashLookup is the lookup of messages by Ethereum transaction
+	// hash.
+	FeatureEthTxHashLookup = "eth-tx-hash-lookup"
+	// FeatureEthFilters is the Ethereum filter API, and eth_getLogs.
+	FeatureEthFilters = "eth-filters"
+	// FeatureEthSubscriptions is eth_subscribe.
+	FeatureEthSubscriptions = "eth-subscriptions"
+	// FeatureEthHistoricLogs is the index of past actor events, needed by the
+	// paged and streamed log queries.
+	FeatureEthHistoricLogs = "eth-historic-logs"
+	// FeatureUserActors is the experimental user actor API
+	// (UserActors.Enable).
+	FeatureUserActors = "user-actors"
+	// FeatureIndexQuery is the SQL query API over the indexes
+	// (Index.EnableSQLQuery).
+	FeatureIndexQuery = "index-query"
+)
+
+// MethodFeatures maps the methods of the full node API which are only served
+// when an optional feature is enabled to the feature. Eth methods missing
+// from the map need FeatureEthRPC. The features of the Eth methods are checked
+// against their implementation by TestEthMethodFeatures in node/impl/full.
+var MethodFeatures = map[string]string{
+	"EthGetTransactionByHash":           FeatureEthTxHashLookup,
+	"EthGetTransactionByHashLimited":    FeatureEthTxHashLookup,
+	"EthGetTransactionHashByCid":        FeatureEthTxHashLookup,
+	"EthGetMessageCidByTransactionHash": FeatureEthTxHashLookup,
+	"EthGetTransactionReceipt":          FeatureEthTxHashLookup,
+	"EthGetTransactionReceiptLimited":   FeatureEthTxHashLookup,
+	"EthGetTransactionReceiptProof":     FeatureEthTxHashLookup,
+	"EthTraceTransactionGasProfile":     FeatureEthTxHashLookup,
+
+	"EthGetLogs":                     FeatureEthFilters,
+	"EthGetFilterChanges":            FeatureEthFilters,
+	"EthGetFilterLogs":               FeatureEthFilters,
+	"EthNewFilter":                   FeatureEthFilters,
+	"EthNewBlockFilter":              FeatureEthFilters,
+	"EthNewPendingTransactionFilter": FeatureEthFilters,
+	"EthUninstallFilter":             FeatureEthFilters,
+
+	"EthSubscribe":             FeatureEthSubscriptions,
+	"EthSubscribeStorageSlots": FeatureEthSubscriptions,
+	"EthUnsubscribe":           FeatureEthSubscriptions,
+
+	"EthGetLogsPage":   FeatureEthHistoricLogs,
+	"EthGetLogsStream": FeatureEthHistoricLogs,
+
+	"ActorValidateCode":    FeatureUserActors,
+	"ActorEstimateInstall": FeatureUserActors,
+	"ActorInstall":         FeatureUserActors,
+	"ActorCreate":          FeatureUserActors,
+	"StateInstalledActors": FeatureUserActors,
+
+	"ChainIndexQuery": FeatureIndexQuery,
+}
+
+// MethodFeature returns the optional feature a method needs, or an empty
+// string.
+func MethodFeature(method string) string {
+	if f, ok := MethodFeatures[method]; ok {
+		return f
+	}
+	if strings.HasPrefix(method, "Eth") {
+		return FeatureEthRPC
+	}
+	return ""
+}
+
+// DeprecatedMethods maps the deprecated methods of the APIs to a notice
+// telling what to use instead. The methods are marked as deprecated in the
+// OpenRPC documents.
+var DeprecatedMethods = map[string]string{
+	"MarketListRetrievalDeals": "retrieval deals are no longer tracked, the method always returns an empty list",
+}
+
+// FullAPISpec describes the methods of the full node API, as registered on the
+// RPC server. enabled tells which optional features are enabled; features
+// missing from it, and the features they depend on, are disabled.
+func FullAPISpec(enabled map[string]bool) *APISpec {
+	aliases := aliasRecorder{}
+	CreateEthRPCAliases(aliases)
+
+	isEnabled := func(feature string) bool {
+		if feature == "" {
+			return true
+		}
+		if feature != FeatureEthRPC && feature != FeatureUserActors && feature != FeatureIndexQuery && !enabled[FeatureEthRPC] {
+			return false
+		}
+		return enabled[feature]
+	}
+
+	spec := &APISpec{}
+	for _, f := range []string{FeatureEthRPC, FeatureEthTxHashLookup, FeatureEthFilters, FeatureEthSubscriptions, FeatureEthHistoricLogs, FeatureUserActors, FeatureIndexQuery} {
+		spec.Features = append(spec.Features, APIFeature{Name: f, Enabled: isEnabled(f)})
+	}
+
+	for _, str := range GetInternalStructs(new(FullNodeStruct)) {
+		rt := reflect.TypeOf(str).Elem()
+		for i := 0; i < rt.NumField(); i++ {
+			field := rt.Field(i)
+			name := "Filecoin." + field.Name
+
+			m := APIMethod{
+				Name:         name,
+				Aliases:      aliases[name],
+				Permission:   field.Tag.Get("perm"),
+				Feature:      MethodFeature(field.Name),
+				Deprecated:   DeprecatedMethods[field.Name],
+				Subscription: field.Type.NumOut() > 0 && field.Type.Out(0).Kind() == reflect.Chan,
+			}
+			m.Enabled = isEnabled(m.Feature)
+			sort.Strings(m.Aliases)
+
+			spec.Methods = append(spec.Methods, m)
+		}
+	}
+	sort.Slice(spec.Methods, func(i, j int) bool {
+		return spec.Methods[i].Name < spec.Methods[j].Name
+	})
+
+	return spec
+}
+
+// aliasRecorder records the aliases of methods, by method.
+type aliasRecorder map[string][]string
+
+func (r aliasRecorder) AliasMethod(alias, original string) {
+	r[original] = append(r[original], alias)
+}
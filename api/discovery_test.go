@@ -0,0 +1,60 @@
+// stm: #unit
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFullAPISpec(t *testing.T) {
+	spec := FullAPISpec(map[string]bool{
+		FeatureEthRPC:          true,
+		FeatureEthTxHashLookup: true,
+		FeatureEthFilters:      true,
+	})
+
+	features := map[string]bool{}
+	for _, f := range spec.Features {
+		features[f.Name] = f.Enabled
+	}
+	require.True(t, features[FeatureEthFilters])
+	require.False(t, features[FeatureEthSubscriptions])
+	require.False(t, features[FeatureUserActors])
+
+	methods := map[string]APIMethod{}
+	for _, m := range spec.Methods {
+		methods[m.Name] = m
+	}
+
+	head := methods["Filecoin.ChainHead"]
+	require.True(t, head.Enabled)
+	require.Empty(t, head.Feature)
+	require.Equal(t, "read", head.Permission)
+	require.True(t, methods["Filecoin.ChainNotify"].Subscription)
+
+	logs := methods["Filecoin.EthGetLogs"]
+	require.True(t, logs.Enabled)
+	require.Equal(t, FeatureEthFilters, logs.Feature)
+	require.Equal(t, []string{"eth_getLogs"}, logs.Aliases)
+
+	sub := methods["Filecoin.EthSubscribe"]
+	require.False(t, sub.Enabled)
+	require.Equal(t, FeatureEthSubscriptions, sub.Feature)
+
+	require.Equal(t, []string{"eth_signTypedData", "eth_signTypedData_v4"}, methods["Filecoin.EthSignTypedData"].Aliases)
+	require.True(t, methods["Filecoin.EthChainId"].Enabled)
+
+	// the methods needing features are methods of the API
+	for method := range MethodFeatures {
+		require.Contains(t, methods, "Filecoin."+method)
+	}
+
+	// Eth features are disabled along with the Eth API
+	spec = FullAPISpec(map[string]bool{FeatureEthFilters: true})
+	for _, m := range spec.Methods {
+		if m.Name == "Filecoin.EthGetLogs" {
+			require.False(t, m.Enabled)
+		}
+	}
+}
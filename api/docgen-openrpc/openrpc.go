@@ -11,6 +11,7 @@ import (
 	"github.com/ipfs/go-cid"
 	meta_schema "github.com/open-rpc/meta-schema"
 
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/api/docgen"
 	"github.com/filecoin-project/lotus/build"
 )
@@ -149,6 +150,11 @@ func NewLotusOpenRPCDocument(Comments, GroupDocs map[string]string) *go_openrpc_
 		return "", nil // noComment
 	}
 
+	appReflector.FnGetMethodDeprecated = func(r reflect.Value, m reflect.Method, funcDecl *ast.FuncDecl) (bool, error) {
+		_, deprecated := api.DeprecatedMethods[m.Name]
+		return deprecated, nil
+	}
+
 	appReflector.FnSchemaExamples = func(ty reflect.Type) (examples *meta_schema.Examples, err error) {
 		v := docgen.ExampleValue("unknown", ty, ty) // This isn't ideal, but seems to work well enough.
 		return &meta_schema.Examples{
@@ -32,6 +32,7 @@ import (
 	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
 	"github.com/filecoin-project/go-jsonrpc/auth"
 	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/go-state-types/builtin/v9/verifreg"
 	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/exitcode"
@@ -86,6 +87,7 @@ func init() {
 	}
 
 	ExampleValues[reflect.TypeOf(addr)] = addr
+	addExample(&addr)
 
 	pid, err := peer.Decode("12D3KooWGzxzKZYveHXtpG6AsrUJBcWxHBFS2HsEoGTxrMLvKXtf")
 	if err != nil {
@@ -120,6 +122,8 @@ func init() {
 	addExample(abi.UnpaddedPieceSize(1024).Padded())
 	addExample(abi.DealID(5432))
 	addExample(abi.SectorNumber(9))
+	sectorNumberExample := abi.SectorNumber(9)
+	addExample(&sectorNumberExample)
 	addExample(abi.SectorSize(32 * 1024 * 1024 * 1024))
 	addExample(api.MpoolChange(0))
 	addExample(network.Connected)
@@ -127,6 +131,9 @@ func init() {
 	addExample(api.SyncStateStage(1))
 	addExample(api.FullAPIVersion1)
 	addExample(api.PCHInbound)
+	addExample(api.RandomnessFromBeacon)
+	addExample(api.EthStateReadBalance)
+	addExample(api.ScheduledMessagePending)
 	addExample(time.Minute)
 	addExample(graphsync.NewRequestID())
 	addExample(datatransfer.TransferID(3))
@@ -141,6 +148,8 @@ func init() {
 	addExample(&apiSelExample)
 	addExample(network.ReachabilityPublic)
 	addExample(build.TestNetworkVersion)
+	addExample(actorstypes.Version11)
+	addExample(map[string]api.ObjStat{"dag-cbor": {Size: 1024, Links: 4}})
 	allocationId := verifreg.AllocationId(0)
 	addExample(allocationId)
 	addExample(&allocationId)
@@ -276,6 +285,7 @@ func init() {
 	addExample(map[api.SectorState]int{
 		api.SectorState(sealing.Proving): 120,
 	})
+	addExample(map[string]int64{"pc1": 42})
 	addExample([]abi.SectorNumber{123, 124})
 	addExample([]storiface.SectorLock{
 		{
@@ -402,6 +412,48 @@ func init() {
 		Address:   []ethtypes.EthAddress{ethaddr},
 	})
 
+	addExample(map[string]map[ethtypes.EthUint64]ethtypes.EthTx{
+		ethaddr.String(): {ethint: ExampleValue("init", reflect.TypeOf(ethtypes.EthTx{}), nil).(ethtypes.EthTx)},
+	})
+	addExample(map[string]map[ethtypes.EthUint64]*types.SignedMessage{
+		"f01234": {ethint: ExampleValue("init", reflect.TypeOf(&types.SignedMessage{}), nil).(*types.SignedMessage)},
+	})
+	addExample(map[string]map[ethtypes.EthUint64]string{
+		ethaddr.String(): {ethint: ethaddr.String() + ": 0 wei + 21000 gas × 100 wei"},
+	})
+
+	typedData := ethtypes.EthTypedData{
+		Types: map[string][]ethtypes.EthTypedDataField{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Permit": {
+				{Name: "owner", Type: "address"},
+				{Name: "spender", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "deadline", Type: "uint256"},
+			},
+		},
+		PrimaryType: "Permit",
+		Domain: map[string]interface{}{
+			"name":              "Token",
+			"chainId":           314,
+			"verifyingContract": ethaddr.String(),
+		},
+		Message: map[string]interface{}{
+			"owner":    ethaddr.String(),
+			"spender":  ethaddr.String(),
+			"value":    "1000000000000000000",
+			"nonce":    0,
+			"deadline": 1700000000,
+		},
+	}
+	addExample(typedData)
+	addExample(&typedData)
+
 	percent := types.Percent(123)
 	addExample(percent)
 	addExample(&percent)
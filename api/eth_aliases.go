@@ -27,6 +27,9 @@ func CreateEthRPCAliases(as apitypes.Aliaser) {
 	as.AliasMethod("eth_maxPriorityFeePerGas", "Filecoin.EthMaxPriorityFeePerGas")
 	as.AliasMethod("eth_gasPrice", "Filecoin.EthGasPrice")
 	as.AliasMethod("eth_sendRawTransaction", "Filecoin.EthSendRawTransaction")
+	as.AliasMethod("eth_decodeRawTransaction", "Filecoin.EthDecodeRawTransaction")
+	as.AliasMethod("eth_signTypedData", "Filecoin.EthSignTypedData")
+	as.AliasMethod("eth_signTypedData_v4", "Filecoin.EthSignTypedData")
 	as.AliasMethod("eth_estimateGas", "Filecoin.EthEstimateGas")
 	as.AliasMethod("eth_call", "Filecoin.EthCall")
 
@@ -39,6 +42,16 @@ func CreateEthRPCAliases(as apitypes.Aliaser) {
 	as.AliasMethod("eth_uninstallFilter", "Filecoin.EthUninstallFilter")
 	as.AliasMethod("eth_subscribe", "Filecoin.EthSubscribe")
 	as.AliasMethod("eth_unsubscribe", "Filecoin.EthUnsubscribe")
+	as.AliasMethod("filecoin_subscribeStorageSlots", "Filecoin.EthSubscribeStorageSlots")
+	as.AliasMethod("filecoin_batchStateRead", "Filecoin.EthBatchStateRead")
+	as.AliasMethod("filecoin_callBundle", "Filecoin.EthCallBundle")
+	as.AliasMethod("filecoin_getTransactionReceiptProof", "Filecoin.EthGetTransactionReceiptProof")
+
+	as.AliasMethod("txpool_content", "Filecoin.EthTxPoolContent")
+	as.AliasMethod("txpool_inspect", "Filecoin.EthTxPoolInspect")
+	as.AliasMethod("txpool_status", "Filecoin.EthTxPoolStatus")
+
+	as.AliasMethod("trace_transactionGasProfile", "Filecoin.EthTraceTransactionGasProfile")
 
 	as.AliasMethod("net_version", "Filecoin.NetVersion")
 	as.AliasMethod("net_listening", "Filecoin.NetListening")
@@ -0,0 +1,172 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// EEthExecutionReverted is the code geth returns the errors of reverted calls
+// with, which Ethereum tooling relies on to find the revert data.
+const EEthExecutionReverted = 3
+
+// maxEthErrorsRequest is the size of the largest request whose response is
+// checked for execution reverted errors by EthErrorsHandler.
+const maxEthErrorsRequest = 10 << 20
+
+// EthErrorsHandler returns the ErrExecutionReverted errors of the calls made
+// to the eth_ method aliases over HTTP in the format used by geth: code 3,
+// with the revert data in the error data field. The code of ErrExecutionReverted
+// can't be 3, which is ErrActorNotFound for the clients of the Filecoin API, so
+// calls made with the Filecoin method names, and over websockets, keep
+// receiving EExecutionReverted with the details in the error meta field.
+func EthErrorsHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxEthErrorsRequest+1))
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if err != nil || len(body) > maxEthErrorsRequest {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		methods, batch := ethCallMethods(body)
+		eth := false
+		for _, m := range methods {
+			eth = eth || strings.HasPrefix(m, "eth_")
+		}
+		if !eth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		bw := &bufferedWriter{ResponseWriter: w}
+		next.ServeHTTP(bw, r)
+
+		out := bw.buf.Bytes()
+		if rewritten, ok := rewriteEthErrors(out, methods, batch); ok {
+			out = rewritten
+		}
+		if bw.status != 0 {
+			w.WriteHeader(bw.status)
+		}
+		_, _ = w.Write(out)
+	})
+}
+
+// ethCallMethods returns the methods called by a request, which can be a batch
+// of calls.
+func ethCallMethods(body []byte) ([]string, bool) {
+	type call struct {
+		Method string `json:"method"`
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []call
+		if err := json.Unmarshal(body, &calls); err != nil {
+			return nil, true
+		}
+		methods := make([]string, len(calls))
+		for i, c := range calls {
+			methods[i] = c.Method
+		}
+		return methods, true
+	}
+
+	var c call
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, false
+	}
+	return []string{c.Method}, false
+}
+
+// rewriteEthErrors rewrites the ErrExecutionReverted errors in the responses
+// to eth_ calls. The responses to a batch are in the order of its calls.
+func rewriteEthErrors(body []byte, methods []string, batch bool) ([]byte, bool) {
+	var resps []map[string]json.RawMessage
+	if batch {
+		if err := json.Unmarshal(body, &resps); err != nil || len(resps) != len(methods) {
+			return nil, false
+		}
+	} else {
+		var resp map[string]json.RawMessage
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, false
+		}
+		resps = append(resps, resp)
+	}
+
+	changed := false
+	for i, resp := range resps {
+		if !strings.HasPrefix(methods[i], "eth_") || resp["error"] == nil {
+			continue
+		}
+
+		var e struct {
+			Code int                  `json:"code"`
+			Meta ErrExecutionReverted `json:"meta"`
+		}
+		if err := json.Unmarshal(resp["error"], &e); err != nil || e.Code != EExecutionReverted {
+			continue
+		}
+
+		msg := "execution reverted"
+		if e.Meta.Reason != "" {
+			msg += ": " + e.Meta.Reason
+		}
+		ethErr, err := json.Marshal(struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+			Data    string `json:"data"`
+		}{EEthExecutionReverted, msg, e.Meta.Data})
+		if err != nil {
+			return nil, false
+		}
+		resp["error"] = ethErr
+		changed = true
+	}
+	if !changed {
+		return nil, false
+	}
+
+	var out []byte
+	var err error
+	if batch {
+		out, err = json.Marshal(resps)
+	} else {
+		out, err = json.Marshal(resps[0])
+	}
+	if err != nil {
+		return nil, false
+	}
+	return append(out, '\n'), true
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// bufferedWriter holds the response, for it to be rewritten before it's sent.
+type bufferedWriter struct {
+	http.ResponseWriter
+	status int
+	buf    bytes.Buffer
+}
+
+func (w *bufferedWriter) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+func (w *bufferedWriter) Write(b []byte) (int, error) {
+	return w.buf.Write(b)
+}
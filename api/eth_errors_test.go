@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-jsonrpc"
+)
+
+type revertingHandler struct{}
+
+func (revertingHandler) EthCall(ctx context.Context) (string, error) {
+	return "", &ErrExecutionReverted{Message: "message execution failed: exit 33", Reason: "ERC20: insufficient allowance", Data: "0x08c379a0"}
+}
+
+func (revertingHandler) ChainHead(ctx context.Context) (string, error) {
+	return "head", nil
+}
+
+func TestEthErrorsHandler(t *testing.T) {
+	rpcServer := jsonrpc.NewServer(jsonrpc.WithServerErrors(RPCErrors))
+	rpcServer.Register("Filecoin", revertingHandler{})
+	rpcServer.AliasMethod("eth_call", "Filecoin.EthCall")
+
+	srv := httptest.NewServer(EthErrorsHandler(rpcServer))
+	defer srv.Close()
+
+	type rpcError struct {
+		Code    int                  `json:"code"`
+		Message string               `json:"message"`
+		Data    string               `json:"data"`
+		Meta    ErrExecutionReverted `json:"meta"`
+	}
+	type response struct {
+		Result string    `json:"result"`
+		Error  *rpcError `json:"error"`
+	}
+	post := func(body string, out interface{}) {
+		resp, err := http.Post(srv.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(out))
+	}
+
+	// eth_ aliases get the format of geth
+	var resp response
+	post(`{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]}`, &resp)
+	require.Equal(t, &rpcError{Code: EEthExecutionReverted, Message: "execution reverted: ERC20: insufficient allowance", Data: "0x08c379a0"}, resp.Error)
+
+	// the Filecoin methods keep the meta field
+	resp = response{}
+	post(`{"jsonrpc":"2.0","id":1,"method":"Filecoin.EthCall","params":[]}`, &resp)
+	require.Equal(t, EExecutionReverted, resp.Error.Code)
+	require.Equal(t, "0x08c379a0", resp.Error.Meta.Data)
+	require.Empty(t, resp.Error.Data)
+
+	// only the eth_ calls of a batch are rewritten
+	var batch []response
+	post(`[{"jsonrpc":"2.0","id":1,"method":"eth_call","params":[]},{"jsonrpc":"2.0","id":2,"method":"Filecoin.EthCall","params":[]},{"jsonrpc":"2.0","id":3,"method":"Filecoin.ChainHead","params":[]}]`, &batch)
+	require.Len(t, batch, 3)
+	require.Equal(t, EEthExecutionReverted, batch[0].Error.Code)
+	require.Equal(t, "0x08c379a0", batch[0].Error.Data)
+	require.Equal(t, EExecutionReverted, batch[1].Error.Code)
+	require.Equal(t, "head", batch[2].Result)
+}
@@ -6,7 +6,7 @@ package mocks
 
 import (
 	context "context"
-	json "encoding/json"
+	"encoding/json"
 	reflect "reflect"
 	time "time"
 
@@ -67,6 +67,81 @@ func (m *MockFullNode) EXPECT() *MockFullNodeMockRecorder {
 	return m.recorder
 }
 
+// ActorCreate mocks base method.
+func (m *MockFullNode) ActorCreate(arg0 context.Context, arg1 address.Address, arg2 cid.Cid, arg3 []byte, arg4 big.Int) (*api.MessagePrototype, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActorCreate", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(*api.MessagePrototype)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActorCreate indicates an expected call of ActorCreate.
+func (mr *MockFullNodeMockRecorder) ActorCreate(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActorCreate", reflect.TypeOf((*MockFullNode)(nil).ActorCreate), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ActorEstimateInstall mocks base method.
+func (m *MockFullNode) ActorEstimateInstall(arg0 context.Context, arg1 address.Address, arg2 []byte) (*api.ActorInstallEstimate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActorEstimateInstall", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.ActorInstallEstimate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActorEstimateInstall indicates an expected call of ActorEstimateInstall.
+func (mr *MockFullNodeMockRecorder) ActorEstimateInstall(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActorEstimateInstall", reflect.TypeOf((*MockFullNode)(nil).ActorEstimateInstall), arg0, arg1, arg2)
+}
+
+// ActorInstall mocks base method.
+func (m *MockFullNode) ActorInstall(arg0 context.Context, arg1 address.Address, arg2 []byte) (*api.MessagePrototype, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActorInstall", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.MessagePrototype)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActorInstall indicates an expected call of ActorInstall.
+func (mr *MockFullNodeMockRecorder) ActorInstall(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActorInstall", reflect.TypeOf((*MockFullNode)(nil).ActorInstall), arg0, arg1, arg2)
+}
+
+// ActorValidateCode mocks base method.
+func (m *MockFullNode) ActorValidateCode(arg0 context.Context, arg1 []byte) (cid.Cid, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ActorValidateCode", arg0, arg1)
+	ret0, _ := ret[0].(cid.Cid)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ActorValidateCode indicates an expected call of ActorValidateCode.
+func (mr *MockFullNodeMockRecorder) ActorValidateCode(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ActorValidateCode", reflect.TypeOf((*MockFullNode)(nil).ActorValidateCode), arg0, arg1)
+}
+
+// AdvanceEpochs mocks base method.
+func (m *MockFullNode) AdvanceEpochs(arg0 context.Context, arg1 abi.ChainEpoch) (*types.TipSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdvanceEpochs", arg0, arg1)
+	ret0, _ := ret[0].(*types.TipSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdvanceEpochs indicates an expected call of AdvanceEpochs.
+func (mr *MockFullNodeMockRecorder) AdvanceEpochs(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdvanceEpochs", reflect.TypeOf((*MockFullNode)(nil).AdvanceEpochs), arg0, arg1)
+}
+
 // AuthNew mocks base method.
 func (m *MockFullNode) AuthNew(arg0 context.Context, arg1 []auth.Permission) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -97,6 +172,36 @@ func (mr *MockFullNodeMockRecorder) AuthVerify(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuthVerify", reflect.TypeOf((*MockFullNode)(nil).AuthVerify), arg0, arg1)
 }
 
+// BackupCreate mocks base method.
+func (m *MockFullNode) BackupCreate(arg0 context.Context, arg1 string, arg2 api.BackupOptions) (*api.BackupInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BackupCreate", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.BackupInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BackupCreate indicates an expected call of BackupCreate.
+func (mr *MockFullNodeMockRecorder) BackupCreate(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BackupCreate", reflect.TypeOf((*MockFullNode)(nil).BackupCreate), arg0, arg1, arg2)
+}
+
+// BeaconStatus mocks base method.
+func (m *MockFullNode) BeaconStatus(arg0 context.Context) ([]api.BeaconStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BeaconStatus", arg0)
+	ret0, _ := ret[0].([]api.BeaconStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BeaconStatus indicates an expected call of BeaconStatus.
+func (mr *MockFullNodeMockRecorder) BeaconStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BeaconStatus", reflect.TypeOf((*MockFullNode)(nil).BeaconStatus), arg0)
+}
+
 // ChainBlockstoreInfo mocks base method.
 func (m *MockFullNode) ChainBlockstoreInfo(arg0 context.Context) (map[string]interface{}, error) {
 	m.ctrl.T.Helper()
@@ -155,6 +260,21 @@ func (mr *MockFullNodeMockRecorder) ChainExport(arg0, arg1, arg2, arg3 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExport", reflect.TypeOf((*MockFullNode)(nil).ChainExport), arg0, arg1, arg2, arg3)
 }
 
+// ChainExportObj mocks base method.
+func (m *MockFullNode) ChainExportObj(arg0 context.Context, arg1 string, arg2 api.DagWalkOpts) (<-chan []byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainExportObj", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan []byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainExportObj indicates an expected call of ChainExportObj.
+func (mr *MockFullNodeMockRecorder) ChainExportObj(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExportObj", reflect.TypeOf((*MockFullNode)(nil).ChainExportObj), arg0, arg1, arg2)
+}
+
 // ChainExportRangeInternal mocks base method.
 func (m *MockFullNode) ChainExportRangeInternal(arg0 context.Context, arg1, arg2 types.TipSetKey, arg3 api.ChainExportConfig) error {
 	m.ctrl.T.Helper()
@@ -169,6 +289,51 @@ func (mr *MockFullNodeMockRecorder) ChainExportRangeInternal(arg0, arg1, arg2, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExportRangeInternal", reflect.TypeOf((*MockFullNode)(nil).ChainExportRangeInternal), arg0, arg1, arg2, arg3)
 }
 
+// ChainExportStream mocks base method.
+func (m *MockFullNode) ChainExportStream(arg0 context.Context, arg1 types.TipSetKey, arg2 api.ChainExportStreamParams) (<-chan api.ChainExportChunk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainExportStream", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan api.ChainExportChunk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainExportStream indicates an expected call of ChainExportStream.
+func (mr *MockFullNodeMockRecorder) ChainExportStream(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainExportStream", reflect.TypeOf((*MockFullNode)(nil).ChainExportStream), arg0, arg1, arg2)
+}
+
+// ChainFindObjPath mocks base method.
+func (m *MockFullNode) ChainFindObjPath(arg0 context.Context, arg1, arg2 cid.Cid, arg3 api.DagWalkOpts) ([]api.DagPathStep, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainFindObjPath", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]api.DagPathStep)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainFindObjPath indicates an expected call of ChainFindObjPath.
+func (mr *MockFullNodeMockRecorder) ChainFindObjPath(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainFindObjPath", reflect.TypeOf((*MockFullNode)(nil).ChainFindObjPath), arg0, arg1, arg2, arg3)
+}
+
+// ChainFork mocks base method.
+func (m *MockFullNode) ChainFork(arg0 context.Context, arg1 types.TipSetKey) (*types.TipSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainFork", arg0, arg1)
+	ret0, _ := ret[0].(*types.TipSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainFork indicates an expected call of ChainFork.
+func (mr *MockFullNodeMockRecorder) ChainFork(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainFork", reflect.TypeOf((*MockFullNode)(nil).ChainFork), arg0, arg1)
+}
+
 // ChainGetBlock mocks base method.
 func (m *MockFullNode) ChainGetBlock(arg0 context.Context, arg1 cid.Cid) (*types.BlockHeader, error) {
 	m.ctrl.T.Helper()
@@ -364,6 +529,21 @@ func (mr *MockFullNodeMockRecorder) ChainGetTipSetByHeight(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainGetTipSetByHeight", reflect.TypeOf((*MockFullNode)(nil).ChainGetTipSetByHeight), arg0, arg1, arg2)
 }
 
+// ChainGetTipSetProof mocks base method.
+func (m *MockFullNode) ChainGetTipSetProof(arg0 context.Context, arg1 types.TipSetKey) (*api.TipSetProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainGetTipSetProof", arg0, arg1)
+	ret0, _ := ret[0].(*api.TipSetProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainGetTipSetProof indicates an expected call of ChainGetTipSetProof.
+func (mr *MockFullNodeMockRecorder) ChainGetTipSetProof(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainGetTipSetProof", reflect.TypeOf((*MockFullNode)(nil).ChainGetTipSetProof), arg0, arg1)
+}
+
 // ChainHasObj mocks base method.
 func (m *MockFullNode) ChainHasObj(arg0 context.Context, arg1 cid.Cid) (bool, error) {
 	m.ctrl.T.Helper()
@@ -408,6 +588,21 @@ func (mr *MockFullNodeMockRecorder) ChainHotGC(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainHotGC", reflect.TypeOf((*MockFullNode)(nil).ChainHotGC), arg0, arg1)
 }
 
+// ChainIndexQuery mocks base method.
+func (m *MockFullNode) ChainIndexQuery(arg0 context.Context, arg1, arg2 string) (*api.IndexQueryResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainIndexQuery", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.IndexQueryResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainIndexQuery indicates an expected call of ChainIndexQuery.
+func (mr *MockFullNodeMockRecorder) ChainIndexQuery(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainIndexQuery", reflect.TypeOf((*MockFullNode)(nil).ChainIndexQuery), arg0, arg1, arg2)
+}
+
 // ChainNotify mocks base method.
 func (m *MockFullNode) ChainNotify(arg0 context.Context) (<-chan []*api.HeadChange, error) {
 	m.ctrl.T.Helper()
@@ -423,6 +618,36 @@ func (mr *MockFullNodeMockRecorder) ChainNotify(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainNotify", reflect.TypeOf((*MockFullNode)(nil).ChainNotify), arg0)
 }
 
+// ChainNotifyAtConfidence mocks base method.
+func (m *MockFullNode) ChainNotifyAtConfidence(arg0 context.Context, arg1 abi.ChainEpoch) (<-chan []*api.HeadChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainNotifyAtConfidence", arg0, arg1)
+	ret0, _ := ret[0].(<-chan []*api.HeadChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainNotifyAtConfidence indicates an expected call of ChainNotifyAtConfidence.
+func (mr *MockFullNodeMockRecorder) ChainNotifyAtConfidence(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainNotifyAtConfidence", reflect.TypeOf((*MockFullNode)(nil).ChainNotifyAtConfidence), arg0, arg1)
+}
+
+// ChainNotifyResume mocks base method.
+func (m *MockFullNode) ChainNotifyResume(arg0 context.Context, arg1 string) (<-chan api.HeadChangeBatch, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainNotifyResume", arg0, arg1)
+	ret0, _ := ret[0].(<-chan api.HeadChangeBatch)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainNotifyResume indicates an expected call of ChainNotifyResume.
+func (mr *MockFullNodeMockRecorder) ChainNotifyResume(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainNotifyResume", reflect.TypeOf((*MockFullNode)(nil).ChainNotifyResume), arg0, arg1)
+}
+
 // ChainPrune mocks base method.
 func (m *MockFullNode) ChainPrune(arg0 context.Context, arg1 api.PruneOpts) error {
 	m.ctrl.T.Helper()
@@ -510,6 +735,21 @@ func (mr *MockFullNodeMockRecorder) ChainTipSetWeight(arg0, arg1 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainTipSetWeight", reflect.TypeOf((*MockFullNode)(nil).ChainTipSetWeight), arg0, arg1)
 }
 
+// ChainWalkObj mocks base method.
+func (m *MockFullNode) ChainWalkObj(arg0 context.Context, arg1 string, arg2 api.DagWalkOpts) (*api.DagWalk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainWalkObj", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.DagWalk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainWalkObj indicates an expected call of ChainWalkObj.
+func (mr *MockFullNodeMockRecorder) ChainWalkObj(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainWalkObj", reflect.TypeOf((*MockFullNode)(nil).ChainWalkObj), arg0, arg1, arg2)
+}
+
 // ClientCalcCommP mocks base method.
 func (m *MockFullNode) ClientCalcCommP(arg0 context.Context, arg1 string) (*api.CommPRet, error) {
 	m.ctrl.T.Helper()
@@ -996,6 +1236,21 @@ func (mr *MockFullNodeMockRecorder) EthAddressToFilecoinAddress(arg0, arg1 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthAddressToFilecoinAddress", reflect.TypeOf((*MockFullNode)(nil).EthAddressToFilecoinAddress), arg0, arg1)
 }
 
+// EthBatchStateRead mocks base method.
+func (m *MockFullNode) EthBatchStateRead(arg0 context.Context, arg1 []api.EthStateRead, arg2 string) ([]api.EthStateReadResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthBatchStateRead", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]api.EthStateReadResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthBatchStateRead indicates an expected call of EthBatchStateRead.
+func (mr *MockFullNodeMockRecorder) EthBatchStateRead(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthBatchStateRead", reflect.TypeOf((*MockFullNode)(nil).EthBatchStateRead), arg0, arg1, arg2)
+}
+
 // EthBlockNumber mocks base method.
 func (m *MockFullNode) EthBlockNumber(arg0 context.Context) (ethtypes.EthUint64, error) {
 	m.ctrl.T.Helper()
@@ -1026,6 +1281,21 @@ func (mr *MockFullNodeMockRecorder) EthCall(arg0, arg1, arg2 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthCall", reflect.TypeOf((*MockFullNode)(nil).EthCall), arg0, arg1, arg2)
 }
 
+// EthCallBundle mocks base method.
+func (m *MockFullNode) EthCallBundle(arg0 context.Context, arg1 []ethtypes.EthCall, arg2 string, arg3 []api.EthAccountOverride) (*api.EthBundleResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthCallBundle", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*api.EthBundleResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthCallBundle indicates an expected call of EthCallBundle.
+func (mr *MockFullNodeMockRecorder) EthCallBundle(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthCallBundle", reflect.TypeOf((*MockFullNode)(nil).EthCallBundle), arg0, arg1, arg2, arg3)
+}
+
 // EthChainId mocks base method.
 func (m *MockFullNode) EthChainId(arg0 context.Context) (ethtypes.EthUint64, error) {
 	m.ctrl.T.Helper()
@@ -1041,6 +1311,21 @@ func (mr *MockFullNodeMockRecorder) EthChainId(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthChainId", reflect.TypeOf((*MockFullNode)(nil).EthChainId), arg0)
 }
 
+// EthDecodeRawTransaction mocks base method.
+func (m *MockFullNode) EthDecodeRawTransaction(arg0 context.Context, arg1 ethtypes.EthBytes) (*api.EthRawTxDecoding, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthDecodeRawTransaction", arg0, arg1)
+	ret0, _ := ret[0].(*api.EthRawTxDecoding)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthDecodeRawTransaction indicates an expected call of EthDecodeRawTransaction.
+func (mr *MockFullNodeMockRecorder) EthDecodeRawTransaction(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthDecodeRawTransaction", reflect.TypeOf((*MockFullNode)(nil).EthDecodeRawTransaction), arg0, arg1)
+}
+
 // EthEstimateGas mocks base method.
 func (m *MockFullNode) EthEstimateGas(arg0 context.Context, arg1 ethtypes.EthCall) (ethtypes.EthUint64, error) {
 	m.ctrl.T.Helper()
@@ -1221,6 +1506,36 @@ func (mr *MockFullNodeMockRecorder) EthGetLogs(arg0, arg1 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthGetLogs", reflect.TypeOf((*MockFullNode)(nil).EthGetLogs), arg0, arg1)
 }
 
+// EthGetLogsPage mocks base method.
+func (m *MockFullNode) EthGetLogsPage(arg0 context.Context, arg1 *ethtypes.EthFilterSpec, arg2 string) (*api.EthLogsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthGetLogsPage", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.EthLogsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthGetLogsPage indicates an expected call of EthGetLogsPage.
+func (mr *MockFullNodeMockRecorder) EthGetLogsPage(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthGetLogsPage", reflect.TypeOf((*MockFullNode)(nil).EthGetLogsPage), arg0, arg1, arg2)
+}
+
+// EthGetLogsStream mocks base method.
+func (m *MockFullNode) EthGetLogsStream(arg0 context.Context, arg1 *ethtypes.EthFilterSpec) (<-chan api.EthLogsPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthGetLogsStream", arg0, arg1)
+	ret0, _ := ret[0].(<-chan api.EthLogsPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthGetLogsStream indicates an expected call of EthGetLogsStream.
+func (mr *MockFullNodeMockRecorder) EthGetLogsStream(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthGetLogsStream", reflect.TypeOf((*MockFullNode)(nil).EthGetLogsStream), arg0, arg1)
+}
+
 // EthGetMessageCidByTransactionHash mocks base method.
 func (m *MockFullNode) EthGetMessageCidByTransactionHash(arg0 context.Context, arg1 *ethtypes.EthHash) (*cid.Cid, error) {
 	m.ctrl.T.Helper()
@@ -1371,6 +1686,21 @@ func (mr *MockFullNodeMockRecorder) EthGetTransactionReceiptLimited(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthGetTransactionReceiptLimited", reflect.TypeOf((*MockFullNode)(nil).EthGetTransactionReceiptLimited), arg0, arg1, arg2)
 }
 
+// EthGetTransactionReceiptProof mocks base method.
+func (m *MockFullNode) EthGetTransactionReceiptProof(arg0 context.Context, arg1 ethtypes.EthHash, arg2 string) (*api.EthReceiptProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthGetTransactionReceiptProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.EthReceiptProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthGetTransactionReceiptProof indicates an expected call of EthGetTransactionReceiptProof.
+func (mr *MockFullNodeMockRecorder) EthGetTransactionReceiptProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthGetTransactionReceiptProof", reflect.TypeOf((*MockFullNode)(nil).EthGetTransactionReceiptProof), arg0, arg1, arg2)
+}
+
 // EthMaxPriorityFeePerGas mocks base method.
 func (m *MockFullNode) EthMaxPriorityFeePerGas(arg0 context.Context) (ethtypes.EthBigInt, error) {
 	m.ctrl.T.Helper()
@@ -1461,6 +1791,51 @@ func (mr *MockFullNodeMockRecorder) EthSendRawTransaction(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSendRawTransaction", reflect.TypeOf((*MockFullNode)(nil).EthSendRawTransaction), arg0, arg1)
 }
 
+// EthSendSponsoredCall mocks base method.
+func (m *MockFullNode) EthSendSponsoredCall(arg0 context.Context, arg1 api.EthSponsoredCall) (*api.EthSponsoredCallResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSendSponsoredCall", arg0, arg1)
+	ret0, _ := ret[0].(*api.EthSponsoredCallResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSendSponsoredCall indicates an expected call of EthSendSponsoredCall.
+func (mr *MockFullNodeMockRecorder) EthSendSponsoredCall(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSendSponsoredCall", reflect.TypeOf((*MockFullNode)(nil).EthSendSponsoredCall), arg0, arg1)
+}
+
+// EthSignTypedData mocks base method.
+func (m *MockFullNode) EthSignTypedData(arg0 context.Context, arg1 ethtypes.EthAddress, arg2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSignTypedData", arg0, arg1, arg2)
+	ret0, _ := ret[0].(ethtypes.EthBytes)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSignTypedData indicates an expected call of EthSignTypedData.
+func (mr *MockFullNodeMockRecorder) EthSignTypedData(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSignTypedData", reflect.TypeOf((*MockFullNode)(nil).EthSignTypedData), arg0, arg1, arg2)
+}
+
+// EthSponsoredCallTypedData mocks base method.
+func (m *MockFullNode) EthSponsoredCallTypedData(arg0 context.Context, arg1 api.EthSponsoredCall) (*ethtypes.EthTypedData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSponsoredCallTypedData", arg0, arg1)
+	ret0, _ := ret[0].(*ethtypes.EthTypedData)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSponsoredCallTypedData indicates an expected call of EthSponsoredCallTypedData.
+func (mr *MockFullNodeMockRecorder) EthSponsoredCallTypedData(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSponsoredCallTypedData", reflect.TypeOf((*MockFullNode)(nil).EthSponsoredCallTypedData), arg0, arg1)
+}
+
 // EthSubscribe mocks base method.
 func (m *MockFullNode) EthSubscribe(arg0 context.Context, arg1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
 	m.ctrl.T.Helper()
@@ -1476,6 +1851,21 @@ func (mr *MockFullNodeMockRecorder) EthSubscribe(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSubscribe", reflect.TypeOf((*MockFullNode)(nil).EthSubscribe), arg0, arg1)
 }
 
+// EthSubscribeStorageSlots mocks base method.
+func (m *MockFullNode) EthSubscribeStorageSlots(arg0 context.Context, arg1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthSubscribeStorageSlots", arg0, arg1)
+	ret0, _ := ret[0].(ethtypes.EthSubscriptionID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthSubscribeStorageSlots indicates an expected call of EthSubscribeStorageSlots.
+func (mr *MockFullNodeMockRecorder) EthSubscribeStorageSlots(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSubscribeStorageSlots", reflect.TypeOf((*MockFullNode)(nil).EthSubscribeStorageSlots), arg0, arg1)
+}
+
 // EthSyncing mocks base method.
 func (m *MockFullNode) EthSyncing(arg0 context.Context) (ethtypes.EthSyncingResult, error) {
 	m.ctrl.T.Helper()
@@ -1491,6 +1881,66 @@ func (mr *MockFullNodeMockRecorder) EthSyncing(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthSyncing", reflect.TypeOf((*MockFullNode)(nil).EthSyncing), arg0)
 }
 
+// EthTraceTransactionGasProfile mocks base method.
+func (m *MockFullNode) EthTraceTransactionGasProfile(arg0 context.Context, arg1 ethtypes.EthHash) (*api.EthGasProfile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthTraceTransactionGasProfile", arg0, arg1)
+	ret0, _ := ret[0].(*api.EthGasProfile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthTraceTransactionGasProfile indicates an expected call of EthTraceTransactionGasProfile.
+func (mr *MockFullNodeMockRecorder) EthTraceTransactionGasProfile(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthTraceTransactionGasProfile", reflect.TypeOf((*MockFullNode)(nil).EthTraceTransactionGasProfile), arg0, arg1)
+}
+
+// EthTxPoolContent mocks base method.
+func (m *MockFullNode) EthTxPoolContent(arg0 context.Context) (api.EthTxPoolContent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthTxPoolContent", arg0)
+	ret0, _ := ret[0].(api.EthTxPoolContent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthTxPoolContent indicates an expected call of EthTxPoolContent.
+func (mr *MockFullNodeMockRecorder) EthTxPoolContent(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthTxPoolContent", reflect.TypeOf((*MockFullNode)(nil).EthTxPoolContent), arg0)
+}
+
+// EthTxPoolInspect mocks base method.
+func (m *MockFullNode) EthTxPoolInspect(arg0 context.Context) (api.EthTxPoolInspect, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthTxPoolInspect", arg0)
+	ret0, _ := ret[0].(api.EthTxPoolInspect)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthTxPoolInspect indicates an expected call of EthTxPoolInspect.
+func (mr *MockFullNodeMockRecorder) EthTxPoolInspect(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthTxPoolInspect", reflect.TypeOf((*MockFullNode)(nil).EthTxPoolInspect), arg0)
+}
+
+// EthTxPoolStatus mocks base method.
+func (m *MockFullNode) EthTxPoolStatus(arg0 context.Context) (api.EthTxPoolStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EthTxPoolStatus", arg0)
+	ret0, _ := ret[0].(api.EthTxPoolStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EthTxPoolStatus indicates an expected call of EthTxPoolStatus.
+func (mr *MockFullNodeMockRecorder) EthTxPoolStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EthTxPoolStatus", reflect.TypeOf((*MockFullNode)(nil).EthTxPoolStatus), arg0)
+}
+
 // EthUninstallFilter mocks base method.
 func (m *MockFullNode) EthUninstallFilter(arg0 context.Context, arg1 ethtypes.EthFilterID) (bool, error) {
 	m.ctrl.T.Helper()
@@ -1596,6 +2046,21 @@ func (mr *MockFullNodeMockRecorder) GasEstimateMessageGas(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasEstimateMessageGas", reflect.TypeOf((*MockFullNode)(nil).GasEstimateMessageGas), arg0, arg1, arg2, arg3)
 }
 
+// GasForecastBaseFee mocks base method.
+func (m *MockFullNode) GasForecastBaseFee(arg0 context.Context, arg1 int, arg2 types.TipSetKey) (*api.BaseFeeForecast, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GasForecastBaseFee", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.BaseFeeForecast)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GasForecastBaseFee indicates an expected call of GasForecastBaseFee.
+func (mr *MockFullNodeMockRecorder) GasForecastBaseFee(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GasForecastBaseFee", reflect.TypeOf((*MockFullNode)(nil).GasForecastBaseFee), arg0, arg1, arg2)
+}
+
 // ID mocks base method.
 func (m *MockFullNode) ID(arg0 context.Context) (peer.ID, error) {
 	m.ctrl.T.Helper()
@@ -1641,6 +2106,20 @@ func (mr *MockFullNodeMockRecorder) LogList(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogList", reflect.TypeOf((*MockFullNode)(nil).LogList), arg0)
 }
 
+// LogResetLevels mocks base method.
+func (m *MockFullNode) LogResetLevels(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LogResetLevels", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// LogResetLevels indicates an expected call of LogResetLevels.
+func (mr *MockFullNodeMockRecorder) LogResetLevels(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LogResetLevels", reflect.TypeOf((*MockFullNode)(nil).LogResetLevels), arg0)
+}
+
 // LogSetLevel mocks base method.
 func (m *MockFullNode) LogSetLevel(arg0 context.Context, arg1, arg2 string) error {
 	m.ctrl.T.Helper()
@@ -1729,6 +2208,21 @@ func (mr *MockFullNodeMockRecorder) MarketWithdraw(arg0, arg1, arg2, arg3 interf
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarketWithdraw", reflect.TypeOf((*MockFullNode)(nil).MarketWithdraw), arg0, arg1, arg2, arg3)
 }
 
+// MineOne mocks base method.
+func (m *MockFullNode) MineOne(arg0 context.Context) (*types.TipSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MineOne", arg0)
+	ret0, _ := ret[0].(*types.TipSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MineOne indicates an expected call of MineOne.
+func (mr *MockFullNodeMockRecorder) MineOne(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MineOne", reflect.TypeOf((*MockFullNode)(nil).MineOne), arg0)
+}
+
 // MinerCreateBlock mocks base method.
 func (m *MockFullNode) MinerCreateBlock(arg0 context.Context, arg1 *api.BlockTemplate) (*types.BlockMsg, error) {
 	m.ctrl.T.Helper()
@@ -1744,6 +2238,21 @@ func (mr *MockFullNodeMockRecorder) MinerCreateBlock(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinerCreateBlock", reflect.TypeOf((*MockFullNode)(nil).MinerCreateBlock), arg0, arg1)
 }
 
+// MinerCreateBlockDryRun mocks base method.
+func (m *MockFullNode) MinerCreateBlockDryRun(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.MinerBlockDryRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MinerCreateBlockDryRun", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.MinerBlockDryRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MinerCreateBlockDryRun indicates an expected call of MinerCreateBlockDryRun.
+func (mr *MockFullNodeMockRecorder) MinerCreateBlockDryRun(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MinerCreateBlockDryRun", reflect.TypeOf((*MockFullNode)(nil).MinerCreateBlockDryRun), arg0, arg1, arg2)
+}
+
 // MinerGetBaseInfo mocks base method.
 func (m *MockFullNode) MinerGetBaseInfo(arg0 context.Context, arg1 address.Address, arg2 abi.ChainEpoch, arg3 types.TipSetKey) (*api.MiningBaseInfo, error) {
 	m.ctrl.T.Helper()
@@ -1804,6 +2313,20 @@ func (mr *MockFullNodeMockRecorder) MpoolBatchPushUntrusted(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolBatchPushUntrusted", reflect.TypeOf((*MockFullNode)(nil).MpoolBatchPushUntrusted), arg0, arg1)
 }
 
+// MpoolCancelScheduled mocks base method.
+func (m *MockFullNode) MpoolCancelScheduled(arg0 context.Context, arg1 uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolCancelScheduled", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MpoolCancelScheduled indicates an expected call of MpoolCancelScheduled.
+func (mr *MockFullNodeMockRecorder) MpoolCancelScheduled(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolCancelScheduled", reflect.TypeOf((*MockFullNode)(nil).MpoolCancelScheduled), arg0, arg1)
+}
+
 // MpoolCheckMessages mocks base method.
 func (m *MockFullNode) MpoolCheckMessages(arg0 context.Context, arg1 []*api.MessagePrototype) ([][]api.MessageCheckStatus, error) {
 	m.ctrl.T.Helper()
@@ -1893,6 +2416,21 @@ func (mr *MockFullNodeMockRecorder) MpoolGetNonce(arg0, arg1 interface{}) *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolGetNonce", reflect.TypeOf((*MockFullNode)(nil).MpoolGetNonce), arg0, arg1)
 }
 
+// MpoolListScheduled mocks base method.
+func (m *MockFullNode) MpoolListScheduled(arg0 context.Context) ([]*api.ScheduledMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolListScheduled", arg0)
+	ret0, _ := ret[0].([]*api.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolListScheduled indicates an expected call of MpoolListScheduled.
+func (mr *MockFullNodeMockRecorder) MpoolListScheduled(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolListScheduled", reflect.TypeOf((*MockFullNode)(nil).MpoolListScheduled), arg0)
+}
+
 // MpoolPending mocks base method.
 func (m *MockFullNode) MpoolPending(arg0 context.Context, arg1 types.TipSetKey) ([]*types.SignedMessage, error) {
 	m.ctrl.T.Helper()
@@ -1908,6 +2446,21 @@ func (mr *MockFullNodeMockRecorder) MpoolPending(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolPending", reflect.TypeOf((*MockFullNode)(nil).MpoolPending), arg0, arg1)
 }
 
+// MpoolPredictInclusion mocks base method.
+func (m *MockFullNode) MpoolPredictInclusion(arg0 context.Context, arg1 api.InclusionSpec) (*api.InclusionPrediction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolPredictInclusion", arg0, arg1)
+	ret0, _ := ret[0].(*api.InclusionPrediction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolPredictInclusion indicates an expected call of MpoolPredictInclusion.
+func (mr *MockFullNodeMockRecorder) MpoolPredictInclusion(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolPredictInclusion", reflect.TypeOf((*MockFullNode)(nil).MpoolPredictInclusion), arg0, arg1)
+}
+
 // MpoolPush mocks base method.
 func (m *MockFullNode) MpoolPush(arg0 context.Context, arg1 *types.SignedMessage) (cid.Cid, error) {
 	m.ctrl.T.Helper()
@@ -1953,6 +2506,21 @@ func (mr *MockFullNodeMockRecorder) MpoolPushUntrusted(arg0, arg1 interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolPushUntrusted", reflect.TypeOf((*MockFullNode)(nil).MpoolPushUntrusted), arg0, arg1)
 }
 
+// MpoolScheduleMessage mocks base method.
+func (m *MockFullNode) MpoolScheduleMessage(arg0 context.Context, arg1 *types.Message, arg2 api.MessageSchedule, arg3 *api.MessageSendSpec) (*api.ScheduledMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolScheduleMessage", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*api.ScheduledMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolScheduleMessage indicates an expected call of MpoolScheduleMessage.
+func (mr *MockFullNodeMockRecorder) MpoolScheduleMessage(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolScheduleMessage", reflect.TypeOf((*MockFullNode)(nil).MpoolScheduleMessage), arg0, arg1, arg2, arg3)
+}
+
 // MpoolSelect mocks base method.
 func (m *MockFullNode) MpoolSelect(arg0 context.Context, arg1 types.TipSetKey, arg2 float64) ([]*types.SignedMessage, error) {
 	m.ctrl.T.Helper()
@@ -1982,6 +2550,35 @@ func (mr *MockFullNodeMockRecorder) MpoolSetConfig(arg0, arg1 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolSetConfig", reflect.TypeOf((*MockFullNode)(nil).MpoolSetConfig), arg0, arg1)
 }
 
+// MpoolSpendBudget mocks base method.
+func (m *MockFullNode) MpoolSpendBudget(arg0 context.Context) (*api.SpendBudget, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolSpendBudget", arg0)
+	ret0, _ := ret[0].(*api.SpendBudget)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MpoolSpendBudget indicates an expected call of MpoolSpendBudget.
+func (mr *MockFullNodeMockRecorder) MpoolSpendBudget(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolSpendBudget", reflect.TypeOf((*MockFullNode)(nil).MpoolSpendBudget), arg0)
+}
+
+// MpoolSpendBudgetOverride mocks base method.
+func (m *MockFullNode) MpoolSpendBudgetOverride(arg0 context.Context, arg1 big.Int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MpoolSpendBudgetOverride", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MpoolSpendBudgetOverride indicates an expected call of MpoolSpendBudgetOverride.
+func (mr *MockFullNodeMockRecorder) MpoolSpendBudgetOverride(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MpoolSpendBudgetOverride", reflect.TypeOf((*MockFullNode)(nil).MpoolSpendBudgetOverride), arg0, arg1)
+}
+
 // MpoolSub mocks base method.
 func (m *MockFullNode) MpoolSub(arg0 context.Context) (<-chan api.MpoolUpdate, error) {
 	m.ctrl.T.Helper()
@@ -2620,6 +3217,50 @@ func (mr *MockFullNodeMockRecorder) NetVersion(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NetVersion", reflect.TypeOf((*MockFullNode)(nil).NetVersion), arg0)
 }
 
+// NodeAPISpec mocks base method.
+func (m *MockFullNode) NodeAPISpec(arg0 context.Context) (*api.APISpec, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeAPISpec", arg0)
+	ret0, _ := ret[0].(*api.APISpec)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeAPISpec indicates an expected call of NodeAPISpec.
+func (mr *MockFullNodeMockRecorder) NodeAPISpec(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeAPISpec", reflect.TypeOf((*MockFullNode)(nil).NodeAPISpec), arg0)
+}
+
+// NodeMaintenanceOverride mocks base method.
+func (m *MockFullNode) NodeMaintenanceOverride(arg0 context.Context, arg1 *api.MaintenanceOverride) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeMaintenanceOverride", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// NodeMaintenanceOverride indicates an expected call of NodeMaintenanceOverride.
+func (mr *MockFullNodeMockRecorder) NodeMaintenanceOverride(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeMaintenanceOverride", reflect.TypeOf((*MockFullNode)(nil).NodeMaintenanceOverride), arg0, arg1)
+}
+
+// NodeMaintenanceStatus mocks base method.
+func (m *MockFullNode) NodeMaintenanceStatus(arg0 context.Context) (*api.MaintenanceStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NodeMaintenanceStatus", arg0)
+	ret0, _ := ret[0].(*api.MaintenanceStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NodeMaintenanceStatus indicates an expected call of NodeMaintenanceStatus.
+func (mr *MockFullNodeMockRecorder) NodeMaintenanceStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NodeMaintenanceStatus", reflect.TypeOf((*MockFullNode)(nil).NodeMaintenanceStatus), arg0)
+}
+
 // NodeStatus mocks base method.
 func (m *MockFullNode) NodeStatus(arg0 context.Context, arg1 bool) (api.NodeStatus, error) {
 	m.ctrl.T.Helper()
@@ -2919,6 +3560,21 @@ func (mr *MockFullNodeMockRecorder) RaftState(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RaftState", reflect.TypeOf((*MockFullNode)(nil).RaftState), arg0)
 }
 
+// RepoLockStatus mocks base method.
+func (m *MockFullNode) RepoLockStatus(arg0 context.Context) (api.RepoLockStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepoLockStatus", arg0)
+	ret0, _ := ret[0].(api.RepoLockStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepoLockStatus indicates an expected call of RepoLockStatus.
+func (mr *MockFullNodeMockRecorder) RepoLockStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepoLockStatus", reflect.TypeOf((*MockFullNode)(nil).RepoLockStatus), arg0)
+}
+
 // Session mocks base method.
 func (m *MockFullNode) Session(arg0 context.Context) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
@@ -3143,6 +3799,21 @@ func (mr *MockFullNodeMockRecorder) StateEncodeParams(arg0, arg1, arg2, arg3 int
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateEncodeParams", reflect.TypeOf((*MockFullNode)(nil).StateEncodeParams), arg0, arg1, arg2, arg3)
 }
 
+// StateGasStats mocks base method.
+func (m *MockFullNode) StateGasStats(arg0 context.Context, arg1 abi.ChainEpoch) (*api.GasStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateGasStats", arg0, arg1)
+	ret0, _ := ret[0].(*api.GasStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateGasStats indicates an expected call of StateGasStats.
+func (mr *MockFullNodeMockRecorder) StateGasStats(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGasStats", reflect.TypeOf((*MockFullNode)(nil).StateGasStats), arg0, arg1)
+}
+
 // StateGetActor mocks base method.
 func (m *MockFullNode) StateGetActor(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*types.ActorV5, error) {
 	m.ctrl.T.Helper()
@@ -3263,6 +3934,21 @@ func (mr *MockFullNodeMockRecorder) StateGetNetworkParams(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGetNetworkParams", reflect.TypeOf((*MockFullNode)(nil).StateGetNetworkParams), arg0)
 }
 
+// StateGetProof mocks base method.
+func (m *MockFullNode) StateGetProof(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.ActorProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateGetProof", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.ActorProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateGetProof indicates an expected call of StateGetProof.
+func (mr *MockFullNodeMockRecorder) StateGetProof(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGetProof", reflect.TypeOf((*MockFullNode)(nil).StateGetProof), arg0, arg1, arg2)
+}
+
 // StateGetRandomnessFromBeacon mocks base method.
 func (m *MockFullNode) StateGetRandomnessFromBeacon(arg0 context.Context, arg1 crypto.DomainSeparationTag, arg2 abi.ChainEpoch, arg3 []byte, arg4 types.TipSetKey) (abi.Randomness, error) {
 	m.ctrl.T.Helper()
@@ -3293,6 +3979,36 @@ func (mr *MockFullNodeMockRecorder) StateGetRandomnessFromTickets(arg0, arg1, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGetRandomnessFromTickets", reflect.TypeOf((*MockFullNode)(nil).StateGetRandomnessFromTickets), arg0, arg1, arg2, arg3, arg4)
 }
 
+// StateGetRandomnessWithProof mocks base method.
+func (m *MockFullNode) StateGetRandomnessWithProof(arg0 context.Context, arg1 api.RandomnessSource, arg2 crypto.DomainSeparationTag, arg3 abi.ChainEpoch, arg4 []byte, arg5 types.TipSetKey) (*api.RandomnessProof, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateGetRandomnessWithProof", arg0, arg1, arg2, arg3, arg4, arg5)
+	ret0, _ := ret[0].(*api.RandomnessProof)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateGetRandomnessWithProof indicates an expected call of StateGetRandomnessWithProof.
+func (mr *MockFullNodeMockRecorder) StateGetRandomnessWithProof(arg0, arg1, arg2, arg3, arg4, arg5 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateGetRandomnessWithProof", reflect.TypeOf((*MockFullNode)(nil).StateGetRandomnessWithProof), arg0, arg1, arg2, arg3, arg4, arg5)
+}
+
+// StateInstalledActors mocks base method.
+func (m *MockFullNode) StateInstalledActors(arg0 context.Context, arg1 types.TipSetKey) ([]cid.Cid, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateInstalledActors", arg0, arg1)
+	ret0, _ := ret[0].([]cid.Cid)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateInstalledActors indicates an expected call of StateInstalledActors.
+func (mr *MockFullNodeMockRecorder) StateInstalledActors(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateInstalledActors", reflect.TypeOf((*MockFullNode)(nil).StateInstalledActors), arg0, arg1)
+}
+
 // StateListActors mocks base method.
 func (m *MockFullNode) StateListActors(arg0 context.Context, arg1 types.TipSetKey) ([]address.Address, error) {
 	m.ctrl.T.Helper()
@@ -3308,6 +4024,21 @@ func (mr *MockFullNodeMockRecorder) StateListActors(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateListActors", reflect.TypeOf((*MockFullNode)(nil).StateListActors), arg0, arg1)
 }
 
+// StateListAddressMessages mocks base method.
+func (m *MockFullNode) StateListAddressMessages(arg0 context.Context, arg1 address.Address, arg2 *api.AddressMessagesQuery) (*api.AddressMessages, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateListAddressMessages", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.AddressMessages)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateListAddressMessages indicates an expected call of StateListAddressMessages.
+func (mr *MockFullNodeMockRecorder) StateListAddressMessages(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateListAddressMessages", reflect.TypeOf((*MockFullNode)(nil).StateListAddressMessages), arg0, arg1, arg2)
+}
+
 // StateListMessages mocks base method.
 func (m *MockFullNode) StateListMessages(arg0 context.Context, arg1 *api.MessageMatch, arg2 types.TipSetKey, arg3 abi.ChainEpoch) ([]cid.Cid, error) {
 	m.ctrl.T.Helper()
@@ -3473,6 +4204,36 @@ func (mr *MockFullNodeMockRecorder) StateMinerAvailableBalance(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerAvailableBalance", reflect.TypeOf((*MockFullNode)(nil).StateMinerAvailableBalance), arg0, arg1, arg2)
 }
 
+// StateMinerCheckBeneficiaryChange mocks base method.
+func (m *MockFullNode) StateMinerCheckBeneficiaryChange(arg0 context.Context, arg1 address.Address, arg2 miner.ChangeBeneficiaryParams, arg3 types.TipSetKey) (*api.AddressChangeCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateMinerCheckBeneficiaryChange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*api.AddressChangeCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateMinerCheckBeneficiaryChange indicates an expected call of StateMinerCheckBeneficiaryChange.
+func (mr *MockFullNodeMockRecorder) StateMinerCheckBeneficiaryChange(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerCheckBeneficiaryChange", reflect.TypeOf((*MockFullNode)(nil).StateMinerCheckBeneficiaryChange), arg0, arg1, arg2, arg3)
+}
+
+// StateMinerCheckOwnerChange mocks base method.
+func (m *MockFullNode) StateMinerCheckOwnerChange(arg0 context.Context, arg1, arg2 address.Address, arg3 types.TipSetKey) (*api.AddressChangeCheck, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateMinerCheckOwnerChange", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*api.AddressChangeCheck)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateMinerCheckOwnerChange indicates an expected call of StateMinerCheckOwnerChange.
+func (mr *MockFullNodeMockRecorder) StateMinerCheckOwnerChange(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerCheckOwnerChange", reflect.TypeOf((*MockFullNode)(nil).StateMinerCheckOwnerChange), arg0, arg1, arg2, arg3)
+}
+
 // StateMinerDeadlines mocks base method.
 func (m *MockFullNode) StateMinerDeadlines(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) ([]api.Deadline, error) {
 	m.ctrl.T.Helper()
@@ -3548,6 +4309,21 @@ func (mr *MockFullNodeMockRecorder) StateMinerPartitions(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerPartitions", reflect.TypeOf((*MockFullNode)(nil).StateMinerPartitions), arg0, arg1, arg2, arg3)
 }
 
+// StateMinerPendingChanges mocks base method.
+func (m *MockFullNode) StateMinerPendingChanges(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.MinerPendingChanges, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateMinerPendingChanges", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.MinerPendingChanges)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateMinerPendingChanges indicates an expected call of StateMinerPendingChanges.
+func (mr *MockFullNodeMockRecorder) StateMinerPendingChanges(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerPendingChanges", reflect.TypeOf((*MockFullNode)(nil).StateMinerPendingChanges), arg0, arg1, arg2)
+}
+
 // StateMinerPower mocks base method.
 func (m *MockFullNode) StateMinerPower(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.MinerPower, error) {
 	m.ctrl.T.Helper()
@@ -3593,6 +4369,21 @@ func (mr *MockFullNodeMockRecorder) StateMinerProvingDeadline(arg0, arg1, arg2 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerProvingDeadline", reflect.TypeOf((*MockFullNode)(nil).StateMinerProvingDeadline), arg0, arg1, arg2)
 }
 
+// StateMinerProvingSchedule mocks base method.
+func (m *MockFullNode) StateMinerProvingSchedule(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.ProvingSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateMinerProvingSchedule", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.ProvingSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateMinerProvingSchedule indicates an expected call of StateMinerProvingSchedule.
+func (mr *MockFullNodeMockRecorder) StateMinerProvingSchedule(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateMinerProvingSchedule", reflect.TypeOf((*MockFullNode)(nil).StateMinerProvingSchedule), arg0, arg1, arg2)
+}
+
 // StateMinerRecoveries mocks base method.
 func (m *MockFullNode) StateMinerRecoveries(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (bitfield.BitField, error) {
 	m.ctrl.T.Helper()
@@ -3683,6 +4474,36 @@ func (mr *MockFullNodeMockRecorder) StateNetworkVersion(arg0, arg1 interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateNetworkVersion", reflect.TypeOf((*MockFullNode)(nil).StateNetworkVersion), arg0, arg1)
 }
 
+// StatePatch mocks base method.
+func (m *MockFullNode) StatePatch(arg0 context.Context, arg1 []api.ActorPatch) (*types.TipSet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatePatch", arg0, arg1)
+	ret0, _ := ret[0].(*types.TipSet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatePatch indicates an expected call of StatePatch.
+func (mr *MockFullNodeMockRecorder) StatePatch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatePatch", reflect.TypeOf((*MockFullNode)(nil).StatePatch), arg0, arg1)
+}
+
+// StatePledgeForPowerBatch mocks base method.
+func (m *MockFullNode) StatePledgeForPowerBatch(arg0 context.Context, arg1 []api.ProspectiveSector, arg2 types.TipSetKey) (*api.PledgeRequirements, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StatePledgeForPowerBatch", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.PledgeRequirements)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StatePledgeForPowerBatch indicates an expected call of StatePledgeForPowerBatch.
+func (mr *MockFullNodeMockRecorder) StatePledgeForPowerBatch(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StatePledgeForPowerBatch", reflect.TypeOf((*MockFullNode)(nil).StatePledgeForPowerBatch), arg0, arg1, arg2)
+}
+
 // StateReadState mocks base method.
 func (m *MockFullNode) StateReadState(arg0 context.Context, arg1 address.Address, arg2 types.TipSetKey) (*api.ActorState, error) {
 	m.ctrl.T.Helper()
@@ -3713,6 +4534,21 @@ func (mr *MockFullNodeMockRecorder) StateReplay(arg0, arg1, arg2 interface{}) *g
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateReplay", reflect.TypeOf((*MockFullNode)(nil).StateReplay), arg0, arg1, arg2)
 }
 
+// StateReplayCached mocks base method.
+func (m *MockFullNode) StateReplayCached(arg0 context.Context, arg1 types.TipSetKey, arg2 cid.Cid) (*api.InvocResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateReplayCached", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*api.InvocResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateReplayCached indicates an expected call of StateReplayCached.
+func (mr *MockFullNodeMockRecorder) StateReplayCached(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateReplayCached", reflect.TypeOf((*MockFullNode)(nil).StateReplayCached), arg0, arg1, arg2)
+}
+
 // StateSearchMsg mocks base method.
 func (m *MockFullNode) StateSearchMsg(arg0 context.Context, arg1 types.TipSetKey, arg2 cid.Cid, arg3 abi.ChainEpoch, arg4 bool) (*api.MsgLookup, error) {
 	m.ctrl.T.Helper()
@@ -3788,6 +4624,21 @@ func (mr *MockFullNodeMockRecorder) StateSectorPreCommitInfo(arg0, arg1, arg2, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateSectorPreCommitInfo", reflect.TypeOf((*MockFullNode)(nil).StateSectorPreCommitInfo), arg0, arg1, arg2, arg3)
 }
 
+// StateUpgradeSchedule mocks base method.
+func (m *MockFullNode) StateUpgradeSchedule(arg0 context.Context) ([]api.UpgradeInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateUpgradeSchedule", arg0)
+	ret0, _ := ret[0].([]api.UpgradeInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateUpgradeSchedule indicates an expected call of StateUpgradeSchedule.
+func (mr *MockFullNodeMockRecorder) StateUpgradeSchedule(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateUpgradeSchedule", reflect.TypeOf((*MockFullNode)(nil).StateUpgradeSchedule), arg0)
+}
+
 // StateVMCirculatingSupplyInternal mocks base method.
 func (m *MockFullNode) StateVMCirculatingSupplyInternal(arg0 context.Context, arg1 types.TipSetKey) (api.CirculatingSupply, error) {
 	m.ctrl.T.Helper()
@@ -3863,6 +4714,21 @@ func (mr *MockFullNodeMockRecorder) StateWaitMsg(arg0, arg1, arg2, arg3, arg4 in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateWaitMsg", reflect.TypeOf((*MockFullNode)(nil).StateWaitMsg), arg0, arg1, arg2, arg3, arg4)
 }
 
+// StateWatchBalances mocks base method.
+func (m *MockFullNode) StateWatchBalances(arg0 context.Context, arg1 []address.Address) (<-chan []*api.BalanceChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StateWatchBalances", arg0, arg1)
+	ret0, _ := ret[0].(<-chan []*api.BalanceChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StateWatchBalances indicates an expected call of StateWatchBalances.
+func (mr *MockFullNodeMockRecorder) StateWatchBalances(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StateWatchBalances", reflect.TypeOf((*MockFullNode)(nil).StateWatchBalances), arg0, arg1)
+}
+
 // SyncCheckBad mocks base method.
 func (m *MockFullNode) SyncCheckBad(arg0 context.Context, arg1 cid.Cid) (string, error) {
 	m.ctrl.T.Helper()
@@ -4008,6 +4874,20 @@ func (mr *MockFullNodeMockRecorder) Version(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Version", reflect.TypeOf((*MockFullNode)(nil).Version), arg0)
 }
 
+// WalletApprove mocks base method.
+func (m *MockFullNode) WalletApprove(arg0 context.Context, arg1 uuid.UUID, arg2 bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletApprove", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WalletApprove indicates an expected call of WalletApprove.
+func (mr *MockFullNodeMockRecorder) WalletApprove(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletApprove", reflect.TypeOf((*MockFullNode)(nil).WalletApprove), arg0, arg1, arg2)
+}
+
 // WalletBalance mocks base method.
 func (m *MockFullNode) WalletBalance(arg0 context.Context, arg1 address.Address) (big.Int, error) {
 	m.ctrl.T.Helper()
@@ -4127,6 +5007,21 @@ func (mr *MockFullNodeMockRecorder) WalletNew(arg0, arg1 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletNew", reflect.TypeOf((*MockFullNode)(nil).WalletNew), arg0, arg1)
 }
 
+// WalletPendingApprovals mocks base method.
+func (m *MockFullNode) WalletPendingApprovals(arg0 context.Context) ([]api.WalletApproval, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WalletPendingApprovals", arg0)
+	ret0, _ := ret[0].([]api.WalletApproval)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WalletPendingApprovals indicates an expected call of WalletPendingApprovals.
+func (mr *MockFullNodeMockRecorder) WalletPendingApprovals(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WalletPendingApprovals", reflect.TypeOf((*MockFullNode)(nil).WalletPendingApprovals), arg0)
+}
+
 // WalletSetDefault mocks base method.
 func (m *MockFullNode) WalletSetDefault(arg0 context.Context, arg1 address.Address) error {
 	m.ctrl.T.Helper()
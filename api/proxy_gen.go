@@ -81,8 +81,12 @@ type CommonMethods struct {
 
 	LogList func(p0 context.Context) ([]string, error) `perm:"write"`
 
+	LogResetLevels func(p0 context.Context) error `perm:"write"`
+
 	LogSetLevel func(p0 context.Context, p1 string, p2 string) error `perm:"write"`
 
+	RepoLockStatus func(p0 context.Context) (RepoLockStatus, error) `perm:"read"`
+
 	Session func(p0 context.Context) (uuid.UUID, error) `perm:"read"`
 
 	Shutdown func(p0 context.Context) error `perm:"admin"`
@@ -132,6 +136,20 @@ type FullNodeStruct struct {
 }
 
 type FullNodeMethods struct {
+	ActorCreate func(p0 context.Context, p1 address.Address, p2 cid.Cid, p3 []byte, p4 types.BigInt) (*MessagePrototype, error) `perm:"sign"`
+
+	ActorEstimateInstall func(p0 context.Context, p1 address.Address, p2 []byte) (*ActorInstallEstimate, error) `perm:"read"`
+
+	ActorInstall func(p0 context.Context, p1 address.Address, p2 []byte) (*MessagePrototype, error) `perm:"sign"`
+
+	ActorValidateCode func(p0 context.Context, p1 []byte) (cid.Cid, error) `perm:"read"`
+
+	AdvanceEpochs func(p0 context.Context, p1 abi.ChainEpoch) (*types.TipSet, error) `perm:"admin"`
+
+	BackupCreate func(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) `perm:"admin"`
+
+	BeaconStatus func(p0 context.Context) ([]BeaconStatus, error) `perm:"read"`
+
 	ChainBlockstoreInfo func(p0 context.Context) (map[string]interface{}, error) `perm:"read"`
 
 	ChainCheckBlockstore func(p0 context.Context) error `perm:"admin"`
@@ -140,8 +158,16 @@ type FullNodeMethods struct {
 
 	ChainExport func(p0 context.Context, p1 abi.ChainEpoch, p2 bool, p3 types.TipSetKey) (<-chan []byte, error) `perm:"read"`
 
+	ChainExportObj func(p0 context.Context, p1 string, p2 DagWalkOpts) (<-chan []byte, error) `perm:"read"`
+
 	ChainExportRangeInternal func(p0 context.Context, p1 types.TipSetKey, p2 types.TipSetKey, p3 ChainExportConfig) error `perm:"admin"`
 
+	ChainExportStream func(p0 context.Context, p1 types.TipSetKey, p2 ChainExportStreamParams) (<-chan ChainExportChunk, error) `perm:"read"`
+
+	ChainFindObjPath func(p0 context.Context, p1 cid.Cid, p2 cid.Cid, p3 DagWalkOpts) ([]DagPathStep, error) `perm:"read"`
+
+	ChainFork func(p0 context.Context, p1 types.TipSetKey) (*types.TipSet, error) `perm:"admin"`
+
 	ChainGetBlock func(p0 context.Context, p1 cid.Cid) (*types.BlockHeader, error) `perm:"read"`
 
 	ChainGetBlockMessages func(p0 context.Context, p1 cid.Cid) (*BlockMessages, error) `perm:"read"`
@@ -168,14 +194,22 @@ type FullNodeMethods struct {
 
 	ChainGetTipSetByHeight func(p0 context.Context, p1 abi.ChainEpoch, p2 types.TipSetKey) (*types.TipSet, error) `perm:"read"`
 
+	ChainGetTipSetProof func(p0 context.Context, p1 types.TipSetKey) (*TipSetProof, error) `perm:"read"`
+
 	ChainHasObj func(p0 context.Context, p1 cid.Cid) (bool, error) `perm:"read"`
 
 	ChainHead func(p0 context.Context) (*types.TipSet, error) `perm:"read"`
 
 	ChainHotGC func(p0 context.Context, p1 HotGCOpts) error `perm:"admin"`
 
+	ChainIndexQuery func(p0 context.Context, p1 string, p2 string) (*IndexQueryResult, error) `perm:"read"`
+
 	ChainNotify func(p0 context.Context) (<-chan []*HeadChange, error) `perm:"read"`
 
+	ChainNotifyAtConfidence func(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) `perm:"read"`
+
+	ChainNotifyResume func(p0 context.Context, p1 string) (<-chan HeadChangeBatch, error) `perm:"read"`
+
 	ChainPrune func(p0 context.Context, p1 PruneOpts) error `perm:"admin"`
 
 	ChainPutObj func(p0 context.Context, p1 blocks.Block) error `perm:"admin"`
@@ -188,6 +222,8 @@ type FullNodeMethods struct {
 
 	ChainTipSetWeight func(p0 context.Context, p1 types.TipSetKey) (types.BigInt, error) `perm:"read"`
 
+	ChainWalkObj func(p0 context.Context, p1 string, p2 DagWalkOpts) (*DagWalk, error) `perm:"read"`
+
 	ClientCalcCommP func(p0 context.Context, p1 string) (*CommPRet, error) `perm:"write"`
 
 	ClientCancelDataTransfer func(p0 context.Context, p1 datatransfer.TransferID, p2 peer.ID, p3 bool) error `perm:"write"`
@@ -250,12 +286,18 @@ type FullNodeMethods struct {
 
 	EthAddressToFilecoinAddress func(p0 context.Context, p1 ethtypes.EthAddress) (address.Address, error) `perm:"read"`
 
+	EthBatchStateRead func(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) `perm:"read"`
+
 	EthBlockNumber func(p0 context.Context) (ethtypes.EthUint64, error) `perm:"read"`
 
 	EthCall func(p0 context.Context, p1 ethtypes.EthCall, p2 string) (ethtypes.EthBytes, error) `perm:"read"`
 
+	EthCallBundle func(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) `perm:"read"`
+
 	EthChainId func(p0 context.Context) (ethtypes.EthUint64, error) `perm:"read"`
 
+	EthDecodeRawTransaction func(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) `perm:"read"`
+
 	EthEstimateGas func(p0 context.Context, p1 ethtypes.EthCall) (ethtypes.EthUint64, error) `perm:"read"`
 
 	EthFeeHistory func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthFeeHistory, error) `perm:"read"`
@@ -280,6 +322,10 @@ type FullNodeMethods struct {
 
 	EthGetLogs func(p0 context.Context, p1 *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error) `perm:"read"`
 
+	EthGetLogsPage func(p0 context.Context, p1 *ethtypes.EthFilterSpec, p2 string) (*EthLogsPage, error) `perm:"read"`
+
+	EthGetLogsStream func(p0 context.Context, p1 *ethtypes.EthFilterSpec) (<-chan EthLogsPage, error) `perm:"read"`
+
 	EthGetMessageCidByTransactionHash func(p0 context.Context, p1 *ethtypes.EthHash) (*cid.Cid, error) `perm:"read"`
 
 	EthGetStorageAt func(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthBytes, p3 string) (ethtypes.EthBytes, error) `perm:"read"`
@@ -300,6 +346,8 @@ type FullNodeMethods struct {
 
 	EthGetTransactionReceiptLimited func(p0 context.Context, p1 ethtypes.EthHash, p2 abi.ChainEpoch) (*EthTxReceipt, error) `perm:"read"`
 
+	EthGetTransactionReceiptProof func(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) `perm:"read"`
+
 	EthMaxPriorityFeePerGas func(p0 context.Context) (ethtypes.EthBigInt, error) `perm:"read"`
 
 	EthNewBlockFilter func(p0 context.Context) (ethtypes.EthFilterID, error) `perm:"read"`
@@ -312,10 +360,26 @@ type FullNodeMethods struct {
 
 	EthSendRawTransaction func(p0 context.Context, p1 ethtypes.EthBytes) (ethtypes.EthHash, error) `perm:"read"`
 
+	EthSendSponsoredCall func(p0 context.Context, p1 EthSponsoredCall) (*EthSponsoredCallResult, error) `perm:"sign"`
+
+	EthSignTypedData func(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) `perm:"sign"`
+
+	EthSponsoredCallTypedData func(p0 context.Context, p1 EthSponsoredCall) (*ethtypes.EthTypedData, error) `perm:"read"`
+
 	EthSubscribe func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) `perm:"read"`
 
+	EthSubscribeStorageSlots func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) `perm:"read"`
+
 	EthSyncing func(p0 context.Context) (ethtypes.EthSyncingResult, error) `perm:"read"`
 
+	EthTraceTransactionGasProfile func(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) `perm:"read"`
+
+	EthTxPoolContent func(p0 context.Context) (EthTxPoolContent, error) `perm:"read"`
+
+	EthTxPoolInspect func(p0 context.Context) (EthTxPoolInspect, error) `perm:"read"`
+
+	EthTxPoolStatus func(p0 context.Context) (EthTxPoolStatus, error) `perm:"read"`
+
 	EthUninstallFilter func(p0 context.Context, p1 ethtypes.EthFilterID) (bool, error) `perm:"read"`
 
 	EthUnsubscribe func(p0 context.Context, p1 ethtypes.EthSubscriptionID) (bool, error) `perm:"read"`
@@ -330,6 +394,8 @@ type FullNodeMethods struct {
 
 	GasEstimateMessageGas func(p0 context.Context, p1 *types.Message, p2 *MessageSendSpec, p3 types.TipSetKey) (*types.Message, error) `perm:"read"`
 
+	GasForecastBaseFee func(p0 context.Context, p1 int, p2 types.TipSetKey) (*BaseFeeForecast, error) `perm:"read"`
+
 	MarketAddBalance func(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.BigInt) (cid.Cid, error) `perm:"sign"`
 
 	MarketGetReserved func(p0 context.Context, p1 address.Address) (types.BigInt, error) `perm:"sign"`
@@ -340,8 +406,12 @@ type FullNodeMethods struct {
 
 	MarketWithdraw func(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.BigInt) (cid.Cid, error) `perm:"sign"`
 
+	MineOne func(p0 context.Context) (*types.TipSet, error) `perm:"admin"`
+
 	MinerCreateBlock func(p0 context.Context, p1 *BlockTemplate) (*types.BlockMsg, error) `perm:"write"`
 
+	MinerCreateBlockDryRun func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerBlockDryRun, error) `perm:"write"`
+
 	MinerGetBaseInfo func(p0 context.Context, p1 address.Address, p2 abi.ChainEpoch, p3 types.TipSetKey) (*MiningBaseInfo, error) `perm:"read"`
 
 	MpoolBatchPush func(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error) `perm:"write"`
@@ -350,6 +420,8 @@ type FullNodeMethods struct {
 
 	MpoolBatchPushUntrusted func(p0 context.Context, p1 []*types.SignedMessage) ([]cid.Cid, error) `perm:"write"`
 
+	MpoolCancelScheduled func(p0 context.Context, p1 uuid.UUID) error `perm:"sign"`
+
 	MpoolCheckMessages func(p0 context.Context, p1 []*MessagePrototype) ([][]MessageCheckStatus, error) `perm:"read"`
 
 	MpoolCheckPendingMessages func(p0 context.Context, p1 address.Address) ([][]MessageCheckStatus, error) `perm:"read"`
@@ -362,18 +434,28 @@ type FullNodeMethods struct {
 
 	MpoolGetNonce func(p0 context.Context, p1 address.Address) (uint64, error) `perm:"read"`
 
+	MpoolListScheduled func(p0 context.Context) ([]*ScheduledMessage, error) `perm:"read"`
+
 	MpoolPending func(p0 context.Context, p1 types.TipSetKey) ([]*types.SignedMessage, error) `perm:"read"`
 
+	MpoolPredictInclusion func(p0 context.Context, p1 InclusionSpec) (*InclusionPrediction, error) `perm:"read"`
+
 	MpoolPush func(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) `perm:"write"`
 
 	MpoolPushMessage func(p0 context.Context, p1 *types.Message, p2 *MessageSendSpec) (*types.SignedMessage, error) `perm:"sign"`
 
 	MpoolPushUntrusted func(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) `perm:"write"`
 
+	MpoolScheduleMessage func(p0 context.Context, p1 *types.Message, p2 MessageSchedule, p3 *MessageSendSpec) (*ScheduledMessage, error) `perm:"sign"`
+
 	MpoolSelect func(p0 context.Context, p1 types.TipSetKey, p2 float64) ([]*types.SignedMessage, error) `perm:"read"`
 
 	MpoolSetConfig func(p0 context.Context, p1 *types.MpoolConfig) error `perm:"admin"`
 
+	MpoolSpendBudget func(p0 context.Context) (*SpendBudget, error) `perm:"read"`
+
+	MpoolSpendBudgetOverride func(p0 context.Context, p1 abi.TokenAmount) error `perm:"admin"`
+
 	MpoolSub func(p0 context.Context) (<-chan MpoolUpdate, error) `perm:"read"`
 
 	MsigAddApprove func(p0 context.Context, p1 address.Address, p2 address.Address, p3 uint64, p4 address.Address, p5 address.Address, p6 bool) (*MessagePrototype, error) `perm:"sign"`
@@ -414,6 +496,12 @@ type FullNodeMethods struct {
 
 	NetVersion func(p0 context.Context) (string, error) `perm:"read"`
 
+	NodeAPISpec func(p0 context.Context) (*APISpec, error) `perm:"read"`
+
+	NodeMaintenanceOverride func(p0 context.Context, p1 *MaintenanceOverride) error `perm:"admin"`
+
+	NodeMaintenanceStatus func(p0 context.Context) (*MaintenanceStatus, error) `perm:"read"`
+
 	NodeStatus func(p0 context.Context, p1 bool) (NodeStatus, error) `perm:"read"`
 
 	PaychAllocateLane func(p0 context.Context, p1 address.Address) (uint64, error) `perm:"sign"`
@@ -478,6 +566,8 @@ type FullNodeMethods struct {
 
 	StateEncodeParams func(p0 context.Context, p1 cid.Cid, p2 abi.MethodNum, p3 json.RawMessage) ([]byte, error) `perm:"read"`
 
+	StateGasStats func(p0 context.Context, p1 abi.ChainEpoch) (*GasStats, error) `perm:"read"`
+
 	StateGetActor func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*types.Actor, error) `perm:"read"`
 
 	StateGetAllocation func(p0 context.Context, p1 address.Address, p2 verifregtypes.AllocationId, p3 types.TipSetKey) (*verifregtypes.Allocation, error) `perm:"read"`
@@ -494,12 +584,20 @@ type FullNodeMethods struct {
 
 	StateGetNetworkParams func(p0 context.Context) (*NetworkParams, error) `perm:"read"`
 
+	StateGetProof func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) `perm:"read"`
+
 	StateGetRandomnessFromBeacon func(p0 context.Context, p1 crypto.DomainSeparationTag, p2 abi.ChainEpoch, p3 []byte, p4 types.TipSetKey) (abi.Randomness, error) `perm:"read"`
 
 	StateGetRandomnessFromTickets func(p0 context.Context, p1 crypto.DomainSeparationTag, p2 abi.ChainEpoch, p3 []byte, p4 types.TipSetKey) (abi.Randomness, error) `perm:"read"`
 
+	StateGetRandomnessWithProof func(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) `perm:"read"`
+
+	StateInstalledActors func(p0 context.Context, p1 types.TipSetKey) ([]cid.Cid, error) `perm:"read"`
+
 	StateListActors func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) `perm:"read"`
 
+	StateListAddressMessages func(p0 context.Context, p1 address.Address, p2 *AddressMessagesQuery) (*AddressMessages, error) `perm:"read"`
+
 	StateListMessages func(p0 context.Context, p1 *MessageMatch, p2 types.TipSetKey, p3 abi.ChainEpoch) ([]cid.Cid, error) `perm:"read"`
 
 	StateListMiners func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) `perm:"read"`
@@ -522,6 +620,10 @@ type FullNodeMethods struct {
 
 	StateMinerAvailableBalance func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (types.BigInt, error) `perm:"read"`
 
+	StateMinerCheckBeneficiaryChange func(p0 context.Context, p1 address.Address, p2 miner.ChangeBeneficiaryParams, p3 types.TipSetKey) (*AddressChangeCheck, error) `perm:"read"`
+
+	StateMinerCheckOwnerChange func(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.TipSetKey) (*AddressChangeCheck, error) `perm:"read"`
+
 	StateMinerDeadlines func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]Deadline, error) `perm:"read"`
 
 	StateMinerFaults func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error) `perm:"read"`
@@ -532,12 +634,16 @@ type FullNodeMethods struct {
 
 	StateMinerPartitions func(p0 context.Context, p1 address.Address, p2 uint64, p3 types.TipSetKey) ([]Partition, error) `perm:"read"`
 
+	StateMinerPendingChanges func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerPendingChanges, error) `perm:"read"`
+
 	StateMinerPower func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerPower, error) `perm:"read"`
 
 	StateMinerPreCommitDepositForPower func(p0 context.Context, p1 address.Address, p2 miner.SectorPreCommitInfo, p3 types.TipSetKey) (types.BigInt, error) `perm:"read"`
 
 	StateMinerProvingDeadline func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*dline.Info, error) `perm:"read"`
 
+	StateMinerProvingSchedule func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ProvingSchedule, error) `perm:"read"`
+
 	StateMinerRecoveries func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error) `perm:"read"`
 
 	StateMinerSectorAllocated func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (bool, error) `perm:"read"`
@@ -550,10 +656,16 @@ type FullNodeMethods struct {
 
 	StateNetworkVersion func(p0 context.Context, p1 types.TipSetKey) (apitypes.NetworkVersion, error) `perm:"read"`
 
+	StatePatch func(p0 context.Context, p1 []ActorPatch) (*types.TipSet, error) `perm:"admin"`
+
+	StatePledgeForPowerBatch func(p0 context.Context, p1 []ProspectiveSector, p2 types.TipSetKey) (*PledgeRequirements, error) `perm:"read"`
+
 	StateReadState func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorState, error) `perm:"read"`
 
 	StateReplay func(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid) (*InvocResult, error) `perm:"read"`
 
+	StateReplayCached func(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid) (*InvocResult, error) `perm:"read"`
+
 	StateSearchMsg func(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid, p3 abi.ChainEpoch, p4 bool) (*MsgLookup, error) `perm:"read"`
 
 	StateSectorExpiration func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*lminer.SectorExpiration, error) `perm:"read"`
@@ -564,6 +676,8 @@ type FullNodeMethods struct {
 
 	StateSectorPreCommitInfo func(p0 context.Context, p1 address.Address, p2 abi.SectorNumber, p3 types.TipSetKey) (*miner.SectorPreCommitOnChainInfo, error) `perm:"read"`
 
+	StateUpgradeSchedule func(p0 context.Context) ([]UpgradeInfo, error) `perm:"read"`
+
 	StateVMCirculatingSupplyInternal func(p0 context.Context, p1 types.TipSetKey) (CirculatingSupply, error) `perm:"read"`
 
 	StateVerifiedClientStatus func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*abi.StoragePower, error) `perm:"read"`
@@ -574,6 +688,8 @@ type FullNodeMethods struct {
 
 	StateWaitMsg func(p0 context.Context, p1 cid.Cid, p2 uint64, p3 abi.ChainEpoch, p4 bool) (*MsgLookup, error) `perm:"read"`
 
+	StateWatchBalances func(p0 context.Context, p1 []address.Address) (<-chan []*BalanceChange, error) `perm:"read"`
+
 	SyncCheckBad func(p0 context.Context, p1 cid.Cid) (string, error) `perm:"read"`
 
 	SyncCheckpoint func(p0 context.Context, p1 types.TipSetKey) error `perm:"admin"`
@@ -592,6 +708,8 @@ type FullNodeMethods struct {
 
 	SyncValidateTipset func(p0 context.Context, p1 types.TipSetKey) (bool, error) `perm:"read"`
 
+	WalletApprove func(p0 context.Context, p1 uuid.UUID, p2 bool) error `perm:"admin"`
+
 	WalletBalance func(p0 context.Context, p1 address.Address) (types.BigInt, error) `perm:"read"`
 
 	WalletDefaultAddress func(p0 context.Context) (address.Address, error) `perm:"write"`
@@ -608,6 +726,8 @@ type FullNodeMethods struct {
 
 	WalletNew func(p0 context.Context, p1 types.KeyType) (address.Address, error) `perm:"write"`
 
+	WalletPendingApprovals func(p0 context.Context) ([]WalletApproval, error) `perm:"admin"`
+
 	WalletSetDefault func(p0 context.Context, p1 address.Address) error `perm:"write"`
 
 	WalletSign func(p0 context.Context, p1 address.Address, p2 []byte) (*crypto.Signature, error) `perm:"sign"`
@@ -656,6 +776,8 @@ type GatewayMethods struct {
 
 	ChainNotify func(p0 context.Context) (<-chan []*HeadChange, error) ``
 
+	ChainNotifyAtConfidence func(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) ``
+
 	ChainPutObj func(p0 context.Context, p1 blocks.Block) error ``
 
 	ChainReadObj func(p0 context.Context, p1 cid.Cid) ([]byte, error) ``
@@ -664,12 +786,18 @@ type GatewayMethods struct {
 
 	EthAccounts func(p0 context.Context) ([]ethtypes.EthAddress, error) ``
 
+	EthBatchStateRead func(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) ``
+
 	EthBlockNumber func(p0 context.Context) (ethtypes.EthUint64, error) ``
 
 	EthCall func(p0 context.Context, p1 ethtypes.EthCall, p2 string) (ethtypes.EthBytes, error) ``
 
+	EthCallBundle func(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) ``
+
 	EthChainId func(p0 context.Context) (ethtypes.EthUint64, error) ``
 
+	EthDecodeRawTransaction func(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) ``
+
 	EthEstimateGas func(p0 context.Context, p1 ethtypes.EthCall) (ethtypes.EthUint64, error) ``
 
 	EthFeeHistory func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthFeeHistory, error) ``
@@ -710,6 +838,8 @@ type GatewayMethods struct {
 
 	EthGetTransactionReceiptLimited func(p0 context.Context, p1 ethtypes.EthHash, p2 abi.ChainEpoch) (*EthTxReceipt, error) ``
 
+	EthGetTransactionReceiptProof func(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) ``
+
 	EthMaxPriorityFeePerGas func(p0 context.Context) (ethtypes.EthBigInt, error) ``
 
 	EthNewBlockFilter func(p0 context.Context) (ethtypes.EthFilterID, error) ``
@@ -724,8 +854,18 @@ type GatewayMethods struct {
 
 	EthSubscribe func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) ``
 
+	EthSubscribeStorageSlots func(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) ``
+
 	EthSyncing func(p0 context.Context) (ethtypes.EthSyncingResult, error) ``
 
+	EthTraceTransactionGasProfile func(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) ``
+
+	EthTxPoolContent func(p0 context.Context) (EthTxPoolContent, error) ``
+
+	EthTxPoolInspect func(p0 context.Context) (EthTxPoolInspect, error) ``
+
+	EthTxPoolStatus func(p0 context.Context) (EthTxPoolStatus, error) ``
+
 	EthUninstallFilter func(p0 context.Context, p1 ethtypes.EthFilterID) (bool, error) ``
 
 	EthUnsubscribe func(p0 context.Context, p1 ethtypes.EthSubscriptionID) (bool, error) ``
@@ -734,6 +874,8 @@ type GatewayMethods struct {
 
 	GasEstimateMessageGas func(p0 context.Context, p1 *types.Message, p2 *MessageSendSpec, p3 types.TipSetKey) (*types.Message, error) ``
 
+	GatewayUsage func(p0 context.Context) (*GatewayUsage, error) ``
+
 	MpoolGetNonce func(p0 context.Context, p1 address.Address) (uint64, error) ``
 
 	MpoolPush func(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) ``
@@ -760,6 +902,10 @@ type GatewayMethods struct {
 
 	StateGetActor func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*types.Actor, error) ``
 
+	StateGetProof func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) ``
+
+	StateGetRandomnessWithProof func(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) ``
+
 	StateListMiners func(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) ``
 
 	StateLookupID func(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (address.Address, error) ``
@@ -889,6 +1035,8 @@ type StorageMinerMethods struct {
 
 	ActorWithdrawBalance func(p0 context.Context, p1 abi.TokenAmount) (cid.Cid, error) `perm:"admin"`
 
+	BackupCreate func(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) `perm:"admin"`
+
 	BeneficiaryWithdrawBalance func(p0 context.Context, p1 abi.TokenAmount) (cid.Cid, error) `perm:"admin"`
 
 	CheckProvable func(p0 context.Context, p1 abi.RegisteredPoStProof, p2 []storiface.SectorRef) (map[abi.SectorNumber]string, error) `perm:"admin"`
@@ -927,6 +1075,8 @@ type StorageMinerMethods struct {
 
 	DealsConsiderVerifiedStorageDeals func(p0 context.Context) (bool, error) `perm:"admin"`
 
+	DealsFilterDecisions func(p0 context.Context, p1 int) ([]DealFilterDecision, error) `perm:"read"`
+
 	DealsImportData func(p0 context.Context, p1 cid.Cid, p2 string) error `perm:"admin"`
 
 	DealsList func(p0 context.Context) ([]*MarketDeal, error) `perm:"admin"`
@@ -991,12 +1141,20 @@ type StorageMinerMethods struct {
 
 	PiecesGetPieceInfo func(p0 context.Context, p1 cid.Cid) (*piecestore.PieceInfo, error) `perm:"read"`
 
+	PiecesHotness func(p0 context.Context) ([]PieceHotness, error) `perm:"read"`
+
 	PiecesListCidInfos func(p0 context.Context) ([]cid.Cid, error) `perm:"read"`
 
 	PiecesListPieces func(p0 context.Context) ([]cid.Cid, error) `perm:"read"`
 
 	PledgeSector func(p0 context.Context) (abi.SectorID, error) `perm:"write"`
 
+	ProofParamsFetch func(p0 context.Context, p1 []abi.SectorSize, p2 string) error `perm:"admin"`
+
+	ProofParamsStatus func(p0 context.Context, p1 []abi.SectorSize, p2 bool) (ProofParamsStatus, error) `perm:"read"`
+
+	ProvingSimulate func(p0 context.Context, p1 ProvingSimulateParams) (*ProvingSimulation, error) `perm:"read"`
+
 	RecoverFault func(p0 context.Context, p1 []abi.SectorNumber) ([]cid.Cid, error) `perm:"admin"`
 
 	ReturnAddPiece func(p0 context.Context, p1 storiface.CallID, p2 abi.PieceInfo, p3 *storiface.CallError) error `perm:"admin"`
@@ -1039,6 +1197,10 @@ type StorageMinerMethods struct {
 
 	SealingAbort func(p0 context.Context, p1 storiface.CallID) error `perm:"admin"`
 
+	SealingCostEstimate func(p0 context.Context, p1 SealingCostEstimateParams) (*SealingCostEstimate, error) `perm:"read"`
+
+	SealingPipelineStats func(p0 context.Context, p1 int) (*SealingPipelineStats, error) `perm:"read"`
+
 	SealingRemoveRequest func(p0 context.Context, p1 uuid.UUID) error `perm:"admin"`
 
 	SealingSchedDiag func(p0 context.Context, p1 bool) (interface{}, error) `perm:"admin"`
@@ -1095,12 +1257,16 @@ type StorageMinerMethods struct {
 
 	SectorsListInStates func(p0 context.Context, p1 []SectorState) ([]abi.SectorNumber, error) `perm:"read"`
 
+	SectorsReconcileUnsealed func(p0 context.Context, p1 []abi.SectorNumber, p2 bool) ([]UnsealedReconcileAction, error) `perm:"admin"`
+
 	SectorsRefs func(p0 context.Context) (map[string][]SealedRef, error) `perm:"read"`
 
 	SectorsStatus func(p0 context.Context, p1 abi.SectorNumber, p2 bool) (SectorInfo, error) `perm:"read"`
 
 	SectorsSummary func(p0 context.Context) (map[SectorState]int, error) `perm:"read"`
 
+	SectorsSummaryStream func(p0 context.Context) (<-chan []byte, error) `perm:"read"`
+
 	SectorsUnsealPiece func(p0 context.Context, p1 storiface.SectorRef, p2 storiface.UnpaddedByteIndex, p3 abi.UnpaddedPieceSize, p4 abi.SealRandomness, p5 *cid.Cid) error `perm:"admin"`
 
 	SectorsUpdate func(p0 context.Context, p1 abi.SectorNumber, p2 SectorState) error `perm:"admin"`
@@ -1113,6 +1279,8 @@ type StorageMinerMethods struct {
 
 	StorageBestAlloc func(p0 context.Context, p1 storiface.SectorFileType, p2 abi.SectorSize, p3 storiface.PathType) ([]storiface.StorageInfo, error) `perm:"admin"`
 
+	StorageBestAllocSector func(p0 context.Context, p1 storiface.SectorFileType, p2 abi.SectorSize, p3 storiface.PathType, p4 abi.SectorID) ([]storiface.StorageInfo, error) `perm:"admin"`
+
 	StorageDeclareSector func(p0 context.Context, p1 storiface.ID, p2 abi.SectorID, p3 storiface.SectorFileType, p4 bool) error `perm:"admin"`
 
 	StorageDetach func(p0 context.Context, p1 storiface.ID, p2 string) error `perm:"admin"`
@@ -1137,15 +1305,23 @@ type StorageMinerMethods struct {
 
 	StorageReportHealth func(p0 context.Context, p1 storiface.ID, p2 storiface.HealthReport) error `perm:"admin"`
 
+	StorageSetSectorPlacement func(p0 context.Context, p1 abi.SectorID, p2 []storiface.Group) error `perm:"admin"`
+
 	StorageStat func(p0 context.Context, p1 storiface.ID) (fsutil.FsStat, error) `perm:"admin"`
 
 	StorageTryLock func(p0 context.Context, p1 abi.SectorID, p2 storiface.SectorFileType, p3 storiface.SectorFileType) (bool, error) `perm:"admin"`
 
 	WorkerConnect func(p0 context.Context, p1 string) error `perm:"admin"`
 
+	WorkerGPUStats func(p0 context.Context) ([]storiface.WorkerGPUStats, error) `perm:"admin"`
+
 	WorkerJobs func(p0 context.Context) (map[uuid.UUID][]storiface.WorkerJob, error) `perm:"admin"`
 
 	WorkerStats func(p0 context.Context) (map[uuid.UUID]storiface.WorkerStats, error) `perm:"admin"`
+
+	WorkerTaskHistory func(p0 context.Context, p1 storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) `perm:"admin"`
+
+	WorkerTaskStats func(p0 context.Context, p1 time.Time) ([]storiface.WorkerTaskStats, error) `perm:"admin"`
 }
 
 type StorageMinerStub struct {
@@ -1196,6 +1372,8 @@ type WorkerMethods struct {
 
 	FinalizeSector func(p0 context.Context, p1 storiface.SectorRef) (storiface.CallID, error) `perm:"admin"`
 
+	GPUStats func(p0 context.Context) ([]storiface.GPUStats, error) `perm:"admin"`
+
 	GenerateSectorKeyFromData func(p0 context.Context, p1 storiface.SectorRef, p2 cid.Cid) (storiface.CallID, error) `perm:"admin"`
 
 	GenerateWindowPoSt func(p0 context.Context, p1 abi.RegisteredPoStProof, p2 abi.ActorID, p3 []storiface.PostSectorChallenge, p4 int, p5 abi.PoStRandomness) (storiface.WindowPoStResult, error) `perm:"admin"`
@@ -1359,6 +1537,17 @@ func (s *CommonStub) LogList(p0 context.Context) ([]string, error) {
 	return *new([]string), ErrNotSupported
 }
 
+func (s *CommonStruct) LogResetLevels(p0 context.Context) error {
+	if s.Internal.LogResetLevels == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.LogResetLevels(p0)
+}
+
+func (s *CommonStub) LogResetLevels(p0 context.Context) error {
+	return ErrNotSupported
+}
+
 func (s *CommonStruct) LogSetLevel(p0 context.Context, p1 string, p2 string) error {
 	if s.Internal.LogSetLevel == nil {
 		return ErrNotSupported
@@ -1370,6 +1559,17 @@ func (s *CommonStub) LogSetLevel(p0 context.Context, p1 string, p2 string) error
 	return ErrNotSupported
 }
 
+func (s *CommonStruct) RepoLockStatus(p0 context.Context) (RepoLockStatus, error) {
+	if s.Internal.RepoLockStatus == nil {
+		return *new(RepoLockStatus), ErrNotSupported
+	}
+	return s.Internal.RepoLockStatus(p0)
+}
+
+func (s *CommonStub) RepoLockStatus(p0 context.Context) (RepoLockStatus, error) {
+	return *new(RepoLockStatus), ErrNotSupported
+}
+
 func (s *CommonStruct) Session(p0 context.Context) (uuid.UUID, error) {
 	if s.Internal.Session == nil {
 		return *new(uuid.UUID), ErrNotSupported
@@ -1425,6 +1625,83 @@ func (s *EthSubscriberStub) EthSubscription(p0 context.Context, p1 jsonrpc.RawPa
 	return ErrNotSupported
 }
 
+func (s *FullNodeStruct) ActorCreate(p0 context.Context, p1 address.Address, p2 cid.Cid, p3 []byte, p4 types.BigInt) (*MessagePrototype, error) {
+	if s.Internal.ActorCreate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ActorCreate(p0, p1, p2, p3, p4)
+}
+
+func (s *FullNodeStub) ActorCreate(p0 context.Context, p1 address.Address, p2 cid.Cid, p3 []byte, p4 types.BigInt) (*MessagePrototype, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) ActorEstimateInstall(p0 context.Context, p1 address.Address, p2 []byte) (*ActorInstallEstimate, error) {
+	if s.Internal.ActorEstimateInstall == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ActorEstimateInstall(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ActorEstimateInstall(p0 context.Context, p1 address.Address, p2 []byte) (*ActorInstallEstimate, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) ActorInstall(p0 context.Context, p1 address.Address, p2 []byte) (*MessagePrototype, error) {
+	if s.Internal.ActorInstall == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ActorInstall(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ActorInstall(p0 context.Context, p1 address.Address, p2 []byte) (*MessagePrototype, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) ActorValidateCode(p0 context.Context, p1 []byte) (cid.Cid, error) {
+	if s.Internal.ActorValidateCode == nil {
+		return *new(cid.Cid), ErrNotSupported
+	}
+	return s.Internal.ActorValidateCode(p0, p1)
+}
+
+func (s *FullNodeStub) ActorValidateCode(p0 context.Context, p1 []byte) (cid.Cid, error) {
+	return *new(cid.Cid), ErrNotSupported
+}
+
+func (s *FullNodeStruct) AdvanceEpochs(p0 context.Context, p1 abi.ChainEpoch) (*types.TipSet, error) {
+	if s.Internal.AdvanceEpochs == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.AdvanceEpochs(p0, p1)
+}
+
+func (s *FullNodeStub) AdvanceEpochs(p0 context.Context, p1 abi.ChainEpoch) (*types.TipSet, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) BackupCreate(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) {
+	if s.Internal.BackupCreate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.BackupCreate(p0, p1, p2)
+}
+
+func (s *FullNodeStub) BackupCreate(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) BeaconStatus(p0 context.Context) ([]BeaconStatus, error) {
+	if s.Internal.BeaconStatus == nil {
+		return *new([]BeaconStatus), ErrNotSupported
+	}
+	return s.Internal.BeaconStatus(p0)
+}
+
+func (s *FullNodeStub) BeaconStatus(p0 context.Context) ([]BeaconStatus, error) {
+	return *new([]BeaconStatus), ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainBlockstoreInfo(p0 context.Context) (map[string]interface{}, error) {
 	if s.Internal.ChainBlockstoreInfo == nil {
 		return *new(map[string]interface{}), ErrNotSupported
@@ -1469,6 +1746,17 @@ func (s *FullNodeStub) ChainExport(p0 context.Context, p1 abi.ChainEpoch, p2 boo
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainExportObj(p0 context.Context, p1 string, p2 DagWalkOpts) (<-chan []byte, error) {
+	if s.Internal.ChainExportObj == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainExportObj(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ChainExportObj(p0 context.Context, p1 string, p2 DagWalkOpts) (<-chan []byte, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainExportRangeInternal(p0 context.Context, p1 types.TipSetKey, p2 types.TipSetKey, p3 ChainExportConfig) error {
 	if s.Internal.ChainExportRangeInternal == nil {
 		return ErrNotSupported
@@ -1480,6 +1768,39 @@ func (s *FullNodeStub) ChainExportRangeInternal(p0 context.Context, p1 types.Tip
 	return ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainExportStream(p0 context.Context, p1 types.TipSetKey, p2 ChainExportStreamParams) (<-chan ChainExportChunk, error) {
+	if s.Internal.ChainExportStream == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainExportStream(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ChainExportStream(p0 context.Context, p1 types.TipSetKey, p2 ChainExportStreamParams) (<-chan ChainExportChunk, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) ChainFindObjPath(p0 context.Context, p1 cid.Cid, p2 cid.Cid, p3 DagWalkOpts) ([]DagPathStep, error) {
+	if s.Internal.ChainFindObjPath == nil {
+		return *new([]DagPathStep), ErrNotSupported
+	}
+	return s.Internal.ChainFindObjPath(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStub) ChainFindObjPath(p0 context.Context, p1 cid.Cid, p2 cid.Cid, p3 DagWalkOpts) ([]DagPathStep, error) {
+	return *new([]DagPathStep), ErrNotSupported
+}
+
+func (s *FullNodeStruct) ChainFork(p0 context.Context, p1 types.TipSetKey) (*types.TipSet, error) {
+	if s.Internal.ChainFork == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainFork(p0, p1)
+}
+
+func (s *FullNodeStub) ChainFork(p0 context.Context, p1 types.TipSetKey) (*types.TipSet, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainGetBlock(p0 context.Context, p1 cid.Cid) (*types.BlockHeader, error) {
 	if s.Internal.ChainGetBlock == nil {
 		return nil, ErrNotSupported
@@ -1623,6 +1944,17 @@ func (s *FullNodeStub) ChainGetTipSetByHeight(p0 context.Context, p1 abi.ChainEp
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainGetTipSetProof(p0 context.Context, p1 types.TipSetKey) (*TipSetProof, error) {
+	if s.Internal.ChainGetTipSetProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainGetTipSetProof(p0, p1)
+}
+
+func (s *FullNodeStub) ChainGetTipSetProof(p0 context.Context, p1 types.TipSetKey) (*TipSetProof, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainHasObj(p0 context.Context, p1 cid.Cid) (bool, error) {
 	if s.Internal.ChainHasObj == nil {
 		return false, ErrNotSupported
@@ -1656,6 +1988,17 @@ func (s *FullNodeStub) ChainHotGC(p0 context.Context, p1 HotGCOpts) error {
 	return ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainIndexQuery(p0 context.Context, p1 string, p2 string) (*IndexQueryResult, error) {
+	if s.Internal.ChainIndexQuery == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainIndexQuery(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ChainIndexQuery(p0 context.Context, p1 string, p2 string) (*IndexQueryResult, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainNotify(p0 context.Context) (<-chan []*HeadChange, error) {
 	if s.Internal.ChainNotify == nil {
 		return nil, ErrNotSupported
@@ -1667,6 +2010,28 @@ func (s *FullNodeStub) ChainNotify(p0 context.Context) (<-chan []*HeadChange, er
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainNotifyAtConfidence(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) {
+	if s.Internal.ChainNotifyAtConfidence == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainNotifyAtConfidence(p0, p1)
+}
+
+func (s *FullNodeStub) ChainNotifyAtConfidence(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) ChainNotifyResume(p0 context.Context, p1 string) (<-chan HeadChangeBatch, error) {
+	if s.Internal.ChainNotifyResume == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainNotifyResume(p0, p1)
+}
+
+func (s *FullNodeStub) ChainNotifyResume(p0 context.Context, p1 string) (<-chan HeadChangeBatch, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ChainPrune(p0 context.Context, p1 PruneOpts) error {
 	if s.Internal.ChainPrune == nil {
 		return ErrNotSupported
@@ -1733,6 +2098,17 @@ func (s *FullNodeStub) ChainTipSetWeight(p0 context.Context, p1 types.TipSetKey)
 	return *new(types.BigInt), ErrNotSupported
 }
 
+func (s *FullNodeStruct) ChainWalkObj(p0 context.Context, p1 string, p2 DagWalkOpts) (*DagWalk, error) {
+	if s.Internal.ChainWalkObj == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainWalkObj(p0, p1, p2)
+}
+
+func (s *FullNodeStub) ChainWalkObj(p0 context.Context, p1 string, p2 DagWalkOpts) (*DagWalk, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) ClientCalcCommP(p0 context.Context, p1 string) (*CommPRet, error) {
 	if s.Internal.ClientCalcCommP == nil {
 		return nil, ErrNotSupported
@@ -2074,6 +2450,17 @@ func (s *FullNodeStub) EthAddressToFilecoinAddress(p0 context.Context, p1 ethtyp
 	return *new(address.Address), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthBatchStateRead(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) {
+	if s.Internal.EthBatchStateRead == nil {
+		return *new([]EthStateReadResult), ErrNotSupported
+	}
+	return s.Internal.EthBatchStateRead(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthBatchStateRead(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) {
+	return *new([]EthStateReadResult), ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthBlockNumber(p0 context.Context) (ethtypes.EthUint64, error) {
 	if s.Internal.EthBlockNumber == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -2096,6 +2483,17 @@ func (s *FullNodeStub) EthCall(p0 context.Context, p1 ethtypes.EthCall, p2 strin
 	return *new(ethtypes.EthBytes), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthCallBundle(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) {
+	if s.Internal.EthCallBundle == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthCallBundle(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStub) EthCallBundle(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthChainId(p0 context.Context) (ethtypes.EthUint64, error) {
 	if s.Internal.EthChainId == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -2107,6 +2505,17 @@ func (s *FullNodeStub) EthChainId(p0 context.Context) (ethtypes.EthUint64, error
 	return *new(ethtypes.EthUint64), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthDecodeRawTransaction(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) {
+	if s.Internal.EthDecodeRawTransaction == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthDecodeRawTransaction(p0, p1)
+}
+
+func (s *FullNodeStub) EthDecodeRawTransaction(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthEstimateGas(p0 context.Context, p1 ethtypes.EthCall) (ethtypes.EthUint64, error) {
 	if s.Internal.EthEstimateGas == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -2239,6 +2648,28 @@ func (s *FullNodeStub) EthGetLogs(p0 context.Context, p1 *ethtypes.EthFilterSpec
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthGetLogsPage(p0 context.Context, p1 *ethtypes.EthFilterSpec, p2 string) (*EthLogsPage, error) {
+	if s.Internal.EthGetLogsPage == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthGetLogsPage(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthGetLogsPage(p0 context.Context, p1 *ethtypes.EthFilterSpec, p2 string) (*EthLogsPage, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthGetLogsStream(p0 context.Context, p1 *ethtypes.EthFilterSpec) (<-chan EthLogsPage, error) {
+	if s.Internal.EthGetLogsStream == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthGetLogsStream(p0, p1)
+}
+
+func (s *FullNodeStub) EthGetLogsStream(p0 context.Context, p1 *ethtypes.EthFilterSpec) (<-chan EthLogsPage, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthGetMessageCidByTransactionHash(p0 context.Context, p1 *ethtypes.EthHash) (*cid.Cid, error) {
 	if s.Internal.EthGetMessageCidByTransactionHash == nil {
 		return nil, ErrNotSupported
@@ -2349,6 +2780,17 @@ func (s *FullNodeStub) EthGetTransactionReceiptLimited(p0 context.Context, p1 et
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthGetTransactionReceiptProof(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) {
+	if s.Internal.EthGetTransactionReceiptProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthGetTransactionReceiptProof(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthGetTransactionReceiptProof(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthMaxPriorityFeePerGas(p0 context.Context) (ethtypes.EthBigInt, error) {
 	if s.Internal.EthMaxPriorityFeePerGas == nil {
 		return *new(ethtypes.EthBigInt), ErrNotSupported
@@ -2415,6 +2857,39 @@ func (s *FullNodeStub) EthSendRawTransaction(p0 context.Context, p1 ethtypes.Eth
 	return *new(ethtypes.EthHash), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthSendSponsoredCall(p0 context.Context, p1 EthSponsoredCall) (*EthSponsoredCallResult, error) {
+	if s.Internal.EthSendSponsoredCall == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthSendSponsoredCall(p0, p1)
+}
+
+func (s *FullNodeStub) EthSendSponsoredCall(p0 context.Context, p1 EthSponsoredCall) (*EthSponsoredCallResult, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthSignTypedData(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	if s.Internal.EthSignTypedData == nil {
+		return *new(ethtypes.EthBytes), ErrNotSupported
+	}
+	return s.Internal.EthSignTypedData(p0, p1, p2)
+}
+
+func (s *FullNodeStub) EthSignTypedData(p0 context.Context, p1 ethtypes.EthAddress, p2 ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	return *new(ethtypes.EthBytes), ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthSponsoredCallTypedData(p0 context.Context, p1 EthSponsoredCall) (*ethtypes.EthTypedData, error) {
+	if s.Internal.EthSponsoredCallTypedData == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthSponsoredCallTypedData(p0, p1)
+}
+
+func (s *FullNodeStub) EthSponsoredCallTypedData(p0 context.Context, p1 EthSponsoredCall) (*ethtypes.EthTypedData, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthSubscribe(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
 	if s.Internal.EthSubscribe == nil {
 		return *new(ethtypes.EthSubscriptionID), ErrNotSupported
@@ -2426,6 +2901,17 @@ func (s *FullNodeStub) EthSubscribe(p0 context.Context, p1 jsonrpc.RawParams) (e
 	return *new(ethtypes.EthSubscriptionID), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthSubscribeStorageSlots(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	if s.Internal.EthSubscribeStorageSlots == nil {
+		return *new(ethtypes.EthSubscriptionID), ErrNotSupported
+	}
+	return s.Internal.EthSubscribeStorageSlots(p0, p1)
+}
+
+func (s *FullNodeStub) EthSubscribeStorageSlots(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	return *new(ethtypes.EthSubscriptionID), ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthSyncing(p0 context.Context) (ethtypes.EthSyncingResult, error) {
 	if s.Internal.EthSyncing == nil {
 		return *new(ethtypes.EthSyncingResult), ErrNotSupported
@@ -2437,6 +2923,50 @@ func (s *FullNodeStub) EthSyncing(p0 context.Context) (ethtypes.EthSyncingResult
 	return *new(ethtypes.EthSyncingResult), ErrNotSupported
 }
 
+func (s *FullNodeStruct) EthTraceTransactionGasProfile(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) {
+	if s.Internal.EthTraceTransactionGasProfile == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthTraceTransactionGasProfile(p0, p1)
+}
+
+func (s *FullNodeStub) EthTraceTransactionGasProfile(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthTxPoolContent(p0 context.Context) (EthTxPoolContent, error) {
+	if s.Internal.EthTxPoolContent == nil {
+		return *new(EthTxPoolContent), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolContent(p0)
+}
+
+func (s *FullNodeStub) EthTxPoolContent(p0 context.Context) (EthTxPoolContent, error) {
+	return *new(EthTxPoolContent), ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthTxPoolInspect(p0 context.Context) (EthTxPoolInspect, error) {
+	if s.Internal.EthTxPoolInspect == nil {
+		return *new(EthTxPoolInspect), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolInspect(p0)
+}
+
+func (s *FullNodeStub) EthTxPoolInspect(p0 context.Context) (EthTxPoolInspect, error) {
+	return *new(EthTxPoolInspect), ErrNotSupported
+}
+
+func (s *FullNodeStruct) EthTxPoolStatus(p0 context.Context) (EthTxPoolStatus, error) {
+	if s.Internal.EthTxPoolStatus == nil {
+		return *new(EthTxPoolStatus), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolStatus(p0)
+}
+
+func (s *FullNodeStub) EthTxPoolStatus(p0 context.Context) (EthTxPoolStatus, error) {
+	return *new(EthTxPoolStatus), ErrNotSupported
+}
+
 func (s *FullNodeStruct) EthUninstallFilter(p0 context.Context, p1 ethtypes.EthFilterID) (bool, error) {
 	if s.Internal.EthUninstallFilter == nil {
 		return false, ErrNotSupported
@@ -2514,6 +3044,17 @@ func (s *FullNodeStub) GasEstimateMessageGas(p0 context.Context, p1 *types.Messa
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) GasForecastBaseFee(p0 context.Context, p1 int, p2 types.TipSetKey) (*BaseFeeForecast, error) {
+	if s.Internal.GasForecastBaseFee == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GasForecastBaseFee(p0, p1, p2)
+}
+
+func (s *FullNodeStub) GasForecastBaseFee(p0 context.Context, p1 int, p2 types.TipSetKey) (*BaseFeeForecast, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) MarketAddBalance(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.BigInt) (cid.Cid, error) {
 	if s.Internal.MarketAddBalance == nil {
 		return *new(cid.Cid), ErrNotSupported
@@ -2569,6 +3110,17 @@ func (s *FullNodeStub) MarketWithdraw(p0 context.Context, p1 address.Address, p2
 	return *new(cid.Cid), ErrNotSupported
 }
 
+func (s *FullNodeStruct) MineOne(p0 context.Context) (*types.TipSet, error) {
+	if s.Internal.MineOne == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MineOne(p0)
+}
+
+func (s *FullNodeStub) MineOne(p0 context.Context) (*types.TipSet, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) MinerCreateBlock(p0 context.Context, p1 *BlockTemplate) (*types.BlockMsg, error) {
 	if s.Internal.MinerCreateBlock == nil {
 		return nil, ErrNotSupported
@@ -2580,6 +3132,17 @@ func (s *FullNodeStub) MinerCreateBlock(p0 context.Context, p1 *BlockTemplate) (
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) MinerCreateBlockDryRun(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerBlockDryRun, error) {
+	if s.Internal.MinerCreateBlockDryRun == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MinerCreateBlockDryRun(p0, p1, p2)
+}
+
+func (s *FullNodeStub) MinerCreateBlockDryRun(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerBlockDryRun, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) MinerGetBaseInfo(p0 context.Context, p1 address.Address, p2 abi.ChainEpoch, p3 types.TipSetKey) (*MiningBaseInfo, error) {
 	if s.Internal.MinerGetBaseInfo == nil {
 		return nil, ErrNotSupported
@@ -2624,6 +3187,17 @@ func (s *FullNodeStub) MpoolBatchPushUntrusted(p0 context.Context, p1 []*types.S
 	return *new([]cid.Cid), ErrNotSupported
 }
 
+func (s *FullNodeStruct) MpoolCancelScheduled(p0 context.Context, p1 uuid.UUID) error {
+	if s.Internal.MpoolCancelScheduled == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.MpoolCancelScheduled(p0, p1)
+}
+
+func (s *FullNodeStub) MpoolCancelScheduled(p0 context.Context, p1 uuid.UUID) error {
+	return ErrNotSupported
+}
+
 func (s *FullNodeStruct) MpoolCheckMessages(p0 context.Context, p1 []*MessagePrototype) ([][]MessageCheckStatus, error) {
 	if s.Internal.MpoolCheckMessages == nil {
 		return *new([][]MessageCheckStatus), ErrNotSupported
@@ -2690,6 +3264,17 @@ func (s *FullNodeStub) MpoolGetNonce(p0 context.Context, p1 address.Address) (ui
 	return 0, ErrNotSupported
 }
 
+func (s *FullNodeStruct) MpoolListScheduled(p0 context.Context) ([]*ScheduledMessage, error) {
+	if s.Internal.MpoolListScheduled == nil {
+		return *new([]*ScheduledMessage), ErrNotSupported
+	}
+	return s.Internal.MpoolListScheduled(p0)
+}
+
+func (s *FullNodeStub) MpoolListScheduled(p0 context.Context) ([]*ScheduledMessage, error) {
+	return *new([]*ScheduledMessage), ErrNotSupported
+}
+
 func (s *FullNodeStruct) MpoolPending(p0 context.Context, p1 types.TipSetKey) ([]*types.SignedMessage, error) {
 	if s.Internal.MpoolPending == nil {
 		return *new([]*types.SignedMessage), ErrNotSupported
@@ -2701,6 +3286,17 @@ func (s *FullNodeStub) MpoolPending(p0 context.Context, p1 types.TipSetKey) ([]*
 	return *new([]*types.SignedMessage), ErrNotSupported
 }
 
+func (s *FullNodeStruct) MpoolPredictInclusion(p0 context.Context, p1 InclusionSpec) (*InclusionPrediction, error) {
+	if s.Internal.MpoolPredictInclusion == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MpoolPredictInclusion(p0, p1)
+}
+
+func (s *FullNodeStub) MpoolPredictInclusion(p0 context.Context, p1 InclusionSpec) (*InclusionPrediction, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) MpoolPush(p0 context.Context, p1 *types.SignedMessage) (cid.Cid, error) {
 	if s.Internal.MpoolPush == nil {
 		return *new(cid.Cid), ErrNotSupported
@@ -2734,6 +3330,17 @@ func (s *FullNodeStub) MpoolPushUntrusted(p0 context.Context, p1 *types.SignedMe
 	return *new(cid.Cid), ErrNotSupported
 }
 
+func (s *FullNodeStruct) MpoolScheduleMessage(p0 context.Context, p1 *types.Message, p2 MessageSchedule, p3 *MessageSendSpec) (*ScheduledMessage, error) {
+	if s.Internal.MpoolScheduleMessage == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MpoolScheduleMessage(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStub) MpoolScheduleMessage(p0 context.Context, p1 *types.Message, p2 MessageSchedule, p3 *MessageSendSpec) (*ScheduledMessage, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) MpoolSelect(p0 context.Context, p1 types.TipSetKey, p2 float64) ([]*types.SignedMessage, error) {
 	if s.Internal.MpoolSelect == nil {
 		return *new([]*types.SignedMessage), ErrNotSupported
@@ -2756,6 +3363,28 @@ func (s *FullNodeStub) MpoolSetConfig(p0 context.Context, p1 *types.MpoolConfig)
 	return ErrNotSupported
 }
 
+func (s *FullNodeStruct) MpoolSpendBudget(p0 context.Context) (*SpendBudget, error) {
+	if s.Internal.MpoolSpendBudget == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.MpoolSpendBudget(p0)
+}
+
+func (s *FullNodeStub) MpoolSpendBudget(p0 context.Context) (*SpendBudget, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) MpoolSpendBudgetOverride(p0 context.Context, p1 abi.TokenAmount) error {
+	if s.Internal.MpoolSpendBudgetOverride == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.MpoolSpendBudgetOverride(p0, p1)
+}
+
+func (s *FullNodeStub) MpoolSpendBudgetOverride(p0 context.Context, p1 abi.TokenAmount) error {
+	return ErrNotSupported
+}
+
 func (s *FullNodeStruct) MpoolSub(p0 context.Context) (<-chan MpoolUpdate, error) {
 	if s.Internal.MpoolSub == nil {
 		return nil, ErrNotSupported
@@ -2976,6 +3605,39 @@ func (s *FullNodeStub) NetVersion(p0 context.Context) (string, error) {
 	return "", ErrNotSupported
 }
 
+func (s *FullNodeStruct) NodeAPISpec(p0 context.Context) (*APISpec, error) {
+	if s.Internal.NodeAPISpec == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.NodeAPISpec(p0)
+}
+
+func (s *FullNodeStub) NodeAPISpec(p0 context.Context) (*APISpec, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) NodeMaintenanceOverride(p0 context.Context, p1 *MaintenanceOverride) error {
+	if s.Internal.NodeMaintenanceOverride == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.NodeMaintenanceOverride(p0, p1)
+}
+
+func (s *FullNodeStub) NodeMaintenanceOverride(p0 context.Context, p1 *MaintenanceOverride) error {
+	return ErrNotSupported
+}
+
+func (s *FullNodeStruct) NodeMaintenanceStatus(p0 context.Context) (*MaintenanceStatus, error) {
+	if s.Internal.NodeMaintenanceStatus == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.NodeMaintenanceStatus(p0)
+}
+
+func (s *FullNodeStub) NodeMaintenanceStatus(p0 context.Context) (*MaintenanceStatus, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) NodeStatus(p0 context.Context, p1 bool) (NodeStatus, error) {
 	if s.Internal.NodeStatus == nil {
 		return *new(NodeStatus), ErrNotSupported
@@ -3328,6 +3990,17 @@ func (s *FullNodeStub) StateEncodeParams(p0 context.Context, p1 cid.Cid, p2 abi.
 	return *new([]byte), ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateGasStats(p0 context.Context, p1 abi.ChainEpoch) (*GasStats, error) {
+	if s.Internal.StateGasStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateGasStats(p0, p1)
+}
+
+func (s *FullNodeStub) StateGasStats(p0 context.Context, p1 abi.ChainEpoch) (*GasStats, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateGetActor(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*types.Actor, error) {
 	if s.Internal.StateGetActor == nil {
 		return nil, ErrNotSupported
@@ -3416,6 +4089,17 @@ func (s *FullNodeStub) StateGetNetworkParams(p0 context.Context) (*NetworkParams
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateGetProof(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) {
+	if s.Internal.StateGetProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateGetProof(p0, p1, p2)
+}
+
+func (s *FullNodeStub) StateGetProof(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateGetRandomnessFromBeacon(p0 context.Context, p1 crypto.DomainSeparationTag, p2 abi.ChainEpoch, p3 []byte, p4 types.TipSetKey) (abi.Randomness, error) {
 	if s.Internal.StateGetRandomnessFromBeacon == nil {
 		return *new(abi.Randomness), ErrNotSupported
@@ -3438,15 +4122,48 @@ func (s *FullNodeStub) StateGetRandomnessFromTickets(p0 context.Context, p1 cryp
 	return *new(abi.Randomness), ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateGetRandomnessWithProof(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) {
+	if s.Internal.StateGetRandomnessWithProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateGetRandomnessWithProof(p0, p1, p2, p3, p4, p5)
+}
+
+func (s *FullNodeStub) StateGetRandomnessWithProof(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) StateInstalledActors(p0 context.Context, p1 types.TipSetKey) ([]cid.Cid, error) {
+	if s.Internal.StateInstalledActors == nil {
+		return *new([]cid.Cid), ErrNotSupported
+	}
+	return s.Internal.StateInstalledActors(p0, p1)
+}
+
+func (s *FullNodeStub) StateInstalledActors(p0 context.Context, p1 types.TipSetKey) ([]cid.Cid, error) {
+	return *new([]cid.Cid), ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateListActors(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) {
 	if s.Internal.StateListActors == nil {
 		return *new([]address.Address), ErrNotSupported
 	}
-	return s.Internal.StateListActors(p0, p1)
+	return s.Internal.StateListActors(p0, p1)
+}
+
+func (s *FullNodeStub) StateListActors(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) {
+	return *new([]address.Address), ErrNotSupported
+}
+
+func (s *FullNodeStruct) StateListAddressMessages(p0 context.Context, p1 address.Address, p2 *AddressMessagesQuery) (*AddressMessages, error) {
+	if s.Internal.StateListAddressMessages == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateListAddressMessages(p0, p1, p2)
 }
 
-func (s *FullNodeStub) StateListActors(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) {
-	return *new([]address.Address), ErrNotSupported
+func (s *FullNodeStub) StateListAddressMessages(p0 context.Context, p1 address.Address, p2 *AddressMessagesQuery) (*AddressMessages, error) {
+	return nil, ErrNotSupported
 }
 
 func (s *FullNodeStruct) StateListMessages(p0 context.Context, p1 *MessageMatch, p2 types.TipSetKey, p3 abi.ChainEpoch) ([]cid.Cid, error) {
@@ -3570,6 +4287,28 @@ func (s *FullNodeStub) StateMinerAvailableBalance(p0 context.Context, p1 address
 	return *new(types.BigInt), ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateMinerCheckBeneficiaryChange(p0 context.Context, p1 address.Address, p2 miner.ChangeBeneficiaryParams, p3 types.TipSetKey) (*AddressChangeCheck, error) {
+	if s.Internal.StateMinerCheckBeneficiaryChange == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateMinerCheckBeneficiaryChange(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStub) StateMinerCheckBeneficiaryChange(p0 context.Context, p1 address.Address, p2 miner.ChangeBeneficiaryParams, p3 types.TipSetKey) (*AddressChangeCheck, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) StateMinerCheckOwnerChange(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.TipSetKey) (*AddressChangeCheck, error) {
+	if s.Internal.StateMinerCheckOwnerChange == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateMinerCheckOwnerChange(p0, p1, p2, p3)
+}
+
+func (s *FullNodeStub) StateMinerCheckOwnerChange(p0 context.Context, p1 address.Address, p2 address.Address, p3 types.TipSetKey) (*AddressChangeCheck, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateMinerDeadlines(p0 context.Context, p1 address.Address, p2 types.TipSetKey) ([]Deadline, error) {
 	if s.Internal.StateMinerDeadlines == nil {
 		return *new([]Deadline), ErrNotSupported
@@ -3625,6 +4364,17 @@ func (s *FullNodeStub) StateMinerPartitions(p0 context.Context, p1 address.Addre
 	return *new([]Partition), ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateMinerPendingChanges(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerPendingChanges, error) {
+	if s.Internal.StateMinerPendingChanges == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateMinerPendingChanges(p0, p1, p2)
+}
+
+func (s *FullNodeStub) StateMinerPendingChanges(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerPendingChanges, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateMinerPower(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*MinerPower, error) {
 	if s.Internal.StateMinerPower == nil {
 		return nil, ErrNotSupported
@@ -3658,6 +4408,17 @@ func (s *FullNodeStub) StateMinerProvingDeadline(p0 context.Context, p1 address.
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateMinerProvingSchedule(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ProvingSchedule, error) {
+	if s.Internal.StateMinerProvingSchedule == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateMinerProvingSchedule(p0, p1, p2)
+}
+
+func (s *FullNodeStub) StateMinerProvingSchedule(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ProvingSchedule, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateMinerRecoveries(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (bitfield.BitField, error) {
 	if s.Internal.StateMinerRecoveries == nil {
 		return *new(bitfield.BitField), ErrNotSupported
@@ -3724,6 +4485,28 @@ func (s *FullNodeStub) StateNetworkVersion(p0 context.Context, p1 types.TipSetKe
 	return *new(apitypes.NetworkVersion), ErrNotSupported
 }
 
+func (s *FullNodeStruct) StatePatch(p0 context.Context, p1 []ActorPatch) (*types.TipSet, error) {
+	if s.Internal.StatePatch == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StatePatch(p0, p1)
+}
+
+func (s *FullNodeStub) StatePatch(p0 context.Context, p1 []ActorPatch) (*types.TipSet, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *FullNodeStruct) StatePledgeForPowerBatch(p0 context.Context, p1 []ProspectiveSector, p2 types.TipSetKey) (*PledgeRequirements, error) {
+	if s.Internal.StatePledgeForPowerBatch == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StatePledgeForPowerBatch(p0, p1, p2)
+}
+
+func (s *FullNodeStub) StatePledgeForPowerBatch(p0 context.Context, p1 []ProspectiveSector, p2 types.TipSetKey) (*PledgeRequirements, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateReadState(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorState, error) {
 	if s.Internal.StateReadState == nil {
 		return nil, ErrNotSupported
@@ -3746,6 +4529,17 @@ func (s *FullNodeStub) StateReplay(p0 context.Context, p1 types.TipSetKey, p2 ci
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateReplayCached(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid) (*InvocResult, error) {
+	if s.Internal.StateReplayCached == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateReplayCached(p0, p1, p2)
+}
+
+func (s *FullNodeStub) StateReplayCached(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid) (*InvocResult, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateSearchMsg(p0 context.Context, p1 types.TipSetKey, p2 cid.Cid, p3 abi.ChainEpoch, p4 bool) (*MsgLookup, error) {
 	if s.Internal.StateSearchMsg == nil {
 		return nil, ErrNotSupported
@@ -3801,6 +4595,17 @@ func (s *FullNodeStub) StateSectorPreCommitInfo(p0 context.Context, p1 address.A
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateUpgradeSchedule(p0 context.Context) ([]UpgradeInfo, error) {
+	if s.Internal.StateUpgradeSchedule == nil {
+		return *new([]UpgradeInfo), ErrNotSupported
+	}
+	return s.Internal.StateUpgradeSchedule(p0)
+}
+
+func (s *FullNodeStub) StateUpgradeSchedule(p0 context.Context) ([]UpgradeInfo, error) {
+	return *new([]UpgradeInfo), ErrNotSupported
+}
+
 func (s *FullNodeStruct) StateVMCirculatingSupplyInternal(p0 context.Context, p1 types.TipSetKey) (CirculatingSupply, error) {
 	if s.Internal.StateVMCirculatingSupplyInternal == nil {
 		return *new(CirculatingSupply), ErrNotSupported
@@ -3856,6 +4661,17 @@ func (s *FullNodeStub) StateWaitMsg(p0 context.Context, p1 cid.Cid, p2 uint64, p
 	return nil, ErrNotSupported
 }
 
+func (s *FullNodeStruct) StateWatchBalances(p0 context.Context, p1 []address.Address) (<-chan []*BalanceChange, error) {
+	if s.Internal.StateWatchBalances == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateWatchBalances(p0, p1)
+}
+
+func (s *FullNodeStub) StateWatchBalances(p0 context.Context, p1 []address.Address) (<-chan []*BalanceChange, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *FullNodeStruct) SyncCheckBad(p0 context.Context, p1 cid.Cid) (string, error) {
 	if s.Internal.SyncCheckBad == nil {
 		return "", ErrNotSupported
@@ -3955,6 +4771,17 @@ func (s *FullNodeStub) SyncValidateTipset(p0 context.Context, p1 types.TipSetKey
 	return false, ErrNotSupported
 }
 
+func (s *FullNodeStruct) WalletApprove(p0 context.Context, p1 uuid.UUID, p2 bool) error {
+	if s.Internal.WalletApprove == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.WalletApprove(p0, p1, p2)
+}
+
+func (s *FullNodeStub) WalletApprove(p0 context.Context, p1 uuid.UUID, p2 bool) error {
+	return ErrNotSupported
+}
+
 func (s *FullNodeStruct) WalletBalance(p0 context.Context, p1 address.Address) (types.BigInt, error) {
 	if s.Internal.WalletBalance == nil {
 		return *new(types.BigInt), ErrNotSupported
@@ -4043,6 +4870,17 @@ func (s *FullNodeStub) WalletNew(p0 context.Context, p1 types.KeyType) (address.
 	return *new(address.Address), ErrNotSupported
 }
 
+func (s *FullNodeStruct) WalletPendingApprovals(p0 context.Context) ([]WalletApproval, error) {
+	if s.Internal.WalletPendingApprovals == nil {
+		return *new([]WalletApproval), ErrNotSupported
+	}
+	return s.Internal.WalletPendingApprovals(p0)
+}
+
+func (s *FullNodeStub) WalletPendingApprovals(p0 context.Context) ([]WalletApproval, error) {
+	return *new([]WalletApproval), ErrNotSupported
+}
+
 func (s *FullNodeStruct) WalletSetDefault(p0 context.Context, p1 address.Address) error {
 	if s.Internal.WalletSetDefault == nil {
 		return ErrNotSupported
@@ -4241,6 +5079,17 @@ func (s *GatewayStub) ChainNotify(p0 context.Context) (<-chan []*HeadChange, err
 	return nil, ErrNotSupported
 }
 
+func (s *GatewayStruct) ChainNotifyAtConfidence(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) {
+	if s.Internal.ChainNotifyAtConfidence == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ChainNotifyAtConfidence(p0, p1)
+}
+
+func (s *GatewayStub) ChainNotifyAtConfidence(p0 context.Context, p1 abi.ChainEpoch) (<-chan []*HeadChange, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) ChainPutObj(p0 context.Context, p1 blocks.Block) error {
 	if s.Internal.ChainPutObj == nil {
 		return ErrNotSupported
@@ -4285,6 +5134,17 @@ func (s *GatewayStub) EthAccounts(p0 context.Context) ([]ethtypes.EthAddress, er
 	return *new([]ethtypes.EthAddress), ErrNotSupported
 }
 
+func (s *GatewayStruct) EthBatchStateRead(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) {
+	if s.Internal.EthBatchStateRead == nil {
+		return *new([]EthStateReadResult), ErrNotSupported
+	}
+	return s.Internal.EthBatchStateRead(p0, p1, p2)
+}
+
+func (s *GatewayStub) EthBatchStateRead(p0 context.Context, p1 []EthStateRead, p2 string) ([]EthStateReadResult, error) {
+	return *new([]EthStateReadResult), ErrNotSupported
+}
+
 func (s *GatewayStruct) EthBlockNumber(p0 context.Context) (ethtypes.EthUint64, error) {
 	if s.Internal.EthBlockNumber == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -4307,6 +5167,17 @@ func (s *GatewayStub) EthCall(p0 context.Context, p1 ethtypes.EthCall, p2 string
 	return *new(ethtypes.EthBytes), ErrNotSupported
 }
 
+func (s *GatewayStruct) EthCallBundle(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) {
+	if s.Internal.EthCallBundle == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthCallBundle(p0, p1, p2, p3)
+}
+
+func (s *GatewayStub) EthCallBundle(p0 context.Context, p1 []ethtypes.EthCall, p2 string, p3 []EthAccountOverride) (*EthBundleResult, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) EthChainId(p0 context.Context) (ethtypes.EthUint64, error) {
 	if s.Internal.EthChainId == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -4318,6 +5189,17 @@ func (s *GatewayStub) EthChainId(p0 context.Context) (ethtypes.EthUint64, error)
 	return *new(ethtypes.EthUint64), ErrNotSupported
 }
 
+func (s *GatewayStruct) EthDecodeRawTransaction(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) {
+	if s.Internal.EthDecodeRawTransaction == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthDecodeRawTransaction(p0, p1)
+}
+
+func (s *GatewayStub) EthDecodeRawTransaction(p0 context.Context, p1 ethtypes.EthBytes) (*EthRawTxDecoding, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) EthEstimateGas(p0 context.Context, p1 ethtypes.EthCall) (ethtypes.EthUint64, error) {
 	if s.Internal.EthEstimateGas == nil {
 		return *new(ethtypes.EthUint64), ErrNotSupported
@@ -4538,6 +5420,17 @@ func (s *GatewayStub) EthGetTransactionReceiptLimited(p0 context.Context, p1 eth
 	return nil, ErrNotSupported
 }
 
+func (s *GatewayStruct) EthGetTransactionReceiptProof(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) {
+	if s.Internal.EthGetTransactionReceiptProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthGetTransactionReceiptProof(p0, p1, p2)
+}
+
+func (s *GatewayStub) EthGetTransactionReceiptProof(p0 context.Context, p1 ethtypes.EthHash, p2 string) (*EthReceiptProof, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) EthMaxPriorityFeePerGas(p0 context.Context) (ethtypes.EthBigInt, error) {
 	if s.Internal.EthMaxPriorityFeePerGas == nil {
 		return *new(ethtypes.EthBigInt), ErrNotSupported
@@ -4615,6 +5508,17 @@ func (s *GatewayStub) EthSubscribe(p0 context.Context, p1 jsonrpc.RawParams) (et
 	return *new(ethtypes.EthSubscriptionID), ErrNotSupported
 }
 
+func (s *GatewayStruct) EthSubscribeStorageSlots(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	if s.Internal.EthSubscribeStorageSlots == nil {
+		return *new(ethtypes.EthSubscriptionID), ErrNotSupported
+	}
+	return s.Internal.EthSubscribeStorageSlots(p0, p1)
+}
+
+func (s *GatewayStub) EthSubscribeStorageSlots(p0 context.Context, p1 jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	return *new(ethtypes.EthSubscriptionID), ErrNotSupported
+}
+
 func (s *GatewayStruct) EthSyncing(p0 context.Context) (ethtypes.EthSyncingResult, error) {
 	if s.Internal.EthSyncing == nil {
 		return *new(ethtypes.EthSyncingResult), ErrNotSupported
@@ -4626,6 +5530,50 @@ func (s *GatewayStub) EthSyncing(p0 context.Context) (ethtypes.EthSyncingResult,
 	return *new(ethtypes.EthSyncingResult), ErrNotSupported
 }
 
+func (s *GatewayStruct) EthTraceTransactionGasProfile(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) {
+	if s.Internal.EthTraceTransactionGasProfile == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.EthTraceTransactionGasProfile(p0, p1)
+}
+
+func (s *GatewayStub) EthTraceTransactionGasProfile(p0 context.Context, p1 ethtypes.EthHash) (*EthGasProfile, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *GatewayStruct) EthTxPoolContent(p0 context.Context) (EthTxPoolContent, error) {
+	if s.Internal.EthTxPoolContent == nil {
+		return *new(EthTxPoolContent), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolContent(p0)
+}
+
+func (s *GatewayStub) EthTxPoolContent(p0 context.Context) (EthTxPoolContent, error) {
+	return *new(EthTxPoolContent), ErrNotSupported
+}
+
+func (s *GatewayStruct) EthTxPoolInspect(p0 context.Context) (EthTxPoolInspect, error) {
+	if s.Internal.EthTxPoolInspect == nil {
+		return *new(EthTxPoolInspect), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolInspect(p0)
+}
+
+func (s *GatewayStub) EthTxPoolInspect(p0 context.Context) (EthTxPoolInspect, error) {
+	return *new(EthTxPoolInspect), ErrNotSupported
+}
+
+func (s *GatewayStruct) EthTxPoolStatus(p0 context.Context) (EthTxPoolStatus, error) {
+	if s.Internal.EthTxPoolStatus == nil {
+		return *new(EthTxPoolStatus), ErrNotSupported
+	}
+	return s.Internal.EthTxPoolStatus(p0)
+}
+
+func (s *GatewayStub) EthTxPoolStatus(p0 context.Context) (EthTxPoolStatus, error) {
+	return *new(EthTxPoolStatus), ErrNotSupported
+}
+
 func (s *GatewayStruct) EthUninstallFilter(p0 context.Context, p1 ethtypes.EthFilterID) (bool, error) {
 	if s.Internal.EthUninstallFilter == nil {
 		return false, ErrNotSupported
@@ -4670,6 +5618,17 @@ func (s *GatewayStub) GasEstimateMessageGas(p0 context.Context, p1 *types.Messag
 	return nil, ErrNotSupported
 }
 
+func (s *GatewayStruct) GatewayUsage(p0 context.Context) (*GatewayUsage, error) {
+	if s.Internal.GatewayUsage == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.GatewayUsage(p0)
+}
+
+func (s *GatewayStub) GatewayUsage(p0 context.Context) (*GatewayUsage, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) MpoolGetNonce(p0 context.Context, p1 address.Address) (uint64, error) {
 	if s.Internal.MpoolGetNonce == nil {
 		return 0, ErrNotSupported
@@ -4813,6 +5772,28 @@ func (s *GatewayStub) StateGetActor(p0 context.Context, p1 address.Address, p2 t
 	return nil, ErrNotSupported
 }
 
+func (s *GatewayStruct) StateGetProof(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) {
+	if s.Internal.StateGetProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateGetProof(p0, p1, p2)
+}
+
+func (s *GatewayStub) StateGetProof(p0 context.Context, p1 address.Address, p2 types.TipSetKey) (*ActorProof, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *GatewayStruct) StateGetRandomnessWithProof(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) {
+	if s.Internal.StateGetRandomnessWithProof == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.StateGetRandomnessWithProof(p0, p1, p2, p3, p4, p5)
+}
+
+func (s *GatewayStub) StateGetRandomnessWithProof(p0 context.Context, p1 RandomnessSource, p2 crypto.DomainSeparationTag, p3 abi.ChainEpoch, p4 []byte, p5 types.TipSetKey) (*RandomnessProof, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *GatewayStruct) StateListMiners(p0 context.Context, p1 types.TipSetKey) ([]address.Address, error) {
 	if s.Internal.StateListMiners == nil {
 		return *new([]address.Address), ErrNotSupported
@@ -5352,6 +6333,17 @@ func (s *StorageMinerStub) ActorWithdrawBalance(p0 context.Context, p1 abi.Token
 	return *new(cid.Cid), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) BackupCreate(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) {
+	if s.Internal.BackupCreate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.BackupCreate(p0, p1, p2)
+}
+
+func (s *StorageMinerStub) BackupCreate(p0 context.Context, p1 string, p2 BackupOptions) (*BackupInfo, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *StorageMinerStruct) BeneficiaryWithdrawBalance(p0 context.Context, p1 abi.TokenAmount) (cid.Cid, error) {
 	if s.Internal.BeneficiaryWithdrawBalance == nil {
 		return *new(cid.Cid), ErrNotSupported
@@ -5561,6 +6553,17 @@ func (s *StorageMinerStub) DealsConsiderVerifiedStorageDeals(p0 context.Context)
 	return false, ErrNotSupported
 }
 
+func (s *StorageMinerStruct) DealsFilterDecisions(p0 context.Context, p1 int) ([]DealFilterDecision, error) {
+	if s.Internal.DealsFilterDecisions == nil {
+		return *new([]DealFilterDecision), ErrNotSupported
+	}
+	return s.Internal.DealsFilterDecisions(p0, p1)
+}
+
+func (s *StorageMinerStub) DealsFilterDecisions(p0 context.Context, p1 int) ([]DealFilterDecision, error) {
+	return *new([]DealFilterDecision), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) DealsImportData(p0 context.Context, p1 cid.Cid, p2 string) error {
 	if s.Internal.DealsImportData == nil {
 		return ErrNotSupported
@@ -5913,6 +6916,17 @@ func (s *StorageMinerStub) PiecesGetPieceInfo(p0 context.Context, p1 cid.Cid) (*
 	return nil, ErrNotSupported
 }
 
+func (s *StorageMinerStruct) PiecesHotness(p0 context.Context) ([]PieceHotness, error) {
+	if s.Internal.PiecesHotness == nil {
+		return *new([]PieceHotness), ErrNotSupported
+	}
+	return s.Internal.PiecesHotness(p0)
+}
+
+func (s *StorageMinerStub) PiecesHotness(p0 context.Context) ([]PieceHotness, error) {
+	return *new([]PieceHotness), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) PiecesListCidInfos(p0 context.Context) ([]cid.Cid, error) {
 	if s.Internal.PiecesListCidInfos == nil {
 		return *new([]cid.Cid), ErrNotSupported
@@ -5946,6 +6960,39 @@ func (s *StorageMinerStub) PledgeSector(p0 context.Context) (abi.SectorID, error
 	return *new(abi.SectorID), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) ProofParamsFetch(p0 context.Context, p1 []abi.SectorSize, p2 string) error {
+	if s.Internal.ProofParamsFetch == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.ProofParamsFetch(p0, p1, p2)
+}
+
+func (s *StorageMinerStub) ProofParamsFetch(p0 context.Context, p1 []abi.SectorSize, p2 string) error {
+	return ErrNotSupported
+}
+
+func (s *StorageMinerStruct) ProofParamsStatus(p0 context.Context, p1 []abi.SectorSize, p2 bool) (ProofParamsStatus, error) {
+	if s.Internal.ProofParamsStatus == nil {
+		return *new(ProofParamsStatus), ErrNotSupported
+	}
+	return s.Internal.ProofParamsStatus(p0, p1, p2)
+}
+
+func (s *StorageMinerStub) ProofParamsStatus(p0 context.Context, p1 []abi.SectorSize, p2 bool) (ProofParamsStatus, error) {
+	return *new(ProofParamsStatus), ErrNotSupported
+}
+
+func (s *StorageMinerStruct) ProvingSimulate(p0 context.Context, p1 ProvingSimulateParams) (*ProvingSimulation, error) {
+	if s.Internal.ProvingSimulate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.ProvingSimulate(p0, p1)
+}
+
+func (s *StorageMinerStub) ProvingSimulate(p0 context.Context, p1 ProvingSimulateParams) (*ProvingSimulation, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *StorageMinerStruct) RecoverFault(p0 context.Context, p1 []abi.SectorNumber) ([]cid.Cid, error) {
 	if s.Internal.RecoverFault == nil {
 		return *new([]cid.Cid), ErrNotSupported
@@ -6177,6 +7224,28 @@ func (s *StorageMinerStub) SealingAbort(p0 context.Context, p1 storiface.CallID)
 	return ErrNotSupported
 }
 
+func (s *StorageMinerStruct) SealingCostEstimate(p0 context.Context, p1 SealingCostEstimateParams) (*SealingCostEstimate, error) {
+	if s.Internal.SealingCostEstimate == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SealingCostEstimate(p0, p1)
+}
+
+func (s *StorageMinerStub) SealingCostEstimate(p0 context.Context, p1 SealingCostEstimateParams) (*SealingCostEstimate, error) {
+	return nil, ErrNotSupported
+}
+
+func (s *StorageMinerStruct) SealingPipelineStats(p0 context.Context, p1 int) (*SealingPipelineStats, error) {
+	if s.Internal.SealingPipelineStats == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SealingPipelineStats(p0, p1)
+}
+
+func (s *StorageMinerStub) SealingPipelineStats(p0 context.Context, p1 int) (*SealingPipelineStats, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *StorageMinerStruct) SealingRemoveRequest(p0 context.Context, p1 uuid.UUID) error {
 	if s.Internal.SealingRemoveRequest == nil {
 		return ErrNotSupported
@@ -6485,6 +7554,17 @@ func (s *StorageMinerStub) SectorsListInStates(p0 context.Context, p1 []SectorSt
 	return *new([]abi.SectorNumber), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) SectorsReconcileUnsealed(p0 context.Context, p1 []abi.SectorNumber, p2 bool) ([]UnsealedReconcileAction, error) {
+	if s.Internal.SectorsReconcileUnsealed == nil {
+		return *new([]UnsealedReconcileAction), ErrNotSupported
+	}
+	return s.Internal.SectorsReconcileUnsealed(p0, p1, p2)
+}
+
+func (s *StorageMinerStub) SectorsReconcileUnsealed(p0 context.Context, p1 []abi.SectorNumber, p2 bool) ([]UnsealedReconcileAction, error) {
+	return *new([]UnsealedReconcileAction), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) SectorsRefs(p0 context.Context) (map[string][]SealedRef, error) {
 	if s.Internal.SectorsRefs == nil {
 		return *new(map[string][]SealedRef), ErrNotSupported
@@ -6518,6 +7598,17 @@ func (s *StorageMinerStub) SectorsSummary(p0 context.Context) (map[SectorState]i
 	return *new(map[SectorState]int), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) SectorsSummaryStream(p0 context.Context) (<-chan []byte, error) {
+	if s.Internal.SectorsSummaryStream == nil {
+		return nil, ErrNotSupported
+	}
+	return s.Internal.SectorsSummaryStream(p0)
+}
+
+func (s *StorageMinerStub) SectorsSummaryStream(p0 context.Context) (<-chan []byte, error) {
+	return nil, ErrNotSupported
+}
+
 func (s *StorageMinerStruct) SectorsUnsealPiece(p0 context.Context, p1 storiface.SectorRef, p2 storiface.UnpaddedByteIndex, p3 abi.UnpaddedPieceSize, p4 abi.SealRandomness, p5 *cid.Cid) error {
 	if s.Internal.SectorsUnsealPiece == nil {
 		return ErrNotSupported
@@ -6584,6 +7675,17 @@ func (s *StorageMinerStub) StorageBestAlloc(p0 context.Context, p1 storiface.Sec
 	return *new([]storiface.StorageInfo), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) StorageBestAllocSector(p0 context.Context, p1 storiface.SectorFileType, p2 abi.SectorSize, p3 storiface.PathType, p4 abi.SectorID) ([]storiface.StorageInfo, error) {
+	if s.Internal.StorageBestAllocSector == nil {
+		return *new([]storiface.StorageInfo), ErrNotSupported
+	}
+	return s.Internal.StorageBestAllocSector(p0, p1, p2, p3, p4)
+}
+
+func (s *StorageMinerStub) StorageBestAllocSector(p0 context.Context, p1 storiface.SectorFileType, p2 abi.SectorSize, p3 storiface.PathType, p4 abi.SectorID) ([]storiface.StorageInfo, error) {
+	return *new([]storiface.StorageInfo), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) StorageDeclareSector(p0 context.Context, p1 storiface.ID, p2 abi.SectorID, p3 storiface.SectorFileType, p4 bool) error {
 	if s.Internal.StorageDeclareSector == nil {
 		return ErrNotSupported
@@ -6716,6 +7818,17 @@ func (s *StorageMinerStub) StorageReportHealth(p0 context.Context, p1 storiface.
 	return ErrNotSupported
 }
 
+func (s *StorageMinerStruct) StorageSetSectorPlacement(p0 context.Context, p1 abi.SectorID, p2 []storiface.Group) error {
+	if s.Internal.StorageSetSectorPlacement == nil {
+		return ErrNotSupported
+	}
+	return s.Internal.StorageSetSectorPlacement(p0, p1, p2)
+}
+
+func (s *StorageMinerStub) StorageSetSectorPlacement(p0 context.Context, p1 abi.SectorID, p2 []storiface.Group) error {
+	return ErrNotSupported
+}
+
 func (s *StorageMinerStruct) StorageStat(p0 context.Context, p1 storiface.ID) (fsutil.FsStat, error) {
 	if s.Internal.StorageStat == nil {
 		return *new(fsutil.FsStat), ErrNotSupported
@@ -6749,6 +7862,17 @@ func (s *StorageMinerStub) WorkerConnect(p0 context.Context, p1 string) error {
 	return ErrNotSupported
 }
 
+func (s *StorageMinerStruct) WorkerGPUStats(p0 context.Context) ([]storiface.WorkerGPUStats, error) {
+	if s.Internal.WorkerGPUStats == nil {
+		return *new([]storiface.WorkerGPUStats), ErrNotSupported
+	}
+	return s.Internal.WorkerGPUStats(p0)
+}
+
+func (s *StorageMinerStub) WorkerGPUStats(p0 context.Context) ([]storiface.WorkerGPUStats, error) {
+	return *new([]storiface.WorkerGPUStats), ErrNotSupported
+}
+
 func (s *StorageMinerStruct) WorkerJobs(p0 context.Context) (map[uuid.UUID][]storiface.WorkerJob, error) {
 	if s.Internal.WorkerJobs == nil {
 		return *new(map[uuid.UUID][]storiface.WorkerJob), ErrNotSupported
@@ -6771,6 +7895,28 @@ func (s *StorageMinerStub) WorkerStats(p0 context.Context) (map[uuid.UUID]storif
 	return *new(map[uuid.UUID]storiface.WorkerStats), ErrNotSupported
 }
 
+func (s *StorageMinerStruct) WorkerTaskHistory(p0 context.Context, p1 storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) {
+	if s.Internal.WorkerTaskHistory == nil {
+		return *new([]storiface.TaskHistoryEntry), ErrNotSupported
+	}
+	return s.Internal.WorkerTaskHistory(p0, p1)
+}
+
+func (s *StorageMinerStub) WorkerTaskHistory(p0 context.Context, p1 storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) {
+	return *new([]storiface.TaskHistoryEntry), ErrNotSupported
+}
+
+func (s *StorageMinerStruct) WorkerTaskStats(p0 context.Context, p1 time.Time) ([]storiface.WorkerTaskStats, error) {
+	if s.Internal.WorkerTaskStats == nil {
+		return *new([]storiface.WorkerTaskStats), ErrNotSupported
+	}
+	return s.Internal.WorkerTaskStats(p0, p1)
+}
+
+func (s *StorageMinerStub) WorkerTaskStats(p0 context.Context, p1 time.Time) ([]storiface.WorkerTaskStats, error) {
+	return *new([]storiface.WorkerTaskStats), ErrNotSupported
+}
+
 func (s *WalletStruct) WalletDelete(p0 context.Context, p1 address.Address) error {
 	if s.Internal.WalletDelete == nil {
 		return ErrNotSupported
@@ -6925,6 +8071,17 @@ func (s *WorkerStub) FinalizeSector(p0 context.Context, p1 storiface.SectorRef)
 	return *new(storiface.CallID), ErrNotSupported
 }
 
+func (s *WorkerStruct) GPUStats(p0 context.Context) ([]storiface.GPUStats, error) {
+	if s.Internal.GPUStats == nil {
+		return *new([]storiface.GPUStats), ErrNotSupported
+	}
+	return s.Internal.GPUStats(p0)
+}
+
+func (s *WorkerStub) GPUStats(p0 context.Context) ([]storiface.GPUStats, error) {
+	return *new([]storiface.GPUStats), ErrNotSupported
+}
+
 func (s *WorkerStruct) GenerateSectorKeyFromData(p0 context.Context, p1 storiface.SectorRef, p2 cid.Cid) (storiface.CallID, error) {
 	if s.Internal.GenerateSectorKeyFromData == nil {
 		return *new(storiface.CallID), ErrNotSupported
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"reflect"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+)
+
+// ReadOnlyMutatingMethods lists methods which only require read permission,
+// but still change node state or publish messages to the network. These are
+// disabled in read-only mode along with all methods requiring more than read
+// permission.
+var ReadOnlyMutatingMethods = map[string]struct{}{
+	"EthSendRawTransaction": {},
+}
+
+func readOnlyProxies(in, out interface{}) {
+	ra := reflect.ValueOf(in)
+
+	for _, o := range GetInternalStructs(out) {
+		rint := reflect.ValueOf(o).Elem()
+
+		for f := 0; f < rint.NumField(); f++ {
+			field := rint.Type().Field(f)
+			_, mutating := ReadOnlyMutatingMethods[field.Name]
+
+			if auth.Permission(field.Tag.Get("perm")) == PermRead && !mutating {
+				rint.Field(f).Set(ra.MethodByName(field.Name))
+				continue
+			}
+
+			ftyp := field.Type
+			rint.Field(f).Set(reflect.MakeFunc(ftyp, func(args []reflect.Value) []reflect.Value {
+				var err error = &ErrReadOnly{}
+				rerr := reflect.ValueOf(&err).Elem()
+
+				if ftyp.NumOut() == 2 {
+					return []reflect.Value{reflect.Zero(ftyp.Out(0)), rerr}
+				}
+				return []reflect.Value{rerr}
+			}))
+		}
+	}
+}
+
+// ReadOnlyFullAPI returns a FullNode API which only allows calls to methods
+// which don't change the state of the node, regardless of the permissions of
+// the caller. Disabled methods return ErrReadOnly.
+func ReadOnlyFullAPI(a FullNode) FullNode {
+	var out FullNodeStruct
+	readOnlyProxies(a, &out)
+	return &out
+}
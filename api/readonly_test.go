@@ -0,0 +1,51 @@
+// stm: #unit
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestReadOnlyFullAPI(t *testing.T) {
+	ctx := context.Background()
+
+	var in FullNodeStruct
+	in.Internal.ChainHead = func(context.Context) (*types.TipSet, error) {
+		return nil, nil
+	}
+	in.Internal.WalletBalance = func(context.Context, address.Address) (types.BigInt, error) {
+		return types.NewInt(1), nil
+	}
+
+	ro := ReadOnlyFullAPI(&in)
+
+	_, err := ro.ChainHead(ctx)
+	require.NoError(t, err)
+
+	bal, err := ro.WalletBalance(ctx, address.Undef)
+	require.NoError(t, err)
+	require.Equal(t, types.NewInt(1), bal)
+
+	// write, sign and admin methods are disabled
+	_, err = ro.MpoolPush(ctx, &types.SignedMessage{})
+	requireReadOnly(t, err)
+	_, err = ro.WalletSign(ctx, address.Undef, nil)
+	requireReadOnly(t, err)
+	err = ro.Shutdown(ctx)
+	requireReadOnly(t, err)
+
+	// read methods which change state are disabled too
+	_, err = ro.EthSendRawTransaction(ctx, nil)
+	requireReadOnly(t, err)
+}
+
+func requireReadOnly(t *testing.T, err error) {
+	var roErr *ErrReadOnly
+	require.ErrorAs(t, err, &roErr)
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/xerrors"
+)
+
+// MaxSectorRecordSize bounds the length prefix of a single encoded SectorRecord.
+const MaxSectorRecordSize = 1 << 20
+
+// WriteSectorRecord writes the record as CBOR prefixed with its length as an
+// unsigned varint.
+func WriteSectorRecord(w io.Writer, r *SectorRecord) error {
+	var buf bytes.Buffer
+	if err := r.MarshalCBOR(&buf); err != nil {
+		return xerrors.Errorf("marshaling sector record: %w", err)
+	}
+
+	var lbuf [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(lbuf[:], uint64(buf.Len()))
+	if _, err := w.Write(lbuf[:n]); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// ReadSectorRecord reads a record written by WriteSectorRecord, returning
+// io.EOF once the reader is exhausted.
+func ReadSectorRecord(r *bufio.Reader) (*SectorRecord, error) {
+	l, err := binary.ReadUvarint(r)
+	if err != nil {
+		if err == io.EOF {
+			return nil, io.EOF
+		}
+		return nil, xerrors.Errorf("reading record length: %w", err)
+	}
+	if l > MaxSectorRecordSize {
+		return nil, xerrors.Errorf("sector record of %d bytes exceeds the limit of %d", l, MaxSectorRecordSize)
+	}
+
+	var out SectorRecord
+	if err := out.UnmarshalCBOR(io.LimitReader(r, int64(l))); err != nil {
+		return nil, xerrors.Errorf("unmarshaling sector record: %w", err)
+	}
+	return &out, nil
+}
+
+// DecodeSectorRecords decodes a chunk sent by SectorsSummaryStream. It returns
+// an error when the chunk ends the stream with an error record.
+func DecodeSectorRecords(chunk []byte) ([]SectorRecord, error) {
+	r := bufio.NewReader(bytes.NewReader(chunk))
+
+	var out []SectorRecord
+	for {
+		rec, err := ReadSectorRecord(r)
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if rec.Error != "" {
+			return out, xerrors.Errorf("sector stream failed: %s", rec.Error)
+		}
+		out = append(out, *rec)
+	}
+}
@@ -0,0 +1,67 @@
+// stm: #unit
+package api
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+func TestSectorRecordsRoundtrip(t *testing.T) {
+	records := []SectorRecord{
+		{
+			Number:        1,
+			State:         "Proving",
+			SealProof:     abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+			DealIDs:       []abi.DealID{5, 6},
+			OnChain:       true,
+			Activation:    100,
+			Expiration:    600000,
+			InitialPledge: big.NewInt(123456789),
+			Deadline:      12,
+			Partition:     3,
+		},
+		{
+			Number:        2,
+			State:         "PreCommit1",
+			SealProof:     abi.RegisteredSealProof_StackedDrg32GiBV1_1,
+			InitialPledge: big.Zero(),
+		},
+	}
+
+	var buf bytes.Buffer
+	for i := range records {
+		require.NoError(t, WriteSectorRecord(&buf, &records[i]))
+	}
+
+	out, err := DecodeSectorRecords(buf.Bytes())
+	require.NoError(t, err)
+	require.Len(t, out, 2)
+	require.Equal(t, records[0], out[0])
+	require.Equal(t, records[1].Number, out[1].Number)
+	require.Equal(t, records[1].State, out[1].State)
+	require.Empty(t, out[1].DealIDs)
+
+	out, err = DecodeSectorRecords(nil)
+	require.NoError(t, err)
+	require.Empty(t, out)
+
+	// truncated chunks fail to decode
+	_, err = DecodeSectorRecords(buf.Bytes()[:buf.Len()-1])
+	require.Error(t, err)
+}
+
+func TestSectorRecordsError(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, WriteSectorRecord(&buf, &SectorRecord{Number: 1, InitialPledge: big.Zero()}))
+	require.NoError(t, WriteSectorRecord(&buf, &SectorRecord{Error: "getting deadlines: boom"}))
+
+	// the records before the error record are returned with the error
+	out, err := DecodeSectorRecords(buf.Bytes())
+	require.ErrorContains(t, err, "boom")
+	require.Len(t, out, 1)
+}
@@ -19,9 +19,14 @@ import (
 	datatransfer "github.com/filecoin-project/go-data-transfer/v2"
 	"github.com/filecoin-project/go-fil-markets/retrievalmarket"
 	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/crypto"
 
+	apitypes "github.com/filecoin-project/lotus/api/types"
+	"github.com/filecoin-project/lotus/chain/beacon"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
 
@@ -51,6 +56,45 @@ type ObjStat struct {
 	Links uint64
 }
 
+// DagWalkOpts limits the walk of a graph of IPLD objects.
+type DagWalkOpts struct {
+	// MaxDepth is the number of links followed from the root, unlimited when 0
+	MaxDepth int
+	// MaxBlocks is the number of blocks visited, unlimited when 0 except for
+	// ChainFindObjPath, which then visits up to DefaultFindPathMaxBlocks
+	MaxBlocks int
+	// Selector limits the walk to the blocks the selector traverses from the
+	// root; a path selects the whole graph under the object at the path
+	Selector *Selector
+}
+
+// DefaultFindPathMaxBlocks is the number of blocks ChainFindObjPath visits
+// when the options don't set MaxBlocks.
+const DefaultFindPathMaxBlocks = 1_000_000
+
+// DagWalk describes the graph of IPLD objects walked from a root.
+type DagWalk struct {
+	Root   cid.Cid
+	Blocks uint64
+	Size   uint64
+	// Depth is the deepest level of links reached
+	Depth int
+	// Missing is the number of linked blocks missing from the blockstore
+	Missing uint64
+	// Truncated is whether the limits stopped the walk
+	Truncated bool
+	// Codecs breaks the blocks (as Links) and size down by codec
+	Codecs map[string]ObjStat
+}
+
+// DagPathStep is an object on the path between two objects.
+type DagPathStep struct {
+	// Link is the path within the previous object of the link to this one,
+	// empty for the first object
+	Link string
+	Cid  cid.Cid
+}
+
 type PubsubScore struct {
 	ID    peer.ID
 	Score *pubsub.PeerScoreSnapshot
@@ -172,6 +216,7 @@ type NodeStatus struct {
 	SyncStatus  NodeSyncStatus
 	PeerStatus  NodePeerStatus
 	ChainStatus NodeChainStatus
+	Health      NodeHealth
 }
 
 type NodeSyncStatus struct {
@@ -189,6 +234,28 @@ type NodeChainStatus struct {
 	BlocksPerTipsetLastFinality float64
 }
 
+// NodeHealth reports the checks deciding whether the node is ready to serve
+// requests, as served by the /health/readyz endpoint.
+type NodeHealth struct {
+	Ready bool
+	// Failing describes the checks exceeding their configured thresholds
+	Failing []string
+
+	// SyncLag is the number of epochs the chain head is behind the current epoch
+	SyncLag uint64
+	// Peers is the number of connected peers
+	Peers int
+	// PeersError is set when listing the connected peers failed
+	PeersError string `json:",omitempty"`
+	// APILatency is the time it took to load the chain head
+	APILatency time.Duration
+	// DatastoreError is set when reading the metadata datastore failed
+	DatastoreError string `json:",omitempty"`
+	// EventsIndexLag is the number of epochs actor events are processed behind
+	// the chain head, or -1 when events aren't processed
+	EventsIndexLag int64
+}
+
 type CheckStatusCode int
 
 //go:generate go run golang.org/x/tools/cmd/stringer -type=CheckStatusCode -trimprefix=CheckStatus
@@ -304,6 +371,249 @@ type MinerInfo struct {
 	PendingBeneficiaryTerm     *miner.PendingBeneficiaryChange
 }
 
+// MinerPendingChanges is the decoded state of owner, worker and beneficiary
+// changes of a miner actor
+type MinerPendingChanges struct {
+	Epoch abi.ChainEpoch
+
+	Owner        address.Address
+	PendingOwner *address.Address // proposed by the owner, must be confirmed by the new owner
+
+	Worker            address.Address
+	NewWorker         *address.Address
+	WorkerChangeEpoch abi.ChainEpoch // epoch from which the worker change can be confirmed, -1 if none is pending
+
+	Beneficiary        address.Address
+	BeneficiaryTerm    BeneficiaryTermStatus
+	PendingBeneficiary *PendingBeneficiaryStatus
+
+	// Warnings about the current or pending terms which likely need attention
+	Warnings []string
+}
+
+type BeneficiaryTermStatus struct {
+	Quota      abi.TokenAmount
+	UsedQuota  abi.TokenAmount
+	Available  abi.TokenAmount // quota left to withdraw, zero once the term is expired
+	Expiration abi.ChainEpoch
+	Expired    bool
+}
+
+type PendingBeneficiaryStatus struct {
+	NewBeneficiary address.Address
+	NewQuota       abi.TokenAmount
+	NewExpiration  abi.ChainEpoch
+
+	ApprovedByBeneficiary bool
+	ApprovedByNominee     bool
+
+	// Addresses which still have to send a confirmation with the exact same
+	// terms before the change takes effect
+	AwaitingApproval []address.Address
+}
+
+// AddressChangeCheck is the result of checking a proposed miner address
+// change before sending it
+type AddressChangeCheck struct {
+	Errors   []string // the change would be rejected by the actor, or could never take effect
+	Warnings []string // the change would go through, but might not be what's intended
+}
+
+func (c *AddressChangeCheck) OK() bool {
+	return len(c.Errors) == 0
+}
+
+type RandomnessSource string
+
+const (
+	RandomnessFromTickets RandomnessSource = "tickets"
+	RandomnessFromBeacon  RandomnessSource = "beacon"
+)
+
+// RandomnessProof is randomness along with the data it's derived from. The
+// randomness is blake2b-256(personalization as a big endian int64 ||
+// blake2b-256(Base) || epoch as a big endian int64 || entropy), where the base is
+// the VRF proof of the ticket, or the signature of the beacon entry.
+type RandomnessProof struct {
+	Randomness      abi.Randomness
+	Source          RandomnessSource
+	Personalization crypto.DomainSeparationTag
+	Epoch           abi.ChainEpoch
+	Entropy         []byte
+	Base            []byte
+
+	// Tipset the ticket or the beacon entry is taken from
+	TipSet types.TipSetKey
+	Height abi.ChainEpoch
+
+	// For tickets randomness, the block with the smallest ticket of the tipset
+	Block  *cid.Cid
+	Ticket *types.Ticket
+
+	// For beacon randomness, the beacon entry, the previous entry needed to
+	// verify entries of chained drand networks, and the info of the drand chain,
+	// with its public key
+	BeaconEntry     *types.BeaconEntry
+	PrevBeaconEntry *types.BeaconEntry
+	DrandChainInfo  string
+}
+
+// BeaconStatus is the health of a random beacon of the beacon schedule.
+type BeaconStatus struct {
+	// Start is the epoch from which the beacon is used
+	Start abi.ChainEpoch
+	// Active is true for the beacon used at the current head
+	Active bool
+
+	Status beacon.Status
+}
+
+// GasStats are the aggregates of the gas used by executed messages.
+type GasStats struct {
+	// From and To are the heights of the first and last tipset whose receipts are
+	// aggregated
+	From    abi.ChainEpoch
+	To      abi.ChainEpoch
+	Tipsets int
+
+	Messages int64
+	GasUsed  int64
+
+	Methods []ActorMethodGasStats
+}
+
+// ActorMethodGasStats are the aggregates of the gas used by executed messages
+// calling a method of actors of the same code.
+type ActorMethodGasStats struct {
+	// Code is undefined for messages sent to addresses without actors
+	Code   cid.Cid
+	Actor  string
+	Method abi.MethodNum
+
+	Messages   int64
+	Failed     int64
+	GasUsed    int64
+	GasLimit   int64
+	MaxGasUsed int64
+}
+
+// InclusionSpec describes the message MpoolPredictInclusion predicts the
+// inclusion of.
+type InclusionSpec struct {
+	// Message is the CID of a message pending in the message pool
+	Message *cid.Cid
+
+	// GasFeeCap, GasPremium and GasLimit describe a message when Message is nil
+	GasFeeCap  abi.TokenAmount
+	GasPremium abi.TokenAmount
+	GasLimit   int64
+
+	// Epochs is the horizon the probability of inclusion is computed over,
+	// defaults to 10
+	Epochs abi.ChainEpoch
+}
+
+type InclusionPrediction struct {
+	Epochs abi.ChainEpoch
+
+	// BaseFee is the base fee of the next tipset
+	BaseFee abi.TokenAmount
+	// BaseFeeTrend is the average ratio by which the base fee recently changed
+	// per epoch
+	BaseFeeTrend float64
+
+	// EffectivePremium is the premium paid at the current base fee
+	EffectivePremium abi.TokenAmount
+	// MessagesAhead and GasAhead are the pending messages paying a higher
+	// effective premium
+	MessagesAhead int
+	GasAhead      int64
+	// PremiumPercentile is the share of recently included gas paying a lower
+	// premium
+	PremiumPercentile float64
+
+	// EstimatedEpochs is the number of epochs until the message is expected to
+	// be included, given the messages ahead of it
+	EstimatedEpochs abi.ChainEpoch
+	// PricedOutEpochs is the number of epochs after which the projected base fee
+	// exceeds the gas fee cap. It is -1 if the fee cap covers the base fee until
+	// the end of the horizon, 0 if it never does
+	PricedOutEpochs abi.ChainEpoch
+	// Probability is the probability of inclusion within Epochs
+	Probability float64
+
+	// SuggestedGasPremium is the premium needed to be ahead of an epoch worth of
+	// pending messages
+	SuggestedGasPremium abi.TokenAmount
+}
+
+type BaseFeeForecast struct {
+	Head abi.ChainEpoch
+	// GasUsedPerBlock is the average gas limit of the messages included in
+	// recent blocks
+	GasUsedPerBlock int64
+	// PendingGas is the gas limit of the pending messages which can be included
+	// at the current base fee
+	PendingGas int64
+
+	Epochs []BaseFeeForecastEpoch
+
+	// Cheapest is the first epoch with the lowest expected base fee
+	Cheapest        abi.ChainEpoch
+	CheapestBaseFee abi.TokenAmount
+}
+
+type BaseFeeForecastEpoch struct {
+	Epoch   abi.ChainEpoch
+	BaseFee abi.TokenAmount
+	Low     abi.TokenAmount
+	High    abi.TokenAmount
+	// GasUsed is the expected gas limit of the messages included at the epoch
+	GasUsed int64
+}
+
+// AddressMessagesQuery selects the messages returned by StateListAddressMessages.
+type AddressMessagesQuery struct {
+	// FromEpoch and ToEpoch bound the inclusion epochs, a ToEpoch of 0 is
+	// unbounded
+	FromEpoch abi.ChainEpoch
+	ToEpoch   abi.ChainEpoch
+	// Direction selects the messages sent or received by the address, with
+	// "sent" or "received"; both are returned when empty
+	Direction string
+	// Selector only returns the EVM contract invocations calling the function
+	// of this 4 byte selector, e.g. 0xa9059cbb for transfer(address,uint256)
+	Selector ethtypes.EthBytes
+	// To only returns the messages sent to this address, e.g. the calls of the
+	// address to a contract with Direction "sent"
+	To address.Address
+	// Limit is the page size, 100 when 0
+	Limit int
+	// Cursor is the Cursor of the previous page
+	Cursor string
+}
+
+// AddressMessages is a page of messages sent or received by an address.
+type AddressMessages struct {
+	Messages []AddressMessage
+	// Cursor continues the query with the next page, it's empty on the last
+	// page
+	Cursor string
+}
+
+type AddressMessage struct {
+	Message cid.Cid
+	// TipSet is the cid of the key of the tipset including the message
+	TipSet cid.Cid
+	Epoch  abi.ChainEpoch
+	// Sent is true when the address is the sender of the message, and false
+	// when it's the recipient
+	Sent bool
+	// Selector is the selector of the EVM function called by the message,
+	// empty for other messages
+	Selector ethtypes.EthBytes
+}
+
 type NetworkParams struct {
 	NetworkName             dtypes.NetworkName
 	BlockDelaySecs          uint64
@@ -406,3 +716,209 @@ type ChainExportConfig struct {
 	IncludeReceipts   bool
 	IncludeStateRoots bool
 }
+
+type ChainExportStreamParams struct {
+	// NRoots is the number of most recent state trees to include. Ignored when
+	// Tail is set.
+	NRoots abi.ChainEpoch
+	// SkipOldMsgs excludes messages from before the requested roots. Ignored
+	// when Tail is set.
+	SkipOldMsgs bool
+
+	// Tail, when set, switches to an epoch-range export: the header chain back
+	// to genesis, messages from Tail to the head tipset, and the state at Tail
+	// only.
+	Tail types.TipSetKey
+
+	// ChunkSize is the maximum size of the data in each chunk. Defaults to 1MiB,
+	// and is at most 16MiB.
+	ChunkSize int
+
+	// ResumeToken, when set, resumes an export after the chunk in which the
+	// token was returned. The head tipset and all other parameters are taken
+	// from the token.
+	ResumeToken string
+}
+
+type ChainExportChunk struct {
+	Data []byte
+
+	// Offset is the position of Data within the whole CAR stream.
+	Offset uint64
+	// ResumeToken can be passed in ChainExportStreamParams to resume the export
+	// right after this chunk.
+	ResumeToken string
+
+	// Done is set on the last chunk of a successful export.
+	Done bool
+	// Error is set on the last chunk if the export has failed.
+	Error string
+}
+
+type BackupOptions struct {
+	// Sections of the repo to back up or restore: keystore, identity, config,
+	// metadata. All sections are selected when empty.
+	Sections []string
+	// Passphrase encrypting the archive, or decrypting it when restoring.
+	Passphrase string
+}
+
+type BackupInfo struct {
+	Created   time.Time
+	NodeType  string
+	Encrypted bool
+	Sections  []BackupSection
+}
+
+type BackupSection struct {
+	Name    string
+	Entries int
+	Size    int64
+}
+
+// WalletApproval is a request for approval of a message refused by the wallet
+// signing policy. Once approved, the message is signed when it's sent again.
+type WalletApproval struct {
+	ID         uuid.UUID
+	Signer     address.Address
+	Message    *types.Message
+	MessageCid cid.Cid
+	Requested  time.Time
+	// Approved is set once the request is approved, until the message is
+	// signed.
+	Approved bool
+	// Expires is when the request, or the approval, is dropped.
+	Expires time.Time
+}
+
+// MessageSchedule are the conditions a message scheduled with
+// MpoolScheduleMessage is pushed on. At least one condition must be set, and
+// the message is pushed once all set conditions hold.
+type MessageSchedule struct {
+	// AtEpoch is the epoch from which the message is pushed
+	AtEpoch abi.ChainEpoch
+	// AtTime is the time from which the message is pushed
+	AtTime time.Time
+	// BaseFeeBelow pushes the message once the base fee is below it
+	BaseFeeBelow *abi.TokenAmount
+
+	// ExpireEpoch is the epoch from which the message is no longer pushed,
+	// 0 for never
+	ExpireEpoch abi.ChainEpoch
+}
+
+type ScheduledMessageState string
+
+const (
+	ScheduledMessagePending   ScheduledMessageState = "pending"
+	ScheduledMessagePushed    ScheduledMessageState = "pushed"
+	ScheduledMessageFailed    ScheduledMessageState = "failed"
+	ScheduledMessageExpired   ScheduledMessageState = "expired"
+	ScheduledMessageCancelled ScheduledMessageState = "cancelled"
+)
+
+// ScheduledMessage is a message scheduled with MpoolScheduleMessage.
+type ScheduledMessage struct {
+	ID       uuid.UUID
+	Message  *types.Message
+	MaxFee   abi.TokenAmount
+	Schedule MessageSchedule
+
+	State   ScheduledMessageState
+	Created time.Time
+	// Finished is when the message stopped being pending
+	Finished time.Time
+
+	// Attempts is the number of failed pushes, and Error the error of the last one
+	Attempts int
+	Error    string `json:",omitempty"`
+
+	// Signed is the CID of the pushed message, and PushEpoch the epoch it was
+	// pushed at
+	Signed    *cid.Cid       `json:",omitempty"`
+	PushEpoch abi.ChainEpoch `json:",omitempty"`
+}
+
+// SpendBudget is the state of the budget of the FIL the node's wallets spend
+// on gas.
+type SpendBudget struct {
+	Windows []SpendBudgetWindow
+	// Allowance can be spent beyond the budget, it's set with
+	// MpoolSpendBudgetOverride
+	Allowance abi.TokenAmount
+	// Queued is the number of messages held until the budget refills
+	Queued int
+}
+
+type SpendBudgetWindow struct {
+	Period time.Duration
+	Budget abi.TokenAmount
+	// Available is the gas which can be spent in the window now
+	Available abi.TokenAmount
+}
+
+// MaintenanceStatus is the state of the maintenance windows, during which
+// expensive background activities are allowed.
+type MaintenanceStatus struct {
+	// Open is whether expensive background activities are allowed
+	Open bool
+	// Until is when Open changes, zero when activities are always allowed
+	Until    time.Time
+	Windows  []MaintenanceWindow
+	Override *MaintenanceOverride `json:",omitempty"`
+	Tasks    []MaintenanceTask
+}
+
+// MaintenanceWindow is a configured maintenance window.
+type MaintenanceWindow struct {
+	// Schedule is the cron expression of the start of the window
+	Schedule  string
+	Duration  time.Duration
+	NextStart time.Time
+}
+
+// MaintenanceOverride forces the maintenance windows open or closed until a
+// time.
+type MaintenanceOverride struct {
+	Open  bool
+	Until time.Time
+}
+
+// MaintenanceTask is the state of a task or activity run in maintenance
+// windows.
+type MaintenanceTask struct {
+	Name    string
+	Running bool
+	Runs    int
+
+	LastStart    time.Time
+	LastDuration time.Duration
+	LastError    string `json:",omitempty"`
+}
+
+// UpgradeInfo is an upcoming network upgrade, of the upgrade schedule built
+// into the binary or announced for the network, and whether the binary is
+// ready to perform it.
+type UpgradeInfo struct {
+	Network         apitypes.NetworkVersion
+	Height          abi.ChainEpoch
+	EpochsRemaining abi.ChainEpoch
+	// ETA is the wall-clock time of the upgrade epoch
+	ETA time.Time
+
+	ActorsVersion actorstypes.Version
+	// Migration is whether the upgrade migrates the state
+	Migration bool
+	// BundlePresent is whether the builtin actors bundle of the actors
+	// version is loaded
+	BundlePresent bool
+
+	// Announced is whether the upgrade is only announced for the network,
+	// and missing from the schedule of the binary or at another epoch in it
+	Announced bool
+
+	// Supported is whether this binary can perform the upgrade, Reason
+	// explains why it can't
+	Supported bool
+	Reason    string `json:",omitempty"`
+}
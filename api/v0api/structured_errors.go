@@ -0,0 +1,13 @@
+package v0api
+
+import (
+	"github.com/filecoin-project/lotus/api"
+)
+
+// StructuredErrorsFullAPI is api.StructuredErrorsFullAPI for the v0 FullNode
+// API.
+func StructuredErrorsFullAPI(a FullNode) FullNode {
+	var out FullNodeStruct
+	api.StructuredErrorsProxy(a, &out)
+	return &out
+}
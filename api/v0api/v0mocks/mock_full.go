@@ -2129,6 +2129,21 @@ func (mr *MockFullNodeMockRecorder) PaychVoucherSubmit(arg0, arg1, arg2, arg3, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PaychVoucherSubmit", reflect.TypeOf((*MockFullNode)(nil).PaychVoucherSubmit), arg0, arg1, arg2, arg3, arg4)
 }
 
+// RepoLockStatus mocks base method.
+func (m *MockFullNode) RepoLockStatus(arg0 context.Context) (api.RepoLockStatus, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RepoLockStatus", arg0)
+	ret0, _ := ret[0].(api.RepoLockStatus)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RepoLockStatus indicates an expected call of RepoLockStatus.
+func (mr *MockFullNodeMockRecorder) RepoLockStatus(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RepoLockStatus", reflect.TypeOf((*MockFullNode)(nil).RepoLockStatus), arg0)
+}
+
 // Session mocks base method.
 func (m *MockFullNode) Session(arg0 context.Context) (uuid.UUID, error) {
 	m.ctrl.T.Helper()
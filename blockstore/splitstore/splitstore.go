@@ -134,6 +134,16 @@ type Config struct {
 	HotstoreMaxSpaceSafetyBuffer uint64
 }
 
+// CompactionGate restricts compactions to some times, such as maintenance
+// windows.
+type CompactionGate interface {
+	// Allowed returns whether a compaction can start now.
+	Allowed() bool
+	// Track is called when a compaction starts, and returns the function called
+	// with its result.
+	Track(name string) func(error)
+}
+
 // ChainAccessor allows the Splitstore to access the chain. It will most likely
 // be a ChainStore at runtime.
 type ChainAccessor interface {
@@ -169,6 +179,8 @@ type SplitStore struct {
 	pruneEpoch  abi.ChainEpoch // protected by compaction lock
 
 	headChangeMx sync.Mutex
+	// compactionGate defers compactions to the times it allows, protected by headChangeMx
+	compactionGate CompactionGate
 
 	chain ChainAccessor
 	ds    dstore.Datastore
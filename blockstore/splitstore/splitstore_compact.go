@@ -145,6 +145,17 @@ func (s *SplitStore) HeadChange(_, apply []*types.TipSet) error {
 	}
 
 	if epoch-s.baseEpoch > CompactionThreshold {
+		if s.compactionGate != nil && !s.compactionGate.Allowed() {
+			// outside of maintenance windows, defer compaction
+			atomic.StoreInt32(&s.compacting, 0)
+			return nil
+		}
+
+		var done func(error)
+		if s.compactionGate != nil {
+			done = s.compactionGate.Track("splitstore-compaction")
+		}
+
 		// it's time to compact -- prepare the transaction and go!
 		s.beginTxnProtect()
 		s.compactType = hot
@@ -155,7 +166,10 @@ func (s *SplitStore) HeadChange(_, apply []*types.TipSet) error {
 			log.Info("compacting splitstore")
 			start := time.Now()
 
-			s.compact(curTs)
+			err := s.compact(curTs)
+			if done != nil {
+				done(err)
+			}
 
 			log.Infow("compaction done", "took", time.Since(start))
 		}()
@@ -525,7 +539,14 @@ func (s *SplitStore) applyProtectors() error {
 //   - We delete in small batches taking a lock; each batch is checked again for marks, from the concurrent transactional mark, so as to never delete anything live
 //
 // - We then end the transaction and compact/gc the hotstore.
-func (s *SplitStore) compact(curTs *types.TipSet) {
+// SetCompactionGate restricts compactions to the times allowed by the gate.
+func (s *SplitStore) SetCompactionGate(g CompactionGate) {
+	s.headChangeMx.Lock()
+	defer s.headChangeMx.Unlock()
+	s.compactionGate = g
+}
+
+func (s *SplitStore) compact(curTs *types.TipSet) error {
 	log.Info("waiting for active views to complete")
 	start := time.Now()
 	s.viewWait()
@@ -539,6 +560,7 @@ func (s *SplitStore) compact(curTs *types.TipSet) {
 	if err != nil {
 		log.Errorf("COMPACTION ERROR: %s", err)
 	}
+	return err
 }
 
 func (s *SplitStore) doCompact(curTs *types.TipSet) error {
@@ -0,0 +1,7 @@
+package build
+
+// TrustedSnapshotPublishers are the addresses of the keys trusted to sign the
+// manifests of chain snapshots imported with `lotus daemon --import-snapshot`.
+// Publishers can also be added in the node configuration. Until any publisher
+// is trusted, snapshots without a signed manifest are imported with a warning.
+var TrustedSnapshotPublishers []string
@@ -0,0 +1,183 @@
+package init
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/ipfs/go-cid"
+	"github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	init9 "github.com/filecoin-project/go-state-types/builtin/v9/init"
+
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// User actors are WASM actors installed by users rather than shipped with the
+// network. They are only supported by init actors built with the experimental
+// m2-native feature, which is not enabled on any public network.
+
+// MethodInstallCode is the method of the init actor installing user actor code.
+const MethodInstallCode abi.MethodNum = 4
+
+// wasmMagic is the header of WASM modules: the \0asm magic and version 1.
+var wasmMagic = []byte{0x00, 0x61, 0x73, 0x6d, 0x01, 0x00, 0x00, 0x00}
+
+// ValidateCode does basic checks of user actor code before it's sent to the
+// chain, so that obviously invalid code doesn't waste gas.
+func ValidateCode(code []byte, maxSize int) error {
+	if len(code) == 0 {
+		return xerrors.Errorf("actor code is empty")
+	}
+	if maxSize > 0 && len(code) > maxSize {
+		return xerrors.Errorf("actor code is %d bytes, more than the maximum of %d", len(code), maxSize)
+	}
+	if !bytes.HasPrefix(code, wasmMagic) {
+		return xerrors.Errorf("actor code is not a WASM module (version 1)")
+	}
+	return nil
+}
+
+// CodeCid returns the CID user actor code is installed under.
+func CodeCid(code []byte) (cid.Cid, error) {
+	return cid.V1Builder{Codec: cid.Raw, MhType: multihash.BLAKE2B_MIN + 31}.Sum(code)
+}
+
+// InstallParams are the parameters of MethodInstallCode.
+type InstallParams struct {
+	Code []byte
+}
+
+// InstallReturn is the return value of MethodInstallCode.
+type InstallReturn struct {
+	CodeCid   cid.Cid
+	Installed bool
+}
+
+func (p *InstallParams) MarshalCBOR(w io.Writer) error {
+	cw := cbg.NewCborWriter(w)
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, 1); err != nil {
+		return err
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(p.Code))); err != nil {
+		return err
+	}
+	_, err := cw.Write(p.Code)
+	return err
+}
+
+func (p *InstallParams) UnmarshalCBOR(r io.Reader) error {
+	cr := cbg.NewCborReader(r)
+	if err := readTupleHeader(cr, 1); err != nil {
+		return err
+	}
+	code, err := cbg.ReadByteArray(cr, cbg.ByteArrayMaxLen)
+	if err != nil {
+		return xerrors.Errorf("reading code: %w", err)
+	}
+	p.Code = code
+	return nil
+}
+
+func (r *InstallReturn) MarshalCBOR(w io.Writer) error {
+	cw := cbg.NewCborWriter(w)
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, 2); err != nil {
+		return err
+	}
+	if err := cbg.WriteCid(cw, r.CodeCid); err != nil {
+		return err
+	}
+	return cbg.WriteBool(cw, r.Installed)
+}
+
+func (r *InstallReturn) UnmarshalCBOR(rd io.Reader) error {
+	cr := cbg.NewCborReader(rd)
+	if err := readTupleHeader(cr, 2); err != nil {
+		return err
+	}
+	c, err := cbg.ReadCid(cr)
+	if err != nil {
+		return xerrors.Errorf("reading code cid: %w", err)
+	}
+	r.CodeCid = c
+
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajOther || (extra != 20 && extra != 21) {
+		return xerrors.Errorf("expected a boolean for installed")
+	}
+	r.Installed = extra == 21
+	return nil
+}
+
+func readTupleHeader(cr *cbg.CborReader, fields uint64) error {
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+	if extra != fields {
+		return fmt.Errorf("cbor input had wrong number of fields (%d, expected %d)", extra, fields)
+	}
+	return nil
+}
+
+// installedActorsRoot reads the installed_actors field of the state of init
+// actors supporting user actors, which is a fourth field after the address
+// map, the next ID and the network name.
+type installedActorsRoot struct {
+	root *cid.Cid
+}
+
+func (s *installedActorsRoot) UnmarshalCBOR(r io.Reader) error {
+	cr := cbg.NewCborReader(r)
+	maj, extra, err := cr.ReadHeader()
+	if err != nil {
+		return err
+	}
+	if maj != cbg.MajArray {
+		return fmt.Errorf("cbor input should be of type array")
+	}
+	if extra < 4 {
+		return nil
+	}
+	for i := 0; i < 3; i++ {
+		var skip cbg.Deferred
+		if err := skip.UnmarshalCBOR(cr); err != nil {
+			return xerrors.Errorf("reading init state field %d: %w", i, err)
+		}
+	}
+	c, err := cbg.ReadCid(cr)
+	if err != nil {
+		return xerrors.Errorf("reading installed actors: %w", err)
+	}
+	s.root = &c
+	return nil
+}
+
+// LoadInstalledActors returns the code CIDs of the user actors installed in the
+// init actor. It returns false if the init actor doesn't support user actors.
+func LoadInstalledActors(ctx context.Context, store adt.Store, act *types.Actor) ([]cid.Cid, bool, error) {
+	var st installedActorsRoot
+	if err := store.Get(ctx, act.Head, &st); err != nil {
+		return nil, false, xerrors.Errorf("loading init actor state: %w", err)
+	}
+	if st.root == nil {
+		return nil, false, nil
+	}
+
+	var installed init9.InstalledActors
+	if err := store.Get(ctx, *st.root, &installed); err != nil {
+		return nil, true, xerrors.Errorf("loading installed actors: %w", err)
+	}
+	return installed.Entries, true, nil
+}
@@ -0,0 +1,112 @@
+package init
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	init9 "github.com/filecoin-project/go-state-types/builtin/v9/init"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestValidateCode(t *testing.T) {
+	code := append(append([]byte{}, wasmMagic...), 0x01, 0x02)
+
+	require.NoError(t, ValidateCode(code, 0))
+	require.NoError(t, ValidateCode(code, len(code)))
+	require.Error(t, ValidateCode(code, len(code)-1))
+	require.Error(t, ValidateCode(nil, 0))
+	require.Error(t, ValidateCode([]byte("not wasm"), 0))
+
+	c, err := CodeCid(code)
+	require.NoError(t, err)
+	require.Equal(t, uint64(cid.Raw), c.Prefix().Codec)
+	// small code isn't inlined with an identity hash
+	require.NotEqual(t, uint64(0), c.Prefix().MhType)
+}
+
+func TestInstallParamsRoundTrip(t *testing.T) {
+	p := InstallParams{Code: []byte{1, 2, 3}}
+	var buf bytes.Buffer
+	require.NoError(t, p.MarshalCBOR(&buf))
+	var p2 InstallParams
+	require.NoError(t, p2.UnmarshalCBOR(&buf))
+	require.Equal(t, p, p2)
+
+	c, err := CodeCid(p.Code)
+	require.NoError(t, err)
+	r := InstallReturn{CodeCid: c, Installed: true}
+	buf.Reset()
+	require.NoError(t, r.MarshalCBOR(&buf))
+	var r2 InstallReturn
+	require.NoError(t, r2.UnmarshalCBOR(&buf))
+	require.Equal(t, r, r2)
+}
+
+// m2State mirrors the state of init actors supporting user actors.
+type m2State struct {
+	AddressMap      cid.Cid
+	NextID          uint64
+	NetworkName     string
+	InstalledActors cid.Cid
+}
+
+func (s *m2State) MarshalCBOR(w io.Writer) error {
+	cw := cbg.NewCborWriter(w)
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, 4); err != nil {
+		return err
+	}
+	if err := cbg.WriteCid(cw, s.AddressMap); err != nil {
+		return err
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, s.NextID); err != nil {
+		return err
+	}
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(s.NetworkName))); err != nil {
+		return err
+	}
+	if _, err := cw.WriteString(s.NetworkName); err != nil {
+		return err
+	}
+	return cbg.WriteCid(cw, s.InstalledActors)
+}
+
+func TestLoadInstalledActors(t *testing.T) {
+	ctx := context.Background()
+	store := adt.WrapStore(ctx, cbor.NewCborStore(blockstore.NewMemory()))
+
+	code, err := CodeCid([]byte("code"))
+	require.NoError(t, err)
+	installed, err := store.Put(ctx, &init9.InstalledActors{Entries: []cid.Cid{code}})
+	require.NoError(t, err)
+
+	// a state without installed actors isn't supported
+	st9, err := init9.ConstructState(store, "test")
+	require.NoError(t, err)
+	head, err := store.Put(ctx, st9)
+	require.NoError(t, err)
+	_, supported, err := LoadInstalledActors(ctx, store, &types.Actor{Head: head})
+	require.NoError(t, err)
+	require.False(t, supported)
+
+	head, err = store.Put(ctx, &m2State{
+		AddressMap:      st9.AddressMap,
+		NextID:          uint64(st9.NextID),
+		NetworkName:     st9.NetworkName,
+		InstalledActors: installed,
+	})
+	require.NoError(t, err)
+	actors, supported, err := LoadInstalledActors(ctx, store, &types.Actor{Head: head})
+	require.NoError(t, err)
+	require.True(t, supported)
+	require.Equal(t, []cid.Cid{code}, actors)
+}
@@ -2,6 +2,7 @@ package beacon
 
 import (
 	"context"
+	"time"
 
 	logging "github.com/ipfs/go-log/v2"
 	"golang.org/x/xerrors"
@@ -47,6 +48,39 @@ type RandomBeacon interface {
 	MaxBeaconRoundForEpoch(network.Version, abi.ChainEpoch) uint64
 }
 
+// StatusReporter is implemented by beacons reporting the health of the
+// sources they fetch entries from.
+type StatusReporter interface {
+	Status() Status
+}
+
+// Status is the health of a random beacon.
+type Status struct {
+	// Healthy is false when none of the sources of the beacon are healthy
+	Healthy bool
+	// MissedRounds counts the rounds which couldn't be fetched from any source
+	MissedRounds uint64
+	LastRound    uint64
+	LastFetch    time.Time
+	LastError    string
+
+	Sources []SourceStatus
+}
+
+// SourceStatus is the health of a source of beacon entries.
+type SourceStatus struct {
+	Address string
+	Healthy bool
+	// Latency of the last successful fetch
+	Latency             time.Duration
+	Fetches             uint64
+	Failures            uint64
+	ConsecutiveFailures int
+	LastSuccess         time.Time
+	LastFailure         time.Time
+	LastError           string
+}
+
 func ValidateBlockValues(bSchedule Schedule, nv network.Version, h *types.BlockHeader, parentEpoch abi.ChainEpoch,
 	prevEntry types.BeaconEntry) error {
 	{
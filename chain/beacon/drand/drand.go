@@ -24,6 +24,7 @@ import (
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/beacon"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal/alerting"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
 
@@ -62,6 +63,10 @@ type DrandBeacon struct {
 	filRoundTime uint64
 
 	localCache *lru.Cache[uint64, *types.BeaconEntry]
+
+	chainHash    string
+	fetchTimeout time.Duration
+	health       *beaconHealth
 }
 
 // DrandHTTPClient interface overrides the user agent used by drand
@@ -82,6 +87,12 @@ func (l *logger) Named(s string) dlog.Logger {
 }
 
 func NewDrandBeacon(genesisTs, interval uint64, ps *pubsub.PubSub, config dtypes.DrandConfig) (*DrandBeacon, error) {
+	return NewDrandBeaconWithHealth(genesisTs, interval, ps, config, dtypes.DrandHealthConfig{})
+}
+
+// NewDrandBeaconWithHealth creates a drand beacon tracking the health of the
+// configured servers, failing over from the unhealthy ones.
+func NewDrandBeaconWithHealth(genesisTs, interval uint64, ps *pubsub.PubSub, config dtypes.DrandConfig, hcfg dtypes.DrandHealthConfig) (*DrandBeacon, error) {
 	if genesisTs == 0 {
 		panic("what are you doing this cant be zero")
 	}
@@ -91,6 +102,9 @@ func NewDrandBeacon(genesisTs, interval uint64, ps *pubsub.PubSub, config dtypes
 		return nil, xerrors.Errorf("unable to unmarshal drand chain info: %w", err)
 	}
 
+	hcfg = withHealthDefaults(hcfg)
+	health := &beaconHealth{}
+
 	var clients []dclient.Client
 	for _, url := range config.Servers {
 		hc, err := hclient.NewWithInfo(url, drandChain, nil)
@@ -98,8 +112,9 @@ func NewDrandBeacon(genesisTs, interval uint64, ps *pubsub.PubSub, config dtypes
 			return nil, xerrors.Errorf("could not create http drand client: %w", err)
 		}
 		hc.(DrandHTTPClient).SetUserAgent("drand-client-lotus/" + build.BuildVersion)
-		clients = append(clients, hc)
-
+		sc := newServerClient(hc, url, hcfg)
+		health.servers = append(health.servers, sc)
+		clients = append(clients, sc)
 	}
 
 	opts := []dclient.Option{
@@ -125,8 +140,11 @@ func NewDrandBeacon(genesisTs, interval uint64, ps *pubsub.PubSub, config dtypes
 	}
 
 	db := &DrandBeacon{
-		client:     client,
-		localCache: lc,
+		client:       client,
+		localCache:   lc,
+		chainHash:    drandChain.HashString(),
+		fetchTimeout: hcfg.FetchTimeout,
+		health:       health,
 	}
 
 	db.pubkey = drandChain.PublicKey
@@ -152,12 +170,19 @@ func (db *DrandBeacon) Entry(ctx context.Context, round uint64) <-chan beacon.Re
 	go func() {
 		start := build.Clock.Now()
 		log.Debugw("start fetching randomness", "round", round)
-		resp, err := db.client.Get(ctx, round)
+		fctx, cancel := context.WithTimeout(ctx, db.fetchTimeout)
+		resp, err := db.client.Get(fctx, round)
+		cancel()
 
 		var br beacon.Response
 		if err != nil {
+			if ctx.Err() == nil {
+				log.Warnw("failed to fetch randomness", "round", round, "took", build.Clock.Since(start), "error", err)
+				db.health.record(round, err)
+			}
 			br.Err = xerrors.Errorf("drand failed Get request: %w", err)
 		} else {
+			db.health.record(resp.Round(), nil)
 			br.Entry.Round = resp.Round()
 			br.Entry.Data = resp.Signature()
 		}
@@ -168,6 +193,18 @@ func (db *DrandBeacon) Entry(ctx context.Context, round uint64) <-chan beacon.Re
 
 	return out
 }
+
+// SetAlerting raises an alert through the given alerting system when none of
+// the servers of the beacon are healthy.
+func (db *DrandBeacon) SetAlerting(al *alerting.Alerting) {
+	db.health.setAlerting(al, db.chainHash)
+}
+
+// Status returns the health of the beacon and of its servers.
+func (db *DrandBeacon) Status() beacon.Status {
+	return db.health.status()
+}
+
 func (db *DrandBeacon) cacheValue(e types.BeaconEntry) {
 	db.localCache.Add(e.Round, &e)
 }
@@ -235,3 +272,4 @@ func (db *DrandBeacon) maxBeaconRoundV2(latestTs uint64) uint64 {
 }
 
 var _ beacon.RandomBeacon = (*DrandBeacon)(nil)
+var _ beacon.StatusReporter = (*DrandBeacon)(nil)
@@ -0,0 +1,230 @@
+package drand
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	dclient "github.com/drand/drand/client"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/beacon"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+const (
+	DefaultFetchTimeout        = 30 * time.Second
+	DefaultUnhealthyAfter      = 3
+	DefaultRetryUnhealthyAfter = time.Minute
+)
+
+func withHealthDefaults(cfg dtypes.DrandHealthConfig) dtypes.DrandHealthConfig {
+	if cfg.FetchTimeout <= 0 {
+		cfg.FetchTimeout = DefaultFetchTimeout
+	}
+	if cfg.UnhealthyAfter <= 0 {
+		cfg.UnhealthyAfter = DefaultUnhealthyAfter
+	}
+	if cfg.RetryUnhealthyAfter <= 0 {
+		cfg.RetryUnhealthyAfter = DefaultRetryUnhealthyAfter
+	}
+	return cfg
+}
+
+// serverClient wraps the client of a single drand server, tracking its health.
+// Unhealthy servers fail fast until they are due for a retry, so that the
+// wrapping client fails over to the healthy ones.
+type serverClient struct {
+	dclient.Client
+
+	addr string
+	cfg  dtypes.DrandHealthConfig
+
+	lk          sync.Mutex
+	latency     time.Duration
+	fetches     uint64
+	failures    uint64
+	consecutive int
+	lastSuccess time.Time
+	lastFailure time.Time
+	lastError   string
+}
+
+func newServerClient(c dclient.Client, addr string, cfg dtypes.DrandHealthConfig) *serverClient {
+	return &serverClient{
+		Client: c,
+		addr:   addr,
+		cfg:    cfg,
+	}
+}
+
+func (sc *serverClient) Get(ctx context.Context, round uint64) (dclient.Result, error) {
+	if !sc.available() {
+		return nil, xerrors.Errorf("drand server %s is unhealthy", sc.addr)
+	}
+
+	start := build.Clock.Now()
+	res, err := sc.Client.Get(ctx, round)
+	took := build.Clock.Since(start)
+
+	// requests cancelled because another server answered first say nothing
+	// about the health of this one
+	if err != nil && xerrors.Is(ctx.Err(), context.Canceled) {
+		return res, err
+	}
+
+	sc.record(took, err)
+	return res, err
+}
+
+func (sc *serverClient) record(took time.Duration, err error) {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+
+	now := build.Clock.Now()
+	sc.fetches++
+	if err != nil {
+		sc.failures++
+		sc.consecutive++
+		sc.lastFailure = now
+		sc.lastError = err.Error()
+		if sc.consecutive == sc.cfg.UnhealthyAfter {
+			log.Warnw("drand server became unhealthy", "server", sc.addr, "failures", sc.consecutive, "error", err)
+		}
+		return
+	}
+
+	if sc.consecutive >= sc.cfg.UnhealthyAfter {
+		log.Infow("drand server recovered", "server", sc.addr)
+	}
+	sc.consecutive = 0
+	sc.latency = took
+	sc.lastSuccess = now
+}
+
+func (sc *serverClient) healthyLocked() bool {
+	return sc.consecutive < sc.cfg.UnhealthyAfter
+}
+
+func (sc *serverClient) healthy() bool {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	return sc.healthyLocked()
+}
+
+// available returns whether the server should be fetched from, unhealthy
+// servers are retried once every RetryUnhealthyAfter.
+func (sc *serverClient) available() bool {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	return sc.healthyLocked() || build.Clock.Since(sc.lastFailure) >= sc.cfg.RetryUnhealthyAfter
+}
+
+func (sc *serverClient) status() beacon.SourceStatus {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	return beacon.SourceStatus{
+		Address:             sc.addr,
+		Healthy:             sc.healthyLocked(),
+		Latency:             sc.latency,
+		Fetches:             sc.fetches,
+		Failures:            sc.failures,
+		ConsecutiveFailures: sc.consecutive,
+		LastSuccess:         sc.lastSuccess,
+		LastFailure:         sc.lastFailure,
+		LastError:           sc.lastError,
+	}
+}
+
+// beaconHealth tracks the rounds of a beacon, raising an alert when all of its
+// servers are unhealthy.
+type beaconHealth struct {
+	servers []*serverClient
+
+	alerting *alerting.Alerting
+	alert    alerting.AlertType
+
+	lk           sync.Mutex
+	missedRounds uint64
+	lastRound    uint64
+	lastFetch    time.Time
+	lastError    string
+}
+
+func (bh *beaconHealth) setAlerting(al *alerting.Alerting, chainHash string) {
+	bh.lk.Lock()
+	defer bh.lk.Unlock()
+	bh.alerting = al
+	bh.alert = al.AddAlertType("drand", "servers-unhealthy-"+chainHash)
+}
+
+func (bh *beaconHealth) healthy() bool {
+	// without servers entries come from pubsub only
+	if len(bh.servers) == 0 {
+		return true
+	}
+	for _, sc := range bh.servers {
+		if sc.healthy() {
+			return true
+		}
+	}
+	return false
+}
+
+func (bh *beaconHealth) record(round uint64, err error) {
+	healthy := bh.healthy()
+
+	bh.lk.Lock()
+	defer bh.lk.Unlock()
+
+	if err != nil {
+		bh.missedRounds++
+		bh.lastError = err.Error()
+	} else {
+		bh.lastFetch = build.Clock.Now()
+		if round > bh.lastRound {
+			bh.lastRound = round
+		}
+	}
+
+	if bh.alerting == nil {
+		return
+	}
+	if !healthy && !bh.alerting.IsRaised(bh.alert) {
+		bh.alerting.Raise(bh.alert, map[string]interface{}{
+			"message": "all drand servers are unhealthy, fetching beacon entries may stall block production",
+			"round":   round,
+			"error":   bh.lastError,
+		})
+	} else if healthy && bh.alerting.IsRaised(bh.alert) {
+		bh.alerting.Resolve(bh.alert, map[string]string{
+			"message": "drand servers recovered",
+		})
+	}
+}
+
+func (bh *beaconHealth) status() beacon.Status {
+	st := beacon.Status{
+		Healthy: bh.healthy(),
+	}
+	for _, sc := range bh.servers {
+		st.Sources = append(st.Sources, sc.status())
+	}
+	sort.SliceStable(st.Sources, func(i, j int) bool {
+		if st.Sources[i].Healthy != st.Sources[j].Healthy {
+			return st.Sources[i].Healthy
+		}
+		return st.Sources[i].Latency < st.Sources[j].Latency
+	})
+
+	bh.lk.Lock()
+	defer bh.lk.Unlock()
+	st.MissedRounds = bh.missedRounds
+	st.LastRound = bh.lastRound
+	st.LastFetch = bh.lastFetch
+	st.LastError = bh.lastError
+	return st
+}
@@ -0,0 +1,123 @@
+// stm: #unit
+package drand
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	dclient "github.com/drand/drand/client"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+type testResult struct {
+	round uint64
+}
+
+func (r *testResult) Round() uint64      { return r.round }
+func (r *testResult) Randomness() []byte { return nil }
+func (r *testResult) Signature() []byte  { return nil }
+
+type testClient struct {
+	dclient.Client
+
+	fail  bool
+	calls int
+}
+
+func (c *testClient) Get(ctx context.Context, round uint64) (dclient.Result, error) {
+	c.calls++
+	if c.fail {
+		return nil, xerrors.New("unreachable")
+	}
+	return &testResult{round: round}, nil
+}
+
+func TestServerHealth(t *testing.T) {
+	ctx := context.Background()
+	cfg := withHealthDefaults(dtypes.DrandHealthConfig{RetryUnhealthyAfter: 50 * time.Millisecond})
+
+	c := &testClient{fail: true}
+	sc := newServerClient(c, "https://relay.example", cfg)
+
+	for i := 0; i < cfg.UnhealthyAfter; i++ {
+		_, err := sc.Get(ctx, 1)
+		require.Error(t, err)
+	}
+	require.False(t, sc.healthy())
+	require.Equal(t, cfg.UnhealthyAfter, c.calls)
+
+	// unhealthy servers fail fast until they are due for a retry
+	_, err := sc.Get(ctx, 1)
+	require.Error(t, err)
+	require.Equal(t, cfg.UnhealthyAfter, c.calls)
+
+	time.Sleep(cfg.RetryUnhealthyAfter)
+	c.fail = false
+	res, err := sc.Get(ctx, 2)
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), res.Round())
+	require.True(t, sc.healthy())
+
+	st := sc.status()
+	require.Equal(t, uint64(cfg.UnhealthyAfter+1), st.Fetches)
+	require.Equal(t, uint64(cfg.UnhealthyAfter), st.Failures)
+	require.Equal(t, 0, st.ConsecutiveFailures)
+	require.Equal(t, "unreachable", st.LastError)
+
+	// cancelled requests don't count as failures
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	c.fail = true
+	_, err = sc.Get(cctx, 3)
+	require.Error(t, err)
+	require.Equal(t, uint64(cfg.UnhealthyAfter), sc.status().Failures)
+}
+
+func TestBeaconHealthAlert(t *testing.T) {
+	ctx := context.Background()
+	cfg := withHealthDefaults(dtypes.DrandHealthConfig{UnhealthyAfter: 1})
+
+	c1, c2 := &testClient{fail: true}, &testClient{}
+	bh := &beaconHealth{
+		servers: []*serverClient{
+			newServerClient(c1, "a", cfg),
+			newServerClient(c2, "b", cfg),
+		},
+	}
+	al := alerting.NewAlertingSystem(journal.NilJournal())
+	bh.setAlerting(al, "chain")
+
+	_, err := bh.servers[0].Get(ctx, 1)
+	bh.record(1, err)
+	require.False(t, al.IsRaised(bh.alert))
+
+	st := bh.status()
+	require.True(t, st.Healthy)
+	require.Equal(t, uint64(1), st.MissedRounds)
+	// healthy servers are listed first
+	require.Equal(t, "b", st.Sources[0].Address)
+	require.False(t, st.Sources[1].Healthy)
+
+	c2.fail = true
+	_, err = bh.servers[1].Get(ctx, 2)
+	bh.record(2, err)
+	require.True(t, al.IsRaised(bh.alert))
+	require.False(t, bh.status().Healthy)
+
+	c2.fail = false
+	_, err = bh.servers[1].Get(ctx, 3)
+	require.Error(t, err) // not due for a retry yet
+	bh.servers[1].record(time.Millisecond, nil)
+	bh.record(3, nil)
+	require.False(t, al.IsRaised(bh.alert))
+
+	st = bh.status()
+	require.Equal(t, uint64(2), st.MissedRounds)
+	require.Equal(t, uint64(3), st.LastRound)
+}
@@ -160,6 +160,8 @@ func IsValidForSending(nv network.Version, act *types.Actor) bool {
 	}
 
 	// Only allow such actors to send if their delegated address is in the EAM's namespace.
+	// This is a consensus rule, so it must not depend on the f4 namespaces registered
+	// with the node: allowing other namespaces requires a network upgrade.
 	id, _, err := varint.FromUvarint(act.Address.Payload())
 	return err == nil && id == builtintypes.EthereumAddressManagerActorID
 }
@@ -1,6 +1,7 @@
 package ethhashlookup
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"strconv"
@@ -149,6 +150,14 @@ func NewTransactionHashLookup(path string) (*EthTxHashLookup, error) {
 	}, nil
 }
 
+// Vacuum rebuilds the database file to reclaim the space of deleted rows.
+func (ei *EthTxHashLookup) Vacuum(ctx context.Context) error {
+	if _, err := ei.db.ExecContext(ctx, "VACUUM;"); err != nil {
+		return xerrors.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
 func (ei *EthTxHashLookup) Close() error {
 	if ei.db == nil {
 		return nil
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"math"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/ipfs/go-cid"
 	cbg "github.com/whyrusleeping/cbor-gen"
+	"go.opencensus.io/stats"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -16,8 +18,10 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
 
+	"github.com/filecoin-project/lotus/chain/actors/policy"
 	cstore "github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
 )
 
 func isIndexedValue(b uint8) bool {
@@ -40,6 +44,10 @@ type EventFilter struct {
 	collected []*CollectedEvent
 	lastTaken time.Time
 	ch        chan<- interface{}
+	// replaying is set while a subscription replays historic events, the live ones are buffered
+	// until it's done and overflowed is set when they don't fit
+	replaying  bool
+	overflowed bool
 }
 
 var _ Filter = (*EventFilter)(nil)
@@ -84,6 +92,7 @@ func (f *EventFilter) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 	if err != nil {
 		return xerrors.Errorf("load executed messages: %w", err)
 	}
+	var matched int64
 	for msgIdx, em := range ems {
 		for evIdx, ev := range em.Events() {
 			// lookup address corresponding to the actor id
@@ -106,6 +115,7 @@ func (f *EventFilter) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 			}
 
 			// event matches filter, so record it
+			matched++
 			cev := &CollectedEvent{
 				Entries:     ev.Entries,
 				EmitterAddr: addr,
@@ -125,6 +135,11 @@ func (f *EventFilter) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 				continue
 			}
 
+			if f.replaying && f.maxResults > 0 && len(f.collected) == f.maxResults {
+				f.overflowed = true
+				f.mu.Unlock()
+				continue
+			}
 			if f.maxResults > 0 && len(f.collected) == f.maxResults {
 				copy(f.collected, f.collected[1:])
 				f.collected = f.collected[:len(f.collected)-1]
@@ -133,6 +148,9 @@ func (f *EventFilter) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 			f.mu.Unlock()
 		}
 	}
+	if matched > 0 {
+		stats.Record(ctx, metrics.EventFilterMatched.M(matched))
+	}
 
 	return nil
 }
@@ -159,6 +177,18 @@ func (f *EventFilter) LastTaken() time.Time {
 	return f.lastTaken
 }
 
+// query returns the index query selecting the events matched by this filter
+func (f *EventFilter) query() *EventQuery {
+	return &EventQuery{
+		MinHeight:  f.minHeight,
+		MaxHeight:  f.maxHeight,
+		TipSetCid:  f.tipsetCid,
+		Addresses:  f.addresses,
+		Keys:       f.keys,
+		MaxResults: f.maxResults,
+	}
+}
+
 // matchTipset reports whether this filter matches the given tipset
 func (f *EventFilter) matchTipset(te *TipSetEvents) bool {
 	if f.tipsetCid != cid.Undef {
@@ -270,6 +300,47 @@ func (te *TipSetEvents) messages(ctx context.Context) ([]executedMessage, error)
 	return te.ems, te.err
 }
 
+// indexedEvents returns the events of the tipset emitted by actors with an address that can be
+// matched against.
+func (te *TipSetEvents) indexedEvents(ctx context.Context, revert bool, resolver func(ctx context.Context, emitter abi.ActorID, ts *types.TipSet) (address.Address, bool)) ([]*CollectedEvent, error) {
+	// cache of lookups between actor id and f4 address
+	addressLookups := make(map[abi.ActorID]address.Address)
+
+	ems, err := te.messages(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("load executed messages: %w", err)
+	}
+
+	var ces []*CollectedEvent
+	for msgIdx, em := range ems {
+		for evIdx, ev := range em.Events() {
+			addr, found := addressLookups[ev.Emitter]
+			if !found {
+				var ok bool
+				addr, ok = resolver(ctx, ev.Emitter, te.rctTs)
+				if !ok {
+					// not an address we will be able to match against
+					continue
+				}
+				addressLookups[ev.Emitter] = addr
+			}
+
+			ces = append(ces, &CollectedEvent{
+				Entries:     ev.Entries,
+				EmitterAddr: addr,
+				EventIdx:    evIdx,
+				Reverted:    revert,
+				Height:      te.msgTs.Height(),
+				TipSetKey:   te.msgTs.Key(),
+				MsgIdx:      msgIdx,
+				MsgCid:      em.Message().Cid(),
+			})
+		}
+	}
+
+	return ces, nil
+}
+
 type executedMessage struct {
 	msg types.ChainMsg
 	rct *types.MessageReceipt
@@ -293,13 +364,21 @@ type EventFilterManager struct {
 	ChainStore       *cstore.ChainStore
 	AddressResolver  func(ctx context.Context, emitter abi.ActorID, ts *types.TipSet) (address.Address, bool)
 	MaxFilterResults int
-	EventIndex       *EventIndex
+	EventIndex       EventIndexer
 
 	mu            sync.Mutex // guards mutations to filters
 	filters       map[types.FilterID]*EventFilter
 	currentHeight abi.ChainEpoch
 }
 
+// ProcessedHeight returns the height of the last tipset whose events were
+// processed.
+func (m *EventFilterManager) ProcessedHeight() abi.ChainEpoch {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.currentHeight
+}
+
 func (m *EventFilterManager) Apply(ctx context.Context, from, to *types.TipSet) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -316,7 +395,7 @@ func (m *EventFilterManager) Apply(ctx context.Context, from, to *types.TipSet)
 	}
 
 	if m.EventIndex != nil {
-		if err := m.EventIndex.CollectEvents(ctx, tse, false, m.AddressResolver); err != nil {
+		if err := m.indexEvents(ctx, tse, false); err != nil {
 			return err
 		}
 	}
@@ -347,7 +426,7 @@ func (m *EventFilterManager) Revert(ctx context.Context, from, to *types.TipSet)
 	}
 
 	if m.EventIndex != nil {
-		if err := m.EventIndex.CollectEvents(ctx, tse, true, m.AddressResolver); err != nil {
+		if err := m.indexEvents(ctx, tse, true); err != nil {
 			return err
 		}
 	}
@@ -388,7 +467,7 @@ func (m *EventFilterManager) Install(ctx context.Context, minHeight, maxHeight a
 
 	if m.EventIndex != nil && minHeight != -1 && minHeight < currentHeight {
 		// Filter needs historic events
-		if err := m.EventIndex.PrefillFilter(ctx, f); err != nil {
+		if err := m.prefillFilter(ctx, f); err != nil {
 			return nil, err
 		}
 	}
@@ -398,11 +477,281 @@ func (m *EventFilterManager) Install(ctx context.Context, minHeight, maxHeight a
 		m.filters = make(map[types.FilterID]*EventFilter)
 	}
 	m.filters[id] = f
+	n := len(m.filters)
+	m.mu.Unlock()
+
+	recordInstalledFilters(ctx, "event", n)
+
+	return f, nil
+}
+
+// replayPageSize is about the number of events read from the index at once when replaying.
+const replayPageSize = 1000
+
+// InstallReplay installs a filter for a subscription that starts at fromHeight. The filter is
+// returned right away, then the matching events already in the index are sent to ch in the
+// background, in chain order and a page at a time, followed by the live events. The filter is
+// registered before the index is read so that events applied in the meantime are buffered, up to the
+// maximum number of filter results, and the ones also returned by the index are dropped, leaving no
+// gap or duplicate between the two. ctx must last as long as the subscription. done is called with
+// the outcome of the replay, the caller removes the filter when it fails.
+func (m *EventFilterManager) InstallReplay(ctx context.Context, fromHeight abi.ChainEpoch, addresses []address.Address, keys map[string][][]byte, ch chan<- interface{}, done func(error)) (*EventFilter, error) {
+	return m.installReplay(ctx, fromHeight, nil, nil, addresses, keys, ch, done)
+}
+
+// EventPosition is the position of an event in the chain.
+type EventPosition struct {
+	Height   abi.ChainEpoch
+	MsgIdx   int
+	EventIdx int
+}
+
+// after tells whether ce comes after the position in the chain.
+func (p *EventPosition) after(ce *CollectedEvent) bool {
+	if ce.Height != p.Height {
+		return ce.Height > p.Height
+	}
+	if ce.MsgIdx != p.MsgIdx {
+		return ce.MsgIdx > p.MsgIdx
+	}
+	return ce.EventIdx > p.EventIdx
+}
+
+// InstallResume is InstallReplay resuming a subscription after the event at
+// the given position, which the subscriber already received. The events at
+// the height of the position up to it are skipped.
+func (m *EventFilterManager) InstallResume(ctx context.Context, after EventPosition, addresses []address.Address, keys map[string][][]byte, ch chan<- interface{}, done func(error)) (*EventFilter, error) {
+	return m.installReplay(ctx, after.Height, &after, nil, addresses, keys, ch, done)
+}
+
+// InstallReorgResume is InstallReplay resuming a subscription after the event at the given position
+// when its tipset was reverted since. The events of the reverted tipsets, which are listed newest
+// first and start with the tipset of the position, are sent again as reverted up to the position,
+// newest first, before the events are replayed from fromHeight.
+func (m *EventFilterManager) InstallReorgResume(ctx context.Context, after EventPosition, reverted []types.TipSetKey, fromHeight abi.ChainEpoch, addresses []address.Address, keys map[string][][]byte, ch chan<- interface{}, done func(error)) (*EventFilter, error) {
+	return m.installReplay(ctx, fromHeight, nil, &reorg{tipsets: reverted, upTo: after}, addresses, keys, ch, done)
+}
+
+// reorg holds the reverted tipsets a resumed subscription received events of.
+type reorg struct {
+	tipsets []types.TipSetKey
+	upTo    EventPosition
+}
+
+func (m *EventFilterManager) installReplay(ctx context.Context, fromHeight abi.ChainEpoch, after *EventPosition, rv *reorg, addresses []address.Address, keys map[string][][]byte, ch chan<- interface{}, done func(error)) (*EventFilter, error) {
+	if m.EventIndex == nil {
+		return nil, xerrors.Errorf("historic event index disabled")
+	}
+
+	id, err := newFilterID()
+	if err != nil {
+		return nil, xerrors.Errorf("new filter id: %w", err)
+	}
+
+	f := &EventFilter{
+		id:         id,
+		minHeight:  fromHeight,
+		maxHeight:  -1,
+		addresses:  addresses,
+		keys:       keys,
+		maxResults: m.MaxFilterResults,
+		replaying:  true,
+	}
+
+	m.mu.Lock()
+	if m.filters == nil {
+		m.filters = make(map[types.FilterID]*EventFilter)
+	}
+	m.filters[id] = f
+	n := len(m.filters)
+	// the events up to the current height are in the index, the filter collects the later ones
+	head := m.currentHeight
 	m.mu.Unlock()
 
+	recordInstalledFilters(ctx, "event", n)
+
+	go func() {
+		err := m.replay(ctx, f, fromHeight, head, after, rv, ch)
+		if done != nil {
+			done(err)
+		}
+	}()
+
 	return f, nil
 }
 
+// replay sends the indexed events from the from to the to heights to ch, then the ones collected by
+// the filter in the meantime. When after is set, the events up to it are skipped. When rv is set, the
+// events of the reverted tipsets are sent as reverted first.
+func (m *EventFilterManager) replay(ctx context.Context, f *EventFilter, from, to abi.ChainEpoch, after *EventPosition, rv *reorg, ch chan<- interface{}) error {
+	if rv != nil {
+		if err := m.revert(ctx, f, rv, ch); err != nil {
+			return err
+		}
+	}
+
+	// only the events which reorgs can still revert may be collected by the filter too
+	seenFrom := to - policy.ChainFinality
+	seen := make(map[eventKey]struct{})
+
+	for from <= to {
+		q := &EventQuery{
+			MinHeight: from,
+			MaxHeight: to,
+			Addresses: f.addresses,
+			Keys:      f.keys,
+		}
+		ces, next, err := QueryEventsPage(ctx, m.EventIndex, q, replayPageSize)
+		if err != nil {
+			return xerrors.Errorf("reading historic events: %w", err)
+		}
+
+		for _, ce := range sortedEvents(ces) {
+			// events of reverted tipsets are not part of the chain anymore
+			if ce.Reverted {
+				continue
+			}
+			if after != nil && !after.after(ce) {
+				continue
+			}
+			if ce.Height >= seenFrom {
+				seen[eventKeyOf(ce)] = struct{}{}
+			}
+			select {
+			case ch <- ce:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+
+		if next < 0 {
+			break
+		}
+		from = next
+	}
+
+	return f.stream(ctx, seen, after, ch)
+}
+
+// revert sends the indexed events of the reverted tipsets up to the position of rv to ch, as
+// reverted, newest first.
+func (m *EventFilterManager) revert(ctx context.Context, f *EventFilter, rv *reorg, ch chan<- interface{}) error {
+	for i, tsk := range rv.tipsets {
+		tsc, err := tsk.Cid()
+		if err != nil {
+			return err
+		}
+		ces, err := m.EventIndex.QueryEvents(ctx, &EventQuery{
+			MinHeight: -1,
+			MaxHeight: -1,
+			TipSetCid: tsc,
+			Addresses: f.addresses,
+			Keys:      f.keys,
+		})
+		if err != nil {
+			return xerrors.Errorf("reading the events of reverted tipset %s: %w", tsk, err)
+		}
+
+		ces = sortedEvents(ces)
+		for j := len(ces) - 1; j >= 0; j-- {
+			// the subscriber didn't receive the events after the position
+			if i == 0 && rv.upTo.after(ces[j]) {
+				continue
+			}
+			ce := *ces[j]
+			ce.Reverted = true
+			select {
+			case ch <- &ce:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+// stream sends the events collected by the filter while replaying to ch, skipping the replayed ones
+// and those up to after when it's set, and then makes the filter push new events to ch. The events
+// are sent without holding the lock, so that the filter keeps collecting meanwhile.
+func (f *EventFilter) stream(ctx context.Context, seen map[eventKey]struct{}, after *EventPosition, ch chan<- interface{}) error {
+	for {
+		f.mu.Lock()
+		if f.overflowed {
+			f.mu.Unlock()
+			return xerrors.Errorf("more than %d live events were collected while replaying", f.maxResults)
+		}
+		collected := f.collected
+		f.collected = nil
+		if len(collected) == 0 {
+			f.replaying = false
+			f.ch = ch
+			f.mu.Unlock()
+			return nil
+		}
+		f.mu.Unlock()
+
+		for _, ce := range collected {
+			if _, ok := seen[eventKeyOf(ce)]; ok && !ce.Reverted {
+				continue
+			}
+			if after != nil && !ce.Reverted && !after.after(ce) {
+				continue
+			}
+			select {
+			case ch <- ce:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+type eventKey struct {
+	tsk      string
+	msgIdx   int
+	eventIdx int
+}
+
+func eventKeyOf(ce *CollectedEvent) eventKey {
+	return eventKey{tsk: ce.TipSetKey.String(), msgIdx: ce.MsgIdx, eventIdx: ce.EventIdx}
+}
+
+// sortedEvents sorts events into the order they were emitted in the chain.
+func sortedEvents(ces []*CollectedEvent) []*CollectedEvent {
+	sort.SliceStable(ces, func(i, j int) bool {
+		if ces[i].Height != ces[j].Height {
+			return ces[i].Height < ces[j].Height
+		}
+		if ces[i].MsgIdx != ces[j].MsgIdx {
+			return ces[i].MsgIdx < ces[j].MsgIdx
+		}
+		return ces[i].EventIdx < ces[j].EventIdx
+	})
+	return ces
+}
+
+func (m *EventFilterManager) indexEvents(ctx context.Context, tse *TipSetEvents, revert bool) error {
+	ces, err := tse.indexedEvents(ctx, revert, m.AddressResolver)
+	if err != nil {
+		return err
+	}
+
+	return m.EventIndex.StoreEvents(ctx, ces)
+}
+
+// prefillFilter fills a filter's collection of events from the historic index
+func (m *EventFilterManager) prefillFilter(ctx context.Context, f *EventFilter) error {
+	ces, err := m.EventIndex.QueryEvents(ctx, f.query())
+	if err != nil {
+		return err
+	}
+
+	if len(ces) > 0 {
+		f.setCollectedEvents(ces)
+	}
+	return nil
+}
+
 func (m *EventFilterManager) Remove(ctx context.Context, id types.FilterID) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -410,6 +759,7 @@ func (m *EventFilterManager) Remove(ctx context.Context, id types.FilterID) erro
 		return ErrFilterNotFound
 	}
 	delete(m.filters, id)
+	recordInstalledFilters(ctx, "event", len(m.filters))
 	return nil
 }
 
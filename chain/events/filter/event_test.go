@@ -3,6 +3,7 @@ package filter
 import (
 	"context"
 	pseudo "math/rand"
+	"path/filepath"
 	"testing"
 
 	"github.com/ipfs/go-cid"
@@ -273,6 +274,123 @@ func TestEventFilterCollectEvents(t *testing.T) {
 	}
 }
 
+func TestEventFilterReplayThenStream(t *testing.T) {
+	ctx := context.Background()
+	rng := pseudo.New(pseudo.NewSource(299792458))
+	a1 := randomF4Addr(t, rng)
+	a1ID := abi.ActorID(1)
+
+	addrMap := addressMap{}
+	addrMap.add(a1ID, a1)
+
+	ev1 := fakeEvent(a1ID, []kv{{k: "type", v: []byte("approval")}}, nil)
+	events := []*types.Event{ev1}
+	em := executedMessage{
+		msg: fakeMessage(randomF4Addr(t, rng), randomF4Addr(t, rng)),
+		rct: fakeReceipt(t, rng, newStore(), events),
+		evs: events,
+	}
+
+	events14000 := buildTipSetEvents(t, rng, 14000, em)
+	events14001 := buildTipSetEvents(t, rng, 14001, em)
+	events14002 := buildTipSetEvents(t, rng, 14002, em)
+
+	ei, err := NewEventIndex(filepath.Join(t.TempDir(), "actorevents.db"))
+	require.NoError(t, err)
+	require.NoError(t, ei.CollectEvents(ctx, events14000, false, addrMap.ResolveAddress))
+	require.NoError(t, ei.CollectEvents(ctx, events14001, false, addrMap.ResolveAddress))
+
+	m := &EventFilterManager{EventIndex: ei, currentHeight: 14002}
+	replay := func(install func(done func(error)) (*EventFilter, error)) (*EventFilter, chan error) {
+		done := make(chan error, 1)
+		f, err := install(func(err error) { done <- err })
+		require.NoError(t, err)
+		return f, done
+	}
+
+	ch := make(chan interface{}, 10)
+	f, done := replay(func(done func(error)) (*EventFilter, error) {
+		return m.InstallReplay(ctx, 14000, nil, nil, ch, done)
+	})
+	require.NoError(t, <-done)
+
+	// live events now go straight to the channel
+	require.NoError(t, f.CollectEvents(ctx, events14002, false, addrMap.ResolveAddress))
+	close(ch)
+
+	var heights []abi.ChainEpoch
+	for v := range ch {
+		heights = append(heights, v.(*CollectedEvent).Height)
+	}
+	require.Equal(t, []abi.ChainEpoch{14000, 14001, 14002}, heights)
+
+	// resuming skips the events up to the position
+	ch = make(chan interface{}, 10)
+	f, done = replay(func(done func(error)) (*EventFilter, error) {
+		return m.InstallResume(ctx, EventPosition{Height: 14000}, nil, nil, ch, done)
+	})
+	require.NoError(t, <-done)
+	require.NoError(t, f.CollectEvents(ctx, events14002, false, addrMap.ResolveAddress))
+	close(ch)
+
+	heights = nil
+	for v := range ch {
+		heights = append(heights, v.(*CollectedEvent).Height)
+	}
+	require.Equal(t, []abi.ChainEpoch{14001, 14002}, heights)
+
+	// resuming after a reorg first reverts the events received from the reverted tipsets
+	ch = make(chan interface{}, 10)
+	f, done = replay(func(done func(error)) (*EventFilter, error) {
+		return m.InstallReorgResume(ctx, EventPosition{Height: 14001}, []types.TipSetKey{events14001.msgTs.Key()}, 14001, nil, nil, ch, done)
+	})
+	require.NoError(t, <-done)
+	close(ch)
+
+	var reverted []bool
+	heights = nil
+	for v := range ch {
+		heights = append(heights, v.(*CollectedEvent).Height)
+		reverted = append(reverted, v.(*CollectedEvent).Reverted)
+	}
+	require.Equal(t, []abi.ChainEpoch{14001, 14001}, heights)
+	require.Equal(t, []bool{true, false}, reverted)
+
+	// events collected while replaying are sent after the replayed ones, once
+	ch = make(chan interface{})
+	f, done = replay(func(done func(error)) (*EventFilter, error) {
+		return m.InstallReplay(ctx, 14000, nil, nil, ch, done)
+	})
+	require.Equal(t, abi.ChainEpoch(14000), (<-ch).(*CollectedEvent).Height)
+	require.NoError(t, f.CollectEvents(ctx, events14001, false, addrMap.ResolveAddress))
+	require.NoError(t, f.CollectEvents(ctx, events14002, false, addrMap.ResolveAddress))
+	require.NoError(t, f.CollectEvents(ctx, events14002, true, addrMap.ResolveAddress))
+
+	var got []*CollectedEvent
+	for len(got) < 3 {
+		got = append(got, (<-ch).(*CollectedEvent))
+	}
+	require.NoError(t, <-done)
+	require.Equal(t, abi.ChainEpoch(14001), got[0].Height)
+	require.Equal(t, abi.ChainEpoch(14002), got[1].Height)
+	require.False(t, got[1].Reverted)
+	require.Equal(t, abi.ChainEpoch(14002), got[2].Height)
+	require.True(t, got[2].Reverted)
+	require.Empty(t, f.TakeCollectedEvents(ctx))
+
+	// the replay fails when the live events don't fit the buffer
+	m.MaxFilterResults = 1
+	ch = make(chan interface{})
+	f, done = replay(func(done func(error)) (*EventFilter, error) {
+		return m.InstallReplay(ctx, 14000, nil, nil, ch, done)
+	})
+	require.Equal(t, abi.ChainEpoch(14000), (<-ch).(*CollectedEvent).Height)
+	require.NoError(t, f.CollectEvents(ctx, events14002, false, addrMap.ResolveAddress))
+	require.NoError(t, f.CollectEvents(ctx, events14002, true, addrMap.ResolveAddress))
+	require.Equal(t, abi.ChainEpoch(14001), (<-ch).(*CollectedEvent).Height)
+	require.Error(t, <-done)
+}
+
 type kv struct {
 	k string
 	v []byte
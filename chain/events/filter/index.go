@@ -10,12 +10,14 @@ import (
 
 	"github.com/ipfs/go-cid"
 	_ "github.com/mattn/go-sqlite3"
+	"go.opencensus.io/stats"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
 )
 
 var pragmas = []string{
@@ -58,9 +60,14 @@ var ddls = []string{
 
 	// version 1.
 	`INSERT OR IGNORE INTO _meta (version) VALUES (1)`,
+
+	// version 2, storing events checks the events already stored for the tipset. Databases of an
+	// earlier version are upgraded by the repo migrations.
+	`CREATE INDEX IF NOT EXISTS event_tipset_key_cid ON event (tipset_key_cid)`,
+	`INSERT OR IGNORE INTO _meta (version) VALUES (2)`,
 }
 
-const schemaVersion = 1
+const schemaVersion = 2
 
 const (
 	insertEvent = `INSERT OR IGNORE INTO event
@@ -70,8 +77,30 @@ const (
 	insertEntry = `INSERT OR IGNORE INTO event_entry
 	(event_id, indexed, flags, key, codec, value)
 	VALUES(?, ?, ?, ?, ?, ?)`
+
+	// state of the last events stored for a tipset
+	selectTipSetState = `SELECT reverted FROM event WHERE tipset_key_cid=? ORDER BY id DESC LIMIT 1`
 )
 
+// EventIndexer stores and queries the events of the historic index. It's implemented by the
+// EventIndex, and by clients of an event index service shared by several nodes.
+type EventIndexer interface {
+	StoreEvents(ctx context.Context, ces []*CollectedEvent) error
+	QueryEvents(ctx context.Context, q *EventQuery) ([]*CollectedEvent, error)
+}
+
+// EventQuery selects events from the index.
+type EventQuery struct {
+	MinHeight  abi.ChainEpoch // minimum epoch of the events or -1 if no minimum
+	MaxHeight  abi.ChainEpoch // maximum epoch of the events or -1 if no maximum
+	TipSetCid  cid.Cid        // tipset of the events, overrides the heights when set
+	Addresses  []address.Address
+	Keys       map[string][][]byte
+	MaxResults int // maximum number of results, 0 is unlimited
+}
+
+var _ EventIndexer = (*EventIndex)(nil)
+
 type EventIndex struct {
 	db *sql.DB
 }
@@ -113,6 +142,9 @@ func NewEventIndex(path string) (*EventIndex, error) {
 		}
 		if version != schemaVersion {
 			_ = db.Close()
+			if version < schemaVersion {
+				return nil, xerrors.Errorf("invalid database version: got %d, expected %d; upgrade it with 'lotus repo migrate'", version, schemaVersion)
+			}
 			return nil, xerrors.Errorf("invalid database version: got %d, expected %d", version, schemaVersion)
 		}
 	}
@@ -122,6 +154,14 @@ func NewEventIndex(path string) (*EventIndex, error) {
 	}, nil
 }
 
+// Vacuum rebuilds the database file to reclaim the space of deleted rows.
+func (ei *EventIndex) Vacuum(ctx context.Context) error {
+	if _, err := ei.db.ExecContext(ctx, "VACUUM;"); err != nil {
+		return xerrors.Errorf("vacuum: %w", err)
+	}
+	return nil
+}
+
 func (ei *EventIndex) Close() error {
 	if ei.db == nil {
 		return nil
@@ -130,19 +170,34 @@ func (ei *EventIndex) Close() error {
 }
 
 func (ei *EventIndex) CollectEvents(ctx context.Context, te *TipSetEvents, revert bool, resolver func(ctx context.Context, emitter abi.ActorID, ts *types.TipSet) (address.Address, bool)) error {
-	// cache of lookups between actor id and f4 address
+	ces, err := te.indexedEvents(ctx, revert, resolver)
+	if err != nil {
+		return err
+	}
 
-	addressLookups := make(map[abi.ActorID]address.Address)
+	return ei.StoreEvents(ctx, ces)
+}
 
-	ems, err := te.messages(ctx)
-	if err != nil {
-		return xerrors.Errorf("load executed messages: %w", err)
+// StoreEvents adds events to the index. Events of a tipset that are already in the index in the
+// same applied or reverted state are skipped, so that several nodes can store the same events.
+func (ei *EventIndex) StoreEvents(ctx context.Context, ces []*CollectedEvent) error {
+	if len(ces) == 0 {
+		return nil
 	}
+	defer metrics.Timer(ctx, metrics.EventIndexWriteDuration)()
 
 	tx, err := ei.db.Begin()
 	if err != nil {
 		return xerrors.Errorf("begin transaction: %w", err)
 	}
+	defer func() {
+		_ = tx.Rollback()
+	}()
+
+	stmtState, err := tx.Prepare(selectTipSetState)
+	if err != nil {
+		return xerrors.Errorf("prepare select tipset state: %w", err)
+	}
 	stmtEvent, err := tx.Prepare(insertEvent)
 	if err != nil {
 		return xerrors.Errorf("prepare insert event: %w", err)
@@ -152,55 +207,63 @@ func (ei *EventIndex) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 		return xerrors.Errorf("prepare insert entry: %w", err)
 	}
 
-	for msgIdx, em := range ems {
-		for evIdx, ev := range em.Events() {
-			addr, found := addressLookups[ev.Emitter]
-			if !found {
-				var ok bool
-				addr, ok = resolver(ctx, ev.Emitter, te.rctTs)
-				if !ok {
-					// not an address we will be able to match against
-					continue
-				}
-				addressLookups[ev.Emitter] = addr
-			}
+	// whether the events of each tipset in the batch are stored
+	store := make(map[types.TipSetKey]bool)
 
-			tsKeyCid, err := te.msgTs.Key().Cid()
-			if err != nil {
-				return xerrors.Errorf("tipset key cid: %w", err)
-			}
+	for _, ce := range ces {
+		tsKeyCid, err := ce.TipSetKey.Cid()
+		if err != nil {
+			return xerrors.Errorf("tipset key cid: %w", err)
+		}
 
-			res, err := stmtEvent.Exec(
-				te.msgTs.Height(),          // height
-				te.msgTs.Key().Bytes(),     // tipset_key
-				tsKeyCid.Bytes(),           // tipset_key_cid
-				addr.Bytes(),               // emitter_addr
-				evIdx,                      // event_index
-				em.Message().Cid().Bytes(), // message_cid
-				msgIdx,                     // message_index
-				revert,                     // reverted
-			)
-			if err != nil {
-				return xerrors.Errorf("exec insert event: %w", err)
+		ok, seen := store[ce.TipSetKey]
+		if !seen {
+			var reverted bool
+			err := stmtState.QueryRow(tsKeyCid.Bytes()).Scan(&reverted)
+			switch {
+			case errors.Is(err, sql.ErrNoRows):
+				ok = true
+			case err != nil:
+				return xerrors.Errorf("select tipset state: %w", err)
+			default:
+				ok = reverted != ce.Reverted
 			}
+			store[ce.TipSetKey] = ok
+		}
+		if !ok {
+			continue
+		}
 
-			lastID, err := res.LastInsertId()
-			if err != nil {
-				return xerrors.Errorf("get last row id: %w", err)
-			}
+		res, err := stmtEvent.Exec(
+			ce.Height,              // height
+			ce.TipSetKey.Bytes(),   // tipset_key
+			tsKeyCid.Bytes(),       // tipset_key_cid
+			ce.EmitterAddr.Bytes(), // emitter_addr
+			ce.EventIdx,            // event_index
+			ce.MsgCid.Bytes(),      // message_cid
+			ce.MsgIdx,              // message_index
+			ce.Reverted,            // reverted
+		)
+		if err != nil {
+			return xerrors.Errorf("exec insert event: %w", err)
+		}
 
-			for _, entry := range ev.Entries {
-				_, err := stmtEntry.Exec(
-					lastID,                      // event_id
-					isIndexedValue(entry.Flags), // indexed
-					[]byte{entry.Flags},         // flags
-					entry.Key,                   // key
-					entry.Codec,                 // codec
-					entry.Value,                 // value
-				)
-				if err != nil {
-					return xerrors.Errorf("exec insert entry: %w", err)
-				}
+		lastID, err := res.LastInsertId()
+		if err != nil {
+			return xerrors.Errorf("get last row id: %w", err)
+		}
+
+		for _, entry := range ce.Entries {
+			_, err := stmtEntry.Exec(
+				lastID,                      // event_id
+				isIndexedValue(entry.Flags), // indexed
+				[]byte{entry.Flags},         // flags
+				entry.Key,                   // key
+				entry.Codec,                 // codec
+				entry.Value,                 // value
+			)
+			if err != nil {
+				return xerrors.Errorf("exec insert entry: %w", err)
 			}
 		}
 	}
@@ -209,41 +272,204 @@ func (ei *EventIndex) CollectEvents(ctx context.Context, te *TipSetEvents, rever
 		return xerrors.Errorf("commit transaction: %w", err)
 	}
 
+	// the size is only reported as a metric, so failing to get it isn't an error
+	var size int64
+	if err := ei.db.QueryRowContext(ctx, "SELECT page_count * page_size FROM pragma_page_count(), pragma_page_size()").Scan(&size); err == nil {
+		stats.Record(ctx, metrics.EventIndexSize.M(size))
+	}
+
 	return nil
 }
 
+// pruneBatch is the number of emitters whose events are deleted per statement
+const pruneBatch = 500
+
+// PruneEmitters deletes the events of the emitters which retain rejects, returning the number of
+// deleted events. The space of the deleted events is reclaimed by Vacuum.
+func (ei *EventIndex) PruneEmitters(ctx context.Context, retain func(address.Address) bool) (int64, error) {
+	rows, err := ei.db.QueryContext(ctx, "SELECT DISTINCT emitter_addr FROM event")
+	if err != nil {
+		return 0, xerrors.Errorf("query emitters: %w", err)
+	}
+	var prune [][]byte
+	for rows.Next() {
+		var b []byte
+		if err := rows.Scan(&b); err != nil {
+			_ = rows.Close()
+			return 0, xerrors.Errorf("read emitter: %w", err)
+		}
+		addr, err := address.NewFromBytes(b)
+		if err != nil {
+			_ = rows.Close()
+			return 0, xerrors.Errorf("decode emitter address: %w", err)
+		}
+		if !retain(addr) {
+			prune = append(prune, b)
+		}
+	}
+	if err := rows.Close(); err != nil {
+		return 0, xerrors.Errorf("query emitters: %w", err)
+	}
+
+	var deleted int64
+	for len(prune) > 0 {
+		batch := prune
+		if len(batch) > pruneBatch {
+			batch = batch[:pruneBatch]
+		}
+		prune = prune[len(batch):]
+
+		placeholders := strings.Repeat("?, ", len(batch)-1) + "?"
+		values := make([]any, len(batch))
+		for i, b := range batch {
+			values[i] = b
+		}
+
+		tx, err := ei.db.BeginTx(ctx, nil)
+		if err != nil {
+			return deleted, xerrors.Errorf("begin transaction: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, "DELETE FROM event_entry WHERE event_id IN (SELECT id FROM event WHERE emitter_addr IN ("+placeholders+"))", values...); err != nil {
+			_ = tx.Rollback()
+			return deleted, xerrors.Errorf("delete event entries: %w", err)
+		}
+		res, err := tx.ExecContext(ctx, "DELETE FROM event WHERE emitter_addr IN ("+placeholders+")", values...)
+		if err != nil {
+			_ = tx.Rollback()
+			return deleted, xerrors.Errorf("delete events: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			_ = tx.Rollback()
+			return deleted, xerrors.Errorf("count deleted events: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return deleted, xerrors.Errorf("commit transaction: %w", err)
+		}
+		deleted += n
+	}
+
+	return deleted, nil
+}
+
 // PrefillFilter fills a filter's collection of events from the historic index
 func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
+	ces, err := ei.QueryEvents(ctx, f.query())
+	if err != nil {
+		return err
+	}
+
+	if len(ces) == 0 {
+		return nil
+	}
+
+	f.setCollectedEvents(ces)
+	return nil
+}
+
+// QueryEventsPage returns the first events of the index matching the query, in height order, up to about budget
+// events, along with the height the next page starts at, or -1 when all the events of the query were
+// returned. The heights of the query must be set. The range is split adaptively: sub-ranges holding
+// more events than the remaining budget are halved until they fit, and grown back after they do.
+// The events of a height are never split across pages, so a page of a single height can exceed the
+// budget. A budget of 0 is unlimited.
+func QueryEventsPage(ctx context.Context, ei EventIndexer, q *EventQuery, budget int) ([]*CollectedEvent, abi.ChainEpoch, error) {
+	if q.TipSetCid != cid.Undef || q.MinHeight < 0 || q.MaxHeight < 0 {
+		return nil, 0, xerrors.Errorf("paged queries need a height range")
+	}
+
+	var out []*CollectedEvent
+	from, to := q.MinHeight, q.MaxHeight
+	step := to - from + 1
+	for from <= to {
+		end := from + step - 1
+		if end > to {
+			end = to
+		}
+
+		sub := *q
+		sub.MinHeight, sub.MaxHeight = from, end
+		sub.MaxResults = 0
+		if budget > 0 {
+			// one more than the remaining budget to detect overflows
+			sub.MaxResults = budget - len(out) + 1
+		}
+
+		ces, err := ei.QueryEvents(ctx, &sub)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if budget > 0 && len(out)+len(ces) > budget {
+			if end > from {
+				step = (end - from + 1) / 2
+				continue
+			}
+			if len(out) > 0 {
+				// the page is full, the next one starts at this height
+				return out, from, nil
+			}
+
+			// all the events of a single height are returned together
+			sub.MaxResults = 0
+			if ces, err = ei.QueryEvents(ctx, &sub); err != nil {
+				return nil, 0, err
+			}
+		}
+
+		out = append(out, ces...)
+		from = end + 1
+
+		if budget > 0 && len(out) >= budget {
+			break
+		}
+		// few events in the sub-range, try a larger one next
+		if budget == 0 || len(ces) < (budget-len(out))/2 {
+			step *= 2
+		}
+	}
+
+	if from > to {
+		return out, -1, nil
+	}
+	return out, from, nil
+}
+
+// QueryEvents returns the events of the index matching the query, in height order. When the
+// number of results is limited, the most recent events are returned.
+func (ei *EventIndex) QueryEvents(ctx context.Context, q *EventQuery) ([]*CollectedEvent, error) {
+	defer metrics.Timer(ctx, metrics.EventIndexQueryDuration)()
+
 	clauses := []string{}
 	values := []any{}
 	joins := []string{}
 
-	if f.tipsetCid != cid.Undef {
+	if q.TipSetCid != cid.Undef {
 		clauses = append(clauses, "event.tipset_key_cid=?")
-		values = append(values, f.tipsetCid.Bytes())
+		values = append(values, q.TipSetCid.Bytes())
 	} else {
-		if f.minHeight >= 0 {
+		if q.MinHeight >= 0 {
 			clauses = append(clauses, "event.height>=?")
-			values = append(values, f.minHeight)
+			values = append(values, q.MinHeight)
 		}
-		if f.maxHeight >= 0 {
+		if q.MaxHeight >= 0 {
 			clauses = append(clauses, "event.height<=?")
-			values = append(values, f.maxHeight)
+			values = append(values, q.MaxHeight)
 		}
 	}
 
-	if len(f.addresses) > 0 {
+	if len(q.Addresses) > 0 {
 		subclauses := []string{}
-		for _, addr := range f.addresses {
+		for _, addr := range q.Addresses {
 			subclauses = append(subclauses, "emitter_addr=?")
 			values = append(values, addr.Bytes())
 		}
 		clauses = append(clauses, "("+strings.Join(subclauses, " OR ")+")")
 	}
 
-	if len(f.keys) > 0 {
+	if len(q.Keys) > 0 {
 		join := 0
-		for key, vals := range f.keys {
+		for key, vals := range q.Keys {
 			if len(vals) > 0 {
 				join++
 				joinAlias := fmt.Sprintf("ee%d", join)
@@ -288,25 +514,25 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 
 	stmt, err := ei.db.Prepare(s)
 	if err != nil {
-		return xerrors.Errorf("prepare prefill query: %w", err)
+		return nil, xerrors.Errorf("prepare events query: %w", err)
 	}
 
-	q, err := stmt.QueryContext(ctx, values...)
+	rows, err := stmt.QueryContext(ctx, values...)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil
+			return nil, nil
 		}
-		return xerrors.Errorf("exec prefill query: %w", err)
+		return nil, xerrors.Errorf("exec events query: %w", err)
 	}
 
 	var ces []*CollectedEvent
 	var currentID int64 = -1
 	var ce *CollectedEvent
 
-	for q.Next() {
+	for rows.Next() {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		default:
 		}
 
@@ -326,7 +552,7 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 			value        []byte
 		}
 
-		if err := q.Scan(
+		if err := rows.Scan(
 			&row.id,
 			&row.height,
 			&row.tipsetKey,
@@ -341,7 +567,7 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 			&row.codec,
 			&row.value,
 		); err != nil {
-			return xerrors.Errorf("read prefill row: %w", err)
+			return nil, xerrors.Errorf("read events row: %w", err)
 		}
 
 		if row.id != currentID {
@@ -351,7 +577,7 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 				// Unfortunately we can't easily incorporate the max results limit into the query due to the
 				// unpredictable number of rows caused by joins
 				// Break here to stop collecting rows
-				if f.maxResults > 0 && len(ces) >= f.maxResults {
+				if q.MaxResults > 0 && len(ces) >= q.MaxResults {
 					break
 				}
 			}
@@ -366,17 +592,17 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 
 			ce.EmitterAddr, err = address.NewFromBytes(row.emitterAddr)
 			if err != nil {
-				return xerrors.Errorf("parse emitter addr: %w", err)
+				return nil, xerrors.Errorf("parse emitter addr: %w", err)
 			}
 
 			ce.TipSetKey, err = types.TipSetKeyFromBytes(row.tipsetKey)
 			if err != nil {
-				return xerrors.Errorf("parse tipsetkey: %w", err)
+				return nil, xerrors.Errorf("parse tipsetkey: %w", err)
 			}
 
 			ce.MsgCid, err = cid.Cast(row.messageCid)
 			if err != nil {
-				return xerrors.Errorf("parse message cid: %w", err)
+				return nil, xerrors.Errorf("parse message cid: %w", err)
 			}
 		}
 
@@ -393,14 +619,9 @@ func (ei *EventIndex) PrefillFilter(ctx context.Context, f *EventFilter) error {
 		ces = append(ces, ce)
 	}
 
-	if len(ces) == 0 {
-		return nil
-	}
-
 	// collected event list is in inverted order since we selected only the most recent events
 	// sort it into height order
 	sort.Slice(ces, func(i, j int) bool { return ces[i].Height < ces[j].Height })
-	f.setCollectedEvents(ces)
 
-	return nil
+	return ces, nil
 }
@@ -281,3 +281,71 @@ func TestEventIndexPrefillFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestEventIndexQueryEventsPage(t *testing.T) {
+	ctx := context.Background()
+	rng := pseudo.New(pseudo.NewSource(299792458))
+	a1 := randomF4Addr(t, rng)
+	a1ID := abi.ActorID(1)
+	addrMap := addressMap{}
+	addrMap.add(a1ID, a1)
+
+	ei, err := NewEventIndex(filepath.Join(t.TempDir(), "actorevents.db"))
+	require.NoError(t, err, "create event index")
+
+	// two events at each height from 100 to 119
+	st := newStore()
+	for h := abi.ChainEpoch(100); h < 120; h++ {
+		events := []*types.Event{
+			fakeEvent(a1ID, []kv{{k: "type", v: []byte("transfer")}}, nil),
+			fakeEvent(a1ID, []kv{{k: "type", v: []byte("approval")}}, nil),
+		}
+		em := executedMessage{
+			msg: fakeMessage(randomF4Addr(t, rng), randomF4Addr(t, rng)),
+			rct: fakeReceipt(t, rng, st, events),
+			evs: events,
+		}
+		require.NoError(t, ei.CollectEvents(ctx, buildTipSetEvents(t, rng, h, em), false, addrMap.ResolveAddress))
+	}
+
+	readAll := func(q EventQuery, budget int) (pages [][]*CollectedEvent) {
+		for {
+			ces, next, err := QueryEventsPage(ctx, ei, &q, budget)
+			require.NoError(t, err)
+			pages = append(pages, ces)
+			if next == -1 {
+				return pages
+			}
+			require.Greater(t, next, q.MinHeight)
+			q.MinHeight = next
+		}
+	}
+
+	for _, tc := range []struct {
+		budget    int
+		pages     int
+		pageLimit int
+	}{
+		{budget: 0, pages: 1, pageLimit: 40},
+		{budget: 100, pages: 1, pageLimit: 40},
+		{budget: 5, pages: 10, pageLimit: 4},
+		// a single height over the budget is returned whole
+		{budget: 1, pages: 20, pageLimit: 2},
+	} {
+		pages := readAll(EventQuery{MinHeight: 95, MaxHeight: 119}, tc.budget)
+		require.Len(t, pages, tc.pages, "budget %d", tc.budget)
+
+		var all []*CollectedEvent
+		for _, p := range pages {
+			require.LessOrEqual(t, len(p), tc.pageLimit, "budget %d", tc.budget)
+			all = append(all, p...)
+		}
+		require.Len(t, all, 40, "budget %d", tc.budget)
+		for i, ce := range all {
+			require.Equal(t, abi.ChainEpoch(100+i/2), ce.Height, "budget %d", tc.budget)
+		}
+	}
+
+	_, _, err = QueryEventsPage(ctx, ei, &EventQuery{MinHeight: -1, MaxHeight: 130}, 5)
+	require.Error(t, err)
+}
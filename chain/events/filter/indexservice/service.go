@@ -0,0 +1,90 @@
+// Package indexservice serves an event index over JSON-RPC, so that the nodes of an RPC fleet can
+// share a single historic events database instead of each maintaining their own.
+package indexservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/lotus/chain/events/filter"
+)
+
+// Namespace of the JSON-RPC methods of the service
+const Namespace = "EventIndex"
+
+type server struct {
+	index filter.EventIndexer
+}
+
+func (s *server) StoreEvents(ctx context.Context, ces []*filter.CollectedEvent) error {
+	return s.index.StoreEvents(ctx, ces)
+}
+
+func (s *server) QueryEvents(ctx context.Context, q *filter.EventQuery) ([]*filter.CollectedEvent, error) {
+	return s.index.QueryEvents(ctx, q)
+}
+
+// Handler returns the http.Handler of the service. When token is set, requests must send it as a
+// bearer token.
+func Handler(index filter.EventIndexer, token string) http.Handler {
+	rpcServer := jsonrpc.NewServer()
+	rpcServer.Register(Namespace, &server{index: index})
+
+	if token == "" {
+		return rpcServer
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if subtle.ConstantTimeCompare([]byte(got), []byte(token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		rpcServer.ServeHTTP(w, r)
+	})
+}
+
+// Client is an event indexer backed by an event index service.
+type Client struct {
+	Internal struct {
+		StoreEvents func(ctx context.Context, ces []*filter.CollectedEvent) error
+		QueryEvents func(ctx context.Context, q *filter.EventQuery) ([]*filter.CollectedEvent, error)
+	}
+
+	readOnly bool
+}
+
+var _ filter.EventIndexer = (*Client)(nil)
+
+// NewClient connects to the event index service at addr. A read only client doesn't store events,
+// for fleets where a single node writes to the service.
+func NewClient(ctx context.Context, addr string, token string, readOnly bool) (*Client, jsonrpc.ClientCloser, error) {
+	var header http.Header
+	if token != "" {
+		header = http.Header{"Authorization": []string{"Bearer " + token}}
+	}
+
+	c := &Client{readOnly: readOnly}
+	closer, err := jsonrpc.NewMergeClient(ctx, addr, Namespace, []interface{}{&c.Internal}, header)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("connecting to event index service: %w", err)
+	}
+	return c, closer, nil
+}
+
+func (c *Client) StoreEvents(ctx context.Context, ces []*filter.CollectedEvent) error {
+	if c.readOnly || len(ces) == 0 {
+		return nil
+	}
+	return c.Internal.StoreEvents(ctx, ces)
+}
+
+func (c *Client) QueryEvents(ctx context.Context, q *filter.EventQuery) ([]*filter.CollectedEvent, error) {
+	return c.Internal.QueryEvents(ctx, q)
+}
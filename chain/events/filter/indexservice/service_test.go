@@ -0,0 +1,74 @@
+// stm: #unit
+package indexservice
+
+import (
+	"context"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestEventIndexService(t *testing.T) {
+	ctx := context.Background()
+
+	index, err := filter.NewEventIndex(filepath.Join(t.TempDir(), "events.db"))
+	require.NoError(t, err)
+	defer index.Close() //nolint:errcheck
+
+	srv := httptest.NewServer(Handler(index, "secret"))
+	defer srv.Close()
+
+	emitter, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	ts := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	ev := &filter.CollectedEvent{
+		Entries:     []types.EventEntry{{Flags: types.EventFlagIndexedKey | types.EventFlagIndexedValue, Key: "t1", Codec: 0x55, Value: []byte("value")}},
+		EmitterAddr: emitter,
+		EventIdx:    1,
+		Height:      ts.Height(),
+		TipSetKey:   ts.Key(),
+		MsgIdx:      2,
+		MsgCid:      ts.Cids()[0],
+	}
+
+	c, closer, err := NewClient(ctx, "http://"+srv.Listener.Addr().String(), "secret", false)
+	require.NoError(t, err)
+	defer closer()
+
+	require.NoError(t, c.StoreEvents(ctx, []*filter.CollectedEvent{ev}))
+	// the same events stored by another node are skipped
+	require.NoError(t, c.StoreEvents(ctx, []*filter.CollectedEvent{ev}))
+
+	q := &filter.EventQuery{MinHeight: -1, MaxHeight: -1, Keys: map[string][][]byte{"t1": {[]byte("value")}}}
+	ces, err := c.QueryEvents(ctx, q)
+	require.NoError(t, err)
+	require.Equal(t, []*filter.CollectedEvent{ev}, ces)
+
+	// read only clients don't store events
+	ro, roCloser, err := NewClient(ctx, "http://"+srv.Listener.Addr().String(), "secret", true)
+	require.NoError(t, err)
+	defer roCloser()
+
+	other := *ev
+	other.Height = abi.ChainEpoch(10)
+	require.NoError(t, ro.StoreEvents(ctx, []*filter.CollectedEvent{&other}))
+	ces, err = ro.QueryEvents(ctx, &filter.EventQuery{MinHeight: 5, MaxHeight: -1})
+	require.NoError(t, err)
+	require.Empty(t, ces)
+
+	// requests need the token
+	bad, badCloser, err := NewClient(ctx, "http://"+srv.Listener.Addr().String(), "wrong", false)
+	require.NoError(t, err)
+	defer badCloser()
+	_, err = bad.QueryEvents(ctx, q)
+	require.Error(t, err)
+}
@@ -126,8 +126,11 @@ func (m *MemPoolFilterManager) Install(ctx context.Context) (*MemPoolFilter, err
 		m.filters = make(map[types.FilterID]*MemPoolFilter)
 	}
 	m.filters[id] = f
+	n := len(m.filters)
 	m.mu.Unlock()
 
+	recordInstalledFilters(ctx, "mempool", n)
+
 	return f, nil
 }
 
@@ -138,5 +141,6 @@ func (m *MemPoolFilterManager) Remove(ctx context.Context, id types.FilterID) er
 		return ErrFilterNotFound
 	}
 	delete(m.filters, id)
+	recordInstalledFilters(ctx, "mempool", len(m.filters))
 	return nil
 }
@@ -0,0 +1,102 @@
+package filter
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+)
+
+var ErrEventsNotRetained = errors.New("the events of the address are not retained by the historic event index")
+
+// RetainingIndex stores the events of the emitters retained by its allow and deny lists only, for
+// nodes serving the events of a few contracts. Historic queries of the addresses which aren't
+// retained fail with ErrEventsNotRetained, as their results would be incomplete.
+type RetainingIndex struct {
+	EventIndexer
+
+	allowed map[address.Address]struct{}
+	denied  map[address.Address]struct{}
+}
+
+// NewRetainingIndex wraps index to only retain the events of the allowed addresses, all
+// addresses when empty, except the denied ones.
+func NewRetainingIndex(index EventIndexer, allowed, denied []address.Address) *RetainingIndex {
+	ri := &RetainingIndex{
+		EventIndexer: index,
+		denied:       map[address.Address]struct{}{},
+	}
+	if len(allowed) > 0 {
+		ri.allowed = map[address.Address]struct{}{}
+		for _, a := range allowed {
+			ri.allowed[a] = struct{}{}
+		}
+	}
+	for _, a := range denied {
+		ri.denied[a] = struct{}{}
+	}
+	return ri
+}
+
+// Retains returns whether the events emitted by addr are stored.
+func (ri *RetainingIndex) Retains(addr address.Address) bool {
+	if _, ok := ri.denied[addr]; ok {
+		return false
+	}
+	if ri.allowed == nil {
+		return true
+	}
+	_, ok := ri.allowed[addr]
+	return ok
+}
+
+func (ri *RetainingIndex) StoreEvents(ctx context.Context, ces []*CollectedEvent) error {
+	retained := make([]*CollectedEvent, 0, len(ces))
+	for _, ce := range ces {
+		if ri.Retains(ce.EmitterAddr) {
+			retained = append(retained, ce)
+		}
+	}
+	return ri.EventIndexer.StoreEvents(ctx, retained)
+}
+
+// QueryEvents queries the index, queries without addresses only return the events of the
+// retained addresses.
+func (ri *RetainingIndex) QueryEvents(ctx context.Context, q *EventQuery) ([]*CollectedEvent, error) {
+	var missing []string
+	for _, a := range q.Addresses {
+		if !ri.Retains(a) {
+			missing = append(missing, a.String())
+		}
+	}
+	if len(missing) > 0 {
+		return nil, xerrors.Errorf("%s: %w", strings.Join(missing, ", "), ErrEventsNotRetained)
+	}
+	return ri.EventIndexer.QueryEvents(ctx, q)
+}
+
+// Vacuum vacuums the wrapped index, when it's a local database.
+func (ri *RetainingIndex) Vacuum(ctx context.Context) error {
+	v, ok := ri.EventIndexer.(interface {
+		Vacuum(ctx context.Context) error
+	})
+	if !ok {
+		return nil
+	}
+	return v.Vacuum(ctx)
+}
+
+// Prune deletes the events of the emitters which aren't retained from the wrapped index, when
+// it's a local database, returning the number of deleted events.
+func (ri *RetainingIndex) Prune(ctx context.Context) (int64, error) {
+	ei, ok := ri.EventIndexer.(*EventIndex)
+	if !ok {
+		return 0, nil
+	}
+	return ei.PruneEmitters(ctx, ri.Retains)
+}
+
+var _ EventIndexer = (*RetainingIndex)(nil)
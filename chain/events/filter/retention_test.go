@@ -0,0 +1,85 @@
+package filter
+
+import (
+	"context"
+	pseudo "math/rand"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestRetainingIndex(t *testing.T) {
+	ctx := context.Background()
+	rng := pseudo.New(pseudo.NewSource(299792458))
+
+	a1, a2, a3 := randomF4Addr(t, rng), randomF4Addr(t, rng), randomF4Addr(t, rng)
+	addrMap := addressMap{}
+	addrMap.add(1, a1)
+	addrMap.add(2, a2)
+	addrMap.add(3, a3)
+
+	ei, err := NewEventIndex(filepath.Join(t.TempDir(), "actorevents.db"))
+	require.NoError(t, err, "create event index")
+
+	st := newStore()
+	collect := func(idx EventIndexer, h abi.ChainEpoch) {
+		events := []*types.Event{
+			fakeEvent(1, []kv{{k: "type", v: []byte("transfer")}}, nil),
+			fakeEvent(2, []kv{{k: "type", v: []byte("transfer")}}, nil),
+			fakeEvent(3, []kv{{k: "type", v: []byte("transfer")}}, nil),
+		}
+		em := executedMessage{
+			msg: fakeMessage(randomF4Addr(t, rng), randomF4Addr(t, rng)),
+			rct: fakeReceipt(t, rng, st, events),
+			evs: events,
+		}
+		ces, err := buildTipSetEvents(t, rng, h, em).indexedEvents(ctx, false, addrMap.ResolveAddress)
+		require.NoError(t, err)
+		require.NoError(t, idx.StoreEvents(ctx, ces))
+	}
+	emitters := func(idx EventIndexer, q *EventQuery) []address.Address {
+		ces, err := idx.QueryEvents(ctx, q)
+		require.NoError(t, err)
+		var out []address.Address
+		for _, ce := range ces {
+			out = append(out, ce.EmitterAddr)
+		}
+		return out
+	}
+	all := &EventQuery{MinHeight: -1, MaxHeight: -1}
+
+	// events stored before the lists were configured
+	collect(ei, 100)
+	require.Len(t, emitters(ei, all), 3)
+
+	ri := NewRetainingIndex(ei, []address.Address{a1, a2}, []address.Address{a2})
+	require.True(t, ri.Retains(a1))
+	require.False(t, ri.Retains(a2))
+	require.False(t, ri.Retains(a3))
+
+	collect(ri, 101)
+	require.ElementsMatch(t, []address.Address{a1, a2, a3, a1}, emitters(ri, all))
+
+	// the events stored before are pruned
+	n, err := ri.Prune(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 2, n)
+	require.Equal(t, []address.Address{a1, a1}, emitters(ri, all))
+	require.Equal(t, []address.Address{a1, a1}, emitters(ri, &EventQuery{MinHeight: -1, MaxHeight: -1, Addresses: []address.Address{a1}}))
+
+	// queries of addresses which aren't retained would miss events
+	_, err = ri.QueryEvents(ctx, &EventQuery{MinHeight: -1, MaxHeight: -1, Addresses: []address.Address{a1, a3}})
+	require.True(t, xerrors.Is(err, ErrEventsNotRetained), err)
+
+	// only the deny list
+	ri = NewRetainingIndex(ei, nil, []address.Address{a1})
+	collect(ri, 102)
+	require.ElementsMatch(t, []address.Address{a1, a1, a2, a3}, emitters(ri, all))
+}
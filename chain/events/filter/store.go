@@ -7,9 +7,12 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
 )
 
 type Filter interface {
@@ -32,6 +35,12 @@ var (
 	ErrMaximumNumberOfFilters  = errors.New("maximum number of filters registered")
 )
 
+// recordInstalledFilters records the number of installed filters of a type
+func recordInstalledFilters(ctx context.Context, filterType string, n int) {
+	ctx, _ = tag.New(ctx, tag.Upsert(metrics.FilterType, filterType))
+	stats.Record(ctx, metrics.EventFiltersInstalled.M(int64(n)))
+}
+
 func newFilterID() (types.FilterID, error) {
 	rawid, err := uuid.NewRandom()
 	if err != nil {
@@ -114,8 +114,11 @@ func (m *TipSetFilterManager) Install(ctx context.Context) (*TipSetFilter, error
 		m.filters = make(map[types.FilterID]*TipSetFilter)
 	}
 	m.filters[id] = f
+	n := len(m.filters)
 	m.mu.Unlock()
 
+	recordInstalledFilters(ctx, "tipset", n)
+
 	return f, nil
 }
 
@@ -126,5 +129,6 @@ func (m *TipSetFilterManager) Remove(ctx context.Context, id types.FilterID) err
 		return ErrFilterNotFound
 	}
 	delete(m.filters, id)
+	recordInstalledFilters(ctx, "tipset", len(m.filters))
 	return nil
 }
@@ -0,0 +1,262 @@
+// Package gasstats maintains rolling aggregates of the gas used by the messages
+// executed on chain, per actor code and method.
+package gasstats
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("gasstats")
+
+// DefaultWindow is the number of recent epochs aggregates are kept for.
+const DefaultWindow = abi.ChainEpoch(2880)
+
+type methodKey struct {
+	code   cid.Cid
+	method abi.MethodNum
+}
+
+type methodStats struct {
+	messages   int64
+	failed     int64
+	gasUsed    int64
+	gasLimit   int64
+	maxGasUsed int64
+}
+
+// tipsetStats are the aggregates of the messages executed in the parent of the
+// tipset, whose receipts it includes.
+type tipsetStats struct {
+	key     types.TipSetKey
+	height  abi.ChainEpoch
+	methods map[methodKey]*methodStats
+}
+
+// Tracker follows the chain head, aggregating the gas used by executed
+// messages over a window of recent epochs.
+type Tracker struct {
+	cs     *store.ChainStore
+	sm     *stmgr.StateManager
+	window abi.ChainEpoch
+
+	lk      sync.Mutex
+	tipsets []*tipsetStats // by ascending height
+}
+
+func NewTracker(cs *store.ChainStore, sm *stmgr.StateManager, window abi.ChainEpoch) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Tracker{
+		cs:     cs,
+		sm:     sm,
+		window: window,
+	}
+}
+
+// Run tracks the head changes of the chain until the context is cancelled.
+func (t *Tracker) Run(ctx context.Context) {
+	for changes := range t.cs.SubHeadChanges(ctx) {
+		for _, hc := range changes {
+			var err error
+			switch hc.Type {
+			case store.HCRevert:
+				t.revert(hc.Val)
+			case store.HCApply, store.HCCurrent:
+				err = t.apply(ctx, hc.Val)
+			}
+			if err != nil {
+				log.Warnw("failed to aggregate gas stats", "tipset", hc.Val.Key(), "height", hc.Val.Height(), "error", err)
+			}
+		}
+	}
+}
+
+func (t *Tracker) apply(ctx context.Context, ts *types.TipSet) error {
+	if ts.Height() == 0 {
+		return nil
+	}
+
+	pts, err := t.cs.LoadTipSet(ctx, ts.Parents())
+	if err != nil {
+		return xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	msgs, err := t.cs.MessagesForTipset(ctx, pts)
+	if err != nil {
+		return xerrors.Errorf("loading messages: %w", err)
+	}
+
+	rcts, err := t.cs.ReadReceipts(ctx, ts.Blocks()[0].ParentMessageReceipts)
+	if err != nil {
+		return xerrors.Errorf("loading receipts: %w", err)
+	}
+
+	if len(msgs) != len(rcts) {
+		return xerrors.Errorf("got %d messages but %d receipts", len(msgs), len(rcts))
+	}
+
+	// actors are looked up after execution, then before it for actors deleted
+	// by the messages
+	post, err := t.sm.ParentState(ts)
+	if err != nil {
+		return err
+	}
+	pre, err := t.sm.ParentState(pts)
+	if err != nil {
+		return err
+	}
+
+	tss := &tipsetStats{
+		key:     ts.Key(),
+		height:  ts.Height(),
+		methods: make(map[methodKey]*methodStats),
+	}
+	codes := make(map[string]cid.Cid)
+	for i, cm := range msgs {
+		msg := cm.VMMessage()
+		code, ok := codes[msg.To.String()]
+		if !ok {
+			code = actorCode(post, pre, msg)
+			codes[msg.To.String()] = code
+		}
+
+		tss.add(methodKey{code: code, method: msg.Method}, msg, &rcts[i])
+	}
+
+	t.lk.Lock()
+	defer t.lk.Unlock()
+	t.addTipSet(tss)
+	return nil
+}
+
+func actorCode(post, pre *state.StateTree, msg *types.Message) cid.Cid {
+	for _, st := range []*state.StateTree{post, pre} {
+		if act, err := st.GetActor(msg.To); err == nil {
+			return act.Code
+		}
+	}
+	return cid.Undef
+}
+
+func (tss *tipsetStats) add(k methodKey, msg *types.Message, rct *types.MessageReceipt) {
+	ms, ok := tss.methods[k]
+	if !ok {
+		ms = &methodStats{}
+		tss.methods[k] = ms
+	}
+	ms.messages++
+	if rct.ExitCode != exitcode.Ok {
+		ms.failed++
+	}
+	ms.gasUsed += rct.GasUsed
+	ms.gasLimit += msg.GasLimit
+	if rct.GasUsed > ms.maxGasUsed {
+		ms.maxGasUsed = rct.GasUsed
+	}
+}
+
+// addTipSet must be called with the lock held.
+func (t *Tracker) addTipSet(tss *tipsetStats) {
+	// the head can be re-applied, e.g. as the current tipset of a new subscription
+	for len(t.tipsets) > 0 && t.tipsets[len(t.tipsets)-1].height >= tss.height {
+		t.tipsets = t.tipsets[:len(t.tipsets)-1]
+	}
+	t.tipsets = append(t.tipsets, tss)
+
+	drop := 0
+	for drop < len(t.tipsets) && t.tipsets[drop].height <= tss.height-t.window {
+		drop++
+	}
+	t.tipsets = t.tipsets[drop:]
+}
+
+func (t *Tracker) revert(ts *types.TipSet) {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	if n := len(t.tipsets); n > 0 && t.tipsets[n-1].key == ts.Key() {
+		t.tipsets = t.tipsets[:n-1]
+	}
+}
+
+// Stats returns the aggregates over the given number of recent epochs, or over
+// the whole window when lookback is 0.
+func (t *Tracker) Stats(lookback abi.ChainEpoch) *api.GasStats {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	out := &api.GasStats{
+		Methods: []api.ActorMethodGasStats{},
+	}
+	if len(t.tipsets) == 0 {
+		return out
+	}
+
+	out.To = t.tipsets[len(t.tipsets)-1].height
+	methods := make(map[methodKey]*api.ActorMethodGasStats)
+	for _, tss := range t.tipsets {
+		if lookback > 0 && tss.height <= out.To-lookback {
+			continue
+		}
+		if out.Tipsets == 0 {
+			out.From = tss.height
+		}
+		out.Tipsets++
+
+		for k, ms := range tss.methods {
+			ams, ok := methods[k]
+			if !ok {
+				ams = &api.ActorMethodGasStats{
+					Code:   k.code,
+					Method: k.method,
+				}
+				if k.code.Defined() {
+					ams.Actor = builtin.ActorNameByCode(k.code)
+				}
+				methods[k] = ams
+			}
+			ams.Messages += ms.messages
+			ams.Failed += ms.failed
+			ams.GasUsed += ms.gasUsed
+			ams.GasLimit += ms.gasLimit
+			if ms.maxGasUsed > ams.MaxGasUsed {
+				ams.MaxGasUsed = ms.maxGasUsed
+			}
+
+			out.Messages += ms.messages
+			out.GasUsed += ms.gasUsed
+		}
+	}
+
+	for _, ams := range methods {
+		out.Methods = append(out.Methods, *ams)
+	}
+	sort.Slice(out.Methods, func(i, j int) bool {
+		if out.Methods[i].GasUsed != out.Methods[j].GasUsed {
+			return out.Methods[i].GasUsed > out.Methods[j].GasUsed
+		}
+		if out.Methods[i].Actor != out.Methods[j].Actor {
+			return out.Methods[i].Actor < out.Methods[j].Actor
+		}
+		return out.Methods[i].Method < out.Methods[j].Method
+	})
+
+	return out
+}
@@ -0,0 +1,88 @@
+// stm: #unit
+package gasstats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestTrackerWindow(t *testing.T) {
+	tr := NewTracker(nil, nil, 4)
+
+	code := builtin0.StorageMinerActorCodeID
+	send := methodKey{code: builtin0.AccountActorCodeID, method: builtin.MethodSend}
+	submit := methodKey{code: code, method: 5}
+
+	var nonce uint64
+	tipset := func(h abi.ChainEpoch, msgs ...methodKey) (*tipsetStats, *types.TipSet) {
+		nonce++
+		ts := mock.TipSet(mock.MkBlock(nil, 0, nonce))
+		tss := &tipsetStats{
+			key:     ts.Key(),
+			height:  h,
+			methods: make(map[methodKey]*methodStats),
+		}
+		for i, k := range msgs {
+			rct := &types.MessageReceipt{GasUsed: int64(100 * (i + 1))}
+			if i > 0 {
+				rct.ExitCode = exitcode.ErrForbidden
+			}
+			tss.add(k, &types.Message{GasLimit: 1000}, rct)
+		}
+		return tss, ts
+	}
+	add := func(tss *tipsetStats, _ *types.TipSet) {
+		tr.addTipSet(tss)
+	}
+
+	add(tipset(1, send))
+	add(tipset(2, submit, submit))
+	add(tipset(4, send, submit))
+
+	st := tr.Stats(0)
+	require.Equal(t, abi.ChainEpoch(1), st.From)
+	require.Equal(t, abi.ChainEpoch(4), st.To)
+	require.Equal(t, 3, st.Tipsets)
+	require.Equal(t, int64(5), st.Messages)
+	require.Equal(t, int64(100+300+300), st.GasUsed)
+
+	// sorted by gas used
+	require.Len(t, st.Methods, 2)
+	require.Equal(t, code, st.Methods[0].Code)
+	require.Equal(t, builtin.ActorNameByCode(code), st.Methods[0].Actor)
+	require.Equal(t, int64(3), st.Methods[0].Messages)
+	require.Equal(t, int64(2), st.Methods[0].Failed)
+	require.Equal(t, int64(500), st.Methods[0].GasUsed)
+	require.Equal(t, int64(3000), st.Methods[0].GasLimit)
+	require.Equal(t, int64(200), st.Methods[0].MaxGasUsed)
+
+	st = tr.Stats(1)
+	require.Equal(t, 1, st.Tipsets)
+	require.Equal(t, int64(300), st.GasUsed)
+
+	// tipsets leave the window
+	add(tipset(5))
+	st = tr.Stats(0)
+	require.Equal(t, abi.ChainEpoch(2), st.From)
+	require.Equal(t, 3, st.Tipsets)
+
+	// reverted tipsets are removed, re-applied ones replaced
+	last, lastTs := tipset(5, send)
+	tr.addTipSet(last)
+	tr.revert(lastTs)
+	require.Equal(t, abi.ChainEpoch(4), tr.Stats(0).To)
+	tr.addTipSet(last)
+	add(tipset(5, send, send))
+	st = tr.Stats(0)
+	require.Equal(t, 3, st.Tipsets)
+	require.Equal(t, int64(2+2+2), st.Messages)
+}
@@ -0,0 +1,222 @@
+package gen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/go-homedir"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/build"
+	genesis2 "github.com/filecoin-project/lotus/chain/gen/genesis"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/wallet/key"
+	"github.com/filecoin-project/lotus/genesis"
+)
+
+// DefaultDevnetMnemonic is the mnemonic of the development accounts of
+// common Ethereum tooling, so that devnet accounts match the ones those tools
+// and their documentation use.
+const DefaultDevnetMnemonic = "test test test test test test test test test test test junk"
+
+// devnetUpgrades are the network upgrades following the genesis network
+// version of 2k builds, along with the environment variables setting their
+// heights.
+var devnetUpgrades = []struct {
+	Env     string
+	Network network.Version
+}{
+	{"LOTUS_LIGHTNING_HEIGHT", network.Version19},
+	{"LOTUS_THUNDER_HEIGHT", network.Version20},
+}
+
+// DevnetPreset selects the network version of a devnet genesis and when the
+// following upgrades happen. Upgrade heights are build parameters, the Env
+// variables must be set for all nodes of the devnet (2k builds only). Nodes
+// running in-process can instead use Upgrades, which is nil when the upgrade
+// heights of the build are kept.
+type DevnetPreset struct {
+	Name        string
+	Description string
+
+	NetworkVersion network.Version
+	Env            map[string]string
+	Upgrades       map[network.Version]abi.ChainEpoch
+}
+
+func DevnetPresets() []DevnetPreset {
+	latest := DevnetPreset{
+		Name:           "latest",
+		Description:    "start at the latest network version, without any upgrades",
+		NetworkVersion: devnetUpgrades[len(devnetUpgrades)-1].Network,
+		Env:            map[string]string{},
+		Upgrades:       map[network.Version]abi.ChainEpoch{},
+	}
+	quick := DevnetPreset{
+		Name:           "quick-upgrades",
+		Description:    "start at the genesis network version of the build, and upgrade to the next version every 10 epochs",
+		NetworkVersion: build.GenesisNetworkVersion,
+		Env:            map[string]string{},
+		Upgrades:       map[network.Version]abi.ChainEpoch{},
+	}
+	for i, u := range devnetUpgrades {
+		latest.Env[u.Env] = "-1"
+		latest.Upgrades[u.Network] = -1
+		quick.Env[u.Env] = fmt.Sprint(abi.ChainEpoch(10 * (i + 1)))
+		quick.Upgrades[u.Network] = abi.ChainEpoch(10 * (i + 1))
+	}
+
+	return []DevnetPreset{
+		latest,
+		quick,
+		{
+			Name:           "default",
+			Description:    "start at the genesis network version of the build, with the upgrade heights of the build",
+			NetworkVersion: build.GenesisNetworkVersion,
+		},
+	}
+}
+
+func DevnetPresetByName(name string) (DevnetPreset, error) {
+	for _, p := range DevnetPresets() {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return DevnetPreset{}, xerrors.Errorf("unknown devnet preset %q", name)
+}
+
+type DevnetOptions struct {
+	NetworkName string
+	Preset      DevnetPreset
+
+	// Accounts is the number of pre-funded Ethereum accounts, derived from
+	// Mnemonic and Passphrase
+	Mnemonic   string
+	Passphrase string
+	Accounts   int
+	Balance    abi.TokenAmount
+
+	Contracts []genesis.Contract
+}
+
+// DevnetAccount is a pre-funded account of a devnet genesis
+type DevnetAccount struct {
+	Key        *key.Key
+	EthAddress ethtypes.EthAddress
+}
+
+// DevnetContract is a contract deployed in a devnet genesis
+type DevnetContract struct {
+	Name       string
+	EthAddress ethtypes.EthAddress
+}
+
+// DevnetTemplate builds a genesis template for a local FEVM devnet. The
+// template only depends on the options: the same mnemonic always yields the
+// same accounts, and the same contracts are always deployed at the same
+// addresses. Miners are added to the template as usual.
+func DevnetTemplate(opts DevnetOptions) (*genesis.Template, []DevnetAccount, []DevnetContract, error) {
+	if opts.Preset.NetworkVersion < network.Version18 {
+		return nil, nil, nil, xerrors.Errorf("devnets require network version 18 or later, preset %s uses %d", opts.Preset.Name, opts.Preset.NetworkVersion)
+	}
+
+	tmpl := &genesis.Template{
+		NetworkVersion:   opts.Preset.NetworkVersion,
+		Accounts:         []genesis.Actor{},
+		Miners:           []genesis.Miner{},
+		Contracts:        opts.Contracts,
+		NetworkName:      opts.NetworkName,
+		VerifregRootKey:  DefaultVerifregRootkeyActor,
+		RemainderAccount: DefaultRemainderAccountActor,
+	}
+
+	var accounts []DevnetAccount
+	for i := 0; i < opts.Accounts; i++ {
+		k, err := key.DeriveEthKey(opts.Mnemonic, opts.Passphrase, uint32(i))
+		if err != nil {
+			return nil, nil, nil, xerrors.Errorf("deriving account %d: %w", i, err)
+		}
+		ea, err := ethtypes.EthAddressFromFilecoinAddress(k.Address)
+		if err != nil {
+			return nil, nil, nil, xerrors.Errorf("converting account %d address: %w", i, err)
+		}
+
+		tmpl.Accounts = append(tmpl.Accounts, genesis.Actor{
+			Type:    genesis.TEthAccount,
+			Balance: opts.Balance,
+			Meta:    (&genesis.EthAccountMeta{Address: ea}).ActorMeta(),
+		})
+		accounts = append(accounts, DevnetAccount{Key: k, EthAddress: ea})
+	}
+
+	var contracts []DevnetContract
+	for i, c := range opts.Contracts {
+		ea, err := genesis2.ContractAddress(i)
+		if err != nil {
+			return nil, nil, nil, xerrors.Errorf("computing address of contract %d: %w", i, err)
+		}
+		contracts = append(contracts, DevnetContract{Name: c.Name, EthAddress: ea})
+	}
+
+	return tmpl, accounts, contracts, nil
+}
+
+// ReadDevnetContract reads a contract to deploy in a devnet genesis. The file
+// contains the hex encoded initcode of the contract (e.g. the output of
+// solc --bin), or is a JSON build artifact with a "bytecode" field.
+func ReadDevnetContract(f string) (genesis.Contract, error) {
+	path, err := homedir.Expand(f)
+	if err != nil {
+		return genesis.Contract{}, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return genesis.Contract{}, err
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	code := strings.TrimSpace(string(b))
+	if strings.HasPrefix(code, "{") {
+		// build artifact, the bytecode is either a string or an object with
+		// the code in its object field
+		var artifact struct {
+			ContractName string
+			Bytecode     json.RawMessage
+		}
+		if err := json.Unmarshal(b, &artifact); err != nil {
+			return genesis.Contract{}, xerrors.Errorf("parsing artifact: %w", err)
+		}
+		if artifact.ContractName != "" {
+			name = artifact.ContractName
+		}
+
+		var obj struct{ Object string }
+		if err := json.Unmarshal(artifact.Bytecode, &code); err != nil {
+			if err := json.Unmarshal(artifact.Bytecode, &obj); err != nil {
+				return genesis.Contract{}, xerrors.Errorf("artifact has no bytecode")
+			}
+			code = obj.Object
+		}
+	}
+
+	initcode, err := ethtypes.DecodeHexStringTrimSpace(code)
+	if err != nil {
+		return genesis.Contract{}, xerrors.Errorf("decoding initcode: %w", err)
+	}
+	if len(initcode) == 0 {
+		return genesis.Contract{}, xerrors.Errorf("empty initcode")
+	}
+
+	return genesis.Contract{
+		Name:     name,
+		Initcode: initcode,
+	}, nil
+}
@@ -0,0 +1,71 @@
+// stm: #unit
+package gen
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	genesis2 "github.com/filecoin-project/lotus/chain/gen/genesis"
+	"github.com/filecoin-project/lotus/genesis"
+	_ "github.com/filecoin-project/lotus/lib/sigs/delegated"
+)
+
+func TestDevnetTemplate(t *testing.T) {
+	preset, err := DevnetPresetByName("latest")
+	require.NoError(t, err)
+	require.Equal(t, network.Version20, preset.NetworkVersion)
+	_, err = DevnetPresetByName("nope")
+	require.Error(t, err)
+
+	opts := DevnetOptions{
+		NetworkName: "devnet",
+		Preset:      preset,
+		Mnemonic:    DefaultDevnetMnemonic,
+		Accounts:    3,
+		Balance:     big.NewInt(1000),
+		Contracts:   []genesis.Contract{{Name: "a", Initcode: []byte{0x60}}, {Name: "b", Initcode: []byte{0x61}}},
+	}
+
+	tmpl, accounts, contracts, err := DevnetTemplate(opts)
+	require.NoError(t, err)
+	require.Len(t, tmpl.Accounts, 3)
+	require.Len(t, accounts, 3)
+	require.Equal(t, "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266", accounts[0].EthAddress.String())
+	require.Len(t, contracts, 2)
+	require.NotEqual(t, contracts[0].EthAddress, contracts[1].EthAddress)
+
+	// deterministic
+	tmpl2, _, contracts2, err := DevnetTemplate(opts)
+	require.NoError(t, err)
+	require.Equal(t, tmpl, tmpl2)
+	require.Equal(t, contracts, contracts2)
+
+	// the eth accounts are created in the genesis state
+	st, keyIDs, err := genesis2.MakeInitialStateTree(context.Background(), blockstore.NewMemory(), *tmpl)
+	require.NoError(t, err)
+	for _, a := range accounts {
+		ida, ok := keyIDs[a.Key.Address]
+		require.True(t, ok)
+
+		act, err := st.GetActor(a.Key.Address)
+		require.NoError(t, err)
+		require.True(t, builtin.IsEthAccountActor(act.Code))
+		require.Equal(t, big.NewInt(1000), act.Balance)
+		require.Equal(t, a.Key.Address, *act.Address)
+
+		resolved, err := st.LookupID(a.Key.Address)
+		require.NoError(t, err)
+		require.Equal(t, ida, resolved)
+	}
+
+	opts.Preset.NetworkVersion = network.Version17
+	_, _, _, err = DevnetTemplate(opts)
+	require.Error(t, err)
+}
@@ -76,25 +76,38 @@ func SetupInitActor(ctx context.Context, bs bstore.Blockstore, netname string, i
 			continue
 		}
 
-		if a.Type != genesis.TAccount {
+		var owner address.Address
+		switch a.Type {
+		case genesis.TAccount:
+			var ainfo genesis.AccountMeta
+			if err := json.Unmarshal(a.Meta, &ainfo); err != nil {
+				return 0, nil, nil, xerrors.Errorf("unmarshaling account meta: %w", err)
+			}
+			owner = ainfo.Owner
+		case genesis.TEthAccount:
+			var ainfo genesis.EthAccountMeta
+			if err := json.Unmarshal(a.Meta, &ainfo); err != nil {
+				return 0, nil, nil, xerrors.Errorf("unmarshaling eth account meta: %w", err)
+			}
+			var err error
+			owner, err = ainfo.Address.ToFilecoinAddress()
+			if err != nil {
+				return 0, nil, nil, xerrors.Errorf("converting eth account address: %w", err)
+			}
+		default:
 			return 0, nil, nil, xerrors.Errorf("unsupported account type: %s", a.Type)
 		}
 
-		var ainfo genesis.AccountMeta
-		if err := json.Unmarshal(a.Meta, &ainfo); err != nil {
-			return 0, nil, nil, xerrors.Errorf("unmarshaling account meta: %w", err)
-		}
-
-		fmt.Printf("init set %s t0%d\n", ainfo.Owner, counter)
+		fmt.Printf("init set %s t0%d\n", owner, counter)
 
 		value := cbg.CborInt(counter)
-		if err := amap.Put(abi.AddrKey(ainfo.Owner), &value); err != nil {
+		if err := amap.Put(abi.AddrKey(owner), &value); err != nil {
 			return 0, nil, nil, err
 		}
 		counter = counter + 1
 
 		var err error
-		keyToId[ainfo.Owner], err = address.NewIDAddress(uint64(value))
+		keyToId[owner], err = address.NewIDAddress(uint64(value))
 		if err != nil {
 			return 0, nil, nil, err
 		}
@@ -238,6 +238,10 @@ func MakeInitialStateTree(ctx context.Context, bs bstore.Blockstore, template ge
 			if err := CreateAccountActor(ctx, cst, state, info, keyIDs, av); err != nil {
 				return nil, nil, xerrors.Errorf("failed to create account actor: %w", err)
 			}
+		case genesis.TEthAccount:
+			if err := CreateEthAccountActor(state, info, keyIDs, av); err != nil {
+				return nil, nil, xerrors.Errorf("failed to create eth account actor: %w", err)
+			}
 		case genesis.TMultisig:
 			ida, err := address.NewIDAddress(uint64(idStart))
 			if err != nil {
@@ -598,6 +602,12 @@ func MakeGenesisBlock(ctx context.Context, j journal.Journal, bs bstore.Blocksto
 		return nil, xerrors.Errorf("failed to flush state tree: %w", err)
 	}
 
+	// Deploy contracts
+	stateroot, err = SetupContracts(ctx, cs, sys, stateroot, template.Contracts, template.NetworkVersion)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to deploy contracts: %w", err)
+	}
+
 	store := adt.WrapStore(ctx, cbor.NewCborStore(bs))
 	emptyroot, err := adt0.MakeEmptyArray(store).Root()
 	if err != nil {
@@ -1,25 +1,33 @@
 package genesis
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"fmt"
 
+	"github.com/ipfs/go-cid"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	actorstypes "github.com/filecoin-project/go-state-types/actors"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v10/eam"
 	"github.com/filecoin-project/go-state-types/manifest"
 	"github.com/filecoin-project/go-state-types/network"
 
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/actors/adt"
 	init_ "github.com/filecoin-project/lotus/chain/actors/builtin/init"
+	"github.com/filecoin-project/lotus/chain/consensus"
 	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/chain/vm"
+	"github.com/filecoin-project/lotus/genesis"
 )
 
 // EthNullAddresses are the Ethereum addresses we want to create zero-balanced EthAccounts in.
@@ -55,6 +63,11 @@ func SetupEAM(_ context.Context, nst *state.StateTree, nv network.Version) error
 
 // MakeEthNullAddressActor creates a null address actor at the specified Ethereum address.
 func MakeEthNullAddressActor(av actorstypes.Version, addr address.Address) (*types.Actor, error) {
+	return MakeEthAccountActor(av, addr, big.Zero())
+}
+
+// MakeEthAccountActor creates an EthAccount actor at the specified f4 address.
+func MakeEthAccountActor(av actorstypes.Version, addr address.Address, bal types.BigInt) (*types.Actor, error) {
 	actcid, ok := actors.GetActorCodeID(av, manifest.EthAccountKey)
 	if !ok {
 		return nil, xerrors.Errorf("failed to get EthAccount actor code ID for actors version %d", av)
@@ -64,13 +77,122 @@ func MakeEthNullAddressActor(av actorstypes.Version, addr address.Address) (*typ
 		Code:    actcid,
 		Head:    vm.EmptyObjectCid,
 		Nonce:   0,
-		Balance: big.Zero(),
+		Balance: bal,
 		Address: &addr,
 	}
 
 	return act, nil
 }
 
+func CreateEthAccountActor(st *state.StateTree, info genesis.Actor, keyIDs map[address.Address]address.Address, av actorstypes.Version) error {
+	if av < actorstypes.Version10 {
+		return xerrors.Errorf("eth accounts require actors v10 or later, got %d", av)
+	}
+
+	var ainfo genesis.EthAccountMeta
+	if err := json.Unmarshal(info.Meta, &ainfo); err != nil {
+		return xerrors.Errorf("unmarshaling eth account meta: %w", err)
+	}
+
+	addr, err := ainfo.Address.ToFilecoinAddress()
+	if err != nil {
+		return xerrors.Errorf("converting eth account address: %w", err)
+	}
+
+	ida, ok := keyIDs[addr]
+	if !ok {
+		return fmt.Errorf("no registered ID for eth account actor: %s", ainfo.Address)
+	}
+
+	act, err := MakeEthAccountActor(av, addr, info.Balance)
+	if err != nil {
+		return err
+	}
+
+	if err := st.SetActor(ida, act); err != nil {
+		return xerrors.Errorf("setting eth account actor: %w", err)
+	}
+	return nil
+}
+
+// ContractAddress returns the Ethereum address of the contract at the given
+// index of the genesis template.
+func ContractAddress(index int) (ethtypes.EthAddress, error) {
+	deployer, err := ethtypes.EthAddressFromFilecoinAddress(builtin.SystemActorAddr)
+	if err != nil {
+		return ethtypes.EthAddress{}, err
+	}
+	return deployer.CreateAddress(uint64(index))
+}
+
+// SetupContracts deploys the contracts of the genesis template, running their
+// initcode through the EAM on behalf of the system actor.
+func SetupContracts(ctx context.Context, cs *store.ChainStore, sys vm.SyscallBuilder, stateroot cid.Cid, contracts []genesis.Contract, nv network.Version) (cid.Cid, error) {
+	if len(contracts) == 0 {
+		return stateroot, nil
+	}
+
+	av, err := actorstypes.VersionForNetwork(nv)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to get actors version for network version %d: %w", nv, err)
+	}
+	if av < actorstypes.Version10 {
+		return cid.Undef, xerrors.Errorf("contracts require actors v10 or later, got %d", av)
+	}
+
+	csc := func(context.Context, abi.ChainEpoch, *state.StateTree) (abi.TokenAmount, error) {
+		return big.Zero(), nil
+	}
+
+	vmopt := vm.VMOpts{
+		StateBase:      stateroot,
+		Epoch:          0,
+		Rand:           &fakeRand{},
+		Bstore:         cs.StateBlockstore(),
+		Actors:         consensus.NewActorRegistry(),
+		Syscalls:       mkFakedSigSyscalls(sys),
+		CircSupplyCalc: csc,
+		NetworkVersion: nv,
+		BaseFee:        big.Zero(),
+	}
+	vmi, err := vm.NewVM(ctx, &vmopt)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("failed to create VM: %w", err)
+	}
+
+	for i, c := range contracts {
+		expected, err := ContractAddress(i)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("computing contract address: %w", err)
+		}
+
+		ret, err := doExecValue(ctx, vmi, builtin.EthereumAddressManagerActorAddr, builtin.SystemActorAddr, big.Zero(), builtin.MethodsEAM.Create, mustEnc(&eam.CreateParams{
+			Initcode: c.Initcode,
+			Nonce:    uint64(i),
+		}))
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("deploying contract %d (%s): %w", i, c.Name, err)
+		}
+
+		var res eam.CreateReturn
+		if err := res.UnmarshalCBOR(bytes.NewReader(ret)); err != nil {
+			return cid.Undef, xerrors.Errorf("decoding create return of contract %d (%s): %w", i, c.Name, err)
+		}
+		if ethtypes.EthAddress(res.EthAddress) != expected {
+			return cid.Undef, xerrors.Errorf("contract %d (%s) deployed at %s, expected %s", i, c.Name, ethtypes.EthAddress(res.EthAddress), expected)
+		}
+
+		log.Infof("deployed contract %d (%s) at %s (f0%d)", i, c.Name, expected, res.ActorID)
+	}
+
+	st, err := vmi.Flush(ctx)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("vm flush: %w", err)
+	}
+
+	return st, nil
+}
+
 func SetupEthNullAddresses(ctx context.Context, st *state.StateTree, nv network.Version) ([]address.Address, error) {
 	av, err := actorstypes.VersionForNetwork(nv)
 	if err != nil {
@@ -0,0 +1,490 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"strings"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var addrDbName = "addrindex.db"
+var addrDbDefs = []string{
+	`CREATE TABLE IF NOT EXISTS address_messages (
+     address VARCHAR(100) NOT NULL,
+     cid VARCHAR(80) NOT NULL,
+     sent INTEGER NOT NULL,
+     tipset_cid VARCHAR(80) NOT NULL,
+     epoch INTEGER NOT NULL,
+     selector BLOB,
+     to_address VARCHAR(100),
+     PRIMARY KEY (address, cid, sent) ON CONFLICT REPLACE
+   )`,
+	`CREATE INDEX IF NOT EXISTS address_epochs ON address_messages (address, epoch)
+  `,
+	`CREATE INDEX IF NOT EXISTS address_tipset_cids ON address_messages (tipset_cid)
+  `,
+	`CREATE TABLE IF NOT EXISTS _meta (
+    	version UINT64 NOT NULL UNIQUE
+	)`,
+}
+
+// addrDbVersion is the version of the schema, databases of earlier versions
+// are upgraded with the statements of addrDbUpgrades when opened.
+const addrDbVersion = 2
+
+var addrDbUpgrades = map[int][]string{
+	// version 2, messages record the selector of the EVM function they call
+	// and their recipient. Messages indexed before have neither.
+	2: {
+		`ALTER TABLE address_messages ADD COLUMN selector BLOB`,
+		`ALTER TABLE address_messages ADD COLUMN to_address VARCHAR(100)`,
+	},
+}
+
+// addrDbIndexes are created once the schema is upgraded.
+var addrDbIndexes = []string{
+	`CREATE INDEX IF NOT EXISTS address_selectors ON address_messages (address, selector, epoch)
+  `,
+}
+
+const (
+	// prepared stmts
+	dbqInsertAddrMessage        = "INSERT INTO address_messages (address, cid, sent, tipset_cid, epoch, selector, to_address) VALUES (?, ?, ?, ?, ?, ?, ?)"
+	dbqDeleteTipsetAddrMessages = "DELETE FROM address_messages WHERE tipset_cid = ?"
+	// reconciliation
+	dbqCountAddrMessages         = "SELECT COUNT(*) FROM address_messages"
+	dbqMinAddrEpoch              = "SELECT MIN(epoch) FROM address_messages"
+	dbqCountTipsetAddrMessages   = "SELECT COUNT(*) FROM address_messages WHERE tipset_cid = ?"
+	dbqDeleteAddrMessagesByEpoch = "DELETE FROM address_messages WHERE epoch >= ?"
+)
+
+var addrIndexReconcileQueries = reconcileQueries{
+	count:         dbqCountAddrMessages,
+	minEpoch:      dbqMinAddrEpoch,
+	countTipset:   dbqCountTipsetAddrMessages,
+	deleteByEpoch: dbqDeleteAddrMessagesByEpoch,
+}
+
+type addrIndex struct {
+	cs ChainStore
+
+	db               *sql.DB
+	insertMsgStmt    *sql.Stmt
+	deleteTipSetStmt *sql.Stmt
+
+	sema chan struct{}
+	mx   sync.Mutex
+	pend []headChange
+
+	cancel  func()
+	workers sync.WaitGroup
+	closeLk sync.RWMutex
+	closed  bool
+}
+
+var _ AddrIndex = (*addrIndex)(nil)
+
+// NewAddrIndex opens the address index in basePath, and keeps it up to date
+// with the tipsets applied from now on.
+func NewAddrIndex(lctx context.Context, basePath string, cs ChainStore) (AddrIndex, error) {
+	var (
+		dbPath string
+		exists bool
+		err    error
+	)
+
+	err = os.MkdirAll(basePath, 0755)
+	if err != nil {
+		return nil, xerrors.Errorf("error creating addrindex base directory: %w", err)
+	}
+
+	dbPath = path.Join(basePath, addrDbName)
+	_, err = os.Stat(dbPath)
+	switch {
+	case err == nil:
+		exists = true
+
+	case errors.Is(err, fs.ErrNotExist):
+
+	case err != nil:
+		return nil, xerrors.Errorf("error stating addrindex database: %w", err)
+	}
+
+	db, err := sql.Open("sqlite3", dbPath)
+	if err != nil {
+		return nil, xerrors.Errorf("error opening addrindex database: %w", err)
+	}
+
+	for _, stmt := range addrDbDefs {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, xerrors.Errorf("error executing sql statement '%s': %w", stmt, err)
+		}
+	}
+
+	if err := upgradeAddrIndex(db); err != nil {
+		_ = db.Close()
+		return nil, xerrors.Errorf("error upgrading addrindex database: %w", err)
+	}
+
+	for _, stmt := range addrDbIndexes {
+		if _, err := db.Exec(stmt); err != nil {
+			_ = db.Close()
+			return nil, xerrors.Errorf("error executing sql statement '%s': %w", stmt, err)
+		}
+	}
+
+	if exists {
+		if err := reconcileIndex(db, cs, addrIndexReconcileQueries); err != nil {
+			_ = db.Close()
+			return nil, xerrors.Errorf("error reconciling addrindex database: %w", err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(lctx)
+
+	x := &addrIndex{
+		db:     db,
+		cs:     cs,
+		sema:   make(chan struct{}, 1),
+		cancel: cancel,
+	}
+
+	if x.insertMsgStmt, err = db.Prepare(dbqInsertAddrMessage); err == nil {
+		x.deleteTipSetStmt, err = db.Prepare(dbqDeleteTipsetAddrMessages)
+	}
+	if err != nil {
+		cancel()
+		if err := db.Close(); err != nil {
+			log.Errorf("error closing addrindex database: %s", err)
+		}
+
+		return nil, xerrors.Errorf("error preparing addrindex database statements: %w", err)
+	}
+
+	rnf := store.WrapHeadChangeCoalescer(
+		x.onHeadChange,
+		CoalesceMinDelay,
+		CoalesceMaxDelay,
+		CoalesceMergeInterval,
+	)
+	cs.SubscribeHeadChanges(rnf)
+
+	x.workers.Add(1)
+	go x.background(ctx)
+
+	return x, nil
+}
+
+// upgradeAddrIndex brings the schema of the database to addrDbVersion.
+// Databases without a version were created with the current schema.
+func upgradeAddrIndex(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("SELECT COALESCE(MAX(version), 0) FROM _meta").Scan(&version); err != nil {
+		return xerrors.Errorf("error reading schema version: %w", err)
+	}
+	if version == 0 {
+		_, err := db.Exec("INSERT INTO _meta (version) VALUES (?)", addrDbVersion)
+		return err
+	}
+	if version > addrDbVersion {
+		return xerrors.Errorf("invalid database version: got %d, expected %d", version, addrDbVersion)
+	}
+
+	for v := version + 1; v <= addrDbVersion; v++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return xerrors.Errorf("error creating transaction: %w", err)
+		}
+		for _, stmt := range addrDbUpgrades[v] {
+			if _, err := tx.Exec(stmt); err != nil {
+				_ = tx.Rollback()
+				return xerrors.Errorf("error executing sql statement '%s': %w", stmt, err)
+			}
+		}
+		if _, err := tx.Exec("INSERT INTO _meta (version) VALUES (?)", v); err != nil {
+			_ = tx.Rollback()
+			return xerrors.Errorf("error recording schema version %d: %w", v, err)
+		}
+		if err := tx.Commit(); err != nil {
+			return xerrors.Errorf("error upgrading to schema version %d: %w", v, err)
+		}
+		log.Infof("upgraded address index to schema version %d", v)
+	}
+
+	return nil
+}
+
+// head change notifee
+func (x *addrIndex) onHeadChange(rev, app []*types.TipSet) error {
+	x.closeLk.RLock()
+	defer x.closeLk.RUnlock()
+
+	if x.closed {
+		return nil
+	}
+
+	// do it in the background to avoid blocking head change processing
+	x.mx.Lock()
+	x.pend = append(x.pend, headChange{rev: rev, app: app})
+	pendLen := len(x.pend)
+	x.mx.Unlock()
+
+	if pendLen > 10 {
+		log.Warnf("address index head change processing is building backlog: %d pending head changes", pendLen)
+	}
+
+	select {
+	case x.sema <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (x *addrIndex) background(ctx context.Context) {
+	defer x.workers.Done()
+
+	for {
+		select {
+		case <-x.sema:
+			err := x.processHeadChanges(ctx)
+			if err != nil {
+				// we can't rely on an inconsistent index, so shut it down.
+				log.Errorf("error processing head change notifications: %s; shutting down address index", err)
+				// Close waits for this worker to exit
+				go func() {
+					if err2 := x.Close(); err2 != nil {
+						log.Errorf("error shutting down index: %s", err2)
+					}
+				}()
+			}
+
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (x *addrIndex) processHeadChanges(ctx context.Context) error {
+	x.mx.Lock()
+	pend := x.pend
+	x.pend = nil
+	x.mx.Unlock()
+
+	tx, err := x.db.Begin()
+	if err != nil {
+		return xerrors.Errorf("error creating transaction: %w", err)
+	}
+
+	for _, hc := range pend {
+		for _, ts := range hc.rev {
+			if err := x.doRevert(tx, ts); err != nil {
+				if err2 := tx.Rollback(); err2 != nil {
+					log.Errorf("error rolling back transaction: %s", err2)
+				}
+				return xerrors.Errorf("error reverting %s: %w", ts, err)
+			}
+		}
+
+		for _, ts := range hc.app {
+			if err := x.doApply(ctx, tx, ts); err != nil {
+				if err2 := tx.Rollback(); err2 != nil {
+					log.Errorf("error rolling back transaction: %s", err2)
+				}
+				return xerrors.Errorf("error applying %s: %w", ts, err)
+			}
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (x *addrIndex) doRevert(tx *sql.Tx, ts *types.TipSet) error {
+	tskey, err := ts.Key().Cid()
+	if err != nil {
+		return xerrors.Errorf("error computing tipset cid: %w", err)
+	}
+
+	_, err = tx.Stmt(x.deleteTipSetStmt).Exec(tskey.String())
+	return err
+}
+
+func (x *addrIndex) doApply(ctx context.Context, tx *sql.Tx, ts *types.TipSet) error {
+	tscid, err := ts.Key().Cid()
+	if err != nil {
+		return xerrors.Errorf("error computing tipset cid: %w", err)
+	}
+
+	tskey := tscid.String()
+	epoch := int64(ts.Height())
+
+	msgs, err := x.cs.MessagesForTipset(ctx, ts)
+	if err != nil {
+		return xerrors.Errorf("error retrieving messages for tipset %s: %w", ts, err)
+	}
+
+	insertStmt := tx.Stmt(x.insertMsgStmt)
+	for _, msg := range msgs {
+		key := msg.Cid().String()
+		vmsg := msg.VMMessage()
+		selector := CallSelector(vmsg)
+		to := vmsg.To.String()
+		if _, err := insertStmt.Exec(vmsg.From.String(), key, true, tskey, epoch, selector, to); err != nil {
+			return xerrors.Errorf("error inserting message: %w", err)
+		}
+		if _, err := insertStmt.Exec(to, key, false, tskey, epoch, selector, to); err != nil {
+			return xerrors.Errorf("error inserting message: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// CallSelector returns the 4 byte selector of the EVM function called by a
+// contract invocation, nil for other messages.
+func CallSelector(m *types.Message) []byte {
+	if m.Method != builtintypes.MethodsEVM.InvokeContract {
+		return nil
+	}
+	input, err := cbg.ReadByteArray(bytes.NewReader(m.Params), uint64(len(m.Params)))
+	if err != nil || len(input) < 4 {
+		return nil
+	}
+	return input[:4]
+}
+
+// interface
+func (x *addrIndex) GetAddrMessages(ctx context.Context, addrs []address.Address, q AddrQuery) ([]AddrMsgInfo, error) {
+	x.closeLk.RLock()
+	defer x.closeLk.RUnlock()
+
+	if x.closed {
+		return nil, ErrClosed
+	}
+
+	if len(addrs) == 0 {
+		return nil, nil
+	}
+
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	placeholders := make([]string, len(addrs))
+	for i, a := range addrs {
+		placeholders[i] = "?"
+		args = append(args, a.String())
+	}
+	conds = append(conds, "address IN ("+strings.Join(placeholders, ", ")+")")
+
+	if q.MinEpoch >= 0 {
+		conds = append(conds, "epoch >= ?")
+		args = append(args, int64(q.MinEpoch))
+	}
+	if q.MaxEpoch >= 0 {
+		conds = append(conds, "epoch <= ?")
+		args = append(args, int64(q.MaxEpoch))
+	}
+	if q.Sent != q.Received {
+		conds = append(conds, "sent = ?")
+		args = append(args, q.Sent)
+	}
+	if q.Selector != nil {
+		conds = append(conds, "selector = ?")
+		args = append(args, q.Selector)
+	}
+	if len(q.To) > 0 {
+		placeholders := make([]string, len(q.To))
+		for i, a := range q.To {
+			placeholders[i] = "?"
+			args = append(args, a.String())
+		}
+		conds = append(conds, "to_address IN ("+strings.Join(placeholders, ", ")+")")
+	}
+	if q.After != nil {
+		// messages sent and received by the addresses share the cid, sent
+		// ones come first
+		conds = append(conds, "(epoch < ? OR (epoch = ? AND (cid > ? OR (cid = ? AND sent < ?))))")
+		key := q.After.Message.String()
+		args = append(args, int64(q.After.Epoch), int64(q.After.Epoch), key, key, q.After.Sent)
+	}
+
+	query := "SELECT cid, sent, tipset_cid, epoch, selector FROM address_messages WHERE " + strings.Join(conds, " AND ") +
+		" ORDER BY epoch DESC, cid ASC, sent DESC"
+	if q.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, q.Limit)
+	}
+
+	rows, err := x.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, xerrors.Errorf("error querying addrindex database: %w", err)
+	}
+	defer rows.Close() //nolint:errcheck
+
+	var out []AddrMsgInfo
+	for rows.Next() {
+		var (
+			msg, tipset string
+			sent        bool
+			epoch       int64
+			selector    []byte
+		)
+		if err := rows.Scan(&msg, &sent, &tipset, &epoch, &selector); err != nil {
+			return nil, xerrors.Errorf("error reading addrindex row: %w", err)
+		}
+
+		msgCid, err := cid.Decode(msg)
+		if err != nil {
+			return nil, xerrors.Errorf("error decoding message cid: %w", err)
+		}
+		tipsetCid, err := cid.Decode(tipset)
+		if err != nil {
+			return nil, xerrors.Errorf("error decoding tipset cid: %w", err)
+		}
+
+		out = append(out, AddrMsgInfo{
+			Message:  msgCid,
+			TipSet:   tipsetCid,
+			Epoch:    abi.ChainEpoch(epoch),
+			Sent:     sent,
+			Selector: selector,
+		})
+	}
+
+	return out, rows.Err()
+}
+
+func (x *addrIndex) Close() error {
+	x.closeLk.Lock()
+	defer x.closeLk.Unlock()
+
+	if x.closed {
+		return nil
+	}
+
+	x.closed = true
+
+	x.cancel()
+	x.workers.Wait()
+
+	return x.db.Close()
+}
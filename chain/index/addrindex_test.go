@@ -0,0 +1,243 @@
+package index
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestAddrIndex(t *testing.T) {
+	cs := newMockChainStore()
+	cs.genesis()
+
+	tmp := t.TempDir()
+	t.Cleanup(func() { _ = os.RemoveAll(tmp) })
+
+	addrIndex, err := NewAddrIndex(context.Background(), tmp, cs)
+	require.NoError(t, err)
+
+	defer addrIndex.Close() //nolint
+
+	alice, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	bob, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	// alice sends a message to bob at every epoch, bob sends one back at
+	// even epochs
+	advance := func() *types.TipSet {
+		ts := cs.makeBlk()
+		msgs := []types.ChainMsg{cs.makeMsg(), &types.Message{From: alice, To: bob, Nonce: uint64(ts.Height())}}
+		if ts.Height()%2 == 0 {
+			msgs = append(msgs, &types.Message{From: bob, To: alice, Nonce: uint64(ts.Height())})
+		}
+		cs.msgs[ts.Key()] = msgs
+		require.NoError(t, cs.reorg(nil, []*types.TipSet{ts}))
+		return ts
+	}
+
+	for i := 0; i < 10; i++ {
+		advance()
+	}
+
+	waitForCoalescerAfterLastEvent()
+
+	ctx := context.Background()
+	all := AddrQuery{MinEpoch: -1, MaxEpoch: -1}
+
+	res, err := addrIndex.GetAddrMessages(ctx, []address.Address{alice}, all)
+	require.NoError(t, err)
+	require.Len(t, res, 15)
+	require.Equal(t, cs.curTs.Height(), res[0].Epoch)
+	for i := 1; i < len(res); i++ {
+		require.GreaterOrEqual(t, res[i-1].Epoch, res[i].Epoch)
+	}
+
+	sent, err := addrIndex.GetAddrMessages(ctx, []address.Address{alice}, AddrQuery{MinEpoch: -1, MaxEpoch: -1, Sent: true})
+	require.NoError(t, err)
+	require.Len(t, sent, 10)
+	for _, m := range sent {
+		require.True(t, m.Sent)
+	}
+
+	received, err := addrIndex.GetAddrMessages(ctx, []address.Address{alice}, AddrQuery{MinEpoch: 3, MaxEpoch: 6, Received: true})
+	require.NoError(t, err)
+	require.Len(t, received, 2)
+	require.EqualValues(t, 6, received[0].Epoch)
+	require.EqualValues(t, 4, received[1].Epoch)
+	require.False(t, received[0].Sent)
+
+	// paging returns the same messages
+	var paged []AddrMsgInfo
+	q := all
+	q.Limit = 4
+	for {
+		page, err := addrIndex.GetAddrMessages(ctx, []address.Address{alice}, q)
+		require.NoError(t, err)
+		paged = append(paged, page...)
+		if len(page) < q.Limit {
+			break
+		}
+		q.After = &page[len(page)-1]
+	}
+	require.Equal(t, res, paged)
+
+	// reverted messages are removed
+	reorgme := cs.curTs
+	reorgmeParent, err := cs.GetTipSetFromKey(ctx, reorgme.Parents())
+	require.NoError(t, err)
+	require.NoError(t, cs.reorg([]*types.TipSet{reorgme}, nil))
+	cs.setHead(reorgmeParent)
+
+	waitForCoalescerAfterLastEvent()
+
+	res, err = addrIndex.GetAddrMessages(ctx, []address.Address{bob}, all)
+	require.NoError(t, err)
+	require.Len(t, res, 13)
+	require.Equal(t, reorgmeParent.Height(), res[0].Epoch)
+}
+
+func invokeContract(t *testing.T, from, to address.Address, nonce uint64, input []byte) *types.Message {
+	var params bytes.Buffer
+	require.NoError(t, cbg.WriteByteArray(&params, input))
+	return &types.Message{
+		From:   from,
+		To:     to,
+		Nonce:  nonce,
+		Method: builtintypes.MethodsEVM.InvokeContract,
+		Params: params.Bytes(),
+	}
+}
+
+func TestAddrIndexSelector(t *testing.T) {
+	cs := newMockChainStore()
+	cs.genesis()
+
+	addrIndex, err := NewAddrIndex(context.Background(), t.TempDir(), cs)
+	require.NoError(t, err)
+
+	defer addrIndex.Close() //nolint
+
+	alice, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	token, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	other, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+
+	transfer := []byte{0xa9, 0x05, 0x9c, 0xbb}
+	approve := []byte{0x09, 0x5e, 0xa7, 0xb3}
+
+	ts := cs.makeBlk()
+	cs.msgs[ts.Key()] = []types.ChainMsg{
+		invokeContract(t, alice, token, 0, append(transfer, make([]byte, 64)...)),
+		invokeContract(t, alice, token, 1, append(approve, make([]byte, 64)...)),
+		invokeContract(t, alice, other, 2, transfer),
+		invokeContract(t, alice, token, 3, nil),
+		&types.Message{From: alice, To: token, Nonce: 4},
+	}
+	require.NoError(t, cs.reorg(nil, []*types.TipSet{ts}))
+
+	waitForCoalescerAfterLastEvent()
+
+	ctx := context.Background()
+	all := AddrQuery{MinEpoch: -1, MaxEpoch: -1}
+
+	// calls to transfer() on the token
+	q := all
+	q.Received = true
+	q.Selector = transfer
+	res, err := addrIndex.GetAddrMessages(ctx, []address.Address{token}, q)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, cs.msgs[ts.Key()][0].Cid(), res[0].Message)
+	require.Equal(t, transfer, res[0].Selector)
+
+	// transfer() calls of alice, to any contract and to the token
+	q = all
+	q.Sent = true
+	q.Selector = transfer
+	res, err = addrIndex.GetAddrMessages(ctx, []address.Address{alice}, q)
+	require.NoError(t, err)
+	require.Len(t, res, 2)
+
+	q.To = []address.Address{token}
+	res, err = addrIndex.GetAddrMessages(ctx, []address.Address{alice}, q)
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.Equal(t, cs.msgs[ts.Key()][0].Cid(), res[0].Message)
+
+	// messages without calldata have no selector
+	res, err = addrIndex.GetAddrMessages(ctx, []address.Address{token}, all)
+	require.NoError(t, err)
+	require.Len(t, res, 4)
+	var withoutSelector int
+	for _, m := range res {
+		if m.Selector == nil {
+			withoutSelector++
+		}
+	}
+	require.Equal(t, 2, withoutSelector)
+}
+
+func TestAddrIndexUpgrade(t *testing.T) {
+	cs := newMockChainStore()
+	cs.genesis()
+
+	tmp := t.TempDir()
+
+	// a version 1 database, without selectors and recipients
+	db, err := sql.Open("sqlite3", path.Join(tmp, addrDbName))
+	require.NoError(t, err)
+	for _, stmt := range []string{
+		`CREATE TABLE address_messages (
+		address VARCHAR(100) NOT NULL,
+		cid VARCHAR(80) NOT NULL,
+		sent INTEGER NOT NULL,
+		tipset_cid VARCHAR(80) NOT NULL,
+		epoch INTEGER NOT NULL,
+		PRIMARY KEY (address, cid, sent) ON CONFLICT REPLACE
+		)`,
+		`CREATE TABLE _meta (version UINT64 NOT NULL UNIQUE)`,
+		`INSERT INTO _meta (version) VALUES (1)`,
+	} {
+		_, err := db.Exec(stmt)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	addrIndex, err := NewAddrIndex(context.Background(), tmp, cs)
+	require.NoError(t, err)
+
+	alice, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	token, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	ts := cs.makeBlk()
+	cs.msgs[ts.Key()] = []types.ChainMsg{invokeContract(t, alice, token, 0, []byte{1, 2, 3, 4})}
+	require.NoError(t, cs.reorg(nil, []*types.TipSet{ts}))
+
+	waitForCoalescerAfterLastEvent()
+
+	res, err := addrIndex.GetAddrMessages(context.Background(), []address.Address{token}, AddrQuery{MinEpoch: -1, MaxEpoch: -1, Selector: []byte{1, 2, 3, 4}})
+	require.NoError(t, err)
+	require.Len(t, res, 1)
+	require.NoError(t, addrIndex.Close())
+
+	// reopening doesn't upgrade again
+	addrIndex, err = NewAddrIndex(context.Background(), tmp, cs)
+	require.NoError(t, err)
+	require.NoError(t, addrIndex.Close())
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/ipfs/go-cid"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 )
 
@@ -43,3 +44,52 @@ func (dummyMsgIndex) Close() error {
 }
 
 var DummyMsgIndex MsgIndex = dummyMsgIndex{}
+
+// AddrMsgInfo is a message sent or received by an address, as tracked by the
+// address index.
+type AddrMsgInfo struct {
+	// the message this record refers to
+	Message cid.Cid
+	// the tipset where this message was included
+	TipSet cid.Cid
+	// the epoch where this message was included
+	Epoch abi.ChainEpoch
+	// whether the address is the sender of the message, or the recipient
+	Sent bool
+	// the selector of the EVM function called by the message, nil for other
+	// messages
+	Selector []byte
+}
+
+// AddrQuery selects the messages returned by the address index. Messages are
+// returned ordered by descending epoch, then by cid.
+type AddrQuery struct {
+	// MinEpoch and MaxEpoch bound the inclusion epochs, negative values are
+	// unbounded
+	MinEpoch abi.ChainEpoch
+	MaxEpoch abi.ChainEpoch
+	// Sent and Received select the messages sent and received by the
+	// addresses; both kinds are returned when neither is set
+	Sent     bool
+	Received bool
+	// Selector only selects the EVM contract invocations calling the function
+	// of the 4 byte selector
+	Selector []byte
+	// To only selects the messages sent to any of the addresses
+	To []address.Address
+	// After continues a previous query after the last returned message
+	After *AddrMsgInfo
+	// Limit is the maximum number of returned messages
+	Limit int
+}
+
+// AddrIndex is the interface to the index of the messages sent and received
+// by each address.
+type AddrIndex interface {
+	// GetAddrMessages returns the messages sent or received by any of the
+	// addresses. Addresses are matched as they appear in messages, callers
+	// should query both the ID and robust address of an actor.
+	GetAddrMessages(ctx context.Context, addrs []address.Address, q AddrQuery) ([]AddrMsgInfo, error)
+	// Close closes the index
+	Close() error
+}
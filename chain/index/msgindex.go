@@ -132,7 +132,7 @@ func NewMsgIndex(lctx context.Context, basePath string, cs ChainStore) (MsgIndex
 
 	// TODO we may consider populating the index when first creating the db
 	if exists {
-		if err := reconcileIndex(db, cs); err != nil {
+		if err := reconcileIndex(db, cs, msgIndexReconcileQueries); err != nil {
 			return nil, xerrors.Errorf("error reconciling msgindex database: %w", err)
 		}
 	}
@@ -273,7 +273,22 @@ func prepareDB(db *sql.DB) error {
 	return nil
 }
 
-func reconcileIndex(db *sql.DB, cs ChainStore) error {
+// reconcileQueries are the queries reconciling a tipset keyed index table.
+type reconcileQueries struct {
+	count         string
+	minEpoch      string
+	countTipset   string
+	deleteByEpoch string
+}
+
+var msgIndexReconcileQueries = reconcileQueries{
+	count:         dbqCountMessages,
+	minEpoch:      dbqMinEpoch,
+	countTipset:   dbqCountTipsetMessages,
+	deleteByEpoch: dbqDeleteMessagesByEpoch,
+}
+
+func reconcileIndex(db *sql.DB, cs ChainStore, q reconcileQueries) error {
 	// Invariant: after reconciliation, every tipset in the index is in the current chain; ie either
 	//  the chain head or reachable by walking the chain.
 	// Algorithm:
@@ -285,7 +300,7 @@ func reconcileIndex(db *sql.DB, cs ChainStore) error {
 	//  5. If the walk ends in the boundary epoch, then delete everything.
 	//
 
-	row := db.QueryRow(dbqCountMessages)
+	row := db.QueryRow(q.count)
 
 	var result int64
 	if err := row.Scan(&result); err != nil {
@@ -296,14 +311,14 @@ func reconcileIndex(db *sql.DB, cs ChainStore) error {
 		return nil
 	}
 
-	row = db.QueryRow(dbqMinEpoch)
+	row = db.QueryRow(q.minEpoch)
 	if err := row.Scan(&result); err != nil {
 		return xerrors.Errorf("error finding boundary epoch: %w", err)
 	}
 
 	boundaryEpoch := abi.ChainEpoch(result)
 
-	countMsgsStmt, err := db.Prepare(dbqCountTipsetMessages)
+	countMsgsStmt, err := db.Prepare(q.countTipset)
 	if err != nil {
 		return xerrors.Errorf("error preparing statement: %w", err)
 	}
@@ -336,7 +351,7 @@ func reconcileIndex(db *sql.DB, cs ChainStore) error {
 	}
 
 	// delete everything above the minEpoch
-	if _, err = db.Exec(dbqDeleteMessagesByEpoch, int64(boundaryEpoch)); err != nil {
+	if _, err = db.Exec(q.deleteByEpoch, int64(boundaryEpoch)); err != nil {
 		return xerrors.Errorf("error deleting stale reorged out message: %w", err)
 	}
 
@@ -0,0 +1,160 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io/fs"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+	"golang.org/x/xerrors"
+)
+
+// the sqlite driver used for queries, which only authorizes reads
+const queryDriver = "sqlite3_index_query"
+
+// sqlite3 doesn't export the action code of recursive common table expressions
+const sqliteRecursive = 33
+
+func init() {
+	sql.Register(queryDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			conn.RegisterAuthorizer(func(action int, _, _, _ string) int {
+				switch action {
+				case sqlite3.SQLITE_SELECT, sqlite3.SQLITE_READ, sqlite3.SQLITE_FUNCTION, sqliteRecursive:
+					return sqlite3.SQLITE_OK
+				default:
+					return sqlite3.SQLITE_DENY
+				}
+			})
+			return nil
+		},
+	})
+}
+
+// ErrUnknownQueryIndex is returned when querying an index which isn't known,
+// or whose database doesn't exist yet.
+var ErrUnknownQueryIndex = errors.New("unknown index")
+
+// ErrInvalidQuery is returned when sqlite rejects a query, because it's
+// invalid or doesn't only read the database.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// QueryLimits bounds the work of a query.
+type QueryLimits struct {
+	// MaxRows is the maximum number of rows returned, further rows being
+	// dropped.
+	MaxRows int
+	// Timeout is the maximum duration of the query, after which it's
+	// interrupted.
+	Timeout time.Duration
+}
+
+// QueryResult is the result of a query.
+type QueryResult struct {
+	Columns []string
+	Rows    [][]interface{}
+	// Truncated is set when the query returned more than QueryLimits.MaxRows
+	// rows.
+	Truncated bool
+}
+
+// QueryEngine runs read-only SQL queries over the sqlite databases of the
+// indexes. The databases are opened read-only for each query, and only
+// statements reading them are authorized: writes, pragmas and attaching other
+// databases fail.
+type QueryEngine struct {
+	paths  map[string]string
+	limits QueryLimits
+}
+
+// NewQueryEngine creates a query engine over the databases at paths, by index
+// name.
+func NewQueryEngine(paths map[string]string, limits QueryLimits) *QueryEngine {
+	return &QueryEngine{paths: paths, limits: limits}
+}
+
+// Indexes returns the names of the indexes which can be queried.
+func (q *QueryEngine) Indexes() []string {
+	var out []string
+	for name, p := range q.paths {
+		if _, err := os.Stat(p); err == nil {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Query runs a query over an index.
+func (q *QueryEngine) Query(ctx context.Context, index, query string) (*QueryResult, error) {
+	p, ok := q.paths[index]
+	if !ok {
+		return nil, xerrors.Errorf("%w %q", ErrUnknownQueryIndex, index)
+	}
+	if _, err := os.Stat(p); errors.Is(err, fs.ErrNotExist) {
+		return nil, xerrors.Errorf("%w %q: the index database doesn't exist", ErrUnknownQueryIndex, index)
+	} else if err != nil {
+		return nil, xerrors.Errorf("stating the %s index database: %w", index, err)
+	}
+
+	db, err := sql.Open(queryDriver, p+"?mode=ro&_query_only=true")
+	if err != nil {
+		return nil, xerrors.Errorf("opening the %s index database: %w", index, err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	if q.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, q.limits.Timeout)
+		defer cancel()
+	}
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		var serr sqlite3.Error
+		if ctx.Err() == nil && errors.As(err, &serr) {
+			return nil, xerrors.Errorf("%w: %s", ErrInvalidQuery, err)
+		}
+		return nil, xerrors.Errorf("running query: %w", queryErr(ctx, err))
+	}
+	defer rows.Close() //nolint:errcheck
+
+	res := &QueryResult{Rows: [][]interface{}{}}
+	if res.Columns, err = rows.Columns(); err != nil {
+		return nil, xerrors.Errorf("reading columns: %w", err)
+	}
+
+	for rows.Next() {
+		if q.limits.MaxRows > 0 && len(res.Rows) == q.limits.MaxRows {
+			res.Truncated = true
+			break
+		}
+
+		row := make([]interface{}, len(res.Columns))
+		ptrs := make([]interface{}, len(row))
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+		if err := rows.Scan(ptrs...); err != nil {
+			return nil, xerrors.Errorf("reading row: %w", queryErr(ctx, err))
+		}
+		res.Rows = append(res.Rows, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, xerrors.Errorf("reading rows: %w", queryErr(ctx, err))
+	}
+
+	return res, nil
+}
+
+// queryErr returns the error of the context when the query was interrupted.
+func queryErr(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
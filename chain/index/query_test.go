@@ -0,0 +1,73 @@
+package index
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryEngine(t *testing.T) {
+	ctx := context.Background()
+	dbPath := filepath.Join(t.TempDir(), "test.db")
+
+	db, err := sql.Open("sqlite3", dbPath)
+	require.NoError(t, err)
+	_, err = db.Exec("CREATE TABLE messages (cid VARCHAR(80) PRIMARY KEY, epoch INTEGER NOT NULL)")
+	require.NoError(t, err)
+	for i := 0; i < 5; i++ {
+		_, err = db.Exec("INSERT INTO messages VALUES (?, ?)", string(rune('a'+i)), i)
+		require.NoError(t, err)
+	}
+	require.NoError(t, db.Close())
+
+	q := NewQueryEngine(map[string]string{
+		"messages": dbPath,
+		"missing":  filepath.Join(t.TempDir(), "missing.db"),
+	}, QueryLimits{MaxRows: 3, Timeout: time.Second})
+
+	require.Equal(t, []string{"messages"}, q.Indexes())
+
+	res, err := q.Query(ctx, "messages", "SELECT cid, epoch FROM messages WHERE epoch >= 1 ORDER BY epoch")
+	require.NoError(t, err)
+	require.Equal(t, []string{"cid", "epoch"}, res.Columns)
+	require.Equal(t, [][]interface{}{{"b", int64(1)}, {"c", int64(2)}, {"d", int64(3)}}, res.Rows)
+	require.True(t, res.Truncated)
+
+	res, err = q.Query(ctx, "messages", "WITH e AS (SELECT epoch FROM messages) SELECT COUNT(*) AS n FROM e")
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{{int64(5)}}, res.Rows)
+	require.False(t, res.Truncated)
+
+	// only reads are authorized
+	for _, stmt := range []string{
+		"DELETE FROM messages",
+		"INSERT INTO messages VALUES ('z', 9)",
+		"PRAGMA journal_mode = DELETE",
+		"ATTACH DATABASE '" + filepath.Join(t.TempDir(), "other.db") + "' AS other",
+		"CREATE TABLE other (x INTEGER)",
+	} {
+		_, err := q.Query(ctx, "messages", stmt)
+		require.ErrorIs(t, err, ErrInvalidQuery, stmt)
+	}
+
+	res, err = q.Query(ctx, "messages", "SELECT COUNT(*) FROM messages")
+	require.NoError(t, err)
+	require.Equal(t, [][]interface{}{{int64(5)}}, res.Rows)
+
+	_, err = q.Query(ctx, "messages", "SELECT nope FROM messages")
+	require.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = q.Query(ctx, "missing", "SELECT 1")
+	require.ErrorIs(t, err, ErrUnknownQueryIndex)
+	_, err = q.Query(ctx, "other", "SELECT 1")
+	require.ErrorIs(t, err, ErrUnknownQueryIndex)
+
+	// queries are interrupted after the timeout
+	q.limits.Timeout = 50 * time.Millisecond
+	_, err = q.Query(ctx, "messages", "WITH RECURSIVE c(x) AS (SELECT 1 UNION ALL SELECT x + 1 FROM c) SELECT MAX(x) FROM c")
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
@@ -0,0 +1,270 @@
+// Package lightsync syncs the block headers of the chain from an upstream
+// node, for light clients that can't validate the chain themselves.
+//
+// The syncer doesn't fetch messages or execute them, so it can't check blocks
+// are valid. It only checks what can be checked from the headers: that they
+// link up to the local chain, that their heights, timestamps and weights are
+// consistent, and that their drand beacon entries are valid. Anything past
+// finality is never reverted: the syncer refuses upstream chains forking from
+// the local chain before finality, and checkpoints the finalized tipset.
+//
+// The state is not synced, but state proofs against the synced headers can be
+// verified with state.VerifyActorProof.
+package lightsync
+
+import (
+	"context"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/beacon"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/clockcheck"
+)
+
+var log = logging.Logger("lightsync")
+
+// UpstreamAPI is the API of the node headers are synced from.
+type UpstreamAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+	ChainGetTipSet(context.Context, types.TipSetKey) (*types.TipSet, error)
+	ChainGetTipSetByHeight(context.Context, abi.ChainEpoch, types.TipSetKey) (*types.TipSet, error)
+}
+
+// NetworkVersionFunc returns the network version at an epoch.
+type NetworkVersionFunc func(ctx context.Context, height abi.ChainEpoch) network.Version
+
+// Weight is the weight of tipsets on light clients, which can't compute the
+// weight of a tipset without its parent state. The weight of the parent
+// tipset, recorded in the block headers, is used instead.
+func Weight(ctx context.Context, stateBs blockstore.Blockstore, ts *types.TipSet) (types.BigInt, error) {
+	if ts == nil {
+		return types.NewInt(0), nil
+	}
+	return ts.ParentWeight(), nil
+}
+
+// Syncer syncs block headers from an upstream node.
+type Syncer struct {
+	upstream   UpstreamAPI
+	cs         *store.ChainStore
+	beacon     beacon.Schedule
+	nv         NetworkVersionFunc
+	checkpoint types.TipSetKey
+
+	// finalized is the last tipset checkpointed as final
+	finalized *types.TipSet
+}
+
+// NewSyncer creates a header syncer. When the local chain only holds the
+// genesis, syncing starts from the checkpoint tipset. Without checkpoint, the
+// upstream node is trusted for the tipset at finality below its head.
+func NewSyncer(upstream UpstreamAPI, cs *store.ChainStore, b beacon.Schedule, nv NetworkVersionFunc, checkpoint types.TipSetKey) *Syncer {
+	return &Syncer{
+		upstream:   upstream,
+		cs:         cs,
+		beacon:     b,
+		nv:         nv,
+		checkpoint: checkpoint,
+	}
+}
+
+// Run syncs headers at the given interval until the context is cancelled.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			log.Errorw("syncing headers", "error", err)
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Sync syncs the headers up to the head of the upstream node.
+func (s *Syncer) Sync(ctx context.Context) error {
+	target, err := s.upstream.ChainHead(ctx)
+	if err != nil {
+		return xerrors.Errorf("getting upstream head: %w", err)
+	}
+
+	head := s.cs.GetHeaviestTipSet()
+	if head.Height() == 0 && target.Height() > build.Finality {
+		if head, err = s.bootstrap(ctx, target); err != nil {
+			return xerrors.Errorf("bootstrapping header sync: %w", err)
+		}
+	}
+	if target.Equals(head) {
+		return nil
+	}
+
+	tipsets, err := s.collect(ctx, head, target)
+	if err != nil {
+		return err
+	}
+
+	// validate from the oldest tipset, whose parent is in the local chain
+	for i := len(tipsets) - 1; i >= 0; i-- {
+		if err := s.validate(ctx, tipsets[i]); err != nil {
+			return xerrors.Errorf("invalid tipset %s at height %d: %w", tipsets[i].Key(), tipsets[i].Height(), err)
+		}
+		if err := s.cs.PersistTipsets(ctx, tipsets[i:i+1]); err != nil {
+			return xerrors.Errorf("persisting headers: %w", err)
+		}
+	}
+
+	if err := s.cs.MaybeTakeHeavierTipSet(ctx, target); err != nil {
+		return xerrors.Errorf("updating head: %w", err)
+	}
+
+	s.checkpointFinalized(ctx)
+	return nil
+}
+
+// bootstrap sets the head of the local chain to the checkpoint tipset.
+func (s *Syncer) bootstrap(ctx context.Context, target *types.TipSet) (*types.TipSet, error) {
+	var (
+		anchor *types.TipSet
+		err    error
+	)
+	if s.checkpoint != types.EmptyTSK {
+		anchor, err = s.upstream.ChainGetTipSet(ctx, s.checkpoint)
+		if err != nil {
+			return nil, xerrors.Errorf("getting checkpoint tipset %s: %w", s.checkpoint, err)
+		}
+		if anchor.Key() != s.checkpoint {
+			return nil, xerrors.Errorf("upstream returned tipset %s for checkpoint %s", anchor.Key(), s.checkpoint)
+		}
+	} else {
+		log.Warnw("no light client checkpoint configured, trusting the upstream node for the finalized tipset to sync from")
+		anchor, err = s.upstream.ChainGetTipSetByHeight(ctx, target.Height()-build.Finality, target.Key())
+		if err != nil {
+			return nil, xerrors.Errorf("getting finalized tipset: %w", err)
+		}
+	}
+
+	if err := s.checkTimestamps(ctx, anchor); err != nil {
+		return nil, xerrors.Errorf("invalid checkpoint tipset: %w", err)
+	}
+	if err := s.cs.PersistTipsets(ctx, []*types.TipSet{anchor}); err != nil {
+		return nil, xerrors.Errorf("persisting checkpoint headers: %w", err)
+	}
+	if err := s.cs.SetHead(ctx, anchor); err != nil {
+		return nil, xerrors.Errorf("setting head to the checkpoint: %w", err)
+	}
+
+	log.Infow("header sync starting from checkpoint", "tipset", anchor.Key(), "height", anchor.Height())
+	return anchor, nil
+}
+
+// collect fetches the tipsets from the target back to a tipset of the local
+// chain, newest first.
+func (s *Syncer) collect(ctx context.Context, head, target *types.TipSet) ([]*types.TipSet, error) {
+	finalized := head.Height() - build.Finality
+
+	var out []*types.TipSet
+	cur := target
+	for {
+		if _, err := s.cs.LoadTipSet(ctx, cur.Key()); err == nil {
+			return out, nil
+		}
+		if cur.Height() < finalized {
+			return nil, xerrors.Errorf("upstream chain forks from the local chain before finality (height %d)", finalized)
+		}
+		out = append(out, cur)
+
+		parent, err := s.upstream.ChainGetTipSet(ctx, cur.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("getting tipset %s: %w", cur.Parents(), err)
+		}
+		if parent.Key() != cur.Parents() {
+			return nil, xerrors.Errorf("upstream returned tipset %s for parents %s", parent.Key(), cur.Parents())
+		}
+		cur = parent
+	}
+}
+
+// validate checks a tipset whose parent is in the local chain.
+func (s *Syncer) validate(ctx context.Context, ts *types.TipSet) error {
+	parent, err := s.cs.LoadTipSet(ctx, ts.Parents())
+	if err != nil {
+		return xerrors.Errorf("loading parent tipset: %w", err)
+	}
+
+	if ts.Height() <= parent.Height() {
+		return xerrors.Errorf("height %d not above the parent height %d", ts.Height(), parent.Height())
+	}
+	if !ts.ParentWeight().GreaterThan(parent.ParentWeight()) {
+		return xerrors.Errorf("parent weight %s not above the weight of the grandparent %s", ts.ParentWeight(), parent.ParentWeight())
+	}
+	if err := s.checkTimestamps(ctx, ts); err != nil {
+		return err
+	}
+
+	prevEntry, err := s.cs.GetLatestBeaconEntry(ctx, parent)
+	if err != nil {
+		return xerrors.Errorf("getting latest beacon entry: %w", err)
+	}
+	nv := s.nv(ctx, ts.Height())
+	for _, blk := range ts.Blocks() {
+		if err := beacon.ValidateBlockValues(s.beacon, nv, blk, parent.Height(), *prevEntry); err != nil {
+			return xerrors.Errorf("block %s: %w", blk.Cid(), err)
+		}
+	}
+	return nil
+}
+
+func (s *Syncer) checkTimestamps(ctx context.Context, ts *types.TipSet) error {
+	gen, err := s.cs.GetGenesis(ctx)
+	if err != nil {
+		return xerrors.Errorf("getting genesis: %w", err)
+	}
+	expected := gen.Timestamp + uint64(ts.Height())*build.BlockDelaySecs
+	for _, blk := range ts.Blocks() {
+		if blk.Timestamp != expected {
+			return xerrors.Errorf("block %s has timestamp %d, expected %d", blk.Cid(), blk.Timestamp, expected)
+		}
+	}
+	if expected > uint64(build.Clock.Now().Unix())+build.AllowableClockDriftSecs {
+		return clockcheck.Annotate(xerrors.Errorf("tipset is in the future"))
+	}
+	return nil
+}
+
+// checkpointFinalized prevents reverting the tipsets at finality below the
+// head.
+func (s *Syncer) checkpointFinalized(ctx context.Context) {
+	head := s.cs.GetHeaviestTipSet()
+	if head.Height() <= build.Finality {
+		return
+	}
+	if s.finalized != nil && s.finalized.Height() >= head.Height()-build.Finality {
+		return
+	}
+	ts, err := s.cs.GetTipsetByHeight(ctx, head.Height()-build.Finality, head, true)
+	if err != nil {
+		// the headers below the checkpoint the sync started from aren't
+		// synced
+		log.Debugw("loading finalized tipset", "error", err)
+		return
+	}
+	if err := s.cs.SetCheckpoint(ctx, ts); err != nil {
+		log.Warnw("checkpointing finalized tipset", "tipset", ts.Key(), "error", err)
+		return
+	}
+	s.finalized = ts
+}
@@ -0,0 +1,143 @@
+package lightsync
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/beacon"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+type testUpstream struct {
+	head     *types.TipSet
+	tipsets  map[types.TipSetKey]*types.TipSet
+	byHeight map[abi.ChainEpoch]*types.TipSet
+}
+
+func (u *testUpstream) ChainHead(context.Context) (*types.TipSet, error) {
+	return u.head, nil
+}
+
+func (u *testUpstream) ChainGetTipSet(_ context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	ts, ok := u.tipsets[tsk]
+	if !ok {
+		return nil, xerrors.Errorf("tipset %s not found", tsk)
+	}
+	return ts, nil
+}
+
+func (u *testUpstream) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	ts, ok := u.byHeight[h]
+	if !ok {
+		return nil, xerrors.Errorf("no tipset at height %d", h)
+	}
+	return ts, nil
+}
+
+func (u *testUpstream) add(ts *types.TipSet) {
+	u.tipsets[ts.Key()] = ts
+	u.byHeight[ts.Height()] = ts
+	u.head = ts
+}
+
+var testBeacon = beacon.NewMockBeacon(time.Second)
+
+func beaconEntry(h abi.ChainEpoch) []types.BeaconEntry {
+	resp := <-testBeacon.Entry(context.Background(), testBeacon.MaxBeaconRoundForEpoch(network.Version0, h))
+	return []types.BeaconEntry{resp.Entry}
+}
+
+func mkTipSet(parent *types.TipSet) *types.TipSet {
+	blk := mock.MkBlock(parent, 1, 1)
+	blk.BeaconEntries = beaconEntry(blk.Height)
+	return mock.TipSet(blk)
+}
+
+func setup(t *testing.T, n int) (*types.TipSet, *testUpstream, *Syncer) {
+	gen := mkTipSet(nil)
+
+	up := &testUpstream{tipsets: map[types.TipSetKey]*types.TipSet{}, byHeight: map[abi.ChainEpoch]*types.TipSet{}}
+	up.add(gen)
+	for i := 0; i < n; i++ {
+		up.add(mkTipSet(up.head))
+	}
+
+	bs := blockstore.NewMemorySync()
+	cs := store.NewChainStore(bs, bs, datastore.NewMapDatastore(), Weight, nil)
+	t.Cleanup(func() { _ = cs.Close() })
+	require.NoError(t, cs.SetGenesis(context.Background(), gen.Blocks()[0]))
+	require.NoError(t, cs.Load(context.Background()))
+
+	nv := func(context.Context, abi.ChainEpoch) network.Version { return build.TestNetworkVersion }
+	s := NewSyncer(up, cs, beacon.Schedule{{Start: 0, Beacon: testBeacon}}, nv, types.EmptyTSK)
+	return gen, up, s
+}
+
+func TestSyncHeaders(t *testing.T) {
+	ctx := context.Background()
+	_, up, s := setup(t, 20)
+
+	require.NoError(t, s.Sync(ctx))
+	require.True(t, s.cs.GetHeaviestTipSet().Equals(up.head))
+
+	for i := 0; i < 5; i++ {
+		up.add(mkTipSet(up.head))
+	}
+	require.NoError(t, s.Sync(ctx))
+	require.True(t, s.cs.GetHeaviestTipSet().Equals(up.head))
+
+	// invalid headers aren't synced
+	synced := up.head
+	bad := mock.MkBlock(up.head, 1, 1)
+	bad.BeaconEntries = beaconEntry(bad.Height)
+	bad.Timestamp++
+	up.add(mock.TipSet(bad))
+	require.Error(t, s.Sync(ctx))
+	require.True(t, s.cs.GetHeaviestTipSet().Equals(synced))
+
+	bad = mock.MkBlock(synced, 1, 2)
+	bad.BeaconEntries = nil
+	up.add(mock.TipSet(bad))
+	require.Error(t, s.Sync(ctx))
+	require.True(t, s.cs.GetHeaviestTipSet().Equals(synced))
+}
+
+func TestSyncFromCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	_, up, s := setup(t, int(build.Finality)+50)
+
+	checkpoint := up.byHeight[build.Finality+10]
+	s.checkpoint = checkpoint.Key()
+
+	require.NoError(t, s.Sync(ctx))
+	require.True(t, s.cs.GetHeaviestTipSet().Equals(up.head))
+
+	// headers below the checkpoint aren't synced
+	_, err := s.cs.LoadTipSet(ctx, checkpoint.Parents())
+	require.Error(t, err)
+}
+
+func TestSyncWrongCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	_, up, s := setup(t, int(build.Finality)+50)
+
+	// the upstream node returns another tipset for the checkpoint
+	checkpoint := up.byHeight[build.Finality+10]
+	s.checkpoint = checkpoint.Parents()
+	up.tipsets[checkpoint.Parents()] = checkpoint
+
+	require.Error(t, s.Sync(ctx))
+	require.Equal(t, abi.ChainEpoch(0), s.cs.GetHeaviestTipSet().Height())
+}
@@ -145,6 +145,12 @@ type MessagePool struct {
 	cfgLk sync.RWMutex
 	cfg   *types.MpoolConfig
 
+	selectorLk sync.RWMutex
+	selector   MessageSelector
+
+	spamFilterLk sync.RWMutex
+	spamFilter   *SpamFilter
+
 	api Provider
 
 	minGasPrice types.BigInt
@@ -1134,11 +1140,29 @@ func (mp *MessagePool) getStateBalance(ctx context.Context, addr address.Address
 //   - extra strict add checks are used when adding the messages to the msgSet
 //     that means: no nonce gaps, at most 10 pending messages for the actor
 func (mp *MessagePool) PushUntrusted(ctx context.Context, m *types.SignedMessage) (cid.Cid, error) {
+	// the signature is checked before the spam filter accounts for the message,
+	// so that forged messages can't use up the quota of their sender or get it
+	// banned
 	err := mp.checkMessage(ctx, m)
 	if err != nil {
 		return cid.Undef, err
 	}
 
+	filter := mp.getSpamFilter()
+	var sender address.Address
+	if filter != nil {
+		mp.curTsLk.RLock()
+		sender, err = mp.resolveToKey(ctx, m.Message.From)
+		mp.curTsLk.RUnlock()
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("resolving sender key address: %w", err)
+		}
+
+		if err := filter.Check(m, sender, mp.pressure()); err != nil {
+			return cid.Undef, err
+		}
+	}
+
 	// serialize push access to reduce lock contention
 	mp.addSema <- struct{}{}
 	defer func() {
@@ -1149,10 +1173,17 @@ func (mp *MessagePool) PushUntrusted(ctx context.Context, m *types.SignedMessage
 	publish, err := mp.addTs(ctx, m, mp.curTs, true, true)
 	if err != nil {
 		mp.curTsLk.Unlock()
+		if filter != nil {
+			filter.Rejected(sender)
+		}
 		return cid.Undef, err
 	}
 	mp.curTsLk.Unlock()
 
+	if filter != nil {
+		filter.Accepted(m, sender)
+	}
+
 	if publish {
 		msgb, err := m.Serialize()
 		if err != nil {
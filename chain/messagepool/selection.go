@@ -56,32 +56,58 @@ func (mp *MessagePool) SelectMessages(ctx context.Context, ts *types.TipSet, tq
 		}
 	}
 
-	// if the ticket quality is high enough that the first block has higher probability
-	// than any other block, then we don't bother with optimal selection because the
-	// first block will always have higher effective performance
-	var sm *selectedMessages
-	var err error
-	if tq > 0.84 {
-		sm, err = mp.selectMessagesGreedy(ctx, mp.curTs, ts)
-	} else {
-		sm, err = mp.selectMessagesOptimal(ctx, mp.curTs, ts, tq)
-	}
-
+	in, err := mp.selectionInput(ctx, mp.curTs, ts, tq)
 	if err != nil {
 		return nil, err
 	}
 
-	if sm == nil {
+	if len(in.Pending) == 0 {
 		return nil, nil
 	}
 
+	msgs, err := mp.getSelector().SelectMessages(ctx, mp, in)
+	if err != nil {
+		return nil, err
+	}
+
 	// one last sanity check
-	if len(sm.msgs) > build.BlockMessageLimit {
-		log.Errorf("message selection chose too many messages %d > %d", len(sm.msgs), build.BlockMessageLimit)
-		sm.msgs = sm.msgs[:build.BlockMessageLimit]
+	if len(msgs) > build.BlockMessageLimit {
+		log.Errorf("message selection chose too many messages %d > %d", len(msgs), build.BlockMessageLimit)
+		msgs = msgs[:build.BlockMessageLimit]
+	}
+	var gasLimit int64
+	for i, m := range msgs {
+		gasLimit += m.Message.GasLimit
+		if gasLimit > build.BlockGasLimit {
+			log.Errorf("message selection exceeded the block gas limit with %d messages, keeping %d", len(msgs), i)
+			msgs = msgs[:i]
+			break
+		}
 	}
 
-	return sm.msgs, nil
+	return msgs, nil
+}
+
+// selectionInput loads the messages to select from for a block mined on ts.
+func (mp *MessagePool) selectionInput(ctx context.Context, curTs, ts *types.TipSet, tq float64) (*SelectionInput, error) {
+	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing basefee: %w", err)
+	}
+
+	// Load messages from the target tipset; if it is the same as the current tipset in
+	// the mpool, then this is just the pending messages
+	pending, err := mp.getPendingMessages(ctx, curTs, ts)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SelectionInput{
+		TipSet:        ts,
+		TicketQuality: tq,
+		BaseFee:       baseFee,
+		Pending:       pending,
+	}, nil
 }
 
 type selectedMessages struct {
@@ -209,19 +235,17 @@ func (sm *selectedMessages) trimChain(mc *msgChain, mp *MessagePool, baseFee typ
 }
 
 func (mp *MessagePool) selectMessagesOptimal(ctx context.Context, curTs, ts *types.TipSet, tq float64) (*selectedMessages, error) {
-	start := time.Now()
-
-	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
-	if err != nil {
-		return nil, xerrors.Errorf("computing basefee: %w", err)
-	}
-
-	// 0. Load messages from the target tipset; if it is the same as the current tipset in
-	//    the mpool, then this is just the pending messages
-	pending, err := mp.getPendingMessages(ctx, curTs, ts)
+	in, err := mp.selectionInput(ctx, curTs, ts, tq)
 	if err != nil {
 		return nil, err
 	}
+	return mp.selectOptimal(ctx, in)
+}
+
+func (mp *MessagePool) selectOptimal(ctx context.Context, in *SelectionInput) (*selectedMessages, error) {
+	start := time.Now()
+
+	ts, tq, baseFee, pending := in.TipSet, in.TicketQuality, in.BaseFee, in.Pending
 
 	if len(pending) == 0 {
 		return nil, nil
@@ -457,19 +481,17 @@ tailLoop:
 }
 
 func (mp *MessagePool) selectMessagesGreedy(ctx context.Context, curTs, ts *types.TipSet) (*selectedMessages, error) {
-	start := time.Now()
-
-	baseFee, err := mp.api.ChainComputeBaseFee(context.TODO(), ts)
-	if err != nil {
-		return nil, xerrors.Errorf("computing basefee: %w", err)
-	}
-
-	// 0. Load messages for the target tipset; if it is the same as the current tipset in the mpool
-	//    then this is just the pending messages
-	pending, err := mp.getPendingMessages(ctx, curTs, ts)
+	in, err := mp.selectionInput(ctx, curTs, ts, 1)
 	if err != nil {
 		return nil, err
 	}
+	return mp.selectGreedy(ctx, in)
+}
+
+func (mp *MessagePool) selectGreedy(ctx context.Context, in *SelectionInput) (*selectedMessages, error) {
+	start := time.Now()
+
+	ts, baseFee, pending := in.TipSet, in.BaseFee, in.Pending
 
 	if len(pending) == 0 {
 		return nil, nil
@@ -0,0 +1,154 @@
+package messagepool
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// SelectionInput holds the messages a MessageSelector selects from.
+type SelectionInput struct {
+	// TipSet the block is mined on.
+	TipSet *types.TipSet
+	// TicketQuality of the block ticket; the lower it is, the more likely
+	// other blocks of the tipset come first and include the same messages.
+	TicketQuality float64
+	// BaseFee of the block.
+	BaseFee types.BigInt
+	// Pending messages by sender key address and nonce. The message sets can
+	// be shared with the pool and must not be modified; use Filter to drop
+	// messages. The built-in selectors remove the senders they processed.
+	Pending map[address.Address]map[uint64]*types.SignedMessage
+}
+
+// Filter drops the pending messages keep rejects. It visits the messages of
+// each sender in nonce order, and drops the rest of the messages of a sender
+// after the first rejected one, as they can't be included without it. Filter
+// can be used to build policies such as per-sender caps on top of the
+// built-in selectors.
+func (in *SelectionInput) Filter(keep func(*types.SignedMessage) bool) {
+	for a, mset := range in.Pending {
+		nonces := make([]uint64, 0, len(mset))
+		for nonce := range mset {
+			nonces = append(nonces, nonce)
+		}
+		sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+		kept := make(map[uint64]*types.SignedMessage, len(mset))
+		for _, nonce := range nonces {
+			if !keep(mset[nonce]) {
+				break
+			}
+			kept[nonce] = mset[nonce]
+		}
+
+		if len(kept) == 0 {
+			delete(in.Pending, a)
+			continue
+		}
+		in.Pending[a] = kept
+	}
+}
+
+// MessageSelector selects the messages of a block from the pending messages.
+// Selectors are called with the pool locked, so they must not call methods of
+// the pool.
+type MessageSelector interface {
+	SelectMessages(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error)
+}
+
+// MessageSelectorFunc adapts a function to a MessageSelector.
+type MessageSelectorFunc func(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error)
+
+func (f MessageSelectorFunc) SelectMessages(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error) {
+	return f(ctx, mp, in)
+}
+
+var (
+	// GreedySelector fills the block with the message chains paying the most
+	// gas premium per unit of gas.
+	GreedySelector MessageSelector = MessageSelectorFunc(func(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error) {
+		return selectedMsgs(mp.selectGreedy(ctx, in))
+	})
+	// OptimalSelector accounts for the probability of other blocks of the
+	// tipset including the same messages, weighted by the ticket quality.
+	OptimalSelector MessageSelector = MessageSelectorFunc(func(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error) {
+		return selectedMsgs(mp.selectOptimal(ctx, in))
+	})
+	// DefaultSelector uses the greedy selector when the ticket is good enough
+	// for the block to likely come first in the tipset, and the optimal
+	// selector otherwise.
+	DefaultSelector MessageSelector = MessageSelectorFunc(func(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error) {
+		// if the ticket quality is high enough that the first block has higher probability
+		// than any other block, then we don't bother with optimal selection because the
+		// first block will always have higher effective performance
+		if in.TicketQuality > 0.84 {
+			return GreedySelector.SelectMessages(ctx, mp, in)
+		}
+		return OptimalSelector.SelectMessages(ctx, mp, in)
+	})
+)
+
+func selectedMsgs(sm *selectedMessages, err error) ([]*types.SignedMessage, error) {
+	if err != nil || sm == nil {
+		return nil, err
+	}
+	return sm.msgs, nil
+}
+
+var (
+	selectorsLk sync.RWMutex
+	selectors   = map[string]MessageSelector{
+		"default": DefaultSelector,
+		"greedy":  GreedySelector,
+		"optimal": OptimalSelector,
+	}
+)
+
+// RegisterSelector makes a selector available under a name, for nodes to
+// select it in their configuration. It's meant to be called from init
+// functions of packages built into the node.
+func RegisterSelector(name string, s MessageSelector) error {
+	selectorsLk.Lock()
+	defer selectorsLk.Unlock()
+
+	if _, ok := selectors[name]; ok {
+		return xerrors.Errorf("message selector %q already registered", name)
+	}
+	selectors[name] = s
+	return nil
+}
+
+// GetSelector returns the selector registered under a name.
+func GetSelector(name string) (MessageSelector, error) {
+	selectorsLk.RLock()
+	defer selectorsLk.RUnlock()
+
+	s, ok := selectors[name]
+	if !ok {
+		return nil, xerrors.Errorf("unknown message selector %q", name)
+	}
+	return s, nil
+}
+
+// SetSelector replaces the selector of the messages of mined blocks.
+func (mp *MessagePool) SetSelector(s MessageSelector) {
+	mp.selectorLk.Lock()
+	defer mp.selectorLk.Unlock()
+	mp.selector = s
+}
+
+func (mp *MessagePool) getSelector() MessageSelector {
+	mp.selectorLk.RLock()
+	defer mp.selectorLk.RUnlock()
+	if mp.selector == nil {
+		return DefaultSelector
+	}
+	return mp.selector
+}
@@ -0,0 +1,112 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/messagepool/gasguess"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/chain/wallet"
+)
+
+func TestSelectorPolicy(t *testing.T) {
+	mp, tma := makeTestMpool()
+
+	w1, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	a1, err := w1.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+
+	w2, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	a2, err := w2.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+
+	block := tma.nextBlock()
+	ts := mock.TipSet(block)
+	tma.applyBlock(t, block)
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalance(a2, 1) // in FIL
+
+	for i := 0; i < 10; i++ {
+		mustAdd(t, mp, makeTestMessage(w1, a1, a2, uint64(i), gasLimit, uint64(i+1)))
+		mustAdd(t, mp, makeTestMessage(w2, a2, a1, uint64(i), gasLimit, uint64(i+1)))
+	}
+
+	// at most 3 messages per sender, none from a2 after nonce 5
+	mp.SetSelector(MessageSelectorFunc(func(ctx context.Context, mp *MessagePool, in *SelectionInput) ([]*types.SignedMessage, error) {
+		counts := map[string]int{}
+		in.Filter(func(m *types.SignedMessage) bool {
+			if m.Message.From == a2 && m.Message.Nonce >= 5 {
+				return false
+			}
+			counts[m.Message.From.String()]++
+			return counts[m.Message.From.String()] <= 3
+		})
+		return DefaultSelector.SelectMessages(ctx, mp, in)
+	}))
+
+	msgs, err := mp.SelectMessages(context.Background(), ts, 1.0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 6)
+
+	nonces := map[string][]uint64{}
+	for _, m := range msgs {
+		nonces[m.Message.From.String()] = append(nonces[m.Message.From.String()], m.Message.Nonce)
+	}
+	require.Equal(t, []uint64{0, 1, 2}, nonces[a1.String()])
+	require.Equal(t, []uint64{0, 1, 2}, nonces[a2.String()])
+
+	// filtering doesn't touch the pool
+	mp.SetSelector(GreedySelector)
+	msgs, err = mp.SelectMessages(context.Background(), ts, 1.0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 20)
+}
+
+func TestSelectionInputFilter(t *testing.T) {
+	mset := map[uint64]*types.SignedMessage{}
+	for i := uint64(0); i < 5; i++ {
+		mset[i] = &types.SignedMessage{Message: types.Message{Nonce: i}}
+	}
+	in := &SelectionInput{Pending: map[address.Address]map[uint64]*types.SignedMessage{
+		address.TestAddress:  mset,
+		address.TestAddress2: {0: {Message: types.Message{Nonce: 7}}},
+	}}
+
+	// messages after a rejected nonce are dropped too, and senders without
+	// messages left are removed
+	in.Filter(func(m *types.SignedMessage) bool {
+		return m.Message.Nonce != 2 && m.Message.Nonce != 7
+	})
+	require.Len(t, in.Pending, 1)
+	require.Len(t, in.Pending[address.TestAddress], 2)
+	require.Contains(t, in.Pending[address.TestAddress], uint64(1))
+
+	// the message sets of the pool are left alone
+	require.Len(t, mset, 5)
+}
+
+func TestRegisterSelector(t *testing.T) {
+	s, err := GetSelector("greedy")
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	_, err = GetSelector("test-selector")
+	require.Error(t, err)
+
+	require.NoError(t, RegisterSelector("test-selector", GreedySelector))
+	require.Error(t, RegisterSelector("test-selector", OptimalSelector))
+
+	_, err = GetSelector("test-selector")
+	require.NoError(t, err)
+}
@@ -0,0 +1,287 @@
+package messagepool
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var (
+	ErrSenderBanned            = errors.New("sender temporarily banned for spamming")
+	ErrSenderRateLimited       = errors.New("sender push rate limit exceeded")
+	ErrDuplicateMessage        = errors.New("message was already pushed")
+	ErrTooManyReplacements     = errors.New("too many replacements of the message nonce")
+	ErrPremiumTooLowOnPressure = errors.New("gas premium too low while the message pool is under pressure")
+)
+
+// SpamFilterConfig configures the filtering of untrusted pushes. Zero values
+// disable the respective checks, duplicates are refused when RecentWindow is
+// set.
+type SpamFilterConfig struct {
+	// MaxSenderPushes is the number of messages a sender can push in each
+	// SenderRateInterval.
+	MaxSenderPushes    int
+	SenderRateInterval time.Duration
+
+	// PressureThreshold is the fraction of the pool size limit from which
+	// messages need a gas premium of at least PressureMinPremium.
+	PressureThreshold  float64
+	PressureMinPremium abi.TokenAmount
+
+	// RecentWindow is how long pushed messages are remembered, to refuse
+	// duplicates, count the replacements of the messages of a nonce and
+	// the rejected pushes of a sender.
+	RecentWindow time.Duration
+	// MaxReplacements is the number of times the message of a sender nonce
+	// can be replaced in RecentWindow.
+	MaxReplacements int
+
+	// BanThreshold is the number of rejected pushes of a sender in
+	// RecentWindow after which its pushes are refused for BanDuration.
+	BanThreshold int
+	BanDuration  time.Duration
+}
+
+// SpamFilter scores the senders of untrusted pushes, refusing messages of
+// senders pushing too fast, duplicates, repeated replacements and, when the
+// pool is under pressure, messages with a low gas premium. Senders whose
+// pushes are repeatedly rejected, by the filter or the pool, are banned for a
+// while.
+type SpamFilter struct {
+	cfg SpamFilterConfig
+	now func() time.Time
+
+	lk        sync.Mutex
+	senders   map[address.Address]*spamSender
+	recent    map[cid.Cid]time.Time
+	nonces    map[spamNonceKey]*spamNonce
+	lastPrune time.Time
+}
+
+type spamSender struct {
+	limiter     *rate.Limiter
+	rejections  []time.Time
+	bannedUntil time.Time
+	lastSeen    time.Time
+}
+
+type spamNonceKey struct {
+	from  address.Address
+	nonce uint64
+}
+
+type spamNonce struct {
+	msg          cid.Cid
+	replacements int
+	lastSeen     time.Time
+}
+
+func NewSpamFilter(cfg SpamFilterConfig) (*SpamFilter, error) {
+	if cfg.MaxSenderPushes > 0 && cfg.SenderRateInterval <= 0 {
+		return nil, xerrors.Errorf("sender rate interval must be positive")
+	}
+	if cfg.PressureThreshold < 0 || cfg.PressureThreshold > 1 {
+		return nil, xerrors.Errorf("pressure threshold must be between 0 and 1")
+	}
+	if (cfg.MaxReplacements > 0 || cfg.BanThreshold > 0) && cfg.RecentWindow <= 0 {
+		return nil, xerrors.Errorf("recent window must be positive")
+	}
+	if cfg.BanThreshold > 0 && cfg.BanDuration <= 0 {
+		return nil, xerrors.Errorf("ban duration must be positive")
+	}
+
+	return &SpamFilter{
+		cfg:     cfg,
+		now:     time.Now,
+		senders: map[address.Address]*spamSender{},
+		recent:  map[cid.Cid]time.Time{},
+		nonces:  map[spamNonceKey]*spamNonce{},
+	}, nil
+}
+
+// Check returns an error when m must be refused, pressure being the fraction
+// of the pool size limit in use. Senders are scored by from, the key address
+// of the sender, and the signature of m must have been verified, so that
+// messages can't be forged to use up the quota of a sender or get it banned.
+// Refusals count as rejections of the sender, except for duplicates, which
+// clients legitimately retry.
+func (f *SpamFilter) Check(m *types.SignedMessage, from address.Address, pressure float64) error {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	now := f.now()
+	f.prune(now)
+
+	s := f.sender(from, now)
+
+	err := f.check(m, from, s, pressure, now)
+	if err != nil && !xerrors.Is(err, ErrSenderBanned) && !xerrors.Is(err, ErrDuplicateMessage) {
+		f.reject(from, s, now)
+	}
+	return err
+}
+
+func (f *SpamFilter) check(m *types.SignedMessage, from address.Address, s *spamSender, pressure float64, now time.Time) error {
+	if now.Before(s.bannedUntil) {
+		return xerrors.Errorf("%s until %s: %w", from, s.bannedUntil.Format(time.RFC3339), ErrSenderBanned)
+	}
+
+	c := m.Cid()
+	if _, ok := f.recent[c]; ok {
+		return xerrors.Errorf("message %s: %w", c, ErrDuplicateMessage)
+	}
+
+	if s.limiter != nil && !s.limiter.AllowN(now, 1) {
+		return xerrors.Errorf("%s pushed more than %d messages in %s: %w", from, f.cfg.MaxSenderPushes, f.cfg.SenderRateInterval, ErrSenderRateLimited)
+	}
+
+	if f.cfg.MaxReplacements > 0 {
+		n, ok := f.nonces[spamNonceKey{from, m.Message.Nonce}]
+		if ok && n.msg != c && n.replacements >= f.cfg.MaxReplacements {
+			return xerrors.Errorf("message of %s with nonce %d replaced %d times: %w", from, m.Message.Nonce, n.replacements, ErrTooManyReplacements)
+		}
+	}
+
+	if f.cfg.PressureThreshold > 0 && pressure >= f.cfg.PressureThreshold && !f.cfg.PressureMinPremium.Nil() &&
+		m.Message.GasPremium.LessThan(f.cfg.PressureMinPremium) {
+		return xerrors.Errorf("gas premium %s below %s with the pool %.0f%% full: %w", m.Message.GasPremium, f.cfg.PressureMinPremium, pressure*100, ErrPremiumTooLowOnPressure)
+	}
+
+	return nil
+}
+
+// Accepted records a message of from added to the pool.
+func (f *SpamFilter) Accepted(m *types.SignedMessage, from address.Address) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	now := f.now()
+	c := m.Cid()
+	if f.cfg.RecentWindow > 0 {
+		f.recent[c] = now
+	}
+
+	if f.cfg.MaxReplacements > 0 {
+		k := spamNonceKey{from, m.Message.Nonce}
+		n, ok := f.nonces[k]
+		switch {
+		case !ok:
+			f.nonces[k] = &spamNonce{msg: c, lastSeen: now}
+		case n.msg != c:
+			n.msg = c
+			n.replacements++
+			n.lastSeen = now
+		}
+	}
+}
+
+// Rejected records a message of from rejected by the pool, which must have a
+// valid signature.
+func (f *SpamFilter) Rejected(from address.Address) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	now := f.now()
+	f.reject(from, f.sender(from, now), now)
+}
+
+func (f *SpamFilter) sender(from address.Address, now time.Time) *spamSender {
+	s, ok := f.senders[from]
+	if !ok {
+		s = &spamSender{}
+		if f.cfg.MaxSenderPushes > 0 {
+			every := f.cfg.SenderRateInterval / time.Duration(f.cfg.MaxSenderPushes)
+			s.limiter = rate.NewLimiter(rate.Every(every), f.cfg.MaxSenderPushes)
+		}
+		f.senders[from] = s
+	}
+	s.lastSeen = now
+	return s
+}
+
+func (f *SpamFilter) reject(from address.Address, s *spamSender, now time.Time) {
+	if f.cfg.BanThreshold <= 0 {
+		return
+	}
+
+	s.rejections = append(recentTimes(s.rejections, now.Add(-f.cfg.RecentWindow)), now)
+	if len(s.rejections) >= f.cfg.BanThreshold {
+		s.bannedUntil = now.Add(f.cfg.BanDuration)
+		s.rejections = nil
+		log.Warnw("banning spamming sender of untrusted messages", "from", from, "until", s.bannedUntil)
+	}
+}
+
+// prune forgets the state older than the recent window, at most once every
+// tenth of it.
+func (f *SpamFilter) prune(now time.Time) {
+	window := f.cfg.RecentWindow
+	if f.cfg.SenderRateInterval > window {
+		window = f.cfg.SenderRateInterval
+	}
+	if window <= 0 {
+		window = time.Minute
+	}
+	if now.Sub(f.lastPrune) < window/10 {
+		return
+	}
+	f.lastPrune = now
+	cutoff := now.Add(-window)
+
+	for c, t := range f.recent {
+		if t.Before(cutoff) {
+			delete(f.recent, c)
+		}
+	}
+	for k, n := range f.nonces {
+		if n.lastSeen.Before(cutoff) {
+			delete(f.nonces, k)
+		}
+	}
+	for a, s := range f.senders {
+		if s.lastSeen.Before(cutoff) && now.After(s.bannedUntil) {
+			delete(f.senders, a)
+		}
+	}
+}
+
+func recentTimes(ts []time.Time, cutoff time.Time) []time.Time {
+	for len(ts) > 0 && ts[0].Before(cutoff) {
+		ts = ts[1:]
+	}
+	return ts
+}
+
+// SetSpamFilter sets the filter of untrusted pushes, nil disables filtering.
+func (mp *MessagePool) SetSpamFilter(f *SpamFilter) {
+	mp.spamFilterLk.Lock()
+	defer mp.spamFilterLk.Unlock()
+	mp.spamFilter = f
+}
+
+func (mp *MessagePool) getSpamFilter() *SpamFilter {
+	mp.spamFilterLk.RLock()
+	defer mp.spamFilterLk.RUnlock()
+	return mp.spamFilter
+}
+
+// pressure returns the fraction of the pool size limit in use.
+func (mp *MessagePool) pressure() float64 {
+	limit := mp.getConfig().SizeLimitHigh
+	if limit <= 0 {
+		return 0
+	}
+
+	mp.lk.RLock()
+	defer mp.lk.RUnlock()
+	return float64(mp.currentSize) / float64(limit)
+}
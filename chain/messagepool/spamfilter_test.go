@@ -0,0 +1,125 @@
+package messagepool
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/messagepool/gasguess"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/wallet"
+)
+
+func TestSpamFilter(t *testing.T) {
+	f, err := NewSpamFilter(SpamFilterConfig{
+		MaxSenderPushes:    2,
+		SenderRateInterval: time.Minute,
+		PressureThreshold:  0.5,
+		PressureMinPremium: types.NewInt(10),
+		RecentWindow:       10 * time.Minute,
+		MaxReplacements:    1,
+		BanThreshold:       3,
+		BanDuration:        time.Hour,
+	})
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	f.now = func() time.Time { return now }
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	a1, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+	a2, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+
+	requireRefused := func(m *types.SignedMessage, pressure float64, expected error) {
+		err := f.Check(m, m.Message.From, pressure)
+		require.True(t, xerrors.Is(err, expected), "expected %s, got %v", expected, err)
+	}
+
+	m := makeTestMessage(w, a1, a2, 0, 1000, 1)
+	require.NoError(t, f.Check(m, a1, 0))
+	f.Accepted(m, a1)
+	requireRefused(m, 0, ErrDuplicateMessage)
+
+	// 2 pushes per minute
+	now = now.Add(time.Minute)
+	replaced := makeTestMessage(w, a1, a2, 0, 1000, 2)
+	require.NoError(t, f.Check(replaced, a1, 0))
+	f.Accepted(replaced, a1)
+	require.NoError(t, f.Check(makeTestMessage(w, a1, a2, 1, 1000, 1), a1, 0))
+	requireRefused(makeTestMessage(w, a1, a2, 2, 1000, 1), 0, ErrSenderRateLimited)
+
+	now = now.Add(time.Minute)
+	requireRefused(makeTestMessage(w, a1, a2, 0, 1000, 3), 0, ErrTooManyReplacements)
+
+	// only under pressure
+	requireRefused(makeTestMessage(w, a2, a1, 0, 1000, 5), 0.6, ErrPremiumTooLowOnPressure)
+	require.NoError(t, f.Check(makeTestMessage(w, a2, a1, 0, 1000, 5), a2, 0.4))
+
+	// the third rejection in the recent window bans the sender
+	f.Rejected(a1)
+	requireRefused(makeTestMessage(w, a1, a2, 1, 1000, 1), 0, ErrSenderBanned)
+	now = now.Add(time.Hour)
+	require.NoError(t, f.Check(makeTestMessage(w, a1, a2, 1, 1000, 1), a1, 0))
+
+	// state is forgotten after the recent window
+	now = now.Add(11 * time.Minute)
+	require.NoError(t, f.Check(m, a1, 0))
+}
+
+func TestPushUntrustedSpamFilter(t *testing.T) {
+	mp, tma := makeTestMpool()
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	a1, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+	a2, err := w.WalletNew(context.Background(), types.KTSecp256k1)
+	require.NoError(t, err)
+
+	tma.applyBlock(t, tma.nextBlock())
+	tma.setBalance(a1, 1) // in FIL
+	tma.setBalanceRaw(a2, types.NewInt(0))
+
+	f, err := NewSpamFilter(SpamFilterConfig{
+		RecentWindow: time.Minute,
+		BanThreshold: 2,
+		BanDuration:  time.Hour,
+	})
+	require.NoError(t, err)
+	mp.SetSpamFilter(f)
+
+	gasLimit := gasguess.Costs[gasguess.CostKey{Code: builtin2.StorageMarketActorCodeID, M: 2}]
+	m := makeTestMessage(w, a1, a2, 0, gasLimit, 1)
+	_, err = mp.PushUntrusted(context.Background(), m)
+	require.NoError(t, err)
+	_, err = mp.PushUntrusted(context.Background(), m)
+	require.True(t, xerrors.Is(err, ErrDuplicateMessage), err)
+
+	// a2 has no funds, its messages are rejected by the pool, which counts
+	// towards bans
+	for i := 0; i < 2; i++ {
+		_, err = mp.PushUntrusted(context.Background(), makeTestMessage(w, a2, a1, uint64(i), gasLimit, 1))
+		require.True(t, xerrors.Is(err, ErrNotEnoughFunds), err)
+	}
+	_, err = mp.PushUntrusted(context.Background(), makeTestMessage(w, a2, a1, 0, gasLimit, 1))
+	require.True(t, xerrors.Is(err, ErrSenderBanned), err)
+
+	// messages with invalid signatures don't count towards bans of their
+	// sender
+	for i := 0; i < 3; i++ {
+		forged := makeTestMessage(w, a1, a2, uint64(i+1), gasLimit, 1)
+		forged.Signature.Data[0] ^= 0xff
+		_, err = mp.PushUntrusted(context.Background(), forged)
+		require.Error(t, err)
+	}
+	_, err = mp.PushUntrusted(context.Background(), makeTestMessage(w, a1, a2, 1, gasLimit, 1))
+	require.NoError(t, err)
+}
@@ -0,0 +1,384 @@
+package messagescheduler
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("messagescheduler")
+
+// maxPushAttempts is the number of failed pushes after which a scheduled
+// message is failed
+const maxPushAttempts = 5
+
+// finishedRetention is how long scheduled messages which are no longer
+// pending are kept
+const finishedRetention = 7 * 24 * time.Hour
+
+// API is the node API the scheduler watches the chain and pushes messages with.
+type API interface {
+	ChainNotify(context.Context) (<-chan []*api.HeadChange, error)
+	MpoolPushMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) (*types.SignedMessage, error)
+}
+
+// MessageScheduler pushes scheduled messages once the conditions of their
+// schedule hold, checking them at every new head. Scheduled messages are
+// persisted in the datastore.
+type MessageScheduler struct {
+	api API
+	ds  datastore.Batching
+	now func() time.Time
+
+	lk   sync.Mutex
+	msgs map[uuid.UUID]*api.ScheduledMessage
+	// pushing holds the IDs of the due messages queued or being pushed; they are
+	// pushed without holding the lock, so that pushes don't block the API
+	pushing map[uuid.UUID]struct{}
+	queue   []*dueMessage
+	queued  chan struct{}
+}
+
+// dueMessage is a scheduled message queued to be pushed
+type dueMessage struct {
+	id     uuid.UUID
+	msg    types.Message
+	maxFee abi.TokenAmount
+	height abi.ChainEpoch
+}
+
+// NewMessageScheduler creates a scheduler, loading the scheduled messages
+// from the datastore.
+func NewMessageScheduler(ctx context.Context, a API, ds datastore.Batching) (*MessageScheduler, error) {
+	s := &MessageScheduler{
+		api:  a,
+		ds:   ds,
+		now:  time.Now,
+		msgs: map[uuid.UUID]*api.ScheduledMessage{},
+
+		pushing: map[uuid.UUID]struct{}{},
+		queued:  make(chan struct{}, 1),
+	}
+
+	res, err := ds.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, xerrors.Errorf("querying scheduled messages: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, xerrors.Errorf("reading scheduled messages: %w", r.Error)
+		}
+		var sm api.ScheduledMessage
+		if err := json.Unmarshal(r.Value, &sm); err != nil {
+			return nil, xerrors.Errorf("decoding scheduled message %s: %w", r.Key, err)
+		}
+		s.msgs[sm.ID] = &sm
+	}
+
+	return s, nil
+}
+
+// Run checks the scheduled messages at every new head until the context is
+// cancelled. The due messages are pushed in the background.
+func (s *MessageScheduler) Run(ctx context.Context) {
+	go s.pushLoop(ctx)
+
+	var notifs <-chan []*api.HeadChange
+	for {
+		if notifs == nil {
+			var err error
+			notifs, err = s.api.ChainNotify(ctx)
+			if err != nil {
+				log.Errorf("ChainNotify error: %+v", err)
+
+				select {
+				case <-build.Clock.After(10 * time.Second):
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+		}
+
+		select {
+		case changes, ok := <-notifs:
+			if !ok {
+				log.Warn("message scheduler notifs channel closed")
+				notifs = nil
+				continue
+			}
+
+			var head *types.TipSet
+			for _, change := range changes {
+				if change.Type == store.HCCurrent || change.Type == store.HCApply {
+					head = change.Val
+				}
+			}
+			if head != nil {
+				s.check(ctx, head)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Schedule schedules a message to be pushed once the conditions of the
+// schedule hold. The MsgUuid of the spec, if set, is the ID of the scheduled
+// message; pushes use it so that a message is never pushed twice.
+func (s *MessageScheduler) Schedule(ctx context.Context, msg *types.Message, schedule api.MessageSchedule, spec *api.MessageSendSpec) (*api.ScheduledMessage, error) {
+	if schedule.AtEpoch <= 0 && schedule.AtTime.IsZero() && schedule.BaseFeeBelow == nil {
+		return nil, api.NewErrInvalidParams("no_schedule", "the schedule has no conditions")
+	}
+	if schedule.BaseFeeBelow != nil && schedule.BaseFeeBelow.Sign() <= 0 {
+		return nil, api.NewErrInvalidParams("invalid_schedule", "the base fee condition must be positive")
+	}
+	if schedule.ExpireEpoch > 0 && schedule.ExpireEpoch <= schedule.AtEpoch {
+		return nil, api.NewErrInvalidParams("invalid_schedule", "the schedule expires at epoch %d, before its epoch %d", schedule.ExpireEpoch, schedule.AtEpoch)
+	}
+	if msg.Nonce != 0 {
+		return nil, api.NewErrInvalidParams("invalid_message", "scheduled messages are assigned a nonce when pushed, the nonce must be 0, was %d", msg.Nonce)
+	}
+
+	id := uuid.New()
+	maxFee := big.Zero()
+	if spec != nil {
+		if spec.MsgUuid != uuid.Nil {
+			id = spec.MsgUuid
+		}
+		if !spec.MaxFee.NilOrZero() {
+			maxFee = spec.MaxFee
+		}
+	}
+
+	cp := *msg
+	sm := &api.ScheduledMessage{
+		ID:       id,
+		Message:  &cp,
+		MaxFee:   maxFee,
+		Schedule: schedule,
+		State:    api.ScheduledMessagePending,
+		Created:  s.now(),
+	}
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if _, ok := s.msgs[id]; ok {
+		return nil, api.NewErrInvalidParams("duplicate_message", "a message is already scheduled with ID %s", id)
+	}
+	if err := s.save(ctx, sm); err != nil {
+		return nil, err
+	}
+	s.msgs[id] = sm
+
+	log.Infow("scheduled message", "id", id, "from", msg.From, "to", msg.To, "method", msg.Method)
+
+	out := *sm
+	return &out, nil
+}
+
+// List returns the scheduled messages, in the order they were scheduled.
+func (s *MessageScheduler) List(ctx context.Context) ([]*api.ScheduledMessage, error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	out := make([]*api.ScheduledMessage, 0, len(s.msgs))
+	for _, sm := range s.sorted() {
+		cp := *sm
+		out = append(out, &cp)
+	}
+	return out, nil
+}
+
+// Cancel cancels a pending scheduled message.
+func (s *MessageScheduler) Cancel(ctx context.Context, id uuid.UUID) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	sm, ok := s.msgs[id]
+	if !ok {
+		return api.NewErrNotFound("scheduled_message_not_found", false, "no scheduled message with ID %s", id)
+	}
+	if sm.State != api.ScheduledMessagePending {
+		return api.NewErrInvalidParams("not_pending", "scheduled message %s is %s", id, sm.State)
+	}
+	if _, ok := s.pushing[id]; ok {
+		return api.NewErrInvalidParams("not_pending", "scheduled message %s is being pushed", id)
+	}
+
+	return s.finish(ctx, sm, api.ScheduledMessageCancelled)
+}
+
+// sorted returns the scheduled messages, in the order they were scheduled
+func (s *MessageScheduler) sorted() []*api.ScheduledMessage {
+	out := make([]*api.ScheduledMessage, 0, len(s.msgs))
+	for _, sm := range s.msgs {
+		out = append(out, sm)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Created.Before(out[j].Created)
+	})
+	return out
+}
+
+// check queues the pending messages due at head to be pushed, expires the ones
+// past their schedule, and drops old messages which are no longer pending.
+func (s *MessageScheduler) check(ctx context.Context, head *types.TipSet) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	now := s.now()
+	baseFee := head.Blocks()[0].ParentBaseFee
+
+	for _, sm := range s.sorted() {
+		if sm.State != api.ScheduledMessagePending {
+			if now.Sub(sm.Finished) > finishedRetention {
+				if err := s.ds.Delete(ctx, dsKey(sm.ID)); err != nil {
+					log.Errorw("deleting scheduled message", "id", sm.ID, "error", err)
+					continue
+				}
+				delete(s.msgs, sm.ID)
+			}
+			continue
+		}
+
+		if _, ok := s.pushing[sm.ID]; ok {
+			continue
+		}
+
+		sched := sm.Schedule
+		if sched.ExpireEpoch > 0 && head.Height() >= sched.ExpireEpoch {
+			log.Warnw("scheduled message expired", "id", sm.ID, "expire-epoch", sched.ExpireEpoch)
+			if err := s.finish(ctx, sm, api.ScheduledMessageExpired); err != nil {
+				log.Errorw("saving scheduled message", "id", sm.ID, "error", err)
+			}
+			continue
+		}
+		if head.Height() < sched.AtEpoch || now.Before(sched.AtTime) ||
+			(sched.BaseFeeBelow != nil && !baseFee.LessThan(*sched.BaseFeeBelow)) {
+			continue
+		}
+
+		s.pushing[sm.ID] = struct{}{}
+		s.queue = append(s.queue, &dueMessage{
+			id:     sm.ID,
+			msg:    *sm.Message,
+			maxFee: sm.MaxFee,
+			height: head.Height(),
+		})
+	}
+
+	if len(s.queue) > 0 {
+		select {
+		case s.queued <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// pushLoop pushes the queued messages until the context is cancelled
+func (s *MessageScheduler) pushLoop(ctx context.Context) {
+	for {
+		select {
+		case <-s.queued:
+			s.pushQueued(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// pushQueued pushes the queued messages, in the order they were scheduled
+func (s *MessageScheduler) pushQueued(ctx context.Context) {
+	s.lk.Lock()
+	queue := s.queue
+	s.queue = nil
+	s.lk.Unlock()
+
+	for _, d := range queue {
+		s.push(ctx, d)
+	}
+}
+
+// push pushes a due message, failing it after maxPushAttempts failed pushes
+func (s *MessageScheduler) push(ctx context.Context, d *dueMessage) {
+	smsg, err := s.api.MpoolPushMessage(ctx, &d.msg, &api.MessageSendSpec{
+		MaxFee:  d.maxFee,
+		MsgUuid: d.id,
+	})
+
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	delete(s.pushing, d.id)
+	sm, ok := s.msgs[d.id]
+	if !ok {
+		return
+	}
+
+	if err != nil {
+		sm.Attempts++
+		sm.Error = err.Error()
+		log.Warnw("pushing scheduled message", "id", sm.ID, "attempt", sm.Attempts, "error", err)
+
+		if sm.Attempts >= maxPushAttempts {
+			err = s.finish(ctx, sm, api.ScheduledMessageFailed)
+		} else {
+			err = s.save(ctx, sm)
+		}
+		if err != nil {
+			log.Errorw("saving scheduled message", "id", sm.ID, "error", err)
+		}
+		return
+	}
+
+	c := smsg.Cid()
+	sm.Signed = &c
+	sm.PushEpoch = d.height
+	sm.Error = ""
+	log.Infow("pushed scheduled message", "id", sm.ID, "cid", c, "epoch", d.height)
+
+	if err := s.finish(ctx, sm, api.ScheduledMessagePushed); err != nil {
+		log.Errorw("saving scheduled message", "id", sm.ID, "error", err)
+	}
+}
+
+func (s *MessageScheduler) finish(ctx context.Context, sm *api.ScheduledMessage, state api.ScheduledMessageState) error {
+	sm.State = state
+	sm.Finished = s.now()
+	return s.save(ctx, sm)
+}
+
+func (s *MessageScheduler) save(ctx context.Context, sm *api.ScheduledMessage) error {
+	b, err := json.Marshal(sm)
+	if err != nil {
+		return xerrors.Errorf("encoding scheduled message: %w", err)
+	}
+	if err := s.ds.Put(ctx, dsKey(sm.ID), b); err != nil {
+		return xerrors.Errorf("saving scheduled message: %w", err)
+	}
+	return nil
+}
+
+func dsKey(id uuid.UUID) datastore.Key {
+	return datastore.NewKey(id.String())
+}
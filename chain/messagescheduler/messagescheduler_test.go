@@ -0,0 +1,142 @@
+package messagescheduler
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+type testAPI struct {
+	pushed []*api.MessageSendSpec
+	fail   bool
+}
+
+func (a *testAPI) ChainNotify(context.Context) (<-chan []*api.HeadChange, error) {
+	return nil, xerrors.Errorf("not implemented")
+}
+
+func (a *testAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) (*types.SignedMessage, error) {
+	if a.fail {
+		return nil, xerrors.Errorf("not enough funds")
+	}
+	a.pushed = append(a.pushed, spec)
+	return &types.SignedMessage{Message: *msg}, nil
+}
+
+func mkHead(height abi.ChainEpoch, baseFee int64) *types.TipSet {
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Height = height
+	blk.ParentBaseFee = big.NewInt(baseFee)
+	return mock.TipSet(blk)
+}
+
+func TestMessageScheduler(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	a := &testAPI{}
+
+	s, err := NewMessageScheduler(ctx, a, ds)
+	require.NoError(t, err)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+
+	msg := &types.Message{From: mock.Address(1000), To: mock.Address(1001), Value: big.NewInt(1)}
+	schedule := func(sched api.MessageSchedule) *api.ScheduledMessage {
+		sm, err := s.Schedule(ctx, msg, sched, nil)
+		require.NoError(t, err)
+		now = now.Add(time.Second)
+		return sm
+	}
+
+	_, err = s.Schedule(ctx, msg, api.MessageSchedule{}, nil)
+	require.Error(t, err)
+	_, err = s.Schedule(ctx, msg, api.MessageSchedule{AtEpoch: 10, ExpireEpoch: 5}, nil)
+	require.Error(t, err)
+
+	feeCond := big.NewInt(100)
+	atEpoch := schedule(api.MessageSchedule{AtEpoch: 10})
+	atTime := schedule(api.MessageSchedule{AtTime: now.Add(time.Hour)})
+	lowFee := schedule(api.MessageSchedule{BaseFeeBelow: &feeCond, ExpireEpoch: 20})
+	cancelled := schedule(api.MessageSchedule{AtEpoch: 10})
+
+	require.NoError(t, s.Cancel(ctx, cancelled.ID))
+	require.Error(t, s.Cancel(ctx, cancelled.ID))
+	require.Error(t, s.Cancel(ctx, uuid.New()))
+
+	state := func(id uuid.UUID) api.ScheduledMessageState {
+		list, err := s.List(ctx)
+		require.NoError(t, err)
+		for _, sm := range list {
+			if sm.ID == id {
+				return sm.State
+			}
+		}
+		return ""
+	}
+
+	check := func(head *types.TipSet) {
+		s.check(ctx, head)
+		s.pushQueued(ctx)
+	}
+
+	check(mkHead(9, 200))
+	require.Empty(t, a.pushed)
+
+	check(mkHead(10, 200))
+	require.Len(t, a.pushed, 1)
+	require.Equal(t, atEpoch.ID, a.pushed[0].MsgUuid)
+	require.Equal(t, api.ScheduledMessagePushed, state(atEpoch.ID))
+	require.Equal(t, api.ScheduledMessageCancelled, state(cancelled.ID))
+
+	now = now.Add(time.Hour)
+	check(mkHead(11, 200))
+	require.Len(t, a.pushed, 2)
+	require.Equal(t, atTime.ID, a.pushed[1].MsgUuid)
+
+	// messages being pushed can't be cancelled, and aren't queued again
+	s.check(ctx, mkHead(12, 50))
+	require.Error(t, s.Cancel(ctx, lowFee.ID))
+	s.check(ctx, mkHead(13, 50))
+	require.Len(t, s.queue, 1)
+	s.queue = nil
+	delete(s.pushing, lowFee.ID)
+
+	// failed pushes are retried
+	a.fail = true
+	check(mkHead(12, 50))
+	require.Equal(t, api.ScheduledMessagePending, state(lowFee.ID))
+
+	// the schedule is persisted
+	s, err = NewMessageScheduler(ctx, a, ds)
+	require.NoError(t, err)
+	s.now = func() time.Time { return now }
+	list, err := s.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 4)
+	require.Equal(t, lowFee.ID, list[2].ID)
+	require.Equal(t, 1, list[2].Attempts)
+
+	// expired past its epoch
+	check(mkHead(20, 50))
+	require.Equal(t, api.ScheduledMessageExpired, state(lowFee.ID))
+
+	// finished messages are dropped after a while
+	now = now.Add(finishedRetention + time.Second)
+	check(mkHead(21, 50))
+	list, err = s.List(ctx)
+	require.NoError(t, err)
+	require.Empty(t, list)
+}
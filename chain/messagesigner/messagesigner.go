@@ -34,20 +34,35 @@ type MsgSigner interface {
 	SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error
 }
 
+// NonceTracker assigns nonces to the messages signed by the node. Once a nonce
+// handed out by NextNonce was passed to SaveNonce, it must never be handed out
+// again for the same address.
+type NonceTracker interface {
+	NextNonce(ctx context.Context, addr address.Address) (uint64, error)
+	SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error
+}
+
 // MessageSigner keeps track of nonces per address, and increments the nonce
 // when signing a message
 type MessageSigner struct {
 	wallet api.Wallet
 	lk     sync.Mutex
-	mpool  messagepool.MpoolNonceAPI
+	nonces NonceTracker
 	ds     datastore.Batching
 }
 
 func NewMessageSigner(wallet api.Wallet, mpool messagepool.MpoolNonceAPI, ds dtypes.MetadataDS) *MessageSigner {
 	ds = namespace.Wrap(ds, datastore.NewKey("/message-signer/"))
+	return NewMessageSignerWithNonceTracker(wallet, NewDatastoreNonceTracker(mpool, ds), ds)
+}
+
+// NewMessageSignerWithNonceTracker creates a MessageSigner which delegates
+// nonce assignment to the given NonceTracker. Signed messages are stored in
+// the given datastore.
+func NewMessageSignerWithNonceTracker(wallet api.Wallet, nonces NonceTracker, ds datastore.Batching) *MessageSigner {
 	return &MessageSigner{
 		wallet: wallet,
-		mpool:  mpool,
+		nonces: nonces,
 		ds:     ds,
 	}
 }
@@ -125,20 +140,47 @@ func (ms *MessageSigner) StoreSignedMessage(ctx context.Context, uuid uuid.UUID,
 }
 
 // NextNonce gets the next nonce for the given address.
-// If there is no nonce in the datastore, gets the nonce from the message pool.
 func (ms *MessageSigner) NextNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	return ms.nonces.NextNonce(ctx, addr)
+}
+
+// SaveNonce marks the given nonce as used for this address
+func (ms *MessageSigner) SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error {
+	return ms.nonces.SaveNonce(ctx, addr, nonce)
+}
+
+// DatastoreNonceTracker is the default NonceTracker. It persists the next
+// nonce for each address in a datastore, falling back to the message pool
+// for addresses it hasn't seen before.
+type DatastoreNonceTracker struct {
+	mpool messagepool.MpoolNonceAPI
+	ds    datastore.Batching
+}
+
+var _ NonceTracker = (*DatastoreNonceTracker)(nil)
+
+func NewDatastoreNonceTracker(mpool messagepool.MpoolNonceAPI, ds datastore.Batching) *DatastoreNonceTracker {
+	return &DatastoreNonceTracker{
+		mpool: mpool,
+		ds:    ds,
+	}
+}
+
+// NextNonce gets the next nonce for the given address.
+// If there is no nonce in the datastore, gets the nonce from the message pool.
+func (nt *DatastoreNonceTracker) NextNonce(ctx context.Context, addr address.Address) (uint64, error) {
 	// Nonces used to be created by the mempool and we need to support nodes
 	// that have mempool nonces, so first check the mempool for a nonce for
 	// this address. Note that the mempool returns the actor state's nonce
 	// by default.
-	nonce, err := ms.mpool.GetNonce(ctx, addr, types.EmptyTSK)
+	nonce, err := nt.mpool.GetNonce(ctx, addr, types.EmptyTSK)
 	if err != nil {
 		return 0, xerrors.Errorf("failed to get nonce from mempool: %w", err)
 	}
 
 	// Get the next nonce for this address from the datastore
-	addrNonceKey := ms.dstoreKey(addr)
-	dsNonceBytes, err := ms.ds.Get(ctx, addrNonceKey)
+	addrNonceKey := nt.dstoreKey(addr)
+	dsNonceBytes, err := nt.ds.Get(ctx, addrNonceKey)
 
 	switch {
 	case xerrors.Is(err, datastore.ErrNotFound):
@@ -172,25 +214,25 @@ func (ms *MessageSigner) NextNonce(ctx context.Context, addr address.Address) (u
 
 // SaveNonce increments the nonce for this address and writes it to the
 // datastore
-func (ms *MessageSigner) SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error {
+func (nt *DatastoreNonceTracker) SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error {
 	// Increment the nonce
 	nonce++
 
 	// Write the nonce to the datastore
-	addrNonceKey := ms.dstoreKey(addr)
+	addrNonceKey := nt.dstoreKey(addr)
 	buf := bytes.Buffer{}
 	_, err := buf.Write(cbg.CborEncodeMajorType(cbg.MajUnsignedInt, nonce))
 	if err != nil {
 		return xerrors.Errorf("failed to marshall nonce: %w", err)
 	}
-	err = ms.ds.Put(ctx, addrNonceKey, buf.Bytes())
+	err = nt.ds.Put(ctx, addrNonceKey, buf.Bytes())
 	if err != nil {
 		return xerrors.Errorf("failed to write nonce to datastore: %w", err)
 	}
 	return nil
 }
 
-func (ms *MessageSigner) dstoreKey(addr address.Address) datastore.Key {
+func (nt *DatastoreNonceTracker) dstoreKey(addr address.Address) datastore.Key {
 	return datastore.KeyWithNamespaces([]string{dsKeyActorNonce, addr.String()})
 }
 
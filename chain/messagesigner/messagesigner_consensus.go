@@ -9,6 +9,8 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-address"
+
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -28,16 +30,52 @@ func NewMessageSignerConsensus(
 	consensus *consensus.Consensus) *MessageSignerConsensus {
 
 	ds = namespace.Wrap(ds, datastore.NewKey("/message-signer-consensus/"))
+	nonces := &ConsensusNonceTracker{
+		NonceTracker: NewDatastoreNonceTracker(mpool, ds),
+		Consensus:    consensus,
+	}
 	return &MessageSignerConsensus{
-		MsgSigner: &MessageSigner{
-			wallet: wallet,
-			mpool:  mpool,
-			ds:     ds,
-		},
+		MsgSigner: NewMessageSignerWithNonceTracker(wallet, nonces, ds),
 		Consensus: consensus,
 	}
 }
 
+// ConsensusNonceTracker extends a local NonceTracker with the nonces committed
+// to the cluster state. Nonces are only persisted locally by the node which
+// signed the message, so without this a standby node taking over as leader
+// could assign nonces which the previous leader already used.
+type ConsensusNonceTracker struct {
+	NonceTracker
+	Consensus ClusterState
+}
+
+// ClusterState returns the state replicated across the nodes of the cluster,
+// implemented by consensus.Consensus.
+type ClusterState interface {
+	State(ctx context.Context) (*consensus.RaftState, error)
+}
+
+var _ NonceTracker = (*ConsensusNonceTracker)(nil)
+
+func (nt *ConsensusNonceTracker) NextNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	nonce, err := nt.NonceTracker.NextNonce(ctx, addr)
+	if err != nil {
+		return 0, err
+	}
+
+	cstate, err := nt.Consensus.State(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("getting cluster state: %w", err)
+	}
+
+	if clusterNonce, ok := cstate.NonceMap[addr]; ok && clusterNonce >= nonce {
+		log.Infow("using nonce from cluster state", "addr", addr, "local", nonce, "cluster", clusterNonce+1)
+		nonce = clusterNonce + 1
+	}
+
+	return nonce, nil
+}
+
 func (ms *MessageSignerConsensus) IsLeader(ctx context.Context) bool {
 	return ms.Consensus.IsLeader(ctx)
 }
@@ -75,6 +113,27 @@ func (ms *MessageSignerConsensus) SignMessage(
 	return signedMsg, nil
 }
 
+// SaveNonce marks the nonce as used for this address, both locally and in the
+// cluster state. It's used to track nonces of messages which weren't signed by
+// the node itself. If this node isn't the leader, the update is forwarded to
+// the leader.
+func (ms *MessageSignerConsensus) SaveNonce(ctx context.Context, addr address.Address, nonce uint64) error {
+	op := &consensus.ConsensusOp{
+		Nonce: nonce,
+		Addr:  addr,
+	}
+
+	redirected, err := ms.Consensus.RedirectToLeader("SaveNonce", op, &struct{}{})
+	if err != nil || redirected {
+		return err
+	}
+
+	if err := ms.MsgSigner.SaveNonce(ctx, addr, nonce); err != nil {
+		return err
+	}
+	return ms.Consensus.Commit(ctx, op)
+}
+
 func (ms *MessageSignerConsensus) GetSignedMessage(ctx context.Context, uuid uuid.UUID) (*types.SignedMessage, error) {
 	cstate, err := ms.Consensus.State(ctx)
 	if err != nil {
@@ -0,0 +1,69 @@
+// stm: #unit
+package messagesigner
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	ds_sync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	consensus "github.com/filecoin-project/lotus/lib/consensus/raft"
+)
+
+type mockClusterState struct {
+	state *consensus.RaftState
+	err   error
+}
+
+func (cs *mockClusterState) State(context.Context) (*consensus.RaftState, error) {
+	return cs.state, cs.err
+}
+
+func TestConsensusNonceTracker(t *testing.T) {
+	ctx := context.Background()
+
+	tracked, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	other, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	mpool := newMockMpool()
+	cs := &mockClusterState{state: &consensus.RaftState{NonceMap: map[address.Address]uint64{}}}
+	nt := &ConsensusNonceTracker{
+		NonceTracker: NewDatastoreNonceTracker(mpool, ds_sync.MutexWrap(datastore.NewMapDatastore())),
+		Consensus:    cs,
+	}
+
+	// nothing committed to the cluster state, the local nonce is used
+	mpool.setNonce(tracked, 5)
+	nonce, err := nt.NextNonce(ctx, tracked)
+	require.NoError(t, err)
+	require.Equal(t, uint64(5), nonce)
+
+	// a previous leader used nonces this node doesn't know about
+	cs.state.NonceMap[tracked] = 9
+	nonce, err = nt.NextNonce(ctx, tracked)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), nonce)
+
+	// once this node saved the nonce, the local nonce is ahead again
+	require.NoError(t, nt.SaveNonce(ctx, tracked, 10))
+	nonce, err = nt.NextNonce(ctx, tracked)
+	require.NoError(t, err)
+	require.Equal(t, uint64(11), nonce)
+
+	// the nonces of other addresses are unaffected
+	nonce, err = nt.NextNonce(ctx, other)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), nonce)
+
+	// without the cluster state no nonce can safely be assigned
+	cs.err = xerrors.New("no leader")
+	_, err = nt.NextNonce(ctx, tracked)
+	require.Error(t, err)
+}
@@ -203,3 +203,43 @@ func TestMessageSignerSignMessage(t *testing.T) {
 		})
 	}
 }
+
+type mockNonceTracker struct {
+	next map[address.Address]uint64
+}
+
+func (nt *mockNonceTracker) NextNonce(_ context.Context, addr address.Address) (uint64, error) {
+	return nt.next[addr], nil
+}
+
+func (nt *mockNonceTracker) SaveNonce(_ context.Context, addr address.Address, nonce uint64) error {
+	nt.next[addr] = nonce + 1
+	return nil
+}
+
+func TestMessageSignerCustomNonceTracker(t *testing.T) {
+	ctx := context.Background()
+
+	w, _ := wallet.NewWallet(wallet.NewMemKeyStore())
+	from, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	to, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	nt := &mockNonceTracker{next: map[address.Address]uint64{from: 10}}
+	ms := NewMessageSignerWithNonceTracker(w, nt, ds_sync.MutexWrap(datastore.NewMapDatastore()))
+
+	noop := func(*types.SignedMessage) error { return nil }
+
+	smsg, err := ms.SignMessage(ctx, &types.Message{To: to, From: from}, nil, noop)
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), smsg.Message.Nonce)
+	require.Equal(t, uint64(11), nt.next[from])
+
+	// a failed callback must not consume the nonce
+	_, err = ms.SignMessage(ctx, &types.Message{To: to, From: from}, nil, func(*types.SignedMessage) error {
+		return xerrors.Errorf("err")
+	})
+	require.Error(t, err)
+	require.Equal(t, uint64(11), nt.next[from])
+}
@@ -0,0 +1,70 @@
+//go:build mqbridge
+
+package mqbridge
+
+import (
+	"context"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"golang.org/x/xerrors"
+)
+
+// Kafka publishes the records of each kind to the topic named after the kind, prefixed with the
+// topic prefix, waiting for the acknowledgement of all in-sync replicas.
+type Kafka struct {
+	w      *kafka.Writer
+	prefix string
+}
+
+func NewKafka(brokers []string, topicPrefix string) (*Kafka, error) {
+	if len(brokers) == 0 {
+		return nil, xerrors.Errorf("no kafka brokers")
+	}
+
+	return &Kafka{
+		w: &kafka.Writer{
+			Addr: kafka.TCP(brokers...),
+			// records are written to the first partition of the topics, so that they are consumed
+			// in chain order
+			Balancer: kafka.BalancerFunc(func(_ kafka.Message, partitions ...int) int {
+				return partitions[0]
+			}),
+			RequiredAcks: kafka.RequireAll,
+			BatchSize:    1000,
+			BatchTimeout: 10 * time.Millisecond,
+		},
+		prefix: topicPrefix,
+	}, nil
+}
+
+func (k *Kafka) Publish(ctx context.Context, records []Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	msgs := make([]kafka.Message, len(records))
+	for i, r := range records {
+		msgs[i] = kafka.Message{
+			Topic: k.prefix + string(r.Kind),
+			Key:   []byte(r.ID),
+			Value: r.Value,
+		}
+	}
+	if err := k.w.WriteMessages(ctx, msgs...); err != nil {
+		return xerrors.Errorf("writing %d records to kafka: %w", len(msgs), err)
+	}
+	return nil
+}
+
+func (k *Kafka) Close() error {
+	return k.w.Close()
+}
+
+var _ Publisher = &Kafka{}
+
+func init() {
+	backends["kafka"] = func(servers []string, prefix string) (Publisher, error) {
+		return NewKafka(servers, prefix)
+	}
+}
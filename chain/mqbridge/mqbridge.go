@@ -0,0 +1,427 @@
+// Package mqbridge streams the chain to message queues: the applied and reverted tipsets, the
+// receipts of the executed messages and the actor events matching configured queries are published
+// to Kafka or NATS, for indexers and analytics pipelines which can't follow the chain through the
+// node API.
+//
+// The Kafka and NATS clients are only built into the node with the mqbridge build tag.
+package mqbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("mqbridge")
+
+// DatastorePrefix is the namespace of the metadata datastore the bridge persists its cursor in.
+var DatastorePrefix = datastore.NewKey("/chainbridge")
+
+var cursorKey = datastore.NewKey("/cursor")
+
+// ErrCursorExpired is returned when the head changes can't be resumed from the persisted cursor
+// anymore. The bridge doesn't skip the changes since the cursor on its own, the operator resets the
+// cursor with ResetCursor to publish from the head.
+var ErrCursorExpired = xerrors.New("the chain bridge cursor can't be resumed from")
+
+// Kind is the kind of a record, each kind is published to its own topic.
+type Kind string
+
+const (
+	KindTipSet  Kind = "tipsets"
+	KindReceipt Kind = "receipts"
+	KindEvent   Kind = "events"
+)
+
+// Record is a JSON encoded TipSetRecord, ReceiptRecord or EventRecord. ID identifies the record,
+// records published again after a restart or a failure have the same ID, so that consumers can
+// drop duplicates.
+type Record struct {
+	Kind  Kind
+	ID    string
+	Value []byte
+}
+
+// Publisher publishes records to a message queue.
+type Publisher interface {
+	// Publish returns once all records are acknowledged by the broker, in order within each
+	// kind.
+	Publish(ctx context.Context, records []Record) error
+	Close() error
+}
+
+// backends create the publishers of the message queues built into the node.
+var backends = map[string]func(servers []string, prefix string) (Publisher, error){}
+
+// NewPublisher creates a publisher to the servers of a backend, "kafka" or "nats". The records
+// are published to the topics or subjects named after their kind, with the prefix.
+func NewPublisher(backend string, servers []string, prefix string) (Publisher, error) {
+	newPub, ok := backends[backend]
+	switch {
+	case ok:
+		return newPub(servers, prefix)
+	case backend == "kafka" || backend == "nats":
+		return nil, xerrors.Errorf("the %s chain bridge backend is not built in, build the node with the mqbridge build tag", backend)
+	default:
+		return nil, xerrors.Errorf("unknown chain bridge backend %q", backend)
+	}
+}
+
+// TipSetRecord is published when a tipset is applied to or reverted from the chain. Type is
+// "apply" or "revert".
+type TipSetRecord struct {
+	Type      string
+	Height    abi.ChainEpoch
+	TipSet    types.TipSetKey
+	Parents   types.TipSetKey
+	Timestamp uint64
+}
+
+// ReceiptRecord is the receipt of a message executed in the parent state of TipSet: receipts are
+// published with the tipset after the one including the messages, and reverted with it.
+type ReceiptRecord struct {
+	Type    string
+	Height  abi.ChainEpoch
+	TipSet  types.TipSetKey
+	Index   int
+	Message cid.Cid
+	From    address.Address
+	To      address.Address
+	Method  abi.MethodNum
+	Receipt types.MessageReceipt
+}
+
+// EventRecord is an actor event matching the query named Query, emitted by the message at
+// MessageIndex of the receipts of TipSet.
+type EventRecord struct {
+	Type         string
+	Query        string
+	Height       abi.ChainEpoch
+	TipSet       types.TipSetKey
+	Message      cid.Cid
+	MessageIndex int
+	EventIndex   int
+	Emitter      address.Address
+	Entries      []types.EventEntry
+}
+
+// Query selects the events emitted by Addresses, any actor when empty, whose entries with the keys
+// of Keys hold one of the listed values.
+type Query struct {
+	Name      string
+	Addresses []address.Address
+	Keys      map[string][][]byte
+}
+
+type Options struct {
+	// Receipts publishes the receipts of the executed messages.
+	Receipts bool
+	// Queries select the events published, no events are published without queries.
+	Queries []Query
+	// RetryInterval is the wait before resuming after a failure.
+	RetryInterval time.Duration
+}
+
+// API is the node API the bridge follows the chain with.
+type API interface {
+	ChainNotifyResume(ctx context.Context, cursor string) (<-chan api.HeadChangeBatch, error)
+	ChainGetParentMessages(ctx context.Context, blockCid cid.Cid) ([]api.Message, error)
+	ChainGetParentReceipts(ctx context.Context, blockCid cid.Cid) ([]*types.MessageReceipt, error)
+	ChainGetEvents(ctx context.Context, root cid.Cid) ([]types.Event, error)
+	StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
+}
+
+// Bridge publishes the head changes of the chain. The head change cursor is persisted in the
+// datastore once all records of the changes are acknowledged, and the bridge resumes from it after
+// restarts and failures, so records are published at least once.
+type Bridge struct {
+	api  API
+	pub  Publisher
+	ds   datastore.Batching
+	opts Options
+
+	// ids caches the actor IDs of the query addresses
+	ids map[address.Address]abi.ActorID
+}
+
+func New(a API, pub Publisher, ds datastore.Batching, opts Options) *Bridge {
+	if opts.RetryInterval <= 0 {
+		opts.RetryInterval = 10 * time.Second
+	}
+	return &Bridge{
+		api:  a,
+		pub:  pub,
+		ds:   ds,
+		opts: opts,
+		ids:  map[address.Address]abi.ActorID{},
+	}
+}
+
+// Run publishes the head changes until ctx is done.
+func (b *Bridge) Run(ctx context.Context) {
+	for {
+		err := b.follow(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if xerrors.Is(err, ErrCursorExpired) {
+			log.Errorw("chain bridge stopped, reset its cursor with 'lotus-shed chain-bridge reset-cursor' to publish from the head; the changes since the cursor won't be published", "error", err)
+			return
+		}
+		log.Errorw("chain bridge interrupted, resuming from the last published tipset", "error", err, "retry", b.opts.RetryInterval)
+
+		select {
+		case <-time.After(b.opts.RetryInterval):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (b *Bridge) follow(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	cursor, err := b.cursor(ctx)
+	if err != nil {
+		return err
+	}
+
+	ch, err := b.api.ChainNotifyResume(ctx, cursor)
+	if err != nil {
+		se, _, ok := api.AsStructuredError(err)
+		if cursor == "" || !ok || (se.Reason != "cursor_expired" && se.Reason != "unknown_cursor" && se.Reason != "invalid_cursor") {
+			return xerrors.Errorf("subscribing to head changes: %w", err)
+		}
+		return xerrors.Errorf("%w: %s", ErrCursorExpired, err)
+	}
+
+	for batch := range ch {
+		var records []Record
+		for _, hc := range batch.Changes {
+			rs, err := b.records(ctx, hc)
+			if err != nil {
+				return xerrors.Errorf("collecting the records of %s tipset %s: %w", hc.Type, hc.Val.Key(), err)
+			}
+			records = append(records, rs...)
+		}
+
+		if err := b.pub.Publish(ctx, records); err != nil {
+			return xerrors.Errorf("publishing: %w", err)
+		}
+		if err := b.ds.Put(ctx, cursorKey, []byte(batch.Cursor)); err != nil {
+			return xerrors.Errorf("persisting the cursor: %w", err)
+		}
+	}
+
+	return xerrors.Errorf("head change subscription closed")
+}
+
+func (b *Bridge) cursor(ctx context.Context) (string, error) {
+	c, err := b.ds.Get(ctx, cursorKey)
+	switch {
+	case xerrors.Is(err, datastore.ErrNotFound):
+		return "", nil
+	case err != nil:
+		return "", xerrors.Errorf("loading the cursor: %w", err)
+	}
+	return string(c), nil
+}
+
+// ResetCursor deletes the persisted cursor from the datastore of the bridge, so that it publishes
+// from the head.
+func ResetCursor(ctx context.Context, ds datastore.Datastore) error {
+	return ds.Delete(ctx, cursorKey)
+}
+
+// records returns the records of a head change, in chain order.
+func (b *Bridge) records(ctx context.Context, hc *api.HeadChange) ([]Record, error) {
+	typ := hc.Type
+	if typ == store.HCCurrent {
+		typ = store.HCApply
+	}
+	ts := hc.Val
+
+	tsc, err := ts.Key().Cid()
+	if err != nil {
+		return nil, err
+	}
+	id := fmt.Sprintf("%s/%s", typ, tsc)
+
+	var records []Record
+	add := func(kind Kind, id string, v interface{}) error {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return xerrors.Errorf("encoding %s record: %w", kind, err)
+		}
+		records = append(records, Record{Kind: kind, ID: id, Value: b})
+		return nil
+	}
+
+	err = add(KindTipSet, id, TipSetRecord{
+		Type:      typ,
+		Height:    ts.Height(),
+		TipSet:    ts.Key(),
+		Parents:   ts.Parents(),
+		Timestamp: ts.MinTimestamp(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.opts.Receipts && len(b.opts.Queries) == 0 {
+		return records, nil
+	}
+
+	msgs, err := b.api.ChainGetParentMessages(ctx, ts.Cids()[0])
+	if err != nil {
+		return nil, xerrors.Errorf("getting parent messages: %w", err)
+	}
+	rcpts, err := b.api.ChainGetParentReceipts(ctx, ts.Cids()[0])
+	if err != nil {
+		return nil, xerrors.Errorf("getting parent receipts: %w", err)
+	}
+	if len(msgs) != len(rcpts) {
+		return nil, xerrors.Errorf("%d parent messages but %d receipts", len(msgs), len(rcpts))
+	}
+
+	queries, err := b.resolveQueries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, r := range rcpts {
+		m := msgs[i]
+		if b.opts.Receipts {
+			err := add(KindReceipt, fmt.Sprintf("%s/%d", id, i), ReceiptRecord{
+				Type:    typ,
+				Height:  ts.Height(),
+				TipSet:  ts.Key(),
+				Index:   i,
+				Message: m.Cid,
+				From:    m.Message.From,
+				To:      m.Message.To,
+				Method:  m.Message.Method,
+				Receipt: *r,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		if len(queries) == 0 || r.EventsRoot == nil {
+			continue
+		}
+		evs, err := b.api.ChainGetEvents(ctx, *r.EventsRoot)
+		if err != nil {
+			return nil, xerrors.Errorf("getting the events of message %s: %w", m.Cid, err)
+		}
+		for ei, ev := range evs {
+			for _, q := range queries {
+				if !q.matches(&ev) {
+					continue
+				}
+				emitter, err := address.NewIDAddress(uint64(ev.Emitter))
+				if err != nil {
+					return nil, err
+				}
+				err = add(KindEvent, fmt.Sprintf("%s/%d/%d/%s", id, i, ei, q.Name), EventRecord{
+					Type:         typ,
+					Query:        q.Name,
+					Height:       ts.Height(),
+					TipSet:       ts.Key(),
+					Message:      m.Cid,
+					MessageIndex: i,
+					EventIndex:   ei,
+					Emitter:      emitter,
+					Entries:      ev.Entries,
+				})
+				if err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+
+	return records, nil
+}
+
+type resolvedQuery struct {
+	Query
+	// emitters are the IDs of the addresses, nil for any actor
+	emitters map[abi.ActorID]struct{}
+}
+
+// resolveQueries resolves the query addresses to actor IDs, which events are emitted by. Addresses
+// of actors which don't exist yet are left out until they do.
+func (b *Bridge) resolveQueries(ctx context.Context) ([]resolvedQuery, error) {
+	queries := make([]resolvedQuery, len(b.opts.Queries))
+	for i, q := range b.opts.Queries {
+		queries[i].Query = q
+		if len(q.Addresses) == 0 {
+			continue
+		}
+
+		queries[i].emitters = map[abi.ActorID]struct{}{}
+		for _, a := range q.Addresses {
+			id, ok := b.ids[a]
+			if !ok {
+				ida, err := b.api.StateLookupID(ctx, a, types.EmptyTSK)
+				if err != nil {
+					var anf *api.ErrActorNotFound
+					if xerrors.As(err, &anf) {
+						continue
+					}
+					return nil, xerrors.Errorf("resolving %s: %w", a, err)
+				}
+				idn, err := address.IDFromAddress(ida)
+				if err != nil {
+					return nil, err
+				}
+				id = abi.ActorID(idn)
+				b.ids[a] = id
+			}
+			queries[i].emitters[id] = struct{}{}
+		}
+	}
+	return queries, nil
+}
+
+func (q *resolvedQuery) matches(ev *types.Event) bool {
+	if q.emitters != nil {
+		if _, ok := q.emitters[ev.Emitter]; !ok {
+			return false
+		}
+	}
+
+	for key, vals := range q.Keys {
+		matched := false
+		for _, e := range ev.Entries {
+			if e.Key != key {
+				continue
+			}
+			for _, v := range vals {
+				if string(e.Value) == string(v) {
+					matched = true
+				}
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
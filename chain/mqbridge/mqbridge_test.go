@@ -0,0 +1,195 @@
+package mqbridge
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+type testAPI struct {
+	cursors   []string
+	subs      [][]api.HeadChangeBatch
+	resumeErr error
+
+	msgs   []api.Message
+	rcpts  []*types.MessageReceipt
+	events []types.Event
+}
+
+func (a *testAPI) ChainNotifyResume(ctx context.Context, cursor string) (<-chan api.HeadChangeBatch, error) {
+	a.cursors = append(a.cursors, cursor)
+	if cursor != "" && a.resumeErr != nil {
+		return nil, a.resumeErr
+	}
+	ch := make(chan api.HeadChangeBatch, len(a.subs[0]))
+	for _, b := range a.subs[0] {
+		ch <- b
+	}
+	close(ch)
+	a.subs = a.subs[1:]
+	return ch, nil
+}
+
+func (a *testAPI) ChainGetParentMessages(ctx context.Context, blockCid cid.Cid) ([]api.Message, error) {
+	return a.msgs, nil
+}
+
+func (a *testAPI) ChainGetParentReceipts(ctx context.Context, blockCid cid.Cid) ([]*types.MessageReceipt, error) {
+	return a.rcpts, nil
+}
+
+func (a *testAPI) ChainGetEvents(ctx context.Context, root cid.Cid) ([]types.Event, error) {
+	return a.events, nil
+}
+
+func (a *testAPI) StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error) {
+	return addr, nil
+}
+
+type testPublisher struct {
+	// failCall is the Publish call which fails, counting from 1
+	failCall int
+	calls    int
+	records  []Record
+}
+
+func (p *testPublisher) Publish(ctx context.Context, records []Record) error {
+	p.calls++
+	if p.calls == p.failCall {
+		return xerrors.New("broker unavailable")
+	}
+	p.records = append(p.records, records...)
+	return nil
+}
+
+func (p *testPublisher) Close() error {
+	return nil
+}
+
+func TestBridge(t *testing.T) {
+	ctx := context.Background()
+
+	ts1 := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	ts2 := mock.TipSet(mock.MkBlock(ts1, 1, 2))
+	ts2b := mock.TipSet(mock.MkBlock(ts1, 1, 3))
+
+	m := mock.UnsignedMessage(mock.Address(1001), mock.Address(1002), 0)
+	root := ts1.Blocks()[0].Messages
+	a := &testAPI{
+		subs: [][]api.HeadChangeBatch{
+			{
+				{Changes: []*api.HeadChange{{Type: store.HCCurrent, Val: ts1}}, Cursor: "c1"},
+				{Changes: []*api.HeadChange{{Type: store.HCApply, Val: ts2}}, Cursor: "c2"},
+			},
+			{
+				{Changes: []*api.HeadChange{{Type: store.HCApply, Val: ts2}}, Cursor: "c2"},
+				{Changes: []*api.HeadChange{{Type: store.HCRevert, Val: ts2}, {Type: store.HCApply, Val: ts2b}}, Cursor: "c3"},
+			},
+		},
+		msgs:  []api.Message{{Cid: m.Cid(), Message: m}},
+		rcpts: []*types.MessageReceipt{{GasUsed: 100, EventsRoot: &root}},
+		events: []types.Event{
+			{Emitter: 1002, Entries: []types.EventEntry{{Key: "t1", Value: []byte("transfer")}}},
+			{Emitter: 1002, Entries: []types.EventEntry{{Key: "t1", Value: []byte("approval")}}},
+			{Emitter: 1003, Entries: []types.EventEntry{{Key: "t1", Value: []byte("transfer")}}},
+		},
+	}
+	pub := &testPublisher{failCall: 2}
+	ds := datastore.NewMapDatastore()
+
+	b := New(a, pub, ds, Options{
+		Receipts: true,
+		Queries: []Query{{
+			Name:      "transfers",
+			Addresses: []address.Address{mock.Address(1002)},
+			Keys:      map[string][][]byte{"t1": {[]byte("transfer")}},
+		}},
+	})
+
+	// the second batch fails to publish, the cursor stays at the first
+	require.Error(t, b.follow(ctx))
+	cursor, err := b.cursor(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "c1", cursor)
+
+	require.Error(t, b.follow(ctx))
+	require.Equal(t, []string{"", "c1"}, a.cursors)
+	cursor, err = b.cursor(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "c3", cursor)
+
+	var kinds []Kind
+	var typs []string
+	for _, r := range pub.records {
+		kinds = append(kinds, r.Kind)
+
+		var v struct{ Type string }
+		require.NoError(t, json.Unmarshal(r.Value, &v))
+		typs = append(typs, v.Type)
+	}
+	tipset := []Kind{KindTipSet, KindReceipt, KindEvent}
+	require.Equal(t, append(append(append(tipset, tipset...), tipset...), tipset...), kinds)
+	require.Equal(t, []string{
+		"apply", "apply", "apply",
+		"apply", "apply", "apply",
+		"revert", "revert", "revert",
+		"apply", "apply", "apply",
+	}, typs)
+
+	var ev EventRecord
+	require.NoError(t, json.Unmarshal(pub.records[5].Value, &ev))
+	require.Equal(t, "transfers", ev.Query)
+	require.Equal(t, abi.ChainEpoch(1), ev.Height)
+	require.Equal(t, m.Cid(), ev.Message)
+	require.Equal(t, 0, ev.EventIndex)
+	require.Equal(t, mock.Address(1002), ev.Emitter)
+
+	// the applied and reverted records of a tipset have distinct IDs
+	require.NotEqual(t, pub.records[3].ID, pub.records[6].ID)
+	require.NotEqual(t, pub.records[3].ID, pub.records[9].ID)
+}
+
+func TestBridgeCursorExpired(t *testing.T) {
+	ctx := context.Background()
+
+	ts1 := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	a := &testAPI{
+		subs: [][]api.HeadChangeBatch{
+			{{Changes: []*api.HeadChange{{Type: store.HCCurrent, Val: ts1}}, Cursor: "c1"}},
+		},
+		resumeErr: api.NewErrLimitExceeded("cursor_expired", false, "cursor too old"),
+	}
+	pub := &testPublisher{}
+	ds := datastore.NewMapDatastore()
+	require.NoError(t, ds.Put(ctx, cursorKey, []byte("c0")))
+
+	b := New(a, pub, ds, Options{})
+
+	// the bridge doesn't skip to the head on its own
+	err := b.follow(ctx)
+	require.ErrorIs(t, err, ErrCursorExpired)
+	require.Equal(t, []string{"c0"}, a.cursors)
+	require.Empty(t, pub.records)
+
+	b.Run(ctx)
+	require.Equal(t, []string{"c0", "c0"}, a.cursors)
+
+	// until the cursor is reset
+	require.NoError(t, ResetCursor(ctx, ds))
+	require.Error(t, b.follow(ctx))
+	require.Equal(t, []string{"c0", "c0", ""}, a.cursors)
+	require.Len(t, pub.records, 1)
+}
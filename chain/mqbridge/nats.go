@@ -0,0 +1,91 @@
+//go:build mqbridge
+
+package mqbridge
+
+import (
+	"context"
+	"strings"
+
+	"github.com/nats-io/nats.go"
+	"golang.org/x/xerrors"
+)
+
+// natsMaxPending is the number of records published before waiting for their acknowledgements.
+const natsMaxPending = 256
+
+// NATS publishes the records of each kind to the JetStream subject named after the kind, prefixed
+// with the subject prefix, waiting for the acknowledgement of the stream. The subjects must be
+// captured by a stream, records are deduplicated by their ID within the duplicates window of the
+// stream.
+type NATS struct {
+	nc     *nats.Conn
+	js     nats.JetStreamContext
+	prefix string
+}
+
+func NewNATS(servers []string, subjectPrefix string) (*NATS, error) {
+	if len(servers) == 0 {
+		return nil, xerrors.Errorf("no nats servers")
+	}
+
+	nc, err := nats.Connect(strings.Join(servers, ","), nats.Name("lotus"), nats.MaxReconnects(-1))
+	if err != nil {
+		return nil, xerrors.Errorf("connecting to nats: %w", err)
+	}
+	js, err := nc.JetStream(nats.PublishAsyncMaxPending(natsMaxPending))
+	if err != nil {
+		nc.Close()
+		return nil, xerrors.Errorf("getting the jetstream context: %w", err)
+	}
+
+	return &NATS{
+		nc:     nc,
+		js:     js,
+		prefix: subjectPrefix,
+	}, nil
+}
+
+func (n *NATS) Publish(ctx context.Context, records []Record) error {
+	for len(records) > 0 {
+		chunk := records
+		if len(chunk) > natsMaxPending {
+			chunk = chunk[:natsMaxPending]
+		}
+		records = records[len(chunk):]
+
+		futs := make([]nats.PubAckFuture, len(chunk))
+		for i, r := range chunk {
+			var err error
+			futs[i], err = n.js.PublishMsgAsync(&nats.Msg{
+				Subject: n.prefix + string(r.Kind),
+				Data:    r.Value,
+			}, nats.MsgId(r.ID))
+			if err != nil {
+				return xerrors.Errorf("publishing record %s: %w", r.ID, err)
+			}
+		}
+
+		for i, f := range futs {
+			select {
+			case <-f.Ok():
+			case err := <-f.Err():
+				return xerrors.Errorf("publishing record %s: %w", chunk[i].ID, err)
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func (n *NATS) Close() error {
+	return n.nc.Drain()
+}
+
+var _ Publisher = &NATS{}
+
+func init() {
+	backends["nats"] = func(servers []string, prefix string) (Publisher, error) {
+		return NewNATS(servers, prefix)
+	}
+}
@@ -0,0 +1,160 @@
+package msgtrace
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.7.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+var log = logging.Logger("msgtrace")
+
+const instrumentationName = "github.com/filecoin-project/lotus/chain/msgtrace"
+
+// Tracer exports the messages of sampled tipsets executed by the node as
+// OpenTelemetry traces, one trace per message with a span for each internal
+// call.
+type Tracer struct {
+	sampleRate float64
+
+	tp     *tracesdk.TracerProvider
+	tracer trace.Tracer
+}
+
+var _ stmgr.TipSetTracer = &Tracer{}
+
+func NewTracer(exp tracesdk.SpanExporter, sampleRate float64) *Tracer {
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithBatcher(exp),
+		tracesdk.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceNameKey.String("lotus"),
+		)),
+		// tipsets are sampled before execution, all their messages are traced
+		tracesdk.WithSampler(tracesdk.AlwaysSample()),
+	)
+
+	return &Tracer{
+		sampleRate: sampleRate,
+		tp:         tp,
+		tracer:     tp.Tracer(instrumentationName),
+	}
+}
+
+// Sampled samples tipsets by a hash of their key, so that nodes tracing at the
+// same rate trace the same tipsets.
+func (t *Tracer) Sampled(ts *types.TipSet) bool {
+	switch {
+	case t.sampleRate <= 0:
+		return false
+	case t.sampleRate >= 1:
+		return true
+	}
+
+	h := sha256.Sum256(ts.Key().Bytes())
+	return float64(binary.BigEndian.Uint64(h[:8])) < t.sampleRate*math.MaxUint64
+}
+
+func (t *Tracer) MessageApplied(ctx context.Context, ts *types.TipSet, mcid cid.Cid, msg *types.Message, ret *vm.ApplyRet, implicit bool) error {
+	end := time.Now()
+	start := end.Add(-ret.Duration)
+
+	mctx, span := t.tracer.Start(context.Background(), "message",
+		trace.WithTimestamp(start),
+		trace.WithAttributes(
+			attribute.String("message.cid", mcid.String()),
+			attribute.Int64("message.nonce", int64(msg.Nonce)),
+			attribute.Int64("message.gas_limit", msg.GasLimit),
+			attribute.Int64("message.gas_used", ret.GasUsed),
+			attribute.Bool("message.implicit", implicit),
+			attribute.Int64("tipset.height", int64(ts.Height())),
+			attribute.String("tipset.key", ts.Key().String()),
+		),
+		trace.WithAttributes(callAttributes(&ret.ExecutionTrace)...),
+	)
+	if ret.ActorErr != nil {
+		span.RecordError(ret.ActorErr)
+	}
+
+	t.traceSubcalls(mctx, ret.ExecutionTrace.Subcalls, start, end)
+	endSpan(span, &ret.ExecutionTrace, end)
+
+	return nil
+}
+
+// traceSubcalls lays out the subcalls one after another from the start of the
+// parent call. Only the duration of the whole message is measured, durations
+// of internal calls are derived from the time taken by their gas charges.
+func (t *Tracer) traceSubcalls(ctx context.Context, subcalls []types.ExecutionTrace, start, end time.Time) {
+	at := start
+	for i := range subcalls {
+		call := &subcalls[i]
+
+		callEnd := at.Add(callDuration(call))
+		if callEnd.After(end) {
+			callEnd = end
+		}
+
+		cctx, span := t.tracer.Start(ctx, "call",
+			trace.WithTimestamp(at),
+			trace.WithAttributes(callAttributes(call)...),
+		)
+		t.traceSubcalls(cctx, call.Subcalls, at, callEnd)
+		endSpan(span, call, callEnd)
+
+		at = callEnd
+	}
+}
+
+func callDuration(et *types.ExecutionTrace) time.Duration {
+	var d time.Duration
+	for _, gc := range et.GasCharges {
+		d += gc.TimeTaken
+	}
+	for i := range et.Subcalls {
+		d += callDuration(&et.Subcalls[i])
+	}
+	return d
+}
+
+func callAttributes(et *types.ExecutionTrace) []attribute.KeyValue {
+	gas := et.SumGas()
+	return []attribute.KeyValue{
+		attribute.String("call.from", et.Msg.From.String()),
+		attribute.String("call.to", et.Msg.To.String()),
+		attribute.Int64("call.method", int64(et.Msg.Method)),
+		attribute.String("call.value", et.Msg.Value.String()),
+		attribute.Int64("call.exit_code", int64(et.MsgRct.ExitCode)),
+		attribute.Int64("call.gas", gas.TotalGas),
+	}
+}
+
+func endSpan(span trace.Span, et *types.ExecutionTrace, end time.Time) {
+	if !et.MsgRct.ExitCode.IsSuccess() {
+		span.SetStatus(codes.Error, et.MsgRct.ExitCode.String())
+	}
+	span.End(trace.WithTimestamp(end))
+}
+
+// Shutdown exports pending spans and stops the exporter.
+func (t *Tracer) Shutdown(ctx context.Context) error {
+	if err := t.tp.Shutdown(ctx); err != nil {
+		log.Warnw("shutting down message tracing", "error", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// stm: #unit
+package msgtrace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+func TestSampled(t *testing.T) {
+	ts := mock.TipSet(mock.MkBlock(nil, 1, 1))
+
+	require.False(t, NewTracer(tracetest.NewNoopExporter(), 0).Sampled(ts))
+	require.True(t, NewTracer(tracetest.NewNoopExporter(), 1).Sampled(ts))
+
+	tr := NewTracer(tracetest.NewNoopExporter(), 0.5)
+	var sampled int
+	for i := uint64(0); i < 200; i++ {
+		ts := mock.TipSet(mock.MkBlock(nil, 1, i))
+		// the decision only depends on the tipset
+		require.Equal(t, tr.Sampled(ts), tr.Sampled(ts))
+		if tr.Sampled(ts) {
+			sampled++
+		}
+	}
+	require.Greater(t, sampled, 50)
+	require.Less(t, sampled, 150)
+}
+
+func TestMessageSpans(t *testing.T) {
+	exp := tracetest.NewInMemoryExporter()
+	tr := NewTracer(exp, 1)
+
+	ts := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	msg := mock.UnsignedMessage(mock.Address(100), mock.Address(101), 0)
+
+	call := func(to uint64, took time.Duration, code exitcode.ExitCode, subcalls ...types.ExecutionTrace) types.ExecutionTrace {
+		return types.ExecutionTrace{
+			Msg: types.MessageTrace{
+				From:   mock.Address(100),
+				To:     mock.Address(to),
+				Value:  abi.NewTokenAmount(0),
+				Method: 2,
+			},
+			MsgRct:     types.ReturnTrace{ExitCode: code},
+			GasCharges: []*types.GasTrace{{TotalGas: 10, TimeTaken: took}},
+			Subcalls:   subcalls,
+		}
+	}
+
+	ret := &vm.ApplyRet{
+		ExecutionTrace: call(101, time.Millisecond, exitcode.Ok,
+			call(102, 2*time.Millisecond, exitcode.Ok,
+				call(103, time.Millisecond, exitcode.ErrForbidden),
+			),
+			call(104, time.Millisecond, exitcode.Ok),
+		),
+		Duration: 10 * time.Millisecond,
+	}
+
+	require.NoError(t, tr.MessageApplied(context.Background(), ts, cid.Undef, msg, ret, false))
+	require.NoError(t, tr.tp.ForceFlush(context.Background()))
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 4)
+
+	byTo := map[string]tracetest.SpanStub{}
+	for _, s := range spans {
+		for _, a := range s.Attributes {
+			if a.Key == "call.to" {
+				byTo[a.Value.AsString()] = s
+			}
+		}
+	}
+	root, c102, c103, c104 := byTo[mock.Address(101).String()], byTo[mock.Address(102).String()], byTo[mock.Address(103).String()], byTo[mock.Address(104).String()]
+
+	require.Equal(t, "message", root.Name)
+	require.False(t, root.Parent.IsValid())
+	require.Equal(t, 10*time.Millisecond, root.EndTime.Sub(root.StartTime))
+
+	// all calls belong to the trace of the message
+	for _, s := range []tracetest.SpanStub{c102, c103, c104} {
+		require.Equal(t, "call", s.Name)
+		require.Equal(t, root.SpanContext.TraceID(), s.SpanContext.TraceID())
+	}
+	require.Equal(t, root.SpanContext.SpanID(), c102.Parent.SpanID())
+	require.Equal(t, root.SpanContext.SpanID(), c104.Parent.SpanID())
+	require.Equal(t, c102.SpanContext.SpanID(), c103.Parent.SpanID())
+
+	// subcalls are laid out one after another, with durations of their gas charges
+	require.Equal(t, root.StartTime, c102.StartTime)
+	require.Equal(t, 3*time.Millisecond, c102.EndTime.Sub(c102.StartTime))
+	require.Equal(t, c102.EndTime, c104.StartTime)
+
+	require.Equal(t, codes.Error, c103.Status.Code)
+	require.Equal(t, codes.Unset, c102.Status.Code)
+}
@@ -70,47 +70,55 @@ func (sr *stateRand) GetBeaconRandomnessTipset(ctx context.Context, round abi.Ch
 	return randTs, nil
 }
 
-func (sr *stateRand) getChainRandomness(ctx context.Context, pers crypto.DomainSeparationTag, round abi.ChainEpoch, entropy []byte, lookback bool) ([]byte, error) {
-	_, span := trace.StartSpan(ctx, "store.GetChainRandomness")
-	defer span.End()
-	span.AddAttributes(trace.Int64Attribute("round", int64(round)))
-
-	ts, err := sr.cs.LoadTipSet(ctx, types.NewTipSetKey(sr.blks...))
-	if err != nil {
-		return nil, err
-	}
+type NetworkVersionGetter func(context.Context, abi.ChainEpoch) network.Version
 
-	if round > ts.Height() {
-		return nil, xerrors.Errorf("cannot draw randomness from the future")
-	}
+type stateRand struct {
+	cs                   *store.ChainStore
+	blks                 []cid.Cid
+	beacon               beacon.Schedule
+	networkVersionGetter NetworkVersionGetter
+}
 
-	searchHeight := round
-	if searchHeight < 0 {
-		searchHeight = 0
+func NewStateRand(cs *store.ChainStore, blks []cid.Cid, b beacon.Schedule, networkVersionGetter NetworkVersionGetter) vm.Rand {
+	return &stateRand{
+		cs:                   cs,
+		blks:                 blks,
+		beacon:               b,
+		networkVersionGetter: networkVersionGetter,
 	}
+}
 
-	randTs, err := sr.cs.GetTipsetByHeight(ctx, searchHeight, ts, lookback)
-	if err != nil {
-		return nil, err
-	}
+// RandomnessBase is the chain data randomness for an epoch is drawn from: the ticket of the
+// smallest ticket block of a tipset for chain randomness, or a beacon entry for beacon randomness.
+type RandomnessBase struct {
+	// TipSet the ticket or beacon entry is taken from
+	TipSet *types.TipSet
 
-	mtb := randTs.MinTicketBlock()
+	// set for chain randomness
+	Block  cid.Cid
+	Ticket *types.Ticket
 
-	// if at (or just past -- for null epochs) appropriate epoch
-	// or at genesis (works for negative epochs)
-	return DrawRandomness(mtb.Ticket.VRFProof, pers, round, entropy)
+	// set for beacon randomness
+	BeaconEntry *types.BeaconEntry
 }
 
-type NetworkVersionGetter func(context.Context, abi.ChainEpoch) network.Version
+// Data returns the bytes randomness is drawn from.
+func (rb *RandomnessBase) Data() []byte {
+	if rb.BeaconEntry != nil {
+		return rb.BeaconEntry.Data
+	}
+	return rb.Ticket.VRFProof
+}
 
-type stateRand struct {
-	cs                   *store.ChainStore
-	blks                 []cid.Cid
-	beacon               beacon.Schedule
-	networkVersionGetter NetworkVersionGetter
+// RandomnessBaseGetter returns the chain data randomness is drawn from.
+type RandomnessBaseGetter interface {
+	GetChainRandomnessBase(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*RandomnessBase, error)
+	GetBeaconRandomnessBase(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*RandomnessBase, error)
 }
 
-func NewStateRand(cs *store.ChainStore, blks []cid.Cid, b beacon.Schedule, networkVersionGetter NetworkVersionGetter) vm.Rand {
+// NewStateRandBaseGetter returns the getter of the data randomness is drawn from, when sampled at
+// the given tipset.
+func NewStateRandBaseGetter(cs *store.ChainStore, blks []cid.Cid, b beacon.Schedule, networkVersionGetter NetworkVersionGetter) RandomnessBaseGetter {
 	return &stateRand{
 		cs:                   cs,
 		blks:                 blks,
@@ -120,7 +128,7 @@ func NewStateRand(cs *store.ChainStore, blks []cid.Cid, b beacon.Schedule, netwo
 }
 
 // network v0-12
-func (sr *stateRand) getBeaconRandomnessV1(ctx context.Context, pers crypto.DomainSeparationTag, round abi.ChainEpoch, entropy []byte) ([]byte, error) {
+func (sr *stateRand) getBeaconRandomnessBaseV1(ctx context.Context, round abi.ChainEpoch) (*RandomnessBase, error) {
 	randTs, err := sr.GetBeaconRandomnessTipset(ctx, round, true)
 	if err != nil {
 		return nil, err
@@ -133,11 +141,11 @@ func (sr *stateRand) getBeaconRandomnessV1(ctx context.Context, pers crypto.Doma
 
 	// if at (or just past -- for null epochs) appropriate epoch
 	// or at genesis (works for negative epochs)
-	return DrawRandomness(be.Data, pers, round, entropy)
+	return &RandomnessBase{TipSet: randTs, BeaconEntry: be}, nil
 }
 
 // network v13
-func (sr *stateRand) getBeaconRandomnessV2(ctx context.Context, pers crypto.DomainSeparationTag, round abi.ChainEpoch, entropy []byte) ([]byte, error) {
+func (sr *stateRand) getBeaconRandomnessBaseV2(ctx context.Context, round abi.ChainEpoch) (*RandomnessBase, error) {
 	randTs, err := sr.GetBeaconRandomnessTipset(ctx, round, false)
 	if err != nil {
 		return nil, err
@@ -150,52 +158,74 @@ func (sr *stateRand) getBeaconRandomnessV2(ctx context.Context, pers crypto.Doma
 
 	// if at (or just past -- for null epochs) appropriate epoch
 	// or at genesis (works for negative epochs)
-	return DrawRandomness(be.Data, pers, round, entropy)
+	return &RandomnessBase{TipSet: randTs, BeaconEntry: be}, nil
 }
 
 // network v14 and on
-func (sr *stateRand) getBeaconRandomnessV3(ctx context.Context, pers crypto.DomainSeparationTag, filecoinEpoch abi.ChainEpoch, entropy []byte) ([]byte, error) {
+func (sr *stateRand) getBeaconRandomnessBaseV3(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*RandomnessBase, error) {
 	if filecoinEpoch < 0 {
-		return sr.getBeaconRandomnessV2(ctx, pers, filecoinEpoch, entropy)
+		return sr.getBeaconRandomnessBaseV2(ctx, filecoinEpoch)
 	}
 
-	be, err := sr.extractBeaconEntryForEpoch(ctx, filecoinEpoch)
+	be, ts, err := sr.extractBeaconEntryForEpoch(ctx, filecoinEpoch)
 	if err != nil {
 		log.Errorf("failed to get beacon entry as expected: %s", err)
 		return nil, err
 	}
 
-	return DrawRandomness(be.Data, pers, filecoinEpoch, entropy)
+	return &RandomnessBase{TipSet: ts, BeaconEntry: be}, nil
 }
 
-func (sr *stateRand) GetChainRandomness(ctx context.Context, pers crypto.DomainSeparationTag, filecoinEpoch abi.ChainEpoch, entropy []byte) ([]byte, error) {
+func (sr *stateRand) GetChainRandomnessBase(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*RandomnessBase, error) {
 	nv := sr.networkVersionGetter(ctx, filecoinEpoch)
 
-	if nv >= network.Version13 {
-		return sr.getChainRandomness(ctx, pers, filecoinEpoch, entropy, false)
+	randTs, err := sr.GetBeaconRandomnessTipset(ctx, filecoinEpoch, nv < network.Version13)
+	if err != nil {
+		return nil, err
 	}
 
-	return sr.getChainRandomness(ctx, pers, filecoinEpoch, entropy, true)
+	// if at (or just past -- for null epochs) appropriate epoch
+	// or at genesis (works for negative epochs)
+	mtb := randTs.MinTicketBlock()
+	return &RandomnessBase{TipSet: randTs, Block: mtb.Cid(), Ticket: mtb.Ticket}, nil
 }
 
-func (sr *stateRand) GetBeaconRandomness(ctx context.Context, pers crypto.DomainSeparationTag, filecoinEpoch abi.ChainEpoch, entropy []byte) ([]byte, error) {
+func (sr *stateRand) GetBeaconRandomnessBase(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*RandomnessBase, error) {
 	nv := sr.networkVersionGetter(ctx, filecoinEpoch)
 
 	if nv >= network.Version14 {
-		return sr.getBeaconRandomnessV3(ctx, pers, filecoinEpoch, entropy)
+		return sr.getBeaconRandomnessBaseV3(ctx, filecoinEpoch)
 	} else if nv == network.Version13 {
-		return sr.getBeaconRandomnessV2(ctx, pers, filecoinEpoch, entropy)
+		return sr.getBeaconRandomnessBaseV2(ctx, filecoinEpoch)
 	} else {
-		return sr.getBeaconRandomnessV1(ctx, pers, filecoinEpoch, entropy)
+		return sr.getBeaconRandomnessBaseV1(ctx, filecoinEpoch)
 	}
 }
 
-func (sr *stateRand) extractBeaconEntryForEpoch(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*types.BeaconEntry, error) {
-	randTs, err := sr.GetBeaconRandomnessTipset(ctx, filecoinEpoch, false)
+func (sr *stateRand) GetChainRandomness(ctx context.Context, pers crypto.DomainSeparationTag, filecoinEpoch abi.ChainEpoch, entropy []byte) ([]byte, error) {
+	rb, err := sr.GetChainRandomnessBase(ctx, filecoinEpoch)
+	if err != nil {
+		return nil, err
+	}
+
+	return DrawRandomness(rb.Data(), pers, filecoinEpoch, entropy)
+}
+
+func (sr *stateRand) GetBeaconRandomness(ctx context.Context, pers crypto.DomainSeparationTag, filecoinEpoch abi.ChainEpoch, entropy []byte) ([]byte, error) {
+	rb, err := sr.GetBeaconRandomnessBase(ctx, filecoinEpoch)
 	if err != nil {
 		return nil, err
 	}
 
+	return DrawRandomness(rb.Data(), pers, filecoinEpoch, entropy)
+}
+
+func (sr *stateRand) extractBeaconEntryForEpoch(ctx context.Context, filecoinEpoch abi.ChainEpoch) (*types.BeaconEntry, *types.TipSet, error) {
+	randTs, err := sr.GetBeaconRandomnessTipset(ctx, filecoinEpoch, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	nv := sr.networkVersionGetter(ctx, filecoinEpoch)
 
 	round := sr.beacon.BeaconForEpoch(filecoinEpoch).MaxBeaconRoundForEpoch(nv, filecoinEpoch)
@@ -204,17 +234,17 @@ func (sr *stateRand) extractBeaconEntryForEpoch(ctx context.Context, filecoinEpo
 		cbe := randTs.Blocks()[0].BeaconEntries
 		for _, v := range cbe {
 			if v.Round == round {
-				return &v, nil
+				return &v, randTs, nil
 			}
 		}
 
 		next, err := sr.cs.LoadTipSet(ctx, randTs.Parents())
 		if err != nil {
-			return nil, xerrors.Errorf("failed to load parents when searching back for beacon entry: %w", err)
+			return nil, nil, xerrors.Errorf("failed to load parents when searching back for beacon entry: %w", err)
 		}
 
 		randTs = next
 	}
 
-	return nil, xerrors.Errorf("didn't find beacon for round %d (epoch %d)", round, filecoinEpoch)
+	return nil, nil, xerrors.Errorf("didn't find beacon for round %d (epoch %d)", round, filecoinEpoch)
 }
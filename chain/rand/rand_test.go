@@ -243,3 +243,48 @@ func TestNullRandomnessV3(t *testing.T) {
 		t.Fatal("timed out")
 	}
 }
+
+func TestRandomnessBase(t *testing.T) {
+	ctx := context.Background()
+	cg, err := gen.NewGenerator()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		_, err := cg.NextTipSet()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ts := cg.CurTipset.TipSet()
+	entropy := []byte{0, 2, 3, 4}
+	pers := crypto.DomainSeparationTag_WinningPoStChallengeSeed
+	randEpoch := ts.Height() - 2
+
+	sm := cg.StateManager()
+
+	rb, err := sm.GetRandomnessBase(ctx, false, randEpoch, ts.Key())
+	require.NoError(t, err)
+	require.Equal(t, randEpoch, rb.TipSet.Height())
+	require.Equal(t, rb.TipSet.MinTicketBlock().Cid(), rb.Block)
+	require.Equal(t, rb.TipSet.MinTicket(), rb.Ticket)
+
+	expected, err := sm.GetRandomnessFromTickets(ctx, pers, randEpoch, entropy, ts.Key())
+	require.NoError(t, err)
+	drawn, err := rand.DrawRandomness(rb.Data(), pers, randEpoch, entropy)
+	require.NoError(t, err)
+	require.Equal(t, expected, abi.Randomness(drawn))
+
+	rb, err = sm.GetRandomnessBase(ctx, true, randEpoch, ts.Key())
+	require.NoError(t, err)
+	require.NotNil(t, rb.BeaconEntry)
+	require.Contains(t, rb.TipSet.Blocks()[0].BeaconEntries, *rb.BeaconEntry)
+
+	expected, err = sm.GetRandomnessFromBeacon(ctx, pers, randEpoch, entropy, ts.Key())
+	require.NoError(t, err)
+	drawn, err = rand.DrawRandomness(rb.Data(), pers, randEpoch, entropy)
+	require.NoError(t, err)
+	require.Equal(t, expected, abi.Randomness(drawn))
+}
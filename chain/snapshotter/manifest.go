@@ -0,0 +1,199 @@
+package snapshotter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/sigs"
+	_ "github.com/filecoin-project/lotus/lib/sigs/bls"
+	_ "github.com/filecoin-project/lotus/lib/sigs/secp"
+)
+
+// ManifestSuffix is appended to the name of a snapshot to get the name of its
+// manifest.
+const ManifestSuffix = ".manifest.json"
+
+// ManifestVersion is the version of the manifests written by this node.
+const ManifestVersion = 1
+
+// Manifest describes a snapshot file, so that nodes importing it can check it
+// wasn't altered, and, when it's signed, that it comes from a publisher they
+// trust.
+type Manifest struct {
+	Version int
+	// Network is the name of the network of the snapshot
+	Network string
+	// Head is the tipset the snapshot was exported from
+	Head   types.TipSetKey
+	Height abi.ChainEpoch
+	// Size and SHA256 are the size and hex-encoded sha256 checksum of the
+	// snapshot file, compressed or not
+	Size   int64
+	SHA256 string
+
+	// Publisher is the key address which signed the manifest
+	Publisher address.Address
+	// Signature of the manifest without the signature by the publisher
+	Signature *crypto.Signature `json:",omitempty"`
+}
+
+// SigningBytes returns the bytes of the manifest signed by the publisher.
+func (m *Manifest) SigningBytes() ([]byte, error) {
+	unsigned := *m
+	unsigned.Signature = nil
+	return json.Marshal(&unsigned)
+}
+
+// Signer signs bytes with the key of an address.
+type Signer func(ctx context.Context, addr address.Address, msg []byte) (*crypto.Signature, error)
+
+// Sign signs the manifest with the key of the publisher.
+func (m *Manifest) Sign(ctx context.Context, publisher address.Address, sign Signer) error {
+	m.Publisher = publisher
+	b, err := m.SigningBytes()
+	if err != nil {
+		return err
+	}
+	m.Signature, err = sign(ctx, publisher, b)
+	if err != nil {
+		return xerrors.Errorf("signing manifest: %w", err)
+	}
+	return nil
+}
+
+// Signed reports whether the manifest carries a signature.
+func (m *Manifest) Signed() bool {
+	return m.Signature != nil
+}
+
+// VerifySignature checks the manifest is signed by one of the trusted
+// publishers.
+func (m *Manifest) VerifySignature(trusted []address.Address) error {
+	if !m.Signed() {
+		return xerrors.Errorf("manifest is not signed")
+	}
+
+	found := false
+	for _, a := range trusted {
+		if a == m.Publisher {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return xerrors.Errorf("manifest publisher %s is not trusted", m.Publisher)
+	}
+
+	b, err := m.SigningBytes()
+	if err != nil {
+		return err
+	}
+	if err := sigs.Verify(m.Signature, m.Publisher, b); err != nil {
+		return xerrors.Errorf("invalid manifest signature: %w", err)
+	}
+	return nil
+}
+
+// VerifySnapshot checks a snapshot matches the manifest, given the size and
+// checksum of the snapshot file and the tipset it was imported at.
+func (m *Manifest) VerifySnapshot(size int64, sha256 string, head types.TipSetKey) error {
+	if err := m.VerifyFile(size, sha256); err != nil {
+		return err
+	}
+	if head != m.Head {
+		return xerrors.Errorf("snapshot head is %s, manifest says %s", head, m.Head)
+	}
+	return nil
+}
+
+// VerifyFile checks the size and checksum of a snapshot file match the
+// manifest, so it can be checked before being imported.
+func (m *Manifest) VerifyFile(size int64, sha256 string) error {
+	if size != m.Size {
+		return xerrors.Errorf("snapshot is %d bytes, manifest says %d", size, m.Size)
+	}
+	if !strings.EqualFold(sha256, m.SHA256) {
+		return xerrors.Errorf("snapshot sha256 is %s, manifest says %s", sha256, m.SHA256)
+	}
+	return nil
+}
+
+// ParseTrustedPublishers parses the addresses of trusted snapshot publishers,
+// which must be key addresses.
+func ParseTrustedPublishers(publishers ...[]string) ([]address.Address, error) {
+	var out []address.Address
+	for _, list := range publishers {
+		for _, s := range list {
+			a, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing snapshot publisher %q: %w", s, err)
+			}
+			if a.Protocol() != address.BLS && a.Protocol() != address.SECP256K1 {
+				return nil, xerrors.Errorf("snapshot publisher %s is not a key address", a)
+			}
+			out = append(out, a)
+		}
+	}
+	return out, nil
+}
+
+// ReadManifest reads the manifest at a path or http(s) URL. It returns
+// os.ErrNotExist when there is no manifest.
+func ReadManifest(ctx context.Context, loc string) (*Manifest, error) {
+	var rd io.ReadCloser
+	if strings.HasPrefix(loc, "http://") || strings.HasPrefix(loc, "https://") {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, loc, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, xerrors.Errorf("fetching manifest: %w", err)
+		}
+		if resp.StatusCode == http.StatusNotFound {
+			_ = resp.Body.Close()
+			return nil, os.ErrNotExist
+		}
+		if resp.StatusCode != http.StatusOK {
+			_ = resp.Body.Close()
+			return nil, xerrors.Errorf("fetching manifest: %s", resp.Status)
+		}
+		rd = resp.Body
+	} else {
+		f, err := os.Open(loc)
+		if err != nil {
+			return nil, err
+		}
+		rd = f
+	}
+	defer rd.Close() //nolint:errcheck
+
+	var m Manifest
+	if err := json.NewDecoder(io.LimitReader(rd, 1<<20)).Decode(&m); err != nil {
+		return nil, xerrors.Errorf("decoding manifest: %w", err)
+	}
+	if m.Version != ManifestVersion {
+		return nil, xerrors.Errorf("unsupported manifest version %d", m.Version)
+	}
+	return &m, nil
+}
+
+// WriteManifest writes a manifest to a file.
+func WriteManifest(path string, m *Manifest) error {
+	b, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0644)
+}
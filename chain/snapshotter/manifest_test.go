@@ -0,0 +1,76 @@
+// stm: #unit
+package snapshotter
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/sigs"
+)
+
+func TestManifestSignature(t *testing.T) {
+	ctx := context.Background()
+
+	pk, err := sigs.Generate(crypto.SigTypeSecp256k1)
+	require.NoError(t, err)
+	pub, err := sigs.ToPublic(crypto.SigTypeSecp256k1, pk)
+	require.NoError(t, err)
+	publisher, err := address.NewSecp256k1Address(pub)
+	require.NoError(t, err)
+
+	signer := func(ctx context.Context, addr address.Address, msg []byte) (*crypto.Signature, error) {
+		require.Equal(t, publisher, addr)
+		return sigs.Sign(crypto.SigTypeSecp256k1, pk, msg)
+	}
+
+	head := types.NewTipSetKey(cid.MustParse("bafy2bzacecnamqgqmifpluoeldx7zzglxcljo6oja4vrmtj7432rphldpdmm2"))
+	m := &Manifest{
+		Version: ManifestVersion,
+		Network: "testnetnet",
+		Head:    head,
+		Height:  100,
+		Size:    1234,
+		SHA256:  "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+	}
+	require.Error(t, m.VerifySignature([]address.Address{publisher}))
+
+	require.NoError(t, m.Sign(ctx, publisher, signer))
+
+	// the signature survives writing and reading the manifest
+	path := filepath.Join(t.TempDir(), "snapshot.car"+ManifestSuffix)
+	require.NoError(t, WriteManifest(path, m))
+	m, err = ReadManifest(ctx, path)
+	require.NoError(t, err)
+
+	require.NoError(t, m.VerifySignature([]address.Address{publisher}))
+	require.NoError(t, m.VerifySnapshot(1234, "E3B0C44298FC1C149AFBF4C8996FB92427AE41E4649B934CA495991B7852B855", head))
+
+	require.Error(t, m.VerifySignature(nil), "untrusted publisher")
+	require.Error(t, m.VerifySnapshot(1235, m.SHA256, head), "size mismatch")
+	require.Error(t, m.VerifySnapshot(1234, m.SHA256, types.EmptyTSK), "head mismatch")
+	require.NoError(t, m.VerifyFile(1234, m.SHA256))
+	require.Error(t, m.VerifyFile(1234, "00"), "checksum mismatch")
+
+	m.Size++
+	require.Error(t, m.VerifySignature([]address.Address{publisher}), "tampered manifest")
+}
+
+func TestParseTrustedPublishers(t *testing.T) {
+	addrs, err := ParseTrustedPublishers(
+		[]string{"f1abjxfbp274xpdqcpuaykwkfb43omjotacm2p3za"},
+		[]string{"f3vvmn62lofvhjd2ugzca6sof2j2ubwok6cj4xxbfzz4yuxfkgobpihhd2thlanmsh3w2ptld2gqkn2jvlss4a"},
+	)
+	require.NoError(t, err)
+	require.Len(t, addrs, 2)
+
+	_, err = ParseTrustedPublishers([]string{"f01000"})
+	require.Error(t, err)
+}
@@ -0,0 +1,304 @@
+package snapshotter
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// unsignedPayload is used as the payload hash of large uploads, so that parts
+// don't need to be read twice.
+const unsignedPayload = "UNSIGNED-PAYLOAD"
+
+// partSize is the size of the parts of multipart uploads. With the S3 limit of
+// 10000 parts, this allows uploading objects of up to ~1TiB.
+const partSize = 100 << 20
+
+// s3Client is a minimal client for S3-compatible object storage (AWS S3, GCS
+// with HMAC keys, MinIO, ...), supporting just what the snapshotter needs.
+type s3Client struct {
+	endpoint  *url.URL
+	region    string
+	bucket    string
+	accessKey string
+	secretKey string
+
+	http *http.Client
+}
+
+type s3Object struct {
+	Key          string
+	LastModified time.Time
+	Size         int64
+}
+
+func newS3Client(endpoint, region, bucket, accessKey, secretKey string) (*s3Client, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing endpoint: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, xerrors.Errorf("unsupported endpoint scheme %q", u.Scheme)
+	}
+	if bucket == "" {
+		return nil, xerrors.Errorf("bucket not set")
+	}
+
+	return &s3Client{
+		endpoint:  u,
+		region:    region,
+		bucket:    bucket,
+		accessKey: accessKey,
+		secretKey: secretKey,
+		http:      &http.Client{},
+	}, nil
+}
+
+// Upload uploads the file at path to key using a multipart upload.
+func (c *s3Client) Upload(ctx context.Context, key string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	st, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	var initRes struct {
+		UploadID string `xml:"UploadId"`
+	}
+	if err := c.do(ctx, http.MethodPost, key, url.Values{"uploads": {""}}, nil, &initRes); err != nil {
+		return xerrors.Errorf("initiating multipart upload: %w", err)
+	}
+
+	type part struct {
+		PartNumber int
+		ETag       string
+	}
+	var parts []part
+
+	err = func() error {
+		for off, n := int64(0), 1; off < st.Size() || n == 1; off, n = off+partSize, n+1 {
+			size := st.Size() - off
+			if size > partSize {
+				size = partSize
+			}
+
+			q := url.Values{
+				"partNumber": {strconv.Itoa(n)},
+				"uploadId":   {initRes.UploadID},
+			}
+			req, err := c.newRequest(ctx, http.MethodPut, key, q, io.NewSectionReader(f, off, size), unsignedPayload)
+			if err != nil {
+				return err
+			}
+			req.ContentLength = size
+
+			resp, err := c.send(req, nil)
+			if err != nil {
+				return xerrors.Errorf("uploading part %d: %w", n, err)
+			}
+			parts = append(parts, part{PartNumber: n, ETag: resp.Header.Get("ETag")})
+		}
+		return nil
+	}()
+	if err != nil {
+		if aerr := c.do(ctx, http.MethodDelete, key, url.Values{"uploadId": {initRes.UploadID}}, nil, nil); aerr != nil {
+			log.Warnw("aborting multipart upload failed", "key", key, "error", aerr)
+		}
+		return err
+	}
+
+	body, err := xml.Marshal(struct {
+		XMLName xml.Name `xml:"CompleteMultipartUpload"`
+		Parts   []part   `xml:"Part"`
+	}{Parts: parts})
+	if err != nil {
+		return err
+	}
+
+	if err := c.do(ctx, http.MethodPost, key, url.Values{"uploadId": {initRes.UploadID}}, body, nil); err != nil {
+		return xerrors.Errorf("completing multipart upload: %w", err)
+	}
+	return nil
+}
+
+// Put uploads a small object in a single request.
+func (c *s3Client) Put(ctx context.Context, key string, data []byte) error {
+	return c.do(ctx, http.MethodPut, key, nil, data, nil)
+}
+
+func (c *s3Client) Delete(ctx context.Context, key string) error {
+	return c.do(ctx, http.MethodDelete, key, nil, nil, nil)
+}
+
+// List returns all objects with the given key prefix.
+func (c *s3Client) List(ctx context.Context, prefix string) ([]s3Object, error) {
+	var out []s3Object
+	var token string
+	for {
+		q := url.Values{
+			"list-type": {"2"},
+			"prefix":    {prefix},
+		}
+		if token != "" {
+			q.Set("continuation-token", token)
+		}
+
+		var res struct {
+			Contents              []s3Object
+			IsTruncated           bool
+			NextContinuationToken string
+		}
+		if err := c.do(ctx, http.MethodGet, "", q, nil, &res); err != nil {
+			return nil, xerrors.Errorf("listing objects: %w", err)
+		}
+
+		out = append(out, res.Contents...)
+		if !res.IsTruncated || res.NextContinuationToken == "" {
+			return out, nil
+		}
+		token = res.NextContinuationToken
+	}
+}
+
+// do sends a request with an in-memory body, and decodes the XML response
+// into out, if set.
+func (c *s3Client) do(ctx context.Context, method, key string, q url.Values, body []byte, out interface{}) error {
+	h := sha256.Sum256(body)
+	req, err := c.newRequest(ctx, method, key, q, bytes.NewReader(body), hex.EncodeToString(h[:]))
+	if err != nil {
+		return err
+	}
+	req.ContentLength = int64(len(body))
+
+	_, err = c.send(req, out)
+	return err
+}
+
+func (c *s3Client) send(req *http.Request, out interface{}) (*http.Response, error) {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, xerrors.Errorf("%s %s: %s: %s", req.Method, req.URL.Path, resp.Status, string(msg))
+	}
+
+	if out != nil {
+		if err := xml.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, xerrors.Errorf("decoding response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+func (c *s3Client) newRequest(ctx context.Context, method, key string, q url.Values, body io.Reader, payloadHash string) (*http.Request, error) {
+	u := *c.endpoint
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + c.bucket
+	if key != "" {
+		u.Path += "/" + key
+	}
+	u.RawPath = uriEscape(u.Path, false)
+	u.RawQuery = canonicalQuery(q)
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	c.sign(req, payloadHash, time.Now().UTC())
+	return req, nil
+}
+
+// sign signs the request using AWS Signature Version 4.
+func (c *s3Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+
+	const signedHeaders = "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := fmt.Sprintf("host:%s\nx-amz-content-sha256:%s\nx-amz-date:%s\n", req.URL.Host, payloadHash, amzDate)
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := fmt.Sprintf("%s/%s/s3/aws4_request", date, c.region)
+	crh := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, hex.EncodeToString(crh[:])}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+c.secretKey), date)
+	key = hmacSHA256(key, c.region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s", c.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+	return h.Sum(nil)
+}
+
+func canonicalQuery(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range q[k] {
+			parts = append(parts, uriEscape(k, true)+"="+uriEscape(v, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEscape escapes s as required by SigV4: everything except unreserved
+// characters is percent-encoded, and '/' only if encodeSlash is set.
+func uriEscape(s string, encodeSlash bool) string {
+	var sb strings.Builder
+	for _, b := range []byte(s) {
+		switch {
+		case 'A' <= b && b <= 'Z', 'a' <= b && b <= 'z', '0' <= b && b <= '9', b == '-', b == '_', b == '.', b == '~':
+			sb.WriteByte(b)
+		case b == '/' && !encodeSlash:
+			sb.WriteByte(b)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", b)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,399 @@
+// Package snapshotter periodically exports chain snapshots, compresses them
+// with zstd and uploads them, along with their checksum and manifest, to
+// S3-compatible object storage.
+package snapshotter
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/DataDog/zstd"
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/robfig/cron/v3"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+var log = logging.Logger("snapshotter")
+
+const (
+	snapshotPrefix = "snapshot_"
+	snapshotSuffix = ".car.zst"
+	checksumSuffix = ".sha256"
+)
+
+// SnapshotEvent is recorded in the journal after each snapshot attempt.
+type SnapshotEvent struct {
+	Key      string
+	Height   abi.ChainEpoch
+	Size     int64
+	Sha256   string
+	Duration time.Duration
+	Error    string `json:",omitempty"`
+	// RetentionError is the error deleting old snapshots after the upload
+	RetentionError string `json:",omitempty"`
+}
+
+// Gate defers snapshots to the times it allows, such as maintenance windows.
+type Gate interface {
+	// Wait blocks until a snapshot can be taken.
+	Wait(ctx context.Context) error
+	// Track is called when a snapshot starts, and returns the function called
+	// with its result.
+	Track(name string) func(error)
+}
+
+type Snapshotter struct {
+	cfg      config.SnapshotsConfig
+	cs       *store.ChainStore
+	s3       *s3Client
+	schedule cron.Schedule
+	staging  string
+	network  string
+
+	signingKey address.Address
+	sign       Signer
+
+	journal        journal.Journal
+	evtType        journal.EventType
+	alerting       *alerting.Alerting
+	alert          alerting.AlertType
+	retentionAlert alerting.AlertType
+
+	gate Gate
+
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+func NewSnapshotter(cfg config.SnapshotsConfig, cs *store.ChainStore, staging string, network string, sign Signer, j journal.Journal, al *alerting.Alerting) (*Snapshotter, error) {
+	signingKey := address.Undef
+	if cfg.SigningKey != "" {
+		var err error
+		signingKey, err = address.NewFromString(cfg.SigningKey)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing signing key %q: %w", cfg.SigningKey, err)
+		}
+	}
+
+	schedule, err := cron.ParseStandard(cfg.Schedule)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing schedule %q: %w", cfg.Schedule, err)
+	}
+
+	s3, err := newS3Client(cfg.Endpoint, cfg.Region, cfg.Bucket, cfg.AccessKey, cfg.SecretKey)
+	if err != nil {
+		return nil, xerrors.Errorf("creating object storage client: %w", err)
+	}
+
+	if cfg.StagingPath != "" {
+		staging = cfg.StagingPath
+	}
+	if err := os.MkdirAll(staging, 0755); err != nil {
+		return nil, xerrors.Errorf("creating staging directory: %w", err)
+	}
+
+	if j == nil {
+		j = journal.NilJournal()
+	}
+
+	s := &Snapshotter{
+		cfg:      cfg,
+		cs:       cs,
+		s3:       s3,
+		schedule: schedule,
+		staging:  staging,
+		network:  network,
+		journal:  j,
+
+		signingKey: signingKey,
+		sign:       sign,
+
+		evtType:  j.RegisterEventType("snapshotter", "snapshot"),
+		alerting: al,
+		stopped:  make(chan struct{}),
+	}
+	if al != nil {
+		s.alert = al.AddAlertType("snapshotter", "snapshot-failed")
+		s.retentionAlert = al.AddAlertType("snapshotter", "retention-failed")
+	}
+
+	return s, nil
+}
+
+// SetGate defers snapshots to the times allowed by the gate. It must be called
+// before Start.
+func (s *Snapshotter) SetGate(g Gate) {
+	s.gate = g
+}
+
+func (s *Snapshotter) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+}
+
+func (s *Snapshotter) Stop(ctx context.Context) error {
+	s.cancel()
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Snapshotter) run(ctx context.Context) {
+	defer close(s.stopped)
+
+	for {
+		next := s.schedule.Next(build.Clock.Now())
+		log.Infow("next snapshot scheduled", "at", next)
+
+		timer := build.Clock.Timer(build.Clock.Until(next))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		}
+
+		var done func(error)
+		if s.gate != nil {
+			if err := s.gate.Wait(ctx); err != nil {
+				return
+			}
+			done = s.gate.Track("snapshot")
+		}
+
+		evt, err := s.Snapshot(ctx)
+		if done != nil {
+			done(err)
+		}
+
+		var retentionErr error
+		if err == nil {
+			retentionErr = s.applyRetention(ctx)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		s.report(evt, err, retentionErr)
+	}
+}
+
+func (s *Snapshotter) report(evt SnapshotEvent, err, retentionErr error) {
+	if err != nil {
+		evt.Error = err.Error()
+		log.Errorw("snapshot failed", "error", err)
+
+		if s.alerting != nil {
+			s.alerting.Raise(s.alert, map[string]interface{}{
+				"message": "chain snapshot failed",
+				"height":  evt.Height,
+				"error":   err.Error(),
+			})
+		}
+	} else {
+		log.Infow("snapshot uploaded", "key", evt.Key, "height", evt.Height, "size", evt.Size, "took", evt.Duration)
+
+		if s.alerting != nil && s.alerting.IsRaised(s.alert) {
+			s.alerting.Resolve(s.alert, map[string]string{
+				"message": "chain snapshot succeeded",
+			})
+		}
+	}
+
+	if retentionErr != nil {
+		evt.RetentionError = retentionErr.Error()
+		log.Errorw("removing old snapshots failed", "error", retentionErr)
+
+		if s.alerting != nil {
+			s.alerting.Raise(s.retentionAlert, map[string]interface{}{
+				"message": "removing old chain snapshots failed",
+				"error":   retentionErr.Error(),
+			})
+		}
+	} else if err == nil && s.alerting != nil && s.alerting.IsRaised(s.retentionAlert) {
+		s.alerting.Resolve(s.retentionAlert, map[string]string{
+			"message": "removing old chain snapshots succeeded",
+		})
+	}
+
+	s.journal.RecordEvent(s.evtType, func() interface{} {
+		return evt
+	})
+}
+
+// Snapshot exports, compresses and uploads a single snapshot. The retention
+// policy is applied separately, once the snapshot is uploaded.
+func (s *Snapshotter) Snapshot(ctx context.Context) (SnapshotEvent, error) {
+	start := build.Clock.Now()
+
+	ts, err := s.snapshotTipSet(ctx)
+	if err != nil {
+		return SnapshotEvent{}, err
+	}
+
+	name := fmt.Sprintf("%s%d_%d%s", snapshotPrefix, ts.Height(), start.Unix(), snapshotSuffix)
+	evt := SnapshotEvent{
+		Key:    s.cfg.Prefix + name,
+		Height: ts.Height(),
+	}
+
+	path := filepath.Join(s.staging, name)
+	defer func() {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Warnw("removing staged snapshot", "path", path, "error", err)
+		}
+	}()
+
+	log.Infow("exporting snapshot", "height", ts.Height(), "path", path)
+	evt.Size, evt.Sha256, err = s.export(ctx, ts, path)
+	if err != nil {
+		return evt, xerrors.Errorf("exporting snapshot: %w", err)
+	}
+
+	log.Infow("uploading snapshot", "key", evt.Key, "size", evt.Size)
+	if err := s.s3.Upload(ctx, evt.Key, path); err != nil {
+		return evt, xerrors.Errorf("uploading snapshot: %w", err)
+	}
+
+	// same format as sha256sum, so the snapshot can be checked with `sha256sum -c`
+	sum := fmt.Sprintf("%s  %s\n", evt.Sha256, name)
+	if err := s.s3.Put(ctx, evt.Key+checksumSuffix, []byte(sum)); err != nil {
+		return evt, xerrors.Errorf("uploading checksum: %w", err)
+	}
+
+	m := &Manifest{
+		Version: ManifestVersion,
+		Network: s.network,
+		Head:    ts.Key(),
+		Height:  ts.Height(),
+		Size:    evt.Size,
+		SHA256:  evt.Sha256,
+	}
+	if s.signingKey != address.Undef {
+		if err := m.Sign(ctx, s.signingKey, s.sign); err != nil {
+			return evt, err
+		}
+	}
+	mb, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return evt, err
+	}
+	if err := s.s3.Put(ctx, evt.Key+ManifestSuffix, mb); err != nil {
+		return evt, xerrors.Errorf("uploading manifest: %w", err)
+	}
+
+	evt.Duration = build.Clock.Since(start)
+	return evt, nil
+}
+
+func (s *Snapshotter) snapshotTipSet(ctx context.Context) (*types.TipSet, error) {
+	head := s.cs.GetHeaviestTipSet()
+	if head == nil {
+		return nil, xerrors.Errorf("no chain head")
+	}
+
+	h := head.Height() - abi.ChainEpoch(s.cfg.Confidence)
+	if h < 0 {
+		h = 0
+	}
+
+	return s.cs.GetTipsetByHeight(ctx, h, head, true)
+}
+
+func (s *Snapshotter) export(ctx context.Context, ts *types.TipSet, path string) (int64, string, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := sha256.New()
+	cw := &countingWriter{w: io.MultiWriter(f, h)}
+	zw := zstd.NewWriter(cw)
+
+	if err := s.cs.Export(ctx, ts, abi.ChainEpoch(s.cfg.RecentStateRoots), true, zw); err != nil {
+		_ = zw.Close()
+		return 0, "", err
+	}
+	if err := zw.Close(); err != nil {
+		return 0, "", xerrors.Errorf("closing zstd writer: %w", err)
+	}
+	if err := f.Close(); err != nil {
+		return 0, "", err
+	}
+
+	return cw.n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// applyRetention deletes all but the most recent cfg.Retention snapshots.
+func (s *Snapshotter) applyRetention(ctx context.Context) error {
+	if s.cfg.Retention <= 0 {
+		return nil
+	}
+
+	objs, err := s.s3.List(ctx, s.cfg.Prefix+snapshotPrefix)
+	if err != nil {
+		return err
+	}
+
+	var snaps []s3Object
+	for _, o := range objs {
+		if strings.HasSuffix(o.Key, snapshotSuffix) {
+			snaps = append(snaps, o)
+		}
+	}
+	if len(snaps) <= s.cfg.Retention {
+		return nil
+	}
+
+	sort.Slice(snaps, func(i, j int) bool {
+		return snaps[i].LastModified.After(snaps[j].LastModified)
+	})
+
+	for _, o := range snaps[s.cfg.Retention:] {
+		log.Infow("removing old snapshot", "key", o.Key)
+		if err := s.s3.Delete(ctx, o.Key); err != nil {
+			return xerrors.Errorf("deleting %s: %w", o.Key, err)
+		}
+		if err := s.s3.Delete(ctx, o.Key+checksumSuffix); err != nil {
+			return xerrors.Errorf("deleting %s: %w", o.Key+checksumSuffix, err)
+		}
+		if err := s.s3.Delete(ctx, o.Key+ManifestSuffix); err != nil {
+			return xerrors.Errorf("deleting %s: %w", o.Key+ManifestSuffix, err)
+		}
+	}
+
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,36 @@
+// stm: #unit
+package snapshotter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+)
+
+func TestReportRetention(t *testing.T) {
+	j := journal.NilJournal()
+	al := alerting.NewAlertingSystem(j)
+	s := &Snapshotter{
+		journal:        j,
+		alerting:       al,
+		alert:          al.AddAlertType("snapshotter", "snapshot-failed"),
+		retentionAlert: al.AddAlertType("snapshotter", "retention-failed"),
+	}
+
+	// a retention failure doesn't fail the uploaded snapshot
+	s.report(SnapshotEvent{Key: "snapshot_1.car.zst"}, nil, errors.New("access denied"))
+	require.False(t, al.IsRaised(s.alert))
+	require.True(t, al.IsRaised(s.retentionAlert))
+
+	s.report(SnapshotEvent{}, errors.New("export failed"), nil)
+	require.True(t, al.IsRaised(s.alert))
+	require.True(t, al.IsRaised(s.retentionAlert))
+
+	s.report(SnapshotEvent{Key: "snapshot_2.car.zst"}, nil, nil)
+	require.False(t, al.IsRaised(s.alert))
+	require.False(t, al.IsRaised(s.retentionAlert))
+}
@@ -0,0 +1,359 @@
+// Package spendbudget limits the FIL the node's wallets spend on gas over rolling windows, a safety
+// net against runaway automation draining wallets. The maximum gas cost of a message is reserved
+// when it's pushed, and what it didn't spend is credited back once it's executed.
+package spendbudget
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("spendbudget")
+
+var ErrBudgetExceeded = xerrors.New("gas spend budget exceeded")
+
+var stateKey = datastore.NewKey("/state")
+
+// Window is a budget of Budget FIL, refilling continuously over Period.
+type Window struct {
+	Period time.Duration
+	Budget abi.TokenAmount
+}
+
+type Options struct {
+	Windows []Window
+
+	// Queue holds spends exceeding the budget until it refills, for up to QueueTimeout, instead
+	// of refusing them.
+	Queue        bool
+	QueueTimeout time.Duration
+}
+
+// Governor tracks the gas spent by the node's wallets in a token bucket per window. A spend is
+// allowed when it fits in all buckets, or in the allowance granted with SetAllowance. Bucket
+// levels are persisted in the datastore, so restarting the node doesn't refill them.
+type Governor struct {
+	opts Options
+	ds   datastore.Batching
+	now  func() time.Time
+	// ctx bounds the waits for the execution of messages
+	ctx context.Context
+
+	lk        sync.Mutex
+	levels    []abi.TokenAmount
+	updated   time.Time
+	allowance abi.TokenAmount
+	queued    int
+	// changed is closed when the allowance is raised, waking queued spends
+	changed chan struct{}
+}
+
+type persistedLevel struct {
+	Period time.Duration
+	Level  abi.TokenAmount
+}
+
+type persistedState struct {
+	Updated time.Time
+	Levels  []persistedLevel
+}
+
+// New creates a governor, loading the bucket levels from the datastore. Windows new to the
+// datastore start full. Reservations are settled until ctx is done.
+func New(ctx context.Context, ds datastore.Batching, opts Options) (*Governor, error) {
+	for _, w := range opts.Windows {
+		if w.Period <= 0 {
+			return nil, xerrors.Errorf("spend budget window period must be positive")
+		}
+		if w.Budget.Nil() || w.Budget.LessThanEqual(big.Zero()) {
+			return nil, xerrors.Errorf("spend budget of the %s window must be positive", w.Period)
+		}
+	}
+	if opts.Queue && opts.QueueTimeout <= 0 {
+		return nil, xerrors.Errorf("spend budget queue timeout must be positive")
+	}
+
+	g := &Governor{
+		opts:      opts,
+		ds:        ds,
+		now:       time.Now,
+		ctx:       ctx,
+		levels:    make([]abi.TokenAmount, len(opts.Windows)),
+		allowance: big.Zero(),
+		changed:   make(chan struct{}),
+	}
+	for i, w := range opts.Windows {
+		g.levels[i] = w.Budget
+	}
+
+	b, err := ds.Get(ctx, stateKey)
+	switch {
+	case xerrors.Is(err, datastore.ErrNotFound):
+		return g, nil
+	case err != nil:
+		return nil, xerrors.Errorf("loading spend budget state: %w", err)
+	}
+
+	var st persistedState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, xerrors.Errorf("decoding spend budget state: %w", err)
+	}
+	for i, w := range opts.Windows {
+		for _, l := range st.Levels {
+			if l.Period == w.Period && l.Level.LessThan(w.Budget) {
+				g.levels[i] = l.Level
+			}
+		}
+	}
+	g.updated = st.Updated
+
+	return g, nil
+}
+
+// Spend reserves cost, the maximum gas cost of a message from from, from the budget. Depending on
+// the options it fails with ErrBudgetExceeded when the cost doesn't fit, or waits for the budget
+// to refill. The reservation is refunded when the message isn't pushed, or settled once it's
+// executed.
+func (g *Governor) Spend(ctx context.Context, from address.Address, cost abi.TokenAmount) (r *Reservation, err error) {
+	var timeout <-chan time.Time
+	if g.opts.Queue {
+		t := time.NewTimer(g.opts.QueueTimeout)
+		defer t.Stop()
+		timeout = t.C
+	}
+
+	g.lk.Lock()
+	defer g.lk.Unlock()
+
+	for {
+		g.refill()
+
+		if g.fits(cost) {
+			return g.charge(ctx, cost, false), nil
+		}
+		if g.allowance.GreaterThanEqual(cost) {
+			log.Warnw("spending beyond the gas budget with the override allowance", "from", from, "cost", types.FIL(cost), "allowance", types.FIL(g.allowance))
+			return g.charge(ctx, cost, true), nil
+		}
+
+		wait, ok := g.waitFor(cost)
+		if !g.opts.Queue || !ok {
+			return nil, xerrors.Errorf("%w: %s from %s, %s", ErrBudgetExceeded, types.FIL(cost), from, g.describe())
+		}
+
+		log.Infow("holding message until the gas budget refills", "from", from, "cost", types.FIL(cost), "wait", wait)
+
+		g.queued++
+		changed := g.changed
+		g.lk.Unlock()
+
+		t := time.NewTimer(wait)
+		select {
+		case <-t.C:
+		case <-changed:
+		case <-timeout:
+			err = xerrors.Errorf("%w: %s from %s wasn't covered in %s", ErrBudgetExceeded, types.FIL(cost), from, g.opts.QueueTimeout)
+		case <-ctx.Done():
+			err = ctx.Err()
+		}
+		t.Stop()
+
+		g.lk.Lock()
+		g.queued--
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// SetAllowance sets the amount which can be spent beyond the budget, until it's used up.
+func (g *Governor) SetAllowance(allowance abi.TokenAmount) {
+	g.lk.Lock()
+	defer g.lk.Unlock()
+
+	log.Warnw("gas budget override allowance set", "allowance", types.FIL(allowance))
+
+	g.allowance = allowance
+	close(g.changed)
+	g.changed = make(chan struct{})
+}
+
+// Status returns the state of the budget.
+func (g *Governor) Status() *api.SpendBudget {
+	g.lk.Lock()
+	defer g.lk.Unlock()
+
+	g.refill()
+
+	st := &api.SpendBudget{
+		Allowance: g.allowance,
+		Queued:    g.queued,
+		Windows:   make([]api.SpendBudgetWindow, len(g.opts.Windows)),
+	}
+	for i, w := range g.opts.Windows {
+		st.Windows[i] = api.SpendBudgetWindow{
+			Period:    w.Period,
+			Budget:    w.Budget,
+			Available: g.levels[i],
+		}
+	}
+	return st
+}
+
+// refill tops up the buckets with what they regained since the last update.
+func (g *Governor) refill() {
+	now := g.now()
+	if g.updated.IsZero() {
+		g.updated = now
+		return
+	}
+	elapsed := now.Sub(g.updated)
+	if elapsed <= 0 {
+		return
+	}
+	g.updated = now
+
+	for i, w := range g.opts.Windows {
+		regained := big.Div(big.Mul(w.Budget, big.NewInt(int64(elapsed))), big.NewInt(int64(w.Period)))
+		g.levels[i] = big.Min(big.Add(g.levels[i], regained), w.Budget)
+	}
+}
+
+func (g *Governor) fits(cost abi.TokenAmount) bool {
+	for _, l := range g.levels {
+		if l.LessThan(cost) {
+			return false
+		}
+	}
+	return true
+}
+
+// waitFor returns how long until all buckets hold cost, false if a budget is smaller than cost.
+func (g *Governor) waitFor(cost abi.TokenAmount) (time.Duration, bool) {
+	var wait time.Duration
+	for i, w := range g.opts.Windows {
+		if w.Budget.LessThan(cost) {
+			return 0, false
+		}
+		deficit := big.Sub(cost, g.levels[i])
+		if deficit.LessThanEqual(big.Zero()) {
+			continue
+		}
+		// round up, so that the bucket holds cost after waiting
+		d := big.Div(big.Sub(big.Add(big.Mul(deficit, big.NewInt(int64(w.Period))), w.Budget), big.NewInt(1)), w.Budget)
+		if dw := time.Duration(d.Int64()); dw > wait {
+			wait = dw
+		}
+	}
+	return wait, true
+}
+
+// charge takes cost from the buckets, or from the allowance, and persists the levels. Spends
+// covered by the allowance drain the buckets without overdrawing them.
+func (g *Governor) charge(ctx context.Context, cost abi.TokenAmount, fromAllowance bool) *Reservation {
+	r := &Reservation{
+		g:             g,
+		cost:          cost,
+		taken:         make([]abi.TokenAmount, len(g.levels)),
+		fromAllowance: fromAllowance,
+	}
+	for i, l := range g.levels {
+		r.taken[i] = big.Min(l, cost)
+		g.levels[i] = big.Sub(l, r.taken[i])
+	}
+	if fromAllowance {
+		g.allowance = big.Sub(g.allowance, cost)
+	}
+	g.persist(ctx)
+	return r
+}
+
+// Settle settles a reservation in the background, with the gas cost returned by wait once the
+// message is executed. The whole reservation stays charged when wait fails, or when the node
+// stops before the message is executed.
+func (g *Governor) Settle(r *Reservation, wait func(ctx context.Context) (abi.TokenAmount, error)) {
+	go func() {
+		spent, err := wait(g.ctx)
+		if err != nil {
+			if g.ctx.Err() == nil {
+				log.Warnw("waiting for the gas cost of a message, its maximum cost stays charged", "cost", types.FIL(r.cost), "error", err)
+			}
+			return
+		}
+		r.Settle(spent)
+	}()
+}
+
+// Reservation is the maximum gas cost of a message, charged to the budget until the message is
+// executed.
+type Reservation struct {
+	g             *Governor
+	cost          abi.TokenAmount
+	taken         []abi.TokenAmount
+	fromAllowance bool
+	once          sync.Once
+}
+
+// Refund credits the whole reservation back, for messages which weren't pushed.
+func (r *Reservation) Refund() {
+	r.Settle(big.Zero())
+}
+
+// Settle credits back the part of the reservation the message didn't spend. Only the first call
+// of Settle or Refund has an effect.
+func (r *Reservation) Settle(spent abi.TokenAmount) {
+	r.once.Do(func() {
+		g := r.g
+		g.lk.Lock()
+		defer g.lk.Unlock()
+
+		g.refill()
+		for i, w := range g.opts.Windows {
+			if unused := big.Sub(r.taken[i], spent); unused.GreaterThan(big.Zero()) {
+				g.levels[i] = big.Min(big.Add(g.levels[i], unused), w.Budget)
+			}
+		}
+		if unused := big.Sub(r.cost, spent); r.fromAllowance && unused.GreaterThan(big.Zero()) {
+			g.allowance = big.Add(g.allowance, unused)
+		}
+		g.persist(context.Background())
+	})
+}
+
+func (g *Governor) persist(ctx context.Context) {
+	st := persistedState{Updated: g.updated}
+	for i, w := range g.opts.Windows {
+		st.Levels = append(st.Levels, persistedLevel{Period: w.Period, Level: g.levels[i]})
+	}
+	b, err := json.Marshal(st)
+	if err != nil {
+		log.Errorw("encoding spend budget state", "error", err)
+		return
+	}
+	if err := g.ds.Put(ctx, stateKey, b); err != nil {
+		log.Errorw("persisting spend budget state", "error", err)
+	}
+}
+
+func (g *Governor) describe() string {
+	left := make([]string, len(g.opts.Windows))
+	for i, w := range g.opts.Windows {
+		left[i] = fmt.Sprintf("%s of %s per %s left", types.FIL(g.levels[i]).Short(), types.FIL(w.Budget).Short(), w.Period)
+	}
+	return strings.Join(left, ", ")
+}
@@ -0,0 +1,131 @@
+package spendbudget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func fil(s string) big.Int {
+	return big.Int(types.MustParseFIL(s))
+}
+
+func newGovernor(t *testing.T, ds datastore.Batching, now *time.Time, opts Options) *Governor {
+	g, err := New(context.Background(), ds, opts)
+	require.NoError(t, err)
+	g.now = func() time.Time { return *now }
+	return g
+}
+
+func TestSpendBudget(t *testing.T) {
+	ctx := context.Background()
+	from, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	now := time.Unix(1700000000, 0)
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	g := newGovernor(t, ds, &now, Options{Windows: []Window{
+		{Period: time.Hour, Budget: fil("1")},
+		{Period: 24 * time.Hour, Budget: fil("10")},
+	}})
+
+	_, err = g.Spend(ctx, from, fil("0.6"))
+	require.NoError(t, err)
+	_, err = g.Spend(ctx, from, fil("0.6"))
+	require.True(t, xerrors.Is(err, ErrBudgetExceeded), err)
+
+	// half of the hourly budget refills in half an hour
+	now = now.Add(30 * time.Minute)
+	r, err := g.Spend(ctx, from, fil("0.6"))
+	require.NoError(t, err)
+
+	st := g.Status()
+	require.Equal(t, "0.3", types.FIL(st.Windows[0].Available).Unitless())
+
+	r.Refund()
+	r.Refund()
+	require.Equal(t, "0.9", types.FIL(g.Status().Windows[0].Available).Unitless())
+
+	// executed messages are credited what they didn't spend
+	r, err = g.Spend(ctx, from, fil("0.6"))
+	require.NoError(t, err)
+	require.Equal(t, "0.3", types.FIL(g.Status().Windows[0].Available).Unitless())
+	r.Settle(fil("0.2"))
+	r.Refund()
+	require.Equal(t, "0.7", types.FIL(g.Status().Windows[0].Available).Unitless())
+
+	settled := make(chan struct{})
+	r, err = g.Spend(ctx, from, fil("0.6"))
+	require.NoError(t, err)
+	g.Settle(r, func(context.Context) (abi.TokenAmount, error) {
+		defer close(settled)
+		return fil("0.1"), nil
+	})
+	<-settled
+	require.Eventually(t, func() bool {
+		return types.FIL(g.Status().Windows[0].Available).Unitless() == "0.6"
+	}, time.Second, time.Millisecond)
+
+	// the allowance covers spends beyond the budget
+	_, err = g.Spend(ctx, from, fil("2"))
+	require.True(t, xerrors.Is(err, ErrBudgetExceeded), err)
+	g.SetAllowance(fil("3"))
+	_, err = g.Spend(ctx, from, fil("2"))
+	require.NoError(t, err)
+	st = g.Status()
+	require.Equal(t, "1", types.FIL(st.Allowance).Unitless())
+	require.Equal(t, "0", types.FIL(st.Windows[0].Available).Unitless())
+
+	// the levels survive restarts
+	g = newGovernor(t, ds, &now, g.opts)
+	require.Equal(t, "0", types.FIL(g.Status().Windows[0].Available).Unitless())
+}
+
+func TestSpendBudgetQueue(t *testing.T) {
+	ctx := context.Background()
+	from, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	g, err := New(ctx, datastore.NewMapDatastore(), Options{
+		Windows:      []Window{{Period: time.Second, Budget: fil("1")}},
+		Queue:        true,
+		QueueTimeout: time.Minute,
+	})
+	require.NoError(t, err)
+
+	_, err = g.Spend(ctx, from, fil("1"))
+	require.NoError(t, err)
+
+	// waits for the budget to refill
+	start := time.Now()
+	_, err = g.Spend(ctx, from, fil("0.2"))
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+
+	// never fits
+	_, err = g.Spend(ctx, from, fil("2"))
+	require.True(t, xerrors.Is(err, ErrBudgetExceeded), err)
+
+	// woken by the override
+	_, err = g.Spend(ctx, from, fil("1"))
+	require.NoError(t, err)
+	done := make(chan error)
+	go func() {
+		_, err := g.Spend(ctx, from, fil("1"))
+		done <- err
+	}()
+	require.Eventually(t, func() bool { return g.Status().Queued == 1 }, time.Second, time.Millisecond)
+	g.SetAllowance(fil("1"))
+	require.NoError(t, <-done)
+}
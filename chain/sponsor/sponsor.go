@@ -0,0 +1,302 @@
+// Package sponsor relays contract calls signed by an Ethereum account through an ERC-2771
+// forwarder contract, with the gas paid by a sponsor address of the node wallet.
+//
+// Filecoin messages are paid for by their sender, so a call can only be sponsored when the
+// target contract accepts calls relayed by a trusted forwarder, which verifies the signature
+// of the account and appends its address to the calldata. Forwarders are expected to have the
+// interface of the OpenZeppelin MinimalForwarder.
+package sponsor
+
+import (
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	fbig "github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+var (
+	ErrSponsorNotAllowed   = xerrors.New("sponsor not allowed")
+	ErrForwarderNotAllowed = xerrors.New("forwarder not allowed")
+	ErrTargetNotAllowed    = xerrors.New("target not allowed")
+	ErrValueTooHigh        = xerrors.New("value exceeds the sponsored maximum")
+	ErrRateLimited         = xerrors.New("sponsored call rate limit exceeded")
+)
+
+const (
+	forwarderName    = "MinimalForwarder"
+	forwarderVersion = "0.0.1"
+)
+
+var (
+	executeSelector  = selector("execute((address,address,uint256,uint256,uint256,bytes),bytes)")
+	getNonceSelector = selector("getNonce(address)")
+)
+
+// ForwardRequest is a call executed by a forwarder on behalf of From.
+type ForwardRequest struct {
+	From  ethtypes.EthAddress
+	To    ethtypes.EthAddress
+	Value abi.TokenAmount
+	Gas   uint64
+	Nonce uint64
+	Data  []byte
+}
+
+// TypedData returns the EIP-712 typed data From signs to authorize the request.
+func (r *ForwardRequest) TypedData(chainID uint64, forwarder ethtypes.EthAddress) ethtypes.EthTypedData {
+	return ethtypes.EthTypedData{
+		Types: map[string][]ethtypes.EthTypedDataField{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "version", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"ForwardRequest": {
+				{Name: "from", Type: "address"},
+				{Name: "to", Type: "address"},
+				{Name: "value", Type: "uint256"},
+				{Name: "gas", Type: "uint256"},
+				{Name: "nonce", Type: "uint256"},
+				{Name: "data", Type: "bytes"},
+			},
+		},
+		PrimaryType: "ForwardRequest",
+		Domain: map[string]interface{}{
+			"name":              forwarderName,
+			"version":           forwarderVersion,
+			"chainId":           strconv.FormatUint(chainID, 10),
+			"verifyingContract": forwarder.String(),
+		},
+		Message: map[string]interface{}{
+			"from":  r.From.String(),
+			"to":    r.To.String(),
+			"value": r.Value.String(),
+			"gas":   strconv.FormatUint(r.Gas, 10),
+			"nonce": strconv.FormatUint(r.Nonce, 10),
+			"data":  ethtypes.EthBytes(r.Data).String(),
+		},
+	}
+}
+
+// ExecuteCalldata returns the calldata of the execute call of the forwarder relaying the
+// request with its signature.
+func (r *ForwardRequest) ExecuteCalldata(sig []byte) []byte {
+	var req []byte
+	req = append(req, addressWord(r.From)...)
+	req = append(req, addressWord(r.To)...)
+	req = append(req, intWord(r.Value.Int)...)
+	req = append(req, uintWord(r.Gas)...)
+	req = append(req, uintWord(r.Nonce)...)
+	req = append(req, uintWord(6*32)...)
+	req = append(req, bytesTail(r.Data)...)
+
+	out := append([]byte{}, executeSelector...)
+	out = append(out, uintWord(2*32)...)
+	out = append(out, uintWord(uint64(2*32+len(req)))...)
+	out = append(out, req...)
+	out = append(out, bytesTail(sig)...)
+	return out
+}
+
+// GetNonceCalldata returns the calldata of the forwarder call returning the next nonce of from.
+func GetNonceCalldata(from ethtypes.EthAddress) []byte {
+	return append(append([]byte{}, getNonceSelector...), addressWord(from)...)
+}
+
+// DecodeNonce decodes the nonce returned by the getNonce call of the forwarder.
+func DecodeNonce(ret []byte) (uint64, error) {
+	if len(ret) != 32 {
+		return 0, xerrors.Errorf("expected a 32 byte nonce, got %d bytes", len(ret))
+	}
+	n := new(big.Int).SetBytes(ret)
+	if !n.IsUint64() {
+		return 0, xerrors.Errorf("nonce %s overflows", n)
+	}
+	return n.Uint64(), nil
+}
+
+func selector(sig string) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write([]byte(sig))
+	return h.Sum(nil)[:4]
+}
+
+func addressWord(a ethtypes.EthAddress) []byte {
+	out := make([]byte, 32)
+	copy(out[12:], a[:])
+	return out
+}
+
+func uintWord(n uint64) []byte {
+	out := make([]byte, 32)
+	binary.BigEndian.PutUint64(out[24:], n)
+	return out
+}
+
+func intWord(n *big.Int) []byte {
+	out := make([]byte, 32)
+	if n != nil {
+		n.FillBytes(out)
+	}
+	return out
+}
+
+// bytesTail encodes dynamic bytes, their length followed by the zero padded bytes
+func bytesTail(b []byte) []byte {
+	out := uintWord(uint64(len(b)))
+	out = append(out, b...)
+	if pad := len(b) % 32; pad != 0 {
+		out = append(out, make([]byte, 32-pad)...)
+	}
+	return out
+}
+
+// Policy restricts the calls the node sponsors.
+type Policy struct {
+	// Sponsors are the addresses allowed to pay for calls, the first one is the default.
+	Sponsors []address.Address
+	// Forwarders are the forwarder contracts calls are relayed through.
+	Forwarders []ethtypes.EthAddress
+	// Targets are the only contracts calls may be made to, any when empty.
+	Targets []ethtypes.EthAddress
+
+	// MaxValue is the maximum value forwarded by a call, paid by the sponsor.
+	MaxValue abi.TokenAmount
+	// MaxFee is the maximum fee paid for a call, estimated when nil or zero.
+	MaxFee abi.TokenAmount
+
+	// MaxCallsPerHour is the number of calls sponsored per account per hour, 0 doesn't limit
+	// calls.
+	MaxCallsPerHour int
+}
+
+// Checker enforces a sponsoring policy.
+type Checker struct {
+	policy     Policy
+	sponsors   map[address.Address]struct{}
+	forwarders map[ethtypes.EthAddress]struct{}
+	targets    map[ethtypes.EthAddress]struct{}
+
+	lk        sync.Mutex
+	limiters  map[ethtypes.EthAddress]*limiter
+	lastSweep time.Time
+}
+
+// limiter is the hourly allowance of an account.
+type limiter struct {
+	*rate.Limiter
+	lastCall time.Time
+}
+
+// limiterExpiry is the time after which an unused limiter has its full
+// allowance back, and can be dropped.
+const limiterExpiry = time.Hour
+
+func NewChecker(p Policy) (*Checker, error) {
+	if len(p.Sponsors) == 0 {
+		return nil, xerrors.Errorf("no sponsor addresses")
+	}
+	if len(p.Forwarders) == 0 {
+		return nil, xerrors.Errorf("no forwarder contracts")
+	}
+	if p.MaxValue.Nil() {
+		p.MaxValue = fbig.Zero()
+	}
+
+	c := &Checker{
+		policy:     p,
+		sponsors:   map[address.Address]struct{}{},
+		forwarders: map[ethtypes.EthAddress]struct{}{},
+		targets:    map[ethtypes.EthAddress]struct{}{},
+		limiters:   map[ethtypes.EthAddress]*limiter{},
+	}
+	for _, a := range p.Sponsors {
+		c.sponsors[a] = struct{}{}
+	}
+	for _, a := range p.Forwarders {
+		c.forwarders[a] = struct{}{}
+	}
+	for _, a := range p.Targets {
+		c.targets[a] = struct{}{}
+	}
+	return c, nil
+}
+
+// DefaultSponsor is the sponsor of calls which don't name one.
+func (c *Checker) DefaultSponsor() address.Address {
+	return c.policy.Sponsors[0]
+}
+
+// MaxFee is the maximum fee paid for a call.
+func (c *Checker) MaxFee() abi.TokenAmount {
+	return c.policy.MaxFee
+}
+
+// Check checks a call is allowed by the policy, before it's rate limited with Allow.
+func (c *Checker) Check(sponsor address.Address, forwarder ethtypes.EthAddress, req *ForwardRequest) error {
+	if _, ok := c.sponsors[sponsor]; !ok {
+		return xerrors.Errorf("%w: %s", ErrSponsorNotAllowed, sponsor)
+	}
+	if _, ok := c.forwarders[forwarder]; !ok {
+		return xerrors.Errorf("%w: %s", ErrForwarderNotAllowed, forwarder)
+	}
+	if _, ok := c.targets[req.To]; len(c.targets) > 0 && !ok {
+		return xerrors.Errorf("%w: %s", ErrTargetNotAllowed, req.To)
+	}
+	if !req.Value.Nil() && req.Value.GreaterThan(c.policy.MaxValue) {
+		return xerrors.Errorf("%w: %s > %s", ErrValueTooHigh, types.FIL(req.Value), types.FIL(c.policy.MaxValue))
+	}
+	return nil
+}
+
+// Allow takes a call of the account from its hourly allowance.
+func (c *Checker) Allow(from ethtypes.EthAddress) error {
+	if c.policy.MaxCallsPerHour <= 0 {
+		return nil
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+
+	now := time.Now()
+	c.sweep(now)
+
+	l, ok := c.limiters[from]
+	if !ok {
+		l = &limiter{Limiter: rate.NewLimiter(rate.Every(time.Hour/time.Duration(c.policy.MaxCallsPerHour)), c.policy.MaxCallsPerHour)}
+		c.limiters[from] = l
+	}
+	l.lastCall = now
+	if !l.AllowN(now, 1) {
+		return xerrors.Errorf("%w: %d calls per hour for %s", ErrRateLimited, c.policy.MaxCallsPerHour, from)
+	}
+	return nil
+}
+
+// sweep drops the limiters of accounts which didn't make calls for
+// limiterExpiry, called with c.lk held.
+func (c *Checker) sweep(now time.Time) {
+	if now.Sub(c.lastSweep) < limiterExpiry {
+		return
+	}
+	c.lastSweep = now
+
+	for from, l := range c.limiters {
+		if now.Sub(l.lastCall) >= limiterExpiry {
+			delete(c.limiters, from)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package sponsor
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+func mustEthAddr(t *testing.T, s string) ethtypes.EthAddress {
+	a, err := ethtypes.ParseEthAddress(s)
+	require.NoError(t, err)
+	return a
+}
+
+func TestForwardRequestEncoding(t *testing.T) {
+	// selectors of the OpenZeppelin MinimalForwarder
+	require.Equal(t, "47153f82", hex.EncodeToString(executeSelector))
+	require.Equal(t, "2d0335ab", hex.EncodeToString(getNonceSelector))
+
+	from := mustEthAddr(t, "0x1111111111111111111111111111111111111111")
+	to := mustEthAddr(t, "0x2222222222222222222222222222222222222222")
+	req := &ForwardRequest{
+		From:  from,
+		To:    to,
+		Value: big.NewInt(5),
+		Gas:   100000,
+		Nonce: 3,
+		Data:  []byte{0xaa, 0xbb},
+	}
+
+	word := func(b []byte, i int) []byte { return b[4+32*i : 4+32*(i+1)] }
+	cd := req.ExecuteCalldata(make([]byte, 65))
+	require.Equal(t, executeSelector, cd[:4])
+	// head, request (6 words, data length and data) and signature (length and 3 words)
+	require.Len(t, cd, 4+32*(2+8+4))
+	require.Equal(t, uintWord(64), word(cd, 0))
+	require.Equal(t, uintWord(64+8*32), word(cd, 1))
+	require.Equal(t, addressWord(from), word(cd, 2))
+	require.Equal(t, addressWord(to), word(cd, 3))
+	require.Equal(t, uintWord(5), word(cd, 4))
+	require.Equal(t, uintWord(100000), word(cd, 5))
+	require.Equal(t, uintWord(3), word(cd, 6))
+	require.Equal(t, uintWord(6*32), word(cd, 7))
+	require.Equal(t, uintWord(2), word(cd, 8))
+	require.Equal(t, []byte{0xaa, 0xbb}, word(cd, 9)[:2])
+	require.Equal(t, uintWord(65), word(cd, 10))
+
+	cd = GetNonceCalldata(from)
+	require.Equal(t, getNonceSelector, cd[:4])
+	require.Equal(t, addressWord(from), cd[4:])
+
+	n, err := DecodeNonce(uintWord(7))
+	require.NoError(t, err)
+	require.Equal(t, uint64(7), n)
+	_, err = DecodeNonce([]byte{1})
+	require.Error(t, err)
+
+	td := req.TypedData(314, to)
+	_, err = td.Hash()
+	require.NoError(t, err)
+}
+
+func TestChecker(t *testing.T) {
+	sponsor, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	other, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	forwarder := mustEthAddr(t, "0x3333333333333333333333333333333333333333")
+	target := mustEthAddr(t, "0x2222222222222222222222222222222222222222")
+	from := mustEthAddr(t, "0x1111111111111111111111111111111111111111")
+
+	c, err := NewChecker(Policy{
+		Sponsors:        []address.Address{sponsor},
+		Forwarders:      []ethtypes.EthAddress{forwarder},
+		Targets:         []ethtypes.EthAddress{target},
+		MaxValue:        big.NewInt(10),
+		MaxCallsPerHour: 2,
+	})
+	require.NoError(t, err)
+	require.Equal(t, sponsor, c.DefaultSponsor())
+
+	req := &ForwardRequest{From: from, To: target, Value: big.NewInt(10)}
+	require.NoError(t, c.Check(sponsor, forwarder, req))
+	require.True(t, xerrors.Is(c.Check(other, forwarder, req), ErrSponsorNotAllowed))
+	require.True(t, xerrors.Is(c.Check(sponsor, target, req), ErrForwarderNotAllowed))
+	require.True(t, xerrors.Is(c.Check(sponsor, forwarder, &ForwardRequest{To: from}), ErrTargetNotAllowed))
+	require.True(t, xerrors.Is(c.Check(sponsor, forwarder, &ForwardRequest{To: target, Value: big.NewInt(11)}), ErrValueTooHigh))
+
+	require.NoError(t, c.Allow(from))
+	require.NoError(t, c.Allow(from))
+	require.True(t, xerrors.Is(c.Allow(from), ErrRateLimited))
+	require.NoError(t, c.Allow(target))
+
+	// the limiters of accounts are dropped once their allowance is back
+	require.Len(t, c.limiters, 2)
+	c.sweep(time.Now().Add(limiterExpiry))
+	require.Empty(t, c.limiters)
+
+	_, err = NewChecker(Policy{Forwarders: []ethtypes.EthAddress{forwarder}})
+	require.Error(t, err)
+}
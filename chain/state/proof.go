@@ -0,0 +1,415 @@
+package state
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	amt4 "github.com/filecoin-project/go-amt-ipld/v4"
+	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// An actor proof is the set of IPLD blocks read while looking up an actor in
+// the state tree: the state root, the HAMT nodes on the path to the actor and,
+// for addresses other than ID addresses, the init actor state and the HAMT
+// nodes of its address map. Anyone knowing the state root can check the proof
+// by looking up the actor again in a state tree made of the proof blocks only.
+// When the actor doesn't exist, the blocks prove its absence.
+
+// recordingBlockstore records the blocks read from a blockstore.
+type recordingBlockstore struct {
+	bs cbor.IpldBlockstore
+
+	lk   sync.Mutex
+	seen map[cid.Cid]struct{}
+	blks []blocks.Block
+}
+
+func (r *recordingBlockstore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	b, err := r.bs.Get(ctx, c)
+	if err != nil {
+		return nil, err
+	}
+
+	r.lk.Lock()
+	defer r.lk.Unlock()
+	if _, ok := r.seen[c]; !ok {
+		r.seen[c] = struct{}{}
+		r.blks = append(r.blks, b)
+	}
+	return b, nil
+}
+
+func (r *recordingBlockstore) Put(ctx context.Context, b blocks.Block) error {
+	return xerrors.Errorf("proving actors doesn't write to the state")
+}
+
+// ProveActor looks up an actor in the state tree with the given root, and
+// returns it with the blocks proving it. The actor is nil when it doesn't
+// exist, in which case the blocks prove its absence.
+func ProveActor(ctx context.Context, bs cbor.IpldBlockstore, root cid.Cid, addr address.Address) (*types.Actor, []blocks.Block, error) {
+	rec := &recordingBlockstore{bs: bs, seen: make(map[cid.Cid]struct{})}
+	act, err := lookupActor(rec, root, addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	return act, rec.blks, nil
+}
+
+// VerifyActorProof checks the blocks prove the state of an actor in the state
+// tree with the given root, and returns the actor. The actor is nil when the
+// blocks prove it doesn't exist. The root must come from a trusted source, such
+// as the ParentStateRoot of a block header in a trusted tipset.
+func VerifyActorProof(ctx context.Context, root cid.Cid, addr address.Address, blks []blocks.Block) (*types.Actor, error) {
+	bs, err := proofBlockstore(ctx, blks)
+	if err != nil {
+		return nil, err
+	}
+
+	act, err := lookupActor(bs, root, addr)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid actor proof: %w", err)
+	}
+	return act, nil
+}
+
+func lookupActor(bs cbor.IpldBlockstore, root cid.Cid, addr address.Address) (*types.Actor, error) {
+	st, err := LoadStateTree(cbor.NewCborStore(bs), root)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state tree: %w", err)
+	}
+	act, err := st.GetActor(addr)
+	if xerrors.Is(err, types.ErrActorNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("looking up actor %s: %w", addr, err)
+	}
+	return act, nil
+}
+
+// VerifyTipSetProof checks the serialized block headers are the blocks of the
+// tipset with the given key, and returns the tipset. Its ParentState is the
+// state root actor proofs against the tipset are checked with.
+func VerifyTipSetProof(tsk types.TipSetKey, headers [][]byte) (*types.TipSet, error) {
+	cids := tsk.Cids()
+	if len(headers) != len(cids) {
+		return nil, xerrors.Errorf("expected %d block headers, got %d", len(cids), len(headers))
+	}
+
+	blks := make([]*types.BlockHeader, len(headers))
+	for i, h := range headers {
+		blk, err := types.DecodeBlock(h)
+		if err != nil {
+			return nil, xerrors.Errorf("decoding block header %d: %w", i, err)
+		}
+		c, err := cids[i].Prefix().Sum(h)
+		if err != nil {
+			return nil, xerrors.Errorf("hashing block header %d: %w", i, err)
+		}
+		if !c.Equals(cids[i]) {
+			return nil, xerrors.Errorf("block header %d doesn't match the tipset key (expected %s, got %s)", i, cids[i], c)
+		}
+		blks[i] = blk
+	}
+
+	ts, err := types.NewTipSet(blks)
+	if err != nil {
+		return nil, xerrors.Errorf("invalid tipset: %w", err)
+	}
+	if ts.Key() != tsk {
+		return nil, xerrors.Errorf("block headers are not in tipset order")
+	}
+	return ts, nil
+}
+
+// A receipt proof is the set of IPLD blocks read while getting a receipt from
+// the receipts AMT a block header commits to in ParentMessageReceipts, and
+// while reading all the events of the receipt from its events AMT.
+
+// ProveReceipt gets the receipt at the given index of the receipts AMT with
+// the given root, and returns it and its events with the blocks proving them.
+func ProveReceipt(ctx context.Context, bs cbor.IpldBlockstore, root cid.Cid, index uint64) (*types.MessageReceipt, []types.Event, []blocks.Block, error) {
+	rec := &recordingBlockstore{bs: bs, seen: make(map[cid.Cid]struct{})}
+	rct, evs, err := loadReceipt(ctx, rec, root, index)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return rct, evs, rec.blks, nil
+}
+
+// VerifyReceiptProof checks the blocks prove the receipt at the given index of
+// the receipts AMT with the given root, and returns the receipt and its events.
+// The root must come from a trusted source, such as the ParentMessageReceipts
+// of a block header verified with VerifyHeaderChain.
+func VerifyReceiptProof(ctx context.Context, root cid.Cid, index uint64, blks []blocks.Block) (*types.MessageReceipt, []types.Event, error) {
+	bs, err := proofBlockstore(ctx, blks)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rct, evs, err := loadReceipt(ctx, bs, root, index)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("invalid receipt proof: %w", err)
+	}
+	return rct, evs, nil
+}
+
+func loadReceipt(ctx context.Context, bs cbor.IpldBlockstore, root cid.Cid, index uint64) (*types.MessageReceipt, []types.Event, error) {
+	cst := cbor.NewCborStore(bs)
+
+	// block headers use adt0, for now.
+	rcpts, err := blockadt.AsArray(blockadt.WrapStore(ctx, cst), root)
+	if err != nil {
+		return nil, nil, xerrors.Errorf("loading receipts: %w", err)
+	}
+	var rct types.MessageReceipt
+	if found, err := rcpts.Get(index, &rct); err != nil {
+		return nil, nil, xerrors.Errorf("getting receipt %d: %w", index, err)
+	} else if !found {
+		return nil, nil, xerrors.Errorf("receipt %d not found", index)
+	}
+
+	if rct.EventsRoot == nil {
+		return &rct, nil, nil
+	}
+
+	evtArr, err := amt4.LoadAMT(ctx, cst, *rct.EventsRoot, amt4.UseTreeBitWidth(types.EventAMTBitwidth))
+	if err != nil {
+		return nil, nil, xerrors.Errorf("loading events: %w", err)
+	}
+	evs := make([]types.Event, 0, evtArr.Len())
+	if err := evtArr.ForEach(ctx, func(u uint64, deferred *cbg.Deferred) error {
+		if u != uint64(len(evs)) {
+			return xerrors.Errorf("missing event %d", len(evs))
+		}
+		var evt types.Event
+		if err := evt.UnmarshalCBOR(bytes.NewReader(deferred.Raw)); err != nil {
+			return err
+		}
+		evs = append(evs, evt)
+		return nil
+	}); err != nil {
+		return nil, nil, xerrors.Errorf("reading events: %w", err)
+	}
+	return &rct, evs, nil
+}
+
+// A message inclusion proof is the set of IPLD blocks read while finding the
+// position of a message in the execution order of the tipset including it: the
+// message metadata and message AMTs of its blocks, and the messages up to the
+// proven one, which are needed to skip the duplicates and the messages with an
+// unexpected nonce. When senders are resolved to IDs, the state blocks of the
+// lookups are included too. The position is the index of the receipt of the
+// message in the receipts of the next tipset.
+
+// ProveMessageInclusion finds the position of a message in the execution order
+// of the tipset including it, and returns it with the blocks proving it.
+func ProveMessageInclusion(ctx context.Context, bs cbor.IpldBlockstore, ts *types.TipSet, msg cid.Cid) (uint64, []blocks.Block, error) {
+	rec := &recordingBlockstore{bs: bs, seen: make(map[cid.Cid]struct{})}
+	index, err := messageIndex(ctx, rec, ts, msg)
+	if err != nil {
+		return 0, nil, err
+	}
+	return index, rec.blks, nil
+}
+
+// VerifyMessageInclusion checks the serialized block headers are the blocks of
+// the tipset with the given key, and that the blocks prove the message is
+// executed at the given position of the tipset. The key must come from a
+// trusted source, such as the Parents of a block header verified with
+// VerifyHeaderChain.
+func VerifyMessageInclusion(ctx context.Context, tsk types.TipSetKey, headers [][]byte, msg cid.Cid, index uint64, blks []blocks.Block) error {
+	ts, err := VerifyTipSetProof(tsk, headers)
+	if err != nil {
+		return err
+	}
+	bs, err := proofBlockstore(ctx, blks)
+	if err != nil {
+		return err
+	}
+
+	proven, err := messageIndex(ctx, bs, ts, msg)
+	if err != nil {
+		return xerrors.Errorf("invalid message inclusion proof: %w", err)
+	}
+	if proven != index {
+		return xerrors.Errorf("message %s is executed at position %d, not %d", msg, proven, index)
+	}
+	return nil
+}
+
+var errMessageFound = xerrors.New("message found")
+
+// messageIndex returns the position of a message in the execution order of the
+// tipset, selecting the messages as ChainStore.BlockMsgsForTipset does.
+func messageIndex(ctx context.Context, bs cbor.IpldBlockstore, ts *types.TipSet, msg cid.Cid) (uint64, error) {
+	cst := cbor.NewCborStore(bs)
+	store := blockadt.WrapStore(ctx, cst)
+
+	// the state is only loaded when senders are resolved to IDs
+	var st *StateTree
+	lookupID := func(a address.Address) (address.Address, error) {
+		if st == nil {
+			var err error
+			if st, err = LoadStateTree(cst, ts.ParentState()); err != nil {
+				return address.Undef, xerrors.Errorf("loading state tree: %w", err)
+			}
+		}
+		return st.LookupID(a)
+	}
+
+	applied := make(map[address.Address]uint64)
+	useIds := false
+	selectMsg := func(m *types.Message) (bool, error) {
+		sender := m.From
+		if ts.Height() >= build.UpgradeHyperdriveHeight && (useIds || m.From.Protocol() == address.ID) {
+			if !useIds {
+				useIds = true
+				resolved := make(map[address.Address]uint64, len(applied))
+				for robust, nonce := range applied {
+					id, err := lookupID(robust)
+					if err != nil {
+						return false, xerrors.Errorf("failed to resolve sender: %w", err)
+					}
+					resolved[id] = nonce
+				}
+				applied = resolved
+			}
+			var err error
+			if sender, err = lookupID(m.From); err != nil {
+				return false, xerrors.Errorf("failed to resolve sender: %w", err)
+			}
+		}
+
+		// the first message of a sender has the right nonce, the block isn't valid otherwise
+		if _, ok := applied[sender]; !ok {
+			applied[sender] = m.Nonce
+		}
+		if applied[sender] != m.Nonce {
+			return false, nil
+		}
+		applied[sender]++
+		return true, nil
+	}
+
+	var index uint64
+	for _, b := range ts.Blocks() {
+		var mm types.MsgMeta
+		if err := cst.Get(ctx, b.Messages, &mm); err != nil {
+			return 0, xerrors.Errorf("loading message meta of block %s: %w", b.Cid(), err)
+		}
+
+		for i, root := range []cid.Cid{mm.BlsMessages, mm.SecpkMessages} {
+			// block headers use adt0, for now.
+			arr, err := blockadt.AsArray(store, root)
+			if err != nil {
+				return 0, xerrors.Errorf("loading messages of block %s: %w", b.Cid(), err)
+			}
+
+			var c cbg.CborCid
+			err = arr.ForEach(&c, func(int64) error {
+				mc := cid.Cid(c)
+				var m *types.Message
+				if i == 0 {
+					m = new(types.Message)
+					if err := cst.Get(ctx, mc, m); err != nil {
+						return xerrors.Errorf("loading message %s: %w", mc, err)
+					}
+				} else {
+					var sm types.SignedMessage
+					if err := cst.Get(ctx, mc, &sm); err != nil {
+						return xerrors.Errorf("loading message %s: %w", mc, err)
+					}
+					m = &sm.Message
+				}
+
+				selected, err := selectMsg(m)
+				if err != nil || !selected {
+					return err
+				}
+				if mc.Equals(msg) {
+					return errMessageFound
+				}
+				index++
+				return nil
+			})
+			if xerrors.Is(err, errMessageFound) {
+				return index, nil
+			}
+			if err != nil {
+				return 0, err
+			}
+		}
+	}
+	return 0, xerrors.Errorf("message %s isn't executed in tipset %s", msg, ts.Key())
+}
+
+// VerifyHeaderChain checks the serialized block headers link a trusted tipset
+// to one of its ancestors, and returns the header of the ancestor. The first
+// header must be a block of the tipset with the given key, and every next one a
+// block of the parent tipset of the previous one. One block per tipset is
+// enough, since all the blocks of a tipset have the same parents and commit to
+// the same parent state and receipts.
+func VerifyHeaderChain(tsk types.TipSetKey, headers [][]byte) (*types.BlockHeader, error) {
+	if len(headers) == 0 {
+		return nil, xerrors.Errorf("no block headers")
+	}
+
+	var blk *types.BlockHeader
+	for i, h := range headers {
+		var err error
+		blk, err = types.DecodeBlock(h)
+		if err != nil {
+			return nil, xerrors.Errorf("decoding block header %d: %w", i, err)
+		}
+
+		var member bool
+		for _, c := range tsk.Cids() {
+			s, err := c.Prefix().Sum(h)
+			if err != nil {
+				return nil, xerrors.Errorf("hashing block header %d: %w", i, err)
+			}
+			if s.Equals(c) {
+				member = true
+				break
+			}
+		}
+		if !member {
+			return nil, xerrors.Errorf("block header %d isn't a block of tipset %s", i, tsk)
+		}
+
+		tsk = types.NewTipSetKey(blk.Parents...)
+	}
+	return blk, nil
+}
+
+// proofBlockstore returns a blockstore holding the proof blocks, after checking
+// the blocks match their cids.
+func proofBlockstore(ctx context.Context, blks []blocks.Block) (blockstore.Blockstore, error) {
+	bs := blockstore.NewMemory()
+	for _, b := range blks {
+		c, err := b.Cid().Prefix().Sum(b.RawData())
+		if err != nil {
+			return nil, xerrors.Errorf("hashing proof block %s: %w", b.Cid(), err)
+		}
+		if !c.Equals(b.Cid()) {
+			return nil, xerrors.Errorf("proof block data doesn't match its cid %s", b.Cid())
+		}
+		if err := bs.Put(ctx, b); err != nil {
+			return nil, err
+		}
+	}
+	return bs, nil
+}
@@ -0,0 +1,263 @@
+package state
+
+import (
+	"context"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	amt4 "github.com/filecoin-project/go-amt-ipld/v4"
+	"github.com/filecoin-project/go-state-types/crypto"
+	blockadt "github.com/filecoin-project/specs-actors/actors/util/adt"
+	builtin2 "github.com/filecoin-project/specs-actors/v2/actors/builtin"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestActorProof(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+
+	sv, err := VersionForNetwork(build.TestNetworkVersion)
+	require.NoError(t, err)
+	st, err := NewStateTree(cbor.NewCborStore(bs), sv)
+	require.NoError(t, err)
+
+	// enough actors for the HAMT to have several levels
+	for i := uint64(100); i < 1100; i++ {
+		a, err := address.NewIDAddress(i)
+		require.NoError(t, err)
+		require.NoError(t, st.SetActor(a, &types.Actor{
+			Balance: types.NewInt(i),
+			Code:    builtin2.AccountActorCodeID,
+			Head:    builtin2.AccountActorCodeID,
+			Nonce:   i,
+		}))
+	}
+	root, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	addr, err := address.NewIDAddress(500)
+	require.NoError(t, err)
+	act, blks, err := ProveActor(ctx, bs, root, addr)
+	require.NoError(t, err)
+	require.NotNil(t, act)
+	require.Equal(t, uint64(500), act.Nonce)
+
+	proven, err := VerifyActorProof(ctx, root, addr, blks)
+	require.NoError(t, err)
+	require.Equal(t, act, proven)
+
+	// the proof doesn't cover other actors
+	other, err := address.NewIDAddress(501)
+	require.NoError(t, err)
+	_, err = VerifyActorProof(ctx, root, other, blks)
+	require.Error(t, err)
+
+	// tampered blocks are rejected
+	last := blks[len(blks)-1]
+	data := append([]byte{}, last.RawData()...)
+	data[len(data)-1] ^= 1
+	tampered, err := blocks.NewBlockWithCid(data, last.Cid())
+	require.NoError(t, err)
+	_, err = VerifyActorProof(ctx, root, addr, append(append([]blocks.Block{}, blks[:len(blks)-1]...), tampered))
+	require.Error(t, err)
+
+	// absence proofs
+	missing, err := address.NewIDAddress(5000)
+	require.NoError(t, err)
+	act, blks, err = ProveActor(ctx, bs, root, missing)
+	require.NoError(t, err)
+	require.Nil(t, act)
+	proven, err = VerifyActorProof(ctx, root, missing, blks)
+	require.NoError(t, err)
+	require.Nil(t, proven)
+}
+
+func TestTipSetProof(t *testing.T) {
+	w := mock.Address(1000)
+	b1 := mock.MkBlock(nil, 1, 1)
+	b2 := mock.MkBlock(nil, 1, 2)
+	b2.Miner = w
+	ts, err := types.NewTipSet([]*types.BlockHeader{b1, b2})
+	require.NoError(t, err)
+
+	var headers [][]byte
+	for _, b := range ts.Blocks() {
+		h, err := b.Serialize()
+		require.NoError(t, err)
+		headers = append(headers, h)
+	}
+
+	proven, err := VerifyTipSetProof(ts.Key(), headers)
+	require.NoError(t, err)
+	require.Equal(t, ts.ParentState(), proven.ParentState())
+
+	_, err = VerifyTipSetProof(ts.Key(), headers[:1])
+	require.Error(t, err)
+	_, err = VerifyTipSetProof(ts.Key(), [][]byte{headers[1], headers[0]})
+	require.Error(t, err)
+}
+
+func TestReceiptProof(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	cst := cbor.NewCborStore(bs)
+
+	events := []types.Event{
+		{Emitter: 1000, Entries: []types.EventEntry{{Flags: 0x03, Key: "t1", Codec: 0x55, Value: []byte{1}}}},
+		{Emitter: 1001, Entries: []types.EventEntry{{Flags: 0x03, Key: "t1", Codec: 0x55, Value: []byte{2}}}},
+	}
+	objs := make([]cbg.CBORMarshaler, len(events))
+	for i := range events {
+		objs[i] = &events[i]
+	}
+	eventsRoot, err := amt4.FromArray(ctx, cst, objs, amt4.UseTreeBitWidth(types.EventAMTBitwidth))
+	require.NoError(t, err)
+
+	// enough receipts for the AMT to have several levels
+	rcpts := blockadt.MakeEmptyArray(blockadt.WrapStore(ctx, cst))
+	for i := uint64(0); i < 200; i++ {
+		rct := types.NewMessageReceiptV0(0, nil, int64(i))
+		if i == 120 {
+			rct = types.NewMessageReceiptV1(0, nil, int64(i), &eventsRoot)
+		}
+		require.NoError(t, rcpts.Set(i, &rct))
+	}
+	root, err := rcpts.Root()
+	require.NoError(t, err)
+
+	rct, evs, blks, err := ProveReceipt(ctx, bs, root, 120)
+	require.NoError(t, err)
+	require.Equal(t, int64(120), rct.GasUsed)
+	require.Equal(t, events, evs)
+
+	proven, provenEvs, err := VerifyReceiptProof(ctx, root, 120, blks)
+	require.NoError(t, err)
+	require.Equal(t, rct, proven)
+	require.Equal(t, events, provenEvs)
+
+	// the proof doesn't cover other receipts
+	_, _, err = VerifyReceiptProof(ctx, root, 10, blks)
+	require.Error(t, err)
+
+	// receipts without events
+	rct, evs, blks, err = ProveReceipt(ctx, bs, root, 10)
+	require.NoError(t, err)
+	require.Nil(t, rct.EventsRoot)
+	require.Empty(t, evs)
+	_, _, err = VerifyReceiptProof(ctx, root, 10, blks)
+	require.NoError(t, err)
+
+	_, _, _, err = ProveReceipt(ctx, bs, root, 500)
+	require.Error(t, err)
+}
+
+func TestMessageInclusionProof(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	cst := cbor.NewCborStore(bs)
+
+	from1, from2 := mock.Address(1000), mock.Address(1001)
+	bls1 := &types.Message{From: from1, To: from2, Nonce: 0}
+	secp1 := &types.SignedMessage{
+		Message:   types.Message{From: from2, To: from1, Nonce: 0},
+		Signature: crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte{1}},
+	}
+	secp2 := &types.SignedMessage{
+		Message:   types.Message{From: from1, To: from2, Nonce: 1},
+		Signature: crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: []byte{2}},
+	}
+
+	msgMeta := func(bls []*types.Message, secp []*types.SignedMessage) cid.Cid {
+		store := blockadt.WrapStore(ctx, cst)
+		blsArr, secpArr := blockadt.MakeEmptyArray(store), blockadt.MakeEmptyArray(store)
+		for i, m := range bls {
+			c, err := cst.Put(ctx, m)
+			require.NoError(t, err)
+			cc := cbg.CborCid(c)
+			require.NoError(t, blsArr.Set(uint64(i), &cc))
+		}
+		for i, m := range secp {
+			c, err := cst.Put(ctx, m)
+			require.NoError(t, err)
+			cc := cbg.CborCid(c)
+			require.NoError(t, secpArr.Set(uint64(i), &cc))
+		}
+		blsRoot, err := blsArr.Root()
+		require.NoError(t, err)
+		secpRoot, err := secpArr.Root()
+		require.NoError(t, err)
+		c, err := cst.Put(ctx, &types.MsgMeta{BlsMessages: blsRoot, SecpkMessages: secpRoot})
+		require.NoError(t, err)
+		return c
+	}
+
+	// the second block includes bls1 again, which isn't executed twice
+	b1 := mock.MkBlock(nil, 1, 1)
+	b1.Messages = msgMeta([]*types.Message{bls1}, []*types.SignedMessage{secp1})
+	b2 := mock.MkBlock(nil, 1, 2)
+	b2.Messages = msgMeta([]*types.Message{bls1}, []*types.SignedMessage{secp2})
+	ts := mock.TipSet(b1, b2)
+
+	var headers [][]byte
+	for _, b := range ts.Blocks() {
+		h, err := b.Serialize()
+		require.NoError(t, err)
+		headers = append(headers, h)
+	}
+
+	index, blks, err := ProveMessageInclusion(ctx, bs, ts, secp2.Cid())
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), index)
+	require.NoError(t, VerifyMessageInclusion(ctx, ts.Key(), headers, secp2.Cid(), 2, blks))
+
+	// the proof doesn't hold for other positions, messages or tipsets
+	require.Error(t, VerifyMessageInclusion(ctx, ts.Key(), headers, secp2.Cid(), 1, blks))
+	require.Error(t, VerifyMessageInclusion(ctx, ts.Key(), headers, secp1.Cid(), 2, blks))
+	require.Error(t, VerifyMessageInclusion(ctx, ts.Key(), headers[:1], secp2.Cid(), 2, blks))
+	require.Error(t, VerifyMessageInclusion(ctx, ts.Key(), headers, secp2.Cid(), 2, blks[1:]))
+
+	_, _, err = ProveMessageInclusion(ctx, bs, ts, mock.UnsignedMessage(from1, from2, 5).Cid())
+	require.Error(t, err)
+}
+
+func TestHeaderChain(t *testing.T) {
+	ts1 := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	ts2 := mock.TipSet(mock.MkBlock(ts1, 1, 2))
+	b3 := mock.MkBlock(ts2, 1, 3)
+	b3b := mock.MkBlock(ts2, 1, 4)
+	ts3, err := types.NewTipSet([]*types.BlockHeader{b3, b3b})
+	require.NoError(t, err)
+
+	serialize := func(b *types.BlockHeader) []byte {
+		h, err := b.Serialize()
+		require.NoError(t, err)
+		return h
+	}
+
+	headers := [][]byte{serialize(b3b), serialize(ts2.Blocks()[0]), serialize(ts1.Blocks()[0])}
+	blk, err := VerifyHeaderChain(ts3.Key(), headers)
+	require.NoError(t, err)
+	require.Equal(t, ts1.Blocks()[0].Cid(), blk.Cid())
+
+	blk, err = VerifyHeaderChain(ts3.Key(), headers[:1])
+	require.NoError(t, err)
+	require.Equal(t, b3b.Cid(), blk.Cid())
+
+	// headers must follow the parents
+	_, err = VerifyHeaderChain(ts3.Key(), [][]byte{headers[0], headers[2]})
+	require.Error(t, err)
+	_, err = VerifyHeaderChain(ts2.Key(), headers)
+	require.Error(t, err)
+	_, err = VerifyHeaderChain(ts3.Key(), nil)
+	require.Error(t, err)
+}
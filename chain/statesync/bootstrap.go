@@ -0,0 +1,185 @@
+package statesync
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/exchange"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// RetryInterval is the wait before a failed bootstrap is retried.
+var RetryInterval = 30 * time.Second
+
+// errFatal marks bootstrap errors that retrying won't fix.
+var errFatal = errors.New("fatal")
+
+// Bootstrapper brings a fresh node to a checkpoint tipset. It fetches the
+// block headers from the checkpoint to genesis over ChainExchange, and the
+// state trees, message receipts and messages of the tipsets within
+// StateEpochs of the checkpoint with the state sync protocol.
+//
+// The checkpoint is trusted like a snapshot would be: everything fetched is
+// verified against it, but it isn't validated from genesis.
+type Bootstrapper struct {
+	cs         *store.ChainStore
+	xchg       exchange.Client
+	client     *Client
+	checkpoint types.TipSetKey
+	// stateEpochs is the number of epochs below the checkpoint whose state
+	// is fetched
+	stateEpochs abi.ChainEpoch
+}
+
+// NewBootstrapper creates a bootstrapper to the checkpoint.
+func NewBootstrapper(cs *store.ChainStore, xchg exchange.Client, client *Client, checkpoint types.TipSetKey, stateEpochs abi.ChainEpoch) *Bootstrapper {
+	return &Bootstrapper{
+		cs:          cs,
+		xchg:        xchg,
+		client:      client,
+		checkpoint:  checkpoint,
+		stateEpochs: stateEpochs,
+	}
+}
+
+// Needed returns whether the chain store is fresh, holding only the genesis.
+func (b *Bootstrapper) Needed() bool {
+	return b.cs.GetHeaviestTipSet().Height() == 0
+}
+
+// Run bootstraps the chain store, retrying until it succeeds, fails in a way
+// retrying won't fix, or the context is cancelled.
+func (b *Bootstrapper) Run(ctx context.Context) error {
+	for {
+		err := b.bootstrap(ctx)
+		if err == nil || errors.Is(err, errFatal) || ctx.Err() != nil {
+			return err
+		}
+		log.Warnw("state sync bootstrap failed, retrying", "checkpoint", b.checkpoint, "err", err, "retry", RetryInterval)
+
+		select {
+		case <-time.After(RetryInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (b *Bootstrapper) bootstrap(ctx context.Context) error {
+	if len(b.client.Peers()) == 0 {
+		return ErrNoPeers
+	}
+
+	log.Infow("fetching block headers", "checkpoint", b.checkpoint)
+	recent, err := b.fetchHeaders(ctx)
+	if err != nil {
+		return err
+	}
+	ts := recent[0]
+
+	roots := make([]cid.Cid, 0, 3*len(recent))
+	for _, rts := range recent {
+		roots = append(roots, rts.ParentState(), rts.Blocks()[0].ParentMessageReceipts)
+		for _, blk := range rts.Blocks() {
+			roots = append(roots, blk.Messages)
+		}
+	}
+
+	log.Infow("fetching state", "checkpoint", b.checkpoint, "height", ts.Height(), "tipsets", len(recent))
+	stats, err := b.client.Fetch(ctx, b.cs.StateBlockstore(), roots...)
+	if err != nil {
+		return xerrors.Errorf("fetching state: %w", err)
+	}
+	log.Infow("fetched state", "checkpoint", b.checkpoint, "fetched", stats.Fetched, "present", stats.Present, "bytes", stats.Bytes)
+
+	if err := b.cs.FlushValidationCache(ctx); err != nil {
+		return xerrors.Errorf("flushing validation cache failed: %w", err)
+	}
+	if err := b.cs.ForceHeadSilent(ctx, ts); err != nil {
+		return xerrors.Errorf("setting the checkpoint as head: %w", err)
+	}
+	if err := b.cs.SetCheckpoint(ctx, ts); err != nil {
+		return xerrors.Errorf("setting the chain checkpoint: %w", err)
+	}
+
+	log.Infow("bootstrapped the chain from state sync", "head", ts.Key(), "height", ts.Height())
+	return nil
+}
+
+// fetchHeaders fetches and persists the block headers from the checkpoint to
+// genesis, and returns the tipsets within stateEpochs of the checkpoint, from
+// the checkpoint down.
+func (b *Bootstrapper) fetchHeaders(ctx context.Context) ([]*types.TipSet, error) {
+	genesis, err := b.cs.GetGenesis(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("loading genesis: %w", err)
+	}
+
+	tss, err := b.xchg.GetBlocks(ctx, b.checkpoint, 1)
+	if err != nil {
+		return nil, xerrors.Errorf("fetching the checkpoint tipset: %w", err)
+	}
+	if len(tss) != 1 || tss[0].Key() != b.checkpoint {
+		return nil, xerrors.Errorf("peers returned the wrong tipset for the checkpoint")
+	}
+	cur := tss[0]
+	if err := b.cs.PersistTipsets(ctx, tss); err != nil {
+		return nil, xerrors.Errorf("persisting headers: %w", err)
+	}
+
+	recent := []*types.TipSet{cur}
+	minHeight := cur.Height() - b.stateEpochs
+	lastLog := time.Now()
+	for cur.Height() > 0 {
+		if time.Since(lastLog) > 30*time.Second {
+			log.Infow("fetching block headers", "height", cur.Height())
+			lastLog = time.Now()
+		}
+
+		if ts, err := b.cs.LoadTipSet(ctx, cur.Parents()); err == nil {
+			// headers already fetched by an interrupted bootstrap
+			cur = ts
+		} else {
+			count := exchange.MaxRequestLength
+			if h := uint64(cur.Height()); h < count {
+				count = h
+			}
+			tss, err := b.xchg.GetBlocks(ctx, cur.Parents(), int(count))
+			if err != nil {
+				return nil, xerrors.Errorf("fetching headers below %d: %w", cur.Height(), err)
+			}
+			if len(tss) == 0 {
+				return nil, xerrors.Errorf("peers returned no headers below %d", cur.Height())
+			}
+			for _, ts := range tss {
+				if ts.Key() != cur.Parents() || ts.Height() >= cur.Height() {
+					return nil, xerrors.Errorf("peers returned headers not linking to %s", cur.Key())
+				}
+				cur = ts
+				if cur.Height() >= minHeight {
+					recent = append(recent, cur)
+				}
+			}
+			if err := b.cs.PersistTipsets(ctx, tss); err != nil {
+				return nil, xerrors.Errorf("persisting headers: %w", err)
+			}
+			continue
+		}
+
+		if cur.Height() >= minHeight {
+			recent = append(recent, cur)
+		}
+	}
+
+	if cur.Blocks()[0].Cid() != genesis.Cid() {
+		return nil, xerrors.Errorf("checkpoint %s doesn't descend from the genesis of this network: %w", b.checkpoint, errFatal)
+	}
+	return recent, nil
+}
@@ -0,0 +1,314 @@
+package statesync
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mh "github.com/multiformats/go-multihash"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	cborutil "github.com/filecoin-project/go-cbor-util"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	incrt "github.com/filecoin-project/lotus/lib/increadtimeout"
+)
+
+const (
+	// FetchParallelism is the number of requests a client has in flight.
+	FetchParallelism = 8
+	// MaxAttempts is the number of times a block is requested before the
+	// fetch fails.
+	MaxAttempts = 10
+)
+
+// ErrNoPeers is returned when no connected peer supports the protocol.
+var ErrNoPeers = xerrors.New("no connected peers support the state sync protocol")
+
+// Client fetches graphs of blocks from peers with the state sync protocol.
+type Client struct {
+	host host.Host
+
+	// peers and send are the network side of the client, replaced in tests
+	peers func() []peer.ID
+	send  func(ctx context.Context, p peer.ID, req *Request) (*Response, error)
+}
+
+// NewClient creates a state sync client.
+func NewClient(h host.Host) *Client {
+	c := &Client{host: h}
+	c.peers = c.connectedPeers
+	c.send = c.sendRequest
+	return c
+}
+
+// Peers returns the connected peers supporting the state sync protocol.
+func (c *Client) Peers() []peer.ID {
+	return c.peers()
+}
+
+func (c *Client) connectedPeers() []peer.ID {
+	var out []peer.ID
+	for _, p := range c.host.Network().Peers() {
+		supported, err := c.host.Peerstore().SupportsProtocols(p, ProtocolID)
+		if err == nil && len(supported) > 0 {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// FetchStats counts the blocks of a fetch.
+type FetchStats struct {
+	// Fetched blocks were requested from peers.
+	Fetched int
+	// Present blocks were already in the blockstore.
+	Present int
+	// Bytes is the size of the fetched blocks.
+	Bytes uint64
+}
+
+// batchResult is the outcome of a request for a batch of blocks.
+type batchResult struct {
+	peer   peer.ID
+	cids   []cid.Cid
+	blocks []blocks.Block
+	err    error
+}
+
+// Fetch fetches the graphs referenced by the roots into the blockstore. Blocks
+// already in the blockstore aren't fetched again, but their links are still
+// followed, so an interrupted fetch can be resumed by fetching the same roots.
+func (c *Client) Fetch(ctx context.Context, bs blockstore.Blockstore, roots ...cid.Cid) (FetchStats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var stats FetchStats
+	seen := cid.NewSet()
+	attempts := map[cid.Cid]int{}
+
+	// blocks left to visit, taken from the end to keep the queue short
+	var queue []cid.Cid
+	push := func(l cid.Cid) {
+		if skipLink(l) || !seen.Visit(l) {
+			return
+		}
+		queue = append(queue, l)
+	}
+	for _, r := range roots {
+		push(r)
+	}
+
+	results := make(chan batchResult, FetchParallelism)
+	var inflight int
+	var next int
+	lastLog := time.Now()
+
+	for len(queue) > 0 || inflight > 0 {
+		for inflight < FetchParallelism && len(queue) > 0 {
+			var batch []cid.Cid
+			for len(queue) > 0 && len(batch) < MaxRequestBlocks {
+				bc := queue[len(queue)-1]
+				queue = queue[:len(queue)-1]
+
+				var links []cid.Cid
+				err := bs.View(ctx, bc, func(data []byte) error {
+					stats.Present++
+					var err error
+					links, err = scanLinks(bc, data)
+					return err
+				})
+				switch {
+				case err == nil:
+					for _, l := range links {
+						push(l)
+					}
+				case ipld.IsNotFound(err):
+					batch = append(batch, bc)
+				default:
+					return stats, xerrors.Errorf("reading %s: %w", bc, err)
+				}
+			}
+			if len(batch) == 0 {
+				continue
+			}
+
+			peers := c.Peers()
+			if len(peers) == 0 {
+				return stats, ErrNoPeers
+			}
+			p := peers[next%len(peers)]
+			next++
+
+			inflight++
+			go func() {
+				blks, err := c.request(ctx, p, batch)
+				results <- batchResult{peer: p, cids: batch, blocks: blks, err: err}
+			}()
+		}
+		if inflight == 0 {
+			continue
+		}
+
+		var res batchResult
+		select {
+		case res = <-results:
+			inflight--
+		case <-ctx.Done():
+			return stats, ctx.Err()
+		}
+
+		if res.err != nil {
+			log.Debugw("state sync request failed", "peer", res.peer, "blocks", len(res.cids), "err", res.err)
+		}
+
+		var got []blocks.Block
+		for i, bc := range res.cids {
+			if res.err == nil && res.blocks[i] != nil {
+				got = append(got, res.blocks[i])
+				continue
+			}
+
+			attempts[bc]++
+			if attempts[bc] >= MaxAttempts {
+				if res.err != nil {
+					return stats, xerrors.Errorf("fetching %s: %w", bc, res.err)
+				}
+				return stats, xerrors.Errorf("block %s not found on any peer after %d attempts", bc, attempts[bc])
+			}
+			queue = append(queue, bc)
+		}
+
+		if err := bs.PutMany(ctx, got); err != nil {
+			return stats, xerrors.Errorf("storing fetched blocks: %w", err)
+		}
+		for _, b := range got {
+			delete(attempts, b.Cid())
+			stats.Fetched++
+			stats.Bytes += uint64(len(b.RawData()))
+
+			links, err := scanLinks(b.Cid(), b.RawData())
+			if err != nil {
+				return stats, err
+			}
+			for _, l := range links {
+				push(l)
+			}
+		}
+
+		if time.Since(lastLog) > 30*time.Second {
+			log.Infow("fetching state", "fetched", stats.Fetched, "present", stats.Present, "bytes", stats.Bytes, "queued", len(queue))
+			lastLog = time.Now()
+		}
+	}
+
+	return stats, nil
+}
+
+// request asks a peer for blocks, and returns them at the index of their CID,
+// nil when the peer didn't send them. Blocks which don't match their CID fail
+// the whole request.
+func (c *Client) request(ctx context.Context, p peer.ID, cids []cid.Cid) ([]blocks.Block, error) {
+	res, err := c.send(ctx, p, &Request{Cids: cids})
+	if err != nil {
+		return nil, err
+	}
+	if err := res.statusToError(); err != nil {
+		return nil, err
+	}
+	return verifyResponse(cids, res)
+}
+
+func (c *Client) sendRequest(ctx context.Context, p peer.ID, req *Request) (*Response, error) {
+	stream, err := c.host.NewStream(
+		network.WithNoDial(ctx, "should already have connection"),
+		p,
+		ProtocolID)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to open stream to peer: %w", err)
+	}
+	defer stream.Close() //nolint:errcheck
+
+	_ = stream.SetWriteDeadline(time.Now().Add(WriteReqDeadline))
+	if err := cborutil.WriteCborRPC(stream, req); err != nil {
+		return nil, xerrors.Errorf("writing request: %w", err)
+	}
+	_ = stream.SetWriteDeadline(time.Time{})
+	if err := stream.CloseWrite(); err != nil {
+		log.Warnw("CloseWrite err", "error", err)
+	}
+
+	var res Response
+	err = cborutil.ReadCborRPC(
+		bufio.NewReader(incrt.New(stream, ReadResMinSpeed, ReadResDeadline)),
+		&res)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to read state sync response: %w", err)
+	}
+	return &res, nil
+}
+
+// verifyResponse checks the blocks of a response against the requested CIDs.
+func verifyResponse(cids []cid.Cid, res *Response) ([]blocks.Block, error) {
+	if len(res.Blocks) != len(cids) {
+		return nil, xerrors.Errorf("response has %d blocks, requested %d", len(res.Blocks), len(cids))
+	}
+
+	out := make([]blocks.Block, len(cids))
+	for i, data := range res.Blocks {
+		if len(data) == 0 {
+			continue
+		}
+
+		c, err := cids[i].Prefix().Sum(data)
+		if err != nil {
+			return nil, xerrors.Errorf("hashing block %s: %w", cids[i], err)
+		}
+		if !c.Equals(cids[i]) {
+			return nil, xerrors.Errorf("block %s doesn't match its cid", cids[i])
+		}
+		out[i], err = blocks.NewBlockWithCid(data, cids[i])
+		if err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// skipLink returns whether a link doesn't point to a block to fetch.
+func skipLink(c cid.Cid) bool {
+	switch {
+	// sector commitments aren't blocks
+	case c.Prefix().Codec == cid.FilCommitmentSealed || c.Prefix().Codec == cid.FilCommitmentUnsealed:
+		return true
+	// identity blocks are inlined in their cid
+	case c.Prefix().MhType == mh.IDENTITY:
+		return true
+	default:
+		return false
+	}
+}
+
+// scanLinks returns the links of a block. Only DAG-CBOR blocks have links in
+// the chain state.
+func scanLinks(c cid.Cid, data []byte) ([]cid.Cid, error) {
+	if c.Prefix().Codec != cid.DagCBOR {
+		return nil, nil
+	}
+
+	var links []cid.Cid
+	if err := cbg.ScanForLinks(bytes.NewReader(data), func(l cid.Cid) {
+		links = append(links, l)
+	}); err != nil {
+		return nil, xerrors.Errorf("scanning links of %s: %w", c, err)
+	}
+	return links, nil
+}
@@ -0,0 +1,16 @@
+// Package statesync contains the state sync server and client components.
+//
+// State sync lets a fresh node fetch the state tree at a recent checkpoint
+// from its peers, instead of importing a chain snapshot obtained out of band.
+// It is an RPC-oriented protocol with a single operation: a request lists the
+// CIDs of up to MaxRequestBlocks blocks, and the response holds the raw data
+// of those blocks the peer has, in the same order, within MaxResponseBytes.
+//
+// The client walks the graphs from their roots (state trees, message receipts
+// and message metadata), requesting the blocks it doesn't have yet in batches.
+// Each block is checked against the hash of its CID before it is stored or its
+// links are followed, so everything fetched is verified against the roots.
+//
+// The Bootstrapper uses the client, with the ChainExchange protocol for block
+// headers, to bring a fresh node to a trusted checkpoint tipset.
+package statesync
@@ -0,0 +1,74 @@
+package statesync
+
+import (
+	"time"
+
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("statesync")
+
+const (
+	// ProtocolID is the protocol ID of the state sync protocol.
+	ProtocolID = "/fil/chain/state/0.0.1"
+)
+
+const (
+	// MaxRequestBlocks is the maximum number of blocks in a request.
+	MaxRequestBlocks = 1024
+	// MaxResponseBytes caps the size of the blocks in a response. The server
+	// stops adding blocks to a response once it is reached.
+	MaxResponseBytes = 8 << 20
+
+	WriteReqDeadline = 5 * time.Second
+	ReadResDeadline  = WriteReqDeadline
+	ReadResMinSpeed  = 50 << 10
+	WriteResDeadline = 60 * time.Second
+)
+
+// Request asks a peer for blocks.
+type Request struct {
+	Cids []cid.Cid
+}
+
+// Response holds the requested blocks, at the index of their CID in the
+// request. Blocks the peer doesn't have, or which didn't fit in the response,
+// are empty.
+type Response struct {
+	Status status
+	// String that complements the error status.
+	ErrorMessage string
+
+	Blocks [][]byte
+}
+
+type status uint64
+
+const (
+	Ok status = 0
+	// Some blocks are missing from the response, they may be requested again
+	// or from another peer.
+	Partial status = 101
+
+	// Errors
+	GoAway        status = 202
+	InternalError status = 203
+	BadRequest    status = 204
+)
+
+func (res *Response) statusToError() error {
+	switch res.Status {
+	case Ok, Partial:
+		return nil
+	case GoAway:
+		return xerrors.Errorf("peer is busy")
+	case InternalError:
+		return xerrors.Errorf("state sync peer errored: %s", res.ErrorMessage)
+	case BadRequest:
+		return xerrors.Errorf("state sync request invalid: %s", res.ErrorMessage)
+	default:
+		return xerrors.Errorf("unrecognized response code: %d", res.Status)
+	}
+}
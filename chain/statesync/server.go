@@ -0,0 +1,124 @@
+package statesync
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	ipld "github.com/ipfs/go-ipld-format"
+	inet "github.com/libp2p/go-libp2p/core/network"
+	"golang.org/x/xerrors"
+
+	cborutil "github.com/filecoin-project/go-cbor-util"
+
+	"github.com/filecoin-project/lotus/blockstore"
+)
+
+// MaxConcurrentRequests is the number of requests a server services at once.
+// Requests over it are answered with GoAway.
+const MaxConcurrentRequests = 8
+
+// Server services requests for the state sync protocol from a blockstore.
+type Server struct {
+	ctx      context.Context
+	bs       blockstore.Blockstore
+	throttle chan struct{}
+}
+
+// NewServer creates a server for the blocks of a blockstore. Requests are
+// serviced until ctx is cancelled.
+func NewServer(ctx context.Context, bs blockstore.Blockstore) *Server {
+	return &Server{
+		ctx:      ctx,
+		bs:       bs,
+		throttle: make(chan struct{}, MaxConcurrentRequests),
+	}
+}
+
+// HandleStream is the protocol handler to be registered on a libp2p protocol
+// router. Streams are single-use: the server reads a single Request, writes
+// a single Response, and closes the stream.
+func (s *Server) HandleStream(stream inet.Stream) {
+	defer stream.Close() //nolint:errcheck
+
+	_ = stream.SetReadDeadline(time.Now().Add(WriteReqDeadline))
+	var req Request
+	if err := cborutil.ReadCborRPC(bufio.NewReader(stream), &req); err != nil {
+		log.Warnf("failed to read state sync request: %s", err)
+		return
+	}
+	_ = stream.SetReadDeadline(time.Time{})
+
+	var resp *Response
+	select {
+	case s.throttle <- struct{}{}:
+		resp = s.processRequest(s.ctx, &req)
+		<-s.throttle
+	default:
+		resp = &Response{Status: GoAway}
+	}
+
+	_ = stream.SetDeadline(time.Now().Add(WriteResDeadline))
+	buffered := bufio.NewWriter(stream)
+	err := cborutil.WriteCborRPC(buffered, resp)
+	if err == nil {
+		err = buffered.Flush()
+	}
+	if err != nil {
+		log.Warnw("failed to write back state sync response",
+			"err", err, "peer", stream.Conn().RemotePeer())
+	}
+	_ = stream.SetDeadline(time.Time{})
+}
+
+func (s *Server) processRequest(ctx context.Context, req *Request) *Response {
+	if len(req.Cids) == 0 {
+		return &Response{
+			Status:       BadRequest,
+			ErrorMessage: "no cids in request",
+		}
+	}
+	if len(req.Cids) > MaxRequestBlocks {
+		return &Response{
+			Status:       BadRequest,
+			ErrorMessage: fmt.Sprintf("request over the maximum of %d blocks", MaxRequestBlocks),
+		}
+	}
+
+	resp := &Response{
+		Status: Ok,
+		Blocks: make([][]byte, len(req.Cids)),
+	}
+	var size int
+	for i, c := range req.Cids {
+		if size >= MaxResponseBytes {
+			resp.Status = Partial
+			break
+		}
+
+		err := s.bs.View(ctx, c, func(data []byte) error {
+			if size+len(data) > MaxResponseBytes && size > 0 {
+				return nil
+			}
+			resp.Blocks[i] = append([]byte(nil), data...)
+			size += len(data)
+			return nil
+		})
+		if ipld.IsNotFound(err) {
+			resp.Status = Partial
+			continue
+		}
+		if err != nil {
+			log.Warnw("state sync request: reading block failed", "cid", c, "err", err)
+			return &Response{
+				Status:       InternalError,
+				ErrorMessage: xerrors.Errorf("reading %s: %w", c, err).Error(),
+			}
+		}
+		if resp.Blocks[i] == nil {
+			resp.Status = Partial
+		}
+	}
+	return resp
+}
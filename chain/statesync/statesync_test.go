@@ -0,0 +1,157 @@
+// stm: #unit
+package statesync
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/blockstore"
+)
+
+// testDag stores a tree with fanout 4 and the given depth, and returns its
+// root and number of blocks.
+func testDag(t *testing.T, bs blockstore.Blockstore, depth int) (cid.Cid, int) {
+	var build func(depth, idx int) (cid.Cid, int)
+	build = func(depth, idx int) (cid.Cid, int) {
+		obj := map[string]interface{}{"depth": depth, "idx": idx}
+		n := 1
+		if depth > 0 {
+			var links []cid.Cid
+			for i := 0; i < 4; i++ {
+				l, ln := build(depth-1, idx*4+i)
+				links = append(links, l)
+				n += ln
+			}
+			obj["links"] = links
+		}
+
+		nd, err := cbor.WrapObject(obj, mh.SHA2_256, -1)
+		require.NoError(t, err)
+		require.NoError(t, bs.Put(context.Background(), nd))
+		return nd.Cid(), n
+	}
+	return build(depth, 0)
+}
+
+// connect returns a client fetching from servers of the blockstores, through
+// the encoding of the protocol.
+func connect(t *testing.T, servers ...blockstore.Blockstore) *Client {
+	handlers := map[peer.ID]*Server{}
+	var peers []peer.ID
+	for i, bs := range servers {
+		p := peer.ID(fmt.Sprintf("peer%d", i))
+		handlers[p] = NewServer(context.Background(), bs)
+		peers = append(peers, p)
+	}
+
+	return &Client{
+		peers: func() []peer.ID { return peers },
+		send: func(ctx context.Context, p peer.ID, req *Request) (*Response, error) {
+			var buf bytes.Buffer
+			require.NoError(t, req.MarshalCBOR(&buf))
+			var sreq Request
+			require.NoError(t, sreq.UnmarshalCBOR(&buf))
+
+			require.NoError(t, handlers[p].processRequest(ctx, &sreq).MarshalCBOR(&buf))
+			var res Response
+			require.NoError(t, res.UnmarshalCBOR(&buf))
+			return &res, nil
+		},
+	}
+}
+
+func TestFetch(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	// the graph is split across two servers
+	full := blockstore.NewMemory()
+	root, n := testDag(t, full, 5)
+
+	half1, half2 := blockstore.NewMemory(), blockstore.NewMemory()
+	var i int
+	for c, b := range full {
+		if i%2 == 0 {
+			half1[c] = b
+		} else {
+			half2[c] = b
+		}
+		i++
+	}
+
+	client := connect(t, half1, half2)
+	require.Len(t, client.Peers(), 2)
+
+	local := blockstore.NewMemory()
+	stats, err := client.Fetch(ctx, local, root)
+	require.NoError(t, err)
+	require.Equal(t, n, stats.Fetched)
+	require.Zero(t, stats.Present)
+	require.Len(t, local, n)
+
+	// resuming visits the blocks already fetched
+	require.NoError(t, local.DeleteBlock(ctx, root))
+	stats, err = client.Fetch(ctx, local, root)
+	require.NoError(t, err)
+	require.Equal(t, 1, stats.Fetched)
+	require.Equal(t, n-1, stats.Present)
+}
+
+func TestFetchMissing(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	bs := blockstore.NewMemory()
+	root, _ := testDag(t, bs, 2)
+
+	nd, err := cbor.WrapObject("missing", mh.SHA2_256, -1)
+	require.NoError(t, err)
+
+	client := connect(t, bs)
+	_, err = client.Fetch(ctx, blockstore.NewMemory(), root, nd.Cid())
+	require.ErrorContains(t, err, "not found on any peer")
+
+	_, err = connect(t).Fetch(ctx, blockstore.NewMemory(), nd.Cid())
+	require.ErrorIs(t, err, ErrNoPeers)
+}
+
+func TestVerifyResponse(t *testing.T) {
+	a := blocks.NewBlock([]byte("a"))
+	b := blocks.NewBlock([]byte("b"))
+	cids := []cid.Cid{a.Cid(), b.Cid()}
+
+	blks, err := verifyResponse(cids, &Response{Blocks: [][]byte{a.RawData(), nil}})
+	require.NoError(t, err)
+	require.Equal(t, a.RawData(), blks[0].RawData())
+	require.Nil(t, blks[1])
+
+	_, err = verifyResponse(cids, &Response{Blocks: [][]byte{a.RawData(), a.RawData()}})
+	require.ErrorContains(t, err, "doesn't match its cid")
+
+	_, err = verifyResponse(cids, &Response{Blocks: [][]byte{a.RawData()}})
+	require.Error(t, err)
+}
+
+func TestServerLimits(t *testing.T) {
+	s := NewServer(context.Background(), blockstore.NewMemory())
+
+	resp := s.processRequest(context.Background(), &Request{})
+	require.Equal(t, BadRequest, resp.Status)
+
+	resp = s.processRequest(context.Background(), &Request{Cids: make([]cid.Cid, MaxRequestBlocks+1)})
+	require.Equal(t, BadRequest, resp.Status)
+
+	resp = s.processRequest(context.Background(), &Request{Cids: []cid.Cid{blocks.NewBlock([]byte("a")).Cid()}})
+	require.Equal(t, Partial, resp.Status)
+	require.Empty(t, resp.Blocks[0])
+}
@@ -0,0 +1,339 @@
+package stmgr
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"go.opencensus.io/trace"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/rand"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
+)
+
+// ActorOverride replaces fields of an actor in the state a bundle is
+// simulated on. Nil fields are left unchanged. Overrides of actors which don't
+// exist in the state are skipped.
+type ActorOverride struct {
+	Balance *abi.TokenAmount
+	Nonce   *uint64
+}
+
+// ActorChange is the change of an actor by a bundle. Before is nil for
+// created actors, and After for deleted ones.
+type ActorChange struct {
+	Before *types.Actor
+	After  *types.Actor
+}
+
+// BundleResult is the result of the simulation of a bundle of messages.
+type BundleResult struct {
+	// Results of the messages, in bundle order
+	Results []*api.InvocResult
+	// Changes of the actors the bundle touched, by ID address, compared to
+	// the state with the overrides applied
+	Changes map[address.Address]ActorChange
+}
+
+// CallBundle applies a bundle of messages in order atop the state of the given tipset, after its
+// messages, like CallWithGas does for a single message. Each message sees the changes of the
+// previous ones, and its nonce is set to the nonce of the sender at that point. Overrides are
+// applied to the state before the bundle. Nothing is persisted to the chain state. The execution
+// limits of the context apply to the bundle as a whole.
+func (sm *StateManager) CallBundle(ctx context.Context, msgs []*types.Message, overrides map[address.Address]ActorOverride, ts *types.TipSet) (*BundleResult, error) {
+	// the result is only read when the bundle completes within the time limit
+	var out *BundleResult
+	_, err := callLimited(ctx, func(ctx context.Context) (*api.InvocResult, error) {
+		res, err := sm.callBundle(ctx, msgs, overrides, ts)
+		out = res
+		return nil, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (sm *StateManager) callBundle(ctx context.Context, msgs []*types.Message, overrides map[address.Address]ActorOverride, ts *types.TipSet) (res *BundleResult, err error) {
+	ctx, span := trace.StartSpan(ctx, "statemanager.callBundle")
+	defer span.End()
+
+	limits := ExecutionLimitsFromContext(ctx)
+
+	if ts.Height() > 0 {
+		pts, err := sm.cs.GetTipSetFromKey(ctx, ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("failed to find a non-forking epoch: %w", err)
+		}
+		if sm.hasExpensiveForkBetween(pts.Height(), ts.Height()+1) {
+			return nil, ErrExpensiveFork
+		}
+	}
+
+	tsMsgs, err := sm.cs.MessagesForTipset(ctx, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to lookup messages for parent tipset: %w", err)
+	}
+	stateCid, err := sm.HandleStateForks(ctx, ts.ParentState(), ts.Height(), nil, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to handle fork: %w", err)
+	}
+
+	var writeStore blockstore.Blockstore = blockstore.NewMemorySync()
+	if limits.MaxMemory > 0 {
+		lbs := newLimitedBlockstore(writeStore, limits.MaxMemory)
+		defer func() {
+			if lbs.exceeded.Load() {
+				res, err = nil, xerrors.Errorf("%w (%d bytes)", ErrExecutionMemoryLimit, limits.MaxMemory)
+			}
+		}()
+		writeStore = lbs
+	}
+	buffStore := blockstore.NewTieredBstore(sm.cs.StateBlockstore(), writeStore)
+	cst := cbor.NewCborStore(buffStore)
+
+	vmopt := &vm.VMOpts{
+		Epoch:          ts.Height(),
+		Timestamp:      ts.MinTimestamp(),
+		Rand:           rand.NewStateRand(sm.cs, ts.Cids(), sm.beacon, sm.GetNetworkVersion),
+		Bstore:         buffStore,
+		Actors:         sm.tsExec.NewActorRegistry(),
+		Syscalls:       sm.Syscalls,
+		CircSupplyCalc: sm.GetVMCirculatingSupply,
+		NetworkVersion: sm.GetNetworkVersion(ctx, ts.Height()),
+		LookbackState:  LookbackStateGetterForTipset(sm, ts),
+		TipSetGetter:   TipSetGetterForTipset(sm.cs, ts),
+		Tracing:        true,
+	}
+	// apply runs messages on a new VM atop stateCid, returning the new state
+	apply := func(baseFee abi.TokenAmount, apply func(vm.Interface) error) (cid.Cid, error) {
+		vmopt.StateBase = stateCid
+		vmopt.BaseFee = baseFee
+		vmi, err := sm.newVM(ctx, vmopt)
+		if err != nil {
+			return cid.Undef, xerrors.Errorf("failed to set up vm: %w", err)
+		}
+		if err := apply(vmi); err != nil {
+			return cid.Undef, err
+		}
+		return vmi.Flush(ctx)
+	}
+
+	stateCid, err = apply(ts.Blocks()[0].ParentBaseFee, func(vmi vm.Interface) error {
+		for i, m := range tsMsgs {
+			if _, err := vmi.ApplyMessage(ctx, m); err != nil {
+				return xerrors.Errorf("applying tipset message (%d, %s): %w", i, m.Cid(), err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(overrides) > 0 {
+		tree, err := state.LoadStateTree(cst, stateCid)
+		if err != nil {
+			return nil, xerrors.Errorf("loading state tree: %w", err)
+		}
+		for addr, o := range overrides {
+			act, err := tree.GetActor(addr)
+			if xerrors.Is(err, types.ErrActorNotFound) {
+				log.Warnw("skipping override of missing actor", "actor", addr)
+				continue
+			}
+			if err != nil {
+				return nil, xerrors.Errorf("overriding actor %s: %w", addr, err)
+			}
+			if o.Balance != nil {
+				act.Balance = *o.Balance
+			}
+			if o.Nonce != nil {
+				act.Nonce = *o.Nonce
+			}
+			if err := tree.SetActor(addr, act); err != nil {
+				return nil, xerrors.Errorf("overriding actor %s: %w", addr, err)
+			}
+		}
+		if stateCid, err = tree.Flush(ctx); err != nil {
+			return nil, xerrors.Errorf("flushing overrides: %w", err)
+		}
+	}
+	baseState := stateCid
+
+	res = &BundleResult{Changes: map[address.Address]ActorChange{}}
+	touched := map[address.Address]struct{}{}
+	for i, m := range msgs {
+		msg := *m
+		if msg.GasLimit == 0 {
+			msg.GasLimit = build.BlockGasLimit
+		}
+		if limits.MaxGas > 0 && msg.GasLimit > limits.MaxGas {
+			msg.GasLimit = limits.MaxGas
+		}
+		if msg.GasFeeCap == types.EmptyInt {
+			msg.GasFeeCap = big.Zero()
+		}
+		if msg.GasPremium == types.EmptyInt {
+			msg.GasPremium = big.Zero()
+		}
+		if msg.Value == types.EmptyInt {
+			msg.Value = big.Zero()
+		}
+
+		tree, err := state.LoadStateTree(cst, stateCid)
+		if err != nil {
+			return nil, xerrors.Errorf("loading state tree: %w", err)
+		}
+		from, err := tree.GetActor(msg.From)
+		if err != nil {
+			return nil, xerrors.Errorf("message %d: getting sender: %w", i, err)
+		}
+		msg.Nonce = from.Nonce
+
+		applied := bundleChainMsg(&msg, from)
+
+		// as for calls, gas is free when the fee cap is zero
+		baseFee := ts.Blocks()[0].ParentBaseFee
+		if msg.GasFeeCap.NilOrZero() {
+			baseFee = big.Zero()
+		}
+
+		var ret *vm.ApplyRet
+		stateCid, err = apply(baseFee, func(vmi vm.Interface) error {
+			ret, err = vmi.ApplyMessage(ctx, applied)
+			return err
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("applying message %d: %w", i, err)
+		}
+		if limits.MaxCallDepth > 0 && callDepth(ret.ExecutionTrace) > limits.MaxCallDepth {
+			return nil, newErrExecutionLimit(ctx, LimitCallDepth, int64(limits.MaxCallDepth))
+		}
+
+		var errs string
+		if ret.ActorErr != nil {
+			errs = ret.ActorErr.Error()
+		}
+		res.Results = append(res.Results, &api.InvocResult{
+			MsgCid:         msg.Cid(),
+			Msg:            &msg,
+			MsgRct:         &ret.MessageReceipt,
+			GasCost:        MakeMsgGasCost(&msg, ret),
+			ExecutionTrace: ret.ExecutionTrace,
+			Error:          errs,
+			Duration:       ret.Duration,
+		})
+
+		touched[msg.From] = struct{}{}
+		traceActors(ret.ExecutionTrace, touched)
+	}
+
+	if err := bundleChanges(cst, baseState, stateCid, touched, res.Changes); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// bundleChainMsg returns the message to apply for msg, with an empty
+// signature of the type of the sender's key.
+func bundleChainMsg(msg *types.Message, from *types.Actor) types.ChainMsg {
+	key := msg.From
+	if key.Protocol() == address.ID {
+		if from.Address == nil {
+			return msg
+		}
+		key = *from.Address
+	}
+
+	switch key.Protocol() {
+	case address.SECP256K1:
+		return &types.SignedMessage{
+			Message:   *msg,
+			Signature: crypto.Signature{Type: crypto.SigTypeSecp256k1, Data: make([]byte, 65)},
+		}
+	case address.Delegated:
+		return &types.SignedMessage{
+			Message:   *msg,
+			Signature: crypto.Signature{Type: crypto.SigTypeDelegated, Data: make([]byte, 65)},
+		}
+	default:
+		return msg
+	}
+}
+
+// traceActors adds the actors called in an execution trace to touched
+func traceActors(et types.ExecutionTrace, touched map[address.Address]struct{}) {
+	touched[et.Msg.From] = struct{}{}
+	touched[et.Msg.To] = struct{}{}
+	for _, sub := range et.Subcalls {
+		traceActors(sub, touched)
+	}
+}
+
+// bundleChanges compares the touched actors between two states
+func bundleChanges(cst cbor.IpldStore, before, after cid.Cid, touched map[address.Address]struct{}, out map[address.Address]ActorChange) error {
+	oldTree, err := state.LoadStateTree(cst, before)
+	if err != nil {
+		return xerrors.Errorf("loading state tree: %w", err)
+	}
+	newTree, err := state.LoadStateTree(cst, after)
+	if err != nil {
+		return xerrors.Errorf("loading state tree: %w", err)
+	}
+
+	getActor := func(tree *state.StateTree, addr address.Address) (*types.Actor, error) {
+		act, err := tree.GetActor(addr)
+		if xerrors.Is(err, types.ErrActorNotFound) {
+			return nil, nil
+		}
+		return act, err
+	}
+
+	for addr := range touched {
+		id, err := newTree.LookupID(addr)
+		if err != nil {
+			if id, err = oldTree.LookupID(addr); err != nil {
+				continue // neither created nor existing, e.g. a failed send
+			}
+		}
+		if _, ok := out[id]; ok {
+			continue
+		}
+
+		b, err := getActor(oldTree, id)
+		if err != nil {
+			return xerrors.Errorf("getting actor %s: %w", id, err)
+		}
+		a, err := getActor(newTree, id)
+		if err != nil {
+			return xerrors.Errorf("getting actor %s: %w", id, err)
+		}
+		if actorEqual(b, a) {
+			continue
+		}
+		out[id] = ActorChange{Before: b, After: a}
+	}
+	return nil
+}
+
+func actorEqual(a, b *types.Actor) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	if (a.Address == nil) != (b.Address == nil) || (a.Address != nil && *a.Address != *b.Address) {
+		return false
+	}
+	return a.Code == b.Code && a.Head == b.Head && a.Nonce == b.Nonce && a.Balance.Equals(b.Balance)
+}
@@ -0,0 +1,61 @@
+// stm: #unit
+package stmgr
+
+import (
+	"context"
+	"testing"
+
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestBundleChanges(t *testing.T) {
+	ctx := context.Background()
+	cst := cbor.NewCborStore(blockstore.NewMemory())
+
+	st, err := state.NewStateTree(cst, types.StateTreeVersion5)
+	require.NoError(t, err)
+	code, err := cst.Put(ctx, "code")
+	require.NoError(t, err)
+
+	id := func(n uint64) address.Address {
+		addr, err := address.NewIDAddress(n)
+		require.NoError(t, err)
+		return addr
+	}
+	setActor := func(addr address.Address, balance, nonce uint64) {
+		require.NoError(t, st.SetActor(addr, &types.Actor{Code: code, Head: code, Balance: types.NewInt(balance), Nonce: nonce}))
+	}
+
+	setActor(id(1000), 10, 0)
+	setActor(id(1001), 10, 0)
+	setActor(id(1002), 10, 0)
+	before, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	setActor(id(1000), 5, 1)
+	setActor(id(1003), 5, 0)
+	require.NoError(t, st.DeleteActor(id(1002)))
+	after, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	touched := map[address.Address]struct{}{
+		id(1000): {}, id(1001): {}, id(1002): {}, id(1003): {},
+		id(1004): {}, // never existed
+	}
+	out := map[address.Address]ActorChange{}
+	require.NoError(t, bundleChanges(cst, before, after, touched, out))
+
+	require.Len(t, out, 3)
+	require.Equal(t, types.NewInt(10), out[id(1000)].Before.Balance)
+	require.Equal(t, types.NewInt(5), out[id(1000)].After.Balance)
+	require.Equal(t, uint64(1), out[id(1000)].After.Nonce)
+	require.Nil(t, out[id(1002)].After)
+	require.Nil(t, out[id(1003)].Before)
+}
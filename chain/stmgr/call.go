@@ -23,6 +23,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/state"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/vm"
+	"github.com/filecoin-project/lotus/lib/calltiming"
 )
 
 var ErrExpensiveFork = errors.New("refusing explicit call due to state fork at epoch")
@@ -48,12 +49,16 @@ func (sm *StateManager) Call(ctx context.Context, msg *types.Message, ts *types.
 		msg.Value = types.NewInt(0)
 	}
 
-	return sm.callInternal(ctx, msg, nil, ts, cid.Undef, sm.GetNetworkVersion, false, false)
+	return callLimited(ctx, func(ctx context.Context) (*api.InvocResult, error) {
+		return sm.callInternal(ctx, msg, nil, ts, cid.Undef, sm.GetNetworkVersion, false, false)
+	})
 }
 
 // CallWithGas calculates the state for a given tipset, and then applies the given message on top of that state.
 func (sm *StateManager) CallWithGas(ctx context.Context, msg *types.Message, priorMsgs []types.ChainMsg, ts *types.TipSet, applyTsMessages bool) (*api.InvocResult, error) {
-	return sm.callInternal(ctx, msg, priorMsgs, ts, cid.Undef, sm.GetNetworkVersion, true, applyTsMessages)
+	return callLimited(ctx, func(ctx context.Context) (*api.InvocResult, error) {
+		return sm.callInternal(ctx, msg, priorMsgs, ts, cid.Undef, sm.GetNetworkVersion, true, applyTsMessages)
+	})
 }
 
 // CallAtStateAndVersion allows you to specify a message to execute on the given stateCid and network version.
@@ -65,13 +70,16 @@ func (sm *StateManager) CallAtStateAndVersion(ctx context.Context, msg *types.Me
 		return v
 	}
 
-	return sm.callInternal(ctx, msg, nil, nil, stateCid, nvGetter, true, false)
+	return callLimited(ctx, func(ctx context.Context) (*api.InvocResult, error) {
+		return sm.callInternal(ctx, msg, nil, nil, stateCid, nvGetter, true, false)
+	})
 }
 
 //   - If no tipset is specified, the first tipset without an expensive migration or one in its parent is used.
 //   - If executing a message at a given tipset or its parent would trigger an expensive migration, the call will
 //     fail with ErrExpensiveFork.
-func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, priorMsgs []types.ChainMsg, ts *types.TipSet, stateCid cid.Cid, nvGetter rand.NetworkVersionGetter, checkGas, applyTsMessages bool) (*api.InvocResult, error) {
+//   - The gas and memory limits of the ExecutionLimits in ctx are applied.
+func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, priorMsgs []types.ChainMsg, ts *types.TipSet, stateCid cid.Cid, nvGetter rand.NetworkVersionGetter, checkGas, applyTsMessages bool) (res *api.InvocResult, err error) {
 	ctx, span := trace.StartSpan(ctx, "statemanager.callInternal")
 	defer span.End()
 
@@ -79,7 +87,13 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 	msgCopy := *msg
 	msg = &msgCopy
 
-	var err error
+	limits := ExecutionLimitsFromContext(ctx)
+	if limits.MaxGas > 0 && msg.GasLimit > limits.MaxGas {
+		msg.GasLimit = limits.MaxGas
+	}
+
+	endPrepare := calltiming.Start(ctx, "call-prepare")
+
 	var pts *types.TipSet
 	if ts == nil {
 		ts = sm.cs.GetHeaviestTipSet()
@@ -140,6 +154,7 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 	if err != nil {
 		return nil, fmt.Errorf("failed to handle fork: %w", err)
 	}
+	endPrepare()
 
 	if span.IsRecordingEvents() {
 		span.AddAttributes(
@@ -149,13 +164,40 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 		)
 	}
 
-	buffStore := blockstore.NewTieredBstore(sm.cs.StateBlockstore(), blockstore.NewMemorySync())
+	var writeStore blockstore.Blockstore = blockstore.NewMemorySync()
+	if limits.MaxMemory > 0 {
+		lbs := newLimitedBlockstore(writeStore, limits.MaxMemory)
+		// execution may fail in many ways once writes are refused, report the
+		// limit instead
+		defer func() {
+			if lbs.exceeded.Load() {
+				res, err = nil, xerrors.Errorf("%w (%d bytes)", ErrExecutionMemoryLimit, limits.MaxMemory)
+			}
+		}()
+		writeStore = lbs
+	}
+	buffStore := blockstore.NewTieredBstore(sm.cs.StateBlockstore(), writeStore)
+	var vmStore blockstore.Blockstore = buffStore
+	if limits.MaxIpldReads > 0 {
+		rbs := newReadLimitedBlockstore(buffStore, limits.MaxIpldReads)
+		// the FVM reports failed reads as fatal errors, report the limit
+		// instead
+		defer func() {
+			if rbs.exceeded.Load() {
+				res, err = nil, newErrExecutionLimit(ctx, LimitIpldReads, limits.MaxIpldReads)
+			}
+		}()
+		vmStore = rbs
+	}
+	if limits.Timeout > 0 {
+		vmStore = &cancelableBlockstore{Blockstore: vmStore, ctx: ctx}
+	}
 	vmopt := &vm.VMOpts{
 		StateBase:      stateCid,
 		Epoch:          ts.Height(),
 		Timestamp:      ts.MinTimestamp(),
 		Rand:           rand.NewStateRand(sm.cs, ts.Cids(), sm.beacon, nvGetter),
-		Bstore:         buffStore,
+		Bstore:         vmStore,
 		Actors:         sm.tsExec.NewActorRegistry(),
 		Syscalls:       sm.Syscalls,
 		CircSupplyCalc: sm.GetVMCirculatingSupply,
@@ -164,11 +206,14 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 		LookbackState:  LookbackStateGetterForTipset(sm, ts),
 		TipSetGetter:   TipSetGetterForTipset(sm.cs, ts),
 		Tracing:        true,
+		MaxCallDepth:   limits.MaxCallDepth,
+		CountSyscalls:  true,
 	}
 	vmi, err := sm.newVM(ctx, vmopt)
 	if err != nil {
 		return nil, xerrors.Errorf("failed to set up vm: %w", err)
 	}
+	endPrior := calltiming.Start(ctx, "call-apply-prior")
 	for i, m := range priorMsgs {
 		_, err = vmi.ApplyMessage(ctx, m)
 		if err != nil {
@@ -182,6 +227,7 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 	if err != nil {
 		return nil, xerrors.Errorf("flushing vm: %w", err)
 	}
+	endPrior()
 
 	stTree, err := state.LoadStateTree(cbor.NewCborStore(buffStore), stateCid)
 	if err != nil {
@@ -207,6 +253,9 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 		}
 	}
 
+	endExecute := calltiming.Start(ctx, "call-execute")
+	defer endExecute()
+
 	var ret *vm.ApplyRet
 	var gasInfo api.MsgGasCost
 	if checkGas {
@@ -250,6 +299,10 @@ func (sm *StateManager) callInternal(ctx context.Context, msg *types.Message, pr
 		}
 	}
 
+	if limits.MaxCallDepth > 0 && callDepth(ret.ExecutionTrace) > limits.MaxCallDepth {
+		return nil, newErrExecutionLimit(ctx, LimitCallDepth, int64(limits.MaxCallDepth))
+	}
+
 	var errs string
 	if ret.ActorErr != nil {
 		errs = ret.ActorErr.Error()
@@ -0,0 +1,243 @@
+package stmgr
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"sync/atomic"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
+)
+
+var (
+	ErrExecutionTimeout     = errors.New("call exceeded the execution time limit")
+	ErrExecutionMemoryLimit = errors.New("call exceeded the execution memory limit")
+)
+
+// ExecutionLimits bound the resources used by explicit calls (Call,
+// CallWithGas and CallAtStateAndVersion), which back eth_call,
+// eth_estimateGas and StateCall. Limits are carried in the call context, so
+// that the API server can apply different limits to different clients. Zero
+// values mean no limit.
+type ExecutionLimits struct {
+	// Timeout is the maximum wall-clock time of a call. The FVM can't be
+	// interrupted, so a call which times out returns an error right away, and
+	// is aborted on its next state read. Until it is, it holds one of the
+	// limited slots of timed calls.
+	Timeout time.Duration
+	// MaxGas caps the gas limit of the called message.
+	MaxGas int64
+	// MaxMemory is the maximum number of bytes of state a call may buffer in
+	// memory, on top of the chain state it executes on.
+	MaxMemory uint64
+	// MaxIpldReads is the maximum number of state blocks a call may read. The
+	// call is aborted on the first read over the limit.
+	MaxIpldReads int64
+	// MaxCallDepth is the maximum depth of nested actor calls of a call. The
+	// legacy VM aborts the calls over the limit as they are entered; the FVM
+	// can't be interrupted between actor calls, so the depth is also checked
+	// on the execution trace once the call completes.
+	MaxCallDepth int
+}
+
+func (l ExecutionLimits) IsZero() bool {
+	return l == ExecutionLimits{}
+}
+
+type executionLimitsKey struct{}
+
+// WithExecutionLimits returns a context applying the given limits to explicit
+// calls made with it.
+func WithExecutionLimits(ctx context.Context, l ExecutionLimits) context.Context {
+	return context.WithValue(ctx, executionLimitsKey{}, l)
+}
+
+// ExecutionLimitsFromContext returns the limits set with WithExecutionLimits,
+// or no limits.
+func ExecutionLimitsFromContext(ctx context.Context) ExecutionLimits {
+	l, _ := ctx.Value(executionLimitsKey{}).(ExecutionLimits)
+	return l
+}
+
+// Names of the limits in api.ErrExecutionLimit errors.
+const (
+	LimitIpldReads = "ipld_reads"
+	LimitCallDepth = "call_depth"
+)
+
+func newErrExecutionLimit(ctx context.Context, limit string, max int64) *api.ErrExecutionLimit {
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(metrics.ExecutionLimit, limit)}, metrics.VMExecutionLimitExceeded.M(1))
+	return &api.ErrExecutionLimit{Limit: limit, Max: max}
+}
+
+// callDepth returns the depth of the deepest nested actor call of a trace,
+// the call of the message itself being at depth 1.
+func callDepth(et types.ExecutionTrace) int {
+	depth := 0
+	for _, sub := range et.Subcalls {
+		if d := callDepth(sub); d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
+
+// timedCallSlots bounds the number of calls with a time limit executing at
+// once, including those which timed out but are still running, so that
+// repeated timeouts can't pile up abandoned executions.
+var timedCallSlots = make(chan struct{}, 2*runtime.NumCPU())
+
+// callLimited runs call, returning ErrExecutionTimeout if it doesn't complete
+// within the time limit. The context of call is cancelled on timeout, which
+// aborts the execution on its next state read, see cancelableBlockstore.
+func callLimited(ctx context.Context, call func(ctx context.Context) (*api.InvocResult, error)) (*api.InvocResult, error) {
+	limits := ExecutionLimitsFromContext(ctx)
+	if limits.Timeout <= 0 {
+		return call(ctx)
+	}
+
+	timer := time.NewTimer(limits.Timeout)
+	defer timer.Stop()
+
+	slots := timedCallSlots
+	select {
+	case slots <- struct{}{}:
+	case <-timer.C:
+		return nil, xerrors.Errorf("%w (%s, waiting for an execution slot)", ErrExecutionTimeout, limits.Timeout)
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	type result struct {
+		res *api.InvocResult
+		err error
+	}
+	done := make(chan result, 1)
+	cctx, cancel := context.WithCancel(ctx)
+	go func() {
+		defer func() { <-slots }()
+		res, err := call(cctx)
+		done <- result{res, err}
+	}()
+
+	select {
+	case r := <-done:
+		cancel()
+		return r.res, r.err
+	case <-timer.C:
+		cancel()
+		return nil, xerrors.Errorf("%w (%s)", ErrExecutionTimeout, limits.Timeout)
+	case <-ctx.Done():
+		cancel()
+		return nil, ctx.Err()
+	}
+}
+
+// cancelableBlockstore fails reads once its context is done, so that calls
+// which timed out stop executing.
+type cancelableBlockstore struct {
+	blockstore.Blockstore
+
+	ctx context.Context
+}
+
+func (bs *cancelableBlockstore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	if err := bs.ctx.Err(); err != nil {
+		return nil, err
+	}
+	return bs.Blockstore.Get(ctx, c)
+}
+
+func (bs *cancelableBlockstore) View(ctx context.Context, c cid.Cid, cb func([]byte) error) error {
+	if err := bs.ctx.Err(); err != nil {
+		return err
+	}
+	return bs.Blockstore.View(ctx, c, cb)
+}
+
+// limitedBlockstore fails writes once more than limit bytes were written to
+// it. Blocks are counted every time they are written, as the state written by
+// a call is mostly new.
+type limitedBlockstore struct {
+	blockstore.Blockstore
+
+	limit    uint64
+	written  atomic.Uint64
+	exceeded atomic.Bool
+}
+
+func newLimitedBlockstore(bs blockstore.Blockstore, limit uint64) *limitedBlockstore {
+	return &limitedBlockstore{Blockstore: bs, limit: limit}
+}
+
+func (bs *limitedBlockstore) reserve(n int) error {
+	if bs.written.Add(uint64(n)) > bs.limit {
+		bs.exceeded.Store(true)
+		return xerrors.Errorf("%w (%d bytes)", ErrExecutionMemoryLimit, bs.limit)
+	}
+	return nil
+}
+
+func (bs *limitedBlockstore) Put(ctx context.Context, b blocks.Block) error {
+	if err := bs.reserve(len(b.RawData())); err != nil {
+		return err
+	}
+	return bs.Blockstore.Put(ctx, b)
+}
+
+func (bs *limitedBlockstore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	var n int
+	for _, b := range blks {
+		n += len(b.RawData())
+	}
+	if err := bs.reserve(n); err != nil {
+		return err
+	}
+	return bs.Blockstore.PutMany(ctx, blks)
+}
+
+// readLimitedBlockstore fails reads once more than limit blocks were read
+// from it.
+type readLimitedBlockstore struct {
+	blockstore.Blockstore
+
+	limit    int64
+	reads    atomic.Int64
+	exceeded atomic.Bool
+}
+
+func newReadLimitedBlockstore(bs blockstore.Blockstore, limit int64) *readLimitedBlockstore {
+	return &readLimitedBlockstore{Blockstore: bs, limit: limit}
+}
+
+func (bs *readLimitedBlockstore) read() error {
+	if bs.reads.Add(1) > bs.limit {
+		bs.exceeded.Store(true)
+		return &api.ErrExecutionLimit{Limit: LimitIpldReads, Max: bs.limit}
+	}
+	return nil
+}
+
+func (bs *readLimitedBlockstore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	if err := bs.read(); err != nil {
+		return nil, err
+	}
+	return bs.Blockstore.Get(ctx, c)
+}
+
+func (bs *readLimitedBlockstore) View(ctx context.Context, c cid.Cid, cb func([]byte) error) error {
+	if err := bs.read(); err != nil {
+		return err
+	}
+	return bs.Blockstore.View(ctx, c, cb)
+}
@@ -0,0 +1,135 @@
+// stm: #unit
+package stmgr
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestCallLimited(t *testing.T) {
+	ctx := context.Background()
+	require.True(t, ExecutionLimitsFromContext(ctx).IsZero())
+
+	res := &api.InvocResult{Error: "ok"}
+	fast := func(context.Context) (*api.InvocResult, error) { return res, nil }
+
+	release := make(chan struct{})
+	defer close(release)
+	slow := func(context.Context) (*api.InvocResult, error) {
+		<-release
+		return res, nil
+	}
+
+	// no limit
+	out, err := callLimited(ctx, fast)
+	require.NoError(t, err)
+	require.Equal(t, res, out)
+
+	lctx := WithExecutionLimits(ctx, ExecutionLimits{Timeout: 10 * time.Millisecond})
+	require.Equal(t, 10*time.Millisecond, ExecutionLimitsFromContext(lctx).Timeout)
+
+	out, err = callLimited(lctx, fast)
+	require.NoError(t, err)
+	require.Equal(t, res, out)
+
+	_, err = callLimited(lctx, slow)
+	require.True(t, xerrors.Is(err, ErrExecutionTimeout), err)
+
+	// calls which timed out hold their slot until they complete
+	slots := timedCallSlots
+	timedCallSlots = make(chan struct{}, 1)
+	defer func() { timedCallSlots = slots }()
+
+	finish := make(chan struct{})
+	cancelled := make(chan error, 1)
+	_, err = callLimited(lctx, func(ctx context.Context) (*api.InvocResult, error) {
+		<-ctx.Done()
+		cancelled <- ctx.Err()
+		<-finish
+		return res, nil
+	})
+	require.True(t, xerrors.Is(err, ErrExecutionTimeout), err)
+	require.Error(t, <-cancelled)
+
+	_, err = callLimited(lctx, fast)
+	require.True(t, xerrors.Is(err, ErrExecutionTimeout), err)
+
+	close(finish)
+	require.Eventually(t, func() bool {
+		_, err := callLimited(lctx, fast)
+		return err == nil
+	}, time.Second, time.Millisecond)
+}
+
+func TestCancelableBlockstore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	mem := blockstore.NewMemorySync()
+	b := blocks.NewBlock([]byte("hello"))
+	require.NoError(t, mem.Put(ctx, b))
+
+	bs := &cancelableBlockstore{Blockstore: mem, ctx: ctx}
+	_, err := bs.Get(ctx, b.Cid())
+	require.NoError(t, err)
+
+	cancel()
+	_, err = bs.Get(context.Background(), b.Cid())
+	require.ErrorIs(t, err, context.Canceled)
+	require.ErrorIs(t, bs.View(context.Background(), b.Cid(), func([]byte) error { return nil }), context.Canceled)
+}
+
+func TestLimitedBlockstore(t *testing.T) {
+	ctx := context.Background()
+	bs := newLimitedBlockstore(blockstore.NewMemorySync(), 10)
+
+	require.NoError(t, bs.Put(ctx, blocks.NewBlock([]byte("hello"))))
+	require.NoError(t, bs.PutMany(ctx, []blocks.Block{blocks.NewBlock([]byte("ab")), blocks.NewBlock([]byte("cd"))}))
+	require.False(t, bs.exceeded.Load())
+
+	b := blocks.NewBlock([]byte("world"))
+	err := bs.Put(ctx, b)
+	require.True(t, xerrors.Is(err, ErrExecutionMemoryLimit), err)
+	require.True(t, bs.exceeded.Load())
+
+	has, err := bs.Has(ctx, b.Cid())
+	require.NoError(t, err)
+	require.False(t, has)
+}
+
+func TestReadLimitedBlockstore(t *testing.T) {
+	ctx := context.Background()
+	mem := blockstore.NewMemorySync()
+	b := blocks.NewBlock([]byte("hello"))
+	require.NoError(t, mem.Put(ctx, b))
+
+	bs := newReadLimitedBlockstore(mem, 2)
+
+	_, err := bs.Get(ctx, b.Cid())
+	require.NoError(t, err)
+	require.NoError(t, bs.View(ctx, b.Cid(), func([]byte) error { return nil }))
+	require.False(t, bs.exceeded.Load())
+
+	_, err = bs.Get(ctx, b.Cid())
+	var limitErr *api.ErrExecutionLimit
+	require.True(t, xerrors.As(err, &limitErr), err)
+	require.Equal(t, &api.ErrExecutionLimit{Limit: LimitIpldReads, Max: 2}, limitErr)
+	require.True(t, bs.exceeded.Load())
+}
+
+func TestCallDepth(t *testing.T) {
+	require.Equal(t, 1, callDepth(types.ExecutionTrace{}))
+	require.Equal(t, 3, callDepth(types.ExecutionTrace{
+		Subcalls: []types.ExecutionTrace{
+			{},
+			{Subcalls: []types.ExecutionTrace{{}}},
+		},
+	}))
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
 )
 
 func (sm *StateManager) TipSetState(ctx context.Context, ts *types.TipSet) (st cid.Cid, rec cid.Cid, err error) {
@@ -69,7 +70,12 @@ func (sm *StateManager) TipSetState(ctx context.Context, ts *types.TipSet) (st c
 		return st, rec, nil
 	}
 
-	st, rec, err = sm.tsExec.ExecuteTipSet(ctx, sm, ts, sm.tsExecMonitor, false)
+	em, vmTracing := sm.tsExecMonitor, false
+	if tracer := sm.tipSetTracer(); tracer != nil && tracer.Sampled(ts) {
+		em, vmTracing = multiMonitor{sm.tsExecMonitor, tracer}, true
+	}
+
+	st, rec, err = sm.tsExec.ExecuteTipSet(ctx, sm, ts, em, vmTracing)
 	if err != nil {
 		return cid.Undef, cid.Undef, err
 	}
@@ -123,6 +129,8 @@ func tryLookupTipsetState(ctx context.Context, cs *store.ChainStore, ts *types.T
 }
 
 func (sm *StateManager) ExecutionTraceWithMonitor(ctx context.Context, ts *types.TipSet, em ExecMonitor) (cid.Cid, error) {
+	// traces are computed for RPC clients, keep out of the lanes reserved for validation
+	ctx = vm.WithExecutionLane(ctx, vm.ExecutionLaneDefault)
 	st, _, err := sm.tsExec.ExecuteTipSet(ctx, sm, ts, em, true)
 	return st, err
 }
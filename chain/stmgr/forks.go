@@ -173,6 +173,20 @@ func (us UpgradeSchedule) GetNtwkVersion(e abi.ChainEpoch) (network.Version, err
 }
 
 func (sm *StateManager) HandleStateForks(ctx context.Context, root cid.Cid, height abi.ChainEpoch, cb ExecMonitor, ts *types.TipSet) (cid.Cid, error) {
+	retCid, err := sm.runStateMigration(ctx, root, height, cb, ts)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	retCid, err = sm.applyStatePatches(ctx, retCid, height)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("applying state patches: %w", err)
+	}
+
+	return retCid, nil
+}
+
+func (sm *StateManager) runStateMigration(ctx context.Context, root cid.Cid, height abi.ChainEpoch, cb ExecMonitor, ts *types.TipSet) (cid.Cid, error) {
 	retCid := root
 	u := sm.stateMigrations[height]
 	if u != nil && u.upgrade != nil {
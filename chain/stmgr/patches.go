@@ -0,0 +1,71 @@
+package stmgr
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/state"
+)
+
+// StatePatch modifies the state tree outside of message execution.
+type StatePatch func(ctx context.Context, st *state.StateTree) error
+
+// AddStatePatch registers a patch applied to the state after the given
+// height, the same way state migrations are: when executing the first tipset
+// after that height, before its messages, right after any state migration.
+// Patches break consensus with any node not applying them, they are only
+// meant for local devnets. They are applied to any chain going through the
+// height, and stay registered so that recomputing the state gives the same
+// result; the patched state is cached, so that the patches run once for each
+// state they are applied to.
+func (sm *StateManager) AddStatePatch(height abi.ChainEpoch, patch StatePatch) {
+	sm.statePatchesLk.Lock()
+	defer sm.statePatchesLk.Unlock()
+
+	if sm.statePatches == nil {
+		sm.statePatches = make(map[abi.ChainEpoch][]StatePatch)
+		sm.patchedStates = make(map[abi.ChainEpoch]map[cid.Cid]cid.Cid)
+	}
+	sm.statePatches[height] = append(sm.statePatches[height], patch)
+	// the patched states don't include the new patch
+	delete(sm.patchedStates, height)
+}
+
+func (sm *StateManager) applyStatePatches(ctx context.Context, root cid.Cid, height abi.ChainEpoch) (cid.Cid, error) {
+	sm.statePatchesLk.Lock()
+	defer sm.statePatchesLk.Unlock()
+
+	patches := sm.statePatches[height]
+	if len(patches) == 0 {
+		return root, nil
+	}
+	if patched, ok := sm.patchedStates[height][root]; ok {
+		return patched, nil
+	}
+
+	st, err := sm.StateTree(root)
+	if err != nil {
+		return cid.Undef, err
+	}
+	for i, patch := range patches {
+		if err := patch(ctx, st); err != nil {
+			return cid.Undef, xerrors.Errorf("applying state patch %d at height %d: %w", i, height, err)
+		}
+	}
+	patched, err := st.Flush(ctx)
+	if err != nil {
+		return cid.Undef, err
+	}
+
+	if sm.patchedStates[height] == nil {
+		sm.patchedStates[height] = make(map[cid.Cid]cid.Cid)
+	}
+	sm.patchedStates[height][root] = patched
+
+	log.Warnw("applied state patches", "height", height, "count", len(patches), "from", root, "to", patched)
+	return patched, nil
+}
@@ -0,0 +1,80 @@
+// stm: #unit
+package stmgr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestStatePatches(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	sm := &StateManager{cs: store.NewChainStore(bs, bs, datastore.NewMapDatastore(), nil, nil)}
+
+	cst := cbor.NewCborStore(bs)
+	st, err := state.NewStateTree(cst, types.StateTreeVersion5)
+	require.NoError(t, err)
+	addr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	code, err := cst.Put(ctx, "code")
+	require.NoError(t, err)
+	require.NoError(t, st.SetActor(addr, &types.Actor{Code: code, Head: code, Balance: types.NewInt(1)}))
+	root, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	runs := 0
+	setBalance := func(b uint64) StatePatch {
+		return func(ctx context.Context, st *state.StateTree) error {
+			runs++
+			act, err := st.GetActor(addr)
+			if err != nil {
+				return err
+			}
+			act.Balance = types.NewInt(b)
+			return st.SetActor(addr, act)
+		}
+	}
+	sm.AddStatePatch(10, setBalance(5))
+	sm.AddStatePatch(10, setBalance(7))
+
+	// no patches at this height
+	out, err := sm.applyStatePatches(ctx, root, 9)
+	require.NoError(t, err)
+	require.Equal(t, root, out)
+
+	balance := func(root cid.Cid) types.BigInt {
+		st, err := sm.StateTree(root)
+		require.NoError(t, err)
+		act, err := st.GetActor(addr)
+		require.NoError(t, err)
+		return act.Balance
+	}
+
+	// patches are applied in order, once, and stay registered
+	for i := 0; i < 2; i++ {
+		out, err = sm.applyStatePatches(ctx, root, 10)
+		require.NoError(t, err)
+		require.NotEqual(t, root, out)
+		require.Equal(t, types.NewInt(7), balance(out))
+		require.Equal(t, 2, runs)
+	}
+
+	// a new patch invalidates the patched states
+	sm.AddStatePatch(10, setBalance(9))
+	out, err = sm.applyStatePatches(ctx, root, 10)
+	require.NoError(t, err)
+	require.Equal(t, types.NewInt(9), balance(out))
+	require.Equal(t, 5, runs)
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"sync/atomic"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/ipfs/go-cid"
@@ -135,6 +136,12 @@ type StateManager struct {
 	// ErrExpensiveFork.
 	expensiveUpgrades map[abi.ChainEpoch]struct{}
 
+	// Maps chain epochs to state patches of local devnets, and the states
+	// they were applied to to the patched states.
+	statePatches   map[abi.ChainEpoch][]StatePatch
+	patchedStates  map[abi.ChainEpoch]map[cid.Cid]cid.Cid
+	statePatchesLk sync.Mutex
+
 	stCache             map[string][]cid.Cid
 	tCache              treeCache
 	compWait            map[string]chan struct{}
@@ -150,6 +157,7 @@ type StateManager struct {
 
 	tsExec        Executor
 	tsExecMonitor ExecMonitor
+	tsTracer      atomic.Pointer[TipSetTracer] // optional
 	beacon        beacon.Schedule
 
 	msgIndex index.MsgIndex
@@ -252,6 +260,19 @@ func NewStateManagerWithUpgradeScheduleAndMonitor(cs *store.ChainStore, exec Exe
 	return sm, nil
 }
 
+// SetTipSetTracer sets the tracer of tipsets executed when computing their
+// state.
+func (sm *StateManager) SetTipSetTracer(t TipSetTracer) {
+	sm.tsTracer.Store(&t)
+}
+
+func (sm *StateManager) tipSetTracer() TipSetTracer {
+	if t := sm.tsTracer.Load(); t != nil {
+		return *t
+	}
+	return nil
+}
+
 func cidsToKey(cids []cid.Cid) string {
 	var out string
 	for _, c := range cids {
@@ -513,6 +534,22 @@ func (sm *StateManager) GetRandomnessFromBeacon(ctx context.Context, personaliza
 
 }
 
+// GetRandomnessBase returns the ticket or beacon entry that randomness for randEpoch is drawn
+// from when sampled at the given tipset.
+func (sm *StateManager) GetRandomnessBase(ctx context.Context, fromBeacon bool, randEpoch abi.ChainEpoch, tsk types.TipSetKey) (*rand.RandomnessBase, error) {
+	pts, err := sm.ChainStore().GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	r := rand.NewStateRandBaseGetter(sm.ChainStore(), pts.Cids(), sm.beacon, sm.GetNetworkVersion)
+
+	if fromBeacon {
+		return r.GetBeaconRandomnessBase(ctx, randEpoch)
+	}
+	return r.GetChainRandomnessBase(ctx, randEpoch)
+}
+
 func (sm *StateManager) GetRandomnessFromTickets(ctx context.Context, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (abi.Randomness, error) {
 	pts, err := sm.ChainStore().LoadTipSet(ctx, tsk)
 	if err != nil {
@@ -15,6 +15,29 @@ type ExecMonitor interface {
 	MessageApplied(ctx context.Context, ts *types.TipSet, mcid cid.Cid, msg *types.Message, ret *vm.ApplyRet, implicit bool) error
 }
 
+// TipSetTracer is an ExecMonitor receiving detailed execution traces of the
+// tipsets it samples, when the state manager computes their state.
+type TipSetTracer interface {
+	ExecMonitor
+	Sampled(ts *types.TipSet) bool
+}
+
+var _ ExecMonitor = multiMonitor{}
+
+type multiMonitor []ExecMonitor
+
+func (m multiMonitor) MessageApplied(ctx context.Context, ts *types.TipSet, mcid cid.Cid, msg *types.Message, ret *vm.ApplyRet, implicit bool) error {
+	for _, em := range m {
+		if em == nil {
+			continue
+		}
+		if err := em.MessageApplied(ctx, ts, mcid, msg, ret, implicit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 var _ ExecMonitor = (*InvocationTracer)(nil)
 
 type InvocationTracer struct {
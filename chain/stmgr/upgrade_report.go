@@ -0,0 +1,127 @@
+package stmgr
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+// Upcoming reports the upgrades after the given epoch, when they're expected,
+// and whether this binary is ready to perform them. These are the upgrades of
+// the schedule, and the announced upgrades which the schedule doesn't have at
+// the same epoch, which only newer releases can perform.
+func (us UpgradeSchedule) Upcoming(head abi.ChainEpoch, genesisTime uint64, announced []dtypes.AnnouncedUpgrade) []api.UpgradeInfo {
+	eta := func(h abi.ChainEpoch) time.Time {
+		return time.Unix(int64(genesisTime)+int64(h)*int64(build.BlockDelaySecs), 0)
+	}
+
+	var out []api.UpgradeInfo
+	for _, u := range us {
+		// negative heights are disabled upgrades
+		if u.Height < 0 || u.Height <= head {
+			continue
+		}
+
+		prev, _ := us.GetNtwkVersion(u.Height)
+		info := api.UpgradeInfo{
+			Network:         u.Network,
+			Height:          u.Height,
+			EpochsRemaining: u.Height - head,
+			ETA:             eta(u.Height),
+			Migration:       u.Migration != nil,
+		}
+		info.Reason = upgradeReadiness(u, prev, &info)
+		info.Supported = info.Reason == ""
+
+		out = append(out, info)
+	}
+
+	for _, a := range announced {
+		if a.Height <= head {
+			continue
+		}
+
+		reason := us.announcedReadiness(a)
+		if reason == "" {
+			// reported with the schedule
+			continue
+		}
+
+		info := api.UpgradeInfo{
+			Network:         a.Network,
+			Height:          a.Height,
+			EpochsRemaining: a.Height - head,
+			ETA:             eta(a.Height),
+			Announced:       true,
+			Reason:          reason,
+		}
+		if av, err := actorstypes.VersionForNetwork(a.Network); err == nil {
+			info.ActorsVersion = av
+		}
+		out = append(out, info)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Height < out[j].Height
+	})
+	return out
+}
+
+// announcedReadiness returns why this binary can't perform an announced
+// upgrade, if it can't. Upgrades the schedule has at the announced epoch
+// return no reason, their readiness is the one of the schedule.
+func (us UpgradeSchedule) announcedReadiness(a dtypes.AnnouncedUpgrade) string {
+	if a.Network > build.TestNetworkVersion {
+		return fmt.Sprintf("network version %d is newer than the newest version supported by this binary (%d)", a.Network, build.TestNetworkVersion)
+	}
+
+	for _, u := range us {
+		// negative heights are disabled upgrades
+		if u.Network != a.Network || u.Height < 0 {
+			continue
+		}
+		if u.Height != a.Height {
+			return fmt.Sprintf("the upgrade schedule of this binary upgrades to network version %d at epoch %d instead", u.Network, u.Height)
+		}
+		return ""
+	}
+	return fmt.Sprintf("network version %d isn't in the upgrade schedule of this binary", a.Network)
+}
+
+// upgradeReadiness fills the actors version and bundle presence of an upgrade
+// from the prev network version, and returns why this binary can't perform
+// it, if it can't.
+func upgradeReadiness(u Upgrade, prev network.Version, info *api.UpgradeInfo) string {
+	if u.Network > build.TestNetworkVersion {
+		return fmt.Sprintf("network version %d is newer than the newest version supported by this binary (%d)", u.Network, build.TestNetworkVersion)
+	}
+
+	av, err := actorstypes.VersionForNetwork(u.Network)
+	if err != nil {
+		return fmt.Sprintf("no actors version is known for network version %d", u.Network)
+	}
+	info.ActorsVersion = av
+
+	// actors before v8 are built into the binary
+	info.BundlePresent = true
+	if av >= actorstypes.Version8 {
+		_, info.BundlePresent = actors.GetManifest(av)
+	}
+	if !info.BundlePresent {
+		return fmt.Sprintf("the builtin actors bundle for actors v%d isn't loaded", av)
+	}
+
+	if pav, err := actorstypes.VersionForNetwork(prev); err == nil && pav != av && u.Migration == nil {
+		return fmt.Sprintf("no state migration from actors v%d to v%d is defined", pav, av)
+	}
+	return ""
+}
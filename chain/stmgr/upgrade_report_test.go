@@ -0,0 +1,96 @@
+// stm: #unit
+package stmgr_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	actorstypes "github.com/filecoin-project/go-state-types/actors"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+func TestUpcomingUpgrades(t *testing.T) {
+	migrate := func(context.Context, *stmgr.StateManager, stmgr.MigrationCache, stmgr.ExecMonitor, cid.Cid, abi.ChainEpoch, *types.TipSet) (cid.Cid, error) {
+		return cid.Undef, nil
+	}
+
+	us := stmgr.UpgradeSchedule{
+		{Height: 10, Network: network.Version17, Migration: migrate},
+		// disabled
+		{Height: -1, Network: network.Version18, Migration: migrate},
+		{Height: 100, Network: network.Version18, Migration: migrate},
+		// actors change without a migration
+		{Height: 200, Network: network.Version19},
+		{Height: 300, Network: build.TestNetworkVersion + 1, Migration: migrate},
+	}
+
+	genesis := uint64(1_600_000_000)
+	up := us.Upcoming(50, genesis, nil)
+	require.Len(t, up, 3)
+
+	require.Equal(t, network.Version18, up[0].Network)
+	require.Equal(t, abi.ChainEpoch(100), up[0].Height)
+	require.Equal(t, abi.ChainEpoch(50), up[0].EpochsRemaining)
+	require.Equal(t, time.Unix(int64(genesis)+100*int64(build.BlockDelaySecs), 0), up[0].ETA)
+	require.Equal(t, actorstypes.Version10, up[0].ActorsVersion)
+	require.True(t, up[0].Migration)
+	require.True(t, up[0].BundlePresent)
+	require.True(t, up[0].Supported, up[0].Reason)
+
+	require.False(t, up[1].Supported)
+	require.Contains(t, up[1].Reason, "no state migration")
+
+	require.False(t, up[2].Supported)
+	require.Contains(t, up[2].Reason, "newer than the newest version")
+
+	require.Empty(t, us.Upcoming(300, genesis, nil))
+
+	// announced upgrades the schedule has at the same epoch are reported with
+	// the schedule, the others can't be performed
+	up = us.Upcoming(50, genesis, []dtypes.AnnouncedUpgrade{
+		{Height: 100, Network: network.Version18},
+		{Height: 150, Network: network.Version19},
+		{Height: 250, Network: network.Version16},
+		{Height: 400, Network: build.TestNetworkVersion + 1},
+		// past
+		{Height: 40, Network: build.TestNetworkVersion + 1},
+	})
+	require.Len(t, up, 6)
+
+	require.Equal(t, abi.ChainEpoch(100), up[0].Height)
+	require.False(t, up[0].Announced)
+	require.True(t, up[0].Supported)
+
+	require.Equal(t, abi.ChainEpoch(150), up[1].Height)
+	require.True(t, up[1].Announced)
+	require.False(t, up[1].Supported)
+	require.Contains(t, up[1].Reason, "at epoch 200 instead")
+	require.Equal(t, abi.ChainEpoch(100), up[1].EpochsRemaining)
+	require.Equal(t, time.Unix(int64(genesis)+150*int64(build.BlockDelaySecs), 0), up[1].ETA)
+	require.Equal(t, actorstypes.Version11, up[1].ActorsVersion)
+
+	require.Equal(t, abi.ChainEpoch(200), up[2].Height)
+	require.False(t, up[2].Announced)
+
+	require.Equal(t, abi.ChainEpoch(250), up[3].Height)
+	require.True(t, up[3].Announced)
+	require.Contains(t, up[3].Reason, "isn't in the upgrade schedule")
+
+	require.Equal(t, abi.ChainEpoch(300), up[4].Height)
+	require.False(t, up[4].Announced)
+
+	require.Equal(t, abi.ChainEpoch(400), up[5].Height)
+	require.True(t, up[5].Announced)
+	require.False(t, up[5].Supported)
+	require.Contains(t, up[5].Reason, "newer than the newest version")
+}
@@ -0,0 +1,70 @@
+// stm: #unit
+package store_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/gen"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestSubHeadChangesAtConfidence(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cg, err := gen.NewGenerator()
+	require.NoError(t, err)
+
+	mine := func(from *types.TipSet, miner int, n int) []*types.TipSet {
+		var out []*types.TipSet
+		for i := 0; i < n; i++ {
+			ts, err := cg.NextTipSetFromMiners(from, cg.Miners[miner:miner+1], 0)
+			require.NoError(t, err)
+			from = ts.TipSet.TipSet()
+			out = append(out, from)
+		}
+		return out
+	}
+
+	// a[i] and b[i] are at height i+1, b forks off a after a[1]
+	a := mine(cg.CurTipset.TipSet(), 0, 6)
+	b := mine(a[1], 1, 5)
+
+	cs := cg.ChainStore()
+	require.NoError(t, cs.SetHead(ctx, a[3]))
+
+	sub := cs.SubHeadChangesAtConfidence(ctx, 2)
+
+	var pending []*api.HeadChange
+	expect := func(typ string, tss ...*types.TipSet) {
+		for _, ts := range tss {
+			for len(pending) == 0 {
+				select {
+				case hcs := <-sub:
+					pending = append(pending, hcs...)
+				case <-time.After(10 * time.Second):
+					t.Fatal("timed out waiting for head changes")
+				}
+			}
+			require.Equal(t, typ, pending[0].Type)
+			require.Equal(t, ts.Key(), pending[0].Val.Key())
+			pending = pending[1:]
+		}
+	}
+
+	expect(store.HCCurrent, a[1])
+
+	require.NoError(t, cs.SetHead(ctx, a[5]))
+	expect(store.HCApply, a[2], a[3])
+
+	// reorg deeper than the confidence depth
+	require.NoError(t, cs.SetHead(ctx, b[4]))
+	expect(store.HCRevert, a[3], a[2])
+	expect(store.HCApply, b[0], b[1], b[2])
+}
@@ -45,8 +45,29 @@ func (cs *ChainStore) Export(ctx context.Context, ts *types.TipSet, inclRecentRo
 		return xerrors.Errorf("failed to write car header: %s", err)
 	}
 
+	return cs.WalkSnapshot(ctx, ts, inclRecentRoots, skipOldMsgs, true, cs.carBlockWriter(ctx, w))
+}
+
+// ExportEpochRange writes a CAR snapshot containing the header chain from head
+// back to genesis, messages of blocks between tail and head, and the state at
+// tail. Unlike ExportRange, the output is deterministic for a given head and
+// tail, which allows resuming an interrupted export at a byte offset.
+func (cs *ChainStore) ExportEpochRange(ctx context.Context, head, tail *types.TipSet, w io.Writer) error {
+	h := &car.CarHeader{
+		Roots:   head.Cids(),
+		Version: 1,
+	}
+
+	if err := car.WriteHeader(h, w); err != nil {
+		return xerrors.Errorf("failed to write car header: %s", err)
+	}
+
+	return cs.WalkSnapshotRange(ctx, head, tail, true, cs.carBlockWriter(ctx, w))
+}
+
+func (cs *ChainStore) carBlockWriter(ctx context.Context, w io.Writer) func(cid.Cid) error {
 	unionBs := cs.UnionStore()
-	return cs.WalkSnapshot(ctx, ts, inclRecentRoots, skipOldMsgs, true, func(c cid.Cid) error {
+	return func(c cid.Cid) error {
 		blk, err := unionBs.Get(ctx, c)
 		if err != nil {
 			return xerrors.Errorf("writing object to car, bs.Get: %w", err)
@@ -57,7 +78,7 @@ func (cs *ChainStore) Export(ctx context.Context, ts *types.TipSet, inclRecentRo
 		}
 
 		return nil
-	})
+	}
 }
 
 func (cs *ChainStore) Import(ctx context.Context, r io.Reader) (*types.TipSet, error) {
@@ -609,6 +630,35 @@ func (cs *ChainStore) WalkSnapshot(ctx context.Context, ts *types.TipSet, inclRe
 		ts = cs.GetHeaviestTipSet()
 	}
 
+	inclMsgs := func(h abi.ChainEpoch) bool {
+		return !skipOldMsgs || h > ts.Height()-inclRecentRoots
+	}
+	inclState := func(h abi.ChainEpoch) bool {
+		return h > ts.Height()-inclRecentRoots
+	}
+
+	return cs.walkSnapshot(ctx, ts, inclMsgs, inclState, skipMsgReceipts, cb)
+}
+
+// WalkSnapshotRange walks the header chain from head back to genesis, including
+// messages of blocks between tail and head, and state only at tail (and genesis).
+func (cs *ChainStore) WalkSnapshotRange(ctx context.Context, head, tail *types.TipSet, skipMsgReceipts bool, cb func(cid.Cid) error) error {
+	if head.Height() < tail.Height() {
+		return xerrors.Errorf("height of head-tipset (%d) must be greater or equal to the height of the tail-tipset (%d)", head.Height(), tail.Height())
+	}
+
+	inclMsgs := func(h abi.ChainEpoch) bool {
+		return h >= tail.Height()
+	}
+	inclState := func(h abi.ChainEpoch) bool {
+		return h == tail.Height()
+	}
+
+	return cs.walkSnapshot(ctx, head, inclMsgs, inclState, skipMsgReceipts, cb)
+}
+
+func (cs *ChainStore) walkSnapshot(ctx context.Context, ts *types.TipSet, inclMsgs, inclState func(abi.ChainEpoch) bool, skipMsgReceipts bool, cb func(cid.Cid) error) error {
+
 	seen := cid.NewSet()
 	walked := cid.NewSet()
 
@@ -642,7 +692,7 @@ func (cs *ChainStore) WalkSnapshot(ctx context.Context, ts *types.TipSet, inclRe
 		}
 
 		var cids []cid.Cid
-		if !skipOldMsgs || b.Height > ts.Height()-inclRecentRoots {
+		if inclMsgs(b.Height) {
 			if walked.Visit(b.Messages) {
 				mcids, err := recurseLinks(ctx, cs.chainBlockstore, walked, b.Messages, []cid.Cid{b.Messages})
 				if err != nil {
@@ -663,7 +713,7 @@ func (cs *ChainStore) WalkSnapshot(ctx context.Context, ts *types.TipSet, inclRe
 
 		out := cids
 
-		if b.Height == 0 || b.Height > ts.Height()-inclRecentRoots {
+		if b.Height == 0 || inclState(b.Height) {
 			if walked.Visit(b.ParentStateRoot) {
 				cids, err := recurseLinks(ctx, cs.stateBlockstore, walked, b.ParentStateRoot, []cid.Cid{b.ParentStateRoot})
 				if err != nil {
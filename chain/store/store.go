@@ -334,6 +334,67 @@ func (cs *ChainStore) SubHeadChanges(ctx context.Context) chan []*api.HeadChange
 	return out
 }
 
+// SubHeadChangesAtConfidence is like SubHeadChanges, but only notifies of
+// tipsets once they are depth epochs below the head. Tipsets which were applied
+// and are later reorged out are reverted, before the tipsets replacing them are
+// applied.
+func (cs *ChainStore) SubHeadChangesAtConfidence(ctx context.Context, depth abi.ChainEpoch) chan []*api.HeadChange {
+	ctx, cancel := context.WithCancel(ctx)
+	in := cs.SubHeadChanges(ctx)
+	out := make(chan []*api.HeadChange, 16)
+
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		var last *types.TipSet
+		for range in {
+			// notifications may lag behind the head, which is what the
+			// confidence is relative to
+			head := cs.GetHeaviestTipSet()
+
+			h := head.Height() - depth
+			if h < 0 {
+				h = 0
+			}
+			confident, err := cs.GetTipsetByHeight(ctx, h, head, true)
+			if err != nil {
+				log.Errorf("closing confident head change subscription: getting tipset at height %d: %s", h, err)
+				return
+			}
+
+			var notif []*api.HeadChange
+			if last == nil {
+				notif = []*api.HeadChange{{Type: HCCurrent, Val: confident}}
+			} else if !last.Equals(confident) {
+				revert, apply, err := ReorgOps(ctx, cs.LoadTipSet, last, confident)
+				if err != nil {
+					log.Errorf("closing confident head change subscription: computing reorg ops: %s", err)
+					return
+				}
+				for _, ts := range revert {
+					notif = append(notif, &api.HeadChange{Type: HCRevert, Val: ts})
+				}
+				for i := len(apply) - 1; i >= 0; i-- {
+					notif = append(notif, &api.HeadChange{Type: HCApply, Val: apply[i]})
+				}
+			}
+			last = confident
+
+			if len(notif) == 0 {
+				continue
+			}
+			select {
+			case out <- notif:
+			default:
+				log.Errorf("closing confident head change subscription due to slow reader")
+				return
+			}
+		}
+	}()
+	return out
+}
+
 func (cs *ChainStore) SubscribeHeadChanges(f ReorgNotifee) {
 	cs.reorgNotifeeCh <- f
 }
@@ -127,6 +127,49 @@ func TestChainExportImport(t *testing.T) {
 	}
 }
 
+func TestChainExportEpochRange(t *testing.T) {
+	ctx := context.Background()
+	cg, err := gen.NewGenerator()
+	require.NoError(t, err)
+
+	var tail, last *types.TipSet
+	for i := 0; i < 20; i++ {
+		ts, err := cg.NextTipSet()
+		require.NoError(t, err)
+
+		last = ts.TipSet.TipSet()
+		if i == 9 {
+			tail = last
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, cg.ChainStore().ExportEpochRange(ctx, last, tail, buf))
+
+	// the export must be deterministic, so that it can be resumed at an offset
+	buf2 := new(bytes.Buffer)
+	require.NoError(t, cg.ChainStore().ExportEpochRange(ctx, last, tail, buf2))
+	require.Equal(t, buf.Bytes(), buf2.Bytes())
+
+	nbs := blockstore.NewMemorySync()
+	cs := store.NewChainStore(nbs, nbs, datastore.NewMapDatastore(), filcns.Weight, nil)
+	defer cs.Close() //nolint:errcheck
+
+	root, err := cs.Import(ctx, buf)
+	require.NoError(t, err)
+	require.Truef(t, root.Equals(last), "imported chain differed from exported chain")
+
+	has, err := nbs.Has(ctx, tail.ParentState())
+	require.NoError(t, err)
+	require.True(t, has, "state at tail should be included")
+
+	has, err = nbs.Has(ctx, last.ParentState())
+	require.NoError(t, err)
+	require.False(t, has, "state at head should not be included")
+
+	require.Error(t, cg.ChainStore().ExportEpochRange(ctx, tail, last, new(bytes.Buffer)))
+}
+
 // Test to check if tipset key cids are being stored on snapshot
 func TestChainImportTipsetKeyCid(t *testing.T) {
 
@@ -0,0 +1,314 @@
+package ethtypes
+
+import (
+	"bytes"
+	"encoding/json"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/sha3"
+	"golang.org/x/xerrors"
+)
+
+// EthTypedDataField is a member of an EIP-712 struct type.
+type EthTypedDataField struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// EthTypedData is EIP-712 typed structured data, as passed to eth_signTypedData_v4.
+type EthTypedData struct {
+	// Types holds the struct types of the data, including EIP712Domain.
+	Types       map[string][]EthTypedDataField `json:"types"`
+	PrimaryType string                         `json:"primaryType"`
+	Domain      map[string]interface{}         `json:"domain"`
+	Message     map[string]interface{}         `json:"message"`
+}
+
+const eip712DomainType = "EIP712Domain"
+
+var (
+	eip712ArrayType = regexp.MustCompile(`^(.+)\[(\d*)\]$`)
+	eip712IntType   = regexp.MustCompile(`^(u?)int(\d*)$`)
+	eip712BytesType = regexp.MustCompile(`^bytes(\d+)$`)
+)
+
+func (td *EthTypedData) UnmarshalJSON(b []byte) error {
+	// wallets commonly pass the typed data as a JSON encoded string
+	if bytes.HasPrefix(bytes.TrimSpace(b), []byte(`"`)) {
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return err
+		}
+		b = []byte(s)
+	}
+
+	// numbers are kept as json.Number, as uint256 values don't fit floats
+	type typedData EthTypedData
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var out typedData
+	if err := dec.Decode(&out); err != nil {
+		return err
+	}
+	*td = EthTypedData(out)
+	return nil
+}
+
+// SigningPayload returns the bytes whose keccak256 hash is signed for the data,
+// 0x19 0x01 || domainSeparator || hashStruct(message).
+func (td *EthTypedData) SigningPayload() ([]byte, error) {
+	if _, ok := td.Types[eip712DomainType]; !ok {
+		return nil, xerrors.Errorf("typed data doesn't define the %s type", eip712DomainType)
+	}
+	if _, ok := td.Types[td.PrimaryType]; !ok {
+		return nil, xerrors.Errorf("primary type %q isn't defined", td.PrimaryType)
+	}
+
+	domain, err := td.hashStruct(eip712DomainType, td.Domain)
+	if err != nil {
+		return nil, xerrors.Errorf("hashing domain: %w", err)
+	}
+	msg, err := td.hashStruct(td.PrimaryType, td.Message)
+	if err != nil {
+		return nil, xerrors.Errorf("hashing message: %w", err)
+	}
+
+	payload := make([]byte, 0, 66)
+	payload = append(payload, 0x19, 0x01)
+	payload = append(payload, domain...)
+	return append(payload, msg...), nil
+}
+
+// Hash returns the EIP-712 digest of the data.
+func (td *EthTypedData) Hash() (EthHash, error) {
+	payload, err := td.SigningPayload()
+	if err != nil {
+		return EthHash{}, err
+	}
+	var h EthHash
+	copy(h[:], keccak256(payload))
+	return h, nil
+}
+
+// EncodeType returns the EIP-712 encoding of a struct type, followed by the types it
+// references in alphabetical order.
+func (td *EthTypedData) EncodeType(primary string) string {
+	deps := map[string]struct{}{}
+	td.dependencies(primary, deps)
+	delete(deps, primary)
+
+	sorted := make([]string, 0, len(deps))
+	for dep := range deps {
+		sorted = append(sorted, dep)
+	}
+	sort.Strings(sorted)
+
+	var sb strings.Builder
+	for _, name := range append([]string{primary}, sorted...) {
+		fields := make([]string, len(td.Types[name]))
+		for i, f := range td.Types[name] {
+			fields[i] = f.Type + " " + f.Name
+		}
+		sb.WriteString(name + "(" + strings.Join(fields, ",") + ")")
+	}
+	return sb.String()
+}
+
+func (td *EthTypedData) dependencies(typ string, found map[string]struct{}) {
+	typ = eip712BaseType(typ)
+	if _, ok := found[typ]; ok {
+		return
+	}
+	fields, ok := td.Types[typ]
+	if !ok {
+		return
+	}
+	found[typ] = struct{}{}
+
+	for _, f := range fields {
+		td.dependencies(f.Type, found)
+	}
+}
+
+func (td *EthTypedData) hashStruct(typ string, data map[string]interface{}) ([]byte, error) {
+	buf := keccak256([]byte(td.EncodeType(typ)))
+	for _, f := range td.Types[typ] {
+		v, ok := data[f.Name]
+		if !ok {
+			return nil, xerrors.Errorf("%s is missing field %q", typ, f.Name)
+		}
+		enc, err := td.encodeValue(f.Type, v)
+		if err != nil {
+			return nil, xerrors.Errorf("encoding %s.%s: %w", typ, f.Name, err)
+		}
+		buf = append(buf, enc...)
+	}
+	return keccak256(buf), nil
+}
+
+// encodeValue encodes a value to 32 bytes, dynamic and struct values are encoded to their hash.
+func (td *EthTypedData) encodeValue(typ string, v interface{}) ([]byte, error) {
+	if m := eip712ArrayType.FindStringSubmatch(typ); m != nil {
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, xerrors.Errorf("expected an array, got %T", v)
+		}
+		if m[2] != "" {
+			n, err := strconv.Atoi(m[2])
+			if err != nil || n != len(items) {
+				return nil, xerrors.Errorf("expected %s items, got %d", m[2], len(items))
+			}
+		}
+
+		var buf []byte
+		for i, item := range items {
+			enc, err := td.encodeValue(m[1], item)
+			if err != nil {
+				return nil, xerrors.Errorf("item %d: %w", i, err)
+			}
+			buf = append(buf, enc...)
+		}
+		return keccak256(buf), nil
+	}
+
+	if _, ok := td.Types[typ]; ok {
+		data, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, xerrors.Errorf("expected a %s struct, got %T", typ, v)
+		}
+		return td.hashStruct(typ, data)
+	}
+
+	switch typ {
+	case "string":
+		s, ok := v.(string)
+		if !ok {
+			return nil, xerrors.Errorf("expected a string, got %T", v)
+		}
+		return keccak256([]byte(s)), nil
+	case "bytes":
+		b, err := eip712Bytes(v)
+		if err != nil {
+			return nil, err
+		}
+		return keccak256(b), nil
+	case "bool":
+		b, ok := v.(bool)
+		if !ok {
+			return nil, xerrors.Errorf("expected a bool, got %T", v)
+		}
+		out := make([]byte, 32)
+		if b {
+			out[31] = 1
+		}
+		return out, nil
+	case "address":
+		s, ok := v.(string)
+		if !ok {
+			return nil, xerrors.Errorf("expected an address, got %T", v)
+		}
+		a, err := ParseEthAddress(s)
+		if err != nil {
+			return nil, err
+		}
+		out := make([]byte, 32)
+		copy(out[12:], a[:])
+		return out, nil
+	}
+
+	if m := eip712BytesType.FindStringSubmatch(typ); m != nil {
+		n, err := strconv.Atoi(m[1])
+		if err != nil || n < 1 || n > 32 {
+			return nil, xerrors.Errorf("invalid type %s", typ)
+		}
+		b, err := eip712Bytes(v)
+		if err != nil {
+			return nil, err
+		}
+		if len(b) != n {
+			return nil, xerrors.Errorf("expected %d bytes, got %d", n, len(b))
+		}
+		out := make([]byte, 32)
+		copy(out, b)
+		return out, nil
+	}
+
+	if m := eip712IntType.FindStringSubmatch(typ); m != nil {
+		bits := 256
+		if m[2] != "" {
+			var err error
+			if bits, err = strconv.Atoi(m[2]); err != nil || bits < 8 || bits > 256 || bits%8 != 0 {
+				return nil, xerrors.Errorf("invalid type %s", typ)
+			}
+		}
+		return eip712Int(v, m[1] == "u", bits)
+	}
+
+	return nil, xerrors.Errorf("unknown type %s", typ)
+}
+
+func eip712Bytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, xerrors.Errorf("expected hex bytes, got %T", v)
+	}
+	return DecodeHexString(s)
+}
+
+// eip712Int encodes an integer as a 256 bit two's complement.
+func eip712Int(v interface{}, unsigned bool, bits int) ([]byte, error) {
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case string:
+		s = v
+	case float64:
+		s = strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return nil, xerrors.Errorf("expected an integer, got %T", v)
+	}
+
+	n, ok := new(big.Int).SetString(s, 0)
+	if !ok {
+		return nil, xerrors.Errorf("invalid integer %q", s)
+	}
+
+	var lo, hi *big.Int
+	if unsigned {
+		lo, hi = big.NewInt(0), new(big.Int).Lsh(big.NewInt(1), uint(bits))
+	} else {
+		hi = new(big.Int).Lsh(big.NewInt(1), uint(bits-1))
+		lo = new(big.Int).Neg(hi)
+	}
+	if n.Cmp(lo) < 0 || n.Cmp(hi) >= 0 {
+		return nil, xerrors.Errorf("%s is out of range for %d bit integers", s, bits)
+	}
+
+	if n.Sign() < 0 {
+		n.Add(n, new(big.Int).Lsh(big.NewInt(1), 256))
+	}
+	out := make([]byte, 32)
+	n.FillBytes(out)
+	return out, nil
+}
+
+func eip712BaseType(typ string) string {
+	for {
+		m := eip712ArrayType.FindStringSubmatch(typ)
+		if m == nil {
+			return typ
+		}
+		typ = m[1]
+	}
+}
+
+func keccak256(b []byte) []byte {
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(b)
+	return hasher.Sum(nil)
+}
@@ -0,0 +1,97 @@
+package ethtypes
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// the example of EIP-712
+const mailTypedData = `{
+	"types": {
+		"EIP712Domain": [
+			{"name": "name", "type": "string"},
+			{"name": "version", "type": "string"},
+			{"name": "chainId", "type": "uint256"},
+			{"name": "verifyingContract", "type": "address"}
+		],
+		"Person": [
+			{"name": "name", "type": "string"},
+			{"name": "wallet", "type": "address"}
+		],
+		"Mail": [
+			{"name": "from", "type": "Person"},
+			{"name": "to", "type": "Person"},
+			{"name": "contents", "type": "string"}
+		]
+	},
+	"primaryType": "Mail",
+	"domain": {
+		"name": "Ether Mail",
+		"version": "1",
+		"chainId": 1,
+		"verifyingContract": "0xCcCCccccCCCCcCCCCCCcCcCccCcCCCcCcccccccC"
+	},
+	"message": {
+		"from": {"name": "Cow", "wallet": "0xCD2a3d9F938E13CD947Ec05AbC7FE734Df8DD826"},
+		"to": {"name": "Bob", "wallet": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB"},
+		"contents": "Hello, Bob!"
+	}
+}`
+
+func TestEIP712Hash(t *testing.T) {
+	var td EthTypedData
+	require.NoError(t, json.Unmarshal([]byte(mailTypedData), &td))
+
+	require.Equal(t, "Mail(Person from,Person to,string contents)Person(string name,address wallet)", td.EncodeType("Mail"))
+
+	payload, err := td.SigningPayload()
+	require.NoError(t, err)
+	require.Equal(t, "1901"+
+		"f2cee375fa42b42143804025fc449deafd50cc031ca257e0b194a650a912090f"+
+		"c52c0ee5d84264471806290a3f2c4cecfc5490626bf912d01f240d7a274b371e", hex.EncodeToString(payload))
+
+	h, err := td.Hash()
+	require.NoError(t, err)
+	require.Equal(t, "0xbe609aee343fb3c4b28e1df9e632fca64fcfaede20f02e86244efddf30957bd2", h.String())
+
+	// typed data is also passed as a string
+	enc, err := json.Marshal(mailTypedData)
+	require.NoError(t, err)
+	var fromString EthTypedData
+	require.NoError(t, json.Unmarshal(enc, &fromString))
+	h2, err := fromString.Hash()
+	require.NoError(t, err)
+	require.Equal(t, h, h2)
+}
+
+func TestEIP712Values(t *testing.T) {
+	td := EthTypedData{Types: map[string][]EthTypedDataField{}}
+
+	enc, err := td.encodeValue("int8", json.Number("-1"))
+	require.NoError(t, err)
+	require.Equal(t, "ff", hex.EncodeToString(enc[:1]))
+	require.Equal(t, "ff", hex.EncodeToString(enc[31:]))
+
+	_, err = td.encodeValue("uint8", json.Number("256"))
+	require.Error(t, err)
+	_, err = td.encodeValue("uint256", json.Number("-1"))
+	require.Error(t, err)
+
+	enc, err = td.encodeValue("uint256", "0x10")
+	require.NoError(t, err)
+	require.Equal(t, byte(0x10), enc[31])
+
+	enc, err = td.encodeValue("bytes4", "0xdeadbeef")
+	require.NoError(t, err)
+	require.Equal(t, "deadbeef"+"00000000000000000000000000000000000000000000000000000000", hex.EncodeToString(enc))
+	_, err = td.encodeValue("bytes4", "0xdead")
+	require.Error(t, err)
+
+	_, err = td.encodeValue("uint256[2]", []interface{}{json.Number("1")})
+	require.Error(t, err)
+	_, err = td.encodeValue("Unknown", "x")
+	require.Error(t, err)
+}
@@ -0,0 +1,315 @@
+package ethtypes
+
+import (
+	"fmt"
+	mathbig "math/big"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	gocrypto "github.com/filecoin-project/go-crypto"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+const (
+	LegacyTxType  = 0
+	Eip2930TxType = 1
+)
+
+// EthAccessTuple is an entry of an EIP-2930 access list.
+type EthAccessTuple struct {
+	Address     EthAddress `json:"address"`
+	StorageKeys []EthHash  `json:"storageKeys"`
+}
+
+// EthRawTx is a raw Ethereum transaction decoded from any of the legacy, EIP-2930 and
+// EIP-1559 envelopes. Fee fields not present in the envelope are nil.
+type EthRawTx struct {
+	Type                 EthUint64        `json:"type"`
+	ChainID              *EthUint64       `json:"chainId"`
+	Nonce                EthUint64        `json:"nonce"`
+	To                   *EthAddress      `json:"to"`
+	Value                EthBigInt        `json:"value"`
+	Input                EthBytes         `json:"input"`
+	Gas                  EthUint64        `json:"gas"`
+	GasPrice             *EthBigInt       `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *EthBigInt       `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *EthBigInt       `json:"maxPriorityFeePerGas,omitempty"`
+	AccessList           []EthAccessTuple `json:"accessList"`
+	V                    EthBigInt        `json:"v"`
+	R                    EthBigInt        `json:"r"`
+	S                    EthBigInt        `json:"s"`
+	Hash                 EthHash          `json:"hash"`
+
+	// signingHash is the keccak256 hash signed by the sender
+	signingHash []byte
+	// recID is the recovery id derived from V
+	recID byte
+}
+
+// DecodeEthRawTx decodes a signed raw Ethereum transaction. Unlike ParseEthTxArgs it
+// accepts every envelope type, including the ones which can't be submitted to Filecoin.
+func DecodeEthRawTx(data []byte) (*EthRawTx, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("empty data")
+	}
+
+	if data[0] > 0x7f {
+		return decodeLegacyRawTx(data)
+	}
+
+	switch data[0] {
+	case Eip2930TxType, Eip1559TxType:
+		return decodeTypedRawTx(data)
+	default:
+		return nil, fmt.Errorf("unsupported transaction type %d", data[0])
+	}
+}
+
+func decodeLegacyRawTx(data []byte) (*EthRawTx, error) {
+	d, err := DecodeRLP(data)
+	if err != nil {
+		return nil, err
+	}
+	decoded, ok := d.([]interface{})
+	if !ok || len(decoded) != 9 {
+		return nil, fmt.Errorf("not a legacy transaction: should be a list of 9 elements")
+	}
+
+	tx := &EthRawTx{Type: LegacyTxType, Hash: EthHashFromTxBytes(data)}
+	gasPrice, err := tx.decodeCommon(decoded[0], decoded[1], decoded[2], decoded[3], decoded[4], decoded[5])
+	if err != nil {
+		return nil, err
+	}
+	tx.GasPrice = &gasPrice
+	if err := tx.decodeSig(decoded[6], decoded[7], decoded[8]); err != nil {
+		return nil, err
+	}
+
+	// https://eips.ethereum.org/EIPS/eip-155: v is 27 or 28 for unprotected transactions,
+	// and chainId * 2 + 35 or 36 for replay protected ones
+	v := tx.V.Int
+	unsigned := decoded[:6]
+	switch {
+	case v.IsInt64() && (v.Int64() == 27 || v.Int64() == 28):
+		tx.recID = byte(v.Int64() - 27)
+	case v.Cmp(mathbig.NewInt(35)) >= 0:
+		rec := new(mathbig.Int).Sub(v, mathbig.NewInt(35))
+		chainID := new(mathbig.Int).Rsh(rec, 1)
+		if !chainID.IsUint64() {
+			return nil, fmt.Errorf("chain id derived from v is out of range")
+		}
+		id := EthUint64(chainID.Uint64())
+		tx.ChainID = &id
+		tx.recID = byte(rec.Bit(0))
+		unsigned = append(append([]interface{}{}, unsigned...), chainID.Bytes(), []byte{}, []byte{})
+	default:
+		return nil, fmt.Errorf("invalid v value %s for a legacy transaction", v)
+	}
+
+	msg, err := EncodeRLP(unsigned)
+	if err != nil {
+		return nil, err
+	}
+	tx.signingHash = keccak256(msg)
+	return tx, nil
+}
+
+func decodeTypedRawTx(data []byte) (*EthRawTx, error) {
+	typ := data[0]
+	d, err := DecodeRLP(data[1:])
+	if err != nil {
+		return nil, err
+	}
+	decoded, ok := d.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not a type %d transaction: decoded data is not a list", typ)
+	}
+
+	// EIP-1559 splits the gas price into the max priority fee and max fee
+	fields := 11
+	if typ == Eip1559TxType {
+		fields = 12
+	}
+	if len(decoded) != fields {
+		return nil, fmt.Errorf("not a type %d transaction: should have %d elements in the rlp list", typ, fields)
+	}
+
+	chainID, err := parseInt(decoded[0])
+	if err != nil {
+		return nil, err
+	}
+	id := EthUint64(chainID)
+	tx := &EthRawTx{
+		Type:    EthUint64(typ),
+		ChainID: &id,
+		Hash:    EthHashFromTxBytes(data),
+	}
+
+	rest := decoded[1:]
+	if typ == Eip1559TxType {
+		maxPriorityFeePerGas, err := parseBigInt(rest[1])
+		if err != nil {
+			return nil, err
+		}
+		mp := EthBigInt(maxPriorityFeePerGas)
+		tx.MaxPriorityFeePerGas = &mp
+		// drop the priority fee so that the remaining fields line up with EIP-2930
+		rest = append([]interface{}{rest[0]}, rest[2:]...)
+	}
+
+	fee, err := tx.decodeCommon(rest[0], rest[1], rest[2], rest[3], rest[4], rest[5])
+	if err != nil {
+		return nil, err
+	}
+	if typ == Eip1559TxType {
+		tx.MaxFeePerGas = &fee
+	} else {
+		tx.GasPrice = &fee
+	}
+
+	if tx.AccessList, err = parseAccessList(rest[6]); err != nil {
+		return nil, err
+	}
+	if err := tx.decodeSig(rest[7], rest[8], rest[9]); err != nil {
+		return nil, err
+	}
+
+	v := tx.V.Int
+	if !v.IsInt64() || (v.Int64() != 0 && v.Int64() != 1) {
+		return nil, fmt.Errorf("type %d transactions only support 0 or 1 for v", typ)
+	}
+	tx.recID = byte(v.Int64())
+
+	msg, err := EncodeRLP(decoded[:len(decoded)-3])
+	if err != nil {
+		return nil, err
+	}
+	tx.signingHash = keccak256(append([]byte{typ}, msg...))
+	return tx, nil
+}
+
+// decodeCommon decodes the nonce, gas and value fields shared by all envelopes and
+// returns the single fee field which precedes the gas limit.
+func (tx *EthRawTx) decodeCommon(nonce, fee, gas, to, value, input interface{}) (EthBigInt, error) {
+	n, err := parseInt(nonce)
+	if err != nil {
+		return EthBigIntZero, err
+	}
+	tx.Nonce = EthUint64(n)
+
+	f, err := parseBigInt(fee)
+	if err != nil {
+		return EthBigIntZero, err
+	}
+
+	g, err := parseInt(gas)
+	if err != nil {
+		return EthBigIntZero, err
+	}
+	tx.Gas = EthUint64(g)
+
+	if tx.To, err = parseEthAddr(to); err != nil {
+		return EthBigIntZero, err
+	}
+
+	val, err := parseBigInt(value)
+	if err != nil {
+		return EthBigIntZero, err
+	}
+	tx.Value = EthBigInt(val)
+
+	if tx.Input, err = parseBytes(input); err != nil {
+		return EthBigIntZero, err
+	}
+	return EthBigInt(f), nil
+}
+
+func (tx *EthRawTx) decodeSig(v, r, s interface{}) error {
+	for _, f := range []struct {
+		raw interface{}
+		out *EthBigInt
+	}{{v, &tx.V}, {r, &tx.R}, {s, &tx.S}} {
+		n, err := parseBigInt(f.raw)
+		if err != nil {
+			return err
+		}
+		*f.out = EthBigInt(n)
+	}
+	return nil
+}
+
+func parseAccessList(v interface{}) ([]EthAccessTuple, error) {
+	items, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("access list is not a list")
+	}
+
+	out := make([]EthAccessTuple, 0, len(items))
+	for i, item := range items {
+		tuple, ok := item.([]interface{})
+		if !ok || len(tuple) != 2 {
+			return nil, fmt.Errorf("access list entry %d should be a list of 2 elements", i)
+		}
+		addr, err := parseEthAddr(tuple[0])
+		if err != nil || addr == nil {
+			return nil, fmt.Errorf("access list entry %d has an invalid address", i)
+		}
+		keys, ok := tuple[1].([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("access list entry %d storage keys are not a list", i)
+		}
+
+		entry := EthAccessTuple{Address: *addr, StorageKeys: make([]EthHash, len(keys))}
+		for j, k := range keys {
+			b, err := parseBytes(k)
+			if err != nil || len(b) != EthHashLength {
+				return nil, fmt.Errorf("access list entry %d has an invalid storage key %d", i, j)
+			}
+			copy(entry.StorageKeys[j][:], b)
+		}
+		out = append(out, entry)
+	}
+	return out, nil
+}
+
+// Sender recovers the address which signed the transaction.
+func (tx *EthRawTx) Sender() (EthAddress, error) {
+	sig := make([]byte, 0, 65)
+	sig = append(sig, padLeadingZeros(tx.R.Int.Bytes(), 32)...)
+	sig = append(sig, padLeadingZeros(tx.S.Int.Bytes(), 32)...)
+	sig = append(sig, tx.recID)
+	if len(sig) != 65 {
+		return EthAddress{}, xerrors.Errorf("invalid signature length %d", len(sig))
+	}
+
+	pubk, err := gocrypto.EcRecover(tx.signingHash, sig)
+	if err != nil {
+		return EthAddress{}, xerrors.Errorf("recovering public key: %w", err)
+	}
+	ethAddr, err := EthAddressFromPubKey(pubk)
+	if err != nil {
+		return EthAddress{}, err
+	}
+	return CastEthAddress(ethAddr)
+}
+
+// FilecoinGas returns the gas limit, fee cap and premium of the Filecoin message the
+// transaction translates to. A legacy or EIP-2930 gas price is used for both the fee cap
+// and premium.
+func (tx *EthRawTx) FilecoinGas() (gasLimit int64, feeCap, premium big.Int) {
+	gasLimit = int64(tx.Gas)
+	if tx.MaxFeePerGas != nil {
+		return gasLimit, big.Int(*tx.MaxFeePerGas), big.Int(*tx.MaxPriorityFeePerGas)
+	}
+	return gasLimit, big.Int(*tx.GasPrice), big.Int(*tx.GasPrice)
+}
+
+// FilecoinSender returns the f410 address of the sender.
+func (tx *EthRawTx) FilecoinSender() (address.Address, error) {
+	from, err := tx.Sender()
+	if err != nil {
+		return address.Undef, err
+	}
+	return from.ToFilecoinAddress()
+}
@@ -0,0 +1,113 @@
+package ethtypes
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	gocrypto "github.com/filecoin-project/go-crypto"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+func TestDecodeLegacyRawTx(t *testing.T) {
+	// the example transaction of EIP-155
+	raw := mustDecodeHex("0xf86c098504a817c800825208943535353535353535353535353535353535353535880de0b6b3a76400008025a028ef61340bd939bc2195fe537567866003e1a15d3c71ff63e1590620aa636276a067cbe9d8997f761aecb703304b3800ccf555c9f3dc64214b297fb1966a3b6d83")
+
+	tx, err := DecodeEthRawTx(raw)
+	require.NoError(t, err)
+	require.EqualValues(t, LegacyTxType, tx.Type)
+	require.NotNil(t, tx.ChainID)
+	require.EqualValues(t, 1, *tx.ChainID)
+	require.EqualValues(t, 9, tx.Nonce)
+	require.EqualValues(t, 21000, tx.Gas)
+	require.Equal(t, big.NewInt(20_000_000_000), big.Int(*tx.GasPrice))
+	require.Nil(t, tx.MaxFeePerGas)
+
+	from, err := tx.Sender()
+	require.NoError(t, err)
+	require.Equal(t, "0x9d8a62f656a8d1615c1294fd71e9cfb3e4855a4f", from.String())
+
+	gasLimit, feeCap, premium := tx.FilecoinGas()
+	require.EqualValues(t, 21000, gasLimit)
+	require.Equal(t, big.Int(*tx.GasPrice), feeCap)
+	require.Equal(t, feeCap, premium)
+
+	// legacy transactions can't be submitted
+	_, err = ParseEthTxArgs(raw)
+	require.Error(t, err)
+}
+
+func TestDecodeTypedRawTx(t *testing.T) {
+	sk, err := gocrypto.GenerateKey()
+	require.NoError(t, err)
+	ethAddr, err := EthAddressFromPubKey(gocrypto.PublicKey(sk))
+	require.NoError(t, err)
+	signer, err := CastEthAddress(ethAddr)
+	require.NoError(t, err)
+
+	to := EthAddress{0xff, 0x01}
+	storageKey := EthHash{0x02}
+	accessList := []interface{}{[]interface{}{to[:], []interface{}{storageKey[:]}}}
+
+	for _, typ := range []byte{Eip2930TxType, Eip1559TxType} {
+		fields := []interface{}{[]byte{0x01, 0x3a}, []byte{0x05}}
+		if typ == Eip1559TxType {
+			fields = append(fields, []byte{0x64})
+		}
+		fields = append(fields, []byte{0x03, 0xe8}, []byte{0x52, 0x08}, to[:], []byte{0x07}, []byte{0xca, 0xfe}, accessList)
+
+		unsigned, err := EncodeRLP(fields)
+		require.NoError(t, err)
+		sig, err := gocrypto.Sign(sk, keccak256(append([]byte{typ}, unsigned...)))
+		require.NoError(t, err)
+
+		signed, err := EncodeRLP(append(fields, []byte{sig[64]}, sig[:32], sig[32:64]))
+		require.NoError(t, err)
+		raw := append([]byte{typ}, signed...)
+
+		tx, err := DecodeEthRawTx(raw)
+		require.NoError(t, err, "type %d", typ)
+		require.EqualValues(t, typ, tx.Type)
+		require.EqualValues(t, 314, *tx.ChainID)
+		require.EqualValues(t, 5, tx.Nonce)
+		require.EqualValues(t, 21000, tx.Gas)
+		require.Equal(t, &to, tx.To)
+		require.Equal(t, EthBytes{0xca, 0xfe}, tx.Input)
+		require.Equal(t, []EthAccessTuple{{Address: to, StorageKeys: []EthHash{storageKey}}}, tx.AccessList)
+		require.Equal(t, EthHashFromTxBytes(raw), tx.Hash)
+
+		from, err := tx.Sender()
+		require.NoError(t, err)
+		require.Equal(t, signer, from)
+
+		_, feeCap, premium := tx.FilecoinGas()
+		require.Equal(t, big.NewInt(1000), feeCap)
+		if typ == Eip1559TxType {
+			require.Equal(t, big.NewInt(100), premium)
+		} else {
+			require.Equal(t, feeCap, premium)
+		}
+	}
+}
+
+func TestDecodeRawTxMatchesTxArgs(t *testing.T) {
+	testcases, err := prepareTxTestcases()
+	require.NoError(t, err)
+
+	for _, tc := range testcases {
+		tx, err := DecodeEthRawTx(tc.Input)
+		require.NoError(t, err)
+		args, err := ParseEthTxArgs(tc.Input)
+		require.NoError(t, err)
+
+		sender, err := tx.FilecoinSender()
+		require.NoError(t, err)
+		expected, err := args.Sender()
+		require.NoError(t, err)
+		require.Equal(t, expected, sender)
+
+		require.EqualValues(t, args.Nonce, tx.Nonce)
+		require.Equal(t, args.MaxFeePerGas, big.Int(*tx.MaxFeePerGas))
+		require.Equal(t, args.MaxPriorityFeePerGas, big.Int(*tx.MaxPriorityFeePerGas))
+	}
+}
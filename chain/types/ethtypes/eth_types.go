@@ -18,6 +18,7 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	builtintypes "github.com/filecoin-project/go-state-types/builtin"
 
@@ -180,6 +181,30 @@ type EthBlock struct {
 
 const EthBloomSize = 2048
 
+// SafeEpochDelay is how far behind 'latest' the 'safe' block tag points.
+const SafeEpochDelay = abi.ChainEpoch(30)
+
+// BlockTagHeight returns the height the 'safe' and 'finalized' block tags point
+// to with the given head, measured back from 'latest', the parent of the head.
+// It returns false for other block params.
+func BlockTagHeight(tag string, head abi.ChainEpoch) (abi.ChainEpoch, bool) {
+	var delay abi.ChainEpoch
+	switch tag {
+	case "safe":
+		delay = SafeEpochDelay
+	case "finalized":
+		delay = build.Finality
+	default:
+		return 0, false
+	}
+
+	height := head - 1 - delay
+	if height < 0 {
+		height = 0
+	}
+	return height, true
+}
+
 var (
 	EmptyEthBloom  = [EthBloomSize / 8]byte{}
 	FullEthBloom   = [EthBloomSize / 8]byte{}
@@ -243,6 +268,19 @@ type EthSyncingResult struct {
 	StartingBlock EthUint64
 	CurrentBlock  EthUint64
 	HighestBlock  EthUint64
+
+	// Filecoin extensions, describing the sync worker which is making progress
+	// (if any).
+	SyncWorker *EthUint64
+	SyncStage  string
+}
+
+type ethSyncingProgress struct {
+	StartingBlock EthUint64  `json:"startingBlock"`
+	CurrentBlock  EthUint64  `json:"currentBlock"`
+	HighestBlock  EthUint64  `json:"highestBlock"`
+	SyncWorker    *EthUint64 `json:"syncWorker,omitempty"`
+	SyncStage     string     `json:"syncStage,omitempty"`
 }
 
 func (sr EthSyncingResult) MarshalJSON() ([]byte, error) {
@@ -251,15 +289,33 @@ func (sr EthSyncingResult) MarshalJSON() ([]byte, error) {
 		return []byte("false"), nil
 	}
 
-	// need to do an anonymous struct to avoid infinite recursion
-	return json.Marshal(&struct {
-		StartingBlock EthUint64 `json:"startingblock"`
-		CurrentBlock  EthUint64 `json:"currentblock"`
-		HighestBlock  EthUint64 `json:"highestblock"`
-	}{
+	return json.Marshal(&ethSyncingProgress{
 		StartingBlock: sr.StartingBlock,
 		CurrentBlock:  sr.CurrentBlock,
-		HighestBlock:  sr.HighestBlock})
+		HighestBlock:  sr.HighestBlock,
+		SyncWorker:    sr.SyncWorker,
+		SyncStage:     sr.SyncStage,
+	})
+}
+
+func (sr *EthSyncingResult) UnmarshalJSON(b []byte) error {
+	if bytes.Equal(b, []byte("false")) {
+		*sr = EthSyncingResult{DoneSync: true}
+		return nil
+	}
+
+	var p ethSyncingProgress
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+	*sr = EthSyncingResult{
+		StartingBlock: p.StartingBlock,
+		CurrentBlock:  p.CurrentBlock,
+		HighestBlock:  p.HighestBlock,
+		SyncWorker:    p.SyncWorker,
+		SyncStage:     p.SyncStage,
+	}
+	return nil
 }
 
 const (
@@ -432,6 +488,23 @@ func (ea EthAddress) ToFilecoinAddress() (address.Address, error) {
 	return addr, nil
 }
 
+// CreateAddress returns the address of the contract created by ea with the
+// given nonce, following the rules of the CREATE opcode.
+func (ea EthAddress) CreateAddress(nonce uint64) (EthAddress, error) {
+	n := binary.BigEndian.AppendUint64(nil, nonce)
+	enc, err := EncodeRLP([]interface{}{ea[:], removeLeadingZeros(n)})
+	if err != nil {
+		return EthAddress{}, err
+	}
+
+	hasher := sha3.NewLegacyKeccak256()
+	hasher.Write(enc)
+
+	var out EthAddress
+	copy(out[:], hasher.Sum(nil)[12:])
+	return out, nil
+}
+
 type EthHash [EthHashLength]byte
 
 func (h EthHash) MarshalJSON() ([]byte, error) {
@@ -769,14 +842,61 @@ type EthSubscriptionParams struct {
 	// The JSON decoding must treat a string as equivalent to an array with one value, for example
 	// "0x8888f1f195afa192cfee86069858" must be decoded as [ "0x8888f1f195afa192cfee86069858" ]
 	Address EthAddressList `json:"address"`
+
+	// Epoch from which matching events are first replayed from the event index, before switching
+	// to live events.
+	// Optional, default nil: only live events are sent.
+	FromEpoch *EthUint64 `json:"fromEpoch,omitempty"`
+
+	// Whether to send a cursor with each log, in the cursor field of the
+	// notification, which can be passed in ResumeAfter to resume the
+	// subscription after the log.
+	// Optional, default false.
+	Cursors bool `json:"cursors,omitempty"`
+
+	// Cursor of the last log received by a previous subscription, to resume it
+	// without gaps or duplicates. When the log was reverted since, the logs
+	// received from the reverted tipsets are sent again with removed set first.
+	// Implies Cursors.
+	// Optional, default empty: no logs are replayed, unless FromEpoch is set.
+	ResumeAfter string `json:"resumeAfter,omitempty"`
+}
+
+// EthStorageSlotsSpec selects storage slots of a contract to watch with
+// filecoin_subscribeStorageSlots.
+type EthStorageSlotsSpec struct {
+	Address EthAddress `json:"address"`
+	// Storage keys, left padded to 32 bytes like in eth_getStorageAt.
+	Slots []EthBytes `json:"slots"`
+}
+
+// EthStorageSlotsParams handles raw jsonrpc params for
+// filecoin_subscribeStorageSlots, one spec per watched contract.
+type EthStorageSlotsParams []EthStorageSlotsSpec
+
+// EthStorageSlotChange is sent to filecoin_subscribeStorageSlots subscribers
+// when a watched storage slot changes. The block is the one in which the
+// messages changing the slot were executed.
+type EthStorageSlotChange struct {
+	Address     EthAddress `json:"address"`
+	Slot        EthHash    `json:"slot"`
+	OldValue    EthHash    `json:"oldValue"`
+	NewValue    EthHash    `json:"newValue"`
+	BlockHash   EthHash    `json:"blockHash"`
+	BlockNumber EthUint64  `json:"blockNumber"`
 }
 
 type EthSubscriptionResponse struct {
 	// The persistent identifier for the subscription which can be used to unsubscribe.
 	SubscriptionID EthSubscriptionID `json:"subscription"`
 
-	// The object matching the subscription. This may be a Block (tipset), a Transaction (message) or an EthLog
+	// The object matching the subscription. This may be a Block (tipset), a Transaction (message), an EthLog
+	// or an EthStorageSlotChange
 	Result interface{} `json:"result"`
+
+	// Cursor resuming the subscription after the result, set for logs
+	// subscriptions asking for cursors.
+	Cursor string `json:"cursor,omitempty"`
 }
 
 func GetContractEthAddressFromCode(sender EthAddress, salt [32]byte, initcode []byte) (EthAddress, error) {
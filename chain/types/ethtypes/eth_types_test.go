@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 	"github.com/filecoin-project/go-state-types/builtin"
 )
@@ -193,6 +194,20 @@ func TestMaskedIDInF4(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestCreateAddress(t *testing.T) {
+	deployer, err := ParseEthAddress("0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266")
+	require.NoError(t, err)
+
+	for nonce, expected := range []string{
+		"0x5fbdb2315678afecb367f032d93f642f64180aa3",
+		"0xe7f1725e7734ce288f8367e1bb143e90bb3f0512",
+	} {
+		addr, err := deployer.CreateAddress(uint64(nonce))
+		require.NoError(t, err)
+		require.Equal(t, expected, addr.String())
+	}
+}
+
 func TestUnmarshalEthCall(t *testing.T) {
 	data := `{"from":"0x4D6D86b31a112a05A473c4aE84afaF873f632325","to":"0xFe01CC39f5Ae8553D6914DBb9dC27D219fa22D7f","gas":"0x5","gasPrice":"0x6","value":"0x123","data":""}`
 
@@ -445,3 +460,49 @@ func TestEthHashListUnmarshalJSON(t *testing.T) {
 		require.Equal(t, tc.want, got)
 	}
 }
+
+func TestEthSyncingResultJSON(t *testing.T) {
+	worker := EthUint64(3)
+	testcases := []struct {
+		res  EthSyncingResult
+		want string
+	}{
+		{
+			res:  EthSyncingResult{DoneSync: true},
+			want: `false`,
+		},
+		{
+			res:  EthSyncingResult{StartingBlock: 10, CurrentBlock: 20, HighestBlock: 30},
+			want: `{"startingBlock":"0xa","currentBlock":"0x14","highestBlock":"0x1e"}`,
+		},
+		{
+			res:  EthSyncingResult{StartingBlock: 10, CurrentBlock: 20, HighestBlock: 30, SyncWorker: &worker, SyncStage: "message sync"},
+			want: `{"startingBlock":"0xa","currentBlock":"0x14","highestBlock":"0x1e","syncWorker":"0x3","syncStage":"message sync"}`,
+		},
+	}
+	for _, tc := range testcases {
+		tc := tc
+		t.Run("", func(t *testing.T) {
+			b, err := json.Marshal(tc.res)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, string(b))
+
+			var got EthSyncingResult
+			require.NoError(t, json.Unmarshal(b, &got))
+			require.Equal(t, tc.res, got)
+		})
+	}
+}
+
+func TestBlockTagHeight(t *testing.T) {
+	h, ok := BlockTagHeight("safe", 100)
+	require.True(t, ok)
+	require.Equal(t, abi.ChainEpoch(100-1-SafeEpochDelay), h)
+
+	h, ok = BlockTagHeight("finalized", 10)
+	require.True(t, ok)
+	require.Equal(t, abi.ChainEpoch(0), h)
+
+	_, ok = BlockTagHeight("latest", 100)
+	require.False(t, ok)
+}
@@ -0,0 +1,178 @@
+// Package f4 holds the namespaces of delegated (f4) addresses known to the
+// node.
+//
+// A delegated address is made of the ID of the actor managing its namespace,
+// and of a sub-address assigned by that actor. The Ethereum Address Manager
+// (namespace 10) is built in; experimental actor platforms can register their
+// own namespaces to plug into address validation and the rendering of
+// addresses in the API and CLI. Registered namespaces don't change consensus
+// rules, such as which placeholder actors can send messages, as nodes with
+// different registrations would disagree on the validity of blocks.
+package f4
+
+import (
+	"encoding/hex"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/multiformats/go-varint"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+)
+
+// Namespace handles the delegated addresses of the actor managing it.
+type Namespace interface {
+	// Name is a short human readable name of the namespace, e.g. "eam".
+	Name() string
+	// ValidateSubAddress checks a sub-address is well formed for the
+	// namespace.
+	ValidateSubAddress(sub []byte) error
+	// Format renders a sub-address in the native format of the platform.
+	Format(sub []byte) (string, error)
+	// Parse parses an address in the native format of the platform into a
+	// sub-address. It returns an error if the string isn't in that format.
+	Parse(s string) ([]byte, error)
+}
+
+var (
+	namespacesLk sync.RWMutex
+	namespaces   = map[abi.ActorID]Namespace{
+		abi.ActorID(builtintypes.EthereumAddressManagerActorID): eamNamespace{},
+	}
+)
+
+// RegisterNamespace registers the namespace managed by an actor. It's meant to
+// be called from init functions of packages built into the node.
+func RegisterNamespace(id abi.ActorID, ns Namespace) error {
+	namespacesLk.Lock()
+	defer namespacesLk.Unlock()
+
+	if existing, ok := namespaces[id]; ok {
+		return xerrors.Errorf("f4 namespace %d already registered (%s)", id, existing.Name())
+	}
+	namespaces[id] = ns
+	return nil
+}
+
+// Lookup returns the namespace managed by an actor.
+func Lookup(id abi.ActorID) (Namespace, bool) {
+	namespacesLk.RLock()
+	defer namespacesLk.RUnlock()
+
+	ns, ok := namespaces[id]
+	return ns, ok
+}
+
+// Namespaces returns the IDs of the registered namespaces, in increasing
+// order.
+func Namespaces() []abi.ActorID {
+	namespacesLk.RLock()
+	defer namespacesLk.RUnlock()
+
+	ids := make([]abi.ActorID, 0, len(namespaces))
+	for id := range namespaces {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// Split returns the namespace and sub-address of a delegated address.
+func Split(addr address.Address) (abi.ActorID, []byte, error) {
+	if addr.Protocol() != address.Delegated {
+		return 0, nil, xerrors.Errorf("not a delegated address: %s", addr)
+	}
+	payload := addr.Payload()
+	id, n, err := varint.FromUvarint(payload)
+	if err != nil {
+		return 0, nil, xerrors.Errorf("invalid delegated address namespace in %s: %w", addr, err)
+	}
+	return abi.ActorID(id), payload[n:], nil
+}
+
+// Resolve returns the registered namespace of a delegated address and its
+// sub-address, after checking the sub-address is valid for the namespace.
+func Resolve(addr address.Address) (Namespace, []byte, error) {
+	id, sub, err := Split(addr)
+	if err != nil {
+		return nil, nil, err
+	}
+	ns, ok := Lookup(id)
+	if !ok {
+		return nil, nil, xerrors.Errorf("unknown f4 namespace %d in %s", id, addr)
+	}
+	if err := ns.ValidateSubAddress(sub); err != nil {
+		return nil, nil, xerrors.Errorf("invalid %s address %s: %w", ns.Name(), addr, err)
+	}
+	return ns, sub, nil
+}
+
+// IsRegistered reports whether addr is a valid delegated address in a
+// registered namespace.
+func IsRegistered(addr address.Address) bool {
+	_, _, err := Resolve(addr)
+	return err == nil
+}
+
+// Format renders a delegated address in the native format of its namespace.
+func Format(addr address.Address) (string, error) {
+	ns, sub, err := Resolve(addr)
+	if err != nil {
+		return "", err
+	}
+	return ns.Format(sub)
+}
+
+// Parse parses an address in the native format of the namespace managed by
+// id into a delegated address.
+func Parse(id abi.ActorID, s string) (address.Address, error) {
+	ns, ok := Lookup(id)
+	if !ok {
+		return address.Undef, xerrors.Errorf("unknown f4 namespace %d", id)
+	}
+	sub, err := ns.Parse(s)
+	if err != nil {
+		return address.Undef, xerrors.Errorf("parsing %s address: %w", ns.Name(), err)
+	}
+	if err := ns.ValidateSubAddress(sub); err != nil {
+		return address.Undef, xerrors.Errorf("invalid %s address: %w", ns.Name(), err)
+	}
+	return address.NewDelegatedAddress(uint64(id), sub)
+}
+
+// eamNamespace is the namespace of the Ethereum Address Manager, whose
+// sub-addresses are 20 byte Ethereum addresses.
+type eamNamespace struct{}
+
+const ethAddressLength = 20
+
+// Sub-addresses starting with this prefix are masked ID addresses, which
+// can't be assigned by the EAM.
+var maskedIDPrefix = [ethAddressLength - 8]byte{0xff}
+
+func (eamNamespace) Name() string { return "eam" }
+
+func (eamNamespace) ValidateSubAddress(sub []byte) error {
+	if len(sub) != ethAddressLength {
+		return xerrors.Errorf("expected %d byte sub-address, got %d", ethAddressLength, len(sub))
+	}
+	if string(sub[:len(maskedIDPrefix)]) == string(maskedIDPrefix[:]) {
+		return xerrors.Errorf("sub-address can't be a masked ID")
+	}
+	return nil
+}
+
+func (eamNamespace) Format(sub []byte) (string, error) {
+	return "0x" + hex.EncodeToString(sub), nil
+}
+
+func (eamNamespace) Parse(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		return nil, xerrors.Errorf("expected 0x prefixed address")
+	}
+	return hex.DecodeString(s[2:])
+}
@@ -0,0 +1,92 @@
+package f4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+)
+
+type testNamespace struct{}
+
+func (testNamespace) Name() string { return "test" }
+
+func (testNamespace) ValidateSubAddress(sub []byte) error {
+	if len(sub) != 4 {
+		return xerrors.Errorf("expected 4 bytes")
+	}
+	return nil
+}
+
+func (testNamespace) Format(sub []byte) (string, error) { return "test:" + string(sub), nil }
+
+func (testNamespace) Parse(s string) ([]byte, error) {
+	if len(s) < 5 || s[:5] != "test:" {
+		return nil, xerrors.Errorf("expected test: prefix")
+	}
+	return []byte(s[5:]), nil
+}
+
+func TestEAMNamespace(t *testing.T) {
+	sub := make([]byte, 20)
+	sub[19] = 1
+	addr, err := address.NewDelegatedAddress(builtintypes.EthereumAddressManagerActorID, sub)
+	require.NoError(t, err)
+
+	require.True(t, IsRegistered(addr))
+
+	s, err := Format(addr)
+	require.NoError(t, err)
+	require.Equal(t, "0x0000000000000000000000000000000000000001", s)
+
+	parsed, err := Parse(abi.ActorID(builtintypes.EthereumAddressManagerActorID), s)
+	require.NoError(t, err)
+	require.Equal(t, addr, parsed)
+
+	// masked IDs and short sub-addresses aren't valid EAM addresses
+	masked := append([]byte{0xff}, make([]byte, 19)...)
+	addr, err = address.NewDelegatedAddress(builtintypes.EthereumAddressManagerActorID, masked)
+	require.NoError(t, err)
+	require.False(t, IsRegistered(addr))
+
+	addr, err = address.NewDelegatedAddress(builtintypes.EthereumAddressManagerActorID, sub[:10])
+	require.NoError(t, err)
+	require.False(t, IsRegistered(addr))
+}
+
+func TestRegisterNamespace(t *testing.T) {
+	const id = abi.ActorID(1234)
+
+	addr, err := address.NewDelegatedAddress(uint64(id), []byte("abcd"))
+	require.NoError(t, err)
+	require.False(t, IsRegistered(addr))
+	_, err = Format(addr)
+	require.Error(t, err)
+
+	require.NoError(t, RegisterNamespace(id, testNamespace{}))
+	t.Cleanup(func() {
+		namespacesLk.Lock()
+		delete(namespaces, id)
+		namespacesLk.Unlock()
+	})
+	require.Error(t, RegisterNamespace(id, testNamespace{}))
+	require.Error(t, RegisterNamespace(abi.ActorID(builtintypes.EthereumAddressManagerActorID), testNamespace{}))
+	require.Contains(t, Namespaces(), id)
+
+	require.True(t, IsRegistered(addr))
+
+	s, err := Format(addr)
+	require.NoError(t, err)
+	require.Equal(t, "test:abcd", s)
+
+	parsed, err := Parse(id, s)
+	require.NoError(t, err)
+	require.Equal(t, addr, parsed)
+
+	_, err = Parse(id, "test:abc")
+	require.Error(t, err)
+}
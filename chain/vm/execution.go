@@ -5,10 +5,12 @@ import (
 	"os"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/tag"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/metrics"
@@ -28,6 +30,22 @@ const (
 // the execution environment; see below for definition, methods, and initialization
 var execution *executionEnv
 
+type executionLaneKey struct{}
+
+// WithExecutionLane returns a context the VMs created with run their executions in the given
+// lane, instead of the lane of their VMOpts. This lets callers re-executing tipsets on behalf of
+// RPC clients keep out of the lanes reserved for chain validation.
+func WithExecutionLane(ctx context.Context, lane ExecutionLane) context.Context {
+	return context.WithValue(ctx, executionLaneKey{}, lane)
+}
+
+func executionLaneFromContext(ctx context.Context, def ExecutionLane) ExecutionLane {
+	if lane, ok := ctx.Value(executionLaneKey{}).(ExecutionLane); ok {
+		return lane
+	}
+	return def
+}
+
 // implementation of vm executor with simple sanity check preventing use after free.
 type vmExecutor struct {
 	vmi  Interface
@@ -61,9 +79,11 @@ func (e *vmExecutor) Flush(ctx context.Context) (cid.Cid, error) {
 type executionToken struct {
 	lane     ExecutionLane
 	reserved int
+	start    time.Time
 }
 
 func (token *executionToken) Done() {
+	metricsDuration(metrics.VMExecutionDuration, token.lane, token.start)
 	execution.putToken(token)
 }
 
@@ -75,18 +95,30 @@ type executionEnv struct {
 	available int
 	// reserved executors
 	reserved int
+
+	// configured executors, available and reserved change as tokens are taken
+	totalAvailable int
+	totalReserved  int
 }
 
-func (e *executionEnv) getToken(lane ExecutionLane) *executionToken {
+func (e *executionEnv) getToken(lane ExecutionLane) (token *executionToken) {
 	metricsUp(metrics.VMExecutionWaiting, lane)
 	defer metricsDown(metrics.VMExecutionWaiting, lane)
 
+	start := time.Now()
+	defer func() {
+		metricsDuration(metrics.VMExecutionWaitDuration, lane, start)
+		token.start = time.Now()
+	}()
+
 	e.mx.Lock()
 	defer e.mx.Unlock()
 
+	// available and reserved are negative while the lanes were reduced by SetExecutionLanes, until
+	// the executions holding tokens return them
 	switch lane {
 	case ExecutionLaneDefault:
-		for e.available <= e.reserved {
+		for e.available <= 0 || e.available <= e.reserved {
 			e.cond.Wait()
 		}
 
@@ -96,7 +128,7 @@ func (e *executionEnv) getToken(lane ExecutionLane) *executionToken {
 		return &executionToken{lane: lane, reserved: 0}
 
 	case ExecutionLanePriority:
-		for e.available == 0 {
+		for e.available <= 0 {
 			e.cond.Wait()
 		}
 
@@ -140,6 +172,14 @@ func metricsDown(metric *stats.Int64Measure, lane ExecutionLane) {
 }
 
 func metricsAdjust(metric *stats.Int64Measure, lane ExecutionLane, delta int) {
+	stats.Record(laneMetricsCtx(lane), metric.M(int64(delta)))
+}
+
+func metricsDuration(metric *stats.Float64Measure, lane ExecutionLane, start time.Time) {
+	stats.Record(laneMetricsCtx(lane), metric.M(metrics.SinceInMilliseconds(start)))
+}
+
+func laneMetricsCtx(lane ExecutionLane) context.Context {
 	laneName := "default"
 	if lane > ExecutionLaneDefault {
 		laneName = "priority"
@@ -149,7 +189,50 @@ func metricsAdjust(metric *stats.Int64Measure, lane ExecutionLane, delta int) {
 		context.Background(),
 		tag.Upsert(metrics.ExecutionLane, laneName),
 	)
-	stats.Record(ctx, metric.M(int64(delta)))
+	return ctx
+}
+
+func checkExecutionLanes(available, priority int) error {
+	if available < 2 {
+		return xerrors.Errorf("insufficient execution concurrency: %d", available)
+	}
+
+	if available <= priority {
+		return xerrors.Errorf("insufficient default execution concurrency: %d lanes, %d reserved", available, priority)
+	}
+
+	return nil
+}
+
+// ExecutionLanes returns the number of execution lanes, and the number of lanes reserved for
+// priority computations.
+func ExecutionLanes() (available, priority int) {
+	execution.mx.Lock()
+	defer execution.mx.Unlock()
+
+	return execution.totalAvailable, execution.totalReserved
+}
+
+// SetExecutionLanes replaces the number of execution lanes, and the number of lanes reserved for
+// priority computations, set from the LOTUS_FVM_CONCURRENCY and LOTUS_FVM_CONCURRENCY_RESERVED
+// environment variables. It is meant to be called on startup, before executions start.
+func SetExecutionLanes(available, priority int) error {
+	if err := checkExecutionLanes(available, priority); err != nil {
+		return err
+	}
+
+	execution.mx.Lock()
+	defer execution.mx.Unlock()
+
+	// executions holding tokens return them to the new totals
+	execution.available += available - execution.totalAvailable
+	execution.reserved += priority - execution.totalReserved
+	execution.totalAvailable = available
+	execution.totalReserved = priority
+
+	execution.cond.Broadcast()
+
+	return nil
 }
 
 func init() {
@@ -172,21 +255,19 @@ func init() {
 	}
 
 	// some sanity checks
-	if available < 2 {
-		panic("insufficient execution concurrency")
-	}
-
-	if available <= priority {
-		panic("insufficient default execution concurrency")
+	if err := checkExecutionLanes(available, priority); err != nil {
+		panic(err)
 	}
 
 	mx := &sync.Mutex{}
 	cond := sync.NewCond(mx)
 
 	execution = &executionEnv{
-		mx:        mx,
-		cond:      cond,
-		available: available,
-		reserved:  priority,
+		mx:             mx,
+		cond:           cond,
+		available:      available,
+		reserved:       priority,
+		totalAvailable: available,
+		totalReserved:  priority,
 	}
 }
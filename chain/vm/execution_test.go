@@ -0,0 +1,73 @@
+package vm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestExecutionLaneFromContext(t *testing.T) {
+	ctx := context.Background()
+	require.Equal(t, ExecutionLanePriority, executionLaneFromContext(ctx, ExecutionLanePriority))
+	require.Equal(t, ExecutionLaneDefault, executionLaneFromContext(ctx, ExecutionLaneDefault))
+
+	ctx = WithExecutionLane(ctx, ExecutionLaneDefault)
+	require.Equal(t, ExecutionLaneDefault, executionLaneFromContext(ctx, ExecutionLanePriority))
+}
+
+func TestSetExecutionLanes(t *testing.T) {
+	available, priority := ExecutionLanes()
+	t.Cleanup(func() {
+		require.NoError(t, SetExecutionLanes(available, priority))
+	})
+
+	require.Error(t, SetExecutionLanes(1, 0))
+	require.Error(t, SetExecutionLanes(4, 4))
+
+	require.NoError(t, SetExecutionLanes(8, 3))
+	a, p := ExecutionLanes()
+	require.Equal(t, 8, a)
+	require.Equal(t, 3, p)
+
+	// a held token is returned to the new totals
+	token := execution.getToken(ExecutionLaneDefault)
+	require.NoError(t, SetExecutionLanes(6, 2))
+	token.Done()
+
+	execution.mx.Lock()
+	require.Equal(t, 6, execution.available)
+	require.Equal(t, 2, execution.reserved)
+	execution.mx.Unlock()
+
+	// no lane is available until tokens held over the reduced totals are returned
+	var tokens []*executionToken
+	for i := 0; i < 3; i++ {
+		tokens = append(tokens, execution.getToken(ExecutionLanePriority))
+	}
+	require.NoError(t, SetExecutionLanes(2, 1))
+
+	got := make(chan *executionToken)
+	go func() {
+		got <- execution.getToken(ExecutionLanePriority)
+	}()
+	waiting := func() {
+		select {
+		case <-got:
+			t.Fatal("got a token while none was available")
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	waiting()
+	tokens[0].Done()
+	waiting()
+	tokens[1].Done()
+	(<-got).Done()
+	tokens[2].Done()
+
+	execution.mx.Lock()
+	require.Equal(t, 2, execution.available)
+	require.Equal(t, 1, execution.reserved)
+	execution.mx.Unlock()
+}
@@ -49,9 +49,12 @@ type FvmExtern struct {
 	lbState LookbackStateGetter
 	tsGet   TipSetGetter
 	base    cid.Cid
+
+	syscalls *syscallCounts
 }
 
 func (x *FvmExtern) TipsetCid(ctx context.Context, epoch abi.ChainEpoch) (cid.Cid, error) {
+	x.syscalls.inc(syscallTipsetCid)
 	tsk, err := x.tsGet(ctx, epoch)
 	if err != nil {
 		return cid.Undef, err
@@ -62,6 +65,7 @@ func (x *FvmExtern) TipsetCid(ctx context.Context, epoch abi.ChainEpoch) (cid.Ci
 // VerifyConsensusFault is similar to the one in syscalls.go used by the Lotus VM, except it never errors
 // Errors are logged and "no fault" is returned, which is functionally what go-actors does anyway
 func (x *FvmExtern) VerifyConsensusFault(ctx context.Context, a, b, extra []byte) (*ffi_cgo.ConsensusFault, int64) {
+	x.syscalls.inc(syscallConsensusFault)
 	totalGas := int64(0)
 	ret := &ffi_cgo.ConsensusFault{
 		Type: ffi_cgo.ConsensusFaultNone,
@@ -239,9 +243,11 @@ type FVM struct {
 
 	// returnEvents specifies whether to parse and return events when applying messages.
 	returnEvents bool
+
+	syscalls *syscallCounts
 }
 
-func defaultFVMOpts(ctx context.Context, opts *VMOpts) (*ffi.FVMOpts, error) {
+func defaultFVMOpts(ctx context.Context, opts *VMOpts, sc *syscallCounts) (*ffi.FVMOpts, error) {
 	state, err := state.LoadStateTree(cbor.NewCborStore(opts.Bstore), opts.StateBase)
 	if err != nil {
 		return nil, xerrors.Errorf("loading state tree: %w", err)
@@ -252,15 +258,23 @@ func defaultFVMOpts(ctx context.Context, opts *VMOpts) (*ffi.FVMOpts, error) {
 		return nil, xerrors.Errorf("calculating circ supply: %w", err)
 	}
 
+	var rand Rand = opts.Rand
+	var bs blockstore.Blockstore = opts.Bstore
+	if sc != nil {
+		rand = &countingRand{Rand: rand, sc: sc}
+		bs = &countingBlockstore{Blockstore: bs, sc: sc}
+	}
+
 	return &ffi.FVMOpts{
 		FVMVersion: 0,
 		Externs: &FvmExtern{
-			Rand:       opts.Rand,
-			Blockstore: opts.Bstore,
+			Rand:       rand,
+			Blockstore: bs,
 			lbState:    opts.LookbackState,
 			tsGet:      opts.TipSetGetter,
 			base:       opts.StateBase,
 			epoch:      opts.Epoch,
+			syscalls:   sc,
 		},
 		Epoch:          opts.Epoch,
 		Timestamp:      opts.Timestamp,
@@ -276,7 +290,8 @@ func defaultFVMOpts(ctx context.Context, opts *VMOpts) (*ffi.FVMOpts, error) {
 }
 
 func NewFVM(ctx context.Context, opts *VMOpts) (*FVM, error) {
-	fvmOpts, err := defaultFVMOpts(ctx, opts)
+	sc := newSyscallCounts(opts)
+	fvmOpts, err := defaultFVMOpts(ctx, opts, sc)
 	if err != nil {
 		return nil, xerrors.Errorf("creating fvm opts: %w", err)
 	}
@@ -291,6 +306,7 @@ func NewFVM(ctx context.Context, opts *VMOpts) (*FVM, error) {
 		fvm:          fvm,
 		nv:           opts.NetworkVersion,
 		returnEvents: opts.ReturnEvents,
+		syscalls:     sc,
 	}
 
 	return ret, nil
@@ -303,7 +319,8 @@ func NewDebugFVM(ctx context.Context, opts *VMOpts) (*FVM, error) {
 	vmBstore := blockstore.NewTieredBstore(overlayBstore, baseBstore)
 
 	opts.Bstore = vmBstore
-	fvmOpts, err := defaultFVMOpts(ctx, opts)
+	sc := newSyscallCounts(opts)
+	fvmOpts, err := defaultFVMOpts(ctx, opts, sc)
 	if err != nil {
 		return nil, xerrors.Errorf("creating fvm opts: %w", err)
 	}
@@ -397,6 +414,7 @@ func NewDebugFVM(ctx context.Context, opts *VMOpts) (*FVM, error) {
 		fvm:          fvm,
 		nv:           opts.NetworkVersion,
 		returnEvents: opts.ReturnEvents,
+		syscalls:     sc,
 	}
 
 	return ret, nil
@@ -412,6 +430,7 @@ func (vm *FVM) ApplyMessage(ctx context.Context, cmsg types.ChainMsg) (*ApplyRet
 	}
 
 	ret, err := vm.fvm.ApplyMessage(msgBytes, uint(cmsg.ChainLength()))
+	vm.syscalls.record(ctx)
 	if err != nil {
 		return nil, xerrors.Errorf("applying msg: %w", err)
 	}
@@ -477,6 +496,7 @@ func (vm *FVM) ApplyImplicitMessage(ctx context.Context, cmsg *types.Message) (*
 		return nil, xerrors.Errorf("serializing msg: %w", err)
 	}
 	ret, err := vm.fvm.ApplyImplicitMessage(msgBytes)
+	vm.syscalls.record(ctx)
 	if err != nil {
 		return nil, xerrors.Errorf("applying msg: %w", err)
 	}
@@ -0,0 +1,125 @@
+package vm
+
+import (
+	"context"
+	"sync/atomic"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/metrics"
+)
+
+// The calls the FVM makes to the node through its externs.
+const (
+	syscallBlockGet = iota
+	syscallBlockPut
+	syscallBlockHas
+	syscallChainRandomness
+	syscallBeaconRandomness
+	syscallConsensusFault
+	syscallTipsetCid
+
+	numSyscalls
+)
+
+var syscallNames = [numSyscalls]string{
+	syscallBlockGet:         "ipld_block_get",
+	syscallBlockPut:         "ipld_block_put",
+	syscallBlockHas:         "ipld_block_has",
+	syscallChainRandomness:  "get_chain_randomness",
+	syscallBeaconRandomness: "get_beacon_randomness",
+	syscallConsensusFault:   "verify_consensus_fault",
+	syscallTipsetCid:        "get_tipset_cid",
+}
+
+// syscallCounts counts the calls the FVM makes to the node. Counts are
+// recorded as metrics after each applied message, rather than on every call,
+// as state reads are very frequent.
+type syscallCounts struct {
+	counts [numSyscalls]atomic.Int64
+}
+
+// newSyscallCounts returns the counts of the calls of a VM, or nil, which
+// counts nothing, unless opts.CountSyscalls is set.
+func newSyscallCounts(opts *VMOpts) *syscallCounts {
+	if !opts.CountSyscalls {
+		return nil
+	}
+	return new(syscallCounts)
+}
+
+func (sc *syscallCounts) inc(syscall int) {
+	sc.add(syscall, 1)
+}
+
+func (sc *syscallCounts) add(syscall int, n int64) {
+	if sc != nil {
+		sc.counts[syscall].Add(n)
+	}
+}
+
+// record records the calls made since the last record.
+func (sc *syscallCounts) record(ctx context.Context) {
+	if sc == nil {
+		return
+	}
+	for i := range sc.counts {
+		if n := sc.counts[i].Swap(0); n > 0 {
+			_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(metrics.Syscall, syscallNames[i])}, metrics.VMSyscalls.M(n))
+		}
+	}
+}
+
+// countingBlockstore counts the state reads and writes of the FVM.
+type countingBlockstore struct {
+	blockstore.Blockstore
+	sc *syscallCounts
+}
+
+func (bs *countingBlockstore) Get(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+	bs.sc.inc(syscallBlockGet)
+	return bs.Blockstore.Get(ctx, c)
+}
+
+func (bs *countingBlockstore) View(ctx context.Context, c cid.Cid, cb func([]byte) error) error {
+	bs.sc.inc(syscallBlockGet)
+	return bs.Blockstore.View(ctx, c, cb)
+}
+
+func (bs *countingBlockstore) Has(ctx context.Context, c cid.Cid) (bool, error) {
+	bs.sc.inc(syscallBlockHas)
+	return bs.Blockstore.Has(ctx, c)
+}
+
+func (bs *countingBlockstore) Put(ctx context.Context, b blocks.Block) error {
+	bs.sc.inc(syscallBlockPut)
+	return bs.Blockstore.Put(ctx, b)
+}
+
+func (bs *countingBlockstore) PutMany(ctx context.Context, blks []blocks.Block) error {
+	bs.sc.add(syscallBlockPut, int64(len(blks)))
+	return bs.Blockstore.PutMany(ctx, blks)
+}
+
+// countingRand counts the randomness requests of the FVM.
+type countingRand struct {
+	Rand
+	sc *syscallCounts
+}
+
+func (r *countingRand) GetChainRandomness(ctx context.Context, pers crypto.DomainSeparationTag, round abi.ChainEpoch, entropy []byte) ([]byte, error) {
+	r.sc.inc(syscallChainRandomness)
+	return r.Rand.GetChainRandomness(ctx, pers, round, entropy)
+}
+
+func (r *countingRand) GetBeaconRandomness(ctx context.Context, pers crypto.DomainSeparationTag, round abi.ChainEpoch, entropy []byte) ([]byte, error) {
+	r.sc.inc(syscallBeaconRandomness)
+	return r.Rand.GetBeaconRandomness(ctx, pers, round, entropy)
+}
@@ -0,0 +1,46 @@
+package vm
+
+import (
+	"context"
+	"testing"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/blockstore"
+)
+
+func TestCountingBlockstore(t *testing.T) {
+	ctx := context.Background()
+	sc := new(syscallCounts)
+	bs := &countingBlockstore{Blockstore: blockstore.NewMemorySync(), sc: sc}
+
+	b := blocks.NewBlock([]byte("hello"))
+	require.NoError(t, bs.Put(ctx, b))
+	require.NoError(t, bs.PutMany(ctx, []blocks.Block{blocks.NewBlock([]byte("a")), blocks.NewBlock([]byte("b"))}))
+	_, err := bs.Get(ctx, b.Cid())
+	require.NoError(t, err)
+	require.NoError(t, bs.View(ctx, b.Cid(), func([]byte) error { return nil }))
+	has, err := bs.Has(ctx, b.Cid())
+	require.NoError(t, err)
+	require.True(t, has)
+
+	require.EqualValues(t, 3, sc.counts[syscallBlockPut].Load())
+	require.EqualValues(t, 2, sc.counts[syscallBlockGet].Load())
+	require.EqualValues(t, 1, sc.counts[syscallBlockHas].Load())
+
+	// recording resets the counts
+	sc.record(ctx)
+	for i := range sc.counts {
+		require.Zero(t, sc.counts[i].Load(), syscallNames[i])
+	}
+
+	// a VM without counts doesn't count
+	var nilCounts *syscallCounts
+	nilCounts.inc(syscallTipsetCid)
+	nilCounts.record(ctx)
+
+	// only explicit calls count
+	require.Nil(t, newSyscallCounts(&VMOpts{}))
+	require.NotNil(t, newSyscallCounts(&VMOpts{CountSyscalls: true}))
+}
@@ -170,6 +170,9 @@ func (vm *LegacyVM) makeRuntime(ctx context.Context, msg *types.Message, parent
 	if rt.depth > MaxCallDepth && rt.NetworkVersion() >= network.Version6 {
 		rt.Abortf(exitcode.SysErrForbidden, "message execution exceeds call depth")
 	}
+	if vm.maxCallDepth > 0 && rt.depth >= uint64(vm.maxCallDepth) {
+		rt.Abortf(exitcode.SysErrForbidden, "message execution exceeds the call depth limit of %d", vm.maxCallDepth)
+	}
 
 	cbb := &gasChargingBlocks{rt.chargeGasFunc(2), rt.pricelist, vm.cst.Blocks}
 	cst := cbor.NewCborStore(cbb)
@@ -230,6 +233,7 @@ type LegacyVM struct {
 	baseFee        abi.TokenAmount
 	lbStateGet     LookbackStateGetter
 	baseCircSupply abi.TokenAmount
+	maxCallDepth   int
 
 	Syscalls SyscallBuilder
 }
@@ -252,6 +256,15 @@ type VMOpts struct {
 	ReturnEvents bool
 	// ExecutionLane specifies the execution priority of the created vm
 	ExecutionLane ExecutionLane
+	// MaxCallDepth aborts the nested actor calls deeper than it as they are
+	// entered, the call of the message being at depth 1. Only the legacy VM
+	// enforces it, the FVM can't be interrupted between actor calls. It's
+	// meant for explicit calls, consensus execution leaves it unset.
+	MaxCallDepth int
+	// CountSyscalls records the calls the FVM makes to the node as metrics.
+	// It's meant for explicit calls, counting the calls of consensus
+	// execution would slow it down.
+	CountSyscalls bool
 }
 
 func NewLegacyVM(ctx context.Context, opts *VMOpts) (*LegacyVM, error) {
@@ -284,6 +297,7 @@ func NewLegacyVM(ctx context.Context, opts *VMOpts) (*LegacyVM, error) {
 		baseFee:        opts.BaseFee,
 		baseCircSupply: baseCirc,
 		lbStateGet:     opts.LookbackState,
+		maxCallDepth:   opts.MaxCallDepth,
 	}, nil
 }
 
@@ -55,10 +55,11 @@ func makeVM(ctx context.Context, opts *VMOpts) (Interface, error) {
 }
 
 func NewVM(ctx context.Context, opts *VMOpts) (Interface, error) {
-	switch opts.ExecutionLane {
+	lane := executionLaneFromContext(ctx, opts.ExecutionLane)
+	switch lane {
 	case ExecutionLaneDefault, ExecutionLanePriority:
 	default:
-		return nil, fmt.Errorf("invalid execution lane: %d", opts.ExecutionLane)
+		return nil, fmt.Errorf("invalid execution lane: %d", lane)
 	}
 
 	vmi, err := makeVM(ctx, opts)
@@ -66,5 +67,5 @@ func NewVM(ctx context.Context, opts *VMOpts) (Interface, error) {
 		return nil, err
 	}
 
-	return newVMExecutor(vmi, opts.ExecutionLane), nil
+	return newVMExecutor(vmi, lane), nil
 }
@@ -0,0 +1,87 @@
+package key
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"strings"
+
+	"github.com/decred/dcrd/dcrec/secp256k1/v4"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+const hardened = 1 << 31
+
+// ethDerivationPath is the BIP-44 path of Ethereum accounts (m/44'/60'/0'/0),
+// the account index is appended to it.
+var ethDerivationPath = []uint32{44 | hardened, 60 | hardened, 0 | hardened, 0}
+
+// DeriveEthKey derives the key of the Ethereum account with the given index
+// from a BIP-39 mnemonic, along the m/44'/60'/0'/0/index path used by Ethereum
+// wallets and development tools, and returns it as a delegated key. The
+// mnemonic words aren't checked against the BIP-39 word list.
+func DeriveEthKey(mnemonic, passphrase string, index uint32) (*Key, error) {
+	if index >= hardened {
+		return nil, xerrors.Errorf("account index %d out of range", index)
+	}
+
+	// BIP-39 seed
+	normalized := strings.Join(strings.Fields(mnemonic), " ")
+	seed := pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+
+	// BIP-32 master key
+	mac := hmac.New(sha512.New, []byte("Bitcoin seed"))
+	_, _ = mac.Write(seed)
+	sum := mac.Sum(nil)
+
+	var k secp256k1.ModNScalar
+	if overflow := k.SetByteSlice(sum[:32]); overflow || k.IsZero() {
+		return nil, xerrors.Errorf("invalid master key derived from mnemonic")
+	}
+	chainCode := sum[32:]
+
+	for _, i := range append(ethDerivationPath, index) {
+		var err error
+		k, chainCode, err = deriveChildKey(k, chainCode, i)
+		if err != nil {
+			return nil, xerrors.Errorf("deriving child key %d: %w", i&^hardened, err)
+		}
+	}
+
+	pk := k.Bytes()
+	return NewKey(types.KeyInfo{
+		Type:       types.KTDelegated,
+		PrivateKey: pk[:],
+	})
+}
+
+// deriveChildKey implements the BIP-32 private parent key to private child key
+// derivation.
+func deriveChildKey(k secp256k1.ModNScalar, chainCode []byte, i uint32) (secp256k1.ModNScalar, []byte, error) {
+	var data []byte
+	if i >= hardened {
+		kb := k.Bytes()
+		data = append([]byte{0}, kb[:]...)
+	} else {
+		data = secp256k1.NewPrivateKey(&k).PubKey().SerializeCompressed()
+	}
+	data = binary.BigEndian.AppendUint32(data, i)
+
+	mac := hmac.New(sha512.New, chainCode)
+	_, _ = mac.Write(data)
+	sum := mac.Sum(nil)
+
+	var child secp256k1.ModNScalar
+	if overflow := child.SetByteSlice(sum[:32]); overflow {
+		return child, nil, xerrors.Errorf("invalid child key")
+	}
+	child.Add(&k)
+	if child.IsZero() {
+		return child, nil, xerrors.Errorf("invalid child key")
+	}
+
+	return child, sum[32:], nil
+}
@@ -0,0 +1,32 @@
+package key
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	_ "github.com/filecoin-project/lotus/lib/sigs/delegated"
+)
+
+func TestDeriveEthKey(t *testing.T) {
+	// well known development mnemonic and accounts
+	const mnemonic = "test test test test test test test test test test test junk"
+
+	for i, tc := range []struct {
+		priv string
+		addr string
+	}{
+		{"ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80", "0xf39fd6e51aad88f6f4ce6ab8827279cfffb92266"},
+		{"59c6995e998f97a5a0044966f0945389dc9e86dae88c7a8412f4603b6b78690d", "0x70997970c51812dc3a010c7d01b50e0d17dc79c8"},
+	} {
+		k, err := DeriveEthKey(mnemonic, "", uint32(i))
+		require.NoError(t, err)
+		require.Equal(t, tc.priv, hex.EncodeToString(k.PrivateKey))
+
+		ea, err := ethtypes.EthAddressFromFilecoinAddress(k.Address)
+		require.NoError(t, err)
+		require.Equal(t, tc.addr, ea.String())
+	}
+}
@@ -0,0 +1,292 @@
+// Package signpolicy wraps a wallet with a signing policy, rate limiting signatures and refusing to
+// sign high value messages until they're approved.
+package signpolicy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/messagesigner"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var log = logging.Logger("wallet-policy")
+
+var (
+	ErrRateLimited      = xerrors.New("signing rate limit exceeded")
+	ErrApprovalRequired = xerrors.New("signing request requires approval")
+	ErrSignTypeDenied   = xerrors.New("signing request type not allowed by the approval policy")
+)
+
+const webhookTimeout = 10 * time.Second
+
+type Policy struct {
+	// Addresses the policy applies to, all addresses when empty.
+	Addresses []address.Address
+
+	// MaxSignatures is the number of signatures allowed per address in each RateInterval, 0
+	// doesn't limit signing. Block signatures are never limited.
+	MaxSignatures int
+	RateInterval  time.Duration
+
+	// ApprovalThreshold is the value from which messages require approval, nil or zero
+	// disables approvals. When approvals are enabled, the addresses the policy applies to
+	// only sign messages and blocks, other signing requests can't be checked and are denied.
+	ApprovalThreshold abi.TokenAmount
+	// ApprovalWebhook is notified of new approval requests when set.
+	ApprovalWebhook string
+	// ApprovalTimeout is how long approval requests, and approvals until the message is
+	// sent again, are kept.
+	ApprovalTimeout time.Duration
+}
+
+type PolicyWallet struct {
+	api.Wallet
+
+	policy Policy
+	addrs  map[address.Address]struct{}
+	client *http.Client
+
+	lk       sync.Mutex
+	limiters map[address.Address]*rate.Limiter
+	// pending holds the approval requests by the intent of their message, see intentKey
+	pending map[string]*api.WalletApproval
+}
+
+func New(w api.Wallet, p Policy) (*PolicyWallet, error) {
+	if p.MaxSignatures > 0 && p.RateInterval <= 0 {
+		return nil, xerrors.Errorf("signing rate interval must be positive")
+	}
+	if p.requiresApproval() && p.ApprovalTimeout <= 0 {
+		return nil, xerrors.Errorf("approval timeout must be positive")
+	}
+
+	pw := &PolicyWallet{
+		Wallet:   w,
+		policy:   p,
+		client:   &http.Client{Timeout: webhookTimeout},
+		limiters: map[address.Address]*rate.Limiter{},
+		pending:  map[string]*api.WalletApproval{},
+	}
+	if len(p.Addresses) > 0 {
+		pw.addrs = map[address.Address]struct{}{}
+		for _, a := range p.Addresses {
+			pw.addrs[a] = struct{}{}
+		}
+	}
+	return pw, nil
+}
+
+func (p Policy) requiresApproval() bool {
+	return !p.ApprovalThreshold.Nil() && p.ApprovalThreshold.GreaterThan(big.Zero())
+}
+
+// WalletSign signs when the policy allows it. Messages requiring approval aren't held: an
+// approval request is made and an approval_required error returned, and the message is signed
+// once it's sent again after the request is approved, so that signers don't wait on approvals.
+func (w *PolicyWallet) WalletSign(ctx context.Context, signer address.Address, toSign []byte, meta api.MsgMeta) (*crypto.Signature, error) {
+	if !w.applies(signer) {
+		return w.Wallet.WalletSign(ctx, signer, toSign, meta)
+	}
+
+	if w.policy.requiresApproval() {
+		switch meta.Type {
+		case api.MTChainMsg:
+			msg, err := signedMessage(signer, toSign, meta)
+			if err != nil {
+				return nil, err
+			}
+			if msg.Value.GreaterThanEqual(w.policy.ApprovalThreshold) {
+				if err := w.checkApproval(signer, msg); err != nil {
+					return nil, err
+				}
+			}
+		case api.MTBlock:
+			// block signing bytes are the header without its signature, which can't be a message
+			if _, err := types.DecodeBlock(toSign); err != nil {
+				return nil, xerrors.Errorf("%w: the signed data of %s request isn't a block header", ErrSignTypeDenied, meta.Type)
+			}
+		default:
+			return nil, xerrors.Errorf("%w: %s requests can't be checked for %s", ErrSignTypeDenied, meta.Type, signer)
+		}
+	}
+
+	if meta.Type != api.MTBlock && !w.allow(signer) {
+		return nil, xerrors.Errorf("%w: %d signatures per %s for %s", ErrRateLimited, w.policy.MaxSignatures, w.policy.RateInterval, signer)
+	}
+
+	return w.Wallet.WalletSign(ctx, signer, toSign, meta)
+}
+
+// signedMessage decodes the message of a chain message signing request, checking that the signed
+// data is the message's, so that approvals can't be bypassed with a different message in Extra.
+func signedMessage(signer address.Address, toSign []byte, meta api.MsgMeta) (*types.Message, error) {
+	msg, err := types.DecodeMessage(meta.Extra)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding message: %w", err)
+	}
+	sb, err := messagesigner.SigningBytes(msg, signer.Protocol())
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(sb, toSign) {
+		return nil, xerrors.Errorf("%w: the signed data isn't the signing bytes of the message", ErrSignTypeDenied)
+	}
+	return msg, nil
+}
+
+func (w *PolicyWallet) applies(signer address.Address) bool {
+	if w.addrs == nil {
+		return true
+	}
+	_, ok := w.addrs[signer]
+	return ok
+}
+
+func (w *PolicyWallet) allow(signer address.Address) bool {
+	if w.policy.MaxSignatures <= 0 {
+		return true
+	}
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	l, ok := w.limiters[signer]
+	if !ok {
+		every := w.policy.RateInterval / time.Duration(w.policy.MaxSignatures)
+		l = rate.NewLimiter(rate.Every(every), w.policy.MaxSignatures)
+		w.limiters[signer] = l
+	}
+	return l.Allow()
+}
+
+// intentKey identifies the messages an approval is for. The nonce and the gas of a message may
+// change when it's sent again after it's approved, so they aren't part of it.
+func intentKey(signer address.Address, msg *types.Message) string {
+	return fmt.Sprintf("%s/%s/%s/%d/%x", signer, msg.To, msg.Value, msg.Method, msg.Params)
+}
+
+// checkApproval consumes the approval of msg, or requests one and returns an approval_required
+// error.
+func (w *PolicyWallet) checkApproval(signer address.Address, msg *types.Message) error {
+	key := intentKey(signer, msg)
+	now := time.Now()
+
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.expire(now)
+
+	p, ok := w.pending[key]
+	switch {
+	case ok && p.Approved:
+		delete(w.pending, key)
+		log.Infow("signing approved request", "id", p.ID, "signer", signer)
+		return nil
+	case !ok:
+		p = &api.WalletApproval{
+			ID:         uuid.New(),
+			Signer:     signer,
+			Message:    msg,
+			MessageCid: msg.Cid(),
+			Requested:  now,
+			Expires:    now.Add(w.policy.ApprovalTimeout),
+		}
+		w.pending[key] = p
+
+		log.Warnw("signing request requires approval", "id", p.ID, "signer", signer, "to", msg.To, "value", types.FIL(msg.Value), "expires", p.Expires)
+		if w.policy.ApprovalWebhook != "" {
+			go w.notify(*p)
+		}
+	}
+
+	err := api.NewErrTransactionRejected("approval_required", true, "%w: approve request %s before %s, then send the message again", ErrApprovalRequired, p.ID, p.Expires.Format(time.RFC3339))
+	err.Details = map[string]interface{}{"id": p.ID.String(), "expires": p.Expires}
+	return err
+}
+
+// expire drops the requests and approvals which expired, w.lk must be held.
+func (w *PolicyWallet) expire(now time.Time) {
+	for k, p := range w.pending {
+		if now.After(p.Expires) {
+			delete(w.pending, k)
+		}
+	}
+}
+
+// notify posts a held request to the approval webhook, it can then be approved through the API.
+func (w *PolicyWallet) notify(a api.WalletApproval) {
+	b, err := json.Marshal(a)
+	if err != nil {
+		log.Errorw("encoding approval request", "id", a.ID, "error", err)
+		return
+	}
+
+	resp, err := w.client.Post(w.policy.ApprovalWebhook, "application/json", bytes.NewReader(b))
+	if err != nil {
+		log.Errorw("notifying approval webhook", "id", a.ID, "error", err)
+		return
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		log.Errorw("notifying approval webhook", "id", a.ID, "status", resp.Status)
+	}
+}
+
+// Pending lists the approval requests, and the approved messages which weren't sent again yet,
+// oldest first.
+func (w *PolicyWallet) Pending() []api.WalletApproval {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.expire(time.Now())
+
+	out := make([]api.WalletApproval, 0, len(w.pending))
+	for _, p := range w.pending {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		return out[i].Requested.Before(out[j].Requested)
+	})
+	return out
+}
+
+// Decide approves or rejects an approval request. Approved messages are signed once when they're
+// sent again, before the request expires.
+func (w *PolicyWallet) Decide(id uuid.UUID, approve bool) error {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	w.expire(time.Now())
+
+	for k, p := range w.pending {
+		if p.ID != id || p.Approved {
+			continue
+		}
+		if approve {
+			p.Approved = true
+		} else {
+			delete(w.pending, k)
+		}
+		log.Infow("signing request decided", "id", id, "approved", approve)
+		return nil
+	}
+	return xerrors.Errorf("no signing request %s pending approval", id)
+}
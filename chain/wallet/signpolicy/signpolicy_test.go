@@ -0,0 +1,173 @@
+// stm: #unit
+package signpolicy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/wallet"
+)
+
+func TestRateLimit(t *testing.T) {
+	ctx := context.Background()
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	a1, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+	a2, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	pw, err := New(w, Policy{MaxSignatures: 2, RateInterval: time.Hour})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		_, err := pw.WalletSign(ctx, a1, []byte("data"), api.MsgMeta{})
+		require.NoError(t, err)
+	}
+	_, err = pw.WalletSign(ctx, a1, []byte("data"), api.MsgMeta{})
+	require.ErrorIs(t, err, ErrRateLimited)
+
+	// blocks are never limited
+	_, err = pw.WalletSign(ctx, a1, []byte("data"), api.MsgMeta{Type: api.MTBlock})
+	require.NoError(t, err)
+
+	// limits are per address
+	_, err = pw.WalletSign(ctx, a2, []byte("data"), api.MsgMeta{})
+	require.NoError(t, err)
+}
+
+func TestApproval(t *testing.T) {
+	ctx := context.Background()
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	from, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	notified := make(chan api.WalletApproval, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		var a api.WalletApproval
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&a))
+		notified <- a
+	}))
+	defer srv.Close()
+
+	pw, err := New(w, Policy{
+		ApprovalThreshold: types.FromFil(10),
+		ApprovalWebhook:   srv.URL,
+		ApprovalTimeout:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	sign := func(value big.Int) error {
+		msg := &types.Message{From: from, To: from, Value: value, GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+		mb, err := msg.ToStorageBlock()
+		require.NoError(t, err)
+		_, err = pw.WalletSign(ctx, from, mb.Cid().Bytes(), api.MsgMeta{Type: api.MTChainMsg, Extra: mb.RawData()})
+		return err
+	}
+
+	// below the threshold
+	require.NoError(t, sign(types.FromFil(1)))
+
+	for _, approve := range []bool{true, false} {
+		// the request fails right away, asking for approval
+		err := sign(types.FromFil(10))
+		require.ErrorIs(t, err, ErrApprovalRequired)
+		se, _, ok := api.AsStructuredError(err)
+		require.True(t, ok)
+		require.Equal(t, "approval_required", se.Reason)
+
+		a := <-notified
+		require.Equal(t, from, a.Signer)
+		require.Equal(t, a.ID.String(), se.Details["id"])
+		pending := pw.Pending()
+		require.Len(t, pending, 1)
+		require.Equal(t, a.ID, pending[0].ID)
+		require.Equal(t, a.MessageCid, pending[0].MessageCid)
+
+		// sending the message again doesn't make another request
+		require.ErrorIs(t, sign(types.FromFil(10)), ErrApprovalRequired)
+		require.Len(t, pw.Pending(), 1)
+
+		require.NoError(t, pw.Decide(a.ID, approve))
+		if approve {
+			require.True(t, pw.Pending()[0].Approved)
+			require.Error(t, pw.Decide(a.ID, true))
+
+			// an approval is for its message only, and is used once
+			require.ErrorIs(t, sign(types.FromFil(11)), ErrApprovalRequired)
+			<-notified
+			require.NoError(t, sign(types.FromFil(10)))
+			require.Len(t, pw.Pending(), 1)
+			pw.pending = map[string]*api.WalletApproval{}
+		}
+		require.Empty(t, pw.Pending())
+	}
+
+	// requests expire
+	pw.policy.ApprovalTimeout = -time.Second
+	require.ErrorIs(t, sign(types.FromFil(10)), ErrApprovalRequired)
+	<-notified
+	require.Empty(t, pw.Pending())
+}
+
+func TestApprovalSignTypes(t *testing.T) {
+	ctx := context.Background()
+
+	w, err := wallet.NewWallet(wallet.NewMemKeyStore())
+	require.NoError(t, err)
+	from, err := w.WalletNew(ctx, types.KTSecp256k1)
+	require.NoError(t, err)
+
+	pw, err := New(w, Policy{
+		ApprovalThreshold: types.FromFil(10),
+		ApprovalTimeout:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	msg := &types.Message{From: from, To: from, Value: types.FromFil(10), GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+	mb, err := msg.ToStorageBlock()
+	require.NoError(t, err)
+
+	// raw signatures could sign any message
+	for _, typ := range []api.MsgType{api.MTUnknown, api.MTDealProposal} {
+		_, err = pw.WalletSign(ctx, from, mb.Cid().Bytes(), api.MsgMeta{Type: typ})
+		require.ErrorIs(t, err, ErrSignTypeDenied)
+	}
+	_, err = pw.WalletSign(ctx, from, mb.Cid().Bytes(), api.MsgMeta{Type: api.MTBlock})
+	require.ErrorIs(t, err, ErrSignTypeDenied)
+
+	// the message in Extra must be the signed one
+	small := &types.Message{From: from, To: from, Value: types.FromFil(1), GasFeeCap: big.Zero(), GasPremium: big.Zero()}
+	sb, err := small.ToStorageBlock()
+	require.NoError(t, err)
+	_, err = pw.WalletSign(ctx, from, mb.Cid().Bytes(), api.MsgMeta{Type: api.MTChainMsg, Extra: sb.RawData()})
+	require.ErrorIs(t, err, ErrSignTypeDenied)
+
+	bh := &types.BlockHeader{
+		Miner:                 from,
+		Parents:               []cid.Cid{mb.Cid()},
+		ParentMessageReceipts: mb.Cid(),
+		ParentStateRoot:       mb.Cid(),
+		Messages:              mb.Cid(),
+		ParentWeight:          big.Zero(),
+		ParentBaseFee:         big.Zero(),
+	}
+	bsb, err := bh.SigningBytes()
+	require.NoError(t, err)
+	_, err = pw.WalletSign(ctx, from, bsb, api.MsgMeta{Type: api.MTBlock})
+	require.NoError(t, err)
+}
@@ -4,7 +4,10 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
+	"time"
 
+	"github.com/ipfs/go-datastore"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/mitchellh/go-homedir"
 	"github.com/urfave/cli/v2"
@@ -12,12 +15,16 @@ import (
 
 	"github.com/filecoin-project/go-jsonrpc"
 
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/lib/backupds"
 	"github.com/filecoin-project/lotus/node/repo"
+	"github.com/filecoin-project/lotus/node/repo/archive"
 )
 
 type BackupAPI interface {
 	CreateBackup(ctx context.Context, fpath string) error
+	BackupCreate(ctx context.Context, fpath string, opts api.BackupOptions) (*api.BackupInfo, error)
 }
 
 type BackupApiFn func(ctx *cli.Context) (BackupAPI, jsonrpc.ClientCloser, error)
@@ -133,5 +140,209 @@ this command must be within this base path`,
 
 			return onlineBackup(cctx)
 		},
+		Subcommands: []*cli.Command{
+			backupCreateCmd(repoFlag, rt, getApi),
+			backupRestoreCmd(repoFlag, rt),
+		},
+	}
+}
+
+var backupArchiveFlags = []cli.Flag{
+	&cli.StringSliceFlag{
+		Name:  "section",
+		Usage: fmt.Sprintf("repo sections to include, all when not set: %s", strings.Join(archive.Sections, ", ")),
+	},
+	&cli.StringFlag{
+		Name:  "passphrase-file",
+		Usage: "file holding the passphrase encrypting the archive",
+	},
+}
+
+func backupOptions(cctx *cli.Context) (api.BackupOptions, error) {
+	opts := api.BackupOptions{
+		Sections: cctx.StringSlice("section"),
+	}
+	if pf := cctx.String("passphrase-file"); pf != "" {
+		pf, err := homedir.Expand(pf)
+		if err != nil {
+			return opts, xerrors.Errorf("expanding passphrase file path: %w", err)
+		}
+		b, err := os.ReadFile(pf)
+		if err != nil {
+			return opts, xerrors.Errorf("reading passphrase file: %w", err)
+		}
+		opts.Passphrase = strings.TrimRight(string(b), "\r\n")
+		if opts.Passphrase == "" {
+			return opts, xerrors.Errorf("empty passphrase in %s", pf)
+		}
+	}
+	return opts, nil
+}
+
+// withOfflineRepo locks the repo, for backup operations with the node stopped.
+func withOfflineRepo(cctx *cli.Context, repoFlag string, rt repo.RepoType, cb func(lr repo.LockedRepo, mds datastore.Batching) error) error {
+	logging.SetLogLevel("badger", "ERROR") // nolint:errcheck
+
+	r, err := repo.NewFS(cctx.String(repoFlag))
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.Exists()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return xerrors.Errorf("repo at '%s' is not initialized", cctx.String(repoFlag))
+	}
+
+	lr, err := r.Lock(rt)
+	if err != nil {
+		return xerrors.Errorf("locking repo: %w", err)
+	}
+	defer lr.Close() // nolint:errcheck
+
+	mds, err := lr.Datastore(cctx.Context, "/metadata")
+	if err != nil {
+		return xerrors.Errorf("getting metadata datastore: %w", err)
+	}
+
+	return cb(lr, mds)
+}
+
+func printBackupInfo(cctx *cli.Context, info *api.BackupInfo) {
+	afmt := NewAppFmt(cctx.App)
+	afmt.Printf("Created: %s\n", info.Created.Format(time.RFC3339))
+	afmt.Printf("Node type: %s\n", info.NodeType)
+	afmt.Printf("Encrypted: %t\n", info.Encrypted)
+	for _, s := range info.Sections {
+		afmt.Printf("  %s: %d entries, %s\n", s.Name, s.Entries, types.SizeStr(types.NewInt(uint64(s.Size))))
+	}
+}
+
+func backupCreateCmd(repoFlag string, rt repo.RepoType, getApi BackupApiFn) *cli.Command {
+	return &cli.Command{
+		Name:  "create",
+		Usage: "Create an archive of the node repo",
+		Description: `Creates an archive of the keystore, peer identity, config and metadata
+datastore, with checksums of its content. The archive is encrypted when a
+passphrase file is given.
+
+Online backups require LOTUS_BACKUP_BASE_PATH to be set on the node, the
+archive path being within it.`,
+		Flags: append([]cli.Flag{
+			&cli.BoolFlag{
+				Name:  "offline",
+				Usage: "use the repo directly, with the node stopped",
+			},
+		}, backupArchiveFlags...),
+		ArgsUsage: "[archive path]",
+		Action: func(cctx *cli.Context) error {
+			if cctx.NArg() != 1 {
+				return IncorrectNumArgs(cctx)
+			}
+
+			opts, err := backupOptions(cctx)
+			if err != nil {
+				return err
+			}
+
+			fpath, err := homedir.Expand(cctx.Args().First())
+			if err != nil {
+				return xerrors.Errorf("expanding file path: %w", err)
+			}
+
+			var info *api.BackupInfo
+			if cctx.Bool("offline") {
+				err = withOfflineRepo(cctx, repoFlag, rt, func(lr repo.LockedRepo, mds datastore.Batching) error {
+					bds, err := backupds.Wrap(mds, backupds.NoLogdir)
+					if err != nil {
+						return err
+					}
+
+					out, err := os.OpenFile(fpath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+					if err != nil {
+						return xerrors.Errorf("opening archive file %s: %w", fpath, err)
+					}
+
+					m, err := archive.Create(cctx.Context, out, lr, bds, archive.Options{
+						Sections:   opts.Sections,
+						Passphrase: opts.Passphrase,
+					})
+					if cerr := out.Close(); cerr != nil && err == nil {
+						err = cerr
+					}
+					if err != nil {
+						_ = os.Remove(fpath)
+						return xerrors.Errorf("creating archive: %w", err)
+					}
+
+					info = m.Info()
+					return nil
+				})
+			} else {
+				var bapi BackupAPI
+				var closer jsonrpc.ClientCloser
+				bapi, closer, err = getApi(cctx)
+				if err != nil {
+					return xerrors.Errorf("getting api: %w (if the node isn't running you can use the --offline flag)", err)
+				}
+				defer closer()
+
+				info, err = bapi.BackupCreate(ReqContext(cctx), fpath, opts)
+			}
+			if err != nil {
+				return err
+			}
+
+			printBackupInfo(cctx, info)
+			return nil
+		},
+	}
+}
+
+func backupRestoreCmd(repoFlag string, rt repo.RepoType) *cli.Command {
+	return &cli.Command{
+		Name:  "restore",
+		Usage: "Restore the node repo from an archive, with the node stopped",
+		Description: `Checks the integrity of the archive, then restores the selected sections into
+the node repo. Keys and metadata entries in the archive replace the existing
+ones. The repo is locked while it's restored, so the node must be stopped.`,
+		Flags:     backupArchiveFlags,
+		ArgsUsage: "[archive path]",
+		Action: func(cctx *cli.Context) error {
+			if cctx.NArg() != 1 {
+				return IncorrectNumArgs(cctx)
+			}
+
+			opts, err := backupOptions(cctx)
+			if err != nil {
+				return err
+			}
+
+			fpath, err := homedir.Expand(cctx.Args().First())
+			if err != nil {
+				return xerrors.Errorf("expanding file path: %w", err)
+			}
+
+			var info *api.BackupInfo
+			err = withOfflineRepo(cctx, repoFlag, rt, func(lr repo.LockedRepo, mds datastore.Batching) error {
+				m, err := archive.Restore(cctx.Context, fpath, lr, mds, archive.Options{
+					Sections:   opts.Sections,
+					Passphrase: opts.Passphrase,
+				})
+				if err != nil {
+					return xerrors.Errorf("restoring archive: %w", err)
+				}
+				info = m.Info()
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			printBackupInfo(cctx, info)
+			return nil
+		},
 	}
 }
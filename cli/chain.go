@@ -3,6 +3,7 @@ package cli
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
@@ -15,6 +16,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"text/tabwriter"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -26,6 +28,7 @@ import (
 	cborutil "github.com/filecoin-project/go-cbor-util"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/specs-actors/actors/builtin"
 	"github.com/filecoin-project/specs-actors/actors/builtin/account"
 	"github.com/filecoin-project/specs-actors/actors/builtin/market"
@@ -39,6 +42,7 @@ import (
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/consensus"
+	"github.com/filecoin-project/lotus/chain/snapshotter"
 	"github.com/filecoin-project/lotus/chain/types"
 )
 
@@ -51,12 +55,16 @@ var ChainCmd = &cli.Command{
 		ChainReadObjCmd,
 		ChainDeleteObjCmd,
 		ChainStatObjCmd,
+		ChainWalkObjCmd,
+		ChainFindObjPathCmd,
+		ChainExportObjCmd,
 		ChainGetMsgCmd,
 		ChainSetHeadCmd,
 		ChainListCmd,
 		ChainGetCmd,
 		ChainBisectCmd,
 		ChainExportCmd,
+		ChainExportStreamCmd,
 		ChainExportRangeCmd,
 		SlashConsensusFault,
 		ChainGasPriceCmd,
@@ -65,6 +73,7 @@ var ChainCmd = &cli.Command{
 		ChainEncodeCmd,
 		ChainDisputeSetCmd,
 		ChainPruneCmd,
+		ChainIndexQueryCmd,
 	},
 }
 
@@ -314,6 +323,185 @@ var ChainStatObjCmd = &cli.Command{
 	},
 }
 
+var dagWalkFlags = []cli.Flag{
+	&cli.IntFlag{
+		Name:  "max-depth",
+		Usage: "number of links followed from the root, unlimited when 0",
+	},
+	&cli.IntFlag{
+		Name:  "max-blocks",
+		Usage: "number of blocks visited, unlimited when 0, except for find-path",
+	},
+	&cli.StringFlag{
+		Name:  "selector",
+		Usage: "IPLD selector, in JSON or as a path, limiting the walk to the blocks it traverses from the root",
+	},
+}
+
+func dagWalkOpts(cctx *cli.Context) lapi.DagWalkOpts {
+	opts := lapi.DagWalkOpts{
+		MaxDepth:  cctx.Int("max-depth"),
+		MaxBlocks: cctx.Int("max-blocks"),
+	}
+	if cctx.IsSet("selector") {
+		sel := lapi.Selector(cctx.String("selector"))
+		opts.Selector = &sel
+	}
+	return opts
+}
+
+var ChainWalkObjCmd = &cli.Command{
+	Name:      "walk-obj",
+	Usage:     "Walk the graph of an object and break its size down by codec",
+	ArgsUsage: "[cid or path]",
+	Description: `Walk the graph referenced by an object, breadth first, and print the
+   number of blocks and their size by codec.
+
+   The object is a CID, or a path as accepted by 'lotus chain get'.
+`,
+	Flags: dagWalkFlags,
+	Action: func(cctx *cli.Context) error {
+		afmt := NewAppFmt(cctx.App)
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		res, err := api.ChainWalkObj(ctx, cctx.Args().First(), dagWalkOpts(cctx))
+		if err != nil {
+			return err
+		}
+
+		afmt.Printf("Root: %s\n", res.Root)
+		afmt.Printf("Blocks: %d\n", res.Blocks)
+		afmt.Printf("Size: %s (%d)\n", types.SizeStr(types.NewInt(res.Size)), res.Size)
+		afmt.Printf("Depth: %d\n", res.Depth)
+		if res.Missing > 0 {
+			afmt.Printf("Missing: %d\n", res.Missing)
+		}
+		if res.Truncated {
+			afmt.Println("Truncated by the walk limits")
+		}
+
+		codecs := make([]string, 0, len(res.Codecs))
+		for c := range res.Codecs {
+			codecs = append(codecs, c)
+		}
+		sort.Strings(codecs)
+
+		afmt.Println("\nCodecs:")
+		tw := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "  Codec\tBlocks\tSize")
+		for _, c := range codecs {
+			st := res.Codecs[c]
+			_, _ = fmt.Fprintf(tw, "  %s\t%d\t%s\n", c, st.Links, types.SizeStr(types.NewInt(st.Size)))
+		}
+		return tw.Flush()
+	},
+}
+
+var ChainFindObjPathCmd = &cli.Command{
+	Name:      "find-path",
+	Usage:     "Find the shortest chain of links between two objects",
+	ArgsUsage: "[from cid] [to cid]",
+	Flags:     dagWalkFlags,
+	Action: func(cctx *cli.Context) error {
+		afmt := NewAppFmt(cctx.App)
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		if cctx.NArg() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		from, err := cid.Decode(cctx.Args().Get(0))
+		if err != nil {
+			return fmt.Errorf("failed to parse cid input: %s", err)
+		}
+		to, err := cid.Decode(cctx.Args().Get(1))
+		if err != nil {
+			return fmt.Errorf("failed to parse cid input: %s", err)
+		}
+
+		steps, err := api.ChainFindObjPath(ctx, from, to, dagWalkOpts(cctx))
+		if err != nil {
+			return err
+		}
+		if steps == nil {
+			return xerrors.Errorf("%s isn't reachable from %s within the walk limits", to, from)
+		}
+
+		p := "/ipfs/" + from.String()
+		for _, s := range steps {
+			if s.Link != "" {
+				p += "/" + s.Link
+			}
+			afmt.Printf("%s\t%s\n", s.Cid, s.Link)
+		}
+		afmt.Printf("\nPath: %s\n", p)
+		return nil
+	},
+}
+
+var ChainExportObjCmd = &cli.Command{
+	Name:      "export-obj",
+	Usage:     "Export the graph of an object as a CAR file",
+	ArgsUsage: "[cid or path] [outputPath]",
+	Description: `Export the graph referenced by an object, breadth first, as a CAR file
+   rooted at the object.
+
+   The object is a CID, or a path as accepted by 'lotus chain get'.
+`,
+	Flags: dagWalkFlags,
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		if cctx.NArg() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		stream, err := api.ChainExportObj(ctx, cctx.Args().Get(0), dagWalkOpts(cctx))
+		if err != nil {
+			return err
+		}
+
+		fi, err := createExportFile(cctx.App, cctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+		defer fi.Close() //nolint:errcheck
+
+		var last bool
+		for b := range stream {
+			last = len(b) == 0
+
+			if _, err := fi.Write(b); err != nil {
+				return err
+			}
+		}
+
+		if !last {
+			return xerrors.Errorf("incomplete export (remote connection lost?)")
+		}
+		return fi.Close()
+	},
+}
+
 var ChainGetMsgCmd = &cli.Command{
 	Name:      "getmessage",
 	Aliases:   []string{"get-message", "get-msg"},
@@ -1084,6 +1272,14 @@ var ChainExportCmd = &cli.Command{
 		&cli.BoolFlag{
 			Name: "skip-old-msgs",
 		},
+		&cli.BoolFlag{
+			Name:  "manifest",
+			Usage: "write a manifest with the checksum of the export to [outputPath].manifest.json",
+		},
+		&cli.StringFlag{
+			Name:  "sign-with",
+			Usage: "sign the manifest with the given wallet key address (implies --manifest)",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		api, closer, err := GetFullNodeAPI(cctx)
@@ -1097,6 +1293,8 @@ var ChainExportCmd = &cli.Command{
 			return IncorrectNumArgs(cctx)
 		}
 
+		afmt := NewAppFmt(cctx.App)
+
 		rsrs := abi.ChainEpoch(cctx.Int64("recent-stateroots"))
 		if cctx.IsSet("recent-stateroots") && rsrs < build.Finality {
 			return fmt.Errorf("\"recent-stateroots\" has to be greater than %d", build.Finality)
@@ -1124,29 +1322,229 @@ var ChainExportCmd = &cli.Command{
 			return fmt.Errorf("must pass recent stateroots along with skip-old-msgs")
 		}
 
+		var signer address.Address
+		if cctx.IsSet("sign-with") {
+			signer, err = address.NewFromString(cctx.String("sign-with"))
+			if err != nil {
+				return xerrors.Errorf("parsing signing address: %w", err)
+			}
+		}
+		withManifest := cctx.Bool("manifest") || signer != address.Undef
+
 		stream, err := api.ChainExport(ctx, rsrs, skipold, ts.Key())
 		if err != nil {
 			return err
 		}
 
+		hasher := sha256.New()
+		w := io.MultiWriter(fi, hasher)
+
 		var last bool
+		var size int64
 		for b := range stream {
 			last = len(b) == 0
 
-			_, err := fi.Write(b)
+			n, err := w.Write(b)
 			if err != nil {
 				return err
 			}
+			size += int64(n)
 		}
 
 		if !last {
 			return xerrors.Errorf("incomplete export (remote connection lost?)")
 		}
 
+		if !withManifest {
+			return nil
+		}
+
+		nn, err := api.StateNetworkName(ctx)
+		if err != nil {
+			return err
+		}
+		m := &snapshotter.Manifest{
+			Version: snapshotter.ManifestVersion,
+			Network: string(nn),
+			Head:    ts.Key(),
+			Height:  ts.Height(),
+			Size:    size,
+			SHA256:  hex.EncodeToString(hasher.Sum(nil)),
+		}
+		if signer != address.Undef {
+			err := m.Sign(ctx, signer, func(ctx context.Context, addr address.Address, msg []byte) (*crypto.Signature, error) {
+				return api.WalletSign(ctx, addr, msg)
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		mpath := cctx.Args().First() + snapshotter.ManifestSuffix
+		if err := snapshotter.WriteManifest(mpath, m); err != nil {
+			return xerrors.Errorf("writing manifest: %w", err)
+		}
+		afmt.Printf("Wrote manifest to %s\n", mpath)
+
 		return nil
 	},
 }
 
+// chainExportProgress is persisted next to the output file of export-stream,
+// allowing an interrupted export to be resumed.
+type chainExportProgress struct {
+	Offset      uint64
+	ResumeToken string
+}
+
+var ChainExportStreamCmd = &cli.Command{
+	Name:      "export-stream",
+	Usage:     "export chain to a car file over the API, resuming interrupted exports",
+	ArgsUsage: "[outputPath]",
+	Description: `Streams a snapshot from the node in chunks, recording progress in [outputPath].progress.
+If the export is interrupted, running the same command again resumes it from the last written chunk.
+
+When --tail is set, an epoch-range snapshot is exported, containing messages from the tail to the
+exported tipset, and the state at the tail only.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "tipset",
+			Usage: "specify tipset to start the export from",
+			Value: "@head",
+		},
+		&cli.StringFlag{
+			Name:  "tail",
+			Usage: "specify tipset to end an epoch-range export at (lower epoch)",
+		},
+		&cli.Int64Flag{
+			Name:  "recent-stateroots",
+			Usage: "specify the number of recent state roots to include in the export",
+		},
+		&cli.BoolFlag{
+			Name: "skip-old-msgs",
+		},
+		&cli.IntFlag{
+			Name:  "chunk-size",
+			Usage: "size of the chunks sent by the node",
+			Value: 1 << 20,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+		afmt := NewAppFmt(cctx.App)
+
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		outPath := cctx.Args().First()
+		progressPath := outPath + ".progress"
+
+		params := lapi.ChainExportStreamParams{
+			ChunkSize: cctx.Int("chunk-size"),
+		}
+
+		var progress chainExportProgress
+		pb, err := os.ReadFile(progressPath)
+		switch {
+		case err == nil:
+			if err := json.Unmarshal(pb, &progress); err != nil {
+				return xerrors.Errorf("reading export progress: %w", err)
+			}
+			params.ResumeToken = progress.ResumeToken
+			afmt.Printf("Resuming export at offset %d\n", progress.Offset)
+		case os.IsNotExist(err):
+		default:
+			return err
+		}
+
+		var tsk types.TipSetKey
+		if params.ResumeToken == "" {
+			params.NRoots = abi.ChainEpoch(cctx.Int64("recent-stateroots"))
+			params.SkipOldMsgs = cctx.Bool("skip-old-msgs")
+
+			if cctx.IsSet("tail") {
+				tail, err := ParseTipSetRef(ctx, api, cctx.String("tail"))
+				if err != nil {
+					return fmt.Errorf("parsing tail: %w", err)
+				}
+				params.Tail = tail.Key()
+			} else {
+				if cctx.IsSet("recent-stateroots") && params.NRoots < build.Finality {
+					return fmt.Errorf("\"recent-stateroots\" has to be greater than %d", build.Finality)
+				}
+				if params.NRoots == 0 && params.SkipOldMsgs {
+					return fmt.Errorf("must pass recent stateroots along with skip-old-msgs")
+				}
+			}
+
+			ts, err := LoadTipSet(ctx, cctx, api)
+			if err != nil {
+				return err
+			}
+			tsk = ts.Key()
+		}
+
+		fi, err := os.OpenFile(outPath, os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if err := fi.Close(); err != nil {
+				fmt.Printf("error closing output file: %+v", err)
+			}
+		}()
+
+		// drop anything written after the last recorded chunk
+		if err := fi.Truncate(int64(progress.Offset)); err != nil {
+			return err
+		}
+		if _, err := fi.Seek(int64(progress.Offset), io.SeekStart); err != nil {
+			return err
+		}
+
+		stream, err := api.ChainExportStream(ctx, tsk, params)
+		if err != nil {
+			return err
+		}
+
+		for chunk := range stream {
+			if chunk.Error != "" {
+				return xerrors.Errorf("export failed at offset %d: %s", chunk.Offset, chunk.Error)
+			}
+
+			if _, err := fi.Write(chunk.Data); err != nil {
+				return err
+			}
+
+			progress = chainExportProgress{
+				Offset:      chunk.Offset + uint64(len(chunk.Data)),
+				ResumeToken: chunk.ResumeToken,
+			}
+
+			if chunk.Done {
+				afmt.Printf("Export complete, %d bytes written\n", progress.Offset)
+				return os.Remove(progressPath)
+			}
+
+			pb, err := json.Marshal(progress)
+			if err != nil {
+				return err
+			}
+			if err := os.WriteFile(progressPath, pb, 0644); err != nil {
+				return xerrors.Errorf("writing export progress: %w", err)
+			}
+		}
+
+		return xerrors.Errorf("incomplete export at offset %d (remote connection lost?), run the command again to resume", progress.Offset)
+	},
+}
+
 var ChainExportRangeCmd = &cli.Command{
 	Name:      "export-range",
 	Usage:     "export chain to a car file",
@@ -1686,3 +2084,66 @@ var chainPruneColdCmd = &cli.Command{
 		return api.ChainPrune(ctx, opts)
 	},
 }
+
+var ChainIndexQueryCmd = &cli.Command{
+	Name:      "index-query",
+	Usage:     "Run a read-only SQL query over an index of the node (events, txhash or msgindex)",
+	ArgsUsage: "[index query]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "json",
+			Usage: "print the result as JSON",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		res, err := api.ChainIndexQuery(ctx, cctx.Args().Get(0), cctx.Args().Get(1))
+		if err != nil {
+			return err
+		}
+
+		if cctx.Bool("json") {
+			out, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return err
+			}
+			afmt := NewAppFmt(cctx.App)
+			afmt.Println(string(out))
+			return nil
+		}
+
+		// blobs are sent base64 encoded
+		tw := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, strings.Join(res.Columns, "\t"))
+		for _, row := range res.Rows {
+			vals := make([]string, len(row))
+			for i, v := range row {
+				if v == nil {
+					vals[i] = "NULL"
+				} else {
+					vals[i] = fmt.Sprint(v)
+				}
+			}
+			_, _ = fmt.Fprintln(tw, strings.Join(vals, "\t"))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if res.Truncated {
+			afmt := NewAppFmt(cctx.App)
+			afmt.Printf("\nresult truncated to %d rows\n", len(res.Rows))
+		}
+		return nil
+	},
+}
@@ -15,6 +15,7 @@ var LogCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		LogList,
 		LogSetLevel,
+		LogResetLevels,
 		LogAlerts,
 	},
 }
@@ -54,6 +55,10 @@ var LogSetLevel = &cli.Command{
 
    eg) log set-level --system chain --system chainxchg debug
 
+   Levels are persisted in the node repo and restored when the node restarts,
+   taking precedence over the levels in the node config, until they are
+   removed with log reset-levels.
+
    Available Levels:
    debug
    info
@@ -104,6 +109,20 @@ var LogSetLevel = &cli.Command{
 	},
 }
 
+var LogResetLevels = &cli.Command{
+	Name:  "reset-levels",
+	Usage: "Remove the log levels persisted by set-level, restoring the levels of the node config",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return api.LogResetLevels(ReqContext(cctx))
+	},
+}
+
 var LogAlerts = &cli.Command{
 	Name:  "alerts",
 	Usage: "Get alert states",
@@ -35,6 +35,8 @@ var MpoolCmd = &cli.Command{
 		MpoolConfig,
 		MpoolGasPerfCmd,
 		mpoolManage,
+		MpoolScheduleCmd,
+		MpoolBudgetCmd,
 	},
 }
 
@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var MpoolBudgetCmd = &cli.Command{
+	Name:  "budget",
+	Usage: "Manage the budget of the FIL the node's wallets spend on gas",
+	Subcommands: []*cli.Command{
+		mpoolBudgetStatusCmd,
+		mpoolBudgetOverrideCmd,
+	},
+}
+
+var mpoolBudgetStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Show the gas which can be spent in each budget window",
+	Action: func(cctx *cli.Context) error {
+		fapi, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		b, err := fapi.MpoolSpendBudget(ctx)
+		if err != nil {
+			return err
+		}
+		if len(b.Windows) == 0 {
+			fmt.Fprintln(cctx.App.Writer, "No gas spend budget is configured")
+			return nil
+		}
+
+		tw := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "Period\tBudget\tAvailable")
+		for _, w := range b.Windows {
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", w.Period, types.FIL(w.Budget), types.FIL(w.Available))
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		fmt.Fprintf(cctx.App.Writer, "\nOverride allowance: %s\n", types.FIL(b.Allowance))
+		fmt.Fprintf(cctx.App.Writer, "Queued messages: %d\n", b.Queued)
+		return nil
+	},
+}
+
+var mpoolBudgetOverrideCmd = &cli.Command{
+	Name:      "override",
+	Usage:     "Allow messages exceeding the budget to be pushed, until the allowance is used up",
+	ArgsUsage: "[allowance (FIL), 0 revokes it]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		allowance, err := types.ParseFIL(cctx.Args().First())
+		if err != nil {
+			return xerrors.Errorf("parsing allowance: %w", err)
+		}
+
+		fapi, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		return fapi.MpoolSpendBudgetOverride(ctx, abi.TokenAmount(allowance))
+	},
+}
@@ -0,0 +1,232 @@
+package cli
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+var MpoolScheduleCmd = &cli.Command{
+	Name:  "schedule",
+	Usage: "Manage messages scheduled to be pushed at an epoch, a time or a base fee",
+	Subcommands: []*cli.Command{
+		mpoolScheduleAddCmd,
+		mpoolScheduleListCmd,
+		mpoolScheduleCancelCmd,
+	},
+}
+
+var mpoolScheduleAddCmd = &cli.Command{
+	Name:      "add",
+	Usage:     "Schedule a message",
+	ArgsUsage: "[targetAddress] [amount]",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "from",
+			Usage: "optionally specify the account to send the message from",
+		},
+		&cli.Uint64Flag{
+			Name:  "method",
+			Usage: "specify method to invoke",
+			Value: uint64(builtin.MethodSend),
+		},
+		&cli.StringFlag{
+			Name:  "params-hex",
+			Usage: "specify invocation parameters in hex",
+		},
+		&cli.Int64Flag{
+			Name:  "epoch",
+			Usage: "push the message from this epoch",
+		},
+		&cli.TimestampFlag{
+			Name:   "time",
+			Usage:  "push the message from this time, e.g. 2006-01-02T15:04:05Z",
+			Layout: time.RFC3339,
+		},
+		&cli.StringFlag{
+			Name:  "basefee-below",
+			Usage: "push the message once the base fee is below this value, e.g. '100 nanofil'",
+		},
+		&cli.Int64Flag{
+			Name:  "expire-epoch",
+			Usage: "don't push the message from this epoch",
+		},
+		&cli.StringFlag{
+			Name:  "max-fee",
+			Usage: "maximum fee of the message, estimated when pushed if not set",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 2 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		fapi, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		to, err := address.NewFromString(cctx.Args().Get(0))
+		if err != nil {
+			return xerrors.Errorf("parsing target address: %w", err)
+		}
+		val, err := types.ParseFIL(cctx.Args().Get(1))
+		if err != nil {
+			return xerrors.Errorf("parsing amount: %w", err)
+		}
+
+		var from address.Address
+		if cctx.IsSet("from") {
+			from, err = address.NewFromString(cctx.String("from"))
+		} else {
+			from, err = fapi.WalletDefaultAddress(ctx)
+		}
+		if err != nil {
+			return xerrors.Errorf("getting sender address: %w", err)
+		}
+
+		var params []byte
+		if cctx.IsSet("params-hex") {
+			params, err = hex.DecodeString(cctx.String("params-hex"))
+			if err != nil {
+				return xerrors.Errorf("decoding hex params: %w", err)
+			}
+		}
+
+		sched := api.MessageSchedule{
+			AtEpoch:     abi.ChainEpoch(cctx.Int64("epoch")),
+			ExpireEpoch: abi.ChainEpoch(cctx.Int64("expire-epoch")),
+		}
+		if t := cctx.Timestamp("time"); t != nil {
+			sched.AtTime = *t
+		}
+		if cctx.IsSet("basefee-below") {
+			f, err := types.ParseFIL(cctx.String("basefee-below"))
+			if err != nil {
+				return xerrors.Errorf("parsing base fee: %w", err)
+			}
+			fee := abi.TokenAmount(f)
+			sched.BaseFeeBelow = &fee
+		}
+
+		var spec *api.MessageSendSpec
+		if cctx.IsSet("max-fee") {
+			f, err := types.ParseFIL(cctx.String("max-fee"))
+			if err != nil {
+				return xerrors.Errorf("parsing max fee: %w", err)
+			}
+			spec = &api.MessageSendSpec{MaxFee: abi.TokenAmount(f)}
+		}
+
+		sm, err := fapi.MpoolScheduleMessage(ctx, &types.Message{
+			From:   from,
+			To:     to,
+			Value:  abi.TokenAmount(val),
+			Method: abi.MethodNum(cctx.Uint64("method")),
+			Params: params,
+		}, sched, spec)
+		if err != nil {
+			return err
+		}
+
+		afmt := NewAppFmt(cctx.App)
+		afmt.Println(sm.ID)
+		return nil
+	},
+}
+
+var mpoolScheduleListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List scheduled messages",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "all",
+			Usage: "also list messages which are no longer pending",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		fapi, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		msgs, err := fapi.MpoolListScheduled(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "ID\tState\tFrom\tTo\tValue\tSchedule\tResult")
+		for _, sm := range msgs {
+			if !cctx.Bool("all") && sm.State != api.ScheduledMessagePending {
+				continue
+			}
+
+			result := sm.Error
+			if sm.Signed != nil {
+				result = fmt.Sprintf("%s at %d", sm.Signed, sm.PushEpoch)
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", sm.ID, sm.State, sm.Message.From, sm.Message.To,
+				types.FIL(sm.Message.Value), formatSchedule(sm.Schedule), result)
+		}
+		return tw.Flush()
+	},
+}
+
+func formatSchedule(s api.MessageSchedule) string {
+	var conds []string
+	if s.AtEpoch > 0 {
+		conds = append(conds, fmt.Sprintf("epoch %d", s.AtEpoch))
+	}
+	if !s.AtTime.IsZero() {
+		conds = append(conds, s.AtTime.Format(time.RFC3339))
+	}
+	if s.BaseFeeBelow != nil {
+		conds = append(conds, fmt.Sprintf("base fee < %s", types.FIL(*s.BaseFeeBelow)))
+	}
+	if s.ExpireEpoch > 0 {
+		conds = append(conds, fmt.Sprintf("expires %d", s.ExpireEpoch))
+	}
+	return strings.Join(conds, ", ")
+}
+
+var mpoolScheduleCancelCmd = &cli.Command{
+	Name:      "cancel",
+	Usage:     "Cancel a pending scheduled message",
+	ArgsUsage: "[id]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return IncorrectNumArgs(cctx)
+		}
+
+		id, err := uuid.Parse(cctx.Args().First())
+		if err != nil {
+			return xerrors.Errorf("parsing ID: %w", err)
+		}
+
+		fapi, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		return fapi.MpoolCancelScheduled(ReqContext(cctx), id)
+	},
+}
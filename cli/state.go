@@ -44,6 +44,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/f4"
 	cliutil "github.com/filecoin-project/lotus/cli/util"
 )
 
@@ -782,6 +783,13 @@ var StateGetActorCmd = &cli.Command{
 		fmt.Printf("Head:\t\t%s\n", a.Head)
 		if a.Address != nil {
 			fmt.Printf("Delegated address:\t\t%s\n", a.Address)
+			if ns, sub, err := f4.Resolve(*a.Address); err == nil {
+				native, err := ns.Format(sub)
+				if err != nil {
+					return err
+				}
+				fmt.Printf("Native address (%s):\t%s\n", ns.Name(), native)
+			}
 		}
 
 		return nil
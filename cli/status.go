@@ -38,6 +38,18 @@ var StatusCmd = &cli.Command{
 		fmt.Printf("Peers to Publish Messages: %d\n", status.PeerStatus.PeersToPublishMsgs)
 		fmt.Printf("Peers to Publish Blocks: %d\n", status.PeerStatus.PeersToPublishBlocks)
 
+		if status.Health.Ready {
+			fmt.Println("Ready: yes")
+		} else {
+			fmt.Println("Ready: no")
+			for _, f := range status.Health.Failing {
+				fmt.Printf("  %s\n", f)
+			}
+		}
+		if status.Health.EventsIndexLag >= 0 {
+			fmt.Printf("Events Index Lag: %d\n", status.Health.EventsIndexLag)
+		}
+
 		if inclChainStatus && status.SyncStatus.Epoch > uint64(build.Finality) {
 			var ok100, okFin string
 			if status.ChainStatus.BlocksPerTipsetLast100 >= 4.75 {
@@ -36,9 +36,10 @@ const (
 // The order of precedence is as follows:
 //
 //  1. *-api-url command line flags.
-//  2. *_API_INFO environment variables
-//  3. deprecated *_API_INFO environment variables
-//  4. *-repo command line flags.
+//  2. the miner selected with the --miner flag, for miner repos
+//  3. *_API_INFO environment variables
+//  4. deprecated *_API_INFO environment variables
+//  5. *-repo command line flags.
 func GetAPIInfoMulti(ctx *cli.Context, t repo.RepoType) ([]APIInfo, error) {
 	// Check if there was a flag passed with the listen address of the API
 	// server (only used by the tests)
@@ -52,6 +53,16 @@ func GetAPIInfoMulti(ctx *cli.Context, t repo.RepoType) ([]APIInfo, error) {
 		return []APIInfo{{Addr: strma}}, nil
 	}
 
+	if t == repo.StorageMiner {
+		ainfo, err := selectedMiner(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if ainfo != nil {
+			return []APIInfo{*ainfo}, nil
+		}
+	}
+
 	//
 	// Note: it is not correct/intuitive to prefer environment variables over
 	// CLI flags (repo flags below).
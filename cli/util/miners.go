@@ -0,0 +1,143 @@
+package cliutil
+
+import (
+	"context"
+	"os"
+
+	"github.com/BurntSushi/toml"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/client"
+)
+
+const (
+	// FlagMiner selects miners of the miners config by name.
+	FlagMiner = "miner"
+	// FlagMinersConfig is the path of the miners config.
+	FlagMinersConfig = "miners-config"
+)
+
+// MinersConfig lists the API endpoints of the miners managed from the CLI,
+// in a TOML file such as:
+//
+//	[[Miner]]
+//	Name = "f01000"
+//	APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+type MinersConfig struct {
+	Miner []MinerEndpoint
+}
+
+// MinerEndpoint is the API endpoint of a miner.
+type MinerEndpoint struct {
+	// Name selects the miner with --miner, e.g. its actor address
+	Name string
+	// APIInfo has the format of MINER_API_INFO
+	APIInfo string
+}
+
+// LoadMinersConfig loads the miners config at path.
+func LoadMinersConfig(path string) (*MinersConfig, error) {
+	p, err := homedir.Expand(path)
+	if err != nil {
+		return nil, xerrors.Errorf("expanding miners config path: %w", err)
+	}
+
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return nil, xerrors.Errorf("reading miners config: %w", err)
+	}
+
+	var cfg MinersConfig
+	md, err := toml.Decode(string(b), &cfg)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding miners config %s: %w", p, err)
+	}
+	if len(md.Undecoded()) > 0 {
+		return nil, xerrors.Errorf("unknown fields in miners config %s: %v", p, md.Undecoded())
+	}
+
+	names := map[string]struct{}{}
+	for i, m := range cfg.Miner {
+		if m.Name == "" || m.APIInfo == "" {
+			return nil, xerrors.Errorf("miner %d of miners config %s needs a Name and an APIInfo", i, p)
+		}
+		if _, ok := names[m.Name]; ok {
+			return nil, xerrors.Errorf("miner %s is listed twice in miners config %s", m.Name, p)
+		}
+		names[m.Name] = struct{}{}
+	}
+
+	return &cfg, nil
+}
+
+// Select returns the endpoints of the named miners, in the order of the
+// config, or all of them when no name is given.
+func (c *MinersConfig) Select(names []string) ([]MinerEndpoint, error) {
+	if len(names) == 0 {
+		return c.Miner, nil
+	}
+
+	selected := map[string]bool{}
+	for _, n := range names {
+		selected[n] = false
+	}
+
+	var out []MinerEndpoint
+	for _, m := range c.Miner {
+		if _, ok := selected[m.Name]; ok {
+			selected[m.Name] = true
+			out = append(out, m)
+		}
+	}
+	for n, found := range selected {
+		if !found {
+			return nil, xerrors.Errorf("miner %s not found in the miners config", n)
+		}
+	}
+	return out, nil
+}
+
+// GetMinerEndpoints returns the endpoints of the miners selected with --miner,
+// or of all miners of the miners config.
+func GetMinerEndpoints(ctx *cli.Context) ([]MinerEndpoint, error) {
+	cfg, err := LoadMinersConfig(ctx.String(FlagMinersConfig))
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Select(ctx.StringSlice(FlagMiner))
+}
+
+// selectedMiner returns the API info of the miner selected with --miner, if
+// any. Commands run against a single miner, so only one can be selected.
+func selectedMiner(ctx *cli.Context) (*APIInfo, error) {
+	names := ctx.StringSlice(FlagMiner)
+	switch len(names) {
+	case 0:
+		return nil, nil
+	case 1:
+	default:
+		return nil, xerrors.Errorf("%d miners selected with --%s, but the command runs against a single miner", len(names), FlagMiner)
+	}
+
+	eps, err := GetMinerEndpoints(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ainfo := ParseApiInfo(eps[0].APIInfo)
+	return &ainfo, nil
+}
+
+// Connect connects to the API of the miner.
+func (e MinerEndpoint) Connect(ctx context.Context) (api.StorageMiner, jsonrpc.ClientCloser, error) {
+	ainfo := ParseApiInfo(e.APIInfo)
+	addr, err := ainfo.DialArgs("v0")
+	if err != nil {
+		return nil, nil, xerrors.Errorf("miner %s: %w", e.Name, err)
+	}
+	return client.NewStorageMinerRPCV0(ctx, addr, ainfo.AuthHeader())
+}
@@ -0,0 +1,86 @@
+package cliutil
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMinersConfig(t *testing.T) {
+	load := func(cfg string) (*MinersConfig, error) {
+		p := filepath.Join(t.TempDir(), "miners.toml")
+		require.NoError(t, os.WriteFile(p, []byte(cfg), 0644))
+		return LoadMinersConfig(p)
+	}
+
+	cfg, err := load(`
+[[Miner]]
+Name = "f01000"
+APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+
+[[Miner]]
+Name = "f01001"
+APIInfo = "TOKEN:/ip4/10.0.0.2/tcp/2345/http"
+`)
+	require.NoError(t, err)
+	require.Equal(t, []MinerEndpoint{
+		{Name: "f01000", APIInfo: "TOKEN:/ip4/10.0.0.1/tcp/2345/http"},
+		{Name: "f01001", APIInfo: "TOKEN:/ip4/10.0.0.2/tcp/2345/http"},
+	}, cfg.Miner)
+
+	for name, bad := range map[string]string{
+		"duplicate name": `
+[[Miner]]
+Name = "f01000"
+APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+
+[[Miner]]
+Name = "f01000"
+APIInfo = "TOKEN:/ip4/10.0.0.2/tcp/2345/http"
+`,
+		"missing name": `
+[[Miner]]
+APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+`,
+		"missing api info": `
+[[Miner]]
+Name = "f01000"
+`,
+		"unknown field": `
+[[Miner]]
+Name = "f01000"
+APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+Token = "TOKEN"
+`,
+		"invalid toml": `[[Miner]`,
+	} {
+		_, err := load(bad)
+		require.Error(t, err, name)
+	}
+
+	_, err = LoadMinersConfig(filepath.Join(t.TempDir(), "missing.toml"))
+	require.Error(t, err)
+}
+
+func TestMinersConfigSelect(t *testing.T) {
+	cfg := &MinersConfig{Miner: []MinerEndpoint{
+		{Name: "a", APIInfo: "A"},
+		{Name: "b", APIInfo: "B"},
+		{Name: "c", APIInfo: "C"},
+	}}
+
+	// all miners when none is selected
+	eps, err := cfg.Select(nil)
+	require.NoError(t, err)
+	require.Equal(t, cfg.Miner, eps)
+
+	// in the order of the config, once each
+	eps, err = cfg.Select([]string{"c", "a", "c"})
+	require.NoError(t, err)
+	require.Equal(t, []MinerEndpoint{{Name: "a", APIInfo: "A"}, {Name: "c", APIInfo: "C"}}, eps)
+
+	_, err = cfg.Select([]string{"a", "d"})
+	require.Error(t, err)
+}
@@ -8,7 +8,9 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
 
@@ -38,6 +40,7 @@ var walletCmd = &cli.Command{
 		walletVerify,
 		walletDelete,
 		walletMarket,
+		walletApprovals,
 	},
 }
 
@@ -523,6 +526,87 @@ var walletDelete = &cli.Command{
 	},
 }
 
+var walletApprovals = &cli.Command{
+	Name:  "approvals",
+	Usage: "Manage messages refused until approved by the wallet signing policy",
+	Subcommands: []*cli.Command{
+		walletApprovalsList,
+		walletApprovalsDecide("approve", "Approve a message, it's signed when it's sent again", true),
+		walletApprovalsDecide("reject", "Reject an approval request", false),
+	},
+}
+
+var walletApprovalsList = &cli.Command{
+	Name:  "list",
+	Usage: "List approval requests",
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := ReqContext(cctx)
+
+		pending, err := api.WalletPendingApprovals(ctx)
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("ID"),
+			tablewriter.Col("Signer"),
+			tablewriter.Col("To"),
+			tablewriter.Col("Value"),
+			tablewriter.Col("Method"),
+			tablewriter.Col("Status"),
+			tablewriter.Col("Expires"),
+		)
+		for _, p := range pending {
+			status := "pending"
+			if p.Approved {
+				status = "approved"
+			}
+			tw.Write(map[string]interface{}{
+				"ID":      p.ID,
+				"Signer":  p.Signer,
+				"To":      p.Message.To,
+				"Value":   types.FIL(p.Message.Value),
+				"Method":  p.Message.Method,
+				"Status":  status,
+				"Expires": p.Expires.Format(time.RFC3339),
+			})
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
+
+func walletApprovalsDecide(name, usage string, approve bool) *cli.Command {
+	return &cli.Command{
+		Name:      name,
+		Usage:     usage,
+		ArgsUsage: "<id>",
+		Action: func(cctx *cli.Context) error {
+			if cctx.NArg() != 1 {
+				return IncorrectNumArgs(cctx)
+			}
+
+			id, err := uuid.Parse(cctx.Args().First())
+			if err != nil {
+				return xerrors.Errorf("parsing id: %w", err)
+			}
+
+			api, closer, err := GetFullNodeAPIV1(cctx)
+			if err != nil {
+				return err
+			}
+			defer closer()
+			ctx := ReqContext(cctx)
+
+			return api.WalletApprove(ctx, id, approve)
+		},
+	}
+}
+
 var walletMarket = &cli.Command{
 	Name:  "market",
 	Usage: "Interact with market balances",
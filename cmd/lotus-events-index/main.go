@@ -0,0 +1,155 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	logging "github.com/ipfs/go-log/v2"
+	manet "github.com/multiformats/go-multiaddr/net"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/events/filter/indexservice"
+	"github.com/filecoin-project/lotus/lib/lotuslog"
+	"github.com/filecoin-project/lotus/node"
+	"github.com/filecoin-project/lotus/node/repo/migrate"
+)
+
+var log = logging.Logger("events-index")
+
+func main() {
+	lotuslog.SetupLogLevels()
+
+	app := &cli.App{
+		Name:    "lotus-events-index",
+		Usage:   "Actor events index service shared by the nodes of an RPC fleet",
+		Version: build.UserVersion(),
+		Commands: []*cli.Command{
+			runCmd,
+			migrateCmd,
+		},
+	}
+
+	if err := app.Run(os.Args); err != nil {
+		log.Errorw("exit in error", "err", err)
+		os.Exit(1)
+		return
+	}
+}
+
+var runCmd = &cli.Command{
+	Name:  "run",
+	Usage: "Start the events index service",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "listen",
+			Usage: "host address and port the service will listen on, a token is required unless it's a loopback address",
+			Value: "127.0.0.1:1237",
+		},
+		&cli.StringFlag{
+			Name:     "db",
+			Usage:    "path to the sqlite events database, created if it doesn't exist",
+			Required: true,
+		},
+		&cli.StringFlag{
+			Name:    "token",
+			Usage:   "bearer token required from the nodes using the service",
+			EnvVars: []string{"LOTUS_EVENTS_INDEX_TOKEN"},
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		pending, err := migrate.Pending(cctx.Context, eventsRepo(cctx))
+		if err != nil {
+			return xerrors.Errorf("checking events database migrations: %w", err)
+		}
+		if len(pending) > 0 {
+			return xerrors.Errorf("%d migrations pending on the events database, apply them with 'lotus-events-index migrate --db %s'", len(pending), cctx.String("db"))
+		}
+
+		index, err := filter.NewEventIndex(cctx.String("db"))
+		if err != nil {
+			return xerrors.Errorf("opening events database: %w", err)
+		}
+		defer index.Close() //nolint:errcheck
+
+		addr, err := net.ResolveTCPAddr("tcp", cctx.String("listen"))
+		if err != nil {
+			return xerrors.Errorf("failed to resolve endpoint address: %w", err)
+		}
+
+		if !addr.IP.IsLoopback() && cctx.String("token") == "" {
+			return xerrors.Errorf("a token is required to listen on %s, which isn't a loopback address", cctx.String("listen"))
+		}
+
+		maddr, err := manet.FromNetAddr(addr)
+		if err != nil {
+			return xerrors.Errorf("failed to convert endpoint address to multiaddr: %w", err)
+		}
+
+		mux := http.NewServeMux()
+		mux.Handle("/rpc/v0", indexservice.Handler(index, cctx.String("token")))
+
+		stopFunc, err := node.ServeRPC(mux, "lotus-events-index", maddr)
+		if err != nil {
+			return xerrors.Errorf("failed to serve rpc endpoint: %w", err)
+		}
+
+		log.Infow("serving events index", "addr", cctx.String("listen"))
+
+		<-node.MonitorShutdown(nil, node.ShutdownHandler{
+			Component: "rpc",
+			StopFunc:  stopFunc,
+		})
+		return nil
+	},
+}
+
+var migrateCmd = &cli.Command{
+	Name:  "migrate",
+	Usage: "Apply pending schema migrations to the events database",
+	Description: `The service must be stopped. The database is backed up next to it before being
+   migrated.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:     "db",
+			Usage:    "path to the sqlite events database",
+			Required: true,
+		},
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "check the migrations apply without committing them",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		res, err := migrate.Run(cctx.Context, eventsRepo(cctx), cctx.Bool("dry-run"))
+		if err != nil {
+			return xerrors.Errorf("migrating events database: %w", err)
+		}
+
+		if len(res.Applied) == 0 {
+			fmt.Println("Events database is up to date")
+			return nil
+		}
+		for _, m := range res.Applied {
+			fmt.Printf("%s v%d: %s\n", m.Store, m.Version, m.Description)
+		}
+		if res.DryRun {
+			fmt.Printf("%d migrations can be applied (dry run, nothing was changed)\n", len(res.Applied))
+			return nil
+		}
+		fmt.Printf("Applied %d migrations, database backed up to %s\n", len(res.Applied), res.Backup)
+		return nil
+	},
+}
+
+func eventsRepo(cctx *cli.Context) migrate.Repo {
+	return migrate.Repo{
+		Events:    cctx.String("db"),
+		BackupDir: filepath.Join(filepath.Dir(cctx.String("db")), "migration-backups"),
+	}
+}
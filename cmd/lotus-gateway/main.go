@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net"
 	"os"
@@ -151,6 +152,15 @@ var runCmd = &cli.Command{
 			Usage: "The number of incomming connections to accept from a single IP per minute.  Use 0 to disable",
 			Value: 0,
 		},
+		&cli.StringFlag{
+			Name:  "tenant-quotas",
+			Usage: "path to a JSON file with the default quota and the quotas of tenants, identified by the sha256 hash of their API token; other clients are accounted by remote IP",
+		},
+		&cli.DurationFlag{
+			Name:  "quota-period",
+			Usage: "period over which the returned logs and eth_getLogs scan cost of tenants are accounted",
+			Value: gateway.DefaultQuotaPeriod,
+		},
 	},
 	Action: func(cctx *cli.Context) error {
 		log.Info("Starting lotus gateway")
@@ -198,6 +208,19 @@ var runCmd = &cli.Command{
 		}
 
 		gwapi := gateway.NewNode(api, subHnd, lookbackCap, waitLookback, rateLimit, rateLimitTimeout)
+
+		var quotas gateway.TenantQuotas
+		if path := cctx.String("tenant-quotas"); path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return xerrors.Errorf("reading tenant quotas: %w", err)
+			}
+			if err := json.Unmarshal(b, &quotas); err != nil {
+				return xerrors.Errorf("parsing tenant quotas: %w", err)
+			}
+		}
+		gwapi.SetTenantQuotas(quotas, cctx.Duration("quota-period"))
+
 		h, err := gateway.Handler(gwapi, api, perConnRateLimit, connPerMinute, serverOptions...)
 		if err != nil {
 			return xerrors.Errorf("failed to set up gateway HTTP handler")
@@ -33,6 +33,7 @@ import (
 	lminer "github.com/filecoin-project/lotus/chain/actors/builtin/miner"
 	"github.com/filecoin-project/lotus/chain/types"
 	lcli "github.com/filecoin-project/lotus/cli"
+	cliutil "github.com/filecoin-project/lotus/cli/util"
 	"github.com/filecoin-project/lotus/lib/tablewriter"
 )
 
@@ -51,6 +52,7 @@ var actorCmd = &cli.Command{
 		actorCompactAllocatedCmd,
 		actorProposeChangeBeneficiary,
 		actorConfirmChangeBeneficiary,
+		actorPendingChangesCmd,
 	},
 }
 
@@ -528,6 +530,18 @@ var actorControlList = &cli.Command{
 			dealPublish[ca] = struct{}{}
 		}
 
+		if len(ac.WindowPoStControl) > 0 {
+			post = map[address.Address]struct{}{}
+			for _, ca := range ac.WindowPoStControl {
+				ca, err := api.StateLookupID(ctx, ca, types.EmptyTSK)
+				if err != nil {
+					return err
+				}
+
+				post[ca] = struct{}{}
+			}
+		}
+
 		printKey := func(name string, a address.Address) {
 			var actor *types.Actor
 			if actor, err = api.StateGetActor(ctx, a, types.EmptyTSK); err != nil {
@@ -730,12 +744,7 @@ var actorSetOwnerCmd = &cli.Command{
 			return lcli.IncorrectNumArgs(cctx)
 		}
 
-		if !cctx.Bool("really-do-it") {
-			fmt.Println("Pass --really-do-it to actually execute this action")
-			return nil
-		}
-
-		api, acloser, err := lcli.GetFullNodeAPI(cctx)
+		fullApi, acloser, err := lcli.GetFullNodeAPIV1(cctx)
 		if err != nil {
 			return err
 		}
@@ -748,7 +757,7 @@ var actorSetOwnerCmd = &cli.Command{
 			return err
 		}
 
-		newAddrId, err := api.StateLookupID(ctx, na, types.EmptyTSK)
+		newAddrId, err := fullApi.StateLookupID(ctx, na, types.EmptyTSK)
 		if err != nil {
 			return err
 		}
@@ -758,7 +767,7 @@ var actorSetOwnerCmd = &cli.Command{
 			return err
 		}
 
-		fromAddrId, err := api.StateLookupID(ctx, fa, types.EmptyTSK)
+		fromAddrId, err := fullApi.StateLookupID(ctx, fa, types.EmptyTSK)
 		if err != nil {
 			return err
 		}
@@ -768,7 +777,7 @@ var actorSetOwnerCmd = &cli.Command{
 			return err
 		}
 
-		mi, err := api.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		mi, err := fullApi.StateMinerInfo(ctx, maddr, types.EmptyTSK)
 		if err != nil {
 			return err
 		}
@@ -777,12 +786,42 @@ var actorSetOwnerCmd = &cli.Command{
 			return xerrors.New("from address must either be the old owner or the new owner")
 		}
 
+		if fromAddrId == mi.Owner {
+			check, err := fullApi.StateMinerCheckOwnerChange(ctx, maddr, newAddrId, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("checking owner change: %w", err)
+			}
+			if err := printAddressChangeCheck(check); err != nil {
+				return err
+			}
+
+			fmt.Printf("Proposing owner change from %s to %s, which must then be confirmed by the new owner\n", mi.Owner, newAddrId)
+		} else {
+			pc, err := fullApi.StateMinerPendingChanges(ctx, maddr, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("getting pending changes: %w", err)
+			}
+			if pc.PendingOwner == nil {
+				return xerrors.Errorf("no owner change proposed, the current owner %s must propose it first", mi.Owner)
+			}
+			if *pc.PendingOwner != newAddrId {
+				return xerrors.Errorf("new owner %s does not match the proposed owner %s", newAddrId, *pc.PendingOwner)
+			}
+
+			fmt.Printf("Confirming owner change from %s to %s\n", mi.Owner, newAddrId)
+		}
+
+		if !cctx.Bool("really-do-it") {
+			fmt.Println("Pass --really-do-it to actually execute this action")
+			return nil
+		}
+
 		sp, err := actors.SerializeParams(&newAddrId)
 		if err != nil {
 			return xerrors.Errorf("serializing params: %w", err)
 		}
 
-		smsg, err := api.MpoolPushMessage(ctx, &types.Message{
+		smsg, err := fullApi.MpoolPushMessage(ctx, &types.Message{
 			From:   fromAddrId,
 			To:     maddr,
 			Method: builtin.MethodsMiner.ChangeOwnerAddress,
@@ -796,7 +835,7 @@ var actorSetOwnerCmd = &cli.Command{
 		fmt.Println("Message CID:", smsg.Cid())
 
 		// wait for it to get mined into a block
-		wait, err := api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence)
+		wait, err := fullApi.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
 		if err != nil {
 			return err
 		}
@@ -952,7 +991,7 @@ var actorProposeChangeBeneficiary = &cli.Command{
 			return lcli.IncorrectNumArgs(cctx)
 		}
 
-		api, acloser, err := lcli.GetFullNodeAPI(cctx)
+		fullApi, acloser, err := lcli.GetFullNodeAPIV1(cctx)
 		if err != nil {
 			return xerrors.Errorf("getting fullnode api: %w", err)
 		}
@@ -965,7 +1004,7 @@ var actorProposeChangeBeneficiary = &cli.Command{
 			return xerrors.Errorf("parsing beneficiary address: %w", err)
 		}
 
-		newAddr, err := api.StateLookupID(ctx, na, types.EmptyTSK)
+		newAddr, err := fullApi.StateLookupID(ctx, na, types.EmptyTSK)
 		if err != nil {
 			return xerrors.Errorf("looking up new beneficiary address: %w", err)
 		}
@@ -985,7 +1024,7 @@ var actorProposeChangeBeneficiary = &cli.Command{
 			return xerrors.Errorf("getting miner address: %w", err)
 		}
 
-		mi, err := api.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		mi, err := fullApi.StateMinerInfo(ctx, maddr, types.EmptyTSK)
 		if err != nil {
 			return xerrors.Errorf("getting miner info: %w", err)
 		}
@@ -1005,23 +1044,41 @@ var actorProposeChangeBeneficiary = &cli.Command{
 			}
 		}
 
-		if !cctx.Bool("really-do-it") {
-			fmt.Println("Pass --really-do-it to actually execute this action. Review what you're about to approve CAREFULLY please")
-			return nil
-		}
-
 		params := &miner.ChangeBeneficiaryParams{
 			NewBeneficiary: newAddr,
 			NewQuota:       abi.TokenAmount(quota),
 			NewExpiration:  abi.ChainEpoch(expiration),
 		}
 
+		head, err := fullApi.ChainHead(ctx)
+		if err != nil {
+			return xerrors.Errorf("getting chain head: %w", err)
+		}
+
+		fmt.Println("Proposing Beneficiary Term of:")
+		fmt.Println("Beneficiary:", newAddr)
+		fmt.Println("Quota:", quota)
+		fmt.Println("Expiration Epoch:", cliutil.EpochTime(head.Height(), params.NewExpiration))
+
+		check, err := fullApi.StateMinerCheckBeneficiaryChange(ctx, maddr, *params, head.Key())
+		if err != nil {
+			return xerrors.Errorf("checking beneficiary change: %w", err)
+		}
+		if err := printAddressChangeCheck(check); err != nil {
+			return err
+		}
+
+		if !cctx.Bool("really-do-it") {
+			fmt.Println("Pass --really-do-it to actually execute this action. Review what you're about to approve CAREFULLY please")
+			return nil
+		}
+
 		sp, err := actors.SerializeParams(params)
 		if err != nil {
 			return xerrors.Errorf("serializing params: %w", err)
 		}
 
-		smsg, err := api.MpoolPushMessage(ctx, &types.Message{
+		smsg, err := fullApi.MpoolPushMessage(ctx, &types.Message{
 			From:   mi.Owner,
 			To:     maddr,
 			Method: builtin.MethodsMiner.ChangeBeneficiary,
@@ -1035,7 +1092,7 @@ var actorProposeChangeBeneficiary = &cli.Command{
 		fmt.Println("Propose Message CID:", smsg.Cid())
 
 		// wait for it to get mined into a block
-		wait, err := api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence)
+		wait, err := fullApi.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
 		if err != nil {
 			return xerrors.Errorf("waiting for message to be included in block: %w", err)
 		}
@@ -1045,7 +1102,7 @@ var actorProposeChangeBeneficiary = &cli.Command{
 			return fmt.Errorf("propose beneficiary change failed")
 		}
 
-		updatedMinerInfo, err := api.StateMinerInfo(ctx, maddr, wait.TipSet)
+		updatedMinerInfo, err := fullApi.StateMinerInfo(ctx, maddr, wait.TipSet)
 		if err != nil {
 			return xerrors.Errorf("getting miner info: %w", err)
 		}
@@ -1187,7 +1244,7 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 			return lcli.IncorrectNumArgs(cctx)
 		}
 
-		api, acloser, err := lcli.GetFullNodeAPI(cctx)
+		fullApi, acloser, err := lcli.GetFullNodeAPIV1(cctx)
 		if err != nil {
 			return xerrors.Errorf("getting fullnode api: %w", err)
 		}
@@ -1200,7 +1257,7 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 			return xerrors.Errorf("parsing beneficiary address: %w", err)
 		}
 
-		mi, err := api.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		mi, err := fullApi.StateMinerInfo(ctx, maddr, types.EmptyTSK)
 		if err != nil {
 			return xerrors.Errorf("getting miner info: %w", err)
 		}
@@ -1226,10 +1283,18 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 			fromAddr = mi.PendingBeneficiaryTerm.NewBeneficiary
 		}
 
+		pc, err := fullApi.StateMinerPendingChanges(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("getting pending changes: %w", err)
+		}
+
 		fmt.Println("Confirming Pending Beneficiary Term of:")
 		fmt.Println("Beneficiary: ", mi.PendingBeneficiaryTerm.NewBeneficiary)
-		fmt.Println("Quota:", mi.PendingBeneficiaryTerm.NewQuota)
-		fmt.Println("Expiration Epoch:", mi.PendingBeneficiaryTerm.NewExpiration)
+		fmt.Println("Quota:", types.FIL(mi.PendingBeneficiaryTerm.NewQuota))
+		fmt.Println("Expiration Epoch:", cliutil.EpochTime(pc.Epoch, mi.PendingBeneficiaryTerm.NewExpiration))
+		for _, w := range pc.Warnings {
+			fmt.Println(color.YellowString("WARNING: %s", w))
+		}
 
 		if !cctx.Bool("really-do-it") {
 			fmt.Println("Pass --really-do-it to actually execute this action. Review what you're about to approve CAREFULLY please")
@@ -1247,7 +1312,7 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 			return xerrors.Errorf("serializing params: %w", err)
 		}
 
-		smsg, err := api.MpoolPushMessage(ctx, &types.Message{
+		smsg, err := fullApi.MpoolPushMessage(ctx, &types.Message{
 			From:   fromAddr,
 			To:     maddr,
 			Method: builtin.MethodsMiner.ChangeBeneficiary,
@@ -1261,7 +1326,7 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 		fmt.Println("Confirm Message CID:", smsg.Cid())
 
 		// wait for it to get mined into a block
-		wait, err := api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence)
+		wait, err := fullApi.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
 		if err != nil {
 			return xerrors.Errorf("waiting for message to be included in block: %w", err)
 		}
@@ -1271,7 +1336,7 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 			return fmt.Errorf("confirm beneficiary change failed with code %d", wait.Receipt.ExitCode)
 		}
 
-		updatedMinerInfo, err := api.StateMinerInfo(ctx, maddr, types.EmptyTSK)
+		updatedMinerInfo, err := fullApi.StateMinerInfo(ctx, maddr, types.EmptyTSK)
 		if err != nil {
 			return err
 		}
@@ -1286,6 +1351,91 @@ var actorConfirmChangeBeneficiary = &cli.Command{
 	},
 }
 
+var actorPendingChangesCmd = &cli.Command{
+	Name:  "pending-changes",
+	Usage: "Show owner, worker and beneficiary changes awaiting confirmation",
+	Action: func(cctx *cli.Context) error {
+		fullApi, acloser, err := lcli.GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return xerrors.Errorf("getting fullnode api: %w", err)
+		}
+		defer acloser()
+
+		ctx := lcli.ReqContext(cctx)
+
+		maddr, err := getActorAddress(ctx, cctx)
+		if err != nil {
+			return xerrors.Errorf("getting miner address: %w", err)
+		}
+
+		pc, err := fullApi.StateMinerPendingChanges(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Miner:\t%s (epoch %d)\n", maddr, pc.Epoch)
+
+		fmt.Printf("\nOwner:\t%s\n", pc.Owner)
+		if pc.PendingOwner != nil {
+			fmt.Printf("  Pending Owner:\t%s\n", color.YellowString("%s", *pc.PendingOwner))
+			fmt.Printf("  Confirm with:\tlotus-miner actor set-owner %s %s\n", *pc.PendingOwner, *pc.PendingOwner)
+		}
+
+		fmt.Printf("\nWorker:\t%s\n", pc.Worker)
+		if pc.NewWorker != nil {
+			fmt.Printf("  Pending Worker:\t%s\n", color.YellowString("%s", *pc.NewWorker))
+			fmt.Printf("  Confirmable at:\t%s\n", cliutil.EpochTime(pc.Epoch, pc.WorkerChangeEpoch))
+			fmt.Printf("  Confirm with:\tlotus-miner actor confirm-change-worker %s\n", *pc.NewWorker)
+		}
+
+		fmt.Printf("\nBeneficiary:\t%s\n", pc.Beneficiary)
+		if pc.Beneficiary != pc.Owner {
+			bt := pc.BeneficiaryTerm
+			fmt.Printf("  Quota:\t%s (used %s, available %s)\n", types.FIL(bt.Quota), types.FIL(bt.UsedQuota), types.FIL(bt.Available))
+			fmt.Printf("  Expiration:\t%s\n", cliutil.EpochTime(pc.Epoch, bt.Expiration))
+		}
+
+		if pb := pc.PendingBeneficiary; pb != nil {
+			fmt.Printf("  Pending Beneficiary:\t%s\n", color.YellowString("%s", pb.NewBeneficiary))
+			fmt.Printf("    Quota:\t%s\n", types.FIL(pb.NewQuota))
+			fmt.Printf("    Expiration:\t%s\n", cliutil.EpochTime(pc.Epoch, pb.NewExpiration))
+			fmt.Printf("    Approved by beneficiary:\t%t\n", pb.ApprovedByBeneficiary)
+			fmt.Printf("    Approved by nominee:\t%t\n", pb.ApprovedByNominee)
+			for _, a := range pb.AwaitingApproval {
+				flag := "--new-beneficiary"
+				if a == pc.Beneficiary {
+					flag = "--existing-beneficiary"
+				}
+				fmt.Printf("    Awaiting %s:\tlotus-miner actor confirm-change-beneficiary %s %s\n", a, flag, maddr)
+			}
+		}
+
+		if len(pc.Warnings) > 0 {
+			fmt.Println()
+		}
+		for _, w := range pc.Warnings {
+			fmt.Println(color.YellowString("WARNING: %s", w))
+		}
+
+		return nil
+	},
+}
+
+// printAddressChangeCheck prints the result of an address change check,
+// returning an error if the change would fail
+func printAddressChangeCheck(check *api.AddressChangeCheck) error {
+	for _, w := range check.Warnings {
+		fmt.Println(color.YellowString("WARNING: %s", w))
+	}
+	for _, e := range check.Errors {
+		fmt.Println(color.RedString("ERROR: %s", e))
+	}
+	if !check.OK() {
+		return xerrors.Errorf("refusing to send a change which would fail or never take effect")
+	}
+	return nil
+}
+
 var actorCompactAllocatedCmd = &cli.Command{
 	Name:  "compact-allocated",
 	Usage: "compact allocated sectors bitfield",
@@ -446,6 +446,7 @@ var stateList = []stateMeta{
 	{col: color.FgRed, state: sealing.ReplicaUpdateFailed},
 	{col: color.FgRed, state: sealing.ReleaseSectorKeyFailed},
 	{col: color.FgRed, state: sealing.FinalizeReplicaUpdateFailed},
+	{col: color.FgRed, state: sealing.ReceiveSectorFailed},
 }
 
 func init() {
@@ -481,7 +481,7 @@ func storageMinerInit(ctx context.Context, cctx *cli.Context, api v1api.FullNode
 				AllowReplicaUpdate:       true,
 				AllowProveReplicaUpdate2: true,
 				AllowRegenSectorKey:      true,
-			}, config.ProvingConfig{}, wsts, smsts)
+			}, config.ProvingConfig{}, wsts, smsts, nil, journal.NilJournal())
 			if err != nil {
 				return err
 			}
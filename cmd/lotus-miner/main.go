@@ -41,6 +41,7 @@ func main() {
 		stopCmd,
 		configCmd,
 		backupCmd,
+		minersCmd,
 		lcli.WithCategory("chain", actorCmd),
 		lcli.WithCategory("chain", infoCmd),
 		lcli.WithCategory("market", setHidden(storageDealsCmd)),
@@ -52,6 +53,7 @@ func main() {
 		lcli.WithCategory("storage", provingCmd),
 		lcli.WithCategory("storage", storageCmd),
 		lcli.WithCategory("storage", sealingCmd),
+		lcli.WithCategory("storage", proofParamsCmd),
 		lcli.WithCategory("retrieval", setHidden(piecesCmd)),
 	}
 
@@ -145,6 +147,16 @@ func main() {
 				Usage:  "(experimental; may be removed) call this command against a markets node; use only with common commands like net, auth, pprof, etc. whose target may be ambiguous",
 				Hidden: true,
 			},
+			&cli.StringSliceFlag{
+				Name:  cliutil.FlagMiner,
+				Usage: "name of the miner of the miners config to run the command against; repeat to select several miners for the miners commands",
+			},
+			&cli.StringFlag{
+				Name:    cliutil.FlagMinersConfig,
+				EnvVars: []string{"LOTUS_MINERS_CONFIG"},
+				Value:   "~/.lotus-miners.toml",
+				Usage:   "path of the miners config, listing the API endpoints of the miners managed from this CLI",
+			},
 			cliutil.FlagVeryVerbose,
 		},
 		Commands: append(local, append(lcli.CommonCommands, &netCmd)...),
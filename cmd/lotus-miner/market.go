@@ -353,6 +353,7 @@ var storageDealsCmd = &cli.Command{
 		setSealDurationCmd,
 		dealsPendingPublish,
 		dealsRetryPublish,
+		dealsFilterDecisionsCmd,
 	},
 }
 
@@ -881,6 +882,46 @@ var transfersDiagnosticsCmd = &cli.Command{
 	},
 }
 
+var dealsFilterDecisionsCmd = &cli.Command{
+	Name:  "filter-decisions",
+	Usage: "list the last decisions of the storage deal filter",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "number of decisions to list",
+			Value: 50,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, closer, err := lcli.GetMarketsAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		decisions, err := api.DealsFilterDecisions(ctx, cctx.Int("limit"))
+		if err != nil {
+			return xerrors.Errorf("getting filter decisions: %w", err)
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(w, "Time\tProposalCID\tClient\tSize\tVerified\tPrice\tAccepted\tReason\n")
+		for _, d := range decisions {
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%t\t%s\t%t\t%s\n",
+				d.Time.Format(time.Stamp),
+				d.ProposalCid,
+				d.Client,
+				units.BytesSize(float64(d.PieceSize)),
+				d.Verified,
+				types.FIL(d.StoragePricePerEpoch),
+				d.Accepted,
+				d.Reason)
+		}
+		return w.Flush()
+	},
+}
+
 var dealsPendingPublish = &cli.Command{
 	Name:  "pending-publish",
 	Usage: "list deals waiting in publish queue",
@@ -0,0 +1,346 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	lcli "github.com/filecoin-project/lotus/cli"
+	cliutil "github.com/filecoin-project/lotus/cli/util"
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+	sealing "github.com/filecoin-project/lotus/storage/pipeline"
+)
+
+var minersCmd = &cli.Command{
+	Name:  "miners",
+	Usage: "Manage the miners of the miners config",
+	Description: `The miners config lists the API endpoints of the miners managed from this CLI,
+in the file of --miners-config:
+
+   [[Miner]]
+   Name = "f01000"
+   APIInfo = "TOKEN:/ip4/10.0.0.1/tcp/2345/http"
+
+Any command runs against a single miner of the config with --miner <name>.
+These commands aggregate across all miners, or those selected with --miner,
+which can then be repeated.`,
+	Subcommands: []*cli.Command{
+		minersListCmd,
+		minersProvingCmd,
+		minersSectorsCmd,
+		minersFundsCmd,
+	},
+}
+
+var minersListCmd = &cli.Command{
+	Name:  "list",
+	Usage: "List the miners, their actor addresses and versions",
+	Action: func(cctx *cli.Context) error {
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Version"),
+			tablewriter.Col("Endpoint"),
+			tablewriter.NewLineCol("Error"),
+		)
+
+		err := forEachMiner(cctx, tw, func(ctx context.Context, ep cliutil.MinerEndpoint, mapi api.StorageMiner, maddr address.Address, row map[string]interface{}) error {
+			row["Endpoint"] = cliutil.ParseApiInfo(ep.APIInfo).Addr
+
+			v, err := mapi.Version(ctx)
+			if err != nil {
+				return err
+			}
+			row["Version"] = v.Version
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var minersProvingCmd = &cli.Command{
+	Name:  "proving",
+	Usage: "Show the proving status of the miners",
+	Action: func(cctx *cli.Context) error {
+		fapi, closer, err := lcli.GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Deadline"),
+			tablewriter.Col("Proven"),
+			tablewriter.Col("Live"),
+			tablewriter.Col("Active"),
+			tablewriter.Col("Faulty"),
+			tablewriter.Col("Recovering"),
+			tablewriter.NewLineCol("Error"),
+		)
+
+		err = forEachMiner(cctx, tw, func(ctx context.Context, ep cliutil.MinerEndpoint, mapi api.StorageMiner, maddr address.Address, row map[string]interface{}) error {
+			head, err := fapi.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			di, err := fapi.StateMinerProvingDeadline(ctx, maddr, head.Key())
+			if err != nil {
+				return xerrors.Errorf("getting proving deadline: %w", err)
+			}
+			dls, err := fapi.StateMinerDeadlines(ctx, maddr, head.Key())
+			if err != nil {
+				return xerrors.Errorf("getting deadlines: %w", err)
+			}
+			parts, err := fapi.StateMinerPartitions(ctx, maddr, di.Index, head.Key())
+			if err != nil {
+				return xerrors.Errorf("getting partitions: %w", err)
+			}
+			proven, err := dls[di.Index].PostSubmissions.Count()
+			if err != nil {
+				return err
+			}
+			sc, err := fapi.StateMinerSectorCount(ctx, maddr, head.Key())
+			if err != nil {
+				return xerrors.Errorf("getting sector count: %w", err)
+			}
+			recoveries, err := fapi.StateMinerRecoveries(ctx, maddr, head.Key())
+			if err != nil {
+				return xerrors.Errorf("getting recoveries: %w", err)
+			}
+			recovering, err := recoveries.Count()
+			if err != nil {
+				return err
+			}
+
+			row["Deadline"] = di.Index
+			row["Proven"] = fmt.Sprintf("%d/%d", proven, len(parts))
+			row["Live"] = sc.Live
+			row["Active"] = sc.Active
+			row["Faulty"] = sc.Faulty
+			if sc.Faulty > 0 {
+				row["Faulty"] = color.RedString("%d", sc.Faulty)
+			}
+			row["Recovering"] = recovering
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var minersSectorsCmd = &cli.Command{
+	Name:  "sectors",
+	Usage: "Show the sector counts of the miners",
+	Action: func(cctx *cli.Context) error {
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Total"),
+			tablewriter.Col("Proving"),
+			tablewriter.Col("Sealing"),
+			tablewriter.Col("Failed"),
+			tablewriter.NewLineCol("Error"),
+		)
+
+		var lk sync.Mutex
+		var total, proving, sealingCount, failed int
+		err := forEachMiner(cctx, tw, func(ctx context.Context, ep cliutil.MinerEndpoint, mapi api.StorageMiner, maddr address.Address, row map[string]interface{}) error {
+			summary, err := mapi.SectorsSummary(ctx)
+			if err != nil {
+				return err
+			}
+
+			var t, p, s, f int
+			for st, n := range summary {
+				t += n
+				switch {
+				case st == api.SectorState(sealing.Proving) || st == api.SectorState(sealing.Available):
+					p += n
+				case strings.Contains(string(st), "Fail"):
+					f += n
+				case st == api.SectorState(sealing.Removed):
+				default:
+					s += n
+				}
+			}
+
+			row["Total"] = t
+			row["Proving"] = p
+			row["Sealing"] = s
+			row["Failed"] = f
+			if f > 0 {
+				row["Failed"] = color.RedString("%d", f)
+			}
+
+			lk.Lock()
+			defer lk.Unlock()
+			total, proving, sealingCount, failed = total+t, proving+p, sealingCount+s, failed+f
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		tw.Write(map[string]interface{}{
+			"Name":    color.New(color.Bold).Sprint("Total"),
+			"Total":   total,
+			"Proving": proving,
+			"Sealing": sealingCount,
+			"Failed":  failed,
+		})
+		return tw.Flush(os.Stdout)
+	},
+}
+
+var minersFundsCmd = &cli.Command{
+	Name:  "funds",
+	Usage: "Show the balances of the miner actors",
+	Action: func(cctx *cli.Context) error {
+		fapi, closer, err := lcli.GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("Actor"),
+			tablewriter.Col("Balance"),
+			tablewriter.Col("PreCommit"),
+			tablewriter.Col("Pledge"),
+			tablewriter.Col("Vesting"),
+			tablewriter.Col("Available"),
+			tablewriter.Col("Market"),
+			tablewriter.NewLineCol("Error"),
+		)
+
+		var lk sync.Mutex
+		totals := map[string]abi.TokenAmount{}
+		add := func(col string, v abi.TokenAmount, row map[string]interface{}) {
+			row[col] = types.FIL(v).Short()
+
+			lk.Lock()
+			defer lk.Unlock()
+			if t, ok := totals[col]; ok {
+				v = big.Add(t, v)
+			}
+			totals[col] = v
+		}
+
+		err = forEachMiner(cctx, tw, func(ctx context.Context, ep cliutil.MinerEndpoint, mapi api.StorageMiner, maddr address.Address, row map[string]interface{}) error {
+			mact, err := fapi.StateGetActor(ctx, maddr, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("getting miner actor: %w", err)
+			}
+			mas, err := miner.Load(store.ActorStore(ctx, blockstore.NewAPIBlockstore(fapi)), mact)
+			if err != nil {
+				return xerrors.Errorf("loading miner actor state: %w", err)
+			}
+			locked, err := mas.LockedFunds()
+			if err != nil {
+				return xerrors.Errorf("getting locked funds: %w", err)
+			}
+			avail, err := mas.AvailableBalance(mact.Balance)
+			if err != nil {
+				return xerrors.Errorf("getting available balance: %w", err)
+			}
+			mb, err := fapi.StateMarketBalance(ctx, maddr, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("getting market balance: %w", err)
+			}
+
+			add("Balance", mact.Balance, row)
+			add("PreCommit", locked.PreCommitDeposits, row)
+			add("Pledge", locked.InitialPledgeRequirement, row)
+			add("Vesting", locked.VestingFunds, row)
+			add("Available", avail, row)
+			add("Market", mb.Escrow, row)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		row := map[string]interface{}{"Name": color.New(color.Bold).Sprint("Total")}
+		for col, v := range totals {
+			row[col] = types.FIL(v).Short()
+		}
+		tw.Write(row)
+		return tw.Flush(os.Stdout)
+	},
+}
+
+// forEachMiner runs cb against the selected miners concurrently, passing it the
+// actor address of the miner, and writes a row for each miner in the order of
+// the miners config. Miners which can't be reached or fail are listed with
+// their error.
+func forEachMiner(cctx *cli.Context, tw *tablewriter.TableWriter, cb func(ctx context.Context, ep cliutil.MinerEndpoint, mapi api.StorageMiner, maddr address.Address, row map[string]interface{}) error) error {
+	eps, err := cliutil.GetMinerEndpoints(cctx)
+	if err != nil {
+		return err
+	}
+	if len(eps) == 0 {
+		return xerrors.Errorf("no miners in the miners config")
+	}
+
+	ctx := lcli.ReqContext(cctx)
+	rows := make([]map[string]interface{}, len(eps))
+
+	var wg sync.WaitGroup
+	for i, ep := range eps {
+		i, ep := i, ep
+		rows[i] = map[string]interface{}{"Name": ep.Name}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := func() error {
+				mapi, closer, err := ep.Connect(ctx)
+				if err != nil {
+					return err
+				}
+				defer closer()
+
+				maddr, err := mapi.ActorAddress(ctx)
+				if err != nil {
+					return err
+				}
+				rows[i]["Actor"] = maddr
+
+				return cb(ctx, ep, mapi, maddr, rows[i])
+			}()
+			if err != nil {
+				rows[i]["Error"] = color.RedString("%s", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, row := range rows {
+		tw.Write(row)
+	}
+	return nil
+}
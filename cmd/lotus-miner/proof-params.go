@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/docker/go-units"
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	lcli "github.com/filecoin-project/lotus/cli"
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+)
+
+var proofParamsCmd = &cli.Command{
+	Name:  "proof-params",
+	Usage: "Manage the proof parameter cache",
+	Subcommands: []*cli.Command{
+		proofParamsStatusCmd,
+		proofParamsFetchCmd,
+	},
+}
+
+func parseSectorSizes(cctx *cli.Context) ([]abi.SectorSize, error) {
+	var sizes []abi.SectorSize
+	for _, arg := range cctx.Args().Slice() {
+		s, err := units.RAMInBytes(arg)
+		if err != nil {
+			return nil, xerrors.Errorf("error parsing sector size (specify as \"32GiB\", for instance): %w", err)
+		}
+		sizes = append(sizes, abi.SectorSize(s))
+	}
+	return sizes, nil
+}
+
+var proofParamsStatusCmd = &cli.Command{
+	Name:      "status",
+	Usage:     "List proof parameter files of the given sector sizes, or of all sector sizes",
+	ArgsUsage: "[sectorSize ...]",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "verify",
+			Usage: "check the digest of all present files, which reads them fully",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerAPI, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		sizes, err := parseSectorSizes(cctx)
+		if err != nil {
+			return err
+		}
+
+		st, err := minerAPI.ProofParamsStatus(ctx, sizes, cctx.Bool("verify"))
+		if err != nil {
+			return err
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Name"),
+			tablewriter.Col("SectorSize"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Status"),
+			tablewriter.NewLineCol("Error"))
+
+		for _, f := range st.Files {
+			ssize := "-"
+			if f.SectorSize != 0 {
+				ssize = f.SectorSize.ShortString()
+			}
+
+			status := color.GreenString("present")
+			switch {
+			case !f.Present:
+				status = color.RedString("missing")
+			case f.Error != "":
+				status = color.RedString("corrupted")
+			case f.Verified:
+				status = color.GreenString("verified")
+			}
+
+			m := map[string]interface{}{
+				"Name":       f.Name,
+				"SectorSize": ssize,
+				"Size":       types.SizeStr(types.NewInt(uint64(f.Size))),
+				"Status":     status,
+			}
+			if f.Error != "" {
+				m["Error"] = f.Error
+			}
+			tw.Write(m)
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+
+		fmt.Printf("\nDirectory: %s\n", st.Dir)
+		fmt.Printf("Disk usage: %s\n", types.SizeStr(types.NewInt(uint64(st.DiskUsage))))
+		if st.Missing > 0 || st.Corrupted > 0 {
+			fmt.Printf("%d missing, %d corrupted; fetch them with lotus-miner proof-params fetch\n", st.Missing, st.Corrupted)
+		}
+		return nil
+	},
+}
+
+var proofParamsFetchCmd = &cli.Command{
+	Name:      "fetch",
+	Usage:     "Fetch missing or corrupted proof parameters of the given sector sizes on the miner",
+	ArgsUsage: "[sectorSize ...]",
+	Description: `Without sector sizes only the verification keys and files shared by all
+sector sizes are fetched.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "mirror",
+			Usage: "gateway URL to fetch parameters from instead of the default, e.g. https://example.com/ipfs/",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerAPI, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		sizes, err := parseSectorSizes(cctx)
+		if err != nil {
+			return err
+		}
+
+		if err := minerAPI.ProofParamsFetch(ctx, sizes, cctx.String("mirror")); err != nil {
+			return err
+		}
+
+		fmt.Println("Proof parameters fetched")
+		return nil
+	},
+}
@@ -21,6 +21,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/proof"
 
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
@@ -38,11 +39,13 @@ var provingCmd = &cli.Command{
 		provingInfoCmd,
 		provingDeadlinesCmd,
 		provingDeadlineInfoCmd,
+		provingScheduleCmd,
 		provingFaultsCmd,
 		provingCheckProvableCmd,
 		workersCmd(false),
 		provingComputeCmd,
 		provingRecoverFaultsCmd,
+		provingSimulateCmd,
 	},
 }
 
@@ -298,6 +301,83 @@ var provingDeadlinesCmd = &cli.Command{
 	},
 }
 
+var provingScheduleCmd = &cli.Command{
+	Name:  "schedule",
+	Usage: "View the next challenge window of each deadline, with wall-clock times",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "utc",
+			Usage: "Print times in UTC instead of local time",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		api, acloser, err := lcli.GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer acloser()
+
+		ctx := lcli.ReqContext(cctx)
+
+		maddr, err := getActorAddress(ctx, cctx)
+		if err != nil {
+			return err
+		}
+
+		ps, err := api.StateMinerProvingSchedule(ctx, maddr, types.EmptyTSK)
+		if err != nil {
+			return xerrors.Errorf("getting proving schedule: %w", err)
+		}
+
+		fmtTime := func(t time.Time) string {
+			if cctx.Bool("utc") {
+				t = t.UTC()
+			}
+			return t.Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Printf("Miner: %s\n", color.BlueString("%s", maddr))
+		fmt.Printf("Current Epoch: %d (%s)\n", ps.Epoch, fmtTime(ps.EpochTime))
+		fmt.Printf("Proving Period Start: %d\n\n", ps.PeriodStart)
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "deadline\topen\tclose\tfault cutoff\tpartitions\tsectors (faults)\tproven partitions")
+
+		for _, dl := range ps.Deadlines {
+			var sectors, faults uint64
+			for _, p := range dl.Partitions {
+				sc, err := p.LiveSectors.Count()
+				if err != nil {
+					return err
+				}
+				fc, err := p.FaultySectors.Count()
+				if err != nil {
+					return err
+				}
+				sectors += sc
+				faults += fc
+			}
+
+			proven, err := dl.PostSubmissions.Count()
+			if err != nil {
+				return err
+			}
+
+			var cur string
+			if dl.Current {
+				cur = "\t(current)"
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%d (%s)\t%d (%s)\t%d (%s)\t%d\t%d (%d)\t%d%s\n", dl.Index,
+				dl.Open, fmtTime(dl.OpenTime),
+				dl.Close, fmtTime(dl.CloseTime),
+				dl.FaultCutoff, fmtTime(dl.FaultCutoffTime),
+				len(dl.Partitions), sectors, faults, proven, cur)
+		}
+
+		return tw.Flush()
+	},
+}
+
 var provingDeadlineInfoCmd = &cli.Command{
 	Name:  "deadline",
 	Usage: "View the current proving period deadline information by its index",
@@ -768,3 +848,88 @@ var provingRecoverFaultsCmd = &cli.Command{
 		return nil
 	},
 }
+
+var provingSimulateCmd = &cli.Command{
+	Name:      "simulate",
+	Usage:     "Project the power loss, fault fees and recovery of sectors unavailable for an outage",
+	ArgsUsage: "[sector numbers]",
+	Description: `Simulates the given sectors, or the sectors stored on the given storage paths,
+being unavailable for the outage duration starting now, e.g. to take down a
+storage path for maintenance. Sectors miss the challenge windows of their
+deadlines opening during the outage, and recover in the first window after.
+Nothing is changed or sent to the chain.`,
+	Flags: []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "storage-id",
+			Usage: "storage path (path id) assumed missing",
+		},
+		&cli.DurationFlag{
+			Name:  "outage",
+			Usage: "duration of the outage",
+			Value: 24 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		var params api.ProvingSimulateParams
+		for _, v := range cctx.Args().Slice() {
+			s, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return xerrors.Errorf("parsing sector number %s: %w", v, err)
+			}
+			params.Sectors = append(params.Sectors, abi.SectorNumber(s))
+		}
+		for _, id := range cctx.StringSlice("storage-id") {
+			params.StoragePaths = append(params.StoragePaths, storiface.ID(id))
+		}
+		if len(params.Sectors) == 0 && len(params.StoragePaths) == 0 {
+			return lcli.ShowHelp(cctx, xerrors.Errorf("must pass sector numbers or --storage-id"))
+		}
+		params.Outage = abi.ChainEpoch(cctx.Duration("outage").Seconds() / float64(build.BlockDelaySecs))
+
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		sim, err := minerApi.ProvingSimulate(ctx, params)
+		if err != nil {
+			return err
+		}
+
+		fmt.Printf("Current Epoch: %d\n", sim.Height)
+		fmt.Printf("Outage:        %d epochs, until %s\n", sim.Outage, cliutil.EpochTime(sim.Height, sim.Height+sim.Outage))
+		fmt.Printf("Sectors:       %d\n", sim.Sectors)
+		if len(sim.Skipped) > 0 {
+			fmt.Printf("Skipped:       %d (already faulty or not active)\n", len(sim.Skipped))
+		}
+		fmt.Printf("Power Lost:    %s raw, %s QA\n", types.SizeStr(sim.RawPower), types.DeciStr(sim.QAPower))
+		fmt.Printf("Fault Fees:    %s\n", color.RedString(types.FIL(sim.Penalty).Short()))
+		if sim.Terminated > 0 {
+			fmt.Printf("Terminated:    %s (termination fees not included)\n", color.RedString("%d sectors", sim.Terminated))
+		}
+		if sim.Recovered > 0 {
+			fmt.Printf("Recovered by:  %s\n", cliutil.EpochTime(sim.Height, sim.Recovered))
+		}
+		fmt.Println()
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "deadline\tsectors\tQA power\tfaulted\tfees\tpenalty\trecovered")
+		for _, dl := range sim.Deadlines {
+			if dl.Faulted == 0 {
+				_, _ = fmt.Fprintf(tw, "%d\t%d\t%s\t%s\t\t\t\n", dl.Index, dl.Sectors, types.DeciStr(dl.QAPower), color.GreenString("no"))
+				continue
+			}
+
+			recovered := fmt.Sprint(dl.Recovered)
+			if dl.Terminated != 0 {
+				recovered = color.RedString("terminated at %d", dl.Terminated)
+			}
+			_, _ = fmt.Fprintf(tw, "%d\t%d\t%s\t%d\t%d\t%s\t%s\n", dl.Index, dl.Sectors, types.DeciStr(dl.QAPower),
+				dl.Faulted, dl.FaultFees, types.FIL(dl.Penalty).Short(), recovered)
+		}
+		return tw.Flush()
+	},
+}
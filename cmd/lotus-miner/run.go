@@ -119,7 +119,7 @@ var runCmd = &cli.Command{
 
 		lr, err := r.Lock(repo.StorageMiner)
 		if err != nil {
-			return err
+			return repo.ExplainLockError(r, err)
 		}
 		c, err := lr.Config()
 		if err != nil {
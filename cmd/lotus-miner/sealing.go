@@ -34,6 +34,10 @@ var sealingCmd = &cli.Command{
 	Usage: "interact with sealing pipeline",
 	Subcommands: []*cli.Command{
 		sealingJobsCmd,
+		sealingHistoryCmd,
+		sealingTaskStatsCmd,
+		sealingPipelineStatsCmd,
+		sealingGPUsCmd,
 		workersCmd(true),
 		sealingSchedDiagCmd,
 		sealingAbortCmd,
@@ -89,6 +93,7 @@ func workersCmd(sealing bool) *cli.Command {
 				        VMEM: [||||||||                                                        ] 12% 125.8 GiB/1008 GiB
 				        GPU:  [                                                                ] 0% 0.00/1 gpu(s) in use
 				        GPU: NVIDIA GeForce RTX 3090, not used
+				        NUMA 0: 64 cpu(s), 412.3 GiB/503.8 GiB free, 1 pinned task(s), allows PC1
 			*/
 
 			for _, stat := range st {
@@ -196,6 +201,22 @@ func workersCmd(sealing bool) *cli.Command {
 				for _, gpu := range stat.Info.Resources.GPUs {
 					fmt.Printf("\tGPU: %s\n", color.New(gpuCol).Sprintf("%s, %sused", gpu, gpuUse))
 				}
+
+				// NUMA nodes
+
+				for _, node := range stat.Info.Resources.NUMANodes {
+					var tasks []string
+					for _, tt := range node.Tasks {
+						tasks = append(tasks, tt.Short())
+					}
+					allows := "no pinned tasks"
+					if len(tasks) > 0 {
+						allows = "allows " + strings.Join(tasks, " ")
+					}
+
+					fmt.Printf("\tNUMA %d: %d cpu(s), %s/%s free, %d pinned task(s), %s\n", node.ID, len(node.CPUs),
+						types.SizeStr(types.NewInt(node.MemFree)), types.SizeStr(types.NewInt(node.MemTotal)), node.Running, allows)
+				}
 			}
 
 			return nil
@@ -308,6 +329,290 @@ var sealingJobsCmd = &cli.Command{
 	},
 }
 
+var sealingHistoryCmd = &cli.Command{
+	Name:  "history",
+	Usage: "list finished sealing tasks",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "hostname",
+			Usage: "only list tasks executed on the worker with this hostname",
+		},
+		&cli.StringFlag{
+			Name:  "task",
+			Usage: "only list tasks of this type, e.g. PC1 or seal/v0/precommit/1",
+		},
+		&cli.Uint64Flag{
+			Name:  "sector",
+			Usage: "only list tasks for this sector",
+		},
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only list tasks started within this duration",
+		},
+		&cli.BoolFlag{
+			Name:  "failed",
+			Usage: "only list failed tasks",
+		},
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "maximum number of tasks to list",
+			Value: 50,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		filter := storiface.TaskHistoryFilter{
+			Hostname: cctx.String("hostname"),
+			Failed:   cctx.Bool("failed"),
+		}
+		if cctx.IsSet("sector") {
+			sn := abi.SectorNumber(cctx.Uint64("sector"))
+			filter.Sector = &sn
+		}
+		if cctx.IsSet("since") {
+			filter.Since = time.Now().Add(-cctx.Duration("since"))
+		}
+
+		task := cctx.String("task")
+		if strings.Contains(task, "/") {
+			filter.Task = sealtasks.TaskType(task)
+		}
+		if task == "" || filter.Task != "" {
+			// short task names are matched below, the limit is applied after that
+			filter.Limit = cctx.Int("limit")
+		}
+
+		entries, err := minerApi.WorkerTaskHistory(ctx, filter)
+		if err != nil {
+			return xerrors.Errorf("getting task history: %w", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "ID\tSector\tWorker\tHostname\tTask\tAttempt\tStarted\tTook\tError\n")
+
+		var n int
+		for _, e := range entries {
+			if task != "" && filter.Task == "" && !strings.EqualFold(e.Task.Short(), task) {
+				continue
+			}
+			if limit := cctx.Int("limit"); limit > 0 && n >= limit {
+				break
+			}
+			n++
+
+			errStr := ""
+			if e.Error != "" {
+				errStr = color.RedString(e.Error)
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%s\t%d\t%s\t%s\t%s\n",
+				hex.EncodeToString(e.ID.ID[:4]),
+				e.Sector.Number,
+				hex.EncodeToString(e.Worker[:4]),
+				e.Hostname,
+				e.Task.Short(),
+				e.Attempt,
+				e.Start.Format(time.Stamp),
+				e.Duration.Truncate(time.Millisecond*100),
+				errStr)
+		}
+
+		return tw.Flush()
+	},
+}
+
+var sealingTaskStatsCmd = &cli.Command{
+	Name:  "task-stats",
+	Usage: "show task duration percentiles and failure counts per worker",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "since",
+			Usage: "only consider tasks started within this duration",
+			Value: 24 * time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		stats, err := minerApi.WorkerTaskStats(ctx, time.Now().Add(-cctx.Duration("since")))
+		if err != nil {
+			return xerrors.Errorf("getting task stats: %w", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Worker\tHostname\tTask\tCount\tFailed\tRetries\tP50\tP95\tMax\n")
+
+		for _, st := range stats {
+			failed := fmt.Sprint(st.Failed)
+			if st.Failed > 0 {
+				failed = color.RedString(failed)
+			}
+
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%d\t%s\t%s\t%s\n",
+				hex.EncodeToString(st.Worker[:4]),
+				st.Hostname,
+				st.Task.Short(),
+				st.Count,
+				failed,
+				st.Retries,
+				st.P50.Truncate(time.Second),
+				st.P95.Truncate(time.Second),
+				st.Max.Truncate(time.Second))
+		}
+
+		return tw.Flush()
+	},
+}
+
+var sealingPipelineStatsCmd = &cli.Command{
+	Name:  "pipeline-stats",
+	Usage: "show the throughput and queue depth of each sealing pipeline stage",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "days",
+			Usage: "number of days of history to show",
+			Value: 7,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		stats, err := minerApi.SealingPipelineStats(ctx, cctx.Int("days"))
+		if err != nil {
+			return xerrors.Errorf("getting pipeline stats: %w", err)
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Stage\tSectors/day\tQueued\n")
+		for _, st := range stats.Stages {
+			_, _ = fmt.Fprintf(tw, "%s\t%d\t%d\n", st.Stage, st.PerDay, st.Queued)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+
+		if len(stats.History) == 0 {
+			return nil
+		}
+
+		fmt.Println()
+		tw = tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprint(tw, "Day")
+		for _, st := range stats.Stages {
+			_, _ = fmt.Fprintf(tw, "\t%s", st.Stage)
+		}
+		_, _ = fmt.Fprintln(tw)
+		for _, day := range stats.History {
+			_, _ = fmt.Fprint(tw, day.Day)
+			for _, st := range stats.Stages {
+				_, _ = fmt.Fprintf(tw, "\t%d", day.Completed[st.Stage])
+			}
+			_, _ = fmt.Fprintln(tw)
+		}
+		return tw.Flush()
+	},
+}
+
+var sealingGPUsCmd = &cli.Command{
+	Name:  "gpus",
+	Usage: "show GPU models, memory, utilization and running GPU tasks of all workers",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "format",
+			Usage: "output format of the list, either table or json",
+			Value: "table",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		ctx := lcli.ReqContext(cctx)
+
+		stats, err := minerApi.WorkerGPUStats(ctx)
+		if err != nil {
+			return xerrors.Errorf("getting gpu stats: %w", err)
+		}
+
+		switch cctx.String("format") {
+		case "table":
+		case "json":
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			return enc.Encode(stats)
+		default:
+			return fmt.Errorf("unknown format: %s; use `table` or `json`", cctx.String("format"))
+		}
+
+		tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintf(tw, "Worker\tHostname\tGPU\tModel\tMemory\tUtil\tTemp\tTasks\n")
+
+		for _, st := range stats {
+			var tasks []string
+			for _, job := range st.Tasks {
+				tasks = append(tasks, fmt.Sprintf("%s(%d)", job.Task.Short(), job.Sector.Number))
+			}
+			taskStr := strings.Join(tasks, " ")
+			if taskStr == "" {
+				taskStr = "-"
+			}
+
+			worker := hex.EncodeToString(st.Worker[:4])
+			if st.Error != "" && len(st.GPUs) == 0 {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t-\t%s\t-\t-\t-\t%s\n", worker, st.Hostname, color.RedString(st.Error), taskStr)
+				continue
+			}
+			if len(st.GPUs) == 0 {
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t-\tno gpus\t-\t-\t-\t%s\n", worker, st.Hostname, taskStr)
+				continue
+			}
+
+			for i, gpu := range st.GPUs {
+				mem, util, temp := "-", "-", "-"
+				if gpu.HasUsage {
+					mem = fmt.Sprintf("%s/%s", types.SizeStr(types.NewInt(gpu.MemUsed)), types.SizeStr(types.NewInt(gpu.MemTotal)))
+					util = fmt.Sprintf("%.0f%%", gpu.Utilization)
+					if gpu.Utilization >= 90 {
+						util = color.GreenString(util)
+					}
+					temp = fmt.Sprintf("%dC", gpu.Temperature)
+				}
+
+				// tasks can't be attributed to a single GPU, list them once per worker
+				if i > 0 {
+					taskStr = ""
+				}
+
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\t%s\t%s\n", worker, st.Hostname, gpu.Index, gpu.Name, mem, util, temp, taskStr)
+			}
+		}
+
+		return tw.Flush()
+	},
+}
+
 var sealingSchedDiagCmd = &cli.Command{
 	Name:  "sched-diag",
 	Usage: "Dump internal scheduler state",
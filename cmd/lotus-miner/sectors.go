@@ -39,6 +39,7 @@ import (
 	"github.com/filecoin-project/lotus/lib/strle"
 	"github.com/filecoin-project/lotus/lib/tablewriter"
 	sealing "github.com/filecoin-project/lotus/storage/pipeline"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
 const parallelSectorChecks = 300
@@ -49,6 +50,7 @@ var sectorsCmd = &cli.Command{
 	Subcommands: []*cli.Command{
 		sectorsStatusCmd,
 		sectorsListCmd,
+		sectorsExportCmd,
 		sectorsRefsCmd,
 		sectorsUpdateCmd,
 		sectorsPledgeCmd,
@@ -68,6 +70,58 @@ var sectorsCmd = &cli.Command{
 		sectorsRefreshPieceMatchingCmd,
 		sectorsCompactPartitionsCmd,
 		sectorsUnsealCmd,
+		sectorsReconcileUnsealedCmd,
+		sectorsHotnessCmd,
+	},
+}
+
+var sectorsExportCmd = &cli.Command{
+	Name:  "export",
+	Usage: "stream a record of every sector known locally or on chain to stdout",
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "cbor",
+			Usage: "write length-prefixed CBOR records instead of JSON lines",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerApi, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		chunks, err := minerApi.SectorsSummaryStream(ctx)
+		if err != nil {
+			return err
+		}
+
+		w := bufio.NewWriter(cctx.App.Writer)
+		defer w.Flush() //nolint:errcheck
+
+		enc := json.NewEncoder(w)
+		for chunk := range chunks {
+			records, err := api.DecodeSectorRecords(chunk)
+			if err != nil {
+				return err
+			}
+
+			if cctx.Bool("cbor") {
+				if _, err := w.Write(chunk); err != nil {
+					return err
+				}
+				continue
+			}
+
+			for _, rec := range records {
+				if err := enc.Encode(rec); err != nil {
+					return err
+				}
+			}
+		}
+
+		return ctx.Err()
 	},
 }
 
@@ -156,6 +210,12 @@ var sectorsStatusCmd = &cli.Command{
 		}
 		fmt.Printf("Deals:\t\t%v\n", status.Deals)
 		fmt.Printf("Retries:\t%d\n", status.Retries)
+		if len(status.PlacementGroups) > 0 {
+			fmt.Printf("Placement:\t%s\n", strings.Join(status.PlacementGroups, ", "))
+		}
+		if status.RetryClass != "" {
+			fmt.Printf("Failures:\t%d (%s)\n", status.RetryAttempts, status.RetryClass)
+		}
 		if status.LastErr != "" {
 			fmt.Printf("Last Error:\t\t%s\n", status.LastErr)
 		}
@@ -2321,3 +2381,144 @@ var sectorsUnsealCmd = &cli.Command{
 		return minerAPI.SectorUnseal(ctx, abi.SectorNumber(sectorNum))
 	},
 }
+
+var sectorsReconcileUnsealedCmd = &cli.Command{
+	Name:      "reconcile-unsealed",
+	Usage:     "bring unsealed copies of proving sectors into compliance with the unsealed copy policies",
+	ArgsUsage: "[sector ranges, e.g. 1-100,150]",
+	Description: `Lists unsealed copies which should be removed or trimmed, and sectors which
+should be unsealed according to the UnsealedCopyPolicies and AlwaysKeepUnsealedCopy
+sealing settings. Without arguments all proving sectors are checked.
+
+Pass --really-do-it to apply the changes; unsealing runs synchronously and can take a
+long time for many sectors.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "remove, trim and unseal sector data",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerAPI, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+		if cctx.NArg() > 1 {
+			return lcli.IncorrectNumArgs(cctx)
+		}
+
+		var sectors []abi.SectorNumber
+		if cctx.Args().Present() {
+			bf, err := strle.HumanRangesToBitField(cctx.Args().First())
+			if err != nil {
+				return xerrors.Errorf("parsing ranges: %w", err)
+			}
+			if err := bf.ForEach(func(sn uint64) error {
+				sectors = append(sectors, abi.SectorNumber(sn))
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		actions, err := minerAPI.SectorsReconcileUnsealed(ctx, sectors, cctx.Bool("really-do-it"))
+		if err != nil {
+			return err
+		}
+		if len(actions) == 0 {
+			fmt.Println("All unsealed copies comply with the policies")
+			return nil
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Sector"),
+			tablewriter.Col("Action"),
+			tablewriter.Col("Keep"),
+			tablewriter.Col("Missing"),
+			tablewriter.Col("Applied"),
+			tablewriter.NewLineCol("Error"))
+
+		ranges := func(rs []storiface.Range) string {
+			var out []string
+			for _, r := range rs {
+				out = append(out, fmt.Sprintf("%d+%d", r.Offset, r.Size))
+			}
+			return strings.Join(out, ",")
+		}
+
+		for _, act := range actions {
+			m := map[string]interface{}{
+				"Sector":  act.Sector,
+				"Action":  act.Action,
+				"Keep":    ranges(act.Keep),
+				"Missing": ranges(act.Missing),
+				"Applied": act.Applied,
+			}
+			if act.Error != "" {
+				m["Error"] = act.Error
+			}
+			tw.Write(m)
+		}
+
+		if err := tw.Flush(os.Stdout); err != nil {
+			return err
+		}
+		if !cctx.Bool("really-do-it") {
+			fmt.Println("Pass --really-do-it to apply the actions")
+		}
+		return nil
+	},
+}
+
+var sectorsHotnessCmd = &cli.Command{
+	Name:  "hotness",
+	Usage: "list how often pieces were read for retrievals, hottest first",
+	Flags: []cli.Flag{
+		&cli.IntFlag{
+			Name:  "limit",
+			Usage: "only list this many pieces, 0 for all",
+			Value: 50,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		minerAPI, closer, err := lcli.GetStorageMinerAPI(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+		ctx := lcli.ReqContext(cctx)
+
+		pieces, err := minerAPI.PiecesHotness(ctx)
+		if err != nil {
+			return err
+		}
+		if limit := cctx.Int("limit"); limit > 0 && len(pieces) > limit {
+			pieces = pieces[:limit]
+		}
+
+		tw := tablewriter.New(
+			tablewriter.Col("Sector"),
+			tablewriter.Col("Offset"),
+			tablewriter.Col("Size"),
+			tablewriter.Col("Score"),
+			tablewriter.Col("Reads"),
+			tablewriter.Col("Unseals"),
+			tablewriter.Col("LastRead"))
+
+		for _, p := range pieces {
+			tw.Write(map[string]interface{}{
+				"Sector":   p.Sector.Number,
+				"Offset":   p.Offset,
+				"Size":     types.SizeStr(types.NewInt(uint64(p.Size))),
+				"Score":    fmt.Sprintf("%.2f", p.Score),
+				"Reads":    p.Reads,
+				"Unseals":  p.Unseals,
+				"LastRead": p.LastRead.Format(time.RFC3339),
+			})
+		}
+
+		return tw.Flush(os.Stdout)
+	},
+}
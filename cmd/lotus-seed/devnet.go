@@ -0,0 +1,160 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/mitchellh/go-homedir"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/gen"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/genesis"
+	_ "github.com/filecoin-project/lotus/lib/sigs/delegated"
+)
+
+var genesisNewDevnetCmd = &cli.Command{
+	Name:      "new-devnet",
+	Usage:     "create a new genesis template for a local FEVM devnet",
+	ArgsUsage: "[genesis.json]",
+	Description: `Creates a genesis template with pre-funded Ethereum accounts derived from a
+mnemonic, and contracts deployed from their initcode. The template only depends
+on the flags: the same mnemonic always yields the same accounts, and contracts
+are always deployed at the same addresses.
+
+Contract files contain the hex encoded initcode of the contract (e.g. the
+output of solc --bin), or are JSON build artifacts with a "bytecode" field.
+
+Add miners to the template with add-miner as usual.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name: "network-name",
+		},
+		&cli.StringFlag{
+			Name:  "preset",
+			Usage: "network version and upgrade timing preset, see --list-presets",
+			Value: "latest",
+		},
+		&cli.BoolFlag{
+			Name:  "list-presets",
+			Usage: "list the available presets and exit",
+		},
+		&cli.StringFlag{
+			Name:  "mnemonic",
+			Usage: "BIP-39 mnemonic from which the Ethereum accounts are derived",
+			Value: gen.DefaultDevnetMnemonic,
+		},
+		&cli.StringFlag{
+			Name:  "passphrase",
+			Usage: "BIP-39 passphrase of the mnemonic",
+		},
+		&cli.IntFlag{
+			Name:  "accounts",
+			Usage: "number of pre-funded Ethereum accounts",
+			Value: 10,
+		},
+		&cli.StringFlag{
+			Name:  "balance",
+			Usage: "balance of each Ethereum account",
+			Value: "10000 FIL",
+		},
+		&cli.StringSliceFlag{
+			Name:  "contract",
+			Usage: "file with the initcode of a contract to deploy, can be repeated",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.Bool("list-presets") {
+			for _, p := range gen.DevnetPresets() {
+				fmt.Printf("%s: %s (network version %d)\n", p.Name, p.Description, p.NetworkVersion)
+			}
+			return nil
+		}
+
+		if cctx.NArg() != 1 {
+			return xerrors.New("seed genesis new-devnet [genesis.json]")
+		}
+
+		preset, err := gen.DevnetPresetByName(cctx.String("preset"))
+		if err != nil {
+			return err
+		}
+
+		balance, err := types.ParseFIL(cctx.String("balance"))
+		if err != nil {
+			return xerrors.Errorf("parsing balance: %w", err)
+		}
+
+		var contracts []genesis.Contract
+		for _, f := range cctx.StringSlice("contract") {
+			c, err := gen.ReadDevnetContract(f)
+			if err != nil {
+				return xerrors.Errorf("reading contract %s: %w", f, err)
+			}
+			contracts = append(contracts, c)
+		}
+
+		opts := gen.DevnetOptions{
+			NetworkName: cctx.String("network-name"),
+			Preset:      preset,
+			Mnemonic:    cctx.String("mnemonic"),
+			Passphrase:  cctx.String("passphrase"),
+			Accounts:    cctx.Int("accounts"),
+			Balance:     abi.TokenAmount(balance),
+			Contracts:   contracts,
+		}
+		if opts.NetworkName == "" {
+			opts.NetworkName = "devnet-" + uuid.New().String()
+		}
+
+		tmpl, accounts, deployed, err := gen.DevnetTemplate(opts)
+		if err != nil {
+			return err
+		}
+
+		genb, err := json.MarshalIndent(tmpl, "", "  ")
+		if err != nil {
+			return err
+		}
+
+		genf, err := homedir.Expand(cctx.Args().First())
+		if err != nil {
+			return err
+		}
+
+		if err := os.WriteFile(genf, genb, 0644); err != nil {
+			return err
+		}
+
+		fmt.Printf("Accounts (%s each):\n", types.FIL(balance))
+		for i, a := range accounts {
+			fmt.Printf("  %d: %s (%s) private key 0x%s\n", i, a.EthAddress, a.Key.Address, hex.EncodeToString(a.Key.PrivateKey))
+		}
+		if len(deployed) > 0 {
+			fmt.Println("Contracts:")
+			for i, c := range deployed {
+				fmt.Printf("  %d: %s %s\n", i, c.EthAddress, c.Name)
+			}
+		}
+		if len(preset.Env) > 0 {
+			var env []string
+			for k, v := range preset.Env {
+				env = append(env, k+"="+v)
+			}
+			sort.Strings(env)
+			fmt.Println("Set the following environment variables for all nodes of the devnet:")
+			for _, e := range env {
+				fmt.Printf("  export %s\n", e)
+			}
+		}
+
+		return nil
+	},
+}
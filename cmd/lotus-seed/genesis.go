@@ -35,6 +35,7 @@ var genesisCmd = &cli.Command{
 	Description: "manipulate lotus genesis template",
 	Subcommands: []*cli.Command{
 		genesisNewCmd,
+		genesisNewDevnetCmd,
 		genesisAddMinerCmd,
 		genesisAddMsigsCmd,
 		genesisSetVRKCmd,
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/chain/mqbridge"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+var chainBridgeCmd = &cli.Command{
+	Name:  "chain-bridge",
+	Usage: "Tools for the chain message queue bridge",
+	Subcommands: []*cli.Command{
+		chainBridgeResetCursorCmd,
+	},
+}
+
+var chainBridgeResetCursorCmd = &cli.Command{
+	Name:  "reset-cursor",
+	Usage: "Make the chain bridge publish from the head, skipping the changes since its cursor",
+	Description: `The chain bridge stops when its cursor can't be resumed from anymore. Resetting
+   the cursor makes it publish from the head, the head changes since the cursor
+   are never published. The node must be stopped.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "confirm resetting the cursor",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if !cctx.Bool("really-do-it") {
+			return xerrors.Errorf("pass --really-do-it to reset the chain bridge cursor")
+		}
+
+		r, err := repo.NewFS(cctx.String("repo"))
+		if err != nil {
+			return xerrors.Errorf("opening fs repo: %w", err)
+		}
+
+		exists, err := r.Exists()
+		if err != nil {
+			return err
+		}
+		if !exists {
+			return xerrors.Errorf("lotus repo doesn't exist")
+		}
+
+		lr, err := r.Lock(repo.FullNode)
+		if err != nil {
+			return err
+		}
+		defer lr.Close() //nolint:errcheck
+
+		mds, err := lr.Datastore(cctx.Context, "/metadata")
+		if err != nil {
+			return err
+		}
+
+		if err := mqbridge.ResetCursor(cctx.Context, namespace.Wrap(mds, mqbridge.DatastorePrefix)); err != nil {
+			return xerrors.Errorf("resetting the cursor: %w", err)
+		}
+		fmt.Println("Chain bridge cursor reset, the bridge publishes from the head once the node is started")
+		return nil
+	},
+}
@@ -63,6 +63,7 @@ func main() {
 		msgCmd,
 		electionCmd,
 		rpcCmd,
+		rpcReplayCmd,
 		cidCmd,
 		blockmsgidCmd,
 		signaturesCmd,
@@ -85,6 +86,7 @@ func main() {
 		gasTraceCmd,
 		replayOfflineCmd,
 		msgindexCmd,
+		chainBridgeCmd,
 		FevmAnalyticsCmd,
 		mismatchesCmd,
 	}
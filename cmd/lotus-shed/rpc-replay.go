@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	lcli "github.com/filecoin-project/lotus/cli"
+	"github.com/filecoin-project/lotus/lib/rpcreplay"
+	"github.com/filecoin-project/lotus/lib/tablewriter"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+var rpcReplayCmd = &cli.Command{
+	Name:  "rpc-replay",
+	Usage: "Replay recorded RPC calls against a node, comparing the responses and latencies",
+	Description: `Replays the calls of RPC recordings, written by a node with RPCRecording enabled,
+against the node of the API info, or the --target node, in the order they were
+recorded. The responses are compared to the recorded ones, so the node replayed
+against should be at the same chain state as the recorded one. Methods whose
+results depend on the chain head can be ignored with --ignore-method.
+
+The command fails when a response differs, a call fails, or a method's median
+latency regressed by more than --latency-factor, so it can gate a candidate
+build.`,
+	ArgsUsage: "<recording files>",
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "target",
+			Usage: "base URL of the node to replay against, instead of the node of the API info",
+		},
+		&cli.StringSliceFlag{
+			Name:  "ignore-method",
+			Usage: "prefix of the methods not to replay, e.g. eth_blockNumber",
+		},
+		&cli.Float64Flag{
+			Name:  "latency-factor",
+			Usage: "ratio of the replayed to recorded median latency of a method above which it's reported as regressed; 0 disables it",
+			Value: 2,
+		},
+		&cli.IntFlag{
+			Name:  "max-mismatches",
+			Usage: "number of mismatching calls to print",
+			Value: 20,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() == 0 {
+			return lcli.IncorrectNumArgs(cctx)
+		}
+
+		addr, headers, err := lcli.GetRawAPI(cctx, repo.FullNode, "v1")
+		if err != nil && !cctx.IsSet("target") {
+			return err
+		}
+		if cctx.IsSet("target") {
+			addr = cctx.String("target")
+		}
+
+		u, err := url.Parse(addr)
+		if err != nil {
+			return xerrors.Errorf("parsing api URL: %w", err)
+		}
+		switch u.Scheme {
+		case "ws":
+			u.Scheme = "http"
+		case "wss":
+			u.Scheme = "https"
+		}
+		// recordings hold the path of each call
+		u.Path = ""
+
+		rp := &rpcreplay.Replayer{
+			Target:        u.String(),
+			Header:        headers,
+			IgnoreMethods: cctx.StringSlice("ignore-method"),
+			LatencyFactor: cctx.Float64("latency-factor"),
+			MaxMismatches: cctx.Int("max-mismatches"),
+		}
+
+		ctx := lcli.ReqContext(cctx)
+		afmt := lcli.NewAppFmt(cctx.App)
+
+		passed := true
+		for _, path := range cctx.Args().Slice() {
+			f, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			rep, err := rp.Replay(ctx, f)
+			_ = f.Close()
+			if err != nil {
+				return xerrors.Errorf("replaying %s: %w", path, err)
+			}
+
+			afmt.Printf("%s: %d calls, %d matched, %d mismatched, %d skipped, %d failed\n",
+				path, rep.Calls, rep.Matched, rep.Mismatched, rep.Skipped, rep.Failed)
+
+			for _, m := range rep.Mismatches {
+				afmt.Printf("\n%s %s\n", color.RedString("MISMATCH"), strings.Join(m.Methods, ","))
+				afmt.Printf("  request:  %s\n", m.Request)
+				afmt.Printf("  at:       %s\n", m.Path)
+				afmt.Printf("  recorded: %s\n", m.Recorded)
+				afmt.Printf("  replayed: %s\n", m.Replayed)
+			}
+			afmt.Println()
+
+			tw := tablewriter.New(
+				tablewriter.Col("Method"),
+				tablewriter.Col("Calls"),
+				tablewriter.Col("Recorded p50"),
+				tablewriter.Col("Replayed p50"),
+				tablewriter.Col("Recorded p95"),
+				tablewriter.Col("Replayed p95"),
+				tablewriter.Col("Regressed"),
+			)
+			for _, l := range rep.Latencies {
+				regressed := ""
+				if l.Regressed {
+					regressed = color.RedString("yes")
+				}
+				tw.Write(map[string]interface{}{
+					"Method":       l.Method,
+					"Calls":        l.Calls,
+					"Recorded p50": l.RecordedP50,
+					"Replayed p50": l.ReplayedP50,
+					"Recorded p95": l.RecordedP95,
+					"Replayed p95": l.ReplayedP95,
+					"Regressed":    regressed,
+				})
+			}
+			if err := tw.Flush(cctx.App.Writer); err != nil {
+				return err
+			}
+			afmt.Println()
+
+			passed = passed && rep.Passed()
+		}
+
+		if !passed {
+			return fmt.Errorf("replayed calls mismatched, failed or regressed")
+		}
+		return nil
+	},
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/urfave/cli/v2"
 	"golang.org/x/xerrors"
@@ -63,6 +64,14 @@ var infoCmd = &cli.Command{
 			types.SizeStr(types.NewInt(info.Resources.MemPhysical)),
 			types.SizeStr(types.NewInt(info.Resources.MemSwapUsed)),
 			types.SizeStr(types.NewInt(info.Resources.MemSwap)))
+		for _, node := range info.Resources.NUMANodes {
+			var tasks []string
+			for _, tt := range node.Tasks {
+				tasks = append(tasks, tt.Short())
+			}
+			fmt.Printf("NUMA %d: CPUs: %d; RAM free: %s/%s; Pinned: %d; Tasks: %s\n", node.ID, len(node.CPUs),
+				types.SizeStr(types.NewInt(node.MemFree)), types.SizeStr(types.NewInt(node.MemTotal)), node.Running, strings.Join(tasks, " "))
+		}
 
 		fmt.Printf("Task types: ")
 		for _, t := range ttList(tt) {
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -265,6 +266,22 @@ var runCmd = &cli.Command{
 			Value:   0,
 			EnvVars: []string{"LOTUS_WORKER_POST_READ_TIMEOUT"},
 		},
+		&cli.StringFlag{
+			Name:    "numa-nodes",
+			Usage:   "NUMA nodes tasks of a type are allowed to run on, tasks are pinned to the least loaded node; e.g. 'PC1=0,1;PC2=1'",
+			EnvVars: []string{"LOTUS_WORKER_NUMA_NODES"},
+		},
+		&cli.BoolFlag{
+			Name:    "numa-pin-pc1",
+			Usage:   "pin PC1 tasks to the least loaded NUMA node when --numa-nodes doesn't list PC1",
+			Value:   true,
+			EnvVars: []string{"LOTUS_WORKER_NUMA_PIN_PC1"},
+		},
+		&cli.BoolFlag{
+			Name:    "numa-membind",
+			Usage:   "only allocate memory of pinned tasks from their NUMA node, instead of preferring it",
+			EnvVars: []string{"LOTUS_WORKER_NUMA_MEMBIND"},
+		},
 		&cli.StringFlag{
 			Name:    "timeout",
 			Usage:   "used when 'listen' is unspecified. must be a valid duration recognized by golang's time.ParseDuration function",
@@ -446,6 +463,11 @@ var runCmd = &cli.Command{
 			}
 		}
 
+		numaNodes, err := parseNUMANodes(cctx.String("numa-nodes"), taskTypes)
+		if err != nil {
+			return xerrors.Errorf("parsing --numa-nodes: %w", err)
+		}
+
 		if needParams {
 			if err := paramfetch.GetParams(ctx, build.ParametersJSON(), build.SrsJSON(), uint64(ssize)); err != nil {
 				return xerrors.Errorf("get params: %w", err)
@@ -582,6 +604,9 @@ var runCmd = &cli.Command{
 				MaxParallelChallengeReads: cctx.Int("post-parallel-reads"),
 				ChallengeReadTimeout:      cctx.Duration("post-read-timeout"),
 				Name:                      cctx.String("name"),
+				NUMANodes:                 numaNodes,
+				NUMAPinPC1:                cctx.Bool("numa-pin-pc1"),
+				NUMAMemBind:               cctx.Bool("numa-membind"),
 			}, remote, localStore, nodeApi, nodeApi, wsts),
 			LocalStore: localStore,
 			Storage:    lr,
@@ -758,3 +783,39 @@ func extractRoutableIP(timeout time.Duration) (string, error) {
 
 	return strings.Split(localAddr.IP.String(), ":")[0], nil
 }
+
+// parseNUMANodes parses NUMA node lists of the form 'PC1=0,1;PC2=1'
+func parseNUMANodes(spec string, taskTypes []sealtasks.TaskType) (map[sealtasks.TaskType][]int, error) {
+	out := map[sealtasks.TaskType][]int{}
+	for _, entry := range strings.Split(spec, ";") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, nodes, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, xerrors.Errorf("expected TASK=NODES, got '%s'", entry)
+		}
+
+		var tt sealtasks.TaskType
+		for _, t := range taskTypes {
+			if t.Short() == strings.TrimSpace(name) {
+				tt = t
+				break
+			}
+		}
+		if tt == "" {
+			return nil, xerrors.Errorf("task type '%s' isn't enabled on this worker", name)
+		}
+
+		for _, n := range strings.Split(nodes, ",") {
+			node, err := strconv.Atoi(strings.TrimSpace(n))
+			if err != nil || node < 0 {
+				return nil, xerrors.Errorf("invalid NUMA node '%s' for %s", n, name)
+			}
+			out[tt] = append(out[tt], node)
+		}
+	}
+	return out, nil
+}
@@ -19,7 +19,7 @@ import (
 )
 
 var backupCmd = lcli.BackupCmd("repo", repo.FullNode, func(cctx *cli.Context) (lcli.BackupAPI, jsonrpc.ClientCloser, error) {
-	return lcli.GetFullNodeAPI(cctx)
+	return lcli.GetFullNodeAPIV1(cctx)
 })
 
 func restore(cctx *cli.Context, r repo.Repo) error {
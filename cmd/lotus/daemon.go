@@ -6,8 +6,10 @@ package main
 import (
 	"bufio"
 	"context"
+	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -35,6 +37,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/consensus"
 	"github.com/filecoin-project/lotus/chain/consensus/filcns"
 	"github.com/filecoin-project/lotus/chain/index"
+	"github.com/filecoin-project/lotus/chain/snapshotter"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
@@ -119,6 +122,14 @@ var DaemonCmd = &cli.Command{
 			Name:  "import-snapshot",
 			Usage: "import chain state from a given chain export file or url",
 		},
+		&cli.StringFlag{
+			Name:  "snapshot-manifest",
+			Usage: "path or url of the signed manifest of the imported snapshot (default: snapshot path with .manifest.json appended)",
+		},
+		&cli.BoolFlag{
+			Name:  "allow-unsigned-snapshot",
+			Usage: "import snapshots without a manifest signed by a trusted publisher, when publishers are configured",
+		},
 		&cli.BoolFlag{
 			Name:  "halt-after-import",
 			Usage: "halt the process after importing chain from file",
@@ -127,6 +138,11 @@ var DaemonCmd = &cli.Command{
 			Name:  "lite",
 			Usage: "start lotus in lite mode",
 		},
+		&cli.BoolFlag{
+			Name: "light",
+			Usage: "start lotus as a light client (implies --lite): block headers are synced from the upstream node and checked " +
+				"locally, state proofs are verified against them, and the rest of the API is served by the upstream node",
+		},
 		&cli.StringFlag{
 			Name:  "pprof",
 			Usage: "specify name of file for writing cpu profile to",
@@ -152,6 +168,10 @@ var DaemonCmd = &cli.Command{
 			Name:  "api-max-req-size",
 			Usage: "maximum API request size accepted by the JSON RPC server",
 		},
+		&cli.BoolFlag{
+			Name:  "api-http2",
+			Usage: "also accept cleartext HTTP/2 (h2c) connections on the API endpoint, so JSON-RPC calls and /rpc/streams/v1 subscriptions can be multiplexed over one connection",
+		},
 		&cli.PathFlag{
 			Name:  "restore",
 			Usage: "restore from backup file",
@@ -160,9 +180,14 @@ var DaemonCmd = &cli.Command{
 			Name:  "restore-config",
 			Usage: "config file to use when restoring from backup",
 		},
+		&cli.BoolFlag{
+			Name:  "read-only",
+			Usage: "only serve API methods which don't change node state, disabling wallet signing, message pushing and admin methods regardless of token permissions",
+		},
 	},
 	Action: func(cctx *cli.Context) error {
-		isLite := cctx.Bool("lite")
+		isLight := cctx.Bool("light")
+		isLite := cctx.Bool("lite") || isLight
 
 		err := runmetrics.Enable(runmetrics.RunMetricOptions{
 			EnableCPU:    true,
@@ -263,6 +288,12 @@ var DaemonCmd = &cli.Command{
 			}
 		}
 
+		if !freshRepo {
+			if err := checkRepoMigrations(ctx, r); err != nil {
+				return xerrors.Errorf("checking repo migrations: %w", err)
+			}
+		}
+
 		chainfile := cctx.String("import-chain")
 		snapshot := cctx.String("import-snapshot")
 		if chainfile != "" || snapshot != "" {
@@ -275,7 +306,7 @@ var DaemonCmd = &cli.Command{
 				issnapshot = true
 			}
 
-			if err := ImportChain(ctx, r, chainfile, issnapshot); err != nil {
+			if err := ImportChain(ctx, r, chainfile, issnapshot, cctx.String("snapshot-manifest"), cctx.Bool("allow-unsigned-snapshot")); err != nil {
 				return err
 			}
 			if cctx.Bool("halt-after-import") {
@@ -319,7 +350,7 @@ var DaemonCmd = &cli.Command{
 
 		var api lapi.FullNode
 		stop, err := node.New(ctx,
-			node.FullAPI(&api, node.Lite(isLite)),
+			node.FullAPI(&api, node.Lite(isLite), node.LightSync(isLight)),
 
 			node.Base(),
 			node.Repo(r),
@@ -370,11 +401,20 @@ var DaemonCmd = &cli.Command{
 		}
 
 		// Instantiate the full node handler.
-		h, err := node.FullNodeHandler(api, true, serverOptions...)
+		handler := node.FullNodeHandler
+		if cctx.Bool("read-only") {
+			log.Warn("serving the API in read-only mode")
+			handler = node.ReadOnlyFullNodeHandler
+		}
+		h, err := handler(api, true, serverOptions...)
 		if err != nil {
 			return fmt.Errorf("failed to instantiate rpc handler: %s", err)
 		}
 
+		if cctx.Bool("api-http2") {
+			h = node.HTTP2Handler(h)
+		}
+
 		// Serve the RPC.
 		rpcStopper, err := node.ServeRPC(h, "lotus-daemon", endpoint)
 		if err != nil {
@@ -430,7 +470,10 @@ func importKey(ctx context.Context, api lapi.FullNode, f string) error {
 	return nil
 }
 
-func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool) (err error) {
+// ImportChain imports a chain export into the repo. Snapshots are checked
+// against their manifest, which must be signed by a trusted publisher when
+// publishers are configured, unless allowUnsigned is set.
+func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool, manifestLoc string, allowUnsigned bool) (err error) {
 	var rd io.Reader
 	var l int64
 	if strings.HasPrefix(fname, "http://") || strings.HasPrefix(fname, "https://") {
@@ -468,6 +511,45 @@ func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool)
 	}
 	defer lr.Close() //nolint:errcheck
 
+	c, err := lr.Config()
+	if err != nil {
+		return err
+	}
+	cfg, ok := c.(*config.FullNode)
+	if !ok {
+		return xerrors.Errorf("invalid config for repo, got: %T", c)
+	}
+
+	var manifest *snapshotter.Manifest
+	if snapshot {
+		manifest, err = loadSnapshotManifest(ctx, cfg, fname, manifestLoc, allowUnsigned)
+		if err != nil {
+			return err
+		}
+	}
+
+	// local snapshots are checked against their manifest before anything is
+	// imported, remote ones as they're streamed, before their head is accepted
+	var local bool
+	if manifest != nil {
+		if _, ok := rd.(*os.File); ok {
+			local = true
+			size, sum, err := hashFile(fname)
+			if err != nil {
+				return xerrors.Errorf("hashing snapshot: %w", err)
+			}
+			if err := manifest.VerifyFile(size, sum); err != nil {
+				return xerrors.Errorf("snapshot doesn't match its manifest: %w", err)
+			}
+			log.Infof("snapshot file matches its manifest")
+		}
+	}
+
+	// hash the snapshot as it's read, to check it against the manifest
+	hasher := sha256.New()
+	var size byteCounter
+	rd = io.TeeReader(rd, io.MultiWriter(hasher, &size))
+
 	bs, err := lr.Blockstore(ctx, repo.UniversalBlockstore)
 	if err != nil {
 		return xerrors.Errorf("failed to open blockstore: %w", err)
@@ -522,6 +604,21 @@ func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool)
 		return xerrors.Errorf("importing chain failed: %w", err)
 	}
 
+	if manifest != nil && local {
+		if ts.Key() != manifest.Head {
+			return xerrors.Errorf("snapshot doesn't match its manifest: head is %s, manifest says %s", ts.Key(), manifest.Head)
+		}
+	} else if manifest != nil {
+		// read any trailing bytes, so that the whole file is hashed
+		if _, err := io.Copy(io.Discard, bufr); err != nil {
+			return xerrors.Errorf("reading snapshot: %w", err)
+		}
+		if err := manifest.VerifySnapshot(int64(size), hex.EncodeToString(hasher.Sum(nil)), ts.Key()); err != nil {
+			return xerrors.Errorf("snapshot doesn't match its manifest: %w", err)
+		}
+		log.Infof("snapshot matches its manifest")
+	}
+
 	if err := cst.FlushValidationCache(ctx); err != nil {
 		return xerrors.Errorf("flushing validation cache failed: %w", err)
 	}
@@ -556,14 +653,6 @@ func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool)
 
 	// populate the message index if user has EnableMsgIndex enabled
 	//
-	c, err := lr.Config()
-	if err != nil {
-		return err
-	}
-	cfg, ok := c.(*config.FullNode)
-	if !ok {
-		return xerrors.Errorf("invalid config for repo, got: %T", c)
-	}
 	if cfg.Index.EnableMsgIndex {
 		log.Info("populating message index...")
 		if err := index.PopulateAfterSnapshot(ctx, path.Join(lr.Path(), "sqlite"), cst); err != nil {
@@ -574,3 +663,69 @@ func ImportChain(ctx context.Context, r repo.Repo, fname string, snapshot bool)
 
 	return nil
 }
+
+// loadSnapshotManifest reads and verifies the manifest of a snapshot. Once
+// publishers are trusted, it must be signed by one of them unless unsigned
+// snapshots are allowed. It returns nil when there's no manifest and that's
+// allowed.
+func loadSnapshotManifest(ctx context.Context, cfg *config.FullNode, fname, loc string, allowUnsigned bool) (*snapshotter.Manifest, error) {
+	if loc == "" {
+		loc = fname + snapshotter.ManifestSuffix
+	}
+
+	trusted, err := snapshotter.ParseTrustedPublishers(build.TrustedSnapshotPublishers, cfg.Snapshots.TrustedPublishers)
+	if err != nil {
+		return nil, err
+	}
+	requireSigned := len(trusted) > 0 && !allowUnsigned
+
+	m, err := snapshotter.ReadManifest(ctx, loc)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		if requireSigned {
+			return nil, xerrors.Errorf("snapshot manifest %s not found; use --allow-unsigned-snapshot to import the snapshot anyway", loc)
+		}
+		if len(trusted) == 0 {
+			log.Warnf("importing snapshot without a manifest, its origin and integrity can't be checked; configure Snapshots.TrustedPublishers to require signed snapshots")
+		} else {
+			log.Warnf("importing snapshot without a manifest, its origin and integrity can't be checked")
+		}
+		return nil, nil
+	case err != nil:
+		return nil, xerrors.Errorf("reading snapshot manifest: %w", err)
+	}
+
+	if err := m.VerifySignature(trusted); err != nil {
+		if requireSigned {
+			return nil, xerrors.Errorf("verifying snapshot manifest: %w; use --allow-unsigned-snapshot to import the snapshot anyway", err)
+		}
+		log.Warnf("importing snapshot with an unverified manifest, only its integrity is checked: %s", err)
+		return m, nil
+	}
+
+	log.Infof("snapshot manifest signed by trusted publisher %s", m.Publisher)
+	return m, nil
+}
+
+// hashFile returns the size and hex-encoded sha256 checksum of a file.
+func hashFile(fname string) (int64, string, error) {
+	f, err := os.Open(fname)
+	if err != nil {
+		return 0, "", err
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", err
+	}
+	return n, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+type byteCounter int64
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	*c += byteCounter(len(p))
+	return len(p), nil
+}
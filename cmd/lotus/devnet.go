@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/multiformats/go-multiaddr"
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/gen"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/genesis"
+	"github.com/filecoin-project/lotus/node"
+	"github.com/filecoin-project/lotus/node/devnet"
+)
+
+var devnetCmd = &cli.Command{
+	Name:  "devnet",
+	Usage: "Run a local development network in-process",
+	Description: `Starts a full node and a miner with mock proofs in a single process, with the
+Eth JSON-RPC API enabled. The chain lives in memory and is discarded on exit.
+
+The genesis has pre-funded Ethereum accounts derived from a mnemonic, and the
+given contracts deployed at deterministic addresses (see lotus-seed genesis
+new-devnet).
+
+By default blocks are only mined on request, with the Filecoin.MineOne and
+Filecoin.AdvanceEpochs JSON-RPC methods:
+
+  curl -X POST -H "Content-Type: application/json" \
+    --data '{"jsonrpc":"2.0","method":"Filecoin.MineOne","params":[],"id":1}' \
+    http://127.0.0.1:1234/rpc/v1
+
+Use --automine to mine a block whenever a message is submitted, and
+--block-time to mine at a fixed interval.`,
+	Flags: []cli.Flag{
+		&cli.StringFlag{
+			Name:  "api",
+			Usage: "port of the JSON-RPC API",
+			Value: "1234",
+		},
+		&cli.BoolFlag{
+			Name:  "automine",
+			Usage: "mine a block as soon as a message enters the mpool",
+		},
+		&cli.DurationFlag{
+			Name:  "block-time",
+			Usage: "mine a block at this interval, 0 to only mine on request",
+		},
+		&cli.StringFlag{
+			Name:  "preset",
+			Usage: "network version and upgrade timing preset, see lotus-seed genesis new-devnet --list-presets",
+			Value: "latest",
+		},
+		&cli.StringFlag{
+			Name:  "mnemonic",
+			Usage: "BIP-39 mnemonic from which the Ethereum accounts are derived",
+			Value: gen.DefaultDevnetMnemonic,
+		},
+		&cli.StringFlag{
+			Name:  "passphrase",
+			Usage: "BIP-39 passphrase of the mnemonic",
+		},
+		&cli.IntFlag{
+			Name:  "accounts",
+			Usage: "number of pre-funded Ethereum accounts",
+			Value: 10,
+		},
+		&cli.StringFlag{
+			Name:  "balance",
+			Usage: "balance of each Ethereum account",
+			Value: "10000 FIL",
+		},
+		&cli.StringSliceFlag{
+			Name:  "contract",
+			Usage: "file with the initcode of a contract to deploy, can be repeated",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		preset, err := gen.DevnetPresetByName(cctx.String("preset"))
+		if err != nil {
+			return err
+		}
+
+		balance, err := types.ParseFIL(cctx.String("balance"))
+		if err != nil {
+			return xerrors.Errorf("parsing balance: %w", err)
+		}
+
+		var contracts []genesis.Contract
+		for _, f := range cctx.StringSlice("contract") {
+			c, err := gen.ReadDevnetContract(f)
+			if err != nil {
+				return xerrors.Errorf("reading contract %s: %w", f, err)
+			}
+			contracts = append(contracts, c)
+		}
+
+		ctx, cancel := context.WithCancel(cctx.Context)
+		defer cancel()
+
+		log.Info("starting devnet")
+		d, err := devnet.New(ctx, devnet.Options{
+			Genesis: gen.DevnetOptions{
+				NetworkName: "devnet",
+				Preset:      preset,
+				Mnemonic:    cctx.String("mnemonic"),
+				Passphrase:  cctx.String("passphrase"),
+				Accounts:    cctx.Int("accounts"),
+				Balance:     abi.TokenAmount(balance),
+				Contracts:   contracts,
+			},
+		})
+		if err != nil {
+			return xerrors.Errorf("starting devnet: %w", err)
+		}
+
+		h, err := node.FullNodeHandler(d.FullNode, false)
+		if err != nil {
+			return xerrors.Errorf("failed to instantiate rpc handler: %w", err)
+		}
+
+		endpoint, err := multiaddr.NewMultiaddr("/ip4/127.0.0.1/tcp/" + cctx.String("api"))
+		if err != nil {
+			return err
+		}
+		rpcStopper, err := node.ServeRPC(h, "lotus-devnet", endpoint)
+		if err != nil {
+			return fmt.Errorf("failed to start json-rpc endpoint: %s", err)
+		}
+
+		fmt.Printf("Devnet running, miner %s\n", d.MinerAddr)
+		fmt.Printf("JSON-RPC: http://127.0.0.1:%s/rpc/v1 (chain id %d)\n", cctx.String("api"), build.Eip155ChainId)
+		fmt.Printf("Accounts (%s each):\n", types.FIL(balance))
+		for i, a := range d.Accounts {
+			fmt.Printf("  %d: %s (%s) private key 0x%s\n", i, a.EthAddress, a.Key.Address, hex.EncodeToString(a.Key.PrivateKey))
+		}
+		if len(d.Contracts) > 0 {
+			fmt.Println("Contracts:")
+			for i, c := range d.Contracts {
+				fmt.Printf("  %d: %s %s\n", i, c.EthAddress, c.Name)
+			}
+		}
+		var upgrades []network.Version
+		for nv, h := range preset.Upgrades {
+			if h >= 0 {
+				upgrades = append(upgrades, nv)
+			}
+		}
+		sort.Slice(upgrades, func(i, j int) bool { return upgrades[i] < upgrades[j] })
+		for _, nv := range upgrades {
+			fmt.Printf("Upgrade to network version %d at epoch %d\n", nv, preset.Upgrades[nv])
+		}
+
+		shutdownChan := make(chan struct{})
+		mining := make(chan error, 1)
+		go func() {
+			err := d.Run(ctx, cctx.Duration("block-time"), cctx.Bool("automine"))
+			mining <- err
+			if err != nil {
+				log.Errorf("mining failed: %s", err)
+				close(shutdownChan)
+			}
+		}()
+
+		finishCh := node.MonitorShutdown(shutdownChan,
+			node.ShutdownHandler{Component: "rpc server", StopFunc: rpcStopper},
+			node.ShutdownHandler{Component: "mining", StopFunc: func(context.Context) error {
+				cancel()
+				return <-mining
+			}},
+			node.ShutdownHandler{Component: "devnet", StopFunc: d.Stop},
+		)
+		<-finishCh
+
+		return nil
+	},
+}
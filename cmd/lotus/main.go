@@ -30,8 +30,11 @@ func main() {
 
 	local := []*cli.Command{
 		DaemonCmd,
+		devnetCmd,
 		backupCmd,
 		configCmd,
+		repoCmd,
+		maintenanceCmd,
 	}
 	if AdvanceBlockCmd != nil {
 		local = append(local, AdvanceBlockCmd)
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+
+	"github.com/filecoin-project/lotus/api"
+	lcli "github.com/filecoin-project/lotus/cli"
+)
+
+var maintenanceCmd = &cli.Command{
+	Name:  "maintenance",
+	Usage: "Manage the maintenance windows of expensive background activities",
+	Subcommands: []*cli.Command{
+		maintenanceStatusCmd,
+		maintenanceOpenCmd,
+		maintenanceCloseCmd,
+		maintenanceClearCmd,
+	},
+}
+
+var maintenanceStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Show the maintenance windows and the tasks run in them",
+	Action: func(cctx *cli.Context) error {
+		napi, closer, err := lcli.GetFullNodeAPIV1(cctx)
+		if err != nil {
+			return err
+		}
+		defer closer()
+
+		st, err := napi.NodeMaintenanceStatus(lcli.ReqContext(cctx))
+		if err != nil {
+			return err
+		}
+
+		afmt := lcli.NewAppFmt(cctx.App)
+		state := "closed"
+		if st.Open {
+			state = "open"
+		}
+		if st.Until.IsZero() {
+			afmt.Printf("Maintenance: %s\n", state)
+		} else {
+			afmt.Printf("Maintenance: %s until %s\n", state, st.Until.Format(time.RFC3339))
+		}
+		if st.Override != nil {
+			afmt.Printf("Override: open=%t until %s\n", st.Override.Open, st.Override.Until.Format(time.RFC3339))
+		}
+
+		afmt.Println("\nWindows:")
+		if len(st.Windows) == 0 {
+			afmt.Println("  none, activities run whenever they are due")
+		}
+		for _, w := range st.Windows {
+			afmt.Printf("  %q for %s, next at %s\n", w.Schedule, w.Duration, w.NextStart.Format(time.RFC3339))
+		}
+
+		afmt.Println("\nTasks:")
+		tw := tabwriter.NewWriter(cctx.App.Writer, 2, 4, 2, ' ', 0)
+		_, _ = fmt.Fprintln(tw, "  Name\tRuns\tLast Start\tTook\tResult")
+		for _, t := range st.Tasks {
+			result := "ok"
+			switch {
+			case t.Running:
+				result = "running"
+			case t.LastError != "":
+				result = t.LastError
+			case t.Runs == 0:
+				result = "-"
+			}
+			start := "-"
+			if !t.LastStart.IsZero() {
+				start = t.LastStart.Format(time.RFC3339)
+			}
+			_, _ = fmt.Fprintf(tw, "  %s\t%d\t%s\t%s\t%s\n", t.Name, t.Runs, start, t.LastDuration.Round(time.Millisecond), result)
+		}
+		return tw.Flush()
+	},
+}
+
+var maintenanceOpenCmd = &cli.Command{
+	Name:  "open",
+	Usage: "Allow expensive background activities for a while, regardless of the windows",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "for",
+			Usage: "how long to keep the windows open",
+			Value: time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		return setMaintenanceOverride(cctx, &api.MaintenanceOverride{Open: true, Until: time.Now().Add(cctx.Duration("for"))})
+	},
+}
+
+var maintenanceCloseCmd = &cli.Command{
+	Name:  "close",
+	Usage: "Defer expensive background activities for a while, regardless of the windows",
+	Flags: []cli.Flag{
+		&cli.DurationFlag{
+			Name:  "for",
+			Usage: "how long to keep the windows closed",
+			Value: time.Hour,
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		return setMaintenanceOverride(cctx, &api.MaintenanceOverride{Open: false, Until: time.Now().Add(cctx.Duration("for"))})
+	},
+}
+
+var maintenanceClearCmd = &cli.Command{
+	Name:  "clear",
+	Usage: "Clear the override, restoring the configured windows",
+	Action: func(cctx *cli.Context) error {
+		return setMaintenanceOverride(cctx, nil)
+	},
+}
+
+func setMaintenanceOverride(cctx *cli.Context, o *api.MaintenanceOverride) error {
+	napi, closer, err := lcli.GetFullNodeAPIV1(cctx)
+	if err != nil {
+		return err
+	}
+	defer closer()
+
+	return napi.NodeMaintenanceOverride(lcli.ReqContext(cctx), o)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v2"
+	"golang.org/x/xerrors"
+
+	lcli "github.com/filecoin-project/lotus/cli"
+	"github.com/filecoin-project/lotus/node/repo"
+	"github.com/filecoin-project/lotus/node/repo/migrate"
+)
+
+var repoCmd = &cli.Command{
+	Name:  "repo",
+	Usage: "Manage the node repo",
+	Subcommands: []*cli.Command{
+		repoStatusCmd,
+		repoMigrateCmd,
+		repoRestoreCmd,
+		repoLockStatusCmd,
+		repoUnlockCmd,
+	},
+}
+
+var repoStatusCmd = &cli.Command{
+	Name:  "status",
+	Usage: "Print schema versions of the repo stores",
+	Action: func(cctx *cli.Context) error {
+		return withLockedRepo(cctx, func(mr migrate.Repo) error {
+			sts, err := migrate.Statuses(cctx.Context, mr)
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(os.Stdout, 2, 4, 2, ' ', 0)
+			_, _ = fmt.Fprintf(tw, "Store\tVersion\tLatest\tPath\n")
+			for _, st := range sts {
+				version := fmt.Sprint(st.Version)
+				if !st.Exists {
+					version = "-"
+				}
+				_, _ = fmt.Fprintf(tw, "%s\t%s\t%d\t%s\n", st.Store, version, st.Latest, st.Path)
+			}
+			return tw.Flush()
+		})
+	},
+}
+
+var repoMigrateCmd = &cli.Command{
+	Name:  "migrate",
+	Usage: "Apply pending schema migrations to the repo stores",
+	Description: `The node must be stopped. Stores are backed up before being migrated, and can
+   be reverted with 'lotus repo restore'. The daemon doesn't start while migrations are
+   pending.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "dry-run",
+			Usage: "check the migrations apply without committing them",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		return withLockedRepo(cctx, func(mr migrate.Repo) error {
+			res, err := migrate.Run(cctx.Context, mr, cctx.Bool("dry-run"))
+			if err != nil {
+				return err
+			}
+
+			if len(res.Applied) == 0 {
+				fmt.Println("Repo is up to date")
+				return nil
+			}
+
+			for _, m := range res.Applied {
+				fmt.Printf("%s v%d: %s\n", m.Store, m.Version, m.Description)
+			}
+			if res.DryRun {
+				fmt.Printf("%d migrations can be applied (dry run, nothing was changed)\n", len(res.Applied))
+				return nil
+			}
+			fmt.Printf("Applied %d migrations, stores backed up to %s\n", len(res.Applied), res.Backup)
+			return nil
+		})
+	},
+}
+
+var repoRestoreCmd = &cli.Command{
+	Name:      "restore",
+	Usage:     "Revert repo stores to their backup from a migration",
+	ArgsUsage: "[backup directory]",
+	Action: func(cctx *cli.Context) error {
+		if cctx.NArg() != 1 {
+			return lcli.IncorrectNumArgs(cctx)
+		}
+
+		return withLockedRepo(cctx, func(mr migrate.Repo) error {
+			if err := migrate.Restore(mr, cctx.Args().First()); err != nil {
+				return err
+			}
+			fmt.Println("Restored repo stores")
+			return nil
+		})
+	},
+}
+
+var repoLockStatusCmd = &cli.Command{
+	Name:  "lock-status",
+	Usage: "Identify the process holding the repo lock",
+	Action: func(cctx *cli.Context) error {
+		r, err := repo.NewFS(cctx.String("repo"))
+		if err != nil {
+			return err
+		}
+
+		st, err := r.LockStatus()
+		if err != nil {
+			return err
+		}
+		printLockStatus(st)
+		return nil
+	},
+}
+
+var repoUnlockCmd = &cli.Command{
+	Name:  "unlock",
+	Usage: "Break a stale repo lock",
+	Description: `Breaks the repo lock when it can't be locked anymore though no process holds it.
+   Locks held by a live process are never broken, even when the recorded holder
+   isn't running, as it may run in another container.`,
+	Flags: []cli.Flag{
+		&cli.BoolFlag{
+			Name:  "really-do-it",
+			Usage: "confirm breaking the lock",
+		},
+	},
+	Action: func(cctx *cli.Context) error {
+		r, err := repo.NewFS(cctx.String("repo"))
+		if err != nil {
+			return err
+		}
+
+		st, err := r.LockStatus()
+		if err != nil {
+			return err
+		}
+		printLockStatus(st)
+
+		switch {
+		case !st.Locked:
+			return nil
+		case !st.Stale:
+			return xerrors.Errorf("the lock isn't stale, stop its holder")
+		case !cctx.Bool("really-do-it"):
+			fmt.Println("Pass --really-do-it to break the lock")
+			return nil
+		}
+
+		if _, err := r.BreakLock(); err != nil {
+			return err
+		}
+		fmt.Println("Lock broken")
+		return nil
+	},
+}
+
+func printLockStatus(st repo.LockStatus) {
+	fmt.Printf("Lock: %s\n", st.Path)
+	fmt.Printf("Status: %s\n", st.Reason)
+	if st.Owner != nil {
+		fmt.Printf("Holder: pid %d on %s (%s repo) since %s\n", st.Owner.PID, st.Owner.Hostname, st.Owner.RepoType, st.Owner.Since.Format(time.RFC3339))
+	}
+	if st.Locked {
+		fmt.Printf("Stale: %t\n", st.Stale)
+	}
+}
+
+func withLockedRepo(cctx *cli.Context, cb func(migrate.Repo) error) error {
+	r, err := repo.NewFS(cctx.String("repo"))
+	if err != nil {
+		return err
+	}
+
+	ok, err := r.Exists()
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return xerrors.Errorf("repo not initialized")
+	}
+
+	lr, err := r.Lock(repo.FullNode)
+	if err != nil {
+		return xerrors.Errorf("locking repo: %w", repo.ExplainLockError(r, err))
+	}
+	defer lr.Close() // nolint:errcheck
+
+	mr, err := migrate.ForRepo(lr)
+	if err != nil {
+		return err
+	}
+	return cb(mr)
+}
+
+// checkRepoMigrations fails when migrations are pending on the repo stores, they're only
+// applied by 'lotus repo migrate' so operators can back up the repo and dry-run them first.
+func checkRepoMigrations(ctx context.Context, r repo.Repo) error {
+	lr, err := r.Lock(repo.FullNode)
+	if err != nil {
+		return repo.ExplainLockError(r, err)
+	}
+	defer lr.Close() // nolint:errcheck
+
+	mr, err := migrate.ForRepo(lr)
+	if err != nil {
+		return err
+	}
+
+	pending, err := migrate.Pending(ctx, mr)
+	if err != nil {
+		return err
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+	for _, m := range pending {
+		log.Warnw("pending repo migration", "store", m.Store, "version", m.Version, "description", m.Description)
+	}
+	return xerrors.Errorf("%d migrations pending on the repo stores, apply them with 'lotus repo migrate' (preview with '--dry-run') before starting the daemon", len(pending))
+}
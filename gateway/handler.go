@@ -40,13 +40,13 @@ func Handler(gwapi lapi.Gateway, api lapi.FullNode, rateLimit int64, connPerMinu
 
 		lapi.CreateEthRPCAliases(rpcServer)
 
-		m.Handle(path, rpcServer)
+		m.Handle(path, lapi.EthErrorsHandler(rpcServer))
 	}
 
-	ma := proxy.MetricedGatewayAPI(gwapi)
+	ma := lapi.StructuredErrorsGatewayAPI(proxy.MetricedGatewayAPI(gwapi))
 
 	serveRpc("/rpc/v1", ma)
-	serveRpc("/rpc/v0", lapi.Wrap(new(v1api.FullNodeStruct), new(v0api.WrapperV1Full), ma))
+	serveRpc("/rpc/v0", v0api.StructuredErrorsFullAPI(lapi.Wrap(new(v1api.FullNodeStruct), new(v0api.WrapperV1Full), ma).(v0api.FullNode)))
 
 	registry := promclient.DefaultRegisterer.(*promclient.Registry)
 	exporter, err := prometheus.NewExporter(prometheus.Options{
@@ -90,7 +90,11 @@ func (h RateLimiterHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	r = r.WithContext(context.WithValue(r.Context(), perConnLimiterKey, h.limiter))
 
 	// also add a filter tracker to the context
-	r = r.WithContext(context.WithValue(r.Context(), statefulCallTrackerKey, newStatefulCallTracker()))
+	ft := newStatefulCallTracker()
+	r = r.WithContext(context.WithValue(r.Context(), statefulCallTrackerKey, ft))
+	defer ft.close()
+
+	r = r.WithContext(context.WithValue(r.Context(), tenantKey, tenantFromRequest(r)))
 
 	h.handler.ServeHTTP(w, r)
 }
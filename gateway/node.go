@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-jsonrpc"
 	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/crypto"
 	"github.com/filecoin-project/go-state-types/dline"
 	"github.com/filecoin-project/go-state-types/network"
 
@@ -58,6 +59,7 @@ type TargetAPI interface {
 	ChainHasObj(context.Context, cid.Cid) (bool, error)
 	ChainHead(ctx context.Context) (*types.TipSet, error)
 	ChainNotify(context.Context) (<-chan []*api.HeadChange, error)
+	ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*api.HeadChange, error)
 	ChainGetPath(ctx context.Context, from, to types.TipSetKey) ([]*api.HeadChange, error)
 	ChainReadObj(context.Context, cid.Cid) ([]byte, error)
 	ChainPutObj(context.Context, blocks.Block) error
@@ -74,6 +76,8 @@ type TargetAPI interface {
 	StateDealProviderCollateralBounds(ctx context.Context, size abi.PaddedPieceSize, verified bool, tsk types.TipSetKey) (api.DealCollateralBounds, error)
 	StateDecodeParams(ctx context.Context, toAddr address.Address, method abi.MethodNum, params []byte, tsk types.TipSetKey) (interface{}, error)
 	StateGetActor(ctx context.Context, actor address.Address, ts types.TipSetKey) (*types.Actor, error)
+	StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*api.ActorProof, error)
+	StateGetRandomnessWithProof(ctx context.Context, source api.RandomnessSource, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (*api.RandomnessProof, error)
 	StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
 	StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
 	StateMarketBalance(ctx context.Context, addr address.Address, tsk types.TipSetKey) (api.MarketBalance, error)
@@ -109,6 +113,7 @@ type TargetAPI interface {
 	EthGetMessageCidByTransactionHash(ctx context.Context, txHash *ethtypes.EthHash) (*cid.Cid, error)
 	EthGetTransactionCount(ctx context.Context, sender ethtypes.EthAddress, blkOpt string) (ethtypes.EthUint64, error)
 	EthGetTransactionReceiptLimited(ctx context.Context, txHash ethtypes.EthHash, limit abi.ChainEpoch) (*api.EthTxReceipt, error)
+	EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*api.EthReceiptProof, error)
 	EthGetTransactionByBlockHashAndIndex(ctx context.Context, blkHash ethtypes.EthHash, txIndex ethtypes.EthUint64) (ethtypes.EthTx, error)
 	EthGetTransactionByBlockNumberAndIndex(ctx context.Context, blkNum ethtypes.EthUint64, txIndex ethtypes.EthUint64) (ethtypes.EthTx, error)
 	EthGetCode(ctx context.Context, address ethtypes.EthAddress, blkOpt string) (ethtypes.EthBytes, error)
@@ -124,7 +129,14 @@ type TargetAPI interface {
 	EthMaxPriorityFeePerGas(ctx context.Context) (ethtypes.EthBigInt, error)
 	EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthUint64, error)
 	EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam string) (ethtypes.EthBytes, error)
+	EthBatchStateRead(ctx context.Context, reads []api.EthStateRead, blkParam string) ([]api.EthStateReadResult, error)
+	EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []api.EthAccountOverride) (*api.EthBundleResult, error)
 	EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error)
+	EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*api.EthRawTxDecoding, error)
+	EthTxPoolContent(ctx context.Context) (api.EthTxPoolContent, error)
+	EthTxPoolInspect(ctx context.Context) (api.EthTxPoolInspect, error)
+	EthTxPoolStatus(ctx context.Context) (api.EthTxPoolStatus, error)
+	EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*api.EthGasProfile, error)
 	EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error)
 	EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
 	EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
@@ -133,6 +145,7 @@ type TargetAPI interface {
 	EthNewPendingTransactionFilter(ctx context.Context) (ethtypes.EthFilterID, error)
 	EthUninstallFilter(ctx context.Context, id ethtypes.EthFilterID) (bool, error)
 	EthSubscribe(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
+	EthSubscribeStorageSlots(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
 	EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error)
 	Web3ClientVersion(ctx context.Context) (string, error)
 }
@@ -147,6 +160,7 @@ type Node struct {
 	rateLimiter            *rate.Limiter
 	rateLimitTimeout       time.Duration
 	errLookback            error
+	usage                  *usageTracker
 }
 
 var (
@@ -158,7 +172,7 @@ var (
 )
 
 // NewNode creates a new gateway node.
-func NewNode(api TargetAPI, sHnd *EthSubHandler, lookbackCap time.Duration, stateWaitLookbackLimit abi.ChainEpoch, rateLimit int64, rateLimitTimeout time.Duration) *Node {
+func NewNode(target TargetAPI, sHnd *EthSubHandler, lookbackCap time.Duration, stateWaitLookbackLimit abi.ChainEpoch, rateLimit int64, rateLimitTimeout time.Duration) *Node {
 	var limit rate.Limit
 	if rateLimit == 0 {
 		limit = rate.Inf
@@ -166,13 +180,14 @@ func NewNode(api TargetAPI, sHnd *EthSubHandler, lookbackCap time.Duration, stat
 		limit = rate.Every(time.Second / time.Duration(rateLimit))
 	}
 	return &Node{
-		target:                 api,
+		target:                 target,
 		subHnd:                 sHnd,
 		lookbackCap:            lookbackCap,
 		stateWaitLookbackLimit: stateWaitLookbackLimit,
 		rateLimiter:            rate.NewLimiter(limit, stateRateLimitTokens),
 		rateLimitTimeout:       rateLimitTimeout,
-		errLookback:            fmt.Errorf("lookbacks of more than %s are disallowed", lookbackCap),
+		errLookback:            api.NewErrLimitExceeded("lookback_too_long", false, "lookbacks of more than %s are disallowed", lookbackCap),
+		usage:                  newUsageTracker(),
 	}
 }
 
@@ -219,19 +234,21 @@ func (gw *Node) checkTimestamp(at time.Time) error {
 }
 
 func (gw *Node) limit(ctx context.Context, tokens int) error {
+	gw.usage.tenant(ctx).addRequest()
+
 	ctx2, cancel := context.WithTimeout(ctx, gw.rateLimitTimeout)
 	defer cancel()
 	if perConnLimiter, ok := ctx2.Value(perConnLimiterKey).(*rate.Limiter); ok {
 		err := perConnLimiter.WaitN(ctx2, tokens)
 		if err != nil {
-			return fmt.Errorf("connection limited. %w", err)
+			return api.NewErrLimitExceeded("connection_rate_limited", true, "connection limited. %w", err)
 		}
 	}
 
 	err := gw.rateLimiter.WaitN(ctx2, tokens)
 	if err != nil {
 		stats.Record(ctx, metrics.RateLimitCount.M(1))
-		return fmt.Errorf("server busy. %w", err)
+		return api.NewErrLimitExceeded("rate_limited", true, "server busy. %w", err)
 	}
 	return nil
 }
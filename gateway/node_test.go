@@ -3,6 +3,7 @@ package gateway
 
 import (
 	"context"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -252,6 +253,18 @@ func TestGatewayVersion(t *testing.T) {
 	require.Equal(t, api.FullAPIVersion1, v.APIVersion)
 }
 
+func TestGatewayChainNotifyAtConfidence(t *testing.T) {
+	ctx := context.Background()
+	mock := &mockGatewayDepsAPI{}
+	a := NewNode(mock, nil, DefaultLookbackCap, DefaultStateWaitLookbackLimit, 0, time.Minute)
+
+	_, err := a.ChainNotifyAtConfidence(ctx, -1)
+	require.ErrorContains(t, err, "must not be negative")
+
+	_, err = a.ChainNotifyAtConfidence(ctx, math.MaxInt64)
+	require.ErrorContains(t, err, "lookbacks of more than")
+}
+
 func TestGatewayLimitTokensAvailable(t *testing.T) {
 	ctx := context.Background()
 	mock := &mockGatewayDepsAPI{}
@@ -103,6 +103,9 @@ func (gw *Node) checkBlkParam(ctx context.Context, blkParam string, lookback eth
 			break
 		}
 		num = ethtypes.EthUint64(head.Height()) - lookback
+	case "safe", "finalized":
+		height, _ := ethtypes.BlockTagHeight(blkParam, head.Height())
+		num = ethtypes.EthUint64(height)
 	default:
 		if err := num.UnmarshalJSON([]byte(`"` + blkParam + `"`)); err != nil {
 			return fmt.Errorf("cannot parse block number: %v", err)
@@ -208,6 +211,19 @@ func (gw *Node) EthGetTransactionReceiptLimited(ctx context.Context, txHash etht
 	return gw.target.EthGetTransactionReceiptLimited(ctx, txHash, limit)
 }
 
+func (gw *Node) EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*api.EthReceiptProof, error) {
+	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+		return nil, err
+	}
+	if checkpoint != "" {
+		if err := gw.checkBlkParam(ctx, checkpoint, 0); err != nil {
+			return nil, err
+		}
+	}
+
+	return gw.target.EthGetTransactionReceiptProof(ctx, txHash, checkpoint)
+}
+
 func (gw *Node) EthGetCode(ctx context.Context, address ethtypes.EthAddress, blkOpt string) (ethtypes.EthBytes, error) {
 	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
 		return nil, err
@@ -345,6 +361,36 @@ func (gw *Node) EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam strin
 	return gw.target.EthCall(ctx, tx, blkParam)
 }
 
+func (gw *Node) EthBatchStateRead(ctx context.Context, reads []api.EthStateRead, blkParam string) ([]api.EthStateReadResult, error) {
+	// every read in the batch is charged like a separate state read
+	for range reads {
+		if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gw.checkBlkParam(ctx, blkParam, 0); err != nil {
+		return nil, err
+	}
+
+	return gw.target.EthBatchStateRead(ctx, reads, blkParam)
+}
+
+func (gw *Node) EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []api.EthAccountOverride) (*api.EthBundleResult, error) {
+	// every transaction in the bundle is charged like a separate call
+	for range txs {
+		if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := gw.checkBlkParam(ctx, blkParam, 0); err != nil {
+		return nil, err
+	}
+
+	return gw.target.EthCallBundle(ctx, txs, blkParam, overrides)
+}
+
 func (gw *Node) EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error) {
 	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
 		return ethtypes.EthHash{}, err
@@ -353,6 +399,55 @@ func (gw *Node) EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthByt
 	return gw.target.EthSendRawTransaction(ctx, rawTx)
 }
 
+func (gw *Node) EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*api.EthRawTxDecoding, error) {
+	if err := gw.limit(ctx, basicRateLimitTokens); err != nil {
+		return nil, err
+	}
+
+	return gw.target.EthDecodeRawTransaction(ctx, rawTx)
+}
+
+func (gw *Node) EthTxPoolContent(ctx context.Context) (api.EthTxPoolContent, error) {
+	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+		return api.EthTxPoolContent{}, err
+	}
+
+	return gw.target.EthTxPoolContent(ctx)
+}
+
+func (gw *Node) EthTxPoolInspect(ctx context.Context) (api.EthTxPoolInspect, error) {
+	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+		return api.EthTxPoolInspect{}, err
+	}
+
+	return gw.target.EthTxPoolInspect(ctx)
+}
+
+func (gw *Node) EthTxPoolStatus(ctx context.Context) (api.EthTxPoolStatus, error) {
+	if err := gw.limit(ctx, basicRateLimitTokens); err != nil {
+		return api.EthTxPoolStatus{}, err
+	}
+
+	return gw.target.EthTxPoolStatus(ctx)
+}
+
+func (gw *Node) EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*api.EthGasProfile, error) {
+	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+		return nil, err
+	}
+
+	// replaying is expensive, only allow it for transactions within the lookback limit
+	rct, err := gw.target.EthGetTransactionReceiptLimited(ctx, txHash, gw.stateWaitLookbackLimit)
+	if err != nil {
+		return nil, err
+	}
+	if rct == nil {
+		return nil, xerrors.Errorf("transaction %s not found within the lookback limit", txHash)
+	}
+
+	return gw.target.EthTraceTransactionGasProfile(ctx, txHash)
+}
+
 func (gw *Node) EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error) {
 	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
 		return nil, err
@@ -374,7 +469,66 @@ func (gw *Node) EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec)
 		}
 	}
 
-	return gw.target.EthGetLogs(ctx, filter)
+	scanEpochs, err := gw.logsScanEpochs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tu := gw.usage.tenant(ctx)
+	if err := tu.checkLogs(scanEpochs); err != nil {
+		return nil, err
+	}
+
+	res, err := gw.target.EthGetLogs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	tu.addLogs(int64(len(res.Results)), scanEpochs)
+	return res, nil
+}
+
+// logsScanEpochs returns the number of epochs an eth_getLogs query scans.
+func (gw *Node) logsScanEpochs(ctx context.Context, filter *ethtypes.EthFilterSpec) (int64, error) {
+	if filter.BlockHash != nil {
+		return 1, nil
+	}
+
+	head, err := gw.target.ChainHead(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	height := func(blkParam *string) (abi.ChainEpoch, error) {
+		if blkParam == nil {
+			return head.Height(), nil
+		}
+		switch *blkParam {
+		case "earliest":
+			return 0, nil
+		case "pending", "latest", "safe", "finalized", "":
+			return head.Height(), nil
+		default:
+			num, err := ethtypes.EthUint64FromHex(*blkParam)
+			if err != nil {
+				return 0, fmt.Errorf("cannot parse block number: %v", err)
+			}
+			return abi.ChainEpoch(num), nil
+		}
+	}
+
+	from, err := height(filter.FromBlock)
+	if err != nil {
+		return 0, err
+	}
+	to, err := height(filter.ToBlock)
+	if err != nil {
+		return 0, err
+	}
+	if to < from {
+		return 0, nil
+	}
+	return int64(to-from) + 1, nil
 }
 
 /* FILTERS: Those are stateful.. figure out how to properly either bind them to users, or time out? */
@@ -393,7 +547,9 @@ func (gw *Node) EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID
 		return nil, nil
 	}
 
-	return gw.target.EthGetFilterChanges(ctx, id)
+	return gw.filterResults(ctx, func() (*ethtypes.EthFilterResult, error) {
+		return gw.target.EthGetFilterChanges(ctx, id)
+	})
 }
 
 func (gw *Node) EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error) {
@@ -410,7 +566,27 @@ func (gw *Node) EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID) (
 		return nil, nil
 	}
 
-	return gw.target.EthGetFilterLogs(ctx, id)
+	return gw.filterResults(ctx, func() (*ethtypes.EthFilterResult, error) {
+		return gw.target.EthGetFilterLogs(ctx, id)
+	})
+}
+
+// filterResults accounts the results returned by a filter to the tenant.
+func (gw *Node) filterResults(ctx context.Context, cb func() (*ethtypes.EthFilterResult, error)) (*ethtypes.EthFilterResult, error) {
+	tu := gw.usage.tenant(ctx)
+	if err := tu.checkLogs(0); err != nil {
+		return nil, err
+	}
+
+	res, err := cb()
+	if err != nil {
+		return nil, err
+	}
+
+	if res != nil {
+		tu.addLogs(int64(len(res.Results)), 0)
+	}
+	return res, nil
 }
 
 func (gw *Node) EthNewFilter(ctx context.Context, filter *ethtypes.EthFilterSpec) (ethtypes.EthFilterID, error) {
@@ -418,7 +594,7 @@ func (gw *Node) EthNewFilter(ctx context.Context, filter *ethtypes.EthFilterSpec
 		return ethtypes.EthFilterID{}, err
 	}
 
-	return addUserFilterLimited(ctx, func() (ethtypes.EthFilterID, error) {
+	return addUserFilterLimited(ctx, gw.usage.tenant(ctx), func() (ethtypes.EthFilterID, error) {
 		return gw.target.EthNewFilter(ctx, filter)
 	})
 }
@@ -428,7 +604,7 @@ func (gw *Node) EthNewBlockFilter(ctx context.Context) (ethtypes.EthFilterID, er
 		return ethtypes.EthFilterID{}, err
 	}
 
-	return addUserFilterLimited(ctx, func() (ethtypes.EthFilterID, error) {
+	return addUserFilterLimited(ctx, gw.usage.tenant(ctx), func() (ethtypes.EthFilterID, error) {
 		return gw.target.EthNewBlockFilter(ctx)
 	})
 }
@@ -438,7 +614,7 @@ func (gw *Node) EthNewPendingTransactionFilter(ctx context.Context) (ethtypes.Et
 		return ethtypes.EthFilterID{}, err
 	}
 
-	return addUserFilterLimited(ctx, func() (ethtypes.EthFilterID, error) {
+	return addUserFilterLimited(ctx, gw.usage.tenant(ctx), func() (ethtypes.EthFilterID, error) {
 		return gw.target.EthNewPendingTransactionFilter(ctx)
 	})
 }
@@ -463,22 +639,39 @@ func (gw *Node) EthUninstallFilter(ctx context.Context, id ethtypes.EthFilterID)
 	}
 
 	delete(ft.userFilters, id)
+	if ft.tenant != nil {
+		ft.tenant.release(1, 0)
+	}
 	return ok, nil
 }
 
 func (gw *Node) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
 	// validate params
-	_, err := jsonrpc.DecodeParams[ethtypes.EthSubscribeParams](p)
+	params, err := jsonrpc.DecodeParams[ethtypes.EthSubscribeParams](p)
+	if err != nil {
+		return ethtypes.EthSubscriptionID{}, xerrors.Errorf("decoding params: %w", err)
+	}
+
+	return gw.subscribe(ctx, p, params.EventType == "logs", gw.target.EthSubscribe)
+}
+
+func (gw *Node) EthSubscribeStorageSlots(ctx context.Context, p jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	// validate params
+	_, err := jsonrpc.DecodeParams[ethtypes.EthStorageSlotsParams](p)
 	if err != nil {
 		return ethtypes.EthSubscriptionID{}, xerrors.Errorf("decoding params: %w", err)
 	}
 
+	return gw.subscribe(ctx, p, false, gw.target.EthSubscribeStorageSlots)
+}
+
+func (gw *Node) subscribe(ctx context.Context, p jsonrpc.RawParams, logs bool, targetSubscribe func(context.Context, jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)) (ethtypes.EthSubscriptionID, error) {
 	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
 		return ethtypes.EthSubscriptionID{}, err
 	}
 
 	if gw.subHnd == nil {
-		return ethtypes.EthSubscriptionID{}, xerrors.New("subscription support not enabled")
+		return ethtypes.EthSubscriptionID{}, api.NewErrMethodNotSupported("subscriptions_disabled", "subscription support not enabled")
 	}
 
 	ethCb, ok := jsonrpc.ExtractReverseClient[api.EthSubscriberMethods](ctx)
@@ -491,11 +684,17 @@ func (gw *Node) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethtypes
 	defer ft.lk.Unlock()
 
 	if len(ft.userSubscriptions) >= EthMaxFiltersPerConn {
-		return ethtypes.EthSubscriptionID{}, fmt.Errorf("too many subscriptions")
+		return ethtypes.EthSubscriptionID{}, api.NewErrLimitExceeded("too_many_subscriptions", false, "too many subscriptions")
+	}
+
+	tu := gw.usage.tenant(ctx)
+	if err := tu.addSubscription(); err != nil {
+		return ethtypes.EthSubscriptionID{}, err
 	}
 
-	sub, err := gw.target.EthSubscribe(ctx, p)
+	sub, err := targetSubscribe(ctx, p)
 	if err != nil {
+		tu.release(0, 1)
 		return ethtypes.EthSubscriptionID{}, err
 	}
 
@@ -505,13 +704,16 @@ func (gw *Node) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethtypes
 			return err
 		}
 
+		tu.addSubscriptionMessage(logs)
 		return ethCb.EthSubscription(ctx, outParam)
 	})
 	if err != nil {
+		tu.release(0, 1)
 		return ethtypes.EthSubscriptionID{}, err
 	}
 
 	ft.userSubscriptions[sub] = time.Now()
+	ft.tenant = tu
 
 	return sub, err
 }
@@ -536,6 +738,9 @@ func (gw *Node) EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionI
 	}
 
 	delete(ft.userSubscriptions, id)
+	if ft.tenant != nil {
+		ft.tenant.release(0, 1)
+	}
 
 	if gw.subHnd != nil {
 		gw.subHnd.RemoveSub(id)
@@ -546,21 +751,27 @@ func (gw *Node) EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionI
 
 var EthMaxFiltersPerConn = 16 // todo make this configurable
 
-func addUserFilterLimited(ctx context.Context, cb func() (ethtypes.EthFilterID, error)) (ethtypes.EthFilterID, error) {
+func addUserFilterLimited(ctx context.Context, tu *tenantUsage, cb func() (ethtypes.EthFilterID, error)) (ethtypes.EthFilterID, error) {
 	ft := statefulCallFromContext(ctx)
 	ft.lk.Lock()
 	defer ft.lk.Unlock()
 
 	if len(ft.userFilters) >= EthMaxFiltersPerConn {
-		return ethtypes.EthFilterID{}, fmt.Errorf("too many filters")
+		return ethtypes.EthFilterID{}, api.NewErrLimitExceeded("too_many_filters", false, "too many filters")
+	}
+
+	if err := tu.addFilter(); err != nil {
+		return ethtypes.EthFilterID{}, err
 	}
 
 	id, err := cb()
 	if err != nil {
+		tu.release(1, 0)
 		return id, err
 	}
 
 	ft.userFilters[id] = time.Now()
+	ft.tenant = tu
 
 	return id, nil
 }
@@ -574,6 +785,9 @@ type statefulCallTracker struct {
 
 	userFilters       map[ethtypes.EthFilterID]time.Time
 	userSubscriptions map[ethtypes.EthSubscriptionID]time.Time
+
+	// tenant the filters and subscriptions are accounted to
+	tenant *tenantUsage
 }
 
 // called per request (ws connection)
@@ -583,3 +797,15 @@ func newStatefulCallTracker() *statefulCallTracker {
 		userSubscriptions: make(map[ethtypes.EthSubscriptionID]time.Time),
 	}
 }
+
+// close releases the filters and subscriptions of the connection from the
+// tenant usage once the connection is closed.
+func (ft *statefulCallTracker) close() {
+	ft.lk.Lock()
+	defer ft.lk.Unlock()
+
+	if ft.tenant != nil {
+		ft.tenant.release(len(ft.userFilters), len(ft.userSubscriptions))
+		ft.tenant = nil
+	}
+}
@@ -2,6 +2,7 @@ package gateway
 
 import (
 	"context"
+	"time"
 
 	blocks "github.com/ipfs/go-block-format"
 	"github.com/ipfs/go-cid"
@@ -33,6 +34,16 @@ func (gw *Node) StateReplay(ctx context.Context, tsk types.TipSetKey, c cid.Cid)
 	return gw.target.StateReplay(ctx, tsk, c)
 }
 
+func (gw *Node) StateGetRandomnessWithProof(ctx context.Context, source api.RandomnessSource, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (*api.RandomnessProof, error) {
+	if err := gw.limit(ctx, chainRateLimitTokens); err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipSetHeight(ctx, randEpoch, tsk); err != nil {
+		return nil, err
+	}
+	return gw.target.StateGetRandomnessWithProof(ctx, source, personalization, randEpoch, entropy, tsk)
+}
+
 func (gw *Node) GasEstimateGasPremium(ctx context.Context, nblocksincl uint64, sender address.Address, gaslimit int64, tsk types.TipSetKey) (types.BigInt, error) {
 	if err := gw.limit(ctx, chainRateLimitTokens); err != nil {
 		return types.BigInt{}, err
@@ -177,6 +188,19 @@ func (gw *Node) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange, erro
 	return gw.target.ChainNotify(ctx)
 }
 
+func (gw *Node) ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*api.HeadChange, error) {
+	if err := gw.limit(ctx, chainRateLimitTokens); err != nil {
+		return nil, err
+	}
+	if depth < 0 {
+		return nil, xerrors.Errorf("confidence depth must not be negative")
+	}
+	if depth > abi.ChainEpoch(gw.lookbackCap/(time.Duration(build.BlockDelaySecs)*time.Second)) {
+		return nil, gw.errLookback
+	}
+	return gw.target.ChainNotifyAtConfidence(ctx, depth)
+}
+
 func (gw *Node) ChainGetPath(ctx context.Context, from, to types.TipSetKey) ([]*api.HeadChange, error) {
 	if err := gw.limit(ctx, chainRateLimitTokens); err != nil {
 		return nil, err
@@ -205,7 +229,7 @@ func (gw *Node) ChainReadObj(ctx context.Context, c cid.Cid) ([]byte, error) {
 }
 
 func (gw *Node) ChainPutObj(context.Context, blocks.Block) error {
-	return xerrors.New("not supported")
+	return api.NewErrMethodNotSupported("not_supported", "not supported")
 }
 
 func (gw *Node) GasEstimateMessageGas(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec, tsk types.TipSetKey) (*types.Message, error) {
@@ -323,6 +347,16 @@ func (gw *Node) StateGetActor(ctx context.Context, actor address.Address, tsk ty
 	return gw.target.StateGetActor(ctx, actor, tsk)
 }
 
+func (gw *Node) StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*api.ActorProof, error) {
+	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
+		return nil, err
+	}
+	if err := gw.checkTipsetKey(ctx, tsk); err != nil {
+		return nil, err
+	}
+	return gw.target.StateGetProof(ctx, actor, tsk)
+}
+
 func (gw *Node) StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error) {
 	if err := gw.limit(ctx, stateRateLimitTokens); err != nil {
 		return nil, err
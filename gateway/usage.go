@@ -0,0 +1,312 @@
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// DefaultQuotaPeriod is the period over which the log volume and the eth_getLogs
+// scan cost of a tenant are accounted.
+const DefaultQuotaPeriod = time.Hour * 24
+
+var ErrQuotaExceeded = xerrors.New("tenant quota exceeded")
+
+type tenantKeyType string
+
+const tenantKey tenantKeyType = "tenant"
+
+// maxTrackedTenants bounds the number of tenants whose usage is tracked, the
+// requests of new tenants beyond it are accounted to overflowTenant.
+const maxTrackedTenants = 100_000
+
+const overflowTenant = "overflow"
+
+// TenantQuotas configures the quotas enforced for each tenant of the gateway.
+// Tenants are identified by the hex encoded sha256 hash of their API token,
+// only the tokens listed in Tenants are trusted. Requests with any other token,
+// or without one, are accounted to the remote IP of the client, as "ip:<address>".
+type TenantQuotas struct {
+	// Default applies to tenants without their own quota
+	Default api.GatewayQuota
+	Tenants map[string]api.GatewayQuota
+}
+
+func (q TenantQuotas) quota(tenant string) api.GatewayQuota {
+	if tq, ok := q.Tenants[tenant]; ok {
+		return tq
+	}
+	return q.Default
+}
+
+// requestTenant identifies the client of a request, it's resolved to a tenant
+// against the configured quotas.
+type requestTenant struct {
+	// hex encoded sha256 hash of the API token, if any
+	token string
+	ip    string
+}
+
+// tenantFromRequest returns the identity of the client of the request, from the
+// API token sent the same way as to the lotus API, and its remote address.
+func tenantFromRequest(r *http.Request) requestTenant {
+	var rt requestTenant
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if token == "" {
+		token = r.URL.Query().Get("token")
+	}
+	if token != "" {
+		h := sha256.Sum256([]byte(token))
+		rt.token = hex.EncodeToString(h[:])
+	}
+
+	rt.ip = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		rt.ip = host
+	}
+	return rt
+}
+
+func tenantFromContext(ctx context.Context) requestTenant {
+	rt, _ := ctx.Value(tenantKey).(requestTenant)
+	return rt
+}
+
+type usageTracker struct {
+	lk        sync.Mutex
+	quotas    TenantQuotas
+	period    time.Duration
+	tenants   map[string]*tenantUsage
+	lastSweep time.Time
+}
+
+func newUsageTracker() *usageTracker {
+	return &usageTracker{
+		period:  DefaultQuotaPeriod,
+		tenants: make(map[string]*tenantUsage),
+	}
+}
+
+func (u *usageTracker) setQuotas(quotas TenantQuotas, period time.Duration) {
+	u.lk.Lock()
+	defer u.lk.Unlock()
+
+	u.quotas = quotas
+	u.period = period
+	for tenant, tu := range u.tenants {
+		tu.lk.Lock()
+		tu.quota = quotas.quota(tenant)
+		tu.period = period
+		tu.lk.Unlock()
+	}
+}
+
+// resolve returns the tenant of a client, the token is only trusted if it's
+// one of a configured tenant, must be called with the lock held.
+func (u *usageTracker) resolve(rt requestTenant) string {
+	if _, ok := u.quotas.Tenants[rt.token]; ok && rt.token != "" {
+		return rt.token
+	}
+	if rt.ip == "" {
+		return ""
+	}
+	return "ip:" + rt.ip
+}
+
+func (u *usageTracker) tenant(ctx context.Context) *tenantUsage {
+	rt := tenantFromContext(ctx)
+	now := time.Now()
+
+	u.lk.Lock()
+	defer u.lk.Unlock()
+
+	tenant := u.resolve(rt)
+	tu, ok := u.tenants[tenant]
+	if !ok {
+		full := len(u.tenants) >= maxTrackedTenants
+		if since := now.Sub(u.lastSweep); since >= u.idleExpiry() || (full && since >= time.Minute) {
+			u.sweep(now)
+		}
+		if len(u.tenants) >= maxTrackedTenants {
+			tenant = overflowTenant
+			tu, ok = u.tenants[tenant]
+		}
+	}
+	if !ok {
+		tu = &tenantUsage{
+			tenant:      tenant,
+			quota:       u.quotas.quota(tenant),
+			period:      u.period,
+			periodStart: now,
+		}
+		u.tenants[tenant] = tu
+	}
+
+	tu.lk.Lock()
+	tu.lastSeen = now
+	tu.lk.Unlock()
+	return tu
+}
+
+// idleExpiry is how long a tenant without filters or subscriptions is tracked
+// after its last request. Its period counters would have been reset by then.
+func (u *usageTracker) idleExpiry() time.Duration {
+	if u.period <= 0 {
+		return DefaultQuotaPeriod
+	}
+	return u.period
+}
+
+// sweep stops tracking the idle tenants, must be called with the lock held.
+func (u *usageTracker) sweep(now time.Time) {
+	u.lastSweep = now
+	expiry := u.idleExpiry()
+	for tenant, tu := range u.tenants {
+		tu.lk.Lock()
+		idle := tu.filters == 0 && tu.subscriptions == 0 && now.Sub(tu.lastSeen) >= expiry
+		tu.lk.Unlock()
+		if idle {
+			delete(u.tenants, tenant)
+		}
+	}
+}
+
+type tenantUsage struct {
+	lk sync.Mutex
+
+	tenant string
+	quota  api.GatewayQuota
+	period time.Duration
+
+	filters       int
+	subscriptions int
+
+	lastSeen             time.Time
+	periodStart          time.Time
+	requests             int64
+	logsReturned         int64
+	logsScanEpochs       int64
+	subscriptionMessages int64
+}
+
+// rollPeriod resets the per-period counters once the period is over, must be
+// called with the lock held.
+func (tu *tenantUsage) rollPeriod() {
+	if tu.period <= 0 || time.Since(tu.periodStart) < tu.period {
+		return
+	}
+	tu.periodStart = time.Now()
+	tu.requests = 0
+	tu.logsReturned = 0
+	tu.logsScanEpochs = 0
+	tu.subscriptionMessages = 0
+}
+
+func (tu *tenantUsage) addRequest() {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.rollPeriod()
+	tu.requests++
+}
+
+func (tu *tenantUsage) addFilter() error {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	if tu.quota.MaxFilters > 0 && tu.filters >= tu.quota.MaxFilters {
+		return api.NewErrLimitExceeded("quota_exceeded", false, "%w: too many installed filters (maximum: %d)", ErrQuotaExceeded, tu.quota.MaxFilters)
+	}
+	tu.filters++
+	return nil
+}
+
+func (tu *tenantUsage) addSubscription() error {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	if tu.quota.MaxSubscriptions > 0 && tu.subscriptions >= tu.quota.MaxSubscriptions {
+		return api.NewErrLimitExceeded("quota_exceeded", false, "%w: too many subscriptions (maximum: %d)", ErrQuotaExceeded, tu.quota.MaxSubscriptions)
+	}
+	tu.subscriptions++
+	return nil
+}
+
+func (tu *tenantUsage) release(filters, subscriptions int) {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.filters -= filters
+	tu.subscriptions -= subscriptions
+}
+
+// checkLogs returns an error if the tenant can't be returned more logs, or
+// can't run an eth_getLogs query scanning the given number of epochs.
+func (tu *tenantUsage) checkLogs(scanEpochs int64) error {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.rollPeriod()
+	if tu.quota.MaxLogs > 0 && tu.logsReturned >= tu.quota.MaxLogs {
+		return api.NewErrLimitExceeded("quota_exceeded", true, "%w: returned log volume (maximum: %d per %s)", ErrQuotaExceeded, tu.quota.MaxLogs, tu.period)
+	}
+	if tu.quota.MaxLogsScanEpochs > 0 && tu.logsScanEpochs+scanEpochs > tu.quota.MaxLogsScanEpochs {
+		return api.NewErrLimitExceeded("quota_exceeded", true, "%w: eth_getLogs scan cost (maximum: %d epochs per %s)", ErrQuotaExceeded, tu.quota.MaxLogsScanEpochs, tu.period)
+	}
+	return nil
+}
+
+func (tu *tenantUsage) addLogs(returned, scanEpochs int64) {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.rollPeriod()
+	tu.logsReturned += returned
+	tu.logsScanEpochs += scanEpochs
+}
+
+func (tu *tenantUsage) addSubscriptionMessage(log bool) {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.rollPeriod()
+	tu.subscriptionMessages++
+	if log {
+		tu.logsReturned++
+	}
+}
+
+func (tu *tenantUsage) usage() *api.GatewayUsage {
+	tu.lk.Lock()
+	defer tu.lk.Unlock()
+	tu.rollPeriod()
+	return &api.GatewayUsage{
+		Tenant:               tu.tenant,
+		Filters:              tu.filters,
+		Subscriptions:        tu.subscriptions,
+		PeriodStart:          tu.periodStart,
+		Period:               tu.period,
+		Requests:             tu.requests,
+		LogsReturned:         tu.logsReturned,
+		LogsScanEpochs:       tu.logsScanEpochs,
+		SubscriptionMessages: tu.subscriptionMessages,
+		Quota:                tu.quota,
+	}
+}
+
+// SetTenantQuotas sets the quotas enforced for the tenants of the gateway, and
+// the period over which the log volume and scan cost are accounted.
+func (gw *Node) SetTenantQuotas(quotas TenantQuotas, period time.Duration) {
+	gw.usage.setQuotas(quotas, period)
+}
+
+func (gw *Node) GatewayUsage(ctx context.Context) (*api.GatewayUsage, error) {
+	if err := gw.limit(ctx, basicRateLimitTokens); err != nil {
+		return nil, err
+	}
+
+	return gw.usage.tenant(ctx).usage(), nil
+}
@@ -0,0 +1,133 @@
+// stm: #unit
+package gateway
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+type mockUsageDepsAPI struct {
+	mockGatewayDepsAPI
+
+	logs int
+}
+
+func (m *mockUsageDepsAPI) EthNewFilter(ctx context.Context, filter *ethtypes.EthFilterSpec) (ethtypes.EthFilterID, error) {
+	return ethtypes.EthFilterID(ethtypes.EthHash{byte(len(m.tipsets))}), nil
+}
+
+func (m *mockUsageDepsAPI) EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error) {
+	return &ethtypes.EthFilterResult{Results: make([]interface{}, m.logs)}, nil
+}
+
+func TestTenantFromRequest(t *testing.T) {
+	h := sha256.Sum256([]byte("secret"))
+	tenant := hex.EncodeToString(h[:])
+
+	r := httptest.NewRequest("GET", "/rpc/v1", nil)
+	r.RemoteAddr = "10.0.0.1:1234"
+	require.Equal(t, requestTenant{ip: "10.0.0.1"}, tenantFromRequest(r))
+
+	r.Header.Set("Authorization", "Bearer secret")
+	require.Equal(t, requestTenant{token: tenant, ip: "10.0.0.1"}, tenantFromRequest(r))
+
+	r = httptest.NewRequest("GET", "/rpc/v1?token=secret", nil)
+	require.Equal(t, tenant, tenantFromRequest(r).token)
+
+	u := newUsageTracker()
+	u.setQuotas(TenantQuotas{Tenants: map[string]api.GatewayQuota{tenant: {}}}, time.Hour)
+
+	// only the tokens of configured tenants are trusted
+	require.Equal(t, tenant, u.resolve(requestTenant{token: tenant, ip: "10.0.0.1"}))
+	require.Equal(t, "ip:10.0.0.1", u.resolve(requestTenant{token: "unknown", ip: "10.0.0.1"}))
+	require.Equal(t, "ip:10.0.0.1", u.resolve(requestTenant{ip: "10.0.0.1"}))
+}
+
+func TestUsageTrackerSweep(t *testing.T) {
+	u := newUsageTracker()
+	u.setQuotas(TenantQuotas{}, time.Hour)
+
+	ctx := func(ip string) context.Context {
+		return context.WithValue(context.Background(), tenantKey, requestTenant{ip: ip})
+	}
+
+	idle := u.tenant(ctx("10.0.0.1"))
+	active := u.tenant(ctx("10.0.0.2"))
+	require.NoError(t, active.addFilter())
+
+	idle.lastSeen = time.Now().Add(-2 * time.Hour)
+	active.lastSeen = idle.lastSeen
+	u.sweep(time.Now())
+
+	// idle tenants are dropped, the ones with filters or subscriptions are kept
+	require.Len(t, u.tenants, 1)
+	require.Contains(t, u.tenants, "ip:10.0.0.2")
+}
+
+func TestGatewayTenantQuotas(t *testing.T) {
+	mock := &mockUsageDepsAPI{logs: 3}
+	mock.createTipSets(5, 0)
+
+	a := NewNode(mock, nil, DefaultLookbackCap, DefaultStateWaitLookbackLimit, 0, time.Minute)
+	a.SetTenantQuotas(TenantQuotas{
+		Default: api.GatewayQuota{MaxFilters: 2},
+		Tenants: map[string]api.GatewayQuota{
+			"limited": {MaxFilters: 1, MaxLogs: 2, MaxLogsScanEpochs: 10},
+		},
+	}, time.Hour)
+
+	conn := func(tenant string) (context.Context, *statefulCallTracker) {
+		ft := newStatefulCallTracker()
+		ctx := context.WithValue(context.Background(), statefulCallTrackerKey, ft)
+		return context.WithValue(ctx, tenantKey, requestTenant{token: tenant, ip: "10.0.0.1"}), ft
+	}
+
+	ctx, ft := conn("limited")
+	_, err := a.EthNewFilter(ctx, &ethtypes.EthFilterSpec{})
+	require.NoError(t, err)
+
+	// the quota applies across connections of the tenant
+	ctx2, _ := conn("limited")
+	_, err = a.EthNewFilter(ctx2, &ethtypes.EthFilterSpec{})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+	_, code, ok := api.AsStructuredError(err)
+	require.True(t, ok)
+	require.EqualValues(t, api.ELimitExceeded, code)
+
+	// other tenants get the default quota
+	other, _ := conn("unknown")
+	_, err = a.EthNewFilter(other, &ethtypes.EthFilterSpec{})
+	require.NoError(t, err)
+
+	// closing the connection releases its filters
+	ft.close()
+	_, err = a.EthNewFilter(ctx2, &ethtypes.EthFilterSpec{})
+	require.NoError(t, err)
+
+	from := "0x2"
+	res, err := a.EthGetLogs(ctx2, &ethtypes.EthFilterSpec{FromBlock: &from})
+	require.NoError(t, err)
+	require.Len(t, res.Results, 3)
+
+	// over the returned logs quota
+	_, err = a.EthGetLogs(ctx2, &ethtypes.EthFilterSpec{FromBlock: &from})
+	require.ErrorIs(t, err, ErrQuotaExceeded)
+
+	usage, err := a.GatewayUsage(ctx2)
+	require.NoError(t, err)
+	require.Equal(t, "limited", usage.Tenant)
+	require.Equal(t, 1, usage.Filters)
+	require.Equal(t, int64(3), usage.LogsReturned)
+	require.Equal(t, int64(4), usage.LogsScanEpochs)
+	require.Equal(t, int64(6), usage.Requests)
+	require.Equal(t, 1, usage.Quota.MaxFilters)
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/chain/exchange"
 	"github.com/filecoin-project/lotus/chain/market"
+	"github.com/filecoin-project/lotus/chain/statesync"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/cmd/lotus-shed/shedgen"
 	"github.com/filecoin-project/lotus/node/hello"
@@ -66,6 +67,7 @@ func main() {
 		api.PieceDealInfo{},
 		api.SectorPiece{},
 		api.DealSchedule{},
+		api.SectorRecord{},
 	)
 	if err != nil {
 		fmt.Println(err)
@@ -100,6 +102,15 @@ func main() {
 		os.Exit(1)
 	}
 
+	err = gen.WriteTupleEncodersToFile("./chain/statesync/cbor_gen.go", "statesync",
+		statesync.Request{},
+		statesync.Response{},
+	)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
 	err = gen.WriteMapEncodersToFile("./storage/sealer/storiface/cbor_gen.go", "storiface",
 		storiface.CallID{},
 		storiface.SecDataHttpHeader{},
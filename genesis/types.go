@@ -12,13 +12,15 @@ import (
 	"github.com/filecoin-project/go-state-types/network"
 
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 )
 
 type ActorType string
 
 const (
-	TAccount  ActorType = "account"
-	TMultisig ActorType = "multisig"
+	TAccount    ActorType = "account"
+	TMultisig   ActorType = "multisig"
+	TEthAccount ActorType = "ethaccount"
 )
 
 type PreSeal struct {
@@ -56,6 +58,18 @@ func (am *AccountMeta) ActorMeta() json.RawMessage {
 	return out
 }
 
+type EthAccountMeta struct {
+	Address ethtypes.EthAddress
+}
+
+func (em *EthAccountMeta) ActorMeta() json.RawMessage {
+	out, err := json.Marshal(em)
+	if err != nil {
+		panic(err)
+	}
+	return out
+}
+
 type MultisigMeta struct {
 	Signers         []address.Address
 	Threshold       int
@@ -78,10 +92,19 @@ type Actor struct {
 	Meta json.RawMessage
 }
 
+// Contract is deployed at genesis by running its initcode. Contracts are
+// created by the system actor, the contract at index i of the template is
+// deployed at the CREATE address of the system actor with nonce i.
+type Contract struct {
+	Name     string `json:",omitempty"`
+	Initcode ethtypes.EthBytes
+}
+
 type Template struct {
 	NetworkVersion network.Version
 	Accounts       []Actor
 	Miners         []Miner
+	Contracts      []Contract `json:",omitempty"`
 
 	NetworkName string
 	Timestamp   uint64 `json:",omitempty"`
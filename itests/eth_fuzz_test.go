@@ -0,0 +1,59 @@
+// stm: #integration
+package itests
+
+import (
+	"context"
+	"os"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/itests/kit"
+)
+
+const (
+	// envEthFuzzSeed sets the seed of TestEthAPIFuzz, to reproduce a failing
+	// run from the seed it logged.
+	envEthFuzzSeed = "LOTUS_ETH_FUZZ_SEED"
+	// envEthFuzzIterations sets the number of requests sent to each method.
+	envEthFuzzIterations = "LOTUS_ETH_FUZZ_ITERATIONS"
+)
+
+func TestEthAPIFuzz(t *testing.T) {
+	kit.Expensive(t)
+	kit.QuietAllLogsExcept()
+
+	seed := time.Now().UnixNano()
+	if s := os.Getenv(envEthFuzzSeed); s != "" {
+		var err error
+		seed, err = strconv.ParseInt(s, 10, 64)
+		require.NoError(t, err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Minute)
+	defer cancel()
+
+	client, _, ens := kit.EnsembleMinimal(t, kit.MockProofs(), kit.ThroughRPC(), kit.WithEthRPC())
+	ens.InterconnectAll().BeginMining(10 * time.Millisecond)
+
+	// give the fuzzer a chain with some blocks to look up
+	client.WaitTillChain(ctx, kit.HeightAtLeast(10))
+
+	fuzzer := kit.NewEthFuzzer(t, client, seed)
+	if s := os.Getenv(envEthFuzzIterations); s != "" {
+		n, err := strconv.Atoi(s)
+		require.NoError(t, err)
+		fuzzer.Iterations = n
+	}
+	fuzzer.Run(ctx)
+}
+
+func TestEthFuzzMethods(t *testing.T) {
+	methods := kit.EthFuzzMethods()
+	require.Contains(t, methods, "EthGetLogs")
+	require.Contains(t, methods, "Web3ClientVersion")
+	require.NotContains(t, methods, "ChainHead")
+	require.Len(t, methods["EthGetBalance"], 2)
+}
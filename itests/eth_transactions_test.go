@@ -364,3 +364,72 @@ func waitForEthTxReceipt(ctx context.Context, client *kit.TestFullNode, hash eth
 	}
 	return receipt, err
 }
+
+func TestEthTxPool(t *testing.T) {
+	blockTime := 100 * time.Millisecond
+	client, _, ens := kit.EnsembleMinimal(t, kit.MockProofs(), kit.ThroughRPC())
+
+	ens.InterconnectAll().BeginMining(blockTime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	key, ethAddr, deployer := client.EVM().NewAccount()
+	_, ethAddr2, _ := client.EVM().NewAccount()
+
+	kit.SendFunds(ctx, t, client, deployer, types.FromFil(1000))
+
+	maxPriorityFeePerGas, err := client.EthMaxPriorityFeePerGas(ctx)
+	require.NoError(t, err)
+
+	// Skip nonce 0, so the transaction stays queued.
+	tx := ethtypes.EthTxArgs{
+		ChainID:              build.Eip155ChainId,
+		Value:                big.NewInt(100),
+		Nonce:                1,
+		To:                   &ethAddr2,
+		MaxFeePerGas:         types.NanoFil,
+		MaxPriorityFeePerGas: big.Int(maxPriorityFeePerGas),
+		GasLimit:             1_000_000,
+		V:                    big.Zero(),
+		R:                    big.Zero(),
+		S:                    big.Zero(),
+	}
+	client.EVM().SignTransaction(&tx, key.PrivateKey)
+	client.EVM().SubmitTransaction(ctx, &tx)
+
+	// Same for a native message.
+	nonce, err := client.MpoolGetNonce(ctx, client.DefaultKey.Address)
+	require.NoError(t, err)
+	smsg, err := client.WalletSignMessage(ctx, client.DefaultKey.Address, &types.Message{
+		From:       client.DefaultKey.Address,
+		To:         client.DefaultKey.Address,
+		Nonce:      nonce + 1,
+		Value:      big.Zero(),
+		GasLimit:   1_000_000,
+		GasFeeCap:  types.NanoFil,
+		GasPremium: big.NewInt(1000),
+	})
+	require.NoError(t, err)
+	_, err = client.MpoolPush(ctx, smsg)
+	require.NoError(t, err)
+
+	content, err := client.EthTxPoolContent(ctx)
+	require.NoError(t, err)
+	require.Contains(t, content.Queued, ethAddr.String())
+	queuedTx := content.Queued[ethAddr.String()][1]
+	require.Equal(t, ethAddr, queuedTx.From)
+	require.Equal(t, ethAddr2, *queuedTx.To)
+	require.Equal(t, tx.Value, big.Int(queuedTx.Value))
+
+	require.Contains(t, content.FilecoinQueued, client.DefaultKey.Address.String())
+	require.Equal(t, smsg.Cid(), content.FilecoinQueued[client.DefaultKey.Address.String()][ethtypes.EthUint64(nonce+1)].Cid())
+
+	inspect, err := client.EthTxPoolInspect(ctx)
+	require.NoError(t, err)
+	require.Contains(t, inspect.Queued[ethAddr.String()][1], ethAddr2.String())
+
+	status, err := client.EthTxPoolStatus(ctx)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, status.Queued, ethtypes.EthUint64(2))
+}
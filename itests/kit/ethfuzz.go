@@ -0,0 +1,383 @@
+package kit
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"reflect"
+	"runtime"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// ethFuzzExtraMethods are methods of the Eth API without the Eth prefix.
+var ethFuzzExtraMethods = map[string]bool{
+	"NetVersion":        true,
+	"NetListening":      true,
+	"Web3ClientVersion": true,
+}
+
+// EthFuzzer sends randomized and malformed requests to every method of the
+// Eth JSON-RPC API of a node, and checks that the node answers each of them
+// with a well formed JSON-RPC response, without panicking, and that it doesn't
+// leak goroutines once done.
+//
+// The parameters are generated from the types of the method parameters, mixing
+// plausible values with malformed hex, negative and overflowing numbers, block
+// ranges spanning the whole chain, oversized arrays and values of the wrong
+// JSON type. Runs are reproducible from their seed.
+type EthFuzzer struct {
+	t    *testing.T
+	node *TestFullNode
+	seed int64
+	rng  *rand.Rand
+
+	// Iterations is the number of requests sent to each method.
+	Iterations int
+	// Timeout is the time the node has to answer a request.
+	Timeout time.Duration
+	// GoroutineSlack is the number of goroutines the node may have more than
+	// before the run once it has settled, for background work unrelated to
+	// the requests.
+	GoroutineSlack int
+
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewEthFuzzer creates a fuzzer of the Eth API of a node started with
+// ThroughRPC() and WithEthRPC().
+func NewEthFuzzer(t *testing.T, node *TestFullNode, seed int64) *EthFuzzer {
+	require.NotEmpty(t, node.ListenURL, "the fuzzed node must be started with ThroughRPC()")
+
+	token, err := node.AuthNew(context.Background(), api.AllPermissions)
+	require.NoError(t, err)
+
+	return &EthFuzzer{
+		t:              t,
+		node:           node,
+		seed:           seed,
+		rng:            rand.New(rand.NewSource(seed)),
+		Iterations:     20,
+		Timeout:        30 * time.Second,
+		GoroutineSlack: 50,
+		endpoint:       node.ListenURL + "/rpc/v1",
+		token:          string(token),
+		client:         &http.Client{},
+	}
+}
+
+// EthFuzzMethods returns the methods of the Eth API, and the types of their
+// parameters.
+func EthFuzzMethods() map[string][]reflect.Type {
+	out := map[string][]reflect.Type{}
+	it := reflect.TypeOf((*api.FullNode)(nil)).Elem()
+	for i := 0; i < it.NumMethod(); i++ {
+		m := it.Method(i)
+		if !strings.HasPrefix(m.Name, "Eth") && !ethFuzzExtraMethods[m.Name] {
+			continue
+		}
+
+		var params []reflect.Type
+		// skip the context
+		for p := 1; p < m.Type.NumIn(); p++ {
+			params = append(params, m.Type.In(p))
+		}
+		out[m.Name] = params
+	}
+	return out
+}
+
+// Run fuzzes every method of the Eth API, in a subtest each.
+func (f *EthFuzzer) Run(ctx context.Context) {
+	f.t.Logf("fuzzing the Eth API with seed %d", f.seed)
+
+	before := runtime.NumGoroutine()
+
+	methods := EthFuzzMethods()
+	names := make([]string, 0, len(methods))
+	for name := range methods {
+		names = append(names, name)
+	}
+	// the order of the methods doesn't depend on map iteration, for the runs
+	// to be reproducible from the seed
+	sort.Strings(names)
+
+	for _, name := range names {
+		params := methods[name]
+		f.t.Run(name, func(t *testing.T) {
+			for i := 0; i < f.Iterations; i++ {
+				f.call(ctx, t, "Filecoin."+name, f.genParams(params))
+			}
+		})
+	}
+
+	// the node still serves requests
+	_, err := f.node.ChainHead(ctx)
+	require.NoError(f.t, err, "node broken after fuzzing (seed %d)", f.seed)
+
+	f.client.CloseIdleConnections()
+	require.Eventually(f.t, func() bool {
+		return runtime.NumGoroutine() <= before+f.GoroutineSlack
+	}, time.Minute, time.Second, "goroutines leaked by fuzzing (seed %d): %d before, %d after", f.seed, before, runtime.NumGoroutine())
+}
+
+// call sends a request, and checks the response is a JSON-RPC result or error
+// which doesn't come from a panic.
+func (f *EthFuzzer) call(ctx context.Context, t *testing.T, method string, params json.RawMessage) {
+	id := f.rng.Int63()
+	body := fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, id, method, params)
+
+	ctx, cancel := context.WithTimeout(ctx, f.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.endpoint, strings.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+f.token)
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		t.Errorf("request failed (seed %d): %s\nrequest: %s", f.seed, err, truncate(body))
+		return
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var res struct {
+		Jsonrpc string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  json.RawMessage `json:"result"`
+		Error   *struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(raw, &res); err != nil {
+		t.Errorf("malformed response (seed %d, status %d): %s\nrequest: %s\nresponse: %s", f.seed, resp.StatusCode, err, truncate(body), truncate(string(raw)))
+		return
+	}
+
+	switch {
+	case res.Jsonrpc != "2.0":
+		t.Errorf("response isn't JSON-RPC 2.0 (seed %d)\nrequest: %s\nresponse: %s", f.seed, truncate(body), truncate(string(raw)))
+	case res.Error == nil && res.Result == nil:
+		t.Errorf("response has neither a result nor an error (seed %d)\nrequest: %s\nresponse: %s", f.seed, truncate(body), truncate(string(raw)))
+	case res.Error != nil && res.Error.Code == 0:
+		t.Errorf("error without a code (seed %d)\nrequest: %s\nresponse: %s", f.seed, truncate(body), truncate(string(raw)))
+	case res.Error != nil && strings.Contains(res.Error.Message, "panic in rpc method"):
+		t.Errorf("method panicked (seed %d): %s\nrequest: %s", f.seed, res.Error.Message, truncate(body))
+	case res.Error == nil && string(res.ID) != fmt.Sprint(id):
+		t.Errorf("response id %s doesn't match the request id %d (seed %d)", res.ID, id, f.seed)
+	}
+}
+
+// genParams generates the parameters of a call. Some calls get too few or too
+// many parameters.
+func (f *EthFuzzer) genParams(types []reflect.Type) json.RawMessage {
+	vals := make([]interface{}, 0, len(types)+1)
+	for _, typ := range types {
+		vals = append(vals, f.genValue(typ, 0))
+	}
+
+	switch f.rng.Intn(20) {
+	case 0:
+		if len(vals) > 0 {
+			vals = vals[:f.rng.Intn(len(vals))]
+		}
+	case 1:
+		vals = append(vals, f.malformed())
+	case 2:
+		// not an array
+		b, _ := json.Marshal(f.malformed())
+		return b
+	}
+
+	b, err := json.Marshal(vals)
+	require.NoError(f.t, err)
+	return b
+}
+
+// genValue generates a value for a parameter of the given type: usually one
+// plausible for the type, but valid or not, and sometimes anything.
+func (f *EthFuzzer) genValue(typ reflect.Type, depth int) interface{} {
+	if depth > 4 || f.rng.Intn(5) == 0 {
+		return f.malformed()
+	}
+
+	// the eth types are recognized by name, lists of them by kind below
+	name := typ.Name()
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() == reflect.Uint8 {
+		switch {
+		case strings.Contains(name, "Address"):
+			return f.hexBytes(f.pick(20, 20, 20, 0, 19, 21, 32))
+		case strings.Contains(name, "Hash"):
+			return f.hexBytes(f.pick(32, 32, 32, 0, 31, 33))
+		case strings.Contains(name, "Uint64") || strings.Contains(name, "BlockNumber"):
+			return f.quantity()
+		case strings.Contains(name, "Bytes"):
+			return f.hexBytes(f.pick(0, 1, 4, 32, 100, 1<<16))
+		}
+	}
+
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if f.rng.Intn(10) == 0 {
+			return nil
+		}
+		return f.genValue(typ.Elem(), depth)
+	case reflect.Bool:
+		return f.rng.Intn(2) == 0
+	case reflect.String:
+		return f.blockParam()
+	case reflect.Int, reflect.Int64, reflect.Uint64, reflect.Int32, reflect.Uint32, reflect.Uint8:
+		return f.number()
+	case reflect.Float64, reflect.Float32:
+		return []float64{-1, 0, 25, 50, 100, 101, 1e300}[f.rng.Intn(7)]
+	case reflect.Slice, reflect.Array:
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return f.hexBytes(f.pick(0, 1, 32, 1000))
+		}
+		n := f.pick(0, 1, 2, 3, 10, 100, 5000)
+		// a single generated element repeated keeps huge arrays cheap, as
+		// long as the element is small
+		elem := f.genValue(typ.Elem(), depth+1)
+		if b, _ := json.Marshal(elem); len(b) > 100 && n > 10 {
+			n = 10
+		}
+		vals := make([]interface{}, n)
+		for i := range vals {
+			vals[i] = elem
+		}
+		return vals
+	case reflect.Map:
+		return map[string]interface{}{"0x00": f.genValue(typ.Elem(), depth+1)}
+	case reflect.Struct:
+		obj := map[string]interface{}{}
+		for i := 0; i < typ.NumField(); i++ {
+			fld := typ.Field(i)
+			if !fld.IsExported() || f.rng.Intn(3) == 0 {
+				continue
+			}
+			key := fld.Name
+			if tag := strings.Split(fld.Tag.Get("json"), ",")[0]; tag == "-" {
+				continue
+			} else if tag != "" {
+				key = tag
+			}
+			obj[key] = f.genValue(fld.Type, depth+1)
+		}
+		return obj
+	default:
+		return f.malformed()
+	}
+}
+
+// malformed returns a value of any JSON type, most likely of the wrong one.
+func (f *EthFuzzer) malformed() interface{} {
+	switch f.rng.Intn(14) {
+	case 0:
+		return nil
+	case 1:
+		return true
+	case 2:
+		return -1
+	case 3:
+		return json.RawMessage("1e400")
+	case 4:
+		return ""
+	case 5:
+		return "0x"
+	case 6:
+		return "0xzz"
+	case 7:
+		return "0x" + strings.Repeat("f", 10000)
+	case 8:
+		return "-0x1"
+	case 9:
+		return map[string]interface{}{}
+	case 10:
+		return []interface{}{}
+	case 11:
+		return json.RawMessage(strings.Repeat("[", 200) + strings.Repeat("]", 200))
+	case 12:
+		return strings.Repeat("a", 1<<16)
+	default:
+		return f.rng.Int63()
+	}
+}
+
+// blockParam returns a block number, tag or hash, valid or not.
+func (f *EthFuzzer) blockParam() interface{} {
+	switch f.rng.Intn(8) {
+	case 0:
+		return "latest"
+	case 1:
+		return "pending"
+	case 2:
+		return "earliest"
+	case 3:
+		return "safe"
+	case 4:
+		return "finalized"
+	case 5:
+		return f.hexBytes(32)
+	default:
+		return f.quantity()
+	}
+}
+
+// quantity returns a hex quantity, often out of range or malformed.
+func (f *EthFuzzer) quantity() interface{} {
+	switch f.rng.Intn(8) {
+	case 0:
+		return "0x0"
+	case 1:
+		return "-0x1"
+	case 2:
+		return "0xffffffffffffffff"
+	case 3:
+		return "0x1" + strings.Repeat("0", 64)
+	case 4:
+		return "0x01"
+	case 5:
+		return f.number()
+	default:
+		return fmt.Sprintf("0x%x", f.rng.Intn(1000))
+	}
+}
+
+func (f *EthFuzzer) number() interface{} {
+	return f.pick(-1, 0, 1, 1<<31, 1<<53, -1<<63)
+}
+
+func (f *EthFuzzer) hexBytes(n int) string {
+	b := make([]byte, n)
+	_, _ = f.rng.Read(b)
+	return "0x" + hex.EncodeToString(b)
+}
+
+func (f *EthFuzzer) pick(vals ...int) int {
+	return vals[f.rng.Intn(len(vals))]
+}
+
+func truncate(s string) string {
+	const max = 2000
+	if len(s) <= max {
+		return s
+	}
+	return s[:max] + fmt.Sprintf("... (%d bytes)", len(s))
+}
@@ -22,6 +22,8 @@ type Alerting struct {
 
 	lk     sync.Mutex
 	alerts map[AlertType]Alert
+
+	raiseListeners []func(Alert)
 }
 
 // AlertType is a unique alert identifier
@@ -101,10 +103,20 @@ func (a *Alerting) update(at AlertType, message interface{}, upd func(Alert, jso
 	a.alerts[at] = upd(alert, rawMsg)
 }
 
+// OnRaise registers a callback called with the alert each time an alert is
+// raised. Callbacks are called synchronously and must not block.
+func (a *Alerting) OnRaise(cb func(Alert)) {
+	a.lk.Lock()
+	defer a.lk.Unlock()
+
+	a.raiseListeners = append(a.raiseListeners, cb)
+}
+
 // Raise marks the alert condition as active and records related event in the journal
 func (a *Alerting) Raise(at AlertType, message interface{}) {
 	log.Errorw("alert raised", "type", at, "message", message)
 
+	var raised Alert
 	a.update(at, message, func(alert Alert, rawMsg json.RawMessage) Alert {
 		alert.Active = true
 		alert.LastActive = &AlertEvent{
@@ -117,8 +129,17 @@ func (a *Alerting) Raise(at AlertType, message interface{}) {
 			return alert.LastActive
 		})
 
+		raised = alert
 		return alert
 	})
+
+	a.lk.Lock()
+	listeners := a.raiseListeners
+	a.lk.Unlock()
+
+	for _, cb := range listeners {
+		cb(raised)
+	}
 }
 
 // Resolve marks the alert condition as resolved and records related event in the journal
@@ -37,9 +37,19 @@ func TestAlerting(t *testing.T) {
 		require.Nil(t, alert.LastResolved)
 	}
 
+	var raised []Alert
+	a.OnRaise(func(alert Alert) {
+		raised = append(raised, alert)
+	})
+
 	j.EXPECT().RecordEvent(a.alerts[al1].journalType, gomock.Any())
 	a.Raise(al1, "test")
 
+	require.Len(t, raised, 1)
+	require.Equal(t, al1, raised[0].Type)
+	require.True(t, raised[0].Active)
+	require.Equal(t, json.RawMessage(`"test"`), raised[0].LastActive.Message)
+
 	for _, alert := range l { // check for no magic mutations
 		require.False(t, alert.Active)
 		require.Nil(t, alert.LastActive)
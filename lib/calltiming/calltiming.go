@@ -0,0 +1,67 @@
+// Package calltiming records how long the phases of a call take, so that the
+// time of slow API calls can be broken down.
+package calltiming
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Phase is the time spent in a phase of a call.
+type Phase struct {
+	Name     string
+	Duration time.Duration
+}
+
+// Recorder collects the phases of a call.
+type Recorder struct {
+	lk     sync.Mutex
+	phases []Phase
+}
+
+type recorderKey struct{}
+
+// WithRecorder returns a context in which the phases started with Start are
+// recorded by the returned recorder.
+func WithRecorder(ctx context.Context) (context.Context, *Recorder) {
+	r := &Recorder{}
+	return context.WithValue(ctx, recorderKey{}, r), r
+}
+
+// Start starts a phase of the call of the context, ended by calling the
+// returned function. It's a no-op when the context has no recorder.
+func Start(ctx context.Context, name string) func() {
+	r, ok := ctx.Value(recorderKey{}).(*Recorder)
+	if !ok {
+		return func() {}
+	}
+
+	start := time.Now()
+	return func() {
+		r.Record(name, time.Since(start))
+	}
+}
+
+// Record adds the time spent in a phase. The time of phases with the same
+// name, e.g. in loops, is added up.
+func (r *Recorder) Record(name string, d time.Duration) {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	for i := range r.phases {
+		if r.phases[i].Name == name {
+			r.phases[i].Duration += d
+			return
+		}
+	}
+	r.phases = append(r.phases, Phase{Name: name, Duration: d})
+}
+
+// Phases returns the recorded phases, in the order they were first recorded.
+func (r *Recorder) Phases() []Phase {
+	r.lk.Lock()
+	defer r.lk.Unlock()
+
+	return append([]Phase(nil), r.phases...)
+}
@@ -0,0 +1,173 @@
+// Package clockcheck measures the offset of the system clock against NTP
+// servers.
+//
+// Blocks are validated against the local clock, so a clock running behind
+// rejects valid blocks as being from the future, and a clock running ahead
+// mines blocks which arrive late. The last measurement is kept to explain
+// such failures.
+package clockcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// ntpEpochOffset is the number of seconds between the NTP epoch (1900) and the
+// unix epoch (1970).
+const ntpEpochOffset = 2208988800
+
+const (
+	ntpPacketSize = 48
+
+	ntpModeClient = 3
+	ntpModeServer = 4
+	ntpVersion    = 4
+)
+
+// Measurement is the offset of the system clock against an NTP server.
+type Measurement struct {
+	Server string
+	// Offset is positive when the system clock is behind the server, and
+	// negative when it's ahead.
+	Offset time.Duration
+	// RTT is the round trip time of the query.
+	RTT time.Duration
+	// Time is the time of the measurement.
+	Time time.Time
+}
+
+func toNtpTime(t time.Time) uint64 {
+	nsec := uint64(t.UnixNano()) + ntpEpochOffset*uint64(time.Second)
+	sec := nsec / uint64(time.Second)
+	frac := (nsec % uint64(time.Second)) << 32 / uint64(time.Second)
+	return sec<<32 | frac
+}
+
+func fromNtpTime(t uint64) time.Time {
+	sec := int64(t>>32) - ntpEpochOffset
+	nsec := int64((t & 0xffffffff) * uint64(time.Second) >> 32)
+	return time.Unix(sec, nsec)
+}
+
+// Query measures the offset of the system clock against an NTP server with a
+// single SNTP (RFC 4330) request. The server is a host, with an optional port
+// which defaults to 123.
+func Query(ctx context.Context, server string) (Measurement, error) {
+	addr := server
+	if _, _, err := net.SplitHostPort(server); err != nil {
+		addr = net.JoinHostPort(server, "123")
+	}
+
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "udp", addr)
+	if err != nil {
+		return Measurement{}, xerrors.Errorf("dialing %s: %w", addr, err)
+	}
+	defer conn.Close() // nolint
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(5 * time.Second)
+	}
+	if err := conn.SetDeadline(deadline); err != nil {
+		return Measurement{}, err
+	}
+
+	req := make([]byte, ntpPacketSize)
+	req[0] = ntpVersion<<3 | ntpModeClient
+
+	sent := time.Now()
+	origin := toNtpTime(sent)
+	binary.BigEndian.PutUint64(req[40:], origin)
+	if _, err := conn.Write(req); err != nil {
+		return Measurement{}, xerrors.Errorf("sending request to %s: %w", addr, err)
+	}
+
+	resp := make([]byte, ntpPacketSize)
+	n, err := conn.Read(resp)
+	if err != nil {
+		return Measurement{}, xerrors.Errorf("reading response from %s: %w", addr, err)
+	}
+	recv := time.Now()
+	if n < ntpPacketSize {
+		return Measurement{}, xerrors.Errorf("short response from %s: %d bytes", addr, n)
+	}
+
+	switch {
+	case resp[0]&0x7 != ntpModeServer:
+		return Measurement{}, xerrors.Errorf("unexpected mode %d in response from %s", resp[0]&0x7, addr)
+	case resp[0]>>6 == 3:
+		return Measurement{}, xerrors.Errorf("server %s isn't synchronized", addr)
+	case resp[1] == 0:
+		return Measurement{}, xerrors.Errorf("server %s refused the request (kiss code %q)", addr, resp[12:16])
+	case binary.BigEndian.Uint64(resp[24:]) != origin:
+		return Measurement{}, xerrors.Errorf("response from %s doesn't match the request", addr)
+	}
+
+	// server receive and transmit times
+	t2 := fromNtpTime(binary.BigEndian.Uint64(resp[32:]))
+	t3 := fromNtpTime(binary.BigEndian.Uint64(resp[40:]))
+
+	return Measurement{
+		Server: server,
+		Offset: (t2.Sub(sent) + t3.Sub(recv)) / 2,
+		RTT:    recv.Sub(sent) - t3.Sub(t2),
+		Time:   recv,
+	}, nil
+}
+
+// QueryAny queries the servers in turn, and returns the first measurement.
+func QueryAny(ctx context.Context, servers []string) (Measurement, error) {
+	if len(servers) == 0 {
+		return Measurement{}, xerrors.Errorf("no NTP servers configured")
+	}
+
+	var errs error
+	for _, s := range servers {
+		m, err := Query(ctx, s)
+		if err == nil {
+			return m, nil
+		}
+		if errs == nil {
+			errs = err
+		} else {
+			errs = xerrors.Errorf("%s; %w", err, errs)
+		}
+	}
+	return Measurement{}, errs
+}
+
+// skew is a measurement above the threshold it's checked against.
+type skew struct {
+	m         Measurement
+	threshold time.Duration
+}
+
+var last atomic.Pointer[skew]
+
+// Record keeps the last measurement, which explains temporal validation
+// errors when its offset is above the threshold.
+func Record(m Measurement, threshold time.Duration) {
+	last.Store(&skew{m: m, threshold: threshold})
+}
+
+// Skewed returns whether the offset of a measurement is above a threshold.
+func Skewed(m Measurement, threshold time.Duration) bool {
+	return m.Offset > threshold || m.Offset < -threshold
+}
+
+// Annotate explains an error rejecting a block as being from the future when
+// the last measurement found the system clock running behind.
+func Annotate(err error) error {
+	s := last.Load()
+	if err == nil || s == nil || s.m.Offset <= 0 || !Skewed(s.m, s.threshold) {
+		return err
+	}
+	return xerrors.Errorf("the system clock is %s behind NTP time (measured against %s at %s), check the time synchronization of this host: %w",
+		s.m.Offset.Round(time.Millisecond), s.m.Server, s.m.Time.Format(time.RFC3339), err)
+}
@@ -0,0 +1,87 @@
+// stm: #unit
+package clockcheck
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+// serveNtp answers SNTP requests with a clock shifted by offset.
+func serveNtp(t *testing.T, offset time.Duration, stratum byte) string {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+
+	go func() {
+		buf := make([]byte, ntpPacketSize)
+		for {
+			_, addr, err := conn.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			now := toNtpTime(time.Now().Add(offset))
+
+			resp := make([]byte, ntpPacketSize)
+			resp[0] = ntpVersion<<3 | ntpModeServer
+			resp[1] = stratum
+			copy(resp[24:32], buf[40:48])
+			binary.BigEndian.PutUint64(resp[32:], now)
+			binary.BigEndian.PutUint64(resp[40:], now)
+			_, _ = conn.WriteTo(resp, addr)
+		}
+	}()
+
+	return conn.LocalAddr().String()
+}
+
+func TestNtpTime(t *testing.T) {
+	now := time.Now()
+	require.WithinDuration(t, now, fromNtpTime(toNtpTime(now)), time.Microsecond)
+}
+
+func TestQuery(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	addr := serveNtp(t, 3*time.Second, 2)
+	m, err := Query(ctx, addr)
+	require.NoError(t, err)
+	require.Equal(t, addr, m.Server)
+	require.InDelta(t, 3*time.Second, m.Offset, float64(100*time.Millisecond))
+	require.True(t, Skewed(m, time.Second))
+	require.False(t, Skewed(m, 5*time.Second))
+
+	// kiss of death
+	_, err = Query(ctx, serveNtp(t, 0, 0))
+	require.Error(t, err)
+
+	// the first server answering is used
+	m, err = QueryAny(ctx, []string{serveNtp(t, 0, 0), serveNtp(t, -2*time.Second, 1)})
+	require.NoError(t, err)
+	require.InDelta(t, -2*time.Second, m.Offset, float64(100*time.Millisecond))
+}
+
+func TestAnnotate(t *testing.T) {
+	defer last.Store(nil)
+
+	errFuture := xerrors.New("block was from the future")
+	require.Equal(t, errFuture, Annotate(errFuture))
+
+	// clocks running ahead don't reject blocks
+	Record(Measurement{Server: "ntp", Offset: -3 * time.Second}, time.Second)
+	require.Equal(t, errFuture, Annotate(errFuture))
+
+	Record(Measurement{Server: "ntp", Offset: 500 * time.Millisecond}, time.Second)
+	require.Equal(t, errFuture, Annotate(errFuture))
+
+	Record(Measurement{Server: "ntp", Offset: 3 * time.Second}, time.Second)
+	err := Annotate(errFuture)
+	require.ErrorIs(t, err, errFuture)
+	require.Contains(t, err.Error(), "the system clock is 3s behind NTP time")
+}
@@ -0,0 +1,110 @@
+package lotuslog
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+// LevelsFile is the file in the repo in which log levels set at runtime are
+// persisted.
+const LevelsFile = "loglevels.json"
+
+var persistLk sync.Mutex
+
+// SetLevel sets the level of a subsystem, "*" for all subsystems, and
+// persists it in the repo, so it is restored on the next start.
+func SetLevel(repoPath, subsystem, level string) error {
+	if err := logging.SetLogLevel(subsystem, level); err != nil {
+		return err
+	}
+
+	persistLk.Lock()
+	defer persistLk.Unlock()
+
+	levels, err := readLevels(repoPath)
+	if err != nil {
+		return err
+	}
+	if subsystem == "*" {
+		// the level of all subsystems was overridden
+		levels = map[string]string{}
+	}
+	levels[subsystem] = level
+
+	b, err := json.MarshalIndent(levels, "", "  ")
+	if err != nil {
+		return err
+	}
+	p := filepath.Join(repoPath, LevelsFile)
+	if err := os.WriteFile(p+".tmp", b, 0644); err != nil {
+		return xerrors.Errorf("persisting log levels: %w", err)
+	}
+	if err := os.Rename(p+".tmp", p); err != nil {
+		return xerrors.Errorf("persisting log levels: %w", err)
+	}
+	return nil
+}
+
+// ResetLevels removes the log levels persisted in the repo, and sets the
+// default levels with the given config levels.
+func ResetLevels(repoPath string, configLevels map[string]string) error {
+	persistLk.Lock()
+	defer persistLk.Unlock()
+
+	if err := os.Remove(filepath.Join(repoPath, LevelsFile)); err != nil && !os.IsNotExist(err) {
+		return xerrors.Errorf("removing persisted log levels: %w", err)
+	}
+
+	SetupLogLevels()
+	SetLevelsFromConfig(configLevels)
+	return nil
+}
+
+// RestoreLevels sets the log levels persisted in the repo.
+func RestoreLevels(repoPath string) error {
+	persistLk.Lock()
+	defer persistLk.Unlock()
+
+	levels, err := readLevels(repoPath)
+	if err != nil {
+		return err
+	}
+
+	if l, ok := levels["*"]; ok {
+		if err := logging.SetLogLevel("*", l); err != nil {
+			return xerrors.Errorf("restoring log level of all subsystems: %w", err)
+		}
+	}
+	for sys, l := range levels {
+		if sys == "*" {
+			continue
+		}
+		// subsystems which no longer exist are skipped
+		if err := logging.SetLogLevel(sys, l); err != nil && !errors.Is(err, logging.ErrNoSuchLogger) {
+			return xerrors.Errorf("restoring log level of %s: %w", sys, err)
+		}
+	}
+	return nil
+}
+
+func readLevels(repoPath string) (map[string]string, error) {
+	b, err := os.ReadFile(filepath.Join(repoPath, LevelsFile))
+	if os.IsNotExist(err) {
+		return map[string]string{}, nil
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("reading persisted log levels: %w", err)
+	}
+
+	levels := map[string]string{}
+	if err := json.Unmarshal(b, &levels); err != nil {
+		return nil, xerrors.Errorf("parsing persisted log levels: %w", err)
+	}
+	return levels, nil
+}
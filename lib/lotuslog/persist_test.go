@@ -0,0 +1,31 @@
+package lotuslog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestResetLevels(t *testing.T) {
+	dir := t.TempDir()
+	lg := logging.Logger("persist-test")
+
+	require.NoError(t, SetLevel(dir, "persist-test", "debug"))
+	require.True(t, lg.Desugar().Core().Enabled(zapcore.DebugLevel))
+	levels, err := readLevels(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"persist-test": "debug"}, levels)
+
+	require.NoError(t, ResetLevels(dir, map[string]string{"persist-test": "warn"}))
+	_, err = os.Stat(filepath.Join(dir, LevelsFile))
+	require.True(t, os.IsNotExist(err))
+	require.False(t, lg.Desugar().Core().Enabled(zapcore.InfoLevel))
+	require.NoError(t, RestoreLevels(dir))
+
+	// resetting without persisted levels is fine
+	require.NoError(t, ResetLevels(dir, nil))
+}
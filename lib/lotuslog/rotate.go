@@ -0,0 +1,294 @@
+package lotuslog
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"go.uber.org/zap"
+	"golang.org/x/xerrors"
+)
+
+const rotateScheme = "lotusrotate"
+
+func init() {
+	if err := zap.RegisterSink(rotateScheme, newRotateSink); err != nil {
+		panic(err)
+	}
+}
+
+// FileConfig configures writing logs to a rotated file.
+type FileConfig struct {
+	// Path of the log file.
+	Path string
+	// MaxSize is the size in bytes after which the file is rotated; 0 never
+	// rotates the file.
+	MaxSize int64
+	// MaxAge is the age after which rotated files are deleted; 0 keeps them
+	// regardless of their age.
+	MaxAge time.Duration
+	// MaxBackups is the number of rotated files kept; 0 keeps all of them.
+	MaxBackups int
+	// Compress rotated files with gzip.
+	Compress bool
+}
+
+// SetupFileRotation writes logs to a rotated file instead of the file set with
+// GOLOG_FILE, keeping the other outputs of the logging system. When cfg.Path
+// is empty, the file set with GOLOG_FILE is rotated, if any. Log levels are
+// reset to their defaults.
+func SetupFileRotation(cfg FileConfig) error {
+	lcfg := logging.GetConfig()
+	if cfg.Path == "" {
+		cfg.Path = lcfg.File
+	}
+	if cfg.Path == "" {
+		return nil
+	}
+
+	p, err := filepath.Abs(cfg.Path)
+	if err != nil {
+		return xerrors.Errorf("resolving log file path: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		return xerrors.Errorf("creating log directory: %w", err)
+	}
+
+	q := url.Values{}
+	q.Set("max-size", strconv.FormatInt(cfg.MaxSize, 10))
+	q.Set("max-age", cfg.MaxAge.String())
+	q.Set("max-backups", strconv.Itoa(cfg.MaxBackups))
+	q.Set("compress", strconv.FormatBool(cfg.Compress))
+	u := url.URL{Scheme: rotateScheme, Path: p, RawQuery: q.Encode()}
+
+	if lcfg.URL != "" {
+		// go-log only supports a single URL output
+		return xerrors.Errorf("log file rotation can't be used with GOLOG_URL")
+	}
+	lcfg.File = ""
+	lcfg.URL = u.String()
+	logging.SetupLogging(lcfg)
+	SetupLogLevels()
+
+	return nil
+}
+
+func newRotateSink(u *url.URL) (zap.Sink, error) {
+	q := u.Query()
+	rf := &rotatingFile{path: u.Path}
+
+	var err error
+	if rf.maxSize, err = strconv.ParseInt(q.Get("max-size"), 10, 64); err != nil {
+		return nil, xerrors.Errorf("parsing max-size: %w", err)
+	}
+	if rf.maxAge, err = time.ParseDuration(q.Get("max-age")); err != nil {
+		return nil, xerrors.Errorf("parsing max-age: %w", err)
+	}
+	if rf.maxBackups, err = strconv.Atoi(q.Get("max-backups")); err != nil {
+		return nil, xerrors.Errorf("parsing max-backups: %w", err)
+	}
+	if rf.compress, err = strconv.ParseBool(q.Get("compress")); err != nil {
+		return nil, xerrors.Errorf("parsing compress: %w", err)
+	}
+
+	if err := rf.open(); err != nil {
+		return nil, err
+	}
+	return rf, nil
+}
+
+// backupTimeFormat is the format of the time appended to the name of rotated
+// files; it sorts in chronological order.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+// rotatingFile is a log file which is renamed, with the time of the rotation,
+// when it grows over maxSize. Rotated files are then compressed and deleted
+// in the background according to the retention settings.
+type rotatingFile struct {
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+	compress   bool
+
+	lk   sync.Mutex
+	f    *os.File
+	size int64
+
+	// cleanupLk makes sure a single cleanup runs at a time
+	cleanupLk sync.Mutex
+}
+
+func (rf *rotatingFile) open() error {
+	f, err := os.OpenFile(rf.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return xerrors.Errorf("opening log file: %w", err)
+	}
+	st, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	rf.f = f
+	rf.size = st.Size()
+	return nil
+}
+
+func (rf *rotatingFile) Write(p []byte) (int, error) {
+	rf.lk.Lock()
+	defer rf.lk.Unlock()
+
+	if rf.maxSize > 0 && rf.size > 0 && rf.size+int64(len(p)) > rf.maxSize {
+		if err := rf.rotate(); err != nil {
+			// keep logging to the current file
+			_, _ = fmt.Fprintf(os.Stderr, "rotating log file %s: %s\n", rf.path, err)
+		}
+	}
+
+	n, err := rf.f.Write(p)
+	rf.size += int64(n)
+	return n, err
+}
+
+func (rf *rotatingFile) rotate() error {
+	if err := rf.f.Close(); err != nil {
+		return err
+	}
+
+	if err := os.Rename(rf.path, rf.backupName(time.Now())); err != nil {
+		if oerr := rf.open(); oerr != nil {
+			return oerr
+		}
+		return err
+	}
+	if err := rf.open(); err != nil {
+		return err
+	}
+
+	go rf.cleanup()
+	return nil
+}
+
+// backupName is the name the log file is rotated to at t, e.g. lotus.log is
+// rotated to lotus-2006-01-02T15-04-05.000.log.
+func (rf *rotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(rf.path)
+	return strings.TrimSuffix(rf.path, ext) + "-" + t.UTC().Format(backupTimeFormat) + ext
+}
+
+// backups returns the rotated files, newest first.
+func (rf *rotatingFile) backups() ([]string, error) {
+	ext := filepath.Ext(rf.path)
+	prefix := strings.TrimSuffix(filepath.Base(rf.path), ext) + "-"
+
+	ents, err := os.ReadDir(filepath.Dir(rf.path))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []string
+	for _, ent := range ents {
+		name := ent.Name()
+		if ent.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		if _, err := time.Parse(backupTimeFormat, ts); err != nil {
+			continue
+		}
+		out = append(out, filepath.Join(filepath.Dir(rf.path), name))
+	}
+
+	// the time format sorts in chronological order
+	sort.Sort(sort.Reverse(sort.StringSlice(out)))
+	return out, nil
+}
+
+// cleanup compresses and deletes rotated files. Errors are written to stderr,
+// as they can't be logged.
+func (rf *rotatingFile) cleanup() {
+	rf.cleanupLk.Lock()
+	defer rf.cleanupLk.Unlock()
+
+	backups, err := rf.backups()
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "listing rotated log files: %s\n", err)
+		return
+	}
+
+	for i, b := range backups {
+		remove := rf.maxBackups > 0 && i >= rf.maxBackups
+		if !remove && rf.maxAge > 0 {
+			st, err := os.Stat(b)
+			if err == nil && time.Since(st.ModTime()) > rf.maxAge {
+				remove = true
+			}
+		}
+
+		if remove {
+			if err := os.Remove(b); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "removing rotated log file: %s\n", err)
+			}
+			continue
+		}
+
+		if rf.compress && !strings.HasSuffix(b, ".gz") {
+			if err := compressFile(b); err != nil {
+				_, _ = fmt.Fprintf(os.Stderr, "compressing rotated log file: %s\n", err)
+			}
+		}
+	}
+}
+
+func compressFile(path string) error {
+	in, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.Create(path + ".gz")
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	if _, err := io.Copy(zw, in); err != nil {
+		_ = out.Close()
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		_ = out.Close()
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+	if err := out.Close(); err != nil {
+		_ = os.Remove(path + ".gz")
+		return err
+	}
+
+	return os.Remove(path)
+}
+
+func (rf *rotatingFile) Sync() error {
+	rf.lk.Lock()
+	defer rf.lk.Unlock()
+	return rf.f.Sync()
+}
+
+func (rf *rotatingFile) Close() error {
+	rf.lk.Lock()
+	defer rf.lk.Unlock()
+	return rf.f.Close()
+}
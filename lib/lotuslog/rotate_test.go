@@ -0,0 +1,73 @@
+package lotuslog
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestRotatingFile(t *testing.T) {
+	dir := t.TempDir()
+	rf := &rotatingFile{
+		path:       filepath.Join(dir, "lotus.log"),
+		maxSize:    100,
+		maxBackups: 2,
+		compress:   true,
+	}
+	require.NoError(t, rf.open())
+	defer rf.Close() //nolint:errcheck
+
+	line := []byte(strings.Repeat("x", 59) + "\n")
+	for i := 0; i < 5; i++ {
+		_, err := rf.Write(line)
+		require.NoError(t, err)
+		// rotated file names have millisecond precision
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	st, err := os.Stat(rf.path)
+	require.NoError(t, err)
+	require.EqualValues(t, len(line), st.Size())
+
+	// rotated files are cleaned up in the background
+	require.Eventually(t, func() bool {
+		backups, err := rf.backups()
+		require.NoError(t, err)
+		if len(backups) != 2 {
+			return false
+		}
+		for _, b := range backups {
+			if !strings.HasSuffix(b, ".log.gz") {
+				return false
+			}
+		}
+		return true
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestPersistedLevels(t *testing.T) {
+	dir := t.TempDir()
+	logging.Logger("lotuslog-test")
+
+	require.NoError(t, SetLevel(dir, "lotuslog-test", "debug"))
+	require.NoError(t, logging.SetLogLevel("lotuslog-test", "error"))
+
+	require.NoError(t, RestoreLevels(dir))
+	require.True(t, logging.Logger("lotuslog-test").Desugar().Core().Enabled(zapcore.DebugLevel))
+
+	levels, err := readLevels(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"lotuslog-test": "debug"}, levels)
+
+	// setting all subsystems overrides the persisted levels
+	require.NoError(t, SetLevel(dir, "*", "warn"))
+	levels, err = readLevels(dir)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"*": "warn"}, levels)
+}
@@ -0,0 +1,348 @@
+// Package maintenance restricts expensive background activities, such as
+// splitstore compaction, snapshot exports and database garbage collection, to
+// maintenance windows.
+//
+// A maintenance window opens at the times given by a cron expression and stays
+// open for a fixed duration. Activities which can be deferred wait for a window
+// to open, and maintenance tasks run once at the start of each window. The
+// windows can be forced open or closed for a while with an override. When no
+// windows are configured, activities are always allowed and maintenance tasks
+// only run while an override forces the windows open.
+package maintenance
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/robfig/cron/v3"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/journal"
+)
+
+var log = logging.Logger("maintenance")
+
+// Window is a recurring maintenance window.
+type Window struct {
+	Spec     string
+	Duration time.Duration
+
+	schedule cron.Schedule
+}
+
+// ParseWindow parses a window opening at the times of a standard cron
+// expression, e.g. "0 2 * * *" for 2am every day.
+func ParseWindow(spec string, d time.Duration) (Window, error) {
+	if d <= 0 {
+		return Window{}, xerrors.Errorf("window %q has no duration", spec)
+	}
+	schedule, err := cron.ParseStandard(spec)
+	if err != nil {
+		return Window{}, xerrors.Errorf("parsing schedule %q: %w", spec, err)
+	}
+	return Window{Spec: spec, Duration: d, schedule: schedule}, nil
+}
+
+// TaskFunc runs a maintenance task. The context is cancelled when the window
+// closes.
+type TaskFunc func(ctx context.Context) error
+
+// RunEvent is recorded in the journal after each run of a maintenance task or
+// activity.
+type RunEvent struct {
+	Task     string
+	Start    time.Time
+	Duration time.Duration
+	Error    string `json:",omitempty"`
+}
+
+type task struct {
+	fn     TaskFunc
+	status api.MaintenanceTask
+}
+
+type Scheduler struct {
+	windows []Window
+
+	journal journal.Journal
+	evtType journal.EventType
+
+	now func() time.Time
+
+	lk       sync.Mutex
+	override *api.MaintenanceOverride
+	tasks    []*task
+	// changed is closed when the override changes
+	changed chan struct{}
+
+	cancel  context.CancelFunc
+	stopped chan struct{}
+}
+
+func NewScheduler(windows []Window, j journal.Journal) *Scheduler {
+	if j == nil {
+		j = journal.NilJournal()
+	}
+	return &Scheduler{
+		windows: windows,
+		journal: j,
+		evtType: j.RegisterEventType("maintenance", "run"),
+		now:     build.Clock.Now,
+		changed: make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+}
+
+// Register adds a task run at the start of each maintenance window.
+func (s *Scheduler) Register(name string, fn TaskFunc) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	s.tasks = append(s.tasks, &task{fn: fn, status: api.MaintenanceTask{Name: name}})
+}
+
+// Track records a run of an activity gated by the maintenance windows, and
+// returns the function to call with its result when it's done.
+func (s *Scheduler) Track(name string) func(error) {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	var t *task
+	for _, tt := range s.tasks {
+		if tt.status.Name == name {
+			t = tt
+		}
+	}
+	if t == nil {
+		t = &task{status: api.MaintenanceTask{Name: name}}
+		s.tasks = append(s.tasks, t)
+	}
+	start := s.begin(t)
+	return func(err error) {
+		s.finish(t, start, err)
+	}
+}
+
+func (s *Scheduler) begin(t *task) time.Time {
+	start := s.now()
+	t.status.Running = true
+	t.status.LastStart = start
+	return start
+}
+
+func (s *Scheduler) finish(t *task, start time.Time, err error) {
+	evt := RunEvent{Task: t.status.Name, Start: start, Duration: s.now().Sub(start)}
+	if err != nil {
+		evt.Error = err.Error()
+		log.Errorw("maintenance task failed", "task", evt.Task, "took", evt.Duration, "error", err)
+	} else {
+		log.Infow("maintenance task done", "task", evt.Task, "took", evt.Duration)
+	}
+
+	s.lk.Lock()
+	t.status.Running = false
+	t.status.Runs++
+	t.status.LastDuration = evt.Duration
+	t.status.LastError = evt.Error
+	s.lk.Unlock()
+
+	s.journal.RecordEvent(s.evtType, func() interface{} {
+		return evt
+	})
+}
+
+// state returns whether maintenance is allowed at the given time, and until
+// when. The time is zero when maintenance is always allowed. It must be called
+// with the lock held.
+func (s *Scheduler) state(t time.Time) (bool, time.Time) {
+	if s.override != nil {
+		if t.Before(s.override.Until) {
+			return s.override.Open, s.override.Until
+		}
+		s.override = nil
+	}
+	if len(s.windows) == 0 {
+		return true, time.Time{}
+	}
+
+	var open bool
+	var until time.Time
+	for _, w := range s.windows {
+		// the last window started in the past duration, if any, is open
+		if start := w.schedule.Next(t.Add(-w.Duration)); !start.IsZero() && !start.After(t) {
+			if end := start.Add(w.Duration); !open || end.After(until) {
+				until = end
+			}
+			open = true
+		}
+	}
+	if open {
+		return true, until
+	}
+	for _, w := range s.windows {
+		if next := w.schedule.Next(t); !next.IsZero() && (until.IsZero() || next.Before(until)) {
+			until = next
+		}
+	}
+	return false, until
+}
+
+// Allowed returns whether expensive background activities are allowed now.
+func (s *Scheduler) Allowed() bool {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+	open, _ := s.state(s.now())
+	return open
+}
+
+// Wait blocks until expensive background activities are allowed.
+func (s *Scheduler) Wait(ctx context.Context) error {
+	for {
+		s.lk.Lock()
+		now := s.now()
+		open, until := s.state(now)
+		changed := s.changed
+		s.lk.Unlock()
+
+		if open {
+			return nil
+		}
+		if err := s.sleep(ctx, now, until, changed); err != nil {
+			return err
+		}
+	}
+}
+
+// sleep waits until the given time, when it isn't zero, or until the override
+// changes.
+func (s *Scheduler) sleep(ctx context.Context, now, until time.Time, changed <-chan struct{}) error {
+	var wake <-chan time.Time
+	if !until.IsZero() {
+		timer := build.Clock.Timer(until.Sub(now))
+		defer timer.Stop()
+		wake = timer.C
+	}
+	select {
+	case <-wake:
+	case <-changed:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	return nil
+}
+
+// SetOverride forces the maintenance windows open or closed until the time of
+// the override, or clears the override when nil.
+func (s *Scheduler) SetOverride(o *api.MaintenanceOverride) error {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	if o != nil && !o.Until.After(s.now()) {
+		return xerrors.Errorf("override ends in the past at %s", o.Until)
+	}
+	s.override = o
+	close(s.changed)
+	s.changed = make(chan struct{})
+
+	log.Infow("maintenance override set", "override", o)
+	return nil
+}
+
+// Status returns the state of the maintenance windows and tasks.
+func (s *Scheduler) Status() *api.MaintenanceStatus {
+	s.lk.Lock()
+	defer s.lk.Unlock()
+
+	now := s.now()
+	st := &api.MaintenanceStatus{}
+	st.Open, st.Until = s.state(now)
+	if s.override != nil {
+		o := *s.override
+		st.Override = &o
+	}
+	for _, w := range s.windows {
+		st.Windows = append(st.Windows, api.MaintenanceWindow{
+			Schedule:  w.Spec,
+			Duration:  w.Duration,
+			NextStart: w.schedule.Next(now),
+		})
+	}
+	for _, t := range s.tasks {
+		st.Tasks = append(st.Tasks, t.status)
+	}
+	sort.Slice(st.Tasks, func(i, j int) bool {
+		return st.Tasks[i].Name < st.Tasks[j].Name
+	})
+	return st
+}
+
+func (s *Scheduler) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+	go s.run(ctx)
+}
+
+func (s *Scheduler) Stop(ctx context.Context) error {
+	s.cancel()
+	select {
+	case <-s.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *Scheduler) run(ctx context.Context) {
+	defer close(s.stopped)
+
+	// end of the last window the tasks ran in
+	var ran time.Time
+	for {
+		s.lk.Lock()
+		now := s.now()
+		open, until := s.state(now)
+		changed := s.changed
+		s.lk.Unlock()
+
+		// tasks only run in windows which end
+		if open && !until.IsZero() && !until.Equal(ran) {
+			ran = until
+			log.Infow("maintenance window open", "until", until)
+			s.runTasks(ctx, until)
+			continue
+		}
+
+		if err := s.sleep(ctx, now, until, changed); err != nil {
+			return
+		}
+	}
+}
+
+// runTasks runs the registered tasks in turn, until the window closes.
+func (s *Scheduler) runTasks(ctx context.Context, until time.Time) {
+	ctx, cancel := context.WithTimeout(ctx, until.Sub(s.now()))
+	defer cancel()
+
+	s.lk.Lock()
+	tasks := append([]*task{}, s.tasks...)
+	s.lk.Unlock()
+
+	for _, t := range tasks {
+		if t.fn == nil {
+			continue
+		}
+		if ctx.Err() != nil {
+			log.Warnw("maintenance window closed before running task", "task", t.status.Name)
+			continue
+		}
+
+		s.lk.Lock()
+		start := s.begin(t)
+		s.lk.Unlock()
+
+		s.finish(t, start, t.fn(ctx))
+	}
+}
@@ -0,0 +1,121 @@
+// stm: #unit
+package maintenance
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+func TestParseWindow(t *testing.T) {
+	_, err := ParseWindow("0 2 * * *", 0)
+	require.Error(t, err)
+	_, err = ParseWindow("not a schedule", time.Hour)
+	require.Error(t, err)
+
+	w, err := ParseWindow("0 2 * * *", time.Hour)
+	require.NoError(t, err)
+	require.Equal(t, "0 2 * * *", w.Spec)
+}
+
+func TestSchedulerWindows(t *testing.T) {
+	w, err := ParseWindow("0 2 * * *", time.Hour)
+	require.NoError(t, err)
+
+	s := NewScheduler([]Window{w}, nil)
+	now := time.Date(2023, 5, 1, 1, 30, 0, 0, time.Local)
+	s.now = func() time.Time { return now }
+
+	// before the window
+	require.False(t, s.Allowed())
+	st := s.Status()
+	require.False(t, st.Open)
+	require.Equal(t, time.Date(2023, 5, 1, 2, 0, 0, 0, time.Local), st.Until)
+	require.Len(t, st.Windows, 1)
+	require.Equal(t, st.Until, st.Windows[0].NextStart)
+
+	// in the window
+	now = time.Date(2023, 5, 1, 2, 30, 0, 0, time.Local)
+	require.True(t, s.Allowed())
+	st = s.Status()
+	require.True(t, st.Open)
+	require.Equal(t, time.Date(2023, 5, 1, 3, 0, 0, 0, time.Local), st.Until)
+
+	// after the window
+	now = time.Date(2023, 5, 1, 3, 0, 0, 0, time.Local)
+	require.False(t, s.Allowed())
+
+	// overrides
+	require.Error(t, s.SetOverride(&api.MaintenanceOverride{Open: true, Until: now}))
+	require.NoError(t, s.SetOverride(&api.MaintenanceOverride{Open: true, Until: now.Add(time.Hour)}))
+	require.True(t, s.Allowed())
+	require.NotNil(t, s.Status().Override)
+
+	now = now.Add(time.Hour)
+	require.False(t, s.Allowed())
+	require.Nil(t, s.Status().Override)
+
+	now = time.Date(2023, 5, 2, 2, 0, 0, 0, time.Local)
+	require.True(t, s.Allowed())
+	require.NoError(t, s.SetOverride(&api.MaintenanceOverride{Open: false, Until: now.Add(time.Minute)}))
+	require.False(t, s.Allowed())
+	require.NoError(t, s.SetOverride(nil))
+	require.True(t, s.Allowed())
+}
+
+func TestSchedulerNoWindows(t *testing.T) {
+	s := NewScheduler(nil, nil)
+	require.True(t, s.Allowed())
+	require.NoError(t, s.Wait(context.Background()))
+
+	st := s.Status()
+	require.True(t, st.Open)
+	require.True(t, st.Until.IsZero())
+}
+
+func TestSchedulerTasks(t *testing.T) {
+	w, err := ParseWindow("0 2 * * *", time.Hour)
+	require.NoError(t, err)
+
+	s := NewScheduler([]Window{w}, nil)
+	now := time.Date(2023, 5, 1, 2, 0, 0, 0, time.Local)
+	s.now = func() time.Time { return now }
+
+	var ran []string
+	s.Register("b", func(ctx context.Context) error {
+		ran = append(ran, "b")
+		_, ok := ctx.Deadline()
+		require.True(t, ok)
+		return xerrors.Errorf("failed")
+	})
+	s.Register("a", func(ctx context.Context) error {
+		ran = append(ran, "a")
+		now = now.Add(10 * time.Minute)
+		return nil
+	})
+
+	s.runTasks(context.Background(), now.Add(time.Hour))
+	require.Equal(t, []string{"b", "a"}, ran)
+
+	done := s.Track("compaction")
+	st := s.Status()
+	require.Len(t, st.Tasks, 3)
+	require.Equal(t, "a", st.Tasks[0].Name)
+	require.Equal(t, 1, st.Tasks[0].Runs)
+	require.Empty(t, st.Tasks[0].LastError)
+	require.Equal(t, "b", st.Tasks[1].Name)
+	require.Equal(t, "failed", st.Tasks[1].LastError)
+	require.Equal(t, "compaction", st.Tasks[2].Name)
+	require.True(t, st.Tasks[2].Running)
+
+	now = now.Add(time.Minute)
+	done(nil)
+	st = s.Status()
+	require.False(t, st.Tasks[2].Running)
+	require.Equal(t, time.Minute, st.Tasks[2].LastDuration)
+}
@@ -0,0 +1,306 @@
+package paramcache
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-paramfetch"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+)
+
+var log = logging.Logger("paramcache")
+
+// DirEnv overrides the parameter directory, also used by the proofs library.
+const DirEnv = "FIL_PROOFS_PARAMETER_CACHE"
+
+// DefaultDir is the parameter directory used when DirEnv isn't set.
+const DefaultDir = "/var/tmp/filecoin-proof-parameters"
+
+// File is a proof parameter file listed in the built-in parameter manifests.
+type File struct {
+	Name       string
+	Cid        string
+	Digest     string
+	SectorSize abi.SectorSize
+}
+
+func Dir() string {
+	if dir := os.Getenv(DirEnv); dir != "" {
+		return dir
+	}
+	return DefaultDir
+}
+
+// Files returns the parameter files needed for proofs of the given sector
+// sizes, or all files when no sizes are given.
+func Files(sizes []abi.SectorSize) ([]File, error) {
+	var manifest map[string]struct {
+		Cid        string `json:"cid"`
+		Digest     string `json:"digest"`
+		SectorSize uint64 `json:"sector_size"`
+	}
+
+	var out []File
+	for _, b := range [][]byte{build.ParametersJSON(), build.SrsJSON()} {
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			return nil, xerrors.Errorf("parsing parameter manifest: %w", err)
+		}
+		for name, info := range manifest {
+			ssize := abi.SectorSize(info.SectorSize)
+			if !needed(ssize, sizes) {
+				continue
+			}
+			out = append(out, File{
+				Name:       name,
+				Cid:        info.Cid,
+				Digest:     info.Digest,
+				SectorSize: ssize,
+			})
+		}
+		manifest = nil
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].SectorSize != out[j].SectorSize {
+			return out[i].SectorSize < out[j].SectorSize
+		}
+		return out[i].Name < out[j].Name
+	})
+	return out, nil
+}
+
+func needed(ssize abi.SectorSize, sizes []abi.SectorSize) bool {
+	// files without a sector size are used by all proofs
+	if len(sizes) == 0 || ssize == 0 {
+		return true
+	}
+	for _, s := range sizes {
+		if s == ssize {
+			return true
+		}
+	}
+	return false
+}
+
+// Check reports whether the files are present in the parameter directory, and
+// with verify set whether their digests match the manifest.
+func Check(ctx context.Context, files []File, verify bool) []api.ProofParamFile {
+	out := make([]api.ProofParamFile, len(files))
+	for i, f := range files {
+		out[i] = api.ProofParamFile{
+			Name:       f.Name,
+			SectorSize: f.SectorSize,
+		}
+
+		path := filepath.Join(Dir(), f.Name)
+		st, err := os.Stat(path)
+		if err != nil {
+			if !os.IsNotExist(err) {
+				out[i].Error = err.Error()
+			}
+			continue
+		}
+		out[i].Present = true
+		out[i].Size = st.Size()
+
+		if !verify || ctx.Err() != nil {
+			continue
+		}
+		if err := checkDigest(path, f.Digest); err != nil {
+			out[i].Error = err.Error()
+			continue
+		}
+		out[i].Verified = true
+	}
+	return out
+}
+
+// checkDigest compares the blake2b digest of the file the same way paramfetch
+// does.
+func checkDigest(path, digest string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() //nolint:errcheck
+
+	h := blake2b.New512()
+	if _, err := io.Copy(h, f); err != nil {
+		return xerrors.Errorf("reading file: %w", err)
+	}
+
+	sum := hex.EncodeToString(h.Sum(nil)[:16])
+	if sum != digest {
+		return xerrors.Errorf("checksum mismatch, %s != %s", sum, digest)
+	}
+	return nil
+}
+
+// Status reports the parameter files of the given sector sizes and the disk
+// usage of the parameter directory.
+func Status(ctx context.Context, sizes []abi.SectorSize, verify bool) (api.ProofParamsStatus, error) {
+	files, err := Files(sizes)
+	if err != nil {
+		return api.ProofParamsStatus{}, err
+	}
+
+	out := api.ProofParamsStatus{
+		Dir:   Dir(),
+		Files: Check(ctx, files, verify),
+	}
+	if err := ctx.Err(); err != nil {
+		return api.ProofParamsStatus{}, err
+	}
+
+	for _, f := range out.Files {
+		switch {
+		case !f.Present:
+			out.Missing++
+		case f.Error != "":
+			out.Corrupted++
+		}
+	}
+
+	out.DiskUsage, err = diskUsage(out.Dir)
+	if err != nil {
+		return api.ProofParamsStatus{}, err
+	}
+	return out, nil
+}
+
+func diskUsage(dir string) (int64, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, xerrors.Errorf("reading parameter directory: %w", err)
+	}
+
+	var total int64
+	for _, e := range entries {
+		if e.IsDir() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return 0, xerrors.Errorf("stat %s: %w", e.Name(), err)
+		}
+		total += info.Size()
+	}
+	return total, nil
+}
+
+var fetchLk sync.Mutex
+
+// Fetch downloads missing or corrupted parameters of the given sector sizes,
+// from the mirror gateway when one is given.
+func Fetch(ctx context.Context, sizes []abi.SectorSize, mirror string) error {
+	fetchLk.Lock()
+	defer fetchLk.Unlock()
+
+	if mirror != "" {
+		return fetchMirror(ctx, sizes, mirror)
+	}
+
+	if len(sizes) == 0 {
+		// only verification keys and shared files
+		sizes = []abi.SectorSize{0}
+	}
+	for _, ssize := range sizes {
+		if err := paramfetch.GetParams(ctx, build.ParametersJSON(), build.SrsJSON(), uint64(ssize)); err != nil {
+			return xerrors.Errorf("fetching parameters for %s sectors: %w", ssize.ShortString(), err)
+		}
+	}
+	return nil
+}
+
+// fetchMirror downloads the files paramfetch would fetch for the sector sizes
+// from the mirror, which paramfetch only reads from the environment: all
+// verification keys and shared files, and the .params files of the sizes.
+func fetchMirror(ctx context.Context, sizes []abi.SectorSize, mirror string) error {
+	if !strings.HasSuffix(mirror, "/") {
+		mirror += "/"
+	}
+
+	all, err := Files(nil)
+	if err != nil {
+		return err
+	}
+	var files []File
+	for _, f := range all {
+		if strings.HasSuffix(f.Name, ".params") && (len(sizes) == 0 || !needed(f.SectorSize, sizes)) {
+			continue
+		}
+		files = append(files, f)
+	}
+
+	if err := os.MkdirAll(Dir(), 0755); err != nil {
+		return xerrors.Errorf("creating parameter directory: %w", err)
+	}
+	for i, st := range Check(ctx, files, true) {
+		if st.Verified {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fetchFile(ctx, mirror, files[i]); err != nil {
+			return xerrors.Errorf("fetching %s: %w", files[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// fetchFile downloads a file into a temporary file, which replaces the file in
+// the parameter directory once its digest is checked.
+func fetchFile(ctx context.Context, mirror string, f File) error {
+	log.Infow("fetching parameter file", "file", f.Name, "mirror", mirror)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mirror+f.Cid, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+	if resp.StatusCode != http.StatusOK {
+		return xerrors.Errorf("mirror returned %s", resp.Status)
+	}
+
+	tmp, err := os.CreateTemp(Dir(), f.Name+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) //nolint:errcheck
+
+	_, err = io.Copy(tmp, resp.Body)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return xerrors.Errorf("writing file: %w", err)
+	}
+
+	if err := checkDigest(tmp.Name(), f.Digest); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(Dir(), f.Name))
+}
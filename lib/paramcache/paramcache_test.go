@@ -0,0 +1,108 @@
+// stm: #unit
+package paramcache
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func TestFiles(t *testing.T) {
+	all, err := Files(nil)
+	require.NoError(t, err)
+
+	files, err := Files([]abi.SectorSize{2048})
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	require.Less(t, len(files), len(all))
+
+	var srs int
+	for _, f := range files {
+		require.Contains(t, []abi.SectorSize{0, 2048}, f.SectorSize)
+		if f.SectorSize == 0 {
+			srs++
+		}
+	}
+	require.Equal(t, 1, srs)
+	// shared files are sorted first
+	require.Equal(t, abi.SectorSize(0), files[0].SectorSize)
+}
+
+func TestStatus(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+
+	files, err := Files([]abi.SectorSize{2048})
+	require.NoError(t, err)
+
+	var vk File
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, ".vk") {
+			vk = f
+			break
+		}
+	}
+	require.NotEmpty(t, vk.Name)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, vk.Name), []byte("not a key"), 0644))
+
+	st, err := Status(context.Background(), []abi.SectorSize{2048}, false)
+	require.NoError(t, err)
+	require.Equal(t, dir, st.Dir)
+	require.Equal(t, int64(9), st.DiskUsage)
+	require.Equal(t, len(files)-1, st.Missing)
+	require.Zero(t, st.Corrupted)
+
+	st, err = Status(context.Background(), []abi.SectorSize{2048}, true)
+	require.NoError(t, err)
+	require.Equal(t, 1, st.Corrupted)
+	for _, f := range st.Files {
+		if f.Name == vk.Name {
+			require.True(t, f.Present)
+			require.False(t, f.Verified)
+			require.Contains(t, f.Error, "checksum mismatch")
+		}
+	}
+}
+
+func TestFetchMirror(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv(DirEnv, dir)
+	t.Setenv("IPFS_GATEWAY", "https://gateway.example/ipfs/")
+
+	files, err := Files([]abi.SectorSize{2048})
+	require.NoError(t, err)
+	var vk File
+	for _, f := range files {
+		if strings.HasSuffix(f.Name, ".vk") {
+			vk = f
+			break
+		}
+	}
+	require.NotEmpty(t, vk.Name)
+
+	// the mirror serves a file whose digest doesn't match for every cid
+	var requested []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		_, _ = w.Write([]byte("not a key"))
+	}))
+	defer srv.Close()
+
+	err = Fetch(context.Background(), []abi.SectorSize{2048}, srv.URL+"/ipfs")
+	require.ErrorContains(t, err, "checksum mismatch")
+	require.Equal(t, []string{"/ipfs/" + files[0].Cid}, requested)
+	require.Equal(t, "https://gateway.example/ipfs/", os.Getenv("IPFS_GATEWAY"))
+
+	// nothing is left in the parameter directory
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
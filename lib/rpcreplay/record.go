@@ -0,0 +1,352 @@
+// Package rpcreplay records the JSON-RPC calls served by a node, and replays
+// them against another node, comparing the responses and latencies.
+//
+// Recordings are files of JSON lines, one Entry per HTTP request. They are
+// sanitized: API tokens and remote addresses are never recorded, and only the
+// calls of the allowed methods are, never wallet or auth calls. Calls changing
+// the state of the node, such as sending transactions, and filter calls, whose
+// results depend on the calls before them, are neither recorded nor replayed.
+// Calls made over websockets aren't recorded.
+package rpcreplay
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("rpcreplay")
+
+// deniedPrefixes are the prefixes of the methods which are never recorded nor
+// replayed.
+var deniedPrefixes = []string{
+	// their params or results can hold keys or tokens
+	"Filecoin.Wallet", "Filecoin.Auth", "Filecoin.EthSign",
+	// they change the state of the node
+	"eth_send", "Filecoin.EthSend", "Filecoin.MpoolPush",
+	// filters and subscriptions depend on the calls installing them
+	"eth_newFilter", "eth_newBlockFilter", "eth_newPendingTransactionFilter", "eth_getFilter", "eth_uninstallFilter",
+	"eth_subscribe", "eth_unsubscribe", "filecoin_subscribe",
+	"Filecoin.EthNew", "Filecoin.EthGetFilter", "Filecoin.EthUninstallFilter", "Filecoin.EthSubscribe", "Filecoin.EthUnsubscribe",
+}
+
+// maxRequestSize is the size of the largest request recorded, larger ones are
+// served without being recorded.
+const maxRequestSize = 1 << 20
+
+// denied returns whether one of the methods is never recorded nor replayed.
+func denied(methods []string) bool {
+	for _, m := range methods {
+		for _, p := range deniedPrefixes {
+			if strings.HasPrefix(m, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Entry is a recorded HTTP request, holding a call or a batch of calls.
+type Entry struct {
+	Time time.Time
+	// Path is the path of the RPC endpoint, e.g. /rpc/v1
+	Path string
+	// Methods are the methods called, several for a batch
+	Methods []string
+
+	Request  json.RawMessage
+	Response json.RawMessage
+	// Truncated is set when the response was too large to be recorded, in
+	// which case it isn't compared on replay
+	Truncated bool `json:",omitempty"`
+	Status    int
+	Duration  time.Duration
+}
+
+// RecorderOptions configure a Recorder.
+type RecorderOptions struct {
+	// Dir is the directory recordings are written to.
+	Dir string
+	// Methods are the prefixes of the methods recorded, e.g. "eth_".
+	Methods []string
+	// SampleRate is the fraction of the requests recorded, from 0 to 1.
+	SampleRate float64
+	// MaxFileSize is the size at which a recording file is rotated.
+	MaxFileSize int64
+	// MaxFiles is the number of recording files kept, the oldest are removed.
+	MaxFiles int
+	// MaxResponseSize is the size above which responses are truncated.
+	MaxResponseSize int
+}
+
+// Recorder records the calls served by an RPC handler.
+type Recorder struct {
+	opts RecorderOptions
+
+	entries chan *Entry
+	dropped atomic.Int64
+	done    chan struct{}
+
+	// file is only accessed by the writer goroutine
+	file *os.File
+	bw   *bufio.Writer
+	size int64
+}
+
+// NewRecorder creates a recorder writing to the directory of the options.
+func NewRecorder(opts RecorderOptions) (*Recorder, error) {
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, xerrors.Errorf("creating the recordings directory: %w", err)
+	}
+	if opts.SampleRate <= 0 || opts.SampleRate > 1 {
+		return nil, xerrors.Errorf("sample rate must be in (0, 1], got %f", opts.SampleRate)
+	}
+
+	r := &Recorder{
+		opts:    opts,
+		entries: make(chan *Entry, 1024),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r, nil
+}
+
+// Close flushes the recorded entries and closes the recording.
+func (r *Recorder) Close() error {
+	close(r.entries)
+	<-r.done
+	if n := r.dropped.Load(); n > 0 {
+		log.Warnw("requests weren't recorded as the recorder couldn't keep up", "dropped", n)
+	}
+	return nil
+}
+
+// Handler records the calls served by next.
+func (r *Recorder) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodPost || rand.Float64() >= r.opts.SampleRate {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(req.Body, maxRequestSize+1))
+		req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(body), req.Body), Closer: req.Body}
+		if err != nil || len(body) > maxRequestSize {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		methods, ok := callMethods(body)
+		if !ok || !r.allowed(methods) {
+			next.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		tw := &teeWriter{ResponseWriter: w, max: r.opts.MaxResponseSize, status: http.StatusOK}
+		next.ServeHTTP(tw, req)
+
+		e := &Entry{
+			Time:      start,
+			Path:      req.URL.Path,
+			Methods:   methods,
+			Request:   json.RawMessage(body),
+			Truncated: tw.truncated,
+			Status:    tw.status,
+			Duration:  time.Since(start),
+		}
+		if !tw.truncated && json.Valid(tw.buf.Bytes()) {
+			e.Response = json.RawMessage(tw.buf.Bytes())
+		} else {
+			e.Truncated = true
+		}
+
+		select {
+		case r.entries <- e:
+		default:
+			r.dropped.Add(1)
+		}
+	})
+}
+
+func (r *Recorder) allowed(methods []string) bool {
+	if denied(methods) {
+		return false
+	}
+	for _, m := range methods {
+		var ok bool
+		for _, p := range r.opts.Methods {
+			if strings.HasPrefix(m, p) {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *Recorder) run() {
+	defer close(r.done)
+
+	for e := range r.entries {
+		if err := r.write(e); err != nil {
+			log.Errorw("writing rpc recording", "error", err)
+		}
+	}
+	if err := r.closeFile(); err != nil {
+		log.Errorw("closing rpc recording", "error", err)
+	}
+}
+
+func (r *Recorder) write(e *Entry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if r.file == nil || (r.opts.MaxFileSize > 0 && r.size+int64(len(b)) > r.opts.MaxFileSize) {
+		if err := r.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := r.bw.Write(append(b, '\n'))
+	r.size += int64(n)
+	if err != nil {
+		return err
+	}
+	// keep the file readable while the node runs, unless entries are queued
+	if len(r.entries) == 0 {
+		return r.bw.Flush()
+	}
+	return nil
+}
+
+func (r *Recorder) rotate() error {
+	if err := r.closeFile(); err != nil {
+		return err
+	}
+
+	name := filepath.Join(r.opts.Dir, fmt.Sprintf("rpc-%s.jsonl", time.Now().UTC().Format("20060102T150405.000000000")))
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0600)
+	if err != nil {
+		return xerrors.Errorf("creating recording file: %w", err)
+	}
+	r.file, r.bw, r.size = f, bufio.NewWriter(f), 0
+
+	if r.opts.MaxFiles > 0 {
+		files, err := Files(r.opts.Dir)
+		if err != nil {
+			return err
+		}
+		for len(files) > r.opts.MaxFiles {
+			if err := os.Remove(files[0]); err != nil {
+				return xerrors.Errorf("removing old recording: %w", err)
+			}
+			files = files[1:]
+		}
+	}
+	return nil
+}
+
+func (r *Recorder) closeFile() error {
+	if r.file == nil {
+		return nil
+	}
+	if err := r.bw.Flush(); err != nil {
+		return err
+	}
+	err := r.file.Close()
+	r.file = nil
+	return err
+}
+
+// Files returns the recording files of a directory, oldest first.
+func Files(dir string) ([]string, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "rpc-*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// callMethods returns the methods called by a request, which can be a batch.
+func callMethods(body []byte) ([]string, bool) {
+	type call struct {
+		Method string `json:"method"`
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []call
+		if err := json.Unmarshal(body, &calls); err != nil || len(calls) == 0 {
+			return nil, false
+		}
+		methods := make([]string, len(calls))
+		for i, c := range calls {
+			methods[i] = c.Method
+		}
+		return methods, true
+	}
+
+	var c call
+	if err := json.Unmarshal(body, &c); err != nil {
+		return nil, false
+	}
+	return []string{c.Method}, true
+}
+
+// readCloser reads the start of a request body already read, then the rest.
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
+// teeWriter keeps a copy of the response, up to max bytes.
+type teeWriter struct {
+	http.ResponseWriter
+	buf       bytes.Buffer
+	max       int
+	truncated bool
+	status    int
+}
+
+func (w *teeWriter) WriteHeader(code int) {
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *teeWriter) Write(b []byte) (int, error) {
+	if !w.truncated {
+		if w.max > 0 && w.buf.Len()+len(b) > w.max {
+			w.truncated = true
+			w.buf.Reset()
+		} else {
+			w.buf.Write(b)
+		}
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *teeWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,309 @@
+package rpcreplay
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+)
+
+// maxEntrySize is the size of the largest entry a recording can hold.
+const maxEntrySize = 64 << 20
+
+// Replayer replays recorded calls against a node, in the order they were
+// recorded, and compares the responses and latencies to the recorded ones.
+//
+// Responses only compare equal when the node replayed against is at the same
+// chain state as the recorded one, e.g. both synced to a checkpoint; methods
+// whose results depend on the head can be ignored.
+type Replayer struct {
+	// Target is the base URL of the node, e.g. http://127.0.0.1:1234
+	Target string
+	// Header is sent with every request, e.g. for an Authorization token.
+	Header http.Header
+	// IgnoreMethods are the prefixes of the methods which aren't replayed.
+	IgnoreMethods []string
+	// LatencyFactor is the ratio of the replayed to recorded median latency
+	// of a method above which it's reported as regressed; 0 disables it.
+	LatencyFactor float64
+	// MaxMismatches is the number of mismatches whose details are reported.
+	MaxMismatches int
+
+	Client *http.Client
+}
+
+// Report is the result of a replay.
+type Report struct {
+	Calls      int
+	Matched    int
+	Mismatched int
+	// Skipped calls are ignored, or have no recorded response to compare to.
+	Skipped int
+	// Failed calls couldn't be sent to the target.
+	Failed int
+
+	Mismatches []Mismatch
+	Latencies  []MethodLatency
+}
+
+// Mismatch is a call whose replayed response differs from the recorded one.
+type Mismatch struct {
+	Methods []string
+	Request json.RawMessage
+	// Path is the location of the first difference in the response, e.g.
+	// result.transactions[3].
+	Path     string
+	Recorded string
+	Replayed string
+}
+
+// MethodLatency compares the recorded and replayed latencies of a method.
+// Batches are reported under their methods joined with commas.
+type MethodLatency struct {
+	Method string
+	Calls  int
+
+	RecordedP50, RecordedP95 time.Duration
+	ReplayedP50, ReplayedP95 time.Duration
+
+	Regressed bool
+}
+
+// Passed returns whether the replay found no failures, mismatches or latency
+// regressions.
+func (r *Report) Passed() bool {
+	if r.Mismatched > 0 || r.Failed > 0 {
+		return false
+	}
+	for _, l := range r.Latencies {
+		if l.Regressed {
+			return false
+		}
+	}
+	return true
+}
+
+// Replay replays the entries of a recording.
+func (rp *Replayer) Replay(ctx context.Context, recording io.Reader) (*Report, error) {
+	client := rp.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	rep := new(Report)
+	recorded := map[string][]time.Duration{}
+	replayed := map[string][]time.Duration{}
+
+	sc := bufio.NewScanner(recording)
+	sc.Buffer(nil, maxEntrySize)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var e Entry
+		if err := json.Unmarshal(sc.Bytes(), &e); err != nil {
+			return nil, xerrors.Errorf("parsing recording line %d: %w", line, err)
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		rep.Calls++
+		// recordings made before methods were denied can still hold them
+		if e.Truncated || denied(e.Methods) || rp.ignored(e.Methods) {
+			rep.Skipped++
+			continue
+		}
+
+		status, resp, took, err := rp.send(ctx, client, &e)
+		if err != nil {
+			log.Warnw("replaying call failed", "methods", e.Methods, "error", err)
+			rep.Failed++
+			continue
+		}
+
+		key := strings.Join(e.Methods, ",")
+		recorded[key] = append(recorded[key], e.Duration)
+		replayed[key] = append(replayed[key], took)
+
+		path, want, got, equal := compare(e.Status, e.Response, status, resp)
+		if equal {
+			rep.Matched++
+			continue
+		}
+		rep.Mismatched++
+		if len(rep.Mismatches) < rp.MaxMismatches {
+			rep.Mismatches = append(rep.Mismatches, Mismatch{
+				Methods:  e.Methods,
+				Request:  e.Request,
+				Path:     path,
+				Recorded: want,
+				Replayed: got,
+			})
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, xerrors.Errorf("reading recording: %w", err)
+	}
+
+	for m, rec := range recorded {
+		l := MethodLatency{
+			Method:      m,
+			Calls:       len(rec),
+			RecordedP50: percentile(rec, 50),
+			RecordedP95: percentile(rec, 95),
+			ReplayedP50: percentile(replayed[m], 50),
+			ReplayedP95: percentile(replayed[m], 95),
+		}
+		l.Regressed = rp.LatencyFactor > 0 && float64(l.ReplayedP50) > float64(l.RecordedP50)*rp.LatencyFactor
+		rep.Latencies = append(rep.Latencies, l)
+	}
+	sort.Slice(rep.Latencies, func(i, j int) bool {
+		return rep.Latencies[i].Method < rep.Latencies[j].Method
+	})
+
+	return rep, nil
+}
+
+func (rp *Replayer) ignored(methods []string) bool {
+	for _, m := range methods {
+		for _, p := range rp.IgnoreMethods {
+			if strings.HasPrefix(m, p) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (rp *Replayer) send(ctx context.Context, client *http.Client, e *Entry) (int, []byte, time.Duration, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(rp.Target, "/")+e.Path, bytes.NewReader(e.Request))
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	for k, v := range rp.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, 0, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, 0, xerrors.Errorf("reading response: %w", err)
+	}
+	return resp.StatusCode, body, time.Since(start), nil
+}
+
+// compare compares a recorded response to a replayed one, ignoring the ids
+// and versions of the JSON-RPC envelopes. When they differ it returns the path
+// of the first difference, and the values there.
+func compare(wantStatus int, want []byte, gotStatus int, got []byte) (string, string, string, bool) {
+	if wantStatus != gotStatus {
+		return "status", fmt.Sprint(wantStatus), fmt.Sprint(gotStatus), false
+	}
+
+	var w, g interface{}
+	if err := json.Unmarshal(want, &w); err != nil {
+		return "", string(want), string(got), bytes.Equal(want, got)
+	}
+	if err := json.Unmarshal(got, &g); err != nil {
+		return "", string(want), string(got), false
+	}
+
+	return diff("", normalize(w), normalize(g))
+}
+
+// normalize drops the id and jsonrpc fields of a response or batch.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		delete(v, "id")
+		delete(v, "jsonrpc")
+	case []interface{}:
+		for _, r := range v {
+			normalize(r)
+		}
+	}
+	return v
+}
+
+func diff(path string, w, g interface{}) (string, string, string, bool) {
+	switch w := w.(type) {
+	case map[string]interface{}:
+		gm, ok := g.(map[string]interface{})
+		if !ok {
+			break
+		}
+		keys := make([]string, 0, len(w)+len(gm))
+		for k := range w {
+			keys = append(keys, k)
+		}
+		for k := range gm {
+			if _, ok := w[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			p := k
+			if path != "" {
+				p = path + "." + k
+			}
+			if dp, dw, dg, eq := diff(p, w[k], gm[k]); !eq {
+				return dp, dw, dg, false
+			}
+		}
+		return "", "", "", true
+	case []interface{}:
+		ga, ok := g.([]interface{})
+		if !ok || len(ga) != len(w) {
+			break
+		}
+		for i := range w {
+			if dp, dw, dg, eq := diff(fmt.Sprintf("%s[%d]", path, i), w[i], ga[i]); !eq {
+				return dp, dw, dg, false
+			}
+		}
+		return "", "", "", true
+	}
+
+	if reflect.DeepEqual(w, g) {
+		return "", "", "", true
+	}
+	return path, marshal(w), marshal(g), false
+}
+
+func marshal(v interface{}) string {
+	if v == nil {
+		return "<missing>"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(b)
+}
+
+func percentile(ds []time.Duration, p int) time.Duration {
+	if len(ds) == 0 {
+		return 0
+	}
+	s := append([]time.Duration(nil), ds...)
+	sort.Slice(s, func(i, j int) bool { return s[i] < s[j] })
+	return s[(len(s)-1)*p/100]
+}
@@ -0,0 +1,228 @@
+package rpcreplay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// echoServer answers calls with their method and first param as result.
+func echoServer(t *testing.T, result func(method string, param json.RawMessage) interface{}) http.Handler {
+	type call struct {
+		ID     int               `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	answer := func(c call) map[string]interface{} {
+		var p json.RawMessage
+		if len(c.Params) > 0 {
+			p = c.Params[0]
+		}
+		return map[string]interface{}{"jsonrpc": "2.0", "id": c.ID, "result": result(c.Method, p)}
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		var out interface{}
+		if body[0] == '[' {
+			var calls []call
+			require.NoError(t, json.Unmarshal(body, &calls))
+			var res []interface{}
+			for _, c := range calls {
+				res = append(res, answer(c))
+			}
+			out = res
+		} else {
+			var c call
+			require.NoError(t, json.Unmarshal(body, &c))
+			out = answer(c)
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(out))
+	})
+}
+
+func post(t *testing.T, url, body string) {
+	resp, err := http.Post(url+"/rpc/v1", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func record(t *testing.T, dir string) []byte {
+	rec, err := NewRecorder(RecorderOptions{
+		Dir:        dir,
+		Methods:    []string{"eth_", "Filecoin.Eth", "Filecoin.Wallet"},
+		SampleRate: 1,
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(rec.Handler(echoServer(t, func(method string, param json.RawMessage) interface{} {
+		return []interface{}{method, param}
+	})))
+	defer srv.Close()
+
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":1,"method":"eth_getBalance","params":["0x01","latest"]}`)
+	post(t, srv.URL, `[{"jsonrpc":"2.0","id":2,"method":"eth_chainId","params":[]},{"jsonrpc":"2.0","id":3,"method":"Filecoin.EthBlockNumber","params":[]}]`)
+	// not allowed, or never recorded
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":4,"method":"Filecoin.ChainHead","params":[]}`)
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":5,"method":"Filecoin.WalletSign","params":["key"]}`)
+	post(t, srv.URL, `[{"jsonrpc":"2.0","id":6,"method":"eth_chainId","params":[]},{"jsonrpc":"2.0","id":7,"method":"Filecoin.WalletList","params":[]}]`)
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":8,"method":"eth_getCode","params":["0x02"]}`)
+	// state changing and filter calls are never recorded, nor requests too large
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":9,"method":"eth_sendRawTransaction","params":["0x03"]}`)
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":10,"method":"eth_newFilter","params":[{}]}`)
+	post(t, srv.URL, `{"jsonrpc":"2.0","id":11,"method":"Filecoin.EthGetFilterChanges","params":["0x04"]}`)
+	post(t, srv.URL, fmt.Sprintf(`{"jsonrpc":"2.0","id":12,"method":"eth_getCode","params":["0x%s"]}`, strings.Repeat("05", maxRequestSize)))
+
+	require.NoError(t, rec.Close())
+
+	files, err := Files(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	b, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	return b
+}
+
+func TestRecord(t *testing.T) {
+	b := record(t, t.TempDir())
+	require.NotContains(t, string(b), "Wallet")
+	require.NotContains(t, string(b), "ChainHead")
+	require.NotContains(t, string(b), "Filter")
+	require.NotContains(t, string(b), "sendRawTransaction")
+
+	var entries []Entry
+	for _, l := range bytes.Split(bytes.TrimSpace(b), []byte("\n")) {
+		var e Entry
+		require.NoError(t, json.Unmarshal(l, &e))
+		entries = append(entries, e)
+	}
+	require.Len(t, entries, 3)
+	require.Equal(t, []string{"eth_getBalance"}, entries[0].Methods)
+	require.Equal(t, []string{"eth_chainId", "Filecoin.EthBlockNumber"}, entries[1].Methods)
+	require.Equal(t, []string{"eth_getCode"}, entries[2].Methods)
+	require.Equal(t, "/rpc/v1", entries[0].Path)
+	require.Equal(t, http.StatusOK, entries[0].Status)
+	require.Contains(t, string(entries[0].Response), `"0x01"`)
+}
+
+func TestRecordRotate(t *testing.T) {
+	dir := t.TempDir()
+	rec, err := NewRecorder(RecorderOptions{
+		Dir:         dir,
+		Methods:     []string{"eth_"},
+		SampleRate:  1,
+		MaxFileSize: 1,
+		MaxFiles:    2,
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(rec.Handler(echoServer(t, func(string, json.RawMessage) interface{} { return 1 })))
+	defer srv.Close()
+	for i := 0; i < 5; i++ {
+		post(t, srv.URL, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"eth_chainId","params":[]}`, i))
+	}
+	require.NoError(t, rec.Close())
+
+	files, err := Files(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+}
+
+func TestReplay(t *testing.T) {
+	recording := record(t, t.TempDir())
+
+	replay := func(result func(string, json.RawMessage) interface{}, ignore ...string) *Report {
+		srv := httptest.NewServer(echoServer(t, result))
+		defer srv.Close()
+
+		rp := &Replayer{Target: srv.URL, IgnoreMethods: ignore, MaxMismatches: 10}
+		rep, err := rp.Replay(context.Background(), bytes.NewReader(recording))
+		require.NoError(t, err)
+		return rep
+	}
+
+	// the same results match, whatever the ids
+	rep := replay(func(method string, param json.RawMessage) interface{} {
+		return []interface{}{method, param}
+	})
+	require.True(t, rep.Passed())
+	require.Equal(t, 3, rep.Calls)
+	require.Equal(t, 3, rep.Matched)
+	require.Len(t, rep.Latencies, 3)
+	require.Equal(t, "eth_chainId,Filecoin.EthBlockNumber", rep.Latencies[0].Method)
+
+	// a changed result is reported with its path
+	rep = replay(func(method string, param json.RawMessage) interface{} {
+		if method == "Filecoin.EthBlockNumber" {
+			return []interface{}{method, "0x10"}
+		}
+		return []interface{}{method, param}
+	})
+	require.False(t, rep.Passed())
+	require.Equal(t, 2, rep.Matched)
+	require.Equal(t, 1, rep.Mismatched)
+	require.Equal(t, "[1].result[1]", rep.Mismatches[0].Path)
+	require.Equal(t, "<missing>", rep.Mismatches[0].Recorded)
+	require.Equal(t, `"0x10"`, rep.Mismatches[0].Replayed)
+
+	// unless the method is ignored
+	rep = replay(func(method string, param json.RawMessage) interface{} {
+		return []interface{}{method, "0x10"}
+	}, "Filecoin.EthBlockNumber", "eth_get")
+	require.True(t, rep.Passed())
+	require.Equal(t, 3, rep.Skipped)
+}
+
+func TestReplayDenied(t *testing.T) {
+	entry, err := json.Marshal(Entry{
+		Path:     "/rpc/v1",
+		Methods:  []string{"eth_sendRawTransaction"},
+		Request:  json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"eth_sendRawTransaction","params":["0x01"]}`),
+		Response: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":"0x02"}`),
+		Status:   http.StatusOK,
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("denied call replayed")
+	}))
+	defer srv.Close()
+
+	rp := &Replayer{Target: srv.URL}
+	rep, err := rp.Replay(context.Background(), bytes.NewReader(entry))
+	require.NoError(t, err)
+	require.Equal(t, 1, rep.Skipped)
+}
+
+func TestLatencyRegression(t *testing.T) {
+	entry, err := json.Marshal(Entry{
+		Path:     "/rpc/v1",
+		Methods:  []string{"eth_chainId"},
+		Request:  json.RawMessage(`{"jsonrpc":"2.0","id":1,"method":"eth_chainId","params":[]}`),
+		Response: json.RawMessage(`{"jsonrpc":"2.0","id":1,"result":1}`),
+		Status:   http.StatusOK,
+		Duration: 1,
+	})
+	require.NoError(t, err)
+
+	srv := httptest.NewServer(echoServer(t, func(string, json.RawMessage) interface{} { return 1 }))
+	defer srv.Close()
+
+	rp := &Replayer{Target: srv.URL, LatencyFactor: 2}
+	rep, err := rp.Replay(context.Background(), bytes.NewReader(entry))
+	require.NoError(t, err)
+	require.Equal(t, 1, rep.Matched)
+	require.True(t, rep.Latencies[0].Regressed)
+	require.False(t, rep.Passed())
+}
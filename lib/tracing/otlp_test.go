@@ -0,0 +1,64 @@
+// stm: #unit
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/sdk/resource"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestOTLPExporter(t *testing.T) {
+	var got otlpTraces
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		got = otlpTraces{}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&got))
+	}))
+	defer srv.Close()
+
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSyncer(NewOTLPExporter(srv.URL)),
+		tracesdk.WithResource(resource.NewWithAttributes("", attribute.String("service.name", "test"))),
+	)
+	tracer := tp.Tracer("test")
+
+	start := time.Unix(100, 5)
+	ctx, parent := tracer.Start(context.Background(), "parent", trace.WithTimestamp(start))
+	_, child := tracer.Start(ctx, "child", trace.WithAttributes(
+		attribute.Int64("height", 10),
+		attribute.Bool("implicit", true),
+	))
+	child.SetStatus(codes.Error, "failed")
+	child.End()
+
+	require.Len(t, got.ResourceSpans, 1)
+	rs := got.ResourceSpans[0]
+	require.Equal(t, "service.name", rs.Resource.Attributes[0].Key)
+	require.Equal(t, "test", *rs.Resource.Attributes[0].Value.StringValue)
+	require.Len(t, rs.ScopeSpans, 1)
+	require.Equal(t, "test", rs.ScopeSpans[0].Scope.Name)
+
+	span := rs.ScopeSpans[0].Spans[0]
+	require.Equal(t, "child", span.Name)
+	require.Len(t, span.TraceID, 32)
+	require.Equal(t, parent.SpanContext().SpanID().String(), span.ParentSpanID)
+	require.Equal(t, 1, span.Kind)
+	require.Equal(t, "10", *span.Attributes[0].Value.IntValue)
+	require.True(t, *span.Attributes[1].Value.BoolValue)
+	require.Equal(t, otlpStatus{Code: 2, Message: "failed"}, span.Status)
+
+	parent.End()
+	span = got.ResourceSpans[0].ScopeSpans[0].Spans[0]
+	require.Empty(t, span.ParentSpanID)
+	require.Equal(t, "100000000005", span.StartTimeUnixNano)
+}
@@ -0,0 +1,309 @@
+// Package webhook delivers node events to HTTP endpoints as signed JSON POSTs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+)
+
+var log = logging.Logger("webhook")
+
+// EventType identifies the kind of event a webhook is called for.
+type EventType string
+
+const (
+	// EventFilterMatch is sent for every event matching a saved query.
+	EventFilterMatch EventType = "filter_match"
+	// EventSectorState is sent when a sector changes state in the sealing pipeline.
+	EventSectorState EventType = "sector_state"
+	// EventAlertRaised is sent when an alert is raised.
+	EventAlertRaised EventType = "alert_raised"
+	// EventDealActivated is sent when a storage deal is activated on chain.
+	EventDealActivated EventType = "deal_activated"
+)
+
+const (
+	// SignatureHeader carries the hex HMAC-SHA256 of the request body, keyed
+	// with the endpoint secret, as "sha256=<hex>".
+	SignatureHeader = "X-Lotus-Signature"
+	EventHeader     = "X-Lotus-Event"
+	DeliveryHeader  = "X-Lotus-Delivery"
+)
+
+// Endpoint is a webhook receiver.
+type Endpoint struct {
+	URL string
+	// Secret signs the payloads when set.
+	Secret string
+	// Events the endpoint is called for, all events when empty.
+	Events []EventType
+
+	// MaxAttempts is the number of delivery attempts of a payload.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed attempt, it doubles
+	// after each further failure, up to MaxBackoff.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// Timeout of a single delivery attempt.
+	Timeout time.Duration
+	// QueueSize is the number of undelivered payloads kept, new payloads are
+	// dropped when the queue is full.
+	QueueSize int
+}
+
+// Defaults of the Endpoint fields left zero.
+const (
+	DefaultMaxAttempts    = 5
+	DefaultInitialBackoff = time.Second
+	DefaultMaxBackoff     = time.Minute
+	DefaultTimeout        = 30 * time.Second
+	DefaultQueueSize      = 256
+)
+
+// Payload is the body POSTed to the endpoints.
+type Payload struct {
+	ID    string
+	Event EventType
+	Time  time.Time
+	Data  json.RawMessage
+}
+
+// Dispatcher queues events for the endpoints subscribed to them, and delivers
+// them in the background.
+type Dispatcher struct {
+	endpoints []*endpoint
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+type endpoint struct {
+	Endpoint
+
+	client *http.Client
+	events map[EventType]struct{}
+	queue  chan *delivery
+}
+
+type delivery struct {
+	payload Payload
+	body    []byte
+}
+
+// New creates a dispatcher for the endpoints, delivery starts with Start.
+func New(endpoints []Endpoint) (*Dispatcher, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	d := &Dispatcher{
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	for _, e := range endpoints {
+		if e.URL == "" {
+			cancel()
+			return nil, xerrors.Errorf("webhook endpoint without URL")
+		}
+		if e.MaxAttempts <= 0 {
+			e.MaxAttempts = DefaultMaxAttempts
+		}
+		if e.InitialBackoff <= 0 {
+			e.InitialBackoff = DefaultInitialBackoff
+		}
+		if e.MaxBackoff <= 0 {
+			e.MaxBackoff = DefaultMaxBackoff
+		}
+		if e.Timeout <= 0 {
+			e.Timeout = DefaultTimeout
+		}
+		if e.QueueSize <= 0 {
+			e.QueueSize = DefaultQueueSize
+		}
+
+		ep := &endpoint{
+			Endpoint: e,
+			client:   &http.Client{Timeout: e.Timeout},
+			queue:    make(chan *delivery, e.QueueSize),
+		}
+		if len(e.Events) > 0 {
+			ep.events = map[EventType]struct{}{}
+			for _, et := range e.Events {
+				ep.events[et] = struct{}{}
+			}
+		}
+		d.endpoints = append(d.endpoints, ep)
+	}
+
+	return d, nil
+}
+
+// Start starts delivering queued payloads.
+func (d *Dispatcher) Start() {
+	for _, ep := range d.endpoints {
+		d.wg.Add(1)
+		go d.run(ep)
+	}
+}
+
+// Close stops delivery, payloads still queued are dropped.
+func (d *Dispatcher) Close() {
+	d.cancel()
+	d.wg.Wait()
+}
+
+// Subscribed checks whether any endpoint is called for the event, so that
+// callers can skip building payloads nobody receives.
+func (d *Dispatcher) Subscribed(et EventType) bool {
+	if d == nil {
+		return false
+	}
+	for _, ep := range d.endpoints {
+		if ep.subscribed(et) {
+			return true
+		}
+	}
+	return false
+}
+
+// Notify queues the event for the endpoints subscribed to it. It never blocks,
+// the event is dropped for endpoints with a full queue.
+func (d *Dispatcher) Notify(et EventType, data interface{}) {
+	if !d.Subscribed(et) {
+		return
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		log.Errorw("marshaling webhook data", "event", et, "error", err)
+		return
+	}
+
+	p := Payload{
+		ID:    uuid.New().String(),
+		Event: et,
+		Time:  time.Now(),
+		Data:  raw,
+	}
+	body, err := json.Marshal(p)
+	if err != nil {
+		log.Errorw("marshaling webhook payload", "event", et, "error", err)
+		return
+	}
+
+	for _, ep := range d.endpoints {
+		if !ep.subscribed(et) {
+			continue
+		}
+		select {
+		case ep.queue <- &delivery{payload: p, body: body}:
+		default:
+			log.Warnw("webhook queue full, dropping event", "url", ep.URL, "event", et, "id", p.ID)
+		}
+	}
+}
+
+func (ep *endpoint) subscribed(et EventType) bool {
+	if ep.events == nil {
+		return true
+	}
+	_, ok := ep.events[et]
+	return ok
+}
+
+func (d *Dispatcher) run(ep *endpoint) {
+	defer d.wg.Done()
+
+	for {
+		select {
+		case <-d.ctx.Done():
+			return
+		case dl := <-ep.queue:
+			d.deliver(ep, dl)
+		}
+	}
+}
+
+// deliver POSTs the payload until it is accepted, or the attempts run out.
+func (d *Dispatcher) deliver(ep *endpoint, dl *delivery) {
+	backoff := ep.InitialBackoff
+	for attempt := 1; ; attempt++ {
+		retry, err := ep.post(d.ctx, dl)
+		if err == nil {
+			return
+		}
+		if !retry || attempt >= ep.MaxAttempts {
+			log.Errorw("webhook delivery failed", "url", ep.URL, "event", dl.payload.Event, "id", dl.payload.ID, "attempts", attempt, "error", err)
+			return
+		}
+
+		log.Warnw("webhook delivery failed, retrying", "url", ep.URL, "event", dl.payload.Event, "id", dl.payload.ID, "attempt", attempt, "backoff", backoff, "error", err)
+
+		select {
+		case <-d.ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > ep.MaxBackoff {
+			backoff = ep.MaxBackoff
+		}
+	}
+}
+
+// post makes a single delivery attempt, and reports whether a failed attempt
+// is worth retrying.
+func (ep *endpoint) post(ctx context.Context, dl *delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ep.URL, bytes.NewReader(dl.body))
+	if err != nil {
+		return false, xerrors.Errorf("creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(dl.payload.Event))
+	req.Header.Set(DeliveryHeader, dl.payload.ID)
+	if ep.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign([]byte(ep.Secret), dl.body))
+	}
+
+	resp, err := ep.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return true, xerrors.Errorf("webhook returned %s", resp.Status)
+	default:
+		// the receiver rejected the payload, sending it again won't help
+		return false, xerrors.Errorf("webhook returned %s", resp.Status)
+	}
+}
+
+// Sign returns the signature header value of a body.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	_, _ = mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks the signature header value of a body, for receivers written
+// in Go.
+func Verify(secret, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, body)), []byte(signature))
+}
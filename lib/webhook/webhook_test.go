@@ -0,0 +1,137 @@
+package webhook
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type receiver struct {
+	lk       sync.Mutex
+	statuses []int // responses of the first requests, 200 afterwards
+	bodies   [][]byte
+	headers  []http.Header
+	received chan struct{}
+}
+
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	body, _ := io.ReadAll(req.Body)
+
+	r.lk.Lock()
+	status := http.StatusOK
+	if len(r.statuses) > 0 {
+		status, r.statuses = r.statuses[0], r.statuses[1:]
+	}
+	r.bodies = append(r.bodies, body)
+	r.headers = append(r.headers, req.Header.Clone())
+	r.lk.Unlock()
+
+	w.WriteHeader(status)
+	r.received <- struct{}{}
+}
+
+func (r *receiver) wait(t *testing.T, n int) {
+	for i := 0; i < n; i++ {
+		select {
+		case <-r.received:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for webhook")
+		}
+	}
+}
+
+func TestDispatcherRetriesAndSigns(t *testing.T) {
+	rcv := &receiver{
+		statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests},
+		received: make(chan struct{}, 10),
+	}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	d, err := New([]Endpoint{{
+		URL:            srv.URL,
+		Secret:         "s3cret",
+		Events:         []EventType{EventAlertRaised},
+		MaxAttempts:    3,
+		InitialBackoff: time.Millisecond,
+		QueueSize:      4,
+	}})
+	require.NoError(t, err)
+	d.Start()
+	defer d.Close()
+
+	require.True(t, d.Subscribed(EventAlertRaised))
+	require.False(t, d.Subscribed(EventSectorState))
+
+	d.Notify(EventSectorState, "ignored")
+	d.Notify(EventAlertRaised, map[string]string{"System": "test"})
+	rcv.wait(t, 3)
+
+	rcv.lk.Lock()
+	defer rcv.lk.Unlock()
+	require.Len(t, rcv.bodies, 3)
+
+	// retries send the same payload
+	require.Equal(t, rcv.bodies[0], rcv.bodies[2])
+	require.Equal(t, rcv.headers[0].Get(DeliveryHeader), rcv.headers[2].Get(DeliveryHeader))
+
+	var p Payload
+	require.NoError(t, json.Unmarshal(rcv.bodies[2], &p))
+	require.Equal(t, EventAlertRaised, p.Event)
+	require.JSONEq(t, `{"System":"test"}`, string(p.Data))
+	require.Equal(t, string(EventAlertRaised), rcv.headers[2].Get(EventHeader))
+	require.True(t, Verify([]byte("s3cret"), rcv.bodies[2], rcv.headers[2].Get(SignatureHeader)))
+	require.False(t, Verify([]byte("other"), rcv.bodies[2], rcv.headers[2].Get(SignatureHeader)))
+}
+
+func TestDispatcherGivesUp(t *testing.T) {
+	rcv := &receiver{
+		statuses: []int{http.StatusBadRequest, http.StatusInternalServerError, http.StatusInternalServerError},
+		received: make(chan struct{}, 10),
+	}
+	srv := httptest.NewServer(rcv)
+	defer srv.Close()
+
+	d, err := New([]Endpoint{{
+		URL:            srv.URL,
+		MaxAttempts:    2,
+		InitialBackoff: time.Millisecond,
+		QueueSize:      4,
+	}})
+	require.NoError(t, err)
+	d.Start()
+	defer d.Close()
+
+	// rejected payloads are not retried
+	d.Notify(EventDealActivated, 1)
+	rcv.wait(t, 1)
+
+	// server errors are retried up to MaxAttempts
+	d.Notify(EventDealActivated, 2)
+	rcv.wait(t, 2)
+
+	// the next payload is delivered
+	d.Notify(EventDealActivated, 3)
+	rcv.wait(t, 1)
+
+	rcv.lk.Lock()
+	defer rcv.lk.Unlock()
+	require.Len(t, rcv.bodies, 4)
+	require.Empty(t, rcv.headers[0].Get(SignatureHeader))
+
+	var p Payload
+	require.NoError(t, json.Unmarshal(rcv.bodies[3], &p))
+	require.Equal(t, "3", string(p.Data))
+}
+
+func TestNilDispatcher(t *testing.T) {
+	var d *Dispatcher
+	require.False(t, d.Subscribed(EventAlertRaised))
+	d.Notify(EventAlertRaised, nil)
+}
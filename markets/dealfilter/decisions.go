@@ -0,0 +1,147 @@
+package dealfilter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+// DecisionLog is the audit log of the decisions of the storage deal filter.
+// It keeps the last decisions, by time.
+type DecisionLog struct {
+	ds  datastore.Batching
+	max int
+
+	lk    sync.Mutex
+	count int
+	last  int64
+}
+
+// NewDecisionLog opens the decision log in ds, keeping at most max decisions.
+func NewDecisionLog(ctx context.Context, ds datastore.Batching, max int) (*DecisionLog, error) {
+	res, err := ds.Query(ctx, query.Query{KeysOnly: true})
+	if err != nil {
+		return nil, xerrors.Errorf("querying deal filter decisions: %w", err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, xerrors.Errorf("reading deal filter decisions: %w", err)
+	}
+
+	return &DecisionLog{ds: ds, max: max, count: len(entries)}, nil
+}
+
+// Wrap returns a filter recording the decisions of f.
+func (l *DecisionLog) Wrap(f dtypes.StorageDealFilter) dtypes.StorageDealFilter {
+	return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		ok, reason, err := f(ctx, deal)
+
+		d := api.DealFilterDecision{
+			Time:                 time.Now(),
+			ProposalCid:          deal.ProposalCid,
+			Client:               deal.Proposal.Client,
+			PieceCid:             deal.Proposal.PieceCID,
+			PieceSize:            deal.Proposal.PieceSize,
+			Verified:             deal.Proposal.VerifiedDeal,
+			StoragePricePerEpoch: deal.Proposal.StoragePricePerEpoch,
+			Accepted:             ok && err == nil,
+			Reason:               reason,
+		}
+		if err != nil {
+			d.Reason = fmt.Sprintf("%s: %s", reason, err)
+		}
+		if rerr := l.Record(ctx, d); rerr != nil {
+			log.Errorf("recording deal filter decision: %s", rerr)
+		}
+
+		return ok, reason, err
+	}
+}
+
+// Record adds a decision to the log, dropping the oldest decisions over the
+// maximum.
+func (l *DecisionLog) Record(ctx context.Context, d api.DealFilterDecision) error {
+	l.lk.Lock()
+	defer l.lk.Unlock()
+
+	// keys are the decision times, kept unique and ordered
+	ts := d.Time.UnixNano()
+	if ts <= l.last {
+		ts = l.last + 1
+	}
+	l.last = ts
+
+	b, err := json.Marshal(d)
+	if err != nil {
+		return err
+	}
+	if err := l.ds.Put(ctx, decisionKey(ts), b); err != nil {
+		return xerrors.Errorf("writing decision: %w", err)
+	}
+	l.count++
+
+	if l.count <= l.max {
+		return nil
+	}
+
+	res, err := l.ds.Query(ctx, query.Query{
+		KeysOnly: true,
+		Orders:   []query.Order{query.OrderByKey{}},
+		Limit:    l.count - l.max,
+	})
+	if err != nil {
+		return xerrors.Errorf("querying oldest decisions: %w", err)
+	}
+	old, err := res.Rest()
+	if err != nil {
+		return xerrors.Errorf("reading oldest decisions: %w", err)
+	}
+	for _, e := range old {
+		if err := l.ds.Delete(ctx, datastore.NewKey(e.Key)); err != nil {
+			return xerrors.Errorf("deleting decision: %w", err)
+		}
+		l.count--
+	}
+	return nil
+}
+
+// List returns the last decisions, newest first.
+func (l *DecisionLog) List(ctx context.Context, limit int) ([]api.DealFilterDecision, error) {
+	res, err := l.ds.Query(ctx, query.Query{
+		Orders: []query.Order{query.OrderByKeyDescending{}},
+		Limit:  limit,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("querying decisions: %w", err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return nil, xerrors.Errorf("reading decisions: %w", err)
+	}
+
+	out := make([]api.DealFilterDecision, 0, len(entries))
+	for _, e := range entries {
+		var d api.DealFilterDecision
+		if err := json.Unmarshal(e.Value, &d); err != nil {
+			return nil, xerrors.Errorf("decoding decision %s: %w", e.Key, err)
+		}
+		out = append(out, d)
+	}
+	return out, nil
+}
+
+func decisionKey(ts int64) datastore.Key {
+	// zero padded, so that keys sort by time
+	return datastore.NewKey(fmt.Sprintf("%020d", ts))
+}
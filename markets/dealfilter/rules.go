@@ -0,0 +1,346 @@
+package dealfilter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+var log = logging.Logger("dealfilter")
+
+// Rules are the rules of the built-in storage deal filter, read from a JSON
+// file. Client addresses are matched as they appear in deal proposals.
+type Rules struct {
+	// VerifiedPriceFloor is the minimum storage price of verified deals, per
+	// GiB per epoch, e.g. "0.0000000001 FIL". Empty accepts any price.
+	VerifiedPriceFloor string
+	// UnverifiedPriceFloor is the minimum storage price of unverified deals,
+	// per GiB per epoch.
+	UnverifiedPriceFloor string
+
+	// ClientAllowlist, when not empty, are the only clients deals are
+	// accepted from.
+	ClientAllowlist []address.Address
+	// ClientBlocklist are clients deals are never accepted from.
+	ClientBlocklist []address.Address
+
+	// MaxDealsPerClientPerHour is the maximum number of deals accepted from a
+	// client over an hour, 0 being unlimited. Only the deals accepted by all
+	// the deal filters count.
+	MaxDealsPerClientPerHour int
+	// ClientRateLimits overrides MaxDealsPerClientPerHour for some clients.
+	ClientRateLimits map[string]int
+}
+
+type parsedRules struct {
+	verifiedFloor   abi.TokenAmount
+	unverifiedFloor abi.TokenAmount
+	allow           map[address.Address]struct{}
+	block           map[address.Address]struct{}
+	maxPerHour      int
+	clientLimits    map[address.Address]int
+}
+
+func (r *Rules) parse() (*parsedRules, error) {
+	pr := &parsedRules{
+		verifiedFloor:   big.Zero(),
+		unverifiedFloor: big.Zero(),
+		allow:           map[address.Address]struct{}{},
+		block:           map[address.Address]struct{}{},
+		maxPerHour:      r.MaxDealsPerClientPerHour,
+		clientLimits:    map[address.Address]int{},
+	}
+
+	parseFloor := func(name, s string) (abi.TokenAmount, error) {
+		if s == "" {
+			return big.Zero(), nil
+		}
+		f, err := types.ParseFIL(s)
+		if err != nil {
+			return big.Zero(), xerrors.Errorf("parsing %s: %w", name, err)
+		}
+		return abi.TokenAmount(f), nil
+	}
+	var err error
+	if pr.verifiedFloor, err = parseFloor("VerifiedPriceFloor", r.VerifiedPriceFloor); err != nil {
+		return nil, err
+	}
+	if pr.unverifiedFloor, err = parseFloor("UnverifiedPriceFloor", r.UnverifiedPriceFloor); err != nil {
+		return nil, err
+	}
+
+	for _, a := range r.ClientAllowlist {
+		pr.allow[a] = struct{}{}
+	}
+	for _, a := range r.ClientBlocklist {
+		pr.block[a] = struct{}{}
+	}
+
+	if r.MaxDealsPerClientPerHour < 0 {
+		return nil, xerrors.Errorf("MaxDealsPerClientPerHour must not be negative")
+	}
+	for s, n := range r.ClientRateLimits {
+		a, err := address.NewFromString(s)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing client %q of ClientRateLimits: %w", s, err)
+		}
+		if n < 0 {
+			return nil, xerrors.Errorf("rate limit of client %s must not be negative", s)
+		}
+		pr.clientLimits[a] = n
+	}
+
+	return pr, nil
+}
+
+// RulesFilter is the built-in storage deal filter, applying the rules of a
+// file. The file is reloaded when it's modified; when the new rules are
+// invalid, the previous ones are kept.
+type RulesFilter struct {
+	path string
+	ds   datastore.Datastore
+	now  func() time.Time
+
+	lk      sync.Mutex
+	modTime time.Time
+	rules   *parsedRules
+	// accepted are the times of the deals accepted over the last hour from
+	// the clients with a rate limit, at most as many as their limit. They are
+	// kept in ds, for the limits to hold across restarts.
+	accepted map[address.Address][]time.Time
+	// reserved are the times added to accepted for the deals being filtered,
+	// removed when the deals are rejected by the filters applied after.
+	reserved map[cid.Cid]time.Time
+}
+
+// NewRulesFilter creates a deal filter applying the rules of the file at path,
+// keeping the deals accepted from rate limited clients in ds.
+func NewRulesFilter(ctx context.Context, path string, ds datastore.Datastore) (*RulesFilter, error) {
+	f := &RulesFilter{
+		path:     path,
+		ds:       ds,
+		now:      time.Now,
+		accepted: map[address.Address][]time.Time{},
+		reserved: map[cid.Cid]time.Time{},
+	}
+	if err := f.reload(); err != nil {
+		return nil, err
+	}
+	if err := f.load(ctx); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RulesFilter) load(ctx context.Context) error {
+	res, err := f.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return xerrors.Errorf("querying accepted deals: %w", err)
+	}
+	entries, err := res.Rest()
+	if err != nil {
+		return xerrors.Errorf("reading accepted deals: %w", err)
+	}
+
+	for _, e := range entries {
+		a, err := address.NewFromString(datastore.NewKey(e.Key).BaseNamespace())
+		if err != nil {
+			return xerrors.Errorf("parsing client of accepted deals %s: %w", e.Key, err)
+		}
+		var times []time.Time
+		if err := json.Unmarshal(e.Value, &times); err != nil {
+			return xerrors.Errorf("decoding accepted deals of %s: %w", a, err)
+		}
+		f.accepted[a] = times
+	}
+	f.expire(ctx, f.now())
+	return nil
+}
+
+// expire drops the deals accepted over an hour ago.
+func (f *RulesFilter) expire(ctx context.Context, now time.Time) {
+	for client, times := range f.accepted {
+		recent := times[:0]
+		for _, t := range times {
+			if now.Sub(t) < time.Hour {
+				recent = append(recent, t)
+			}
+		}
+		if len(recent) == len(times) {
+			continue
+		}
+		if len(recent) == 0 {
+			delete(f.accepted, client)
+		} else {
+			f.accepted[client] = recent
+		}
+		f.persist(ctx, client)
+	}
+}
+
+// persist writes the deals accepted from client to the datastore.
+func (f *RulesFilter) persist(ctx context.Context, client address.Address) {
+	key := datastore.NewKey(client.String())
+	times, ok := f.accepted[client]
+	if !ok {
+		if err := f.ds.Delete(ctx, key); err != nil {
+			log.Errorf("deleting accepted deals of %s: %s", client, err)
+		}
+		return
+	}
+
+	b, err := json.Marshal(times)
+	if err == nil {
+		err = f.ds.Put(ctx, key, b)
+	}
+	if err != nil {
+		log.Errorf("writing accepted deals of %s: %s", client, err)
+	}
+}
+
+func (f *RulesFilter) reload() error {
+	fi, err := os.Stat(f.path)
+	if err != nil {
+		return xerrors.Errorf("stating deal filter rules: %w", err)
+	}
+	if f.rules != nil && fi.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	b, err := os.ReadFile(f.path)
+	if err != nil {
+		return xerrors.Errorf("reading deal filter rules: %w", err)
+	}
+	var r Rules
+	if err := json.Unmarshal(b, &r); err != nil {
+		return xerrors.Errorf("decoding deal filter rules %s: %w", f.path, err)
+	}
+	pr, err := r.parse()
+	if err != nil {
+		return xerrors.Errorf("deal filter rules %s: %w", f.path, err)
+	}
+
+	if f.rules != nil {
+		log.Infow("reloaded deal filter rules", "path", f.path)
+	}
+	f.rules = pr
+	f.modTime = fi.ModTime()
+	return nil
+}
+
+// Filter applies the rules to a storage deal.
+func (f *RulesFilter) Filter(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	if err := f.reload(); err != nil {
+		log.Errorf("keeping the previous deal filter rules: %s", err)
+	}
+	r := f.rules
+	prop := deal.Proposal
+
+	if _, ok := r.block[prop.Client]; ok {
+		return false, fmt.Sprintf("client %s is blocklisted", prop.Client), nil
+	}
+	if _, ok := r.allow[prop.Client]; len(r.allow) > 0 && !ok {
+		return false, fmt.Sprintf("client %s is not allowlisted", prop.Client), nil
+	}
+
+	floor := r.unverifiedFloor
+	if prop.VerifiedDeal {
+		floor = r.verifiedFloor
+	}
+	// price * GiB < floor * size, with the floor per GiB
+	if big.Mul(prop.StoragePricePerEpoch, big.NewInt(1<<30)).LessThan(big.Mul(floor, big.NewInt(int64(prop.PieceSize)))) {
+		return false, fmt.Sprintf("storage price is below the floor of %s per GiB per epoch", types.FIL(floor)), nil
+	}
+
+	limit, ok := r.clientLimits[prop.Client]
+	if !ok {
+		limit = r.maxPerHour
+	}
+	if limit == 0 {
+		return true, "", nil
+	}
+
+	now := f.now()
+	f.expire(ctx, now)
+	if len(f.accepted[prop.Client]) >= limit {
+		return false, fmt.Sprintf("client %s exceeded its limit of %d deals per hour", prop.Client, limit), nil
+	}
+	// reserve the deal until it's accepted, see Wrap
+	f.accepted[prop.Client] = append(f.accepted[prop.Client], now)
+	f.reserved[deal.ProposalCid] = now
+
+	return true, "", nil
+}
+
+// Wrap returns a filter charging the rate limits of the rules for the deals
+// filter accepts. filter must apply the rules, with Filter, along with the
+// other filters; the deals rejected by the other filters aren't charged.
+func (f *RulesFilter) Wrap(filter dtypes.StorageDealFilter) dtypes.StorageDealFilter {
+	return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		ok, reason, err := filter(ctx, deal)
+		f.settle(ctx, deal, ok && err == nil)
+		return ok, reason, err
+	}
+}
+
+// settle keeps the deal reserved by Filter when it's accepted, and releases it
+// otherwise.
+func (f *RulesFilter) settle(ctx context.Context, deal storagemarket.MinerDeal, accepted bool) {
+	f.lk.Lock()
+	defer f.lk.Unlock()
+
+	t, ok := f.reserved[deal.ProposalCid]
+	if !ok {
+		return
+	}
+	delete(f.reserved, deal.ProposalCid)
+
+	client := deal.Proposal.Client
+	if !accepted {
+		times := f.accepted[client]
+		for i := len(times) - 1; i >= 0; i-- {
+			if times[i].Equal(t) {
+				f.accepted[client] = append(times[:i], times[i+1:]...)
+				break
+			}
+		}
+		if len(f.accepted[client]) == 0 {
+			delete(f.accepted, client)
+		}
+	}
+	f.persist(ctx, client)
+}
+
+// ChainStorageDealFilters returns a filter accepting the deals all filters
+// accept, applying them in order.
+func ChainStorageDealFilters(filters ...dtypes.StorageDealFilter) dtypes.StorageDealFilter {
+	return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		for _, f := range filters {
+			ok, reason, err := f(ctx, deal)
+			if err != nil || !ok {
+				return ok, reason, err
+			}
+		}
+		return true, "", nil
+	}
+}
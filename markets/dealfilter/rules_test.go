@@ -0,0 +1,147 @@
+package dealfilter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+)
+
+func TestRulesFilter(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "rules.json")
+
+	writeRules := func(rules string, mtime time.Time) {
+		require.NoError(t, os.WriteFile(path, []byte(rules), 0644))
+		require.NoError(t, os.Chtimes(path, mtime, mtime))
+	}
+
+	alice, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	bob, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+
+	deal := func(client address.Address, verified bool, price int64) storagemarket.MinerDeal {
+		var d storagemarket.MinerDeal
+		d.Proposal.Client = client
+		d.Proposal.VerifiedDeal = verified
+		d.Proposal.PieceSize = abi.PaddedPieceSize(32 << 30)
+		d.Proposal.StoragePricePerEpoch = big.NewInt(price)
+		return d
+	}
+	check := func(f *RulesFilter, d storagemarket.MinerDeal, accept bool) {
+		t.Helper()
+		ok, reason, err := f.Wrap(f.Filter)(ctx, d)
+		require.NoError(t, err)
+		require.Equal(t, accept, ok, reason)
+	}
+
+	mtime := time.Now().Add(-time.Hour)
+	writeRules(`{
+		"UnverifiedPriceFloor": "0.00000000000000001 FIL",
+		"ClientBlocklist": ["f01001"],
+		"MaxDealsPerClientPerHour": 2
+	}`, mtime)
+
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+	f, err := NewRulesFilter(ctx, path, ds)
+	require.NoError(t, err)
+	now := time.Now()
+	f.now = func() time.Time { return now }
+
+	// 10 attoFIL per GiB per epoch, 320 for 32 GiB
+	check(f, deal(alice, false, 319), false)
+
+	// deals rejected by the filters applied after the rules aren't charged
+	reject := f.Wrap(func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		if ok, reason, err := f.Filter(ctx, deal); !ok || err != nil {
+			return ok, reason, err
+		}
+		return false, "rejected", nil
+	})
+	for i := 0; i < 3; i++ {
+		ok, _, err := reject(ctx, deal(alice, false, 320))
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	check(f, deal(alice, false, 320), true)
+	check(f, deal(alice, true, 0), true)
+	check(f, deal(bob, true, 1000), false)
+
+	// the rate limit applies over an hour, and across restarts
+	check(f, deal(alice, true, 0), false)
+	f, err = NewRulesFilter(ctx, path, ds)
+	require.NoError(t, err)
+	f.now = func() time.Time { return now }
+	check(f, deal(alice, true, 0), false)
+	now = now.Add(time.Hour)
+	check(f, deal(alice, true, 0), true)
+
+	// the rules are reloaded when the file changes
+	mtime = mtime.Add(time.Minute)
+	writeRules(`{"ClientAllowlist": ["f01001"]}`, mtime)
+	check(f, deal(bob, false, 0), true)
+	check(f, deal(alice, false, 1000), false)
+
+	// invalid rules are ignored
+	mtime = mtime.Add(time.Minute)
+	writeRules(`{"ClientRateLimits": {"nope": 1}}`, mtime)
+	check(f, deal(bob, false, 0), true)
+	check(f, deal(alice, false, 1000), false)
+
+	_, err = NewRulesFilter(ctx, path, ds)
+	require.Error(t, err)
+}
+
+func TestDecisionLog(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	l, err := NewDecisionLog(ctx, ds, 3)
+	require.NoError(t, err)
+
+	filter := l.Wrap(func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		if deal.Proposal.VerifiedDeal {
+			return true, "", nil
+		}
+		return false, "unverified", nil
+	})
+
+	for i := 0; i < 5; i++ {
+		var d storagemarket.MinerDeal
+		d.Proposal.VerifiedDeal = i%2 == 0
+		d.Proposal.PieceSize = abi.PaddedPieceSize(i + 1)
+		d.Proposal.StoragePricePerEpoch = big.Zero()
+		_, _, err := filter(ctx, d)
+		require.NoError(t, err)
+	}
+
+	decisions, err := l.List(ctx, 10)
+	require.NoError(t, err)
+	require.Len(t, decisions, 3)
+	require.Equal(t, abi.PaddedPieceSize(5), decisions[0].PieceSize)
+	require.True(t, decisions[0].Accepted)
+	require.Equal(t, abi.PaddedPieceSize(4), decisions[1].PieceSize)
+	require.False(t, decisions[1].Accepted)
+	require.Equal(t, "unverified", decisions[1].Reason)
+
+	// the number of decisions is kept after reopening the log
+	l, err = NewDecisionLog(ctx, ds, 3)
+	require.NoError(t, err)
+	require.Equal(t, 3, l.count)
+
+	decisions, err = l.List(ctx, 1)
+	require.NoError(t, err)
+	require.Len(t, decisions, 1)
+}
@@ -0,0 +1,333 @@
+package pricing
+
+import (
+	"context"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/docker/go-units"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-fil-markets/storagemarket/impl/storedask"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+var log = logging.Logger("pricing")
+
+// StorageAPI is the part of the storage API the utilization is computed from.
+type StorageAPI interface {
+	StorageList(ctx context.Context) (map[storiface.ID][]storiface.Decl, error)
+	StorageInfo(ctx context.Context, id storiface.ID) (storiface.StorageInfo, error)
+	StorageStat(ctx context.Context, id storiface.ID) (fsutil.FsStat, error)
+}
+
+type storageAsk interface {
+	GetAsk() *storagemarket.SignedStorageAsk
+	SetAsk(price abi.TokenAmount, verifiedPrice abi.TokenAmount, duration abi.ChainEpoch, options ...storagemarket.StorageAskOption) error
+}
+
+type chainAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+}
+
+// AskChangeEvt is the journal event recorded for each change of the storage
+// ask, including ones skipped in dry-run mode.
+type AskChangeEvt struct {
+	Utilization float64
+
+	Price         abi.TokenAmount
+	VerifiedPrice abi.TokenAmount
+	MinPieceSize  abi.PaddedPieceSize
+	MaxPieceSize  abi.PaddedPieceSize
+	Expiry        abi.ChainEpoch
+
+	PrevPrice         abi.TokenAmount     `json:",omitempty"`
+	PrevVerifiedPrice abi.TokenAmount     `json:",omitempty"`
+	PrevMinPieceSize  abi.PaddedPieceSize `json:",omitempty"`
+	PrevMaxPieceSize  abi.PaddedPieceSize `json:",omitempty"`
+
+	DryRun bool
+	Error  string `json:",omitempty"`
+}
+
+type curvePoint struct {
+	utilization   float64
+	price         abi.TokenAmount
+	verifiedPrice abi.TokenAmount
+	minPieceSize  abi.PaddedPieceSize
+	maxPieceSize  abi.PaddedPieceSize
+}
+
+// askParams are the ask fields set by the automation.
+type askParams struct {
+	price         abi.TokenAmount
+	verifiedPrice abi.TokenAmount
+	minPieceSize  abi.PaddedPieceSize
+	maxPieceSize  abi.PaddedPieceSize
+}
+
+func parseCurve(points []config.StorageAskCurvePoint, sectorSize abi.SectorSize) ([]curvePoint, error) {
+	if len(points) == 0 {
+		return nil, xerrors.Errorf("the ask curve has no points")
+	}
+
+	parsePrice := func(i int, name, s string) (abi.TokenAmount, error) {
+		if s == "" {
+			return big.Zero(), nil
+		}
+		f, err := types.ParseFIL(s)
+		if err != nil {
+			return big.Zero(), xerrors.Errorf("parsing %s of point %d: %w", name, i, err)
+		}
+		if f.Sign() < 0 {
+			return big.Zero(), xerrors.Errorf("%s of point %d must not be negative", name, i)
+		}
+		return abi.TokenAmount(f), nil
+	}
+	parseSize := func(i int, name, s string, def abi.PaddedPieceSize) (abi.PaddedPieceSize, error) {
+		if s == "" {
+			return def, nil
+		}
+		n, err := units.RAMInBytes(s)
+		if err != nil {
+			return 0, xerrors.Errorf("parsing %s of point %d: %w", name, i, err)
+		}
+		size := abi.PaddedPieceSize(n)
+		if err := size.Validate(); err != nil {
+			return 0, xerrors.Errorf("%s of point %d: %w", name, i, err)
+		}
+		if size > abi.PaddedPieceSize(sectorSize) {
+			return 0, xerrors.Errorf("%s of point %d is larger than the sector size", name, i)
+		}
+		return size, nil
+	}
+
+	out := make([]curvePoint, 0, len(points))
+	for i, p := range points {
+		if p.Utilization < 0 || p.Utilization > 1 || math.IsNaN(p.Utilization) {
+			return nil, xerrors.Errorf("utilization of point %d must be between 0 and 1", i)
+		}
+
+		cp := curvePoint{utilization: p.Utilization}
+		var err error
+		if cp.price, err = parsePrice(i, "Price", p.Price); err != nil {
+			return nil, err
+		}
+		if cp.verifiedPrice, err = parsePrice(i, "VerifiedPrice", p.VerifiedPrice); err != nil {
+			return nil, err
+		}
+		if cp.minPieceSize, err = parseSize(i, "MinPieceSize", p.MinPieceSize, storedask.DefaultMinPieceSize); err != nil {
+			return nil, err
+		}
+		if cp.maxPieceSize, err = parseSize(i, "MaxPieceSize", p.MaxPieceSize, abi.PaddedPieceSize(sectorSize)); err != nil {
+			return nil, err
+		}
+		if cp.minPieceSize > cp.maxPieceSize {
+			return nil, xerrors.Errorf("MinPieceSize of point %d is larger than its MaxPieceSize", i)
+		}
+		out = append(out, cp)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].utilization < out[j].utilization
+	})
+	return out, nil
+}
+
+// interpolate returns the price between a and b at frac, from 0 to 1
+func interpolate(a, b abi.TokenAmount, frac float64) abi.TokenAmount {
+	const scale = 1_000_000
+	delta := big.Div(big.Mul(big.Sub(b, a), big.NewInt(int64(math.Round(frac*scale)))), big.NewInt(scale))
+	return big.Add(a, delta)
+}
+
+// askAt returns the ask parameters of the curve at the given utilization.
+func askAt(curve []curvePoint, utilization float64) askParams {
+	last := len(curve) - 1
+	switch {
+	case utilization <= curve[0].utilization:
+		return curve[0].params()
+	case utilization >= curve[last].utilization:
+		return curve[last].params()
+	}
+
+	i := sort.Search(len(curve), func(i int) bool {
+		return curve[i].utilization > utilization
+	})
+	lo, hi := curve[i-1], curve[i]
+	frac := (utilization - lo.utilization) / (hi.utilization - lo.utilization)
+
+	return askParams{
+		price:         interpolate(lo.price, hi.price, frac),
+		verifiedPrice: interpolate(lo.verifiedPrice, hi.verifiedPrice, frac),
+		minPieceSize:  lo.minPieceSize,
+		maxPieceSize:  lo.maxPieceSize,
+	}
+}
+
+func (p curvePoint) params() askParams {
+	return askParams{
+		price:         p.price,
+		verifiedPrice: p.verifiedPrice,
+		minPieceSize:  p.minPieceSize,
+		maxPieceSize:  p.maxPieceSize,
+	}
+}
+
+// AskAutomation periodically sets the storage ask from a pricing curve over
+// the utilization of the long-term storage paths.
+type AskAutomation struct {
+	cfg      config.StorageAskAutomationConfig
+	curve    []curvePoint
+	duration abi.ChainEpoch
+
+	ask     storageAsk
+	storage StorageAPI
+	chain   chainAPI
+
+	journal journal.Journal
+	evtType journal.EventType
+}
+
+func NewAskAutomation(cfg config.StorageAskAutomationConfig, sectorSize abi.SectorSize, ask storageAsk, storage StorageAPI, chain chainAPI, j journal.Journal) (*AskAutomation, error) {
+	curve, err := parseCurve(cfg.Curve, sectorSize)
+	if err != nil {
+		return nil, xerrors.Errorf("parsing the storage ask curve: %w", err)
+	}
+	if time.Duration(cfg.Interval) <= 0 {
+		return nil, xerrors.Errorf("ask automation interval must be positive")
+	}
+	duration := abi.ChainEpoch(time.Duration(cfg.AskDuration) / (time.Duration(build.BlockDelaySecs) * time.Second))
+	if duration <= 0 {
+		return nil, xerrors.Errorf("ask duration must be at least an epoch")
+	}
+
+	return &AskAutomation{
+		cfg:      cfg,
+		curve:    curve,
+		duration: duration,
+		ask:      ask,
+		storage:  storage,
+		chain:    chain,
+		journal:  j,
+		evtType:  j.RegisterEventType("markets/storage/ask", "ask_change"),
+	}, nil
+}
+
+func (a *AskAutomation) Run(ctx context.Context) {
+	log.Infow("storage ask automation enabled", "interval", time.Duration(a.cfg.Interval), "points", len(a.curve), "dry-run", a.cfg.DryRun)
+
+	t := time.NewTicker(time.Duration(a.cfg.Interval))
+	defer t.Stop()
+
+	for {
+		if err := a.check(ctx); err != nil {
+			log.Errorw("storage ask automation failed", "error", err)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// utilization returns the fraction of the capacity of the long-term storage
+// paths in use.
+func (a *AskAutomation) utilization(ctx context.Context) (float64, error) {
+	paths, err := a.storage.StorageList(ctx)
+	if err != nil {
+		return 0, xerrors.Errorf("listing storage paths: %w", err)
+	}
+
+	var capacity, available int64
+	for id := range paths {
+		info, err := a.storage.StorageInfo(ctx, id)
+		if err != nil {
+			return 0, xerrors.Errorf("getting info of storage path %s: %w", id, err)
+		}
+		if !info.CanStore {
+			continue
+		}
+		st, err := a.storage.StorageStat(ctx, id)
+		if err != nil {
+			return 0, xerrors.Errorf("getting stats of storage path %s: %w", id, err)
+		}
+		capacity += st.Capacity
+		available += st.Available
+	}
+	if capacity <= 0 {
+		return 0, xerrors.Errorf("no long-term storage capacity")
+	}
+
+	u := 1 - float64(available)/float64(capacity)
+	return math.Min(math.Max(u, 0), 1), nil
+}
+
+func (a *AskAutomation) check(ctx context.Context) error {
+	u, err := a.utilization(ctx)
+	if err != nil {
+		return err
+	}
+	head, err := a.chain.ChainHead(ctx)
+	if err != nil {
+		return xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	p := askAt(a.curve, u)
+	evt := &AskChangeEvt{
+		Utilization:   u,
+		Price:         p.price,
+		VerifiedPrice: p.verifiedPrice,
+		MinPieceSize:  p.minPieceSize,
+		MaxPieceSize:  p.maxPieceSize,
+		Expiry:        head.Height() + a.duration,
+		DryRun:        a.cfg.DryRun,
+	}
+
+	if cur := a.ask.GetAsk(); cur != nil && cur.Ask != nil {
+		prev := cur.Ask
+		// keep the current ask while it's unchanged and not close to expiry
+		if prev.Price.Equals(p.price) && prev.VerifiedPrice.Equals(p.verifiedPrice) &&
+			prev.MinPieceSize == p.minPieceSize && prev.MaxPieceSize == p.maxPieceSize &&
+			prev.Expiry-head.Height() > a.duration/2 {
+			return nil
+		}
+
+		evt.PrevPrice = prev.Price
+		evt.PrevVerifiedPrice = prev.VerifiedPrice
+		evt.PrevMinPieceSize = prev.MinPieceSize
+		evt.PrevMaxPieceSize = prev.MaxPieceSize
+	}
+	defer a.journal.RecordEvent(a.evtType, func() interface{} { return evt })
+
+	if a.cfg.DryRun {
+		log.Infow("dry run: would set storage ask", "utilization", u, "price", types.FIL(p.price), "verified-price", types.FIL(p.verifiedPrice),
+			"min-piece-size", p.minPieceSize, "max-piece-size", p.maxPieceSize)
+		return nil
+	}
+
+	err = a.ask.SetAsk(p.price, p.verifiedPrice, a.duration,
+		storagemarket.MinPieceSize(p.minPieceSize),
+		storagemarket.MaxPieceSize(p.maxPieceSize))
+	if err != nil {
+		evt.Error = err.Error()
+		return xerrors.Errorf("setting storage ask: %w", err)
+	}
+
+	log.Infow("set storage ask", "utilization", u, "price", types.FIL(p.price), "verified-price", types.FIL(p.verifiedPrice),
+		"min-piece-size", p.minPieceSize, "max-piece-size", p.maxPieceSize)
+	return nil
+}
@@ -0,0 +1,170 @@
+package pricing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestAskCurve(t *testing.T) {
+	curve, err := parseCurve([]config.StorageAskCurvePoint{
+		{Utilization: 0.8, Price: "300 attofil", VerifiedPrice: "30 attofil", MaxPieceSize: "8GiB"},
+		{Utilization: 0.2, Price: "100 attofil", VerifiedPrice: "10 attofil"},
+	}, abi.SectorSize(32<<30))
+	require.NoError(t, err)
+
+	check := func(u float64, price, verified int64, maxSize abi.PaddedPieceSize) {
+		t.Helper()
+		p := askAt(curve, u)
+		require.Equal(t, big.NewInt(price), p.price)
+		require.Equal(t, big.NewInt(verified), p.verifiedPrice)
+		require.Equal(t, abi.PaddedPieceSize(256), p.minPieceSize)
+		require.Equal(t, maxSize, p.maxPieceSize)
+	}
+	check(0, 100, 10, 32<<30)
+	check(0.2, 100, 10, 32<<30)
+	check(0.5, 200, 20, 32<<30)
+	check(0.8, 300, 30, 8<<30)
+	check(1, 300, 30, 8<<30)
+
+	for _, points := range [][]config.StorageAskCurvePoint{
+		nil,
+		{{Utilization: 1.5}},
+		{{Utilization: 0.5, Price: "-1"}},
+		{{Utilization: 0.5, MaxPieceSize: "3GiB"}},
+		{{Utilization: 0.5, MaxPieceSize: "64GiB"}},
+		{{Utilization: 0.5, MinPieceSize: "1GiB", MaxPieceSize: "512MiB"}},
+	} {
+		_, err := parseCurve(points, abi.SectorSize(32<<30))
+		require.Error(t, err, "%v", points)
+	}
+}
+
+type testStorage map[storiface.ID]fsutil.FsStat
+
+func (s testStorage) StorageList(ctx context.Context) (map[storiface.ID][]storiface.Decl, error) {
+	out := map[storiface.ID][]storiface.Decl{}
+	for id := range s {
+		out[id] = nil
+	}
+	return out, nil
+}
+
+func (s testStorage) StorageInfo(ctx context.Context, id storiface.ID) (storiface.StorageInfo, error) {
+	return storiface.StorageInfo{ID: id, CanStore: id != "seal"}, nil
+}
+
+func (s testStorage) StorageStat(ctx context.Context, id storiface.ID) (fsutil.FsStat, error) {
+	return s[id], nil
+}
+
+type testAsk struct {
+	head *abi.ChainEpoch
+	ask  *storagemarket.StorageAsk
+	sets int
+}
+
+func (a *testAsk) GetAsk() *storagemarket.SignedStorageAsk {
+	return &storagemarket.SignedStorageAsk{Ask: a.ask}
+}
+
+func (a *testAsk) SetAsk(price abi.TokenAmount, verifiedPrice abi.TokenAmount, duration abi.ChainEpoch, options ...storagemarket.StorageAskOption) error {
+	a.ask = &storagemarket.StorageAsk{
+		Price:         price,
+		VerifiedPrice: verifiedPrice,
+		Expiry:        *a.head + duration,
+	}
+	for _, o := range options {
+		o(a.ask)
+	}
+	a.sets++
+	return nil
+}
+
+type testChain struct {
+	head *abi.ChainEpoch
+}
+
+func (c testChain) ChainHead(context.Context) (*types.TipSet, error) {
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Height = *c.head
+	return mock.TipSet(blk), nil
+}
+
+type testJournal struct {
+	journal.Journal
+	events []interface{}
+}
+
+func (j *testJournal) RecordEvent(evtType journal.EventType, supplier func() interface{}) {
+	j.events = append(j.events, supplier())
+}
+
+func TestAskAutomation(t *testing.T) {
+	ctx := context.Background()
+
+	storage := testStorage{
+		"store": {Capacity: 100, Available: 50},
+		"seal":  {Capacity: 100, Available: 100},
+	}
+	var head abi.ChainEpoch
+	ask := &testAsk{head: &head}
+	j := &testJournal{Journal: journal.NilJournal()}
+
+	a, err := NewAskAutomation(config.StorageAskAutomationConfig{
+		Interval:    config.Duration(time.Hour),
+		AskDuration: config.Duration(time.Hour),
+		Curve: []config.StorageAskCurvePoint{
+			{Utilization: 0, Price: "100 attofil"},
+			{Utilization: 1, Price: "200 attofil"},
+		},
+	}, abi.SectorSize(32<<30), ask, storage, testChain{head: &head}, j)
+	require.NoError(t, err)
+
+	head = 1000
+	require.NoError(t, a.check(ctx))
+	require.Equal(t, 1, ask.sets)
+	require.Equal(t, big.NewInt(150), ask.ask.Price)
+	require.Len(t, j.events, 1)
+	require.Equal(t, 0.5, j.events[0].(*AskChangeEvt).Utilization)
+
+	// unchanged
+	require.NoError(t, a.check(ctx))
+	require.Equal(t, 1, ask.sets)
+
+	// renewed close to expiry
+	head += a.duration/2 + 1
+	require.NoError(t, a.check(ctx))
+	require.Equal(t, 2, ask.sets)
+
+	// changed with the utilization
+	storage["store"] = fsutil.FsStat{Capacity: 100, Available: 10}
+	require.NoError(t, a.check(ctx))
+	require.Equal(t, 3, ask.sets)
+	require.Equal(t, big.NewInt(190), ask.ask.Price)
+
+	evt := j.events[2].(*AskChangeEvt)
+	require.Equal(t, big.NewInt(150), evt.PrevPrice)
+	require.Equal(t, big.NewInt(190), evt.Price)
+
+	// dry run only journals changes
+	a.cfg.DryRun = true
+	storage["store"] = fsutil.FsStat{Capacity: 100, Available: 100}
+	require.NoError(t, a.check(ctx))
+	require.Equal(t, 3, ask.sets)
+	require.Len(t, j.events, 4)
+	require.True(t, j.events[3].(*AskChangeEvt).DryRun)
+}
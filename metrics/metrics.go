@@ -60,7 +60,12 @@ var (
 	UseFD, _      = tag.NewKey("use_fd")
 
 	// vm execution
-	ExecutionLane, _ = tag.NewKey("lane")
+	ExecutionLane, _  = tag.NewKey("lane")
+	Syscall, _        = tag.NewKey("syscall")
+	ExecutionLimit, _ = tag.NewKey("limit")
+
+	// events
+	FilterType, _ = tag.NewKey("filter_type")
 )
 
 // Measures
@@ -126,6 +131,10 @@ var (
 	VMApplied                           = stats.Int64("vm/applied", "Counter for messages (including internal messages) processed by the VM", stats.UnitDimensionless)
 	VMExecutionWaiting                  = stats.Int64("vm/execution_waiting", "Counter for VM executions waiting to be assigned to a lane", stats.UnitDimensionless)
 	VMExecutionRunning                  = stats.Int64("vm/execution_running", "Counter for running VM executions", stats.UnitDimensionless)
+	VMExecutionWaitDuration             = stats.Float64("vm/execution_wait_ms", "Time VM executions wait to be assigned to a lane", stats.UnitMilliseconds)
+	VMExecutionDuration                 = stats.Float64("vm/execution_ms", "Time spent in VM executions", stats.UnitMilliseconds)
+	VMSyscalls                          = stats.Int64("vm/syscalls", "Counter for calls from the FVM to the node during explicit calls, by syscall", stats.UnitDimensionless)
+	VMExecutionLimitExceeded            = stats.Int64("vm/execution_limit_exceeded", "Counter for calls aborted for exceeding an execution limit", stats.UnitDimensionless)
 
 	// miner
 	WorkerCallsStarted           = stats.Int64("sealing/worker_calls_started", "Counter of started worker tasks", stats.UnitDimensionless)
@@ -187,6 +196,19 @@ var (
 
 	// gateway rate limit
 	RateLimitCount = stats.Int64("ratelimit/limited", "rate limited connections", stats.UnitDimensionless)
+
+	// state replay cache
+	StateReplayCacheHit  = stats.Int64("state/replay_cache_hit", "Number of StateReplayCached calls served from the cache", stats.UnitDimensionless)
+	StateReplayCacheMiss = stats.Int64("state/replay_cache_miss", "Number of StateReplayCached calls replaying the message", stats.UnitDimensionless)
+
+	// events
+	EventFiltersInstalled       = stats.Int64("events/filters_installed", "Number of installed filters, by filter type", stats.UnitDimensionless)
+	EventFilterMatched          = stats.Int64("events/filter_matched", "Counter for events matched by filters", stats.UnitDimensionless)
+	EventSubscriptionQueueDepth = stats.Int64("events/subscription_queue_depth", "Number of responses queued for a subscriber", stats.UnitDimensionless)
+	EventIndexWriteDuration     = stats.Float64("events/index_write_ms", "Duration of writes of the events of a tipset to the events index", stats.UnitMilliseconds)
+	EventIndexQueryDuration     = stats.Float64("events/index_query_ms", "Duration of events index queries", stats.UnitMilliseconds)
+	EventIndexSize              = stats.Int64("events/index_size_bytes", "Size of the events index database", stats.UnitBytes)
+	EthGetLogsDuration          = stats.Float64("eth/get_logs_ms", "Duration of eth_getLogs calls", stats.UnitMilliseconds)
 )
 
 var (
@@ -378,6 +400,26 @@ var (
 		Aggregation: view.Sum(),
 		TagKeys:     []tag.Key{ExecutionLane},
 	}
+	VMExecutionWaitDurationView = &view.View{
+		Measure:     VMExecutionWaitDuration,
+		Aggregation: defaultMillisecondsDistribution,
+		TagKeys:     []tag.Key{ExecutionLane},
+	}
+	VMExecutionDurationView = &view.View{
+		Measure:     VMExecutionDuration,
+		Aggregation: defaultMillisecondsDistribution,
+		TagKeys:     []tag.Key{ExecutionLane},
+	}
+	VMSyscallsView = &view.View{
+		Measure:     VMSyscalls,
+		Aggregation: view.Sum(),
+		TagKeys:     []tag.Key{Syscall},
+	}
+	VMExecutionLimitExceededView = &view.View{
+		Measure:     VMExecutionLimitExceeded,
+		Aggregation: view.Count(),
+		TagKeys:     []tag.Key{ExecutionLimit},
+	}
 
 	// miner
 	WorkerCallsStartedView = &view.View{
@@ -658,6 +700,45 @@ var (
 		Measure:     RateLimitCount,
 		Aggregation: view.Count(),
 	}
+	StateReplayCacheHitView = &view.View{
+		Measure:     StateReplayCacheHit,
+		Aggregation: view.Count(),
+	}
+	StateReplayCacheMissView = &view.View{
+		Measure:     StateReplayCacheMiss,
+		Aggregation: view.Count(),
+	}
+
+	// events
+	EventFiltersInstalledView = &view.View{
+		Measure:     EventFiltersInstalled,
+		Aggregation: view.LastValue(),
+		TagKeys:     []tag.Key{FilterType},
+	}
+	EventFilterMatchedView = &view.View{
+		Measure:     EventFilterMatched,
+		Aggregation: view.Sum(),
+	}
+	EventSubscriptionQueueDepthView = &view.View{
+		Measure:     EventSubscriptionQueueDepth,
+		Aggregation: queueSizeDistribution,
+	}
+	EventIndexWriteDurationView = &view.View{
+		Measure:     EventIndexWriteDuration,
+		Aggregation: defaultMillisecondsDistribution,
+	}
+	EventIndexQueryDurationView = &view.View{
+		Measure:     EventIndexQueryDuration,
+		Aggregation: defaultMillisecondsDistribution,
+	}
+	EventIndexSizeView = &view.View{
+		Measure:     EventIndexSize,
+		Aggregation: view.LastValue(),
+	}
+	EthGetLogsDurationView = &view.View{
+		Measure:     EthGetLogsDuration,
+		Aggregation: defaultMillisecondsDistribution,
+	}
 )
 
 // DefaultViews is an array of OpenCensus views for metric gathering purposes
@@ -744,6 +825,19 @@ var ChainNodeViews = append([]*view.View{
 	VMAppliedView,
 	VMExecutionWaitingView,
 	VMExecutionRunningView,
+	VMExecutionWaitDurationView,
+	VMExecutionDurationView,
+	VMSyscallsView,
+	VMExecutionLimitExceededView,
+	StateReplayCacheHitView,
+	StateReplayCacheMissView,
+	EventFiltersInstalledView,
+	EventFilterMatchedView,
+	EventSubscriptionQueueDepthView,
+	EventIndexWriteDurationView,
+	EventIndexQueryDurationView,
+	EventIndexSizeView,
+	EthGetLogsDurationView,
 }, DefaultViews...)
 
 var MinerNodeViews = append([]*view.View{
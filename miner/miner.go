@@ -126,6 +126,14 @@ type Miner struct {
 
 	evtTypes [1]journal.EventType
 	journal  journal.Journal
+
+	ordering OrderingRule
+}
+
+// SetMessageOrdering sets the rule ordering the messages of mined blocks. It
+// must be called before Start.
+func (m *Miner) SetMessageOrdering(rule OrderingRule) {
+	m.ordering = rule
 }
 
 // Address returns the address of the miner.
@@ -306,6 +314,7 @@ minerLoop:
 					"epoch":     b.Header.Height,
 					"timestamp": b.Header.Timestamp,
 					"cid":       b.Header.Cid(),
+					"ordering":  m.messageOrdering(),
 				}
 			})
 
@@ -324,10 +333,12 @@ minerLoop:
 					"block-time", btime, "time", build.Clock.Now(), "difference", build.Clock.Since(btime))
 			}
 
-			if err := m.sf.MinedBlock(ctx, b.Header, base.TipSet.Height()+base.NullRounds); err != nil {
-				log.Errorf("<!!> SLASH FILTER ERROR: %s", err)
-				if os.Getenv("LOTUS_MINER_NO_SLASHFILTER") != "_yes_i_know_i_can_and_probably_will_lose_all_my_fil_and_power_" {
-					continue
+			if m.sf != nil {
+				if err := m.sf.MinedBlock(ctx, b.Header, base.TipSet.Height()+base.NullRounds); err != nil {
+					log.Errorf("<!!> SLASH FILTER ERROR: %s", err)
+					if os.Getenv("LOTUS_MINER_NO_SLASHFILTER") != "_yes_i_know_i_can_and_probably_will_lose_all_my_fil_and_power_" {
+						continue
+					}
 				}
 			}
 
@@ -370,6 +381,16 @@ type MiningBase struct {
 	NullRounds abi.ChainEpoch
 }
 
+// ResetMiningBase makes the miner build its next block on the current head,
+// even when the head is lighter than its last mining base. Devnets use it
+// after setting the head back to fork the chain.
+func (m *Miner) ResetMiningBase() {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	m.lastWork = nil
+}
+
 // GetBestMiningCandidate implements the fork choice rule from a miner's
 // perspective.
 //
@@ -553,6 +574,12 @@ func (m *Miner) mineOne(ctx context.Context, base *MiningBase) (minedBlock *type
 		return nil, err
 	}
 
+	msgs, err = OrderMessages(m.messageOrdering(), ticket, msgs)
+	if err != nil {
+		err = xerrors.Errorf("failed to order messages for block: %w", err)
+		return nil, err
+	}
+
 	tPending := build.Clock.Now()
 
 	// TODO: winning post proof
@@ -582,6 +609,13 @@ func (m *Miner) mineOne(ctx context.Context, base *MiningBase) (minedBlock *type
 	return minedBlock, nil
 }
 
+func (m *Miner) messageOrdering() OrderingRule {
+	if m.ordering == "" {
+		return OrderingFee
+	}
+	return m.ordering
+}
+
 func (m *Miner) computeTicket(ctx context.Context, brand *types.BeaconEntry, base *MiningBase, mbi *api.MiningBaseInfo) (*types.Ticket, error) {
 	buf := new(bytes.Buffer)
 	if err := m.address.MarshalCBOR(buf); err != nil {
@@ -0,0 +1,121 @@
+package miner
+
+import (
+	"bytes"
+	"container/heap"
+	"sort"
+
+	"github.com/minio/blake2b-simd"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// OrderingRule is how the miner orders the messages of the blocks it mines.
+type OrderingRule string
+
+const (
+	// OrderingFee keeps the order of message selection, which favours the
+	// messages paying the highest gas premium.
+	OrderingFee OrderingRule = "fee"
+	// OrderingTicketShuffle orders the messages by the hash of the block
+	// ticket and the message CID, keeping the messages of each sender in nonce
+	// order. The order can't be influenced by the fees the messages pay, and
+	// anyone can check a block follows the rule from the block alone.
+	OrderingTicketShuffle OrderingRule = "ticket-shuffle"
+)
+
+// ParseOrderingRule parses a message ordering rule, an empty string being
+// OrderingFee.
+func ParseOrderingRule(s string) (OrderingRule, error) {
+	switch r := OrderingRule(s); r {
+	case "":
+		return OrderingFee, nil
+	case OrderingFee, OrderingTicketShuffle:
+		return r, nil
+	default:
+		return "", xerrors.Errorf("unknown message ordering rule %q", s)
+	}
+}
+
+// OrderMessages orders the messages selected for a block with the given ticket.
+func OrderMessages(rule OrderingRule, ticket *types.Ticket, msgs []*types.SignedMessage) ([]*types.SignedMessage, error) {
+	switch rule {
+	case OrderingFee, "":
+		return msgs, nil
+	case OrderingTicketShuffle:
+		return ticketShuffle(ticket, msgs), nil
+	default:
+		return nil, xerrors.Errorf("unknown message ordering rule %q", rule)
+	}
+}
+
+// ticketShuffle repeatedly takes, among the first remaining messages of each
+// sender, the one with the lowest blake2b-256(ticket VRF proof || message CID).
+func ticketShuffle(ticket *types.Ticket, msgs []*types.SignedMessage) []*types.SignedMessage {
+	bySender := make(map[address.Address][]*shuffledMsg)
+	var senders []address.Address
+	for _, m := range msgs {
+		from := m.Message.From
+		if _, ok := bySender[from]; !ok {
+			senders = append(senders, from)
+		}
+		bySender[from] = append(bySender[from], &shuffledMsg{
+			msg: m,
+			key: blake2b.Sum256(append(append([]byte{}, ticket.VRFProof...), m.Cid().Bytes()...)),
+		})
+	}
+
+	h := make(shuffleHeap, 0, len(senders))
+	for _, s := range senders {
+		sm := bySender[s]
+		sort.SliceStable(sm, func(i, j int) bool {
+			return sm[i].msg.Message.Nonce < sm[j].msg.Message.Nonce
+		})
+		h = append(h, sm)
+	}
+	heap.Init(&h)
+
+	out := make([]*types.SignedMessage, 0, len(msgs))
+	for h.Len() > 0 {
+		next := h[0]
+		out = append(out, next[0].msg)
+		if len(next) == 1 {
+			heap.Pop(&h)
+			continue
+		}
+		h[0] = next[1:]
+		heap.Fix(&h, 0)
+	}
+
+	return out
+}
+
+type shuffledMsg struct {
+	msg *types.SignedMessage
+	key [32]byte
+}
+
+// shuffleHeap holds the remaining messages of each sender, by the key of their
+// first message.
+type shuffleHeap [][]*shuffledMsg
+
+func (h shuffleHeap) Len() int { return len(h) }
+func (h shuffleHeap) Less(i, j int) bool {
+	return bytes.Compare(h[i][0].key[:], h[j][0].key[:]) < 0
+}
+func (h shuffleHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *shuffleHeap) Push(x interface{}) {
+	*h = append(*h, x.([]*shuffledMsg))
+}
+
+func (h *shuffleHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	x := old[n-1]
+	*h = old[:n-1]
+	return x
+}
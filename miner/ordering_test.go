@@ -0,0 +1,69 @@
+package miner
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestTicketShuffle(t *testing.T) {
+	var msgs []*types.SignedMessage
+	for i := uint64(0); i < 20; i++ {
+		from, err := address.NewIDAddress(1000 + i%4)
+		require.NoError(t, err)
+		msgs = append(msgs, &types.SignedMessage{Message: types.Message{
+			From:  from,
+			To:    from,
+			Nonce: i / 4,
+			// fee order
+			GasPremium: types.NewInt(100 - i),
+		}})
+	}
+
+	ticket := &types.Ticket{VRFProof: []byte("ticket")}
+	out, err := OrderMessages(OrderingTicketShuffle, ticket, msgs)
+	require.NoError(t, err)
+	require.Len(t, out, len(msgs))
+	require.NotEqual(t, msgs, out)
+
+	// senders' messages stay in nonce order
+	nonces := map[address.Address]uint64{}
+	for _, m := range out {
+		require.Equal(t, nonces[m.Message.From], m.Message.Nonce)
+		nonces[m.Message.From]++
+	}
+
+	// the order only depends on the ticket and the messages
+	reversed := make([]*types.SignedMessage, len(msgs))
+	for i, m := range msgs {
+		reversed[len(msgs)-1-i] = m
+	}
+	again, err := OrderMessages(OrderingTicketShuffle, ticket, reversed)
+	require.NoError(t, err)
+	require.Equal(t, out, again)
+
+	other, err := OrderMessages(OrderingTicketShuffle, &types.Ticket{VRFProof: []byte("other")}, msgs)
+	require.NoError(t, err)
+	require.NotEqual(t, out, other)
+
+	same, err := OrderMessages(OrderingFee, ticket, msgs)
+	require.NoError(t, err)
+	require.Equal(t, msgs, same)
+}
+
+func TestParseOrderingRule(t *testing.T) {
+	r, err := ParseOrderingRule("")
+	require.NoError(t, err)
+	require.Equal(t, OrderingFee, r)
+
+	r, err = ParseOrderingRule("ticket-shuffle")
+	require.NoError(t, err)
+	require.Equal(t, OrderingTicketShuffle, r)
+
+	_, err = ParseOrderingRule("fifo")
+	require.Error(t, err)
+}
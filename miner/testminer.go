@@ -21,6 +21,17 @@ type MineReq struct {
 }
 
 func NewTestMiner(nextCh <-chan MineReq, addr address.Address) func(v1api.FullNode, gen.WinningPoStProver) *Miner {
+	return newTestMiner(nextCh, addr, slashfilter.New(ds.NewMapDatastore()))
+}
+
+// NewTestMinerWithoutSlashFilter returns a test miner mining blocks the slash
+// filter would reject, like blocks of devnets forking their chain, which have
+// the same parents as blocks mined before.
+func NewTestMinerWithoutSlashFilter(nextCh <-chan MineReq, addr address.Address) func(v1api.FullNode, gen.WinningPoStProver) *Miner {
+	return newTestMiner(nextCh, addr, nil)
+}
+
+func newTestMiner(nextCh <-chan MineReq, addr address.Address, sf *slashfilter.SlashFilter) func(v1api.FullNode, gen.WinningPoStProver) *Miner {
 	return func(api v1api.FullNode, epp gen.WinningPoStProver) *Miner {
 		arc, err := lru.NewARC[abi.ChainEpoch, bool](10000)
 		if err != nil {
@@ -33,7 +44,7 @@ func NewTestMiner(nextCh <-chan MineReq, addr address.Address) func(v1api.FullNo
 			epp:               epp,
 			minedBlockHeights: arc,
 			address:           addr,
-			sf:                slashfilter.New(ds.NewMapDatastore()),
+			sf:                sf,
 			journal:           journal.NilJournal(),
 		}
 
@@ -33,6 +33,7 @@ import (
 	_ "github.com/filecoin-project/lotus/lib/sigs/bls"
 	_ "github.com/filecoin-project/lotus/lib/sigs/delegated"
 	_ "github.com/filecoin-project/lotus/lib/sigs/secp"
+	"github.com/filecoin-project/lotus/lib/webhook"
 	"github.com/filecoin-project/lotus/markets/storageadapter"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl/common"
@@ -86,10 +87,12 @@ const (
 
 	// System processes.
 	InitMemoryWatchdog
+	SetExecutionLanesKey
 
 	// health checks
 	CheckFDLimit
 	LegacyMarketsEOL
+	CheckClockKey
 
 	// libp2p
 	PstoreAddSelfKeysKey
@@ -103,21 +106,31 @@ const (
 	RunChainExchangeKey
 	RunChainGraphsync
 	RunPeerMgrKey
+	RunLightSyncKey
+	RunStateSyncKey
 
 	HandleIncomingBlocksKey
 	HandleIncomingMessagesKey
+	SetMessageSelectorKey
+	SetMpoolSpamFilterKey
 	HandleMigrateClientFundsKey
 	HandlePaymentChannelManagerKey
+	RunMessageSchedulerKey
+	RunChainBridgeKey
 
 	RelayIndexerMessagesKey
+	CheckUpgradeScheduleKey
 
 	// miner
 	PreflightChecksKey
 	GetParamsKey
+	CheckProofParamsKey
 	HandleMigrateProviderFundsKey
 	HandleDealsKey
 	HandleRetrievalKey
 	RunSectorServiceKey
+	RunFundsManagerKey
+	RunStorageAskAutomationKey
 
 	// daemon
 	ExtractApiKey
@@ -131,6 +144,16 @@ const (
 
 	StoreEventsKey
 
+	RunSnapshotterKey
+	RegisterMaintenanceTasksKey
+
+	SetupMessageTracingKey
+
+	SetupWebhookAlertsKey
+	RunWebhookSavedQueriesKey
+	SetupWebhookSectorStatesKey
+	SetupWebhookDealsKey
+
 	_nInvokes // keep this last
 )
 
@@ -151,6 +174,8 @@ type Settings struct {
 	Base   bool // Base option applied
 	Config bool // Config option applied
 	Lite   bool // Start node in "lite" mode
+	// LightSync syncs block headers from the upstream node in lite mode
+	LightSync bool
 
 	enableLibp2pNode bool
 }
@@ -245,6 +270,9 @@ func isFullNode(s *Settings) bool {
 func isLiteNode(s *Settings) bool {
 	return s.nodeType == repo.FullNode && s.Lite
 }
+func isLightSyncNode(s *Settings) bool {
+	return isLiteNode(s) && s.LightSync
+}
 
 func Base() Option {
 	return Options(
@@ -263,6 +291,15 @@ func Base() Option {
 // Config sets up constructors based on the provided Config
 func ConfigCommon(cfg *config.Common, enableLibp2pNode bool) Option {
 	// setup logging early
+	if err := lotuslog.SetupFileRotation(lotuslog.FileConfig{
+		Path:       cfg.Logging.File.Path,
+		MaxSize:    cfg.Logging.File.MaxSizeMB << 20,
+		MaxAge:     time.Duration(cfg.Logging.File.MaxAge),
+		MaxBackups: cfg.Logging.File.MaxBackups,
+		Compress:   cfg.Logging.File.Compress,
+	}); err != nil {
+		return Error(xerrors.Errorf("setting up log file rotation: %w", err))
+	}
 	lotuslog.SetLevelsFromConfig(cfg.Logging.SubsystemLevels)
 
 	return Options(
@@ -309,6 +346,13 @@ func ConfigCommon(cfg *config.Common, enableLibp2pNode bool) Option {
 			If(!cfg.Libp2p.DisableNatPortMap, Override(NatPortMapKey, lp2p.NatPortMap)),
 		),
 		Override(new(dtypes.MetadataDS), modules.Datastore(cfg.Backup.DisableMetadataLog)),
+
+		If(cfg.ClockCheck.Enable, Override(CheckClockKey, modules.CheckClock(cfg.ClockCheck))),
+
+		If(len(cfg.Webhooks.Endpoints) > 0,
+			Override(new(*webhook.Dispatcher), modules.WebhookDispatcher(cfg.Webhooks)),
+			Override(SetupWebhookAlertsKey, modules.WebhookAlerts),
+		),
 	)
 }
 
@@ -316,13 +360,13 @@ func Repo(r repo.Repo) Option {
 	return func(settings *Settings) error {
 		lr, err := r.Lock(settings.nodeType)
 		if err != nil {
-			return err
+			return repo.ExplainLockError(r, err)
 		}
 		c, err := lr.Config()
 		if err != nil {
 			return err
 		}
-		return Options(
+		opts := Options(
 			Override(new(repo.LockedRepo), modules.LockedRepo(lr)), // module handles closing
 
 			Override(new(ci.PrivKey), lp2p.PrivKey),
@@ -335,7 +379,14 @@ func Repo(r repo.Repo) Option {
 
 			ApplyIf(IsType(repo.FullNode), ConfigFullNode(c)),
 			ApplyIf(IsType(repo.StorageMiner), ConfigStorageMiner(c)),
-		)(settings)
+		)
+
+		// levels set at runtime override the levels of the config
+		if err := lotuslog.RestoreLevels(lr.Path()); err != nil {
+			log.Warnf("restoring log levels: %s", err)
+		}
+
+		return opts(settings)
 	}
 }
 
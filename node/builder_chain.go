@@ -2,6 +2,7 @@ package node
 
 import (
 	"os"
+	"time"
 
 	gorpc "github.com/libp2p/go-libp2p-gorpc"
 	"go.uber.org/fx"
@@ -19,11 +20,17 @@ import (
 	"github.com/filecoin-project/lotus/chain/consensus/filcns"
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/exchange"
+	"github.com/filecoin-project/lotus/chain/gasstats"
 	"github.com/filecoin-project/lotus/chain/gen/slashfilter"
 	"github.com/filecoin-project/lotus/chain/index"
+	"github.com/filecoin-project/lotus/chain/lightsync"
 	"github.com/filecoin-project/lotus/chain/market"
 	"github.com/filecoin-project/lotus/chain/messagepool"
+	"github.com/filecoin-project/lotus/chain/messagescheduler"
 	"github.com/filecoin-project/lotus/chain/messagesigner"
+	"github.com/filecoin-project/lotus/chain/spendbudget"
+	"github.com/filecoin-project/lotus/chain/sponsor"
+	"github.com/filecoin-project/lotus/chain/statesync"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	rpcstmgr "github.com/filecoin-project/lotus/chain/stmgr/rpc"
 	"github.com/filecoin-project/lotus/chain/store"
@@ -31,8 +38,11 @@ import (
 	"github.com/filecoin-project/lotus/chain/wallet"
 	ledgerwallet "github.com/filecoin-project/lotus/chain/wallet/ledger"
 	"github.com/filecoin-project/lotus/chain/wallet/remotewallet"
+	"github.com/filecoin-project/lotus/chain/wallet/signpolicy"
 	raftcns "github.com/filecoin-project/lotus/lib/consensus/raft"
+	"github.com/filecoin-project/lotus/lib/maintenance"
 	"github.com/filecoin-project/lotus/lib/peermgr"
+	"github.com/filecoin-project/lotus/lib/rpcreplay"
 	"github.com/filecoin-project/lotus/markets/retrievaladapter"
 	"github.com/filecoin-project/lotus/markets/storageadapter"
 	"github.com/filecoin-project/lotus/node/config"
@@ -57,6 +67,7 @@ var ChainNode = Options(
 	// Consensus settings
 	Override(new(dtypes.DrandSchedule), modules.BuiltinDrandConfig),
 	Override(new(stmgr.UpgradeSchedule), modules.UpgradeSchedule),
+	Override(new(dtypes.UpgradeAnnouncementsFunc), modules.NoUpgradeAnnouncements),
 	Override(new(dtypes.NetworkName), modules.NetworkName),
 	Override(new(modules.Genesis), modules.ErrorGenesis),
 	Override(new(dtypes.AfterGenesisSet), modules.SetGenesis),
@@ -115,6 +126,11 @@ var ChainNode = Options(
 	Override(new(wallet.Default), From(new(*wallet.LocalWallet))),
 	Override(new(api.Wallet), From(new(wallet.MultiWallet))),
 
+	// Service: Scheduled messages
+	Override(new(messagescheduler.API), From(new(modules.MessageSchedulerAPI))),
+	Override(new(*messagescheduler.MessageScheduler), modules.NewMessageScheduler),
+	Override(RunMessageSchedulerKey, modules.RunMessageScheduler),
+
 	// Service: Payment channels
 	Override(new(paychmgr.PaychAPI), From(new(modules.PaychAPI))),
 	Override(new(*paychmgr.Store), modules.NewPaychStore),
@@ -157,6 +173,13 @@ var ChainNode = Options(
 		Override(new(full.EthEventAPI), From(new(api.Gateway))),
 	),
 
+	// Light client: serve the chain from the headers synced locally
+	ApplyIf(isLightSyncNode,
+		Override(new(store.WeightFunc), lightsync.Weight),
+		Override(new(full.ChainModuleAPI), From(new(full.LightChainModule))),
+		Override(new(full.StateModuleAPI), From(new(full.LightStateModule))),
+	),
+
 	// Full node API / service startup
 	ApplyIf(isFullNode,
 		Override(new(messagepool.Provider), messagepool.NewProvider),
@@ -172,6 +195,7 @@ var ChainNode = Options(
 		Override(RunPeerMgrKey, modules.RunPeerMgr),
 		Override(HandleIncomingMessagesKey, modules.HandleIncomingMessages),
 		Override(HandleIncomingBlocksKey, modules.HandleIncomingBlocks),
+		Override(CheckUpgradeScheduleKey, modules.CheckUpgradeSchedule),
 	),
 )
 
@@ -214,10 +238,12 @@ func ConfigFullNode(c interface{}) Option {
 		Override(new(dtypes.ChainBlockstore), From(new(dtypes.BasicChainBlockstore))),
 		Override(new(dtypes.StateBlockstore), From(new(dtypes.BasicStateBlockstore))),
 
-		If(os.Getenv("LOTUS_ENABLE_CHAINSTORE_FALLBACK") == "1",
+		// Fetch blocks missing from the local blockstore on demand, e.g. historical
+		// state on nodes imported from a lightweight snapshot.
+		If(cfg.Chainstore.StateFallback.Enable || os.Getenv("LOTUS_ENABLE_CHAINSTORE_FALLBACK") == "1",
 			Override(new(dtypes.ChainBlockstore), modules.FallbackChainBlockstore),
 			Override(new(dtypes.StateBlockstore), modules.FallbackStateBlockstore),
-			Override(SetupFallbackBlockstoresKey, modules.InitFallbackBlockstores),
+			Override(SetupFallbackBlockstoresKey, modules.InitFallbackBlockstores(cfg.Chainstore.StateFallback)),
 		),
 
 		// If the Eth JSON-RPC is enabled, enable storing events at the ChainStore.
@@ -225,6 +251,12 @@ func ConfigFullNode(c interface{}) Option {
 		// as it enables us to serve logs in eth_getTransactionReceipt.
 		If(cfg.Fevm.EnableEthRPC, Override(StoreEventsKey, modules.EnableStoringEvents)),
 
+		If(len(cfg.Beacon.DrandServers) > 0,
+			Override(new(dtypes.DrandSchedule), modules.ConfiguredDrandSchedule(cfg.Beacon))),
+		Override(new(dtypes.DrandHealthConfig), modules.DrandHealthConfig(cfg.Beacon)),
+		If(len(cfg.Upgrades.Announced) > 0 || cfg.Upgrades.AnnouncementsURL != "",
+			Override(new(dtypes.UpgradeAnnouncementsFunc), modules.ConfiguredUpgradeAnnouncements(cfg.Upgrades))),
+
 		Override(new(dtypes.ClientImportMgr), modules.ClientImportMgr),
 
 		Override(new(dtypes.ClientBlockstore), modules.ClientBlockstore),
@@ -250,6 +282,10 @@ func ConfigFullNode(c interface{}) Option {
 			Unset(new(*wallet.LocalWallet)),
 			Override(new(wallet.Default), wallet.NilDefault),
 		),
+		If(modules.WalletSigningPolicyEnabled(cfg.Wallet.SigningPolicy),
+			Override(new(*signpolicy.PolicyWallet), modules.WalletSigningPolicy(cfg.Wallet.SigningPolicy)),
+			Override(new(api.Wallet), From(new(*signpolicy.PolicyWallet))),
+		),
 
 		// Chain node cluster enabled
 		If(cfg.Cluster.ClusterModeEnabled,
@@ -262,6 +298,26 @@ func ConfigFullNode(c interface{}) Option {
 			Override(GoRPCServer, modules.NewRPCServer),
 		),
 
+		Override(new(*maintenance.Scheduler), modules.MaintenanceScheduler(cfg.Maintenance)),
+		Override(RegisterMaintenanceTasksKey, modules.RegisterMaintenanceTasks(cfg.Maintenance)),
+
+		If(cfg.Snapshots.EnableUpload,
+			Override(RunSnapshotterKey, modules.RunSnapshotter(cfg.Snapshots)),
+		),
+
+		If(cfg.MessageTracing.Enable,
+			Override(SetupMessageTracingKey, modules.SetupMessageTracing(cfg.MessageTracing)),
+		),
+
+		ApplyIf(isFullNode,
+			If(len(cfg.Webhooks.Endpoints) > 0 && len(cfg.Webhooks.SavedQueries) > 0,
+				Override(RunWebhookSavedQueriesKey, modules.WebhookSavedQueries(cfg.Webhooks)),
+			),
+			If(cfg.ChainBridge.Enable,
+				Override(RunChainBridgeKey, modules.RunChainBridge(cfg.ChainBridge)),
+			),
+		),
+
 		// Actor event filtering support
 		Override(new(events.EventAPI), From(new(modules.EventAPI))),
 
@@ -269,17 +325,50 @@ func ConfigFullNode(c interface{}) Option {
 		ApplyIf(isFullNode,
 			If(cfg.Fevm.EnableEthRPC,
 				Override(new(full.EthModuleAPI), modules.EthModuleAPI(cfg.Fevm)),
-				Override(new(full.EthEventAPI), modules.EthEventAPI(cfg.Fevm)),
+				Override(new(full.EthEventAPI), modules.EthEventAPI(cfg.Fevm, cfg.Subscriptions)),
 			),
 			If(!cfg.Fevm.EnableEthRPC,
 				Override(new(full.EthModuleAPI), &full.EthModuleDummy{}),
 				Override(new(full.EthEventAPI), &full.EthModuleDummy{}),
 			),
 		),
+		If(cfg.Fevm.SponsoredCalls.Enable, Override(new(*sponsor.Checker), modules.SponsoredCallChecker(cfg.Fevm.SponsoredCalls))),
 
 		// enable message index for full node when configured by the user, otherwise use dummy.
 		If(cfg.Index.EnableMsgIndex, Override(new(index.MsgIndex), modules.MsgIndex)),
 		If(!cfg.Index.EnableMsgIndex, Override(new(index.MsgIndex), modules.DummyMsgIndex)),
+		If(cfg.Index.EnableAddrIndex, Override(new(index.AddrIndex), modules.AddrIndex)),
+		If(cfg.Index.EnableGasStats, Override(new(*gasstats.Tracker), modules.GasStatsTracker(cfg.Index))),
+		If(cfg.Index.EnableSQLQuery, Override(new(*index.QueryEngine), modules.IndexQueryEngine(cfg.Index, cfg.Fevm))),
+
+		Override(new(*config.RPCExecutionLimits), &cfg.RPCExecutionLimits),
+		Override(new(*config.SlowCallLogConfig), &cfg.SlowCallLog),
+		If(cfg.RPCRecording.Enable, Override(new(*rpcreplay.Recorder), modules.RPCRecorder(cfg.RPCRecording))),
+		If(len(cfg.SpendBudget.Windows) > 0, Override(new(*spendbudget.Governor), modules.SpendBudget(cfg.SpendBudget))),
+		Override(new(*config.ChainDataRESTConfig), &cfg.ChainDataREST),
+		Override(new(*config.SubscriptionsConfig), &cfg.Subscriptions),
+		Override(new(*config.HealthConfig), &cfg.Health),
+		Override(new(*config.UserActorsConfig), &cfg.UserActors),
+		ApplyIf(isLightSyncNode,
+			Override(RunLightSyncKey, modules.RunLightSync(cfg.LightClient)),
+		),
+		ApplyIf(isFullNode,
+			If(cfg.StateSync.Serve, Override(RunStateSyncKey, modules.RunStateSync)),
+			If(len(cfg.StateSync.Checkpoint) > 0,
+				Override(new(*statesync.Bootstrapper), modules.StateSyncBootstrapper(cfg.StateSync)),
+			),
+		),
+		Override(new(*full.ReplayCache), full.NewReplayCache(cfg.StateReplayCache.Size, time.Duration(cfg.StateReplayCache.TTL))),
+
+		If(cfg.Execution.Lanes > 0 || cfg.Execution.ReservedLanes > 0,
+			Override(SetExecutionLanesKey, modules.SetExecutionLanes(cfg.Execution)),
+		),
+		If(cfg.MessageSelection.Selector != "",
+			Override(SetMessageSelectorKey, modules.SetMessageSelector(cfg.MessageSelection)),
+		),
+		If(cfg.SpamFilter.Enable,
+			Override(SetMpoolSpamFilterKey, modules.SetMpoolSpamFilter(cfg.SpamFilter)),
+		),
 	)
 }
 
@@ -292,6 +381,15 @@ func Lite(enable bool) FullOption {
 	}
 }
 
+// LightSync makes lite nodes sync block headers from the upstream node, and
+// serve the chain from them.
+func LightSync(enable bool) FullOption {
+	return func(s *Settings) error {
+		s.LightSync = enable
+		return nil
+	}
+}
+
 func FullAPI(out *api.FullNode, fopts ...FullOption) Option {
 	return Options(
 		func(s *Settings) error {
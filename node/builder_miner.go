@@ -33,6 +33,7 @@ import (
 	"github.com/filecoin-project/lotus/node/repo"
 	"github.com/filecoin-project/lotus/storage"
 	"github.com/filecoin-project/lotus/storage/ctladdr"
+	"github.com/filecoin-project/lotus/storage/hotness"
 	"github.com/filecoin-project/lotus/storage/paths"
 	sealing "github.com/filecoin-project/lotus/storage/pipeline"
 	sectorstorage "github.com/filecoin-project/lotus/storage/sealer"
@@ -93,9 +94,11 @@ func ConfigStorageMiner(c interface{}) Option {
 		Override(new(*paths.Remote), modules.RemoteStorage),
 		Override(new(paths.Store), From(new(*paths.Remote))),
 		Override(new(dtypes.RetrievalPricingFunc), modules.RetrievalPricingFunc(cfg.Dealmaking)),
+		Override(new(*hotness.Tracker), hotness.NewTracker),
 
 		If(cfg.Subsystems.EnableMining || cfg.Subsystems.EnableSealing,
 			Override(GetParamsKey, modules.GetParams(!cfg.Proving.DisableBuiltinWindowPoSt || !cfg.Proving.DisableBuiltinWinningPoSt || cfg.Storage.AllowCommit || cfg.Storage.AllowProveReplicaUpdate2)),
+			Override(CheckProofParamsKey, modules.CheckProofParams(!cfg.Proving.DisableBuiltinWindowPoSt || !cfg.Proving.DisableBuiltinWinningPoSt || cfg.Storage.AllowCommit || cfg.Storage.AllowProveReplicaUpdate2)),
 		),
 
 		If(!cfg.Subsystems.EnableMining,
@@ -116,13 +119,21 @@ func ConfigStorageMiner(c interface{}) Option {
 
 			// Mining / proving
 			Override(new(*slashfilter.SlashFilter), modules.NewSlashFilter),
-			Override(new(*miner.Miner), modules.SetupBlockProducer),
+			Override(new(*miner.Miner), modules.SetupBlockProducer(cfg.Mining)),
 			Override(new(gen.WinningPoStProver), storage.NewWinningPoStProver),
 			Override(PreflightChecksKey, modules.PreflightChecks),
 			Override(new(*sealing.Sealing), modules.SealingPipeline(cfg.Fees)),
 
 			Override(new(*wdpost.WindowPoStScheduler), modules.WindowPostScheduler(cfg.Fees, cfg.Proving)),
 			Override(new(sectorblocks.SectorBuilder), From(new(*sealing.Sealing))),
+
+			If(cfg.Withdrawal.Enable,
+				Override(RunFundsManagerKey, modules.FundsManager(cfg.Withdrawal)),
+			),
+
+			If(len(cfg.Webhooks.Endpoints) > 0,
+				Override(SetupWebhookSectorStatesKey, modules.WebhookSectorStates),
+			),
 		),
 
 		If(cfg.Subsystems.EnableSectorStorage,
@@ -158,6 +169,7 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(*sectorblocks.SectorBlocks), sectorblocks.NewSectorBlocks),
 
 			// Markets (retrieval deps)
+			Override(new(sectorstorage.PieceReadRecorder), From(new(*hotness.Tracker))),
 			Override(new(sectorstorage.PieceProvider), sectorstorage.NewPieceProvider),
 			Override(new(dtypes.RetrievalPricingFunc), modules.RetrievalPricingFunc(config.DealmakingConfig{
 				RetrievalPricing: &config.RetrievalPricing{
@@ -187,11 +199,18 @@ func ConfigStorageMiner(c interface{}) Option {
 			Override(new(idxprov.MeshCreator), idxprov.NewMeshCreator),
 			Override(new(provider.Interface), modules.IndexProvider(cfg.IndexProvider)),
 			Override(new(*storedask.StoredAsk), modules.NewStorageAsk),
+			Override(new(*dealfilter.DecisionLog), modules.DealFilterDecisionLog),
 			Override(new(dtypes.StorageDealFilter), modules.BasicDealFilter(cfg.Dealmaking, nil)),
 			Override(new(storagemarket.StorageProvider), modules.StorageProvider),
 			Override(new(*storageadapter.DealPublisher), storageadapter.NewDealPublisher(nil, storageadapter.PublishMsgConfig{})),
 			Override(HandleMigrateProviderFundsKey, modules.HandleMigrateProviderFunds),
 			Override(HandleDealsKey, modules.HandleDeals),
+			If(len(cfg.Webhooks.Endpoints) > 0,
+				Override(SetupWebhookDealsKey, modules.WebhookDeals),
+			),
+			If(cfg.Dealmaking.AskAutomation.Enable,
+				Override(RunStorageAskAutomationKey, modules.StorageAskAutomation(cfg.Dealmaking.AskAutomation)),
+			),
 
 			// Config (todo: get a real property system)
 			Override(new(dtypes.ConsiderOnlineStorageDealsConfigFunc), modules.NewConsiderOnlineStorageDealsConfigFunc),
@@ -49,6 +49,12 @@ func defCommon() Common {
 			SubsystemLevels: map[string]string{
 				"example-subsystem": "INFO",
 			},
+			File: LogFile{
+				MaxSizeMB:  100,
+				MaxAge:     Duration(30 * 24 * time.Hour),
+				MaxBackups: 10,
+				Compress:   true,
+			},
 		},
 		Backup: Backup{
 			DisableMetadataLog: true,
@@ -69,6 +75,12 @@ func defCommon() Common {
 			Bootstrapper: false,
 			DirectPeers:  nil,
 		},
+		ClockCheck: ClockCheck{
+			Enable:    false,
+			Servers:   []string{"pool.ntp.org", "time.cloudflare.com"},
+			Interval:  Duration(10 * time.Minute),
+			Threshold: Duration(time.Second),
+		},
 	}
 }
 
@@ -105,6 +117,7 @@ func DefaultFullNode() *FullNode {
 		Fevm: FevmConfig{
 			EnableEthRPC:                 false,
 			EthTxHashMappingLifetimeDays: 0,
+			EthNullRoundPolicy:           "error",
 			Events: Events{
 				DisableRealTimeFilterAPI: false,
 				DisableHistoricFilterAPI: false,
@@ -113,6 +126,103 @@ func DefaultFullNode() *FullNode {
 				MaxFilterResults:         10000,
 				MaxFilterHeightRange:     2880, // conservative limit of one day
 			},
+			SponsoredCalls: SponsoredCallsConfig{
+				MaxValue:        "0",
+				MaxCallsPerHour: 10,
+			},
+		},
+		Index: IndexConfig{
+			GasStatsWindow:  2880,
+			SQLQueryMaxRows: 1000,
+			SQLQueryTimeout: Duration(10 * time.Second),
+		},
+		Snapshots: SnapshotsConfig{
+			EnableUpload:     false,
+			Schedule:         "0 0 * * *",
+			Confidence:       20,
+			RecentStateRoots: 2000,
+			Region:           "us-east-1",
+			Retention:        7,
+		},
+		Beacon: BeaconConfig{
+			FetchTimeout:        Duration(30 * time.Second),
+			UnhealthyAfter:      3,
+			RetryUnhealthyAfter: Duration(time.Minute),
+		},
+		Health: HealthConfig{
+			MaxSyncLag:        5,
+			MinPeers:          1,
+			MaxAPILatency:     Duration(5 * time.Second),
+			MaxEventsIndexLag: 10,
+		},
+		MessageTracing: MessageTracingConfig{
+			Endpoint:   "http://localhost:4318/v1/traces",
+			SampleRate: 0.01,
+		},
+		StateReplayCache: StateReplayCacheConfig{
+			Size: 1000,
+			TTL:  Duration(time.Hour),
+		},
+		MessageSelection: MessageSelectionConfig{
+			Selector: "default",
+		},
+		UserActors: UserActorsConfig{
+			Enable:      false,
+			MaxCodeSize: 2 << 20,
+		},
+		SlowCallLog: SlowCallLogConfig{
+			Enable:          false,
+			Threshold:       Duration(2 * time.Second),
+			MaxParamsLength: 512,
+		},
+		RPCRecording: RPCRecordingConfig{
+			Enable:          false,
+			Methods:         []string{"eth_", "net_", "web3_", "Filecoin.Eth"},
+			SampleRate:      1,
+			MaxFileSize:     256 << 20,
+			MaxFiles:        8,
+			MaxResponseSize: 1 << 20,
+		},
+		ChainDataREST: ChainDataRESTConfig{
+			Enable:          false,
+			MaxAge:          Duration(30 * time.Second),
+			FinalizedMaxAge: Duration(24 * time.Hour),
+		},
+		LightClient: LightClientConfig{
+			SyncInterval: Duration(10 * time.Second),
+		},
+		Subscriptions: SubscriptionsConfig{
+			ResumeWindow: 900,
+		},
+		StateSync: StateSyncConfig{
+			StateEpochs: 900,
+		},
+		SpendBudget: SpendBudgetConfig{
+			QueueTimeout: Duration(10 * time.Minute),
+		},
+		SpamFilter: SpamFilterConfig{
+			Enable:             false,
+			MaxSenderPushes:    20,
+			SenderRateInterval: Duration(time.Minute),
+			PressureThreshold:  0.8,
+			PressureMinPremium: types.MustParseFIL("100000 attofil"),
+			RecentWindow:       Duration(10 * time.Minute),
+			MaxReplacements:    5,
+			BanThreshold:       30,
+			BanDuration:        Duration(30 * time.Minute),
+		},
+		ChainBridge: ChainBridgeConfig{
+			Backend:       "kafka",
+			TopicPrefix:   "lotus.",
+			Receipts:      true,
+			RetryInterval: Duration(10 * time.Second),
+		},
+		Wallet: Wallet{
+			SigningPolicy: WalletSigningPolicy{
+				RateInterval:      Duration(time.Minute),
+				ApprovalThreshold: types.MustParseFIL("0"),
+				ApprovalTimeout:   Duration(10 * time.Minute),
+			},
 		},
 	}
 }
@@ -155,6 +265,14 @@ func DefaultStorageMiner() *StorageMiner {
 			TerminateBatchMax:                      100,
 			TerminateBatchWait:                     Duration(5 * time.Minute),
 			MaxSectorProveCommitsSubmittedPerEpoch: 20,
+
+			ClientPlacement: []ClientPlacementRule{},
+			RetryPolicies:   []SealingRetryPolicy{},
+
+			UnsealedCopyPolicies: []UnsealedCopyPolicy{},
+
+			RemoveExpiredSectors:      false,
+			RemoveExpiredSectorsDelay: Duration(24 * time.Hour),
 		},
 
 		Proving: ProvingConfig{
@@ -182,6 +300,10 @@ func DefaultStorageMiner() *StorageMiner {
 
 			// By default use the hardware resource filtering strategy.
 			ResourceFiltering: ResourceFilteringHardware,
+
+			TaskHistoryRetention: Duration(7 * 24 * time.Hour),
+
+			PreemptionPolicy: "suspend",
 		},
 
 		Dealmaking: DealmakingConfig{
@@ -214,6 +336,11 @@ func DefaultStorageMiner() *StorageMiner {
 					Path: "",
 				},
 			},
+
+			AskAutomation: StorageAskAutomationConfig{
+				Interval:    Duration(time.Hour),
+				AskDuration: Duration(30 * 24 * time.Hour),
+			},
 		},
 
 		IndexProvider: IndexProviderConfig{
@@ -257,6 +384,19 @@ func DefaultStorageMiner() *StorageMiner {
 			CommitControl:      []string{},
 			TerminateControl:   []string{},
 			DealPublishControl: []string{},
+			WindowPoStControl:  []string{},
+		},
+
+		Withdrawal: MinerWithdrawalConfig{
+			Float:            types.MustParseFIL("0"),
+			Threshold:        types.MustParseFIL("10"),
+			CheckInterval:    Duration(time.Hour),
+			MaxPerWithdrawal: types.MustParseFIL("0"),
+			MaxPerDay:        types.MustParseFIL("0"),
+		},
+
+		Mining: MiningConfig{
+			MessageOrdering: "fee",
 		},
 
 		DAGStore: DAGStoreConfig{
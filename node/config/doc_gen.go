@@ -29,6 +29,20 @@ var Doc = map[string][]DocField{
 			Comment: ``,
 		},
 	},
+	"AnnouncedUpgrade": []DocField{
+		{
+			Name: "Network",
+			Type: "uint",
+
+			Comment: `Network is the network version the upgrade upgrades to`,
+		},
+		{
+			Name: "Height",
+			Type: "int64",
+
+			Comment: `Height is the epoch of the upgrade`,
+		},
+	},
 	"Backup": []DocField{
 		{
 			Name: "DisableMetadataLog",
@@ -55,6 +69,134 @@ your node if metadata log is disabled`,
 			Comment: ``,
 		},
 	},
+	"BeaconConfig": []DocField{
+		{
+			Name: "DrandServers",
+			Type: "[]DrandServer",
+
+			Comment: `DrandServers are the HTTP endpoints of drand relays fetched from in
+addition to the built-in ones, each for the drand network it serves.
+Servers failing repeatedly are marked unhealthy and fetches fail over to
+the healthy ones.`,
+		},
+		{
+			Name: "ReplaceDefaultServers",
+			Type: "bool",
+
+			Comment: `ReplaceDefaultServers only fetches from DrandServers, without the built-in
+servers, for the drand networks servers are configured for.`,
+		},
+		{
+			Name: "FetchTimeout",
+			Type: "Duration",
+
+			Comment: `FetchTimeout bounds the time spent fetching a beacon entry from all
+servers, after which the round is counted as missed.`,
+		},
+		{
+			Name: "UnhealthyAfter",
+			Type: "int",
+
+			Comment: `UnhealthyAfter is the number of consecutive failed fetches after which a
+server is marked unhealthy. An alert is raised when all servers are
+unhealthy.`,
+		},
+		{
+			Name: "RetryUnhealthyAfter",
+			Type: "Duration",
+
+			Comment: `RetryUnhealthyAfter is the time after which an unhealthy server is tried
+again.`,
+		},
+	},
+	"ChainBridgeConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable publishes the applied and reverted tipsets to Kafka or NATS, along
+with the receipts of the executed messages and the actor events matching
+EventQueries. The position of the bridge in the chain is persisted once
+the broker acknowledged the records, and publishing resumes from it after
+restarts and broker outages, so records are published at least once;
+consumers can drop duplicates by the record IDs, sent as the Kafka
+message key or the NATS message ID. The bridge can only resume within
+Subscriptions.ResumeWindow epochs, past it the bridge stops until its
+cursor is reset with 'lotus-shed chain-bridge reset-cursor'.
+The Kafka and NATS clients are only built into the node with the
+mqbridge build tag.`,
+		},
+		{
+			Name: "Backend",
+			Type: "string",
+
+			Comment: `Backend is the message queue published to, "kafka" or "nats".`,
+		},
+		{
+			Name: "Servers",
+			Type: "[]string",
+
+			Comment: `Servers are the Kafka bootstrap brokers, as host:port, or the NATS
+server URLs.`,
+		},
+		{
+			Name: "TopicPrefix",
+			Type: "string",
+
+			Comment: `TopicPrefix prefixes the names of the Kafka topics, or NATS subjects,
+the records are published to: <prefix>tipsets, <prefix>receipts and
+<prefix>events. The Kafka topics must exist, records are written to
+their first partition to keep them in chain order. The NATS subjects
+must be captured by a JetStream stream.`,
+		},
+		{
+			Name: "Receipts",
+			Type: "bool",
+
+			Comment: `Receipts publishes the receipts of the executed messages.`,
+		},
+		{
+			Name: "EventQueries",
+			Type: "[]WebhookSavedQuery",
+
+			Comment: `EventQueries select the actor events published, in the format of
+Webhooks.SavedQueries; f0 addresses are also accepted. Events are
+published once per matching query.`,
+		},
+		{
+			Name: "RetryInterval",
+			Type: "Duration",
+
+			Comment: `RetryInterval is the wait before resuming after a failure to publish.`,
+		},
+	},
+	"ChainDataRESTConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable serving chain data as JSON over plain HTTP GET requests under
+/rest/v0/chain on the API endpoint: tipsets by height, messages by CID,
+address balances and transaction receipts. The endpoints only read
+public chain data and don't require an API token. Responses carry ETags
+and Cache-Control headers, so that they can be cached by browsers and
+CDNs.`,
+		},
+		{
+			Name: "MaxAge",
+			Type: "Duration",
+
+			Comment: `MaxAge is the time responses about data which can still change, such as
+the balance of an address at the head, may be cached for.`,
+		},
+		{
+			Name: "FinalizedMaxAge",
+			Type: "Duration",
+
+			Comment: `FinalizedMaxAge is the time responses about data which can't change
+anymore, such as messages or tipsets past finality, may be cached for.`,
+		},
+	},
 	"Chainstore": []DocField{
 		{
 			Name: "EnableSplitstore",
@@ -66,6 +208,12 @@ your node if metadata log is disabled`,
 			Name: "Splitstore",
 			Type: "Splitstore",
 
+			Comment: ``,
+		},
+		{
+			Name: "StateFallback",
+			Type: "StateFallback",
+
 			Comment: ``,
 		},
 	},
@@ -117,6 +265,52 @@ without existing payment channels with available funds will fail instead
 of automatically performing on-chain operations.`,
 		},
 	},
+	"ClientPlacementRule": []DocField{
+		{
+			Name: "Client",
+			Type: "string",
+
+			Comment: `Client address (ID or robust) the rule applies to`,
+		},
+		{
+			Name: "Groups",
+			Type: "[]string",
+
+			Comment: `Storage path groups deals from the client can be stored in`,
+		},
+	},
+	"ClockCheck": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable periodically measures the offset of the system clock against NTP
+servers, and raises an alert when it's above Threshold. A clock running
+behind rejects valid blocks as being from the future, and a clock
+running ahead mines blocks which arrive late. It's disabled by default,
+as it sends requests to the Servers, which are third parties.`,
+		},
+		{
+			Name: "Servers",
+			Type: "[]string",
+
+			Comment: `Servers are the NTP servers queried in turn until one answers, as a host
+with an optional port.`,
+		},
+		{
+			Name: "Interval",
+			Type: "Duration",
+
+			Comment: `Interval is the time between measurements.`,
+		},
+		{
+			Name: "Threshold",
+			Type: "Duration",
+
+			Comment: `Threshold is the offset above which the alert is raised, and block
+validation failures are explained by the skew.`,
+		},
+	},
 	"Common": []DocField{
 		{
 			Name: "API",
@@ -146,6 +340,18 @@ of automatically performing on-chain operations.`,
 			Name: "Pubsub",
 			Type: "Pubsub",
 
+			Comment: ``,
+		},
+		{
+			Name: "Webhooks",
+			Type: "Webhooks",
+
+			Comment: ``,
+		},
+		{
+			Name: "ClockCheck",
+			Type: "ClockCheck",
+
 			Comment: ``,
 		},
 	},
@@ -326,6 +532,16 @@ regardless of this number.`,
 
 			Comment: `A command used for fine-grained evaluation of storage deals
 see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#using-filters-for-fine-grained-storage-and-retrieval-deal-acceptance for more details`,
+		},
+		{
+			Name: "FilterRules",
+			Type: "string",
+
+			Comment: `Path to a JSON file with the rules of the built-in storage deal filter:
+price floors of verified and unverified deals, client allow and block
+lists, and per-client rate limits. The file is reloaded when it changes.
+The rules are applied before the Filter command, and the decisions of
+the filters are logged, see DealsFilterDecisions.`,
 		},
 		{
 			Name: "RetrievalFilter",
@@ -340,6 +556,27 @@ see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#
 
 			Comment: ``,
 		},
+		{
+			Name: "AskAutomation",
+			Type: "StorageAskAutomationConfig",
+
+			Comment: `Automatic adjustment of the storage ask by storage utilization`,
+		},
+	},
+	"DrandServer": []DocField{
+		{
+			Name: "Chain",
+			Type: "string",
+
+			Comment: `Chain is the chain hash of the drand network the server serves, as listed
+in the /info of the server`,
+		},
+		{
+			Name: "URL",
+			Type: "string",
+
+			Comment: `URL of the HTTP endpoint of the server`,
+		},
 	},
 	"Events": []DocField{
 		{
@@ -393,6 +630,74 @@ support the historic filter APIs. If the database does not exist it will be crea
 the database must already exist and be writeable. If a relative path is provided here, sqlite treats it as
 relative to the CWD (current working directory).`,
 		},
+		{
+			Name: "IndexServiceAddr",
+			Type: "string",
+
+			Comment: `IndexServiceAddr is the JSON-RPC address of a shared event index service, such as
+ws://10.0.0.5:1237/rpc/v0, used instead of a local database for the historic filter APIs.
+This lets the nodes of an RPC fleet share a single events database. DatabasePath is
+ignored when set.`,
+		},
+		{
+			Name: "IndexServiceToken",
+			Type: "string",
+
+			Comment: `IndexServiceToken is sent as a bearer token to the event index service.`,
+		},
+		{
+			Name: "IndexServiceReadOnly",
+			Type: "bool",
+
+			Comment: `IndexServiceReadOnly makes the node only query the event index service, without storing
+the events it applies, for fleets where a single node writes to the service.`,
+		},
+		{
+			Name: "IndexAllowedAddresses",
+			Type: "[]string",
+
+			Comment: `IndexAllowedAddresses only stores the events of these contracts in the
+historic index, as f4 or 0x addresses, for nodes serving the events of a
+few contracts; the events of all contracts are stored when empty.
+Historic queries of other contracts fail, and historic queries without
+addresses only return the events of these contracts.`,
+		},
+		{
+			Name: "IndexDeniedAddresses",
+			Type: "[]string",
+
+			Comment: `IndexDeniedAddresses never stores the events of these contracts in the
+historic index, as f4 or 0x addresses, like contracts emitting many
+events nobody queries. Historic queries of these contracts fail.`,
+		},
+		{
+			Name: "IndexPruneNotRetained",
+			Type: "bool",
+
+			Comment: `IndexPruneNotRetained deletes the events of the contracts which aren't
+retained by IndexAllowedAddresses and IndexDeniedAddresses from a local
+index when the node starts. The events can't be restored without
+reindexing, so they are kept unless this is set.`,
+		},
+	},
+	"ExecutionConfig": []DocField{
+		{
+			Name: "Lanes",
+			Type: "int",
+
+			Comment: `Lanes is the number of VM executions run concurrently. When set, it also
+sizes the FVM thread pool, unless LOTUS_FVM_CONCURRENCY is set.
+0 uses LOTUS_FVM_CONCURRENCY, or 4 when it's not set.`,
+		},
+		{
+			Name: "ReservedLanes",
+			Type: "int",
+
+			Comment: `ReservedLanes is the number of lanes only chain validation runs in, so
+that RPC driven executions (eth_call, gas estimation, replays) never
+delay the validation of the next tipset. It must be lower than Lanes.
+0 uses LOTUS_FVM_CONCURRENCY_RESERVED, or 2 when it's not set.`,
+		},
 	},
 	"FeeConfig": []DocField{
 		{
@@ -416,6 +721,15 @@ This will also enable the RealTimeFilterAPI and HistoricFilterAPI by default, bu
 
 			Comment: `EthTxHashMappingLifetimeDays the transaction hash lookup database will delete mappings that have been stored for more than x days
 Set to 0 to keep all mappings`,
+		},
+		{
+			Name: "EthNullRoundPolicy",
+			Type: "string",
+
+			Comment: `EthNullRoundPolicy selects what eth_getBlockByNumber returns when the requested height is a null round:
+"error"    - return an error, as the height has no tipset (default)
+"previous" - return the closest non-null tipset below the requested height, with its own block number
+"empty"    - return a synthetic block without transactions at the requested height, on top of the previous tipset`,
 		},
 		{
 			Name: "Events",
@@ -423,6 +737,14 @@ Set to 0 to keep all mappings`,
 
 			Comment: ``,
 		},
+		{
+			Name: "SponsoredCalls",
+			Type: "SponsoredCallsConfig",
+
+			Comment: `SponsoredCalls configures the contract calls relayed through ERC-2771
+forwarders with EthSendSponsoredCall, paid for by sponsor addresses of
+the node wallet.`,
+		},
 	},
 	"FullNode": []DocField{
 		{
@@ -467,133 +789,466 @@ Set to 0 to keep all mappings`,
 
 			Comment: ``,
 		},
-	},
-	"IndexConfig": []DocField{
 		{
-			Name: "EnableMsgIndex",
-			Type: "bool",
+			Name: "Snapshots",
+			Type: "SnapshotsConfig",
 
-			Comment: `EnableMsgIndex enables indexing of messages on chain.`,
+			Comment: ``,
 		},
-	},
-	"IndexProviderConfig": []DocField{
 		{
-			Name: "Enable",
-			Type: "bool",
+			Name: "Beacon",
+			Type: "BeaconConfig",
 
-			Comment: `Enable set whether to enable indexing announcement to the network and expose endpoints that
-allow indexer nodes to process announcements. Enabled by default.`,
+			Comment: ``,
 		},
 		{
-			Name: "EntriesCacheCapacity",
-			Type: "int",
+			Name: "Maintenance",
+			Type: "MaintenanceConfig",
 
-			Comment: `EntriesCacheCapacity sets the maximum capacity to use for caching the indexing advertisement
-entries. Defaults to 1024 if not specified. The cache is evicted using LRU policy. The
-maximum storage used by the cache is a factor of EntriesCacheCapacity, EntriesChunkSize and
-the length of multihashes being advertised. For example, advertising 128-bit long multihashes
-with the default EntriesCacheCapacity, and EntriesChunkSize means the cache size can grow to
-256MiB when full.`,
+			Comment: ``,
 		},
 		{
-			Name: "EntriesChunkSize",
-			Type: "int",
+			Name: "RPCExecutionLimits",
+			Type: "RPCExecutionLimits",
 
-			Comment: `EntriesChunkSize sets the maximum number of multihashes to include in a single entries chunk.
-Defaults to 16384 if not specified. Note that chunks are chained together for indexing
-advertisements that include more multihashes than the configured EntriesChunkSize.`,
+			Comment: ``,
 		},
 		{
-			Name: "TopicName",
-			Type: "string",
+			Name: "SlowCallLog",
+			Type: "SlowCallLogConfig",
 
-			Comment: `TopicName sets the topic name on which the changes to the advertised content are announced.
-If not explicitly specified, the topic name is automatically inferred from the network name
-in following format: '/indexer/ingest/<network-name>'
-Defaults to empty, which implies the topic name is inferred from network name.`,
+			Comment: ``,
 		},
 		{
-			Name: "PurgeCacheOnStart",
-			Type: "bool",
+			Name: "RPCRecording",
+			Type: "RPCRecordingConfig",
 
-			Comment: `PurgeCacheOnStart sets whether to clear any cached entries chunks when the provider engine
-starts. By default, the cache is rehydrated from previously cached entries stored in
-datastore if any is present.`,
+			Comment: ``,
 		},
-	},
-	"Libp2p": []DocField{
 		{
-			Name: "ListenAddresses",
-			Type: "[]string",
+			Name: "ChainDataREST",
+			Type: "ChainDataRESTConfig",
 
-			Comment: `Binding address for the libp2p host - 0 means random port.
-Format: multiaddress; see https://multiformats.io/multiaddr/`,
+			Comment: ``,
 		},
 		{
-			Name: "AnnounceAddresses",
-			Type: "[]string",
+			Name: "MessageTracing",
+			Type: "MessageTracingConfig",
 
-			Comment: `Addresses to explicitally announce to other peers. If not specified,
-all interface addresses are announced
-Format: multiaddress`,
+			Comment: ``,
 		},
 		{
-			Name: "NoAnnounceAddresses",
-			Type: "[]string",
+			Name: "Health",
+			Type: "HealthConfig",
 
-			Comment: `Addresses to not announce
-Format: multiaddress`,
+			Comment: ``,
 		},
 		{
-			Name: "BootstrapPeers",
-			Type: "[]string",
+			Name: "StateReplayCache",
+			Type: "StateReplayCacheConfig",
 
 			Comment: ``,
 		},
 		{
-			Name: "ProtectedPeers",
-			Type: "[]string",
+			Name: "Execution",
+			Type: "ExecutionConfig",
 
 			Comment: ``,
 		},
 		{
-			Name: "DisableNatPortMap",
-			Type: "bool",
+			Name: "MessageSelection",
+			Type: "MessageSelectionConfig",
 
-			Comment: `When not disabled (default), lotus asks NAT devices (e.g., routers), to
-open up an external port and forward it to the port lotus is running on.
-When this works (i.e., when your router supports NAT port forwarding),
-it makes the local lotus node accessible from the public internet`,
+			Comment: ``,
 		},
 		{
-			Name: "ConnMgrLow",
-			Type: "uint",
+			Name: "UserActors",
+			Type: "UserActorsConfig",
 
-			Comment: `ConnMgrLow is the number of connections that the basic connection manager
-will trim down to.`,
+			Comment: ``,
 		},
 		{
-			Name: "ConnMgrHigh",
-			Type: "uint",
+			Name: "LightClient",
+			Type: "LightClientConfig",
 
-			Comment: `ConnMgrHigh is the number of connections that, when exceeded, will trigger
-a connection GC operation. Note: protected/recently formed connections don't
-count towards this limit.`,
+			Comment: ``,
 		},
 		{
-			Name: "ConnMgrGrace",
-			Type: "Duration",
+			Name: "Subscriptions",
+			Type: "SubscriptionsConfig",
 
-			Comment: `ConnMgrGrace is a time duration that new connections are immune from being
-closed by the connection manager.`,
+			Comment: ``,
 		},
-	},
-	"Logging": []DocField{
+		{
+			Name: "StateSync",
+			Type: "StateSyncConfig",
+
+			Comment: ``,
+		},
+		{
+			Name: "SpendBudget",
+			Type: "SpendBudgetConfig",
+
+			Comment: ``,
+		},
+		{
+			Name: "SpamFilter",
+			Type: "SpamFilterConfig",
+
+			Comment: ``,
+		},
+		{
+			Name: "ChainBridge",
+			Type: "ChainBridgeConfig",
+
+			Comment: ``,
+		},
+		{
+			Name: "Upgrades",
+			Type: "UpgradesConfig",
+
+			Comment: ``,
+		},
+	},
+	"HealthConfig": []DocField{
+		{
+			Name: "MaxSyncLag",
+			Type: "uint64",
+
+			Comment: `MaxSyncLag is the number of epochs the chain head can be behind the
+current epoch for the node to be ready.`,
+		},
+		{
+			Name: "MinPeers",
+			Type: "int",
+
+			Comment: `MinPeers is the number of connected peers required for the node to be
+ready.`,
+		},
+		{
+			Name: "MaxAPILatency",
+			Type: "Duration",
+
+			Comment: `MaxAPILatency is the time within which the node must load the chain head
+to be ready.`,
+		},
+		{
+			Name: "MaxEventsIndexLag",
+			Type: "int64",
+
+			Comment: `MaxEventsIndexLag is the number of epochs actor events can be processed
+behind the chain head for the node to be ready. Only checked when the
+Eth RPC events are enabled; 0 disables the check.`,
+		},
+	},
+	"IndexConfig": []DocField{
+		{
+			Name: "EnableMsgIndex",
+			Type: "bool",
+
+			Comment: `EnableMsgIndex enables indexing of messages on chain.`,
+		},
+		{
+			Name: "EnableAddrIndex",
+			Type: "bool",
+
+			Comment: `EnableAddrIndex enables indexing the messages sent and received by each
+address, served by the StateListAddressMessages API. Only the tipsets
+applied after the index is enabled are indexed.`,
+		},
+		{
+			Name: "EnableGasStats",
+			Type: "bool",
+
+			Comment: `EnableGasStats enables aggregating the gas used by executed messages per
+actor code and method, served by the StateGasStats API.`,
+		},
+		{
+			Name: "GasStatsWindow",
+			Type: "int64",
+
+			Comment: `GasStatsWindow is the number of recent epochs gas stats are kept for.`,
+		},
+		{
+			Name: "EnableSQLQuery",
+			Type: "bool",
+
+			Comment: `EnableSQLQuery enables the ChainIndexQuery API, running read-only SQL
+queries over the events, transaction hash and message index databases.`,
+		},
+		{
+			Name: "SQLQueryMaxRows",
+			Type: "int",
+
+			Comment: `SQLQueryMaxRows is the maximum number of rows returned by a query.`,
+		},
+		{
+			Name: "SQLQueryTimeout",
+			Type: "Duration",
+
+			Comment: `SQLQueryTimeout is the maximum duration of a query.`,
+		},
+	},
+	"IndexProviderConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable set whether to enable indexing announcement to the network and expose endpoints that
+allow indexer nodes to process announcements. Enabled by default.`,
+		},
+		{
+			Name: "EntriesCacheCapacity",
+			Type: "int",
+
+			Comment: `EntriesCacheCapacity sets the maximum capacity to use for caching the indexing advertisement
+entries. Defaults to 1024 if not specified. The cache is evicted using LRU policy. The
+maximum storage used by the cache is a factor of EntriesCacheCapacity, EntriesChunkSize and
+the length of multihashes being advertised. For example, advertising 128-bit long multihashes
+with the default EntriesCacheCapacity, and EntriesChunkSize means the cache size can grow to
+256MiB when full.`,
+		},
+		{
+			Name: "EntriesChunkSize",
+			Type: "int",
+
+			Comment: `EntriesChunkSize sets the maximum number of multihashes to include in a single entries chunk.
+Defaults to 16384 if not specified. Note that chunks are chained together for indexing
+advertisements that include more multihashes than the configured EntriesChunkSize.`,
+		},
+		{
+			Name: "TopicName",
+			Type: "string",
+
+			Comment: `TopicName sets the topic name on which the changes to the advertised content are announced.
+If not explicitly specified, the topic name is automatically inferred from the network name
+in following format: '/indexer/ingest/<network-name>'
+Defaults to empty, which implies the topic name is inferred from network name.`,
+		},
+		{
+			Name: "PurgeCacheOnStart",
+			Type: "bool",
+
+			Comment: `PurgeCacheOnStart sets whether to clear any cached entries chunks when the provider engine
+starts. By default, the cache is rehydrated from previously cached entries stored in
+datastore if any is present.`,
+		},
+	},
+	"Libp2p": []DocField{
+		{
+			Name: "ListenAddresses",
+			Type: "[]string",
+
+			Comment: `Binding address for the libp2p host - 0 means random port.
+Format: multiaddress; see https://multiformats.io/multiaddr/`,
+		},
+		{
+			Name: "AnnounceAddresses",
+			Type: "[]string",
+
+			Comment: `Addresses to explicitally announce to other peers. If not specified,
+all interface addresses are announced
+Format: multiaddress`,
+		},
+		{
+			Name: "NoAnnounceAddresses",
+			Type: "[]string",
+
+			Comment: `Addresses to not announce
+Format: multiaddress`,
+		},
+		{
+			Name: "BootstrapPeers",
+			Type: "[]string",
+
+			Comment: ``,
+		},
+		{
+			Name: "ProtectedPeers",
+			Type: "[]string",
+
+			Comment: ``,
+		},
+		{
+			Name: "DisableNatPortMap",
+			Type: "bool",
+
+			Comment: `When not disabled (default), lotus asks NAT devices (e.g., routers), to
+open up an external port and forward it to the port lotus is running on.
+When this works (i.e., when your router supports NAT port forwarding),
+it makes the local lotus node accessible from the public internet`,
+		},
+		{
+			Name: "ConnMgrLow",
+			Type: "uint",
+
+			Comment: `ConnMgrLow is the number of connections that the basic connection manager
+will trim down to.`,
+		},
+		{
+			Name: "ConnMgrHigh",
+			Type: "uint",
+
+			Comment: `ConnMgrHigh is the number of connections that, when exceeded, will trigger
+a connection GC operation. Note: protected/recently formed connections don't
+count towards this limit.`,
+		},
+		{
+			Name: "ConnMgrGrace",
+			Type: "Duration",
+
+			Comment: `ConnMgrGrace is a time duration that new connections are immune from being
+closed by the connection manager.`,
+		},
+	},
+	"LightClientConfig": []DocField{
+		{
+			Name: "Checkpoint",
+			Type: "[]string",
+
+			Comment: `Checkpoint is the tipset a light client (lotus daemon --light) starts
+syncing block headers from, as the CIDs of its blocks. It should be a
+final tipset obtained from a trusted source. When empty, the upstream
+node is trusted for the tipset at finality below its head.`,
+		},
+		{
+			Name: "SyncInterval",
+			Type: "Duration",
+
+			Comment: `SyncInterval is how often a light client syncs block headers from the
+upstream node.`,
+		},
+	},
+	"LogFile": []DocField{
+		{
+			Name: "Path",
+			Type: "string",
+
+			Comment: `Path of the log file. Defaults to the file set with the GOLOG_FILE
+environment variable; logs aren't written to a file when neither is set.`,
+		},
+		{
+			Name: "MaxSizeMB",
+			Type: "int64",
+
+			Comment: `MaxSizeMB is the size in megabytes after which the log file is rotated.
+0 disables rotation.`,
+		},
+		{
+			Name: "MaxAge",
+			Type: "Duration",
+
+			Comment: `MaxAge is the age after which rotated log files are deleted. 0 keeps
+them regardless of their age.`,
+		},
+		{
+			Name: "MaxBackups",
+			Type: "int",
+
+			Comment: `MaxBackups is the number of rotated log files kept. 0 keeps all of them.`,
+		},
+		{
+			Name: "Compress",
+			Type: "bool",
+
+			Comment: `Compress rotated log files with gzip.`,
+		},
+	},
+	"Logging": []DocField{
 		{
 			Name: "SubsystemLevels",
 			Type: "map[string]string",
 
-			Comment: `SubsystemLevels specify per-subsystem log levels`,
+			Comment: `SubsystemLevels specify per-subsystem log levels. Levels set with the
+log set-level command are persisted in the repo and take precedence.`,
+		},
+		{
+			Name: "File",
+			Type: "LogFile",
+
+			Comment: `File configures the rotation of the log file`,
+		},
+	},
+	"MaintenanceConfig": []DocField{
+		{
+			Name: "Windows",
+			Type: "[]MaintenanceWindow",
+
+			Comment: `Windows are the maintenance windows, the only times expensive background
+activities run at: splitstore compaction, chain snapshot exports and the
+maintenance tasks enabled below. When no windows are configured, these
+activities run whenever they are due, and the maintenance tasks don't run.`,
+		},
+		{
+			Name: "BadgerGC",
+			Type: "bool",
+
+			Comment: `BadgerGC enables running the value log garbage collection of the badger
+chain blockstore at the start of each window. It has no effect with the
+splitstore enabled, which garbage collects the blockstore itself.`,
+		},
+		{
+			Name: "VacuumSQLite",
+			Type: "bool",
+
+			Comment: `VacuumSQLite enables vacuuming the SQLite databases of the events index
+and of the Ethereum transaction hash lookup at the start of each window,
+to reclaim the space of deleted rows.`,
+		},
+	},
+	"MaintenanceWindow": []DocField{
+		{
+			Name: "Schedule",
+			Type: "string",
+
+			Comment: `Schedule is a cron expression (minute, hour, day of month, month, day of
+week) specifying when the window opens, e.g. "0 2 * * *" for 2am every
+day, in the local time zone unless prefixed with e.g. "CRON_TZ=UTC".
+Descriptors like "@daily" are also supported.`,
+		},
+		{
+			Name: "Duration",
+			Type: "Duration",
+
+			Comment: `Duration is how long the window stays open.`,
+		},
+	},
+	"MessageSelectionConfig": []DocField{
+		{
+			Name: "Selector",
+			Type: "string",
+
+			Comment: `Selector is the name of the policy selecting the messages of the blocks
+mined through this node: "default" uses "greedy" selection for blocks
+with a good ticket and "optimal" selection otherwise. Nodes built with
+custom policies can use the names they register with
+messagepool.RegisterSelector.`,
+		},
+	},
+	"MessageTracingConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable exports the messages of sampled tipsets executed by the node as
+OpenTelemetry traces, one trace per message with a span for each
+internal call.`,
+		},
+		{
+			Name: "Endpoint",
+			Type: "string",
+
+			Comment: `Endpoint is the OTLP/HTTP traces endpoint of the collector spans are
+sent to, using the JSON encoding.`,
+		},
+		{
+			Name: "SampleRate",
+			Type: "float64",
+
+			Comment: `SampleRate is the fraction of executed tipsets whose messages are traced.
+Tipsets are sampled by their key, so nodes with the same rate trace the
+same tipsets. Sampled tipsets are executed with detailed VM tracing,
+which makes their execution slower.`,
 		},
 	},
 	"MinerAddressConfig": []DocField{
@@ -621,6 +1276,16 @@ closed by the connection manager.`,
 
 			Comment: ``,
 		},
+		{
+			Name: "WindowPoStControl",
+			Type: "[]string",
+
+			Comment: `Ordered list of addresses to send WindowPoSt messages (proofs, fault and
+recovery declarations) from. The first address with enough funds, and with
+at most a couple of messages pending in the mpool, is used. When none
+qualify, the worker and owner fallbacks apply as for other messages.
+If empty, control addresses not configured for other uses are used.`,
+		},
 		{
 			Name: "DisableOwnerFallback",
 			Type: "bool",
@@ -726,6 +1391,78 @@ over the worker address if this flag is set.`,
 			Comment: ``,
 		},
 	},
+	"MinerWithdrawalConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable automatic withdrawals of the miner available balance`,
+		},
+		{
+			Name: "FromOwner",
+			Type: "bool",
+
+			Comment: `Send withdrawal messages from the owner address instead of the
+beneficiary. The sending address key must be in the node wallet.`,
+		},
+		{
+			Name: "Destination",
+			Type: "string",
+
+			Comment: `Address to forward withdrawn funds to from the beneficiary address. When
+empty, funds are left with the beneficiary. Forwarding requires the
+beneficiary key in the node wallet.`,
+		},
+		{
+			Name: "Float",
+			Type: "types.FIL",
+
+			Comment: `Available balance to keep in the miner actor`,
+		},
+		{
+			Name: "Threshold",
+			Type: "types.FIL",
+
+			Comment: `Minimum amount above the float which triggers a withdrawal`,
+		},
+		{
+			Name: "CheckInterval",
+			Type: "Duration",
+
+			Comment: `How often the available balance is checked`,
+		},
+		{
+			Name: "MaxPerWithdrawal",
+			Type: "types.FIL",
+
+			Comment: `Maximum amount withdrawn by a single message, 0 for no limit`,
+		},
+		{
+			Name: "MaxPerDay",
+			Type: "types.FIL",
+
+			Comment: `Maximum amount withdrawn within 24 hours, 0 for no limit`,
+		},
+		{
+			Name: "DryRun",
+			Type: "bool",
+
+			Comment: `Only log and journal withdrawals which would be made, without sending
+any messages`,
+		},
+	},
+	"MiningConfig": []DocField{
+		{
+			Name: "MessageOrdering",
+			Type: "string",
+
+			Comment: `MessageOrdering is the rule ordering the messages of mined blocks:
+"fee" keeps the order of message selection, which favours messages
+paying higher gas premiums, "ticket-shuffle" orders them by the hash of
+the block ticket and the message CID, keeping the messages of each sender
+in nonce order. The rule is recorded in the block_mined journal events.`,
+		},
+	},
 	"ProvingConfig": []DocField{
 		{
 			Name: "ParallelCheckLimit",
@@ -827,90 +1564,237 @@ means that a single message can prove at most 10 partitions
 Note that setting this value lower may result in less efficient gas use - more messages will be sent,
 to prove each deadline, resulting in more total gas use (but each message will have lower gas limit)
 
-Setting this value above the network limit has no effect`,
+Setting this value above the network limit has no effect`,
+		},
+		{
+			Name: "MaxPartitionsPerRecoveryMessage",
+			Type: "int",
+
+			Comment: `In some cases when submitting DeclareFaultsRecovered messages,
+there may be too many recoveries to fit in a BlockGasLimit.
+In those cases it may be necessary to set this value to something low (eg 1);
+Note that setting this value lower may result in less efficient gas use - more messages will be sent than needed,
+resulting in more total gas use (but each message will have lower gas limit)`,
+		},
+		{
+			Name: "SingleRecoveringPartitionPerPostMessage",
+			Type: "bool",
+
+			Comment: `Enable single partition per PoSt Message for partitions containing recovery sectors
+
+In cases when submitting PoSt messages which contain recovering sectors, the default network limit may still be
+too high to fit in the block gas limit. In those cases, it becomes useful to only house the single partition
+with recovering sectors in the post message
+
+Note that setting this value lower may result in less efficient gas use - more messages will be sent,
+to prove each deadline, resulting in more total gas use (but each message will have lower gas limit)`,
+		},
+	},
+	"Pubsub": []DocField{
+		{
+			Name: "Bootstrapper",
+			Type: "bool",
+
+			Comment: `Run the node in bootstrap-node mode`,
+		},
+		{
+			Name: "DirectPeers",
+			Type: "[]string",
+
+			Comment: `DirectPeers specifies peers with direct peering agreements. These peers are
+connected outside of the mesh, with all (valid) message unconditionally
+forwarded to them. The router will maintain open connections to these peers.
+Note that the peering agreement should be reciprocal with direct peers
+symmetrically configured at both ends.
+Type: Array of multiaddress peerinfo strings, must include peerid (/p2p/12D3K...`,
+		},
+		{
+			Name: "IPColocationWhitelist",
+			Type: "[]string",
+
+			Comment: ``,
+		},
+		{
+			Name: "RemoteTracer",
+			Type: "string",
+
+			Comment: ``,
+		},
+		{
+			Name: "JsonTracer",
+			Type: "string",
+
+			Comment: `Path to file that will be used to output tracer content in JSON format.
+If present tracer will save data to defined file.
+Format: file path`,
+		},
+		{
+			Name: "ElasticSearchTracer",
+			Type: "string",
+
+			Comment: `Connection string for elasticsearch instance.
+If present tracer will save data to elasticsearch.
+Format: https://<username>:<password>@<elasticsearch_url>:<port>/`,
+		},
+		{
+			Name: "ElasticSearchIndex",
+			Type: "string",
+
+			Comment: `Name of elasticsearch index that will be used to save tracer data.
+This property is used only if ElasticSearchTracer propery is set.`,
+		},
+		{
+			Name: "TracerSourceAuth",
+			Type: "string",
+
+			Comment: `Auth token that will be passed with logs to elasticsearch - used for weighted peers score.`,
+		},
+	},
+	"RPCExecutionLimits": []DocField{
+		{
+			Name: "Timeout",
+			Type: "Duration",
+
+			Comment: `Timeout is the maximum wall-clock time of a single eth_call,
+eth_estimateGas or StateCall execution. The API call fails once the
+timeout is reached, and the execution is aborted on its next state read.
+The number of executions with a timeout running at once, including
+those not aborted yet, is limited to twice the number of CPUs. Set to 0
+to disable the limit.`,
+		},
+		{
+			Name: "MaxGas",
+			Type: "int64",
+
+			Comment: `MaxGas caps the gas limit of messages executed by eth_call,
+eth_estimateGas and StateCall. Set to 0 to disable the limit.`,
+		},
+		{
+			Name: "MaxMemory",
+			Type: "uint64",
+
+			Comment: `MaxMemory is the maximum number of bytes of state a single execution may
+buffer in memory. Set to 0 to disable the limit.`,
+		},
+		{
+			Name: "MaxIpldReads",
+			Type: "int64",
+
+			Comment: `MaxIpldReads is the maximum number of state blocks a single execution
+may read, the execution is aborted on the first read over the limit.
+Protects the node against contracts crafted to read huge amounts of
+state. Set to 0 to disable the limit.`,
+		},
+		{
+			Name: "MaxCallDepth",
+			Type: "int",
+
+			Comment: `MaxCallDepth is the maximum depth of nested actor calls of a single
+execution. The FVM can't be interrupted between actor calls, so it's
+checked once the execution completes, except on networks older than
+the FVM. Set to 0 to disable the limit.`,
+		},
+		{
+			Name: "TokenOverrides",
+			Type: "[]RPCExecutionLimitsOverride",
+
+			Comment: `TokenOverrides replace the limits above for requests authenticated with
+specific API tokens, e.g. to lift the limits for trusted clients.`,
+		},
+	},
+	"RPCExecutionLimitsOverride": []DocField{
+		{
+			Name: "TokenHash",
+			Type: "string",
+
+			Comment: `TokenHash is the hex encoded sha256 hash of the API token the override
+applies to, e.g. the output of "echo -n $TOKEN | sha256sum".`,
+		},
+		{
+			Name: "Timeout",
+			Type: "Duration",
+
+			Comment: `Timeout, MaxGas, MaxMemory, MaxIpldReads and MaxCallDepth replace the
+default limits, 0 means no limit.`,
 		},
 		{
-			Name: "MaxPartitionsPerRecoveryMessage",
-			Type: "int",
+			Name: "MaxGas",
+			Type: "int64",
 
-			Comment: `In some cases when submitting DeclareFaultsRecovered messages,
-there may be too many recoveries to fit in a BlockGasLimit.
-In those cases it may be necessary to set this value to something low (eg 1);
-Note that setting this value lower may result in less efficient gas use - more messages will be sent than needed,
-resulting in more total gas use (but each message will have lower gas limit)`,
+			Comment: ``,
 		},
 		{
-			Name: "SingleRecoveringPartitionPerPostMessage",
-			Type: "bool",
+			Name: "MaxMemory",
+			Type: "uint64",
 
-			Comment: `Enable single partition per PoSt Message for partitions containing recovery sectors
+			Comment: ``,
+		},
+		{
+			Name: "MaxIpldReads",
+			Type: "int64",
 
-In cases when submitting PoSt messages which contain recovering sectors, the default network limit may still be
-too high to fit in the block gas limit. In those cases, it becomes useful to only house the single partition
-with recovering sectors in the post message
+			Comment: ``,
+		},
+		{
+			Name: "MaxCallDepth",
+			Type: "int",
 
-Note that setting this value lower may result in less efficient gas use - more messages will be sent,
-to prove each deadline, resulting in more total gas use (but each message will have lower gas limit)`,
+			Comment: ``,
 		},
 	},
-	"Pubsub": []DocField{
+	"RPCRecordingConfig": []DocField{
 		{
-			Name: "Bootstrapper",
+			Name: "Enable",
 			Type: "bool",
 
-			Comment: `Run the node in bootstrap-node mode`,
+			Comment: `Enable recording the API calls of the allowed methods, with their
+responses and latencies, to files of JSON lines which can be replayed
+against another node with lotus-shed rpc-replay, to compare the
+responses and latencies of a candidate build. API tokens and remote
+addresses are never recorded, nor are wallet and auth calls, calls
+changing the state of the node such as eth_sendRawTransaction, and filter
+calls such as eth_newFilter. Only calls made with HTTP POST requests of up
+to 1MiB are recorded, not calls made over websockets.`,
 		},
 		{
-			Name: "DirectPeers",
-			Type: "[]string",
+			Name: "Directory",
+			Type: "string",
 
-			Comment: `DirectPeers specifies peers with direct peering agreements. These peers are
-connected outside of the mesh, with all (valid) message unconditionally
-forwarded to them. The router will maintain open connections to these peers.
-Note that the peering agreement should be reciprocal with direct peers
-symmetrically configured at both ends.
-Type: Array of multiaddress peerinfo strings, must include peerid (/p2p/12D3K...`,
+			Comment: `Directory the recordings are written to. Defaults to rpc-recordings in
+the repo.`,
 		},
 		{
-			Name: "IPColocationWhitelist",
+			Name: "Methods",
 			Type: "[]string",
 
-			Comment: ``,
-		},
-		{
-			Name: "RemoteTracer",
-			Type: "string",
-
-			Comment: ``,
+			Comment: `Methods are the prefixes of the methods recorded. A batch is only
+recorded when all its calls are allowed.`,
 		},
 		{
-			Name: "JsonTracer",
-			Type: "string",
+			Name: "SampleRate",
+			Type: "float64",
 
-			Comment: `Path to file that will be used to output tracer content in JSON format.
-If present tracer will save data to defined file.
-Format: file path`,
+			Comment: `SampleRate is the fraction of the calls recorded, from 0 to 1.`,
 		},
 		{
-			Name: "ElasticSearchTracer",
-			Type: "string",
+			Name: "MaxFileSize",
+			Type: "int64",
 
-			Comment: `Connection string for elasticsearch instance.
-If present tracer will save data to elasticsearch.
-Format: https://<username>:<password>@<elasticsearch_url>:<port>/`,
+			Comment: `MaxFileSize is the size in bytes at which a recording file is rotated.`,
 		},
 		{
-			Name: "ElasticSearchIndex",
-			Type: "string",
+			Name: "MaxFiles",
+			Type: "int",
 
-			Comment: `Name of elasticsearch index that will be used to save tracer data.
-This property is used only if ElasticSearchTracer propery is set.`,
+			Comment: `MaxFiles is the number of recording files kept; the oldest ones are
+removed.`,
 		},
 		{
-			Name: "TracerSourceAuth",
-			Type: "string",
+			Name: "MaxResponseSize",
+			Type: "int",
 
-			Comment: `Auth token that will be passed with logs to elasticsearch - used for weighted peers score.`,
+			Comment: `MaxResponseSize is the size in bytes above which responses aren't
+recorded; these calls are skipped on replay.`,
 		},
 	},
 	"RetrievalPricing": []DocField{
@@ -1053,6 +1937,35 @@ If you see stuck Finalize tasks after enabling this setting, check
 to use when evaluating tasks against this worker. An empty value defaults
 to "hardware".`,
 		},
+		{
+			Name: "TaskHistoryRetention",
+			Type: "Duration",
+
+			Comment: `TaskHistoryRetention is how long records of finished sealing tasks are
+kept for the WorkerTaskHistory and WorkerTaskStats APIs. 0 keeps records
+forever.`,
+		},
+		{
+			Name: "PreemptibleTasks",
+			Type: "[]string",
+
+			Comment: `PreemptibleTasks lists short names of sealing task types, e.g. "PC2" or
+"PR2", which WindowPoSt and WinningPoSt preempt on the host they run on,
+so that proving doesn't compete with them for GPUs. Empty disables
+preemption.`,
+		},
+		{
+			Name: "PreemptionPolicy",
+			Type: "string",
+
+			Comment: `PreemptionPolicy specifies what happens to preemptible tasks while proving
+runs on their host.
+"suspend" (default) - no new preemptible tasks are started on the host.
+"abort" - additionally abort preemptible tasks running on the host when
+proving starts. The sealing pipeline retries aborted tasks; the proofs
+library can't interrupt them, so the aborted computation finishes on the
+worker and its result is discarded.`,
+		},
 	},
 	"SealingConfig": []DocField{
 		{
@@ -1255,22 +2168,353 @@ number of sectors get committed within the same epoch resulting in occasionally
 Submitting a smaller number of prove commits per epoch would reduce the possibility of failed msgs`,
 		},
 		{
-			Name: "TerminateBatchMax",
-			Type: "uint64",
+			Name: "TerminateBatchMax",
+			Type: "uint64",
+
+			Comment: ``,
+		},
+		{
+			Name: "TerminateBatchMin",
+			Type: "uint64",
+
+			Comment: ``,
+		},
+		{
+			Name: "TerminateBatchWait",
+			Type: "Duration",
+
+			Comment: ``,
+		},
+		{
+			Name: "ClientPlacement",
+			Type: "[]ClientPlacementRule",
+
+			Comment: `ClientPlacement pins deals from specific clients to storage paths in the
+listed groups (see Groups in sectorstore.json). Deals are only packed into
+sectors with the same placement, and all files of those sectors are
+allocated in paths belonging to at least one of the groups.
+Placement groups set on a deal by the markets node take precedence.
+Rules are checked in order, the first one matching a client applies.`,
+		},
+		{
+			Name: "RetryPolicies",
+			Type: "[]SealingRetryPolicy",
+
+			Comment: `RetryPolicies control how sectors in failed states are retried, per class
+of sealing errors. Classes are: precommit1, ticket-expired, precommit2,
+precommit (on-chain precommit failures), compute-proof (C1/C2 failures),
+commit, finalize, replica-update, and default, which applies to classes
+without their own policy and other failed states.
+Without any policy, failed sectors are retried every minute indefinitely.`,
+		},
+		{
+			Name: "UnsealedCopyPolicies",
+			Type: "[]UnsealedCopyPolicy",
+
+			Comment: `UnsealedCopyPolicies decide whether unsealed copies of deal data are kept
+after sealing, per deal client, deal verified status or sector. The first
+policy matching a deal applies. Deals without a matching policy keep their
+unsealed copy when the client requested it or AlwaysKeepUnsealedCopy is set.
+Existing sectors can be brought into compliance with the policies with
+lotus-miner sectors reconcile-unsealed.`,
+		},
+		{
+			Name: "RemoveExpiredSectors",
+			Type: "bool",
+
+			Comment: `When enabled, the data of sectors which expired or were terminated on chain
+is removed from storage once they have been gone from the chain for
+RemoveExpiredSectorsDelay, like running lotus-miner sectors expired
+--remove-expired. Each removal is recorded in the journal.`,
+		},
+		{
+			Name: "RemoveExpiredSectorsDelay",
+			Type: "Duration",
+
+			Comment: `How long sectors must have been expired or terminated for before their data
+is removed. Sectors are always kept for at least the WinningPoSt sector set
+lookback.`,
+		},
+	},
+	"SealingRetryPolicy": []DocField{
+		{
+			Name: "Class",
+			Type: "string",
+
+			Comment: `Error class the policy applies to`,
+		},
+		{
+			Name: "InitialBackoff",
+			Type: "Duration",
+
+			Comment: `Delay before the first retry`,
+		},
+		{
+			Name: "MaxBackoff",
+			Type: "Duration",
+
+			Comment: `Maximum delay between retries, 0 means no limit`,
+		},
+		{
+			Name: "BackoffFactor",
+			Type: "float64",
+
+			Comment: `The delay is multiplied by this factor after each consecutive failure,
+values up to 1 keep it constant`,
+		},
+		{
+			Name: "MaxAttempts",
+			Type: "uint64",
+
+			Comment: `Consecutive failures after which the sector is no longer retried
+automatically, and a sealing:retry-<class> alert is raised. The sector
+then waits in the failed state until its state is changed manually
+(lotus-miner sectors update-state). 0 means no limit.`,
+		},
+	},
+	"SlowCallLogConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable logging API calls taking longer than Threshold to the rpc-slow
+log subsystem, along with their params, the hash of the API token of the
+caller and the time spent in each phase of the call. Only calls made
+with HTTP POST requests are timed, not calls made over websockets.`,
+		},
+		{
+			Name: "Threshold",
+			Type: "Duration",
+
+			Comment: `Threshold is the time after which a call is considered slow.`,
+		},
+		{
+			Name: "MaxParamsLength",
+			Type: "int",
+
+			Comment: `MaxParamsLength is the number of bytes of the params of a call which are
+logged; longer params are truncated.`,
+		},
+		{
+			Name: "RedactMethods",
+			Type: "[]string",
+
+			Comment: `RedactMethods are the methods whose params are never logged, in addition
+to the wallet and auth methods, e.g. "Filecoin.MpoolPushMessage".`,
+		},
+	},
+	"SnapshotsConfig": []DocField{
+		{
+			Name: "EnableUpload",
+			Type: "bool",
+
+			Comment: `EnableUpload enables periodic export of chain snapshots, which are
+compressed with zstd and uploaded to S3-compatible object storage along
+with a sha256 checksum file.`,
+		},
+		{
+			Name: "Schedule",
+			Type: "string",
+
+			Comment: `Schedule is a cron expression (minute, hour, day of month, month, day of
+week) specifying when snapshots are taken, e.g. "0 */6 * * *" for every 6
+hours. Descriptors like "@daily" are also supported.`,
+		},
+		{
+			Name: "Confidence",
+			Type: "int64",
+
+			Comment: `Confidence is the number of epochs behind the chain head at which the
+snapshot is taken, so that the exported tipset is unlikely to be reverted.`,
+		},
+		{
+			Name: "RecentStateRoots",
+			Type: "int64",
+
+			Comment: `RecentStateRoots is the number of recent state trees included in the
+snapshot.`,
+		},
+		{
+			Name: "StagingPath",
+			Type: "string",
+
+			Comment: `StagingPath is the directory in which snapshots are written before being
+uploaded. Defaults to the 'snapshots' directory in the repo.`,
+		},
+		{
+			Name: "Endpoint",
+			Type: "string",
+
+			Comment: `Endpoint is the URL of the S3-compatible object storage, e.g.
+"https://s3.us-east-1.amazonaws.com", or "https://storage.googleapis.com"
+for GCS with HMAC keys.`,
+		},
+		{
+			Name: "Region",
+			Type: "string",
+
+			Comment: `Region used for request signing.`,
+		},
+		{
+			Name: "Bucket",
+			Type: "string",
+
+			Comment: `Bucket in which snapshots are stored.`,
+		},
+		{
+			Name: "Prefix",
+			Type: "string",
+
+			Comment: `Prefix is prepended to the names of uploaded objects, e.g. "mainnet/".`,
+		},
+		{
+			Name: "AccessKey",
+			Type: "string",
+
+			Comment: `AccessKey used for request signing.`,
+		},
+		{
+			Name: "SecretKey",
+			Type: "string",
+
+			Comment: `SecretKey used for request signing.`,
+		},
+		{
+			Name: "Retention",
+			Type: "int",
+
+			Comment: `Retention is the number of most recent snapshots kept in the bucket;
+older snapshots are deleted after each successful upload. 0 keeps all
+snapshots.`,
+		},
+		{
+			Name: "SigningKey",
+			Type: "string",
+
+			Comment: `SigningKey is the address of a wallet key used to sign the manifests
+uploaded along with the snapshots. Manifests are left unsigned when
+empty.`,
+		},
+		{
+			Name: "TrustedPublishers",
+			Type: "[]string",
+
+			Comment: `TrustedPublishers are the addresses of the keys trusted to sign the
+manifests of imported snapshots, in addition to the publishers built
+into the node. Once any publisher is trusted, imported snapshots must
+come with a manifest signed by one of them.`,
+		},
+	},
+	"SpamFilterConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable filters the messages pushed with MpoolPushUntrusted, by gateway
+clients and Ethereum transactions, protecting public nodes from flooding
+of the message pool. Rejected messages are refused with structured
+errors, stating the reason of the rejection.`,
+		},
+		{
+			Name: "MaxSenderPushes",
+			Type: "int",
+
+			Comment: `MaxSenderPushes is the number of messages a sender can push in each
+SenderRateInterval, 0 disables rate limiting.`,
+		},
+		{
+			Name: "SenderRateInterval",
+			Type: "Duration",
+
+			Comment: ``,
+		},
+		{
+			Name: "PressureThreshold",
+			Type: "float64",
+
+			Comment: `PressureThreshold is the fraction of the message pool size limit,
+between 0 and 1, from which messages are refused when their gas premium
+is below PressureMinPremium. 0 disables the check.`,
+		},
+		{
+			Name: "PressureMinPremium",
+			Type: "types.FIL",
+
+			Comment: `PressureMinPremium is the minimum gas premium, per unit of gas, of the
+messages pushed while the pool is under pressure.`,
+		},
+		{
+			Name: "RecentWindow",
+			Type: "Duration",
+
+			Comment: `RecentWindow is how long pushed messages are remembered, to refuse
+duplicates, count the replacements of the message of a nonce and count
+the rejected pushes of a sender.`,
+		},
+		{
+			Name: "MaxReplacements",
+			Type: "int",
+
+			Comment: `MaxReplacements is the number of times the message of a sender nonce can
+be replaced in RecentWindow, 0 doesn't limit replacements.`,
+		},
+		{
+			Name: "BanThreshold",
+			Type: "int",
+
+			Comment: `BanThreshold is the number of pushes of a sender rejected in
+RecentWindow, by the filter or by the message pool, after which its
+pushes are refused for BanDuration. 0 disables bans.`,
+		},
+		{
+			Name: "BanDuration",
+			Type: "Duration",
+
+			Comment: ``,
+		},
+	},
+	"SpendBudgetConfig": []DocField{
+		{
+			Name: "Windows",
+			Type: "[]SpendBudgetWindow",
+
+			Comment: `Windows are budgets of the FIL the node's wallets spend on gas, in the
+messages the node signs and pushes with MpoolPushMessage. Each window is
+a token bucket holding up to its Budget, refilling continuously over its
+Period, and a message is only pushed when its maximum gas cost,
+GasFeeCap × GasLimit, fits in all of them. The maximum cost is reserved
+when the message is pushed, and the part it didn't spend is credited
+back once it's executed; messages still pending when the node stops keep
+their maximum cost charged. The budget is disabled when no windows are
+configured.`,
+		},
+		{
+			Name: "Queue",
+			Type: "bool",
 
-			Comment: ``,
+			Comment: `Queue holds messages exceeding the budget until it refills, instead of
+refusing them. Messages from the same address wait behind a held one.`,
 		},
 		{
-			Name: "TerminateBatchMin",
-			Type: "uint64",
+			Name: "QueueTimeout",
+			Type: "Duration",
 
-			Comment: ``,
+			Comment: `QueueTimeout is how long messages are held, they are refused when the
+budget didn't refill in time.`,
 		},
+	},
+	"SpendBudgetWindow": []DocField{
 		{
-			Name: "TerminateBatchWait",
+			Name: "Period",
 			Type: "Duration",
 
-			Comment: ``,
+			Comment: `Period is the duration the budget refills over, e.g. "24h0m0s".`,
+		},
+		{
+			Name: "Budget",
+			Type: "types.FIL",
+
+			Comment: `Budget is the FIL which can be spent on gas over the Period.`,
 		},
 	},
 	"Splitstore": []DocField{
@@ -1340,6 +2584,197 @@ is set.  Moving GC will not occur when total moving size exceeds
 HotstoreMaxSpaceTarget - HotstoreMaxSpaceSafetyBuffer`,
 		},
 	},
+	"SponsoredCallsConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable enables sending sponsored calls.`,
+		},
+		{
+			Name: "Sponsors",
+			Type: "[]string",
+
+			Comment: `Sponsors are the wallet addresses allowed to pay for sponsored calls.
+The first one pays for the calls which don't name their sponsor.`,
+		},
+		{
+			Name: "Forwarders",
+			Type: "[]string",
+
+			Comment: `Forwarders are the forwarder contracts calls are relayed through, as
+Ethereum addresses. They must have the interface of the OpenZeppelin
+MinimalForwarder.`,
+		},
+		{
+			Name: "Targets",
+			Type: "[]string",
+
+			Comment: `Targets are the only contracts calls may be made to, as Ethereum
+addresses. Calls may be made to any contract when empty.`,
+		},
+		{
+			Name: "MaxValue",
+			Type: "string",
+
+			Comment: `MaxValue is the maximum value a call forwards, paid by the sponsor.`,
+		},
+		{
+			Name: "MaxFee",
+			Type: "string",
+
+			Comment: `MaxFee is the maximum fee paid for a call. The fee is estimated when
+empty.`,
+		},
+		{
+			Name: "MaxCallsPerHour",
+			Type: "int",
+
+			Comment: `MaxCallsPerHour is the number of calls sponsored per account per hour.
+0 doesn't limit calls.`,
+		},
+	},
+	"StateFallback": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable fetching of chain and state blocks which are missing from the local
+blockstore on demand. This allows nodes imported from a lightweight snapshot
+to serve queries against historical state (e.g. StateGetActor or eth_call at
+old epochs) without keeping a full archive. Fetched blocks are persisted in
+the local blockstore.`,
+		},
+		{
+			Name: "ArchiveAPIInfo",
+			Type: "string",
+
+			Comment: `ArchiveAPIInfo is the API info of an archival Lotus node (TOKEN:MULTIADDR,
+same format as FULLNODE_API_INFO) which will be queried for missing blocks.
+When set, the archival node is tried first, before falling back to bitswap.`,
+		},
+		{
+			Name: "ArchivePeers",
+			Type: "[]string",
+
+			Comment: `ArchivePeers is a list of multiaddrs (including the /p2p/ component) of
+archival peers. The node will connect to and protect connections with these
+peers, so that missing blocks can be fetched from them over bitswap.`,
+		},
+	},
+	"StateReplayCacheConfig": []DocField{
+		{
+			Name: "Size",
+			Type: "int",
+
+			Comment: `Size is the number of replay results kept for StateReplayCached, the
+least recently used results are evicted first. 0 disables the cache.`,
+		},
+		{
+			Name: "TTL",
+			Type: "Duration",
+
+			Comment: `TTL is how long results are served from the cache, 0 keeps them until
+they are evicted.`,
+		},
+	},
+	"StateSyncConfig": []DocField{
+		{
+			Name: "Serve",
+			Type: "bool",
+
+			Comment: `Serve answers the state sync requests of peers, which fetch the chain
+state from this node when bootstrapping from a checkpoint. Serving uses
+bandwidth and blockstore reads on behalf of any peer, so it's opt-in.`,
+		},
+		{
+			Name: "Checkpoint",
+			Type: "[]string",
+
+			Comment: `Checkpoint is the tipset, as the CIDs of its blocks, a fresh node
+fetches the chain state at from its peers, instead of syncing from
+genesis or importing a snapshot. The checkpoint is trusted like a
+snapshot would be, so it should be obtained from a trusted source. It is
+only used when the node holds no chain beyond genesis.`,
+		},
+		{
+			Name: "StateEpochs",
+			Type: "int",
+
+			Comment: `StateEpochs is the number of epochs below the checkpoint for which the
+state trees, message receipts and messages are fetched, for lookbacks.`,
+		},
+	},
+	"StorageAskAutomationConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable periodic adjustment of the storage ask according to the
+utilization of the long-term storage paths. Changes of the ask are
+recorded in the journal.`,
+		},
+		{
+			Name: "Interval",
+			Type: "Duration",
+
+			Comment: `How often the storage utilization is checked`,
+		},
+		{
+			Name: "AskDuration",
+			Type: "Duration",
+
+			Comment: `How long the asks are valid for. Asks are renewed when less than half
+of this remains.`,
+		},
+		{
+			Name: "Curve",
+			Type: "[]StorageAskCurvePoint",
+
+			Comment: `Points of the pricing curve, by storage utilization. Prices are
+interpolated linearly between points and are flat beyond the first and
+last points; piece size limits are the ones of the last point at or
+below the utilization.`,
+		},
+		{
+			Name: "DryRun",
+			Type: "bool",
+
+			Comment: `Only log and journal the ask changes which would be made`,
+		},
+	},
+	"StorageAskCurvePoint": []DocField{
+		{
+			Name: "Utilization",
+			Type: "float64",
+
+			Comment: `Fraction of the long-term storage capacity in use, from 0 to 1`,
+		},
+		{
+			Name: "Price",
+			Type: "string",
+
+			Comment: `Price of unverified deals per GiB per epoch, e.g. "0.0000000005 FIL"`,
+		},
+		{
+			Name: "VerifiedPrice",
+			Type: "string",
+
+			Comment: `Price of verified deals per GiB per epoch`,
+		},
+		{
+			Name: "MinPieceSize",
+			Type: "string",
+
+			Comment: `Minimum piece size, e.g. "256B", empty for the default`,
+		},
+		{
+			Name: "MaxPieceSize",
+			Type: "string",
+
+			Comment: `Maximum piece size, e.g. "32GiB", empty for the sector size`,
+		},
+	},
 	"StorageMiner": []DocField{
 		{
 			Name: "Subsystems",
@@ -1389,12 +2824,110 @@ HotstoreMaxSpaceTarget - HotstoreMaxSpaceSafetyBuffer`,
 
 			Comment: ``,
 		},
+		{
+			Name: "Withdrawal",
+			Type: "MinerWithdrawalConfig",
+
+			Comment: ``,
+		},
 		{
 			Name: "DAGStore",
 			Type: "DAGStoreConfig",
 
 			Comment: ``,
 		},
+		{
+			Name: "Mining",
+			Type: "MiningConfig",
+
+			Comment: ``,
+		},
+	},
+	"SubscriptionsConfig": []DocField{
+		{
+			Name: "ResumeWindow",
+			Type: "int",
+
+			Comment: `ResumeWindow is how many epochs below the head the cursors of
+ChainNotifyResume and of eth_subscribe logs subscriptions can resume
+from. Older cursors are rejected, and clients have to start over. 0
+disables resuming.`,
+		},
+	},
+	"UnsealedCopyPolicy": []DocField{
+		{
+			Name: "Client",
+			Type: "string",
+
+			Comment: `Client address (ID or robust) the policy applies to, empty for any client`,
+		},
+		{
+			Name: "Deals",
+			Type: "string",
+
+			Comment: `Deals the policy applies to: verified, unverified, or empty for all deals`,
+		},
+		{
+			Name: "Sectors",
+			Type: "string",
+
+			Comment: `Sector numbers the policy applies to, e.g. 1-100,150, empty for all sectors`,
+		},
+		{
+			Name: "MinRetrievalScore",
+			Type: "float64",
+
+			Comment: `Minimum retrieval score of pieces the policy applies to, 0 for all pieces.
+The score is the number of retrieval reads of the piece, with each read
+counting half as much after a week, see lotus-miner sectors hotness.
+Pieces can't be scored before they are retrieved, so their unsealed copies
+are kept after sealing, and the policy is applied by lotus-miner sectors
+reconcile-unsealed`,
+		},
+		{
+			Name: "Keep",
+			Type: "bool",
+
+			Comment: `Whether to keep the unsealed copy of matching deals`,
+		},
+	},
+	"UpgradesConfig": []DocField{
+		{
+			Name: "Announced",
+			Type: "[]AnnouncedUpgrade",
+
+			Comment: `Announced are network upgrades announced for the network, e.g. by the
+release notes of the release performing them. The upgrades built into
+this binary only tell whether it's ready for the upgrades it knows
+about, alerts are raised as an announced upgrade it can't perform
+approaches: one to a network version it doesn't support, or which it
+schedules at another epoch.`,
+		},
+		{
+			Name: "AnnouncementsURL",
+			Type: "string",
+
+			Comment: `AnnouncementsURL is the URL of a JSON list of announced upgrades, in the
+format of Announced, e.g. [{"Network": 21, "Height": 3469380}], checked
+along with Announced.`,
+		},
+	},
+	"UserActorsConfig": []DocField{
+		{
+			Name: "Enable",
+			Type: "bool",
+
+			Comment: `Enable enables the experimental APIs installing and instantiating user
+WASM actors. They only work on networks whose init actor supports
+installing actor code.`,
+		},
+		{
+			Name: "MaxCodeSize",
+			Type: "int",
+
+			Comment: `MaxCodeSize is the maximum size in bytes of the actor code the APIs
+accept. 0 means no limit.`,
+		},
 	},
 	"UserRaftConfig": []DocField{
 		{
@@ -1477,5 +3010,148 @@ copies that we keep as backups (renaming) after cleanup.`,
 
 			Comment: ``,
 		},
+		{
+			Name: "SigningPolicy",
+			Type: "WalletSigningPolicy",
+
+			Comment: `SigningPolicy rate limits signing, and refuses to sign high value
+messages until they are approved.`,
+		},
+	},
+	"WalletSigningPolicy": []DocField{
+		{
+			Name: "Addresses",
+			Type: "[]string",
+
+			Comment: `Addresses the policy applies to, all wallet addresses when empty.`,
+		},
+		{
+			Name: "MaxSignatures",
+			Type: "int",
+
+			Comment: `MaxSignatures is the number of signatures allowed per address in each
+RateInterval, further signing requests fail. 0 disables rate limiting.
+Block signatures are never limited.`,
+		},
+		{
+			Name: "RateInterval",
+			Type: "Duration",
+
+			Comment: `RateInterval is the period MaxSignatures are allowed in.`,
+		},
+		{
+			Name: "ApprovalThreshold",
+			Type: "types.FIL",
+
+			Comment: `ApprovalThreshold is the value from which messages are refused until
+they are approved with WalletApprove, or 'lotus wallet approvals
+approve'. Sending such a message fails with an approval_required error
+right away, and once the request is approved the same message is signed
+when it's sent again. With approvals enabled, the addresses can only sign
+messages and blocks: raw signatures, typed data and deal proposals are
+denied, as they can't be checked, so miner worker addresses, which sign
+election proofs, must be left out of Addresses. 0 disables approvals.`,
+		},
+		{
+			Name: "ApprovalWebhook",
+			Type: "string",
+
+			Comment: `ApprovalWebhook is a URL notified of approval requests with a JSON POST
+of the request.`,
+		},
+		{
+			Name: "ApprovalTimeout",
+			Type: "Duration",
+
+			Comment: `ApprovalTimeout is how long approval requests are kept, and approved
+messages can be sent again for.`,
+		},
+	},
+	"WebhookEndpoint": []DocField{
+		{
+			Name: "URL",
+			Type: "string",
+
+			Comment: `URL the events are POSTed to.`,
+		},
+		{
+			Name: "Secret",
+			Type: "string",
+
+			Comment: `Secret is the HMAC-SHA256 key of the X-Lotus-Signature header sent with
+each request. Requests are not signed when empty.`,
+		},
+		{
+			Name: "Events",
+			Type: "[]string",
+
+			Comment: `Events sent to the endpoint, all events when empty. One of
+filter_match, sector_state, alert_raised or deal_activated.`,
+		},
+		{
+			Name: "MaxAttempts",
+			Type: "int",
+
+			Comment: `MaxAttempts is the number of delivery attempts of an event, 5 when 0.`,
+		},
+		{
+			Name: "InitialBackoff",
+			Type: "Duration",
+
+			Comment: `InitialBackoff is the wait after the first failed delivery, doubling
+after each further failure up to MaxBackoff. 1s and 1m when 0.`,
+		},
+		{
+			Name: "MaxBackoff",
+			Type: "Duration",
+
+			Comment: ``,
+		},
+		{
+			Name: "QueueSize",
+			Type: "int",
+
+			Comment: `QueueSize is the number of undelivered events kept for the endpoint,
+newer events are dropped when it's full. 256 when 0.`,
+		},
+	},
+	"WebhookSavedQuery": []DocField{
+		{
+			Name: "Name",
+			Type: "string",
+
+			Comment: `Name identifies the query in the filter_match events.`,
+		},
+		{
+			Name: "Addresses",
+			Type: "[]string",
+
+			Comment: `Addresses of the emitting actors, f4 or 0x addresses. Any actor when
+empty.`,
+		},
+		{
+			Name: "Topics",
+			Type: "[][]string",
+
+			Comment: `Topics lists the accepted 0x prefixed 32 byte values of each of the
+event topics, any value when a position is empty.`,
+		},
+	},
+	"Webhooks": []DocField{
+		{
+			Name: "Endpoints",
+			Type: "[]WebhookEndpoint",
+
+			Comment: `Endpoints are sent JSON POSTs of node events.`,
+		},
+		{
+			Name: "SavedQueries",
+			Type: "[]WebhookSavedQuery",
+
+			Comment: `SavedQueries are actor event filters, each matching event is sent to
+the endpoints as a filter_match event.
+Saved queries are only evaluated by chain nodes with Fevm.EnableEthRPC
+set, and the real time filter API enabled.`,
+		},
 	},
 }
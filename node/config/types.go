@@ -12,11 +12,13 @@ import (
 
 // Common is common config between full node and miner
 type Common struct {
-	API     API
-	Backup  Backup
-	Logging Logging
-	Libp2p  Libp2p
-	Pubsub  Pubsub
+	API        API
+	Backup     Backup
+	Logging    Logging
+	Libp2p     Libp2p
+	Pubsub     Pubsub
+	Webhooks   Webhooks
+	ClockCheck ClockCheck
 }
 
 // FullNode is a full node config
@@ -29,6 +31,28 @@ type FullNode struct {
 	Cluster    UserRaftConfig
 	Fevm       FevmConfig
 	Index      IndexConfig
+	Snapshots  SnapshotsConfig
+	Beacon     BeaconConfig
+
+	Maintenance MaintenanceConfig
+
+	RPCExecutionLimits RPCExecutionLimits
+	SlowCallLog        SlowCallLogConfig
+	RPCRecording       RPCRecordingConfig
+	ChainDataREST      ChainDataRESTConfig
+	MessageTracing     MessageTracingConfig
+	Health             HealthConfig
+	StateReplayCache   StateReplayCacheConfig
+	Execution          ExecutionConfig
+	MessageSelection   MessageSelectionConfig
+	UserActors         UserActorsConfig
+	LightClient        LightClientConfig
+	Subscriptions      SubscriptionsConfig
+	StateSync          StateSyncConfig
+	SpendBudget        SpendBudgetConfig
+	SpamFilter         SpamFilterConfig
+	ChainBridge        ChainBridgeConfig
+	Upgrades           UpgradesConfig
 }
 
 // // Common
@@ -44,8 +68,28 @@ type Backup struct {
 
 // Logging is the logging system config
 type Logging struct {
-	// SubsystemLevels specify per-subsystem log levels
+	// SubsystemLevels specify per-subsystem log levels. Levels set with the
+	// log set-level command are persisted in the repo and take precedence.
 	SubsystemLevels map[string]string
+
+	// File configures the rotation of the log file
+	File LogFile
+}
+
+type LogFile struct {
+	// Path of the log file. Defaults to the file set with the GOLOG_FILE
+	// environment variable; logs aren't written to a file when neither is set.
+	Path string
+	// MaxSizeMB is the size in megabytes after which the log file is rotated.
+	// 0 disables rotation.
+	MaxSizeMB int64
+	// MaxAge is the age after which rotated log files are deleted. 0 keeps
+	// them regardless of their age.
+	MaxAge Duration
+	// MaxBackups is the number of rotated log files kept. 0 keeps all of them.
+	MaxBackups int
+	// Compress rotated log files with gzip.
+	Compress bool
 }
 
 // StorageMiner is a miner config
@@ -60,7 +104,18 @@ type StorageMiner struct {
 	Storage       SealerConfig
 	Fees          MinerFeeConfig
 	Addresses     MinerAddressConfig
+	Withdrawal    MinerWithdrawalConfig
 	DAGStore      DAGStoreConfig
+	Mining        MiningConfig
+}
+
+type MiningConfig struct {
+	// MessageOrdering is the rule ordering the messages of mined blocks:
+	// "fee" keeps the order of message selection, which favours messages
+	// paying higher gas premiums, "ticket-shuffle" orders them by the hash of
+	// the block ticket and the message CID, keeping the messages of each sender
+	// in nonce order. The rule is recorded in the block_mined journal events.
+	MessageOrdering string
 }
 
 type DAGStoreConfig struct {
@@ -162,11 +217,54 @@ type DealmakingConfig struct {
 	// A command used for fine-grained evaluation of storage deals
 	// see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#using-filters-for-fine-grained-storage-and-retrieval-deal-acceptance for more details
 	Filter string
+	// Path to a JSON file with the rules of the built-in storage deal filter:
+	// price floors of verified and unverified deals, client allow and block
+	// lists, and per-client rate limits. The file is reloaded when it changes.
+	// The rules are applied before the Filter command, and the decisions of
+	// the filters are logged, see DealsFilterDecisions.
+	FilterRules string
 	// A command used for fine-grained evaluation of retrieval deals
 	// see https://lotus.filecoin.io/storage-providers/advanced-configurations/market/#using-filters-for-fine-grained-storage-and-retrieval-deal-acceptance for more details
 	RetrievalFilter string
 
 	RetrievalPricing *RetrievalPricing
+
+	// Automatic adjustment of the storage ask by storage utilization
+	AskAutomation StorageAskAutomationConfig
+}
+
+type StorageAskAutomationConfig struct {
+	// Enable periodic adjustment of the storage ask according to the
+	// utilization of the long-term storage paths. Changes of the ask are
+	// recorded in the journal.
+	Enable bool
+	// How often the storage utilization is checked
+	Interval Duration
+	// How long the asks are valid for. Asks are renewed when less than half
+	// of this remains.
+	AskDuration Duration
+
+	// Points of the pricing curve, by storage utilization. Prices are
+	// interpolated linearly between points and are flat beyond the first and
+	// last points; piece size limits are the ones of the last point at or
+	// below the utilization.
+	Curve []StorageAskCurvePoint
+
+	// Only log and journal the ask changes which would be made
+	DryRun bool
+}
+
+type StorageAskCurvePoint struct {
+	// Fraction of the long-term storage capacity in use, from 0 to 1
+	Utilization float64
+	// Price of unverified deals per GiB per epoch, e.g. "0.0000000005 FIL"
+	Price string
+	// Price of verified deals per GiB per epoch
+	VerifiedPrice string
+	// Minimum piece size, e.g. "256B", empty for the default
+	MinPieceSize string
+	// Maximum piece size, e.g. "32GiB", empty for the sector size
+	MaxPieceSize string
 }
 
 type IndexProviderConfig struct {
@@ -433,12 +531,88 @@ type SealingConfig struct {
 	TerminateBatchMin  uint64
 	TerminateBatchWait Duration
 
+	// ClientPlacement pins deals from specific clients to storage paths in the
+	// listed groups (see Groups in sectorstore.json). Deals are only packed into
+	// sectors with the same placement, and all files of those sectors are
+	// allocated in paths belonging to at least one of the groups.
+	// Placement groups set on a deal by the markets node take precedence.
+	// Rules are checked in order, the first one matching a client applies.
+	ClientPlacement []ClientPlacementRule
+
+	// RetryPolicies control how sectors in failed states are retried, per class
+	// of sealing errors. Classes are: precommit1, ticket-expired, precommit2,
+	// precommit (on-chain precommit failures), compute-proof (C1/C2 failures),
+	// commit, finalize, replica-update, and default, which applies to classes
+	// without their own policy and other failed states.
+	// Without any policy, failed sectors are retried every minute indefinitely.
+	RetryPolicies []SealingRetryPolicy
+
+	// UnsealedCopyPolicies decide whether unsealed copies of deal data are kept
+	// after sealing, per deal client, deal verified status or sector. The first
+	// policy matching a deal applies. Deals without a matching policy keep their
+	// unsealed copy when the client requested it or AlwaysKeepUnsealedCopy is set.
+	// Existing sectors can be brought into compliance with the policies with
+	// lotus-miner sectors reconcile-unsealed.
+	UnsealedCopyPolicies []UnsealedCopyPolicy
+
+	// When enabled, the data of sectors which expired or were terminated on chain
+	// is removed from storage once they have been gone from the chain for
+	// RemoveExpiredSectorsDelay, like running lotus-miner sectors expired
+	// --remove-expired. Each removal is recorded in the journal.
+	RemoveExpiredSectors bool
+	// How long sectors must have been expired or terminated for before their data
+	// is removed. Sectors are always kept for at least the WinningPoSt sector set
+	// lookback.
+	RemoveExpiredSectorsDelay Duration
+
 	// Keep this many sectors in sealing pipeline, start CC if needed
 	// todo TargetSealingSectors uint64
 
 	// todo TargetSectors - stop auto-pleding new sectors after this many sectors are sealed, default CC upgrade for deals sectors if above
 }
 
+type ClientPlacementRule struct {
+	// Client address (ID or robust) the rule applies to
+	Client string
+	// Storage path groups deals from the client can be stored in
+	Groups []string
+}
+
+type UnsealedCopyPolicy struct {
+	// Client address (ID or robust) the policy applies to, empty for any client
+	Client string
+	// Deals the policy applies to: verified, unverified, or empty for all deals
+	Deals string
+	// Sector numbers the policy applies to, e.g. 1-100,150, empty for all sectors
+	Sectors string
+	// Minimum retrieval score of pieces the policy applies to, 0 for all pieces.
+	// The score is the number of retrieval reads of the piece, with each read
+	// counting half as much after a week, see lotus-miner sectors hotness.
+	// Pieces can't be scored before they are retrieved, so their unsealed copies
+	// are kept after sealing, and the policy is applied by lotus-miner sectors
+	// reconcile-unsealed
+	MinRetrievalScore float64
+	// Whether to keep the unsealed copy of matching deals
+	Keep bool
+}
+
+type SealingRetryPolicy struct {
+	// Error class the policy applies to
+	Class string
+	// Delay before the first retry
+	InitialBackoff Duration
+	// Maximum delay between retries, 0 means no limit
+	MaxBackoff Duration
+	// The delay is multiplied by this factor after each consecutive failure,
+	// values up to 1 keep it constant
+	BackoffFactor float64
+	// Consecutive failures after which the sector is no longer retried
+	// automatically, and a sealing:retry-<class> alert is raised. The sector
+	// then waits in the failed state until its state is changed manually
+	// (lotus-miner sectors update-state). 0 means no limit.
+	MaxAttempts uint64
+}
+
 type SealerConfig struct {
 	ParallelFetchLimit int
 
@@ -478,6 +652,26 @@ type SealerConfig struct {
 	// to use when evaluating tasks against this worker. An empty value defaults
 	// to "hardware".
 	ResourceFiltering ResourceFilteringStrategy
+
+	// TaskHistoryRetention is how long records of finished sealing tasks are
+	// kept for the WorkerTaskHistory and WorkerTaskStats APIs. 0 keeps records
+	// forever.
+	TaskHistoryRetention Duration
+
+	// PreemptibleTasks lists short names of sealing task types, e.g. "PC2" or
+	// "PR2", which WindowPoSt and WinningPoSt preempt on the host they run on,
+	// so that proving doesn't compete with them for GPUs. Empty disables
+	// preemption.
+	PreemptibleTasks []string
+
+	// PreemptionPolicy specifies what happens to preemptible tasks while proving
+	// runs on their host.
+	// "suspend" (default) - no new preemptible tasks are started on the host.
+	// "abort" - additionally abort preemptible tasks running on the host when
+	// proving starts. The sealing pipeline retries aborted tasks; the proofs
+	// library can't interrupt them, so the aborted computation finishes on the
+	// worker and its result is discarded.
+	PreemptionPolicy string
 }
 
 type BatchFeeConfig struct {
@@ -507,6 +701,12 @@ type MinerAddressConfig struct {
 	CommitControl      []string
 	TerminateControl   []string
 	DealPublishControl []string
+	// Ordered list of addresses to send WindowPoSt messages (proofs, fault and
+	// recovery declarations) from. The first address with enough funds, and with
+	// at most a couple of messages pending in the mpool, is used. When none
+	// qualify, the worker and owner fallbacks apply as for other messages.
+	// If empty, control addresses not configured for other uses are used.
+	WindowPoStControl []string
 
 	// DisableOwnerFallback disables usage of the owner address for messages
 	// sent automatically
@@ -518,6 +718,34 @@ type MinerAddressConfig struct {
 	DisableWorkerFallback bool
 }
 
+type MinerWithdrawalConfig struct {
+	// Enable automatic withdrawals of the miner available balance
+	Enable bool
+	// Send withdrawal messages from the owner address instead of the
+	// beneficiary. The sending address key must be in the node wallet.
+	FromOwner bool
+	// Address to forward withdrawn funds to from the beneficiary address. When
+	// empty, funds are left with the beneficiary. Forwarding requires the
+	// beneficiary key in the node wallet.
+	Destination string
+
+	// Available balance to keep in the miner actor
+	Float types.FIL
+	// Minimum amount above the float which triggers a withdrawal
+	Threshold types.FIL
+	// How often the available balance is checked
+	CheckInterval Duration
+
+	// Maximum amount withdrawn by a single message, 0 for no limit
+	MaxPerWithdrawal types.FIL
+	// Maximum amount withdrawn within 24 hours, 0 for no limit
+	MaxPerDay types.FIL
+
+	// Only log and journal withdrawals which would be made, without sending
+	// any messages
+	DryRun bool
+}
+
 // API contains configs for API endpoint
 type API struct {
 	// Binding address for the Lotus API
@@ -586,9 +814,88 @@ type Pubsub struct {
 	TracerSourceAuth string
 }
 
+type Webhooks struct {
+	// Endpoints are sent JSON POSTs of node events.
+	Endpoints []WebhookEndpoint
+
+	// SavedQueries are actor event filters, each matching event is sent to
+	// the endpoints as a filter_match event.
+	// Saved queries are only evaluated by chain nodes with Fevm.EnableEthRPC
+	// set, and the real time filter API enabled.
+	SavedQueries []WebhookSavedQuery
+}
+
+type ClockCheck struct {
+	// Enable periodically measures the offset of the system clock against NTP
+	// servers, and raises an alert when it's above Threshold. A clock running
+	// behind rejects valid blocks as being from the future, and a clock
+	// running ahead mines blocks which arrive late. It's disabled by default,
+	// as it sends requests to the Servers, which are third parties.
+	Enable bool
+	// Servers are the NTP servers queried in turn until one answers, as a host
+	// with an optional port.
+	Servers []string
+	// Interval is the time between measurements.
+	Interval Duration
+	// Threshold is the offset above which the alert is raised, and block
+	// validation failures are explained by the skew.
+	Threshold Duration
+}
+
+type WebhookEndpoint struct {
+	// URL the events are POSTed to.
+	URL string
+	// Secret is the HMAC-SHA256 key of the X-Lotus-Signature header sent with
+	// each request. Requests are not signed when empty.
+	Secret string
+	// Events sent to the endpoint, all events when empty. One of
+	// filter_match, sector_state, alert_raised or deal_activated.
+	Events []string
+
+	// MaxAttempts is the number of delivery attempts of an event, 5 when 0.
+	MaxAttempts int
+	// InitialBackoff is the wait after the first failed delivery, doubling
+	// after each further failure up to MaxBackoff. 1s and 1m when 0.
+	InitialBackoff Duration
+	MaxBackoff     Duration
+	// QueueSize is the number of undelivered events kept for the endpoint,
+	// newer events are dropped when it's full. 256 when 0.
+	QueueSize int
+}
+
+type WebhookSavedQuery struct {
+	// Name identifies the query in the filter_match events.
+	Name string
+	// Addresses of the emitting actors, f4 or 0x addresses. Any actor when
+	// empty.
+	Addresses []string
+	// Topics lists the accepted 0x prefixed 32 byte values of each of the
+	// event topics, any value when a position is empty.
+	Topics [][]string
+}
+
 type Chainstore struct {
 	EnableSplitstore bool
 	Splitstore       Splitstore
+
+	StateFallback StateFallback
+}
+
+type StateFallback struct {
+	// Enable fetching of chain and state blocks which are missing from the local
+	// blockstore on demand. This allows nodes imported from a lightweight snapshot
+	// to serve queries against historical state (e.g. StateGetActor or eth_call at
+	// old epochs) without keeping a full archive. Fetched blocks are persisted in
+	// the local blockstore.
+	Enable bool
+	// ArchiveAPIInfo is the API info of an archival Lotus node (TOKEN:MULTIADDR,
+	// same format as FULLNODE_API_INFO) which will be queried for missing blocks.
+	// When set, the archival node is tried first, before falling back to bitswap.
+	ArchiveAPIInfo string
+	// ArchivePeers is a list of multiaddrs (including the /p2p/ component) of
+	// archival peers. The node will connect to and protect connections with these
+	// peers, so that missing blocks can be fetched from them over bitswap.
+	ArchivePeers []string
 }
 
 type Splitstore struct {
@@ -653,6 +960,38 @@ type Wallet struct {
 	RemoteBackend string
 	EnableLedger  bool
 	DisableLocal  bool
+
+	// SigningPolicy rate limits signing, and refuses to sign high value
+	// messages until they are approved.
+	SigningPolicy WalletSigningPolicy
+}
+
+type WalletSigningPolicy struct {
+	// Addresses the policy applies to, all wallet addresses when empty.
+	Addresses []string
+
+	// MaxSignatures is the number of signatures allowed per address in each
+	// RateInterval, further signing requests fail. 0 disables rate limiting.
+	// Block signatures are never limited.
+	MaxSignatures int
+	// RateInterval is the period MaxSignatures are allowed in.
+	RateInterval Duration
+
+	// ApprovalThreshold is the value from which messages are refused until
+	// they are approved with WalletApprove, or 'lotus wallet approvals
+	// approve'. Sending such a message fails with an approval_required error
+	// right away, and once the request is approved the same message is signed
+	// when it's sent again. With approvals enabled, the addresses can only sign
+	// messages and blocks: raw signatures, typed data and deal proposals are
+	// denied, as they can't be checked, so miner worker addresses, which sign
+	// election proofs, must be left out of Addresses. 0 disables approvals.
+	ApprovalThreshold types.FIL
+	// ApprovalWebhook is a URL notified of approval requests with a JSON POST
+	// of the request.
+	ApprovalWebhook string
+	// ApprovalTimeout is how long approval requests are kept, and approved
+	// messages can be sent again for.
+	ApprovalTimeout Duration
 }
 
 type FeeConfig struct {
@@ -695,7 +1034,190 @@ type FevmConfig struct {
 	// Set to 0 to keep all mappings
 	EthTxHashMappingLifetimeDays int
 
+	// EthNullRoundPolicy selects what eth_getBlockByNumber returns when the requested height is a null round:
+	//   "error"    - return an error, as the height has no tipset (default)
+	//   "previous" - return the closest non-null tipset below the requested height, with its own block number
+	//   "empty"    - return a synthetic block without transactions at the requested height, on top of the previous tipset
+	EthNullRoundPolicy string
+
 	Events Events
+
+	// SponsoredCalls configures the contract calls relayed through ERC-2771
+	// forwarders with EthSendSponsoredCall, paid for by sponsor addresses of
+	// the node wallet.
+	SponsoredCalls SponsoredCallsConfig
+}
+
+type SponsoredCallsConfig struct {
+	// Enable enables sending sponsored calls.
+	Enable bool
+
+	// Sponsors are the wallet addresses allowed to pay for sponsored calls.
+	// The first one pays for the calls which don't name their sponsor.
+	Sponsors []string
+	// Forwarders are the forwarder contracts calls are relayed through, as
+	// Ethereum addresses. They must have the interface of the OpenZeppelin
+	// MinimalForwarder.
+	Forwarders []string
+	// Targets are the only contracts calls may be made to, as Ethereum
+	// addresses. Calls may be made to any contract when empty.
+	Targets []string
+
+	// MaxValue is the maximum value a call forwards, paid by the sponsor.
+	MaxValue string
+	// MaxFee is the maximum fee paid for a call. The fee is estimated when
+	// empty.
+	MaxFee string
+	// MaxCallsPerHour is the number of calls sponsored per account per hour.
+	// 0 doesn't limit calls.
+	MaxCallsPerHour int
+}
+
+type UserActorsConfig struct {
+	// Enable enables the experimental APIs installing and instantiating user
+	// WASM actors. They only work on networks whose init actor supports
+	// installing actor code.
+	Enable bool
+
+	// MaxCodeSize is the maximum size in bytes of the actor code the APIs
+	// accept. 0 means no limit.
+	MaxCodeSize int
+}
+
+type SubscriptionsConfig struct {
+	// ResumeWindow is how many epochs below the head the cursors of
+	// ChainNotifyResume and of eth_subscribe logs subscriptions can resume
+	// from. Older cursors are rejected, and clients have to start over. 0
+	// disables resuming.
+	ResumeWindow int
+}
+
+type LightClientConfig struct {
+	// Checkpoint is the tipset a light client (lotus daemon --light) starts
+	// syncing block headers from, as the CIDs of its blocks. It should be a
+	// final tipset obtained from a trusted source. When empty, the upstream
+	// node is trusted for the tipset at finality below its head.
+	Checkpoint []string
+
+	// SyncInterval is how often a light client syncs block headers from the
+	// upstream node.
+	SyncInterval Duration
+}
+
+type StateSyncConfig struct {
+	// Serve answers the state sync requests of peers, which fetch the chain
+	// state from this node when bootstrapping from a checkpoint. Serving uses
+	// bandwidth and blockstore reads on behalf of any peer, so it's opt-in.
+	Serve bool
+
+	// Checkpoint is the tipset, as the CIDs of its blocks, a fresh node
+	// fetches the chain state at from its peers, instead of syncing from
+	// genesis or importing a snapshot. The checkpoint is trusted like a
+	// snapshot would be, so it should be obtained from a trusted source. It is
+	// only used when the node holds no chain beyond genesis.
+	Checkpoint []string
+
+	// StateEpochs is the number of epochs below the checkpoint for which the
+	// state trees, message receipts and messages are fetched, for lookbacks.
+	StateEpochs int
+}
+
+type SpendBudgetConfig struct {
+	// Windows are budgets of the FIL the node's wallets spend on gas, in the
+	// messages the node signs and pushes with MpoolPushMessage. Each window is
+	// a token bucket holding up to its Budget, refilling continuously over its
+	// Period, and a message is only pushed when its maximum gas cost,
+	// GasFeeCap × GasLimit, fits in all of them. The maximum cost is reserved
+	// when the message is pushed, and the part it didn't spend is credited
+	// back once it's executed; messages still pending when the node stops keep
+	// their maximum cost charged. The budget is disabled when no windows are
+	// configured.
+	Windows []SpendBudgetWindow
+
+	// Queue holds messages exceeding the budget until it refills, instead of
+	// refusing them. Messages from the same address wait behind a held one.
+	Queue bool
+	// QueueTimeout is how long messages are held, they are refused when the
+	// budget didn't refill in time.
+	QueueTimeout Duration
+}
+
+type SpendBudgetWindow struct {
+	// Period is the duration the budget refills over, e.g. "24h0m0s".
+	Period Duration
+	// Budget is the FIL which can be spent on gas over the Period.
+	Budget types.FIL
+}
+
+type SpamFilterConfig struct {
+	// Enable filters the messages pushed with MpoolPushUntrusted, by gateway
+	// clients and Ethereum transactions, protecting public nodes from flooding
+	// of the message pool. Rejected messages are refused with structured
+	// errors, stating the reason of the rejection.
+	Enable bool
+
+	// MaxSenderPushes is the number of messages a sender can push in each
+	// SenderRateInterval, 0 disables rate limiting.
+	MaxSenderPushes    int
+	SenderRateInterval Duration
+
+	// PressureThreshold is the fraction of the message pool size limit,
+	// between 0 and 1, from which messages are refused when their gas premium
+	// is below PressureMinPremium. 0 disables the check.
+	PressureThreshold float64
+	// PressureMinPremium is the minimum gas premium, per unit of gas, of the
+	// messages pushed while the pool is under pressure.
+	PressureMinPremium types.FIL
+
+	// RecentWindow is how long pushed messages are remembered, to refuse
+	// duplicates, count the replacements of the message of a nonce and count
+	// the rejected pushes of a sender.
+	RecentWindow Duration
+	// MaxReplacements is the number of times the message of a sender nonce can
+	// be replaced in RecentWindow, 0 doesn't limit replacements.
+	MaxReplacements int
+
+	// BanThreshold is the number of pushes of a sender rejected in
+	// RecentWindow, by the filter or by the message pool, after which its
+	// pushes are refused for BanDuration. 0 disables bans.
+	BanThreshold int
+	BanDuration  Duration
+}
+
+type ChainBridgeConfig struct {
+	// Enable publishes the applied and reverted tipsets to Kafka or NATS, along
+	// with the receipts of the executed messages and the actor events matching
+	// EventQueries. The position of the bridge in the chain is persisted once
+	// the broker acknowledged the records, and publishing resumes from it after
+	// restarts and broker outages, so records are published at least once;
+	// consumers can drop duplicates by the record IDs, sent as the Kafka
+	// message key or the NATS message ID. The bridge can only resume within
+	// Subscriptions.ResumeWindow epochs, past it the bridge stops until its
+	// cursor is reset with 'lotus-shed chain-bridge reset-cursor'.
+	// The Kafka and NATS clients are only built into the node with the
+	// mqbridge build tag.
+	Enable bool
+	// Backend is the message queue published to, "kafka" or "nats".
+	Backend string
+	// Servers are the Kafka bootstrap brokers, as host:port, or the NATS
+	// server URLs.
+	Servers []string
+	// TopicPrefix prefixes the names of the Kafka topics, or NATS subjects,
+	// the records are published to: <prefix>tipsets, <prefix>receipts and
+	// <prefix>events. The Kafka topics must exist, records are written to
+	// their first partition to keep them in chain order. The NATS subjects
+	// must be captured by a JetStream stream.
+	TopicPrefix string
+
+	// Receipts publishes the receipts of the executed messages.
+	Receipts bool
+	// EventQueries select the actor events published, in the format of
+	// Webhooks.SavedQueries; f0 addresses are also accepted. Events are
+	// published once per matching query.
+	EventQueries []WebhookSavedQuery
+
+	// RetryInterval is the wait before resuming after a failure to publish.
+	RetryInterval Duration
 }
 
 type Events struct {
@@ -729,13 +1251,380 @@ type Events struct {
 	// relative to the CWD (current working directory).
 	DatabasePath string
 
+	// IndexServiceAddr is the JSON-RPC address of a shared event index service, such as
+	// ws://10.0.0.5:1237/rpc/v0, used instead of a local database for the historic filter APIs.
+	// This lets the nodes of an RPC fleet share a single events database. DatabasePath is
+	// ignored when set.
+	IndexServiceAddr string
+
+	// IndexServiceToken is sent as a bearer token to the event index service.
+	IndexServiceToken string
+
+	// IndexServiceReadOnly makes the node only query the event index service, without storing
+	// the events it applies, for fleets where a single node writes to the service.
+	IndexServiceReadOnly bool
+
+	// IndexAllowedAddresses only stores the events of these contracts in the
+	// historic index, as f4 or 0x addresses, for nodes serving the events of a
+	// few contracts; the events of all contracts are stored when empty.
+	// Historic queries of other contracts fail, and historic queries without
+	// addresses only return the events of these contracts.
+	IndexAllowedAddresses []string
+
+	// IndexDeniedAddresses never stores the events of these contracts in the
+	// historic index, as f4 or 0x addresses, like contracts emitting many
+	// events nobody queries. Historic queries of these contracts fail.
+	IndexDeniedAddresses []string
+
+	// IndexPruneNotRetained deletes the events of the contracts which aren't
+	// retained by IndexAllowedAddresses and IndexDeniedAddresses from a local
+	// index when the node starts. The events can't be restored without
+	// reindexing, so they are kept unless this is set.
+	IndexPruneNotRetained bool
+
 	// Others, not implemented yet:
 	// Set a limit on the number of active websocket subscriptions (may be zero)
 	// Set a timeout for subscription clients
 	// Set upper bound on index size
 }
 
+type RPCExecutionLimits struct {
+	// Timeout is the maximum wall-clock time of a single eth_call,
+	// eth_estimateGas or StateCall execution. The API call fails once the
+	// timeout is reached, and the execution is aborted on its next state read.
+	// The number of executions with a timeout running at once, including
+	// those not aborted yet, is limited to twice the number of CPUs. Set to 0
+	// to disable the limit.
+	Timeout Duration
+
+	// MaxGas caps the gas limit of messages executed by eth_call,
+	// eth_estimateGas and StateCall. Set to 0 to disable the limit.
+	MaxGas int64
+
+	// MaxMemory is the maximum number of bytes of state a single execution may
+	// buffer in memory. Set to 0 to disable the limit.
+	MaxMemory uint64
+
+	// MaxIpldReads is the maximum number of state blocks a single execution
+	// may read, the execution is aborted on the first read over the limit.
+	// Protects the node against contracts crafted to read huge amounts of
+	// state. Set to 0 to disable the limit.
+	MaxIpldReads int64
+
+	// MaxCallDepth is the maximum depth of nested actor calls of a single
+	// execution. The FVM can't be interrupted between actor calls, so it's
+	// checked once the execution completes, except on networks older than
+	// the FVM. Set to 0 to disable the limit.
+	MaxCallDepth int
+
+	// TokenOverrides replace the limits above for requests authenticated with
+	// specific API tokens, e.g. to lift the limits for trusted clients.
+	TokenOverrides []RPCExecutionLimitsOverride
+}
+
+type RPCExecutionLimitsOverride struct {
+	// TokenHash is the hex encoded sha256 hash of the API token the override
+	// applies to, e.g. the output of "echo -n $TOKEN | sha256sum".
+	TokenHash string
+
+	// Timeout, MaxGas, MaxMemory, MaxIpldReads and MaxCallDepth replace the
+	// default limits, 0 means no limit.
+	Timeout      Duration
+	MaxGas       int64
+	MaxMemory    uint64
+	MaxIpldReads int64
+	MaxCallDepth int
+}
+
+type SlowCallLogConfig struct {
+	// Enable logging API calls taking longer than Threshold to the rpc-slow
+	// log subsystem, along with their params, the hash of the API token of the
+	// caller and the time spent in each phase of the call. Only calls made
+	// with HTTP POST requests are timed, not calls made over websockets.
+	Enable bool
+
+	// Threshold is the time after which a call is considered slow.
+	Threshold Duration
+
+	// MaxParamsLength is the number of bytes of the params of a call which are
+	// logged; longer params are truncated.
+	MaxParamsLength int
+
+	// RedactMethods are the methods whose params are never logged, in addition
+	// to the wallet and auth methods, e.g. "Filecoin.MpoolPushMessage".
+	RedactMethods []string
+}
+
+type RPCRecordingConfig struct {
+	// Enable recording the API calls of the allowed methods, with their
+	// responses and latencies, to files of JSON lines which can be replayed
+	// against another node with lotus-shed rpc-replay, to compare the
+	// responses and latencies of a candidate build. API tokens and remote
+	// addresses are never recorded, nor are wallet and auth calls, calls
+	// changing the state of the node such as eth_sendRawTransaction, and filter
+	// calls such as eth_newFilter. Only calls made with HTTP POST requests of up
+	// to 1MiB are recorded, not calls made over websockets.
+	Enable bool
+
+	// Directory the recordings are written to. Defaults to rpc-recordings in
+	// the repo.
+	Directory string
+
+	// Methods are the prefixes of the methods recorded. A batch is only
+	// recorded when all its calls are allowed.
+	Methods []string
+
+	// SampleRate is the fraction of the calls recorded, from 0 to 1.
+	SampleRate float64
+
+	// MaxFileSize is the size in bytes at which a recording file is rotated.
+	MaxFileSize int64
+
+	// MaxFiles is the number of recording files kept; the oldest ones are
+	// removed.
+	MaxFiles int
+
+	// MaxResponseSize is the size in bytes above which responses aren't
+	// recorded; these calls are skipped on replay.
+	MaxResponseSize int
+}
+
+type ChainDataRESTConfig struct {
+	// Enable serving chain data as JSON over plain HTTP GET requests under
+	// /rest/v0/chain on the API endpoint: tipsets by height, messages by CID,
+	// address balances and transaction receipts. The endpoints only read
+	// public chain data and don't require an API token. Responses carry ETags
+	// and Cache-Control headers, so that they can be cached by browsers and
+	// CDNs.
+	Enable bool
+
+	// MaxAge is the time responses about data which can still change, such as
+	// the balance of an address at the head, may be cached for.
+	MaxAge Duration
+
+	// FinalizedMaxAge is the time responses about data which can't change
+	// anymore, such as messages or tipsets past finality, may be cached for.
+	FinalizedMaxAge Duration
+}
+
 type IndexConfig struct {
 	// EnableMsgIndex enables indexing of messages on chain.
 	EnableMsgIndex bool
+
+	// EnableAddrIndex enables indexing the messages sent and received by each
+	// address, served by the StateListAddressMessages API. Only the tipsets
+	// applied after the index is enabled are indexed.
+	EnableAddrIndex bool
+
+	// EnableGasStats enables aggregating the gas used by executed messages per
+	// actor code and method, served by the StateGasStats API.
+	EnableGasStats bool
+	// GasStatsWindow is the number of recent epochs gas stats are kept for.
+	GasStatsWindow int64
+
+	// EnableSQLQuery enables the ChainIndexQuery API, running read-only SQL
+	// queries over the events, transaction hash and message index databases.
+	EnableSQLQuery bool
+	// SQLQueryMaxRows is the maximum number of rows returned by a query.
+	SQLQueryMaxRows int
+	// SQLQueryTimeout is the maximum duration of a query.
+	SQLQueryTimeout Duration
+}
+
+type MaintenanceConfig struct {
+	// Windows are the maintenance windows, the only times expensive background
+	// activities run at: splitstore compaction, chain snapshot exports and the
+	// maintenance tasks enabled below. When no windows are configured, these
+	// activities run whenever they are due, and the maintenance tasks don't run.
+	Windows []MaintenanceWindow
+
+	// BadgerGC enables running the value log garbage collection of the badger
+	// chain blockstore at the start of each window. It has no effect with the
+	// splitstore enabled, which garbage collects the blockstore itself.
+	BadgerGC bool
+
+	// VacuumSQLite enables vacuuming the SQLite databases of the events index
+	// and of the Ethereum transaction hash lookup at the start of each window,
+	// to reclaim the space of deleted rows.
+	VacuumSQLite bool
+}
+
+type MaintenanceWindow struct {
+	// Schedule is a cron expression (minute, hour, day of month, month, day of
+	// week) specifying when the window opens, e.g. "0 2 * * *" for 2am every
+	// day, in the local time zone unless prefixed with e.g. "CRON_TZ=UTC".
+	// Descriptors like "@daily" are also supported.
+	Schedule string
+
+	// Duration is how long the window stays open.
+	Duration Duration
+}
+
+type SnapshotsConfig struct {
+	// EnableUpload enables periodic export of chain snapshots, which are
+	// compressed with zstd and uploaded to S3-compatible object storage along
+	// with a sha256 checksum file.
+	EnableUpload bool
+
+	// Schedule is a cron expression (minute, hour, day of month, month, day of
+	// week) specifying when snapshots are taken, e.g. "0 */6 * * *" for every 6
+	// hours. Descriptors like "@daily" are also supported.
+	Schedule string
+
+	// Confidence is the number of epochs behind the chain head at which the
+	// snapshot is taken, so that the exported tipset is unlikely to be reverted.
+	Confidence int64
+
+	// RecentStateRoots is the number of recent state trees included in the
+	// snapshot.
+	RecentStateRoots int64
+
+	// StagingPath is the directory in which snapshots are written before being
+	// uploaded. Defaults to the 'snapshots' directory in the repo.
+	StagingPath string
+
+	// Endpoint is the URL of the S3-compatible object storage, e.g.
+	// "https://s3.us-east-1.amazonaws.com", or "https://storage.googleapis.com"
+	// for GCS with HMAC keys.
+	Endpoint string
+	// Region used for request signing.
+	Region string
+	// Bucket in which snapshots are stored.
+	Bucket string
+	// Prefix is prepended to the names of uploaded objects, e.g. "mainnet/".
+	Prefix string
+	// AccessKey used for request signing.
+	AccessKey string
+	// SecretKey used for request signing.
+	SecretKey string
+
+	// Retention is the number of most recent snapshots kept in the bucket;
+	// older snapshots are deleted after each successful upload. 0 keeps all
+	// snapshots.
+	Retention int
+
+	// SigningKey is the address of a wallet key used to sign the manifests
+	// uploaded along with the snapshots. Manifests are left unsigned when
+	// empty.
+	SigningKey string
+
+	// TrustedPublishers are the addresses of the keys trusted to sign the
+	// manifests of imported snapshots, in addition to the publishers built
+	// into the node. Once any publisher is trusted, imported snapshots must
+	// come with a manifest signed by one of them.
+	TrustedPublishers []string
+}
+
+type HealthConfig struct {
+	// MaxSyncLag is the number of epochs the chain head can be behind the
+	// current epoch for the node to be ready.
+	MaxSyncLag uint64
+	// MinPeers is the number of connected peers required for the node to be
+	// ready.
+	MinPeers int
+	// MaxAPILatency is the time within which the node must load the chain head
+	// to be ready.
+	MaxAPILatency Duration
+	// MaxEventsIndexLag is the number of epochs actor events can be processed
+	// behind the chain head for the node to be ready. Only checked when the
+	// Eth RPC events are enabled; 0 disables the check.
+	MaxEventsIndexLag int64
+}
+
+type MessageTracingConfig struct {
+	// Enable exports the messages of sampled tipsets executed by the node as
+	// OpenTelemetry traces, one trace per message with a span for each
+	// internal call.
+	Enable bool
+
+	// Endpoint is the OTLP/HTTP traces endpoint of the collector spans are
+	// sent to, using the JSON encoding.
+	Endpoint string
+
+	// SampleRate is the fraction of executed tipsets whose messages are traced.
+	// Tipsets are sampled by their key, so nodes with the same rate trace the
+	// same tipsets. Sampled tipsets are executed with detailed VM tracing,
+	// which makes their execution slower.
+	SampleRate float64
+}
+
+type StateReplayCacheConfig struct {
+	// Size is the number of replay results kept for StateReplayCached, the
+	// least recently used results are evicted first. 0 disables the cache.
+	Size int
+	// TTL is how long results are served from the cache, 0 keeps them until
+	// they are evicted.
+	TTL Duration
+}
+
+type ExecutionConfig struct {
+	// Lanes is the number of VM executions run concurrently. When set, it also
+	// sizes the FVM thread pool, unless LOTUS_FVM_CONCURRENCY is set.
+	// 0 uses LOTUS_FVM_CONCURRENCY, or 4 when it's not set.
+	Lanes int
+	// ReservedLanes is the number of lanes only chain validation runs in, so
+	// that RPC driven executions (eth_call, gas estimation, replays) never
+	// delay the validation of the next tipset. It must be lower than Lanes.
+	// 0 uses LOTUS_FVM_CONCURRENCY_RESERVED, or 2 when it's not set.
+	ReservedLanes int
+}
+
+type UpgradesConfig struct {
+	// Announced are network upgrades announced for the network, e.g. by the
+	// release notes of the release performing them. The upgrades built into
+	// this binary only tell whether it's ready for the upgrades it knows
+	// about, alerts are raised as an announced upgrade it can't perform
+	// approaches: one to a network version it doesn't support, or which it
+	// schedules at another epoch.
+	Announced []AnnouncedUpgrade
+	// AnnouncementsURL is the URL of a JSON list of announced upgrades, in the
+	// format of Announced, e.g. [{"Network": 21, "Height": 3469380}], checked
+	// along with Announced.
+	AnnouncementsURL string
+}
+
+type AnnouncedUpgrade struct {
+	// Network is the network version the upgrade upgrades to
+	Network uint
+	// Height is the epoch of the upgrade
+	Height int64
+}
+
+type DrandServer struct {
+	// Chain is the chain hash of the drand network the server serves, as listed
+	// in the /info of the server
+	Chain string
+	// URL of the HTTP endpoint of the server
+	URL string
+}
+
+type MessageSelectionConfig struct {
+	// Selector is the name of the policy selecting the messages of the blocks
+	// mined through this node: "default" uses "greedy" selection for blocks
+	// with a good ticket and "optimal" selection otherwise. Nodes built with
+	// custom policies can use the names they register with
+	// messagepool.RegisterSelector.
+	Selector string
+}
+
+type BeaconConfig struct {
+	// DrandServers are the HTTP endpoints of drand relays fetched from in
+	// addition to the built-in ones, each for the drand network it serves.
+	// Servers failing repeatedly are marked unhealthy and fetches fail over to
+	// the healthy ones.
+	DrandServers []DrandServer
+	// ReplaceDefaultServers only fetches from DrandServers, without the built-in
+	// servers, for the drand networks servers are configured for.
+	ReplaceDefaultServers bool
+
+	// FetchTimeout bounds the time spent fetching a beacon entry from all
+	// servers, after which the round is counted as missed.
+	FetchTimeout Duration
+	// UnhealthyAfter is the number of consecutive failed fetches after which a
+	// server is marked unhealthy. An alert is raised when all servers are
+	// unhealthy.
+	UnhealthyAfter int
+	// RetryUnhealthyAfter is the time after which an unhealthy server is tried
+	// again.
+	RetryUnhealthyAfter Duration
 }
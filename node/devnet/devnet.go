@@ -0,0 +1,570 @@
+// Package devnet runs a local single-miner network in-process, with mock
+// proofs and blocks mined on demand, for developing against the Lotus and Eth
+// APIs without a real network or sealing.
+package devnet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	libp2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	cborutil "github.com/filecoin-project/go-cbor-util"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/v1api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/consensus/filcns"
+	"github.com/filecoin-project/lotus/chain/gen"
+	genesis2 "github.com/filecoin-project/lotus/chain/gen/genesis"
+	"github.com/filecoin-project/lotus/chain/gen/slashfilter"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/wallet/key"
+	"github.com/filecoin-project/lotus/genesis"
+	lotusminer "github.com/filecoin-project/lotus/miner"
+	"github.com/filecoin-project/lotus/node"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/impl/full"
+	"github.com/filecoin-project/lotus/node/modules"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	testing2 "github.com/filecoin-project/lotus/node/modules/testing"
+	"github.com/filecoin-project/lotus/node/repo"
+	pipeline "github.com/filecoin-project/lotus/storage/pipeline"
+	sectorstorage "github.com/filecoin-project/lotus/storage/sealer"
+	"github.com/filecoin-project/lotus/storage/sealer/mock"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+var log = logging.Logger("devnet")
+
+const (
+	// presealSectors 2KiB sectors give the miner the minimum consensus power
+	// of the testing network
+	presealSectors = 2
+	sectorSize     = abi.SectorSize(2 << 10)
+
+	// genesisOffset puts the genesis far enough in the past that blocks can be
+	// mined as fast as they are requested without getting ahead of the clock
+	genesisOffset = 10000000 * time.Second
+
+	maxMineAttempts = 1000
+)
+
+// Options configures a devnet. The genesis is built from Genesis, with the
+// devnet miner added to it.
+type Options struct {
+	Genesis gen.DevnetOptions
+}
+
+// Devnet is a full node and a miner running in-process. Blocks are only mined
+// when requested, with Mine or Run, or with the MineOne and AdvanceEpochs
+// methods of the full node API.
+type Devnet struct {
+	FullNode  api.FullNode
+	Miner     api.StorageMiner
+	MinerAddr address.Address
+
+	Accounts  []gen.DevnetAccount
+	Contracts []gen.DevnetContract
+
+	miner  *lotusminer.Miner
+	mineCh chan lotusminer.MineReq
+	mineLk sync.Mutex
+	// maxEpoch is the highest epoch mined so far
+	maxEpoch abi.ChainEpoch
+
+	stops []node.StopFunc
+	repos []*repo.MemRepo
+}
+
+// setupTestingNetwork switches the process to the parameters of the testing
+// network with mock proofs, the way the integration tests do. These settings
+// are global: a process running a devnet can't run any other node.
+func setupTestingNetwork() error {
+	policy.SetProviderCollateralSupplyTarget(big.NewInt(0), big.NewInt(100))
+	policy.SetConsensusMinerMinPower(abi.NewStoragePower(2048))
+	policy.SetMinVerifiedDealSize(abi.NewStoragePower(256))
+	policy.SetPreCommitChallengeDelay(10)
+	policy.SetSupportedProofTypes(
+		abi.RegisteredSealProof_StackedDrg2KiBV1,
+		abi.RegisteredSealProof_StackedDrg8MiBV1,
+		abi.RegisteredSealProof_StackedDrg512MiBV1,
+		abi.RegisteredSealProof_StackedDrg32GiBV1,
+		abi.RegisteredSealProof_StackedDrg64GiBV1,
+	)
+
+	build.InsecurePoStValidation = true
+	// mock proofs don't need proof parameters
+	build.DisableBuiltinAssets = true
+
+	return build.UseNetworkBundle("testing-fake-proofs")
+}
+
+// upgradeSchedule returns the upgrade schedule of a devnet preset: the
+// network starts at the preset network version, and the following upgrades
+// happen at the preset heights.
+func upgradeSchedule(preset gen.DevnetPreset) stmgr.UpgradeSchedule {
+	us := stmgr.UpgradeSchedule{{
+		Network: preset.NetworkVersion,
+		Height:  -1,
+	}}
+	for _, u := range filcns.DefaultUpgradeSchedule() {
+		if u.Network <= preset.NetworkVersion {
+			continue
+		}
+		if h, ok := preset.Upgrades[u.Network]; ok {
+			u.Height = h
+			u.PreMigrations = nil
+		}
+		if u.Height < 0 {
+			continue
+		}
+		us = append(us, u)
+	}
+	return us
+}
+
+// New creates the genesis of a devnet and starts its nodes. The devnet miner
+// mines the first two blocks, so that the chain is ready for use once New
+// returns.
+func New(ctx context.Context, opts Options) (_ *Devnet, err error) {
+	if err := setupTestingNetwork(); err != nil {
+		return nil, xerrors.Errorf("setting up testing network: %w", err)
+	}
+
+	tmpl, accounts, contracts, err := gen.DevnetTemplate(opts.Genesis)
+	if err != nil {
+		return nil, xerrors.Errorf("creating genesis template: %w", err)
+	}
+	upgrades := upgradeSchedule(opts.Genesis.Preset)
+	if err := upgrades.Validate(); err != nil {
+		return nil, xerrors.Errorf("invalid upgrade schedule: %w", err)
+	}
+
+	d := &Devnet{
+		Accounts:  accounts,
+		Contracts: contracts,
+		mineCh:    make(chan lotusminer.MineReq),
+	}
+	defer func() {
+		if err != nil {
+			_ = d.Stop(context.Background())
+		}
+	}()
+
+	// the miner, pre-sealed with mock sectors
+	d.MinerAddr, err = address.NewIDAddress(genesis2.MinerStart)
+	if err != nil {
+		return nil, err
+	}
+	minerKey, _, err := libp2pcrypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	minerPeer, err := peer.IDFromPrivateKey(minerKey)
+	if err != nil {
+		return nil, err
+	}
+
+	spt, err := miner.SealProofTypeFromSectorSize(sectorSize, tmpl.NetworkVersion)
+	if err != nil {
+		return nil, err
+	}
+	genm, ki, err := mock.PreSeal(spt, d.MinerAddr, presealSectors)
+	if err != nil {
+		return nil, xerrors.Errorf("pre-sealing sectors: %w", err)
+	}
+	ownerKey, err := key.NewKey(*ki)
+	if err != nil {
+		return nil, err
+	}
+	genm.PeerId = minerPeer
+
+	tmpl.Miners = append(tmpl.Miners, *genm)
+	tmpl.Accounts = append(tmpl.Accounts, genesis.Actor{
+		Type:    genesis.TAccount,
+		Balance: big.Mul(big.NewInt(100000000), types.NewInt(build.FilecoinPrecision)),
+		Meta:    (&genesis.AccountMeta{Owner: ownerKey.Address}).ActorMeta(),
+	})
+	tmpl.Timestamp = uint64(time.Now().Add(-genesisOffset).Unix())
+
+	mn := mocknet.New()
+	manualMining := &full.ManualMining{}
+
+	// the full node
+	fullRepo := repo.NewMemory(nil)
+	d.repos = append(d.repos, fullRepo)
+	if err := setConfig(fullRepo, repo.FullNode, func(cfg interface{}) {
+		cfg.(*config.FullNode).Fevm.EnableEthRPC = true
+	}); err != nil {
+		return nil, err
+	}
+
+	stop, err := node.New(ctx,
+		node.FullAPI(&d.FullNode),
+		node.Base(),
+		node.Repo(fullRepo),
+		node.MockHost(mn),
+		node.Test(),
+
+		node.Override(new(dtypes.Bootstrapper), dtypes.Bootstrapper(true)),
+		node.Override(new(stmgr.UpgradeSchedule), upgrades),
+		node.Override(new(modules.Genesis), testing2.MakeGenesisMem(io.Discard, *tmpl)),
+
+		node.Override(new(storiface.Verifier), mock.MockVerifier),
+		node.Override(new(storiface.Prover), mock.MockProver),
+		node.Override(new(*full.ManualMining), manualMining),
+		// blocks mined on a fork have the same parents as blocks mined
+		// before, which the slash filter rejects
+		node.Unset(new(*slashfilter.SlashFilter)),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("starting full node: %w", err)
+	}
+	d.stops = append(d.stops, stop)
+
+	owner, err := d.FullNode.WalletImport(ctx, &ownerKey.KeyInfo)
+	if err != nil {
+		return nil, xerrors.Errorf("importing miner owner key: %w", err)
+	}
+	if err := d.FullNode.WalletSetDefault(ctx, owner); err != nil {
+		return nil, err
+	}
+	for _, a := range accounts {
+		if _, err := d.FullNode.WalletImport(ctx, &a.Key.KeyInfo); err != nil {
+			return nil, xerrors.Errorf("importing account key: %w", err)
+		}
+	}
+
+	// the miner
+	minerRepo := repo.NewMemory(nil)
+	d.repos = append(d.repos, minerRepo)
+
+	var subsystems config.MinerSubsystemConfig
+	if err := setConfig(minerRepo, repo.StorageMiner, func(cfg interface{}) {
+		mcfg := cfg.(*config.StorageMiner)
+		mcfg.Subsystems.EnableMarkets = false
+		mcfg.Subsystems.EnableMining = true
+		mcfg.Subsystems.EnableSealing = true
+		mcfg.Subsystems.EnableSectorStorage = true
+		subsystems = mcfg.Subsystems
+	}); err != nil {
+		return nil, err
+	}
+	if err := initMinerRepo(ctx, minerRepo, d.MinerAddr, minerKey, genm); err != nil {
+		return nil, xerrors.Errorf("initializing miner repo: %w", err)
+	}
+
+	var presealIDs []abi.SectorID
+	for _, s := range genm.Sectors {
+		presealIDs = append(presealIDs, abi.SectorID{Miner: abi.ActorID(genesis2.MinerStart), Number: s.SectorID})
+	}
+
+	stop, err = node.New(ctx,
+		node.StorageMiner(&d.Miner, subsystems),
+		node.Base(),
+		node.Repo(minerRepo),
+		node.Test(),
+		node.MockHost(mn),
+
+		node.Override(new(v1api.RawFullNodeAPI), d.FullNode),
+		node.Override(new(*lotusminer.Miner), func(api v1api.FullNode, epp gen.WinningPoStProver) *lotusminer.Miner {
+			d.miner = lotusminer.NewTestMinerWithoutSlashFilter(d.mineCh, d.MinerAddr)(api, epp)
+			return d.miner
+		}),
+		node.Override(new(stmgr.UpgradeSchedule), upgrades),
+
+		// the only worker is the local one, it gets tasks regardless of
+		// system pressure
+		node.Override(new(config.SealerConfig), func() config.SealerConfig {
+			scfg := config.DefaultStorageMiner()
+			scfg.Storage.ResourceFiltering = config.ResourceFilteringDisabled
+			return scfg.Storage
+		}),
+
+		node.Override(new(*mock.SectorMgr), func() (*mock.SectorMgr, error) {
+			return mock.NewMockSectorMgr(presealIDs), nil
+		}),
+		node.Override(new(sectorstorage.SectorManager), node.From(new(*mock.SectorMgr))),
+		node.Override(new(sectorstorage.Unsealer), node.From(new(*mock.SectorMgr))),
+		node.Override(new(sectorstorage.PieceProvider), node.From(new(*mock.SectorMgr))),
+		node.Override(new(storiface.Verifier), mock.MockVerifier),
+		node.Override(new(storiface.Prover), mock.MockProver),
+		node.Unset(new(*sectorstorage.Manager)),
+	)
+	if err != nil {
+		return nil, xerrors.Errorf("starting miner: %w", err)
+	}
+	d.stops = append(d.stops, stop)
+
+	if err := mn.LinkAll(); err != nil {
+		return nil, xerrors.Errorf("linking nodes: %w", err)
+	}
+
+	manualMining.SetProducer(d)
+
+	if _, err := d.Mine(ctx, 2); err != nil {
+		return nil, xerrors.Errorf("mining first blocks: %w", err)
+	}
+
+	return d, nil
+}
+
+// Mine mines n blocks on top of the current head, and returns the new head.
+// Epochs in which the miner doesn't win are left as null rounds.
+func (d *Devnet) Mine(ctx context.Context, n int) (*types.TipSet, error) {
+	d.mineLk.Lock()
+	defer d.mineLk.Unlock()
+
+	for i := 0; i < n; i++ {
+		if err := d.mineBlock(ctx, 0); err != nil {
+			return nil, err
+		}
+	}
+	return d.FullNode.ChainHead(ctx)
+}
+
+// MineBlock mines a block on top of the head, after the given number of null
+// rounds. More null rounds are added while the miner doesn't win, and when
+// the chain was forked below the highest epoch mined so far.
+func (d *Devnet) MineBlock(ctx context.Context, nulls abi.ChainEpoch) error {
+	d.mineLk.Lock()
+	defer d.mineLk.Unlock()
+
+	return d.mineBlock(ctx, nulls)
+}
+
+// Fork sets the head back to the given tipset, the next blocks are mined on
+// top of it.
+func (d *Devnet) Fork(ctx context.Context, ts *types.TipSet) error {
+	d.mineLk.Lock()
+	defer d.mineLk.Unlock()
+
+	if err := d.FullNode.ChainSetHead(ctx, ts.Key()); err != nil {
+		return err
+	}
+	d.miner.ResetMiningBase()
+	return nil
+}
+
+func (d *Devnet) mineBlock(ctx context.Context, nulls abi.ChainEpoch) error {
+	// the miner refuses to mine twice at the same height, blocks mined on a
+	// fork go after the highest epoch mined so far
+	head, err := d.FullNode.ChainHead(ctx)
+	if err != nil {
+		return err
+	}
+	if min := d.maxEpoch - head.Height(); nulls < min {
+		nulls = min
+	}
+
+	type result struct {
+		win   bool
+		epoch abi.ChainEpoch
+		err   error
+	}
+
+	for i := 0; i < maxMineAttempts; i++ {
+		done := make(chan result, 1)
+		req := lotusminer.MineReq{InjectNulls: nulls, Done: func(win bool, epoch abi.ChainEpoch, err error) {
+			done <- result{win, epoch, err}
+		}}
+		nulls = 0
+
+		select {
+		case d.mineCh <- req:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		var res result
+		select {
+		case res = <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		if res.err != nil {
+			return xerrors.Errorf("mining block: %w", res.err)
+		}
+		if !res.win {
+			continue
+		}
+		if res.epoch > d.maxEpoch {
+			d.maxEpoch = res.epoch
+		}
+
+		// the block is submitted after the miner reports it
+		for {
+			head, err := d.FullNode.ChainHead(ctx)
+			if err != nil {
+				return err
+			}
+			if head.Height() >= res.epoch {
+				return nil
+			}
+
+			select {
+			case <-time.After(10 * time.Millisecond):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+
+	return xerrors.Errorf("no block mined after %d attempts", maxMineAttempts)
+}
+
+// Run mines blocks until the context is cancelled: one every blockTime if it
+// isn't zero, and one as soon as a message enters the mpool if automine is
+// set.
+func (d *Devnet) Run(ctx context.Context, blockTime time.Duration, automine bool) error {
+	var tick <-chan time.Time
+	if blockTime > 0 {
+		t := time.NewTicker(blockTime)
+		defer t.Stop()
+		tick = t.C
+	}
+
+	var mpool <-chan api.MpoolUpdate
+	if automine {
+		sub, err := d.FullNode.MpoolSub(ctx)
+		if err != nil {
+			return xerrors.Errorf("subscribing to mpool: %w", err)
+		}
+		mpool = sub
+	}
+
+	for {
+		select {
+		case <-tick:
+		case u, ok := <-mpool:
+			if !ok {
+				return xerrors.Errorf("mpool subscription closed")
+			}
+			if u.Type != api.MpoolAdd {
+				continue
+			}
+		case <-ctx.Done():
+			return nil
+		}
+
+		if _, err := d.Mine(ctx, 1); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			log.Errorf("mining block: %s", err)
+		}
+	}
+}
+
+// Stop stops the nodes of the devnet.
+func (d *Devnet) Stop(ctx context.Context) error {
+	var err error
+	for i := len(d.stops) - 1; i >= 0; i-- {
+		if serr := d.stops[i](ctx); serr != nil && err == nil {
+			err = serr
+		}
+	}
+	d.stops = nil
+	for _, r := range d.repos {
+		r.Cleanup()
+	}
+	d.repos = nil
+	return err
+}
+
+func setConfig(r *repo.MemRepo, t repo.RepoType, set func(cfg interface{})) error {
+	lr, err := r.Lock(t)
+	if err != nil {
+		return err
+	}
+	if err := lr.SetConfig(set); err != nil {
+		_ = lr.Close()
+		return xerrors.Errorf("setting config: %w", err)
+	}
+	return lr.Close()
+}
+
+// initMinerRepo stores the miner identity, and the pre-sealed sectors as
+// proving sectors of the sealing pipeline.
+func initMinerRepo(ctx context.Context, r *repo.MemRepo, maddr address.Address, pk libp2pcrypto.PrivKey, genm *genesis.Miner) error {
+	lr, err := r.Lock(repo.StorageMiner)
+	if err != nil {
+		return err
+	}
+	defer lr.Close() //nolint:errcheck
+
+	ks, err := lr.KeyStore()
+	if err != nil {
+		return err
+	}
+	kbytes, err := libp2pcrypto.MarshalPrivateKey(pk)
+	if err != nil {
+		return err
+	}
+	if err := ks.Put("libp2p-host", types.KeyInfo{
+		Type:       "libp2p-host",
+		PrivateKey: kbytes,
+	}); err != nil {
+		return err
+	}
+
+	mds, err := lr.Datastore(ctx, "/metadata")
+	if err != nil {
+		return err
+	}
+	if err := mds.Put(ctx, datastore.NewKey("miner-address"), maddr.Bytes()); err != nil {
+		return err
+	}
+
+	maxSectorID := abi.SectorNumber(0)
+	for _, sector := range genm.Sectors {
+		commD := sector.CommD
+		commR := sector.CommR
+
+		b, err := cborutil.Dump(&pipeline.SectorInfo{
+			State:        pipeline.Proving,
+			SectorNumber: sector.SectorID,
+			Pieces: []api.SectorPiece{{
+				Piece: abi.PieceInfo{
+					Size:     abi.PaddedPieceSize(genm.SectorSize),
+					PieceCID: commD,
+				},
+			}},
+			CommD: &commD,
+			CommR: &commR,
+		})
+		if err != nil {
+			return err
+		}
+
+		sectorKey := datastore.NewKey(pipeline.SectorStorePrefix).ChildString(fmt.Sprint(sector.SectorID))
+		if err := mds.Put(ctx, sectorKey, b); err != nil {
+			return err
+		}
+
+		if sector.SectorID > maxSectorID {
+			maxSectorID = sector.SectorID
+		}
+	}
+
+	buf := make([]byte, binary.MaxVarintLen64)
+	size := binary.PutUvarint(buf, uint64(maxSectorID))
+	return mds.Put(ctx, datastore.NewKey(pipeline.StorageCounterDSPrefix), buf[:size])
+}
@@ -0,0 +1,41 @@
+// stm: #unit
+package devnet
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/chain/gen"
+)
+
+func TestUpgradeSchedule(t *testing.T) {
+	latest, err := gen.DevnetPresetByName("latest")
+	require.NoError(t, err)
+
+	us := upgradeSchedule(latest)
+	require.NoError(t, us.Validate())
+	require.Equal(t, latest.NetworkVersion, us[0].Network)
+	require.Equal(t, abi.ChainEpoch(-1), us[0].Height)
+	for _, u := range us[1:] {
+		require.Greater(t, u.Network, latest.NetworkVersion)
+	}
+
+	us = upgradeSchedule(gen.DevnetPreset{
+		NetworkVersion: network.Version18,
+		Upgrades: map[network.Version]abi.ChainEpoch{
+			network.Version19: 10,
+			network.Version20: 20,
+		},
+	})
+	require.NoError(t, us.Validate())
+	require.Equal(t, network.Version18, us[0].Network)
+	require.Equal(t, network.Version19, us[1].Network)
+	require.Equal(t, abi.ChainEpoch(10), us[1].Height)
+	require.NotNil(t, us[1].Migration)
+	require.Equal(t, network.Version20, us[2].Network)
+	require.Equal(t, abi.ChainEpoch(20), us[2].Height)
+}
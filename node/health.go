@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"sync/atomic"
 	"time"
@@ -116,3 +117,79 @@ func NewReadyHandler(api lapi.FullNode) *HealthHandler {
 	}()
 	return &h
 }
+
+// NodeHealthHandler reports whether the node is ready to serve requests, as
+// decided by the health checks of NodeStatus against the thresholds of the
+// Health config section. The health report is written as the response body.
+type NodeHealthHandler struct {
+	api lapi.FullNode
+}
+
+func NewNodeHealthHandler(api lapi.FullNode) *NodeHealthHandler {
+	return &NodeHealthHandler{api: api}
+}
+
+func (h *NodeHealthHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+	defer cancel()
+
+	status, err := h.api.NodeStatus(ctx, false)
+	if err != nil {
+		healthlog.Warnf("failed to get node status: %s", err)
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if !status.Health.Ready {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(status.Health); err != nil {
+		healthlog.Warnf("failed to write health report: %s", err)
+	}
+}
+
+// ActiveHandler reports whether this node is the active member of a node
+// cluster. Only the raft leader signs and pushes messages for the cluster
+// wallets, so load balancers fronting a shared RPC endpoint can use this
+// check to route traffic to the active node and fail over to a standby when
+// leadership moves.
+//
+// Nodes which don't run in cluster mode are always active.
+type ActiveHandler struct {
+	api lapi.FullNode
+}
+
+func NewActiveHandler(api lapi.FullNode) *ActiveHandler {
+	return &ActiveHandler{api: api}
+}
+
+func (h *ActiveHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	self, err := h.api.ID(ctx)
+	if err != nil {
+		healthlog.Warnf("failed to get node peer ID: %s", err)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	leader, err := h.api.RaftLeader(ctx)
+	if err != nil {
+		// RaftLeader errors both when cluster mode is disabled and when the
+		// cluster currently has no leader; RaftState only errors in the
+		// former case, in which the node is always active.
+		if _, serr := h.api.RaftState(ctx); serr != nil {
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	if leader != self {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,52 @@
+// stm: #unit
+package node
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/api/mocks"
+)
+
+func TestNodeHealthHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	full := mocks.NewMockFullNode(ctrl)
+	h := NewNodeHealthHandler(full)
+
+	serve := func() (*httptest.ResponseRecorder, api.NodeHealth) {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/health/readyz", nil))
+
+		var health api.NodeHealth
+		if rec.Header().Get("Content-Type") == "application/json" {
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &health))
+		}
+		return rec, health
+	}
+
+	full.EXPECT().NodeStatus(gomock.Any(), false).Return(api.NodeStatus{
+		Health: api.NodeHealth{Ready: true, Peers: 10, EventsIndexLag: -1},
+	}, nil)
+	rec, health := serve()
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Equal(t, 10, health.Peers)
+
+	full.EXPECT().NodeStatus(gomock.Any(), false).Return(api.NodeStatus{
+		Health: api.NodeHealth{Failing: []string{"0 peers connected, less than 1"}},
+	}, nil)
+	rec, health = serve()
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+	require.Equal(t, []string{"0 peers connected, less than 1"}, health.Failing)
+
+	full.EXPECT().NodeStatus(gomock.Any(), false).Return(api.NodeStatus{}, http.ErrHandlerTimeout)
+	rec, _ = serve()
+	require.Equal(t, http.StatusServiceUnavailable, rec.Code)
+}
@@ -9,43 +9,57 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/lib/backupds"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/repo"
+	"github.com/filecoin-project/lotus/node/repo/archive"
 )
 
-func backup(ctx context.Context, mds dtypes.MetadataDS, fpath string) error {
+// backupPath checks that a backup file is within LOTUS_BACKUP_BASE_PATH, returning its
+// absolute path.
+func backupPath(fpath string) (string, error) {
 	bb, ok := os.LookupEnv("LOTUS_BACKUP_BASE_PATH")
 	if !ok {
-		return xerrors.Errorf("LOTUS_BACKUP_BASE_PATH env var not set")
-	}
-
-	bds, ok := mds.(*backupds.Datastore)
-	if !ok {
-		return xerrors.Errorf("expected a backup datastore")
+		return "", xerrors.Errorf("LOTUS_BACKUP_BASE_PATH env var not set")
 	}
 
 	bb, err := homedir.Expand(bb)
 	if err != nil {
-		return xerrors.Errorf("expanding base path: %w", err)
+		return "", xerrors.Errorf("expanding base path: %w", err)
 	}
 
 	bb, err = filepath.Abs(bb)
 	if err != nil {
-		return xerrors.Errorf("getting absolute base path: %w", err)
+		return "", xerrors.Errorf("getting absolute base path: %w", err)
 	}
 
 	fpath, err = homedir.Expand(fpath)
 	if err != nil {
-		return xerrors.Errorf("expanding file path: %w", err)
+		return "", xerrors.Errorf("expanding file path: %w", err)
 	}
 
 	fpath, err = filepath.Abs(fpath)
 	if err != nil {
-		return xerrors.Errorf("getting absolute file path: %w", err)
+		return "", xerrors.Errorf("getting absolute file path: %w", err)
 	}
 
 	if !strings.HasPrefix(fpath, bb) {
-		return xerrors.Errorf("backup file name (%s) must be inside base path (%s)", fpath, bb)
+		return "", xerrors.Errorf("backup file name (%s) must be inside base path (%s)", fpath, bb)
+	}
+
+	return fpath, nil
+}
+
+func backup(ctx context.Context, mds dtypes.MetadataDS, fpath string) error {
+	fpath, err := backupPath(fpath)
+	if err != nil {
+		return err
+	}
+
+	bds, ok := mds.(*backupds.Datastore)
+	if !ok {
+		return xerrors.Errorf("expected a backup datastore")
 	}
 
 	out, err := os.OpenFile(fpath, os.O_CREATE|os.O_WRONLY, 0644)
@@ -66,3 +80,44 @@ func backup(ctx context.Context, mds dtypes.MetadataDS, fpath string) error {
 
 	return nil
 }
+
+func backupCreate(ctx context.Context, lr repo.LockedRepo, mds dtypes.MetadataDS, fpath string, opts api.BackupOptions) (*api.BackupInfo, error) {
+	if lr == nil {
+		return nil, xerrors.Errorf("node repo not available")
+	}
+
+	fpath, err := backupPath(fpath)
+	if err != nil {
+		return nil, err
+	}
+
+	bds, ok := mds.(*backupds.Datastore)
+	if !ok {
+		return nil, xerrors.Errorf("expected a backup datastore")
+	}
+
+	out, err := os.OpenFile(fpath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, xerrors.Errorf("open %s: %w", fpath, err)
+	}
+
+	m, err := archive.Create(ctx, out, lr, bds, archive.Options{
+		Sections:   opts.Sections,
+		Passphrase: opts.Passphrase,
+	})
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); cerr != nil && err == nil {
+		err = xerrors.Errorf("closing backup file: %w", cerr)
+	}
+	if err != nil {
+		// don't leave partial archives behind
+		if rerr := os.Remove(fpath); rerr != nil {
+			log.Errorw("removing partial backup archive", "file", fpath, "error", rerr)
+		}
+		return nil, xerrors.Errorf("backup error: %w", err)
+	}
+
+	return m.Info(), nil
+}
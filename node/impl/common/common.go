@@ -16,7 +16,10 @@ import (
 	apitypes "github.com/filecoin-project/lotus/api/types"
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/lotuslog"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/repo"
 )
 
 var session = uuid.New()
@@ -29,6 +32,8 @@ type CommonAPI struct {
 	ShutdownChan dtypes.ShutdownChan
 
 	Start dtypes.NodeStartTime
+
+	Repo repo.LockedRepo `optional:"true"`
 }
 
 type jwtPayload struct {
@@ -75,7 +80,30 @@ func (a *CommonAPI) LogList(context.Context) ([]string, error) {
 }
 
 func (a *CommonAPI) LogSetLevel(ctx context.Context, subsystem, level string) error {
-	return logging.SetLogLevel(subsystem, level)
+	if a.Repo == nil {
+		return logging.SetLogLevel(subsystem, level)
+	}
+	return lotuslog.SetLevel(a.Repo.Path(), subsystem, level)
+}
+
+func (a *CommonAPI) LogResetLevels(ctx context.Context) error {
+	if a.Repo == nil {
+		return xerrors.Errorf("node repo not available")
+	}
+
+	c, err := a.Repo.Config()
+	if err != nil {
+		return xerrors.Errorf("getting node config: %w", err)
+	}
+	var levels map[string]string
+	switch cfg := c.(type) {
+	case *config.FullNode:
+		levels = cfg.Logging.SubsystemLevels
+	case *config.StorageMiner:
+		levels = cfg.Logging.SubsystemLevels
+	}
+
+	return lotuslog.ResetLevels(a.Repo.Path(), levels)
 }
 
 func (a *CommonAPI) LogAlerts(ctx context.Context) ([]alerting.Alert, error) {
@@ -98,3 +126,35 @@ func (a *CommonAPI) Closing(ctx context.Context) (<-chan struct{}, error) {
 func (a *CommonAPI) StartTime(context.Context) (time.Time, error) {
 	return time.Time(a.Start), nil
 }
+
+func (a *CommonAPI) RepoLockStatus(context.Context) (api.RepoLockStatus, error) {
+	if a.Repo == nil {
+		return api.RepoLockStatus{}, xerrors.Errorf("node repo not available")
+	}
+
+	fsr, err := repo.NewFS(a.Repo.Path())
+	if err != nil {
+		return api.RepoLockStatus{}, err
+	}
+	st, err := fsr.LockStatus()
+	if err != nil {
+		return api.RepoLockStatus{}, xerrors.Errorf("getting lock status: %w", err)
+	}
+
+	out := api.RepoLockStatus{
+		Path:       st.Path,
+		Locked:     st.Locked,
+		OwnerAlive: st.OwnerAlive,
+		Stale:      st.Stale,
+		Reason:     st.Reason,
+	}
+	if st.Owner != nil {
+		out.Owner = &api.RepoLockOwner{
+			PID:      st.Owner.PID,
+			Hostname: st.Owner.Hostname,
+			RepoType: st.Owner.RepoType,
+			Since:    st.Owner.Since,
+		}
+	}
+	return out, nil
+}
@@ -2,13 +2,21 @@ package impl
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	"github.com/ipfs/go-datastore"
 	logging "github.com/ipfs/go-log/v2"
 	"github.com/libp2p/go-libp2p/core/peer"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/api"
+	apitypes "github.com/filecoin-project/lotus/api/types"
 	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/maintenance"
+	"github.com/filecoin-project/lotus/lib/rpcreplay"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl/client"
 	"github.com/filecoin-project/lotus/node/impl/common"
 	"github.com/filecoin-project/lotus/node/impl/full"
@@ -17,6 +25,7 @@ import (
 	"github.com/filecoin-project/lotus/node/impl/paych"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/node/modules/lp2p"
+	"github.com/filecoin-project/lotus/node/repo"
 )
 
 var log = logging.Logger("node")
@@ -27,6 +36,7 @@ type FullNodeAPI struct {
 	full.ChainAPI
 	client.API
 	full.MpoolAPI
+	full.MpoolScheduleAPI
 	full.GasAPI
 	market.MarketAPI
 	paych.PaychAPI
@@ -36,20 +46,44 @@ type FullNodeAPI struct {
 	full.SyncAPI
 	full.RaftAPI
 	full.EthAPI
+	full.EthSponsorAPI
+	full.MiningAPI
+	full.UserActorAPI
 
 	DS          dtypes.MetadataDS
 	NetworkName dtypes.NetworkName
+
+	// ExecutionLimits are applied to calls by the RPC server
+	ExecutionLimits *config.RPCExecutionLimits `optional:"true"`
+	// SlowCallLog configures the logging of slow calls by the RPC server
+	SlowCallLog *config.SlowCallLogConfig `optional:"true"`
+	// RPCRecorder records the calls served by the RPC server, when enabled
+	RPCRecorder *rpcreplay.Recorder `optional:"true"`
+	// ChainDataREST configures the read-only chain data REST endpoints
+	ChainDataREST *config.ChainDataRESTConfig `optional:"true"`
+	// HealthConfig holds the thresholds of the readiness checks
+	HealthConfig *config.HealthConfig `optional:"true"`
+	// Maintenance gates expensive background activities to maintenance windows
+	Maintenance *maintenance.Scheduler `optional:"true"`
+	// Repo is backed up by BackupCreate
+	Repo repo.LockedRepo `optional:"true"`
 }
 
 func (n *FullNodeAPI) CreateBackup(ctx context.Context, fpath string) error {
 	return backup(ctx, n.DS, fpath)
 }
 
+func (n *FullNodeAPI) BackupCreate(ctx context.Context, fpath string, opts api.BackupOptions) (*api.BackupInfo, error) {
+	return backupCreate(ctx, n.Repo, n.DS, fpath, opts)
+}
+
 func (n *FullNodeAPI) NodeStatus(ctx context.Context, inclChainStatus bool) (status api.NodeStatus, err error) {
+	start := time.Now()
 	curTs, err := n.ChainHead(ctx)
 	if err != nil {
 		return status, err
 	}
+	status.Health.APILatency = time.Since(start)
 
 	status.SyncStatus.Epoch = uint64(curTs.Height())
 	timestamp := time.Unix(int64(curTs.MinTimestamp()), 0)
@@ -88,6 +122,8 @@ func (n *FullNodeAPI) NodeStatus(ctx context.Context, inclChainStatus bool) (sta
 		}
 	}
 
+	n.nodeHealth(ctx, curTs, &status)
+
 	if inclChainStatus && status.SyncStatus.Epoch > uint64(build.Finality) {
 		blockCnt := 0
 		ts := curTs
@@ -119,6 +155,122 @@ func (n *FullNodeAPI) NodeStatus(ctx context.Context, inclChainStatus bool) (sta
 	return status, nil
 }
 
+var healthCheckKey = datastore.NewKey("/health")
+
+// nodeHealth fills the health of the status, with the sync status and API
+// latency already set.
+func (n *FullNodeAPI) nodeHealth(ctx context.Context, head *types.TipSet, status *api.NodeStatus) {
+	h := &status.Health
+	h.SyncLag = status.SyncStatus.Behind
+
+	peers, err := n.NetPeers(ctx)
+	if err != nil {
+		h.PeersError = err.Error()
+	}
+	h.Peers = len(peers)
+
+	dctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	if _, err := n.DS.Has(dctx, healthCheckKey); err != nil {
+		h.DatastoreError = err.Error()
+	}
+
+	h.EventsIndexLag = -1
+	if ee, ok := n.EthEventAPI.(*full.EthEvent); ok && ee.EventFilterManager != nil {
+		h.EventsIndexLag = int64(head.Height() - ee.EventFilterManager.ProcessedHeight())
+	}
+
+	cfg := config.DefaultFullNode().Health
+	if n.HealthConfig != nil {
+		cfg = *n.HealthConfig
+	}
+	h.Failing = healthFailures(*h, cfg)
+	h.Ready = len(h.Failing) == 0
+}
+
+func healthFailures(h api.NodeHealth, cfg config.HealthConfig) []string {
+	var failing []string
+	if h.SyncLag > cfg.MaxSyncLag {
+		failing = append(failing, fmt.Sprintf("chain head is %d epochs behind, more than %d", h.SyncLag, cfg.MaxSyncLag))
+	}
+	if h.PeersError != "" {
+		failing = append(failing, fmt.Sprintf("listing peers: %s", h.PeersError))
+	} else if h.Peers < cfg.MinPeers {
+		failing = append(failing, fmt.Sprintf("%d peers connected, less than %d", h.Peers, cfg.MinPeers))
+	}
+	if h.APILatency > time.Duration(cfg.MaxAPILatency) {
+		failing = append(failing, fmt.Sprintf("loading the chain head took %s, more than %s", h.APILatency, time.Duration(cfg.MaxAPILatency)))
+	}
+	if h.DatastoreError != "" {
+		failing = append(failing, fmt.Sprintf("datastore: %s", h.DatastoreError))
+	}
+	if cfg.MaxEventsIndexLag > 0 && h.EventsIndexLag > cfg.MaxEventsIndexLag {
+		failing = append(failing, fmt.Sprintf("events are processed %d epochs behind, more than %d", h.EventsIndexLag, cfg.MaxEventsIndexLag))
+	}
+	return failing
+}
+
+func (n *FullNodeAPI) NodeAPISpec(ctx context.Context) (*api.APISpec, error) {
+	return api.FullAPISpec(n.features()), nil
+}
+
+func (n *FullNodeAPI) NodeMaintenanceStatus(ctx context.Context) (*api.MaintenanceStatus, error) {
+	if n.Maintenance == nil {
+		return nil, xerrors.Errorf("maintenance windows are not supported by this node")
+	}
+	return n.Maintenance.Status(), nil
+}
+
+func (n *FullNodeAPI) NodeMaintenanceOverride(ctx context.Context, override *api.MaintenanceOverride) error {
+	if n.Maintenance == nil {
+		return xerrors.Errorf("maintenance windows are not supported by this node")
+	}
+	return n.Maintenance.SetOverride(override)
+}
+
+// Discover returns the OpenRPC document of the API, with the Ethereum aliases
+// of the methods, and the methods needing a disabled feature marked with
+// "x-enabled": false.
+func (n *FullNodeAPI) Discover(ctx context.Context) (apitypes.OpenRPCDocument, error) {
+	doc := build.OpenRPCDiscoverJSON_Full()
+	annotateOpenRPC(doc, api.FullAPISpec(n.features()))
+	return doc, nil
+}
+
+func (n *FullNodeAPI) features() map[string]bool {
+	features := n.EthFeatures()
+	features[api.FeatureUserActors] = n.UserActorAPI.Config != nil && n.UserActorAPI.Config.Enable
+	features[api.FeatureIndexQuery] = n.ChainAPI.IndexQuery != nil
+	return features
+}
+
+func annotateOpenRPC(doc apitypes.OpenRPCDocument, spec *api.APISpec) {
+	byName := make(map[string]api.APIMethod, len(spec.Methods))
+	for _, m := range spec.Methods {
+		byName[m.Name] = m
+	}
+
+	methods, _ := doc["methods"].([]interface{})
+	for _, mi := range methods {
+		m, ok := mi.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _ := m["name"].(string)
+		sm, ok := byName[name]
+		if !ok {
+			continue
+		}
+		if len(sm.Aliases) > 0 {
+			m["x-aliases"] = sm.Aliases
+		}
+		if sm.Feature != "" {
+			m["x-feature"] = sm.Feature
+			m["x-enabled"] = sm.Enabled
+		}
+	}
+}
+
 func (n *FullNodeAPI) RaftState(ctx context.Context) (*api.RaftStateData, error) {
 	return n.RaftAPI.GetRaftState(ctx)
 }
@@ -0,0 +1,194 @@
+package full
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+	init11 "github.com/filecoin-project/go-state-types/builtin/v11/init"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors"
+	"github.com/filecoin-project/lotus/chain/actors/adt"
+	init_ "github.com/filecoin-project/lotus/chain/actors/builtin/init"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+// UserActorAPI installs and instantiates user WASM actors. It's experimental,
+// and only enabled when UserActors.Enable is set in the config.
+type UserActorAPI struct {
+	fx.In
+
+	StateAPI StateAPI
+	GasAPI   GasAPI
+
+	Config *config.UserActorsConfig `optional:"true"`
+}
+
+func (a *UserActorAPI) checkEnabled() error {
+	if a.Config == nil || !a.Config.Enable {
+		return xerrors.Errorf("user actors are disabled, set UserActors.Enable in the config to use the experimental user actor APIs")
+	}
+	return nil
+}
+
+// installedActors returns the user actors installed at the tipset, after
+// checking user actors are enabled and supported by the network.
+func (a *UserActorAPI) installedActors(ctx context.Context, tsk types.TipSetKey) ([]cid.Cid, error) {
+	if err := a.checkEnabled(); err != nil {
+		return nil, err
+	}
+
+	nv, err := a.StateAPI.StateNetworkVersion(ctx, tsk)
+	if err != nil {
+		return nil, err
+	}
+	if nv < network.Version18 {
+		return nil, xerrors.Errorf("user actors require network version 18 or later, the network is at version %d", nv)
+	}
+
+	act, err := a.StateAPI.StateGetActor(ctx, init_.Address, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading init actor: %w", err)
+	}
+	store := adt.WrapStore(ctx, a.StateAPI.Chain.ActorStore(ctx))
+	installed, supported, err := init_.LoadInstalledActors(ctx, store, act)
+	if err != nil {
+		return nil, err
+	}
+	if !supported {
+		return nil, xerrors.Errorf("the init actor of the network doesn't support installing user actors")
+	}
+	return installed, nil
+}
+
+func (a *UserActorAPI) validateCode(code []byte) (cid.Cid, error) {
+	maxSize := 0
+	if a.Config != nil {
+		maxSize = a.Config.MaxCodeSize
+	}
+	if err := init_.ValidateCode(code, maxSize); err != nil {
+		return cid.Undef, err
+	}
+	return init_.CodeCid(code)
+}
+
+func installMessage(from address.Address, code []byte) (*types.Message, error) {
+	params, err := actors.SerializeParams(&init_.InstallParams{Code: code})
+	if err != nil {
+		return nil, xerrors.Errorf("serializing install params: %w", err)
+	}
+	return &types.Message{
+		To:     init_.Address,
+		From:   from,
+		Value:  big.Zero(),
+		Method: init_.MethodInstallCode,
+		Params: params,
+	}, nil
+}
+
+func (a *UserActorAPI) ActorValidateCode(ctx context.Context, code []byte) (cid.Cid, error) {
+	if err := a.checkEnabled(); err != nil {
+		return cid.Undef, err
+	}
+	return a.validateCode(code)
+}
+
+func (a *UserActorAPI) ActorEstimateInstall(ctx context.Context, from address.Address, code []byte) (*api.ActorInstallEstimate, error) {
+	installed, err := a.installedActors(ctx, types.EmptyTSK)
+	if err != nil {
+		return nil, err
+	}
+	codeCid, err := a.validateCode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	msg, err := installMessage(from, code)
+	if err != nil {
+		return nil, err
+	}
+	msg, err = a.GasAPI.GasEstimateMessageGas(ctx, msg, nil, types.EmptyTSK)
+	if err != nil {
+		return nil, xerrors.Errorf("estimating install gas: %w", err)
+	}
+
+	est := &api.ActorInstallEstimate{
+		CodeCid:    codeCid,
+		CodeSize:   len(code),
+		GasLimit:   msg.GasLimit,
+		GasFeeCap:  msg.GasFeeCap,
+		GasPremium: msg.GasPremium,
+		MaxCost:    big.Mul(msg.GasFeeCap, big.NewInt(msg.GasLimit)),
+	}
+	for _, c := range installed {
+		if c == codeCid {
+			est.AlreadyInstalled = true
+			break
+		}
+	}
+	return est, nil
+}
+
+func (a *UserActorAPI) ActorInstall(ctx context.Context, from address.Address, code []byte) (*api.MessagePrototype, error) {
+	if _, err := a.installedActors(ctx, types.EmptyTSK); err != nil {
+		return nil, err
+	}
+	if _, err := a.validateCode(code); err != nil {
+		return nil, err
+	}
+
+	msg, err := installMessage(from, code)
+	if err != nil {
+		return nil, err
+	}
+	return &api.MessagePrototype{
+		Message:    *msg,
+		ValidNonce: false,
+	}, nil
+}
+
+func (a *UserActorAPI) ActorCreate(ctx context.Context, from address.Address, code cid.Cid, params []byte, value types.BigInt) (*api.MessagePrototype, error) {
+	installed, err := a.installedActors(ctx, types.EmptyTSK)
+	if err != nil {
+		return nil, err
+	}
+	found := false
+	for _, c := range installed {
+		if c == code {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return nil, xerrors.Errorf("actor code %s is not installed", code)
+	}
+
+	enc, err := actors.SerializeParams(&init11.ExecParams{
+		CodeCID:           code,
+		ConstructorParams: params,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("serializing exec params: %w", err)
+	}
+	return &api.MessagePrototype{
+		Message: types.Message{
+			To:     init_.Address,
+			From:   from,
+			Value:  value,
+			Method: init_.Methods.Exec,
+			Params: enc,
+		},
+		ValidNonce: false,
+	}, nil
+}
+
+func (a *UserActorAPI) StateInstalledActors(ctx context.Context, tsk types.TipSetKey) ([]cid.Cid, error) {
+	return a.installedActors(ctx, tsk)
+}
@@ -0,0 +1,194 @@
+package full
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// maxWatchedBalances limits the addresses of a single StateWatchBalances
+// subscription, as all of them are looked up for every tipset
+const maxWatchedBalances = 1000
+
+func (a *StateAPI) StateWatchBalances(ctx context.Context, addrs []address.Address) (<-chan []*api.BalanceChange, error) {
+	if len(addrs) == 0 {
+		return nil, xerrors.Errorf("no addresses to watch")
+	}
+	if len(addrs) > maxWatchedBalances {
+		return nil, xerrors.Errorf("too many addresses to watch: %d, the maximum is %d", len(addrs), maxWatchedBalances)
+	}
+
+	hcs := a.Chain.SubHeadChanges(ctx)
+	out := make(chan []*api.BalanceChange, 16)
+
+	go func() {
+		defer close(out)
+
+		for changes := range hcs {
+			var batch []*api.BalanceChange
+			for _, hc := range changes {
+				if hc.Type == store.HCCurrent {
+					continue
+				}
+
+				bcs, err := a.tipsetBalanceChanges(ctx, hc.Type, hc.Val, addrs)
+				if err != nil {
+					// tell the client about the gap instead of skipping the tipset
+					log.Warnw("computing balance changes", "tipset", hc.Val.Key(), "error", err)
+					bcs = []*api.BalanceChange{{
+						Type:   hc.Type,
+						TipSet: hc.Val.Parents(),
+						Error:  err.Error(),
+					}}
+				}
+				batch = append(batch, bcs...)
+			}
+			if len(batch) == 0 {
+				continue
+			}
+
+			select {
+			case out <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// tipsetBalanceChanges computes balance changes caused by executing the
+// parent of ts, whose resulting state is the parent state of ts
+func (a *StateAPI) tipsetBalanceChanges(ctx context.Context, typ string, ts *types.TipSet, addrs []address.Address) ([]*api.BalanceChange, error) {
+	if ts.Height() == 0 {
+		return nil, nil
+	}
+
+	executed, err := a.Chain.GetTipSetFromKey(ctx, ts.Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset: %w", err)
+	}
+	newSt, err := a.StateManager.ParentState(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state: %w", err)
+	}
+	oldSt, err := a.StateManager.ParentState(executed)
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent state: %w", err)
+	}
+
+	return balanceChanges(typ, executed, oldSt, newSt, func() ([]types.ChainMsg, error) {
+		return a.Chain.MessagesForTipset(ctx, executed)
+	}, addrs)
+}
+
+// balanceChanges compares the balances of addrs before (oldSt) and after
+// (newSt) executing a tipset. Messages are only loaded when a balance changed.
+func balanceChanges(typ string, executed *types.TipSet, oldSt, newSt *state.StateTree, loadMsgs func() ([]types.ChainMsg, error), addrs []address.Address) ([]*api.BalanceChange, error) {
+	var msgs []types.ChainMsg
+	var msgsLoaded bool
+	resolved := map[address.Address]address.Address{}
+	resolve := func(addr address.Address) (address.Address, error) {
+		if addr.Protocol() == address.ID {
+			return addr, nil
+		}
+		id, ok := resolved[addr]
+		if !ok {
+			var err error
+			id, err = newSt.LookupID(addr)
+			if xerrors.Is(err, types.ErrActorNotFound) {
+				id, err = address.Undef, nil
+			}
+			if err != nil {
+				return address.Undef, xerrors.Errorf("looking up id of %s: %w", addr, err)
+			}
+			resolved[addr] = id
+		}
+		return id, nil
+	}
+
+	var out []*api.BalanceChange
+	for _, addr := range addrs {
+		oldBal, err := actorBalance(oldSt, addr)
+		if err != nil {
+			return nil, xerrors.Errorf("loading balance of %s before execution: %w", addr, err)
+		}
+		newBal, err := actorBalance(newSt, addr)
+		if err != nil {
+			return nil, xerrors.Errorf("loading balance of %s after execution: %w", addr, err)
+		}
+		if oldBal.Equals(newBal) {
+			continue
+		}
+
+		if !msgsLoaded {
+			msgs, err = loadMsgs()
+			if err != nil {
+				return nil, xerrors.Errorf("loading tipset messages: %w", err)
+			}
+			msgsLoaded = true
+		}
+
+		id, err := resolve(addr)
+		if err != nil {
+			return nil, err
+		}
+
+		bc := &api.BalanceChange{
+			Type:       typ,
+			TipSet:     executed.Key(),
+			Height:     executed.Height(),
+			Address:    addr,
+			ID:         id,
+			OldBalance: oldBal,
+			NewBalance: newBal,
+		}
+		if typ == store.HCRevert {
+			bc.OldBalance, bc.NewBalance = newBal, oldBal
+		}
+		bc.Delta = big.Sub(bc.NewBalance, bc.OldBalance)
+
+		for _, m := range msgs {
+			vm := m.VMMessage()
+			match := vm.From == addr || vm.To == addr
+			if !match && bc.ID != address.Undef {
+				from, err := resolve(vm.From)
+				if err != nil {
+					return nil, err
+				}
+				to, err := resolve(vm.To)
+				if err != nil {
+					return nil, err
+				}
+				match = from == bc.ID || to == bc.ID
+			}
+			if match {
+				bc.Messages = append(bc.Messages, m.Cid())
+			}
+		}
+
+		out = append(out, bc)
+	}
+
+	return out, nil
+}
+
+func actorBalance(st *state.StateTree, addr address.Address) (types.BigInt, error) {
+	act, err := st.GetActor(addr)
+	if xerrors.Is(err, types.ErrActorNotFound) {
+		return big.Zero(), nil
+	}
+	if err != nil {
+		return types.BigInt{}, err
+	}
+	return act.Balance, nil
+}
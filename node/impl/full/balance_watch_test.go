@@ -0,0 +1,78 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestBalanceChanges(t *testing.T) {
+	cst := cbor.NewMemCborStore()
+	mkState := func(balances map[address.Address]int64) *state.StateTree {
+		st, err := state.NewStateTree(cst, types.StateTreeVersion5)
+		require.NoError(t, err)
+		for a, b := range balances {
+			require.NoError(t, st.SetActor(a, &types.Actor{Balance: types.NewInt(uint64(b))}))
+		}
+		return st
+	}
+
+	watched := mustIDAddr(t, 1000)
+	unchanged := mustIDAddr(t, 1001)
+	other := mustIDAddr(t, 1002)
+	missing := mustIDAddr(t, 1003)
+
+	oldSt := mkState(map[address.Address]int64{watched: 100, unchanged: 5, other: 50})
+	newSt := mkState(map[address.Address]int64{watched: 130, unchanged: 5, other: 20})
+
+	deposit := mock.UnsignedMessage(other, watched, 0)
+	unrelated := mock.UnsignedMessage(other, unchanged, 1)
+	msgs := []types.ChainMsg{deposit, unrelated}
+
+	var loaded int
+	load := func() ([]types.ChainMsg, error) {
+		loaded++
+		return msgs, nil
+	}
+
+	ts := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	addrs := []address.Address{watched, unchanged, missing}
+
+	bcs, err := balanceChanges(store.HCApply, ts, oldSt, newSt, load, addrs)
+	require.NoError(t, err)
+	require.Len(t, bcs, 1)
+	require.Equal(t, 1, loaded)
+
+	bc := bcs[0]
+	require.Equal(t, watched, bc.Address)
+	require.Equal(t, watched, bc.ID)
+	require.Equal(t, ts.Key(), bc.TipSet)
+	require.Equal(t, types.NewInt(100), bc.OldBalance)
+	require.Equal(t, types.NewInt(130), bc.NewBalance)
+	require.Equal(t, types.NewInt(30), bc.Delta)
+	require.Equal(t, []cid.Cid{deposit.Cid()}, bc.Messages)
+
+	// reverting the tipset undoes the deposit
+	bcs, err = balanceChanges(store.HCRevert, ts, oldSt, newSt, load, addrs)
+	require.NoError(t, err)
+	require.Len(t, bcs, 1)
+	require.Equal(t, types.NewInt(130), bcs[0].OldBalance)
+	require.Equal(t, big.NewInt(-30), bcs[0].Delta)
+
+	// messages aren't loaded when no balance changed
+	bcs, err = balanceChanges(store.HCApply, ts, oldSt, oldSt, load, addrs)
+	require.NoError(t, err)
+	require.Empty(t, bcs)
+	require.Equal(t, 2, loaded)
+}
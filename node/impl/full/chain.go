@@ -4,6 +4,7 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -35,12 +36,14 @@ import (
 
 	"github.com/filecoin-project/lotus/api"
 	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/chain/index"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/vm"
 	"github.com/filecoin-project/lotus/lib/oldpath"
 	"github.com/filecoin-project/lotus/lib/oldpath/oldresolver"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/node/repo"
 )
@@ -49,6 +52,7 @@ var log = logging.Logger("fullnode")
 
 type ChainModuleAPI interface {
 	ChainNotify(context.Context) (<-chan []*api.HeadChange, error)
+	ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*api.HeadChange, error)
 	ChainGetBlockMessages(context.Context, cid.Cid) (*api.BlockMessages, error)
 	ChainHasObj(context.Context, cid.Cid) (bool, error)
 	ChainHead(context.Context) (*types.TipSet, error)
@@ -96,12 +100,24 @@ type ChainAPI struct {
 	BaseBlockstore dtypes.BaseBlockstore
 
 	Repo repo.LockedRepo
+
+	// Subscriptions bounds how far below the head ChainNotifyResume resumes
+	Subscriptions *config.SubscriptionsConfig `optional:"true"`
+
+	IndexQuery *index.QueryEngine `optional:"true"`
 }
 
 func (m *ChainModule) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange, error) {
 	return m.Chain.SubHeadChanges(ctx), nil
 }
 
+func (m *ChainModule) ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*api.HeadChange, error) {
+	if depth < 0 {
+		return nil, xerrors.Errorf("confidence depth must not be negative")
+	}
+	return m.Chain.SubHeadChangesAtConfidence(ctx, depth), nil
+}
+
 func (m *ChainModule) ChainHead(context.Context) (*types.TipSet, error) {
 	return m.Chain.GetHeaviestTipSet(), nil
 }
@@ -114,6 +130,28 @@ func (m *ChainModule) ChainGetTipSet(ctx context.Context, key types.TipSetKey) (
 	return m.Chain.LoadTipSet(ctx, key)
 }
 
+func (a *ChainAPI) ChainGetTipSetProof(ctx context.Context, tsk types.TipSetKey) (*api.TipSetProof, error) {
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	headers := make([][]byte, len(ts.Blocks()))
+	for i, blk := range ts.Blocks() {
+		headers[i], err = blk.Serialize()
+		if err != nil {
+			return nil, xerrors.Errorf("serializing block header %s: %w", blk.Cid(), err)
+		}
+	}
+
+	return &api.TipSetProof{
+		Key:             ts.Key(),
+		Height:          ts.Height(),
+		ParentStateRoot: ts.ParentState(),
+		Headers:         headers,
+	}, nil
+}
+
 func (m *ChainModule) ChainGetPath(ctx context.Context, from, to types.TipSetKey) ([]*api.HeadChange, error) {
 	return m.Chain.GetPath(ctx, from, to)
 }
@@ -238,6 +276,9 @@ func (m *ChainModule) ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpo
 	if err != nil {
 		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
+	if err := checkTipSetHeight(h, ts); err != nil {
+		return nil, err
+	}
 	return m.Chain.GetTipsetByHeight(ctx, h, ts, true)
 }
 
@@ -246,9 +287,24 @@ func (m *ChainModule) ChainGetTipSetAfterHeight(ctx context.Context, h abi.Chain
 	if err != nil {
 		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
+	if err := checkTipSetHeight(h, ts); err != nil {
+		return nil, err
+	}
 	return m.Chain.GetTipsetByHeight(ctx, h, ts, false)
 }
 
+// checkTipSetHeight returns an api.ErrInvalidParams error if a tipset at
+// height h can't be looked up from ts.
+func checkTipSetHeight(h abi.ChainEpoch, ts *types.TipSet) error {
+	if h < 0 {
+		return api.NewErrInvalidParams("invalid_height", "height %d is negative", h)
+	}
+	if h > ts.Height() {
+		return api.NewErrInvalidParams("invalid_height", "looking for tipset with height %d greater than start point %d", h, ts.Height())
+	}
+	return nil
+}
+
 func (m *ChainModule) ChainReadObj(ctx context.Context, obj cid.Cid) ([]byte, error) {
 	blk, err := m.ExposedBlockstore.Get(ctx, obj)
 	if err != nil {
@@ -638,13 +694,23 @@ func (a *ChainAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipo
 	if err != nil {
 		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
 	}
-	r, w := io.Pipe()
-	out := make(chan []byte)
-	go func() {
+
+	return exportStream(ctx, "chain export", func(w io.Writer) error {
 		bw := bufio.NewWriterSize(w, 1<<20)
 
 		err := a.Chain.Export(ctx, ts, nroots, skipoldmsgs, bw)
-		bw.Flush()            //nolint:errcheck // it is a write to a pipe
+		bw.Flush() //nolint:errcheck // it is a write to a pipe
+		return err
+	}), nil
+}
+
+// exportStream streams what the write function writes in chunks, ending with
+// an empty chunk when it completes successfully.
+func exportStream(ctx context.Context, name string, write func(w io.Writer) error) <-chan []byte {
+	r, w := io.Pipe()
+	out := make(chan []byte)
+	go func() {
+		err := write(w)
 		w.CloseWithError(err) //nolint:errcheck // it is a pipe
 	}()
 
@@ -654,7 +720,7 @@ func (a *ChainAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipo
 			buf := make([]byte, 1<<20)
 			n, err := r.Read(buf)
 			if err != nil && err != io.EOF {
-				log.Errorf("chain export pipe read failed: %s", err)
+				log.Errorf("%s pipe read failed: %s", name, err)
 				return
 			}
 			if n > 0 {
@@ -679,6 +745,150 @@ func (a *ChainAPI) ChainExport(ctx context.Context, nroots abi.ChainEpoch, skipo
 		}
 	}()
 
+	return out
+}
+
+// chainExportResumeToken captures everything needed to deterministically
+// re-run an export and skip to the position of the last received chunk.
+type chainExportResumeToken struct {
+	Head        types.TipSetKey
+	Tail        types.TipSetKey
+	NRoots      abi.ChainEpoch
+	SkipOldMsgs bool
+	ChunkSize   int
+	Offset      uint64
+}
+
+const chainExportMaxChunkSize = 16 << 20
+
+func (t chainExportResumeToken) encode() (string, error) {
+	b, err := json.Marshal(t)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func decodeChainExportResumeToken(s string) (chainExportResumeToken, error) {
+	var t chainExportResumeToken
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return t, xerrors.Errorf("decoding resume token: %w", err)
+	}
+	if err := json.Unmarshal(b, &t); err != nil {
+		return t, xerrors.Errorf("unmarshaling resume token: %w", err)
+	}
+	return t, nil
+}
+
+func (a *ChainAPI) ChainExportStream(ctx context.Context, tsk types.TipSetKey, params api.ChainExportStreamParams) (<-chan api.ChainExportChunk, error) {
+	rt := chainExportResumeToken{
+		Head:        tsk,
+		Tail:        params.Tail,
+		NRoots:      params.NRoots,
+		SkipOldMsgs: params.SkipOldMsgs,
+		ChunkSize:   params.ChunkSize,
+	}
+	if params.ResumeToken != "" {
+		var err error
+		if rt, err = decodeChainExportResumeToken(params.ResumeToken); err != nil {
+			return nil, err
+		}
+	}
+	// the chunk size may come from a client's resume token, bound the allocations it makes
+	switch {
+	case rt.ChunkSize <= 0:
+		rt.ChunkSize = 1 << 20
+	case rt.ChunkSize > chainExportMaxChunkSize:
+		rt.ChunkSize = chainExportMaxChunkSize
+	}
+
+	ts, err := a.Chain.GetTipSetFromKey(ctx, rt.Head)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", rt.Head, err)
+	}
+	// pin the head, so that resuming an export of the current head exports the same data
+	rt.Head = ts.Key()
+
+	var tailTs *types.TipSet
+	if !rt.Tail.IsEmpty() {
+		tailTs, err = a.Chain.LoadTipSet(ctx, rt.Tail)
+		if err != nil {
+			return nil, xerrors.Errorf("loading tail tipset %s: %w", rt.Tail, err)
+		}
+		if ts.Height() < tailTs.Height() {
+			return nil, xerrors.Errorf("height of head-tipset (%d) must be greater or equal to the height of the tail-tipset (%d)", ts.Height(), tailTs.Height())
+		}
+	}
+
+	r, w := io.Pipe()
+	go func() {
+		bw := bufio.NewWriterSize(w, 1<<20)
+
+		var err error
+		if tailTs != nil {
+			err = a.Chain.ExportEpochRange(ctx, ts, tailTs, bw)
+		} else {
+			err = a.Chain.Export(ctx, ts, rt.NRoots, rt.SkipOldMsgs, bw)
+		}
+		if err == nil {
+			err = bw.Flush()
+		}
+		w.CloseWithError(err) //nolint:errcheck // it is a pipe
+	}()
+
+	out := make(chan api.ChainExportChunk)
+	go func() {
+		defer close(out)
+		// unblocks the exporter if we stop reading early
+		defer r.Close() //nolint:errcheck // it is a pipe
+
+		send := func(c api.ChainExportChunk) bool {
+			select {
+			case out <- c:
+				return true
+			case <-ctx.Done():
+				log.Warnf("export writer failed: %s", ctx.Err())
+				return false
+			}
+		}
+
+		if rt.Offset > 0 {
+			if _, err := io.CopyN(io.Discard, r, int64(rt.Offset)); err != nil {
+				send(api.ChainExportChunk{Offset: rt.Offset, Error: fmt.Sprintf("seeking to resume offset: %s", err)})
+				return
+			}
+		}
+
+		for {
+			buf := make([]byte, rt.ChunkSize)
+			n, err := io.ReadFull(r, buf)
+
+			chunk := api.ChainExportChunk{Data: buf[:n], Offset: rt.Offset}
+			rt.Offset += uint64(n)
+
+			switch err {
+			case nil:
+			case io.EOF, io.ErrUnexpectedEOF:
+				chunk.Done = true
+			default:
+				// partial data can't be resumed from, drop it
+				chunk.Data = nil
+				chunk.Error = err.Error()
+			}
+
+			if chunk.Error == "" {
+				if chunk.ResumeToken, err = rt.encode(); err != nil {
+					chunk.Error = xerrors.Errorf("encoding resume token: %w", err).Error()
+				}
+			}
+
+			if !send(chunk) || chunk.Done || chunk.Error != "" {
+				return
+			}
+		}
+	}()
+
 	return out, nil
 }
 
@@ -727,6 +937,30 @@ func (a *ChainAPI) ChainGetEvents(ctx context.Context, root cid.Cid) ([]types.Ev
 	return ret, err
 }
 
+func (a *ChainAPI) ChainIndexQuery(ctx context.Context, idx string, query string) (*api.IndexQueryResult, error) {
+	if a.IndexQuery == nil {
+		return nil, api.NewErrMethodNotSupported("index_query_disabled", "index queries are disabled, enable them with Index.EnableSQLQuery")
+	}
+
+	res, err := a.IndexQuery.Query(ctx, idx, query)
+	switch {
+	case xerrors.Is(err, index.ErrUnknownQueryIndex):
+		nf := api.NewErrNotFound("unknown_index", false, "%w", err)
+		nf.Details = map[string]interface{}{"indexes": a.IndexQuery.Indexes()}
+		return nil, nf
+	case xerrors.Is(err, index.ErrInvalidQuery):
+		return nil, api.NewErrInvalidParams("invalid_query", "%w", err)
+	case err != nil:
+		return nil, err
+	}
+
+	return &api.IndexQueryResult{
+		Columns:   res.Columns,
+		Rows:      res.Rows,
+		Truncated: res.Truncated,
+	}, nil
+}
+
 func (a *ChainAPI) ChainPrune(ctx context.Context, opts api.PruneOpts) error {
 	pruner, ok := a.BaseBlockstore.(interface {
 		PruneChain(opts api.PruneOpts) error
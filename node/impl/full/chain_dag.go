@@ -0,0 +1,345 @@
+package full
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-car"
+	carutil "github.com/ipld/go-car/util"
+	"github.com/ipld/go-ipld-prime/datamodel"
+	basicnode "github.com/ipld/go-ipld-prime/node/basic"
+	"github.com/ipld/go-ipld-prime/traversal"
+	"github.com/ipld/go-ipld-prime/traversal/selector"
+	"github.com/ipld/go-ipld-prime/traversal/selector/builder"
+	selectorparse "github.com/ipld/go-ipld-prime/traversal/selector/parse"
+	textselector "github.com/ipld/go-ipld-selector-text-lite"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/markets/utils"
+)
+
+// dagWalker walks graphs of IPLD objects in the chain blockstore breadth
+// first, within the limits of the walk options.
+type dagWalker struct {
+	dag  ipld.DAGService
+	opts api.DagWalkOpts
+	sel  datamodel.Node
+}
+
+func (a *ChainAPI) dagWalker(opts api.DagWalkOpts) (*dagWalker, error) {
+	if opts.MaxDepth < 0 || opts.MaxBlocks < 0 {
+		return nil, xerrors.Errorf("walk limits can't be negative")
+	}
+
+	bs := a.ExposedBlockstore
+	w := &dagWalker{
+		dag:  merkledag.NewDAGService(blockservice.New(bs, offline.Exchange(bs))),
+		opts: opts,
+	}
+	if opts.Selector != nil {
+		sel, err := walkSelector(*opts.Selector)
+		if err != nil {
+			return nil, err
+		}
+		w.sel = sel
+	}
+	return w, nil
+}
+
+// walkSelector parses a JSON selector, or a path selecting the whole graph
+// under the object at the path.
+func walkSelector(s api.Selector) (datamodel.Node, error) {
+	if strings.HasPrefix(string(s), "{") {
+		sel, err := selectorparse.ParseJSONSelector(string(s))
+		if err != nil {
+			return nil, xerrors.Errorf("parsing json selector: %w", err)
+		}
+		return sel, nil
+	}
+
+	ssb := builder.NewSelectorSpecBuilder(basicnode.Prototype.Any)
+	spec, err := textselector.SelectorSpecFromPath(textselector.Expression(s), false,
+		ssb.ExploreRecursive(selector.RecursionLimitNone(), ssb.ExploreAll(ssb.ExploreRecursiveEdge())))
+	if err != nil {
+		return nil, xerrors.Errorf("parsing path selector: %w", err)
+	}
+	return spec.Node(), nil
+}
+
+// errWalkLimit stops the traversal of a selector at the block limit.
+var errWalkLimit = xerrors.New("walk limit reached")
+
+// selected returns the blocks the selector of the walk traverses from the
+// root, or nil when the walk has no selector.
+func (w *dagWalker) selected(ctx context.Context, root cid.Cid) (*cid.Set, error) {
+	if w.sel == nil {
+		return nil, nil
+	}
+
+	out := cid.NewSet()
+	err := utils.TraverseDag(ctx, w.dag, root, w.sel, func(nd ipld.Node) error {
+		out.Add(nd.Cid())
+		if w.opts.MaxBlocks > 0 && out.Len() > w.opts.MaxBlocks {
+			return errWalkLimit
+		}
+		return nil
+	}, func(traversal.Progress, datamodel.Node, traversal.VisitReason) error {
+		return nil
+	})
+	if err != nil && !xerrors.Is(err, errWalkLimit) {
+		return nil, xerrors.Errorf("traversing selector (blocks it traverses can't be missing): %w", err)
+	}
+	return out, nil
+}
+
+// dagVisitFunc is called with each block reached by a walk, and the depth at
+// which it was first reached. The node is nil when the block is missing from
+// the blockstore. Its links are followed when it returns true.
+type dagVisitFunc func(c cid.Cid, nd ipld.Node, depth int) (bool, error)
+
+// errStopWalk stops a walk early from a visit function.
+var errStopWalk = xerrors.New("stop walk")
+
+// walk visits each block reachable from the root once, and returns whether
+// the limits stopped the walk before its end.
+func (w *dagWalker) walk(ctx context.Context, root cid.Cid, visit dagVisitFunc) (bool, error) {
+	selected, err := w.selected(ctx, root)
+	if err != nil {
+		return false, err
+	}
+
+	seen := cid.NewSet()
+	seen.Add(root)
+
+	level := []cid.Cid{root}
+	var blocks int
+	var truncated bool
+	for depth := 0; len(level) > 0; depth++ {
+		var next []cid.Cid
+		for _, c := range level {
+			if w.opts.MaxBlocks > 0 && blocks >= w.opts.MaxBlocks {
+				return true, nil
+			}
+			blocks++
+
+			if err := ctx.Err(); err != nil {
+				return false, err
+			}
+
+			nd, err := w.dag.Get(ctx, c)
+			if err != nil && !ipld.IsNotFound(err) {
+				return false, xerrors.Errorf("loading %s: %w", c, err)
+			}
+
+			follow, err := visit(c, nd, depth)
+			if err == errStopWalk {
+				return false, nil
+			}
+			if err != nil {
+				return false, err
+			}
+			if !follow || nd == nil {
+				continue
+			}
+
+			for _, l := range nd.Links() {
+				// sector commitments aren't blocks
+				if l.Cid.Prefix().Codec == cid.FilCommitmentSealed || l.Cid.Prefix().Codec == cid.FilCommitmentUnsealed {
+					continue
+				}
+				if selected != nil && !selected.Has(l.Cid) {
+					continue
+				}
+				if !seen.Visit(l.Cid) {
+					continue
+				}
+				if w.opts.MaxDepth > 0 && depth >= w.opts.MaxDepth {
+					truncated = true
+					continue
+				}
+				next = append(next, l.Cid)
+			}
+		}
+		level = next
+	}
+	return truncated, nil
+}
+
+// resolvePath resolves the path of an object, as accepted by ChainGetNode,
+// to its CID.
+func (a *ChainAPI) resolvePath(ctx context.Context, p string) (cid.Cid, error) {
+	nd, err := a.ChainGetNode(ctx, p)
+	if err != nil {
+		return cid.Undef, xerrors.Errorf("resolving %s: %w", p, err)
+	}
+	return nd.Cid, nil
+}
+
+func codecName(c cid.Cid) string {
+	switch c.Prefix().Codec {
+	case cid.DagCBOR:
+		return "dag-cbor"
+	case cid.Raw:
+		return "raw"
+	case cid.DagProtobuf:
+		return "dag-pb"
+	case cid.DagJSON:
+		return "dag-json"
+	default:
+		return fmt.Sprintf("0x%x", c.Prefix().Codec)
+	}
+}
+
+func (a *ChainAPI) ChainWalkObj(ctx context.Context, p string, opts api.DagWalkOpts) (*api.DagWalk, error) {
+	w, err := a.dagWalker(opts)
+	if err != nil {
+		return nil, err
+	}
+	root, err := a.resolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	out := &api.DagWalk{
+		Root:   root,
+		Codecs: map[string]api.ObjStat{},
+	}
+	out.Truncated, err = w.walk(ctx, root, func(c cid.Cid, nd ipld.Node, depth int) (bool, error) {
+		if nd == nil {
+			out.Missing++
+			return false, nil
+		}
+
+		size := uint64(len(nd.RawData()))
+		out.Blocks++
+		out.Size += size
+		if depth > out.Depth {
+			out.Depth = depth
+		}
+
+		cs := out.Codecs[codecName(c)]
+		cs.Links++
+		cs.Size += size
+		out.Codecs[codecName(c)] = cs
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// linkName returns the path within a node of its link to a CID.
+func linkName(nd ipld.Node, c cid.Cid) string {
+	for _, l := range nd.Links() {
+		if l.Cid == c && l.Name != "" {
+			return l.Name
+		}
+	}
+	// links of CBOR nodes are unnamed, look for their path in the tree
+	for _, p := range nd.Tree("", -1) {
+		l, rest, err := nd.ResolveLink(strings.Split(p, "/"))
+		if err == nil && len(rest) == 0 && l.Cid == c {
+			return p
+		}
+	}
+	return ""
+}
+
+func (a *ChainAPI) ChainFindObjPath(ctx context.Context, from, to cid.Cid, opts api.DagWalkOpts) ([]api.DagPathStep, error) {
+	// the parents of the visited blocks are held in memory
+	if opts.MaxBlocks == 0 {
+		opts.MaxBlocks = api.DefaultFindPathMaxBlocks
+	}
+	w, err := a.dagWalker(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// parents of the blocks reached, the nodes on the path are loaded again
+	// once it's found
+	parents := map[cid.Cid]cid.Cid{}
+	var found bool
+	_, err = w.walk(ctx, from, func(c cid.Cid, nd ipld.Node, depth int) (bool, error) {
+		if c == to {
+			found = true
+			return false, errStopWalk
+		}
+		if nd == nil {
+			return false, nil
+		}
+
+		for _, l := range nd.Links() {
+			if _, ok := parents[l.Cid]; !ok && l.Cid != from {
+				parents[l.Cid] = c
+			}
+		}
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, nil
+	}
+
+	path := []api.DagPathStep{{Cid: to}}
+	for c := to; c != from; {
+		parent := parents[c]
+		nd, err := w.dag.Get(ctx, parent)
+		if err != nil {
+			return nil, xerrors.Errorf("loading %s: %w", parent, err)
+		}
+		path[len(path)-1].Link = linkName(nd, c)
+		path = append(path, api.DagPathStep{Cid: parent})
+		c = parent
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return path, nil
+}
+
+func (a *ChainAPI) ChainExportObj(ctx context.Context, p string, opts api.DagWalkOpts) (<-chan []byte, error) {
+	w, err := a.dagWalker(opts)
+	if err != nil {
+		return nil, err
+	}
+	root, err := a.resolvePath(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+
+	return exportStream(ctx, "object export", func(out io.Writer) error {
+		bw := bufio.NewWriterSize(out, 1<<20)
+		if err := car.WriteHeader(&car.CarHeader{Roots: []cid.Cid{root}, Version: 1}, bw); err != nil {
+			return xerrors.Errorf("writing car header: %w", err)
+		}
+
+		truncated, err := w.walk(ctx, root, func(c cid.Cid, nd ipld.Node, _ int) (bool, error) {
+			if nd == nil {
+				return false, nil
+			}
+			if err := carutil.LdWrite(bw, c.Bytes(), nd.RawData()); err != nil {
+				return false, xerrors.Errorf("writing block %s: %w", c, err)
+			}
+			return true, nil
+		})
+		if err != nil {
+			return err
+		}
+		if truncated {
+			log.Infow("object export truncated by the walk limits", "root", root, "opts", opts)
+		}
+		return bw.Flush()
+	}), nil
+}
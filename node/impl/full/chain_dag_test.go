@@ -0,0 +1,150 @@
+// stm: #unit
+package full
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	ipld "github.com/ipfs/go-ipld-format"
+	mh "github.com/multiformats/go-multihash"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+)
+
+// testDag stores a small graph, root -> {a, b}, a -> {c, missing}, b -> {c},
+// and returns the CIDs of its nodes.
+func testDag(t *testing.T, bs blockstore.Blockstore) (root, a, b, c, missing cid.Cid) {
+	put := func(obj interface{}) cid.Cid {
+		nd, err := cbor.WrapObject(obj, mh.SHA2_256, -1)
+		require.NoError(t, err)
+		require.NoError(t, bs.Put(context.Background(), nd))
+		return nd.Cid()
+	}
+
+	missingNd, err := cbor.WrapObject("gone", mh.SHA2_256, -1)
+	require.NoError(t, err)
+	missing = missingNd.Cid()
+
+	c = put("leaf")
+	a = put(map[string]interface{}{"leaf": c, "gone": missing})
+	b = put(map[string]interface{}{"leaf": c})
+	root = put(map[string]interface{}{"a": a, "b": b})
+	return root, a, b, c, missing
+}
+
+func TestDagWalk(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	root, ca, cb, c, _ := testDag(t, bs)
+
+	a := &ChainAPI{ExposedBlockstore: bs}
+
+	w, err := a.dagWalker(api.DagWalkOpts{})
+	require.NoError(t, err)
+
+	depths := map[cid.Cid]int{}
+	var missing int
+	truncated, err := w.walk(ctx, root, func(c cid.Cid, nd ipld.Node, depth int) (bool, error) {
+		if nd == nil {
+			missing++
+			return false, nil
+		}
+		depths[c] = depth
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.False(t, truncated)
+	require.Len(t, depths, 4)
+	require.Equal(t, 1, missing)
+	// shared blocks are visited once, at their shallowest depth
+	require.Equal(t, 2, depths[c])
+
+	w, err = a.dagWalker(api.DagWalkOpts{MaxDepth: 1})
+	require.NoError(t, err)
+	var visited int
+	truncated, err = w.walk(ctx, root, func(cid.Cid, ipld.Node, int) (bool, error) {
+		visited++
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, 3, visited)
+
+	w, err = a.dagWalker(api.DagWalkOpts{MaxBlocks: 2})
+	require.NoError(t, err)
+	visited = 0
+	truncated, err = w.walk(ctx, root, func(cid.Cid, ipld.Node, int) (bool, error) {
+		visited++
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.True(t, truncated)
+	require.Equal(t, 2, visited)
+
+	_, err = a.dagWalker(api.DagWalkOpts{MaxDepth: -1})
+	require.Error(t, err)
+
+	// the selector only traverses the root and its "a" field
+	sel := api.Selector(`{"f":{"f>":{"a":{".":{}}}}}`)
+	w, err = a.dagWalker(api.DagWalkOpts{Selector: &sel})
+	require.NoError(t, err)
+	var selected []cid.Cid
+	_, err = w.walk(ctx, root, func(c cid.Cid, _ ipld.Node, _ int) (bool, error) {
+		selected = append(selected, c)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []cid.Cid{root, ca}, selected)
+
+	// a path selects the graph under it
+	sel = "b"
+	w, err = a.dagWalker(api.DagWalkOpts{Selector: &sel})
+	require.NoError(t, err)
+	selected = nil
+	_, err = w.walk(ctx, root, func(c cid.Cid, _ ipld.Node, _ int) (bool, error) {
+		selected = append(selected, c)
+		return true, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []cid.Cid{root, cb, c}, selected)
+
+	sel = "{"
+	_, err = a.dagWalker(api.DagWalkOpts{Selector: &sel})
+	require.Error(t, err)
+}
+
+func TestChainFindObjPath(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	root, a, _, c, missing := testDag(t, bs)
+
+	capi := &ChainAPI{ExposedBlockstore: bs}
+
+	path, err := capi.ChainFindObjPath(ctx, root, missing, api.DagWalkOpts{})
+	require.NoError(t, err)
+	require.Equal(t, []api.DagPathStep{
+		{Cid: root},
+		{Cid: a, Link: "a"},
+		{Cid: missing, Link: "gone"},
+	}, path)
+
+	path, err = capi.ChainFindObjPath(ctx, root, c, api.DagWalkOpts{})
+	require.NoError(t, err)
+	require.Len(t, path, 3)
+	require.Equal(t, root, path[0].Cid)
+	require.Equal(t, c, path[2].Cid)
+	require.Equal(t, "leaf", path[2].Link)
+
+	path, err = capi.ChainFindObjPath(ctx, root, root, api.DagWalkOpts{})
+	require.NoError(t, err)
+	require.Equal(t, []api.DagPathStep{{Cid: root}}, path)
+
+	// unreachable within the limits
+	path, err = capi.ChainFindObjPath(ctx, root, c, api.DagWalkOpts{MaxDepth: 1})
+	require.NoError(t, err)
+	require.Nil(t, path)
+}
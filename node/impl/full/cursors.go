@@ -0,0 +1,173 @@
+package full
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// subscriptionCursor is the position of a subscriber in the chain: the last
+// tipset it was notified of, and for logs subscriptions the last event.
+// Cursors are the position encoded as base64url JSON, so they stay valid
+// across restarts of the node.
+type subscriptionCursor struct {
+	TipSet types.TipSetKey
+	Event  *filter.EventPosition `json:",omitempty"`
+}
+
+func encodeCursor(c subscriptionCursor) string {
+	b, err := json.Marshal(c)
+	if err != nil {
+		// the cursor only holds CIDs and numbers
+		panic(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (subscriptionCursor, error) {
+	var c subscriptionCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, api.NewErrInvalidParams("invalid_cursor", "invalid cursor: %w", err)
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, api.NewErrInvalidParams("invalid_cursor", "invalid cursor: %w", err)
+	}
+	if c.TipSet.IsEmpty() {
+		return c, api.NewErrInvalidParams("invalid_cursor", "invalid cursor: no tipset")
+	}
+	return c, nil
+}
+
+// cursorTipSet loads the tipset of a cursor, checking it's within the resume
+// window below the head.
+func cursorTipSet(ctx context.Context, cs *store.ChainStore, c subscriptionCursor, window int) (*types.TipSet, error) {
+	if window <= 0 {
+		return nil, api.NewErrMethodNotSupported("resume_disabled", "resuming subscriptions is disabled on this node")
+	}
+
+	ts, err := cs.LoadTipSet(ctx, c.TipSet)
+	if err != nil {
+		return nil, api.NewErrNotFound("unknown_cursor", false, "loading the tipset of the cursor: %w", err)
+	}
+
+	head := cs.GetHeaviestTipSet()
+	if head.Height()-ts.Height() > abi.ChainEpoch(window) {
+		err := api.NewErrLimitExceeded("cursor_expired", false, "cursor is %d epochs below the head, more than the resume window (%d)", head.Height()-ts.Height(), window)
+		err.Details = map[string]interface{}{"window": window}
+		return nil, err
+	}
+	return ts, nil
+}
+
+// headChangeCursor returns the cursor of the head after a batch of head
+// changes.
+func headChangeCursor(changes []*api.HeadChange) string {
+	last := changes[len(changes)-1]
+	tsk := last.Val.Key()
+	if last.Type == store.HCRevert {
+		tsk = last.Val.Parents()
+	}
+	return encodeCursor(subscriptionCursor{TipSet: tsk})
+}
+
+func (a *ChainAPI) ChainNotifyResume(ctx context.Context, cursor string) (<-chan api.HeadChangeBatch, error) {
+	var from *types.TipSet
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		var window int
+		if a.Subscriptions != nil {
+			window = a.Subscriptions.ResumeWindow
+		}
+		if from, err = cursorTipSet(ctx, a.Chain, c, window); err != nil {
+			return nil, err
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	in := a.Chain.SubHeadChanges(ctx)
+	out := make(chan api.HeadChangeBatch, 16)
+
+	go func() {
+		defer close(out)
+		defer cancel()
+
+		for changes := range in {
+			if from != nil {
+				// the first notification is the current head, replace it with
+				// the changes since the cursor
+				var err error
+				changes, err = a.Chain.GetPath(ctx, from.Key(), changes[0].Val.Key())
+				if err != nil {
+					log.Errorf("closing resumed head change subscription: getting the changes since the cursor: %s", err)
+					return
+				}
+				from = nil
+			}
+			if len(changes) == 0 {
+				continue
+			}
+
+			select {
+			case out <- api.HeadChangeBatch{Changes: changes, Cursor: headChangeCursor(changes)}:
+			case <-ctx.Done():
+				return
+			default:
+				log.Errorf("closing resumed head change subscription due to slow reader")
+				return
+			}
+		}
+	}()
+
+	return out, nil
+}
+
+// installResumeFilter installs a logs filter resuming a subscription after the
+// log of a cursor. When the tipset of the log was reverted since, the logs the
+// subscriber received from the reverted tipsets are sent again as removed, and
+// the events are replayed from the last tipset it shares with the chain.
+func (e *EthEvent) installResumeFilter(ctx context.Context, sub *ethSubscription, cursor string, addresses []address.Address, keys map[string][][]byte) (filter.Filter, error) {
+	c, err := decodeCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+	if c.Event == nil {
+		return nil, api.NewErrInvalidParams("invalid_cursor", "invalid cursor: not a logs cursor")
+	}
+
+	ts, err := cursorTipSet(ctx, e.Chain, c, e.ResumeWindow)
+	if err != nil {
+		return nil, err
+	}
+
+	reverted, _, err := store.ReorgOps(ctx, e.Chain.LoadTipSet, ts, e.Chain.GetHeaviestTipSet())
+	if err != nil {
+		return nil, xerrors.Errorf("finding the tipset of the cursor in the chain: %w", err)
+	}
+	if len(reverted) == 0 {
+		return e.EventFilterManager.InstallResume(sub.ctx, *c.Event, addresses, keys, sub.in, e.replayDone(sub))
+	}
+
+	common, err := e.Chain.LoadTipSet(ctx, reverted[len(reverted)-1].Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading the last tipset of the cursor in the chain: %w", err)
+	}
+	revertedKeys := make([]types.TipSetKey, len(reverted))
+	for i, rts := range reverted {
+		revertedKeys[i] = rts.Key()
+	}
+	return e.EventFilterManager.InstallReorgResume(sub.ctx, *c.Event, revertedKeys, common.Height()+1, addresses, keys, sub.in, e.replayDone(sub))
+}
@@ -0,0 +1,48 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestSubscriptionCursor(t *testing.T) {
+	parent := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	ts := mock.TipSet(mock.MkBlock(parent, 1, 1))
+
+	in := subscriptionCursor{
+		TipSet: ts.Key(),
+		Event:  &filter.EventPosition{Height: ts.Height(), MsgIdx: 2, EventIdx: 1},
+	}
+	out, err := decodeCursor(encodeCursor(in))
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+
+	for _, c := range []string{"not base64!", "bm90IGpzb24", encodeCursor(subscriptionCursor{})} {
+		_, err := decodeCursor(c)
+		var ip *api.ErrInvalidParams
+		require.ErrorAs(t, err, &ip, c)
+		require.Equal(t, "invalid_cursor", ip.Reason)
+	}
+
+	// after a revert the subscriber is at the parent of the reverted tipset
+	cursor := headChangeCursor([]*api.HeadChange{
+		{Type: store.HCApply, Val: parent},
+		{Type: store.HCRevert, Val: ts},
+	})
+	out, err = decodeCursor(cursor)
+	require.NoError(t, err)
+	require.Equal(t, parent.Key(), out.TipSet)
+	require.Nil(t, out.Event)
+
+	cursor = headChangeCursor([]*api.HeadChange{{Type: store.HCApply, Val: ts}})
+	out, err = decodeCursor(cursor)
+	require.NoError(t, err)
+	require.Equal(t, ts.Key(), out.TipSet)
+}
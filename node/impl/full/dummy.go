@@ -74,6 +74,10 @@ func (e *EthModuleDummy) EthGetTransactionReceiptLimited(ctx context.Context, tx
 	return nil, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*api.EthReceiptProof, error) {
+	return nil, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) EthGetTransactionByBlockHashAndIndex(ctx context.Context, blkHash ethtypes.EthHash, txIndex ethtypes.EthUint64) (ethtypes.EthTx, error) {
 	return ethtypes.EthTx{}, ErrModuleDisabled
 }
@@ -130,6 +134,14 @@ func (e *EthModuleDummy) EthCall(ctx context.Context, tx ethtypes.EthCall, blkPa
 	return nil, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthBatchStateRead(ctx context.Context, reads []api.EthStateRead, blkParam string) ([]api.EthStateReadResult, error) {
+	return nil, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []api.EthAccountOverride) (*api.EthBundleResult, error) {
+	return nil, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) EthMaxPriorityFeePerGas(ctx context.Context) (ethtypes.EthBigInt, error) {
 	return ethtypes.EthBigIntZero, ErrModuleDisabled
 }
@@ -138,6 +150,26 @@ func (e *EthModuleDummy) EthSendRawTransaction(ctx context.Context, rawTx ethtyp
 	return ethtypes.EthHash{}, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*api.EthRawTxDecoding, error) {
+	return nil, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthTxPoolContent(ctx context.Context) (api.EthTxPoolContent, error) {
+	return api.EthTxPoolContent{}, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthTxPoolInspect(ctx context.Context) (api.EthTxPoolInspect, error) {
+	return api.EthTxPoolInspect{}, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthTxPoolStatus(ctx context.Context) (api.EthTxPoolStatus, error) {
+	return api.EthTxPoolStatus{}, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*api.EthGasProfile, error) {
+	return nil, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) Web3ClientVersion(ctx context.Context) (string, error) {
 	return "", ErrModuleDisabled
 }
@@ -146,6 +178,14 @@ func (e *EthModuleDummy) EthGetLogs(ctx context.Context, filter *ethtypes.EthFil
 	return &ethtypes.EthFilterResult{}, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthGetLogsPage(ctx context.Context, filter *ethtypes.EthFilterSpec, continuation string) (*api.EthLogsPage, error) {
+	return nil, ErrModuleDisabled
+}
+
+func (e *EthModuleDummy) EthGetLogsStream(ctx context.Context, filter *ethtypes.EthFilterSpec) (<-chan api.EthLogsPage, error) {
+	return nil, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error) {
 	return &ethtypes.EthFilterResult{}, ErrModuleDisabled
 }
@@ -174,6 +214,10 @@ func (e *EthModuleDummy) EthSubscribe(ctx context.Context, params jsonrpc.RawPar
 	return ethtypes.EthSubscriptionID{}, ErrModuleDisabled
 }
 
+func (e *EthModuleDummy) EthSubscribeStorageSlots(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	return ethtypes.EthSubscriptionID{}, ErrModuleDisabled
+}
+
 func (e *EthModuleDummy) EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error) {
 	return false, ErrModuleDisabled
 }
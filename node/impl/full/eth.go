@@ -3,6 +3,10 @@ package full
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -15,8 +19,10 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/ipfs/go-cid"
+	"github.com/minio/blake2b-simd"
 	cbg "github.com/whyrusleeping/cbor-gen"
 	"github.com/zyedidia/generic/queue"
+	"go.opencensus.io/stats"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
@@ -43,6 +49,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/chain/vm"
+	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
 
@@ -62,6 +69,7 @@ type EthModuleAPI interface {
 	EthGetTransactionCount(ctx context.Context, sender ethtypes.EthAddress, blkOpt string) (ethtypes.EthUint64, error)
 	EthGetTransactionReceipt(ctx context.Context, txHash ethtypes.EthHash) (*api.EthTxReceipt, error)
 	EthGetTransactionReceiptLimited(ctx context.Context, txHash ethtypes.EthHash, limit abi.ChainEpoch) (*api.EthTxReceipt, error)
+	EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*api.EthReceiptProof, error)
 	EthGetCode(ctx context.Context, address ethtypes.EthAddress, blkOpt string) (ethtypes.EthBytes, error)
 	EthGetStorageAt(ctx context.Context, address ethtypes.EthAddress, position ethtypes.EthBytes, blkParam string) (ethtypes.EthBytes, error)
 	EthGetBalance(ctx context.Context, address ethtypes.EthAddress, blkParam string) (ethtypes.EthBigInt, error)
@@ -74,13 +82,22 @@ type EthModuleAPI interface {
 	EthGasPrice(ctx context.Context) (ethtypes.EthBigInt, error)
 	EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthUint64, error)
 	EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam string) (ethtypes.EthBytes, error)
+	EthBatchStateRead(ctx context.Context, reads []api.EthStateRead, blkParam string) ([]api.EthStateReadResult, error)
+	EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []api.EthAccountOverride) (*api.EthBundleResult, error)
 	EthMaxPriorityFeePerGas(ctx context.Context) (ethtypes.EthBigInt, error)
 	EthSendRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (ethtypes.EthHash, error)
+	EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*api.EthRawTxDecoding, error)
+	EthTxPoolContent(ctx context.Context) (api.EthTxPoolContent, error)
+	EthTxPoolInspect(ctx context.Context) (api.EthTxPoolInspect, error)
+	EthTxPoolStatus(ctx context.Context) (api.EthTxPoolStatus, error)
+	EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*api.EthGasProfile, error)
 	Web3ClientVersion(ctx context.Context) (string, error)
 }
 
 type EthEventAPI interface {
 	EthGetLogs(ctx context.Context, filter *ethtypes.EthFilterSpec) (*ethtypes.EthFilterResult, error)
+	EthGetLogsPage(ctx context.Context, filter *ethtypes.EthFilterSpec, continuation string) (*api.EthLogsPage, error)
+	EthGetLogsStream(ctx context.Context, filter *ethtypes.EthFilterSpec) (<-chan api.EthLogsPage, error)
 	EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
 	EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error)
 	EthNewFilter(ctx context.Context, filter *ethtypes.EthFilterSpec) (ethtypes.EthFilterID, error)
@@ -88,6 +105,7 @@ type EthEventAPI interface {
 	EthNewPendingTransactionFilter(ctx context.Context) (ethtypes.EthFilterID, error)
 	EthUninstallFilter(ctx context.Context, id ethtypes.EthFilterID) (bool, error)
 	EthSubscribe(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
+	EthSubscribeStorageSlots(ctx context.Context, params jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error)
 	EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error)
 }
 
@@ -131,6 +149,10 @@ type EthModule struct {
 	StateManager     *stmgr.StateManager
 	EthTxHashManager *EthTxHashManager
 
+	// NullRoundPolicy controls what eth_getBlockByNumber returns when the
+	// requested height is a null round. See the EthNullRound* constants.
+	NullRoundPolicy string
+
 	ChainAPI
 	MpoolAPI
 	StateAPI
@@ -147,7 +169,10 @@ type EthEvent struct {
 	FilterStore          filter.FilterStore
 	SubManager           *EthSubscriptionManager
 	MaxFilterHeightRange abi.ChainEpoch
-	SubscribtionCtx      context.Context
+	// ResumeWindow bounds how far below the head logs subscriptions can be
+	// resumed from, in epochs
+	ResumeWindow    int
+	SubscribtionCtx context.Context
 }
 
 var _ EthEventAPI = (*EthEvent)(nil)
@@ -161,8 +186,63 @@ type EthAPI struct {
 	EthEventAPI
 }
 
+// EthFeatures returns whether the optional features of the Ethereum API are
+// enabled. The features of the API of the gateway, used in lite mode, are
+// assumed to be enabled.
+func (a *EthAPI) EthFeatures() map[string]bool {
+	features := map[string]bool{}
+
+	switch m := a.EthModuleAPI.(type) {
+	case nil, *EthModuleDummy:
+	case *EthModule:
+		features[api.FeatureEthRPC] = true
+		features[api.FeatureEthTxHashLookup] = m.EthTxHashManager != nil
+	default:
+		features[api.FeatureEthRPC] = true
+		features[api.FeatureEthTxHashLookup] = true
+	}
+
+	switch e := a.EthEventAPI.(type) {
+	case nil, *EthModuleDummy:
+	case *EthEvent:
+		features[api.FeatureEthFilters] = e.FilterStore != nil && e.EventFilterManager != nil
+		features[api.FeatureEthSubscriptions] = e.SubManager != nil
+		features[api.FeatureEthHistoricLogs] = e.EventFilterManager != nil && e.EventFilterManager.EventIndex != nil
+	default:
+		features[api.FeatureEthFilters] = true
+		features[api.FeatureEthSubscriptions] = true
+		features[api.FeatureEthHistoricLogs] = true
+	}
+
+	return features
+}
+
 var ErrNullRound = errors.New("requested epoch was a null round")
 
+// Null round policies for eth_getBlockByNumber. Filecoin heights may have no
+// tipset at all (null rounds), which has no Ethereum equivalent, so the
+// behaviour is left up to the operator.
+const (
+	// EthNullRoundError returns ErrNullRound (the default).
+	EthNullRoundError = "error"
+	// EthNullRoundPrevious returns the closest non-null tipset below the
+	// requested height, with its own (lower) block number.
+	EthNullRoundPrevious = "previous"
+	// EthNullRoundEmpty returns a synthetic block without transactions at the
+	// requested height, whose parent is the closest non-null tipset below it.
+	EthNullRoundEmpty = "empty"
+)
+
+// ValidEthNullRoundPolicy returns an error if policy isn't a known null round policy.
+func ValidEthNullRoundPolicy(policy string) error {
+	switch policy {
+	case "", EthNullRoundError, EthNullRoundPrevious, EthNullRoundEmpty:
+		return nil
+	default:
+		return xerrors.Errorf("unknown null round policy %q (expected %q, %q or %q)", policy, EthNullRoundError, EthNullRoundPrevious, EthNullRoundEmpty)
+	}
+}
+
 func (a *EthModule) StateNetworkName(ctx context.Context) (dtypes.NetworkName, error) {
 	return stmgr.GetNetworkName(ctx, a.StateManager, a.Chain.GetHeaviestTipSet().ParentState())
 }
@@ -243,7 +323,7 @@ func (a *EthModule) EthGetBlockByHash(ctx context.Context, blkHash ethtypes.EthH
 
 func (a *EthModule) parseBlkParam(ctx context.Context, blkParam string, strict bool) (tipset *types.TipSet, err error) {
 	if blkParam == "earliest" {
-		return nil, fmt.Errorf("block param \"earliest\" is not supported")
+		return nil, api.NewErrInvalidParams("unsupported_block_param", "block param \"earliest\" is not supported")
 	}
 
 	head := a.Chain.GetHeaviestTipSet()
@@ -256,14 +336,23 @@ func (a *EthModule) parseBlkParam(ctx context.Context, blkParam string, strict b
 			return nil, fmt.Errorf("cannot get parent tipset")
 		}
 		return parent, nil
+	case "safe", "finalized":
+		// If the target height is a null round, the closest tipset below it is
+		// returned, same as for 'latest'.
+		height, _ := ethtypes.BlockTagHeight(blkParam, head.Height())
+		ts, err := a.ChainAPI.ChainGetTipSetByHeight(ctx, height, head.Key())
+		if err != nil {
+			return nil, fmt.Errorf("cannot get tipset at height: %v", height)
+		}
+		return ts, nil
 	default:
 		var num ethtypes.EthUint64
 		err := num.UnmarshalJSON([]byte(`"` + blkParam + `"`))
 		if err != nil {
-			return nil, fmt.Errorf("cannot parse block number: %v", err)
+			return nil, api.NewErrInvalidParams("invalid_block_param", "cannot parse block number: %v", err)
 		}
 		if abi.ChainEpoch(num) > head.Height()-1 {
-			return nil, fmt.Errorf("requested a future epoch (beyond 'latest')")
+			return nil, api.NewErrNotFound("future_epoch", true, "requested a future epoch (beyond 'latest')")
 		}
 		ts, err := a.ChainAPI.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(num), head.Key())
 		if err != nil {
@@ -277,10 +366,29 @@ func (a *EthModule) parseBlkParam(ctx context.Context, blkParam string, strict b
 }
 
 func (a *EthModule) EthGetBlockByNumber(ctx context.Context, blkParam string, fullTxInfo bool) (ethtypes.EthBlock, error) {
-	ts, err := a.parseBlkParam(ctx, blkParam, true)
+	ts, err := a.parseBlkParam(ctx, blkParam, false)
 	if err != nil {
 		return ethtypes.EthBlock{}, err
 	}
+
+	switch blkParam {
+	case "pending", "latest", "safe", "finalized":
+	default:
+		// parseBlkParam already validated the number
+		var num ethtypes.EthUint64
+		_ = num.UnmarshalJSON([]byte(`"` + blkParam + `"`))
+		if ts.Height() != abi.ChainEpoch(num) {
+			switch a.NullRoundPolicy {
+			case EthNullRoundPrevious:
+				// fall through to the non-null tipset below the requested height
+			case EthNullRoundEmpty:
+				return newEthBlockForNullRound(ts, abi.ChainEpoch(num))
+			default:
+				return ethtypes.EthBlock{}, ErrNullRound
+			}
+		}
+	}
+
 	return newEthBlockFromFilecoinTipSet(ctx, ts, fullTxInfo, a.Chain, a.StateAPI)
 }
 
@@ -393,6 +501,10 @@ func (a *EthModule) EthGetTransactionCount(ctx context.Context, sender ethtypes.
 		return ethtypes.EthUint64(0), xerrors.Errorf("failed to process block param: %s; %w", blkParam, err)
 	}
 
+	return a.ethGetTransactionCount(ctx, sender, addr, ts)
+}
+
+func (a *EthModule) ethGetTransactionCount(ctx context.Context, sender ethtypes.EthAddress, addr address.Address, ts *types.TipSet) (ethtypes.EthUint64, error) {
 	// First, handle the case where the "sender" is an EVM actor.
 	if actor, err := a.StateManager.LoadActor(ctx, addr, ts); err != nil {
 		if xerrors.Is(err, types.ErrActorNotFound) {
@@ -573,18 +685,24 @@ func (a *EthModule) EthGetStorageAt(ctx context.Context, ethAddr ethtypes.EthAdd
 		return nil, xerrors.Errorf("cannot get Filecoin address: %w", err)
 	}
 
+	return ethGetStorageAt(ctx, a.StateManager, a.Chain, to, position, ts)
+}
+
+// ethGetStorageAt reads a 32 byte storage slot of the contract at the parent
+// state of ts. Slots of addresses which aren't EVM actors read as zero.
+func ethGetStorageAt(ctx context.Context, sm *stmgr.StateManager, cs *store.ChainStore, to address.Address, position []byte, ts *types.TipSet) (ethtypes.EthBytes, error) {
 	// use the system actor as the caller
 	from, err := address.NewIDAddress(0)
 	if err != nil {
 		return nil, fmt.Errorf("failed to construct system sender address: %w", err)
 	}
 
-	actor, err := a.StateManager.LoadActor(ctx, to, ts)
+	actor, err := sm.LoadActor(ctx, to, ts)
 	if err != nil {
 		if xerrors.Is(err, types.ErrActorNotFound) {
 			return ethtypes.EthBytes(make([]byte, 32)), nil
 		}
-		return nil, xerrors.Errorf("failed to lookup contract %s: %w", ethAddr, err)
+		return nil, xerrors.Errorf("failed to lookup contract %s: %w", to, err)
 	}
 
 	if !builtinactors.IsEvmActor(actor.Code) {
@@ -612,11 +730,11 @@ func (a *EthModule) EthGetStorageAt(ctx context.Context, ethAddr ethtypes.EthAdd
 	// Try calling until we find a height with no migration.
 	var res *api.InvocResult
 	for {
-		res, err = a.StateManager.Call(ctx, msg, ts)
+		res, err = sm.Call(ctx, msg, ts)
 		if err != stmgr.ErrExpensiveFork {
 			break
 		}
-		ts, err = a.Chain.GetTipSetFromKey(ctx, ts.Parents())
+		ts, err = cs.GetTipSetFromKey(ctx, ts.Parents())
 		if err != nil {
 			return nil, xerrors.Errorf("getting parent tipset: %w", err)
 		}
@@ -675,40 +793,73 @@ func (a *EthModule) EthChainId(ctx context.Context) (ethtypes.EthUint64, error)
 	return ethtypes.EthUint64(build.Eip155ChainId), nil
 }
 
+// ethSyncingTolerance is how many epochs the node may trail the network while
+// eth_syncing still reports it as synced. Following the chain always involves
+// short syncs, which shouldn't flip health checks.
+const ethSyncingTolerance = 5
+
 func (a *EthModule) EthSyncing(ctx context.Context) (ethtypes.EthSyncingResult, error) {
 	state, err := a.SyncAPI.SyncState(ctx)
 	if err != nil {
 		return ethtypes.EthSyncingResult{}, fmt.Errorf("failed calling SyncState: %w", err)
 	}
 
-	if len(state.ActiveSyncs) == 0 {
-		return ethtypes.EthSyncingResult{}, errors.New("no active syncs, try again")
-	}
-
-	working := -1
-	for i, ss := range state.ActiveSyncs {
-		if ss.Stage == api.StageIdle {
+	head := a.Chain.GetHeaviestTipSet()
+	return ethSyncingFromState(state, head, build.Clock.Now()), nil
+}
+
+// ethSyncingFromState derives eth_syncing progress from the sync state
+// machine. The highest block is the larger of the highest target any sync
+// worker is heading towards and the height the chain should be at given the
+// head timestamp, so a node without peers (and thus without active syncs)
+// still reports it is behind.
+func ethSyncingFromState(state *api.SyncState, head *types.TipSet, now time.Time) ethtypes.EthSyncingResult {
+	// The worker syncing towards the highest target is the one making progress.
+	var active *api.ActiveSync
+	for i := range state.ActiveSyncs {
+		ss := &state.ActiveSyncs[i]
+		switch ss.Stage {
+		case api.StageIdle, api.StageSyncComplete, api.StageSyncErrored:
 			continue
 		}
-		working = i
+		if ss.Base == nil || ss.Target == nil {
+			continue
+		}
+		if active == nil || ss.Target.Height() > active.Target.Height() {
+			active = ss
+		}
 	}
-	if working == -1 {
-		working = len(state.ActiveSyncs) - 1
+
+	highest := head.Height()
+	if delta := now.Unix() - int64(head.MinTimestamp()); delta > 0 {
+		highest += abi.ChainEpoch(uint64(delta) / build.BlockDelaySecs)
+	}
+	if active != nil && active.Target.Height() > highest {
+		highest = active.Target.Height()
 	}
 
-	ss := state.ActiveSyncs[working]
-	if ss.Base == nil || ss.Target == nil {
-		return ethtypes.EthSyncingResult{}, errors.New("missing syncing information, try again")
+	if highest-head.Height() <= ethSyncingTolerance {
+		return ethtypes.EthSyncingResult{DoneSync: true}
 	}
 
 	res := ethtypes.EthSyncingResult{
-		DoneSync:      ss.Stage == api.StageSyncComplete,
-		CurrentBlock:  ethtypes.EthUint64(ss.Height),
-		StartingBlock: ethtypes.EthUint64(ss.Base.Height()),
-		HighestBlock:  ethtypes.EthUint64(ss.Target.Height()),
+		StartingBlock: ethtypes.EthUint64(head.Height()),
+		CurrentBlock:  ethtypes.EthUint64(head.Height()),
+		HighestBlock:  ethtypes.EthUint64(highest),
+	}
+	if active != nil {
+		worker := ethtypes.EthUint64(active.WorkerID)
+		res.SyncWorker = &worker
+		res.SyncStage = active.Stage.String()
+		res.StartingBlock = ethtypes.EthUint64(active.Base.Height())
+		// Headers are fetched from the target down, only message sync moves
+		// the validated height forward.
+		if active.Stage == api.StageMessages && active.Height > head.Height() {
+			res.CurrentBlock = ethtypes.EthUint64(active.Height)
+		}
 	}
 
-	return res, nil
+	return res
 }
 
 func (a *EthModule) EthFeeHistory(ctx context.Context, p jsonrpc.RawParams) (ethtypes.EthFeeHistory, error) {
@@ -857,9 +1008,180 @@ func (a *EthModule) EthSendRawTransaction(ctx context.Context, rawTx ethtypes.Et
 		return ethtypes.EmptyEthHash, err
 	}
 
+	if err := a.MpoolAPI.TrackPushedNonce(ctx, smsg); err != nil {
+		log.Warnf("failed to track nonce of pushed eth transaction from %s: %s", smsg.Message.From, err)
+	}
+
 	return ethtypes.EthHashFromTxBytes(rawTx), nil
 }
 
+func (a *EthModule) EthDecodeRawTransaction(ctx context.Context, rawTx ethtypes.EthBytes) (*api.EthRawTxDecoding, error) {
+	tx, err := ethtypes.DecodeEthRawTx(rawTx)
+	if err != nil {
+		return nil, xerrors.Errorf("decoding transaction: %w", err)
+	}
+
+	gasLimit, feeCap, premium := tx.FilecoinGas()
+	out := &api.EthRawTxDecoding{
+		Tx:         *tx,
+		GasLimit:   gasLimit,
+		GasFeeCap:  feeCap,
+		GasPremium: premium,
+	}
+
+	if from, err := tx.Sender(); err == nil {
+		out.From = &from
+		if out.Sender, err = from.ToFilecoinAddress(); err != nil {
+			return nil, xerrors.Errorf("converting sender to a filecoin address: %w", err)
+		}
+	}
+
+	// translate the transaction exactly like EthSendRawTransaction does
+	txArgs, err := ethtypes.ParseEthTxArgs(rawTx)
+	if err != nil {
+		out.TranslationError = err.Error()
+		return out, nil
+	}
+	smsg, err := txArgs.ToSignedMessage()
+	if err != nil {
+		out.TranslationError = err.Error()
+		return out, nil
+	}
+
+	msgCid := smsg.Cid()
+	out.Message = &smsg.Message
+	out.MessageCid = &msgCid
+	return out, nil
+}
+
+// forEachTxPoolMessage calls cb for every message in the message pool. A message
+// is queued when it can't be executed yet because a message with a lower nonce
+// from the same sender is missing from the pool, otherwise it is pending.
+func (a *EthModule) forEachTxPoolMessage(ctx context.Context, cb func(smsg *types.SignedMessage, queued bool) error) error {
+	msgs, _ := a.Mpool.Pending(ctx)
+
+	nextNonce := make(map[address.Address]uint64)
+	for _, smsg := range msgs {
+		from := smsg.Message.From
+		next, ok := nextNonce[from]
+		if !ok {
+			// the mpool nonce is the first nonce after the messages
+			// executable on top of the current state
+			var err error
+			next, err = a.Mpool.GetNonce(ctx, from, types.EmptyTSK)
+			if err != nil {
+				return xerrors.Errorf("getting mpool nonce for %s: %w", from, err)
+			}
+			nextNonce[from] = next
+		}
+
+		if err := cb(smsg, smsg.Message.Nonce >= next); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func addTxPoolEntry[T any](pool map[string]map[ethtypes.EthUint64]T, sender string, nonce uint64, entry T) {
+	if pool[sender] == nil {
+		pool[sender] = make(map[ethtypes.EthUint64]T)
+	}
+	pool[sender][ethtypes.EthUint64(nonce)] = entry
+}
+
+func (a *EthModule) EthTxPoolContent(ctx context.Context) (api.EthTxPoolContent, error) {
+	res := api.EthTxPoolContent{
+		Pending:         make(map[string]map[ethtypes.EthUint64]ethtypes.EthTx),
+		Queued:          make(map[string]map[ethtypes.EthUint64]ethtypes.EthTx),
+		FilecoinPending: make(map[string]map[ethtypes.EthUint64]*types.SignedMessage),
+		FilecoinQueued:  make(map[string]map[ethtypes.EthUint64]*types.SignedMessage),
+	}
+
+	err := a.forEachTxPoolMessage(ctx, func(smsg *types.SignedMessage, queued bool) error {
+		if smsg.Signature.Type != crypto.SigTypeDelegated {
+			pool := res.FilecoinPending
+			if queued {
+				pool = res.FilecoinQueued
+			}
+			addTxPoolEntry(pool, smsg.Message.From.String(), smsg.Message.Nonce, smsg)
+			return nil
+		}
+
+		tx, err := newEthTxFromSignedMessage(ctx, smsg, a.StateAPI)
+		if err != nil {
+			return xerrors.Errorf("converting message %s: %w", smsg.Cid(), err)
+		}
+		pool := res.Pending
+		if queued {
+			pool = res.Queued
+		}
+		addTxPoolEntry(pool, tx.From.String(), smsg.Message.Nonce, tx)
+		return nil
+	})
+	if err != nil {
+		return api.EthTxPoolContent{}, err
+	}
+	return res, nil
+}
+
+func (a *EthModule) EthTxPoolInspect(ctx context.Context) (api.EthTxPoolInspect, error) {
+	res := api.EthTxPoolInspect{
+		Pending:         make(map[string]map[ethtypes.EthUint64]string),
+		Queued:          make(map[string]map[ethtypes.EthUint64]string),
+		FilecoinPending: make(map[string]map[ethtypes.EthUint64]string),
+		FilecoinQueued:  make(map[string]map[ethtypes.EthUint64]string),
+	}
+
+	err := a.forEachTxPoolMessage(ctx, func(smsg *types.SignedMessage, queued bool) error {
+		msg := &smsg.Message
+		if smsg.Signature.Type != crypto.SigTypeDelegated {
+			pool := res.FilecoinPending
+			if queued {
+				pool = res.FilecoinQueued
+			}
+			summary := fmt.Sprintf("%s: %s attoFIL + %d gas × %s attoFIL (method %d)", msg.To, msg.Value, msg.GasLimit, msg.GasFeeCap, msg.Method)
+			addTxPoolEntry(pool, msg.From.String(), msg.Nonce, summary)
+			return nil
+		}
+
+		tx, err := newEthTxFromSignedMessage(ctx, smsg, a.StateAPI)
+		if err != nil {
+			return xerrors.Errorf("converting message %s: %w", smsg.Cid(), err)
+		}
+		to := "contract creation"
+		if tx.To != nil {
+			to = tx.To.String()
+		}
+		pool := res.Pending
+		if queued {
+			pool = res.Queued
+		}
+		summary := fmt.Sprintf("%s: %s wei + %d gas × %s wei", to, msg.Value, msg.GasLimit, msg.GasFeeCap)
+		addTxPoolEntry(pool, tx.From.String(), msg.Nonce, summary)
+		return nil
+	})
+	if err != nil {
+		return api.EthTxPoolInspect{}, err
+	}
+	return res, nil
+}
+
+func (a *EthModule) EthTxPoolStatus(ctx context.Context) (api.EthTxPoolStatus, error) {
+	var res api.EthTxPoolStatus
+	err := a.forEachTxPoolMessage(ctx, func(_ *types.SignedMessage, queued bool) error {
+		if queued {
+			res.Queued++
+		} else {
+			res.Pending++
+		}
+		return nil
+	})
+	if err != nil {
+		return api.EthTxPoolStatus{}, err
+	}
+	return res, nil
+}
+
 func (a *EthModule) Web3ClientVersion(ctx context.Context) (string, error) {
 	return build.UserVersion(), nil
 }
@@ -945,8 +1267,17 @@ func (a *EthModule) applyMessage(ctx context.Context, msg *types.Message, tsk ty
 		}
 	}
 	if err != nil {
+		// Return execution limit errors unwrapped, so that clients get the
+		// structured error.
+		var limitErr *api.ErrExecutionLimit
+		if errors.As(err, &limitErr) {
+			return nil, limitErr
+		}
 		return nil, xerrors.Errorf("CallWithGas failed: %w", err)
 	}
+	if res.MsgRct.ExitCode == exitCodeEVMReverted {
+		return nil, newErrExecutionReverted(res)
+	}
 	if res.MsgRct.ExitCode.IsError() {
 		reason := parseEthRevert(res.MsgRct.Return)
 		return nil, xerrors.Errorf("message execution failed: exit %s, revert reason: %s, vm error: %s", res.MsgRct.ExitCode, reason, res.Error)
@@ -975,6 +1306,19 @@ func (a *EthModule) EthEstimateGas(ctx context.Context, tx ethtypes.EthCall) (et
 		// information.
 		msg.GasLimit = build.BlockGasLimit
 		if _, err2 := a.applyMessage(ctx, msg, ts.Key()); err2 != nil {
+			// Return reverts unwrapped, so that the revert data reaches the client.
+			var reverted *api.ErrExecutionReverted
+			if errors.As(err2, &reverted) {
+				return ethtypes.EthUint64(0), &api.ErrExecutionReverted{
+					Message: "failed to estimate gas: " + reverted.Message,
+					Reason:  reverted.Reason,
+					Data:    reverted.Data,
+				}
+			}
+			var limitErr *api.ErrExecutionLimit
+			if errors.As(err2, &limitErr) {
+				return ethtypes.EthUint64(0), limitErr
+			}
 			err = err2
 		}
 		return ethtypes.EthUint64(0), xerrors.Errorf("failed to estimate gas: %w", err)
@@ -1128,6 +1472,11 @@ func (a *EthModule) EthCall(ctx context.Context, tx ethtypes.EthCall, blkParam s
 		return nil, err
 	}
 
+	return ethCallReturn(msg, invokeResult)
+}
+
+// ethCallReturn extracts the data returned by an eth_call from the invocation result.
+func ethCallReturn(msg *types.Message, invokeResult *api.InvocResult) (ethtypes.EthBytes, error) {
 	if msg.To == builtintypes.EthereumAddressManagerActorAddr {
 		// As far as I can tell, the Eth API always returns empty on contract deployment
 		return ethtypes.EthBytes{}, nil
@@ -1142,6 +1491,37 @@ func (e *EthEvent) EthGetLogs(ctx context.Context, filterSpec *ethtypes.EthFilte
 	if e.EventFilterManager == nil {
 		return nil, api.ErrNotSupported
 	}
+	defer metrics.Timer(ctx, metrics.EthGetLogsDuration)()
+
+	q, err := e.parseEthFilterSpec(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.TipSetCid == cid.Undef && e.EventFilterManager.EventIndex != nil {
+		// Read the range from the index in sub-ranges, rather than collecting
+		// all the events of the range and truncating them
+		if err := e.checkFilterHeightRange(q.MinHeight, q.MaxHeight); err != nil {
+			return nil, err
+		}
+		if q.MaxHeight == -1 {
+			q.MaxHeight = e.Chain.GetHeaviestTipSet().Height()
+		}
+		if q.MinHeight > q.MaxHeight {
+			return &ethtypes.EthFilterResult{}, nil
+		}
+
+		ces, next, err := filter.QueryEventsPage(ctx, e.EventFilterManager.EventIndex, q, e.EventFilterManager.MaxFilterResults)
+		if err != nil {
+			return nil, err
+		}
+		if next != -1 {
+			err := api.NewErrLimitExceeded("too_many_results", false, "query matches more than %d logs: narrow the block range or use EthGetLogsPage", e.EventFilterManager.MaxFilterResults)
+			err.Details = map[string]interface{}{"max": e.EventFilterManager.MaxFilterResults}
+			return nil, err
+		}
+		return ethFilterResultFromEvents(ces, e.SubManager.StateAPI)
+	}
 
 	// Create a temporary filter
 	f, err := e.installEthFilterSpec(ctx, filterSpec)
@@ -1155,6 +1535,164 @@ func (e *EthEvent) EthGetLogs(ctx context.Context, filterSpec *ethtypes.EthFilte
 	return ethFilterResultFromEvents(ces, e.SubManager.StateAPI)
 }
 
+// ethLogsContinuation is the state of a paged logs query, encoded in the
+// continuation of its pages.
+type ethLogsContinuation struct {
+	From abi.ChainEpoch
+	To   abi.ChainEpoch
+	// Filter is the hash of the filter spec of the query, continuations only
+	// apply to the query they were returned for
+	Filter string
+}
+
+// ethFilterSpecHash returns the hash of a filter spec, as it's stored in the
+// continuations of paged logs queries.
+func ethFilterSpecHash(filterSpec *ethtypes.EthFilterSpec) (string, error) {
+	b, err := json.Marshal(filterSpec)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:16]), nil
+}
+
+func decodeLogsContinuation(continuation, filterHash string) (ethLogsContinuation, error) {
+	b, err := base64.RawURLEncoding.DecodeString(continuation)
+	if err != nil {
+		return ethLogsContinuation{}, api.NewErrInvalidParams("invalid_continuation", "invalid continuation: %w", err)
+	}
+	var c ethLogsContinuation
+	if err := json.Unmarshal(b, &c); err != nil {
+		return ethLogsContinuation{}, api.NewErrInvalidParams("invalid_continuation", "invalid continuation: %w", err)
+	}
+	if c.Filter != filterHash {
+		return ethLogsContinuation{}, api.NewErrInvalidParams("invalid_continuation", "continuation of another filter spec")
+	}
+	if c.From < 0 || c.From > c.To {
+		return ethLogsContinuation{}, api.NewErrInvalidParams("invalid_continuation", "invalid continuation range")
+	}
+	return c, nil
+}
+
+func (e *EthEvent) EthGetLogsPage(ctx context.Context, filterSpec *ethtypes.EthFilterSpec, continuation string) (*api.EthLogsPage, error) {
+	if e.EventFilterManager == nil || e.EventFilterManager.EventIndex == nil {
+		return nil, api.ErrNotSupported
+	}
+
+	q, err := e.logsPageQuery(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+	filterHash, err := ethFilterSpecHash(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if continuation != "" {
+		c, err := decodeLogsContinuation(continuation, filterHash)
+		if err != nil {
+			return nil, err
+		}
+		q.MinHeight, q.MaxHeight = c.From, c.To
+	}
+
+	return e.logsPage(ctx, q, filterHash)
+}
+
+func (e *EthEvent) EthGetLogsStream(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (<-chan api.EthLogsPage, error) {
+	if e.EventFilterManager == nil || e.EventFilterManager.EventIndex == nil {
+		return nil, api.ErrNotSupported
+	}
+
+	q, err := e.logsPageQuery(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+	filterHash, err := ethFilterSpecHash(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(chan api.EthLogsPage, 1)
+	go func() {
+		defer close(out)
+
+		for {
+			page, err := e.logsPage(ctx, q, filterHash)
+			if err != nil {
+				page = &api.EthLogsPage{
+					FromBlock: ethtypes.EthUint64(q.MinHeight),
+					ToBlock:   ethtypes.EthUint64(q.MinHeight),
+					Error:     err.Error(),
+				}
+			}
+
+			select {
+			case out <- *page:
+			case <-ctx.Done():
+				return
+			}
+
+			if page.Continuation == "" {
+				return
+			}
+			q.MinHeight = abi.ChainEpoch(page.ToBlock) + 1
+		}
+	}()
+
+	return out, nil
+}
+
+// logsPageQuery returns the index query of the logs matching a filter spec,
+// with its heights resolved.
+func (e *EthEvent) logsPageQuery(filterSpec *ethtypes.EthFilterSpec) (*filter.EventQuery, error) {
+	q, err := e.parseEthFilterSpec(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+	if q.TipSetCid != cid.Undef {
+		return nil, xerrors.Errorf("logs of a single block aren't paged, use EthGetLogs")
+	}
+
+	if q.MaxHeight == -1 {
+		q.MaxHeight = e.Chain.GetHeaviestTipSet().Height()
+	}
+	if q.MinHeight > q.MaxHeight {
+		return nil, xerrors.Errorf("invalid epoch range: to block (%d) must be after from block (%d)", q.MaxHeight, q.MinHeight)
+	}
+	return q, nil
+}
+
+// logsPage reads a page of the logs of the query, starting at its minimum
+// height.
+func (e *EthEvent) logsPage(ctx context.Context, q *filter.EventQuery, filterHash string) (*api.EthLogsPage, error) {
+	ces, next, err := filter.QueryEventsPage(ctx, e.EventFilterManager.EventIndex, q, e.EventFilterManager.MaxFilterResults)
+	if err != nil {
+		return nil, err
+	}
+
+	logs, err := ethLogsFromEvents(ces, e.SubManager.StateAPI)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &api.EthLogsPage{
+		Logs:      logs,
+		FromBlock: ethtypes.EthUint64(q.MinHeight),
+		ToBlock:   ethtypes.EthUint64(q.MaxHeight),
+	}
+	if next != -1 {
+		page.ToBlock = ethtypes.EthUint64(next - 1)
+
+		b, err := json.Marshal(ethLogsContinuation{From: next, To: q.MaxHeight, Filter: filterHash})
+		if err != nil {
+			return nil, err
+		}
+		page.Continuation = base64.RawURLEncoding.EncodeToString(b)
+	}
+	return page, nil
+}
+
 func (e *EthEvent) EthGetFilterChanges(ctx context.Context, id ethtypes.EthFilterID) (*ethtypes.EthFilterResult, error) {
 	if e.FilterStore == nil {
 		return nil, api.ErrNotSupported
@@ -1196,12 +1734,29 @@ func (e *EthEvent) EthGetFilterLogs(ctx context.Context, id ethtypes.EthFilterID
 }
 
 func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (*filter.EventFilter, error) {
+	q, err := e.parseEthFilterSpec(filterSpec)
+	if err != nil {
+		return nil, err
+	}
+
+	if q.TipSetCid == cid.Undef {
+		if err := e.checkFilterHeightRange(q.MinHeight, q.MaxHeight); err != nil {
+			return nil, err
+		}
+	}
+
+	return e.EventFilterManager.Install(ctx, q.MinHeight, q.MaxHeight, q.TipSetCid, q.Addresses, q.Keys)
+}
+
+// parseEthFilterSpec returns the index query selecting the events matched by
+// a filter spec. A maximum height of -1 stands for the latest tipset at the
+// time the events are collected.
+func (e *EthEvent) parseEthFilterSpec(filterSpec *ethtypes.EthFilterSpec) (*filter.EventQuery, error) {
 	var (
 		minHeight abi.ChainEpoch
 		maxHeight abi.ChainEpoch
 		tipsetCid cid.Cid
 		addresses []address.Address
-		keys      = map[string][][]byte{}
 	)
 
 	if filterSpec.BlockHash != nil {
@@ -1246,29 +1801,6 @@ func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtype
 			}
 			maxHeight = abi.ChainEpoch(epoch)
 		}
-
-		// Validate height ranges are within limits set by node operator
-		if minHeight == -1 && maxHeight > 0 {
-			// Here the client is looking for events between the head and some future height
-			ts := e.Chain.GetHeaviestTipSet()
-			if maxHeight-ts.Height() > e.MaxFilterHeightRange {
-				return nil, xerrors.Errorf("invalid epoch range: to block is too far in the future (maximum: %d)", e.MaxFilterHeightRange)
-			}
-		} else if minHeight >= 0 && maxHeight == -1 {
-			// Here the client is looking for events between some time in the past and the current head
-			ts := e.Chain.GetHeaviestTipSet()
-			if ts.Height()-minHeight > e.MaxFilterHeightRange {
-				return nil, xerrors.Errorf("invalid epoch range: from block is too far in the past (maximum: %d)", e.MaxFilterHeightRange)
-			}
-
-		} else if minHeight >= 0 && maxHeight >= 0 {
-			if minHeight > maxHeight {
-				return nil, xerrors.Errorf("invalid epoch range: to block (%d) must be after from block (%d)", minHeight, maxHeight)
-			} else if maxHeight-minHeight > e.MaxFilterHeightRange {
-				return nil, xerrors.Errorf("invalid epoch range: range between to and from blocks is too large (maximum: %d)", e.MaxFilterHeightRange)
-			}
-		}
-
 	}
 
 	// Convert all addresses to filecoin f4 addresses
@@ -1285,7 +1817,45 @@ func (e *EthEvent) installEthFilterSpec(ctx context.Context, filterSpec *ethtype
 		return nil, err
 	}
 
-	return e.EventFilterManager.Install(ctx, minHeight, maxHeight, tipsetCid, addresses, keys)
+	return &filter.EventQuery{
+		MinHeight: minHeight,
+		MaxHeight: maxHeight,
+		TipSetCid: tipsetCid,
+		Addresses: addresses,
+		Keys:      keys,
+	}, nil
+}
+
+// checkFilterHeightRange validates height ranges are within limits set by
+// node operator.
+func (e *EthEvent) checkFilterHeightRange(minHeight, maxHeight abi.ChainEpoch) error {
+	if minHeight == -1 && maxHeight > 0 {
+		// Here the client is looking for events between the head and some future height
+		ts := e.Chain.GetHeaviestTipSet()
+		if maxHeight-ts.Height() > e.MaxFilterHeightRange {
+			return blockRangeTooLarge("invalid epoch range: to block is too far in the future (maximum: %d)", e.MaxFilterHeightRange)
+		}
+	} else if minHeight >= 0 && maxHeight == -1 {
+		// Here the client is looking for events between some time in the past and the current head
+		ts := e.Chain.GetHeaviestTipSet()
+		if ts.Height()-minHeight > e.MaxFilterHeightRange {
+			return blockRangeTooLarge("invalid epoch range: from block is too far in the past (maximum: %d)", e.MaxFilterHeightRange)
+		}
+
+	} else if minHeight >= 0 && maxHeight >= 0 {
+		if minHeight > maxHeight {
+			return api.NewErrInvalidParams("invalid_block_range", "invalid epoch range: to block (%d) must be after from block (%d)", minHeight, maxHeight)
+		} else if maxHeight-minHeight > e.MaxFilterHeightRange {
+			return blockRangeTooLarge("invalid epoch range: range between to and from blocks is too large (maximum: %d)", e.MaxFilterHeightRange)
+		}
+	}
+	return nil
+}
+
+func blockRangeTooLarge(format string, max abi.ChainEpoch) error {
+	err := api.NewErrLimitExceeded("block_range_too_large", false, format, max)
+	err.Details = map[string]interface{}{"max": max}
+	return err
 }
 
 func (e *EthEvent) EthNewFilter(ctx context.Context, filterSpec *ethtypes.EthFilterSpec) (ethtypes.EthFilterID, error) {
@@ -1425,6 +1995,7 @@ func (e *EthEvent) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethty
 	if err != nil {
 		return ethtypes.EthSubscriptionID{}, err
 	}
+	defer close(sub.ready)
 
 	switch params.EventType {
 	case EthSubscribeEventTypeHeads:
@@ -1459,6 +2030,32 @@ func (e *EthEvent) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethty
 			}
 		}
 
+		if params.Params != nil {
+			sub.cursors = params.Params.Cursors || params.Params.ResumeAfter != ""
+		}
+
+		if params.Params != nil && params.Params.ResumeAfter != "" {
+			f, err := e.installResumeFilter(ctx, sub, params.Params.ResumeAfter, addresses, keys)
+			if err != nil {
+				// clean up any previous filters added and stop the sub
+				_, _ = e.EthUnsubscribe(ctx, sub.id)
+				return ethtypes.EthSubscriptionID{}, err
+			}
+			sub.addStreamingFilter(f)
+			break
+		}
+
+		if params.Params != nil && params.Params.FromEpoch != nil {
+			f, err := e.installReplayFilter(ctx, sub, abi.ChainEpoch(*params.Params.FromEpoch), addresses, keys)
+			if err != nil {
+				// clean up any previous filters added and stop the sub
+				_, _ = e.EthUnsubscribe(ctx, sub.id)
+				return ethtypes.EthSubscriptionID{}, err
+			}
+			sub.addStreamingFilter(f)
+			break
+		}
+
 		f, err := e.EventFilterManager.Install(ctx, -1, -1, cid.Undef, addresses, keys)
 		if err != nil {
 			// clean up any previous filters added and stop the sub
@@ -1482,6 +2079,30 @@ func (e *EthEvent) EthSubscribe(ctx context.Context, p jsonrpc.RawParams) (ethty
 	return sub.id, nil
 }
 
+// installReplayFilter installs a logs filter that replays the indexed events from fromEpoch to the
+// subscription before streaming live ones. The replay runs in the background once the subscription
+// ID is returned.
+func (e *EthEvent) installReplayFilter(ctx context.Context, sub *ethSubscription, fromEpoch abi.ChainEpoch, addresses []address.Address, keys map[string][][]byte) (filter.Filter, error) {
+	head := e.Chain.GetHeaviestTipSet()
+	if head.Height()-fromEpoch > e.MaxFilterHeightRange {
+		return nil, xerrors.Errorf("invalid epoch range: fromEpoch is too far in the past (maximum: %d)", e.MaxFilterHeightRange)
+	}
+
+	return e.EventFilterManager.InstallReplay(sub.ctx, fromEpoch, addresses, keys, sub.in, e.replayDone(sub))
+}
+
+// replayDone returns the callback closing a subscription whose replay failed, as its logs would be
+// incomplete.
+func (e *EthEvent) replayDone(sub *ethSubscription) func(error) {
+	return func(err error) {
+		if err == nil || sub.ctx.Err() != nil {
+			return
+		}
+		log.Warnw("replaying logs failed, closing subscription", "sub", sub.id, "error", err)
+		_, _ = e.EthUnsubscribe(sub.ctx, sub.id)
+	}
+}
+
 func (e *EthEvent) EthUnsubscribe(ctx context.Context, id ethtypes.EthSubscriptionID) (bool, error) {
 	if e.SubManager == nil {
 		return false, api.ErrNotSupported
@@ -1593,7 +2214,20 @@ func ethLogFromEvent(entries []types.EventEntry) (data []byte, topics []ethtypes
 }
 
 func ethFilterResultFromEvents(evs []*filter.CollectedEvent, sa StateAPI) (*ethtypes.EthFilterResult, error) {
+	logs, err := ethLogsFromEvents(evs, sa)
+	if err != nil {
+		return nil, err
+	}
+
 	res := &ethtypes.EthFilterResult{}
+	for _, log := range logs {
+		res.Results = append(res.Results, log)
+	}
+	return res, nil
+}
+
+func ethLogsFromEvents(evs []*filter.CollectedEvent, sa StateAPI) ([]ethtypes.EthLog, error) {
+	var logs []ethtypes.EthLog
 	for _, ev := range evs {
 		log := ethtypes.EthLog{
 			Removed:          ev.Reverted,
@@ -1629,10 +2263,10 @@ func ethFilterResultFromEvents(evs []*filter.CollectedEvent, sa StateAPI) (*etht
 			return nil, err
 		}
 
-		res.Results = append(res.Results, log)
+		logs = append(logs, log)
 	}
 
-	return res, nil
+	return logs, nil
 }
 
 func ethFilterResultFromTipSets(tsks []types.TipSetKey) (*ethtypes.EthFilterResult, error) {
@@ -1675,6 +2309,8 @@ type EthSubscriptionManager struct {
 	ChainAPI ChainAPI
 	mu       sync.Mutex
 	subs     map[ethtypes.EthSubscriptionID]*ethSubscription
+
+	slotReads storageSlotReads
 }
 
 func (e *EthSubscriptionManager) StartSubscription(ctx context.Context, out ethSubscriptionCallback, dropFilter func(context.Context, filter.Filter) error) (*ethSubscription, error) { // nolint
@@ -1695,6 +2331,8 @@ func (e *EthSubscriptionManager) StartSubscription(ctx context.Context, out ethS
 		id:              id,
 		in:              make(chan interface{}, 200),
 		out:             out,
+		ctx:             ctx,
+		ready:           make(chan struct{}),
 		quit:            quit,
 
 		toSend:   queue.New[[]byte](),
@@ -1740,6 +2378,16 @@ type ethSubscription struct {
 	id              ethtypes.EthSubscriptionID
 	in              chan interface{}
 	out             ethSubscriptionCallback
+	// ctx is done when the subscription stops
+	ctx context.Context
+	// ready is closed once the subscription ID is returned, nothing is sent before
+	ready chan struct{}
+
+	// set for filecoin_subscribeStorageSlots subscriptions, which receive
+	// tipsets and send storage slot changes instead of new heads
+	slots *storageSlotWatch
+	// set for logs subscriptions sending a cursor with each log
+	cursors bool
 
 	mu      sync.Mutex
 	filters []filter.Filter
@@ -1759,9 +2407,23 @@ func (e *ethSubscription) addFilter(ctx context.Context, f filter.Filter) {
 	e.filters = append(e.filters, f)
 }
 
+// addStreamingFilter adds a filter already sending to the subscription.
+func (e *ethSubscription) addStreamingFilter(f filter.Filter) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.filters = append(e.filters, f)
+}
+
 // sendOut processes the final subscription queue. It's here in case the subscriber
 // is slow, and we need to buffer the messages.
 func (e *ethSubscription) startOut(ctx context.Context) {
+	select {
+	case <-e.ready:
+	case <-ctx.Done():
+		return
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
@@ -1790,9 +2452,14 @@ func (e *ethSubscription) startOut(ctx context.Context) {
 }
 
 func (e *ethSubscription) send(ctx context.Context, v interface{}) {
+	e.sendWithCursor(ctx, v, "")
+}
+
+func (e *ethSubscription) sendWithCursor(ctx context.Context, v interface{}, cursor string) {
 	resp := ethtypes.EthSubscriptionResponse{
 		SubscriptionID: e.id,
 		Result:         v,
+		Cursor:         cursor,
 	}
 
 	outParam, err := json.Marshal(resp)
@@ -1807,6 +2474,7 @@ func (e *ethSubscription) send(ctx context.Context, v interface{}) {
 	e.toSend.Enqueue(outParam)
 
 	e.sendQueueLen++
+	stats.Record(ctx, metrics.EventSubscriptionQueueDepth.M(int64(e.sendQueueLen)))
 	if e.sendQueueLen > maxSendQueue {
 		log.Warnw("subscription send queue full, killing subscription", "sub", e.id)
 		e.stop()
@@ -1832,10 +2500,22 @@ func (e *ethSubscription) start(ctx context.Context) {
 					continue
 				}
 
+				var cursor string
+				if e.cursors {
+					cursor = encodeCursor(subscriptionCursor{
+						TipSet: vt.TipSetKey,
+						Event:  &filter.EventPosition{Height: vt.Height, MsgIdx: vt.MsgIdx, EventIdx: vt.EventIdx},
+					})
+				}
 				for _, r := range evs.Results {
-					e.send(ctx, r)
+					e.sendWithCursor(ctx, r, cursor)
 				}
 			case *types.TipSet:
+				if e.slots != nil {
+					e.sendStorageSlotChanges(ctx, vt)
+					break
+				}
+
 				ev, err := newEthBlockFromFilecoinTipSet(ctx, vt, true, e.Chain, e.StateAPI)
 				if err != nil {
 					break
@@ -1953,6 +2633,33 @@ func newEthBlockFromFilecoinTipSet(ctx context.Context, ts *types.TipSet, fullTx
 	return block, nil
 }
 
+// newEthBlockForNullRound builds a synthetic, transaction-less block at the null
+// round height, on top of prev (the closest non-null tipset below it). The block
+// hash is derived from the parent tipset key and the height so it is stable
+// across calls and distinct for each null round following the same tipset.
+func newEthBlockForNullRound(prev *types.TipSet, height abi.ChainEpoch) (ethtypes.EthBlock, error) {
+	prevCid, err := prev.Key().Cid()
+	if err != nil {
+		return ethtypes.EthBlock{}, err
+	}
+	parentHash, err := ethtypes.EthHashFromCid(prevCid)
+	if err != nil {
+		return ethtypes.EthBlock{}, err
+	}
+
+	var heightBuf [8]byte
+	binary.BigEndian.PutUint64(heightBuf[:], uint64(height))
+	hash := ethtypes.EthHash(blake2b.Sum256(append(prevCid.Bytes(), heightBuf[:]...)))
+
+	block := ethtypes.NewEthBlock(false)
+	block.Hash = hash
+	block.Number = ethtypes.EthUint64(height)
+	block.ParentHash = parentHash
+	block.Timestamp = ethtypes.EthUint64(prev.MinTimestamp() + uint64(height-prev.Height())*build.BlockDelaySecs)
+	block.BaseFeePerGas = ethtypes.EthBigInt{Int: prev.Blocks()[0].ParentBaseFee.Int}
+	return block, nil
+}
+
 func messagesAndReceipts(ctx context.Context, ts *types.TipSet, cs *store.ChainStore, sa StateAPI) ([]types.ChainMsg, []types.MessageReceipt, error) {
 	msgs, err := cs.MessagesForTipset(ctx, ts)
 	if err != nil {
@@ -2409,6 +3116,9 @@ func parseEthTopics(topics ethtypes.EthTopicSpec) (map[string][][]byte, error) {
 	return keys, nil
 }
 
+// exitCodeEVMReverted is the exit code of messages reverted by an EVM actor
+const exitCodeEVMReverted = exitcode.ExitCode(33)
+
 const errorFunctionSelector = "\x08\xc3\x79\xa0" // Error(string)
 const panicFunctionSelector = "\x4e\x48\x7b\x71" // Panic(uint256)
 // Eth ABI (solidity) panic codes.
@@ -2440,9 +3150,23 @@ func parseEthRevert(ret []byte) string {
 	if len(cbytes) == 0 {
 		return "none"
 	}
+	reason, isPanic, ok := decodeEthRevert(cbytes)
+	switch {
+	case !ok:
+		return ethtypes.EthBytes(cbytes).String()
+	case isPanic:
+		return reason
+	default:
+		return fmt.Sprintf("Error(%s)", reason)
+	}
+}
+
+// decodeEthRevert decodes standard Solidity revert data, returning the message
+// of an Error(string) revert, or the name of a Panic(uint256) code.
+func decodeEthRevert(cbytes []byte) (reason string, isPanic bool, ok bool) {
 	// If it's not long enough to contain an ABI encoded response, return immediately.
 	if len(cbytes) < 4+32 {
-		return ethtypes.EthBytes(cbytes).String()
+		return "", false, false
 	}
 	switch string(cbytes[:4]) {
 	case panicFunctionSelector:
@@ -2452,12 +3176,12 @@ func parseEthRevert(ret []byte) string {
 		if err != nil {
 			// If it's too big, just return the raw value.
 			codeInt := big.PositiveFromUnsignedBytes(cbytes)
-			return fmt.Sprintf("Panic(%s)", ethtypes.EthBigInt(codeInt).String())
+			return fmt.Sprintf("Panic(%s)", ethtypes.EthBigInt(codeInt).String()), true, true
 		}
 		if s, ok := panicErrorCodes[uint64(code)]; ok {
-			return s
+			return s, true, true
 		}
-		return fmt.Sprintf("Panic(0x%x)", code)
+		return fmt.Sprintf("Panic(0x%x)", code), true, true
 	case errorFunctionSelector:
 		cbytes := cbytes[4:]
 		cbytesLen := ethtypes.EthUint64(len(cbytes))
@@ -2483,9 +3207,30 @@ func parseEthRevert(ret []byte) string {
 			break
 		}
 		// Slice the error message.
-		return fmt.Sprintf("Error(%s)", cbytes[start:start+length])
+		return string(cbytes[start : start+length]), false, true
+	}
+	return "", false, false
+}
+
+// newErrExecutionReverted builds the error returned when a call reverts in an
+// EVM actor, exposing the revert data and decoded reason to clients.
+func newErrExecutionReverted(res *api.InvocResult) *api.ErrExecutionReverted {
+	ret := res.MsgRct.Return
+	e := &api.ErrExecutionReverted{
+		Message: fmt.Sprintf("message execution failed: exit %s, revert reason: %s, vm error: %s", res.MsgRct.ExitCode, parseEthRevert(ret), res.Error),
+		Data:    "0x",
 	}
-	return ethtypes.EthBytes(cbytes).String()
+
+	var cbytes abi.CborBytes
+	if err := cbytes.UnmarshalCBOR(bytes.NewReader(ret)); err != nil || len(cbytes) == 0 {
+		return e
+	}
+	e.Data = ethtypes.EthBytes(cbytes).String()
+	if reason, _, ok := decodeEthRevert(cbytes); ok {
+		e.Reason = reason
+	}
+
+	return e
 }
 
 func calculateRewardsAndGasUsed(rewardPercentiles []float64, txGasRewards gasRewardSorter) ([]ethtypes.EthBigInt, int64) {
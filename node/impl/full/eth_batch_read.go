@@ -0,0 +1,145 @@
+package full
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// maxBatchStateReads limits the number of reads of a single
+// filecoin_batchStateRead request.
+const maxBatchStateReads = 10000
+
+func (a *EthModule) EthBatchStateRead(ctx context.Context, reads []api.EthStateRead, blkParam string) ([]api.EthStateReadResult, error) {
+	if len(reads) > maxBatchStateReads {
+		return nil, xerrors.Errorf("batch of %d reads exceeds the limit of %d", len(reads), maxBatchStateReads)
+	}
+
+	ts, err := a.parseBlkParam(ctx, blkParam, false)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to process block param: %s; %w", blkParam, err)
+	}
+
+	// the state tree is loaded lazily, as batches of calls don't need it
+	var tree *state.StateTree
+	loadTree := func() (*state.StateTree, error) {
+		if tree != nil {
+			return tree, nil
+		}
+		st, _, err := a.StateManager.TipSetState(ctx, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to compute tipset state: %w", err)
+		}
+		if tree, err = a.StateManager.StateTree(st); err != nil {
+			return nil, err
+		}
+		return tree, nil
+	}
+
+	br := &ethBatchReader{
+		balance: func(ctx context.Context, addr address.Address) (ethtypes.EthBigInt, error) {
+			tree, err := loadTree()
+			if err != nil {
+				return ethtypes.EthBigInt{}, err
+			}
+			act, err := tree.GetActor(addr)
+			if xerrors.Is(err, types.ErrActorNotFound) {
+				return ethtypes.EthBigIntZero, nil
+			} else if err != nil {
+				return ethtypes.EthBigInt{}, err
+			}
+			return ethtypes.EthBigInt(act.Balance), nil
+		},
+		nonce: func(ctx context.Context, sender ethtypes.EthAddress, addr address.Address) (ethtypes.EthUint64, error) {
+			return a.ethGetTransactionCount(ctx, sender, addr, ts)
+		},
+		storage: func(ctx context.Context, addr address.Address, position []byte) (ethtypes.EthBytes, error) {
+			return ethGetStorageAt(ctx, a.StateManager, a.Chain, addr, position, ts)
+		},
+		call: func(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthBytes, error) {
+			msg, err := a.ethCallToFilecoinMessage(ctx, tx)
+			if err != nil {
+				return nil, xerrors.Errorf("failed to convert ethcall to filecoin message: %w", err)
+			}
+			res, err := a.applyMessage(ctx, msg, ts.Key())
+			if err != nil {
+				return nil, err
+			}
+			return ethCallReturn(msg, res)
+		},
+	}
+
+	return br.run(ctx, reads), nil
+}
+
+// ethBatchReader evaluates the reads of a batch with the readers bound to the
+// tipset of the batch.
+type ethBatchReader struct {
+	balance func(ctx context.Context, addr address.Address) (ethtypes.EthBigInt, error)
+	nonce   func(ctx context.Context, sender ethtypes.EthAddress, addr address.Address) (ethtypes.EthUint64, error)
+	storage func(ctx context.Context, addr address.Address, position []byte) (ethtypes.EthBytes, error)
+	call    func(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthBytes, error)
+}
+
+func (br *ethBatchReader) run(ctx context.Context, reads []api.EthStateRead) []api.EthStateReadResult {
+	out := make([]api.EthStateReadResult, len(reads))
+	for i, r := range reads {
+		if ctx.Err() != nil {
+			out[i].Error = ctx.Err().Error()
+			continue
+		}
+		if err := br.read(ctx, r, &out[i]); err != nil {
+			out[i] = api.EthStateReadResult{Error: err.Error()}
+		}
+	}
+	return out
+}
+
+func (br *ethBatchReader) read(ctx context.Context, r api.EthStateRead, res *api.EthStateReadResult) error {
+	if r.Kind == api.EthStateReadCall {
+		if r.Call == nil {
+			return xerrors.Errorf("call read without a call")
+		}
+		data, err := br.call(ctx, *r.Call)
+		res.Data = data
+		return err
+	}
+
+	addr, err := r.Address.ToFilecoinAddress()
+	if err != nil {
+		return xerrors.Errorf("cannot get Filecoin address: %w", err)
+	}
+
+	switch r.Kind {
+	case api.EthStateReadBalance:
+		bal, err := br.balance(ctx, addr)
+		if err != nil {
+			return err
+		}
+		res.Balance = &bal
+	case api.EthStateReadNonce:
+		nonce, err := br.nonce(ctx, r.Address, addr)
+		if err != nil {
+			return err
+		}
+		res.Nonce = &nonce
+	case api.EthStateReadStorage:
+		if len(r.Position) > 32 {
+			return xerrors.Errorf("supplied storage key is too long")
+		}
+		// pad with zero bytes if smaller than 32 bytes
+		position := append(make([]byte, 32-len(r.Position), 32), r.Position...)
+		res.Data, err = br.storage(ctx, addr, position)
+		return err
+	default:
+		return xerrors.Errorf("unknown read kind %q", r.Kind)
+	}
+	return nil
+}
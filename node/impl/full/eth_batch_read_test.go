@@ -0,0 +1,84 @@
+// stm: #unit
+package full
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+func TestEthBatchReader(t *testing.T) {
+	ctx := context.Background()
+
+	a1, err := ethtypes.EthAddressFromFilecoinAddress(mustIDAddr(t, 1000))
+	require.NoError(t, err)
+	a2, err := ethtypes.EthAddressFromFilecoinAddress(mustIDAddr(t, 1001))
+	require.NoError(t, err)
+	missing := mustIDAddr(t, 1001)
+
+	var positions [][]byte
+	br := &ethBatchReader{
+		balance: func(ctx context.Context, addr address.Address) (ethtypes.EthBigInt, error) {
+			if addr == missing {
+				return ethtypes.EthBigInt{}, xerrors.Errorf("actor not found")
+			}
+			return ethtypes.EthBigInt(big.NewInt(42)), nil
+		},
+		nonce: func(ctx context.Context, sender ethtypes.EthAddress, addr address.Address) (ethtypes.EthUint64, error) {
+			return 7, nil
+		},
+		storage: func(ctx context.Context, addr address.Address, position []byte) (ethtypes.EthBytes, error) {
+			positions = append(positions, position)
+			return ethtypes.EthBytes{0x01}, nil
+		},
+		call: func(ctx context.Context, tx ethtypes.EthCall) (ethtypes.EthBytes, error) {
+			return tx.Data, nil
+		},
+	}
+
+	res := br.run(ctx, []api.EthStateRead{
+		{Kind: api.EthStateReadBalance, Address: a1},
+		{Kind: api.EthStateReadBalance, Address: a2},
+		{Kind: api.EthStateReadNonce, Address: a1},
+		{Kind: api.EthStateReadStorage, Address: a1, Position: ethtypes.EthBytes{0x05}},
+		{Kind: api.EthStateReadStorage, Address: a1, Position: make(ethtypes.EthBytes, 33)},
+		{Kind: api.EthStateReadCall, Call: &ethtypes.EthCall{To: &a1, Data: ethtypes.EthBytes{0xab}}},
+		{Kind: api.EthStateReadCall},
+		{Kind: "code", Address: a1},
+	})
+	require.Len(t, res, 8)
+
+	// reads fail independently
+	require.Empty(t, res[0].Error)
+	require.Equal(t, ethtypes.EthBigInt(big.NewInt(42)), *res[0].Balance)
+	require.NotEmpty(t, res[1].Error)
+	require.Nil(t, res[1].Balance)
+
+	require.Equal(t, ethtypes.EthUint64(7), *res[2].Nonce)
+	require.Nil(t, res[2].Balance)
+
+	require.Equal(t, ethtypes.EthBytes{0x01}, res[3].Data)
+	require.Len(t, positions, 1)
+	require.Len(t, positions[0], 32)
+	require.Equal(t, byte(0x05), positions[0][31])
+	require.Contains(t, res[4].Error, "too long")
+
+	require.Equal(t, ethtypes.EthBytes{0xab}, res[5].Data)
+	require.NotEmpty(t, res[6].Error)
+	require.Contains(t, res[7].Error, "unknown read kind")
+
+	// reads after the context is cancelled are not evaluated
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	res = br.run(cctx, []api.EthStateRead{{Kind: api.EthStateReadBalance, Address: a1}})
+	require.NotEmpty(t, res[0].Error)
+	require.Nil(t, res[0].Balance)
+}
@@ -0,0 +1,186 @@
+package full
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// maxBundleTxs limits the number of transactions of a single
+// filecoin_callBundle request.
+const maxBundleTxs = 100
+
+func (a *EthModule) EthCallBundle(ctx context.Context, txs []ethtypes.EthCall, blkParam string, overrides []api.EthAccountOverride) (*api.EthBundleResult, error) {
+	if len(txs) == 0 {
+		return nil, api.NewErrInvalidParams("empty_bundle", "the bundle has no transactions")
+	}
+	if len(txs) > maxBundleTxs {
+		return nil, api.NewErrInvalidParams("bundle_too_large", "bundle of %d transactions exceeds the limit of %d", len(txs), maxBundleTxs)
+	}
+
+	msgs := make([]*types.Message, len(txs))
+	for i, tx := range txs {
+		msg, err := a.ethCallToFilecoinMessage(ctx, tx)
+		if err != nil {
+			return nil, xerrors.Errorf("failed to convert transaction %d to filecoin message: %w", i, err)
+		}
+		if tx.Gas > 0 {
+			msg.GasLimit = int64(tx.Gas)
+		}
+		msgs[i] = msg
+	}
+
+	ovs := make(map[address.Address]stmgr.ActorOverride, len(overrides))
+	for _, o := range overrides {
+		addr, err := o.Address.ToFilecoinAddress()
+		if err != nil {
+			return nil, xerrors.Errorf("cannot get Filecoin address of override %s: %w", o.Address, err)
+		}
+		if _, ok := ovs[addr]; ok {
+			return nil, api.NewErrInvalidParams("duplicate_override", "duplicate override of %s", o.Address)
+		}
+		var ov stmgr.ActorOverride
+		if o.Balance != nil {
+			bal := abi.TokenAmount(*o.Balance)
+			ov.Balance = &bal
+		}
+		if o.Nonce != nil {
+			nonce := uint64(*o.Nonce)
+			ov.Nonce = &nonce
+		}
+		ovs[addr] = ov
+	}
+
+	ts, err := a.parseBlkParam(ctx, blkParam, false)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to process block param: %s; %w", blkParam, err)
+	}
+
+	// Try simulating until we find a height with no migration.
+	var res *stmgr.BundleResult
+	for {
+		res, err = a.StateManager.CallBundle(ctx, msgs, ovs, ts)
+		if err != stmgr.ErrExpensiveFork {
+			break
+		}
+		ts, err = a.Chain.GetTipSetFromKey(ctx, ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("getting parent tipset: %w", err)
+		}
+	}
+	if err != nil {
+		// Return execution limit errors unwrapped, so that clients get the
+		// structured error.
+		var limitErr *api.ErrExecutionLimit
+		if errors.As(err, &limitErr) {
+			return nil, limitErr
+		}
+		return nil, xerrors.Errorf("CallBundle failed: %w", err)
+	}
+
+	out := &api.EthBundleResult{
+		BlockNumber: ethtypes.EthUint64(ts.Height()),
+		Results:     make([]api.EthBundleTxResult, len(txs)),
+	}
+	for i, r := range res.Results {
+		out.Results[i] = ethBundleTxResult(txs[i], r)
+		out.TotalGasUsed += out.Results[i].GasUsed
+	}
+	if out.StateChanges, err = ethBundleStateChanges(res.Changes); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ethBundleTxResult converts the invocation result of a bundle transaction.
+func ethBundleTxResult(tx ethtypes.EthCall, res *api.InvocResult) api.EthBundleTxResult {
+	out := api.EthBundleTxResult{
+		To:         tx.To,
+		GasUsed:    ethtypes.EthUint64(res.MsgRct.GasUsed),
+		ReturnData: ethtypes.EthBytes{},
+	}
+	if tx.From != nil {
+		out.From = *tx.From
+	}
+
+	if res.MsgRct.ExitCode == exitCodeEVMReverted {
+		out.Error = "execution reverted"
+		out.Revert = parseEthRevert(res.MsgRct.Return)
+		return out
+	}
+	if res.MsgRct.ExitCode.IsError() {
+		out.Error = xerrors.Errorf("message execution failed: exit %s, vm error: %s", res.MsgRct.ExitCode, res.Error).Error()
+		return out
+	}
+
+	data, err := ethCallReturn(res.Msg, res)
+	if err != nil {
+		out.Error = xerrors.Errorf("decoding return value: %w", err).Error()
+		return out
+	}
+	out.ReturnData = data
+	return out
+}
+
+// ethBundleStateChanges converts the actor changes of a bundle, ordered by
+// actor ID.
+func ethBundleStateChanges(changes map[address.Address]stmgr.ActorChange) ([]api.EthBundleStateChange, error) {
+	ids := make([]address.Address, 0, len(changes))
+	for id := range changes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		a, _ := address.IDFromAddress(ids[i])
+		b, _ := address.IDFromAddress(ids[j])
+		return a < b
+	})
+
+	out := make([]api.EthBundleStateChange, 0, len(ids))
+	for _, id := range ids {
+		c := changes[id]
+		sc := api.EthBundleStateChange{
+			BalanceBefore: ethtypes.EthBigIntZero,
+			BalanceAfter:  ethtypes.EthBigIntZero,
+			Created:       c.Before == nil,
+			Deleted:       c.After == nil,
+		}
+
+		// prefer the f4 address of the actor to its masked ID
+		addr := id
+		for _, act := range []*types.Actor{c.After, c.Before} {
+			if act != nil && act.Address != nil && act.Address.Protocol() == address.Delegated {
+				addr = *act.Address
+				break
+			}
+		}
+		ethAddr, err := ethtypes.EthAddressFromFilecoinAddress(addr)
+		if err != nil {
+			// not an Ethereum address, e.g. of another namespace
+			if ethAddr, err = ethtypes.EthAddressFromFilecoinAddress(id); err != nil {
+				return nil, xerrors.Errorf("converting address of actor %s: %w", id, err)
+			}
+		}
+		sc.Address = ethAddr
+
+		if c.Before != nil {
+			sc.BalanceBefore = ethtypes.EthBigInt(c.Before.Balance)
+			sc.NonceBefore = ethtypes.EthUint64(c.Before.Nonce)
+		}
+		if c.After != nil {
+			sc.BalanceAfter = ethtypes.EthBigInt(c.After.Balance)
+			sc.NonceAfter = ethtypes.EthUint64(c.After.Nonce)
+		}
+		out = append(out, sc)
+	}
+	return out, nil
+}
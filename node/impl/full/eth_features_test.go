@@ -0,0 +1,113 @@
+package full
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// TestEthMethodFeatures checks api.MethodFeatures against the implementation
+// of the Eth API: the methods using, directly or through the other methods of
+// their module, a field which is only set when optional features are enabled
+// must need one of these features.
+func TestEthMethodFeatures(t *testing.T) {
+	// the fields of the modules which are nil unless one of the features is
+	// enabled, see EthFeatures
+	fieldFeatures := map[string]map[string][]string{
+		"EthModule": {
+			"EthTxHashManager": {api.FeatureEthTxHashLookup},
+		},
+		"EthEvent": {
+			// set along with the filters, which the historic logs rely on
+			"SubManager":  {api.FeatureEthFilters, api.FeatureEthSubscriptions, api.FeatureEthHistoricLogs},
+			"FilterStore": {api.FeatureEthFilters, api.FeatureEthSubscriptions, api.FeatureEthHistoricLogs},
+		},
+	}
+
+	// the fields and methods of their module the methods use, by module
+	methods := map[string]map[string]map[string]bool{}
+
+	files, err := filepath.Glob("*.go")
+	require.NoError(t, err)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		if strings.HasSuffix(file, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, file, nil, 0)
+		require.NoError(t, err)
+
+		for _, decl := range f.Decls {
+			fd, ok := decl.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil || fd.Body == nil || len(fd.Recv.List[0].Names) == 0 {
+				continue
+			}
+			star, ok := fd.Recv.List[0].Type.(*ast.StarExpr)
+			if !ok {
+				continue
+			}
+			recvType, ok := star.X.(*ast.Ident)
+			if !ok || fieldFeatures[recvType.Name] == nil {
+				continue
+			}
+			recv := fd.Recv.List[0].Names[0].Name
+
+			uses := map[string]bool{}
+			ast.Inspect(fd.Body, func(n ast.Node) bool {
+				sel, ok := n.(*ast.SelectorExpr)
+				if !ok {
+					return true
+				}
+				if id, ok := sel.X.(*ast.Ident); ok && id.Name == recv {
+					uses[sel.Sel.Name] = true
+				}
+				return true
+			})
+			if methods[recvType.Name] == nil {
+				methods[recvType.Name] = map[string]map[string]bool{}
+			}
+			methods[recvType.Name][fd.Name.Name] = uses
+		}
+	}
+
+	for module, features := range fieldFeatures {
+		// needs returns the fields with features a method uses
+		needs := func(name string) map[string]bool {
+			out := map[string]bool{}
+			seen := map[string]bool{}
+			var visit func(name string)
+			visit = func(name string) {
+				uses, ok := methods[module][name]
+				if !ok || seen[name] {
+					return
+				}
+				seen[name] = true
+				for use := range uses {
+					if _, ok := features[use]; ok {
+						out[use] = true
+					}
+					visit(use)
+				}
+			}
+			visit(name)
+			return out
+		}
+
+		require.NotEmpty(t, methods[module])
+		for name := range methods[module] {
+			if !strings.HasPrefix(name, "Eth") {
+				continue
+			}
+			for field := range needs(name) {
+				require.Contains(t, features[field], api.MethodFeature(name), "%s.%s uses %s", module, name, field)
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package full
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+func (a *EthModule) EthTraceTransactionGasProfile(ctx context.Context, txHash ethtypes.EthHash) (*api.EthGasProfile, error) {
+	c, err := a.EthTxHashManager.TransactionHashLookup.GetCidFromHash(txHash)
+	if err != nil {
+		log.Debug("could not find transaction hash %s in lookup table", txHash.String())
+	}
+
+	// This isn't an eth transaction we have the mapping for, so let's look it up as a filecoin message
+	if c == cid.Undef {
+		c = txHash.ToCid()
+	}
+
+	res, err := a.StateAPI.StateReplay(ctx, types.EmptyTSK, c)
+	if err != nil {
+		return nil, xerrors.Errorf("replaying transaction %s: %w", txHash, err)
+	}
+
+	prof := gasProfile(res.ExecutionTrace)
+	prof.TransactionHash = txHash
+	prof.Message = res.MsgCid
+	if res.MsgRct != nil {
+		prof.GasUsed = res.MsgRct.GasUsed
+	}
+
+	return prof, nil
+}
+
+func gasProfile(et types.ExecutionTrace) *api.EthGasProfile {
+	root := gasProfileFrame(et)
+	return &api.EthGasProfile{
+		Root:   root,
+		Folded: foldGasProfile(nil, root, nil),
+	}
+}
+
+func gasProfileFrame(et types.ExecutionTrace) api.GasProfileFrame {
+	f := api.GasProfileFrame{
+		Name:     gasProfileFrameName(et.Msg),
+		From:     et.Msg.From,
+		To:       et.Msg.To,
+		Method:   et.Msg.Method,
+		ExitCode: et.MsgRct.ExitCode,
+	}
+
+	charges := map[string]*api.GasProfileCharge{}
+	for _, gc := range et.GasCharges {
+		ch, ok := charges[gc.Name]
+		if !ok {
+			ch = &api.GasProfileCharge{Name: gc.Name}
+			charges[gc.Name] = ch
+		}
+		ch.Count++
+		ch.TotalGas += gc.TotalGas
+		ch.ComputeGas += gc.ComputeGas
+		ch.StorageGas += gc.StorageGas
+
+		f.SelfGas += gc.TotalGas
+	}
+	for _, ch := range charges {
+		f.Charges = append(f.Charges, *ch)
+	}
+	sort.Slice(f.Charges, func(i, j int) bool {
+		if f.Charges[i].TotalGas != f.Charges[j].TotalGas {
+			return f.Charges[i].TotalGas > f.Charges[j].TotalGas
+		}
+		return f.Charges[i].Name < f.Charges[j].Name
+	})
+
+	f.Value = f.SelfGas
+	for _, sc := range et.Subcalls {
+		child := gasProfileFrame(sc)
+		f.Value += child.Value
+		f.Children = append(f.Children, child)
+	}
+
+	return f
+}
+
+// gasProfileFrameName names a frame after the receiver and method, adding the
+// function selector for EVM contract invocations
+func gasProfileFrameName(msg types.MessageTrace) string {
+	to := msg.To.String()
+	if msg.To.Protocol() == address.Delegated {
+		if ea, err := ethtypes.EthAddressFromFilecoinAddress(msg.To); err == nil {
+			to = ea.String()
+		}
+	}
+
+	name := fmt.Sprintf("%s.%d", to, msg.Method)
+	if msg.Method == builtintypes.MethodsEVM.InvokeContract {
+		name = fmt.Sprintf("%s.InvokeContract", to)
+		input, err := cbg.ReadByteArray(bytes.NewReader(msg.Params), uint64(len(msg.Params)))
+		if err == nil && len(input) >= 4 {
+			name = fmt.Sprintf("%s.InvokeContract(0x%x)", to, input[:4])
+		}
+	}
+
+	// frame names are separated by ';' in folded stacks
+	return strings.ReplaceAll(name, ";", "_")
+}
+
+// foldGasProfile renders frames in the collapsed stack format, one line per
+// gas charge: "frame;frame;...;charge gas"
+func foldGasProfile(stack []string, f api.GasProfileFrame, out []string) []string {
+	stack = append(stack, f.Name)
+	prefix := strings.Join(stack, ";")
+
+	for _, ch := range f.Charges {
+		if ch.TotalGas == 0 {
+			continue
+		}
+		out = append(out, fmt.Sprintf("%s;%s %d", prefix, strings.ReplaceAll(ch.Name, ";", "_"), ch.TotalGas))
+	}
+	for _, child := range f.Children {
+		out = append(out, foldGasProfile(stack, child, nil)...)
+	}
+
+	return out
+}
@@ -0,0 +1,65 @@
+// stm: #unit
+package full
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-address"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestGasProfile(t *testing.T) {
+	sender, _ := address.NewIDAddress(100)
+	contract, _ := address.NewIDAddress(1000)
+	callee, _ := address.NewIDAddress(1001)
+
+	var params bytes.Buffer
+	require.NoError(t, cbg.WriteByteArray(&params, []byte{0xa9, 0x05, 0x9c, 0xbb, 0x01}))
+
+	et := types.ExecutionTrace{
+		Msg: types.MessageTrace{From: sender, To: contract, Method: builtintypes.MethodsEVM.InvokeContract, Params: params.Bytes()},
+		GasCharges: []*types.GasTrace{
+			{Name: "OnChainMessage", TotalGas: 100, ComputeGas: 60, StorageGas: 40},
+			{Name: "wasm_exec", TotalGas: 30, ComputeGas: 30},
+			{Name: "wasm_exec", TotalGas: 20, ComputeGas: 20},
+		},
+		Subcalls: []types.ExecutionTrace{{
+			Msg:    types.MessageTrace{From: contract, To: callee, Method: 2},
+			MsgRct: types.ReturnTrace{ExitCode: exitcode.ErrForbidden},
+			GasCharges: []*types.GasTrace{
+				{Name: "OnBlockRead", TotalGas: 7},
+				{Name: "OnMethodInvocation", TotalGas: 0},
+			},
+		}},
+	}
+
+	prof := gasProfile(et)
+
+	root := prof.Root
+	require.Equal(t, "f01000.InvokeContract(0xa9059cbb)", root.Name)
+	require.EqualValues(t, 150, root.SelfGas)
+	require.EqualValues(t, 157, root.Value)
+	require.Len(t, root.Charges, 2)
+	require.Equal(t, "OnChainMessage", root.Charges[0].Name)
+	require.Equal(t, 2, root.Charges[1].Count)
+	require.EqualValues(t, 50, root.Charges[1].TotalGas)
+
+	require.Len(t, root.Children, 1)
+	child := root.Children[0]
+	require.Equal(t, "f01001.2", child.Name)
+	require.Equal(t, exitcode.ErrForbidden, child.ExitCode)
+	require.EqualValues(t, 7, child.Value)
+
+	require.Equal(t, []string{
+		"f01000.InvokeContract(0xa9059cbb);OnChainMessage 100",
+		"f01000.InvokeContract(0xa9059cbb);wasm_exec 50",
+		"f01000.InvokeContract(0xa9059cbb);f01001.2;OnBlockRead 7",
+	}, prof.Folded)
+}
@@ -0,0 +1,120 @@
+package full
+
+import (
+	"context"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// maxReceiptProofDepth limits the number of tipsets between the checkpoint and
+// the execution tipset of a receipt proof.
+const maxReceiptProofDepth = build.Finality
+
+func (a *EthModule) EthGetTransactionReceiptProof(ctx context.Context, txHash ethtypes.EthHash, checkpoint string) (*api.EthReceiptProof, error) {
+	if checkpoint == "" {
+		checkpoint = "finalized"
+	}
+	cts, err := a.parseBlkParam(ctx, checkpoint, false)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to process block param: %s; %w", checkpoint, err)
+	}
+
+	c, err := a.EthTxHashManager.TransactionHashLookup.GetCidFromHash(txHash)
+	if err != nil {
+		log.Debug("could not find transaction hash %s in lookup table", txHash.String())
+	}
+	if c == cid.Undef {
+		c = txHash.ToCid()
+	}
+
+	// only messages executed at or before the checkpoint can be proven against it
+	msgLookup, err := a.StateAPI.StateSearchMsg(ctx, cts.Key(), c, api.LookbackNoLimit, true)
+	if err != nil {
+		return nil, xerrors.Errorf("searching for transaction %s: %w", txHash, err)
+	}
+	if msgLookup == nil {
+		return nil, api.NewErrNotFound("receipt_not_final", true, "transaction %s isn't executed at checkpoint %d", txHash, cts.Height())
+	}
+
+	ets, err := a.Chain.LoadTipSet(ctx, msgLookup.TipSet)
+	if err != nil {
+		return nil, xerrors.Errorf("loading execution tipset: %w", err)
+	}
+	if depth := cts.Height() - ets.Height(); depth > maxReceiptProofDepth {
+		return nil, api.NewErrLimitExceeded("proof_too_deep", false, "transaction %s was executed %d epochs before the checkpoint, more than the limit of %d", txHash, depth, maxReceiptProofDepth)
+	}
+
+	pts, err := a.Chain.LoadTipSet(ctx, ets.Parents())
+	if err != nil {
+		return nil, xerrors.Errorf("loading parent tipset: %w", err)
+	}
+	// the position of the message in the including tipset is the index of its receipt
+	msgBs := blockstore.Union(a.Chain.ChainBlockstore(), a.Chain.StateBlockstore())
+	index, msgBlks, err := state.ProveMessageInclusion(ctx, msgBs, pts, msgLookup.Message)
+	if err != nil {
+		return nil, xerrors.Errorf("proving the inclusion of message %s: %w", msgLookup.Message, err)
+	}
+	includingHeaders := make([][]byte, len(pts.Blocks()))
+	for i, b := range pts.Blocks() {
+		if includingHeaders[i], err = b.Serialize(); err != nil {
+			return nil, xerrors.Errorf("serializing block header %s: %w", b.Cid(), err)
+		}
+	}
+
+	var headers [][]byte
+	for ts := cts; ; {
+		h, err := ts.Blocks()[0].Serialize()
+		if err != nil {
+			return nil, xerrors.Errorf("serializing block header %s: %w", ts.Blocks()[0].Cid(), err)
+		}
+		headers = append(headers, h)
+		if ts.Key() == ets.Key() {
+			break
+		}
+
+		ts, err = a.Chain.LoadTipSet(ctx, ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %w", err)
+		}
+		if ts.Height() < ets.Height() {
+			return nil, xerrors.Errorf("execution tipset %s isn't an ancestor of the checkpoint", ets.Key())
+		}
+	}
+
+	receiptsRoot := ets.Blocks()[0].ParentMessageReceipts
+	rct, evs, blks, err := state.ProveReceipt(ctx, a.Chain.ChainBlockstore(), receiptsRoot, index)
+	if err != nil {
+		return nil, xerrors.Errorf("proving receipt %d: %w", index, err)
+	}
+
+	proof := &api.EthReceiptProof{
+		TransactionHash:  txHash,
+		Message:          msgLookup.Message,
+		Checkpoint:       cts.Key(),
+		CheckpointHeight: cts.Height(),
+		ExecutionTipSet:  ets.Key(),
+		ExecutionHeight:  ets.Height(),
+		Headers:          headers,
+		ReceiptsRoot:     receiptsRoot,
+		IncludingHeaders: includingHeaders,
+		MessageBlocks:    make([]api.ProofBlock, len(msgBlks)),
+		Index:            index,
+		Receipt:          *rct,
+		Events:           evs,
+		Blocks:           make([]api.ProofBlock, len(blks)),
+	}
+	for i, b := range msgBlks {
+		proof.MessageBlocks[i] = api.ProofBlock{Cid: b.Cid(), Data: b.RawData()}
+	}
+	for i, b := range blks {
+		proof.Blocks[i] = api.ProofBlock{Cid: b.Cid(), Data: b.RawData()}
+	}
+	return proof, nil
+}
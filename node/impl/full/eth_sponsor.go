@@ -0,0 +1,170 @@
+package full
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	builtintypes "github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors"
+	"github.com/filecoin-project/lotus/chain/sponsor"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/lib/sigs"
+)
+
+// EthSponsorAPI relays contract calls through ERC-2771 forwarders, paid for by
+// sponsor addresses. Sending sponsored calls is only enabled when
+// Fevm.SponsoredCalls is configured.
+type EthSponsorAPI struct {
+	fx.In
+
+	EthModuleAPI EthModuleAPI
+	WalletAPI    WalletAPI
+	MpoolAPI     MpoolAPI
+
+	Checker *sponsor.Checker `optional:"true"`
+}
+
+func (a *EthSponsorAPI) EthSponsoredCallTypedData(ctx context.Context, call api.EthSponsoredCall) (*ethtypes.EthTypedData, error) {
+	req, err := a.forwardRequest(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	td := req.TypedData(build.Eip155ChainId, call.Forwarder)
+	return &td, nil
+}
+
+func (a *EthSponsorAPI) EthSendSponsoredCall(ctx context.Context, call api.EthSponsoredCall) (*api.EthSponsoredCallResult, error) {
+	if a.Checker == nil {
+		return nil, xerrors.Errorf("sponsored calls are disabled, configure Fevm.SponsoredCalls to enable them")
+	}
+
+	sp := call.Sponsor
+	if sp == address.Undef {
+		sp = a.Checker.DefaultSponsor()
+	}
+
+	req, err := a.forwardRequest(ctx, call)
+	if err != nil {
+		return nil, err
+	}
+	if err := a.Checker.Check(sp, call.Forwarder, req); err != nil {
+		return nil, api.NewErrTransactionRejected("sponsor_policy", false, "%w", err)
+	}
+
+	td := req.TypedData(build.Eip155ChainId, call.Forwarder)
+	sig := call.Signature
+	if len(sig) == 0 {
+		if sig, err = a.WalletAPI.EthSignTypedData(ctx, call.From, td); err != nil {
+			return nil, xerrors.Errorf("signing forward request: %w", err)
+		}
+	}
+	// check the signature before paying for a call the forwarder rejects
+	if err := verifyForwardRequest(call.From, td, sig); err != nil {
+		return nil, api.NewErrInvalidParams("invalid_signature", "%w", err)
+	}
+
+	if err := a.Checker.Allow(call.From); err != nil {
+		return nil, api.NewErrLimitExceeded("sponsor_rate_limit", true, "%w", err)
+	}
+
+	to, err := call.Forwarder.ToFilecoinAddress()
+	if err != nil {
+		return nil, xerrors.Errorf("cannot get Filecoin address of forwarder: %w", err)
+	}
+	calldata := abi.CborBytes(req.ExecuteCalldata(sig))
+	params, err := actors.SerializeParams(&calldata)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to serialize params: %w", err)
+	}
+
+	smsg, err := a.MpoolAPI.MpoolPushMessage(ctx, &types.Message{
+		From:   sp,
+		To:     to,
+		Value:  req.Value,
+		Method: builtintypes.MethodsEVM.InvokeContract,
+		Params: params,
+	}, &api.MessageSendSpec{MaxFee: a.Checker.MaxFee()})
+	if err != nil {
+		return nil, xerrors.Errorf("pushing sponsored call: %w", err)
+	}
+
+	log.Infow("sent sponsored call", "sponsor", sp, "from", call.From, "to", call.To, "cid", smsg.Cid())
+	return &api.EthSponsoredCallResult{
+		Sponsor:    sp,
+		MessageCid: smsg.Cid(),
+		Nonce:      ethtypes.EthUint64(req.Nonce),
+		Signature:  sig,
+	}, nil
+}
+
+// forwardRequest returns the forward request of a call, reading the nonce of
+// the account from the forwarder when the call doesn't set it.
+func (a *EthSponsorAPI) forwardRequest(ctx context.Context, call api.EthSponsoredCall) (*sponsor.ForwardRequest, error) {
+	if call.Forwarder == (ethtypes.EthAddress{}) {
+		return nil, api.NewErrInvalidParams("no_forwarder", "the call has no forwarder")
+	}
+
+	req := &sponsor.ForwardRequest{
+		From:  call.From,
+		To:    call.To,
+		Value: big.Int(call.Value),
+		Gas:   uint64(call.Gas),
+		Data:  call.Data,
+	}
+	if req.Value.Nil() {
+		req.Value = big.Zero()
+	}
+
+	if call.Nonce != nil {
+		req.Nonce = uint64(*call.Nonce)
+		return req, nil
+	}
+
+	ret, err := a.EthModuleAPI.EthCall(ctx, ethtypes.EthCall{
+		To:   &call.Forwarder,
+		Data: sponsor.GetNonceCalldata(call.From),
+	}, "pending")
+	if err != nil {
+		return nil, xerrors.Errorf("reading forwarder nonce of %s: %w", call.From, err)
+	}
+	if req.Nonce, err = sponsor.DecodeNonce(ret); err != nil {
+		return nil, xerrors.Errorf("reading forwarder nonce of %s: %w", call.From, err)
+	}
+	return req, nil
+}
+
+// verifyForwardRequest checks the r || s || v signature of the typed data is
+// by the f410 address of from.
+func verifyForwardRequest(from ethtypes.EthAddress, td ethtypes.EthTypedData, sig ethtypes.EthBytes) error {
+	if len(sig) != 65 {
+		return xerrors.Errorf("expected a 65 byte signature, got %d bytes", len(sig))
+	}
+	addr, err := from.ToFilecoinAddress()
+	if err != nil {
+		return xerrors.Errorf("converting sender address: %w", err)
+	}
+	payload, err := td.SigningPayload()
+	if err != nil {
+		return xerrors.Errorf("encoding typed data: %w", err)
+	}
+
+	data := make([]byte, 65)
+	copy(data, sig)
+	if data[64] >= 27 {
+		data[64] -= 27
+	}
+	if err := sigs.Verify(&crypto.Signature{Type: crypto.SigTypeDelegated, Data: data}, addr, payload); err != nil {
+		return xerrors.Errorf("forward request isn't signed by %s: %w", from, err)
+	}
+	return nil
+}
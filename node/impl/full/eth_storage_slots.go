@@ -0,0 +1,311 @@
+package full
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/multierr"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+)
+
+// maxWatchedStorageSlots limits the number of slots a single
+// filecoin_subscribeStorageSlots subscription can watch, as all slots of a
+// contract are read every time its state changes.
+const maxWatchedStorageSlots = 256
+
+func (e *EthEvent) EthSubscribeStorageSlots(ctx context.Context, p jsonrpc.RawParams) (ethtypes.EthSubscriptionID, error) {
+	params, err := jsonrpc.DecodeParams[ethtypes.EthStorageSlotsParams](p)
+	if err != nil {
+		return ethtypes.EthSubscriptionID{}, xerrors.Errorf("decoding params: %w", err)
+	}
+
+	if e.SubManager == nil || e.TipSetFilterManager == nil {
+		return ethtypes.EthSubscriptionID{}, api.ErrNotSupported
+	}
+
+	sm, cs, reads := e.SubManager.StateAPI.StateManager, e.Chain, &e.SubManager.slotReads
+	watch, err := newStorageSlotWatch(params,
+		func(ctx context.Context, addr address.Address, slot [32]byte, ts *types.TipSet) (ethtypes.EthHash, error) {
+			return reads.read(addr, slot, ts, func() (ethtypes.EthHash, error) {
+				v, err := ethGetStorageAt(ctx, sm, cs, addr, slot[:], ts)
+				if err != nil {
+					return ethtypes.EthHash{}, err
+				}
+				var out ethtypes.EthHash
+				copy(out[:], v)
+				return out, nil
+			})
+		},
+		func(ctx context.Context, addr address.Address, ts *types.TipSet) (cid.Cid, error) {
+			act, err := sm.LoadActor(ctx, addr, ts)
+			if xerrors.Is(err, types.ErrActorNotFound) {
+				return cid.Undef, nil
+			}
+			if err != nil {
+				return cid.Undef, err
+			}
+			return act.Head, nil
+		})
+	if err != nil {
+		return ethtypes.EthSubscriptionID{}, err
+	}
+
+	// read the current values, only changes from here on are sent
+	if err := watch.init(ctx, cs.GetHeaviestTipSet()); err != nil {
+		return ethtypes.EthSubscriptionID{}, xerrors.Errorf("reading storage slots: %w", err)
+	}
+
+	ethCb, ok := jsonrpc.ExtractReverseClient[api.EthSubscriberMethods](ctx)
+	if !ok {
+		return ethtypes.EthSubscriptionID{}, xerrors.Errorf("connection doesn't support callbacks")
+	}
+
+	sub, err := e.SubManager.StartSubscription(e.SubscribtionCtx, ethCb.EthSubscription, e.uninstallFilter)
+	if err != nil {
+		return ethtypes.EthSubscriptionID{}, err
+	}
+	defer close(sub.ready)
+
+	// set before adding the filter, which is what delivers tipsets to the subscription
+	sub.slots = watch
+
+	f, err := e.TipSetFilterManager.Install(ctx)
+	if err != nil {
+		// clean up any previous filters added and stop the sub
+		_, _ = e.EthUnsubscribe(ctx, sub.id)
+		return ethtypes.EthSubscriptionID{}, err
+	}
+	sub.addFilter(ctx, f)
+
+	return sub.id, nil
+}
+
+func (e *ethSubscription) sendStorageSlotChanges(ctx context.Context, ts *types.TipSet) {
+	changes, err := e.slots.changes(ctx, ts)
+	if err != nil {
+		log.Warnw("reading watched storage slots", "sub", e.id, "tipset", ts.Key(), "error", err)
+	}
+	if len(changes) == 0 {
+		return
+	}
+
+	// the parent state of ts is the result of executing the parent tipset
+	parent, err := e.Chain.GetTipSetFromKey(ctx, ts.Parents())
+	if err != nil {
+		log.Warnw("loading parent tipset", "sub", e.id, "tipset", ts.Key(), "error", err)
+		return
+	}
+	parentCid, err := parent.Key().Cid()
+	if err != nil {
+		log.Warnw("computing parent tipset cid", "sub", e.id, "error", err)
+		return
+	}
+	blkHash, err := ethtypes.EthHashFromCid(parentCid)
+	if err != nil {
+		log.Warnw("computing parent block hash", "sub", e.id, "error", err)
+		return
+	}
+
+	for _, c := range changes {
+		c.BlockHash = blkHash
+		c.BlockNumber = ethtypes.EthUint64(parent.Height())
+		e.send(ctx, c)
+	}
+}
+
+// storageSlotReads holds the storage slots read at the latest tipset, shared
+// by the subscriptions watching the same slots so that each slot costs a
+// single call per tipset however many subscriptions watch it.
+type storageSlotReads struct {
+	lk     sync.Mutex
+	ts     types.TipSetKey
+	values map[storageSlotKey]ethtypes.EthHash
+}
+
+type storageSlotKey struct {
+	addr address.Address
+	slot [32]byte
+}
+
+// read returns the value of the slot at ts, calling get if it wasn't read yet.
+// The slots of the previous tipset are dropped once a new one is read.
+func (r *storageSlotReads) read(addr address.Address, slot [32]byte, ts *types.TipSet, get func() (ethtypes.EthHash, error)) (ethtypes.EthHash, error) {
+	key := storageSlotKey{addr: addr, slot: slot}
+
+	r.lk.Lock()
+	if r.values == nil || r.ts != ts.Key() {
+		r.ts = ts.Key()
+		r.values = map[storageSlotKey]ethtypes.EthHash{}
+	}
+	v, ok := r.values[key]
+	r.lk.Unlock()
+	if ok {
+		return v, nil
+	}
+
+	v, err := get()
+	if err != nil {
+		return ethtypes.EthHash{}, err
+	}
+
+	r.lk.Lock()
+	if r.ts == ts.Key() {
+		r.values[key] = v
+	}
+	r.lk.Unlock()
+	return v, nil
+}
+
+// storageSlotWatch tracks the values of watched contract storage slots. Slots
+// of a contract are only read again when the contract state root changed.
+type storageSlotWatch struct {
+	contracts []*watchedContract
+
+	readSlot  func(ctx context.Context, addr address.Address, slot [32]byte, ts *types.TipSet) (ethtypes.EthHash, error)
+	actorHead func(ctx context.Context, addr address.Address, ts *types.TipSet) (cid.Cid, error)
+}
+
+type watchedContract struct {
+	ethAddr ethtypes.EthAddress
+	addr    address.Address
+	slots   [][32]byte
+
+	head   cid.Cid
+	values map[[32]byte]ethtypes.EthHash
+}
+
+func newStorageSlotWatch(
+	params ethtypes.EthStorageSlotsParams,
+	readSlot func(context.Context, address.Address, [32]byte, *types.TipSet) (ethtypes.EthHash, error),
+	actorHead func(context.Context, address.Address, *types.TipSet) (cid.Cid, error),
+) (*storageSlotWatch, error) {
+	w := &storageSlotWatch{
+		readSlot:  readSlot,
+		actorHead: actorHead,
+	}
+
+	byAddr := map[ethtypes.EthAddress]*watchedContract{}
+	var total int
+	for _, spec := range params {
+		wc, ok := byAddr[spec.Address]
+		if !ok {
+			addr, err := spec.Address.ToFilecoinAddress()
+			if err != nil {
+				return nil, xerrors.Errorf("invalid address %s: %w", spec.Address, err)
+			}
+			wc = &watchedContract{
+				ethAddr: spec.Address,
+				addr:    addr,
+				values:  map[[32]byte]ethtypes.EthHash{},
+			}
+			byAddr[spec.Address] = wc
+			w.contracts = append(w.contracts, wc)
+		}
+
+		for _, slot := range spec.Slots {
+			if len(slot) > 32 {
+				return nil, xerrors.Errorf("storage slot %s of %s is longer than 32 bytes", slot, spec.Address)
+			}
+			var key [32]byte
+			copy(key[32-len(slot):], slot)
+			if _, dup := wc.values[key]; dup {
+				continue
+			}
+			wc.values[key] = ethtypes.EthHash{}
+			wc.slots = append(wc.slots, key)
+			total++
+		}
+	}
+
+	if total == 0 {
+		return nil, xerrors.Errorf("no storage slots to watch")
+	}
+	if total > maxWatchedStorageSlots {
+		return nil, xerrors.Errorf("too many storage slots to watch: %d, the maximum is %d", total, maxWatchedStorageSlots)
+	}
+
+	return w, nil
+}
+
+// init reads the values of all slots at ts
+func (w *storageSlotWatch) init(ctx context.Context, ts *types.TipSet) error {
+	for _, wc := range w.contracts {
+		head, err := w.actorHead(ctx, wc.addr, ts)
+		if err != nil {
+			return xerrors.Errorf("loading %s: %w", wc.ethAddr, err)
+		}
+		wc.head = head
+
+		for _, slot := range wc.slots {
+			v, err := w.readSlot(ctx, wc.addr, slot, ts)
+			if err != nil {
+				return xerrors.Errorf("reading slot %x of %s: %w", slot, wc.ethAddr, err)
+			}
+			wc.values[slot] = v
+		}
+	}
+	return nil
+}
+
+// changes returns the slots whose value changed since the previous call, as
+// of the parent state of ts. The block fields of the changes aren't set.
+// Contracts which fail to be read are skipped and retried with the next
+// tipset, the changes of the other contracts are still returned along with
+// the error.
+func (w *storageSlotWatch) changes(ctx context.Context, ts *types.TipSet) ([]ethtypes.EthStorageSlotChange, error) {
+	var out []ethtypes.EthStorageSlotChange
+	var errs error
+	for _, wc := range w.contracts {
+		changes, err := w.contractChanges(ctx, wc, ts)
+		if err != nil {
+			errs = multierr.Append(errs, err)
+			continue
+		}
+		out = append(out, changes...)
+	}
+	return out, errs
+}
+
+func (w *storageSlotWatch) contractChanges(ctx context.Context, wc *watchedContract, ts *types.TipSet) ([]ethtypes.EthStorageSlotChange, error) {
+	head, err := w.actorHead(ctx, wc.addr, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading %s: %w", wc.ethAddr, err)
+	}
+	if head == wc.head {
+		return nil, nil
+	}
+
+	var out []ethtypes.EthStorageSlotChange
+	values := make(map[[32]byte]ethtypes.EthHash, len(wc.slots))
+	for _, slot := range wc.slots {
+		v, err := w.readSlot(ctx, wc.addr, slot, ts)
+		if err != nil {
+			return nil, xerrors.Errorf("reading slot %x of %s: %w", slot, wc.ethAddr, err)
+		}
+		values[slot] = v
+
+		if old := wc.values[slot]; old != v {
+			out = append(out, ethtypes.EthStorageSlotChange{
+				Address:  wc.ethAddr,
+				Slot:     slot,
+				OldValue: old,
+				NewValue: v,
+			})
+		}
+	}
+
+	// only commit once all slots were read, so that a failed read is retried
+	// with the next tipset
+	wc.head = head
+	wc.values = values
+
+	return out, nil
+}
@@ -0,0 +1,161 @@
+// stm: #unit
+package full
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestStorageSlotWatch(t *testing.T) {
+	ctx := context.Background()
+
+	c1, err := ethtypes.EthAddressFromFilecoinAddress(mustIDAddr(t, 1000))
+	require.NoError(t, err)
+	c2, err := ethtypes.EthAddressFromFilecoinAddress(mustIDAddr(t, 1001))
+	require.NoError(t, err)
+
+	heads := map[address.Address]cid.Cid{}
+	slots := map[address.Address]map[[32]byte]ethtypes.EthHash{}
+	var reads int
+	var failing address.Address
+
+	set := func(ea ethtypes.EthAddress, slot byte, v byte, head string) {
+		a, err := ea.ToFilecoinAddress()
+		require.NoError(t, err)
+		if slots[a] == nil {
+			slots[a] = map[[32]byte]ethtypes.EthHash{}
+		}
+		var k [32]byte
+		k[31] = slot
+		var val ethtypes.EthHash
+		val[31] = v
+		slots[a][k] = val
+		heads[a] = cid.NewCidV1(cid.Raw, []byte(head))
+	}
+
+	w, err := newStorageSlotWatch(ethtypes.EthStorageSlotsParams{
+		{Address: c1, Slots: []ethtypes.EthBytes{{1}, {2}}},
+		{Address: c2, Slots: []ethtypes.EthBytes{{1}}},
+		{Address: c1, Slots: []ethtypes.EthBytes{{0, 2}}}, // duplicate of slot 2
+	}, func(_ context.Context, a address.Address, slot [32]byte, _ *types.TipSet) (ethtypes.EthHash, error) {
+		if a == failing {
+			return ethtypes.EthHash{}, xerrors.New("read failed")
+		}
+		reads++
+		return slots[a][slot], nil
+	}, func(_ context.Context, a address.Address, _ *types.TipSet) (cid.Cid, error) {
+		return heads[a], nil
+	})
+	require.NoError(t, err)
+	require.Len(t, w.contracts, 2)
+	require.Len(t, w.contracts[0].slots, 2)
+
+	set(c1, 1, 10, "a")
+	set(c2, 1, 20, "b")
+	require.NoError(t, w.init(ctx, nil))
+	require.Equal(t, 3, reads)
+
+	// nothing changed, slots aren't read
+	changes, err := w.changes(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+	require.Equal(t, 3, reads)
+
+	// the contract state changed, but not the watched slots
+	set(c2, 5, 1, "b2")
+	changes, err = w.changes(ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, changes)
+
+	set(c1, 2, 11, "a2")
+	changes, err = w.changes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, c1, changes[0].Address)
+	require.EqualValues(t, 2, changes[0].Slot[31])
+	require.Equal(t, ethtypes.EthHash{}, changes[0].OldValue)
+	require.EqualValues(t, 11, changes[0].NewValue[31])
+
+	// failed reads are retried with the next tipset, without losing changes
+	// of other contracts
+	set(c1, 1, 12, "a3")
+	set(c2, 1, 21, "b3")
+	failing, _ = c2.ToFilecoinAddress()
+	changes, err = w.changes(ctx, nil)
+	require.Error(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, c1, changes[0].Address)
+
+	failing = address.Undef
+	changes, err = w.changes(ctx, nil)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	require.Equal(t, c2, changes[0].Address)
+	require.EqualValues(t, 20, changes[0].OldValue[31])
+
+	_, err = newStorageSlotWatch(ethtypes.EthStorageSlotsParams{{Address: c1}}, nil, nil)
+	require.Error(t, err)
+	_, err = newStorageSlotWatch(ethtypes.EthStorageSlotsParams{{Address: c1, Slots: []ethtypes.EthBytes{make([]byte, 33)}}}, nil, nil)
+	require.Error(t, err)
+}
+
+func TestStorageSlotReads(t *testing.T) {
+	var r storageSlotReads
+	var gets int
+	get := func(v byte) func() (ethtypes.EthHash, error) {
+		return func() (ethtypes.EthHash, error) {
+			gets++
+			var out ethtypes.EthHash
+			out[31] = v
+			return out, nil
+		}
+	}
+
+	ts1 := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	ts2 := mock.TipSet(mock.MkBlock(ts1, 1, 1))
+	a := mustIDAddr(t, 1000)
+	var slot [32]byte
+
+	// subscriptions watching the same slot share the read of each tipset
+	v, err := r.read(a, slot, ts1, get(1))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, v[31])
+	v, err = r.read(a, slot, ts1, get(2))
+	require.NoError(t, err)
+	require.EqualValues(t, 1, v[31])
+	require.Equal(t, 1, gets)
+
+	slot[31] = 1
+	_, err = r.read(a, slot, ts1, get(3))
+	require.NoError(t, err)
+	require.Equal(t, 2, gets)
+
+	// slots are read again at the next tipset
+	v, err = r.read(a, slot, ts2, get(4))
+	require.NoError(t, err)
+	require.EqualValues(t, 4, v[31])
+	require.Len(t, r.values, 1)
+
+	// failed reads aren't kept
+	_, err = r.read(a, [32]byte{}, ts2, func() (ethtypes.EthHash, error) {
+		return ethtypes.EthHash{}, xerrors.New("read failed")
+	})
+	require.Error(t, err)
+	require.Len(t, r.values, 1)
+}
+
+func mustIDAddr(t *testing.T, id uint64) address.Address {
+	a, err := address.NewIDAddress(id)
+	require.NoError(t, err)
+	return a
+}
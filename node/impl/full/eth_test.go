@@ -1,15 +1,24 @@
 package full
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/go-state-types/big"
 
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/types/mock"
 )
 
 func TestEthLogFromEvent(t *testing.T) {
@@ -161,3 +170,163 @@ func TestRewardPercentiles(t *testing.T) {
 		require.Equal(t, ans, rewards)
 	}
 }
+
+func TestEthBlockForNullRound(t *testing.T) {
+	prev := mock.TipSet(mock.MkBlock(mock.TipSet(mock.MkBlock(nil, 1, 1)), 1, 2))
+
+	blk, err := newEthBlockForNullRound(prev, prev.Height()+2)
+	require.NoError(t, err)
+
+	prevCid, err := prev.Key().Cid()
+	require.NoError(t, err)
+	prevHash, err := ethtypes.EthHashFromCid(prevCid)
+	require.NoError(t, err)
+
+	require.Equal(t, ethtypes.EthUint64(prev.Height()+2), blk.Number)
+	require.Equal(t, prevHash, blk.ParentHash)
+	require.Equal(t, ethtypes.EthUint64(prev.MinTimestamp()+2*build.BlockDelaySecs), blk.Timestamp)
+	require.Equal(t, ethtypes.EmptyRootHash, blk.TransactionsRoot)
+	require.Empty(t, blk.Transactions)
+
+	// stable, and distinct for each null round on top of the same tipset
+	again, err := newEthBlockForNullRound(prev, prev.Height()+2)
+	require.NoError(t, err)
+	require.Equal(t, blk.Hash, again.Hash)
+
+	other, err := newEthBlockForNullRound(prev, prev.Height()+1)
+	require.NoError(t, err)
+	require.NotEqual(t, blk.Hash, other.Hash)
+	require.NotEqual(t, prevHash, other.Hash)
+}
+
+func TestValidEthNullRoundPolicy(t *testing.T) {
+	for _, p := range []string{"", EthNullRoundError, EthNullRoundPrevious, EthNullRoundEmpty} {
+		require.NoError(t, ValidEthNullRoundPolicy(p))
+	}
+	require.Error(t, ValidEthNullRoundPolicy("synthetic"))
+}
+
+func TestEthSyncingFromState(t *testing.T) {
+	genesis := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	head := genesis
+	for i := 0; i < 10; i++ {
+		head = mock.TipSet(mock.MkBlock(head, 1, uint64(i+2)))
+	}
+	headTime := time.Unix(int64(head.MinTimestamp()), 0)
+
+	// caught up, following the chain
+	res := ethSyncingFromState(&api.SyncState{ActiveSyncs: []api.ActiveSync{
+		{WorkerID: 1, Stage: api.StageSyncComplete, Base: genesis, Target: head, Height: head.Height()},
+	}}, head, headTime.Add(time.Duration(build.BlockDelaySecs)*time.Second))
+	require.True(t, res.DoneSync)
+
+	// no active syncs, but the head is stale
+	res = ethSyncingFromState(&api.SyncState{}, head, headTime.Add(100*time.Duration(build.BlockDelaySecs)*time.Second))
+	require.False(t, res.DoneSync)
+	require.Nil(t, res.SyncWorker)
+	require.Equal(t, ethtypes.EthUint64(head.Height()), res.CurrentBlock)
+	require.Equal(t, ethtypes.EthUint64(head.Height()+100), res.HighestBlock)
+
+	// message sync towards a far away target
+	targetBlk := mock.MkBlock(head, 1, 100)
+	targetBlk.Height = head.Height() + 1000
+	target := mock.TipSet(targetBlk)
+	res = ethSyncingFromState(&api.SyncState{ActiveSyncs: []api.ActiveSync{
+		{WorkerID: 1, Stage: api.StageIdle},
+		{WorkerID: 2, Stage: api.StageMessages, Base: genesis, Target: target, Height: head.Height() + 20},
+		{WorkerID: 3, Stage: api.StageSyncErrored, Base: genesis, Target: target},
+	}}, head, headTime)
+	require.False(t, res.DoneSync)
+	require.Equal(t, ethtypes.EthUint64(2), *res.SyncWorker)
+	require.Equal(t, api.StageMessages.String(), res.SyncStage)
+	require.Equal(t, ethtypes.EthUint64(genesis.Height()), res.StartingBlock)
+	require.Equal(t, ethtypes.EthUint64(head.Height()+20), res.CurrentBlock)
+	require.Equal(t, ethtypes.EthUint64(head.Height()+1000), res.HighestBlock)
+}
+
+func TestErrExecutionReverted(t *testing.T) {
+	word := func(v uint64) []byte {
+		w := make([]byte, 32)
+		binary.BigEndian.PutUint64(w[24:], v)
+		return w
+	}
+	wrap := func(data []byte) []byte {
+		var buf bytes.Buffer
+		require.NoError(t, cbg.WriteByteArray(&buf, data))
+		return buf.Bytes()
+	}
+	revert := func(ret []byte) *api.ErrExecutionReverted {
+		return newErrExecutionReverted(&api.InvocResult{
+			MsgRct: &types.MessageReceipt{ExitCode: exitCodeEVMReverted, Return: ret},
+			Error:  "reverted",
+		})
+	}
+
+	msg := "ERC20: insufficient allowance"
+	data := []byte(errorFunctionSelector)
+	data = append(data, word(32)...)
+	data = append(data, word(uint64(len(msg)))...)
+	data = append(data, msg...)
+	data = append(data, make([]byte, 32-len(msg)%32)...)
+
+	e := revert(wrap(data))
+	require.Equal(t, msg, e.Reason)
+	require.Equal(t, ethtypes.EthBytes(data).String(), e.Data)
+	require.Contains(t, e.Error(), "exit 33, revert reason: Error(ERC20: insufficient allowance), vm error: reverted")
+
+	e = revert(wrap(append([]byte(panicFunctionSelector), word(0x11)...)))
+	require.Equal(t, "ArithmeticOverflow()", e.Reason)
+
+	// custom errors are returned raw
+	e = revert(wrap([]byte{0x11, 0x22, 0x33, 0x44}))
+	require.Empty(t, e.Reason)
+	require.Equal(t, "0x11223344", e.Data)
+
+	e = revert(nil)
+	require.Equal(t, "0x", e.Data)
+	require.Contains(t, e.Error(), "revert reason: none")
+
+	// the revert data survives the JSON-RPC error meta roundtrip
+	b, err := e.MarshalJSON()
+	require.NoError(t, err)
+	var out api.ErrExecutionReverted
+	require.NoError(t, out.UnmarshalJSON(b))
+	require.Equal(t, *e, out)
+}
+
+func TestLogsContinuation(t *testing.T) {
+	from := "0x10"
+	spec := &ethtypes.EthFilterSpec{FromBlock: &from}
+	hash, err := ethFilterSpecHash(spec)
+	require.NoError(t, err)
+
+	encode := func(c ethLogsContinuation) string {
+		b, err := json.Marshal(c)
+		require.NoError(t, err)
+		return base64.RawURLEncoding.EncodeToString(b)
+	}
+
+	in := ethLogsContinuation{From: 20, To: 30, Filter: hash}
+	out, err := decodeLogsContinuation(encode(in), hash)
+	require.NoError(t, err)
+	require.Equal(t, in, out)
+
+	// continuations only apply to the filter spec they were returned for
+	other := "0x11"
+	otherHash, err := ethFilterSpecHash(&ethtypes.EthFilterSpec{FromBlock: &other})
+	require.NoError(t, err)
+	require.NotEqual(t, hash, otherHash)
+
+	for _, c := range []string{
+		"not base64!",
+		"bm90IGpzb24",
+		encode(ethLogsContinuation{From: 20, To: 30, Filter: otherHash}),
+		encode(ethLogsContinuation{From: 20, To: 30}),
+		encode(ethLogsContinuation{From: 30, To: 20, Filter: hash}),
+	} {
+		_, err := decodeLogsContinuation(c, hash)
+		var ip *api.ErrInvalidParams
+		require.ErrorAs(t, err, &ip, c)
+		require.Equal(t, "invalid_continuation", ip.Reason)
+	}
+}
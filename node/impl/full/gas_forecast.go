@@ -0,0 +1,161 @@
+package full
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// DefaultBaseFeeForecastEpochs is the number of epochs the base fee is forecast
+// for when none is given.
+const DefaultBaseFeeForecastEpochs = 20
+
+// MaxBaseFeeForecastEpochs is the longest base fee forecast horizon.
+const MaxBaseFeeForecastEpochs = 200
+
+// baseFeeForecastLookback is the number of recent tipsets the block fullness is
+// averaged over.
+const baseFeeForecastLookback = 20
+
+type baseFeeForecastInput struct {
+	head abi.ChainEpoch
+	// baseFee is the base fee of the tipset after the head
+	baseFee abi.TokenAmount
+	epochs  int
+
+	// gasUsed and blocks are the gas limit of the messages included in recent
+	// tipsets, and their number of blocks
+	gasUsed []int64
+	blocks  []int
+
+	// pendingGas is the gas limit of the pending messages which can be included
+	// at the current base fee
+	pendingGas int64
+}
+
+func (a *GasAPI) GasForecastBaseFee(ctx context.Context, epochs int, tsk types.TipSetKey) (*api.BaseFeeForecast, error) {
+	if epochs <= 0 {
+		epochs = DefaultBaseFeeForecastEpochs
+	}
+	if epochs > MaxBaseFeeForecastEpochs {
+		return nil, xerrors.Errorf("can't forecast the base fee more than %d epochs ahead", MaxBaseFeeForecastEpochs)
+	}
+
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	baseFee, err := a.Chain.ComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing base fee: %w", err)
+	}
+
+	in := baseFeeForecastInput{
+		head:    ts.Height(),
+		baseFee: baseFee,
+		epochs:  epochs,
+	}
+
+	for i := 0; i < baseFeeForecastLookback && ts.Height() > 0; i++ {
+		meta, err := a.PriceCache.GetTSGasStats(ctx, a.Chain, ts)
+		if err != nil {
+			return nil, err
+		}
+		var used int64
+		for _, gm := range meta {
+			used += gm.Limit
+		}
+		in.gasUsed = append(in.gasUsed, used)
+		in.blocks = append(in.blocks, len(ts.Blocks()))
+
+		if ts, err = a.Chain.LoadTipSet(ctx, ts.Parents()); err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %w", err)
+		}
+	}
+
+	// the forecast is relative to the current pool, regardless of the tipset
+	pending, _ := a.Mpool.Pending(ctx)
+	for _, sm := range pending {
+		if sm.Message.GasFeeCap.GreaterThanEqual(baseFee) {
+			in.pendingGas += sm.Message.GasLimit
+		}
+	}
+
+	return forecastBaseFee(in), nil
+}
+
+// forecastBaseFee projects the base fee assuming blocks keep being as full as
+// recently, plus the pending messages which don't fit in recent fullness, up to
+// the block gas limit.
+func forecastBaseFee(in baseFeeForecastInput) *api.BaseFeeForecast {
+	out := &api.BaseFeeForecast{
+		Head:       in.head,
+		PendingGas: in.pendingGas,
+	}
+
+	var used int64
+	var blocks int
+	for i := range in.gasUsed {
+		used += in.gasUsed[i]
+		blocks += in.blocks[i]
+	}
+
+	epochBlocks := int(build.BlocksPerEpoch)
+	if len(in.blocks) > 0 && blocks > 0 {
+		epochBlocks = (blocks + len(in.blocks)/2) / len(in.blocks)
+		if epochBlocks < 1 {
+			epochBlocks = 1
+		}
+		out.GasUsedPerBlock = used / int64(blocks)
+	}
+
+	steady := out.GasUsedPerBlock * int64(epochBlocks)
+	maxGas := build.BlockGasLimit * int64(epochBlocks)
+	backlog := in.pendingGas - steady
+	if backlog < 0 {
+		backlog = 0
+	}
+
+	expected, low, high := in.baseFee, in.baseFee, in.baseFee
+	out.Cheapest = in.head + 1
+	cheapest := in.baseFee
+	for i := 1; i <= in.epochs; i++ {
+		epoch := in.head + abi.ChainEpoch(i)
+
+		gasUsed := steady + backlog
+		if gasUsed > maxGas {
+			gasUsed = maxGas
+		}
+		backlog -= gasUsed - steady
+		if backlog < 0 {
+			backlog = 0
+		}
+
+		out.Epochs = append(out.Epochs, api.BaseFeeForecastEpoch{
+			Epoch:   epoch,
+			BaseFee: expected,
+			Low:     low,
+			High:    high,
+			GasUsed: gasUsed,
+		})
+		if expected.LessThan(cheapest) {
+			cheapest = expected
+			out.Cheapest = epoch
+		}
+
+		expected = store.ComputeNextBaseFee(expected, gasUsed, epochBlocks, epoch)
+		low = store.ComputeNextBaseFee(low, 0, epochBlocks, epoch)
+		high = store.ComputeNextBaseFee(high, maxGas, epochBlocks, epoch)
+	}
+
+	out.CheapestBaseFee = cheapest
+	return out
+}
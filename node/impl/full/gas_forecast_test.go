@@ -0,0 +1,53 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/build"
+)
+
+func TestForecastBaseFee(t *testing.T) {
+	const head = abi.ChainEpoch(10_000_000)
+	baseFee := big.NewInt(1_000_000)
+
+	in := baseFeeForecastInput{
+		head:    head,
+		baseFee: baseFee,
+		epochs:  5,
+		gasUsed: []int64{5 * build.BlockGasTarget, 5 * build.BlockGasTarget},
+		blocks:  []int{5, 5},
+	}
+
+	// blocks at the target keep the base fee
+	f := forecastBaseFee(in)
+	require.Len(t, f.Epochs, 5)
+	require.Equal(t, build.BlockGasTarget, f.GasUsedPerBlock)
+	for i, e := range f.Epochs {
+		require.Equal(t, head+abi.ChainEpoch(i+1), e.Epoch)
+		require.Equal(t, baseFee, e.BaseFee)
+		require.True(t, e.Low.LessThanEqual(e.BaseFee))
+		require.True(t, e.High.GreaterThanEqual(e.BaseFee))
+	}
+	require.Equal(t, head+1, f.Cheapest)
+
+	// pending messages fill the blocks until the backlog is included
+	in.pendingGas = 5*build.BlockGasTarget + 5*build.BlockGasTarget
+	f = forecastBaseFee(in)
+	require.Equal(t, 5*build.BlockGasLimit, f.Epochs[0].GasUsed)
+	require.Equal(t, 5*build.BlockGasTarget, f.Epochs[1].GasUsed)
+	require.True(t, f.Epochs[1].BaseFee.GreaterThan(baseFee))
+	require.Equal(t, f.Epochs[1].BaseFee, f.Epochs[4].BaseFee)
+
+	// empty blocks lower the base fee
+	in.gasUsed = []int64{0, 0}
+	in.pendingGas = 0
+	f = forecastBaseFee(in)
+	require.Equal(t, head+5, f.Cheapest)
+	require.Equal(t, f.Epochs[4].Low, f.CheapestBaseFee)
+}
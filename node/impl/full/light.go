@@ -0,0 +1,118 @@
+package full
+
+import (
+	"context"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// LightChainModule serves the chain on light clients: tipsets come from the
+// block headers synced locally, and messages and other objects, which aren't
+// synced, from the upstream node.
+type LightChainModule struct {
+	fx.In
+
+	Local    ChainModule
+	Upstream api.Gateway
+}
+
+var _ ChainModuleAPI = (*LightChainModule)(nil)
+
+func (m *LightChainModule) ChainNotify(ctx context.Context) (<-chan []*api.HeadChange, error) {
+	return m.Local.ChainNotify(ctx)
+}
+
+func (m *LightChainModule) ChainNotifyAtConfidence(ctx context.Context, depth abi.ChainEpoch) (<-chan []*api.HeadChange, error) {
+	return m.Local.ChainNotifyAtConfidence(ctx, depth)
+}
+
+func (m *LightChainModule) ChainHead(ctx context.Context) (*types.TipSet, error) {
+	return m.Local.ChainHead(ctx)
+}
+
+func (m *LightChainModule) ChainGetTipSet(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	return m.Local.ChainGetTipSet(ctx, tsk)
+}
+
+func (m *LightChainModule) ChainGetTipSetByHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error) {
+	return m.Local.ChainGetTipSetByHeight(ctx, h, tsk)
+}
+
+func (m *LightChainModule) ChainGetTipSetAfterHeight(ctx context.Context, h abi.ChainEpoch, tsk types.TipSetKey) (*types.TipSet, error) {
+	return m.Local.ChainGetTipSetAfterHeight(ctx, h, tsk)
+}
+
+func (m *LightChainModule) ChainGetPath(ctx context.Context, from, to types.TipSetKey) ([]*api.HeadChange, error) {
+	return m.Local.ChainGetPath(ctx, from, to)
+}
+
+func (m *LightChainModule) ChainGetBlockMessages(ctx context.Context, blk cid.Cid) (*api.BlockMessages, error) {
+	return m.Upstream.ChainGetBlockMessages(ctx, blk)
+}
+
+func (m *LightChainModule) ChainGetMessage(ctx context.Context, mc cid.Cid) (*types.Message, error) {
+	return m.Upstream.ChainGetMessage(ctx, mc)
+}
+
+func (m *LightChainModule) ChainHasObj(ctx context.Context, c cid.Cid) (bool, error) {
+	return m.Upstream.ChainHasObj(ctx, c)
+}
+
+func (m *LightChainModule) ChainReadObj(ctx context.Context, c cid.Cid) ([]byte, error) {
+	return m.Upstream.ChainReadObj(ctx, c)
+}
+
+// LightStateModule serves the state on light clients from the upstream node.
+// Actor proofs are checked against the state roots of the headers synced
+// locally, so that the actors they prove can be trusted.
+type LightStateModule struct {
+	fx.In
+
+	api.Gateway
+
+	Chain *store.ChainStore
+}
+
+var _ StateModuleAPI = (*LightStateModule)(nil)
+
+func (m *LightStateModule) StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*api.ActorProof, error) {
+	ts, err := m.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	proof, err := m.Gateway.StateGetProof(ctx, actor, ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	if proof.StateRoot != ts.ParentState() {
+		return nil, xerrors.Errorf("upstream proof against state root %s, expected %s", proof.StateRoot, ts.ParentState())
+	}
+
+	blks := make([]blocks.Block, len(proof.Blocks))
+	for i, b := range proof.Blocks {
+		if blks[i], err = blocks.NewBlockWithCid(b.Data, b.Cid); err != nil {
+			return nil, xerrors.Errorf("invalid proof block: %w", err)
+		}
+	}
+	act, err := state.VerifyActorProof(ctx, ts.ParentState(), actor, blks)
+	if err != nil {
+		return nil, xerrors.Errorf("verifying upstream proof: %w", err)
+	}
+
+	proof.Address = actor
+	proof.TipSet = ts.Key()
+	proof.Actor = act
+	return proof, nil
+}
@@ -0,0 +1,275 @@
+package full
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// terms expiring sooner than this get a warning
+const beneficiaryExpiryWarnEpochs = 7 * builtin.EpochsInDay
+
+func (a *StateAPI) minerInfoAt(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (miner.MinerInfo, *types.TipSet, error) {
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return miner.MinerInfo{}, nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, err := a.StateManager.LoadActor(ctx, maddr, ts)
+	if err != nil {
+		return miner.MinerInfo{}, nil, xerrors.Errorf("failed to load miner actor: %w", err)
+	}
+
+	mas, err := miner.Load(a.StateManager.ChainStore().ActorStore(ctx), act)
+	if err != nil {
+		return miner.MinerInfo{}, nil, xerrors.Errorf("failed to load miner actor state: %w", err)
+	}
+
+	info, err := mas.Info()
+	if err != nil {
+		return miner.MinerInfo{}, nil, xerrors.Errorf("loading miner info: %w", err)
+	}
+
+	return info, ts, nil
+}
+
+// actorCode returns the code of the actor at addr, and its ID address. Both
+// are undefined when the actor doesn't exist.
+func (a *StateAPI) actorCode(ctx context.Context, addr address.Address, ts *types.TipSet) (address.Address, cid.Cid, error) {
+	id, err := a.StateManager.LookupID(ctx, addr, ts)
+	if xerrors.Is(err, types.ErrActorNotFound) {
+		return address.Undef, cid.Undef, nil
+	}
+	if err != nil {
+		return address.Undef, cid.Undef, xerrors.Errorf("looking up %s: %w", addr, err)
+	}
+
+	act, err := a.StateManager.LoadActor(ctx, id, ts)
+	if xerrors.Is(err, types.ErrActorNotFound) {
+		return address.Undef, cid.Undef, nil
+	}
+	if err != nil {
+		return address.Undef, cid.Undef, xerrors.Errorf("loading actor %s: %w", addr, err)
+	}
+
+	return id, act.Code, nil
+}
+
+func (a *StateAPI) StateMinerPendingChanges(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (*api.MinerPendingChanges, error) {
+	info, ts, err := a.minerInfoAt(ctx, maddr, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	return pendingChanges(info, ts.Height()), nil
+}
+
+func (a *StateAPI) StateMinerCheckBeneficiaryChange(ctx context.Context, maddr address.Address, params minertypes.ChangeBeneficiaryParams, tsk types.TipSetKey) (*api.AddressChangeCheck, error) {
+	info, ts, err := a.minerInfoAt(ctx, maddr, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	id, code, err := a.actorCode(ctx, params.NewBeneficiary, ts)
+	if err != nil {
+		return nil, err
+	}
+	if id != address.Undef {
+		params.NewBeneficiary = id
+	}
+
+	return checkBeneficiaryChange(info, ts.Height(), params, code), nil
+}
+
+func (a *StateAPI) StateMinerCheckOwnerChange(ctx context.Context, maddr address.Address, newOwner address.Address, tsk types.TipSetKey) (*api.AddressChangeCheck, error) {
+	info, ts, err := a.minerInfoAt(ctx, maddr, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	id, code, err := a.actorCode(ctx, newOwner, ts)
+	if err != nil {
+		return nil, err
+	}
+	if id != address.Undef {
+		newOwner = id
+	}
+
+	return checkOwnerChange(info, newOwner, code), nil
+}
+
+func beneficiaryTermStatus(t miner.BeneficiaryTerm, epoch abi.ChainEpoch) api.BeneficiaryTermStatus {
+	st := api.BeneficiaryTermStatus{
+		Quota:      t.Quota,
+		UsedQuota:  t.UsedQuota,
+		Available:  big.Zero(),
+		Expiration: t.Expiration,
+		Expired:    t.Expiration <= epoch,
+	}
+	if !st.Expired && t.Quota.GreaterThan(t.UsedQuota) {
+		st.Available = big.Sub(t.Quota, t.UsedQuota)
+	}
+	return st
+}
+
+func pendingChanges(info miner.MinerInfo, epoch abi.ChainEpoch) *api.MinerPendingChanges {
+	out := &api.MinerPendingChanges{
+		Epoch:             epoch,
+		Owner:             info.Owner,
+		PendingOwner:      info.PendingOwnerAddress,
+		Worker:            info.Worker,
+		WorkerChangeEpoch: -1,
+		Beneficiary:       info.Beneficiary,
+		BeneficiaryTerm:   beneficiaryTermStatus(info.BeneficiaryTerm, epoch),
+	}
+
+	if info.PendingWorkerKey != nil {
+		nw := info.PendingWorkerKey.NewWorker
+		out.NewWorker = &nw
+		out.WorkerChangeEpoch = info.PendingWorkerKey.EffectiveAt
+	}
+
+	// an owner beneficiary has no term
+	if info.Beneficiary != info.Owner {
+		bt := out.BeneficiaryTerm
+		switch {
+		case bt.Expired:
+			out.Warnings = append(out.Warnings, fmt.Sprintf("beneficiary term expired at epoch %d, no funds can be withdrawn until a new term is approved", bt.Expiration))
+		case bt.Available.IsZero():
+			out.Warnings = append(out.Warnings, "beneficiary quota is used up, no funds can be withdrawn until a new term is approved")
+		case bt.Expiration-epoch < beneficiaryExpiryWarnEpochs:
+			out.Warnings = append(out.Warnings, fmt.Sprintf("beneficiary term expires in %d epochs", bt.Expiration-epoch))
+		}
+	}
+
+	if pt := info.PendingBeneficiaryTerm; pt != nil {
+		ps := &api.PendingBeneficiaryStatus{
+			NewBeneficiary:        pt.NewBeneficiary,
+			NewQuota:              pt.NewQuota,
+			NewExpiration:         pt.NewExpiration,
+			ApprovedByBeneficiary: pt.ApprovedByBeneficiary,
+			ApprovedByNominee:     pt.ApprovedByNominee,
+		}
+		if !pt.ApprovedByBeneficiary {
+			ps.AwaitingApproval = append(ps.AwaitingApproval, info.Beneficiary)
+		}
+		if !pt.ApprovedByNominee && pt.NewBeneficiary != info.Beneficiary {
+			ps.AwaitingApproval = append(ps.AwaitingApproval, pt.NewBeneficiary)
+		}
+		out.PendingBeneficiary = ps
+
+		if pt.NewBeneficiary != info.Owner && pt.NewExpiration <= epoch {
+			out.Warnings = append(out.Warnings, fmt.Sprintf("pending beneficiary term expired at epoch %d, approving it wouldn't allow any withdrawals", pt.NewExpiration))
+		}
+	}
+
+	return out
+}
+
+// principal actors can send messages, which is required to approve changes
+func isPrincipal(code cid.Cid) bool {
+	return builtin.IsAccountActor(code) || builtin.IsMultisigActor(code) || builtin.IsEthAccountActor(code)
+}
+
+func checkAddressActor(check *api.AddressChangeCheck, role string, addr address.Address, code cid.Cid) {
+	switch {
+	case !code.Defined():
+		check.Errors = append(check.Errors, fmt.Sprintf("%s %s doesn't exist on chain", role, addr))
+	case builtin.IsPlaceholderActor(code):
+		check.Warnings = append(check.Warnings, fmt.Sprintf("%s %s has never sent a message, make sure its key can sign", role, addr))
+	case !isPrincipal(code):
+		check.Errors = append(check.Errors, fmt.Sprintf("%s %s must be an account or multisig actor", role, addr))
+	}
+}
+
+func checkBeneficiaryChange(info miner.MinerInfo, epoch abi.ChainEpoch, params minertypes.ChangeBeneficiaryParams, code cid.Cid) *api.AddressChangeCheck {
+	check := &api.AddressChangeCheck{}
+	nb := params.NewBeneficiary
+	if params.NewQuota.Int == nil {
+		params.NewQuota = big.Zero()
+	}
+
+	if nb == info.Owner {
+		if !params.NewQuota.IsZero() {
+			check.Errors = append(check.Errors, fmt.Sprintf("quota must be zero when changing the beneficiary to the owner, got %s", types.FIL(params.NewQuota)))
+		}
+		if params.NewExpiration != 0 {
+			check.Errors = append(check.Errors, fmt.Sprintf("expiration must be zero when changing the beneficiary to the owner, got %d", params.NewExpiration))
+		}
+		if info.Beneficiary == info.Owner {
+			check.Errors = append(check.Errors, fmt.Sprintf("beneficiary is already the owner %s", info.Owner))
+		}
+	} else {
+		checkAddressActor(check, "beneficiary", nb, code)
+
+		if !params.NewQuota.GreaterThan(big.Zero()) {
+			check.Errors = append(check.Errors, "quota must be positive")
+		}
+
+		switch {
+		case params.NewExpiration <= epoch:
+			check.Errors = append(check.Errors, fmt.Sprintf("expiration %d isn't after the current epoch %d, the beneficiary would never be able to withdraw", params.NewExpiration, epoch))
+		case params.NewExpiration-epoch < beneficiaryExpiryWarnEpochs:
+			check.Warnings = append(check.Warnings, fmt.Sprintf("term expires in %d epochs, less than the time it may take to get the change approved", params.NewExpiration-epoch))
+		}
+
+		// the used quota is carried over when the beneficiary stays the same
+		if nb == info.Beneficiary && params.NewQuota.GreaterThan(big.Zero()) && !params.NewQuota.GreaterThan(info.BeneficiaryTerm.UsedQuota) {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("quota %s doesn't exceed the %s already used by this beneficiary, nothing could be withdrawn", types.FIL(params.NewQuota), types.FIL(info.BeneficiaryTerm.UsedQuota)))
+		}
+
+		if nb == info.Worker {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("new beneficiary %s is the worker address, which is usually a hot wallet", nb))
+		}
+		for _, ca := range info.ControlAddresses {
+			if nb == ca {
+				check.Warnings = append(check.Warnings, fmt.Sprintf("new beneficiary %s is a control address, which is usually a hot wallet", nb))
+			}
+		}
+	}
+
+	if pt := info.PendingBeneficiaryTerm; pt != nil {
+		if pt.NewBeneficiary == nb && pt.NewQuota.Equals(params.NewQuota) && pt.NewExpiration == params.NewExpiration {
+			check.Warnings = append(check.Warnings, "an identical change is already pending, it only needs to be confirmed")
+		} else {
+			check.Warnings = append(check.Warnings, fmt.Sprintf("replaces the pending change to %s (quota %s, expiration %d)", pt.NewBeneficiary, types.FIL(pt.NewQuota), pt.NewExpiration))
+		}
+	}
+
+	return check
+}
+
+func checkOwnerChange(info miner.MinerInfo, newOwner address.Address, code cid.Cid) *api.AddressChangeCheck {
+	check := &api.AddressChangeCheck{}
+
+	if newOwner == info.Owner {
+		check.Errors = append(check.Errors, fmt.Sprintf("%s is already the owner", newOwner))
+		return check
+	}
+
+	checkAddressActor(check, "new owner", newOwner, code)
+
+	if newOwner == info.Worker {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("new owner %s is the worker address, which is usually a hot wallet", newOwner))
+	}
+	if info.Beneficiary == info.Owner {
+		check.Warnings = append(check.Warnings, "the beneficiary is the owner, it will move to the new owner together with all future withdrawals")
+	}
+	if po := info.PendingOwnerAddress; po != nil && *po != newOwner {
+		check.Warnings = append(check.Warnings, fmt.Sprintf("replaces the pending owner change to %s", *po))
+	}
+
+	return check
+}
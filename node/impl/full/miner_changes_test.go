@@ -0,0 +1,144 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	builtin0 "github.com/filecoin-project/specs-actors/actors/builtin"
+
+	"github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+func TestPendingChanges(t *testing.T) {
+	owner, _ := address.NewIDAddress(100)
+	worker, _ := address.NewIDAddress(101)
+	ben, _ := address.NewIDAddress(102)
+	nominee, _ := address.NewIDAddress(103)
+
+	info := miner.MinerInfo{
+		Owner:       owner,
+		Worker:      worker,
+		Beneficiary: ben,
+		BeneficiaryTerm: miner.BeneficiaryTerm{
+			Quota:      types.FromFil(10),
+			UsedQuota:  types.FromFil(4),
+			Expiration: 100_000,
+		},
+		PendingOwnerAddress: &nominee,
+		PendingBeneficiaryTerm: &miner.PendingBeneficiaryChange{
+			NewBeneficiary:    nominee,
+			NewQuota:          types.FromFil(5),
+			NewExpiration:     50,
+			ApprovedByNominee: true,
+		},
+	}
+
+	pc := pendingChanges(info, 1000)
+	require.Equal(t, &nominee, pc.PendingOwner)
+	require.Nil(t, pc.NewWorker)
+	require.Equal(t, abi.ChainEpoch(-1), pc.WorkerChangeEpoch)
+	require.Equal(t, types.FromFil(6), pc.BeneficiaryTerm.Available)
+	require.False(t, pc.BeneficiaryTerm.Expired)
+	require.Equal(t, []address.Address{ben}, pc.PendingBeneficiary.AwaitingApproval)
+	require.Len(t, pc.Warnings, 1) // pending term already expired
+
+	pc = pendingChanges(info, 100_000)
+	require.True(t, pc.BeneficiaryTerm.Expired)
+	require.True(t, pc.BeneficiaryTerm.Available.IsZero())
+	require.Len(t, pc.Warnings, 2)
+}
+
+func TestCheckBeneficiaryChange(t *testing.T) {
+	owner, _ := address.NewIDAddress(100)
+	worker, _ := address.NewIDAddress(101)
+	ben, _ := address.NewIDAddress(102)
+
+	info := miner.MinerInfo{
+		Owner:       owner,
+		Worker:      worker,
+		Beneficiary: owner,
+		BeneficiaryTerm: miner.BeneficiaryTerm{
+			Quota:     big.Zero(),
+			UsedQuota: big.Zero(),
+		},
+	}
+
+	account := builtin0.AccountActorCodeID
+	epoch := abi.ChainEpoch(1000)
+	params := func(to address.Address, quota abi.TokenAmount, exp abi.ChainEpoch) minertypes.ChangeBeneficiaryParams {
+		return minertypes.ChangeBeneficiaryParams{NewBeneficiary: to, NewQuota: quota, NewExpiration: exp}
+	}
+
+	check := checkBeneficiaryChange(info, epoch, params(ben, types.FromFil(10), 1_000_000), account)
+	require.True(t, check.OK())
+	require.Empty(t, check.Warnings)
+
+	// zero quota, and an expiration in the past
+	check = checkBeneficiaryChange(info, epoch, params(ben, big.Zero(), 10), account)
+	require.Len(t, check.Errors, 2)
+
+	// expiring before it's likely to be approved
+	check = checkBeneficiaryChange(info, epoch, params(ben, types.FromFil(10), epoch+10), account)
+	require.True(t, check.OK())
+	require.Len(t, check.Warnings, 1)
+
+	check = checkBeneficiaryChange(info, epoch, params(ben, types.FromFil(10), 1_000_000), cid.Undef)
+	require.False(t, check.OK())
+	check = checkBeneficiaryChange(info, epoch, params(ben, types.FromFil(10), 1_000_000), builtin0.StorageMinerActorCodeID)
+	require.False(t, check.OK())
+
+	check = checkBeneficiaryChange(info, epoch, params(worker, types.FromFil(10), 1_000_000), account)
+	require.True(t, check.OK())
+	require.Len(t, check.Warnings, 1)
+
+	// back to the owner requires an empty term
+	check = checkBeneficiaryChange(info, epoch, params(owner, types.FromFil(1), 5), account)
+	require.Len(t, check.Errors, 3) // quota, expiration, already the owner
+
+	info.Beneficiary = ben
+	info.BeneficiaryTerm = miner.BeneficiaryTerm{Quota: types.FromFil(10), UsedQuota: types.FromFil(8), Expiration: 1_000_000}
+	info.PendingBeneficiaryTerm = &miner.PendingBeneficiaryChange{NewBeneficiary: owner, NewQuota: big.Zero()}
+
+	check = checkBeneficiaryChange(info, epoch, params(owner, big.Zero(), 0), account)
+	require.True(t, check.OK())
+	require.Len(t, check.Warnings, 1) // identical to the pending change
+
+	// used quota carries over for the same beneficiary
+	check = checkBeneficiaryChange(info, epoch, params(ben, types.FromFil(5), 1_000_000), account)
+	require.True(t, check.OK())
+	require.Len(t, check.Warnings, 2) // quota below used, replaces pending
+}
+
+func TestCheckOwnerChange(t *testing.T) {
+	owner, _ := address.NewIDAddress(100)
+	worker, _ := address.NewIDAddress(101)
+	newOwner, _ := address.NewIDAddress(102)
+
+	info := miner.MinerInfo{
+		Owner:       owner,
+		Worker:      worker,
+		Beneficiary: owner,
+	}
+
+	check := checkOwnerChange(info, owner, builtin0.AccountActorCodeID)
+	require.False(t, check.OK())
+
+	check = checkOwnerChange(info, newOwner, builtin0.MultisigActorCodeID)
+	require.True(t, check.OK())
+	require.Len(t, check.Warnings, 1) // beneficiary moves with the owner
+
+	check = checkOwnerChange(info, newOwner, builtin0.StorageMinerActorCodeID)
+	require.False(t, check.OK())
+
+	info.PendingOwnerAddress = &worker
+	check = checkOwnerChange(info, newOwner, builtin0.AccountActorCodeID)
+	require.Len(t, check.Warnings, 2)
+}
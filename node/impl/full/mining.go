@@ -0,0 +1,331 @@
+package full
+
+import (
+	"bytes"
+	"context"
+	"sync"
+
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lotus/chain/beacon"
+	"github.com/filecoin-project/lotus/chain/consensus"
+	"github.com/filecoin-project/lotus/chain/gen"
+	"github.com/filecoin-project/lotus/chain/messagepool"
+	lrand "github.com/filecoin-project/lotus/chain/rand"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+var ErrManualMiningDisabled = xerrors.New("manual mining is only available on devnets running with mock proofs")
+
+// BlockProducer mines the blocks of a devnet on request.
+type BlockProducer interface {
+	// MineBlock mines a block on top of the head, after the given number of
+	// null rounds.
+	MineBlock(ctx context.Context, nulls abi.ChainEpoch) error
+	// Fork sets the head back to the given tipset, the next blocks are mined
+	// on top of it.
+	Fork(ctx context.Context, ts *types.TipSet) error
+}
+
+// ManualMining lets blocks be mined on request through the API. It's only
+// provided by devnets running with mock proofs, which set the block producer
+// once their miner is started.
+type ManualMining struct {
+	lk       sync.RWMutex
+	producer BlockProducer
+}
+
+func (m *ManualMining) SetProducer(p BlockProducer) {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+	m.producer = p
+}
+
+func (m *ManualMining) getProducer() (BlockProducer, error) {
+	if m == nil {
+		return nil, ErrManualMiningDisabled
+	}
+	m.lk.RLock()
+	defer m.lk.RUnlock()
+	if m.producer == nil {
+		return nil, ErrManualMiningDisabled
+	}
+	return m.producer, nil
+}
+
+type MiningAPI struct {
+	fx.In
+
+	Chain        *store.ChainStore
+	StateManager *stmgr.StateManager
+	ManualMining *ManualMining `optional:"true"`
+
+	Wallet        api.Wallet
+	Beacon        beacon.Schedule
+	ProofVerifier storiface.Verifier
+	Consensus     consensus.Consensus
+	Mpool         *messagepool.MessagePool `optional:"true"`
+}
+
+func (a *MiningAPI) MineOne(ctx context.Context) (*types.TipSet, error) {
+	return a.mine(ctx, 0)
+}
+
+func (a *MiningAPI) AdvanceEpochs(ctx context.Context, epochs abi.ChainEpoch) (*types.TipSet, error) {
+	if epochs < 1 {
+		return nil, xerrors.Errorf("invalid number of epochs %d", epochs)
+	}
+	return a.mine(ctx, epochs-1)
+}
+
+func (a *MiningAPI) mine(ctx context.Context, nulls abi.ChainEpoch) (*types.TipSet, error) {
+	p, err := a.ManualMining.getProducer()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.MineBlock(ctx, nulls); err != nil {
+		return nil, xerrors.Errorf("mining block: %w", err)
+	}
+	return a.Chain.GetHeaviestTipSet(), nil
+}
+
+func (a *MiningAPI) ChainFork(ctx context.Context, tsk types.TipSetKey) (*types.TipSet, error) {
+	p, err := a.ManualMining.getProducer()
+	if err != nil {
+		return nil, err
+	}
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+	if err := p.Fork(ctx, ts); err != nil {
+		return nil, xerrors.Errorf("forking chain: %w", err)
+	}
+	return a.Chain.GetHeaviestTipSet(), nil
+}
+
+func (a *MiningAPI) StatePatch(ctx context.Context, patches []api.ActorPatch) (*types.TipSet, error) {
+	p, err := a.ManualMining.getProducer()
+	if err != nil {
+		return nil, err
+	}
+
+	// check the actors exist now, so that patches can't fail when applied
+	// and break the chain
+	head := a.Chain.GetHeaviestTipSet()
+	root, _, err := a.StateManager.TipSetState(ctx, head)
+	if err != nil {
+		return nil, xerrors.Errorf("computing head state: %w", err)
+	}
+	st, err := a.StateManager.StateTree(root)
+	if err != nil {
+		return nil, err
+	}
+	for _, patch := range patches {
+		if _, err := st.GetActor(patch.Address); err != nil {
+			return nil, xerrors.Errorf("loading actor %s: %w", patch.Address, err)
+		}
+		if patch.Balance != nil && patch.Balance.Sign() < 0 {
+			return nil, xerrors.Errorf("negative balance for actor %s", patch.Address)
+		}
+	}
+
+	a.StateManager.AddStatePatch(head.Height(), func(ctx context.Context, st *state.StateTree) error {
+		return applyActorPatches(st, patches)
+	})
+
+	if err := p.MineBlock(ctx, 0); err != nil {
+		return nil, xerrors.Errorf("mining block: %w", err)
+	}
+	return a.Chain.GetHeaviestTipSet(), nil
+}
+
+func applyActorPatches(st *state.StateTree, patches []api.ActorPatch) error {
+	for _, patch := range patches {
+		act, err := st.GetActor(patch.Address)
+		if err != nil {
+			log.Warnw("skipping state patch of missing actor", "actor", patch.Address, "error", err)
+			continue
+		}
+
+		if patch.Balance != nil {
+			act.Balance = *patch.Balance
+		}
+		if patch.Nonce != nil {
+			act.Nonce = *patch.Nonce
+		}
+		if patch.Head != nil {
+			act.Head = *patch.Head
+		}
+
+		if err := st.SetActor(patch.Address, act); err != nil {
+			return xerrors.Errorf("setting actor %s: %w", patch.Address, err)
+		}
+	}
+	return nil
+}
+
+func (a *MiningAPI) MinerCreateBlockDryRun(ctx context.Context, maddr address.Address, tsk types.TipSetKey) (*api.MinerBlockDryRun, error) {
+	if a.Mpool == nil {
+		return nil, xerrors.Errorf("block production dry-run needs the message pool, which lite nodes don't run")
+	}
+
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	round := dryRunRound(ts, uint64(build.Clock.Now().Unix()))
+	out := &api.MinerBlockDryRun{
+		Round:       round,
+		BlockReward: big.Zero(),
+		GasReward:   big.Zero(),
+	}
+
+	mbi, err := stmgr.MinerGetBaseInfo(ctx, a.StateManager, a.Beacon, ts.Key(), round, maddr, a.ProofVerifier)
+	if err != nil {
+		return nil, xerrors.Errorf("getting mining base info: %w", err)
+	}
+	if mbi == nil || !mbi.EligibleForMining {
+		return out, nil
+	}
+	out.EligibleForMining = true
+
+	rbase := mbi.PrevBeaconEntry
+	if len(mbi.BeaconEntries) > 0 {
+		rbase = mbi.BeaconEntries[len(mbi.BeaconEntries)-1]
+	}
+
+	buf := new(bytes.Buffer)
+	if err := maddr.MarshalCBOR(buf); err != nil {
+		return nil, xerrors.Errorf("marshaling miner address: %w", err)
+	}
+
+	sign := func(ctx context.Context, addr address.Address, data []byte) (*crypto.Signature, error) {
+		return a.Wallet.WalletSign(ctx, addr, data, api.MsgMeta{Type: api.MTUnknown})
+	}
+
+	// same as the miner, see miner.computeTicket and gen.IsRoundWinner
+	ticketEntropy := append([]byte{}, buf.Bytes()...)
+	if round > build.UpgradeSmokeHeight {
+		ticketEntropy = append(ticketEntropy, ts.MinTicket().VRFProof...)
+	}
+	ticketRand, err := lrand.DrawRandomness(rbase.Data, crypto.DomainSeparationTag_TicketProduction, round-build.TicketRandomnessLookback, ticketEntropy)
+	if err != nil {
+		return nil, xerrors.Errorf("drawing ticket randomness: %w", err)
+	}
+	ticketProof, err := gen.ComputeVRF(ctx, sign, mbi.WorkerKey, ticketRand)
+	if err != nil {
+		return nil, xerrors.Errorf("computing ticket: %w", err)
+	}
+	ticket := &types.Ticket{VRFProof: ticketProof}
+
+	electionRand, err := lrand.DrawRandomness(rbase.Data, crypto.DomainSeparationTag_ElectionProofProduction, round, buf.Bytes())
+	if err != nil {
+		return nil, xerrors.Errorf("drawing election randomness: %w", err)
+	}
+	electionProof, err := gen.ComputeVRF(ctx, sign, mbi.WorkerKey, electionRand)
+	if err != nil {
+		return nil, xerrors.Errorf("computing election proof: %w", err)
+	}
+	eproof := &types.ElectionProof{VRFProof: electionProof}
+	eproof.WinCount = eproof.ComputeWinCount(mbi.MinerPower, mbi.NetworkPower)
+	out.WinCount = eproof.WinCount
+
+	msgs, err := a.Mpool.SelectMessages(ctx, ts, ticket.Quality())
+	if err != nil {
+		return nil, xerrors.Errorf("selecting messages: %w", err)
+	}
+
+	// assemble the block of a single win when the miner doesn't win the round
+	wins := eproof.WinCount
+	if wins < 1 {
+		eproof = &types.ElectionProof{WinCount: 1, VRFProof: electionProof}
+		wins = 1
+	}
+
+	fblk, err := a.Consensus.CreateBlock(ctx, a.Wallet, &api.BlockTemplate{
+		Miner:        maddr,
+		Parents:      ts.Key(),
+		Ticket:       ticket,
+		Eproof:       eproof,
+		BeaconValues: mbi.BeaconEntries,
+		Messages:     msgs,
+		Epoch:        round,
+		Timestamp:    ts.MinTimestamp() + build.BlockDelaySecs*uint64(round-ts.Height()),
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("creating block: %w", err)
+	}
+
+	out.Block = &types.BlockMsg{Header: fblk.Header}
+	for _, msg := range fblk.BlsMessages {
+		out.Block.BlsMessages = append(out.Block.BlsMessages, msg.Cid())
+	}
+	for _, msg := range fblk.SecpkMessages {
+		out.Block.SecpkMessages = append(out.Block.SecpkMessages, msg.Cid())
+	}
+
+	out.GasLimit, out.GasReward = projectedGasReward(fblk, fblk.Header.ParentBaseFee)
+
+	ract, err := a.StateManager.LoadActorRaw(ctx, reward.Address, fblk.Header.ParentStateRoot)
+	if err != nil {
+		return nil, xerrors.Errorf("loading reward actor: %w", err)
+	}
+	rst, err := reward.Load(a.StateManager.ChainStore().ActorStore(ctx), ract)
+	if err != nil {
+		return nil, xerrors.Errorf("loading reward actor state: %w", err)
+	}
+	epochReward, err := rst.ThisEpochReward()
+	if err != nil {
+		return nil, xerrors.Errorf("getting epoch reward: %w", err)
+	}
+	out.BlockReward = big.Div(big.Mul(epochReward, big.NewInt(wins)), big.NewIntUnsigned(build.BlocksPerEpoch))
+
+	return out, nil
+}
+
+// dryRunRound returns the round being mined at the given time on top of the
+// tipset, after the null rounds since it.
+func dryRunRound(ts *types.TipSet, now uint64) abi.ChainEpoch {
+	round := ts.Height() + 1
+	if now > ts.MinTimestamp() {
+		if elapsed := abi.ChainEpoch((now - ts.MinTimestamp()) / build.BlockDelaySecs); elapsed > 1 {
+			round = ts.Height() + elapsed
+		}
+	}
+	return round
+}
+
+// projectedGasReward returns the total gas limit of the block messages, and
+// the premiums they pay the miner at the base fee.
+func projectedGasReward(fblk *types.FullBlock, baseFee abi.TokenAmount) (int64, abi.TokenAmount) {
+	var gasLimit int64
+	gasReward := big.Zero()
+
+	add := func(msg *types.Message) {
+		gasLimit += msg.GasLimit
+		gasReward = big.Add(gasReward, big.Mul(msg.EffectiveGasPremium(baseFee), big.NewInt(msg.GasLimit)))
+	}
+	for _, msg := range fblk.BlsMessages {
+		add(msg)
+	}
+	for _, msg := range fblk.SecpkMessages {
+		add(&msg.Message)
+	}
+
+	return gasLimit, gasReward
+}
@@ -0,0 +1,139 @@
+// stm: #unit
+package full
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/consensus/filcns"
+	"github.com/filecoin-project/lotus/chain/state"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestManualMining(t *testing.T) {
+	ctx := context.Background()
+	bs := blockstore.NewMemory()
+	cs := store.NewChainStore(bs, bs, datastore.NewMapDatastore(), filcns.Weight, nil)
+
+	// not a devnet
+	a := &MiningAPI{Chain: cs}
+	_, err := a.MineOne(ctx)
+	require.ErrorIs(t, err, ErrManualMiningDisabled)
+
+	// devnet without a miner yet
+	a.ManualMining = &ManualMining{}
+	_, err = a.AdvanceEpochs(ctx, 1)
+	require.ErrorIs(t, err, ErrManualMiningDisabled)
+
+	p := &testProducer{}
+	a.ManualMining.SetProducer(p)
+
+	_, err = a.MineOne(ctx)
+	require.NoError(t, err)
+	_, err = a.AdvanceEpochs(ctx, 5)
+	require.NoError(t, err)
+	_, err = a.AdvanceEpochs(ctx, 0)
+	require.Error(t, err)
+	require.Equal(t, []abi.ChainEpoch{0, 4}, p.mined)
+}
+
+type testProducer struct {
+	mined []abi.ChainEpoch
+}
+
+func (p *testProducer) MineBlock(ctx context.Context, nulls abi.ChainEpoch) error {
+	p.mined = append(p.mined, nulls)
+	return nil
+}
+
+func (p *testProducer) Fork(ctx context.Context, ts *types.TipSet) error {
+	return nil
+}
+
+func TestApplyActorPatches(t *testing.T) {
+	cst := cbor.NewMemCborStore()
+	st, err := state.NewStateTree(cst, types.StateTreeVersion5)
+	require.NoError(t, err)
+
+	a1, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+	a2, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	missing, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+
+	head, err := cst.Put(context.Background(), "head")
+	require.NoError(t, err)
+	newHead, err := cst.Put(context.Background(), "new head")
+	require.NoError(t, err)
+
+	require.NoError(t, st.SetActor(a1, &types.Actor{Code: head, Head: head, Balance: types.NewInt(1), Nonce: 1}))
+	require.NoError(t, st.SetActor(a2, &types.Actor{Code: head, Head: head, Balance: types.NewInt(1), Nonce: 1}))
+
+	balance := types.NewInt(100)
+	nonce := uint64(7)
+	require.NoError(t, applyActorPatches(st, []api.ActorPatch{
+		{Address: a1, Balance: &balance},
+		{Address: a2, Nonce: &nonce, Head: &newHead},
+		{Address: missing, Balance: &balance},
+	}))
+
+	act, err := st.GetActor(a1)
+	require.NoError(t, err)
+	require.Equal(t, balance, act.Balance)
+	require.Equal(t, uint64(1), act.Nonce)
+	require.Equal(t, head, act.Head)
+
+	act, err = st.GetActor(a2)
+	require.NoError(t, err)
+	require.Equal(t, types.NewInt(1), act.Balance)
+	require.Equal(t, nonce, act.Nonce)
+	require.Equal(t, newHead, act.Head)
+
+	_, err = st.GetActor(missing)
+	require.Error(t, err)
+}
+
+func TestDryRunRound(t *testing.T) {
+	ts := mock.TipSet(mock.MkBlock(mock.TipSet(mock.MkBlock(nil, 1, 1)), 1, 1))
+	start := ts.MinTimestamp()
+
+	require.Equal(t, ts.Height()+1, dryRunRound(ts, start-1))
+	require.Equal(t, ts.Height()+1, dryRunRound(ts, start))
+	require.Equal(t, ts.Height()+1, dryRunRound(ts, start+build.BlockDelaySecs))
+	require.Equal(t, ts.Height()+1, dryRunRound(ts, start+2*build.BlockDelaySecs-1))
+	// the miner didn't win the previous rounds
+	require.Equal(t, ts.Height()+2, dryRunRound(ts, start+2*build.BlockDelaySecs))
+	require.Equal(t, ts.Height()+5, dryRunRound(ts, start+5*build.BlockDelaySecs+1))
+}
+
+func TestProjectedGasReward(t *testing.T) {
+	baseFee := abi.NewTokenAmount(100)
+	fblk := &types.FullBlock{
+		BlsMessages: []*types.Message{
+			{GasLimit: 1000, GasFeeCap: abi.NewTokenAmount(200), GasPremium: abi.NewTokenAmount(10)},
+			// premium capped at fee cap - base fee
+			{GasLimit: 2000, GasFeeCap: abi.NewTokenAmount(105), GasPremium: abi.NewTokenAmount(10)},
+		},
+		SecpkMessages: []*types.SignedMessage{
+			// fee cap below the base fee
+			{Message: types.Message{GasLimit: 3000, GasFeeCap: abi.NewTokenAmount(50), GasPremium: abi.NewTokenAmount(10)}},
+		},
+	}
+
+	gasLimit, gasReward := projectedGasReward(fblk, baseFee)
+	require.EqualValues(t, 6000, gasLimit)
+	require.Equal(t, abi.NewTokenAmount(1000*10+2000*5), gasReward)
+}
@@ -10,12 +10,16 @@ import (
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/big"
 
 	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/messagesigner"
+	"github.com/filecoin-project/lotus/chain/spendbudget"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/vm"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
 
@@ -49,6 +53,8 @@ type MpoolAPI struct {
 	MessageSigner messagesigner.MsgSigner
 
 	PushLocks *dtypes.MpoolLocker
+
+	SpendBudget *spendbudget.Governor `optional:"true"`
 }
 
 func (a *MpoolAPI) MpoolGetConfig(context.Context) (*types.MpoolConfig, error) {
@@ -59,6 +65,24 @@ func (a *MpoolAPI) MpoolSetConfig(ctx context.Context, cfg *types.MpoolConfig) e
 	return a.Mpool.SetConfig(ctx, cfg)
 }
 
+func (a *MpoolAPI) MpoolSpendBudget(context.Context) (*api.SpendBudget, error) {
+	if a.SpendBudget == nil {
+		return &api.SpendBudget{Windows: []api.SpendBudgetWindow{}, Allowance: big.Zero()}, nil
+	}
+	return a.SpendBudget.Status(), nil
+}
+
+func (a *MpoolAPI) MpoolSpendBudgetOverride(ctx context.Context, allowance abi.TokenAmount) error {
+	if a.SpendBudget == nil {
+		return xerrors.Errorf("no gas spend budget is configured")
+	}
+	if allowance.Nil() || allowance.LessThan(big.Zero()) {
+		return xerrors.Errorf("allowance must not be negative")
+	}
+	a.SpendBudget.SetAllowance(allowance)
+	return nil
+}
+
 func (a *MpoolAPI) MpoolSelect(ctx context.Context, tsk types.TipSetKey, ticketQuality float64) ([]*types.SignedMessage, error) {
 	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
 	if err != nil {
@@ -133,11 +157,83 @@ func (a *MpoolAPI) MpoolClear(ctx context.Context, local bool) error {
 }
 
 func (m *MpoolModule) MpoolPush(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
-	return m.Mpool.Push(ctx, smsg, true)
+	c, err := m.Mpool.Push(ctx, smsg, true)
+	return c, mpoolRejection(err)
 }
 
 func (a *MpoolAPI) MpoolPushUntrusted(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
-	return a.Mpool.PushUntrusted(ctx, smsg)
+	c, err := a.Mpool.PushUntrusted(ctx, smsg)
+	return c, mpoolRejection(err)
+}
+
+// mpoolRejections are the reasons of the rejections of messages by the
+// message pool, sent to clients in api.ErrTransactionRejected errors, and
+// whether pushing the same message again may succeed.
+var mpoolRejections = []struct {
+	err       error
+	reason    string
+	retryable bool
+}{
+	{messagepool.ErrMessageTooBig, "message_too_big", false},
+	{messagepool.ErrNonceTooLow, "nonce_too_low", false},
+	{messagepool.ErrGasFeeCapTooLow, "gas_fee_cap_too_low", false},
+	{messagepool.ErrNotEnoughFunds, "insufficient_funds", false},
+	{messagepool.ErrInvalidToAddr, "invalid_to_address", false},
+	{messagepool.ErrRBFTooLowPremium, "replacement_premium_too_low", false},
+	{messagepool.ErrExistingNonce, "nonce_already_used", false},
+	{messagepool.ErrNonceGap, "nonce_gap", true},
+	{messagepool.ErrTooManyPendingMessages, "too_many_pending_messages", true},
+	{messagepool.ErrSoftValidationFailure, "soft_validation_failure", true},
+	{messagepool.ErrSenderBanned, "sender_banned", true},
+	{messagepool.ErrSenderRateLimited, "sender_rate_limited", true},
+	{messagepool.ErrDuplicateMessage, "duplicate_message", false},
+	{messagepool.ErrTooManyReplacements, "too_many_replacements", false},
+	{messagepool.ErrPremiumTooLowOnPressure, "gas_premium_too_low_under_pressure", true},
+}
+
+// mpoolRejection returns the api.ErrTransactionRejected error of a message
+// pool rejection, and other errors unchanged.
+func mpoolRejection(err error) error {
+	if err == nil {
+		return nil
+	}
+	for _, r := range mpoolRejections {
+		if xerrors.Is(err, r.err) {
+			return api.NewErrTransactionRejected(r.reason, r.retryable, "%w", err)
+		}
+	}
+	return err
+}
+
+// TrackPushedNonce makes the message signer aware of the nonce used by a
+// message which was signed outside of the node, for example by an Ethereum
+// wallet, when the node also holds the key of the sender. Without this, the
+// next message signed by the node could reuse the nonce.
+func (a *MpoolAPI) TrackPushedNonce(ctx context.Context, smsg *types.SignedMessage) error {
+	from := smsg.Message.From
+	has, err := a.WalletHas(ctx, from)
+	if err != nil {
+		return xerrors.Errorf("checking wallet for %s: %w", from, err)
+	}
+	if !has {
+		return nil
+	}
+
+	done, err := a.PushLocks.TakeLock(ctx, from)
+	if err != nil {
+		return xerrors.Errorf("taking lock: %w", err)
+	}
+	defer done()
+
+	next, err := a.MessageSigner.NextNonce(ctx, from)
+	if err != nil {
+		return xerrors.Errorf("getting next nonce: %w", err)
+	}
+	if smsg.Message.Nonce < next {
+		return nil
+	}
+
+	return a.MessageSigner.SaveNonce(ctx, from, smsg.Message.Nonce)
 }
 
 func (a *MpoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spec *api.MessageSendSpec) (*types.SignedMessage, error) {
@@ -215,7 +311,17 @@ func (a *MpoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spe
 
 	requiredFunds := big.Add(msg.Value, msg.RequiredFunds())
 	if b.LessThan(requiredFunds) {
-		return nil, xerrors.Errorf("mpool push: not enough funds: %s < %s", b, requiredFunds)
+		return nil, api.NewErrTransactionRejected("insufficient_funds", false, "mpool push: not enough funds: %s < %s", b, requiredFunds)
+	}
+
+	// Reserve the maximum gas cost of the message from the spend budget, what
+	// it doesn't spend is credited back once it's executed
+	var reservation *spendbudget.Reservation
+	if a.SpendBudget != nil {
+		reservation, err = a.SpendBudget.Spend(ctx, msg.From, msg.RequiredFunds())
+		if err != nil {
+			return nil, xerrors.Errorf("mpool push: %w", err)
+		}
 	}
 
 	// Sign and push the message
@@ -226,8 +332,17 @@ func (a *MpoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spe
 		return nil
 	})
 	if err != nil {
+		if reservation != nil {
+			reservation.Refund()
+		}
 		return nil, err
 	}
+	if reservation != nil {
+		c := signedMsg.Cid()
+		a.SpendBudget.Settle(reservation, func(ctx context.Context) (abi.TokenAmount, error) {
+			return a.executedGasCost(ctx, c)
+		})
+	}
 
 	// Store uuid->signed message in datastore
 	err = a.MessageSigner.StoreSignedMessage(ctx, spec.MsgUuid, signedMsg)
@@ -238,6 +353,28 @@ func (a *MpoolAPI) MpoolPushMessage(ctx context.Context, msg *types.Message, spe
 	return signedMsg, nil
 }
 
+// executedGasCost waits for a message, or the one replacing it, to be executed,
+// and returns the gas it cost its sender.
+func (a *MpoolAPI) executedGasCost(ctx context.Context, c cid.Cid) (abi.TokenAmount, error) {
+	ts, rct, executed, err := a.GasAPI.Stmgr.WaitForMessage(ctx, c, build.MessageConfidence, api.LookbackNoLimit, true)
+	if err != nil {
+		return abi.TokenAmount{}, err
+	}
+	m, err := a.GasAPI.Chain.GetCMessage(ctx, executed)
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Errorf("loading message %s: %w", executed, err)
+	}
+	// the message is applied with the base fee of the tipset including it
+	its, err := a.GasAPI.Chain.LoadTipSet(ctx, ts.Parents())
+	if err != nil {
+		return abi.TokenAmount{}, xerrors.Errorf("loading tipset %s: %w", ts.Parents(), err)
+	}
+
+	vmsg := m.VMMessage()
+	out := vm.ComputeGasOutputs(rct.GasUsed, vmsg.GasLimit, its.Blocks()[0].ParentBaseFee, vmsg.GasFeeCap, vmsg.GasPremium, true)
+	return big.Sum(out.BaseFeeBurn, out.OverEstimationBurn, out.MinerTip), nil
+}
+
 func (a *MpoolAPI) MpoolBatchPush(ctx context.Context, smsgs []*types.SignedMessage) ([]cid.Cid, error) {
 	var messageCids []cid.Cid
 	for _, smsg := range smsgs {
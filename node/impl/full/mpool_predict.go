@@ -0,0 +1,228 @@
+package full
+
+import (
+	"context"
+	"math"
+	gobig "math/big"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// DefaultInclusionEpochs is the horizon inclusion is predicted over when none is given.
+const DefaultInclusionEpochs = 10
+
+// MaxInclusionEpochs is the longest horizon inclusion can be predicted over.
+const MaxInclusionEpochs = 2880
+
+// inclusionTrendLookback is the number of recent tipsets whose base fees and
+// included messages are used for predictions.
+const inclusionTrendLookback = 20
+
+type inclusionInput struct {
+	msg cid.Cid
+
+	gasFeeCap  abi.TokenAmount
+	gasPremium abi.TokenAmount
+	gasLimit   int64
+
+	epochs abi.ChainEpoch
+
+	baseFee abi.TokenAmount
+	// recentBaseFees are the base fees of recent tipsets, oldest first
+	recentBaseFees []abi.TokenAmount
+	// included are the premiums and gas limits of recently included messages
+	included []GasMeta
+	pending  []*types.SignedMessage
+
+	// capacity is the gas included per epoch
+	capacity int64
+}
+
+func effectivePremium(feeCap, premium, baseFee abi.TokenAmount) abi.TokenAmount {
+	return big.Min(premium, big.Sub(feeCap, baseFee))
+}
+
+func (a *MpoolAPI) MpoolPredictInclusion(ctx context.Context, spec api.InclusionSpec) (*api.InclusionPrediction, error) {
+	in := inclusionInput{
+		epochs:   spec.Epochs,
+		capacity: build.BlockGasTarget * int64(build.BlocksPerEpoch),
+	}
+	if in.epochs <= 0 {
+		in.epochs = DefaultInclusionEpochs
+	}
+	if in.epochs > MaxInclusionEpochs {
+		return nil, xerrors.Errorf("can't predict inclusion over more than %d epochs", MaxInclusionEpochs)
+	}
+
+	pending, ts := a.Mpool.Pending(ctx)
+	in.pending = pending
+
+	if spec.Message != nil {
+		in.msg = *spec.Message
+		var found *types.SignedMessage
+		for _, sm := range pending {
+			if sm.Cid() == in.msg {
+				found = sm
+				break
+			}
+		}
+		if found == nil {
+			return nil, xerrors.Errorf("message %s not found in the message pool", in.msg)
+		}
+		in.gasFeeCap = found.Message.GasFeeCap
+		in.gasPremium = found.Message.GasPremium
+		in.gasLimit = found.Message.GasLimit
+	} else {
+		if spec.GasFeeCap.Nil() || spec.GasPremium.Nil() {
+			return nil, xerrors.Errorf("either a pending message or the gas fee cap and premium must be given")
+		}
+		in.gasFeeCap = spec.GasFeeCap
+		in.gasPremium = spec.GasPremium
+		in.gasLimit = spec.GasLimit
+	}
+
+	baseFee, err := a.Chain.ComputeBaseFee(ctx, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("computing base fee: %w", err)
+	}
+	in.baseFee = baseFee
+
+	for i := 0; i < inclusionTrendLookback && ts.Height() > 0; i++ {
+		in.recentBaseFees = append([]abi.TokenAmount{ts.Blocks()[0].ParentBaseFee}, in.recentBaseFees...)
+
+		pts, err := a.Chain.LoadTipSet(ctx, ts.Parents())
+		if err != nil {
+			return nil, xerrors.Errorf("loading parent tipset: %w", err)
+		}
+		meta, err := a.PriceCache.GetTSGasStats(ctx, a.Chain, pts)
+		if err != nil {
+			return nil, err
+		}
+		in.included = append(in.included, meta...)
+		ts = pts
+	}
+
+	return predictInclusion(in), nil
+}
+
+// predictInclusion estimates the inclusion of a message from the pool depth,
+// the premiums of recently included messages and the recent base fee trend.
+//
+// The message is expected to be included once the gas of the pending messages
+// paying a higher premium is included, as long as its fee cap covers the base
+// fee projected with the recent trend. From then on, it is included in each
+// epoch with a chance given by the share of recently included gas paying a
+// lower premium.
+func predictInclusion(in inclusionInput) *api.InclusionPrediction {
+	out := &api.InclusionPrediction{
+		Epochs:           in.epochs,
+		BaseFee:          in.baseFee,
+		BaseFeeTrend:     baseFeeTrend(in.recentBaseFees),
+		EffectivePremium: effectivePremium(in.gasFeeCap, in.gasPremium, in.baseFee),
+		PricedOutEpochs:  -1,
+	}
+
+	type pendingMeta struct {
+		premium abi.TokenAmount
+		limit   int64
+	}
+	var pending []pendingMeta
+	for _, sm := range in.pending {
+		if in.msg.Defined() && sm.Cid() == in.msg {
+			continue
+		}
+		m := &sm.Message
+		premium := effectivePremium(m.GasFeeCap, m.GasPremium, in.baseFee)
+		if premium.Sign() < 0 {
+			// can't be included at the current base fee
+			continue
+		}
+		pending = append(pending, pendingMeta{premium: premium, limit: m.GasLimit})
+		if premium.GreaterThan(out.EffectivePremium) {
+			out.MessagesAhead++
+			out.GasAhead += m.GasLimit
+		}
+	}
+
+	out.EstimatedEpochs = abi.ChainEpoch(out.GasAhead/in.capacity) + 1
+
+	// the premium needed to be ahead of a full epoch of pending messages
+	sort.Slice(pending, func(i, j int) bool {
+		return pending[i].premium.GreaterThan(pending[j].premium)
+	})
+	out.SuggestedGasPremium = big.NewInt(MinGasPremium)
+	var gas int64
+	for _, pm := range pending {
+		gas += pm.limit
+		if gas+in.gasLimit > in.capacity {
+			out.SuggestedGasPremium = big.Max(out.SuggestedGasPremium, big.Add(pm.premium, big.NewInt(1)))
+			break
+		}
+	}
+
+	out.PremiumPercentile = premiumPercentile(in.included, out.EffectivePremium)
+	chance := math.Min(math.Max(out.PremiumPercentile, 0.05), 0.95)
+
+	notIncluded := 1.0
+	includable := false
+	for i := abi.ChainEpoch(1); i <= in.epochs; i++ {
+		projected := projectBaseFee(in.baseFee, out.BaseFeeTrend, i-1)
+		if in.gasFeeCap.LessThan(projected) {
+			if includable && out.PricedOutEpochs < 0 {
+				out.PricedOutEpochs = i
+			}
+			continue
+		}
+		includable = true
+		if i >= out.EstimatedEpochs {
+			notIncluded *= 1 - chance
+		}
+	}
+	if !includable {
+		out.PricedOutEpochs = 0
+	}
+	out.Probability = 1 - notIncluded
+
+	return out
+}
+
+// baseFeeTrend returns the average ratio by which the base fee changed per epoch.
+func baseFeeTrend(baseFees []abi.TokenAmount) float64 {
+	if len(baseFees) < 2 {
+		return 1
+	}
+	first, _ := new(gobig.Float).SetInt(big.Max(baseFees[0], big.NewInt(1)).Int).Float64()
+	last, _ := new(gobig.Float).SetInt(big.Max(baseFees[len(baseFees)-1], big.NewInt(1)).Int).Float64()
+	return math.Pow(last/first, 1/float64(len(baseFees)-1))
+}
+
+func projectBaseFee(baseFee abi.TokenAmount, trend float64, epochs abi.ChainEpoch) abi.TokenAmount {
+	projected := new(gobig.Float).SetInt(baseFee.Int)
+	projected.Mul(projected, gobig.NewFloat(math.Pow(trend, float64(epochs))))
+	out, _ := projected.Int(nil)
+	return big.NewFromGo(out)
+}
+
+// premiumPercentile returns the share of the included gas paying a lower premium.
+func premiumPercentile(included []GasMeta, premium abi.TokenAmount) float64 {
+	var total, lower int64
+	for _, gm := range included {
+		total += gm.Limit
+		if gm.Price.LessThan(premium) {
+			lower += gm.Limit
+		}
+	}
+	if total == 0 {
+		return 0.5
+	}
+	return float64(lower) / float64(total)
+}
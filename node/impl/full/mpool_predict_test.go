@@ -0,0 +1,74 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestPredictInclusion(t *testing.T) {
+	const capacity = 1000
+
+	pending := func(premiums ...int64) []*types.SignedMessage {
+		var out []*types.SignedMessage
+		for i, p := range premiums {
+			m := mock.UnsignedMessage(mock.Address(1000+uint64(i)), mock.Address(2000), 0)
+			m.GasFeeCap = big.NewInt(1000e3)
+			m.GasPremium = big.NewInt(p * 1000)
+			m.GasLimit = 400
+			out = append(out, &types.SignedMessage{Message: *m})
+		}
+		return out
+	}
+
+	in := inclusionInput{
+		gasFeeCap:  big.NewInt(500e3),
+		gasPremium: big.NewInt(300e3),
+		gasLimit:   400,
+		epochs:     5,
+		baseFee:    big.NewInt(100e3),
+		recentBaseFees: []abi.TokenAmount{
+			big.NewInt(100e3), big.NewInt(100e3), big.NewInt(100e3),
+		},
+		included: []GasMeta{
+			{Price: big.NewInt(100e3), Limit: 300},
+			{Price: big.NewInt(200e3), Limit: 300},
+			{Price: big.NewInt(400e3), Limit: 400},
+		},
+		pending:  pending(400, 350, 350, 350, 200, 100),
+		capacity: capacity,
+	}
+
+	p := predictInclusion(in)
+	require.Equal(t, 1.0, p.BaseFeeTrend)
+	require.Equal(t, big.NewInt(300e3), p.EffectivePremium)
+	require.Equal(t, 4, p.MessagesAhead)
+	require.Equal(t, int64(1600), p.GasAhead)
+	require.Equal(t, abi.ChainEpoch(2), p.EstimatedEpochs)
+	require.Equal(t, abi.ChainEpoch(-1), p.PricedOutEpochs)
+	require.Equal(t, 0.6, p.PremiumPercentile)
+	// included with a 0.6 chance in each of the epochs 2 to 5
+	require.InDelta(t, 1-0.4*0.4*0.4*0.4, p.Probability, 1e-9)
+	// one epoch fits ours and the two pending messages paying the most
+	require.Equal(t, big.NewInt(350e3+1), p.SuggestedGasPremium)
+
+	// a rising base fee prices the message out
+	in.recentBaseFees = []abi.TokenAmount{big.NewInt(50e3), big.NewInt(100e3)}
+	p = predictInclusion(in)
+	require.Equal(t, 2.0, p.BaseFeeTrend)
+	require.Equal(t, abi.ChainEpoch(4), p.PricedOutEpochs)
+	require.InDelta(t, 1-0.4*0.4, p.Probability, 1e-9)
+
+	// the fee cap doesn't cover the base fee
+	in.gasFeeCap = big.NewInt(50e3)
+	p = predictInclusion(in)
+	require.Equal(t, abi.ChainEpoch(0), p.PricedOutEpochs)
+	require.Equal(t, 0.0, p.Probability)
+}
@@ -0,0 +1,51 @@
+package full
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/messagescheduler"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// MpoolScheduleAPI serves the scheduled messages of the message scheduler.
+type MpoolScheduleAPI struct {
+	fx.In
+
+	Scheduler *messagescheduler.MessageScheduler `optional:"true"`
+}
+
+func (a *MpoolScheduleAPI) scheduler() (*messagescheduler.MessageScheduler, error) {
+	if a.Scheduler == nil {
+		return nil, xerrors.Errorf("the message scheduler is not available on this node")
+	}
+	return a.Scheduler, nil
+}
+
+func (a *MpoolScheduleAPI) MpoolScheduleMessage(ctx context.Context, msg *types.Message, schedule api.MessageSchedule, spec *api.MessageSendSpec) (*api.ScheduledMessage, error) {
+	s, err := a.scheduler()
+	if err != nil {
+		return nil, err
+	}
+	return s.Schedule(ctx, msg, schedule, spec)
+}
+
+func (a *MpoolScheduleAPI) MpoolListScheduled(ctx context.Context) ([]*api.ScheduledMessage, error) {
+	s, err := a.scheduler()
+	if err != nil {
+		return nil, err
+	}
+	return s.List(ctx)
+}
+
+func (a *MpoolScheduleAPI) MpoolCancelScheduled(ctx context.Context, id uuid.UUID) error {
+	s, err := a.scheduler()
+	if err != nil {
+		return err
+	}
+	return s.Cancel(ctx, id)
+}
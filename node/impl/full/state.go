@@ -7,6 +7,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -42,6 +44,9 @@ import (
 	"github.com/filecoin-project/lotus/chain/actors/policy"
 	"github.com/filecoin-project/lotus/chain/beacon"
 	"github.com/filecoin-project/lotus/chain/consensus"
+	"github.com/filecoin-project/lotus/chain/gasstats"
+	"github.com/filecoin-project/lotus/chain/index"
+	"github.com/filecoin-project/lotus/chain/rand"
 	"github.com/filecoin-project/lotus/chain/state"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
@@ -59,6 +64,7 @@ type StateModuleAPI interface {
 	StateAccountKey(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
 	StateDealProviderCollateralBounds(ctx context.Context, size abi.PaddedPieceSize, verified bool, tsk types.TipSetKey) (api.DealCollateralBounds, error)
 	StateGetActor(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*types.Actor, error)
+	StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*api.ActorProof, error)
 	StateListMiners(ctx context.Context, tsk types.TipSetKey) ([]address.Address, error)
 	StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error)
 	StateMarketBalance(ctx context.Context, addr address.Address, tsk types.TipSetKey) (api.MarketBalance, error)
@@ -101,8 +107,14 @@ type StateAPI struct {
 	StateManager  *stmgr.StateManager
 	Chain         *store.ChainStore
 	Beacon        beacon.Schedule
+	DrandSchedule dtypes.DrandSchedule `optional:"true"`
+	GasStats      *gasstats.Tracker    `optional:"true"`
+	AddrIndex     index.AddrIndex      `optional:"true"`
 	Consensus     consensus.Consensus
 	TsExec        stmgr.Executor
+	ReplayCache   *ReplayCache `optional:"true"`
+	Upgrades      stmgr.UpgradeSchedule
+	Announced     dtypes.UpgradeAnnouncementsFunc `optional:"true"`
 }
 
 func (a *StateAPI) StateNetworkName(ctx context.Context) (dtypes.NetworkName, error) {
@@ -250,38 +262,11 @@ func (a *StateAPI) StateMinerPartitions(ctx context.Context, m address.Address,
 
 	var out []api.Partition
 	err = dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
-		allSectors, err := part.AllSectors()
+		p, err := apiPartition(part)
 		if err != nil {
-			return xerrors.Errorf("getting AllSectors: %w", err)
-		}
-
-		faultySectors, err := part.FaultySectors()
-		if err != nil {
-			return xerrors.Errorf("getting FaultySectors: %w", err)
-		}
-
-		recoveringSectors, err := part.RecoveringSectors()
-		if err != nil {
-			return xerrors.Errorf("getting RecoveringSectors: %w", err)
-		}
-
-		liveSectors, err := part.LiveSectors()
-		if err != nil {
-			return xerrors.Errorf("getting LiveSectors: %w", err)
-		}
-
-		activeSectors, err := part.ActiveSectors()
-		if err != nil {
-			return xerrors.Errorf("getting ActiveSectors: %w", err)
+			return err
 		}
-
-		out = append(out, api.Partition{
-			AllSectors:        allSectors,
-			FaultySectors:     faultySectors,
-			RecoveringSectors: recoveringSectors,
-			LiveSectors:       liveSectors,
-			ActiveSectors:     activeSectors,
-		})
+		out = append(out, p)
 		return nil
 	})
 
@@ -312,6 +297,129 @@ func (m *StateModule) StateMinerProvingDeadline(ctx context.Context, addr addres
 	return di.NextNotElapsed(), nil
 }
 
+func (a *StateAPI) StateMinerProvingSchedule(ctx context.Context, addr address.Address, tsk types.TipSetKey) (*api.ProvingSchedule, error) {
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, err := a.StateManager.LoadActor(ctx, addr, ts)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load miner actor: %w", err)
+	}
+
+	mas, err := miner.Load(a.StateManager.ChainStore().ActorStore(ctx), act)
+	if err != nil {
+		return nil, xerrors.Errorf("failed to load miner actor state: %w", err)
+	}
+
+	di, err := mas.DeadlineInfo(ts.Height())
+	if err != nil {
+		return nil, xerrors.Errorf("failed to get deadline info: %w", err)
+	}
+
+	gen, err := a.Chain.GetGenesis(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("loading genesis: %w", err)
+	}
+	epochTime := func(e abi.ChainEpoch) time.Time {
+		return time.Unix(int64(gen.Timestamp)+int64(e)*int64(build.BlockDelaySecs), 0)
+	}
+
+	cur := di.NextNotElapsed()
+	out := &api.ProvingSchedule{
+		Epoch:           ts.Height(),
+		EpochTime:       epochTime(ts.Height()),
+		PeriodStart:     cur.PeriodStart,
+		CurrentDeadline: cur.Index,
+		Deadlines:       make([]api.ProvingDeadline, 0, cur.WPoStPeriodDeadlines),
+	}
+
+	for idx := uint64(0); idx < cur.WPoStPeriodDeadlines; idx++ {
+		pd := provingDeadline(cur, idx, epochTime)
+
+		dl, err := mas.LoadDeadline(idx)
+		if err != nil {
+			return nil, xerrors.Errorf("loading deadline %d: %w", idx, err)
+		}
+		if pd.PostSubmissions, err = dl.PartitionsPoSted(); err != nil {
+			return nil, xerrors.Errorf("loading deadline %d post submissions: %w", idx, err)
+		}
+
+		err = dl.ForEachPartition(func(_ uint64, part miner.Partition) error {
+			p, err := apiPartition(part)
+			if err != nil {
+				return err
+			}
+			pd.Partitions = append(pd.Partitions, p)
+			return nil
+		})
+		if err != nil {
+			return nil, xerrors.Errorf("loading deadline %d partitions: %w", idx, err)
+		}
+
+		out.Deadlines = append(out.Deadlines, pd)
+	}
+
+	return out, nil
+}
+
+// provingDeadline returns the currently open, or next, challenge window of a
+// deadline, given the current deadline
+func provingDeadline(cur *dline.Info, idx uint64, epochTime func(abi.ChainEpoch) time.Time) api.ProvingDeadline {
+	di := dline.NewInfo(cur.PeriodStart, idx, cur.CurrentEpoch, cur.WPoStPeriodDeadlines, cur.WPoStProvingPeriod, cur.WPoStChallengeWindow, cur.WPoStChallengeLookback, cur.FaultDeclarationCutoff).NextNotElapsed()
+
+	return api.ProvingDeadline{
+		Index:   idx,
+		Current: di.IsOpen(),
+
+		Open:        di.Open,
+		Close:       di.Close,
+		Challenge:   di.Challenge,
+		FaultCutoff: di.FaultCutoff,
+
+		OpenTime:        epochTime(di.Open),
+		CloseTime:       epochTime(di.Close),
+		ChallengeTime:   epochTime(di.Challenge),
+		FaultCutoffTime: epochTime(di.FaultCutoff),
+	}
+}
+
+func apiPartition(part miner.Partition) (api.Partition, error) {
+	allSectors, err := part.AllSectors()
+	if err != nil {
+		return api.Partition{}, xerrors.Errorf("getting AllSectors: %w", err)
+	}
+
+	faultySectors, err := part.FaultySectors()
+	if err != nil {
+		return api.Partition{}, xerrors.Errorf("getting FaultySectors: %w", err)
+	}
+
+	recoveringSectors, err := part.RecoveringSectors()
+	if err != nil {
+		return api.Partition{}, xerrors.Errorf("getting RecoveringSectors: %w", err)
+	}
+
+	liveSectors, err := part.LiveSectors()
+	if err != nil {
+		return api.Partition{}, xerrors.Errorf("getting LiveSectors: %w", err)
+	}
+
+	activeSectors, err := part.ActiveSectors()
+	if err != nil {
+		return api.Partition{}, xerrors.Errorf("getting ActiveSectors: %w", err)
+	}
+
+	return api.Partition{
+		AllSectors:        allSectors,
+		FaultySectors:     faultySectors,
+		RecoveringSectors: recoveringSectors,
+		LiveSectors:       liveSectors,
+		ActiveSectors:     activeSectors,
+	}, nil
+}
+
 func (a *StateAPI) StateMinerFaults(ctx context.Context, addr address.Address, tsk types.TipSetKey) (bitfield.BitField, error) {
 	act, err := a.StateManager.LoadActorTsk(ctx, addr, tsk)
 	if err != nil {
@@ -420,36 +528,65 @@ func (a *StateAPI) StateCall(ctx context.Context, msg *types.Message, tsk types.
 }
 
 func (a *StateAPI) StateReplay(ctx context.Context, tsk types.TipSetKey, mc cid.Cid) (*api.InvocResult, error) {
-	msgToReplay := mc
-	var ts *types.TipSet
-	var err error
-	if tsk == types.EmptyTSK {
-		mlkp, err := a.StateSearchMsg(ctx, types.EmptyTSK, mc, stmgr.LookbackNoLimit, true)
-		if err != nil {
-			return nil, xerrors.Errorf("searching for msg %s: %w", mc, err)
-		}
-		if mlkp == nil {
-			return nil, xerrors.Errorf("didn't find msg %s", mc)
-		}
+	ts, msgToReplay, err := a.replayTipSet(ctx, tsk, mc)
+	if err != nil {
+		return nil, err
+	}
 
-		msgToReplay = mlkp.Message
+	return a.replay(ctx, ts, msgToReplay)
+}
 
-		executionTs, err := a.Chain.GetTipSetFromKey(ctx, mlkp.TipSet)
-		if err != nil {
-			return nil, xerrors.Errorf("loading tipset %s: %w", mlkp.TipSet, err)
-		}
+func (a *StateAPI) StateReplayCached(ctx context.Context, tsk types.TipSetKey, mc cid.Cid) (*api.InvocResult, error) {
+	ts, msgToReplay, err := a.replayTipSet(ctx, tsk, mc)
+	if err != nil {
+		return nil, err
+	}
 
-		ts, err = a.Chain.LoadTipSet(ctx, executionTs.Parents())
-		if err != nil {
-			return nil, xerrors.Errorf("loading parent tipset %s: %w", mlkp.TipSet, err)
-		}
-	} else {
-		ts, err = a.Chain.LoadTipSet(ctx, tsk)
+	key := replayKey{msg: msgToReplay, ts: ts.Key()}
+	if res, ok := a.ReplayCache.get(ctx, key); ok {
+		return res, nil
+	}
+
+	res, err := a.replay(ctx, ts, msgToReplay)
+	if err != nil {
+		return nil, err
+	}
+	a.ReplayCache.put(key, res)
+	return res, nil
+}
+
+// replayTipSet finds the tipset a message is replayed on, and the message included on chain
+// when looking it up.
+func (a *StateAPI) replayTipSet(ctx context.Context, tsk types.TipSetKey, mc cid.Cid) (*types.TipSet, cid.Cid, error) {
+	if tsk != types.EmptyTSK {
+		ts, err := a.Chain.LoadTipSet(ctx, tsk)
 		if err != nil {
-			return nil, xerrors.Errorf("loading specified tipset %s: %w", tsk, err)
+			return nil, cid.Undef, xerrors.Errorf("loading specified tipset %s: %w", tsk, err)
 		}
+		return ts, mc, nil
+	}
+
+	mlkp, err := a.StateSearchMsg(ctx, types.EmptyTSK, mc, stmgr.LookbackNoLimit, true)
+	if err != nil {
+		return nil, cid.Undef, xerrors.Errorf("searching for msg %s: %w", mc, err)
+	}
+	if mlkp == nil {
+		return nil, cid.Undef, xerrors.Errorf("didn't find msg %s", mc)
+	}
+
+	executionTs, err := a.Chain.GetTipSetFromKey(ctx, mlkp.TipSet)
+	if err != nil {
+		return nil, cid.Undef, xerrors.Errorf("loading tipset %s: %w", mlkp.TipSet, err)
 	}
 
+	ts, err := a.Chain.LoadTipSet(ctx, executionTs.Parents())
+	if err != nil {
+		return nil, cid.Undef, xerrors.Errorf("loading parent tipset %s: %w", mlkp.TipSet, err)
+	}
+	return ts, mlkp.Message, nil
+}
+
+func (a *StateAPI) replay(ctx context.Context, ts *types.TipSet, msgToReplay cid.Cid) (*api.InvocResult, error) {
 	m, r, err := a.StateManager.Replay(ctx, ts, msgToReplay)
 	if err != nil {
 		return nil, err
@@ -479,6 +616,30 @@ func (m *StateModule) StateGetActor(ctx context.Context, actor address.Address,
 	return m.StateManager.LoadActor(ctx, actor, ts)
 }
 
+func (m *StateModule) StateGetProof(ctx context.Context, actor address.Address, tsk types.TipSetKey) (*api.ActorProof, error) {
+	ts, err := m.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	act, blks, err := state.ProveActor(ctx, m.Chain.StateBlockstore(), ts.ParentState(), actor)
+	if err != nil {
+		return nil, xerrors.Errorf("proving actor %s: %w", actor, err)
+	}
+
+	proof := &api.ActorProof{
+		Address:   actor,
+		TipSet:    ts.Key(),
+		StateRoot: ts.ParentState(),
+		Actor:     act,
+		Blocks:    make([]api.ProofBlock, len(blks)),
+	}
+	for i, b := range blks {
+		proof.Blocks[i] = api.ProofBlock{Cid: b.Cid(), Data: b.RawData()}
+	}
+	return proof, nil
+}
+
 func (m *StateModule) StateLookupID(ctx context.Context, addr address.Address, tsk types.TipSetKey) (address.Address, error) {
 	ts, err := m.Chain.GetTipSetFromKey(ctx, tsk)
 	if err != nil {
@@ -1754,6 +1915,76 @@ func (a *StateAPI) StateGetRandomnessFromBeacon(ctx context.Context, personaliza
 
 }
 
+func (a *StateAPI) StateGetRandomnessWithProof(ctx context.Context, source api.RandomnessSource, personalization crypto.DomainSeparationTag, randEpoch abi.ChainEpoch, entropy []byte, tsk types.TipSetKey) (*api.RandomnessProof, error) {
+	if source != api.RandomnessFromTickets && source != api.RandomnessFromBeacon {
+		return nil, xerrors.Errorf("unknown randomness source %q", source)
+	}
+	fromBeacon := source == api.RandomnessFromBeacon
+
+	rb, err := a.StateManager.GetRandomnessBase(ctx, fromBeacon, randEpoch, tsk)
+	if err != nil {
+		return nil, err
+	}
+
+	randomness, err := rand.DrawRandomness(rb.Data(), personalization, randEpoch, entropy)
+	if err != nil {
+		return nil, err
+	}
+
+	proof := &api.RandomnessProof{
+		Randomness:      randomness,
+		Source:          source,
+		Personalization: personalization,
+		Epoch:           randEpoch,
+		Entropy:         entropy,
+		Base:            rb.Data(),
+		TipSet:          rb.TipSet.Key(),
+		Height:          rb.TipSet.Height(),
+	}
+
+	if !fromBeacon {
+		proof.Block = &rb.Block
+		proof.Ticket = rb.Ticket
+		return proof, nil
+	}
+
+	proof.BeaconEntry = rb.BeaconEntry
+	proof.DrandChainInfo = drandChainInfo(a.DrandSchedule, randEpoch)
+
+	// entries of chained drand networks are signed along with the previous one
+	if round := rb.BeaconEntry.Round; round > 1 {
+		for _, be := range rb.TipSet.Blocks()[0].BeaconEntries {
+			if be.Round == round-1 {
+				be := be
+				proof.PrevBeaconEntry = &be
+			}
+		}
+		if proof.PrevBeaconEntry == nil {
+			select {
+			case resp := <-a.Beacon.BeaconForEpoch(randEpoch).Entry(ctx, round-1):
+				if resp.Err == nil {
+					proof.PrevBeaconEntry = &resp.Entry
+				}
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	return proof, nil
+}
+
+// drandChainInfo returns the info of the drand chain used at the given epoch.
+func drandChainInfo(schedule dtypes.DrandSchedule, epoch abi.ChainEpoch) string {
+	var info string
+	for _, p := range schedule {
+		if p.Start <= epoch {
+			info = p.Config.ChainInfoJSON
+		}
+	}
+	return info
+}
+
 func (a *StateAPI) StateGetBeaconEntry(ctx context.Context, epoch abi.ChainEpoch) (*types.BeaconEntry, error) {
 	b := a.Beacon.BeaconForEpoch(epoch)
 	rr := b.MaxBeaconRoundForEpoch(a.StateManager.GetNetworkVersion(ctx, epoch), epoch)
@@ -1773,6 +2004,176 @@ func (a *StateAPI) StateGetBeaconEntry(ctx context.Context, epoch abi.ChainEpoch
 	}
 }
 
+func (a *StateAPI) BeaconStatus(ctx context.Context) ([]api.BeaconStatus, error) {
+	head := a.Chain.GetHeaviestTipSet()
+	active := a.Beacon.BeaconForEpoch(head.Height())
+
+	out := make([]api.BeaconStatus, 0, len(a.Beacon))
+	for _, bp := range a.Beacon {
+		bs := api.BeaconStatus{
+			Start:  bp.Start,
+			Active: bp.Beacon == active,
+		}
+		if sr, ok := bp.Beacon.(beacon.StatusReporter); ok {
+			bs.Status = sr.Status()
+		} else {
+			bs.Status.Healthy = true
+		}
+		out = append(out, bs)
+	}
+	return out, nil
+}
+
+func (a *StateAPI) StateGasStats(ctx context.Context, lookback abi.ChainEpoch) (*api.GasStats, error) {
+	if a.GasStats == nil {
+		return nil, xerrors.Errorf("gas stats are disabled, enable them with Index.EnableGasStats")
+	}
+	if lookback < 0 {
+		return nil, xerrors.Errorf("lookback must not be negative")
+	}
+	return a.GasStats.Stats(lookback), nil
+}
+
+const (
+	defaultAddressMessagesLimit = 100
+	maxAddressMessagesLimit     = 1000
+)
+
+func (a *StateAPI) StateListAddressMessages(ctx context.Context, addr address.Address, query *api.AddressMessagesQuery) (*api.AddressMessages, error) {
+	if a.AddrIndex == nil {
+		return nil, xerrors.Errorf("address index is disabled, enable it with Index.EnableAddrIndex")
+	}
+	if query == nil {
+		query = &api.AddressMessagesQuery{}
+	}
+
+	q := index.AddrQuery{
+		MinEpoch: query.FromEpoch,
+		MaxEpoch: query.ToEpoch,
+		Limit:    query.Limit,
+	}
+	if q.MaxEpoch == 0 {
+		q.MaxEpoch = -1
+	}
+	switch query.Direction {
+	case "":
+	case "sent":
+		q.Sent = true
+	case "received":
+		q.Received = true
+	default:
+		return nil, xerrors.Errorf("unknown direction %q, expected sent or received", query.Direction)
+	}
+	switch {
+	case q.Limit == 0:
+		q.Limit = defaultAddressMessagesLimit
+	case q.Limit < 0 || q.Limit > maxAddressMessagesLimit:
+		return nil, xerrors.Errorf("limit must be between 1 and %d", maxAddressMessagesLimit)
+	}
+	if query.Cursor != "" {
+		after, err := parseAddressMessagesCursor(query.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		q.After = &after
+	}
+	if query.Selector != nil {
+		if len(query.Selector) != 4 {
+			return nil, xerrors.Errorf("selector must be 4 bytes, got %d", len(query.Selector))
+		}
+		q.Selector = query.Selector
+	}
+
+	ts := a.Chain.GetHeaviestTipSet()
+	if query.To != address.Undef {
+		q.To = a.addressForms(ctx, query.To, ts)
+	}
+
+	msgs, err := a.AddrIndex.GetAddrMessages(ctx, a.addressForms(ctx, addr, ts), q)
+	if err != nil {
+		return nil, xerrors.Errorf("querying address index: %w", err)
+	}
+
+	out := &api.AddressMessages{
+		Messages: make([]api.AddressMessage, len(msgs)),
+	}
+	for i, m := range msgs {
+		out.Messages[i] = api.AddressMessage{
+			Message:  m.Message,
+			TipSet:   m.TipSet,
+			Epoch:    m.Epoch,
+			Sent:     m.Sent,
+			Selector: m.Selector,
+		}
+	}
+	if len(msgs) == q.Limit {
+		out.Cursor = addressMessagesCursor(msgs[len(msgs)-1])
+	}
+
+	return out, nil
+}
+
+// addressForms returns the ID and robust address of an actor, as messages
+// refer to actors by either.
+func (a *StateAPI) addressForms(ctx context.Context, addr address.Address, ts *types.TipSet) []address.Address {
+	addrs := []address.Address{addr}
+	if addr.Protocol() == address.ID {
+		if robust, err := a.StateManager.LookupRobustAddress(ctx, addr, ts); err == nil && robust != addr {
+			addrs = append(addrs, robust)
+		}
+	} else if id, err := a.StateManager.LookupID(ctx, addr, ts); err == nil {
+		addrs = append(addrs, id)
+	}
+	return addrs
+}
+
+// addressMessagesCursor encodes the position of a message in the results of
+// an address index query, as <epoch>:<message cid>:<s|r>.
+func addressMessagesCursor(m index.AddrMsgInfo) string {
+	dir := "r"
+	if m.Sent {
+		dir = "s"
+	}
+	return fmt.Sprintf("%d:%s:%s", m.Epoch, m.Message, dir)
+}
+
+func parseAddressMessagesCursor(s string) (index.AddrMsgInfo, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) != 3 || (parts[2] != "s" && parts[2] != "r") {
+		return index.AddrMsgInfo{}, xerrors.Errorf("malformed cursor %q", s)
+	}
+	epoch, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return index.AddrMsgInfo{}, xerrors.Errorf("malformed cursor epoch: %w", err)
+	}
+	msg, err := cid.Decode(parts[1])
+	if err != nil {
+		return index.AddrMsgInfo{}, xerrors.Errorf("malformed cursor message: %w", err)
+	}
+	return index.AddrMsgInfo{
+		Message: msg,
+		Epoch:   abi.ChainEpoch(epoch),
+		Sent:    parts[2] == "s",
+	}, nil
+}
+
+func (a *StateAPI) StateUpgradeSchedule(ctx context.Context) ([]api.UpgradeInfo, error) {
+	gen, err := a.Chain.GetGenesis(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting genesis: %w", err)
+	}
+
+	var announced []dtypes.AnnouncedUpgrade
+	if a.Announced != nil {
+		announced, err = a.Announced(ctx)
+		if err != nil {
+			log.Warnw("getting announced network upgrades", "error", err)
+		}
+	}
+
+	return a.Upgrades.Upcoming(a.Chain.GetHeaviestTipSet().Height(), gen.Timestamp, announced), nil
+}
+
 func (a *StateAPI) StateGetNetworkParams(ctx context.Context) (*api.NetworkParams, error) {
 	networkName, err := a.StateNetworkName(ctx)
 	if err != nil {
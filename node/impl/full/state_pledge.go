@@ -0,0 +1,161 @@
+package full
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/power"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+// MaxPledgeBatch is the most sectors a single StatePledgeForPowerBatch call can
+// compute the collateral of.
+const MaxPledgeBatch = 10000
+
+type pledgeInput struct {
+	height abi.ChainEpoch
+
+	reward           reward.State
+	powerSmoothed    builtin.FilterEstimate
+	pledgeCollateral abi.TokenAmount
+	circSupply       abi.TokenAmount
+
+	networkVersion func(abi.ChainEpoch) network.Version
+}
+
+func (a *StateAPI) StatePledgeForPowerBatch(ctx context.Context, sectors []api.ProspectiveSector, tsk types.TipSetKey) (*api.PledgeRequirements, error) {
+	if len(sectors) > MaxPledgeBatch {
+		return nil, xerrors.Errorf("can't compute the collateral of more than %d sectors at once", MaxPledgeBatch)
+	}
+
+	ts, err := a.Chain.GetTipSetFromKey(ctx, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("loading tipset %s: %w", tsk, err)
+	}
+
+	state, err := a.StateManager.ParentState(ts)
+	if err != nil {
+		return nil, xerrors.Errorf("loading state %s: %w", tsk, err)
+	}
+
+	store := a.Chain.ActorStore(ctx)
+
+	in := pledgeInput{
+		height: ts.Height(),
+		networkVersion: func(h abi.ChainEpoch) network.Version {
+			return a.StateManager.GetNetworkVersion(ctx, h)
+		},
+	}
+
+	if act, err := state.GetActor(power.Address); err != nil {
+		return nil, xerrors.Errorf("loading power actor: %w", err)
+	} else if s, err := power.Load(store, act); err != nil {
+		return nil, xerrors.Errorf("loading power actor state: %w", err)
+	} else if p, err := s.TotalPowerSmoothed(); err != nil {
+		return nil, xerrors.Errorf("failed to determine total power: %w", err)
+	} else if c, err := s.TotalLocked(); err != nil {
+		return nil, xerrors.Errorf("failed to determine pledge collateral: %w", err)
+	} else {
+		in.powerSmoothed = p
+		in.pledgeCollateral = c
+	}
+
+	rewardActor, err := state.GetActor(reward.Address)
+	if err != nil {
+		return nil, xerrors.Errorf("loading reward actor: %w", err)
+	}
+
+	in.reward, err = reward.Load(store, rewardActor)
+	if err != nil {
+		return nil, xerrors.Errorf("loading reward actor state: %w", err)
+	}
+
+	circSupply, err := a.StateVMCirculatingSupplyInternal(ctx, ts.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting circulating supply: %w", err)
+	}
+	in.circSupply = circSupply.FilCirculating
+
+	return pledgeForSectors(in, sectors)
+}
+
+// pledgeForSectors computes the collateral of the sectors from the current
+// network state, with the same margin as StateMinerPreCommitDepositForPower
+// and StateMinerInitialPledgeCollateral. Deals are assumed to be valid.
+func pledgeForSectors(in pledgeInput, sectors []api.ProspectiveSector) (*api.PledgeRequirements, error) {
+	out := &api.PledgeRequirements{
+		Height:           in.height,
+		NetworkVersion:   in.networkVersion(in.height),
+		Sectors:          make([]api.SectorPledge, 0, len(sectors)),
+		PreCommitDeposit: big.Zero(),
+		InitialPledge:    big.Zero(),
+	}
+
+	for i, ps := range sectors {
+		if ps.SectorSize == 0 {
+			return nil, xerrors.Errorf("sector %d: sector size not set", i)
+		}
+		if ps.Duration <= 0 {
+			return nil, xerrors.Errorf("sector %d: duration must be positive", i)
+		}
+
+		dealWeight, verifiedWeight := ps.DealWeight, ps.VerifiedDealWeight
+		if dealWeight.Nil() {
+			dealWeight = big.Zero()
+		}
+		if verifiedWeight.Nil() {
+			verifiedWeight = big.Zero()
+		}
+		maxWeight := big.Mul(big.NewIntUnsigned(uint64(ps.SectorSize)), big.NewInt(int64(ps.Duration)))
+		if dealWeight.Sign() < 0 || verifiedWeight.Sign() < 0 || big.Add(dealWeight, verifiedWeight).GreaterThan(maxWeight) {
+			return nil, xerrors.Errorf("sector %d: deal weights must be between zero and the sector space-time", i)
+		}
+
+		activation := ps.Activation
+		if activation == 0 {
+			activation = in.height + 1
+		}
+		if activation < in.height {
+			return nil, xerrors.Errorf("sector %d: activation %d is before the tipset height %d", i, activation, in.height)
+		}
+
+		sp := api.SectorPledge{
+			NetworkVersion: in.networkVersion(activation),
+			Activation:     activation,
+			QAPower:        builtin.QAPowerForWeight(ps.SectorSize, ps.Duration, dealWeight, verifiedWeight),
+		}
+
+		// since nv17 the deposit is computed for the maximum power of the sector
+		depositPower := sp.QAPower
+		if sp.NetworkVersion > network.Version16 {
+			depositPower = minertypes.QAPowerMax(ps.SectorSize)
+		}
+
+		deposit, err := in.reward.PreCommitDepositForPower(in.powerSmoothed, depositPower)
+		if err != nil {
+			return nil, xerrors.Errorf("sector %d: calculating precommit deposit: %w", i, err)
+		}
+		sp.PreCommitDeposit = types.BigDiv(types.BigMul(deposit, initialPledgeNum), initialPledgeDen)
+
+		pledge, err := in.reward.InitialPledgeForPower(sp.QAPower, in.pledgeCollateral, &in.powerSmoothed, in.circSupply)
+		if err != nil {
+			return nil, xerrors.Errorf("sector %d: calculating initial pledge: %w", i, err)
+		}
+		sp.InitialPledge = types.BigDiv(types.BigMul(pledge, initialPledgeNum), initialPledgeDen)
+
+		out.Sectors = append(out.Sectors, sp)
+		out.PreCommitDeposit = big.Add(out.PreCommitDeposit, sp.PreCommitDeposit)
+		out.InitialPledge = big.Add(out.InitialPledge, sp.InitialPledge)
+	}
+
+	return out, nil
+}
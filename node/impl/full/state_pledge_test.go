@@ -0,0 +1,88 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+)
+
+// linearReward charges one attoFIL per byte of power for both the deposit and
+// the pledge.
+type linearReward struct {
+	reward.State
+}
+
+func (linearReward) PreCommitDepositForPower(_ builtin.FilterEstimate, power abi.StoragePower) (abi.TokenAmount, error) {
+	return power, nil
+}
+
+func (linearReward) InitialPledgeForPower(power abi.StoragePower, _ abi.TokenAmount, _ *builtin.FilterEstimate, _ abi.TokenAmount) (abi.TokenAmount, error) {
+	return power, nil
+}
+
+func TestPledgeForSectors(t *testing.T) {
+	const upgrade = abi.ChainEpoch(1000)
+	in := pledgeInput{
+		height: 900,
+		reward: linearReward{},
+		networkVersion: func(h abi.ChainEpoch) network.Version {
+			if h >= upgrade {
+				return network.Version17
+			}
+			return network.Version16
+		},
+	}
+
+	const ssize = abi.SectorSize(1 << 30)
+	const duration = abi.ChainEpoch(100)
+	withMargin := func(v abi.TokenAmount) abi.TokenAmount {
+		return big.Div(big.Mul(v, initialPledgeNum), initialPledgeDen)
+	}
+	verified := big.Mul(big.NewInt(int64(ssize)), big.NewInt(int64(duration)))
+
+	out, err := pledgeForSectors(in, []api.ProspectiveSector{
+		{SectorSize: ssize, Duration: duration},
+		{SectorSize: ssize, Duration: duration, VerifiedDealWeight: verified},
+		{SectorSize: ssize, Duration: duration, VerifiedDealWeight: verified, Activation: upgrade},
+	})
+	require.NoError(t, err)
+	require.Equal(t, network.Version16, out.NetworkVersion)
+	require.Len(t, out.Sectors, 3)
+
+	cc := out.Sectors[0]
+	require.Equal(t, abi.ChainEpoch(901), cc.Activation)
+	require.Equal(t, big.NewInt(int64(ssize)), cc.QAPower)
+	require.Equal(t, withMargin(cc.QAPower), cc.InitialPledge)
+	require.Equal(t, withMargin(cc.QAPower), cc.PreCommitDeposit)
+
+	// verified deals multiply the power, and the deposit before nv17
+	vd := out.Sectors[1]
+	require.Equal(t, big.Mul(cc.QAPower, big.NewInt(10)), vd.QAPower)
+	require.Equal(t, withMargin(vd.QAPower), vd.PreCommitDeposit)
+
+	// from nv17 the deposit is computed for the maximum power
+	up := out.Sectors[2]
+	require.Equal(t, network.Version17, up.NetworkVersion)
+	require.Equal(t, vd.InitialPledge, up.InitialPledge)
+	require.Equal(t, withMargin(minertypes.QAPowerMax(ssize)), up.PreCommitDeposit)
+
+	require.Equal(t, big.Sum(cc.InitialPledge, vd.InitialPledge, up.InitialPledge), out.InitialPledge)
+	require.Equal(t, big.Sum(cc.PreCommitDeposit, vd.PreCommitDeposit, up.PreCommitDeposit), out.PreCommitDeposit)
+
+	_, err = pledgeForSectors(in, []api.ProspectiveSector{{SectorSize: ssize, Duration: duration, DealWeight: big.Add(verified, big.NewInt(1))}})
+	require.Error(t, err)
+	_, err = pledgeForSectors(in, []api.ProspectiveSector{{SectorSize: ssize}})
+	require.Error(t, err)
+	_, err = pledgeForSectors(in, []api.ProspectiveSector{{SectorSize: ssize, Duration: duration, Activation: 10}})
+	require.Error(t, err)
+}
@@ -0,0 +1,88 @@
+package full
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/ipfs/go-cid"
+	"go.opencensus.io/stats"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/metrics"
+)
+
+// ReplayCache holds the results of recent message replays, served by StateReplayCached.
+type ReplayCache struct {
+	ttl time.Duration
+	c   *lru.Cache[replayKey, replayEntry] // nil when the cache is disabled
+}
+
+type replayKey struct {
+	msg cid.Cid
+	ts  types.TipSetKey
+}
+
+// replayEntry holds the JSON encoding of a result, each caller decoding its
+// own copy, which it may modify.
+type replayEntry struct {
+	res   []byte
+	added time.Time
+}
+
+// NewReplayCache creates a cache of size results, served for ttl. Results don't expire with a
+// zero ttl, and nothing is cached with a zero size.
+func NewReplayCache(size int, ttl time.Duration) *ReplayCache {
+	rc := &ReplayCache{ttl: ttl}
+	if size > 0 {
+		c, err := lru.New[replayKey, replayEntry](size)
+		if err != nil {
+			// err only if parameter is bad
+			panic(err)
+		}
+		rc.c = c
+	}
+	return rc
+}
+
+func (rc *ReplayCache) get(ctx context.Context, key replayKey) (*api.InvocResult, bool) {
+	if rc == nil || rc.c == nil {
+		stats.Record(ctx, metrics.StateReplayCacheMiss.M(1))
+		return nil, false
+	}
+
+	e, ok := rc.c.Get(key)
+	if ok && rc.ttl > 0 && time.Since(e.added) > rc.ttl {
+		rc.c.Remove(key)
+		ok = false
+	}
+
+	if !ok {
+		stats.Record(ctx, metrics.StateReplayCacheMiss.M(1))
+		return nil, false
+	}
+
+	var res api.InvocResult
+	if err := json.Unmarshal(e.res, &res); err != nil {
+		log.Errorf("decoding cached replay of %s: %s", key.msg, err)
+		rc.c.Remove(key)
+		stats.Record(ctx, metrics.StateReplayCacheMiss.M(1))
+		return nil, false
+	}
+	stats.Record(ctx, metrics.StateReplayCacheHit.M(1))
+	return &res, true
+}
+
+func (rc *ReplayCache) put(key replayKey, res *api.InvocResult) {
+	if rc == nil || rc.c == nil {
+		return
+	}
+	b, err := json.Marshal(res)
+	if err != nil {
+		log.Errorf("encoding replay of %s: %s", key.msg, err)
+		return
+	}
+	rc.c.Add(key, replayEntry{res: b, added: time.Now()})
+}
@@ -0,0 +1,67 @@
+// stm: #unit
+package full
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+func TestReplayCache(t *testing.T) {
+	ctx := context.Background()
+
+	ts := mock.TipSet(mock.MkBlock(nil, 1, 1))
+	key := func(nonce uint64) replayKey {
+		return replayKey{msg: mock.UnsignedMessage(mock.Address(100), mock.Address(101), nonce).Cid(), ts: ts.Key()}
+	}
+	res := func(nonce uint64) *api.InvocResult {
+		return &api.InvocResult{MsgCid: key(nonce).msg}
+	}
+
+	rc := NewReplayCache(2, 0)
+	_, ok := rc.get(ctx, key(0))
+	require.False(t, ok)
+
+	rc.put(key(0), res(0))
+	rc.put(key(1), res(1))
+	got, ok := rc.get(ctx, key(0))
+	require.True(t, ok)
+	require.Equal(t, key(0).msg, got.MsgCid)
+
+	// callers get their own copy
+	got.MsgCid = key(1).msg
+	got, ok = rc.get(ctx, key(0))
+	require.True(t, ok)
+	require.Equal(t, key(0).msg, got.MsgCid)
+
+	// results are keyed by tipset too
+	_, ok = rc.get(ctx, replayKey{msg: key(0).msg, ts: types.EmptyTSK})
+	require.False(t, ok)
+
+	// the least recently used result is evicted
+	rc.put(key(2), res(2))
+	_, ok = rc.get(ctx, key(1))
+	require.False(t, ok)
+	_, ok = rc.get(ctx, key(0))
+	require.True(t, ok)
+
+	// expired results aren't served
+	rc = NewReplayCache(2, time.Millisecond)
+	rc.put(key(0), res(0))
+	time.Sleep(5 * time.Millisecond)
+	_, ok = rc.get(ctx, key(0))
+	require.False(t, ok)
+
+	// disabled caches
+	for _, rc := range []*ReplayCache{NewReplayCache(0, 0), nil} {
+		rc.put(key(0), res(0))
+		_, ok = rc.get(ctx, key(0))
+		require.False(t, ok)
+	}
+}
@@ -0,0 +1,63 @@
+// stm: #unit
+package full
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/dline"
+
+	"github.com/filecoin-project/lotus/chain/index"
+)
+
+func TestProvingDeadline(t *testing.T) {
+	const (
+		deadlines = 48
+		window    = abi.ChainEpoch(60)
+		period    = deadlines * window
+	)
+
+	// epoch 130 is in deadline 2 of the period starting at 10
+	cur := dline.NewInfo(10, 2, 130, deadlines, period, window, 20, 70)
+	epochTime := func(e abi.ChainEpoch) time.Time { return time.Unix(1000+int64(e)*30, 0) }
+
+	pd := provingDeadline(cur, 2, epochTime)
+	require.True(t, pd.Current)
+	require.Equal(t, abi.ChainEpoch(130), pd.Open)
+	require.Equal(t, abi.ChainEpoch(190), pd.Close)
+	require.Equal(t, abi.ChainEpoch(110), pd.Challenge)
+	require.Equal(t, abi.ChainEpoch(60), pd.FaultCutoff)
+	require.Equal(t, time.Unix(1000+130*30, 0), pd.OpenTime)
+
+	// later deadlines open later in the same period
+	pd = provingDeadline(cur, 5, epochTime)
+	require.False(t, pd.Current)
+	require.Equal(t, abi.ChainEpoch(310), pd.Open)
+
+	// elapsed deadlines are in the next period
+	pd = provingDeadline(cur, 1, epochTime)
+	require.False(t, pd.Current)
+	require.Equal(t, 10+period+window, pd.Open)
+	require.Equal(t, epochTime(pd.Close), pd.CloseTime)
+}
+
+func TestAddressMessagesCursor(t *testing.T) {
+	c, err := cid.Parse("bafy2bzacea3wsdh6y3a36tb3skempjoxqpuyompjbmfeyf34fi3uy6uue42v4")
+	require.NoError(t, err)
+
+	for _, sent := range []bool{true, false} {
+		m := index.AddrMsgInfo{Message: c, Epoch: 1234, Sent: sent}
+		parsed, err := parseAddressMessagesCursor(addressMessagesCursor(m))
+		require.NoError(t, err)
+		require.Equal(t, m, parsed)
+	}
+
+	for _, bad := range []string{"", "1234", "x:" + c.String() + ":s", "1234:" + c.String() + ":x", "1234:abc:s"} {
+		_, err := parseAddressMessagesCursor(bad)
+		require.Error(t, err, bad)
+	}
+}
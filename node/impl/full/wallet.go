@@ -3,6 +3,7 @@ package full
 import (
 	"context"
 
+	"github.com/google/uuid"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
@@ -14,7 +15,9 @@ import (
 	"github.com/filecoin-project/lotus/chain/messagesigner"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/chain/wallet"
+	"github.com/filecoin-project/lotus/chain/wallet/signpolicy"
 	"github.com/filecoin-project/lotus/lib/sigs"
 )
 
@@ -24,6 +27,7 @@ type WalletAPI struct {
 	StateManagerAPI stmgr.StateManagerAPI
 	Default         wallet.Default
 	api.Wallet
+	Policy *signpolicy.PolicyWallet `optional:"true"`
 }
 
 func (a *WalletAPI) WalletBalance(ctx context.Context, addr address.Address) (types.BigInt, error) {
@@ -90,3 +94,48 @@ func (a *WalletAPI) WalletSetDefault(ctx context.Context, addr address.Address)
 func (a *WalletAPI) WalletValidateAddress(ctx context.Context, str string) (address.Address, error) {
 	return address.NewFromString(str)
 }
+
+func (a *WalletAPI) WalletPendingApprovals(ctx context.Context) ([]api.WalletApproval, error) {
+	if a.Policy == nil {
+		return []api.WalletApproval{}, nil
+	}
+	return a.Policy.Pending(), nil
+}
+
+func (a *WalletAPI) WalletApprove(ctx context.Context, id uuid.UUID, approve bool) error {
+	if a.Policy == nil {
+		return xerrors.Errorf("no wallet signing policy is configured")
+	}
+	return a.Policy.Decide(id, approve)
+}
+
+func (a *WalletAPI) EthSignTypedData(ctx context.Context, sender ethtypes.EthAddress, data ethtypes.EthTypedData) (ethtypes.EthBytes, error) {
+	addr, err := sender.ToFilecoinAddress()
+	if err != nil {
+		return nil, xerrors.Errorf("converting sender address: %w", err)
+	}
+	if addr.Protocol() != address.Delegated {
+		return nil, xerrors.Errorf("typed data can only be signed with delegated (f410) keys, got %s", addr)
+	}
+
+	payload, err := data.SigningPayload()
+	if err != nil {
+		return nil, xerrors.Errorf("encoding typed data: %w", err)
+	}
+
+	// delegated signatures are over the keccak256 hash of the payload, the EIP-712 digest
+	sig, err := a.Wallet.WalletSign(ctx, addr, payload, api.MsgMeta{
+		Type: api.MTUnknown,
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("failed to sign typed data: %w", err)
+	}
+	if sig.Type != crypto.SigTypeDelegated || len(sig.Data) != 65 {
+		return nil, xerrors.Errorf("unexpected signature type %d of length %d", sig.Type, len(sig.Data))
+	}
+
+	out := make(ethtypes.EthBytes, 65)
+	copy(out, sig.Data)
+	out[64] += 27
+	return out, nil
+}
@@ -0,0 +1,67 @@
+// stm: #unit
+package impl
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/api"
+	apitypes "github.com/filecoin-project/lotus/api/types"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func TestHealthFailures(t *testing.T) {
+	cfg := config.DefaultFullNode().Health
+
+	healthy := api.NodeHealth{
+		SyncLag:        1,
+		Peers:          20,
+		APILatency:     time.Millisecond,
+		EventsIndexLag: -1,
+	}
+	require.Empty(t, healthFailures(healthy, cfg))
+
+	h := healthy
+	h.SyncLag = 100
+	h.Peers = 0
+	h.DatastoreError = "closed"
+	h.EventsIndexLag = 50
+	require.Len(t, healthFailures(h, cfg), 4)
+
+	h = healthy
+	h.APILatency = time.Minute
+	require.Len(t, healthFailures(h, cfg), 1)
+
+	// failing to list peers only fails the peers check
+	h = healthy
+	h.Peers = 0
+	h.PeersError = "closed"
+	require.Equal(t, []string{"listing peers: closed"}, healthFailures(h, cfg))
+
+	// the events check can be disabled
+	h = healthy
+	h.EventsIndexLag = 50
+	cfg.MaxEventsIndexLag = 0
+	require.Empty(t, healthFailures(h, cfg))
+}
+
+func TestAnnotateOpenRPC(t *testing.T) {
+	doc := apitypes.OpenRPCDocument{
+		"methods": []interface{}{
+			map[string]interface{}{"name": "Filecoin.ChainHead"},
+			map[string]interface{}{"name": "Filecoin.EthGetLogs"},
+		},
+	}
+	annotateOpenRPC(doc, api.FullAPISpec(map[string]bool{api.FeatureEthRPC: true}))
+
+	methods := doc["methods"].([]interface{})
+	head := methods[0].(map[string]interface{})
+	require.NotContains(t, head, "x-enabled")
+
+	logs := methods[1].(map[string]interface{})
+	require.Equal(t, []string{"eth_getLogs"}, logs["x-aliases"])
+	require.Equal(t, api.FeatureEthFilters, logs["x-feature"])
+	require.Equal(t, false, logs["x-enabled"])
+}
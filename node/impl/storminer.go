@@ -1,6 +1,7 @@
 package impl
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -40,17 +41,27 @@ import (
 
 	"github.com/filecoin-project/lotus/api"
 	apitypes "github.com/filecoin-project/lotus/api/types"
+	"github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/build"
 	"github.com/filecoin-project/lotus/chain/actors"
 	"github.com/filecoin-project/lotus/chain/actors/builtin"
+	lminer "github.com/filecoin-project/lotus/chain/actors/builtin/miner"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/power"
+	"github.com/filecoin-project/lotus/chain/actors/builtin/reward"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
 	"github.com/filecoin-project/lotus/chain/gen"
+	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/paramcache"
 	mktsdagstore "github.com/filecoin-project/lotus/markets/dagstore"
+	"github.com/filecoin-project/lotus/markets/dealfilter"
 	"github.com/filecoin-project/lotus/markets/storageadapter"
 	"github.com/filecoin-project/lotus/miner"
 	"github.com/filecoin-project/lotus/node/modules"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/repo"
 	"github.com/filecoin-project/lotus/storage/ctladdr"
+	"github.com/filecoin-project/lotus/storage/hotness"
 	"github.com/filecoin-project/lotus/storage/paths"
 	sealing "github.com/filecoin-project/lotus/storage/pipeline"
 	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
@@ -86,11 +97,13 @@ type StorageMinerAPI struct {
 	Host              host.Host                         `optional:"true"`
 	DAGStore          *dagstore.DAGStore                `optional:"true"`
 	DAGStoreWrapper   *mktsdagstore.Wrapper             `optional:"true"`
+	FilterDecisions   *dealfilter.DecisionLog           `optional:"true"`
 
 	// Miner / storage
 	Miner       *sealing.Sealing     `optional:"true"`
 	BlockMiner  *miner.Miner         `optional:"true"`
 	StorageMgr  *sealer.Manager      `optional:"true"`
+	Hotness     *hotness.Tracker     `optional:"true"`
 	IStorageMgr sealer.SectorManager `optional:"true"`
 	paths.SectorIndex
 	storiface.WorkerReturn `optional:"true"`
@@ -101,6 +114,9 @@ type StorageMinerAPI struct {
 	Epp gen.WinningPoStProver `optional:"true"`
 	DS  dtypes.MetadataDS
 
+	// Repo is backed up by BackupCreate
+	Repo repo.LockedRepo `optional:"true"`
+
 	// StorageService is populated when we're not the main storage node (e.g. we're a markets node)
 	StorageService modules.MinerStorageService `optional:"true"`
 
@@ -156,6 +172,18 @@ func (sm *StorageMinerAPI) WorkerJobs(ctx context.Context) (map[uuid.UUID][]stor
 	return sm.StorageMgr.WorkerJobs(), nil
 }
 
+func (sm *StorageMinerAPI) WorkerTaskHistory(ctx context.Context, filter storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) {
+	return sm.StorageMgr.WorkerTaskHistory(ctx, filter)
+}
+
+func (sm *StorageMinerAPI) WorkerTaskStats(ctx context.Context, since time.Time) ([]storiface.WorkerTaskStats, error) {
+	return sm.StorageMgr.WorkerTaskStats(ctx, since)
+}
+
+func (sm *StorageMinerAPI) WorkerGPUStats(ctx context.Context) ([]storiface.WorkerGPUStats, error) {
+	return sm.StorageMgr.WorkerGPUStats(ctx), nil
+}
+
 func (sm *StorageMinerAPI) ActorAddress(context.Context) (address.Address, error) {
 	return sm.Miner.Address(), nil
 }
@@ -281,6 +309,153 @@ func (sm *StorageMinerAPI) SectorUnseal(ctx context.Context, sectorNum abi.Secto
 	return sm.StorageMgr.SectorsUnsealPiece(ctx, sector, storiface.UnpaddedByteIndex(0), abi.UnpaddedPieceSize(0), status.Ticket.Value, status.CommD)
 }
 
+func (sm *StorageMinerAPI) ProofParamsStatus(ctx context.Context, sizes []abi.SectorSize, verify bool) (api.ProofParamsStatus, error) {
+	return paramcache.Status(ctx, sizes, verify)
+}
+
+func (sm *StorageMinerAPI) ProofParamsFetch(ctx context.Context, sizes []abi.SectorSize, mirror string) error {
+	return paramcache.Fetch(ctx, sizes, mirror)
+}
+
+func (sm *StorageMinerAPI) PiecesHotness(ctx context.Context) ([]api.PieceHotness, error) {
+	if sm.Hotness == nil {
+		return nil, xerrors.Errorf("piece hotness tracking not available")
+	}
+	return sm.Hotness.List(ctx)
+}
+
+func (sm *StorageMinerAPI) SectorsReconcileUnsealed(ctx context.Context, sectors []abi.SectorNumber, apply bool) ([]api.UnsealedReconcileAction, error) {
+	var infos []sealing.SectorInfo
+	if len(sectors) == 0 {
+		all, err := sm.Miner.ListSectors()
+		if err != nil {
+			return nil, xerrors.Errorf("listing sectors: %w", err)
+		}
+		for _, si := range all {
+			if si.State == sealing.Proving || si.State == sealing.Available {
+				infos = append(infos, si)
+			}
+		}
+	} else {
+		for _, sn := range sectors {
+			si, err := sm.Miner.GetSectorInfo(sn)
+			if err != nil {
+				return nil, xerrors.Errorf("getting sector %d info: %w", sn, err)
+			}
+			if si.State != sealing.Proving && si.State != sealing.Available {
+				return nil, xerrors.Errorf("sector %d is in state %s, only proving sectors can be reconciled", sn, si.State)
+			}
+			infos = append(infos, si)
+		}
+	}
+
+	minerAddr, err := sm.ActorAddress(ctx)
+	if err != nil {
+		return nil, err
+	}
+	minerID, err := address.IDFromAddress(minerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	out := []api.UnsealedReconcileAction{}
+	for _, si := range infos {
+		ref := storiface.SectorRef{
+			ID:        abi.SectorID{Miner: abi.ActorID(minerID), Number: si.SectorNumber},
+			ProofType: si.SectorType,
+		}
+
+		keep, err := sm.Miner.UnsealedRanges(ctx, si)
+		if err != nil {
+			return nil, err
+		}
+
+		stores, err := sm.StorageFindSector(ctx, ref.ID, storiface.FTUnsealed, 0, false)
+		if err != nil {
+			return nil, xerrors.Errorf("finding unsealed copy of sector %d: %w", si.SectorNumber, err)
+		}
+
+		// check which piece ranges the unsealed file actually covers, a file
+		// existing doesn't mean the kept pieces are unsealed in it
+		var missing, extra []storiface.Range
+		var at abi.UnpaddedPieceSize
+		for _, piece := range si.Pieces {
+			r := storiface.Range{Offset: at, Size: piece.Piece.Size.Unpadded()}
+			at += r.Size
+
+			unsealed := false
+			if len(stores) > 0 {
+				unsealed, err = sm.RemoteStore.CheckIsUnsealed(ctx, ref, r.Offset.Padded(), r.Size.Padded())
+				if err != nil {
+					return nil, xerrors.Errorf("checking unsealed range %d+%d of sector %d: %w", r.Offset, r.Size, si.SectorNumber, err)
+				}
+			}
+
+			switch kept := containsRange(keep, r); {
+			case kept && !unsealed:
+				missing = append(missing, r)
+			case !kept && unsealed:
+				extra = append(extra, r)
+			}
+		}
+
+		var acts []api.UnsealedReconcileAction
+		switch {
+		case len(stores) > 0 && len(keep) == 0:
+			acts = append(acts, api.UnsealedReconcileAction{Sector: si.SectorNumber, Action: api.UnsealedActionRemove})
+		case len(extra) > 0:
+			acts = append(acts, api.UnsealedReconcileAction{Sector: si.SectorNumber, Action: api.UnsealedActionTrim, Keep: keep})
+		}
+		if len(missing) > 0 {
+			acts = append(acts, api.UnsealedReconcileAction{Sector: si.SectorNumber, Action: api.UnsealedActionUnseal, Keep: keep, Missing: missing})
+		}
+
+		for _, act := range acts {
+			if apply {
+				if err := sm.applyUnsealedAction(ctx, ref, si, act); err != nil {
+					log.Errorw("reconciling unsealed copy", "sector", si.SectorNumber, "action", act.Action, "error", err)
+					act.Error = err.Error()
+				} else {
+					act.Applied = true
+				}
+			}
+
+			out = append(out, act)
+		}
+	}
+
+	return out, nil
+}
+
+func (sm *StorageMinerAPI) applyUnsealedAction(ctx context.Context, ref storiface.SectorRef, si sealing.SectorInfo, act api.UnsealedReconcileAction) error {
+	switch act.Action {
+	case api.UnsealedActionRemove, api.UnsealedActionTrim:
+		return sm.StorageMgr.ReleaseUnsealed(ctx, ref, act.Keep)
+	case api.UnsealedActionUnseal:
+		commD := si.CommD
+		if si.CCUpdate {
+			commD = si.UpdateUnsealed
+		}
+		for _, r := range act.Missing {
+			if err := sm.StorageMgr.SectorsUnsealPiece(ctx, ref, storiface.UnpaddedByteIndex(r.Offset), r.Size, si.TicketValue, commD); err != nil {
+				return xerrors.Errorf("unsealing range %d+%d: %w", r.Offset, r.Size, err)
+			}
+		}
+		return nil
+	default:
+		return xerrors.Errorf("unknown action %s", act.Action)
+	}
+}
+
+func containsRange(rs []storiface.Range, r storiface.Range) bool {
+	for _, c := range rs {
+		if c.Offset <= r.Offset && r.Offset+r.Size <= c.Offset+c.Size {
+			return true
+		}
+	}
+	return false
+}
+
 // List all staged sectors
 func (sm *StorageMinerAPI) SectorsList(context.Context) ([]abi.SectorNumber, error) {
 	sectors, err := sm.Miner.ListSectors()
@@ -342,6 +517,188 @@ func (sm *StorageMinerAPI) SectorsSummary(ctx context.Context) (map[api.SectorSt
 	return out, nil
 }
 
+// sectorsStreamChunk is the number of sector records sent in each chunk of
+// SectorsSummaryStream.
+const sectorsStreamChunk = 1024
+
+func (sm *StorageMinerAPI) SectorsSummaryStream(ctx context.Context) (<-chan []byte, error) {
+	head, err := sm.Full.ChainHead(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	out := make(chan []byte)
+	go func() {
+		defer close(out)
+
+		w := &sectorRecordWriter{ctx: ctx, out: out}
+		err := sm.streamSectors(ctx, head.Key(), w)
+		if err == nil {
+			err = w.flush()
+		}
+		if err != nil && ctx.Err() == nil {
+			log.Errorw("streaming sector records", "error", err)
+			w.fail(err)
+		}
+	}()
+
+	return out, nil
+}
+
+// streamSectors writes the records of the sectors known on chain or locally,
+// in sector number order, as they're built.
+func (sm *StorageMinerAPI) streamSectors(ctx context.Context, tsk types.TipSetKey, w *sectorRecordWriter) error {
+	maddr := sm.Miner.Address()
+
+	onChain, err := sm.Full.StateMinerSectors(ctx, maddr, nil, tsk)
+	if err != nil {
+		return xerrors.Errorf("getting on-chain sectors: %w", err)
+	}
+	sort.Slice(onChain, func(i, j int) bool {
+		return onChain[i].SectorNumber < onChain[j].SectorNumber
+	})
+
+	type location struct {
+		deadline, partition uint64
+	}
+	locations := make(map[abi.SectorNumber]location, len(onChain))
+
+	deadlines, err := sm.Full.StateMinerDeadlines(ctx, maddr, tsk)
+	if err != nil {
+		return xerrors.Errorf("getting deadlines: %w", err)
+	}
+	for dlIdx := range deadlines {
+		partitions, err := sm.Full.StateMinerPartitions(ctx, maddr, uint64(dlIdx), tsk)
+		if err != nil {
+			return xerrors.Errorf("getting partitions of deadline %d: %w", dlIdx, err)
+		}
+		for partIdx, part := range partitions {
+			err := part.AllSectors.ForEach(func(sn uint64) error {
+				locations[abi.SectorNumber(sn)] = location{deadline: uint64(dlIdx), partition: uint64(partIdx)}
+				return nil
+			})
+			if err != nil {
+				return xerrors.Errorf("iterating sectors of deadline %d partition %d: %w", dlIdx, partIdx, err)
+			}
+		}
+	}
+
+	sectors, err := sm.Miner.ListSectors()
+	if err != nil {
+		return xerrors.Errorf("listing sectors: %w", err)
+	}
+	sort.Slice(sectors, func(i, j int) bool {
+		return sectors[i].SectorNumber < sectors[j].SectorNumber
+	})
+
+	// merge the on-chain and local sectors, both sorted by number
+	i, j := 0, 0
+	for i < len(onChain) || j < len(sectors) {
+		if j < len(sectors) && sectors[j].State == sealing.UndefinedSectorState {
+			j++ // sector ID not set yet
+			continue
+		}
+
+		var rec *api.SectorRecord
+		switch {
+		case j == len(sectors) || (i < len(onChain) && onChain[i].SectorNumber <= sectors[j].SectorNumber):
+			info := onChain[i]
+			loc := locations[info.SectorNumber]
+			rec = &api.SectorRecord{
+				Number:        info.SectorNumber,
+				SealProof:     info.SealProof,
+				DealIDs:       info.DealIDs,
+				OnChain:       true,
+				Activation:    info.Activation,
+				Expiration:    info.Expiration,
+				InitialPledge: info.InitialPledge,
+				Deadline:      loc.deadline,
+				Partition:     loc.partition,
+			}
+			if j < len(sectors) && sectors[j].SectorNumber == info.SectorNumber {
+				rec.State = api.SectorState(sectors[j].State)
+				j++
+			}
+			i++
+		default:
+			sector := &sectors[j]
+			rec = &api.SectorRecord{
+				Number:        sector.SectorNumber,
+				State:         api.SectorState(sector.State),
+				SealProof:     sector.SectorType,
+				InitialPledge: big.Zero(),
+			}
+			for _, p := range sector.Pieces {
+				if p.DealInfo != nil {
+					rec.DealIDs = append(rec.DealIDs, p.DealInfo.DealID)
+				}
+			}
+			j++
+		}
+
+		if err := w.write(rec); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sectorRecordWriter sends sector records in chunks of sectorsStreamChunk.
+type sectorRecordWriter struct {
+	ctx context.Context
+	out chan<- []byte
+
+	buf bytes.Buffer
+	n   int
+}
+
+func (w *sectorRecordWriter) write(rec *api.SectorRecord) error {
+	if err := api.WriteSectorRecord(&w.buf, rec); err != nil {
+		return xerrors.Errorf("encoding the record of sector %d: %w", rec.Number, err)
+	}
+	w.n++
+	if w.n%sectorsStreamChunk != 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *sectorRecordWriter) flush() error {
+	if w.buf.Len() == 0 {
+		return nil
+	}
+
+	chunk := make([]byte, w.buf.Len())
+	copy(chunk, w.buf.Bytes())
+	w.buf.Reset()
+
+	select {
+	case w.out <- chunk:
+		return nil
+	case <-w.ctx.Done():
+		return w.ctx.Err()
+	}
+}
+
+// fail sends the records written so far, then a terminal record carrying the
+// error, so that consumers don't mistake the stream for a complete one.
+func (w *sectorRecordWriter) fail(err error) {
+	var buf bytes.Buffer
+	if werr := api.WriteSectorRecord(&buf, &api.SectorRecord{Error: err.Error()}); werr != nil {
+		log.Errorw("encoding sector stream error", "error", werr)
+		return
+	}
+	// records which failed to encode were not written to the buffer
+	if w.flush() != nil {
+		return
+	}
+	select {
+	case w.out <- buf.Bytes():
+	case <-w.ctx.Done():
+	}
+}
+
 func (sm *StorageMinerAPI) StorageLocal(ctx context.Context) (map[storiface.ID]string, error) {
 	l, err := sm.LocalStore.Local(ctx)
 	if err != nil {
@@ -407,6 +764,100 @@ func (sm *StorageMinerAPI) SectorGetExpectedSealDuration(ctx context.Context) (t
 	return sm.GetExpectedSealDurationFunc()
 }
 
+func (sm *StorageMinerAPI) SealingPipelineStats(ctx context.Context, days int) (*api.SealingPipelineStats, error) {
+	return sm.Miner.PipelineStats(ctx, days)
+}
+
+func (sm *StorageMinerAPI) SealingCostEstimate(ctx context.Context, params api.SealingCostEstimateParams) (*api.SealingCostEstimate, error) {
+	if params.Sectors <= 0 {
+		return nil, xerrors.Errorf("sector count must be positive")
+	}
+
+	cfg, err := sm.GetSealingConfigFunc()
+	if err != nil {
+		return nil, xerrors.Errorf("getting sealing config: %w", err)
+	}
+
+	head, err := sm.Full.ChainHead(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	nv, err := sm.Full.StateNetworkVersion(ctx, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting network version: %w", err)
+	}
+
+	maddr := sm.Miner.Address()
+	mi, err := sm.Full.StateMinerInfo(ctx, maddr, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting miner info: %w", err)
+	}
+
+	est := &api.SealingCostEstimate{
+		Sectors:        params.Sectors,
+		Height:         head.Height(),
+		NetworkVersion: nv,
+		BaseFee:        head.MinTicketBlock().ParentBaseFee,
+	}
+
+	ssize := params.SectorSize
+	if ssize == 0 || ssize == mi.SectorSize {
+		ssize = mi.SectorSize
+		est.SealProof, err = lminer.PreferredSealProofTypeFromWindowPoStType(nv, mi.WindowPoStProofType)
+	} else {
+		est.SealProof, err = lminer.SealProofTypeFromSectorSize(ssize, nv)
+	}
+	if err != nil {
+		return nil, xerrors.Errorf("getting seal proof type: %w", err)
+	}
+
+	if uint64(params.DealSpace)+uint64(params.VerifiedDealSpace) > uint64(ssize) {
+		return nil, xerrors.Errorf("deal space exceeds the sector size %s", ssize.ShortString())
+	}
+
+	duration := params.Duration
+	if duration == 0 {
+		duration = abi.ChainEpoch(uint64(cfg.CommittedCapacitySectorLifetime.Seconds()) / builtin.EpochDurationSeconds)
+		if duration == 0 {
+			duration = policy.GetMaxSectorExpirationExtension()
+		}
+	}
+	est.Expiration = head.Height() + duration
+
+	pci := minertypes.SectorPreCommitInfo{
+		SealProof:  est.SealProof,
+		Expiration: est.Expiration,
+	}
+	deposit, err := sm.Full.StateMinerPreCommitDepositForPower(ctx, maddr, pci, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting precommit deposit: %w", err)
+	}
+	pledge, err := sm.Full.StateMinerInitialPledgeCollateral(ctx, maddr, pci, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting initial pledge: %w", err)
+	}
+
+	// collateral is proportional to the power of the sectors, which is computed
+	// here from the deal space instead of deals published on chain
+	ccPower := builtin.QAPowerForWeight(ssize, duration, big.Zero(), big.Zero())
+	est.QAPower = builtin.QAPowerForWeight(ssize, duration,
+		big.Mul(big.NewInt(int64(params.DealSpace)), big.NewInt(int64(duration))),
+		big.Mul(big.NewInt(int64(params.VerifiedDealSpace)), big.NewInt(int64(duration))))
+	est.InitialPledge = big.Div(big.Mul(pledge, est.QAPower), ccPower)
+	est.PreCommitDeposit = deposit
+	if nv < network.Version17 {
+		// since nv17 the deposit is computed for the maximum sector power
+		est.PreCommitDeposit = big.Div(big.Mul(deposit, est.QAPower), ccPower)
+	}
+
+	if err := sealing.EstimateOnboardingCost(cfg, est); err != nil {
+		return nil, err
+	}
+
+	return est, nil
+}
+
 func (sm *StorageMinerAPI) SectorsUpdate(ctx context.Context, id abi.SectorNumber, state api.SectorState) error {
 	return sm.Miner.ForceSectorState(ctx, id, sealing.SectorState(state))
 }
@@ -1231,6 +1682,16 @@ func (sm *StorageMinerAPI) DealsSetConsiderUnverifiedStorageDeals(ctx context.Co
 	return sm.SetConsiderUnverifiedStorageDealsConfigFunc(b)
 }
 
+func (sm *StorageMinerAPI) DealsFilterDecisions(ctx context.Context, limit int) ([]api.DealFilterDecision, error) {
+	if sm.FilterDecisions == nil {
+		return nil, xerrors.Errorf("the markets subsystem is not enabled")
+	}
+	if limit <= 0 {
+		return nil, xerrors.Errorf("limit must be positive")
+	}
+	return sm.FilterDecisions.List(ctx, limit)
+}
+
 func (sm *StorageMinerAPI) DealsGetExpectedSealDurationFunc(ctx context.Context) (time.Duration, error) {
 	return sm.GetExpectedSealDurationFunc()
 }
@@ -1310,6 +1771,10 @@ func (sm *StorageMinerAPI) CreateBackup(ctx context.Context, fpath string) error
 	return backup(ctx, sm.DS, fpath)
 }
 
+func (sm *StorageMinerAPI) BackupCreate(ctx context.Context, fpath string, opts api.BackupOptions) (*api.BackupInfo, error) {
+	return backupCreate(ctx, sm.Repo, sm.DS, fpath, opts)
+}
+
 func (sm *StorageMinerAPI) CheckProvable(ctx context.Context, pp abi.RegisteredPoStProof, sectors []storiface.SectorRef) (map[abi.SectorNumber]string, error) {
 	rg := func(ctx context.Context, id abi.SectorID) (cid.Cid, bool, error) {
 		si, err := sm.Miner.SectorsStatus(ctx, id.Number, false)
@@ -1427,3 +1892,172 @@ func (sm *StorageMinerAPI) withdrawBalance(ctx context.Context, amount abi.Token
 
 	return smsg.Cid(), nil
 }
+
+func (sm *StorageMinerAPI) ProvingSimulate(ctx context.Context, params api.ProvingSimulateParams) (*api.ProvingSimulation, error) {
+	if params.Outage <= 0 {
+		return nil, xerrors.Errorf("outage must be positive")
+	}
+	if len(params.Sectors) == 0 && len(params.StoragePaths) == 0 {
+		return nil, xerrors.Errorf("no sectors or storage paths to simulate the outage of")
+	}
+
+	maddr := sm.Miner.Address()
+	mid, err := address.IDFromAddress(maddr)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := map[abi.SectorNumber]struct{}{}
+	for _, s := range params.Sectors {
+		requested[s] = struct{}{}
+	}
+
+	// the file types of the sectors on the missing storage paths, and on the
+	// remaining ones
+	onMissing := map[abi.SectorNumber]struct{}{}
+	remaining := map[abi.SectorNumber]storiface.SectorFileType{}
+	if len(params.StoragePaths) > 0 {
+		decls, err := sm.StorageList(ctx)
+		if err != nil {
+			return nil, xerrors.Errorf("listing storage: %w", err)
+		}
+
+		missing := map[storiface.ID]struct{}{}
+		for _, id := range params.StoragePaths {
+			if _, ok := decls[id]; !ok {
+				return nil, xerrors.Errorf("storage path %s not found", id)
+			}
+			missing[id] = struct{}{}
+		}
+
+		for id, ds := range decls {
+			_, isMissing := missing[id]
+			for _, d := range ds {
+				if d.Miner != abi.ActorID(mid) {
+					continue
+				}
+				if isMissing {
+					onMissing[d.Number] = struct{}{}
+				} else {
+					remaining[d.Number] |= d.SectorFileType
+				}
+			}
+		}
+	}
+
+	head, err := sm.Full.ChainHead(ctx)
+	if err != nil {
+		return nil, xerrors.Errorf("getting chain head: %w", err)
+	}
+	di, err := sm.Full.StateMinerProvingDeadline(ctx, maddr, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting proving deadline: %w", err)
+	}
+	mi, err := sm.Full.StateMinerInfo(ctx, maddr, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting miner info: %w", err)
+	}
+
+	stor := store.ActorStore(ctx, blockstore.NewAPIBlockstore(sm.Full))
+	mact, err := sm.Full.StateGetActor(ctx, maddr, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting miner actor: %w", err)
+	}
+	mas, err := lminer.Load(stor, mact)
+	if err != nil {
+		return nil, xerrors.Errorf("loading miner actor state: %w", err)
+	}
+
+	// the active sectors considered, and their deadlines
+	var skipped []abi.SectorNumber
+	deadlines := map[abi.SectorNumber]uint64{}
+	err = mas.ForEachDeadline(func(dlIdx uint64, dl lminer.Deadline) error {
+		return dl.ForEachPartition(func(_ uint64, part lminer.Partition) error {
+			active, err := part.ActiveSectors()
+			if err != nil {
+				return err
+			}
+			return active.ForEach(func(n uint64) error {
+				sn := abi.SectorNumber(n)
+				_, req := requested[sn]
+				_, miss := onMissing[sn]
+				if req || miss {
+					deadlines[sn] = dlIdx
+				}
+				return nil
+			})
+		})
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("loading active sectors: %w", err)
+	}
+
+	for sn := range requested {
+		if _, ok := deadlines[sn]; !ok {
+			skipped = append(skipped, sn)
+		}
+	}
+
+	bf := bitfield.New()
+	for sn := range deadlines {
+		bf.Set(uint64(sn))
+	}
+	infos, err := mas.LoadSectors(&bf)
+	if err != nil {
+		return nil, xerrors.Errorf("loading sector infos: %w", err)
+	}
+
+	var sectors []wdpost.SimulatedSector
+	for _, si := range infos {
+		if _, req := requested[si.SectorNumber]; !req {
+			// available if the remaining paths hold a provable replica
+			ft := remaining[si.SectorNumber]
+			provable := ft.Has(storiface.FTSealed) && ft.Has(storiface.FTCache)
+			if si.SectorKeyCID != nil {
+				provable = ft.Has(storiface.FTUpdate) && ft.Has(storiface.FTUpdateCache)
+			}
+			if provable {
+				continue
+			}
+		}
+
+		sectors = append(sectors, wdpost.SimulatedSector{
+			Number:   si.SectorNumber,
+			Deadline: deadlines[si.SectorNumber],
+			RawPower: big.NewInt(int64(mi.SectorSize)),
+			QAPower:  minertypes.QAPowerForSector(mi.SectorSize, si),
+		})
+	}
+
+	ract, err := sm.Full.StateGetActor(ctx, reward.Address, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting reward actor: %w", err)
+	}
+	rst, err := reward.Load(stor, ract)
+	if err != nil {
+		return nil, xerrors.Errorf("loading reward actor state: %w", err)
+	}
+	rewardSmoothed, err := rst.ThisEpochRewardSmoothed()
+	if err != nil {
+		return nil, xerrors.Errorf("getting reward estimate: %w", err)
+	}
+
+	pact, err := sm.Full.StateGetActor(ctx, power.Address, head.Key())
+	if err != nil {
+		return nil, xerrors.Errorf("getting power actor: %w", err)
+	}
+	pst, err := power.Load(stor, pact)
+	if err != nil {
+		return nil, xerrors.Errorf("loading power actor state: %w", err)
+	}
+	powerSmoothed, err := pst.TotalPowerSmoothed()
+	if err != nil {
+		return nil, xerrors.Errorf("getting network power estimate: %w", err)
+	}
+
+	sim := wdpost.SimulateOutage(di, sectors, params.Outage, minertypes.FaultMaxAge, wdpost.FaultFee(rewardSmoothed, powerSmoothed))
+
+	sort.Slice(skipped, func(i, j int) bool { return skipped[i] < skipped[j] })
+	sim.Skipped = skipped
+	return sim, nil
+}
@@ -5,19 +5,20 @@ import (
 	"path/filepath"
 	"time"
 
-	"github.com/multiformats/go-varint"
 	"go.uber.org/fx"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
-	builtintypes "github.com/filecoin-project/go-state-types/builtin"
 
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/events/filter/indexservice"
 	"github.com/filecoin-project/lotus/chain/messagepool"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/f4"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl/full"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
@@ -33,13 +34,14 @@ type EventAPI struct {
 
 var _ events.EventAPI = &EventAPI{}
 
-func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI) (*full.EthEvent, error) {
+func EthEventAPI(cfg config.FevmConfig, subs config.SubscriptionsConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI) (*full.EthEvent, error) {
 	return func(mctx helpers.MetricsCtx, r repo.LockedRepo, lc fx.Lifecycle, cs *store.ChainStore, sm *stmgr.StateManager, evapi EventAPI, mp *messagepool.MessagePool, stateapi full.StateAPI, chainapi full.ChainAPI) (*full.EthEvent, error) {
 		ctx := helpers.LifecycleCtx(mctx, lc)
 
 		ee := &full.EthEvent{
 			Chain:                cs,
 			MaxFilterHeightRange: abi.ChainEpoch(cfg.Events.MaxFilterHeightRange),
+			ResumeWindow:         subs.ResumeWindow,
 			SubscribtionCtx:      ctx,
 		}
 
@@ -65,8 +67,21 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 		})
 
 		// Enable indexing of actor events
-		var eventIndex *filter.EventIndex
-		if !cfg.Events.DisableHistoricFilterAPI {
+		var eventIndex filter.EventIndexer
+		if !cfg.Events.DisableHistoricFilterAPI && cfg.Events.IndexServiceAddr != "" {
+			client, closer, err := indexservice.NewClient(ctx, cfg.Events.IndexServiceAddr, cfg.Events.IndexServiceToken, cfg.Events.IndexServiceReadOnly)
+			if err != nil {
+				return nil, err
+			}
+			eventIndex = client
+
+			lc.Append(fx.Hook{
+				OnStop: func(context.Context) error {
+					closer()
+					return nil
+				},
+			})
+		} else if !cfg.Events.DisableHistoricFilterAPI {
 			var dbPath string
 			if cfg.Events.DatabasePath == "" {
 				sqlitePath, err := r.SqlitePath()
@@ -78,19 +93,49 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 				dbPath = cfg.Events.DatabasePath
 			}
 
-			var err error
-			eventIndex, err = filter.NewEventIndex(dbPath)
+			index, err := filter.NewEventIndex(dbPath)
 			if err != nil {
 				return nil, err
 			}
+			eventIndex = index
 
 			lc.Append(fx.Hook{
 				OnStop: func(context.Context) error {
-					return eventIndex.Close()
+					return index.Close()
 				},
 			})
 		}
 
+		if eventIndex != nil && (len(cfg.Events.IndexAllowedAddresses) > 0 || len(cfg.Events.IndexDeniedAddresses) > 0) {
+			allowed, err := parseEventAddresses(cfg.Events.IndexAllowedAddresses)
+			if err != nil {
+				return nil, xerrors.Errorf("event index allowed addresses: %w", err)
+			}
+			denied, err := parseEventAddresses(cfg.Events.IndexDeniedAddresses)
+			if err != nil {
+				return nil, xerrors.Errorf("event index denied addresses: %w", err)
+			}
+
+			ri := filter.NewRetainingIndex(eventIndex, allowed, denied)
+			eventIndex = ri
+
+			if cfg.Events.IndexPruneNotRetained {
+				lc.Append(fx.Hook{
+					OnStart: func(context.Context) error {
+						go func() {
+							n, err := ri.Prune(ctx)
+							if err != nil {
+								log.Errorf("pruning the events of addresses which aren't retained from the event index: %s", err)
+								return
+							}
+							log.Infof("pruned %d events of addresses which aren't retained from the event index", n)
+						}()
+						return nil
+					},
+				})
+			}
+		}
+
 		ee.EventFilterManager = &filter.EventFilterManager{
 			ChainStore: cs,
 			EventIndex: eventIndex, // will be nil unless EnableHistoricFilterAPI is true
@@ -110,8 +155,12 @@ func EthEventAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo
 				if actor.Address.Protocol() != address.Delegated {
 					return address.Undef, false
 				}
-				// we have an f4 address, make sure it's assigned by the EAM
-				if namespace, _, err := varint.FromUvarint(actor.Address.Payload()); err != nil || namespace != builtintypes.EthereumAddressManagerActorID {
+				// we have an f4 address, make sure it's in a registered namespace, such as the EAM's
+				namespace, _, err := f4.Split(*actor.Address)
+				if err != nil {
+					return address.Undef, false
+				}
+				if _, ok := f4.Lookup(namespace); !ok {
 					return address.Undef, false
 				}
 				return *actor.Address, true
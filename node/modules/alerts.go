@@ -1,8 +1,29 @@
 package modules
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/clockcheck"
+	"github.com/filecoin-project/lotus/lib/paramcache"
 	"github.com/filecoin-project/lotus/lib/ulimit"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
 )
 
 func CheckFdLimit(min uint64) func(al *alerting.Alerting) {
@@ -42,6 +63,291 @@ func LegacyMarketsEOL(al *alerting.Alerting) {
 	})
 }
 
+// ProofParamsCheckInterval is how often the proof parameters needed by the node
+// are checked for
+var ProofParamsCheckInterval = time.Hour
+
+// CheckProofParams raises an alert while proof parameter files needed for the
+// sector size of the miner are missing from the parameter directory.
+func CheckProofParams(prover bool) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, al *alerting.Alerting, spt abi.RegisteredSealProof) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, al *alerting.Alerting, spt abi.RegisteredSealProof) error {
+		ssize, err := spt.SectorSize()
+		if err != nil {
+			return err
+		}
+
+		files, err := paramcache.Files([]abi.SectorSize{ssize})
+		if err != nil {
+			return err
+		}
+		if !prover {
+			// nodes which don't compute proofs only need the verification keys
+			var vks []paramcache.File
+			for _, f := range files {
+				if !strings.HasSuffix(f.Name, ".params") {
+					vks = append(vks, f)
+				}
+			}
+			files = vks
+		}
+
+		alert := al.AddAlertType("proofs", "missing-params")
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		check := func(raised bool) bool {
+			var missing []string
+			for _, f := range paramcache.Check(ctx, files, false) {
+				if !f.Present {
+					missing = append(missing, f.Name)
+				}
+			}
+
+			if len(missing) > 0 {
+				al.Raise(alert, map[string]interface{}{
+					"message": "proof parameters needed for " + ssize.ShortString() + " sectors are missing, fetch them with lotus-miner proof-params fetch",
+					"dir":     paramcache.Dir(),
+					"missing": missing,
+				})
+				return true
+			}
+			if raised {
+				al.Resolve(alert, map[string]string{
+					"message": "proof parameters are present",
+				})
+			}
+			return false
+		}
+
+		go func() {
+			raised := check(false)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(ProofParamsCheckInterval):
+				}
+				raised = check(raised)
+			}
+		}()
+
+		return nil
+	}
+}
+
+// CheckClock periodically measures the offset of the system clock against NTP
+// servers, and raises an alert while it's above the threshold.
+func CheckClock(cfg config.ClockCheck) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, al *alerting.Alerting) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, al *alerting.Alerting) {
+		alert := al.AddAlertType("system", "clock-skew")
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		threshold := time.Duration(cfg.Threshold)
+		interval := time.Duration(cfg.Interval)
+		if interval <= 0 {
+			interval = 10 * time.Minute
+		}
+
+		check := func(raised bool) bool {
+			qctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+			defer cancel()
+
+			m, err := clockcheck.QueryAny(qctx, cfg.Servers)
+			if err != nil {
+				log.Warnw("measuring clock offset failed", "error", err)
+				return raised
+			}
+			clockcheck.Record(m, threshold)
+
+			if clockcheck.Skewed(m, threshold) {
+				direction := "behind"
+				if m.Offset < 0 {
+					direction = "ahead of"
+				}
+				log.Warnw("system clock is skewed", "offset", m.Offset, "server", m.Server)
+				al.Raise(alert, map[string]interface{}{
+					"message": fmt.Sprintf("the system clock is %s %s NTP time, blocks may be rejected or mined late; check the time synchronization of this host", m.Offset.Abs().Round(time.Millisecond), direction),
+					"offset":  m.Offset.Seconds(),
+					"server":  m.Server,
+				})
+				return true
+			}
+			if raised {
+				al.Resolve(alert, map[string]interface{}{
+					"message": "the system clock is synchronized",
+					"offset":  m.Offset.Seconds(),
+				})
+			}
+			return false
+		}
+
+		go func() {
+			raised := check(false)
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(interval):
+				}
+				raised = check(raised)
+			}
+		}()
+	}
+}
+
+// UpgradeCheckInterval is how often the upgrade schedule is checked for
+// upgrades the node isn't ready for
+var UpgradeCheckInterval = 10 * time.Minute
+
+// upgradeAlertLevels are the times before an unsupported upgrade at which its
+// alert is raised again, with more urgency.
+var upgradeAlertLevels = []struct {
+	before  time.Duration
+	urgency string
+}{
+	{7 * 24 * time.Hour, "warning"},
+	{24 * time.Hour, "urgent"},
+	{time.Hour, "critical"},
+}
+
+// CheckUpgradeSchedule checks that this binary is ready to perform the
+// upcoming network upgrades, and raises an alert as an upgrade it isn't ready
+// for approaches, escalating as the upgrade gets closer. The upgrades of its
+// own schedule are checked for their actors bundle and state migration, and
+// the announced upgrades for being in the schedule at the same epoch, as
+// upgrades which only newer releases know about can't be performed.
+func CheckUpgradeSchedule(mctx helpers.MetricsCtx, lc fx.Lifecycle, al *alerting.Alerting, us stmgr.UpgradeSchedule, announcements dtypes.UpgradeAnnouncementsFunc, cs *store.ChainStore) error {
+	alert := al.AddAlertType("chain", "upgrade-not-ready")
+	ctx := helpers.LifecycleCtx(mctx, lc)
+
+	gen, err := cs.GetGenesis(ctx)
+	if err != nil {
+		return xerrors.Errorf("getting genesis: %w", err)
+	}
+
+	// level is the index of the last raised alert level, -1 when not raised
+	check := func(level int) int {
+		head := cs.GetHeaviestTipSet()
+		if head == nil {
+			return level
+		}
+
+		announced, err := announcements(ctx)
+		if err != nil {
+			log.Warnw("getting announced network upgrades", "error", err)
+		}
+
+		for _, u := range us.Upcoming(head.Height(), gen.Timestamp, announced) {
+			if u.Supported {
+				continue
+			}
+
+			eta := time.Until(u.ETA)
+			next := -1
+			for i, l := range upgradeAlertLevels {
+				if eta <= l.before {
+					next = i
+				}
+			}
+			if next < 0 {
+				break
+			}
+			if next > level {
+				al.Raise(alert, map[string]interface{}{
+					"message": fmt.Sprintf("the network upgrades to version %d at epoch %d, in %s, which this binary can't perform: %s; upgrade lotus before then",
+						u.Network, u.Height, eta.Round(time.Minute), u.Reason),
+					"urgency": upgradeAlertLevels[next].urgency,
+					"network": u.Network,
+					"height":  u.Height,
+					"eta":     u.ETA,
+				})
+			}
+			return next
+		}
+
+		if level >= 0 {
+			al.Resolve(alert, map[string]string{
+				"message": "this binary is ready for the upcoming network upgrades",
+			})
+		}
+		return -1
+	}
+
+	go func() {
+		level := check(-1)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(UpgradeCheckInterval):
+			}
+			level = check(level)
+		}
+	}()
+
+	return nil
+}
+
+// NoUpgradeAnnouncements is the UpgradeAnnouncementsFunc of nodes without
+// announced upgrades.
+func NoUpgradeAnnouncements() dtypes.UpgradeAnnouncementsFunc {
+	return func(context.Context) ([]dtypes.AnnouncedUpgrade, error) {
+		return nil, nil
+	}
+}
+
+// upgradeAnnouncementsTimeout bounds the time spent fetching the announced
+// upgrades from the announcements URL.
+const upgradeAnnouncementsTimeout = 30 * time.Second
+
+// maxUpgradeAnnouncements is the largest response of the announcements URL.
+const maxUpgradeAnnouncements = 1 << 20
+
+// ConfiguredUpgradeAnnouncements returns the upgrades announced in the config,
+// along with those fetched from its announcements URL.
+func ConfiguredUpgradeAnnouncements(cfg config.UpgradesConfig) func() dtypes.UpgradeAnnouncementsFunc {
+	return func() dtypes.UpgradeAnnouncementsFunc {
+		return func(ctx context.Context) ([]dtypes.AnnouncedUpgrade, error) {
+			var out []dtypes.AnnouncedUpgrade
+			for _, u := range cfg.Announced {
+				out = append(out, dtypes.AnnouncedUpgrade{Network: network.Version(u.Network), Height: abi.ChainEpoch(u.Height)})
+			}
+			if cfg.AnnouncementsURL == "" {
+				return out, nil
+			}
+
+			fetched, err := fetchUpgradeAnnouncements(ctx, cfg.AnnouncementsURL)
+			if err != nil {
+				return out, xerrors.Errorf("fetching announced upgrades from %s: %w", cfg.AnnouncementsURL, err)
+			}
+			return append(out, fetched...), nil
+		}
+	}
+}
+
+func fetchUpgradeAnnouncements(ctx context.Context, url string) ([]dtypes.AnnouncedUpgrade, error) {
+	ctx, cancel := context.WithTimeout(ctx, upgradeAnnouncementsTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close() //nolint:errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, xerrors.Errorf("unexpected status %s", resp.Status)
+	}
+
+	var out []dtypes.AnnouncedUpgrade
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxUpgradeAnnouncements)).Decode(&out); err != nil {
+		return nil, xerrors.Errorf("decoding announced upgrades: %w", err)
+	}
+	return out, nil
+}
+
 // TODO: More things:
 //  * Space in repo dirs (taking into account mounts)
 //  * Miner
@@ -0,0 +1,49 @@
+package modules_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+)
+
+func TestConfiguredUpgradeAnnouncements(t *testing.T) {
+	ctx := context.Background()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/upgrades.json" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"Network": 22, "Height": 5000}]`))
+	}))
+	defer srv.Close()
+
+	configured := []config.AnnouncedUpgrade{{Network: 21, Height: 4000}}
+
+	announced, err := modules.ConfiguredUpgradeAnnouncements(config.UpgradesConfig{
+		Announced:        configured,
+		AnnouncementsURL: srv.URL + "/upgrades.json",
+	})()(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []dtypes.AnnouncedUpgrade{
+		{Network: network.Version(21), Height: 4000},
+		{Network: network.Version(22), Height: 5000},
+	}, announced)
+
+	// the configured upgrades are still returned when the URL fails
+	announced, err = modules.ConfiguredUpgradeAnnouncements(config.UpgradesConfig{
+		Announced:        configured,
+		AnnouncementsURL: srv.URL + "/missing.json",
+	})()(ctx)
+	require.Error(t, err)
+	require.Equal(t, []dtypes.AnnouncedUpgrade{{Network: network.Version(21), Height: 4000}}, announced)
+}
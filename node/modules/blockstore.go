@@ -6,13 +6,20 @@ import (
 	"os"
 	"path/filepath"
 
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
 	bstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/lotus/api/client"
 	"github.com/filecoin-project/lotus/blockstore"
 	badgerbs "github.com/filecoin-project/lotus/blockstore/badger"
 	"github.com/filecoin-project/lotus/blockstore/splitstore"
+	cliutil "github.com/filecoin-project/lotus/cli/util"
+	"github.com/filecoin-project/lotus/lib/addrutil"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
@@ -141,13 +148,71 @@ func FallbackStateBlockstore(sbs dtypes.BasicStateBlockstore) dtypes.StateBlocks
 	return &blockstore.FallbackStore{Blockstore: sbs}
 }
 
-func InitFallbackBlockstores(cbs dtypes.ChainBlockstore, sbs dtypes.StateBlockstore, rem dtypes.ChainBitswap) error {
-	for _, bs := range []bstore.Blockstore{cbs, sbs} {
-		if fbs, ok := bs.(*blockstore.FallbackStore); ok {
-			fbs.SetFallback(rem.GetBlock)
-			continue
+const archivePeerProtectTag = "state-fallback-archive"
+
+func InitFallbackBlockstores(cfg config.StateFallback) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, h host.Host, cbs dtypes.ChainBlockstore, sbs dtypes.StateBlockstore, rem dtypes.ChainBitswap) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, h host.Host, cbs dtypes.ChainBlockstore, sbs dtypes.StateBlockstore, rem dtypes.ChainBitswap) error {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		missFn := rem.GetBlock
+
+		if cfg.ArchiveAPIInfo != "" {
+			ai := cliutil.ParseApiInfo(cfg.ArchiveAPIInfo)
+			addr, err := ai.DialArgs("v1")
+			if err != nil {
+				return xerrors.Errorf("could not get DialArgs for the archival node: %w", err)
+			}
+
+			fapi, closer, err := client.NewFullNodeRPCV1(ctx, addr, ai.AuthHeader())
+			if err != nil {
+				return xerrors.Errorf("creating archival node jsonrpc client: %w", err)
+			}
+			lc.Append(fx.Hook{
+				OnStop: func(context.Context) error {
+					closer()
+					return nil
+				},
+			})
+
+			archive := blockstore.NewAPIBlockstore(fapi)
+			missFn = func(ctx context.Context, c cid.Cid) (blocks.Block, error) {
+				b, err := archive.Get(ctx, c)
+				if err == nil {
+					return b, nil
+				}
+				log.Debugw("fetching block from archival node failed, falling back to bitswap", "cid", c, "error", err)
+				return rem.GetBlock(ctx, c)
+			}
 		}
-		return xerrors.Errorf("expected a FallbackStore")
+
+		if len(cfg.ArchivePeers) > 0 {
+			pis, err := addrutil.ParseAddresses(ctx, cfg.ArchivePeers)
+			if err != nil {
+				return xerrors.Errorf("parsing archive peer addresses: %w", err)
+			}
+
+			lc.Append(fx.Hook{
+				OnStart: func(_ context.Context) error {
+					for _, pi := range pis {
+						h.ConnManager().Protect(pi.ID, archivePeerProtectTag)
+						go func(pi peer.AddrInfo) {
+							if err := h.Connect(ctx, pi); err != nil {
+								log.Warnw("failed to connect to archive peer", "peer", pi.ID, "error", err)
+							}
+						}(pi)
+					}
+					return nil
+				},
+			})
+		}
+
+		for _, bs := range []bstore.Blockstore{cbs, sbs} {
+			if fbs, ok := bs.(*blockstore.FallbackStore); ok {
+				fbs.SetFallback(missFn)
+				continue
+			}
+			return xerrors.Errorf("expected a FallbackStore")
+		}
+		return nil
 	}
-	return nil
 }
@@ -7,11 +7,14 @@ import (
 	"github.com/ipfs/boxo/bitswap"
 	"github.com/ipfs/boxo/bitswap/network"
 	"github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/routing"
 	"go.uber.org/fx"
 	"golang.org/x/xerrors"
 
+	"github.com/filecoin-project/go-state-types/abi"
+
 	"github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/blockstore/splitstore"
 	"github.com/filecoin-project/lotus/build"
@@ -23,10 +26,13 @@ import (
 	"github.com/filecoin-project/lotus/chain/gen/slashfilter"
 	"github.com/filecoin-project/lotus/chain/index"
 	"github.com/filecoin-project/lotus/chain/messagepool"
+	"github.com/filecoin-project/lotus/chain/statesync"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/chain/vm"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
 )
@@ -74,6 +80,40 @@ func MessagePool(lc fx.Lifecycle, mctx helpers.MetricsCtx, us stmgr.UpgradeSched
 	return mp, nil
 }
 
+// SetMessageSelector sets the configured message selection policy of the pool.
+func SetMessageSelector(cfg config.MessageSelectionConfig) func(mp *messagepool.MessagePool) error {
+	return func(mp *messagepool.MessagePool) error {
+		s, err := messagepool.GetSelector(cfg.Selector)
+		if err != nil {
+			return err
+		}
+		mp.SetSelector(s)
+		return nil
+	}
+}
+
+// SetMpoolSpamFilter sets the configured filter of the untrusted pushes of the
+// pool.
+func SetMpoolSpamFilter(cfg config.SpamFilterConfig) func(mp *messagepool.MessagePool) error {
+	return func(mp *messagepool.MessagePool) error {
+		f, err := messagepool.NewSpamFilter(messagepool.SpamFilterConfig{
+			MaxSenderPushes:    cfg.MaxSenderPushes,
+			SenderRateInterval: time.Duration(cfg.SenderRateInterval),
+			PressureThreshold:  cfg.PressureThreshold,
+			PressureMinPremium: abi.TokenAmount(cfg.PressureMinPremium),
+			RecentWindow:       time.Duration(cfg.RecentWindow),
+			MaxReplacements:    cfg.MaxReplacements,
+			BanThreshold:       cfg.BanThreshold,
+			BanDuration:        time.Duration(cfg.BanDuration),
+		})
+		if err != nil {
+			return xerrors.Errorf("creating mpool spam filter: %w", err)
+		}
+		mp.SetSpamFilter(f)
+		return nil
+	}
+}
+
 func ChainStore(lc fx.Lifecycle,
 	mctx helpers.MetricsCtx,
 	cbs dtypes.ChainBlockstore,
@@ -145,6 +185,9 @@ type SyncerParams struct {
 	Beacon       beacon.Schedule
 	Gent         chain.Genesis
 	Consensus    consensus.Consensus
+	MetricsCtx   helpers.MetricsCtx
+
+	StateSync *statesync.Bootstrapper `optional:"true"`
 }
 
 func NewSyncer(params SyncerParams) (*chain.Syncer, error) {
@@ -162,19 +205,67 @@ func NewSyncer(params SyncerParams) (*chain.Syncer, error) {
 		return nil, err
 	}
 
+	// a fresh node bootstrapping from a checkpoint doesn't sync from genesis
+	// in the meantime
+	bootstrap := params.StateSync
+	if bootstrap != nil && !bootstrap.Needed() {
+		bootstrap = nil
+	}
+	ctx := helpers.LifecycleCtx(params.MetricsCtx, lc)
+	started := make(chan struct{})
+
 	lc.Append(fx.Hook{
 		OnStart: func(_ context.Context) error {
-			syncer.Start()
+			if bootstrap == nil {
+				syncer.Start()
+				close(started)
+				return nil
+			}
+
+			go func() {
+				if err := bootstrap.Run(ctx); err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					log.Errorf("state sync bootstrap failed, syncing from genesis instead: %s", err)
+				}
+				syncer.Start()
+				close(started)
+			}()
 			return nil
 		},
 		OnStop: func(_ context.Context) error {
-			syncer.Stop()
+			select {
+			case <-started:
+				syncer.Stop()
+			default:
+			}
 			return nil
 		},
 	})
 	return syncer, nil
 }
 
+// StateSyncBootstrapper bootstraps fresh nodes to the configured checkpoint
+// with the state sync protocol.
+func StateSyncBootstrapper(cfg config.StateSyncConfig) func(cs *store.ChainStore, xchg exchange.Client, h host.Host) (*statesync.Bootstrapper, error) {
+	return func(cs *store.ChainStore, xchg exchange.Client, h host.Host) (*statesync.Bootstrapper, error) {
+		cids := make([]cid.Cid, len(cfg.Checkpoint))
+		for i, s := range cfg.Checkpoint {
+			c, err := cid.Decode(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing StateSync.Checkpoint block cid %q: %w", s, err)
+			}
+			cids[i] = c
+		}
+		if cfg.StateEpochs < 0 {
+			return nil, xerrors.Errorf("StateSync.StateEpochs can't be negative")
+		}
+
+		return statesync.NewBootstrapper(cs, xchg, statesync.NewClient(h), types.NewTipSetKey(cids...), abi.ChainEpoch(cfg.StateEpochs)), nil
+	}
+}
+
 func NewSlashFilter(ds dtypes.MetadataDS) *slashfilter.SlashFilter {
 	return slashfilter.New(ds)
 }
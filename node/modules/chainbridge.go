@@ -0,0 +1,74 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-datastore/namespace"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/chain/mqbridge"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/impl/full"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+type ChainBridgeAPI struct {
+	fx.In
+
+	full.ChainAPI
+	full.StateAPI
+}
+
+var _ mqbridge.API = &ChainBridgeAPI{}
+
+// RunChainBridge is called by dependency injection to publish the chain to
+// the message queue of the config while the node is running
+func RunChainBridge(cfg config.ChainBridgeConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, a ChainBridgeAPI, ds dtypes.MetadataDS) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, a ChainBridgeAPI, ds dtypes.MetadataDS) error {
+		opts := mqbridge.Options{
+			Receipts:      cfg.Receipts,
+			RetryInterval: time.Duration(cfg.RetryInterval),
+		}
+		for _, q := range cfg.EventQueries {
+			addrs, keys, err := parseSavedQuery(q)
+			if err != nil {
+				return xerrors.Errorf("chain bridge event query %q: %w", q.Name, err)
+			}
+			opts.Queries = append(opts.Queries, mqbridge.Query{
+				Name:      q.Name,
+				Addresses: addrs,
+				Keys:      keys,
+			})
+		}
+
+		pub, err := mqbridge.NewPublisher(cfg.Backend, cfg.Servers, cfg.TopicPrefix)
+		if err != nil {
+			return xerrors.Errorf("creating the chain bridge publisher: %w", err)
+		}
+
+		ds = namespace.Wrap(ds, mqbridge.DatastorePrefix)
+		b := mqbridge.New(&a, pub, ds, opts)
+
+		ctx, cancel := context.WithCancel(helpers.LifecycleCtx(mctx, lc))
+		done := make(chan struct{})
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go func() {
+					defer close(done)
+					b.Run(ctx)
+				}()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				cancel()
+				<-done
+				return pub.Close()
+			},
+		})
+
+		return nil
+	}
+}
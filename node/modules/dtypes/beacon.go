@@ -1,6 +1,10 @@
 package dtypes
 
-import "github.com/filecoin-project/go-state-types/abi"
+import (
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
 
 type DrandSchedule []DrandPoint
 
@@ -14,3 +18,15 @@ type DrandConfig struct {
 	Relays        []string
 	ChainInfoJSON string
 }
+
+// DrandHealthConfig configures how the health of drand servers is tracked, zero
+// values use the defaults.
+type DrandHealthConfig struct {
+	// FetchTimeout bounds the time spent fetching a beacon entry from all servers
+	FetchTimeout time.Duration
+	// UnhealthyAfter is the number of consecutive failed fetches after which a
+	// server is considered unhealthy
+	UnhealthyAfter int
+	// RetryUnhealthyAfter is the time after which an unhealthy server is tried again
+	RetryUnhealthyAfter time.Duration
+}
@@ -0,0 +1,19 @@
+package dtypes
+
+import (
+	"context"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+)
+
+// AnnouncedUpgrade is a network upgrade announced for the network, which only
+// releases newer than this binary may know about.
+type AnnouncedUpgrade struct {
+	Network network.Version
+	Height  abi.ChainEpoch
+}
+
+// UpgradeAnnouncementsFunc returns the network upgrades announced for the
+// network. The upgrades known when it fails are returned along with the error.
+type UpgradeAnnouncementsFunc func(ctx context.Context) ([]AnnouncedUpgrade, error)
@@ -6,12 +6,19 @@ import (
 	"path/filepath"
 
 	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
 
 	"github.com/filecoin-project/lotus/chain/ethhashlookup"
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/messagepool"
+	"github.com/filecoin-project/lotus/chain/sponsor"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl/full"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
@@ -20,6 +27,10 @@ import (
 
 func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRepo, fx.Lifecycle, *store.ChainStore, *stmgr.StateManager, EventAPI, *messagepool.MessagePool, full.StateAPI, full.ChainAPI, full.MpoolAPI, full.SyncAPI) (*full.EthModule, error) {
 	return func(mctx helpers.MetricsCtx, r repo.LockedRepo, lc fx.Lifecycle, cs *store.ChainStore, sm *stmgr.StateManager, evapi EventAPI, mp *messagepool.MessagePool, stateapi full.StateAPI, chainapi full.ChainAPI, mpoolapi full.MpoolAPI, syncapi full.SyncAPI) (*full.EthModule, error) {
+		if err := full.ValidEthNullRoundPolicy(cfg.EthNullRoundPolicy); err != nil {
+			return nil, xerrors.Errorf("invalid Fevm.EthNullRoundPolicy: %w", err)
+		}
+
 		sqlitePath, err := r.SqlitePath()
 		if err != nil {
 			return nil, err
@@ -81,6 +92,8 @@ func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRep
 			Mpool:        mp,
 			StateManager: sm,
 
+			NullRoundPolicy: cfg.EthNullRoundPolicy,
+
 			ChainAPI: chainapi,
 			MpoolAPI: mpoolapi,
 			StateAPI: stateapi,
@@ -90,3 +103,50 @@ func EthModuleAPI(cfg config.FevmConfig) func(helpers.MetricsCtx, repo.LockedRep
 		}, nil
 	}
 }
+
+func SponsoredCallChecker(cfg config.SponsoredCallsConfig) func() (*sponsor.Checker, error) {
+	return func() (*sponsor.Checker, error) {
+		var p sponsor.Policy
+		for _, s := range cfg.Sponsors {
+			a, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing sponsor address %q: %w", s, err)
+			}
+			p.Sponsors = append(p.Sponsors, a)
+		}
+		for _, s := range cfg.Forwarders {
+			a, err := ethtypes.ParseEthAddress(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing forwarder address %q: %w", s, err)
+			}
+			p.Forwarders = append(p.Forwarders, a)
+		}
+		for _, s := range cfg.Targets {
+			a, err := ethtypes.ParseEthAddress(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing target address %q: %w", s, err)
+			}
+			p.Targets = append(p.Targets, a)
+		}
+
+		maxValue, err := types.ParseFIL(cfg.MaxValue)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing Fevm.SponsoredCalls.MaxValue: %w", err)
+		}
+		p.MaxValue = abi.TokenAmount(maxValue)
+		if cfg.MaxFee != "" {
+			maxFee, err := types.ParseFIL(cfg.MaxFee)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing Fevm.SponsoredCalls.MaxFee: %w", err)
+			}
+			p.MaxFee = abi.TokenAmount(maxFee)
+		}
+		p.MaxCallsPerHour = cfg.MaxCallsPerHour
+
+		c, err := sponsor.NewChecker(p)
+		if err != nil {
+			return nil, xerrors.Errorf("Fevm.SponsoredCalls: %w", err)
+		}
+		return c, nil
+	}
+}
@@ -0,0 +1,31 @@
+package modules
+
+import (
+	"context"
+
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/gasstats"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+func GasStatsTracker(cfg config.IndexConfig) func(lc fx.Lifecycle, mctx helpers.MetricsCtx, cs *store.ChainStore, sm *stmgr.StateManager) *gasstats.Tracker {
+	return func(lc fx.Lifecycle, mctx helpers.MetricsCtx, cs *store.ChainStore, sm *stmgr.StateManager) *gasstats.Tracker {
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		t := gasstats.NewTracker(cs, sm, abi.ChainEpoch(cfg.GasStatsWindow))
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go t.Run(ctx)
+				return nil
+			},
+		})
+
+		return t
+	}
+}
@@ -0,0 +1,58 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/beacon"
+	"github.com/filecoin-project/lotus/chain/lightsync"
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+// RunLightSync syncs block headers from the upstream node of a light client.
+func RunLightSync(cfg config.LightClientConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, upstream api.Gateway, sm *stmgr.StateManager, b beacon.Schedule) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, upstream api.Gateway, sm *stmgr.StateManager, b beacon.Schedule) error {
+		checkpoint := types.EmptyTSK
+		if len(cfg.Checkpoint) > 0 {
+			cids := make([]cid.Cid, len(cfg.Checkpoint))
+			for i, s := range cfg.Checkpoint {
+				c, err := cid.Decode(s)
+				if err != nil {
+					return xerrors.Errorf("parsing LightClient.Checkpoint block cid %q: %w", s, err)
+				}
+				cids[i] = c
+			}
+			checkpoint = types.NewTipSetKey(cids...)
+		}
+
+		interval := time.Duration(cfg.SyncInterval)
+		if interval <= 0 {
+			return xerrors.Errorf("LightClient.SyncInterval must be positive")
+		}
+
+		nv := func(ctx context.Context, height abi.ChainEpoch) network.Version {
+			return sm.GetNetworkVersion(ctx, height)
+		}
+		syncer := lightsync.NewSyncer(upstream, sm.ChainStore(), b, nv, checkpoint)
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go syncer.Run(ctx, interval)
+				return nil
+			},
+		})
+		return nil
+	}
+}
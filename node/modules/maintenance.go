@@ -0,0 +1,93 @@
+package modules
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/blockstore"
+	"github.com/filecoin-project/lotus/blockstore/splitstore"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/lib/maintenance"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/impl/full"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+// MaintenanceScheduler creates the scheduler restricting expensive background
+// activities to the configured maintenance windows.
+func MaintenanceScheduler(cfg config.MaintenanceConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal) (*maintenance.Scheduler, error) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, j journal.Journal) (*maintenance.Scheduler, error) {
+		windows := make([]maintenance.Window, 0, len(cfg.Windows))
+		for _, w := range cfg.Windows {
+			win, err := maintenance.ParseWindow(w.Schedule, time.Duration(w.Duration))
+			if err != nil {
+				return nil, xerrors.Errorf("invalid maintenance window: %w", err)
+			}
+			windows = append(windows, win)
+		}
+
+		s := maintenance.NewScheduler(windows, j)
+		lc.Append(fx.Hook{
+			OnStart: func(_ context.Context) error {
+				s.Start(helpers.LifecycleCtx(mctx, lc))
+				return nil
+			},
+			OnStop: s.Stop,
+		})
+		return s, nil
+	}
+}
+
+type MaintenanceTasksParams struct {
+	fx.In
+
+	Scheduler  *maintenance.Scheduler
+	Blockstore dtypes.UniversalBlockstore
+	Split      dtypes.SplitBlockstore `optional:"true"`
+	EthModule  full.EthModuleAPI      `optional:"true"`
+	EthEvent   full.EthEventAPI       `optional:"true"`
+}
+
+// vacuumer is implemented by the SQLite events index.
+type vacuumer interface {
+	Vacuum(ctx context.Context) error
+}
+
+// RegisterMaintenanceTasks defers splitstore compactions to the maintenance
+// windows, and registers the enabled maintenance tasks.
+func RegisterMaintenanceTasks(cfg config.MaintenanceConfig) func(p MaintenanceTasksParams) {
+	return func(p MaintenanceTasksParams) {
+		if ss, ok := p.Split.(*splitstore.SplitStore); ok {
+			ss.SetCompactionGate(p.Scheduler)
+		}
+
+		if cfg.BadgerGC {
+			if p.Split != nil {
+				// the splitstore garbage collects the universal blockstore, its
+				// cold store, during compactions
+				log.Warnf("not running badger GC in maintenance windows, the chain blockstore is managed by the splitstore")
+			} else if gc, ok := p.Blockstore.(blockstore.BlockstoreGC); ok {
+				p.Scheduler.Register("badger-gc", func(ctx context.Context) error {
+					return gc.CollectGarbage(ctx)
+				})
+			} else {
+				log.Warnf("chain blockstore %T doesn't support garbage collection", p.Blockstore)
+			}
+		}
+
+		if cfg.VacuumSQLite {
+			if em, ok := p.EthModule.(*full.EthModule); ok && em.EthTxHashManager != nil && em.EthTxHashManager.TransactionHashLookup != nil {
+				p.Scheduler.Register("vacuum-txhash", em.EthTxHashManager.TransactionHashLookup.Vacuum)
+			}
+			if ee, ok := p.EthEvent.(*full.EthEvent); ok && ee.EventFilterManager != nil {
+				if v, ok := ee.EventFilterManager.EventIndex.(vacuumer); ok {
+					p.Scheduler.Register("vacuum-events", v.Vacuum)
+				}
+			}
+		}
+	}
+}
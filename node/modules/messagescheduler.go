@@ -0,0 +1,45 @@
+package modules
+
+import (
+	"context"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/lotus/chain/messagescheduler"
+	"github.com/filecoin-project/lotus/node/impl/full"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+type MessageSchedulerAPI struct {
+	fx.In
+
+	full.ChainAPI
+	full.MpoolAPI
+}
+
+var _ messagescheduler.API = &MessageSchedulerAPI{}
+
+func NewMessageScheduler(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS, api messagescheduler.API) (*messagescheduler.MessageScheduler, error) {
+	ctx := helpers.LifecycleCtx(mctx, lc)
+	ds = namespace.Wrap(ds, datastore.NewKey("/mpool/scheduled/"))
+	return messagescheduler.NewMessageScheduler(ctx, api, ds)
+}
+
+// RunMessageScheduler is called by dependency injection to run the message
+// scheduler while the node is running
+func RunMessageScheduler(mctx helpers.MetricsCtx, lc fx.Lifecycle, s *messagescheduler.MessageScheduler) {
+	ctx, cancel := context.WithCancel(helpers.LifecycleCtx(mctx, lc))
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go s.Run(ctx)
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
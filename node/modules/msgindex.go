@@ -2,11 +2,14 @@ package modules
 
 import (
 	"context"
+	"path/filepath"
+	"time"
 
 	"go.uber.org/fx"
 
 	"github.com/filecoin-project/lotus/chain/index"
 	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/helpers"
 	"github.com/filecoin-project/lotus/node/repo"
 )
@@ -34,3 +37,48 @@ func MsgIndex(lc fx.Lifecycle, mctx helpers.MetricsCtx, cs *store.ChainStore, r
 func DummyMsgIndex() index.MsgIndex {
 	return index.DummyMsgIndex
 }
+
+func AddrIndex(lc fx.Lifecycle, mctx helpers.MetricsCtx, cs *store.ChainStore, r repo.LockedRepo) (index.AddrIndex, error) {
+	basePath, err := r.SqlitePath()
+	if err != nil {
+		return nil, err
+	}
+
+	addrIndex, err := index.NewAddrIndex(helpers.LifecycleCtx(mctx, lc), basePath, cs)
+	if err != nil {
+		return nil, err
+	}
+
+	lc.Append(fx.Hook{
+		OnStop: func(_ context.Context) error {
+			return addrIndex.Close()
+		},
+	})
+
+	return addrIndex, nil
+}
+
+// IndexQueryEngine runs the queries of ChainIndexQuery over the events,
+// transaction hash and message index databases.
+func IndexQueryEngine(cfg config.IndexConfig, fevm config.FevmConfig) func(r repo.LockedRepo) (*index.QueryEngine, error) {
+	return func(r repo.LockedRepo) (*index.QueryEngine, error) {
+		sqlitePath, err := r.SqlitePath()
+		if err != nil {
+			return nil, err
+		}
+
+		eventsPath := fevm.Events.DatabasePath
+		if eventsPath == "" {
+			eventsPath = filepath.Join(sqlitePath, "events.db")
+		}
+
+		return index.NewQueryEngine(map[string]string{
+			"events":   eventsPath,
+			"txhash":   filepath.Join(sqlitePath, "txhash.db"),
+			"msgindex": filepath.Join(sqlitePath, "msgindex.db"),
+		}, index.QueryLimits{
+			MaxRows: cfg.SQLQueryMaxRows,
+			Timeout: time.Duration(cfg.SQLQueryTimeout),
+		}), nil
+	}
+}
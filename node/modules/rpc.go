@@ -32,6 +32,10 @@ func (h *RPCHandler) MpoolPushMessage(ctx context.Context, msgWhole *api.MpoolMe
 	return nil
 }
 
+func (h *RPCHandler) SaveNonce(ctx context.Context, op *consensus.ConsensusOp, ret *struct{}) error {
+	return h.mpoolAPI.MessageSigner.SaveNonce(ctx, op.Addr, op.Nonce)
+}
+
 func (h *RPCHandler) AddPeer(ctx context.Context, pid peer.ID, ret *struct{}) error {
 	return h.cons.AddPeer(ctx, pid)
 }
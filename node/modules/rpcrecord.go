@@ -0,0 +1,42 @@
+package modules
+
+import (
+	"context"
+	"path/filepath"
+
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/lotus/lib/rpcreplay"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+func RPCRecorder(cfg config.RPCRecordingConfig) func(lc fx.Lifecycle, r repo.LockedRepo) (*rpcreplay.Recorder, error) {
+	return func(lc fx.Lifecycle, r repo.LockedRepo) (*rpcreplay.Recorder, error) {
+		dir := cfg.Directory
+		if dir == "" {
+			dir = filepath.Join(r.Path(), "rpc-recordings")
+		}
+
+		rec, err := rpcreplay.NewRecorder(rpcreplay.RecorderOptions{
+			Dir:             dir,
+			Methods:         cfg.Methods,
+			SampleRate:      cfg.SampleRate,
+			MaxFileSize:     cfg.MaxFileSize,
+			MaxFiles:        cfg.MaxFiles,
+			MaxResponseSize: cfg.MaxResponseSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Infow("recording rpc calls", "dir", dir)
+
+		lc.Append(fx.Hook{
+			OnStop: func(context.Context) error {
+				return rec.Close()
+			},
+		})
+
+		return rec, nil
+	}
+}
@@ -2,6 +2,7 @@ package modules
 
 import (
 	"context"
+	"encoding/json"
 	"os"
 	"strconv"
 	"time"
@@ -27,14 +28,17 @@ import (
 	"github.com/filecoin-project/lotus/chain/consensus"
 	"github.com/filecoin-project/lotus/chain/exchange"
 	"github.com/filecoin-project/lotus/chain/messagepool"
+	"github.com/filecoin-project/lotus/chain/statesync"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/sub"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
 	"github.com/filecoin-project/lotus/journal/fsjournal"
 	"github.com/filecoin-project/lotus/lib/peermgr"
 	marketevents "github.com/filecoin-project/lotus/markets/loggers"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/hello"
 	"github.com/filecoin-project/lotus/node/impl/full"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
@@ -102,6 +106,12 @@ func RunChainExchange(h host.Host, svc exchange.Server) {
 	h.SetStreamHandler(exchange.ChainExchangeProtocolID, svc.HandleStream) // new
 }
 
+// RunStateSync serves the chain state to peers bootstrapping with the state
+// sync protocol.
+func RunStateSync(mctx helpers.MetricsCtx, lc fx.Lifecycle, h host.Host, bs dtypes.ExposedBlockstore) {
+	h.SetStreamHandler(statesync.ProtocolID, statesync.NewServer(helpers.LifecycleCtx(mctx, lc), bs).HandleStream)
+}
+
 func waitForSync(stmgr *stmgr.StateManager, epochs int, subscribe func()) {
 	nearsync := time.Duration(epochs*int(build.BlockDelaySecs)) * time.Second
 
@@ -253,12 +263,66 @@ type RandomBeaconParams struct {
 	PubSub      *pubsub.PubSub `optional:"true"`
 	Cs          *store.ChainStore
 	DrandConfig dtypes.DrandSchedule
+	DrandHealth dtypes.DrandHealthConfig `optional:"true"`
+	Alerting    *alerting.Alerting       `optional:"true"`
 }
 
 func BuiltinDrandConfig() dtypes.DrandSchedule {
 	return build.DrandConfigSchedule()
 }
 
+// ConfiguredDrandSchedule returns the built-in drand schedule with the servers
+// configured by the user for each drand network.
+func ConfiguredDrandSchedule(cfg config.BeaconConfig) func() (dtypes.DrandSchedule, error) {
+	return func() (dtypes.DrandSchedule, error) {
+		servers := map[string][]string{}
+		for _, srv := range cfg.DrandServers {
+			servers[srv.Chain] = append(servers[srv.Chain], srv.URL)
+		}
+
+		var shd dtypes.DrandSchedule
+		scheduled := map[string]bool{}
+		for _, dp := range build.DrandConfigSchedule() {
+			var info struct {
+				Hash string `json:"hash"`
+			}
+			if err := json.Unmarshal([]byte(dp.Config.ChainInfoJSON), &info); err != nil {
+				return nil, xerrors.Errorf("parsing drand chain info: %w", err)
+			}
+
+			scheduled[info.Hash] = true
+
+			configured, ok := servers[info.Hash]
+			switch {
+			case !ok:
+			case cfg.ReplaceDefaultServers:
+				dp.Config.Servers = configured
+			default:
+				dp.Config.Servers = append(append([]string{}, dp.Config.Servers...), configured...)
+			}
+			shd = append(shd, dp)
+		}
+
+		for _, srv := range cfg.DrandServers {
+			if scheduled[srv.Chain] {
+				continue
+			}
+			return nil, xerrors.Errorf("drand servers are configured for chain %q, which isn't in the beacon schedule", srv.Chain)
+		}
+		return shd, nil
+	}
+}
+
+func DrandHealthConfig(cfg config.BeaconConfig) func() dtypes.DrandHealthConfig {
+	return func() dtypes.DrandHealthConfig {
+		return dtypes.DrandHealthConfig{
+			FetchTimeout:        time.Duration(cfg.FetchTimeout),
+			UnhealthyAfter:      cfg.UnhealthyAfter,
+			RetryUnhealthyAfter: time.Duration(cfg.RetryUnhealthyAfter),
+		}
+	}
+}
+
 func RandomSchedule(lc fx.Lifecycle, mctx helpers.MetricsCtx, p RandomBeaconParams, _ dtypes.AfterGenesisSet) (beacon.Schedule, error) {
 	gen, err := p.Cs.GetGenesis(helpers.LifecycleCtx(mctx, lc))
 	if err != nil {
@@ -267,10 +331,13 @@ func RandomSchedule(lc fx.Lifecycle, mctx helpers.MetricsCtx, p RandomBeaconPara
 
 	shd := beacon.Schedule{}
 	for _, dc := range p.DrandConfig {
-		bc, err := drand.NewDrandBeacon(gen.Timestamp, build.BlockDelaySecs, p.PubSub, dc.Config)
+		bc, err := drand.NewDrandBeaconWithHealth(gen.Timestamp, build.BlockDelaySecs, p.PubSub, dc.Config, p.DrandHealth)
 		if err != nil {
 			return nil, xerrors.Errorf("creating drand beacon: %w", err)
 		}
+		if p.Alerting != nil {
+			bc.SetAlerting(p.Alerting)
+		}
 		shd = append(shd, beacon.BeaconPoint{Start: dc.Start, Beacon: bc})
 	}
 
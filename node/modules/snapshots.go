@@ -0,0 +1,46 @@
+package modules
+
+import (
+	"context"
+	"path/filepath"
+
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/crypto"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/snapshotter"
+	"github.com/filecoin-project/lotus/chain/store"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/maintenance"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+func RunSnapshotter(cfg config.SnapshotsConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, r repo.LockedRepo, cs *store.ChainStore, nn dtypes.NetworkName, w api.Wallet, j journal.Journal, al *alerting.Alerting, ms *maintenance.Scheduler) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, r repo.LockedRepo, cs *store.ChainStore, nn dtypes.NetworkName, w api.Wallet, j journal.Journal, al *alerting.Alerting, ms *maintenance.Scheduler) error {
+		sign := func(ctx context.Context, addr address.Address, msg []byte) (*crypto.Signature, error) {
+			return w.WalletSign(ctx, addr, msg, api.MsgMeta{Type: api.MTUnknown})
+		}
+
+		s, err := snapshotter.NewSnapshotter(cfg, cs, filepath.Join(r.Path(), "snapshots"), string(nn), sign, j, al)
+		if err != nil {
+			return err
+		}
+		s.SetGate(ms)
+
+		lc.Append(fx.Hook{
+			OnStart: func(_ context.Context) error {
+				s.Start(helpers.LifecycleCtx(mctx, lc))
+				return nil
+			},
+			OnStop: s.Stop,
+		})
+
+		return nil
+	}
+}
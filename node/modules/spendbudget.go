@@ -0,0 +1,34 @@
+package modules
+
+import (
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"go.uber.org/fx"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/spendbudget"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+)
+
+func SpendBudget(cfg config.SpendBudgetConfig) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS) (*spendbudget.Governor, error) {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS) (*spendbudget.Governor, error) {
+		opts := spendbudget.Options{
+			Queue:        cfg.Queue,
+			QueueTimeout: time.Duration(cfg.QueueTimeout),
+		}
+		for _, w := range cfg.Windows {
+			opts.Windows = append(opts.Windows, spendbudget.Window{
+				Period: time.Duration(w.Period),
+				Budget: abi.TokenAmount(w.Budget),
+			})
+		}
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+		return spendbudget.New(ctx, namespace.Wrap(ds, datastore.NewKey("/mpool/spendbudget/")), opts)
+	}
+}
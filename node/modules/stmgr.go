@@ -1,13 +1,20 @@
 package modules
 
 import (
+	"os"
+	"strconv"
+
 	"go.uber.org/fx"
+	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/lotus/chain/beacon"
 	"github.com/filecoin-project/lotus/chain/index"
+	"github.com/filecoin-project/lotus/chain/msgtrace"
 	"github.com/filecoin-project/lotus/chain/stmgr"
 	"github.com/filecoin-project/lotus/chain/store"
 	"github.com/filecoin-project/lotus/chain/vm"
+	"github.com/filecoin-project/lotus/lib/tracing"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 )
 
@@ -22,3 +29,50 @@ func StateManager(lc fx.Lifecycle, cs *store.ChainStore, exec stmgr.Executor, sy
 	})
 	return sm, nil
 }
+
+func SetupMessageTracing(cfg config.MessageTracingConfig) func(lc fx.Lifecycle, sm *stmgr.StateManager) {
+	return func(lc fx.Lifecycle, sm *stmgr.StateManager) {
+		log.Infow("tracing messages of sampled tipsets", "endpoint", cfg.Endpoint, "sampleRate", cfg.SampleRate)
+
+		t := msgtrace.NewTracer(tracing.NewOTLPExporter(cfg.Endpoint), cfg.SampleRate)
+		sm.SetTipSetTracer(t)
+
+		lc.Append(fx.Hook{
+			OnStop: t.Shutdown,
+		})
+	}
+}
+
+// SetExecutionLanes applies the configured execution lanes before the first VM
+// is created.
+func SetExecutionLanes(cfg config.ExecutionConfig) func() error {
+	return func() error {
+		lanes, reserved := vm.ExecutionLanes()
+
+		if cfg.Lanes > 0 {
+			lanes = cfg.Lanes
+
+			// the FVM sizes its thread pool from the environment when the first
+			// machine is created
+			switch env := os.Getenv("LOTUS_FVM_CONCURRENCY"); env {
+			case "":
+				if err := os.Setenv("LOTUS_FVM_CONCURRENCY", strconv.Itoa(lanes)); err != nil {
+					return xerrors.Errorf("setting LOTUS_FVM_CONCURRENCY: %w", err)
+				}
+			case strconv.Itoa(lanes):
+			default:
+				log.Warnw("LOTUS_FVM_CONCURRENCY differs from Execution.Lanes, the FVM thread pool is sized from the environment", "env", env, "lanes", lanes)
+			}
+		}
+		if cfg.ReservedLanes > 0 {
+			reserved = cfg.ReservedLanes
+		}
+
+		if err := vm.SetExecutionLanes(lanes, reserved); err != nil {
+			return xerrors.Errorf("setting execution lanes: %w", err)
+		}
+
+		log.Infow("execution lanes", "lanes", lanes, "reserved", reserved)
+		return nil
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -22,6 +23,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/host"
 	"go.uber.org/fx"
 	"go.uber.org/multierr"
+	"golang.org/x/exp/slices"
 	"golang.org/x/xerrors"
 
 	"github.com/filecoin-project/go-address"
@@ -55,8 +57,11 @@ import (
 	"github.com/filecoin-project/lotus/chain/gen/slashfilter"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/strle"
 	"github.com/filecoin-project/lotus/markets"
 	"github.com/filecoin-project/lotus/markets/dagstore"
+	"github.com/filecoin-project/lotus/markets/dealfilter"
 	"github.com/filecoin-project/lotus/markets/idxprov"
 	marketevents "github.com/filecoin-project/lotus/markets/loggers"
 	"github.com/filecoin-project/lotus/markets/pricing"
@@ -66,10 +71,13 @@ import (
 	"github.com/filecoin-project/lotus/node/modules/helpers"
 	"github.com/filecoin-project/lotus/node/repo"
 	"github.com/filecoin-project/lotus/storage/ctladdr"
+	"github.com/filecoin-project/lotus/storage/funds"
+	"github.com/filecoin-project/lotus/storage/hotness"
 	"github.com/filecoin-project/lotus/storage/paths"
 	sealing "github.com/filecoin-project/lotus/storage/pipeline"
 	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
 	"github.com/filecoin-project/lotus/storage/sealer"
+	"github.com/filecoin-project/lotus/storage/sealer/fsutil"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 	"github.com/filecoin-project/lotus/storage/wdpost"
 )
@@ -206,6 +214,15 @@ func AddressSelector(addrConf *config.MinerAddressConfig) func() (*ctladdr.Addre
 			as.DealPublishControl = append(as.DealPublishControl, addr)
 		}
 
+		for _, s := range addrConf.WindowPoStControl {
+			addr, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing window post control address: %w", err)
+			}
+
+			as.WindowPoStControl = append(as.WindowPoStControl, addr)
+		}
+
 		return as, nil
 	}
 }
@@ -252,8 +269,10 @@ type SealingPipelineParams struct {
 	Prover             storiface.Prover
 	GetSealingConfigFn dtypes.GetSealingConfigFunc
 	Journal            journal.Journal
+	Alerting           *alerting.Alerting
 	AddrSel            *ctladdr.AddressSelector
 	Maddr              dtypes.MinerAddress
+	Hotness            *hotness.Tracker
 }
 
 func SealingPipeline(fc config.MinerFeeConfig) func(params SealingPipelineParams) (*sealing.Sealing, error) {
@@ -268,8 +287,10 @@ func SealingPipeline(fc config.MinerFeeConfig) func(params SealingPipelineParams
 			prover = params.Prover
 			gsd    = params.GetSealingConfigFn
 			j      = params.Journal
+			al     = params.Alerting
 			as     = params.AddrSel
 			maddr  = address.Address(params.Maddr)
+			hot    = params.Hotness
 		)
 
 		ctx := helpers.LifecycleCtx(mctx, lc)
@@ -286,7 +307,7 @@ func SealingPipeline(fc config.MinerFeeConfig) func(params SealingPipelineParams
 		provingBuffer := md.WPoStProvingPeriod * 2
 		pcp := sealing.NewBasicPreCommitPolicy(api, gsd, provingBuffer)
 
-		pipeline := sealing.New(ctx, api, fc, evts, maddr, ds, sealer, verif, prover, &pcp, gsd, j, as)
+		pipeline := sealing.New(ctx, api, fc, evts, maddr, ds, sealer, verif, prover, &pcp, gsd, j, al, as, hot)
 
 		lc.Append(fx.Hook{
 			OnStart: func(context.Context) error {
@@ -332,6 +353,91 @@ func WindowPostScheduler(fc config.MinerFeeConfig, pc config.ProvingConfig) func
 	}
 }
 
+func FundsManager(wc config.MinerWithdrawalConfig) func(params SealingPipelineParams) error {
+	return func(params SealingPipelineParams) error {
+		var (
+			mctx = params.MetricsCtx
+			lc   = params.Lifecycle
+		)
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		fm, err := funds.NewManager(params.API, address.Address(params.Maddr), wc, params.MetadataDS, params.Journal, params.Alerting)
+		if err != nil {
+			return err
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go fm.Run(ctx)
+				return nil
+			},
+		})
+
+		return nil
+	}
+}
+
+type StorageAskAutomationParams struct {
+	fx.In
+
+	MetricsCtx helpers.MetricsCtx
+	Lifecycle  fx.Lifecycle
+	API        v1api.FullNode
+	Maddr      dtypes.MinerAddress
+	Ask        *storedask.StoredAsk
+	Journal    journal.Journal
+
+	// the storage subsystem, when it runs in another process
+	Storage MinerStorageService `optional:"true"`
+	Index   paths.SectorIndex   `optional:"true"`
+	Remote  *paths.Remote       `optional:"true"`
+}
+
+// indexStorageAPI gets the storage stats from the sector index, when the
+// storage subsystem runs in the same process
+type indexStorageAPI struct {
+	paths.SectorIndex
+	remote *paths.Remote
+}
+
+func (s indexStorageAPI) StorageStat(ctx context.Context, id storiface.ID) (fsutil.FsStat, error) {
+	return s.remote.FsStat(ctx, id)
+}
+
+func StorageAskAutomation(cfg config.StorageAskAutomationConfig) func(params StorageAskAutomationParams) error {
+	return func(params StorageAskAutomationParams) error {
+		ctx := helpers.LifecycleCtx(params.MetricsCtx, params.Lifecycle)
+
+		mi, err := params.API.StateMinerInfo(ctx, address.Address(params.Maddr), types.EmptyTSK)
+		if err != nil {
+			return err
+		}
+
+		var storage pricing.StorageAPI = params.Storage
+		if params.Storage == nil {
+			if params.Index == nil || params.Remote == nil {
+				return xerrors.Errorf("storage ask automation requires the storage subsystem")
+			}
+			storage = indexStorageAPI{SectorIndex: params.Index, remote: params.Remote}
+		}
+
+		a, err := pricing.NewAskAutomation(cfg, mi.SectorSize, params.Ask, storage, params.API, params.Journal)
+		if err != nil {
+			return err
+		}
+
+		params.Lifecycle.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				go a.Run(ctx)
+				return nil
+			},
+		})
+
+		return nil
+	}
+}
+
 func HandleRetrieval(host host.Host, lc fx.Lifecycle, m retrievalmarket.RetrievalProvider, j journal.Journal) {
 	m.OnReady(marketevents.ReadyLogger("retrieval provider"))
 	lc.Append(fx.Hook{
@@ -489,27 +595,35 @@ func StagingGraphsync(parallelTransfersForStorage uint64, parallelTransfersForSt
 	}
 }
 
-func SetupBlockProducer(lc fx.Lifecycle, ds dtypes.MetadataDS, api v1api.FullNode, epp gen.WinningPoStProver, sf *slashfilter.SlashFilter, j journal.Journal) (*lotusminer.Miner, error) {
-	minerAddr, err := minerAddrFromDS(ds)
-	if err != nil {
-		return nil, err
-	}
+func SetupBlockProducer(cfg config.MiningConfig) func(lc fx.Lifecycle, ds dtypes.MetadataDS, api v1api.FullNode, epp gen.WinningPoStProver, sf *slashfilter.SlashFilter, j journal.Journal) (*lotusminer.Miner, error) {
+	return func(lc fx.Lifecycle, ds dtypes.MetadataDS, api v1api.FullNode, epp gen.WinningPoStProver, sf *slashfilter.SlashFilter, j journal.Journal) (*lotusminer.Miner, error) {
+		minerAddr, err := minerAddrFromDS(ds)
+		if err != nil {
+			return nil, err
+		}
 
-	m := lotusminer.NewMiner(api, epp, minerAddr, sf, j)
+		ordering, err := lotusminer.ParseOrderingRule(cfg.MessageOrdering)
+		if err != nil {
+			return nil, err
+		}
 
-	lc.Append(fx.Hook{
-		OnStart: func(ctx context.Context) error {
-			if err := m.Start(ctx); err != nil {
-				return err
-			}
-			return nil
-		},
-		OnStop: func(ctx context.Context) error {
-			return m.Stop(ctx)
-		},
-	})
+		m := lotusminer.NewMiner(api, epp, minerAddr, sf, j)
+		m.SetMessageOrdering(ordering)
 
-	return m, nil
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := m.Start(ctx); err != nil {
+					return err
+				}
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return m.Stop(ctx)
+			},
+		})
+
+		return m, nil
+	}
 }
 
 func NewStorageAsk(ctx helpers.MetricsCtx, fapi v1api.FullNode, ds dtypes.MetadataDS, minerAddress dtypes.MinerAddress, spn storagemarket.StorageProviderNode) (*storedask.StoredAsk, error) {
@@ -538,7 +652,10 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 	startDelay dtypes.GetMaxDealStartDelayFunc,
 	spn storagemarket.StorageProviderNode,
 	r repo.LockedRepo,
-) dtypes.StorageDealFilter {
+	decisions *dealfilter.DecisionLog,
+	mctx helpers.MetricsCtx,
+	ds dtypes.MetadataDS,
+) (dtypes.StorageDealFilter, error) {
 	return func(onlineOk dtypes.ConsiderOnlineStorageDealsConfigFunc,
 		offlineOk dtypes.ConsiderOfflineStorageDealsConfigFunc,
 		verifiedOk dtypes.ConsiderVerifiedStorageDealsConfigFunc,
@@ -548,9 +665,26 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 		startDelay dtypes.GetMaxDealStartDelayFunc,
 		spn storagemarket.StorageProviderNode,
 		r repo.LockedRepo,
-	) dtypes.StorageDealFilter {
+		decisions *dealfilter.DecisionLog,
+		mctx helpers.MetricsCtx,
+		ds dtypes.MetadataDS,
+	) (dtypes.StorageDealFilter, error) {
+		userFilter := user
+		var rules *dealfilter.RulesFilter
+		if cfg.FilterRules != "" {
+			var err error
+			rules, err = dealfilter.NewRulesFilter(mctx, cfg.FilterRules, namespace.Wrap(ds, datastore.NewKey("/deals/filter-accepted")))
+			if err != nil {
+				return nil, err
+			}
+			if userFilter != nil {
+				userFilter = dealfilter.ChainStorageDealFilters(rules.Filter, userFilter)
+			} else {
+				userFilter = rules.Filter
+			}
+		}
 
-		return func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
+		filter := dtypes.StorageDealFilter(func(ctx context.Context, deal storagemarket.MinerDeal) (bool, string, error) {
 			b, err := onlineOk()
 			if err != nil {
 				return false, "miner error", err
@@ -642,15 +776,28 @@ func BasicDealFilter(cfg config.DealmakingConfig, user dtypes.StorageDealFilter)
 				return false, fmt.Sprintf("deal start epoch is too far in the future: %s > %s", deal.Proposal.StartEpoch, maxStartEpoch), nil
 			}
 
-			if user != nil {
-				return user(ctx, deal)
+			if userFilter != nil {
+				return userFilter(ctx, deal)
 			}
 
 			return true, "", nil
+		})
+		if rules != nil {
+			// the rate limits are only charged for the deals all filters accept
+			filter = rules.Wrap(filter)
 		}
+		return decisions.Wrap(filter), nil
 	}
 }
 
+// dealFilterDecisionsKept is the number of decisions of the storage deal filter
+// kept in its audit log.
+const dealFilterDecisionsKept = 10000
+
+func DealFilterDecisionLog(mctx helpers.MetricsCtx, lc fx.Lifecycle, ds dtypes.MetadataDS) (*dealfilter.DecisionLog, error) {
+	return dealfilter.NewDecisionLog(helpers.LifecycleCtx(mctx, lc), namespace.Wrap(ds, datastore.NewKey("/deals/filter-decisions")), dealFilterDecisionsKept)
+}
+
 func StorageProvider(minerAddress dtypes.MinerAddress,
 	storedAsk *storedask.StoredAsk,
 	h host.Host, ds dtypes.MetadataDS,
@@ -781,6 +928,7 @@ func RetrievalProvider(
 
 var WorkerCallsPrefix = datastore.NewKey("/worker/calls")
 var ManagerWorkPrefix = datastore.NewKey("/stmgr/calls")
+var TaskHistoryPrefix = datastore.NewKey("/stmgr/history")
 
 func LocalStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, ls paths.LocalStorage, si paths.SectorIndex, urls paths.URLs) (*paths.Local, error) {
 	ctx := helpers.LifecycleCtx(mctx, lc)
@@ -791,13 +939,14 @@ func RemoteStorage(lstor *paths.Local, si paths.SectorIndex, sa sealer.StorageAu
 	return paths.NewRemote(lstor, si, http.Header(sa), sc.ParallelFetchLimit, &paths.DefaultPartialFileHandler{})
 }
 
-func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, ds dtypes.MetadataDS) (*sealer.Manager, error) {
+func SectorStorage(mctx helpers.MetricsCtx, lc fx.Lifecycle, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, ds dtypes.MetadataDS, j journal.Journal) (*sealer.Manager, error) {
 	ctx := helpers.LifecycleCtx(mctx, lc)
 
 	wsts := statestore.New(namespace.Wrap(ds, WorkerCallsPrefix))
 	smsts := statestore.New(namespace.Wrap(ds, ManagerWorkPrefix))
+	hist := namespace.Wrap(ds, TaskHistoryPrefix)
 
-	sst, err := sealer.New(ctx, lstor, stor, ls, si, sc, pc, wsts, smsts)
+	sst, err := sealer.New(ctx, lstor, stor, ls, si, sc, pc, wsts, smsts, hist, j)
 	if err != nil {
 		return nil, err
 	}
@@ -1018,6 +1167,49 @@ func NewSetSealConfigFunc(r repo.LockedRepo) (dtypes.SetSealingConfigFunc, error
 				TerminateBatchMin:                      cfg.TerminateBatchMin,
 				TerminateBatchWait:                     config.Duration(cfg.TerminateBatchWait),
 				MaxSectorProveCommitsSubmittedPerEpoch: cfg.MaxSectorProveCommitsSubmittedPerEpoch,
+
+				RemoveExpiredSectors:      cfg.RemoveExpiredSectors,
+				RemoveExpiredSectorsDelay: config.Duration(cfg.RemoveExpiredSectorsDelay),
+			}
+			for _, rule := range cfg.ClientPlacement {
+				newCfg.ClientPlacement = append(newCfg.ClientPlacement, config.ClientPlacementRule{
+					Client: rule.Client,
+					Groups: rule.Groups,
+				})
+			}
+			for class, policy := range cfg.RetryPolicies {
+				newCfg.RetryPolicies = append(newCfg.RetryPolicies, config.SealingRetryPolicy{
+					Class:          class,
+					InitialBackoff: config.Duration(policy.InitialBackoff),
+					MaxBackoff:     config.Duration(policy.MaxBackoff),
+					BackoffFactor:  policy.BackoffFactor,
+					MaxAttempts:    policy.MaxAttempts,
+				})
+			}
+			sort.Slice(newCfg.RetryPolicies, func(i, j int) bool {
+				return newCfg.RetryPolicies[i].Class < newCfg.RetryPolicies[j].Class
+			})
+			for _, policy := range cfg.UnsealedCopyPolicies {
+				p := config.UnsealedCopyPolicy{
+					Client:            policy.Client,
+					MinRetrievalScore: policy.MinRetrievalScore,
+					Keep:              policy.Keep,
+				}
+				if policy.Verified != nil {
+					p.Deals = "unverified"
+					if *policy.Verified {
+						p.Deals = "verified"
+					}
+				}
+				if policy.Sectors != nil {
+					sectors, err := strle.BitfieldToHumanRanges(*policy.Sectors)
+					if err != nil {
+						log.Errorw("encoding unsealed copy policy sectors", "error", err)
+						continue
+					}
+					p.Sectors = sectors
+				}
+				newCfg.UnsealedCopyPolicies = append(newCfg.UnsealedCopyPolicies, p)
 			}
 			c.SetSealingConfig(newCfg)
 		})
@@ -1026,6 +1218,57 @@ func NewSetSealConfigFunc(r repo.LockedRepo) (dtypes.SetSealingConfigFunc, error
 }
 
 func ToSealingConfig(dealmakingCfg config.DealmakingConfig, sealingCfg config.SealingConfig) sealiface.Config {
+	var placement []sealiface.ClientPlacementRule
+	for _, rule := range sealingCfg.ClientPlacement {
+		placement = append(placement, sealiface.ClientPlacementRule{
+			Client: rule.Client,
+			Groups: rule.Groups,
+		})
+	}
+
+	var retryPolicies map[string]sealiface.RetryPolicy
+	if len(sealingCfg.RetryPolicies) > 0 {
+		retryPolicies = map[string]sealiface.RetryPolicy{}
+		for _, policy := range sealingCfg.RetryPolicies {
+			if !slices.Contains(sealiface.RetryClasses, policy.Class) {
+				log.Warnw("unknown sealing retry policy class", "class", policy.Class, "known", sealiface.RetryClasses)
+			}
+			retryPolicies[policy.Class] = sealiface.RetryPolicy{
+				InitialBackoff: time.Duration(policy.InitialBackoff),
+				MaxBackoff:     time.Duration(policy.MaxBackoff),
+				BackoffFactor:  policy.BackoffFactor,
+				MaxAttempts:    policy.MaxAttempts,
+			}
+		}
+	}
+
+	var unsealedPolicies []sealiface.UnsealedCopyPolicy
+	for _, policy := range sealingCfg.UnsealedCopyPolicies {
+		p := sealiface.UnsealedCopyPolicy{
+			Client:            policy.Client,
+			MinRetrievalScore: policy.MinRetrievalScore,
+			Keep:              policy.Keep,
+		}
+		switch policy.Deals {
+		case "":
+		case "verified", "unverified":
+			verified := policy.Deals == "verified"
+			p.Verified = &verified
+		default:
+			log.Warnw("invalid deals in unsealed copy policy, ignoring the policy", "deals", policy.Deals)
+			continue
+		}
+		if policy.Sectors != "" {
+			sectors, err := strle.HumanRangesToBitField(policy.Sectors)
+			if err != nil {
+				log.Warnw("invalid sectors in unsealed copy policy, ignoring the policy", "sectors", policy.Sectors, "error", err)
+				continue
+			}
+			p.Sectors = &sectors
+		}
+		unsealedPolicies = append(unsealedPolicies, p)
+	}
+
 	return sealiface.Config{
 		MaxWaitDealsSectors:              sealingCfg.MaxWaitDealsSectors,
 		MaxSealingSectors:                sealingCfg.MaxSealingSectors,
@@ -1064,6 +1307,14 @@ func ToSealingConfig(dealmakingCfg config.DealmakingConfig, sealingCfg config.Se
 		TerminateBatchMax:  sealingCfg.TerminateBatchMax,
 		TerminateBatchMin:  sealingCfg.TerminateBatchMin,
 		TerminateBatchWait: time.Duration(sealingCfg.TerminateBatchWait),
+
+		ClientPlacement: placement,
+		RetryPolicies:   retryPolicies,
+
+		UnsealedCopyPolicies: unsealedPolicies,
+
+		RemoveExpiredSectors:      sealingCfg.RemoveExpiredSectors,
+		RemoveExpiredSectorsDelay: time.Duration(sealingCfg.RemoveExpiredSectorsDelay),
 	}
 }
 
@@ -0,0 +1,41 @@
+package modules
+
+import (
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/chain/wallet"
+	"github.com/filecoin-project/lotus/chain/wallet/signpolicy"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+// WalletSigningPolicyEnabled checks whether the policy limits signing at all.
+func WalletSigningPolicyEnabled(cfg config.WalletSigningPolicy) bool {
+	threshold := abi.TokenAmount(cfg.ApprovalThreshold)
+	return cfg.MaxSignatures > 0 || (!threshold.Nil() && threshold.GreaterThan(big.Zero()))
+}
+
+func WalletSigningPolicy(cfg config.WalletSigningPolicy) func(mw wallet.MultiWallet) (*signpolicy.PolicyWallet, error) {
+	return func(mw wallet.MultiWallet) (*signpolicy.PolicyWallet, error) {
+		p := signpolicy.Policy{
+			MaxSignatures:     cfg.MaxSignatures,
+			RateInterval:      time.Duration(cfg.RateInterval),
+			ApprovalThreshold: abi.TokenAmount(cfg.ApprovalThreshold),
+			ApprovalWebhook:   cfg.ApprovalWebhook,
+			ApprovalTimeout:   time.Duration(cfg.ApprovalTimeout),
+		}
+		for _, s := range cfg.Addresses {
+			a, err := address.NewFromString(s)
+			if err != nil {
+				return nil, xerrors.Errorf("parsing signing policy address %q: %w", s, err)
+			}
+			p.Addresses = append(p.Addresses, a)
+		}
+		return signpolicy.New(mw, p)
+	}
+}
@@ -0,0 +1,244 @@
+package modules
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"go.uber.org/fx"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-fil-markets/storagemarket"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/lib/webhook"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/impl/full"
+	"github.com/filecoin-project/lotus/node/modules/helpers"
+	sealing "github.com/filecoin-project/lotus/storage/pipeline"
+)
+
+func WebhookDispatcher(cfg config.Webhooks) func(lc fx.Lifecycle) (*webhook.Dispatcher, error) {
+	return func(lc fx.Lifecycle) (*webhook.Dispatcher, error) {
+		var endpoints []webhook.Endpoint
+		for _, e := range cfg.Endpoints {
+			ep := webhook.Endpoint{
+				URL:            e.URL,
+				Secret:         e.Secret,
+				MaxAttempts:    e.MaxAttempts,
+				InitialBackoff: time.Duration(e.InitialBackoff),
+				MaxBackoff:     time.Duration(e.MaxBackoff),
+				QueueSize:      e.QueueSize,
+			}
+			for _, et := range e.Events {
+				switch webhook.EventType(et) {
+				case webhook.EventFilterMatch, webhook.EventSectorState, webhook.EventAlertRaised, webhook.EventDealActivated:
+					ep.Events = append(ep.Events, webhook.EventType(et))
+				default:
+					return nil, xerrors.Errorf("webhook %s: unknown event %q", e.URL, et)
+				}
+			}
+			endpoints = append(endpoints, ep)
+		}
+
+		d, err := webhook.New(endpoints)
+		if err != nil {
+			return nil, err
+		}
+
+		lc.Append(fx.Hook{
+			OnStart: func(context.Context) error {
+				d.Start()
+				return nil
+			},
+			OnStop: func(context.Context) error {
+				d.Close()
+				return nil
+			},
+		})
+
+		return d, nil
+	}
+}
+
+// WebhookAlerts sends raised alerts to the webhooks.
+func WebhookAlerts(al *alerting.Alerting, d *webhook.Dispatcher) {
+	al.OnRaise(func(alert alerting.Alert) {
+		d.Notify(webhook.EventAlertRaised, alert)
+	})
+}
+
+// WebhookSectorStates sends sector state transitions to the webhooks.
+func WebhookSectorStates(p *sealing.Sealing, d *webhook.Dispatcher) {
+	p.AddStateNotifee(func(before, after sealing.SectorInfo) {
+		if before.State == after.State {
+			return
+		}
+		d.Notify(webhook.EventSectorState, sealing.SealingStateEvt{
+			SectorNumber: after.SectorNumber,
+			SectorType:   after.SectorType,
+			From:         before.State,
+			After:        after.State,
+			Error:        after.LastErr,
+		})
+	})
+}
+
+type webhookDeal struct {
+	ProposalCid  cid.Cid
+	DealID       abi.DealID
+	Client       address.Address
+	Provider     address.Address
+	PieceCID     cid.Cid
+	PieceSize    abi.PaddedPieceSize
+	SectorNumber abi.SectorNumber
+}
+
+// WebhookDeals sends storage deal activations to the webhooks.
+func WebhookDeals(h storagemarket.StorageProvider, d *webhook.Dispatcher) {
+	h.SubscribeToEvents(func(event storagemarket.ProviderEvent, deal storagemarket.MinerDeal) {
+		if event != storagemarket.ProviderEventDealActivated {
+			return
+		}
+		d.Notify(webhook.EventDealActivated, webhookDeal{
+			ProposalCid:  deal.ProposalCid,
+			DealID:       deal.DealID,
+			Client:       deal.Proposal.Client,
+			Provider:     deal.Proposal.Provider,
+			PieceCID:     deal.Proposal.PieceCID,
+			PieceSize:    deal.Proposal.PieceSize,
+			SectorNumber: deal.SectorNumber,
+		})
+	})
+}
+
+type webhookFilterMatch struct {
+	Query string
+	Event *filter.CollectedEvent
+}
+
+// WebhookSavedQueries installs an actor event filter for each saved query,
+// and sends the matching events to the webhooks.
+func WebhookSavedQueries(cfg config.Webhooks) func(mctx helpers.MetricsCtx, lc fx.Lifecycle, eapi full.EthEventAPI, d *webhook.Dispatcher) error {
+	return func(mctx helpers.MetricsCtx, lc fx.Lifecycle, eapi full.EthEventAPI, d *webhook.Dispatcher) error {
+		if len(cfg.SavedQueries) == 0 {
+			return nil
+		}
+
+		ee, ok := eapi.(*full.EthEvent)
+		if !ok || ee.EventFilterManager == nil {
+			log.Warn("webhook saved queries need the Eth RPC and actor events enabled, ignoring them")
+			return nil
+		}
+
+		ctx := helpers.LifecycleCtx(mctx, lc)
+
+		for _, q := range cfg.SavedQueries {
+			addrs, keys, err := parseSavedQuery(q)
+			if err != nil {
+				return xerrors.Errorf("webhook saved query %q: %w", q.Name, err)
+			}
+
+			q := q
+			lc.Append(fx.Hook{
+				OnStart: func(context.Context) error {
+					f, err := ee.EventFilterManager.Install(ctx, -1, -1, cid.Undef, addrs, keys)
+					if err != nil {
+						return xerrors.Errorf("installing webhook saved query %q: %w", q.Name, err)
+					}
+
+					ch := make(chan interface{}, 256)
+					f.SetSubChannel(ch)
+
+					go func() {
+						defer func() {
+							f.ClearSubChannel()
+							_ = ee.EventFilterManager.Remove(context.Background(), f.ID())
+						}()
+
+						for {
+							select {
+							case <-ctx.Done():
+								return
+							case v := <-ch:
+								ce, ok := v.(*filter.CollectedEvent)
+								if !ok {
+									continue
+								}
+								d.Notify(webhook.EventFilterMatch, webhookFilterMatch{
+									Query: q.Name,
+									Event: ce,
+								})
+							}
+						}
+					}()
+
+					return nil
+				},
+			})
+		}
+
+		return nil
+	}
+}
+
+func parseSavedQuery(q config.WebhookSavedQuery) ([]address.Address, map[string][][]byte, error) {
+	addrs, err := parseEventAddresses(q.Addresses)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(q.Topics) > 4 {
+		return nil, nil, xerrors.Errorf("%d topics, events have at most 4", len(q.Topics))
+	}
+	// events emitted with the LOG{0..4} opcodes have topic keys t1..t4
+	keys := map[string][][]byte{}
+	for idx, vals := range q.Topics {
+		key := fmt.Sprintf("t%d", idx+1)
+		for _, v := range vals {
+			h, err := ethtypes.ParseEthHash(v)
+			if err != nil {
+				return nil, nil, xerrors.Errorf("parsing topic %q: %w", v, err)
+			}
+			keys[key] = append(keys[key], h[:])
+		}
+	}
+
+	return addrs, keys, nil
+}
+
+// parseEventAddresses parses the addresses of event emitters, as f4 or 0x
+// addresses. Events are only matched against the f4 addresses of their
+// emitters, so other addresses, including masked ID 0x addresses, would never
+// match and are rejected.
+func parseEventAddresses(ss []string) ([]address.Address, error) {
+	var addrs []address.Address
+	for _, s := range ss {
+		var (
+			a   address.Address
+			err error
+		)
+		if strings.HasPrefix(s, "0x") {
+			var ea ethtypes.EthAddress
+			if ea, err = ethtypes.ParseEthAddress(s); err == nil {
+				a, err = ea.ToFilecoinAddress()
+			}
+		} else {
+			a, err = address.NewFromString(s)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("parsing address %q: %w", s, err)
+		}
+		if a.Protocol() != address.Delegated {
+			return nil, xerrors.Errorf("address %q: events are only matched against f4 addresses", s)
+		}
+		addrs = append(addrs, a)
+	}
+	return addrs, nil
+}
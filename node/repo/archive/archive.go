@@ -0,0 +1,516 @@
+// Package archive creates and restores backup archives of a node repo, holding the keystore,
+// the peer identity, the config and the metadata datastore. Archives are gzipped tarballs,
+// optionally encrypted with a passphrase, with a manifest recording the checksums of their
+// entries.
+package archive
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path"
+	"reflect"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/ipfs/go-datastore"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/backupds"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/repo"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// Sections of an archive, which can be selected when creating or restoring it.
+const (
+	SectionKeystore = "keystore"
+	SectionIdentity = "identity"
+	SectionConfig   = "config"
+	SectionMetadata = "metadata"
+)
+
+var Sections = []string{SectionKeystore, SectionIdentity, SectionConfig, SectionMetadata}
+
+// identityKey is the keystore entry of the libp2p host key, backed up in the identity section.
+const identityKey = "libp2p-host"
+
+const (
+	manifestName    = "manifest.json"
+	configName      = "config.toml"
+	storageName     = "storage.json"
+	datastoreName   = "datastore"
+	manifestVersion = 1
+)
+
+var magic = []byte("LOTUSBAK")
+
+const (
+	formatPlain     byte = 1
+	formatEncrypted byte = 2
+)
+
+type Options struct {
+	// Sections to back up or restore, all sections when empty.
+	Sections []string
+	// Passphrase encrypting the archive, archives aren't encrypted without one.
+	Passphrase string
+}
+
+// Manifest describes the content of an archive.
+type Manifest struct {
+	Version  int
+	Created  time.Time
+	NodeType string
+	Entries  []Entry
+
+	// Encrypted is set when reading an encrypted archive.
+	Encrypted bool `json:"-"`
+}
+
+type Entry struct {
+	Section string
+	Name    string
+	Size    int64
+	Sha256  string
+}
+
+// Sections returns the sections in the archive.
+func (m *Manifest) Sections() []string {
+	var out []string
+	seen := map[string]bool{}
+	for _, e := range m.Entries {
+		if !seen[e.Section] {
+			seen[e.Section] = true
+			out = append(out, e.Section)
+		}
+	}
+	return out
+}
+
+// Info summarizes the archive for API clients.
+func (m *Manifest) Info() *api.BackupInfo {
+	out := &api.BackupInfo{
+		Created:   m.Created,
+		NodeType:  m.NodeType,
+		Encrypted: m.Encrypted,
+	}
+	idx := map[string]int{}
+	for _, e := range m.Entries {
+		i, ok := idx[e.Section]
+		if !ok {
+			i = len(out.Sections)
+			idx[e.Section] = i
+			out.Sections = append(out.Sections, api.BackupSection{Name: e.Section})
+		}
+		out.Sections[i].Entries++
+		out.Sections[i].Size += e.Size
+	}
+	return out
+}
+
+func selectSections(sections []string) (map[string]bool, error) {
+	if len(sections) == 0 {
+		sections = Sections
+	}
+
+	out := map[string]bool{}
+	for _, s := range sections {
+		var known bool
+		for _, k := range Sections {
+			known = known || k == s
+		}
+		if !known {
+			return nil, xerrors.Errorf("unknown backup section %q, expected one of %v", s, Sections)
+		}
+		out[s] = true
+	}
+	return out, nil
+}
+
+// Create writes an archive of the selected sections of the repo to w. The metadata section is
+// backed up from mds.
+func Create(ctx context.Context, w io.Writer, lr repo.LockedRepo, mds *backupds.Datastore, opts Options) (*Manifest, error) {
+	sections, err := selectSections(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+
+	format := formatPlain
+	if opts.Passphrase != "" {
+		format = formatEncrypted
+	}
+	if _, err := w.Write(append(append([]byte{}, magic...), format)); err != nil {
+		return nil, xerrors.Errorf("writing header: %w", err)
+	}
+
+	out := w
+	var enc *encryptWriter
+	if format == formatEncrypted {
+		enc, err = newEncryptWriter(w, opts.Passphrase)
+		if err != nil {
+			return nil, err
+		}
+		out = enc
+	}
+
+	gz := gzip.NewWriter(out)
+	tw := tar.NewWriter(gz)
+
+	m := &Manifest{
+		Version:   manifestVersion,
+		Created:   time.Now().UTC().Truncate(time.Second),
+		NodeType:  lr.RepoType().Type(),
+		Encrypted: format == formatEncrypted,
+	}
+
+	add := func(section, name string, data []byte) error {
+		sum := sha256.Sum256(data)
+		if err := writeEntry(tw, path.Join(section, name), data, m.Created); err != nil {
+			return xerrors.Errorf("writing %s/%s: %w", section, name, err)
+		}
+		m.Entries = append(m.Entries, Entry{
+			Section: section,
+			Name:    name,
+			Size:    int64(len(data)),
+			Sha256:  hex.EncodeToString(sum[:]),
+		})
+		return nil
+	}
+
+	if sections[SectionKeystore] || sections[SectionIdentity] {
+		ks, err := lr.KeyStore()
+		if err != nil {
+			return nil, xerrors.Errorf("getting keystore: %w", err)
+		}
+		names, err := ks.List()
+		if err != nil {
+			return nil, xerrors.Errorf("listing keys: %w", err)
+		}
+		for _, name := range names {
+			section := SectionKeystore
+			if name == identityKey {
+				section = SectionIdentity
+			}
+			if !sections[section] {
+				continue
+			}
+
+			ki, err := ks.Get(name)
+			if err != nil {
+				return nil, xerrors.Errorf("getting key %s: %w", name, err)
+			}
+			data, err := json.Marshal(ki)
+			if err != nil {
+				return nil, err
+			}
+			if err := add(section, name, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if sections[SectionConfig] {
+		cfg, err := lr.Config()
+		if err != nil {
+			return nil, xerrors.Errorf("getting config: %w", err)
+		}
+		var buf bytes.Buffer
+		if err := toml.NewEncoder(&buf).Encode(cfg); err != nil {
+			return nil, xerrors.Errorf("encoding config: %w", err)
+		}
+		if err := add(SectionConfig, configName, buf.Bytes()); err != nil {
+			return nil, err
+		}
+
+		sc, err := lr.GetStorage()
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			// nodes without storage paths
+		case err != nil:
+			return nil, xerrors.Errorf("getting storage config: %w", err)
+		default:
+			data, err := json.MarshalIndent(sc, "", "  ")
+			if err != nil {
+				return nil, err
+			}
+			if err := add(SectionConfig, storageName, data); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if sections[SectionMetadata] {
+		if mds == nil {
+			return nil, xerrors.Errorf("no metadata datastore to back up")
+		}
+		var buf bytes.Buffer
+		if err := mds.Backup(ctx, &buf); err != nil {
+			return nil, xerrors.Errorf("backing up metadata: %w", err)
+		}
+		if err := add(SectionMetadata, datastoreName, buf.Bytes()); err != nil {
+			return nil, err
+		}
+	}
+
+	// the manifest is written last, once the checksums of all entries are known
+	mb, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEntry(tw, manifestName, mb, m.Created); err != nil {
+		return nil, xerrors.Errorf("writing manifest: %w", err)
+	}
+
+	if err := tw.Close(); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	if enc != nil {
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+func writeEntry(tw *tar.Writer, name string, data []byte, mtime time.Time) error {
+	if err := tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     0600,
+		Size:     int64(len(data)),
+		ModTime:  mtime,
+	}); err != nil {
+		return err
+	}
+	_, err := tw.Write(data)
+	return err
+}
+
+// Verify checks the integrity of an archive, returning its manifest.
+func Verify(r io.Reader, passphrase string) (*Manifest, error) {
+	return read(r, passphrase, nil, nil)
+}
+
+// read reads the entries of an archive, checking them against the manifest at its end. When
+// the manifest of the archive is already known its checksums are verified before passing
+// entries to the callback.
+func read(r io.Reader, passphrase string, known *Manifest, cb func(e Entry, data []byte) error) (*Manifest, error) {
+	hdr := make([]byte, len(magic)+1)
+	if _, err := io.ReadFull(r, hdr); err != nil || !bytes.Equal(hdr[:len(magic)], magic) {
+		return nil, xerrors.Errorf("not a backup archive")
+	}
+
+	var encrypted bool
+	switch hdr[len(magic)] {
+	case formatPlain:
+	case formatEncrypted:
+		if passphrase == "" {
+			return nil, xerrors.Errorf("archive is encrypted, a passphrase is required")
+		}
+		dr, err := newDecryptReader(r, passphrase)
+		if err != nil {
+			return nil, err
+		}
+		r = dr
+		encrypted = true
+	default:
+		return nil, xerrors.Errorf("unknown archive format %d", hdr[len(magic)])
+	}
+
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, xerrors.Errorf("reading archive: %w", err)
+	}
+	tr := tar.NewReader(gz)
+
+	var knownSums map[string]Entry
+	if known != nil {
+		knownSums = map[string]Entry{}
+		for _, e := range known.Entries {
+			knownSums[path.Join(e.Section, e.Name)] = e
+		}
+	}
+
+	sums := map[string]string{}
+	var m *Manifest
+	for {
+		th, err := tr.Next()
+		if err == io.EOF {
+			// read to the end of the stream, checking the gzip checksum and that the
+			// archive isn't truncated
+			if _, err := io.Copy(io.Discard, gz); err != nil {
+				return nil, xerrors.Errorf("reading archive: %w", err)
+			}
+			break
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("reading archive: %w", err)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, xerrors.Errorf("reading %s: %w", th.Name, err)
+		}
+
+		if th.Name == manifestName {
+			m = new(Manifest)
+			if err := json.Unmarshal(data, m); err != nil {
+				return nil, xerrors.Errorf("decoding manifest: %w", err)
+			}
+			continue
+		}
+
+		sum := sha256.Sum256(data)
+		sums[th.Name] = hex.EncodeToString(sum[:])
+
+		if cb == nil {
+			continue
+		}
+		e, ok := knownSums[th.Name]
+		if !ok || e.Sha256 != sums[th.Name] {
+			return nil, xerrors.Errorf("entry %s doesn't match the archive manifest", th.Name)
+		}
+		if err := cb(e, data); err != nil {
+			return nil, err
+		}
+	}
+
+	if m == nil {
+		return nil, xerrors.Errorf("archive has no manifest")
+	}
+	if len(sums) != len(m.Entries) {
+		return nil, xerrors.Errorf("archive has %d entries, manifest lists %d", len(sums), len(m.Entries))
+	}
+	for _, e := range m.Entries {
+		name := path.Join(e.Section, e.Name)
+		if sums[name] != e.Sha256 {
+			return nil, xerrors.Errorf("checksum mismatch for %s", name)
+		}
+	}
+
+	m.Encrypted = encrypted
+	return m, nil
+}
+
+// Restore restores the selected sections of the archive at fpath into the repo, after checking
+// the integrity of the whole archive. Keys and metadata entries in the archive overwrite the
+// existing ones, other keys and entries are kept. The repo must be locked offline: restoring
+// into the repo of a running node would race its writes.
+func Restore(ctx context.Context, fpath string, lr repo.LockedRepo, mds datastore.Batching, opts Options) (*Manifest, error) {
+	sections, err := selectSections(opts.Sections)
+	if err != nil {
+		return nil, err
+	}
+
+	m, err := readFile(fpath, opts.Passphrase, nil, nil)
+	if err != nil {
+		return nil, xerrors.Errorf("verifying archive: %w", err)
+	}
+	if m.NodeType != lr.RepoType().Type() {
+		return nil, xerrors.Errorf("archive of a %s repo can't be restored into a %s repo", m.NodeType, lr.RepoType().Type())
+	}
+
+	if len(opts.Sections) > 0 {
+		have := map[string]bool{}
+		for _, s := range m.Sections() {
+			have[s] = true
+		}
+		for s := range sections {
+			if !have[s] {
+				return nil, xerrors.Errorf("archive has no %s section", s)
+			}
+		}
+	}
+
+	var ks types.KeyStore
+	if sections[SectionKeystore] || sections[SectionIdentity] {
+		if ks, err = lr.KeyStore(); err != nil {
+			return nil, xerrors.Errorf("getting keystore: %w", err)
+		}
+	}
+
+	_, err = readFile(fpath, opts.Passphrase, m, func(e Entry, data []byte) error {
+		if !sections[e.Section] {
+			return nil
+		}
+
+		switch e.Section {
+		case SectionKeystore, SectionIdentity:
+			var ki types.KeyInfo
+			if err := json.Unmarshal(data, &ki); err != nil {
+				return xerrors.Errorf("decoding key %s: %w", e.Name, err)
+			}
+			if err := ks.Delete(e.Name); err != nil && !errors.Is(err, types.ErrKeyInfoNotFound) {
+				return xerrors.Errorf("replacing key %s: %w", e.Name, err)
+			}
+			if err := ks.Put(e.Name, ki); err != nil {
+				return xerrors.Errorf("restoring key %s: %w", e.Name, err)
+			}
+
+		case SectionConfig:
+			switch e.Name {
+			case configName:
+				cfg, err := config.FromReader(bytes.NewReader(data), lr.RepoType().Config())
+				if err != nil {
+					return xerrors.Errorf("decoding config: %w", err)
+				}
+				if err := lr.SetConfig(func(c interface{}) {
+					reflect.ValueOf(c).Elem().Set(reflect.ValueOf(cfg).Elem())
+				}); err != nil {
+					return xerrors.Errorf("restoring config: %w", err)
+				}
+			case storageName:
+				var sc storiface.StorageConfig
+				if err := json.Unmarshal(data, &sc); err != nil {
+					return xerrors.Errorf("decoding storage config: %w", err)
+				}
+				if err := lr.SetStorage(func(c *storiface.StorageConfig) {
+					*c = sc
+				}); err != nil {
+					return xerrors.Errorf("restoring storage config: %w", err)
+				}
+			}
+
+		case SectionMetadata:
+			if mds == nil {
+				return xerrors.Errorf("no metadata datastore to restore into")
+			}
+			if err := backupds.RestoreInto(bytes.NewReader(data), mds); err != nil {
+				return xerrors.Errorf("restoring metadata: %w", err)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// VerifyFile checks the integrity of the archive at fpath.
+func VerifyFile(fpath, passphrase string) (*Manifest, error) {
+	return readFile(fpath, passphrase, nil, nil)
+}
+
+func readFile(fpath, passphrase string, known *Manifest, cb func(e Entry, data []byte) error) (*Manifest, error) {
+	f, err := os.Open(fpath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() //nolint:errcheck
+
+	return read(f, passphrase, known, cb)
+}
@@ -0,0 +1,134 @@
+// stm: #unit
+package archive
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/lib/backupds"
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+func lockedRepo(t *testing.T) (repo.LockedRepo, datastore.Batching) {
+	lr, err := repo.NewMemory(nil).Lock(repo.FullNode)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = lr.Close() })
+
+	mds, err := lr.Datastore(context.Background(), "/metadata")
+	require.NoError(t, err)
+	return lr, mds
+}
+
+func TestCreateRestore(t *testing.T) {
+	ctx := context.Background()
+	lr, mds := lockedRepo(t)
+
+	ks, err := lr.KeyStore()
+	require.NoError(t, err)
+	require.NoError(t, ks.Put("wallet-a", types.KeyInfo{Type: types.KTSecp256k1, PrivateKey: []byte("wallet")}))
+	require.NoError(t, ks.Put(identityKey, types.KeyInfo{Type: "libp2p-host", PrivateKey: []byte("host")}))
+	require.NoError(t, mds.Put(ctx, datastore.NewKey("/sealing/1"), []byte("sector")))
+	require.NoError(t, lr.SetConfig(func(c interface{}) {
+		c.(*config.FullNode).Wallet.EnableLedger = true
+	}))
+
+	bds, err := backupds.Wrap(mds, backupds.NoLogdir)
+	require.NoError(t, err)
+
+	fpath := filepath.Join(t.TempDir(), "backup")
+	var buf bytes.Buffer
+	m, err := Create(ctx, &buf, lr, bds, Options{Passphrase: "secret"})
+	require.NoError(t, err)
+	require.ElementsMatch(t, Sections, m.Sections())
+	require.NoError(t, os.WriteFile(fpath, buf.Bytes(), 0600))
+
+	_, err = VerifyFile(fpath, "")
+	require.ErrorContains(t, err, "passphrase is required")
+	_, err = VerifyFile(fpath, "wrong")
+	require.ErrorContains(t, err, "wrong passphrase")
+
+	vm, err := VerifyFile(fpath, "secret")
+	require.NoError(t, err)
+	require.True(t, vm.Encrypted)
+	require.Equal(t, m.Entries, vm.Entries)
+
+	// restore the keystore and metadata into an empty repo
+	lr2, mds2 := lockedRepo(t)
+	_, err = Restore(ctx, fpath, lr2, mds2, Options{Sections: []string{SectionKeystore, SectionMetadata}, Passphrase: "secret"})
+	require.NoError(t, err)
+
+	ks2, err := lr2.KeyStore()
+	require.NoError(t, err)
+	ki, err := ks2.Get("wallet-a")
+	require.NoError(t, err)
+	require.Equal(t, []byte("wallet"), ki.PrivateKey)
+	_, err = ks2.Get(identityKey)
+	require.ErrorIs(t, err, types.ErrKeyInfoNotFound)
+
+	v, err := mds2.Get(ctx, datastore.NewKey("/sealing/1"))
+	require.NoError(t, err)
+	require.Equal(t, []byte("sector"), v)
+
+	cfg, err := lr2.Config()
+	require.NoError(t, err)
+	require.False(t, cfg.(*config.FullNode).Wallet.EnableLedger)
+
+	// restoring again replaces existing keys
+	_, err = Restore(ctx, fpath, lr2, mds2, Options{Passphrase: "secret"})
+	require.NoError(t, err)
+	ki, err = ks2.Get(identityKey)
+	require.NoError(t, err)
+	require.Equal(t, []byte("host"), ki.PrivateKey)
+
+	cfg, err = lr2.Config()
+	require.NoError(t, err)
+	require.True(t, cfg.(*config.FullNode).Wallet.EnableLedger)
+}
+
+func TestCorruptArchive(t *testing.T) {
+	ctx := context.Background()
+	lr, mds := lockedRepo(t)
+	require.NoError(t, mds.Put(ctx, datastore.NewKey("/a"), []byte("b")))
+
+	bds, err := backupds.Wrap(mds, backupds.NoLogdir)
+	require.NoError(t, err)
+
+	for _, pass := range []string{"", "secret"} {
+		var buf bytes.Buffer
+		_, err := Create(ctx, &buf, lr, bds, Options{Sections: []string{SectionMetadata}, Passphrase: pass})
+		require.NoError(t, err)
+
+		data := buf.Bytes()
+		m, err := Verify(bytes.NewReader(data), pass)
+		require.NoError(t, err)
+		require.Equal(t, []string{SectionMetadata}, m.Sections())
+
+		// truncated
+		_, err = Verify(bytes.NewReader(data[:len(data)-10]), pass)
+		require.Error(t, err)
+
+		// flipped byte
+		bad := append([]byte{}, data...)
+		bad[len(bad)/2] ^= 0xff
+		_, err = Verify(bytes.NewReader(bad), pass)
+		require.Error(t, err)
+	}
+
+	// sections missing from the archive can't be restored
+	var buf bytes.Buffer
+	_, err = Create(ctx, &buf, lr, bds, Options{Sections: []string{SectionMetadata}})
+	require.NoError(t, err)
+	fpath := filepath.Join(t.TempDir(), "backup")
+	require.NoError(t, os.WriteFile(fpath, buf.Bytes(), 0600))
+
+	_, err = Restore(ctx, fpath, lr, mds, Options{Sections: []string{SectionKeystore}})
+	require.ErrorContains(t, err, "no keystore section")
+}
@@ -0,0 +1,175 @@
+package archive
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"io"
+
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/xerrors"
+)
+
+// Encrypted archives are split in chunks sealed with AES-GCM, under a key derived from the
+// passphrase with scrypt. Chunk nonces count the chunks, and the last chunk is flagged in its
+// additional data, so reordered or truncated archives fail to decrypt.
+
+const (
+	saltLen   = 16
+	chunkSize = 64 << 10
+
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	chunkMore = []byte{0}
+	chunkLast = []byte{1}
+)
+
+func deriveKey(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if err != nil {
+		return nil, xerrors.Errorf("deriving key: %w", err)
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(aead cipher.AEAD, n uint64) []byte {
+	nonce := make([]byte, aead.NonceSize())
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], n)
+	return nonce
+}
+
+type encryptWriter struct {
+	out  io.Writer
+	aead cipher.AEAD
+	buf  []byte
+	n    uint64
+}
+
+func newEncryptWriter(out io.Writer, passphrase string) (*encryptWriter, error) {
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	aead, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := out.Write(salt); err != nil {
+		return nil, err
+	}
+	return &encryptWriter{out: out, aead: aead, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (w *encryptWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		if len(w.buf) == chunkSize {
+			if err := w.seal(chunkMore); err != nil {
+				return written, err
+			}
+		}
+		n := copy(w.buf[len(w.buf):chunkSize], p)
+		w.buf = w.buf[:len(w.buf)+n]
+		p = p[n:]
+		written += n
+	}
+	return written, nil
+}
+
+func (w *encryptWriter) seal(flag []byte) error {
+	ct := w.aead.Seal(nil, chunkNonce(w.aead, w.n), w.buf, flag)
+	w.n++
+	w.buf = w.buf[:0]
+
+	var hdr [4]byte
+	binary.BigEndian.PutUint32(hdr[:], uint32(len(ct)))
+	if _, err := w.out.Write(hdr[:]); err != nil {
+		return err
+	}
+	_, err := w.out.Write(ct)
+	return err
+}
+
+// Close writes the last chunk, it doesn't close the underlying writer.
+func (w *encryptWriter) Close() error {
+	return w.seal(chunkLast)
+}
+
+type decryptReader struct {
+	in   io.Reader
+	aead cipher.AEAD
+	buf  []byte
+	n    uint64
+	last bool
+}
+
+func newDecryptReader(in io.Reader, passphrase string) (*decryptReader, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(in, salt); err != nil {
+		return nil, xerrors.Errorf("reading salt: %w", err)
+	}
+	aead, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+	return &decryptReader{in: in, aead: aead}, nil
+}
+
+func (r *decryptReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.last {
+			return 0, io.EOF
+		}
+		if err := r.open(); err != nil {
+			return 0, err
+		}
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+func (r *decryptReader) open() error {
+	var hdr [4]byte
+	if _, err := io.ReadFull(r.in, hdr[:]); err != nil {
+		return xerrors.Errorf("archive truncated: %w", err)
+	}
+	size := binary.BigEndian.Uint32(hdr[:])
+	if size > chunkSize+uint32(r.aead.Overhead()) {
+		return xerrors.Errorf("invalid chunk size %d", size)
+	}
+
+	ct := make([]byte, size)
+	if _, err := io.ReadFull(r.in, ct); err != nil {
+		return xerrors.Errorf("archive truncated: %w", err)
+	}
+
+	nonce := chunkNonce(r.aead, r.n)
+	pt, err := r.aead.Open(nil, nonce, ct, chunkMore)
+	if err != nil {
+		pt, err = r.aead.Open(nil, nonce, ct, chunkLast)
+		if err != nil {
+			return xerrors.Errorf("decrypting archive: wrong passphrase or corrupted archive")
+		}
+		r.last = true
+	}
+	r.n++
+	r.buf = pt
+
+	if r.last {
+		// nothing may follow the last chunk
+		if n, _ := r.in.Read(hdr[:1]); n != 0 {
+			return xerrors.Errorf("data after the end of the archive")
+		}
+	}
+	return nil
+}
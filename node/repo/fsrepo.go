@@ -36,6 +36,7 @@ const (
 	fsStorageConfig = "storage.json"
 	fsDatastore     = "datastore"
 	fsLock          = "repo.lock"
+	fsLockOwner     = "repo.lock.owner"
 	fsKeystore      = "keystore"
 	fsSqlite        = "sqlite"
 )
@@ -375,6 +376,10 @@ func (fsr *FsRepo) Lock(repoType RepoType) (LockedRepo, error) {
 	if err != nil {
 		return nil, xerrors.Errorf("could not lock the repo: %w", err)
 	}
+	if err := writeLockOwner(fsr.path, repoType); err != nil {
+		// only used to diagnose locking errors
+		log.Warnw("recording repo lock owner", "error", err)
+	}
 	return &fsLockedRepo{
 		path:       fsr.path,
 		configPath: fsr.configPath,
@@ -453,6 +458,10 @@ func (fsr *fsLockedRepo) Close() error {
 		}
 	}
 
+	if err := os.Remove(fsr.join(fsLockOwner)); err != nil && !os.IsNotExist(err) {
+		log.Warnw("removing repo lock owner", "error", err)
+	}
+
 	err = fsr.closer.Close()
 	fsr.closer = nil
 	return err
@@ -43,6 +43,17 @@ func levelDs(path string, readonly bool) (datastore.Batching, error) {
 	})
 }
 
+// MetadataDatastorePath returns the path of the metadata datastore of the repo at repoPath.
+func MetadataDatastorePath(repoPath string) string {
+	return filepath.Join(repoPath, fsDatastore, "metadata")
+}
+
+// OpenMetadataDatastore opens the metadata datastore at path without going through a locked
+// repo, for tools working on the datastore directly. The repo must be locked by the caller.
+func OpenMetadataDatastore(path string, readonly bool) (datastore.Batching, error) {
+	return levelDs(path, readonly)
+}
+
 func (fsr *fsLockedRepo) openDatastores(readonly bool) (map[string]datastore.Batching, error) {
 	if err := os.MkdirAll(fsr.join(fsDatastore), 0755); err != nil {
 		return nil, xerrors.Errorf("mkdir %s: %w", fsr.join(fsDatastore), err)
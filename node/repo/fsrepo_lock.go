@@ -0,0 +1,203 @@
+package repo
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	fslock "github.com/ipfs/go-fs-lock"
+	"golang.org/x/xerrors"
+)
+
+// The repo lock is an fcntl lock on an empty file, released by the OS when its holder exits.
+// As the lock file must stay empty, the holder is recorded next to it.
+
+// LockOwner identifies the process holding a repo lock.
+type LockOwner struct {
+	PID      int
+	Hostname string
+	RepoType string
+	Since    time.Time
+}
+
+// LockStatus describes the lock of a repo.
+type LockStatus struct {
+	Path string
+	// Locked is set when a process holds the lock.
+	Locked bool
+	// Owner is the recorded holder of the lock, nil when unknown.
+	Owner *LockOwner
+	// OwnerAlive is set when the recorded holder process is running, it can only be checked
+	// for holders on this host. The recorded PID isn't authoritative, the holder may run in
+	// another PID namespace, so a lock held according to the OS is never stale.
+	OwnerAlive bool
+	// Stale is set when the lock prevents locking the repo though no process holds it, the
+	// lock can then be broken with BreakLock.
+	Stale bool
+	// Reason explains the status.
+	Reason string
+}
+
+func (s LockStatus) String() string {
+	if s.Owner == nil {
+		return s.Reason
+	}
+	return fmt.Sprintf("%s (pid %d on %s, %s repo, since %s)", s.Reason, s.Owner.PID, s.Owner.Hostname, s.Owner.RepoType, s.Owner.Since.Format(time.RFC3339))
+}
+
+func writeLockOwner(path string, rt RepoType) error {
+	host, err := os.Hostname()
+	if err != nil {
+		return xerrors.Errorf("getting hostname: %w", err)
+	}
+
+	b, err := json.Marshal(LockOwner{
+		PID:      os.Getpid(),
+		Hostname: host,
+		RepoType: rt.Type(),
+		Since:    time.Now().UTC().Truncate(time.Second),
+	})
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(path, fsLockOwner), b, 0644)
+}
+
+func readLockOwner(path string) (*LockOwner, error) {
+	b, err := os.ReadFile(filepath.Join(path, fsLockOwner))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var o LockOwner
+	if err := json.Unmarshal(b, &o); err != nil {
+		// written by an interrupted holder, the holder is unknown
+		return nil, nil
+	}
+	return &o, nil
+}
+
+// processAlive checks whether a process runs on this host.
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = p.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, os.ErrPermission) || errors.Is(err, syscall.EPERM)
+}
+
+// LockStatus identifies the holder of the repo lock and checks whether the lock is stale.
+func (fsr *FsRepo) LockStatus() (LockStatus, error) {
+	st := LockStatus{Path: filepath.Join(fsr.path, fsLock)}
+
+	owner, err := readLockOwner(fsr.path)
+	if err != nil {
+		return st, xerrors.Errorf("reading lock owner: %w", err)
+	}
+	st.Owner = owner
+
+	fi, err := os.Stat(st.Path)
+	switch {
+	case os.IsNotExist(err):
+		st.Reason = "repo isn't locked"
+		return st, nil
+	case err != nil:
+		return st, err
+	case fi.Size() > 0:
+		// lotus never writes to the lock file, it can't be locked anymore
+		st.Locked, st.Stale = true, true
+		st.Reason = "lock file isn't empty"
+		return st, nil
+	}
+
+	locked, err := fslock.Locked(fsr.path, fsLock)
+	if err != nil {
+		return st, xerrors.Errorf("checking lock: %w", err)
+	}
+	st.Locked = locked
+
+	if !locked {
+		st.Reason = "repo isn't locked"
+		return st, nil
+	}
+
+	if owner == nil {
+		st.Reason = "locked by an unidentified process"
+		return st, nil
+	}
+
+	host, err := os.Hostname()
+	if err != nil {
+		return st, xerrors.Errorf("getting hostname: %w", err)
+	}
+	if owner.Hostname != host {
+		st.Reason = "locked by a process on another host"
+		return st, nil
+	}
+
+	st.OwnerAlive = processAlive(owner.PID)
+	if st.OwnerAlive {
+		st.Reason = "locked by a running process"
+		return st, nil
+	}
+
+	st.Reason = "locked by a live process, though the recorded holder isn't running here, it may run in another container"
+	return st, nil
+}
+
+// BreakLock removes a stale repo lock, so that the repo can be locked again. The lock is only
+// removed once it's acquired, so that the lock of a live holder is never broken.
+func (fsr *FsRepo) BreakLock() (LockStatus, error) {
+	st, err := fsr.LockStatus()
+	if err != nil {
+		return st, err
+	}
+	if !st.Stale {
+		return st, xerrors.Errorf("lock isn't stale: %s", st)
+	}
+
+	// lotus can only lock empty lock files, and no process holding the lock writes to it
+	if err := os.Truncate(st.Path, 0); err != nil {
+		return st, xerrors.Errorf("truncating lock file: %w", err)
+	}
+	cl, err := fslock.Lock(fsr.path, fsLock)
+	if err != nil {
+		return st, xerrors.Errorf("acquiring the lock, it's held by a live process: %w", err)
+	}
+	defer cl.Close() //nolint:errcheck
+
+	log.Warnw("breaking repo lock", "path", st.Path, "status", st.String())
+	if err := os.Remove(filepath.Join(fsr.path, fsLockOwner)); err != nil && !os.IsNotExist(err) {
+		return st, xerrors.Errorf("removing lock owner: %w", err)
+	}
+	if err := os.Remove(st.Path); err != nil && !os.IsNotExist(err) {
+		return st, xerrors.Errorf("removing lock file: %w", err)
+	}
+	return st, nil
+}
+
+// ExplainLockError adds the status of the lock to errors locking a repo, instead of the bare
+// ErrRepoAlreadyLocked.
+func ExplainLockError(r Repo, err error) error {
+	fsr, ok := r.(*FsRepo)
+	if !ok || err == nil {
+		return err
+	}
+
+	st, serr := fsr.LockStatus()
+	if serr != nil || !st.Locked {
+		return err
+	}
+	if st.Stale {
+		return xerrors.Errorf("%w: %s; the stale lock can be broken with 'lotus --repo=%s repo unlock'", err, st, fsr.path)
+	}
+	return xerrors.Errorf("%w: %s", err, st)
+}
@@ -0,0 +1,88 @@
+// stm: #unit
+package repo
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLockStatus(t *testing.T) {
+	r := genFsRepo(t)
+
+	st, err := r.LockStatus()
+	require.NoError(t, err)
+	require.False(t, st.Locked)
+
+	lr, err := r.Lock(FullNode)
+	require.NoError(t, err)
+
+	st, err = r.LockStatus()
+	require.NoError(t, err)
+	require.True(t, st.Locked)
+	require.NotNil(t, st.Owner)
+	require.Equal(t, os.Getpid(), st.Owner.PID)
+	require.Equal(t, "FullNode", st.Owner.RepoType)
+	require.True(t, st.OwnerAlive)
+	require.False(t, st.Stale)
+
+	_, err = r.BreakLock()
+	require.Error(t, err)
+
+	_, err = r.Lock(FullNode)
+	require.ErrorIs(t, ExplainLockError(r, err), ErrRepoAlreadyLocked)
+	require.Contains(t, ExplainLockError(r, err).Error(), "locked by a running process")
+
+	// the recorded holder exited
+	cmd := exec.Command("true")
+	require.NoError(t, cmd.Run())
+	owner := *st.Owner
+	owner.PID = cmd.Process.Pid
+	b, err := json.Marshal(owner)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(r.path, fsLockOwner), b, 0644))
+
+	// the lock is still held, the recorded PID may be from another PID
+	// namespace
+	st, err = r.LockStatus()
+	require.NoError(t, err)
+	require.True(t, st.Locked)
+	require.False(t, st.OwnerAlive)
+	require.False(t, st.Stale)
+
+	_, err = r.BreakLock()
+	require.Error(t, err)
+	require.FileExists(t, filepath.Join(r.path, fsLock))
+
+	require.NoError(t, lr.Close())
+	require.NoFileExists(t, filepath.Join(r.path, fsLockOwner))
+
+	_, err = r.BreakLock()
+	require.Error(t, err)
+}
+
+func TestBreakCorruptLock(t *testing.T) {
+	r := genFsRepo(t)
+
+	// the lock file must be empty to be locked
+	require.NoError(t, os.WriteFile(filepath.Join(r.path, fsLock), []byte("{}"), 0644))
+
+	_, err := r.Lock(FullNode)
+	require.Error(t, err)
+
+	st, err := r.LockStatus()
+	require.NoError(t, err)
+	require.True(t, st.Stale)
+
+	_, err = r.BreakLock()
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(r.path, fsLock))
+
+	lr, err := r.Lock(FullNode)
+	require.NoError(t, err)
+	require.NoError(t, lr.Close())
+}
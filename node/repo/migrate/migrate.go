@@ -0,0 +1,515 @@
+// Package migrate upgrades the schemas of the stores of a repo. Migrations are versioned per
+// store, each store is migrated atomically, and stores are backed up before being migrated so
+// that a failed or unwanted upgrade can be rolled back.
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	_ "github.com/mattn/go-sqlite3"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/node/config"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+var log = logging.Logger("repo-migrate")
+
+// Store is a store of the repo with a versioned schema.
+type Store string
+
+const (
+	// Metadata is the metadata datastore of the repo.
+	Metadata Store = "metadata"
+	// Events is the sqlite database of the events index.
+	Events Store = "events"
+	// TxIndex is the sqlite database of the eth transaction hash index.
+	TxIndex Store = "txindex"
+)
+
+var stores = []Store{Metadata, Events, TxIndex}
+
+// versionKey holds the schema version of the metadata datastore. Datastores without it have
+// version 0.
+var versionKey = datastore.NewKey("/migrate/version")
+
+// Migration upgrades a store to Version from the version before it.
+type Migration struct {
+	Store       Store
+	Version     int
+	Description string
+
+	// Datastore migrates the metadata datastore, writes go to the batch which is committed with
+	// the new version. Datastore migrations also run on datastores created before versions were
+	// recorded, and must be idempotent.
+	Datastore func(ctx context.Context, ds datastore.Read, b datastore.Batch) error
+	// SQL migrates a sqlite database, in the transaction recording the new version.
+	SQL func(ctx context.Context, tx *sql.Tx) error
+}
+
+// baseVersions are the versions of the stores before any migration.
+var baseVersions = map[Store]int{
+	Metadata: 0,
+	Events:   1,
+	TxIndex:  1,
+}
+
+// Latest returns the version stores are migrated to.
+func Latest(store Store) int {
+	v := baseVersions[store]
+	for _, m := range migrations {
+		if m.Store == store && m.Version > v {
+			v = m.Version
+		}
+	}
+	return v
+}
+
+// Repo locates the stores migrations are applied to. Stores with an empty path, or which
+// don't exist yet, are skipped.
+type Repo struct {
+	Metadata string // directory of the metadata datastore
+	Events   string // events index database
+	TxIndex  string // eth transaction hash database
+
+	// BackupDir is where stores are backed up before being migrated.
+	BackupDir string
+}
+
+// ForRepo locates the stores of a locked repo.
+func ForRepo(lr repo.LockedRepo) (Repo, error) {
+	sqlitePath, err := lr.SqlitePath()
+	if err != nil {
+		return Repo{}, xerrors.Errorf("getting sqlite path: %w", err)
+	}
+
+	events := filepath.Join(sqlitePath, "events.db")
+	c, err := lr.Config()
+	if err != nil {
+		return Repo{}, xerrors.Errorf("loading config: %w", err)
+	}
+	if cfg, ok := c.(*config.FullNode); ok && cfg.Fevm.Events.DatabasePath != "" {
+		events = cfg.Fevm.Events.DatabasePath
+	}
+
+	return Repo{
+		Metadata:  repo.MetadataDatastorePath(lr.Path()),
+		Events:    events,
+		TxIndex:   filepath.Join(sqlitePath, "txhash.db"),
+		BackupDir: filepath.Join(lr.Path(), "migration-backups"),
+	}, nil
+}
+
+func (r Repo) path(s Store) string {
+	switch s {
+	case Metadata:
+		return r.Metadata
+	case Events:
+		return r.Events
+	case TxIndex:
+		return r.TxIndex
+	}
+	return ""
+}
+
+// Status is the schema version of a store of the repo.
+type Status struct {
+	Store   Store
+	Path    string
+	Exists  bool
+	Version int
+	Latest  int
+}
+
+// Statuses returns the schema versions of the stores of the repo.
+func Statuses(ctx context.Context, r Repo) ([]Status, error) {
+	var out []Status
+	for _, s := range stores {
+		path := r.path(s)
+		if path == "" {
+			continue
+		}
+
+		st := Status{Store: s, Path: path, Latest: Latest(s)}
+
+		var err error
+		if s == Metadata {
+			st.Version, st.Exists, err = datastoreVersion(ctx, path)
+		} else {
+			st.Version, st.Exists, err = sqliteVersion(ctx, path)
+		}
+		if err != nil {
+			return nil, xerrors.Errorf("getting version of %s store: %w", s, err)
+		}
+
+		out = append(out, st)
+	}
+	return out, nil
+}
+
+// Pending returns the migrations not applied to the stores of the repo yet, in the order they
+// are applied.
+func Pending(ctx context.Context, r Repo) ([]Migration, error) {
+	sts, err := Statuses(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []Migration
+	for _, st := range sts {
+		if !st.Exists {
+			// created with the latest schema when first opened
+			continue
+		}
+		out = append(out, storeMigrations(st.Store, st.Version)...)
+	}
+	return out, nil
+}
+
+func storeMigrations(s Store, from int) []Migration {
+	var out []Migration
+	for _, m := range migrations {
+		if m.Store == s && m.Version > from {
+			out = append(out, m)
+		}
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Version < out[j].Version
+	})
+	return out
+}
+
+// Result describes a migration run.
+type Result struct {
+	Applied []Migration
+	DryRun  bool
+
+	// Backup is the directory holding the backups of the migrated stores, which can be put back
+	// with Restore.
+	Backup string
+}
+
+// Run applies the pending migrations. Each store is migrated atomically; when a store fails
+// to migrate, the stores already migrated are restored from their backups. With dryRun the
+// migrations are applied without committing them, checking they would succeed.
+//
+// The repo must be locked, and its stores must not be open.
+func Run(ctx context.Context, r Repo, dryRun bool) (*Result, error) {
+	pending, err := Pending(ctx, r)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{DryRun: dryRun}
+	if len(pending) == 0 {
+		return res, nil
+	}
+
+	var byStore []Store
+	migs := map[Store][]Migration{}
+	for _, m := range pending {
+		if _, ok := migs[m.Store]; !ok {
+			byStore = append(byStore, m.Store)
+		}
+		migs[m.Store] = append(migs[m.Store], m)
+	}
+
+	if dryRun {
+		for _, s := range byStore {
+			if err := apply(ctx, s, r.path(s), migs[s], false); err != nil {
+				return nil, xerrors.Errorf("migrating %s store: %w", s, err)
+			}
+		}
+		res.Applied = pending
+		return res, nil
+	}
+
+	if r.BackupDir == "" {
+		return nil, xerrors.Errorf("no backup directory")
+	}
+	backup := filepath.Join(r.BackupDir, time.Now().UTC().Format("20060102T150405Z"))
+	for _, s := range byStore {
+		log.Infow("backing up store", "store", s, "backup", backup)
+		if err := backupStore(r.path(s), filepath.Join(backup, string(s))); err != nil {
+			return nil, xerrors.Errorf("backing up %s store: %w", s, err)
+		}
+	}
+
+	for i, s := range byStore {
+		log.Infow("migrating store", "store", s, "to", migs[s][len(migs[s])-1].Version)
+		if err := apply(ctx, s, r.path(s), migs[s], true); err != nil {
+			// the failed store wasn't changed, put back the ones migrated before it
+			for _, done := range byStore[:i] {
+				if rerr := restoreStore(filepath.Join(backup, string(done)), r.path(done)); rerr != nil {
+					log.Errorw("rolling back migration", "store", done, "backup", backup, "error", rerr)
+				}
+			}
+			return nil, xerrors.Errorf("migrating %s store (backups in %s): %w", s, backup, err)
+		}
+	}
+
+	res.Applied = pending
+	res.Backup = backup
+	return res, nil
+}
+
+// Restore puts back the stores backed up in the backup directory of a migration run, reverting
+// them to their version before the migration. The repo must be locked, and its stores must not
+// be open.
+func Restore(r Repo, backup string) error {
+	var restored int
+	for _, s := range stores {
+		src := filepath.Join(backup, string(s))
+		if _, err := os.Stat(src); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if r.path(s) == "" {
+			return xerrors.Errorf("no path for the %s store", s)
+		}
+
+		if err := restoreStore(src, r.path(s)); err != nil {
+			return xerrors.Errorf("restoring %s store: %w", s, err)
+		}
+		restored++
+	}
+
+	if restored == 0 {
+		return xerrors.Errorf("no store backups in %s", backup)
+	}
+	return nil
+}
+
+func apply(ctx context.Context, s Store, path string, migs []Migration, commit bool) error {
+	if s == Metadata {
+		return applyDatastore(ctx, path, migs, commit)
+	}
+	return applySQL(ctx, path, migs, commit)
+}
+
+func applyDatastore(ctx context.Context, path string, migs []Migration, commit bool) (err error) {
+	ds, err := repo.OpenMetadataDatastore(path, false)
+	if err != nil {
+		return xerrors.Errorf("opening datastore: %w", err)
+	}
+	defer func() {
+		if cerr := ds.Close(); cerr != nil && err == nil {
+			err = xerrors.Errorf("closing datastore: %w", cerr)
+		}
+	}()
+
+	b, err := ds.Batch(ctx)
+	if err != nil {
+		return xerrors.Errorf("creating batch: %w", err)
+	}
+
+	for _, m := range migs {
+		if m.Datastore == nil {
+			return xerrors.Errorf("migration to version %d has no datastore migration", m.Version)
+		}
+		if err := m.Datastore(ctx, ds, b); err != nil {
+			return xerrors.Errorf("migrating to version %d: %w", m.Version, err)
+		}
+	}
+
+	var v [8]byte
+	binary.BigEndian.PutUint64(v[:], uint64(migs[len(migs)-1].Version))
+	if err := b.Put(ctx, versionKey, v[:]); err != nil {
+		return xerrors.Errorf("writing version: %w", err)
+	}
+
+	if !commit {
+		return nil
+	}
+	return b.Commit(ctx)
+}
+
+func applySQL(ctx context.Context, path string, migs []Migration, commit bool) (err error) {
+	db, err := sql.Open("sqlite3", path+"?mode=rw")
+	if err != nil {
+		return xerrors.Errorf("open sqlite3 database: %w", err)
+	}
+	defer func() {
+		if cerr := db.Close(); cerr != nil && err == nil {
+			err = xerrors.Errorf("closing database: %w", cerr)
+		}
+	}()
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return xerrors.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	for _, m := range migs {
+		if m.SQL == nil {
+			return xerrors.Errorf("migration to version %d has no sql migration", m.Version)
+		}
+		if err := m.SQL(ctx, tx); err != nil {
+			return xerrors.Errorf("migrating to version %d: %w", m.Version, err)
+		}
+		if _, err := tx.ExecContext(ctx, "INSERT OR IGNORE INTO _meta (version) VALUES (?)", m.Version); err != nil {
+			return xerrors.Errorf("recording version %d: %w", m.Version, err)
+		}
+	}
+
+	if !commit {
+		return nil
+	}
+	return tx.Commit()
+}
+
+func datastoreVersion(ctx context.Context, path string) (int, bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	ds, err := repo.OpenMetadataDatastore(path, true)
+	if err != nil {
+		return 0, false, xerrors.Errorf("opening datastore: %w", err)
+	}
+	defer ds.Close() //nolint:errcheck
+
+	v, err := ds.Get(ctx, versionKey)
+	switch {
+	case errors.Is(err, datastore.ErrNotFound):
+		return 0, true, nil
+	case err != nil:
+		return 0, false, err
+	case len(v) != 8:
+		return 0, false, xerrors.Errorf("invalid version record of %d bytes", len(v))
+	}
+	return int(binary.BigEndian.Uint64(v)), true, nil
+}
+
+func sqliteVersion(ctx context.Context, path string) (int, bool, error) {
+	if _, err := os.Stat(path); err != nil {
+		if os.IsNotExist(err) {
+			return 0, false, nil
+		}
+		return 0, false, err
+	}
+
+	db, err := sql.Open("sqlite3", path+"?mode=ro")
+	if err != nil {
+		return 0, false, xerrors.Errorf("open sqlite3 database: %w", err)
+	}
+	defer db.Close() //nolint:errcheck
+
+	var name string
+	err = db.QueryRowContext(ctx, "SELECT name FROM sqlite_master WHERE type='table' AND name='_meta'").Scan(&name)
+	if err == sql.ErrNoRows {
+		// empty database, the schema is created when it's opened
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, xerrors.Errorf("looking for _meta table: %w", err)
+	}
+
+	var version int
+	if err := db.QueryRowContext(ctx, "SELECT max(version) FROM _meta").Scan(&version); err != nil {
+		return 0, false, xerrors.Errorf("reading version: %w", err)
+	}
+	return version, true, nil
+}
+
+// sqlite keeps uncheckpointed writes of databases in WAL mode next to them
+var sqliteSuffixes = []string{"", "-wal", "-shm"}
+
+// backupStore copies the datastore directory or the sqlite database at path to dst.
+func backupStore(path, dst string) error {
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+
+	fi, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		return copyDir(path, dst)
+	}
+
+	for _, sfx := range sqliteSuffixes {
+		if err := copyFile(path+sfx, dst+sfx); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// restoreStore replaces the store at path with its backup.
+func restoreStore(backup, path string) error {
+	fi, err := os.Stat(backup)
+	if err != nil {
+		return err
+	}
+	if fi.IsDir() {
+		if err := os.RemoveAll(path); err != nil {
+			return err
+		}
+		return copyDir(backup, path)
+	}
+
+	for _, sfx := range sqliteSuffixes {
+		if err := os.Remove(path + sfx); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		if err := copyFile(backup+sfx, path+sfx); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dst, rel), 0755)
+		}
+		return copyFile(path, filepath.Join(dst, rel))
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close() //nolint:errcheck
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return err
+	}
+	if err := out.Sync(); err != nil {
+		_ = out.Close()
+		return err
+	}
+	return out.Close()
+}
@@ -0,0 +1,163 @@
+// stm: #unit
+package migrate
+
+import (
+	"context"
+	"database/sql"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/chain/events/filter"
+	"github.com/filecoin-project/lotus/node/repo"
+)
+
+// creates a database with the schema of the first events index version
+func createEventsV1(t *testing.T, path string) {
+	db, err := sql.Open("sqlite3", path+"?mode=rwc")
+	require.NoError(t, err)
+	defer db.Close() //nolint:errcheck
+
+	for _, ddl := range []string{
+		`CREATE TABLE event (id INTEGER PRIMARY KEY, height INTEGER NOT NULL, tipset_key BLOB NOT NULL, tipset_key_cid BLOB NOT NULL,
+			emitter_addr BLOB NOT NULL, event_index INTEGER NOT NULL, message_cid BLOB NOT NULL, message_index INTEGER NOT NULL,
+			reverted INTEGER NOT NULL, UNIQUE (tipset_key_cid, event_index))`,
+		`CREATE TABLE event_entry (event_id INTEGER, indexed INTEGER NOT NULL, flags BLOB NOT NULL, key TEXT NOT NULL,
+			codec INTEGER, value BLOB NOT NULL)`,
+		`CREATE TABLE _meta (version UINT64 NOT NULL UNIQUE)`,
+		`INSERT INTO _meta (version) VALUES (1)`,
+	} {
+		_, err := db.Exec(ddl)
+		require.NoError(t, err)
+	}
+}
+
+func TestMigrateEvents(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	r := Repo{
+		Events:    filepath.Join(dir, "events.db"),
+		TxIndex:   filepath.Join(dir, "txhash.db"),
+		BackupDir: filepath.Join(dir, "backups"),
+	}
+
+	// stores which don't exist are created at the latest version
+	pending, err := Pending(ctx, r)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	createEventsV1(t, r.Events)
+
+	_, err = filter.NewEventIndex(r.Events)
+	require.ErrorContains(t, err, "lotus repo migrate")
+
+	pending, err = Pending(ctx, r)
+	require.NoError(t, err)
+	require.Len(t, pending, 1)
+	require.Equal(t, Events, pending[0].Store)
+	require.Equal(t, 2, pending[0].Version)
+
+	// dry runs don't change the stores
+	res, err := Run(ctx, r, true)
+	require.NoError(t, err)
+	require.True(t, res.DryRun)
+	require.Len(t, res.Applied, 1)
+	require.Empty(t, res.Backup)
+
+	version, _, err := sqliteVersion(ctx, r.Events)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+
+	res, err = Run(ctx, r, false)
+	require.NoError(t, err)
+	require.Len(t, res.Applied, 1)
+	require.FileExists(t, filepath.Join(res.Backup, string(Events)))
+
+	version, _, err = sqliteVersion(ctx, r.Events)
+	require.NoError(t, err)
+	require.Equal(t, Latest(Events), version)
+
+	ei, err := filter.NewEventIndex(r.Events)
+	require.NoError(t, err)
+	require.NoError(t, ei.Close())
+
+	pending, err = Pending(ctx, r)
+	require.NoError(t, err)
+	require.Empty(t, pending)
+
+	// restoring the backup reverts the migration
+	require.NoError(t, Restore(r, res.Backup))
+	version, _, err = sqliteVersion(ctx, r.Events)
+	require.NoError(t, err)
+	require.Equal(t, 1, version)
+}
+
+func TestMigrateRollback(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	r := Repo{
+		Metadata:  filepath.Join(dir, "metadata"),
+		Events:    filepath.Join(dir, "events.db"),
+		BackupDir: filepath.Join(dir, "backups"),
+	}
+	createEventsV1(t, r.Events)
+
+	ds, err := repo.OpenMetadataDatastore(r.Metadata, false)
+	require.NoError(t, err)
+	require.NoError(t, ds.Put(ctx, datastore.NewKey("/a"), []byte("old")))
+	require.NoError(t, ds.Close())
+
+	orig := migrations
+	defer func() { migrations = orig }()
+
+	// the metadata store is migrated first, then the events migration fails
+	migrations = []Migration{
+		{
+			Store:   Metadata,
+			Version: 1,
+			Datastore: func(ctx context.Context, ds datastore.Read, b datastore.Batch) error {
+				return b.Put(ctx, datastore.NewKey("/a"), []byte("new"))
+			},
+		},
+		{
+			Store:   Events,
+			Version: 2,
+			SQL: func(ctx context.Context, tx *sql.Tx) error {
+				if _, err := tx.ExecContext(ctx, `CREATE INDEX event_height ON event (height)`); err != nil {
+					return err
+				}
+				return xerrors.Errorf("failed")
+			},
+		},
+	}
+
+	_, err = Run(ctx, r, false)
+	require.ErrorContains(t, err, "failed")
+
+	sts, err := Statuses(ctx, r)
+	require.NoError(t, err)
+	require.Equal(t, 0, sts[0].Version)
+	require.Equal(t, 1, sts[1].Version)
+
+	ds, err = repo.OpenMetadataDatastore(r.Metadata, true)
+	require.NoError(t, err)
+	v, err := ds.Get(ctx, datastore.NewKey("/a"))
+	require.NoError(t, err)
+	require.Equal(t, "old", string(v))
+	require.NoError(t, ds.Close())
+
+	// without the failing migration the datastore is migrated
+	migrations = migrations[:1]
+	res, err := Run(ctx, r, false)
+	require.NoError(t, err)
+	require.Len(t, res.Applied, 1)
+
+	sts, err = Statuses(ctx, r)
+	require.NoError(t, err)
+	require.Equal(t, 1, sts[0].Version)
+}
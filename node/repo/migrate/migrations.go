@@ -0,0 +1,20 @@
+package migrate
+
+import (
+	"context"
+	"database/sql"
+)
+
+// migrations upgrading the stores of the repo. New migrations are appended with the next
+// version of their store, and the schema created for new stores must be kept in sync.
+var migrations = []Migration{
+	{
+		Store:       Events,
+		Version:     2,
+		Description: "index events by tipset key cid",
+		SQL: func(ctx context.Context, tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `CREATE INDEX IF NOT EXISTS event_tipset_key_cid ON event (tipset_key_cid)`)
+			return err
+		},
+	},
+}
@@ -29,8 +29,10 @@ import (
 	"github.com/filecoin-project/lotus/api/v1api"
 	bstore "github.com/filecoin-project/lotus/blockstore"
 	"github.com/filecoin-project/lotus/lib/rpcenc"
+	"github.com/filecoin-project/lotus/lib/rpcreplay"
 	"github.com/filecoin-project/lotus/metrics"
 	"github.com/filecoin-project/lotus/metrics/proxy"
+	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/impl"
 	"github.com/filecoin-project/lotus/node/impl/client"
 )
@@ -72,8 +74,30 @@ func ServeRPC(h http.Handler, id string, addr multiaddr.Multiaddr) (StopFunc, er
 
 // FullNodeHandler returns a full node handler, to be mounted as-is on the server.
 func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
+	return fullNodeHandler(a, permissioned, false, opts...)
+}
+
+// ReadOnlyFullNodeHandler returns a full node handler which only serves methods
+// that don't change node state, regardless of the permissions of the caller.
+// Wallet signing, message pushing and admin methods are all disabled.
+func ReadOnlyFullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
+	return fullNodeHandler(a, permissioned, true, opts...)
+}
+
+func fullNodeHandler(a v1api.FullNode, permissioned, readOnly bool, opts ...jsonrpc.ServerOption) (http.Handler, error) {
 	m := mux.NewRouter()
 
+	var limits *config.RPCExecutionLimits
+	var slowLog *config.SlowCallLogConfig
+	var recorder *rpcreplay.Recorder
+	var chainData *config.ChainDataRESTConfig
+	if fna, ok := a.(*impl.FullNodeAPI); ok {
+		limits = fna.ExecutionLimits
+		slowLog = fna.SlowCallLog
+		recorder = fna.RPCRecorder
+		chainData = fna.ChainDataREST
+	}
+
 	serveRpc := func(path string, hnd interface{}) {
 		rpcServer := jsonrpc.NewServer(append(opts, jsonrpc.WithReverseClient[api.EthSubscriberMethods]("Filecoin"), jsonrpc.WithServerErrors(api.RPCErrors))...)
 		rpcServer.Register("Filecoin", hnd)
@@ -81,27 +105,53 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 
 		api.CreateEthRPCAliases(rpcServer)
 
-		var handler http.Handler = rpcServer
+		var handler = newCborNegotiator(hnd, rpcServer)
+		handler = executionLimitsHandler(limits, handler)
+		handler = api.EthErrorsHandler(handler)
 		if permissioned {
-			handler = &auth.Handler{Verify: a.AuthVerify, Next: rpcServer.ServeHTTP}
+			handler = &auth.Handler{Verify: a.AuthVerify, Next: handler.ServeHTTP}
+		}
+		if recorder != nil {
+			handler = recorder.Handler(handler)
 		}
+		handler = slowCallHandler(slowLog, handler)
 
 		m.Handle(path, handler)
 	}
 
-	fnapi := proxy.MetricedFullAPI(a)
+	fnapi := api.StructuredErrorsFullAPI(proxy.MetricedFullAPI(a))
 	if permissioned {
 		fnapi = api.PermissionedFullAPI(fnapi)
 	}
+	if readOnly {
+		fnapi = api.ReadOnlyFullAPI(fnapi)
+	}
 
-	var v0 v0api.FullNode = &(struct{ v0api.FullNode }{&v0api.WrapperV1Full{FullNode: fnapi}})
+	var v0 v0api.FullNode = &(struct{ v0api.FullNode }{v0api.StructuredErrorsFullAPI(&v0api.WrapperV1Full{FullNode: fnapi})})
 	serveRpc("/rpc/v1", fnapi)
 	serveRpc("/rpc/v0", v0)
 
+	// Subscription streams
+	chainNotifyStream := handleStream(permissioned, fnapi.ChainNotify)
+	mpoolSubStream := handleStream(permissioned, fnapi.MpoolSub)
+	if permissioned {
+		m.Handle("/rpc/streams/v1/chain-notify", &auth.Handler{Verify: a.AuthVerify, Next: chainNotifyStream})
+		m.Handle("/rpc/streams/v1/mpool-sub", &auth.Handler{Verify: a.AuthVerify, Next: mpoolSubStream})
+	} else {
+		m.HandleFunc("/rpc/streams/v1/chain-notify", chainNotifyStream)
+		m.HandleFunc("/rpc/streams/v1/mpool-sub", mpoolSubStream)
+	}
+
 	// Import handler
 	handleImportFunc := handleImport(a.(*impl.FullNodeAPI))
 	handleExportFunc := handleExport(a.(*impl.FullNodeAPI))
 	handleRemoteStoreFunc := handleRemoteStore(a.(*impl.FullNodeAPI))
+	if readOnly {
+		// all REST endpoints require write permission
+		handleImportFunc = handleReadOnly
+		handleExportFunc = handleReadOnly
+		handleRemoteStoreFunc = handleReadOnly
+	}
 	if permissioned {
 		importAH := &auth.Handler{
 			Verify: a.AuthVerify,
@@ -125,6 +175,11 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 		m.HandleFunc("/rest/v0/store/{uuid}", handleRemoteStoreFunc)
 	}
 
+	// Public chain data, read without permissions
+	if chainData != nil && chainData.Enable {
+		registerChainDataREST(m, chainData, a)
+	}
+
 	// debugging
 	m.Handle("/debug/metrics", metrics.Exporter())
 	m.Handle("/debug/pprof-set/block", handleFractionOpt("BlockProfileRate", runtime.SetBlockProfileRate))
@@ -132,7 +187,8 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 		runtime.SetMutexProfileFraction(x)
 	}))
 	m.Handle("/health/livez", NewLiveHandler(a))
-	m.Handle("/health/readyz", NewReadyHandler(a))
+	m.Handle("/health/readyz", NewNodeHealthHandler(a))
+	m.Handle("/health/activez", NewActiveHandler(a))
 	m.PathPrefix("/").Handler(http.DefaultServeMux) // pprof
 
 	return m, nil
@@ -140,7 +196,7 @@ func FullNodeHandler(a v1api.FullNode, permissioned bool, opts ...jsonrpc.Server
 
 // MinerHandler returns a miner handler, to be mounted as-is on the server.
 func MinerHandler(a api.StorageMiner, permissioned bool) (http.Handler, error) {
-	mapi := proxy.MetricedStorMinerAPI(a)
+	mapi := api.StructuredErrorsStorMinerAPI(proxy.MetricedStorMinerAPI(a))
 	if permissioned {
 		mapi = api.PermissionedStorMinerAPI(mapi)
 	}
@@ -191,6 +247,11 @@ func MinerHandler(a api.StorageMiner, permissioned bool) (http.Handler, error) {
 	return rootMux, nil
 }
 
+func handleReadOnly(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(403)
+	_ = json.NewEncoder(w).Encode(struct{ Error string }{(&api.ErrReadOnly{}).Error()})
+}
+
 func handleImport(a *impl.FullNodeAPI) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != "PUT" {
@@ -0,0 +1,257 @@
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+	cbg "github.com/whyrusleeping/cbor-gen"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// DAG-CBOR response encoding for the JSON-RPC API.
+//
+// Clients which send a single JSON-RPC request with an
+// `Accept: application/vnd.ipld.dag-cbor` (or `application/cbor`) header get
+// the DAG-CBOR encoding of the call result as the response body, instead of a
+// JSON-RPC response object. This only applies to read methods returning
+// values with a native CBOR encoding (tipsets, block headers, messages,
+// actors, sector infos, raw objects, CIDs and lists of those). Everything
+// else, including errors, gets the regular JSON-RPC response, so clients
+// should check the response Content-Type. Calls are only executed once: when
+// a call fails, its error is returned in a JSON-RPC response.
+
+const (
+	mimeDagCbor = "application/vnd.ipld.dag-cbor"
+	mimeCbor    = "application/cbor"
+
+	// requests larger than this are never negotiated, and are handed over
+	// to the JSON-RPC server untouched
+	maxCborNegotiatedRequest = 1 << 20
+)
+
+var (
+	cborMarshalerType = reflect.TypeOf((*cbg.CBORMarshaler)(nil)).Elem()
+	contextType       = reflect.TypeOf((*context.Context)(nil)).Elem()
+	cidType           = reflect.TypeOf(cid.Undef)
+	bytesType         = reflect.TypeOf([]byte(nil))
+)
+
+// cborReadMethods are the full node methods which only need read permission,
+// so the negotiator doesn't run calls to methods changing node state.
+var cborReadMethods = func() map[string]bool {
+	out := map[string]bool{}
+	mt := reflect.TypeOf(api.FullNodeMethods{})
+	for i := 0; i < mt.NumField(); i++ {
+		if mt.Field(i).Tag.Get("perm") == string(api.PermRead) {
+			out[mt.Field(i).Name] = true
+		}
+	}
+	return out
+}()
+
+type cborErrKey struct{}
+
+// cborCallError returns the error of a call executed by the negotiator, for it
+// to be encoded by a JSON-RPC server, with the same codes as the API errors.
+type cborCallError struct{}
+
+func (cborCallError) Fail(ctx context.Context) error {
+	return ctx.Value(cborErrKey{}).(error)
+}
+
+var cborErrorServer = func() *jsonrpc.RPCServer {
+	s := jsonrpc.NewServer(jsonrpc.WithServerErrors(api.RPCErrors))
+	s.Register("Filecoin", cborCallError{})
+	return s
+}()
+
+type cborNegotiator struct {
+	api  reflect.Value
+	next http.Handler
+}
+
+// newCborNegotiator serves calls to methods of hnd as DAG-CBOR when the client
+// asks for it, and passes all other requests to next.
+func newCborNegotiator(hnd interface{}, next http.Handler) http.Handler {
+	return &cborNegotiator{api: reflect.ValueOf(hnd), next: next}
+}
+
+func acceptedCborType(r *http.Request) string {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mt, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		if mt == mimeDagCbor || mt == mimeCbor {
+			return mt
+		}
+	}
+	return ""
+}
+
+func (h *cborNegotiator) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	ct := acceptedCborType(r)
+	if ct == "" || r.Method != http.MethodPost {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxCborNegotiatedRequest+1))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// whatever happens, the JSON-RPC server can still read the whole request
+	rest := r.Body
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(body), rest))
+	if len(body) > maxCborNegotiatedRequest {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	id, out, ok := h.call(r.Context(), body)
+	if !ok {
+		h.next.ServeHTTP(w, r)
+		return
+	}
+
+	var buf bytes.Buffer
+	err, _ = out[1].Interface().(error)
+	if err == nil {
+		err = writeCborValue(&buf, out[0])
+		if err != nil {
+			err = xerrors.Errorf("encoding cbor response: %w", err)
+		}
+	}
+	if err != nil {
+		writeCborCallError(w, r, id, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", ct)
+	w.WriteHeader(200)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		rpclog.Debugf("writing cbor response failed: %s", err)
+	}
+}
+
+// writeCborCallError sends the JSON-RPC response of a failed call, as the
+// JSON-RPC server would have, without executing the call again.
+func writeCborCallError(w http.ResponseWriter, r *http.Request, id json.RawMessage, err error) {
+	req, merr := json.Marshal(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"id":      id,
+		"method":  "Filecoin.Fail",
+		"params":  []interface{}{},
+	})
+	if merr != nil {
+		http.Error(w, merr.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	r = r.WithContext(context.WithValue(r.Context(), cborErrKey{}, err))
+	r.Body = io.NopCloser(bytes.NewReader(req))
+	r.ContentLength = int64(len(req))
+	cborErrorServer.ServeHTTP(w, r)
+}
+
+// call executes the JSON-RPC request in body, if its result can be returned as
+// CBOR, and returns the request id with the result and error of the call. It
+// returns false when the request must be handled by the JSON-RPC server
+// instead, in which case the call wasn't executed.
+func (h *cborNegotiator) call(ctx context.Context, body []byte) (json.RawMessage, []reflect.Value, bool) {
+	var req struct {
+		ID     json.RawMessage
+		Method string
+		Params []json.RawMessage
+	}
+	if err := json.Unmarshal(body, &req); err != nil {
+		// batches, malformed requests
+		return nil, nil, false
+	}
+
+	name := strings.TrimPrefix(req.Method, "Filecoin.")
+	if name == req.Method || !cborReadMethods[name] {
+		return nil, nil, false
+	}
+
+	m := h.api.MethodByName(name)
+	if !m.IsValid() {
+		return nil, nil, false
+	}
+	mt := m.Type()
+	if mt.IsVariadic() || mt.NumIn() != len(req.Params)+1 || mt.In(0) != contextType || mt.NumOut() != 2 || !cborEncodable(mt.Out(0)) {
+		return nil, nil, false
+	}
+
+	args := []reflect.Value{reflect.ValueOf(ctx)}
+	for i, p := range req.Params {
+		arg := reflect.New(mt.In(i + 1))
+		if err := json.Unmarshal(p, arg.Interface()); err != nil {
+			return nil, nil, false
+		}
+		args = append(args, arg.Elem())
+	}
+
+	return req.ID, m.Call(args), true
+}
+
+func cborEncodable(t reflect.Type) bool {
+	switch {
+	case t.Implements(cborMarshalerType), t == cidType, t == bytesType:
+		return true
+	case t.Kind() == reflect.Slice:
+		return cborEncodable(t.Elem())
+	default:
+		return false
+	}
+}
+
+func writeCborValue(w io.Writer, v reflect.Value) error {
+	cw := cbg.NewCborWriter(w)
+
+	switch t := v.Type(); {
+	case t.Implements(cborMarshalerType):
+		if (v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface) && v.IsNil() {
+			_, err := cw.Write(cbg.CborNull)
+			return err
+		}
+		return v.Interface().(cbg.CBORMarshaler).MarshalCBOR(cw)
+	case t == cidType:
+		return cbg.WriteCid(cw, v.Interface().(cid.Cid))
+	case t == bytesType:
+		b := v.Bytes()
+		if err := cw.WriteMajorTypeHeader(cbg.MajByteString, uint64(len(b))); err != nil {
+			return err
+		}
+		_, err := cw.Write(b)
+		return err
+	case t.Kind() == reflect.Slice:
+		if v.IsNil() {
+			_, err := cw.Write(cbg.CborNull)
+			return err
+		}
+		if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(v.Len())); err != nil {
+			return err
+		}
+		for i := 0; i < v.Len(); i++ {
+			if err := writeCborValue(cw, v.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return xerrors.Errorf("type %s has no cbor encoding", t)
+	}
+}
@@ -0,0 +1,115 @@
+// stm: #unit
+package node
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+	cbg "github.com/whyrusleeping/cbor-gen"
+
+	"github.com/filecoin-project/go-jsonrpc"
+
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+)
+
+type cborTestAPI struct {
+	blk   *types.BlockHeader
+	calls int
+}
+
+func (a *cborTestAPI) ChainGetBlock(ctx context.Context, c cid.Cid) (*types.BlockHeader, error) {
+	a.calls++
+	if c != a.blk.Cid() {
+		return nil, errors.New("block not found")
+	}
+	return a.blk, nil
+}
+
+func (a *cborTestAPI) ChainGetParentReceipts(ctx context.Context, c cid.Cid) ([]*types.MessageReceipt, error) {
+	return []*types.MessageReceipt{{GasUsed: 1}, {GasUsed: 2}}, nil
+}
+
+func (a *cborTestAPI) StateNetworkName(ctx context.Context) (string, error) {
+	return "testnet", nil
+}
+
+func TestCborNegotiation(t *testing.T) {
+	a := &cborTestAPI{blk: mock.MkBlock(nil, 1, 1)}
+
+	rpcServer := jsonrpc.NewServer()
+	rpcServer.Register("Filecoin", a)
+	srv := httptest.NewServer(newCborNegotiator(a, rpcServer))
+	defer srv.Close()
+
+	call := func(accept, method string, params ...interface{}) (string, []byte) {
+		req, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method, "params": params})
+		require.NoError(t, err)
+		hreq, err := http.NewRequest(http.MethodPost, srv.URL, bytes.NewReader(req))
+		require.NoError(t, err)
+		if accept != "" {
+			hreq.Header.Set("Accept", accept)
+		}
+		resp, err := http.DefaultClient.Do(hreq)
+		require.NoError(t, err)
+		defer resp.Body.Close() //nolint:errcheck
+		require.Equal(t, 200, resp.StatusCode)
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.Header.Get("Content-Type"), body
+	}
+
+	// CBOR when asked for
+	ct, body := call("application/vnd.ipld.dag-cbor", "Filecoin.ChainGetBlock", a.blk.Cid())
+	require.Equal(t, mimeDagCbor, ct)
+	var blk types.BlockHeader
+	require.NoError(t, blk.UnmarshalCBOR(bytes.NewReader(body)))
+	require.Equal(t, a.blk.Cid(), blk.Cid())
+
+	// lists
+	ct, body = call("application/json, application/cbor;q=0.9", "Filecoin.ChainGetParentReceipts", a.blk.Cid())
+	require.Equal(t, mimeCbor, ct)
+	cr := cbg.NewCborReader(bytes.NewReader(body))
+	maj, n, err := cr.ReadHeader()
+	require.NoError(t, err)
+	require.EqualValues(t, cbg.MajArray, maj)
+	require.EqualValues(t, 2, n)
+	for i := 0; i < 2; i++ {
+		var rcpt types.MessageReceipt
+		require.NoError(t, rcpt.UnmarshalCBOR(cr))
+		require.EqualValues(t, i+1, rcpt.GasUsed)
+	}
+
+	// JSON otherwise
+	ct, body = call("", "Filecoin.ChainGetBlock", a.blk.Cid())
+	require.NotEqual(t, mimeDagCbor, ct)
+	var jres struct{ Result types.BlockHeader }
+	require.NoError(t, json.Unmarshal(body, &jres))
+	require.Equal(t, a.blk.Cid(), jres.Result.Cid())
+
+	// results without a cbor encoding, and errors, are JSON-RPC responses
+	ct, body = call(mimeDagCbor, "Filecoin.StateNetworkName")
+	require.NotEqual(t, mimeDagCbor, ct)
+	require.Contains(t, string(body), "testnet")
+
+	// failed calls aren't executed again
+	a.calls = 0
+	ct, body = call(mimeDagCbor, "Filecoin.ChainGetBlock", cid.NewCidV1(cid.Raw, a.blk.Cid().Hash()))
+	require.NotEqual(t, mimeDagCbor, ct)
+	var eres struct {
+		ID    int
+		Error struct{ Message string }
+	}
+	require.NoError(t, json.Unmarshal(body, &eres))
+	require.Equal(t, 1, eres.ID)
+	require.Equal(t, "block not found", eres.Error.Message)
+	require.Equal(t, 1, a.calls)
+}
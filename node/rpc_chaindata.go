@@ -0,0 +1,248 @@
+package node
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+var restlog = logging.Logger("rest")
+
+// chainDataAPI is the part of the full node API read by the chain data REST
+// endpoints.
+type chainDataAPI interface {
+	ChainHead(context.Context) (*types.TipSet, error)
+	ChainGetTipSetByHeight(context.Context, abi.ChainEpoch, types.TipSetKey) (*types.TipSet, error)
+	ChainGetMessage(context.Context, cid.Cid) (*types.Message, error)
+	StateGetActor(context.Context, address.Address, types.TipSetKey) (*types.Actor, error)
+	EthGetTransactionReceipt(context.Context, ethtypes.EthHash) (*api.EthTxReceipt, error)
+}
+
+// restBalance is the balance of an address at a tipset.
+type restBalance struct {
+	Address address.Address
+	TipSet  types.TipSetKey
+	Height  abi.ChainEpoch
+	Balance types.BigInt
+}
+
+// restError is an error with the status code it's served with.
+type restError struct {
+	status int
+	err    error
+}
+
+func (e *restError) Error() string { return e.err.Error() }
+
+func restErrorf(status int, format string, args ...interface{}) error {
+	return &restError{status: status, err: xerrors.Errorf(format, args...)}
+}
+
+// restFunc returns the value served as JSON, and whether it can't change
+// anymore.
+type restFunc func(ctx context.Context, vars map[string]string, query map[string][]string) (interface{}, bool, error)
+
+// registerChainDataREST serves read-only chain data over plain GET requests,
+// for integrations which can't use JSON-RPC clients. Responses carry an ETag
+// of their body and are cacheable for FinalizedMaxAge when the data is past
+// finality, and for MaxAge otherwise.
+func registerChainDataREST(m *mux.Router, cfg *config.ChainDataRESTConfig, a chainDataAPI) {
+	h := &chainDataHandler{cfg: cfg, api: a}
+
+	serve := func(path string, f restFunc) {
+		m.Handle(path, h.wrap(f)).Methods(http.MethodGet, http.MethodHead)
+	}
+	serve("/rest/v0/chain/tipset/{height}", h.tipSetByHeight)
+	serve("/rest/v0/chain/message/{cid}", h.message)
+	serve("/rest/v0/chain/balance/{address}", h.balance)
+	serve("/rest/v0/chain/receipt/{hash}", h.receipt)
+}
+
+type chainDataHandler struct {
+	cfg *config.ChainDataRESTConfig
+	api chainDataAPI
+}
+
+func (h *chainDataHandler) wrap(f restFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), 30*time.Second)
+		defer cancel()
+
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+
+		v, final, err := f(ctx, mux.Vars(r), r.URL.Query())
+		if err != nil {
+			status := http.StatusInternalServerError
+			var rerr *restError
+			if xerrors.As(err, &rerr) {
+				status = rerr.status
+			} else if ipld.IsNotFound(err) {
+				status = http.StatusNotFound
+			}
+			if status == http.StatusInternalServerError {
+				restlog.Warnw("serving chain data failed", "path", r.URL.Path, "error", err)
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+
+		body, err := json.Marshal(v)
+		if err != nil {
+			restlog.Warnw("encoding chain data failed", "path", r.URL.Path, "error", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		sum := sha256.Sum256(body)
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		maxAge := time.Duration(h.cfg.MaxAge)
+		if final {
+			maxAge = time.Duration(h.cfg.FinalizedMaxAge)
+		}
+
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int64(maxAge.Seconds())))
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	})
+}
+
+// etagMatches checks whether an If-None-Match header lists the ETag.
+func etagMatches(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// finalAt returns whether data at the given height is past finality.
+func (h *chainDataHandler) finalAt(ctx context.Context, height abi.ChainEpoch) (bool, error) {
+	head, err := h.api.ChainHead(ctx)
+	if err != nil {
+		return false, xerrors.Errorf("getting chain head: %w", err)
+	}
+	return head.Height()-height > build.Finality, nil
+}
+
+// tipSetByHeight serves the tipset at a height, or the last one before it
+// when the height is a null round.
+func (h *chainDataHandler) tipSetByHeight(ctx context.Context, vars map[string]string, _ map[string][]string) (interface{}, bool, error) {
+	height, err := strconv.ParseInt(vars["height"], 10, 64)
+	if err != nil || height < 0 {
+		return nil, false, restErrorf(http.StatusBadRequest, "invalid height %q", vars["height"])
+	}
+
+	ts, err := h.api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(height), types.EmptyTSK)
+	if err != nil {
+		return nil, false, restErrorf(http.StatusNotFound, "getting tipset at height %d: %w", height, err)
+	}
+	final, err := h.finalAt(ctx, abi.ChainEpoch(height))
+	if err != nil {
+		return nil, false, err
+	}
+	return ts, final, nil
+}
+
+// message serves a message, which never changes for a CID.
+func (h *chainDataHandler) message(ctx context.Context, vars map[string]string, _ map[string][]string) (interface{}, bool, error) {
+	c, err := cid.Decode(vars["cid"])
+	if err != nil {
+		return nil, false, restErrorf(http.StatusBadRequest, "invalid message cid %q: %w", vars["cid"], err)
+	}
+
+	msg, err := h.api.ChainGetMessage(ctx, c)
+	if err != nil {
+		return nil, false, xerrors.Errorf("getting message %s: %w", c, err)
+	}
+	return msg, true, nil
+}
+
+// balance serves the balance of an address at the head, or at the tipset at
+// the height given with the height query parameter.
+func (h *chainDataHandler) balance(ctx context.Context, vars map[string]string, query map[string][]string) (interface{}, bool, error) {
+	addr, err := address.NewFromString(vars["address"])
+	if err != nil {
+		return nil, false, restErrorf(http.StatusBadRequest, "invalid address %q: %w", vars["address"], err)
+	}
+
+	ts, err := h.api.ChainHead(ctx)
+	if err != nil {
+		return nil, false, xerrors.Errorf("getting chain head: %w", err)
+	}
+	if hs, ok := query["height"]; ok && len(hs) > 0 {
+		height, err := strconv.ParseInt(hs[0], 10, 64)
+		if err != nil || height < 0 || abi.ChainEpoch(height) > ts.Height() {
+			return nil, false, restErrorf(http.StatusBadRequest, "invalid height %q", hs[0])
+		}
+		ts, err = h.api.ChainGetTipSetByHeight(ctx, abi.ChainEpoch(height), ts.Key())
+		if err != nil {
+			return nil, false, xerrors.Errorf("getting tipset at height %d: %w", height, err)
+		}
+	}
+
+	bal := restBalance{Address: addr, TipSet: ts.Key(), Height: ts.Height(), Balance: types.NewInt(0)}
+	act, err := h.api.StateGetActor(ctx, addr, ts.Key())
+	if err != nil && !xerrors.Is(err, types.ErrActorNotFound) {
+		return nil, false, xerrors.Errorf("getting actor %s: %w", addr, err)
+	}
+	if act != nil {
+		bal.Balance = act.Balance
+	}
+
+	final, err := h.finalAt(ctx, ts.Height())
+	if err != nil {
+		return nil, false, err
+	}
+	return bal, final, nil
+}
+
+// receipt serves the Ethereum receipt of a transaction.
+func (h *chainDataHandler) receipt(ctx context.Context, vars map[string]string, _ map[string][]string) (interface{}, bool, error) {
+	hash, err := ethtypes.ParseEthHash(vars["hash"])
+	if err != nil {
+		return nil, false, restErrorf(http.StatusBadRequest, "invalid transaction hash %q: %w", vars["hash"], err)
+	}
+
+	rct, err := h.api.EthGetTransactionReceipt(ctx, hash)
+	if err != nil {
+		return nil, false, xerrors.Errorf("getting receipt of %s: %w", hash, err)
+	}
+	if rct == nil {
+		return nil, false, restErrorf(http.StatusNotFound, "no receipt for transaction %s", hash)
+	}
+
+	final, err := h.finalAt(ctx, abi.ChainEpoch(rct.BlockNumber))
+	if err != nil {
+		return nil, false, err
+	}
+	return rct, final, nil
+}
@@ -0,0 +1,142 @@
+// stm: #unit
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/ipfs/go-cid"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/ethtypes"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+type testChainDataAPI struct {
+	head *types.TipSet
+	msg  *types.Message
+}
+
+func (a *testChainDataAPI) ChainHead(context.Context) (*types.TipSet, error) {
+	return a.head, nil
+}
+
+func (a *testChainDataAPI) ChainGetTipSetByHeight(_ context.Context, h abi.ChainEpoch, _ types.TipSetKey) (*types.TipSet, error) {
+	if h > a.head.Height() {
+		return nil, xerrors.Errorf("looking for tipset with height greater than start point")
+	}
+	blk := mock.MkBlock(nil, 1, 1)
+	blk.Height = h
+	return mock.TipSet(blk), nil
+}
+
+func (a *testChainDataAPI) ChainGetMessage(_ context.Context, c cid.Cid) (*types.Message, error) {
+	if c != a.msg.Cid() {
+		return nil, ipld.ErrNotFound{Cid: c}
+	}
+	return a.msg, nil
+}
+
+func (a *testChainDataAPI) StateGetActor(_ context.Context, addr address.Address, _ types.TipSetKey) (*types.Actor, error) {
+	if addr != a.msg.From {
+		return nil, xerrors.Errorf("loading actor: %w", types.ErrActorNotFound)
+	}
+	return &types.Actor{Balance: big.NewInt(42)}, nil
+}
+
+func (a *testChainDataAPI) EthGetTransactionReceipt(context.Context, ethtypes.EthHash) (*api.EthTxReceipt, error) {
+	return nil, nil
+}
+
+func TestChainDataREST(t *testing.T) {
+	head := mock.MkBlock(nil, 1, 1)
+	head.Height = 2000
+	a := &testChainDataAPI{
+		head: mock.TipSet(head),
+		msg:  &types.Message{From: mock.Address(1000), To: mock.Address(1001), Value: big.NewInt(1)},
+	}
+
+	m := mux.NewRouter()
+	registerChainDataREST(m, &config.ChainDataRESTConfig{
+		Enable:          true,
+		MaxAge:          config.Duration(30 * time.Second),
+		FinalizedMaxAge: config.Duration(time.Hour),
+	}, a)
+
+	get := func(path string, hdr http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("GET", path, nil)
+		for k, v := range hdr {
+			r.Header[k] = v
+		}
+		w := httptest.NewRecorder()
+		m.ServeHTTP(w, r)
+		return w
+	}
+
+	// messages never change
+	w := get("/rest/v0/chain/message/"+a.msg.Cid().String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	var msg types.Message
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &msg))
+	require.Equal(t, a.msg.Cid(), msg.Cid())
+
+	etag := w.Header().Get("ETag")
+	require.NotEmpty(t, etag)
+	w = get("/rest/v0/chain/message/"+a.msg.Cid().String(), http.Header{"If-None-Match": {`"other", ` + etag}})
+	require.Equal(t, http.StatusNotModified, w.Code)
+	require.Empty(t, w.Body.Bytes())
+
+	require.Equal(t, http.StatusNotFound, get("/rest/v0/chain/message/"+head.Cid().String(), nil).Code)
+	require.Equal(t, http.StatusBadRequest, get("/rest/v0/chain/message/nope", nil).Code)
+
+	// tipsets past finality are cached longer
+	w = get("/rest/v0/chain/tipset/100", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	w = get("/rest/v0/chain/tipset/"+(head.Height-build.Finality).String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=30", w.Header().Get("Cache-Control"))
+	require.Equal(t, http.StatusNotFound, get("/rest/v0/chain/tipset/3000", nil).Code)
+	require.Equal(t, http.StatusBadRequest, get("/rest/v0/chain/tipset/-1", nil).Code)
+
+	// balances
+	w = get("/rest/v0/chain/balance/"+a.msg.From.String(), nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	var bal restBalance
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bal))
+	require.Equal(t, abi.ChainEpoch(2000), bal.Height)
+	require.Equal(t, big.NewInt(42), bal.Balance)
+
+	w = get("/rest/v0/chain/balance/"+a.msg.To.String()+"?height=10", nil)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "public, max-age=3600", w.Header().Get("Cache-Control"))
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bal))
+	require.Equal(t, abi.ChainEpoch(10), bal.Height)
+	require.True(t, bal.Balance.IsZero())
+	require.Equal(t, http.StatusBadRequest, get("/rest/v0/chain/balance/"+a.msg.To.String()+"?height=3000", nil).Code)
+
+	// receipts
+	require.Equal(t, http.StatusNotFound, get("/rest/v0/chain/receipt/"+ethtypes.EthHash{}.String(), nil).Code)
+
+	// only reads are served
+	r := httptest.NewRequest("POST", "/rest/v0/chain/tipset/100", nil)
+	w = httptest.NewRecorder()
+	m.ServeHTTP(w, r)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
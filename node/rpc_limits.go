@@ -0,0 +1,63 @@
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+// executionLimitsHandler applies the configured execution limits to the
+// explicit calls (eth_call, eth_estimateGas, StateCall) made by requests,
+// using the override matching the API token of the request, if any.
+func executionLimitsHandler(cfg *config.RPCExecutionLimits, next http.Handler) http.Handler {
+	if cfg == nil {
+		return next
+	}
+
+	defaults := stmgr.ExecutionLimits{
+		Timeout:      time.Duration(cfg.Timeout),
+		MaxGas:       cfg.MaxGas,
+		MaxMemory:    cfg.MaxMemory,
+		MaxIpldReads: cfg.MaxIpldReads,
+		MaxCallDepth: cfg.MaxCallDepth,
+	}
+	overrides := map[string]stmgr.ExecutionLimits{}
+	for _, o := range cfg.TokenOverrides {
+		overrides[strings.ToLower(o.TokenHash)] = stmgr.ExecutionLimits{
+			Timeout:      time.Duration(o.Timeout),
+			MaxGas:       o.MaxGas,
+			MaxMemory:    o.MaxMemory,
+			MaxIpldReads: o.MaxIpldReads,
+			MaxCallDepth: o.MaxCallDepth,
+		}
+	}
+	if defaults.IsZero() && len(overrides) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		limits := defaults
+		if token := requestToken(r); token != "" {
+			h := sha256.Sum256([]byte(token))
+			if o, ok := overrides[hex.EncodeToString(h[:])]; ok {
+				limits = o
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(stmgr.WithExecutionLimits(r.Context(), limits)))
+	})
+}
+
+// requestToken returns the API token of the request, the same way the auth
+// handler finds it.
+func requestToken(r *http.Request) string {
+	if token := r.Header.Get("Authorization"); token != "" {
+		return strings.TrimPrefix(token, "Bearer ")
+	}
+	return r.URL.Query().Get("token")
+}
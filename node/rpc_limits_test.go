@@ -0,0 +1,61 @@
+// stm: #unit
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/chain/stmgr"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func TestExecutionLimitsHandler(t *testing.T) {
+	trusted := sha256.Sum256([]byte("trusted-token"))
+	cfg := &config.RPCExecutionLimits{
+		Timeout:      config.Duration(time.Second),
+		MaxGas:       1000,
+		MaxIpldReads: 100000,
+		MaxCallDepth: 64,
+		TokenOverrides: []config.RPCExecutionLimitsOverride{{
+			TokenHash: hex.EncodeToString(trusted[:]),
+			MaxGas:    5000,
+		}},
+	}
+
+	var got stmgr.ExecutionLimits
+	h := executionLimitsHandler(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = stmgr.ExecutionLimitsFromContext(r.Context())
+	}))
+
+	serve := func(r *http.Request) stmgr.ExecutionLimits {
+		got = stmgr.ExecutionLimits{}
+		h.ServeHTTP(httptest.NewRecorder(), r)
+		return got
+	}
+
+	defaults := stmgr.ExecutionLimits{Timeout: time.Second, MaxGas: 1000, MaxIpldReads: 100000, MaxCallDepth: 64}
+	override := stmgr.ExecutionLimits{MaxGas: 5000}
+
+	require.Equal(t, defaults, serve(httptest.NewRequest("POST", "/rpc/v1", nil)))
+
+	r := httptest.NewRequest("POST", "/rpc/v1", nil)
+	r.Header.Set("Authorization", "Bearer other-token")
+	require.Equal(t, defaults, serve(r))
+
+	r = httptest.NewRequest("POST", "/rpc/v1", nil)
+	r.Header.Set("Authorization", "Bearer trusted-token")
+	require.Equal(t, override, serve(r))
+
+	require.Equal(t, override, serve(httptest.NewRequest("GET", "/rpc/v1?token=trusted-token", nil)))
+
+	// nothing to apply without limits
+	next := http.NewServeMux()
+	require.Same(t, next, executionLimitsHandler(nil, next))
+	require.Same(t, next, executionLimitsHandler(&config.RPCExecutionLimits{}, next))
+}
@@ -0,0 +1,174 @@
+package node
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+
+	"github.com/filecoin-project/lotus/lib/calltiming"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+var slowlog = logging.Logger("rpc-slow")
+
+// redactedPrefixes are the prefixes of the methods whose params are never
+// logged, as they can hold keys or tokens.
+var redactedPrefixes = []string{"Filecoin.Wallet", "Filecoin.Auth"}
+
+// maxSlowLogRequest is the size of the largest request timed by
+// slowCallHandler, larger requests are passed on without being buffered.
+const maxSlowLogRequest = 10 << 20
+
+// slowCallHandler logs the calls taking longer than the configured threshold.
+// The time of a call is broken down into reading the request, handling it and
+// writing the response, along with the phases recorded with calltiming while
+// handling it.
+func slowCallHandler(cfg *config.SlowCallLogConfig, next http.Handler) http.Handler {
+	if cfg == nil || !cfg.Enable {
+		return next
+	}
+
+	threshold := time.Duration(cfg.Threshold)
+	redacted := map[string]bool{}
+	for _, m := range cfg.RedactMethods {
+		redacted[m] = true
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// websocket connections carry many calls, which can't be timed here
+		if r.Method != http.MethodPost {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		start := time.Now()
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxSlowLogRequest+1))
+		r.Body = readCloser{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		if err != nil || len(body) > maxSlowLogRequest {
+			next.ServeHTTP(w, r)
+			return
+		}
+		read := time.Since(start)
+
+		ctx, rec := calltiming.WithRecorder(r.Context())
+		tw := &timingWriter{ResponseWriter: w}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+
+		took := time.Since(start)
+		if took < threshold {
+			return
+		}
+
+		phases := []calltiming.Phase{{Name: "read-request", Duration: read}}
+		phases = append(phases, rec.Phases()...)
+		if !tw.firstWrite.IsZero() {
+			phases = append(phases,
+				calltiming.Phase{Name: "handle", Duration: tw.firstWrite.Sub(start) - read},
+				calltiming.Phase{Name: "write-response", Duration: time.Since(tw.firstWrite)},
+			)
+		}
+
+		var phaseStrs []string
+		for _, p := range phases {
+			phaseStrs = append(phaseStrs, fmt.Sprintf("%s=%s", p.Name, p.Duration.Round(time.Microsecond)))
+		}
+
+		var token string
+		if t := requestToken(r); t != "" {
+			h := sha256.Sum256([]byte(t))
+			token = hex.EncodeToString(h[:])
+		}
+
+		for _, c := range parseCalls(body) {
+			params := "<redacted>"
+			if !redacted[c.Method] && !hasRedactedPrefix(c.Method) {
+				params = truncateParams(c.Params, cfg.MaxParamsLength)
+			}
+
+			slowlog.Warnw("slow API call",
+				"method", c.Method,
+				"took", took,
+				"params", params,
+				"token", token,
+				"remote", r.RemoteAddr,
+				"phases", strings.Join(phaseStrs, " "))
+		}
+	})
+}
+
+type slowCall struct {
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+}
+
+// parseCalls returns the calls of a request, which can be a batch of calls.
+func parseCalls(body []byte) []slowCall {
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []slowCall
+		if err := json.Unmarshal(body, &calls); err == nil {
+			return calls
+		}
+	} else {
+		var c slowCall
+		if err := json.Unmarshal(body, &c); err == nil {
+			return []slowCall{c}
+		}
+	}
+	return []slowCall{{Method: "<unparseable request>"}}
+}
+
+func hasRedactedPrefix(method string) bool {
+	for _, p := range redactedPrefixes {
+		if strings.HasPrefix(method, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func truncateParams(params json.RawMessage, max int) string {
+	if len(params) > max {
+		return fmt.Sprintf("%s... (%d bytes)", params[:max], len(params))
+	}
+	return string(params)
+}
+
+// timingWriter records when the handler starts writing the response.
+type timingWriter struct {
+	http.ResponseWriter
+	firstWrite time.Time
+}
+
+func (w *timingWriter) WriteHeader(code int) {
+	if w.firstWrite.IsZero() {
+		w.firstWrite = time.Now()
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timingWriter) Write(b []byte) (int, error) {
+	if w.firstWrite.IsZero() {
+		w.firstWrite = time.Now()
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timingWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
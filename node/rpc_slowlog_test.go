@@ -0,0 +1,109 @@
+// stm: #unit
+package node
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	logging "github.com/ipfs/go-log/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/lib/calltiming"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func TestSlowCallHandler(t *testing.T) {
+	cfg := &config.SlowCallLogConfig{
+		Enable:          true,
+		MaxParamsLength: 16,
+	}
+
+	require.NoError(t, logging.SetLogLevel("rpc-slow", "warn"))
+	pipe := logging.NewPipeReader(logging.PipeFormat(logging.JSONOutput))
+	defer pipe.Close() //nolint:errcheck
+	logged := make(chan map[string]interface{}, 1)
+	go func() {
+		dec := json.NewDecoder(pipe)
+		for {
+			var entry map[string]interface{}
+			if err := dec.Decode(&entry); err != nil {
+				return
+			}
+			if entry["logger"] == "rpc-slow" {
+				logged <- entry
+			}
+		}
+	}()
+
+	const body = `{"jsonrpc":"2.0","id":1,"method":"Filecoin.StateCall","params":[{"To":"f01"}]}`
+
+	var gotBody string
+	h := slowCallHandler(cfg, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		gotBody = string(b)
+
+		end := calltiming.Start(r.Context(), "call-execute")
+		time.Sleep(time.Millisecond)
+		end()
+
+		_, _ = w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":null}`))
+	}))
+
+	r := httptest.NewRequest("POST", "/rpc/v1", strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer some-token")
+	h.ServeHTTP(httptest.NewRecorder(), r)
+	require.Equal(t, body, gotBody, "the request body is passed on")
+
+	select {
+	case entry := <-logged:
+		token := sha256.Sum256([]byte("some-token"))
+		require.Equal(t, "Filecoin.StateCall", entry["method"])
+		require.Equal(t, `[{"To":"f01"}]`, entry["params"])
+		require.Equal(t, hex.EncodeToString(token[:]), entry["token"])
+		require.Contains(t, entry["phases"], "read-request=")
+		require.Contains(t, entry["phases"], "call-execute=")
+		require.Contains(t, entry["phases"], "write-response=")
+	case <-time.After(5 * time.Second):
+		t.Fatal("slow call not logged")
+	}
+
+	// larger requests are passed on without being timed
+	big := `{"jsonrpc":"2.0","id":1,"method":"Filecoin.StateCall","params":["` + strings.Repeat("a", maxSlowLogRequest) + `"]}`
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/rpc/v1", strings.NewReader(big)))
+	require.Equal(t, big, gotBody, "the request body is passed on")
+	select {
+	case <-logged:
+		t.Fatal("large call logged")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// nothing to log when disabled
+	next := http.NewServeMux()
+	require.Same(t, next, slowCallHandler(nil, next))
+	require.Same(t, next, slowCallHandler(&config.SlowCallLogConfig{}, next))
+}
+
+func TestSlowCallParams(t *testing.T) {
+	calls := parseCalls([]byte(` [{"method":"Filecoin.ChainHead","params":[]},{"method":"eth_call","params":[{"to":"0x01"},"latest"]}]`))
+	require.Len(t, calls, 2)
+	require.Equal(t, "Filecoin.ChainHead", calls[0].Method)
+	require.Equal(t, "eth_call", calls[1].Method)
+	require.Equal(t, `[{"to":"0x01"},"latest"]`, string(calls[1].Params))
+
+	require.Equal(t, `[{"to":"... (24 bytes)`, truncateParams(calls[1].Params, 8))
+
+	calls = parseCalls([]byte(`not json`))
+	require.Equal(t, "<unparseable request>", calls[0].Method)
+
+	require.True(t, hasRedactedPrefix("Filecoin.WalletSign"))
+	require.True(t, hasRedactedPrefix("Filecoin.AuthNew"))
+	require.False(t, hasRedactedPrefix("Filecoin.StateCall"))
+}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+
+	"github.com/filecoin-project/go-jsonrpc/auth"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// HTTP2Handler serves h over cleartext HTTP/2 (h2c) in addition to HTTP/1.1.
+// Clients can then issue many concurrent JSON-RPC requests, and follow any
+// number of subscription streams, over a single connection without the
+// head-of-line blocking of a websocket. Websocket clients are unaffected.
+func HTTP2Handler(h http.Handler) http.Handler {
+	return h2c.NewHandler(h, &http2.Server{})
+}
+
+// handleStream serves the values of a subscription method as newline-delimited
+// JSON, for as long as the client keeps the request open and the subscription
+// channel isn't closed. This is the streaming equivalent of the websocket
+// channel API for plain HTTP clients; over HTTP/2 every stream is multiplexed
+// over the same connection.
+func handleStream[T any](permissioned bool, sub func(ctx context.Context) (<-chan T, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(404)
+			return
+		}
+		if permissioned && !auth.HasPerm(r.Context(), nil, api.PermRead) {
+			w.WriteHeader(401)
+			_ = json.NewEncoder(w).Encode(struct{ Error string }{"unauthorized: missing read permission"})
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+
+		ch, err := sub(ctx)
+		if err != nil {
+			w.WriteHeader(500)
+			_ = json.NewEncoder(w).Encode(struct{ Error string }{err.Error()})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		w.WriteHeader(200)
+		flusher.Flush()
+
+		enc := json.NewEncoder(w)
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				if err := enc.Encode(v); err != nil {
+					rpclog.Debugf("%s: writing stream response failed: %s", r.URL.Path, err)
+					return
+				}
+				flusher.Flush()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
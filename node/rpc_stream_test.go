@@ -0,0 +1,76 @@
+// stm: #unit
+package node
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/http2"
+)
+
+func TestStreamOverHTTP2(t *testing.T) {
+	sub := func(ctx context.Context) (<-chan int, error) {
+		ch := make(chan int)
+		go func() {
+			defer close(ch)
+			for i := 0; i < 3; i++ {
+				select {
+				case ch <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+		return ch, nil
+	}
+
+	srv := httptest.NewServer(HTTP2Handler(handleStream(false, sub)))
+	defer srv.Close()
+
+	// h2c with prior knowledge
+	client := &http.Client{Transport: &http2.Transport{
+		AllowHTTP: true,
+		DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, addr)
+		},
+	}}
+
+	resp, err := client.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close() //nolint:errcheck
+
+	require.Equal(t, 200, resp.StatusCode)
+	require.Equal(t, 2, resp.ProtoMajor)
+	require.Equal(t, "application/x-ndjson", resp.Header.Get("Content-Type"))
+
+	var got []int
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		var v int
+		require.NoError(t, json.Unmarshal(sc.Bytes(), &v))
+		got = append(got, v)
+	}
+	require.NoError(t, sc.Err())
+	require.Equal(t, []int{0, 1, 2}, got)
+
+	// plain HTTP/1.1 clients are still served
+	resp1, err := http.Get(srv.URL)
+	require.NoError(t, err)
+	defer resp1.Body.Close() //nolint:errcheck
+	require.Equal(t, 1, resp1.ProtoMajor)
+	require.Equal(t, 200, resp1.StatusCode)
+
+	// subscriptions are GET only
+	resp2, err := http.Post(srv.URL, "application/json", nil)
+	require.NoError(t, err)
+	defer resp2.Body.Close() //nolint:errcheck
+	require.Equal(t, 404, resp2.StatusCode)
+}
@@ -23,6 +23,19 @@ type NodeApi interface {
 	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
 }
 
+// NonceApi is implemented by node APIs which can tell how many messages a
+// sender has waiting in the message pool. When the API passed to AddressFor
+// implements it, WindowPoSt senders with too many pending messages are only
+// used when no other sender is available.
+type NonceApi interface {
+	MpoolGetNonce(context.Context, address.Address) (uint64, error)
+	StateGetActor(context.Context, address.Address, types.TipSetKey) (*types.Actor, error)
+}
+
+// MaxPoStSenderPending is the number of pending messages above which a
+// WindowPoSt sender is considered congested.
+const MaxPoStSenderPending = 2
+
 type AddressSelector struct {
 	api.AddressConfig
 }
@@ -44,35 +57,14 @@ func (as *AddressSelector) AddressFor(ctx context.Context, a NodeApi, mi api.Min
 		addrs = append(addrs, as.TerminateControl...)
 	case api.DealPublishAddr:
 		addrs = append(addrs, as.DealPublishControl...)
-	default:
-		defaultCtl := map[address.Address]struct{}{}
-		for _, a := range mi.ControlAddresses {
-			defaultCtl[a] = struct{}{}
-		}
-		delete(defaultCtl, mi.Owner)
-		delete(defaultCtl, mi.Worker)
-
-		configCtl := append([]address.Address{}, as.PreCommitControl...)
-		configCtl = append(configCtl, as.CommitControl...)
-		configCtl = append(configCtl, as.TerminateControl...)
-		configCtl = append(configCtl, as.DealPublishControl...)
-
-		for _, addr := range configCtl {
-			if addr.Protocol() != address.ID {
-				var err error
-				addr, err = a.StateLookupID(ctx, addr, types.EmptyTSK)
-				if err != nil {
-					log.Warnw("looking up control address", "address", addr, "error", err)
-					continue
-				}
-			}
-
-			delete(defaultCtl, addr)
-		}
-
-		for a := range defaultCtl {
-			addrs = append(addrs, a)
+	case api.PoStAddr:
+		if len(as.WindowPoStControl) > 0 {
+			addrs = append(addrs, as.WindowPoStControl...)
+			break
 		}
+		addrs = as.unassignedControl(ctx, a, mi)
+	default:
+		addrs = as.unassignedControl(ctx, a, mi)
 	}
 
 	if len(addrs) == 0 || !as.DisableWorkerFallback {
@@ -82,13 +74,58 @@ func (as *AddressSelector) AddressFor(ctx context.Context, a NodeApi, mi api.Min
 		addrs = append(addrs, mi.Owner)
 	}
 
-	return pickAddress(ctx, a, mi, goodFunds, minFunds, addrs)
+	var skipCongested bool
+	if use == api.PoStAddr {
+		// PoSt messages must land within the proving deadline, don't queue
+		// them behind other messages from the same sender if possible
+		_, skipCongested = a.(NonceApi)
+	}
+
+	return pickAddress(ctx, a, mi, goodFunds, minFunds, addrs, skipCongested)
+}
+
+// unassignedControl returns the miner control addresses which aren't
+// configured for any specific use.
+func (as *AddressSelector) unassignedControl(ctx context.Context, a NodeApi, mi api.MinerInfo) []address.Address {
+	defaultCtl := map[address.Address]struct{}{}
+	for _, a := range mi.ControlAddresses {
+		defaultCtl[a] = struct{}{}
+	}
+	delete(defaultCtl, mi.Owner)
+	delete(defaultCtl, mi.Worker)
+
+	configCtl := append([]address.Address{}, as.PreCommitControl...)
+	configCtl = append(configCtl, as.CommitControl...)
+	configCtl = append(configCtl, as.TerminateControl...)
+	configCtl = append(configCtl, as.DealPublishControl...)
+	configCtl = append(configCtl, as.WindowPoStControl...)
+
+	for _, addr := range configCtl {
+		if addr.Protocol() != address.ID {
+			var err error
+			addr, err = a.StateLookupID(ctx, addr, types.EmptyTSK)
+			if err != nil {
+				log.Warnw("looking up control address", "address", addr, "error", err)
+				continue
+			}
+		}
+
+		delete(defaultCtl, addr)
+	}
+
+	var addrs []address.Address
+	for a := range defaultCtl {
+		addrs = append(addrs, a)
+	}
+	return addrs
 }
 
-func pickAddress(ctx context.Context, a NodeApi, mi api.MinerInfo, goodFunds, minFunds abi.TokenAmount, addrs []address.Address) (address.Address, abi.TokenAmount, error) {
+func pickAddress(ctx context.Context, a NodeApi, mi api.MinerInfo, goodFunds, minFunds abi.TokenAmount, addrs []address.Address, skipCongested bool) (address.Address, abi.TokenAmount, error) {
 	leastBad := mi.Worker
 	bestAvail := minFunds
 
+	var congested []address.Address
+
 	ctl := map[address.Address]struct{}{}
 	for _, a := range append(mi.ControlAddresses, mi.Owner, mi.Worker) {
 		ctl[a] = struct{}{}
@@ -109,6 +146,18 @@ func pickAddress(ctx context.Context, a NodeApi, mi api.MinerInfo, goodFunds, mi
 			continue
 		}
 
+		if skipCongested && isCongested(ctx, a.(NonceApi), addr) {
+			congested = append(congested, addr)
+			continue
+		}
+
+		if maybeUseAddress(ctx, a, addr, goodFunds, &leastBad, &bestAvail) {
+			return leastBad, bestAvail, nil
+		}
+	}
+
+	// all uncongested senders are short on funds, try the congested ones
+	for _, addr := range congested {
 		if maybeUseAddress(ctx, a, addr, goodFunds, &leastBad, &bestAvail) {
 			return leastBad, bestAvail, nil
 		}
@@ -157,3 +206,25 @@ func maybeUseAddress(ctx context.Context, a NodeApi, addr address.Address, goodF
 	log.Warnw("address didn't have enough funds to send message", "address", addr, "required", types.FIL(goodFunds), "balance", types.FIL(b))
 	return false
 }
+
+// isCongested returns true when addr has more than MaxPoStSenderPending
+// messages waiting in the message pool.
+func isCongested(ctx context.Context, a NonceApi, addr address.Address) bool {
+	act, err := a.StateGetActor(ctx, addr, types.EmptyTSK)
+	if err != nil {
+		log.Errorw("getting sender actor", "addr", addr, "error", err)
+		return false
+	}
+
+	next, err := a.MpoolGetNonce(ctx, addr)
+	if err != nil {
+		log.Errorw("getting sender mpool nonce", "addr", addr, "error", err)
+		return false
+	}
+
+	if next > act.Nonce+MaxPoStSenderPending {
+		log.Warnw("sender has too many pending messages", "address", addr, "pending", next-act.Nonce, "max", MaxPoStSenderPending)
+		return true
+	}
+	return false
+}
@@ -0,0 +1,112 @@
+// stm: #unit
+package ctladdr
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/big"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+)
+
+type testNodeApi struct {
+	balances map[address.Address]types.BigInt
+	pending  map[address.Address]uint64
+}
+
+func (t *testNodeApi) WalletBalance(ctx context.Context, a address.Address) (types.BigInt, error) {
+	b, ok := t.balances[a]
+	if !ok {
+		return big.Zero(), nil
+	}
+	return b, nil
+}
+
+func (t *testNodeApi) WalletHas(ctx context.Context, a address.Address) (bool, error) {
+	return true, nil
+}
+
+func (t *testNodeApi) StateAccountKey(ctx context.Context, a address.Address, tsk types.TipSetKey) (address.Address, error) {
+	return a, nil
+}
+
+func (t *testNodeApi) StateLookupID(ctx context.Context, a address.Address, tsk types.TipSetKey) (address.Address, error) {
+	if a.Protocol() != address.ID {
+		return address.Undef, xerrors.Errorf("no id for %s", a)
+	}
+	return a, nil
+}
+
+func (t *testNodeApi) MpoolGetNonce(ctx context.Context, a address.Address) (uint64, error) {
+	return 10 + t.pending[a], nil
+}
+
+func (t *testNodeApi) StateGetActor(ctx context.Context, a address.Address, tsk types.TipSetKey) (*types.Actor, error) {
+	return &types.Actor{Nonce: 10}, nil
+}
+
+func mustIDAddr(t *testing.T, id uint64) address.Address {
+	a, err := address.NewIDAddress(id)
+	require.NoError(t, err)
+	return a
+}
+
+func TestWindowPoStControlOrder(t *testing.T) {
+	owner, worker := mustIDAddr(t, 100), mustIDAddr(t, 101)
+	ctl1, ctl2, ctl3 := mustIDAddr(t, 102), mustIDAddr(t, 103), mustIDAddr(t, 104)
+
+	mi := api.MinerInfo{
+		Owner:            owner,
+		Worker:           worker,
+		ControlAddresses: []address.Address{ctl1, ctl2, ctl3},
+	}
+	as := &AddressSelector{AddressConfig: api.AddressConfig{
+		WindowPoStControl: []address.Address{ctl2, ctl1},
+	}}
+
+	good, min := big.NewInt(100), big.NewInt(10)
+	na := &testNodeApi{
+		balances: map[address.Address]types.BigInt{
+			owner: big.NewInt(1000),
+			ctl1:  big.NewInt(1000),
+			ctl2:  big.NewInt(1000),
+		},
+		pending: map[address.Address]uint64{},
+	}
+
+	// first address in the list
+	addr, _, err := as.AddressFor(context.Background(), na, mi, api.PoStAddr, good, min)
+	require.NoError(t, err)
+	require.Equal(t, ctl2, addr)
+
+	// insufficient funds, falls back to the next one
+	na.balances[ctl2] = big.NewInt(50)
+	addr, _, err = as.AddressFor(context.Background(), na, mi, api.PoStAddr, good, min)
+	require.NoError(t, err)
+	require.Equal(t, ctl1, addr)
+
+	// congested, falls back to the owner
+	na.pending[ctl1] = MaxPoStSenderPending + 1
+	addr, _, err = as.AddressFor(context.Background(), na, mi, api.PoStAddr, good, min)
+	require.NoError(t, err)
+	require.Equal(t, owner, addr)
+
+	// without the owner fallback, the congested sender is still better than
+	// one without funds
+	as.DisableOwnerFallback = true
+	addr, _, err = as.AddressFor(context.Background(), na, mi, api.PoStAddr, good, min)
+	require.NoError(t, err)
+	require.Equal(t, ctl1, addr)
+
+	// WindowPoSt senders aren't used for other messages
+	as.DisableOwnerFallback = false
+	addr, _, err = as.AddressFor(context.Background(), na, mi, api.CommitAddr, good, min)
+	require.NoError(t, err)
+	require.Equal(t, owner, addr)
+}
@@ -0,0 +1,327 @@
+package funds
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+var log = logging.Logger("funds")
+
+// withdrawals are capped over this window by MaxPerDay
+const capWindow = 24 * time.Hour
+
+var historyKey = datastore.NewKey("/funds/withdrawals")
+
+type fullNodeAPI interface {
+	StateMinerAvailableBalance(context.Context, address.Address, types.TipSetKey) (types.BigInt, error)
+	StateMinerInfo(context.Context, address.Address, types.TipSetKey) (api.MinerInfo, error)
+	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+	StateWaitMsg(ctx context.Context, cid cid.Cid, confidence uint64, limit abi.ChainEpoch, allowReplaced bool) (*api.MsgLookup, error)
+}
+
+// WithdrawalEvt is the journal event recorded for each withdrawal, including
+// ones skipped in dry-run mode.
+type WithdrawalEvt struct {
+	Available abi.TokenAmount
+	Amount    abi.TokenAmount
+	DryRun    bool
+
+	From       address.Address `json:",omitempty"`
+	MessageCID cid.Cid         `json:",omitempty"`
+	ForwardTo  address.Address `json:",omitempty"`
+	ForwardCID cid.Cid         `json:",omitempty"`
+	Error      string          `json:",omitempty"`
+}
+
+type withdrawal struct {
+	Time   time.Time
+	Amount abi.TokenAmount
+}
+
+// Manager periodically withdraws the miner available balance above the
+// configured float, optionally forwarding it from the beneficiary to another
+// address.
+type Manager struct {
+	api   fullNodeAPI
+	maddr address.Address
+	cfg   config.MinerWithdrawalConfig
+	dest  address.Address
+	ds    datastore.Batching
+
+	journal journal.Journal
+	evtType journal.EventType
+
+	alerting   *alerting.Alerting
+	failedType alerting.AlertType
+
+	lk      sync.Mutex
+	history []withdrawal
+}
+
+func NewManager(fapi fullNodeAPI, maddr address.Address, cfg config.MinerWithdrawalConfig, ds datastore.Batching, j journal.Journal, al *alerting.Alerting) (*Manager, error) {
+	m := &Manager{
+		api:      fapi,
+		maddr:    maddr,
+		cfg:      cfg,
+		ds:       ds,
+		journal:  j,
+		evtType:  j.RegisterEventType("funds", "withdrawal"),
+		alerting: al,
+	}
+	if al != nil {
+		m.failedType = al.AddAlertType("funds", "withdrawal-failed")
+	}
+
+	if cfg.Destination != "" {
+		dest, err := address.NewFromString(cfg.Destination)
+		if err != nil {
+			return nil, xerrors.Errorf("parsing withdrawal destination: %w", err)
+		}
+		m.dest = dest
+	}
+
+	if time.Duration(cfg.CheckInterval) <= 0 {
+		return nil, xerrors.Errorf("withdrawal check interval must be positive")
+	}
+
+	b, err := ds.Get(context.TODO(), historyKey)
+	switch {
+	case err == nil:
+		if err := json.Unmarshal(b, &m.history); err != nil {
+			log.Warnw("ignoring malformed withdrawal history", "error", err)
+		}
+	case !xerrors.Is(err, datastore.ErrNotFound):
+		return nil, xerrors.Errorf("loading withdrawal history: %w", err)
+	}
+
+	return m, nil
+}
+
+func (m *Manager) Run(ctx context.Context) {
+	log.Infow("automatic withdrawals enabled", "float", m.cfg.Float, "threshold", m.cfg.Threshold, "interval", time.Duration(m.cfg.CheckInterval), "dry-run", m.cfg.DryRun)
+
+	t := time.NewTicker(time.Duration(m.cfg.CheckInterval))
+	defer t.Stop()
+
+	for {
+		if err := m.check(ctx); err != nil {
+			log.Errorw("automatic withdrawal failed", "error", err)
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// amt converts a config amount, treating unset values as zero
+func amt(f types.FIL) abi.TokenAmount {
+	if f.Int == nil {
+		return big.Zero()
+	}
+	return abi.TokenAmount(f)
+}
+
+// withdrawAmount returns how much of the available balance should be
+// withdrawn, given the amount already withdrawn within the cap window
+func withdrawAmount(cfg config.MinerWithdrawalConfig, available, withdrawn abi.TokenAmount) abi.TokenAmount {
+	excess := big.Sub(available, amt(cfg.Float))
+	if !excess.GreaterThan(big.Zero()) || excess.LessThan(amt(cfg.Threshold)) {
+		return big.Zero()
+	}
+
+	if limit := amt(cfg.MaxPerWithdrawal); limit.GreaterThan(big.Zero()) {
+		excess = big.Min(excess, limit)
+	}
+	if limit := amt(cfg.MaxPerDay); limit.GreaterThan(big.Zero()) {
+		excess = big.Min(excess, big.Max(big.Sub(limit, withdrawn), big.Zero()))
+	}
+
+	return excess
+}
+
+// withdrawn returns the amount withdrawn within the cap window, dropping
+// older entries
+func (m *Manager) withdrawn(now time.Time) abi.TokenAmount {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	total := big.Zero()
+	kept := m.history[:0]
+	for _, w := range m.history {
+		if now.Sub(w.Time) > capWindow {
+			continue
+		}
+		kept = append(kept, w)
+		total = big.Add(total, w.Amount)
+	}
+	m.history = kept
+
+	return total
+}
+
+func (m *Manager) record(ctx context.Context, w withdrawal) error {
+	m.lk.Lock()
+	defer m.lk.Unlock()
+
+	m.history = append(m.history, w)
+	b, err := json.Marshal(m.history)
+	if err != nil {
+		return err
+	}
+	return m.ds.Put(ctx, historyKey, b)
+}
+
+func (m *Manager) check(ctx context.Context) error {
+	available, err := m.api.StateMinerAvailableBalance(ctx, m.maddr, types.EmptyTSK)
+	if err != nil {
+		return xerrors.Errorf("getting available balance: %w", err)
+	}
+
+	withdrawn := m.withdrawn(time.Now())
+	amount := withdrawAmount(m.cfg, available, withdrawn)
+	if amount.IsZero() {
+		if limit := amt(m.cfg.MaxPerDay); limit.GreaterThan(big.Zero()) && !withdrawn.LessThan(limit) {
+			log.Debugw("daily withdrawal cap reached", "withdrawn", types.FIL(withdrawn))
+		}
+		return nil
+	}
+
+	evt := &WithdrawalEvt{
+		Available: available,
+		Amount:    amount,
+		DryRun:    m.cfg.DryRun,
+	}
+	defer m.journal.RecordEvent(m.evtType, func() interface{} { return evt })
+
+	if m.cfg.DryRun {
+		log.Infow("dry run: would withdraw", "amount", types.FIL(amount), "available", types.FIL(available))
+		return nil
+	}
+
+	if err := m.withdraw(ctx, evt); err != nil {
+		evt.Error = err.Error()
+		if m.alerting != nil {
+			m.alerting.Raise(m.failedType, map[string]interface{}{
+				"message": "automatic withdrawal failed",
+				"amount":  types.FIL(amount).String(),
+				"error":   err.Error(),
+			})
+		}
+		return err
+	}
+
+	if m.alerting != nil && m.alerting.IsRaised(m.failedType) {
+		m.alerting.Resolve(m.failedType, map[string]string{
+			"message": "automatic withdrawal succeeded",
+		})
+	}
+	return nil
+}
+
+func (m *Manager) withdraw(ctx context.Context, evt *WithdrawalEvt) error {
+	mi, err := m.api.StateMinerInfo(ctx, m.maddr, types.EmptyTSK)
+	if err != nil {
+		return xerrors.Errorf("getting miner info: %w", err)
+	}
+
+	evt.From = mi.Beneficiary
+	if m.cfg.FromOwner {
+		evt.From = mi.Owner
+	}
+
+	params, err := actors.SerializeParams(&minertypes.WithdrawBalanceParams{
+		AmountRequested: evt.Amount,
+	})
+	if err != nil {
+		return err
+	}
+
+	smsg, err := m.api.MpoolPushMessage(ctx, &types.Message{
+		To:     m.maddr,
+		From:   evt.From,
+		Value:  big.Zero(),
+		Method: builtin.MethodsMiner.WithdrawBalance,
+		Params: params,
+	}, nil)
+	if err != nil {
+		return xerrors.Errorf("pushing withdrawal message: %w", err)
+	}
+	evt.MessageCID = smsg.Cid()
+
+	log.Infow("withdrawing available balance", "amount", types.FIL(evt.Amount), "from", evt.From, "message", smsg.Cid())
+
+	wait, err := m.api.StateWaitMsg(ctx, smsg.Cid(), build.MessageConfidence, api.LookbackNoLimit, true)
+	if err != nil {
+		return xerrors.Errorf("waiting for withdrawal message: %w", err)
+	}
+	if wait.Receipt.ExitCode.IsError() {
+		return xerrors.Errorf("withdrawal message failed with exit code %d", wait.Receipt.ExitCode)
+	}
+
+	// the actor returns the amount actually withdrawn, which can be lower than
+	// requested if the available balance changed in the meantime
+	withdrawn := evt.Amount
+	var ret abi.TokenAmount
+	if err := ret.UnmarshalCBOR(bytes.NewReader(wait.Receipt.Return)); err == nil {
+		withdrawn = ret
+	}
+	evt.Amount = withdrawn
+
+	if err := m.record(ctx, withdrawal{Time: time.Now(), Amount: withdrawn}); err != nil {
+		log.Errorw("storing withdrawal history", "error", err)
+	}
+
+	return m.forward(ctx, mi, withdrawn, evt)
+}
+
+// forward sends withdrawn funds from the beneficiary to the destination
+func (m *Manager) forward(ctx context.Context, mi api.MinerInfo, amount abi.TokenAmount, evt *WithdrawalEvt) error {
+	if m.dest == address.Undef || amount.IsZero() {
+		return nil
+	}
+
+	destID, err := m.api.StateLookupID(ctx, m.dest, types.EmptyTSK)
+	if err == nil && destID == mi.Beneficiary {
+		return nil
+	}
+
+	evt.ForwardTo = m.dest
+	smsg, err := m.api.MpoolPushMessage(ctx, &types.Message{
+		To:    m.dest,
+		From:  mi.Beneficiary,
+		Value: amount,
+	}, nil)
+	if err != nil {
+		return xerrors.Errorf("forwarding withdrawn funds to %s: %w", m.dest, err)
+	}
+	evt.ForwardCID = smsg.Cid()
+
+	log.Infow("forwarding withdrawn funds", "amount", types.FIL(amount), "to", m.dest, "message", smsg.Cid())
+	return nil
+}
@@ -0,0 +1,115 @@
+package funds
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/exitcode"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/node/config"
+)
+
+func TestWithdrawAmount(t *testing.T) {
+	cfg := config.MinerWithdrawalConfig{
+		Float:     types.MustParseFIL("100"),
+		Threshold: types.MustParseFIL("10"),
+	}
+	fil := func(s string) abi.TokenAmount { return abi.TokenAmount(types.MustParseFIL(s)) }
+
+	require.Equal(t, big.Zero(), withdrawAmount(cfg, fil("50"), big.Zero()))
+	require.Equal(t, big.Zero(), withdrawAmount(cfg, fil("105"), big.Zero())) // below threshold
+	require.Equal(t, fil("10"), withdrawAmount(cfg, fil("110"), big.Zero()))
+	require.Equal(t, fil("400"), withdrawAmount(cfg, fil("500"), big.Zero()))
+
+	cfg.MaxPerWithdrawal = types.MustParseFIL("150")
+	require.Equal(t, fil("150"), withdrawAmount(cfg, fil("500"), big.Zero()))
+
+	cfg.MaxPerDay = types.MustParseFIL("200")
+	require.Equal(t, fil("50"), withdrawAmount(cfg, fil("500"), fil("150")))
+	require.Equal(t, big.Zero(), withdrawAmount(cfg, fil("500"), fil("250")))
+}
+
+type fakeAPI struct {
+	fullNodeAPI
+
+	available abi.TokenAmount
+	owner     address.Address
+	ben       address.Address
+	pushed    []*types.Message
+}
+
+func (f *fakeAPI) StateMinerAvailableBalance(context.Context, address.Address, types.TipSetKey) (types.BigInt, error) {
+	return f.available, nil
+}
+
+func (f *fakeAPI) StateMinerInfo(context.Context, address.Address, types.TipSetKey) (api.MinerInfo, error) {
+	return api.MinerInfo{Owner: f.owner, Beneficiary: f.ben}, nil
+}
+
+func (f *fakeAPI) StateLookupID(_ context.Context, a address.Address, _ types.TipSetKey) (address.Address, error) {
+	return a, nil
+}
+
+func (f *fakeAPI) MpoolPushMessage(_ context.Context, msg *types.Message, _ *api.MessageSendSpec) (*types.SignedMessage, error) {
+	msg.Nonce = uint64(len(f.pushed))
+	f.pushed = append(f.pushed, msg)
+	return &types.SignedMessage{Message: *msg}, nil
+}
+
+func (f *fakeAPI) StateWaitMsg(context.Context, cid.Cid, uint64, abi.ChainEpoch, bool) (*api.MsgLookup, error) {
+	return &api.MsgLookup{Receipt: types.MessageReceipt{ExitCode: exitcode.Ok}}, nil
+}
+
+func TestManagerCheck(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	maddr, _ := address.NewIDAddress(1000)
+	owner, _ := address.NewIDAddress(100)
+	ben, _ := address.NewIDAddress(101)
+	dest, _ := address.NewIDAddress(102)
+
+	fapi := &fakeAPI{available: abi.TokenAmount(types.MustParseFIL("500")), owner: owner, ben: ben}
+	cfg := config.MinerWithdrawalConfig{
+		Destination:   dest.String(),
+		Float:         types.MustParseFIL("100"),
+		Threshold:     types.MustParseFIL("10"),
+		CheckInterval: config.Duration(time.Hour),
+		MaxPerDay:     types.MustParseFIL("300"),
+		DryRun:        true,
+	}
+
+	m, err := NewManager(fapi, maddr, cfg, ds, journal.NilJournal(), nil)
+	require.NoError(t, err)
+
+	require.NoError(t, m.check(ctx))
+	require.Empty(t, fapi.pushed)
+
+	m.cfg.DryRun = false
+	require.NoError(t, m.check(ctx))
+	require.Len(t, fapi.pushed, 2)
+	require.Equal(t, builtin.MethodsMiner.WithdrawBalance, fapi.pushed[0].Method)
+	require.Equal(t, ben, fapi.pushed[0].From)
+	require.Equal(t, dest, fapi.pushed[1].To)
+	require.Equal(t, abi.TokenAmount(types.MustParseFIL("300")), fapi.pushed[1].Value)
+
+	// the daily cap survives restarts
+	m, err = NewManager(fapi, maddr, cfg, ds, journal.NilJournal(), nil)
+	require.NoError(t, err)
+	m.cfg.DryRun = false
+	require.NoError(t, m.check(ctx))
+	require.Len(t, fapi.pushed, 2)
+}
@@ -0,0 +1,154 @@
+package hotness
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/namespace"
+	"github.com/ipfs/go-datastore/query"
+	logging "github.com/ipfs/go-log/v2"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+var log = logging.Logger("hotness")
+
+var dsPrefix = datastore.NewKey("/piece-hotness")
+
+// HalfLife is the time after which a read counts half as much towards the
+// score of a piece.
+var HalfLife = 7 * 24 * time.Hour
+
+// Tracker persists how often pieces are read for retrievals.
+type Tracker struct {
+	ds  datastore.Batching
+	lk  sync.Mutex
+	now func() time.Time
+}
+
+func NewTracker(ds dtypes.MetadataDS) *Tracker {
+	return &Tracker{
+		ds:  namespace.Wrap(ds, dsPrefix),
+		now: time.Now,
+	}
+}
+
+func pieceKey(sector abi.SectorID, offset storiface.UnpaddedByteIndex) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf("/%d/%d/%d", sector.Miner, sector.Number, offset))
+}
+
+// decay returns the score at the given time.
+func decay(h api.PieceHotness, at time.Time) float64 {
+	elapsed := at.Sub(h.LastRead)
+	if elapsed <= 0 {
+		return h.Score
+	}
+	return h.Score * math.Exp2(-float64(elapsed)/float64(HalfLife))
+}
+
+func (t *Tracker) get(ctx context.Context, k datastore.Key) (api.PieceHotness, bool, error) {
+	b, err := t.ds.Get(ctx, k)
+	if err == datastore.ErrNotFound {
+		return api.PieceHotness{}, false, nil
+	}
+	if err != nil {
+		return api.PieceHotness{}, false, xerrors.Errorf("getting piece hotness: %w", err)
+	}
+
+	var h api.PieceHotness
+	if err := json.Unmarshal(b, &h); err != nil {
+		return api.PieceHotness{}, false, xerrors.Errorf("unmarshaling piece hotness: %w", err)
+	}
+	return h, true, nil
+}
+
+// RecordPieceRead records a read of a piece, unsealed is set when the piece had
+// to be unsealed for the read.
+func (t *Tracker) RecordPieceRead(ctx context.Context, sector abi.SectorID, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize, unsealed bool) {
+	if err := t.recordRead(ctx, sector, offset, size, unsealed); err != nil {
+		log.Warnw("recording piece read", "sector", sector, "offset", offset, "error", err)
+	}
+}
+
+func (t *Tracker) recordRead(ctx context.Context, sector abi.SectorID, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize, unsealed bool) error {
+	t.lk.Lock()
+	defer t.lk.Unlock()
+
+	k := pieceKey(sector, offset)
+	h, found, err := t.get(ctx, k)
+	if err != nil {
+		return err
+	}
+
+	now := t.now()
+	if !found || h.Size != size {
+		h = api.PieceHotness{
+			Sector:    sector,
+			Offset:    abi.UnpaddedPieceSize(offset),
+			Size:      size,
+			FirstRead: now,
+		}
+	}
+
+	h.Score = decay(h, now) + 1
+	h.Reads++
+	if unsealed {
+		h.Unseals++
+	}
+	h.LastRead = now
+
+	b, err := json.Marshal(h)
+	if err != nil {
+		return xerrors.Errorf("marshaling piece hotness: %w", err)
+	}
+	return t.ds.Put(ctx, k, b)
+}
+
+// PieceScore returns the current score of a piece, 0 for pieces never read.
+func (t *Tracker) PieceScore(ctx context.Context, sector abi.SectorID, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize) (float64, error) {
+	h, found, err := t.get(ctx, pieceKey(sector, offset))
+	if err != nil || !found || h.Size != size {
+		return 0, err
+	}
+	return decay(h, t.now()), nil
+}
+
+// List returns the statistics of all pieces which were read, hottest first.
+func (t *Tracker) List(ctx context.Context) ([]api.PieceHotness, error) {
+	res, err := t.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return nil, xerrors.Errorf("querying piece hotness: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	now := t.now()
+	out := []api.PieceHotness{}
+	for r := range res.Next() {
+		if r.Error != nil {
+			return nil, xerrors.Errorf("iterating piece hotness: %w", r.Error)
+		}
+
+		var h api.PieceHotness
+		if err := json.Unmarshal(r.Value, &h); err != nil {
+			return nil, xerrors.Errorf("unmarshaling piece hotness %s: %w", r.Key, err)
+		}
+		h.Score = decay(h, now)
+		out = append(out, h)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Score > out[j].Score
+	})
+	return out, nil
+}
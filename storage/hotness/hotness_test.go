@@ -0,0 +1,60 @@
+// stm: #unit
+package hotness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+)
+
+func TestTracker(t *testing.T) {
+	ctx := context.Background()
+
+	now := time.Unix(1_700_000_000, 0)
+	tr := NewTracker(dssync.MutexWrap(datastore.NewMapDatastore()))
+	tr.now = func() time.Time { return now }
+
+	s1 := abi.SectorID{Miner: 1000, Number: 1}
+	s2 := abi.SectorID{Miner: 1000, Number: 2}
+
+	tr.RecordPieceRead(ctx, s1, 0, 1016, true)
+	tr.RecordPieceRead(ctx, s1, 0, 1016, false)
+	tr.RecordPieceRead(ctx, s2, 1016, 1016, false)
+
+	score, err := tr.PieceScore(ctx, s1, 0, 1016)
+	require.NoError(t, err)
+	require.Equal(t, 2.0, score)
+
+	// unknown pieces, or pieces with another size, are cold
+	score, err = tr.PieceScore(ctx, s1, 1016, 1016)
+	require.NoError(t, err)
+	require.Zero(t, score)
+	score, err = tr.PieceScore(ctx, s1, 0, 2032)
+	require.NoError(t, err)
+	require.Zero(t, score)
+
+	// reads count half as much after each half-life
+	now = now.Add(HalfLife)
+	tr.RecordPieceRead(ctx, s2, 1016, 1016, false)
+
+	list, err := tr.List(ctx)
+	require.NoError(t, err)
+	require.Len(t, list, 2)
+
+	require.Equal(t, s2, list[0].Sector)
+	require.Equal(t, abi.UnpaddedPieceSize(1016), list[0].Offset)
+	require.InDelta(t, 1.5, list[0].Score, 1e-9)
+	require.Equal(t, uint64(2), list[0].Reads)
+
+	require.Equal(t, s1, list[1].Sector)
+	require.InDelta(t, 1.0, list[1].Score, 1e-9)
+	require.Equal(t, uint64(2), list[1].Reads)
+	require.Equal(t, uint64(1), list[1].Unseals)
+	require.True(t, now.Add(-HalfLife).Equal(list[1].LastRead))
+}
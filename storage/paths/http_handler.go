@@ -3,6 +3,7 @@ package paths
 import (
 	"bytes"
 	"encoding/json"
+	"io"
 	"net/http"
 	"os"
 	"strconv"
@@ -109,7 +110,7 @@ func (handler *FetchHandler) remoteGetSector(w http.ResponseWriter, r *http.Requ
 		ProofType: 0,
 	}
 
-	paths, _, err := handler.Local.AcquireSector(r.Context(), si, ft, storiface.FTNone, storiface.PathStorage, storiface.AcquireMove)
+	paths, stores, err := handler.Local.AcquireSector(r.Context(), si, ft, storiface.FTNone, storiface.PathStorage, storiface.AcquireMove)
 	if err != nil {
 		log.Errorf("AcquireSector: %+v", err)
 		w.WriteHeader(500)
@@ -118,6 +119,14 @@ func (handler *FetchHandler) remoteGetSector(w http.ResponseWriter, r *http.Requ
 
 	// TODO: reserve local storage here
 
+	// ranged reads of unsealed files are retrievals, everything else is
+	// transferred between workers for sealing
+	class := IOSealing
+	if _, has := r.Header["Range"]; has && ft == storiface.FTUnsealed {
+		class = IORetrieval
+	}
+	lim := storeIOLimiter(handler.Local, storiface.ID(storiface.PathByType(stores, ft)), class)
+
 	path := storiface.PathByType(paths, ft)
 	if path == "" {
 		log.Error("acquired path was empty")
@@ -142,11 +151,28 @@ func (handler *FetchHandler) remoteGetSector(w http.ResponseWriter, r *http.Requ
 		w.Header().Set("Content-Type", "application/x-tar")
 		w.WriteHeader(200)
 
-		err := tarutil.TarDirectory(path, w, make([]byte, CopyBuf))
+		err := tarutil.TarDirectory(path, lim.readsInto(r.Context(), w), make([]byte, CopyBuf))
 		if err != nil {
 			log.Errorf("send tar: %+v", err)
 			return
 		}
+	} else if lim != nil {
+		f, err := os.Open(path)
+		if err != nil {
+			log.Errorf("opening sector file: %+v", err)
+			w.WriteHeader(500)
+			return
+		}
+		defer f.Close() // nolint
+
+		w.Header().Set("Content-Type", "application/octet-stream")
+		http.ServeContent(w, r, stat.Name(), stat.ModTime(), struct {
+			io.Reader
+			io.Seeker
+		}{
+			Reader: lim.reader(r.Context(), f),
+			Seeker: f,
+		})
 	} else {
 		w.Header().Set("Content-Type", "application/octet-stream")
 		// will do a ranged read over the file at the given path if the caller has asked for a ranged read in the request headers.
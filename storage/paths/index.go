@@ -39,6 +39,8 @@ type SectorIndex interface { // part of storage-miner api
 	StorageFindSector(ctx context.Context, sector abi.SectorID, ft storiface.SectorFileType, ssize abi.SectorSize, allowFetch bool) ([]storiface.SectorStorageInfo, error)
 
 	StorageBestAlloc(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType) ([]storiface.StorageInfo, error)
+	StorageBestAllocSector(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType, sector abi.SectorID) ([]storiface.StorageInfo, error)
+	StorageSetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error
 
 	// atomically acquire locks on all sector file types. close ctx to unlock
 	StorageLock(ctx context.Context, sector abi.SectorID, read storiface.SectorFileType, write storiface.SectorFileType) error
@@ -71,6 +73,10 @@ type Index struct {
 
 	sectors map[storiface.Decl][]*declMeta
 	stores  map[storiface.ID]*storageEntry
+
+	// placement constrains new allocations for a sector to paths in at least
+	// one of the listed groups
+	placement map[abi.SectorID][]storiface.Group
 }
 
 func NewIndex(al *alerting.Alerting) *Index {
@@ -82,8 +88,9 @@ func NewIndex(al *alerting.Alerting) *Index {
 		alerting:   al,
 		pathAlerts: map[storiface.ID]alerting.AlertType{},
 
-		sectors: map[storiface.Decl][]*declMeta{},
-		stores:  map[storiface.ID]*storageEntry{},
+		sectors:   map[storiface.Decl][]*declMeta{},
+		stores:    map[storiface.ID]*storageEntry{},
+		placement: map[abi.SectorID][]storiface.Group{},
 	}
 }
 
@@ -575,10 +582,35 @@ func (i *Index) StorageInfo(ctx context.Context, id storiface.ID) (storiface.Sto
 	return *si.info, nil
 }
 
+func (i *Index) StorageSetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error {
+	i.lk.Lock()
+	defer i.lk.Unlock()
+
+	if len(groups) == 0 {
+		delete(i.placement, sector)
+		return nil
+	}
+
+	i.placement[sector] = append([]storiface.Group(nil), groups...)
+	return nil
+}
+
 func (i *Index) StorageBestAlloc(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType) ([]storiface.StorageInfo, error) {
 	i.lk.RLock()
 	defer i.lk.RUnlock()
 
+	return i.bestAlloc(allocate, ssize, pathType, nil)
+}
+
+func (i *Index) StorageBestAllocSector(ctx context.Context, allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType, sector abi.SectorID) ([]storiface.StorageInfo, error) {
+	i.lk.RLock()
+	defer i.lk.RUnlock()
+
+	return i.bestAlloc(allocate, ssize, pathType, i.placement[sector])
+}
+
+// bestAlloc must be called with the lock held
+func (i *Index) bestAlloc(allocate storiface.SectorFileType, ssize abi.SectorSize, pathType storiface.PathType, placement []storiface.Group) ([]storiface.StorageInfo, error) {
 	var candidates []storageEntry
 
 	var err error
@@ -618,10 +650,18 @@ func (i *Index) StorageBestAlloc(ctx context.Context, allocate storiface.SectorF
 			continue
 		}
 
+		if len(placement) > 0 && !inAnyGroup(p.info.Groups, placement) {
+			log.Debugf("not allocating on %s, placement %+v; path has %+v", p.info.ID, placement, p.info.Groups)
+			continue
+		}
+
 		candidates = append(candidates, *p)
 	}
 
 	if len(candidates) == 0 {
+		if len(placement) > 0 {
+			return nil, xerrors.Errorf("no good path found in placement groups %v", placement)
+		}
 		return nil, xerrors.New("no good path found")
 	}
 
@@ -659,4 +699,15 @@ func (i *Index) FindSector(id abi.SectorID, typ storiface.SectorFileType) ([]sto
 	return out, nil
 }
 
+func inAnyGroup(have, want []storiface.Group) bool {
+	for _, h := range have {
+		for _, w := range want {
+			if h == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 var _ SectorIndex = &Index{}
@@ -152,3 +152,70 @@ func TestFindAllow(t *testing.T) {
 		}
 	}
 }
+
+func TestBestAllocPlacement(t *testing.T) {
+	ctx := context.Background()
+
+	i := NewIndex(nil)
+
+	stor1 := newTestStorage()
+
+	stor2 := newTestStorage()
+	stor2.Groups = []storiface.Group{"eu"}
+
+	stor3 := newTestStorage()
+	stor3.Groups = []storiface.Group{"us", "archive"}
+
+	require.NoError(t, i.StorageAttach(ctx, stor1, bigFsStat))
+	require.NoError(t, i.StorageAttach(ctx, stor2, bigFsStat))
+	require.NoError(t, i.StorageAttach(ctx, stor3, bigFsStat))
+
+	s1 := abi.SectorID{
+		Miner:  12,
+		Number: 34,
+	}
+
+	{
+		si, err := i.StorageBestAllocSector(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing, s1)
+		require.NoError(t, err)
+		require.Len(t, si, 3)
+	}
+
+	require.NoError(t, i.StorageSetSectorPlacement(ctx, s1, []storiface.Group{"eu"}))
+
+	{
+		si, err := i.StorageBestAllocSector(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing, s1)
+		require.NoError(t, err)
+		require.Len(t, si, 1)
+		require.Equal(t, stor2.ID, si[0].ID)
+	}
+
+	{
+		// allocations not for a sector are not affected
+		si, err := i.StorageBestAlloc(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing)
+		require.NoError(t, err)
+		require.Len(t, si, 3)
+	}
+
+	{
+		// other sectors are not affected
+		si, err := i.StorageBestAllocSector(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing, abi.SectorID{Miner: 12, Number: 35})
+		require.NoError(t, err)
+		require.Len(t, si, 3)
+	}
+
+	require.NoError(t, i.StorageSetSectorPlacement(ctx, s1, []storiface.Group{"apac"}))
+
+	{
+		_, err := i.StorageBestAllocSector(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing, s1)
+		require.Error(t, err)
+	}
+
+	require.NoError(t, i.StorageSetSectorPlacement(ctx, s1, nil))
+
+	{
+		si, err := i.StorageBestAllocSector(ctx, storiface.FTUnsealed, s32g, storiface.PathSealing, s1)
+		require.NoError(t, err)
+		require.Len(t, si, 3)
+	}
+}
@@ -50,6 +50,8 @@ type path struct {
 
 	reserved     int64
 	reservations map[abi.SectorID]storiface.SectorFileType
+
+	limiters [ioClasses]*ioLimiter
 }
 
 func (p *path) stat(ls LocalStorage) (fsutil.FsStat, error) {
@@ -172,6 +174,8 @@ func (st *Local) OpenPath(ctx context.Context, p string) error {
 		maxStorage:   meta.MaxStorage,
 		reserved:     0,
 		reservations: map[abi.SectorID]storiface.SectorFileType{},
+
+		limiters: newPathLimiters(meta.IOLimits),
 	}
 
 	fst, err := out.stat(st.localStorage)
@@ -510,7 +514,7 @@ func (st *Local) AcquireSector(ctx context.Context, sid storiface.SectorRef, exi
 			continue
 		}
 
-		sis, err := st.index.StorageBestAlloc(ctx, fileType, ssize, pathType)
+		sis, err := st.index.StorageBestAllocSector(ctx, fileType, ssize, pathType, sid.ID)
 		if err != nil {
 			return storiface.SectorPaths{}, storiface.SectorPaths{}, xerrors.Errorf("finding best storage for allocating : %w", err)
 		}
@@ -749,6 +753,17 @@ func (st *Local) FsStat(ctx context.Context, id storiface.ID) (fsutil.FsStat, er
 	return p.stat(st.localStorage)
 }
 
+func (st *Local) ioLimiter(id storiface.ID, class IOClass) *ioLimiter {
+	st.localLk.RLock()
+	defer st.localLk.RUnlock()
+
+	p, ok := st.paths[id]
+	if !ok {
+		return nil
+	}
+	return p.limiters[class]
+}
+
 func (st *Local) GenerateSingleVanillaProof(ctx context.Context, minerID abi.ActorID, si storiface.PostSectorChallenge, ppt abi.RegisteredPoStProof) ([]byte, error) {
 	sr := storiface.SectorRef{
 		ID: abi.SectorID{
@@ -780,6 +795,15 @@ func (st *Local) GenerateSingleVanillaProof(ctx context.Context, minerID abi.Act
 		return nil, errPathNotFound
 	}
 
+	// the proofs library reads the sector files itself, so each challenge is
+	// charged as one read. WinningPoSt isn't throttled, it only reads a few
+	// challenges and must finish within the block time
+	if wpt, err := si.SealProof.RegisteredWinningPoStProof(); err != nil || wpt != ppt {
+		if err := st.ioLimiter(storiface.ID(sealedID), IOProving).waitRead(ctx, len(si.Challenge), 0); err != nil {
+			return nil, err
+		}
+	}
+
 	psi := ffi.PrivateSectorInfo{
 		SectorInfo: proof.SectorInfo{
 			SealProof:    si.SealProof,
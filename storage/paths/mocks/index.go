@@ -68,6 +68,21 @@ func (mr *MockSectorIndexMockRecorder) StorageBestAlloc(arg0, arg1, arg2, arg3 i
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageBestAlloc", reflect.TypeOf((*MockSectorIndex)(nil).StorageBestAlloc), arg0, arg1, arg2, arg3)
 }
 
+// StorageBestAllocSector mocks base method.
+func (m *MockSectorIndex) StorageBestAllocSector(arg0 context.Context, arg1 storiface.SectorFileType, arg2 abi.SectorSize, arg3 storiface.PathType, arg4 abi.SectorID) ([]storiface.StorageInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageBestAllocSector", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].([]storiface.StorageInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// StorageBestAllocSector indicates an expected call of StorageBestAllocSector.
+func (mr *MockSectorIndexMockRecorder) StorageBestAllocSector(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageBestAllocSector", reflect.TypeOf((*MockSectorIndex)(nil).StorageBestAllocSector), arg0, arg1, arg2, arg3, arg4)
+}
+
 // StorageDeclareSector mocks base method.
 func (m *MockSectorIndex) StorageDeclareSector(arg0 context.Context, arg1 storiface.ID, arg2 abi.SectorID, arg3 storiface.SectorFileType, arg4 bool) error {
 	m.ctrl.T.Helper()
@@ -198,6 +213,20 @@ func (mr *MockSectorIndexMockRecorder) StorageReportHealth(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageReportHealth", reflect.TypeOf((*MockSectorIndex)(nil).StorageReportHealth), arg0, arg1, arg2)
 }
 
+// StorageSetSectorPlacement mocks base method.
+func (m *MockSectorIndex) StorageSetSectorPlacement(arg0 context.Context, arg1 abi.SectorID, arg2 []string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StorageSetSectorPlacement", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StorageSetSectorPlacement indicates an expected call of StorageSetSectorPlacement.
+func (mr *MockSectorIndexMockRecorder) StorageSetSectorPlacement(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StorageSetSectorPlacement", reflect.TypeOf((*MockSectorIndex)(nil).StorageSetSectorPlacement), arg0, arg1, arg2)
+}
+
 // StorageTryLock mocks base method.
 func (m *MockSectorIndex) StorageTryLock(arg0 context.Context, arg1 abi.SectorID, arg2, arg3 storiface.SectorFileType) (bool, error) {
 	m.ctrl.T.Helper()
@@ -169,7 +169,7 @@ func (r *Remote) AcquireSector(ctx context.Context, s storiface.SectorRef, exist
 		dest := storiface.PathByType(fetchPaths, fileType)
 		storageID := storiface.PathByType(ids, fileType)
 
-		url, err := r.acquireFromRemote(ctx, s.ID, fileType, dest)
+		url, err := r.acquireFromRemote(ctx, s.ID, fileType, dest, storeIOLimiter(r.local, storiface.ID(storageID), IOSealing))
 		if err != nil {
 			return storiface.SectorPaths{}, storiface.SectorPaths{}, err
 		}
@@ -205,7 +205,7 @@ func tempFetchDest(spath string, create bool) (string, error) {
 	return filepath.Join(tempdir, b), nil
 }
 
-func (r *Remote) acquireFromRemote(ctx context.Context, s abi.SectorID, fileType storiface.SectorFileType, dest string) (string, error) {
+func (r *Remote) acquireFromRemote(ctx context.Context, s abi.SectorID, fileType storiface.SectorFileType, dest string, lim *ioLimiter) (string, error) {
 	si, err := r.index.StorageFindSector(ctx, s, fileType, 0, false)
 	if err != nil {
 		return "", err
@@ -233,7 +233,7 @@ func (r *Remote) acquireFromRemote(ctx context.Context, s abi.SectorID, fileType
 				return "", xerrors.Errorf("removing dest: %w", err)
 			}
 
-			err = r.fetchThrottled(ctx, url, tempDest)
+			err = r.fetchThrottled(ctx, url, tempDest, lim)
 			if err != nil {
 				merr = multierror.Append(merr, xerrors.Errorf("fetch error %s (storage %s) -> %s: %w", url, info.ID, tempDest, err))
 				// fetching failed, remove temp file
@@ -257,7 +257,7 @@ func (r *Remote) acquireFromRemote(ctx context.Context, s abi.SectorID, fileType
 	return "", xerrors.Errorf("failed to acquire sector %v from remote (tried %v): %w", s, si, merr)
 }
 
-func (r *Remote) fetchThrottled(ctx context.Context, url, outname string) (rerr error) {
+func (r *Remote) fetchThrottled(ctx context.Context, url, outname string, lim *ioLimiter) (rerr error) {
 	if len(r.limit) >= cap(r.limit) {
 		log.Infof("Throttling fetch, %d already running", len(r.limit))
 	}
@@ -273,7 +273,7 @@ func (r *Remote) fetchThrottled(ctx context.Context, url, outname string) (rerr
 		return xerrors.Errorf("context error while waiting for fetch limiter: %w", ctx.Err())
 	}
 
-	return fetch(ctx, url, outname, r.auth)
+	return fetch(ctx, url, outname, r.auth, lim)
 }
 
 func (r *Remote) checkAllocated(ctx context.Context, url string, spt abi.RegisteredSealProof, offset, size abi.PaddedPieceSize) (bool, error) {
@@ -567,12 +567,13 @@ func (r *Remote) Reader(ctx context.Context, s storiface.SectorRef, offset, size
 	ft := storiface.FTUnsealed
 
 	// check if we have the unsealed sector file locally
-	paths, _, err := r.local.AcquireSector(ctx, s, ft, storiface.FTNone, storiface.PathStorage, storiface.AcquireMove)
+	paths, stores, err := r.local.AcquireSector(ctx, s, ft, storiface.FTNone, storiface.PathStorage, storiface.AcquireMove)
 	if err != nil {
 		return nil, xerrors.Errorf("acquire local: %w", err)
 	}
 
 	path := storiface.PathByType(paths, ft)
+	lim := storeIOLimiter(r.local, storiface.ID(storiface.PathByType(stores, ft)), IORetrieval)
 
 	if path != "" {
 		// if we have the unsealed file locally, return a reader that can be used to read the contents of the
@@ -624,7 +625,7 @@ func (r *Remote) Reader(ctx context.Context, s storiface.SectorRef, offset, size
 					io.Reader
 					io.Closer
 				}{
-					Reader: r,
+					Reader: lim.reader(ctx, r),
 					Closer: funcCloser(func() error {
 						// if we already have a reader cached, close this one
 						if pf != nil {
@@ -0,0 +1,200 @@
+package paths
+
+import (
+	"context"
+	"io"
+
+	"golang.org/x/time/rate"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// IOClass is a class of storage traffic, throttled independently on each path.
+type IOClass int
+
+const (
+	// IOSealing is sector files transferred between workers
+	IOSealing IOClass = iota
+	// IORetrieval is unsealed sector data read for retrievals
+	IORetrieval
+	// IOProving is sector data read for PoSt vanilla proofs
+	IOProving
+
+	ioClasses
+)
+
+func (c IOClass) String() string {
+	switch c {
+	case IOSealing:
+		return "sealing"
+	case IORetrieval:
+		return "retrieval"
+	case IOProving:
+		return "proving"
+	default:
+		return "unknown"
+	}
+}
+
+// ioLimiter limits the I/O of one class of traffic on a path. A nil limiter,
+// or a nil rate within it, is unlimited.
+type ioLimiter struct {
+	readBytes  *rate.Limiter
+	writeBytes *rate.Limiter
+	readOps    *rate.Limiter
+	writeOps   *rate.Limiter
+}
+
+// newRateLimiter returns a limiter allowing a second worth of burst, or nil
+// when the rate is unlimited.
+func newRateLimiter(perSec uint64) *rate.Limiter {
+	if perSec == 0 {
+		return nil
+	}
+	burst := int(perSec)
+	if perSec > uint64(CopyBuf) {
+		burst = CopyBuf
+	}
+	return rate.NewLimiter(rate.Limit(perSec), burst)
+}
+
+func newIOLimiter(l storiface.IOLimit) *ioLimiter {
+	if l == (storiface.IOLimit{}) {
+		return nil
+	}
+	return &ioLimiter{
+		readBytes:  newRateLimiter(l.ReadBytesPerSec),
+		writeBytes: newRateLimiter(l.WriteBytesPerSec),
+		readOps:    newRateLimiter(l.ReadIOPS),
+		writeOps:   newRateLimiter(l.WriteIOPS),
+	}
+}
+
+// newPathLimiters returns the limiters of each class of traffic on a path.
+func newPathLimiters(l *storiface.PathIOLimits) [ioClasses]*ioLimiter {
+	var out [ioClasses]*ioLimiter
+	if l == nil {
+		return out
+	}
+	out[IOSealing] = newIOLimiter(l.Sealing)
+	out[IORetrieval] = newIOLimiter(l.Retrieval)
+	out[IOProving] = newIOLimiter(l.Proving)
+	return out
+}
+
+// waitN waits for n tokens, in chunks no larger than the burst of the limiter.
+func waitN(ctx context.Context, l *rate.Limiter, n int) error {
+	if l == nil {
+		return nil
+	}
+	for n > 0 {
+		chunk := n
+		if chunk > l.Burst() {
+			chunk = l.Burst()
+		}
+		if err := l.WaitN(ctx, chunk); err != nil {
+			return xerrors.Errorf("waiting for i/o limiter: %w", err)
+		}
+		n -= chunk
+	}
+	return nil
+}
+
+// waitRead waits until the given number of read operations and bytes can be
+// done.
+func (l *ioLimiter) waitRead(ctx context.Context, ops, bytes int) error {
+	if l == nil {
+		return nil
+	}
+	if err := waitN(ctx, l.readOps, ops); err != nil {
+		return err
+	}
+	return waitN(ctx, l.readBytes, bytes)
+}
+
+// waitWrite waits until the given number of write operations and bytes can be
+// done.
+func (l *ioLimiter) waitWrite(ctx context.Context, ops, bytes int) error {
+	if l == nil {
+		return nil
+	}
+	if err := waitN(ctx, l.writeOps, ops); err != nil {
+		return err
+	}
+	return waitN(ctx, l.writeBytes, bytes)
+}
+
+type waitFunc func(ctx context.Context, ops, bytes int) error
+
+// throttledReader charges each Read against a limiter once done.
+type throttledReader struct {
+	ctx  context.Context
+	r    io.Reader
+	wait waitFunc
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	n, err := t.r.Read(p)
+	if n > 0 {
+		if werr := t.wait(t.ctx, 1, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// throttledWriter charges each Write against a limiter once done.
+type throttledWriter struct {
+	ctx  context.Context
+	w    io.Writer
+	wait waitFunc
+}
+
+func (t *throttledWriter) Write(p []byte) (int, error) {
+	n, err := t.w.Write(p)
+	if n > 0 {
+		if werr := t.wait(t.ctx, 1, n); werr != nil {
+			return n, werr
+		}
+	}
+	return n, err
+}
+
+// reader throttles reads from the path through r.
+func (l *ioLimiter) reader(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, wait: l.waitRead}
+}
+
+// readsInto throttles reads from the path copied into w.
+func (l *ioLimiter) readsInto(ctx context.Context, w io.Writer) io.Writer {
+	if l == nil {
+		return w
+	}
+	return &throttledWriter{ctx: ctx, w: w, wait: l.waitRead}
+}
+
+// writesFrom throttles writes to the path of data copied from r.
+func (l *ioLimiter) writesFrom(ctx context.Context, r io.Reader) io.Reader {
+	if l == nil {
+		return r
+	}
+	return &throttledReader{ctx: ctx, r: r, wait: l.waitWrite}
+}
+
+// ioThrottled is implemented by stores limiting the I/O on their paths.
+type ioThrottled interface {
+	ioLimiter(id storiface.ID, class IOClass) *ioLimiter
+}
+
+// storeIOLimiter returns the limiter of a class of traffic on a path of the
+// store, or nil when it's unlimited.
+func storeIOLimiter(s Store, id storiface.ID, class IOClass) *ioLimiter {
+	if t, ok := s.(ioThrottled); ok {
+		return t.ioLimiter(id, class)
+	}
+	return nil
+}
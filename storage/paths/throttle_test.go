@@ -0,0 +1,95 @@
+// stm: #unit
+package paths
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestIOLimiter(t *testing.T) {
+	ctx := context.Background()
+
+	require.Nil(t, newIOLimiter(storiface.IOLimit{}))
+
+	// nil limiters don't wrap anything
+	var unlimited *ioLimiter
+	r := bytes.NewReader(nil)
+	require.Equal(t, io.Reader(r), unlimited.reader(ctx, r))
+	require.NoError(t, unlimited.waitRead(ctx, 1<<20, 1<<30))
+
+	lim := newIOLimiter(storiface.IOLimit{ReadBytesPerSec: 100 << 10})
+	require.NotNil(t, lim.readBytes)
+	require.Nil(t, lim.writeBytes)
+	require.Nil(t, lim.readOps)
+
+	// the first second worth of data is available at once, the rest at the
+	// limit; reservations are made at a fixed time, not to depend on the clock
+	now := time.Now()
+	require.Equal(t, 100<<10, lim.readBytes.Burst())
+	require.Zero(t, lim.readBytes.ReserveN(now, 100<<10).DelayFrom(now))
+	require.Equal(t, 500*time.Millisecond, lim.readBytes.ReserveN(now, 50<<10).DelayFrom(now))
+
+	lim = newIOLimiter(storiface.IOLimit{ReadBytesPerSec: 100 << 20})
+	n, err := io.Copy(io.Discard, lim.reader(ctx, bytes.NewReader(make([]byte, 150<<10))))
+	require.NoError(t, err)
+	require.Equal(t, int64(150<<10), n)
+
+	// writes aren't limited
+	n, err = io.Copy(io.Discard, lim.writesFrom(ctx, bytes.NewReader(make([]byte, 1<<20))))
+	require.NoError(t, err)
+	require.Equal(t, int64(1<<20), n)
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	_, err = io.Copy(io.Discard, lim.reader(cctx, bytes.NewReader(make([]byte, 1<<20))))
+	require.Error(t, err)
+}
+
+func TestLocalIOLimits(t *testing.T) {
+	ctx := context.TODO()
+
+	root := t.TempDir()
+	tstor := &TestingLocalStorage{
+		root: root,
+	}
+
+	st, err := NewLocal(ctx, tstor, NewIndex(nil), nil)
+	require.NoError(t, err)
+
+	path := filepath.Join(root, "1")
+	require.NoError(t, os.Mkdir(path, 0755))
+
+	meta := &storiface.LocalStorageMeta{
+		ID:       storiface.ID(uuid.New().String()),
+		Weight:   1,
+		CanStore: true,
+		IOLimits: &storiface.PathIOLimits{
+			Retrieval: storiface.IOLimit{ReadBytesPerSec: 50 << 20, ReadIOPS: 100},
+		},
+	}
+	mb, err := json.MarshalIndent(meta, "", "  ")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(path, MetaFile), mb, 0644))
+
+	require.NoError(t, st.OpenPath(ctx, path))
+
+	require.Nil(t, storeIOLimiter(st, meta.ID, IOSealing))
+	require.Nil(t, storeIOLimiter(st, meta.ID, IOProving))
+	require.Nil(t, storeIOLimiter(st, "other", IORetrieval))
+
+	lim := storeIOLimiter(st, meta.ID, IORetrieval)
+	require.NotNil(t, lim)
+	require.Equal(t, CopyBuf, lim.readBytes.Burst())
+	require.Equal(t, 100, lim.readOps.Burst())
+}
@@ -31,7 +31,7 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 
 	cw := cbg.NewCborWriter(w)
 
-	if _, err := cw.Write([]byte{184, 38}); err != nil {
+	if _, err := cw.Write([]byte{184, 41}); err != nil {
 		return err
 	}
 
@@ -309,6 +309,29 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.RetryClass (string) (string)
+	if len("RetryClass") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryClass\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryClass"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryClass")); err != nil {
+		return err
+	}
+
+	if len(t.RetryClass) > cbg.MaxLength {
+		return xerrors.Errorf("Value in field t.RetryClass was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(t.RetryClass))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string(t.RetryClass)); err != nil {
+		return err
+	}
+
 	// t.SectorType (abi.RegisteredSealProof) (int64)
 	if len("SectorType") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"SectorType\" was too long")
@@ -521,6 +544,22 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 		return err
 	}
 
+	// t.RetryAttempts (uint64) (uint64)
+	if len("RetryAttempts") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"RetryAttempts\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("RetryAttempts"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("RetryAttempts")); err != nil {
+		return err
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajUnsignedInt, uint64(t.RetryAttempts)); err != nil {
+		return err
+	}
+
 	// t.FaultReportMsg (cid.Cid) (struct)
 	if len("FaultReportMsg") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"FaultReportMsg\" was too long")
@@ -565,6 +604,38 @@ func (t *SectorInfo) MarshalCBOR(w io.Writer) error {
 		}
 	}
 
+	// t.PlacementGroups ([]string) (slice)
+	if len("PlacementGroups") > cbg.MaxLength {
+		return xerrors.Errorf("Value in field \"PlacementGroups\" was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len("PlacementGroups"))); err != nil {
+		return err
+	}
+	if _, err := io.WriteString(w, string("PlacementGroups")); err != nil {
+		return err
+	}
+
+	if len(t.PlacementGroups) > cbg.MaxLength {
+		return xerrors.Errorf("Slice value in field t.PlacementGroups was too long")
+	}
+
+	if err := cw.WriteMajorTypeHeader(cbg.MajArray, uint64(len(t.PlacementGroups))); err != nil {
+		return err
+	}
+	for _, v := range t.PlacementGroups {
+		if len(v) > cbg.MaxLength {
+			return xerrors.Errorf("Value in field v was too long")
+		}
+
+		if err := cw.WriteMajorTypeHeader(cbg.MajTextString, uint64(len(v))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, string(v)); err != nil {
+			return err
+		}
+	}
+
 	// t.PreCommit2Fails (uint64) (uint64)
 	if len("PreCommit2Fails") > cbg.MaxLength {
 		return xerrors.Errorf("Value in field \"PreCommit2Fails\" was too long")
@@ -1133,6 +1204,17 @@ func (t *SectorInfo) UnmarshalCBOR(r io.Reader) (err error) {
 			if _, err := io.ReadFull(cr, t.SeedValue[:]); err != nil {
 				return err
 			}
+			// t.RetryClass (string) (string)
+		case "RetryClass":
+
+			{
+				sval, err := cbg.ReadString(cr)
+				if err != nil {
+					return err
+				}
+
+				t.RetryClass = string(sval)
+			}
 			// t.SectorType (abi.RegisteredSealProof) (int64)
 		case "SectorType":
 			{
@@ -1357,6 +1439,21 @@ func (t *SectorInfo) UnmarshalCBOR(r io.Reader) (err error) {
 			if _, err := io.ReadFull(cr, t.PreCommit1Out[:]); err != nil {
 				return err
 			}
+			// t.RetryAttempts (uint64) (uint64)
+		case "RetryAttempts":
+
+			{
+
+				maj, extra, err = cr.ReadHeader()
+				if err != nil {
+					return err
+				}
+				if maj != cbg.MajUnsignedInt {
+					return fmt.Errorf("wrong type for uint64 field")
+				}
+				t.RetryAttempts = uint64(extra)
+
+			}
 			// t.FaultReportMsg (cid.Cid) (struct)
 		case "FaultReportMsg":
 
@@ -1403,6 +1500,38 @@ func (t *SectorInfo) UnmarshalCBOR(r io.Reader) (err error) {
 				}
 
 			}
+			// t.PlacementGroups ([]string) (slice)
+		case "PlacementGroups":
+
+			maj, extra, err = cr.ReadHeader()
+			if err != nil {
+				return err
+			}
+
+			if extra > cbg.MaxLength {
+				return fmt.Errorf("t.PlacementGroups: array too large (%d)", extra)
+			}
+
+			if maj != cbg.MajArray {
+				return fmt.Errorf("expected cbor array")
+			}
+
+			if extra > 0 {
+				t.PlacementGroups = make([]string, extra)
+			}
+
+			for i := 0; i < int(extra); i++ {
+
+				{
+					sval, err := cbg.ReadString(cr)
+					if err != nil {
+						return err
+					}
+
+					t.PlacementGroups[i] = string(sval)
+				}
+			}
+
 			// t.PreCommit2Fails (uint64) (uint64)
 		case "PreCommit2Fails":
 
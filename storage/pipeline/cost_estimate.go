@@ -0,0 +1,135 @@
+package sealing
+
+import (
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+// Rough amounts of gas used by onboarding messages, only used for estimates as
+// the actual amounts depend on the state of the miner actor.
+const (
+	EstimatePreCommitGas            = 25_000_000
+	EstimatePreCommitBatchGas       = 20_000_000
+	EstimatePreCommitBatchSectorGas = 8_000_000
+	EstimateProveCommitGas          = 65_000_000
+	EstimateProveCommitAggregateGas = 100_000_000
+	EstimateProveCommitAggSectorGas = 12_000_000
+)
+
+// EstimateOnboardingCost fills the message costs and totals of the estimate
+// from its sector count, network version, base fee and per sector collateral,
+// splitting sectors into messages the way the batchers would at that base fee.
+func EstimateOnboardingCost(cfg sealiface.Config, est *api.SealingCostEstimate) error {
+	if est.Sectors <= 0 {
+		return xerrors.Errorf("sector count must be positive")
+	}
+
+	precommitBatched := cfg.BatchPreCommits
+	if !cfg.BatchPreCommitAboveBaseFee.Nil() && !cfg.BatchPreCommitAboveBaseFee.IsZero() &&
+		est.BaseFee.LessThan(cfg.BatchPreCommitAboveBaseFee) && est.NetworkVersion >= network.Version14 {
+		precommitBatched = false
+	}
+
+	var err error
+	if precommitBatched {
+		est.PreCommit, err = batchesCost(est, batchSize(cfg.MaxPreCommitBatch, miner.PreCommitSectorBatchMaxSize), est.Sectors,
+			EstimatePreCommitBatchGas, EstimatePreCommitBatchSectorGas, policy.AggregatePreCommitNetworkFee)
+		if err != nil {
+			return xerrors.Errorf("estimating precommit batches: %w", err)
+		}
+	} else {
+		est.PreCommit = individualCost(est, est.Sectors, EstimatePreCommitGas)
+	}
+
+	minAggregate := cfg.MinCommitBatch
+	if minAggregate < miner.MinAggregatedSectors {
+		minAggregate = miner.MinAggregatedSectors
+	}
+	aggregate := cfg.AggregateCommits && est.Sectors >= minAggregate
+	if !cfg.AggregateAboveBaseFee.Nil() && !cfg.AggregateAboveBaseFee.IsZero() && est.BaseFee.LessThan(cfg.AggregateAboveBaseFee) {
+		aggregate = false
+	}
+
+	if aggregate {
+		size := batchSize(cfg.MaxCommitBatch, miner.MaxAggregatedSectors)
+		aggregated := est.Sectors
+		// a remainder too small to be aggregated is committed individually
+		if rem := est.Sectors % size; rem != 0 && rem < minAggregate {
+			aggregated -= rem
+		}
+
+		est.Commit, err = batchesCost(est, size, aggregated,
+			EstimateProveCommitAggregateGas, EstimateProveCommitAggSectorGas, policy.AggregateProveCommitNetworkFee)
+		if err != nil {
+			return xerrors.Errorf("estimating commit aggregates: %w", err)
+		}
+
+		ind := individualCost(est, est.Sectors-aggregated, EstimateProveCommitGas)
+		est.Commit.Messages += ind.Messages
+		est.Commit.GasUsed += ind.GasUsed
+		est.Commit.GasFee = big.Add(est.Commit.GasFee, ind.GasFee)
+	} else {
+		est.Commit = individualCost(est, est.Sectors, EstimateProveCommitGas)
+	}
+
+	n := big.NewInt(int64(est.Sectors))
+	est.Pledge = big.Mul(est.InitialPledge, n)
+	est.Fees = big.Sum(est.PreCommit.GasFee, est.PreCommit.NetworkFee, est.Commit.GasFee, est.Commit.NetworkFee)
+	est.Total = big.Add(est.Pledge, est.Fees)
+
+	return nil
+}
+
+func batchSize(configured, max int) int {
+	if configured <= 0 || configured > max {
+		return max
+	}
+	return configured
+}
+
+func individualCost(est *api.SealingCostEstimate, sectors int, gas int64) api.OnboardingMessagesCost {
+	used := gas * int64(sectors)
+	return api.OnboardingMessagesCost{
+		Messages:   sectors,
+		GasUsed:    used,
+		GasFee:     big.Mul(est.BaseFee, big.NewInt(used)),
+		NetworkFee: big.Zero(),
+	}
+}
+
+func batchesCost(est *api.SealingCostEstimate, size, sectors int, baseGas, sectorGas int64,
+	networkFee func(network.Version, int, abi.TokenAmount) (abi.TokenAmount, error)) (api.OnboardingMessagesCost, error) {
+	out := api.OnboardingMessagesCost{
+		Batched:    sectors > 0,
+		GasFee:     big.Zero(),
+		NetworkFee: big.Zero(),
+	}
+
+	for left := sectors; left > 0; left -= size {
+		n := size
+		if left < n {
+			n = left
+		}
+
+		fee, err := networkFee(est.NetworkVersion, n, est.BaseFee)
+		if err != nil {
+			return api.OnboardingMessagesCost{}, err
+		}
+
+		used := baseGas + sectorGas*int64(n)
+		out.Messages++
+		out.GasUsed += used
+		out.GasFee = big.Add(out.GasFee, big.Mul(est.BaseFee, big.NewInt(used)))
+		out.NetworkFee = big.Add(out.NetworkFee, fee)
+	}
+
+	return out, nil
+}
@@ -0,0 +1,108 @@
+// stm: #unit
+package sealing_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	pipeline "github.com/filecoin-project/lotus/storage/pipeline"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+func TestEstimateOnboardingCost(t *testing.T) {
+	baseFee := abi.NewTokenAmount(1e9)
+	nv := network.Version18
+
+	newEstimate := func(sectors int) *api.SealingCostEstimate {
+		return &api.SealingCostEstimate{
+			Sectors:          sectors,
+			NetworkVersion:   nv,
+			BaseFee:          baseFee,
+			PreCommitDeposit: abi.NewTokenAmount(100),
+			InitialPledge:    abi.NewTokenAmount(1000),
+		}
+	}
+	gasFee := func(gas int64) abi.TokenAmount {
+		return big.Mul(baseFee, big.NewInt(gas))
+	}
+
+	batching := sealiface.Config{
+		BatchPreCommits:            true,
+		MaxPreCommitBatch:          10,
+		AggregateCommits:           true,
+		MinCommitBatch:             4,
+		MaxCommitBatch:             10,
+		BatchPreCommitAboveBaseFee: abi.NewTokenAmount(1e8),
+		AggregateAboveBaseFee:      abi.NewTokenAmount(1e8),
+	}
+
+	t.Run("individual", func(t *testing.T) {
+		est := newEstimate(10)
+		require.NoError(t, pipeline.EstimateOnboardingCost(sealiface.Config{}, est))
+
+		require.False(t, est.PreCommit.Batched)
+		require.Equal(t, 10, est.PreCommit.Messages)
+		require.Equal(t, gasFee(10*pipeline.EstimatePreCommitGas), est.PreCommit.GasFee)
+		require.False(t, est.Commit.Batched)
+		require.Equal(t, 10, est.Commit.Messages)
+		require.Equal(t, gasFee(10*pipeline.EstimateProveCommitGas), est.Commit.GasFee)
+
+		require.Equal(t, abi.NewTokenAmount(10000), est.Pledge)
+		require.Equal(t, big.Add(est.PreCommit.GasFee, est.Commit.GasFee), est.Fees)
+		require.Equal(t, big.Add(est.Pledge, est.Fees), est.Total)
+	})
+
+	t.Run("batched", func(t *testing.T) {
+		est := newEstimate(25)
+		require.NoError(t, pipeline.EstimateOnboardingCost(batching, est))
+
+		require.True(t, est.PreCommit.Batched)
+		require.Equal(t, 3, est.PreCommit.Messages)
+		require.Equal(t, int64(3*pipeline.EstimatePreCommitBatchGas+25*pipeline.EstimatePreCommitBatchSectorGas), est.PreCommit.GasUsed)
+
+		require.True(t, est.Commit.Batched)
+		require.Equal(t, 3, est.Commit.Messages)
+
+		var networkFee abi.TokenAmount = big.Zero()
+		for _, n := range []int{10, 10, 5} {
+			fee, err := policy.AggregateProveCommitNetworkFee(nv, n, baseFee)
+			require.NoError(t, err)
+			networkFee = big.Add(networkFee, fee)
+		}
+		require.Equal(t, networkFee, est.Commit.NetworkFee)
+		require.True(t, est.Fees.GreaterThan(big.Add(est.PreCommit.GasFee, est.Commit.GasFee)))
+	})
+
+	t.Run("small remainder", func(t *testing.T) {
+		est := newEstimate(22)
+		require.NoError(t, pipeline.EstimateOnboardingCost(batching, est))
+
+		// two aggregates, then two individual commits
+		require.True(t, est.Commit.Batched)
+		require.Equal(t, 4, est.Commit.Messages)
+		require.Equal(t, int64(2*pipeline.EstimateProveCommitAggregateGas+20*pipeline.EstimateProveCommitAggSectorGas+2*pipeline.EstimateProveCommitGas), est.Commit.GasUsed)
+	})
+
+	t.Run("below base fee threshold", func(t *testing.T) {
+		cfg := batching
+		cfg.BatchPreCommitAboveBaseFee = abi.NewTokenAmount(2e9)
+		cfg.AggregateAboveBaseFee = abi.NewTokenAmount(2e9)
+
+		est := newEstimate(25)
+		require.NoError(t, pipeline.EstimateOnboardingCost(cfg, est))
+		require.False(t, est.PreCommit.Batched)
+		require.Equal(t, 25, est.PreCommit.Messages)
+		require.False(t, est.Commit.Batched)
+		require.Equal(t, 25, est.Commit.Messages)
+		require.True(t, est.Commit.NetworkFee.IsZero())
+	})
+
+	require.Error(t, pipeline.EstimateOnboardingCost(batching, newEstimate(0)))
+}
@@ -41,6 +41,9 @@ func (m *Sealing) Plan(events []statemachine.Event, user interface{}) (interface
 
 	return func(ctx statemachine.Context, si SectorInfo) error {
 		err := next(ctx, si)
+		if err == errRetriesExhausted {
+			return nil // already logged, wait for manual intervention
+		}
 		if err != nil {
 			log.Errorf("unhandled sector error (%d): %+v", si.SectorNumber, err)
 			return nil
@@ -54,6 +57,10 @@ var fsmPlanners = map[SectorState]func(events []statemachine.Event, state *Secto
 	// external import
 	ReceiveSector: planOne(
 		onReturning(SectorReceived{}),
+		onWithCB(SectorReceiveFailed{}, ReceiveSectorFailed, maybeNotifyRemoteDone(false, "ReceiveSectorFailed")),
+	),
+	ReceiveSectorFailed: planOne(
+	// SectorRemove (global)
 	),
 
 	// Sealing
@@ -422,11 +429,20 @@ func (m *Sealing) plan(events []statemachine.Event, state *SectorInfo) (func(sta
 		}
 	}
 
+	prevState, prevClass := state.State, state.RetryClass
+
 	processed, err := p(events, state)
 	if err != nil {
 		return nil, processed, xerrors.Errorf("running planner for state %s failed: %w", state.State, err)
 	}
 
+	if state.State != prevState {
+		trackRetries(state, events[:processed])
+	}
+	if prevClass != "" && (state.RetryClass != prevClass || state.State == Removed) {
+		m.retryAlerts.resolved(prevClass, state.SectorNumber)
+	}
+
 	/////
 	// Now decide what to do next
 
@@ -624,6 +640,9 @@ func (m *Sealing) plan(events []statemachine.Event, state *SectorInfo) (func(sta
 	case UndefinedSectorState:
 		log.Error("sector update with undefined state!")
 		return nil, processed, xerrors.Errorf("sector update with undefined state")
+	case ReceiveSectorFailed:
+		log.Errorf("sector %d import failed validation, remove it and import again", state.SectorNumber)
+		return nil, processed, xerrors.Errorf("sector %d import failed validation", state.SectorNumber)
 	case FailedUnrecoverable:
 		log.Errorf("sector %d failed unrecoverably", state.SectorNumber)
 		return nil, processed, xerrors.Errorf("sector %d failed unrecoverably", state.SectorNumber)
@@ -714,6 +733,10 @@ func (m *Sealing) restartSectors(ctx context.Context) error {
 	}
 
 	for _, sector := range trackedSectors {
+		if err := m.setSectorPlacement(ctx, sector.SectorNumber, sector.PlacementGroups); err != nil {
+			log.Errorf("restoring sector %d placement: %+v", sector.SectorNumber, err)
+		}
+
 		if err := m.sectors.Send(uint64(sector.SectorNumber), SectorRestart{}); err != nil {
 			log.Errorf("restarting sector %d: %+v", sector.SectorNumber, err)
 		}
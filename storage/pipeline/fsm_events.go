@@ -54,19 +54,22 @@ type SectorForceState struct {
 
 func (evt SectorForceState) applyGlobal(state *SectorInfo) bool {
 	state.State = evt.State
+	state.RetryClass, state.RetryAttempts = "", 0 // manual intervention starts over
 	return true
 }
 
 // Normal path
 
 type SectorStart struct {
-	ID         abi.SectorNumber
-	SectorType abi.RegisteredSealProof
+	ID              abi.SectorNumber
+	SectorType      abi.RegisteredSealProof
+	PlacementGroups []string
 }
 
 func (evt SectorStart) apply(state *SectorInfo) {
 	state.SectorNumber = evt.ID
 	state.SectorType = evt.SectorType
+	state.PlacementGroups = evt.PlacementGroups
 }
 
 type SectorStartCC struct {
@@ -563,3 +566,8 @@ func (evt SectorReceive) apply(state *SectorInfo) {
 type SectorReceived struct{}
 
 func (evt SectorReceived) apply(state *SectorInfo) {}
+
+type SectorReceiveFailed struct{ error }
+
+func (evt SectorReceiveFailed) FormatError(xerrors.Printer) (next error) { return evt.error }
+func (evt SectorReceiveFailed) apply(state *SectorInfo)                  {}
@@ -71,8 +71,9 @@ func (m *Sealing) handleWaitDeals(ctx statemachine.Context, sector SectorInfo) e
 
 				return ctx.Send(SectorAddPiece{})
 			},
-			number:   sector.SectorNumber,
-			ccUpdate: sector.CCUpdate,
+			number:    sector.SectorNumber,
+			ccUpdate:  sector.CCUpdate,
+			placement: sector.PlacementGroups,
 		}
 	} else {
 		// make sure we're only accounting for pieces which were correctly added
@@ -348,6 +349,11 @@ func (m *Sealing) SectorAddPieceToAny(ctx context.Context, size abi.UnpaddedPiec
 		return api.SectorOffset{}, err
 	}
 
+	placement, err := m.dealPlacement(ctx, cfg, deal, ts.Key())
+	if err != nil {
+		return api.SectorOffset{}, xerrors.Errorf("getting deal placement: %w", err)
+	}
+
 	m.inputLk.Lock()
 	if pp, exist := m.pendingPieces[proposalCID(deal)]; exist {
 		m.inputLk.Unlock()
@@ -366,7 +372,7 @@ func (m *Sealing) SectorAddPieceToAny(ctx context.Context, size abi.UnpaddedPiec
 	}
 
 	// addPendingPiece takes over m.inputLk
-	pp := m.addPendingPiece(ctx, size, data, deal, claimTerms, sp)
+	pp := m.addPendingPiece(ctx, size, data, deal, claimTerms, placement, sp)
 
 	res, err := waitAddPieceResp(ctx, pp)
 	if err != nil {
@@ -400,12 +406,13 @@ func (m *Sealing) getClaimTerms(ctx context.Context, deal api.PieceDealInfo, tsk
 }
 
 // called with m.inputLk; transfers the lock to another goroutine!
-func (m *Sealing) addPendingPiece(ctx context.Context, size abi.UnpaddedPieceSize, data storiface.Data, deal api.PieceDealInfo, ct pieceClaimBounds, sp abi.RegisteredSealProof) *pendingPiece {
+func (m *Sealing) addPendingPiece(ctx context.Context, size abi.UnpaddedPieceSize, data storiface.Data, deal api.PieceDealInfo, ct pieceClaimBounds, placement []string, sp abi.RegisteredSealProof) *pendingPiece {
 	doneCh := make(chan struct{})
 	pp := &pendingPiece{
 		size:       size,
 		deal:       deal,
 		claimTerms: ct,
+		placement:  placement,
 
 		data: data,
 
@@ -594,7 +601,7 @@ func (m *Sealing) updateInput(ctx context.Context, sp abi.RegisteredSealProof) e
 
 	if len(toAssign) > 0 {
 		log.Errorf("we are trying to create a new sector with open sectors %v", m.openSectors)
-		if err := m.tryGetDealSector(ctx, sp, getExpirationCached); err != nil {
+		if err := m.tryGetDealSector(ctx, sp, getExpirationCached, m.newSectorPlacement(toAssign)); err != nil {
 			log.Errorw("Failed to create a new sector for deals", "error", err)
 		}
 	}
@@ -623,6 +630,11 @@ func (m *Sealing) pendingPieceEpochBounds() []pieceBound {
 	boundsByEpoch := map[abi.ChainEpoch]*pieceBound{}
 
 	for ppi, piece := range m.pendingPieces {
+		if len(piece.placement) > 0 {
+			// pieces with placement constraints only go into new sectors
+			continue
+		}
+
 		// start bound on deal end
 		if boundsByEpoch[piece.deal.DealProposal.EndEpoch] == nil {
 			boundsByEpoch[piece.deal.DealProposal.EndEpoch] = &pieceBound{
@@ -796,7 +808,7 @@ func (m *Sealing) createSector(ctx context.Context, cfg sealiface.Config, sp abi
 	return sid, err
 }
 
-func (m *Sealing) tryGetDealSector(ctx context.Context, sp abi.RegisteredSealProof, ef expFn) error {
+func (m *Sealing) tryGetDealSector(ctx context.Context, sp abi.RegisteredSealProof, ef expFn, placement []string) error {
 	m.startupWait.Wait()
 
 	if m.nextDealSector != nil {
@@ -826,6 +838,12 @@ func (m *Sealing) tryGetDealSector(ctx context.Context, sp abi.RegisteredSealPro
 	// - we don't prefer upgrades, but can't create a new sector
 	shouldUpgrade := canUpgrade && (!cfg.PreferNewSectorsForDeals || !canCreate)
 
+	// sectors available for upgrading are already stored somewhere, so pieces
+	// with placement constraints always need a new sector
+	if len(placement) > 0 {
+		shouldUpgrade = false
+	}
+
 	log.Infow("new deal sector decision",
 		"sealing", m.stats.curSealing(),
 		"maxSeal", cfg.MaxSealingSectorsForDeals,
@@ -833,7 +851,8 @@ func (m *Sealing) tryGetDealSector(ctx context.Context, sp abi.RegisteredSealPro
 		"preferNew", cfg.PreferNewSectorsForDeals,
 		"canCreate", canCreate,
 		"canUpgrade", canUpgrade,
-		"shouldUpgrade", shouldUpgrade)
+		"shouldUpgrade", shouldUpgrade,
+		"placement", placement)
 
 	if shouldUpgrade {
 		got, err := m.maybeUpgradeSector(ctx, sp, cfg, ef)
@@ -850,12 +869,16 @@ func (m *Sealing) tryGetDealSector(ctx context.Context, sp abi.RegisteredSealPro
 		if err != nil {
 			return err
 		}
+		if err := m.setSectorPlacement(ctx, sid, placement); err != nil {
+			return xerrors.Errorf("setting sector placement: %w", err)
+		}
 		m.nextDealSector = &sid
 
-		log.Infow("Creating sector", "number", sid, "type", "deal", "proofType", sp)
+		log.Infow("Creating sector", "number", sid, "type", "deal", "proofType", sp, "placement", placement)
 		if err := m.sectors.Send(uint64(sid), SectorStart{
-			ID:         sid,
-			SectorType: sp,
+			ID:              sid,
+			SectorType:      sp,
+			PlacementGroups: placement,
 		}); err != nil {
 			return err
 		}
@@ -937,6 +960,9 @@ func (m *Sealing) SectorsStatus(ctx context.Context, sid abi.SectorNumber, showO
 		Retries:              info.InvalidProofs,
 		ToUpgrade:            false,
 		ReplicaUpdateMessage: info.ReplicaUpdateMessage,
+		PlacementGroups:      info.PlacementGroups,
+		RetryClass:           info.RetryClass,
+		RetryAttempts:        info.RetryAttempts,
 
 		LastErr: info.LastErr,
 		Log:     log,
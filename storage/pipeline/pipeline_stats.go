@@ -0,0 +1,231 @@
+package sealing
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+// PipelineStatsDSPrefix is the datastore prefix of the hourly counts of sectors
+// completing each stage of the sealing pipeline.
+const PipelineStatsDSPrefix = "/pipeline-stats"
+
+// pipelineStatsRetention is how long the hourly counts are kept for.
+const pipelineStatsRetention = 90 * 24 * time.Hour
+
+// pipelineStage is a stage of the sealing pipeline. A sector completes the
+// stage when it moves from one of the from states to one of the to states.
+type pipelineStage struct {
+	name string
+	from []SectorState
+	to   []SectorState
+	// queued are the states of the sectors in, or waiting for, the stage
+	queued []SectorState
+}
+
+var pipelineStages = []pipelineStage{
+	{
+		name:   "ap",
+		from:   []SectorState{Packing},
+		to:     []SectorState{GetTicket},
+		queued: []SectorState{AddPiece, Packing},
+	},
+	{
+		name:   "pc1",
+		from:   []SectorState{PreCommit1},
+		to:     []SectorState{PreCommit2},
+		queued: []SectorState{GetTicket, PreCommit1},
+	},
+	{
+		name:   "pc2",
+		from:   []SectorState{PreCommit2},
+		to:     []SectorState{PreCommitting},
+		queued: []SectorState{PreCommit2},
+	},
+	{
+		name:   "c2",
+		from:   []SectorState{Committing},
+		to:     []SectorState{SubmitCommit, CommitFinalize},
+		queued: []SectorState{Committing},
+	},
+	{
+		name:   "submit",
+		from:   []SectorState{CommitWait, CommitAggregateWait},
+		to:     []SectorState{FinalizeSector},
+		queued: []SectorState{CommitFinalize, SubmitCommit, SubmitCommitAggregate, CommitWait, CommitAggregateWait},
+	},
+}
+
+func hasState(states []SectorState, st SectorState) bool {
+	for _, s := range states {
+		if s == st {
+			return true
+		}
+	}
+	return false
+}
+
+// pipelineStats counts the sectors completing each stage of the sealing
+// pipeline per hour. The counts are persisted, so that the trends survive
+// restarts.
+type pipelineStats struct {
+	ds  datastore.Batching
+	now func() time.Time
+
+	lk     sync.Mutex
+	hourly map[int64]map[string]int64 // by unix hour
+}
+
+func newPipelineStats(ctx context.Context, ds datastore.Batching) *pipelineStats {
+	ps := &pipelineStats{
+		ds:     ds,
+		now:    time.Now,
+		hourly: map[int64]map[string]int64{},
+	}
+
+	if err := ps.load(ctx); err != nil {
+		log.Errorf("loading sealing pipeline stats: %+v", err)
+	}
+	return ps
+}
+
+func (ps *pipelineStats) load(ctx context.Context) error {
+	res, err := ps.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return xerrors.Errorf("querying pipeline stats: %w", err)
+	}
+	defer res.Close() //nolint:errcheck
+
+	for r := range res.Next() {
+		if r.Error != nil {
+			return xerrors.Errorf("reading pipeline stats: %w", r.Error)
+		}
+
+		hour, err := strconv.ParseInt(datastore.NewKey(r.Key).BaseNamespace(), 10, 64)
+		if err != nil {
+			log.Warnf("invalid pipeline stats key %s", r.Key)
+			continue
+		}
+		counts := map[string]int64{}
+		if err := json.Unmarshal(r.Value, &counts); err != nil {
+			log.Warnf("invalid pipeline stats for hour %d: %s", hour, err)
+			continue
+		}
+		ps.hourly[hour] = counts
+	}
+	return nil
+}
+
+// onStateChange counts the stage completed by a sector state transition.
+func (ps *pipelineStats) onStateChange(before, after SectorInfo) {
+	if before.State == after.State {
+		return
+	}
+
+	for _, stage := range pipelineStages {
+		if hasState(stage.from, before.State) && hasState(stage.to, after.State) {
+			if err := ps.record(context.TODO(), stage.name); err != nil {
+				log.Errorf("recording sealing pipeline stats: %+v", err)
+			}
+			return
+		}
+	}
+}
+
+func (ps *pipelineStats) record(ctx context.Context, stage string) error {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+
+	hour := ps.now().Unix() / 3600
+	counts, ok := ps.hourly[hour]
+	if !ok {
+		counts = map[string]int64{}
+		ps.hourly[hour] = counts
+
+		if err := ps.pruneLocked(ctx, hour); err != nil {
+			return err
+		}
+	}
+	counts[stage]++
+
+	b, err := json.Marshal(counts)
+	if err != nil {
+		return err
+	}
+	return ps.ds.Put(ctx, hourKey(hour), b)
+}
+
+func (ps *pipelineStats) pruneLocked(ctx context.Context, hour int64) error {
+	oldest := hour - int64(pipelineStatsRetention/time.Hour)
+	for h := range ps.hourly {
+		if h > oldest {
+			continue
+		}
+		if err := ps.ds.Delete(ctx, hourKey(h)); err != nil {
+			return xerrors.Errorf("deleting pipeline stats of hour %d: %w", h, err)
+		}
+		delete(ps.hourly, h)
+	}
+	return nil
+}
+
+func hourKey(hour int64) datastore.Key {
+	return datastore.NewKey(strconv.FormatInt(hour, 10))
+}
+
+// stats returns the stats of the pipeline, with the history of the last days.
+func (ps *pipelineStats) stats(queued map[SectorState]int64, days int) *api.SealingPipelineStats {
+	ps.lk.Lock()
+	defer ps.lk.Unlock()
+
+	now := ps.now()
+	hour := now.Unix() / 3600
+
+	out := &api.SealingPipelineStats{}
+	for _, stage := range pipelineStages {
+		st := api.SealingStageStats{Stage: stage.name}
+		for h := hour - 23; h <= hour; h++ {
+			st.PerDay += ps.hourly[h][stage.name]
+		}
+		for _, s := range stage.queued {
+			st.Queued += queued[s]
+		}
+		out.Stages = append(out.Stages, st)
+	}
+
+	today := now.UTC().Truncate(24 * time.Hour)
+	for d := days - 1; d >= 0; d-- {
+		day := today.Add(-time.Duration(d) * 24 * time.Hour)
+		sd := api.SealingPipelineDay{
+			Day:       day.Format("2006-01-02"),
+			Completed: map[string]int64{},
+		}
+		first := day.Unix() / 3600
+		for h := first; h < first+24; h++ {
+			for stage, n := range ps.hourly[h] {
+				sd.Completed[stage] += n
+			}
+		}
+		out.History = append(out.History, sd)
+	}
+
+	return out
+}
+
+// PipelineStats returns the throughput of each stage of the sealing pipeline
+// over the last 24 hours, the number of sectors in each stage, and the number
+// of sectors which completed each stage in each of the last days.
+func (m *Sealing) PipelineStats(ctx context.Context, days int) (*api.SealingPipelineStats, error) {
+	if days < 0 || days > int(pipelineStatsRetention/(24*time.Hour)) {
+		return nil, xerrors.Errorf("days must be between 0 and %d", pipelineStatsRetention/(24*time.Hour))
+	}
+	return m.pipelineStats.stats(m.stats.countByState(), days), nil
+}
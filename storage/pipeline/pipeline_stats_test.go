@@ -0,0 +1,68 @@
+package sealing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPipelineStats(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	now := time.Date(2023, 5, 10, 12, 30, 0, 0, time.UTC)
+	ps := newPipelineStats(ctx, ds)
+	ps.now = func() time.Time { return now }
+
+	transition := func(from, to SectorState) {
+		ps.onStateChange(SectorInfo{State: from}, SectorInfo{State: to})
+	}
+
+	// yesterday
+	now = now.Add(-24 * time.Hour)
+	transition(PreCommit1, PreCommit2)
+	transition(PreCommit1, PreCommit2)
+
+	now = now.Add(24 * time.Hour)
+	transition(Packing, GetTicket)
+	transition(PreCommit1, PreCommit2)
+	transition(Committing, CommitFinalize)
+	transition(CommitAggregateWait, FinalizeSector)
+	// not completing a stage
+	transition(PreCommit1, SealPreCommit1Failed)
+	transition(PreCommit2, PreCommit2)
+	transition(WaitSeed, Committing)
+
+	queued := map[SectorState]int64{PreCommit1: 3, GetTicket: 1, CommitWait: 2, WaitSeed: 5}
+	st := ps.stats(queued, 2)
+
+	perDay := map[string]int64{}
+	queuedBy := map[string]int64{}
+	for _, s := range st.Stages {
+		perDay[s.Stage] = s.PerDay
+		queuedBy[s.Stage] = s.Queued
+	}
+	require.Equal(t, map[string]int64{"ap": 1, "pc1": 1, "pc2": 0, "c2": 1, "submit": 1}, perDay)
+	require.Equal(t, map[string]int64{"ap": 0, "pc1": 4, "pc2": 0, "c2": 0, "submit": 2}, queuedBy)
+
+	require.Len(t, st.History, 2)
+	require.Equal(t, "2023-05-09", st.History[0].Day)
+	require.Equal(t, map[string]int64{"pc1": 2}, st.History[0].Completed)
+	require.Equal(t, "2023-05-10", st.History[1].Day)
+	require.Equal(t, map[string]int64{"ap": 1, "pc1": 1, "c2": 1, "submit": 1}, st.History[1].Completed)
+
+	// the counts are persisted
+	reloaded := newPipelineStats(ctx, ds)
+	reloaded.now = ps.now
+	require.Equal(t, st, reloaded.stats(queued, 2))
+
+	// and pruned after the retention period
+	now = now.Add(pipelineStatsRetention)
+	transition(PreCommit2, PreCommitting)
+	reloaded = newPipelineStats(ctx, ds)
+	require.Len(t, reloaded.hourly, 1)
+}
@@ -0,0 +1,131 @@
+package sealing
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/ipfs/go-cid"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+// normalizePlacement returns a sorted list of unique, non-empty groups, or nil
+// if there are none
+func normalizePlacement(groups []string) []string {
+	var out []string
+	seen := map[string]struct{}{}
+	for _, g := range groups {
+		if g == "" {
+			continue
+		}
+		if _, ok := seen[g]; ok {
+			continue
+		}
+		seen[g] = struct{}{}
+		out = append(out, g)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// samePlacement compares normalized placements
+func samePlacement(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// newSectorPlacement picks the placement for a new deal sector. Unconstrained
+// pieces are served first, then placements in lexicographic order.
+// called with m.inputLk
+func (m *Sealing) newSectorPlacement(toAssign map[cid.Cid]struct{}) []string {
+	var out []string
+	var found bool
+	for proposalCid := range toAssign {
+		piece, ok := m.pendingPieces[proposalCid]
+		if !ok {
+			continue
+		}
+		if len(piece.placement) == 0 {
+			return nil
+		}
+		if !found || strings.Join(piece.placement, ",") < strings.Join(out, ",") {
+			out = piece.placement
+			found = true
+		}
+	}
+	return out
+}
+
+// dealPlacement returns the placement groups for a deal. Groups set on the deal
+// take precedence over client rules from the sealing config.
+func (m *Sealing) dealPlacement(ctx context.Context, cfg sealiface.Config, deal api.PieceDealInfo, tsk types.TipSetKey) ([]string, error) {
+	if len(deal.PlacementGroups) > 0 {
+		return normalizePlacement(deal.PlacementGroups), nil
+	}
+	if len(cfg.ClientPlacement) == 0 || deal.DealProposal == nil {
+		return nil, nil
+	}
+
+	client := deal.DealProposal.Client
+	clientID, err := m.Api.StateLookupID(ctx, client, tsk)
+	if err != nil {
+		return nil, xerrors.Errorf("looking up deal client ID: %w", err)
+	}
+
+	// rules are checked in the configured order, the first matching one applies
+	for _, rule := range cfg.ClientPlacement {
+		if m.clientMatches(ctx, rule.Client, client, clientID, tsk) {
+			return normalizePlacement(rule.Groups), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// clientMatches checks whether a client address from the sealing config refers
+// to the deal client, resolving non-ID addresses on chain.
+func (m *Sealing) clientMatches(ctx context.Context, rule string, client, clientID address.Address, tsk types.TipSetKey) bool {
+	ra, err := address.NewFromString(rule)
+	if err != nil {
+		log.Warnw("invalid client address in sealing config", "client", rule, "error", err)
+		return false
+	}
+	if ra == client || ra == clientID {
+		return true
+	}
+	if ra.Protocol() == address.ID {
+		return false
+	}
+
+	rid, err := m.Api.StateLookupID(ctx, ra, tsk)
+	if err != nil {
+		// the client may not exist on chain yet
+		log.Debugw("looking up client from sealing config", "client", rule, "error", err)
+		return false
+	}
+	return rid == clientID
+}
+
+// setSectorPlacement applies the sector placement to storage allocation
+func (m *Sealing) setSectorPlacement(ctx context.Context, sn abi.SectorNumber, groups []string) error {
+	if len(groups) == 0 {
+		return nil
+	}
+
+	return m.sealer.SetSectorPlacement(ctx, m.minerSectorID(sn), groups)
+}
@@ -0,0 +1,83 @@
+package sealing
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin/v9/market"
+
+	"github.com/filecoin-project/lotus/api"
+)
+
+func TestNormalizePlacement(t *testing.T) {
+	require.Nil(t, normalizePlacement(nil))
+	require.Nil(t, normalizePlacement([]string{""}))
+	require.Equal(t, []string{"eu", "us"}, normalizePlacement([]string{"us", "eu", "us", ""}))
+
+	require.True(t, samePlacement(nil, []string{}))
+	require.True(t, samePlacement([]string{"eu"}, []string{"eu"}))
+	require.False(t, samePlacement([]string{"eu"}, nil))
+	require.False(t, samePlacement([]string{"eu"}, []string{"us"}))
+}
+
+func TestPlacementAssignable(t *testing.T) {
+	piece := func(placement ...string) *pendingPiece {
+		return &pendingPiece{
+			deal: api.PieceDealInfo{
+				DealProposal: &market.DealProposal{
+					EndEpoch: 1000,
+				},
+			},
+			claimTerms: pieceClaimBounds{claimTermEnd: 2000},
+			placement:  placement,
+		}
+	}
+
+	noExp := func(sn abi.SectorNumber) (abi.ChainEpoch, abi.TokenAmount, error) {
+		panic("not expected")
+	}
+
+	open := &openSector{number: 1}
+	ok, err := open.checkDealAssignable(piece(), noExp)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = open.checkDealAssignable(piece("eu"), noExp)
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	open = &openSector{number: 2, placement: []string{"eu"}}
+	ok, err = open.checkDealAssignable(piece("eu"), noExp)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	ok, err = open.checkDealAssignable(piece(), noExp)
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestNewSectorPlacement(t *testing.T) {
+	c := func(s string) cid.Cid {
+		b, err := abi.CidBuilder.Sum([]byte(s))
+		require.NoError(t, err)
+		return b
+	}
+
+	m := &Sealing{pendingPieces: map[cid.Cid]*pendingPiece{
+		c("a"): {placement: []string{"us"}},
+		c("b"): {placement: []string{"eu"}},
+		c("c"): {},
+	}}
+
+	all := map[cid.Cid]struct{}{c("a"): {}, c("b"): {}, c("c"): {}}
+	require.Nil(t, m.newSectorPlacement(all))
+
+	delete(all, c("c"))
+	require.Equal(t, []string{"eu"}, m.newSectorPlacement(all))
+
+	delete(all, c("b"))
+	require.Equal(t, []string{"us"}, m.newSectorPlacement(all))
+}
@@ -290,11 +290,38 @@ func (m *Sealing) handleReceiveSector(ctx statemachine.Context, sector SectorInf
 		}
 	}
 
-	// todo data checks?
+	if sector.CommR != nil {
+		if err := m.checkReceivedData(ctx.Context(), sector); err != nil {
+			return ctx.Send(SectorReceiveFailed{xerrors.Errorf("validating received data: %w", err)})
+		}
+	}
 
 	return ctx.Send(SectorReceived{})
 }
 
+// checkReceivedData makes sure that sealed data of a received sector matches
+// its CommR before the sector is precommitted or committed. This is done by
+// generating a vanilla PoSt proof over the sealed file and the cache.
+func (m *Sealing) checkReceivedData(ctx context.Context, sector SectorInfo) error {
+	ppt, err := sector.SectorType.RegisteredWindowPoStProof()
+	if err != nil {
+		return xerrors.Errorf("getting post proof type: %w", err)
+	}
+
+	ref := m.minerSector(sector.SectorType, sector.SectorNumber)
+	bad, err := m.sealer.CheckProvable(ctx, ppt, []storiface.SectorRef{ref}, func(ctx context.Context, id abi.SectorID) (cid.Cid, bool, error) {
+		return *sector.CommR, false, nil
+	})
+	if err != nil {
+		return xerrors.Errorf("checking sector data: %w", err)
+	}
+	if reason, ok := bad[ref.ID]; ok {
+		return xerrors.Errorf("sealed data doesn't match CommR %s: %s", sector.CommR, reason)
+	}
+
+	return nil
+}
+
 func checkMessagePrefix(c cid.Cid) error {
 	p := c.Prefix()
 	if p.Version != 1 || p.MhLength != 32 || p.MhType != multihash.BLAKE2B_MIN+31 || p.Codec != cid.DagCBOR {
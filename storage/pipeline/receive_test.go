@@ -0,0 +1,42 @@
+package sealing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/storage/sealer/mock"
+)
+
+func TestCheckReceivedData(t *testing.T) {
+	ctx := context.Background()
+
+	maddr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	m := &Sealing{
+		maddr:  maddr,
+		sealer: mock.NewMockSectorMgr([]abi.SectorID{{Miner: 1000, Number: 1}}),
+	}
+
+	commR, err := abi.CidBuilder.Sum([]byte("sealed"))
+	require.NoError(t, err)
+
+	sector := func(sn abi.SectorNumber) SectorInfo {
+		return SectorInfo{
+			SectorNumber: sn,
+			SectorType:   abi.RegisteredSealProof_StackedDrg2KiBV1_1,
+			CommR:        &commR,
+		}
+	}
+
+	require.NoError(t, m.checkReceivedData(ctx, sector(1)))
+
+	err = m.checkReceivedData(ctx, sector(2))
+	require.ErrorContains(t, err, "sealed data doesn't match CommR")
+	require.ErrorContains(t, err, commR.String())
+}
@@ -0,0 +1,216 @@
+package sealing
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-statemachine"
+
+	"github.com/filecoin-project/lotus/journal/alerting"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+// MinRetryTime is the delay between retries of failed sectors in classes
+// without a configured retry policy
+var MinRetryTime = 1 * time.Minute
+
+var errRetriesExhausted = errors.New("retry attempts exhausted")
+
+// retryClass returns the retry class of a failed sector state, or "" for
+// states which aren't failures
+func retryClass(st SectorState, events []statemachine.Event) string {
+	switch st {
+	case SealPreCommit1Failed:
+		for _, evt := range events {
+			if e, ok := evt.User.(SectorSealPreCommit1Failed); ok && e.error != nil {
+				var et *ErrExpiredTicket
+				if errors.As(e.error, &et) {
+					return sealiface.RetryTicketExpired
+				}
+			}
+		}
+		return sealiface.RetryPreCommit1
+	case SealPreCommit2Failed:
+		return sealiface.RetryPreCommit2
+	case PreCommitFailed:
+		return sealiface.RetryPreCommit
+	case ComputeProofFailed, RemoteCommitFailed:
+		return sealiface.RetryComputeProof
+	case CommitFailed:
+		return sealiface.RetryCommit
+	case FinalizeFailed, CommitFinalizeFailed, FinalizeReplicaUpdateFailed:
+		return sealiface.RetryFinalize
+	case ReplicaUpdateFailed, ReleaseSectorKeyFailed:
+		return sealiface.RetryReplicaUpdate
+	case TerminateFailed, RemoveFailed:
+		return sealiface.RetryDefault
+	}
+	return ""
+}
+
+// trackRetries counts consecutive failures of the same class, called on
+// sector state changes
+func trackRetries(state *SectorInfo, events []statemachine.Event) {
+	class := retryClass(state.State, events)
+	switch {
+	case class == "":
+		if state.State == Proving || state.State == Available {
+			state.RetryClass, state.RetryAttempts = "", 0
+		}
+	case class == state.RetryClass:
+		state.RetryAttempts++
+	default:
+		state.RetryClass, state.RetryAttempts = class, 1
+	}
+}
+
+func retryPolicy(cfg sealiface.Config, class string) sealiface.RetryPolicy {
+	if p, ok := cfg.RetryPolicies[class]; ok {
+		return p
+	}
+	if p, ok := cfg.RetryPolicies[sealiface.RetryDefault]; ok {
+		return p
+	}
+	return sealiface.RetryPolicy{InitialBackoff: MinRetryTime}
+}
+
+// retryBackoff returns the delay before retrying after the given consecutive
+// failure (starting at 1)
+func retryBackoff(p sealiface.RetryPolicy, attempt uint64) time.Duration {
+	d := float64(p.InitialBackoff)
+	if attempt > 1 && p.BackoffFactor > 1 {
+		d *= math.Pow(p.BackoffFactor, float64(attempt-1))
+	}
+	if p.MaxBackoff > 0 && d > float64(p.MaxBackoff) {
+		return p.MaxBackoff
+	}
+	if d > math.MaxInt64 {
+		return math.MaxInt64
+	}
+	return time.Duration(d)
+}
+
+// retriesExhausted tells whether a sector is no longer retried after the given
+// consecutive failures
+func retriesExhausted(p sealiface.RetryPolicy, failures uint64) bool {
+	return p.MaxAttempts > 0 && failures >= p.MaxAttempts
+}
+
+// failedCooldown waits before a failed sector is retried, according to the
+// retry policy of its failure class. Once the sector runs out of attempts, an
+// alert is raised and errRetriesExhausted is returned, leaving the sector in
+// the failed state until it's moved manually.
+func (m *Sealing) failedCooldown(ctx statemachine.Context, sector SectorInfo) error {
+	cfg, err := m.getConfig()
+	if err != nil {
+		return err
+	}
+
+	class := sector.RetryClass
+	if class == "" {
+		class = sealiface.RetryDefault
+	}
+	policy := retryPolicy(cfg, class)
+
+	if retriesExhausted(policy, sector.RetryAttempts) {
+		log.Errorw("sector out of retry attempts, needs manual intervention", "sector", sector.SectorNumber, "state", sector.State, "class", class, "failures", sector.RetryAttempts)
+		m.retryAlerts.exhausted(class, sector.SectorNumber)
+		return errRetriesExhausted
+	}
+
+	if len(sector.Log) == 0 {
+		return nil
+	}
+
+	backoff := retryBackoff(policy, sector.RetryAttempts)
+	retryStart := time.Unix(int64(sector.Log[len(sector.Log)-1].Timestamp), 0).Add(backoff)
+	if !time.Now().After(retryStart) {
+		log.Infof("%s(%d), waiting %s before retrying", sector.State, sector.SectorNumber, time.Until(retryStart))
+		select {
+		case <-time.After(time.Until(retryStart)):
+		case <-ctx.Context().Done():
+			return ctx.Context().Err()
+		}
+	}
+
+	return nil
+}
+
+// retryAlerter raises an alert for each retry class with sectors which ran
+// out of retry attempts
+type retryAlerter struct {
+	al *alerting.Alerting
+
+	lk      sync.Mutex
+	types   map[string]alerting.AlertType
+	sectors map[string]map[abi.SectorNumber]struct{}
+}
+
+func newRetryAlerter(al *alerting.Alerting) *retryAlerter {
+	return &retryAlerter{
+		al:      al,
+		types:   map[string]alerting.AlertType{},
+		sectors: map[string]map[abi.SectorNumber]struct{}{},
+	}
+}
+
+func (ra *retryAlerter) exhausted(class string, sn abi.SectorNumber) {
+	if ra == nil || ra.al == nil {
+		return
+	}
+
+	ra.lk.Lock()
+	defer ra.lk.Unlock()
+
+	at, ok := ra.types[class]
+	if !ok {
+		at = ra.al.AddAlertType("sealing", "retry-"+class)
+		ra.types[class] = at
+	}
+	if ra.sectors[class] == nil {
+		ra.sectors[class] = map[abi.SectorNumber]struct{}{}
+	}
+	ra.sectors[class][sn] = struct{}{}
+
+	ra.al.Raise(at, map[string]interface{}{
+		"message": "sectors out of retry attempts",
+		"class":   class,
+		"sectors": ra.sorted(class),
+	})
+}
+
+// resolved is called when a sector is no longer failing in the class
+func (ra *retryAlerter) resolved(class string, sn abi.SectorNumber) {
+	if ra == nil || ra.al == nil {
+		return
+	}
+
+	ra.lk.Lock()
+	defer ra.lk.Unlock()
+
+	if _, ok := ra.sectors[class][sn]; !ok {
+		return
+	}
+	delete(ra.sectors[class], sn)
+
+	if len(ra.sectors[class]) > 0 {
+		return
+	}
+	ra.al.Resolve(ra.types[class], map[string]interface{}{
+		"message": "no sectors out of retry attempts",
+		"class":   class,
+	})
+}
+
+func (ra *retryAlerter) sorted(class string) []abi.SectorNumber {
+	out := make([]abi.SectorNumber, 0, len(ra.sectors[class]))
+	for sn := range ra.sectors[class] {
+		out = append(out, sn)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+	return out
+}
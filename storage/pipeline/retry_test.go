@@ -0,0 +1,96 @@
+package sealing
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-statemachine"
+
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+func TestRetryBackoff(t *testing.T) {
+	p := sealiface.RetryPolicy{
+		InitialBackoff: time.Minute,
+		MaxBackoff:     10 * time.Minute,
+		BackoffFactor:  2,
+	}
+
+	require.Equal(t, time.Minute, retryBackoff(p, 0))
+	require.Equal(t, time.Minute, retryBackoff(p, 1))
+	require.Equal(t, 2*time.Minute, retryBackoff(p, 2))
+	require.Equal(t, 8*time.Minute, retryBackoff(p, 4))
+	require.Equal(t, 10*time.Minute, retryBackoff(p, 5))
+	require.Equal(t, 10*time.Minute, retryBackoff(p, 1000))
+
+	p.MaxBackoff = 0
+	require.Equal(t, time.Duration(1<<63-1), retryBackoff(p, 1000))
+
+	p.BackoffFactor = 0
+	require.Equal(t, time.Minute, retryBackoff(p, 10))
+}
+
+func TestRetryPolicy(t *testing.T) {
+	require.Equal(t, sealiface.RetryPolicy{InitialBackoff: MinRetryTime}, retryPolicy(sealiface.Config{}, sealiface.RetryCommit))
+
+	def := sealiface.RetryPolicy{InitialBackoff: time.Second}
+	commit := sealiface.RetryPolicy{InitialBackoff: time.Hour, MaxAttempts: 3}
+	cfg := sealiface.Config{RetryPolicies: map[string]sealiface.RetryPolicy{
+		sealiface.RetryDefault: def,
+		sealiface.RetryCommit:  commit,
+	}}
+	require.Equal(t, commit, retryPolicy(cfg, sealiface.RetryCommit))
+	require.Equal(t, def, retryPolicy(cfg, sealiface.RetryPreCommit1))
+}
+
+func TestRetriesExhausted(t *testing.T) {
+	p := sealiface.RetryPolicy{MaxAttempts: 3}
+	require.False(t, retriesExhausted(p, 1))
+	require.False(t, retriesExhausted(p, 2))
+	require.True(t, retriesExhausted(p, 3))
+	require.True(t, retriesExhausted(p, 4))
+
+	require.False(t, retriesExhausted(sealiface.RetryPolicy{}, 1000))
+}
+
+func TestTrackRetries(t *testing.T) {
+	si := &SectorInfo{}
+	move := func(st SectorState, events ...statemachine.Event) {
+		si.State = st
+		trackRetries(si, events)
+	}
+
+	move(PreCommit1)
+	require.Equal(t, "", si.RetryClass)
+
+	move(SealPreCommit1Failed, statemachine.Event{User: SectorSealPreCommit1Failed{xerrors.New("pc1")}})
+	require.Equal(t, sealiface.RetryPreCommit1, si.RetryClass)
+	require.Equal(t, uint64(1), si.RetryAttempts)
+
+	move(PreCommit1)
+	move(SealPreCommit1Failed, statemachine.Event{User: SectorSealPreCommit1Failed{xerrors.New("pc1")}})
+	require.Equal(t, uint64(2), si.RetryAttempts)
+
+	// a different class starts over
+	move(PreCommit1)
+	move(SealPreCommit1Failed, statemachine.Event{User: SectorSealPreCommit1Failed{xerrors.Errorf("ticket expired: %w", &ErrExpiredTicket{xerrors.New("expired")})}})
+	require.Equal(t, sealiface.RetryTicketExpired, si.RetryClass)
+	require.Equal(t, uint64(1), si.RetryAttempts)
+
+	move(PreCommit1)
+	move(PreCommit2)
+	move(PreCommitting)
+	move(PreCommitFailed)
+	require.Equal(t, sealiface.RetryPreCommit, si.RetryClass)
+
+	move(PreCommitting)
+	move(PreCommitWait)
+	require.Equal(t, sealiface.RetryPreCommit, si.RetryClass) // kept until the sector is done
+
+	move(Proving)
+	require.Equal(t, "", si.RetryClass)
+	require.Equal(t, uint64(0), si.RetryAttempts)
+}
@@ -3,6 +3,7 @@ package sealiface
 import (
 	"time"
 
+	"github.com/filecoin-project/go-bitfield"
 	"github.com/filecoin-project/go-state-types/abi"
 )
 
@@ -63,4 +64,75 @@ type Config struct {
 	TerminateBatchMax  uint64
 	TerminateBatchMin  uint64
 	TerminateBatchWait time.Duration
+
+	// client placement rules, the first one matching a deal client applies
+	ClientPlacement []ClientPlacementRule
+
+	// retry class -> policy
+	RetryPolicies map[string]RetryPolicy
+
+	// policies deciding whether unsealed copies of deal data are kept, the first
+	// matching one applies
+	UnsealedCopyPolicies []UnsealedCopyPolicy
+
+	RemoveExpiredSectors      bool
+	RemoveExpiredSectorsDelay time.Duration
+}
+
+// ClientPlacementRule pins the deals of a client to storage paths in the
+// listed groups.
+type ClientPlacementRule struct {
+	// client address, ID or robust
+	Client string
+	Groups []string
+}
+
+// UnsealedCopyPolicy decides whether the unsealed copies of matching deals are
+// kept after sealing.
+type UnsealedCopyPolicy struct {
+	// client address, empty for any client
+	Client string
+	// nil for both verified and unverified deals
+	Verified *bool
+	// nil for any sector
+	Sectors *bitfield.BitField
+	// 0 for any piece, otherwise the minimum retrieval score of matching pieces
+	MinRetrievalScore float64
+
+	Keep bool
+}
+
+// Retry classes group failed sector states by the kind of error which caused
+// them, so that each can be retried differently.
+const (
+	RetryDefault       = "default"
+	RetryPreCommit1    = "precommit1"
+	RetryTicketExpired = "ticket-expired"
+	RetryPreCommit2    = "precommit2"
+	RetryPreCommit     = "precommit"
+	RetryComputeProof  = "compute-proof"
+	RetryCommit        = "commit"
+	RetryFinalize      = "finalize"
+	RetryReplicaUpdate = "replica-update"
+)
+
+var RetryClasses = []string{
+	RetryDefault,
+	RetryPreCommit1,
+	RetryTicketExpired,
+	RetryPreCommit2,
+	RetryPreCommit,
+	RetryComputeProof,
+	RetryCommit,
+	RetryFinalize,
+	RetryReplicaUpdate,
+}
+
+type RetryPolicy struct {
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration // 0 = no limit
+	BackoffFactor  float64       // <= 1 = constant backoff
+
+	// consecutive failures after which the sector isn't retried automatically, 0 = no limit
+	MaxAttempts uint64
 }
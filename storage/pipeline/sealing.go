@@ -28,6 +28,7 @@ import (
 	"github.com/filecoin-project/lotus/chain/events"
 	"github.com/filecoin-project/lotus/chain/types"
 	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/journal/alerting"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/node/modules/dtypes"
 	"github.com/filecoin-project/lotus/storage/ctladdr"
@@ -85,6 +86,11 @@ type Events interface {
 	ChainAt(ctx context.Context, hnd events.HeightHandler, rev events.RevertHandler, confidence int, h abi.ChainEpoch) error
 }
 
+// PieceHotness provides the retrieval score of sector pieces.
+type PieceHotness interface {
+	PieceScore(ctx context.Context, sector abi.SectorID, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize) (float64, error)
+}
+
 type AddressSelector interface {
 	AddressFor(ctx context.Context, a ctladdr.NodeApi, mi api.MinerInfo, use api.AddrUse, goodFunds, minFunds abi.TokenAmount) (address.Address, abi.TokenAmount, error)
 }
@@ -118,19 +124,23 @@ type Sealing struct {
 
 	journal        journal.Journal
 	sealingEvtType journal.EventType
+	retryAlerts    *retryAlerter
 	notifee        SectorStateNotifee
 	addrSel        AddressSelector
 
-	stats SectorStats
+	stats         SectorStats
+	pipelineStats *pipelineStats
 
 	terminator  *TerminateBatcher
 	precommiter *PreCommitBatcher
 	commiter    *CommitBatcher
+	janitor     *SectorJanitor
 
 	sclk     sync.Mutex
 	legacySc *storedcounter.StoredCounter
 
 	getConfig dtypes.GetSealingConfigFunc
+	hotness   PieceHotness
 }
 
 type openSector struct {
@@ -138,6 +148,7 @@ type openSector struct {
 	lastDealEnd abi.ChainEpoch
 	number      abi.SectorNumber
 	ccUpdate    bool
+	placement   []string
 
 	maybeAccept func(cid.Cid) error // called with inputLk
 }
@@ -155,6 +166,11 @@ func (o *openSector) checkDealAssignable(piece *pendingPiece, expF expFn) (bool,
 		"update", o.ccUpdate,
 	)
 
+	if !samePlacement(o.placement, piece.placement) {
+		log.Debugw("deal not assignable to sector", "reason", "placement mismatch", "sectorPlacement", o.placement, "dealPlacement", piece.placement)
+		return false, nil
+	}
+
 	// if there are deals assigned, check that no assigned deal expires after termMax
 	if o.lastDealEnd > piece.claimTerms.claimTermEnd {
 		log.Debugw("deal not assignable to sector", "reason", "term end beyond last assigned deal end")
@@ -209,13 +225,16 @@ type pendingPiece struct {
 
 	claimTerms pieceClaimBounds
 
+	// normalized storage path groups the piece must be stored in
+	placement []string
+
 	data storiface.Data
 
 	assigned bool // assigned to a sector?
 	accepted func(abi.SectorNumber, abi.UnpaddedPieceSize, error)
 }
 
-func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events Events, maddr address.Address, ds datastore.Batching, sealer sealer.SectorManager, verif storiface.Verifier, prov storiface.Prover, pcp PreCommitPolicy, gc dtypes.GetSealingConfigFunc, journal journal.Journal, addrSel AddressSelector) *Sealing {
+func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events Events, maddr address.Address, ds datastore.Batching, sealer sealer.SectorManager, verif storiface.Verifier, prov storiface.Prover, pcp PreCommitPolicy, gc dtypes.GetSealingConfigFunc, journal journal.Journal, al *alerting.Alerting, addrSel AddressSelector, hot PieceHotness) *Sealing {
 	s := &Sealing{
 		Api:      api,
 		DealInfo: &CurrentDealInfoManager{api},
@@ -239,6 +258,7 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 
 		journal:        journal,
 		sealingEvtType: journal.RegisterEventType("storage", "sealing_states"),
+		retryAlerts:    newRetryAlerter(al),
 
 		addrSel: addrSel,
 
@@ -247,6 +267,7 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 		commiter:    NewCommitBatcher(mctx, maddr, api, addrSel, fc, gc, prov),
 
 		getConfig: gc,
+		hotness:   hot,
 
 		legacySc: storedcounter.New(ds, datastore.NewKey(StorageCounterDSPrefix)),
 
@@ -254,6 +275,7 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 			bySector: map[abi.SectorID]SectorState{},
 			byState:  map[SectorState]int64{},
 		},
+		pipelineStats: newPipelineStats(mctx, namespace.Wrap(ds, datastore.NewKey(PipelineStatsDSPrefix))),
 	}
 
 	s.notifee = func(before, after SectorInfo) {
@@ -268,6 +290,10 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 		})
 	}
 
+	s.AddStateNotifee(s.pipelineStats.onStateChange)
+
+	s.janitor = NewSectorJanitor(mctx, maddr, api, gc, s.ListSectors, s.RemoveSector, journal)
+
 	s.startupWait.Add(1)
 
 	s.sectors = statemachine.New(namespace.Wrap(ds, datastore.NewKey(SectorStorePrefix)), s, SectorInfo{})
@@ -275,6 +301,18 @@ func New(mctx context.Context, api SealingAPI, fc config.MinerFeeConfig, events
 	return s
 }
 
+// AddStateNotifee registers a notifee called on sector state transitions, in
+// addition to the journal. It must be called before Run.
+func (m *Sealing) AddStateNotifee(n SectorStateNotifee) {
+	prev := m.notifee
+	m.notifee = func(before, after SectorInfo) {
+		if prev != nil {
+			prev(before, after)
+		}
+		n(before, after)
+	}
+}
+
 func (m *Sealing) Run(ctx context.Context) {
 	if err := m.restartSectors(ctx); err != nil {
 		log.Errorf("failed load sector states: %+v", err)
@@ -286,6 +324,10 @@ func (m *Sealing) Stop(ctx context.Context) error {
 		return err
 	}
 
+	if err := m.janitor.Stop(ctx); err != nil {
+		return err
+	}
+
 	if err := m.sectors.Stop(ctx); err != nil {
 		return err
 	}
@@ -0,0 +1,224 @@
+package sealing
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/build"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/node/modules/dtypes"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+// JanitorInterval is how often the janitor looks for sectors which expired or
+// were terminated on chain
+var JanitorInterval = 30 * time.Minute
+
+type SectorJanitorApi interface {
+	ChainHead(ctx context.Context) (*types.TipSet, error)
+	StateNetworkVersion(ctx context.Context, tsk types.TipSetKey) (network.Version, error)
+	StateMinerDeadlines(context.Context, address.Address, types.TipSetKey) ([]api.Deadline, error)
+	StateMinerPartitions(ctx context.Context, m address.Address, dlIdx uint64, tsk types.TipSetKey) ([]api.Partition, error)
+}
+
+// SectorRemovalEvt is journaled for each sector removed by the janitor.
+type SectorRemovalEvt struct {
+	SectorNumber abi.SectorNumber
+	State        SectorState
+	// GoneSince is the first epoch the sector was seen missing on chain
+	GoneSince abi.ChainEpoch
+	Height    abi.ChainEpoch
+}
+
+// SectorJanitor removes the data of sectors which expired or were terminated on
+// chain, once they have been missing from the live sectors of the miner for
+// the configured delay.
+type SectorJanitor struct {
+	api       SectorJanitorApi
+	maddr     address.Address
+	mctx      context.Context
+	getConfig dtypes.GetSealingConfigFunc
+
+	list   func() ([]SectorInfo, error)
+	remove func(context.Context, abi.SectorNumber) error
+
+	journal journal.Journal
+	evtType journal.EventType
+
+	// sector -> first epoch it was seen missing on chain, only accessed by run
+	gone map[abi.SectorNumber]abi.ChainEpoch
+
+	stop, stopped chan struct{}
+}
+
+func NewSectorJanitor(mctx context.Context, maddr address.Address, api SectorJanitorApi, getConfig dtypes.GetSealingConfigFunc, list func() ([]SectorInfo, error), remove func(context.Context, abi.SectorNumber) error, j journal.Journal) *SectorJanitor {
+	sj := &SectorJanitor{
+		api:       api,
+		maddr:     maddr,
+		mctx:      mctx,
+		getConfig: getConfig,
+
+		list:   list,
+		remove: remove,
+
+		journal: j,
+		evtType: j.RegisterEventType("storage", "sector_removal"),
+
+		gone: map[abi.SectorNumber]abi.ChainEpoch{},
+
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+
+	go sj.run()
+
+	return sj
+}
+
+func (j *SectorJanitor) run() {
+	defer close(j.stopped)
+
+	for {
+		select {
+		case <-j.stop:
+			return
+		case <-time.After(JanitorInterval):
+		}
+
+		cfg, err := j.getConfig()
+		if err != nil {
+			log.Warnw("SectorJanitor getconfig error", "error", err)
+			continue
+		}
+		if !cfg.RemoveExpiredSectors {
+			j.gone = map[abi.SectorNumber]abi.ChainEpoch{}
+			continue
+		}
+
+		if err := j.cleanup(cfg); err != nil {
+			log.Warnw("SectorJanitor cleanup error", "error", err)
+		}
+	}
+}
+
+func (j *SectorJanitor) Stop(ctx context.Context) error {
+	close(j.stop)
+
+	select {
+	case <-j.stopped:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// cleanup tracks the proving sectors missing from the live sectors of the miner
+// and removes those missing for longer than the delay.
+func (j *SectorJanitor) cleanup(cfg sealiface.Config) error {
+	head, err := j.api.ChainHead(j.mctx)
+	if err != nil {
+		return xerrors.Errorf("getting chain head: %w", err)
+	}
+
+	nv, err := j.api.StateNetworkVersion(j.mctx, head.Key())
+	if err != nil {
+		return xerrors.Errorf("getting network version: %w", err)
+	}
+
+	live, err := j.liveSectors(head.Key())
+	if err != nil {
+		return err
+	}
+
+	sectors, err := j.list()
+	if err != nil {
+		return xerrors.Errorf("listing sectors: %w", err)
+	}
+
+	// WinningPoSt challenges sectors from the lookback state, which can't be
+	// removed until it doesn't include them anymore
+	delay := abi.ChainEpoch(cfg.RemoveExpiredSectorsDelay / (time.Duration(build.BlockDelaySecs) * time.Second))
+	if lookback := policy.GetWinningPoStSectorSetLookback(nv); delay < lookback {
+		delay = lookback
+	}
+
+	missing := map[abi.SectorNumber]struct{}{}
+	for _, si := range sectors {
+		if si.State != Proving && si.State != Available {
+			continue
+		}
+
+		isLive, err := live.IsSet(uint64(si.SectorNumber))
+		if err != nil {
+			return xerrors.Errorf("checking live sectors: %w", err)
+		}
+		if isLive {
+			continue
+		}
+
+		missing[si.SectorNumber] = struct{}{}
+		since, ok := j.gone[si.SectorNumber]
+		if !ok {
+			log.Infow("sector expired or was terminated on chain, scheduling removal", "sector", si.SectorNumber, "height", head.Height(), "removeAfter", head.Height()+delay)
+			j.gone[si.SectorNumber] = head.Height()
+			continue
+		}
+		if head.Height()-since < delay {
+			continue
+		}
+
+		if err := j.remove(j.mctx, si.SectorNumber); err != nil {
+			log.Errorw("removing expired sector", "sector", si.SectorNumber, "error", err)
+			continue
+		}
+
+		log.Infow("removing data of expired sector", "sector", si.SectorNumber, "goneSince", since)
+		j.journal.RecordEvent(j.evtType, func() interface{} {
+			return SectorRemovalEvt{
+				SectorNumber: si.SectorNumber,
+				State:        si.State,
+				GoneSince:    since,
+				Height:       head.Height(),
+			}
+		})
+	}
+
+	// forget sectors which were removed, or are back on chain after a reorg
+	for sn := range j.gone {
+		if _, ok := missing[sn]; !ok {
+			delete(j.gone, sn)
+		}
+	}
+
+	return nil
+}
+
+func (j *SectorJanitor) liveSectors(tsk types.TipSetKey) (bitfield.BitField, error) {
+	deadlines, err := j.api.StateMinerDeadlines(j.mctx, j.maddr, tsk)
+	if err != nil {
+		return bitfield.BitField{}, xerrors.Errorf("getting deadlines: %w", err)
+	}
+
+	var parts []bitfield.BitField
+	for dlIdx := range deadlines {
+		partitions, err := j.api.StateMinerPartitions(j.mctx, j.maddr, uint64(dlIdx), tsk)
+		if err != nil {
+			return bitfield.BitField{}, xerrors.Errorf("getting partitions of deadline %d: %w", dlIdx, err)
+		}
+		for _, part := range partitions {
+			parts = append(parts, part.LiveSectors)
+		}
+	}
+
+	return bitfield.MultiMerge(parts...)
+}
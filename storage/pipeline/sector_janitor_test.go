@@ -0,0 +1,111 @@
+package sealing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/network"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/actors/policy"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/chain/types/mock"
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+)
+
+type janitorTestApi struct {
+	head *types.TipSet
+	live []uint64
+}
+
+func (a *janitorTestApi) ChainHead(ctx context.Context) (*types.TipSet, error) {
+	return a.head, nil
+}
+
+func (a *janitorTestApi) StateNetworkVersion(ctx context.Context, tsk types.TipSetKey) (network.Version, error) {
+	return network.Version18, nil
+}
+
+func (a *janitorTestApi) StateMinerDeadlines(context.Context, address.Address, types.TipSetKey) ([]api.Deadline, error) {
+	return make([]api.Deadline, 2), nil
+}
+
+func (a *janitorTestApi) StateMinerPartitions(ctx context.Context, m address.Address, dlIdx uint64, tsk types.TipSetKey) ([]api.Partition, error) {
+	if dlIdx != 1 {
+		return nil, nil
+	}
+	return []api.Partition{{LiveSectors: bitfield.NewFromSet(a.live)}}, nil
+}
+
+func TestSectorJanitor(t *testing.T) {
+	ctx := context.Background()
+	maddr, err := address.NewIDAddress(1000)
+	require.NoError(t, err)
+
+	tapi := &janitorTestApi{live: []uint64{1}}
+	setHeight := func(h abi.ChainEpoch) {
+		blk := mock.MkBlock(nil, 0, 0)
+		blk.Height = h
+		tapi.head = mock.TipSet(blk)
+	}
+
+	sectors := []SectorInfo{
+		{SectorNumber: 1, State: Proving},
+		{SectorNumber: 2, State: Proving},
+		{SectorNumber: 3, State: PreCommit1},
+		{SectorNumber: 4, State: Available},
+	}
+	var removed []abi.SectorNumber
+	failRemove := map[abi.SectorNumber]bool{4: true}
+
+	cfg := sealiface.Config{
+		RemoveExpiredSectors:      true,
+		RemoveExpiredSectorsDelay: time.Hour,
+	}
+	getConfig := func() (sealiface.Config, error) { return cfg, nil }
+
+	j := NewSectorJanitor(ctx, maddr, tapi, getConfig, func() ([]SectorInfo, error) {
+		return sectors, nil
+	}, func(ctx context.Context, sn abi.SectorNumber) error {
+		if failRemove[sn] {
+			return xerrors.New("remove failed")
+		}
+		removed = append(removed, sn)
+		return nil
+	}, journal.NilJournal())
+	defer j.Stop(ctx) //nolint:errcheck
+
+	// the delay is at least the winning post lookback
+	lookback := policy.GetWinningPoStSectorSetLookback(network.Version18)
+
+	setHeight(1000)
+	require.NoError(t, j.cleanup(cfg))
+	require.Empty(t, removed)
+	require.Equal(t, map[abi.SectorNumber]abi.ChainEpoch{2: 1000, 4: 1000}, j.gone)
+
+	setHeight(1000 + lookback - 1)
+	require.NoError(t, j.cleanup(cfg))
+	require.Empty(t, removed)
+
+	setHeight(1000 + lookback)
+	require.NoError(t, j.cleanup(cfg))
+	require.Equal(t, []abi.SectorNumber{2}, removed)
+	// failed removals are retried
+	require.Contains(t, j.gone, abi.SectorNumber(4))
+
+	// removed sectors are forgotten once they leave the proving states, and
+	// sectors which reappear on chain are forgotten too
+	sectors[1].State = Removing
+	tapi.live = []uint64{1, 4}
+	require.NoError(t, j.cleanup(cfg))
+	require.Empty(t, j.gone)
+	require.Equal(t, []abi.SectorNumber{2}, removed)
+}
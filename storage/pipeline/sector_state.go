@@ -66,6 +66,7 @@ var ExistSectorStateList = map[SectorState]struct{}{
 	FinalizeReplicaUpdateFailed: {},
 	AbortUpgrade:                {},
 	ReceiveSector:               {},
+	ReceiveSectorFailed:         {},
 }
 
 // cmd/lotus-miner/info.go defines CLI colors corresponding to these states
@@ -118,7 +119,8 @@ const (
 	ReleaseSectorKey      SectorState = "ReleaseSectorKey"
 
 	// external import
-	ReceiveSector SectorState = "ReceiveSector"
+	ReceiveSector       SectorState = "ReceiveSector"
+	ReceiveSectorFailed SectorState = "ReceiveSectorFailed" // received data didn't pass validation
 
 	// error modes
 	FailedUnrecoverable  SectorState = "FailedUnrecoverable"
@@ -2,7 +2,6 @@ package sealing
 
 import (
 	"context"
-	"time"
 
 	"github.com/hashicorp/go-multierror"
 	"golang.org/x/xerrors"
@@ -20,24 +19,6 @@ import (
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
-var MinRetryTime = 1 * time.Minute
-
-func failedCooldown(ctx statemachine.Context, sector SectorInfo) error {
-	// TODO: Exponential backoff when we see consecutive failures
-
-	retryStart := time.Unix(int64(sector.Log[len(sector.Log)-1].Timestamp), 0).Add(MinRetryTime)
-	if len(sector.Log) > 0 && !time.Now().After(retryStart) {
-		log.Infof("%s(%d), waiting %s before retrying", sector.State, sector.SectorNumber, time.Until(retryStart))
-		select {
-		case <-time.After(time.Until(retryStart)):
-		case <-ctx.Context().Done():
-			return ctx.Context().Err()
-		}
-	}
-
-	return nil
-}
-
 func (m *Sealing) checkPreCommitted(ctx statemachine.Context, sector SectorInfo) (*miner.SectorPreCommitOnChainInfo, bool) {
 	ts, err := m.Api.ChainHead(ctx.Context())
 	if err != nil {
@@ -55,7 +36,7 @@ func (m *Sealing) checkPreCommitted(ctx statemachine.Context, sector SectorInfo)
 }
 
 func (m *Sealing) handleSealPrecommit1Failed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -63,7 +44,7 @@ func (m *Sealing) handleSealPrecommit1Failed(ctx statemachine.Context, sector Se
 }
 
 func (m *Sealing) handleSealPrecommit2Failed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -85,7 +66,7 @@ func (m *Sealing) handlePreCommitFailed(ctx statemachine.Context, sector SectorI
 		mw, err := m.Api.StateSearchMsg(ctx.Context(), ts.Key(), *sector.PreCommitMessage, api.LookbackNoLimit, true)
 		if err != nil {
 			// API error
-			if err := failedCooldown(ctx, sector); err != nil {
+			if err := m.failedCooldown(ctx, sector); err != nil {
 				return err
 			}
 
@@ -153,7 +134,7 @@ func (m *Sealing) handlePreCommitFailed(ctx statemachine.Context, sector SectorI
 		// TODO: we could compare more things, but I don't think we really need to
 		//  CommR tells us that CommD (and CommPs), and the ticket are all matching
 
-		if err := failedCooldown(ctx, sector); err != nil {
+		if err := m.failedCooldown(ctx, sector); err != nil {
 			return err
 		}
 
@@ -164,7 +145,7 @@ func (m *Sealing) handlePreCommitFailed(ctx statemachine.Context, sector SectorI
 		log.Warn("retrying precommit even though the message failed to apply")
 	}
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -174,7 +155,7 @@ func (m *Sealing) handlePreCommitFailed(ctx statemachine.Context, sector SectorI
 func (m *Sealing) handleComputeProofFailed(ctx statemachine.Context, sector SectorInfo) error {
 	// TODO: Check sector files
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -186,7 +167,7 @@ func (m *Sealing) handleComputeProofFailed(ctx statemachine.Context, sector Sect
 }
 
 func (m *Sealing) handleRemoteCommitFailed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -198,7 +179,7 @@ func (m *Sealing) handleRemoteCommitFailed(ctx statemachine.Context, sector Sect
 }
 
 func (m *Sealing) handleSubmitReplicaUpdateFailed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -269,7 +250,7 @@ func (m *Sealing) handleSubmitReplicaUpdateFailed(ctx statemachine.Context, sect
 func (m *Sealing) handleReleaseSectorKeyFailed(ctx statemachine.Context, sector SectorInfo) error {
 	// not much we can do, wait for a bit and try again
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -287,7 +268,7 @@ func (m *Sealing) handleCommitFailed(ctx statemachine.Context, sector SectorInfo
 		mw, err := m.Api.StateSearchMsg(ctx.Context(), ts.Key(), *sector.CommitMessage, api.LookbackNoLimit, true)
 		if err != nil {
 			// API error
-			if err := failedCooldown(ctx, sector); err != nil {
+			if err := m.failedCooldown(ctx, sector); err != nil {
 				return err
 			}
 
@@ -320,7 +301,7 @@ func (m *Sealing) handleCommitFailed(ctx statemachine.Context, sector SectorInfo
 			log.Errorf("seed changed, will retry: %+v", err)
 			return ctx.Send(SectorRetryWaitSeed{})
 		case *ErrInvalidProof:
-			if err := failedCooldown(ctx, sector); err != nil {
+			if err := m.failedCooldown(ctx, sector); err != nil {
 				return err
 			}
 
@@ -340,7 +321,7 @@ func (m *Sealing) handleCommitFailed(ctx statemachine.Context, sector SectorInfo
 		case *ErrExpiredDeals:
 			return ctx.Send(SectorDealsExpired{xerrors.Errorf("sector deals expired: %w", err)})
 		case *ErrCommitWaitFailed:
-			if err := failedCooldown(ctx, sector); err != nil {
+			if err := m.failedCooldown(ctx, sector); err != nil {
 				return err
 			}
 
@@ -352,7 +333,7 @@ func (m *Sealing) handleCommitFailed(ctx statemachine.Context, sector SectorInfo
 
 	// TODO: Check sector files
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -362,7 +343,7 @@ func (m *Sealing) handleCommitFailed(ctx statemachine.Context, sector SectorInfo
 func (m *Sealing) handleFinalizeFailed(ctx statemachine.Context, sector SectorInfo) error {
 	// TODO: Check sector files
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -370,7 +351,7 @@ func (m *Sealing) handleFinalizeFailed(ctx statemachine.Context, sector SectorIn
 }
 
 func (m *Sealing) handleRemoveFailed(ctx statemachine.Context, sector SectorInfo) error {
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
@@ -386,7 +367,7 @@ func (m *Sealing) handleTerminateFailed(ctx statemachine.Context, sector SectorI
 		return nil // pause the fsm, needs manual user action
 	}
 
-	if err := failedCooldown(ctx, sector); err != nil {
+	if err := m.failedCooldown(ctx, sector); err != nil {
 		return err
 	}
 
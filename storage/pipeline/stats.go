@@ -100,3 +100,15 @@ func (ss *SectorStats) curStaging() uint64 {
 
 	return ss.curStagingLocked()
 }
+
+// return the number of sectors in each state
+func (ss *SectorStats) countByState() map[SectorState]int64 {
+	ss.lk.Lock()
+	defer ss.lk.Unlock()
+
+	out := make(map[SectorState]int64, len(ss.byState))
+	for st, n := range ss.byState {
+		out[st] = n
+	}
+	return out
+}
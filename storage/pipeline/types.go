@@ -51,6 +51,10 @@ type SectorInfo struct {
 	CreationTime int64 // unix seconds
 	Pieces       []api.SectorPiece
 
+	// PlacementGroups are the storage path groups sector files are restricted
+	// to; only deals with the same placement are packed into the sector
+	PlacementGroups []string
+
 	// PreCommit1
 	TicketValue   abi.SealRandomness
 	TicketEpoch   abi.ChainEpoch
@@ -102,6 +106,10 @@ type SectorInfo struct {
 	RemoteSealingDoneEndpoint string
 	RemoteDataFinalized       bool
 
+	// Retries
+	RetryClass    string
+	RetryAttempts uint64 // consecutive failures in RetryClass
+
 	// Debug
 	LastErr string
 
@@ -156,9 +164,9 @@ func (t *SectorInfo) sealingCtx(ctx context.Context) context.Context {
 	return ctx
 }
 
-// Returns list of offset/length tuples of sector data ranges which clients
-// requested to keep unsealed
-func (t *SectorInfo) keepUnsealedRanges(pieces []api.SectorPiece, invert, alwaysKeep bool) []storiface.Range {
+// Returns list of offset/length tuples of sector data ranges of deals for which
+// keep returns true
+func (t *SectorInfo) keepUnsealedRanges(pieces []api.SectorPiece, invert bool, keep func(*api.PieceDealInfo, storiface.Range) bool) []storiface.Range {
 	var out []storiface.Range
 
 	var at abi.UnpaddedPieceSize
@@ -170,16 +178,15 @@ func (t *SectorInfo) keepUnsealedRanges(pieces []api.SectorPiece, invert, always
 			continue
 		}
 
-		keep := piece.DealInfo.KeepUnsealed || alwaysKeep
-
-		if keep == invert {
+		r := storiface.Range{
+			Offset: at - psize,
+			Size:   psize,
+		}
+		if keep(piece.DealInfo, r) == invert {
 			continue
 		}
 
-		out = append(out, storiface.Range{
-			Offset: at - psize,
-			Size:   psize,
-		})
+		out = append(out, r)
 	}
 
 	return out
@@ -0,0 +1,101 @@
+package sealing
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/chain/types"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// keepUnsealedDeal decides whether the unsealed copy of a deal is kept after
+// sealing. The first matching policy from the config applies, without one the
+// copy is kept when the client requested it or AlwaysKeepUnsealedCopy is set.
+// The copy is kept when a policy needs a retrieval score which isn't known yet.
+func keepUnsealedDeal(cfg sealiface.Config, sn abi.SectorNumber, deal *api.PieceDealInfo, clientMatches func(rule string) bool, score func() (float64, bool)) bool {
+	for _, p := range cfg.UnsealedCopyPolicies {
+		if p.Sectors != nil {
+			set, err := p.Sectors.IsSet(uint64(sn))
+			if err != nil || !set {
+				continue
+			}
+		}
+		if p.Verified != nil && (deal.DealProposal == nil || deal.DealProposal.VerifiedDeal != *p.Verified) {
+			continue
+		}
+		if p.Client != "" && (deal.DealProposal == nil || !clientMatches(p.Client)) {
+			continue
+		}
+		if p.MinRetrievalScore > 0 {
+			s, known := score()
+			if !known {
+				return true
+			}
+			if s < p.MinRetrievalScore {
+				continue
+			}
+		}
+
+		return p.Keep
+	}
+
+	return deal.KeepUnsealed || cfg.AlwaysKeepUnsealedCopy
+}
+
+// unsealedRanges returns the ranges of sector data which are kept unsealed
+// after sealing. The pieces of sectors being finalized can't have been read for
+// retrievals yet, so without scored set the copies matched by MinRetrievalScore
+// policies are kept, for SectorsReconcileUnsealed to apply them later.
+func (m *Sealing) unsealedRanges(ctx context.Context, cfg sealiface.Config, sector SectorInfo, scored bool) []storiface.Range {
+	return sector.keepUnsealedRanges(sector.Pieces, false, func(deal *api.PieceDealInfo, r storiface.Range) bool {
+		var clientID address.Address
+
+		clientMatches := func(rule string) bool {
+			client := deal.DealProposal.Client
+			if clientID == address.Undef {
+				id, err := m.Api.StateLookupID(ctx, client, types.EmptyTSK)
+				if err != nil {
+					log.Warnw("looking up deal client ID", "sector", sector.SectorNumber, "client", client, "error", err)
+					id = client
+				}
+				clientID = id
+			}
+
+			return m.clientMatches(ctx, rule, client, clientID, types.EmptyTSK)
+		}
+
+		score := func() (float64, bool) {
+			if !scored {
+				return 0, false
+			}
+			if m.hotness == nil {
+				return 0, true
+			}
+			s, err := m.hotness.PieceScore(ctx, m.minerSectorID(sector.SectorNumber), storiface.UnpaddedByteIndex(r.Offset), r.Size)
+			if err != nil {
+				log.Warnw("getting piece retrieval score", "sector", sector.SectorNumber, "offset", r.Offset, "error", err)
+				return 0, false
+			}
+			return s, true
+		}
+
+		return keepUnsealedDeal(cfg, sector.SectorNumber, deal, clientMatches, score)
+	})
+}
+
+// UnsealedRanges returns the ranges of sector data which the current config
+// keeps unsealed.
+func (m *Sealing) UnsealedRanges(ctx context.Context, sector SectorInfo) ([]storiface.Range, error) {
+	cfg, err := m.getConfig()
+	if err != nil {
+		return nil, xerrors.Errorf("getting sealing config: %w", err)
+	}
+
+	return m.unsealedRanges(ctx, cfg, sector, true), nil
+}
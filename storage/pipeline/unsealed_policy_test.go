@@ -0,0 +1,108 @@
+package sealing
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-address"
+	"github.com/filecoin-project/go-bitfield"
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/builtin/v9/market"
+
+	"github.com/filecoin-project/lotus/api"
+	"github.com/filecoin-project/lotus/storage/pipeline/sealiface"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestKeepUnsealedDeal(t *testing.T) {
+	client, err := address.NewIDAddress(1001)
+	require.NoError(t, err)
+	other, err := address.NewIDAddress(1002)
+	require.NoError(t, err)
+
+	matches := func(deal *api.PieceDealInfo) func(string) bool {
+		return func(rule string) bool {
+			return rule == deal.DealProposal.Client.String()
+		}
+	}
+	noScore := func() (float64, bool) { return 0, true }
+	verified, unverified := true, false
+	sectors := bitfield.NewFromSet([]uint64{5})
+
+	cfg := sealiface.Config{
+		AlwaysKeepUnsealedCopy: true,
+		UnsealedCopyPolicies: []sealiface.UnsealedCopyPolicy{
+			{Sectors: &sectors, Keep: true},
+			{Client: client.String(), Keep: false},
+			{Verified: &unverified, Keep: false},
+		},
+	}
+
+	deal := func(c address.Address, verified bool) *api.PieceDealInfo {
+		return &api.PieceDealInfo{DealProposal: &market.DealProposal{Client: c, VerifiedDeal: verified}}
+	}
+
+	for _, tc := range []struct {
+		name   string
+		sector abi.SectorNumber
+		deal   *api.PieceDealInfo
+		keep   bool
+	}{
+		{"sector policy", 5, deal(client, false), true},
+		{"client policy", 1, deal(client, true), false},
+		{"unverified policy", 1, deal(other, false), false},
+		{"no matching policy", 1, deal(other, true), true},
+		{"no proposal", 1, &api.PieceDealInfo{}, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.keep, keepUnsealedDeal(cfg, tc.sector, tc.deal, matches(tc.deal), noScore))
+		})
+	}
+
+	// without policies the client request or the global setting applies
+	cfg = sealiface.Config{}
+	require.False(t, keepUnsealedDeal(cfg, 1, deal(client, true), nil, noScore))
+	require.True(t, keepUnsealedDeal(cfg, 1, &api.PieceDealInfo{KeepUnsealed: true}, nil, noScore))
+
+	cfg.UnsealedCopyPolicies = []sealiface.UnsealedCopyPolicy{{Verified: &verified, Keep: true}}
+	require.True(t, keepUnsealedDeal(cfg, 1, deal(client, true), nil, noScore))
+
+	// hot pieces are kept, cold ones discarded
+	cfg.UnsealedCopyPolicies = []sealiface.UnsealedCopyPolicy{
+		{MinRetrievalScore: 2.5, Keep: true},
+		{Keep: false},
+	}
+	hot := func() (float64, bool) { return 3, true }
+	require.True(t, keepUnsealedDeal(cfg, 1, deal(client, true), nil, hot))
+	require.False(t, keepUnsealedDeal(cfg, 1, deal(client, true), nil, noScore))
+
+	// copies are kept until the score is known
+	unknown := func() (float64, bool) { return 0, false }
+	require.True(t, keepUnsealedDeal(cfg, 1, deal(client, true), nil, unknown))
+}
+
+func TestKeepUnsealedRanges(t *testing.T) {
+	piece := func(size abi.PaddedPieceSize, deal *api.PieceDealInfo) api.SectorPiece {
+		return api.SectorPiece{Piece: abi.PieceInfo{Size: size}, DealInfo: deal}
+	}
+	keepDeal := &api.PieceDealInfo{KeepUnsealed: true}
+	dropDeal := &api.PieceDealInfo{}
+
+	si := SectorInfo{}
+	pieces := []api.SectorPiece{
+		piece(1024, keepDeal),
+		piece(1024, nil),
+		piece(2048, dropDeal),
+		piece(1024, keepDeal),
+	}
+	keep := func(deal *api.PieceDealInfo, _ storiface.Range) bool { return deal.KeepUnsealed }
+
+	require.Equal(t, []storiface.Range{
+		{Offset: 0, Size: 1016},
+		{Offset: 4064, Size: 1016},
+	}, si.keepUnsealedRanges(pieces, false, keep))
+	require.Equal(t, []storiface.Range{
+		{Offset: 2032, Size: 2032},
+	}, si.keepUnsealedRanges(pieces, true, keep))
+}
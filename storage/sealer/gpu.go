@@ -0,0 +1,101 @@
+package sealer
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"golang.org/x/xerrors"
+
+	ffi "github.com/filecoin-project/filecoin-ffi"
+
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+// GPUStatter is implemented by workers able to report the usage of their GPUs.
+type GPUStatter interface {
+	GPUStats(context.Context) ([]storiface.GPUStats, error)
+}
+
+var _ GPUStatter = &LocalWorker{}
+
+var nvidiaSmiQuery = []string{
+	"--query-gpu=index,name,uuid,memory.total,memory.used,utilization.gpu,temperature.gpu",
+	"--format=csv,noheader,nounits",
+}
+
+func (l *LocalWorker) GPUStats(ctx context.Context) ([]storiface.GPUStats, error) {
+	out, err := exec.CommandContext(ctx, "nvidia-smi", nvidiaSmiQuery...).Output()
+	if err == nil {
+		return parseNvidiaSmi(out)
+	}
+	log.Debugw("querying nvidia-smi, reporting GPU devices without usage", "error", err)
+
+	devices, derr := ffi.GetGPUDevices()
+	if derr != nil {
+		return nil, xerrors.Errorf("getting gpu devices: %w", derr)
+	}
+
+	stats := make([]storiface.GPUStats, len(devices))
+	for i, name := range devices {
+		stats[i] = storiface.GPUStats{
+			Index: i,
+			Name:  name,
+		}
+	}
+	return stats, nil
+}
+
+// parseNvidiaSmi parses the csv output of nvidia-smi queried with nvidiaSmiQuery.
+func parseNvidiaSmi(out []byte) ([]storiface.GPUStats, error) {
+	var stats []storiface.GPUStats
+
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Split(line, ",")
+		if len(fields) != 7 {
+			return nil, xerrors.Errorf("unexpected nvidia-smi output line: %q", line)
+		}
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+
+		idx, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return nil, xerrors.Errorf("parsing gpu index %q: %w", fields[0], err)
+		}
+
+		gs := storiface.GPUStats{
+			Index:    idx,
+			Name:     fields[1],
+			UUID:     fields[2],
+			HasUsage: true,
+		}
+
+		// fields which aren't supported by a device are reported as [N/A]
+		if v, err := strconv.ParseUint(fields[3], 10, 64); err == nil {
+			gs.MemTotal = v << 20
+		}
+		if v, err := strconv.ParseUint(fields[4], 10, 64); err == nil {
+			gs.MemUsed = v << 20
+		}
+		if v, err := strconv.ParseFloat(fields[5], 64); err == nil {
+			gs.Utilization = v
+		}
+		if v, err := strconv.Atoi(fields[6]); err == nil {
+			gs.Temperature = v
+		}
+
+		stats = append(stats, gs)
+	}
+
+	return stats, sc.Err()
+}
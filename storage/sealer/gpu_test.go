@@ -0,0 +1,50 @@
+package sealer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestParseNvidiaSmi(t *testing.T) {
+	out := []byte(`0, NVIDIA GeForce RTX 3090, GPU-4c1e2a8e-0000, 24576, 10240, 97, 71
+1, NVIDIA A100-SXM4-40GB, GPU-9f3b1c2d-0000, 40960, [N/A], [N/A], 45
+
+`)
+
+	stats, err := parseNvidiaSmi(out)
+	require.NoError(t, err)
+	require.Equal(t, []storiface.GPUStats{
+		{
+			Index:       0,
+			Name:        "NVIDIA GeForce RTX 3090",
+			UUID:        "GPU-4c1e2a8e-0000",
+			HasUsage:    true,
+			MemTotal:    24576 << 20,
+			MemUsed:     10240 << 20,
+			Utilization: 97,
+			Temperature: 71,
+		},
+		{
+			Index:       1,
+			Name:        "NVIDIA A100-SXM4-40GB",
+			UUID:        "GPU-9f3b1c2d-0000",
+			HasUsage:    true,
+			MemTotal:    40960 << 20,
+			Temperature: 45,
+		},
+	}, stats)
+
+	_, err = parseNvidiaSmi([]byte("0, NVIDIA GeForce RTX 3090\n"))
+	require.Error(t, err)
+}
+
+func TestGPUTask(t *testing.T) {
+	require.True(t, gpuTask(sealtasks.TTCommit2))
+	require.True(t, gpuTask(sealtasks.TTGenerateWindowPoSt))
+	require.False(t, gpuTask(sealtasks.TTPreCommit1))
+	require.False(t, gpuTask(sealtasks.TTFetch))
+}
@@ -20,6 +20,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-statestore"
 
+	"github.com/filecoin-project/lotus/journal"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/paths"
 	"github.com/filecoin-project/lotus/storage/sealer/ffiwrapper"
@@ -52,6 +53,10 @@ type SectorManager interface {
 	storiface.ProverPoSt
 	storiface.WorkerReturn
 	FaultTracker
+
+	// SetSectorPlacement restricts storage paths used for new sector files to
+	// paths belonging to at least one of the given groups
+	SetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error
 }
 
 var ClosedWorkerID = uuid.UUID{}
@@ -68,6 +73,10 @@ type Manager struct {
 	winningPoStSched *poStScheduler
 
 	localProver storiface.ProverPoSt
+	// hostname of the builtin worker, on which builtin PoSt runs
+	localHostname string
+
+	preempt *preemptor
 
 	workLk sync.Mutex
 	work   *statestore.StateStore
@@ -99,7 +108,7 @@ type StorageAuth http.Header
 type WorkerStateStore *statestore.StateStore
 type ManagerStateStore *statestore.StateStore
 
-func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, wss WorkerStateStore, mss ManagerStateStore) (*Manager, error) {
+func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.LocalStorage, si paths.SectorIndex, sc config.SealerConfig, pc config.ProvingConfig, wss WorkerStateStore, mss ManagerStateStore, hs TaskHistoryStore, j journal.Journal) (*Manager, error) {
 	prover, err := ffiwrapper.New(&readonlyProvider{stor: lstor, index: si})
 	if err != nil {
 		return nil, xerrors.Errorf("creating prover instance: %w", err)
@@ -110,6 +119,18 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 		return nil, err
 	}
 
+	if hs != nil {
+		sh.workTracker.history, err = newTaskHistory(ctx, hs, time.Duration(sc.TaskHistoryRetention))
+		if err != nil {
+			return nil, xerrors.Errorf("opening task history: %w", err)
+		}
+	}
+
+	preempt, err := newPreemptor(sc.PreemptibleTasks, sc.PreemptionPolicy, j)
+	if err != nil {
+		return nil, xerrors.Errorf("configuring task preemption: %w", err)
+	}
+
 	m := &Manager{
 		ls:         ls,
 		storage:    stor,
@@ -123,6 +144,8 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 
 		localProver: prover,
 
+		preempt: preempt,
+
 		parallelCheckLimit:        pc.ParallelCheckLimit,
 		singleCheckTimeout:        time.Duration(pc.SingleCheckTimeout),
 		partitionCheckTimeout:     time.Duration(pc.PartitionCheckTimeout),
@@ -139,6 +162,21 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 
 	m.setupWorkTracker()
 
+	preempt.running = func() []trackedWork {
+		running, _ := m.sched.workTracker.Running()
+		return running
+	}
+	preempt.abortCall = m.Abort
+	preempt.changed = func() {
+		select {
+		case m.sched.workerChange <- struct{}{}:
+		default: // workerChange is buffered, a scheduling pass is already pending
+		}
+	}
+	m.sched.preempt = preempt
+	m.windowPoStSched.preempt = preempt
+	m.winningPoStSched.preempt = preempt
+
 	go m.sched.runSched()
 
 	localTasks := []sealtasks.TaskType{
@@ -178,6 +216,7 @@ func New(ctx context.Context, lstor *paths.Local, stor paths.Store, ls paths.Loc
 		Name:                    sc.LocalWorkerName,
 	}
 	worker := NewLocalWorker(wcfg, stor, lstor, si, m, wss)
+	m.localHostname = worker.name
 	err = m.AddWorker(ctx, worker)
 	if err != nil {
 		return nil, xerrors.Errorf("adding local worker: %w", err)
@@ -431,7 +470,7 @@ func (m *Manager) AddPiece(ctx context.Context, sector storiface.SectorRef, exis
 	var selector WorkerSelector
 	var err error
 	if len(existingPieces) == 0 { // new
-		selector = newAllocSelector(m.index, storiface.FTUnsealed, storiface.PathSealing)
+		selector = newAllocSelector(m.index, sector.ID, storiface.FTUnsealed, storiface.PathSealing)
 	} else { // use existing
 		selector = newExistingSelector(m.index, sector.ID, storiface.FTUnsealed, false)
 	}
@@ -484,7 +523,7 @@ func (m *Manager) SealPreCommit1(ctx context.Context, sector storiface.SectorRef
 
 	// TODO: also consider where the unsealed data sits
 
-	selector := newAllocSelector(m.index, storiface.FTCache|storiface.FTSealed, storiface.PathSealing)
+	selector := newAllocSelector(m.index, sector.ID, storiface.FTCache|storiface.FTSealed, storiface.PathSealing)
 
 	err = m.sched.Schedule(ctx, sector, sealtasks.TTPreCommit1, selector, m.schedFetch(sector, storiface.FTUnsealed, storiface.PathSealing, storiface.AcquireMove), func(ctx context.Context, w Worker) error {
 		err := m.startWork(ctx, w, wk)(w.SealPreCommit1(ctx, sector, ticket, pieces))
@@ -950,9 +989,17 @@ func (m *Manager) Remove(ctx context.Context, sector storiface.SectorRef) error
 		err = multierror.Append(err, xerrors.Errorf("removing sector (unsealed): %w", rerr))
 	}
 
+	if perr := m.index.StorageSetSectorPlacement(ctx, sector.ID, nil); perr != nil {
+		err = multierror.Append(err, xerrors.Errorf("clearing sector placement: %w", perr))
+	}
+
 	return err
 }
 
+func (m *Manager) SetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error {
+	return m.index.StorageSetSectorPlacement(ctx, sector, groups)
+}
+
 func (m *Manager) ReplicaUpdate(ctx context.Context, sector storiface.SectorRef, pieces []abi.PieceInfo) (out storiface.ReplicaUpdateOut, err error) {
 	ctx, cancel := context.WithCancel(ctx)
 	defer cancel()
@@ -984,7 +1031,7 @@ func (m *Manager) ReplicaUpdate(ctx context.Context, sector storiface.SectorRef,
 		return storiface.ReplicaUpdateOut{}, xerrors.Errorf("acquiring sector lock: %w", err)
 	}
 
-	selector := newAllocSelector(m.index, storiface.FTUpdate|storiface.FTUpdateCache, storiface.PathSealing)
+	selector := newAllocSelector(m.index, sector.ID, storiface.FTUpdate|storiface.FTUpdateCache, storiface.PathSealing)
 
 	err = m.sched.Schedule(ctx, sector, sealtasks.TTReplicaUpdate, selector, m.schedFetch(sector, storiface.FTUnsealed|storiface.FTSealed|storiface.FTCache, storiface.PathSealing, storiface.AcquireCopy), func(ctx context.Context, w Worker) error {
 		err := m.startWork(ctx, w, wk)(w.ReplicaUpdate(ctx, sector, pieces))
@@ -1153,7 +1200,7 @@ func (m *Manager) DownloadSectorData(ctx context.Context, sector storiface.Secto
 		ptype = storiface.PathStorage
 	}
 
-	selector := newAllocSelector(m.index, toFetch, ptype)
+	selector := newAllocSelector(m.index, sector.ID, toFetch, ptype)
 
 	err = m.sched.Schedule(ctx, sector, sealtasks.TTDownloadSector, selector, schedNop, func(ctx context.Context, w Worker) error {
 		err := m.startWork(ctx, w, wk)(w.DownloadSectorData(ctx, sector, finalized, src))
@@ -349,7 +349,15 @@ func (m *Manager) waitCall(ctx context.Context, callID storiface.CallID) (interf
 	}
 }
 
+// returnResult is called with the results of the calls returned by workers.
 func (m *Manager) returnResult(ctx context.Context, callID storiface.CallID, r interface{}, cerr *storiface.CallError) error {
+	// the call has exited on the worker, even if it was aborted
+	m.sched.preempt.exited(callID)
+
+	return m.reportResult(ctx, callID, r, cerr)
+}
+
+func (m *Manager) reportResult(ctx context.Context, callID storiface.CallID, r interface{}, cerr *storiface.CallError) error {
 	res := result{
 		r: r,
 	}
@@ -357,7 +365,7 @@ func (m *Manager) returnResult(ctx context.Context, callID storiface.CallID, r i
 		res.err = cerr
 	}
 
-	m.sched.workTracker.onDone(ctx, callID)
+	m.sched.workTracker.onDone(ctx, callID, cerr)
 
 	m.workLk.Lock()
 	defer m.workLk.Unlock()
@@ -412,5 +420,5 @@ func (m *Manager) returnResult(ctx context.Context, callID storiface.CallID, r i
 
 func (m *Manager) Abort(ctx context.Context, call storiface.CallID) error {
 	// TODO: Allow temp error
-	return m.returnResult(ctx, call, nil, storiface.Err(storiface.ErrUnknown, xerrors.New("task aborted")))
+	return m.reportResult(ctx, call, nil, storiface.Err(storiface.ErrUnknown, xerrors.New("task aborted")))
 }
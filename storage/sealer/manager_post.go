@@ -14,6 +14,7 @@ import (
 	"github.com/filecoin-project/go-state-types/builtin"
 	"github.com/filecoin-project/specs-actors/v7/actors/runtime/proof"
 
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
 	"github.com/filecoin-project/lotus/storage/sealer/storiface"
 )
 
@@ -22,6 +23,7 @@ func (m *Manager) GenerateWinningPoSt(ctx context.Context, minerID abi.ActorID,
 		// if builtin PoSt isn't disabled, and there are no workers, compute the PoSt locally
 
 		log.Info("GenerateWinningPoSt run at lotus-miner")
+		defer m.preempt.begin(m.localHostname, sealtasks.TTGenerateWinningPoSt)()
 		return m.localProver.GenerateWinningPoSt(ctx, minerID, sectorInfo, randomness)
 	}
 	return m.generateWinningPoSt(ctx, minerID, sectorInfo, randomness)
@@ -83,6 +85,7 @@ func (m *Manager) GenerateWindowPoSt(ctx context.Context, minerID abi.ActorID, p
 		// if builtin PoSt isn't disabled, and there are no workers, compute the PoSt locally
 
 		log.Info("GenerateWindowPoSt run at lotus-miner")
+		defer m.preempt.begin(m.localHostname, sealtasks.TTGenerateWindowPoSt)()
 		p, s, err := m.localProver.GenerateWindowPoSt(ctx, minerID, postProofType, sectorInfo, randomness)
 		if err != nil {
 			return p, s, xerrors.Errorf("local prover: %w", err)
@@ -516,6 +516,10 @@ func (mgr *SectorMgr) ReleaseSectorKey(ctx context.Context, sector storiface.Sec
 	return nil
 }
 
+func (mgr *SectorMgr) SetSectorPlacement(ctx context.Context, sector abi.SectorID, groups []storiface.Group) error {
+	return nil
+}
+
 func (mgr *SectorMgr) DownloadSectorData(ctx context.Context, sector storiface.SectorRef, finalized bool, src map[storiface.SectorFileType]storiface.SectorLocation) error {
 	return xerrors.Errorf("not supported")
 }
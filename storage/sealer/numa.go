@@ -0,0 +1,239 @@
+package sealer
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+var numaSysfsRoot = "/sys/devices/system/node"
+
+// numaTopology reads the NUMA nodes of the machine from sysfs. It returns no
+// nodes when the topology isn't available.
+func numaTopology(root string) ([]storiface.NUMANode, error) {
+	dirs, err := filepath.Glob(filepath.Join(root, "node[0-9]*"))
+	if err != nil {
+		return nil, err
+	}
+
+	var out []storiface.NUMANode
+	for _, dir := range dirs {
+		id, err := strconv.Atoi(strings.TrimPrefix(filepath.Base(dir), "node"))
+		if err != nil {
+			continue
+		}
+
+		cl, err := os.ReadFile(filepath.Join(dir, "cpulist"))
+		if err != nil {
+			return nil, xerrors.Errorf("reading node %d cpulist: %w", id, err)
+		}
+		cpus, err := parseCPUList(string(cl))
+		if err != nil {
+			return nil, xerrors.Errorf("parsing node %d cpulist: %w", id, err)
+		}
+
+		node := storiface.NUMANode{ID: id, CPUs: cpus}
+
+		mi, err := os.ReadFile(filepath.Join(dir, "meminfo"))
+		if err == nil {
+			node.MemTotal, node.MemFree = parseNodeMeminfo(mi)
+		}
+
+		out = append(out, node)
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out, nil
+}
+
+// parseCPUList parses the kernel cpulist format, e.g. "0-15,32-47"
+func parseCPUList(s string) ([]int, error) {
+	var out []int
+	for _, part := range strings.Split(strings.TrimSpace(s), ",") {
+		if part == "" {
+			continue
+		}
+
+		lo, hi, isRange := strings.Cut(part, "-")
+		from, err := strconv.Atoi(lo)
+		if err != nil {
+			return nil, xerrors.Errorf("invalid cpu %q", part)
+		}
+		to := from
+		if isRange {
+			if to, err = strconv.Atoi(hi); err != nil || to < from {
+				return nil, xerrors.Errorf("invalid cpu range %q", part)
+			}
+		}
+
+		for c := from; c <= to; c++ {
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+// parseNodeMeminfo reads MemTotal and MemFree, in bytes, from a node meminfo
+// file ("Node 0 MemTotal:       65536 kB")
+func parseNodeMeminfo(b []byte) (total, free uint64) {
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		f := strings.Fields(sc.Text())
+		if len(f) < 4 {
+			continue
+		}
+		v, err := strconv.ParseUint(f[3], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(f) > 4 && f[4] == "kB" {
+			v *= 1024
+		}
+
+		switch f[2] {
+		case "MemTotal:":
+			total = v
+		case "MemFree:":
+			free = v
+		}
+	}
+	return total, free
+}
+
+// numaPinner places tasks on NUMA nodes, pinning them to the node CPUs and
+// memory
+type numaPinner struct {
+	topology []storiface.NUMANode
+	allowed  map[sealtasks.TaskType][]int
+	membind  bool
+
+	lk      sync.Mutex
+	running map[int]int
+}
+
+// newNUMAPinner returns nil on machines with a single NUMA node
+func newNUMAPinner(topology []storiface.NUMANode, wcfg WorkerConfig) *numaPinner {
+	if len(topology) < 2 {
+		if len(wcfg.NUMANodes) > 0 {
+			log.Warnw("NUMA nodes configured, but the machine doesn't have multiple NUMA nodes", "nodes", len(topology))
+		}
+		return nil
+	}
+
+	exists := map[int]bool{}
+	for _, n := range topology {
+		exists[n.ID] = true
+	}
+
+	allowed := map[sealtasks.TaskType][]int{}
+	for tt, nodes := range wcfg.NUMANodes {
+		for _, n := range nodes {
+			if !exists[n] {
+				log.Warnw("ignoring unknown NUMA node", "task", tt.Short(), "node", n)
+				continue
+			}
+			allowed[tt] = append(allowed[tt], n)
+		}
+	}
+	if _, ok := allowed[sealtasks.TTPreCommit1]; !ok && wcfg.NUMAPinPC1 {
+		for _, n := range topology {
+			allowed[sealtasks.TTPreCommit1] = append(allowed[sealtasks.TTPreCommit1], n.ID)
+		}
+	}
+
+	return &numaPinner{
+		topology: topology,
+		allowed:  allowed,
+		membind:  wcfg.NUMAMemBind,
+		running:  map[int]int{},
+	}
+}
+
+// pin binds the calling goroutine to the least loaded NUMA node allowed for
+// the task type. The goroutine stays locked to its OS thread, which is
+// discarded once the goroutine exits, so it must be dedicated to the task.
+// Threads started by the task (e.g. in proofs code) inherit the binding.
+func (np *numaPinner) pin(tt sealtasks.TaskType) (release func(), err error) {
+	if np == nil || len(np.allowed[tt]) == 0 {
+		return func() {}, nil
+	}
+
+	np.lk.Lock()
+	node := -1
+	for _, n := range np.allowed[tt] {
+		if node == -1 || np.running[n] < np.running[node] {
+			node = n
+		}
+	}
+	np.running[node]++
+	np.lk.Unlock()
+
+	release = func() {
+		np.lk.Lock()
+		np.running[node]--
+		np.lk.Unlock()
+	}
+
+	var cpus []int
+	for _, n := range np.topology {
+		if n.ID == node {
+			cpus = n.CPUs
+		}
+	}
+
+	if err := bindThread(node, cpus, np.membind); err != nil {
+		release()
+		return nil, xerrors.Errorf("binding to NUMA node %d: %w", node, err)
+	}
+
+	log.Debugw("pinned task to NUMA node", "task", tt.Short(), "node", node)
+	return release, nil
+}
+
+// nodes returns the node topology with current task placement
+func (np *numaPinner) nodes() []storiface.NUMANode {
+	if np == nil {
+		return nil
+	}
+
+	mem := map[int]storiface.NUMANode{}
+	if cur, err := numaTopology(numaSysfsRoot); err == nil {
+		for _, n := range cur {
+			mem[n.ID] = n
+		}
+	}
+
+	np.lk.Lock()
+	defer np.lk.Unlock()
+
+	out := make([]storiface.NUMANode, len(np.topology))
+	for i, n := range np.topology {
+		if m, ok := mem[n.ID]; ok {
+			n.MemTotal, n.MemFree = m.MemTotal, m.MemFree
+		}
+
+		n.Tasks = nil
+		for tt, nodes := range np.allowed {
+			for _, id := range nodes {
+				if id == n.ID {
+					n.Tasks = append(n.Tasks, tt)
+				}
+			}
+		}
+		sort.Slice(n.Tasks, func(i, j int) bool { return n.Tasks[i].Less(n.Tasks[j]) })
+
+		n.Running = np.running[n.ID]
+		out[i] = n
+	}
+	return out
+}
@@ -0,0 +1,60 @@
+//go:build linux
+// +build linux
+
+package sealer
+
+import (
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+	"golang.org/x/xerrors"
+)
+
+// memory policy modes, see set_mempolicy(2)
+const (
+	mpolPreferred = 1
+	mpolBind      = 2
+)
+
+// bindThread locks the calling goroutine to its OS thread, and binds the
+// thread to the CPUs of a NUMA node. Memory allocations prefer the node, or
+// are restricted to it with membind. On failure the thread is left as it was.
+func bindThread(node int, cpus []int, membind bool) error {
+	runtime.LockOSThread()
+
+	var prev unix.CPUSet
+	if err := unix.SchedGetaffinity(0, &prev); err != nil {
+		runtime.UnlockOSThread()
+		return xerrors.Errorf("getting cpu affinity: %w", err)
+	}
+
+	var set unix.CPUSet
+	for _, c := range cpus {
+		set.Set(c)
+	}
+	if err := unix.SchedSetaffinity(0, &set); err != nil {
+		runtime.UnlockOSThread()
+		return xerrors.Errorf("setting cpu affinity: %w", err)
+	}
+
+	mode := mpolPreferred
+	if membind {
+		mode = mpolBind
+	}
+
+	mask := make([]uint64, node/64+1)
+	mask[node/64] |= 1 << (node % 64)
+	_, _, errno := unix.Syscall(unix.SYS_SET_MEMPOLICY, uintptr(mode), uintptr(unsafe.Pointer(&mask[0])), uintptr(len(mask)*64+1))
+	if errno != 0 {
+		if err := unix.SchedSetaffinity(0, &prev); err != nil {
+			// the thread stays locked, to be discarded with the goroutine
+			log.Errorw("restoring cpu affinity", "error", err)
+		} else {
+			runtime.UnlockOSThread()
+		}
+		return xerrors.Errorf("setting memory policy: %w", errno)
+	}
+
+	return nil
+}
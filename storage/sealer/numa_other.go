@@ -0,0 +1,10 @@
+//go:build !linux
+// +build !linux
+
+package sealer
+
+import "golang.org/x/xerrors"
+
+func bindThread(node int, cpus []int, membind bool) error {
+	return xerrors.Errorf("NUMA pinning is only supported on linux")
+}
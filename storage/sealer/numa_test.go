@@ -0,0 +1,76 @@
+package sealer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestParseCPUList(t *testing.T) {
+	cpus, err := parseCPUList("0-3,8,10-11\n")
+	require.NoError(t, err)
+	require.Equal(t, []int{0, 1, 2, 3, 8, 10, 11}, cpus)
+
+	cpus, err = parseCPUList("\n")
+	require.NoError(t, err)
+	require.Empty(t, cpus)
+
+	_, err = parseCPUList("3-1")
+	require.Error(t, err)
+	_, err = parseCPUList("a")
+	require.Error(t, err)
+}
+
+func TestNUMATopology(t *testing.T) {
+	root := t.TempDir()
+	writeNode := func(id, cpulist, meminfo string) {
+		dir := filepath.Join(root, "node"+id)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "cpulist"), []byte(cpulist), 0644))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "meminfo"), []byte(meminfo), 0644))
+	}
+	writeNode("1", "4-7\n", "Node 1 MemTotal:       2048 kB\nNode 1 MemFree:        1024 kB\n")
+	writeNode("0", "0-3\n", "Node 0 MemTotal:       4096 kB\nNode 0 MemFree:        1024 kB\n")
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "power"), 0755))
+
+	nodes, err := numaTopology(root)
+	require.NoError(t, err)
+	require.Equal(t, []storiface.NUMANode{
+		{ID: 0, CPUs: []int{0, 1, 2, 3}, MemTotal: 4096 << 10, MemFree: 1024 << 10},
+		{ID: 1, CPUs: []int{4, 5, 6, 7}, MemTotal: 2048 << 10, MemFree: 1024 << 10},
+	}, nodes)
+
+	nodes, err = numaTopology(filepath.Join(root, "missing"))
+	require.NoError(t, err)
+	require.Empty(t, nodes)
+}
+
+func TestNUMAPinnerPlacement(t *testing.T) {
+	topology := []storiface.NUMANode{{ID: 0, CPUs: []int{0, 1}}, {ID: 1, CPUs: []int{2, 3}}}
+
+	require.Nil(t, newNUMAPinner(topology[:1], WorkerConfig{NUMAPinPC1: true}))
+
+	np := newNUMAPinner(topology, WorkerConfig{
+		NUMANodes: map[sealtasks.TaskType][]int{
+			sealtasks.TTPreCommit2: {1, 5},
+		},
+		NUMAPinPC1: true,
+	})
+	require.Equal(t, []int{0, 1}, np.allowed[sealtasks.TTPreCommit1])
+	require.Equal(t, []int{1}, np.allowed[sealtasks.TTPreCommit2]) // unknown node dropped
+
+	// tasks without placement aren't pinned
+	release, err := np.pin(sealtasks.TTCommit2)
+	require.NoError(t, err)
+	release()
+
+	nodes := np.nodes()
+	require.Equal(t, []sealtasks.TaskType{sealtasks.TTPreCommit1}, nodes[0].Tasks)
+	require.Equal(t, []sealtasks.TaskType{sealtasks.TTPreCommit2, sealtasks.TTPreCommit1}, nodes[1].Tasks)
+	require.Equal(t, 0, nodes[0].Running+nodes[1].Running)
+}
@@ -31,19 +31,28 @@ type PieceProvider interface {
 	IsUnsealed(ctx context.Context, sector storiface.SectorRef, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize) (bool, error)
 }
 
+// PieceReadRecorder is notified of every piece read through the piece provider.
+type PieceReadRecorder interface {
+	// RecordPieceRead records a read, unsealed is set when the piece had to be
+	// unsealed for the read
+	RecordPieceRead(ctx context.Context, sector abi.SectorID, offset storiface.UnpaddedByteIndex, size abi.UnpaddedPieceSize, unsealed bool)
+}
+
 var _ PieceProvider = &pieceProvider{}
 
 type pieceProvider struct {
 	storage *paths.Remote
 	index   paths.SectorIndex
 	uns     Unsealer
+	rec     PieceReadRecorder
 }
 
-func NewPieceProvider(storage *paths.Remote, index paths.SectorIndex, uns Unsealer) PieceProvider {
+func NewPieceProvider(storage *paths.Remote, index paths.SectorIndex, uns Unsealer, rec PieceReadRecorder) PieceProvider {
 	return &pieceProvider{
 		storage: storage,
 		index:   index,
 		uns:     uns,
+		rec:     rec,
 	}
 }
 
@@ -206,6 +215,10 @@ func (p *pieceProvider) ReadPiece(ctx context.Context, sector storiface.SectorRe
 		log.Debugf("unsealed piece already exists, no need to unseal, sector=%+v, pieceOffset=%d, size=%d", sector, pieceOffset, size)
 	}
 
+	if p.rec != nil {
+		p.rec.RecordPieceRead(ctx, sector.ID, pieceOffset, size, uns)
+	}
+
 	log.Debugf("returning reader to read unsealed piece, sector=%+v, pieceOffset=%d, size=%d", sector, pieceOffset, size)
 
 	return r, uns, nil
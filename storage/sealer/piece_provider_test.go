@@ -21,6 +21,7 @@ import (
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-statestore"
 
+	"github.com/filecoin-project/lotus/journal"
 	"github.com/filecoin-project/lotus/node/config"
 	"github.com/filecoin-project/lotus/storage/paths"
 	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
@@ -218,7 +219,7 @@ func newPieceProviderTestHarness(t *testing.T, mgrConfig config.SealerConfig, se
 	wsts := statestore.New(namespace.Wrap(dstore, datastore.NewKey("/worker/calls")))
 	smsts := statestore.New(namespace.Wrap(dstore, datastore.NewKey("/stmgr/calls")))
 
-	mgr, err := New(ctx, localStore, remoteStore, storage, index, mgrConfig, config.ProvingConfig{}, wsts, smsts)
+	mgr, err := New(ctx, localStore, remoteStore, storage, index, mgrConfig, config.ProvingConfig{}, wsts, smsts, nil, journal.NilJournal())
 	require.NoError(t, err)
 
 	// start a http server on the manager to serve sector file requests.
@@ -230,7 +231,7 @@ func newPieceProviderTestHarness(t *testing.T, mgrConfig config.SealerConfig, se
 		_ = svc.Serve(nl)
 	}()
 
-	pp := NewPieceProvider(remoteStore, index, mgr)
+	pp := NewPieceProvider(remoteStore, index, mgr, nil)
 
 	sector := storiface.SectorRef{
 		ID: abi.SectorID{
@@ -0,0 +1,265 @@
+package sealer
+
+import (
+	"context"
+	"sync"
+
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+const (
+	// PreemptSuspend holds new preemptible tasks on a host while proving runs there
+	PreemptSuspend = "suspend"
+	// PreemptAbort additionally aborts preemptible tasks running on the host
+	// when proving starts there
+	PreemptAbort = "abort"
+)
+
+// PreemptionEvt is the journal event recorded when proving starts and stops
+// preempting sealing tasks on a host.
+type PreemptionEvt struct {
+	Host      string
+	Preemptor sealtasks.TaskType
+	Policy    string
+	// Started is false for the event recorded when proving on the host ended
+	Started bool
+
+	// Running lists preemptible tasks running on the host when proving started
+	Running []storiface.WorkerJob
+	Aborted []storiface.CallID
+}
+
+// preemptor tracks hosts running WindowPoSt or WinningPoSt, and keeps
+// preemptible sealing tasks from competing with proving for their GPUs.
+type preemptor struct {
+	tasks  map[sealtasks.TaskType]struct{}
+	policy string
+
+	running   func() []trackedWork
+	abortCall func(context.Context, storiface.CallID) error
+	changed   func()
+
+	journal journal.Journal
+	evtType journal.EventType
+
+	lk     sync.Mutex
+	active map[string]int // hostname -> number of proving tasks running there
+
+	// aborted tasks still run on their worker, their resources are only
+	// released once the worker returns them
+	exiting map[preemptedTask]chan struct{}
+	byCall  map[storiface.CallID]preemptedTask
+}
+
+type preemptedTask struct {
+	worker storiface.WorkerID
+	sector abi.SectorID
+	task   sealtasks.TaskType
+}
+
+func newPreemptor(tasks []string, policy string, j journal.Journal) (*preemptor, error) {
+	p := &preemptor{
+		tasks:  map[sealtasks.TaskType]struct{}{},
+		policy: policy,
+
+		running:   func() []trackedWork { return nil },
+		abortCall: func(context.Context, storiface.CallID) error { return nil },
+		changed:   func() {},
+
+		journal: j,
+		evtType: j.RegisterEventType("sealer", "preemption"),
+
+		active:  map[string]int{},
+		exiting: map[preemptedTask]chan struct{}{},
+		byCall:  map[storiface.CallID]preemptedTask{},
+	}
+
+	for _, name := range tasks {
+		tt, ok := sealtasks.FromShort(name)
+		if !ok {
+			return nil, xerrors.Errorf("unknown preemptible task type '%s'", name)
+		}
+		if tt.WorkerType() != sealtasks.WorkerSealing {
+			return nil, xerrors.Errorf("proving task %s can't be preempted", name)
+		}
+		p.tasks[tt] = struct{}{}
+	}
+
+	switch policy {
+	case "":
+		p.policy = PreemptSuspend
+	case PreemptSuspend, PreemptAbort:
+	default:
+		return nil, xerrors.Errorf("unknown preemption policy '%s'", policy)
+	}
+
+	return p, nil
+}
+
+func (p *preemptor) enabled() bool {
+	return p != nil && len(p.tasks) > 0
+}
+
+// held returns true when a task of the given type must not be started on the
+// host because proving runs there.
+func (p *preemptor) held(host string, tt sealtasks.TaskType) bool {
+	if !p.enabled() {
+		return false
+	}
+	if _, ok := p.tasks[tt]; !ok {
+		return false
+	}
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	return p.active[host] > 0
+}
+
+// begin marks a proving task as running on the host. The returned function
+// must be called when the task finishes.
+func (p *preemptor) begin(host string, tt sealtasks.TaskType) func() {
+	if !p.enabled() {
+		return func() {}
+	}
+
+	p.lk.Lock()
+	p.active[host]++
+	first := p.active[host] == 1
+	p.lk.Unlock()
+
+	if first {
+		p.preempt(host, tt)
+	}
+
+	return func() {
+		p.lk.Lock()
+		p.active[host]--
+		last := p.active[host] == 0
+		if last {
+			delete(p.active, host)
+		}
+		p.lk.Unlock()
+
+		if !last {
+			return
+		}
+
+		log.Infow("proving finished, resuming preemptible tasks", "host", host)
+		p.journal.RecordEvent(p.evtType, func() interface{} {
+			return PreemptionEvt{
+				Host:      host,
+				Preemptor: tt,
+				Policy:    p.policy,
+			}
+		})
+
+		// held tasks can be scheduled again
+		p.changed()
+	}
+}
+
+func (p *preemptor) preempt(host string, tt sealtasks.TaskType) {
+	evt := PreemptionEvt{
+		Host:      host,
+		Preemptor: tt,
+		Policy:    p.policy,
+		Started:   true,
+	}
+
+	for _, t := range p.running() {
+		if t.workerHostname != host {
+			continue
+		}
+		if _, ok := p.tasks[t.job.Task]; !ok {
+			continue
+		}
+		evt.Running = append(evt.Running, t.job)
+
+		if p.policy != PreemptAbort {
+			continue
+		}
+
+		// the proofs library can't interrupt a running computation, aborting
+		// frees the task for the sealing pipeline to retry it after proving,
+		// while its resources stay allocated until it exits
+		key := p.markExiting(t)
+		if err := p.abortCall(context.TODO(), t.job.ID); err != nil {
+			log.Errorw("aborting preempted task", "call", t.job.ID, "task", t.job.Task, "error", err)
+			p.unmarkExiting(key, t.job.ID)
+			continue
+		}
+		evt.Aborted = append(evt.Aborted, t.job.ID)
+	}
+
+	log.Infow("proving preempts sealing tasks", "host", host, "task", tt, "policy", p.policy, "running", len(evt.Running), "aborted", len(evt.Aborted))
+	p.journal.RecordEvent(p.evtType, func() interface{} { return evt })
+}
+
+func (p *preemptor) markExiting(t trackedWork) preemptedTask {
+	key := preemptedTask{worker: t.worker, sector: t.job.Sector, task: t.job.Task}
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	p.exiting[key] = make(chan struct{})
+	p.byCall[t.job.ID] = key
+	return key
+}
+
+func (p *preemptor) unmarkExiting(key preemptedTask, call storiface.CallID) {
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	delete(p.exiting, key)
+	delete(p.byCall, call)
+}
+
+// exited is called when a worker returns a call, which lets the scheduler
+// release the resources of the call if it was aborted by preemption.
+func (p *preemptor) exited(call storiface.CallID) {
+	if !p.enabled() {
+		return
+	}
+
+	p.lk.Lock()
+	defer p.lk.Unlock()
+
+	key, ok := p.byCall[call]
+	if !ok {
+		return
+	}
+	close(p.exiting[key])
+	delete(p.exiting, key)
+	delete(p.byCall, call)
+}
+
+// waitExited blocks until the task aborted by preemption on the worker has
+// exited, if there is one, so that its resources aren't allocated to other
+// tasks while it still runs. It returns early when the scheduler or the worker
+// is closing.
+func (p *preemptor) waitExited(wid storiface.WorkerID, sector abi.SectorID, task sealtasks.TaskType, closing, workerClosing <-chan struct{}) {
+	if !p.enabled() {
+		return
+	}
+
+	p.lk.Lock()
+	ch, ok := p.exiting[preemptedTask{worker: wid, sector: sector, task: task}]
+	p.lk.Unlock()
+	if !ok {
+		return
+	}
+
+	log.Infow("waiting for the preempted task to exit before releasing its resources", "worker", wid, "sector", sector, "task", task)
+	select {
+	case <-ch:
+	case <-closing:
+	case <-workerClosing:
+	}
+}
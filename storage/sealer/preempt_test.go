@@ -0,0 +1,103 @@
+package sealer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/journal"
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestNewPreemptor(t *testing.T) {
+	p, err := newPreemptor(nil, "", journal.NilJournal())
+	require.NoError(t, err)
+	require.False(t, p.enabled())
+	require.Equal(t, PreemptSuspend, p.policy)
+
+	_, err = newPreemptor([]string{"PC3"}, "", journal.NilJournal())
+	require.Error(t, err)
+	_, err = newPreemptor([]string{"WDP"}, "", journal.NilJournal())
+	require.Error(t, err)
+	_, err = newPreemptor([]string{"PC2"}, "kill", journal.NilJournal())
+	require.Error(t, err)
+
+	var nilp *preemptor
+	require.False(t, nilp.held("host", sealtasks.TTPreCommit2))
+	nilp.begin("host", sealtasks.TTGenerateWindowPoSt)()
+}
+
+func TestPreemption(t *testing.T) {
+	p, err := newPreemptor([]string{"PC2", "PR2"}, PreemptAbort, journal.NilJournal())
+	require.NoError(t, err)
+
+	call := func() storiface.CallID {
+		return storiface.CallID{Sector: abi.SectorID{Miner: 1000, Number: 1}, ID: uuid.New()}
+	}
+	pc2, c2, remote := call(), call(), call()
+	wid := storiface.WorkerID(uuid.New())
+
+	p.running = func() []trackedWork {
+		return []trackedWork{
+			{job: storiface.WorkerJob{ID: pc2, Sector: pc2.Sector, Task: sealtasks.TTPreCommit2}, worker: wid, workerHostname: "gpu1"},
+			{job: storiface.WorkerJob{ID: c2, Task: sealtasks.TTCommit2}, workerHostname: "gpu1"},
+			{job: storiface.WorkerJob{ID: remote, Task: sealtasks.TTPreCommit2}, workerHostname: "gpu2"},
+		}
+	}
+
+	var aborted []storiface.CallID
+	p.abortCall = func(ctx context.Context, ci storiface.CallID) error {
+		aborted = append(aborted, ci)
+		return nil
+	}
+
+	var changed int
+	p.changed = func() { changed++ }
+
+	require.False(t, p.held("gpu1", sealtasks.TTPreCommit2))
+
+	done1 := p.begin("gpu1", sealtasks.TTGenerateWindowPoSt)
+	done2 := p.begin("gpu1", sealtasks.TTGenerateWindowPoSt)
+
+	// only the preemptible task on the proving host is aborted, once
+	require.Equal(t, []storiface.CallID{pc2}, aborted)
+
+	require.True(t, p.held("gpu1", sealtasks.TTPreCommit2))
+	require.True(t, p.held("gpu1", sealtasks.TTProveReplicaUpdate2))
+	require.False(t, p.held("gpu1", sealtasks.TTCommit2))
+	require.False(t, p.held("gpu2", sealtasks.TTPreCommit2))
+
+	done1()
+	require.True(t, p.held("gpu1", sealtasks.TTPreCommit2))
+	require.Zero(t, changed)
+
+	done2()
+	require.False(t, p.held("gpu1", sealtasks.TTPreCommit2))
+	require.Equal(t, 1, changed)
+
+	// the resources of the aborted task are held until the worker returns it
+	released := make(chan struct{})
+	go func() {
+		p.waitExited(wid, pc2.Sector, sealtasks.TTPreCommit2, nil, nil)
+		close(released)
+	}()
+
+	p.exited(c2)
+	select {
+	case <-released:
+		t.Fatal("resources released before the aborted task exited")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	p.exited(pc2)
+	<-released
+
+	// tasks which weren't aborted don't wait
+	p.waitExited(wid, c2.Sector, sealtasks.TTCommit2, nil, nil)
+}
@@ -81,6 +81,8 @@ type Scheduler struct {
 
 	workTracker *workTracker
 
+	preempt *preemptor // optional
+
 	info      chan func(interface{})
 	rmRequest chan *rmRequest
 
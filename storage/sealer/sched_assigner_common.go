@@ -100,6 +100,11 @@ func (a *AssignerCommon) TrySched(sh *Scheduler) {
 					continue
 				}
 
+				if sh.preempt.held(worker.Info.Hostname, task.TaskType) {
+					log.Debugw("skipping worker preempted by proving", "worker", windowRequest.Worker, "task", task.TaskType)
+					continue
+				}
+
 				needRes := worker.Info.Resources.ResourceSpec(task.Sector.ProofType, task.TaskType)
 
 				// TODO: allow bigger windows
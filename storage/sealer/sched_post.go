@@ -25,6 +25,8 @@ type poStScheduler struct {
 	cond    *sync.Cond
 
 	postType sealtasks.TaskType
+
+	preempt *preemptor // optional
 }
 
 func newPoStScheduler(t sealtasks.TaskType) *poStScheduler {
@@ -115,6 +117,8 @@ func (ps *poStScheduler) Schedule(ctx context.Context, primary bool, spt abi.Reg
 			ps.lk.Unlock()
 			defer ps.lk.Lock()
 
+			defer ps.preempt.begin(worker.Info.Hostname, ps.postType)()
+
 			return work(ctx, worker.workerRpc)
 		})
 		if err == nil {
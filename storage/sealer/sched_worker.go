@@ -521,6 +521,9 @@ func (sw *schedWorker) startProcessingTask(req *WorkerRequest) error {
 				log.Warnf("scheduler closed while sending response")
 			}
 
+			// a task aborted by preemption holds its resources until it exits
+			sh.preempt.waitExited(sw.wid, req.Sector.ID, req.TaskType, sh.closing, w.closingMgr)
+
 			return nil
 		})
 
@@ -562,6 +565,8 @@ func (sw *schedWorker) startProcessingReadyTask(req *WorkerRequest) error {
 			log.Warnf("scheduler closed while sending response")
 		}
 
+		sh.preempt.waitExited(sw.wid, req.Sector.ID, req.TaskType, sh.closing, w.closingMgr)
+
 		w.lk.Lock()
 
 		w.active.Free(req.SchedId, req.SealTask(), w.Info.Resources, needRes)
@@ -130,6 +130,16 @@ func (a TaskType) Short() string {
 	return n
 }
 
+// FromShort returns the task type with the given short name
+func FromShort(name string) (TaskType, bool) {
+	for tt, n := range shortNames {
+		if n == name {
+			return tt, true
+		}
+	}
+	return TTNoop, false
+}
+
 type SealTaskType struct {
 	TaskType
 	abi.RegisteredSealProof
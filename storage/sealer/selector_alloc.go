@@ -13,16 +13,18 @@ import (
 )
 
 type allocSelector struct {
-	index paths.SectorIndex
-	alloc storiface.SectorFileType
-	ptype storiface.PathType
+	index  paths.SectorIndex
+	sector abi.SectorID
+	alloc  storiface.SectorFileType
+	ptype  storiface.PathType
 }
 
-func newAllocSelector(index paths.SectorIndex, alloc storiface.SectorFileType, ptype storiface.PathType) *allocSelector {
+func newAllocSelector(index paths.SectorIndex, sector abi.SectorID, alloc storiface.SectorFileType, ptype storiface.PathType) *allocSelector {
 	return &allocSelector{
-		index: index,
-		alloc: alloc,
-		ptype: ptype,
+		index:  index,
+		sector: sector,
+		alloc:  alloc,
+		ptype:  ptype,
 	}
 }
 
@@ -50,7 +52,7 @@ func (s *allocSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi
 		return false, false, xerrors.Errorf("getting sector size: %w", err)
 	}
 
-	best, err := s.index.StorageBestAlloc(ctx, s.alloc, ssize, s.ptype)
+	best, err := s.index.StorageBestAllocSector(ctx, s.alloc, ssize, s.ptype, s.sector)
 	if err != nil {
 		return false, false, xerrors.Errorf("finding best alloc storage: %w", err)
 	}
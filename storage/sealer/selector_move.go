@@ -67,7 +67,7 @@ func (s *moveSelector) Ok(ctx context.Context, task sealtasks.TaskType, spt abi.
 		}
 	}
 
-	best, err := s.index.StorageBestAlloc(ctx, s.alloc, ssize, s.destPtype)
+	best, err := s.index.StorageBestAllocSector(ctx, s.alloc, ssize, s.destPtype, s.sector)
 	if err != nil {
 		return false, false, xerrors.Errorf("finding best dest storage: %w", err)
 	}
@@ -2,6 +2,8 @@ package sealer
 
 import (
 	"context"
+	"sort"
+	"sync"
 	"time"
 
 	"github.com/google/uuid"
@@ -131,3 +133,90 @@ func (m *Manager) WorkerJobs() map[uuid.UUID][]storiface.WorkerJob {
 
 	return out
 }
+
+// gpuTask checks whether tasks of the type use a GPU for any proof type
+func gpuTask(tt sealtasks.TaskType) bool {
+	for _, res := range storiface.ResourceTable[tt] {
+		if res.GPUUtilization > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkerGPUStats queries the GPU usage of all connected workers, including
+// PoSt workers, along with the running tasks using a GPU.
+func (m *Manager) WorkerGPUStats(ctx context.Context) []storiface.WorkerGPUStats {
+	type worker struct {
+		rpc   Worker
+		stats storiface.WorkerGPUStats
+	}
+
+	var workers []*worker
+	cb := func(ctx context.Context, id storiface.WorkerID, handle *WorkerHandle) {
+		handle.lk.Lock()
+		workers = append(workers, &worker{
+			rpc: handle.workerRpc,
+			stats: storiface.WorkerGPUStats{
+				Worker:   uuid.UUID(id),
+				Hostname: handle.Info.Hostname,
+				GpuUsed:  handle.active.gpuUsed,
+			},
+		})
+		handle.lk.Unlock()
+	}
+
+	m.sched.workersLk.RLock()
+	for id, handle := range m.sched.Workers {
+		cb(ctx, id, handle)
+	}
+	m.sched.workersLk.RUnlock()
+
+	m.winningPoStSched.WorkerStats(ctx, cb)
+	m.windowPoStSched.WorkerStats(ctx, cb)
+
+	jobs := m.WorkerJobs()
+
+	var wg sync.WaitGroup
+	for _, w := range workers {
+		for _, job := range jobs[w.stats.Worker] {
+			if job.RunWait == storiface.RWRunning && gpuTask(job.Task) {
+				w.stats.Tasks = append(w.stats.Tasks, job)
+			}
+		}
+
+		gs, ok := w.rpc.(GPUStatter)
+		if !ok {
+			w.stats.Error = "worker doesn't report GPU stats"
+			continue
+		}
+
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+
+			ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+			defer cancel()
+
+			gpus, err := gs.GPUStats(ctx)
+			if err != nil {
+				w.stats.Error = err.Error()
+				return
+			}
+			w.stats.GPUs = gpus
+		}(w)
+	}
+	wg.Wait()
+
+	out := make([]storiface.WorkerGPUStats, len(workers))
+	for i, w := range workers {
+		out[i] = w.stats
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hostname != out[j].Hostname {
+			return out[i].Hostname < out[j].Hostname
+		}
+		return out[i].Worker.String() < out[j].Worker.String()
+	})
+	return out
+}
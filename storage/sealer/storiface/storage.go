@@ -214,4 +214,33 @@ type LocalStorageMeta struct {
 	// - "update-cache"
 	// Any other value will generate a warning and be ignored.
 	DenyTypes []string
+
+	// IOLimits limits the disk bandwidth and operations used on this path by
+	// each class of traffic, so that sealing transfers and retrieval reads
+	// can't starve proving reads on shared disks. Nil means unlimited.
+	IOLimits *PathIOLimits `json:",omitempty"`
+}
+
+// PathIOLimits limits the I/O of each class of traffic on a storage path
+// independently.
+type PathIOLimits struct {
+	// Sealing limits sector files transferred to and from the path between
+	// workers
+	Sealing IOLimit
+
+	// Retrieval limits reads of unsealed sector data served for retrievals
+	Retrieval IOLimit
+
+	// Proving limits reads of the vanilla proofs of WindowPoSt; WinningPoSt,
+	// which must finish within the block time, isn't limited
+	Proving IOLimit
+}
+
+// IOLimit limits the I/O of one class of traffic. Zero values are unlimited.
+type IOLimit struct {
+	ReadBytesPerSec  uint64
+	WriteBytesPerSec uint64
+
+	ReadIOPS  uint64
+	WriteIOPS uint64
 }
@@ -43,6 +43,20 @@ type WorkerResources struct {
 
 	// if nil use the default resource table
 	Resources map[sealtasks.TaskType]map[abi.RegisteredSealProof]Resources
+
+	// empty on single-node machines, or when the topology isn't known
+	NUMANodes []NUMANode
+}
+
+// NUMANode describes a NUMA node of a worker, and the tasks pinned to it
+type NUMANode struct {
+	ID       int
+	CPUs     []int
+	MemTotal uint64
+	MemFree  uint64
+
+	Tasks   []sealtasks.TaskType // task types allowed to run on the node
+	Running int                  // tasks currently pinned to the node
 }
 
 func (wr WorkerResources) ResourceSpec(spt abi.RegisteredSealProof, tt sealtasks.TaskType) Resources {
@@ -92,6 +106,34 @@ type WorkerStats struct {
 	TaskCounts map[string]int
 }
 
+// GPUStats describes a GPU of a worker
+type GPUStats struct {
+	Index int
+	Name  string
+	UUID  string
+
+	// HasUsage is false when only the device name is known, usage fields are
+	// then zero
+	HasUsage    bool
+	MemTotal    uint64
+	MemUsed     uint64
+	Utilization float64 // percent
+	Temperature int     // celsius
+}
+
+type WorkerGPUStats struct {
+	Worker   uuid.UUID
+	Hostname string
+
+	GPUs []GPUStats
+	// GpuUsed is the GPU share allocated by the scheduler
+	GpuUsed float64 // nolint
+	// Tasks are the running tasks which use a GPU
+	Tasks []WorkerJob
+
+	Error string
+}
+
 const (
 	RWPrepared = 1
 	RWRunning  = 0
@@ -117,6 +159,48 @@ type WorkerJob struct {
 	Hostname string `json:",omitempty"` // optional, set for ret-wait jobs
 }
 
+// TaskHistoryEntry describes a finished task execution on a worker
+type TaskHistoryEntry struct {
+	ID       CallID
+	Sector   abi.SectorID
+	Task     sealtasks.TaskType
+	Worker   uuid.UUID
+	Hostname string
+
+	Start    time.Time
+	Duration time.Duration
+
+	// Attempt is the number of times the task type was executed on the sector,
+	// including this execution
+	Attempt int
+
+	Error string `json:",omitempty"` // empty if the task succeeded
+}
+
+type TaskHistoryFilter struct {
+	Hostname string             `json:",omitempty"`
+	Task     sealtasks.TaskType `json:",omitempty"`
+	Sector   *abi.SectorNumber  `json:",omitempty"`
+	Since    time.Time          // zero - no limit
+	Failed   bool               // only list failed tasks
+	Limit    int                // 0 - no limit, newest entries are returned first
+}
+
+// WorkerTaskStats aggregates task history of a single task type on a single worker
+type WorkerTaskStats struct {
+	Worker   uuid.UUID
+	Hostname string
+	Task     sealtasks.TaskType
+
+	Count   int
+	Failed  int
+	Retries int // executions which weren't the first attempt for the sector
+
+	P50 time.Duration
+	P95 time.Duration
+	Max time.Duration
+}
+
 type CallID struct {
 	Sector abi.SectorID
 	ID     uuid.UUID
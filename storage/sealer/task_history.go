@@ -0,0 +1,308 @@
+package sealer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	"golang.org/x/xerrors"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+type TaskHistoryStore datastore.Batching
+
+// how often old history entries are removed
+const taskHistoryGCInterval = time.Hour
+
+// how long the attempt counters of tasks are kept after their last execution
+// when history entries are kept forever
+const taskAttemptsExpiry = 7 * 24 * time.Hour
+
+type sectorTask struct {
+	sector abi.SectorID
+	task   sealtasks.TaskType
+}
+
+type taskAttempts struct {
+	count int
+	last  time.Time
+}
+
+// taskHistory persists finished task executions, keyed by task end time.
+type taskHistory struct {
+	lk sync.Mutex
+
+	ds        datastore.Batching
+	retention time.Duration
+
+	attempts map[sectorTask]taskAttempts
+	lastGC   time.Time
+}
+
+func newTaskHistory(ctx context.Context, ds datastore.Batching, retention time.Duration) (*taskHistory, error) {
+	th := &taskHistory{
+		ds:        ds,
+		retention: retention,
+		attempts:  map[sectorTask]taskAttempts{},
+	}
+
+	err := th.forEach(ctx, func(_ datastore.Key, e storiface.TaskHistoryEntry) error {
+		st := sectorTask{sector: e.Sector, task: e.Task}
+		a := th.attempts[st]
+		if e.Attempt > a.count {
+			a.count = e.Attempt
+		}
+		if end := e.Start.Add(e.Duration); end.After(a.last) {
+			a.last = end
+		}
+		th.attempts[st] = a
+		return nil
+	})
+	if err != nil {
+		return nil, xerrors.Errorf("loading task history: %w", err)
+	}
+
+	if err := th.gc(ctx, time.Now()); err != nil {
+		return nil, xerrors.Errorf("removing old task history: %w", err)
+	}
+
+	return th, nil
+}
+
+func taskHistoryKey(e storiface.TaskHistoryEntry) datastore.Key {
+	return datastore.NewKey(fmt.Sprintf("/%020d/%s", e.Start.Add(e.Duration).UnixNano(), e.ID.ID))
+}
+
+func (th *taskHistory) record(ctx context.Context, e storiface.TaskHistoryEntry) error {
+	th.lk.Lock()
+	defer th.lk.Unlock()
+
+	st := sectorTask{sector: e.Sector, task: e.Task}
+	a := th.attempts[st]
+	a.count++
+	a.last = e.Start.Add(e.Duration)
+	th.attempts[st] = a
+	e.Attempt = a.count
+
+	b, err := json.Marshal(e)
+	if err != nil {
+		return xerrors.Errorf("marshaling history entry: %w", err)
+	}
+	if err := th.ds.Put(ctx, taskHistoryKey(e), b); err != nil {
+		return xerrors.Errorf("storing history entry: %w", err)
+	}
+
+	if time.Since(th.lastGC) > taskHistoryGCInterval {
+		if err := th.gc(ctx, time.Now()); err != nil {
+			log.Warnf("removing old task history: %+v", err)
+		}
+	}
+
+	return nil
+}
+
+// called with th.lk, or before th is shared
+func (th *taskHistory) gc(ctx context.Context, now time.Time) error {
+	th.lastGC = now
+
+	expiry := th.retention
+	if expiry <= 0 {
+		expiry = taskAttemptsExpiry
+	}
+	for st, a := range th.attempts {
+		if a.last.Before(now.Add(-expiry)) {
+			delete(th.attempts, st)
+		}
+	}
+
+	if th.retention <= 0 {
+		return nil
+	}
+
+	cutoff := now.Add(-th.retention)
+
+	b, err := th.ds.Batch(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = th.forEach(ctx, func(k datastore.Key, e storiface.TaskHistoryEntry) error {
+		if e.Start.Add(e.Duration).Before(cutoff) {
+			return b.Delete(ctx, k)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return b.Commit(ctx)
+}
+
+func (th *taskHistory) forEach(ctx context.Context, cb func(datastore.Key, storiface.TaskHistoryEntry) error) error {
+	res, err := th.ds.Query(ctx, query.Query{})
+	if err != nil {
+		return err
+	}
+	defer res.Close() //nolint:errcheck
+
+	for r := range res.Next() {
+		if r.Error != nil {
+			return r.Error
+		}
+
+		var e storiface.TaskHistoryEntry
+		if err := json.Unmarshal(r.Value, &e); err != nil {
+			log.Warnf("skipping malformed task history entry %s: %s", r.Key, err)
+			continue
+		}
+
+		if err := cb(datastore.NewKey(r.Key), e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// list returns history entries matching the filter, newest first
+func (th *taskHistory) list(ctx context.Context, filter storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) {
+	th.lk.Lock()
+	defer th.lk.Unlock()
+
+	var out []storiface.TaskHistoryEntry
+	err := th.forEach(ctx, func(_ datastore.Key, e storiface.TaskHistoryEntry) error {
+		switch {
+		case filter.Hostname != "" && e.Hostname != filter.Hostname,
+			filter.Task != "" && e.Task != filter.Task,
+			filter.Sector != nil && e.Sector.Number != *filter.Sector,
+			!filter.Since.IsZero() && e.Start.Before(filter.Since),
+			filter.Failed && e.Error == "":
+			return nil
+		}
+
+		out = append(out, e)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].Start.Add(out[i].Duration).After(out[j].Start.Add(out[j].Duration))
+	})
+
+	if filter.Limit > 0 && len(out) > filter.Limit {
+		out = out[:filter.Limit]
+	}
+
+	return out, nil
+}
+
+// taskStats aggregates history entries per worker and task type. Duration
+// percentiles only consider successful executions.
+func taskStats(entries []storiface.TaskHistoryEntry) []storiface.WorkerTaskStats {
+	type statKey struct {
+		worker uuid.UUID
+		task   sealtasks.TaskType
+	}
+
+	stats := map[statKey]*storiface.WorkerTaskStats{}
+	durations := map[statKey][]time.Duration{}
+
+	for _, e := range entries {
+		k := statKey{worker: e.Worker, task: e.Task}
+		s, ok := stats[k]
+		if !ok {
+			s = &storiface.WorkerTaskStats{
+				Worker:   e.Worker,
+				Hostname: e.Hostname,
+				Task:     e.Task,
+			}
+			stats[k] = s
+		}
+
+		s.Count++
+		if e.Attempt > 1 {
+			s.Retries++
+		}
+		if e.Error != "" {
+			s.Failed++
+			continue
+		}
+		durations[k] = append(durations[k], e.Duration)
+	}
+
+	out := make([]storiface.WorkerTaskStats, 0, len(stats))
+	for k, s := range stats {
+		d := durations[k]
+		sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+
+		s.P50 = percentile(d, 0.5)
+		s.P95 = percentile(d, 0.95)
+		if len(d) > 0 {
+			s.Max = d[len(d)-1]
+		}
+
+		out = append(out, *s)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Hostname != out[j].Hostname {
+			return out[i].Hostname < out[j].Hostname
+		}
+		if out[i].Worker != out[j].Worker {
+			return out[i].Worker.String() < out[j].Worker.String()
+		}
+		return out[i].Task.Less(out[j].Task)
+	})
+
+	return out
+}
+
+// percentile of sorted durations, using the nearest-rank method
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+func (m *Manager) WorkerTaskHistory(ctx context.Context, filter storiface.TaskHistoryFilter) ([]storiface.TaskHistoryEntry, error) {
+	th := m.sched.workTracker.history
+	if th == nil {
+		return nil, xerrors.Errorf("task history not enabled")
+	}
+
+	return th.list(ctx, filter)
+}
+
+func (m *Manager) WorkerTaskStats(ctx context.Context, since time.Time) ([]storiface.WorkerTaskStats, error) {
+	th := m.sched.workTracker.history
+	if th == nil {
+		return nil, xerrors.Errorf("task history not enabled")
+	}
+
+	entries, err := th.list(ctx, storiface.TaskHistoryFilter{Since: since})
+	if err != nil {
+		return nil, err
+	}
+
+	return taskStats(entries), nil
+}
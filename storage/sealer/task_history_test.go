@@ -0,0 +1,100 @@
+package sealer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+
+	"github.com/filecoin-project/lotus/storage/sealer/sealtasks"
+	"github.com/filecoin-project/lotus/storage/sealer/storiface"
+)
+
+func TestTaskHistory(t *testing.T) {
+	ctx := context.Background()
+	ds := dssync.MutexWrap(datastore.NewMapDatastore())
+
+	th, err := newTaskHistory(ctx, ds, 24*time.Hour)
+	require.NoError(t, err)
+
+	wA := uuid.New()
+	wB := uuid.New()
+
+	now := time.Now()
+	entry := func(w uuid.UUID, host string, sn abi.SectorNumber, task sealtasks.TaskType, ago, took time.Duration, errStr string) storiface.TaskHistoryEntry {
+		sid := abi.SectorID{Miner: 1000, Number: sn}
+		return storiface.TaskHistoryEntry{
+			ID:       storiface.CallID{Sector: sid, ID: uuid.New()},
+			Sector:   sid,
+			Task:     task,
+			Worker:   w,
+			Hostname: host,
+			Start:    now.Add(-ago),
+			Duration: took,
+			Error:    errStr,
+		}
+	}
+
+	for i := 1; i <= 20; i++ {
+		require.NoError(t, th.record(ctx, entry(wA, "a", abi.SectorNumber(i), sealtasks.TTPreCommit1, time.Duration(30-i)*time.Minute, time.Duration(i)*time.Minute, "")))
+	}
+	require.NoError(t, th.record(ctx, entry(wB, "b", 1, sealtasks.TTPreCommit1, 5*time.Minute, time.Minute, "boom")))
+	require.NoError(t, th.record(ctx, entry(wB, "b", 1, sealtasks.TTPreCommit1, 3*time.Minute, 2*time.Minute, "")))
+
+	// too old, removed on gc
+	require.NoError(t, th.record(ctx, entry(wB, "b", 5, sealtasks.TTCommit2, 48*time.Hour, time.Minute, "")))
+
+	all, err := th.list(ctx, storiface.TaskHistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 23)
+	require.Equal(t, sealtasks.TTCommit2, all[len(all)-1].Task) // newest first
+
+	failed, err := th.list(ctx, storiface.TaskHistoryFilter{Failed: true})
+	require.NoError(t, err)
+	require.Len(t, failed, 1)
+	require.Equal(t, "boom", failed[0].Error)
+	require.Equal(t, 2, failed[0].Attempt) // sector 1 PC1 on worker a, then b
+
+	sn := abi.SectorNumber(1)
+	s1, err := th.list(ctx, storiface.TaskHistoryFilter{Sector: &sn, Hostname: "b", Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, s1, 1)
+	require.Equal(t, 3, s1[0].Attempt)
+
+	require.NoError(t, th.gc(ctx, now))
+
+	// reopening restores attempt counters
+	th, err = newTaskHistory(ctx, ds, 24*time.Hour)
+	require.NoError(t, err)
+	all, err = th.list(ctx, storiface.TaskHistoryFilter{})
+	require.NoError(t, err)
+	require.Len(t, all, 22)
+	require.Equal(t, 3, th.attempts[sectorTask{sector: abi.SectorID{Miner: 1000, Number: 1}, task: sealtasks.TTPreCommit1}].count)
+
+	stats := taskStats(all)
+	require.Len(t, stats, 2)
+
+	require.Equal(t, "a", stats[0].Hostname)
+	require.Equal(t, 20, stats[0].Count)
+	require.Equal(t, 0, stats[0].Failed)
+	require.Equal(t, 10*time.Minute, stats[0].P50)
+	require.Equal(t, 19*time.Minute, stats[0].P95)
+	require.Equal(t, 20*time.Minute, stats[0].Max)
+
+	require.Equal(t, "b", stats[1].Hostname)
+	require.Equal(t, 2, stats[1].Count)
+	require.Equal(t, 1, stats[1].Failed)
+	require.Equal(t, 2, stats[1].Retries)
+	require.Equal(t, 2*time.Minute, stats[1].P50)
+
+	// counters of tasks which didn't run within the retention are dropped
+	require.Len(t, th.attempts, 20)
+	require.NoError(t, th.gc(ctx, now.Add(25*time.Hour)))
+	require.Empty(t, th.attempts)
+}
@@ -44,6 +44,15 @@ type WorkerConfig struct {
 
 	MaxParallelChallengeReads int           // 0 = no limit
 	ChallengeReadTimeout      time.Duration // 0 = no timeout
+
+	// NUMANodes lists the NUMA nodes tasks of each type are allowed to run
+	// on. Tasks are pinned to the CPUs of the least loaded allowed node.
+	NUMANodes map[sealtasks.TaskType][]int
+	// NUMAPinPC1 pins PC1 tasks to any NUMA node when NUMANodes doesn't list PC1
+	NUMAPinPC1 bool
+	// NUMAMemBind restricts memory of pinned tasks to their NUMA node, instead
+	// of only preferring it
+	NUMAMemBind bool
 }
 
 // used do provide custom proofs impl (mostly used in testing)
@@ -72,6 +81,8 @@ type LocalWorker struct {
 	challengeThrottle    chan struct{}
 	challengeReadTimeout time.Duration
 
+	numa *numaPinner
+
 	session     uuid.UUID
 	testDisable int64
 	closing     chan struct{}
@@ -115,6 +126,12 @@ func newLocalWorker(executor ExecutorFunc, wcfg WorkerConfig, envLookup EnvFunc,
 		w.challengeThrottle = make(chan struct{}, wcfg.MaxParallelChallengeReads)
 	}
 
+	topology, err := numaTopology(numaSysfsRoot)
+	if err != nil {
+		log.Warnf("reading NUMA topology: %+v", err)
+	}
+	w.numa = newNUMAPinner(topology, wcfg)
+
 	if w.executor == nil {
 		w.executor = w.ffiExec
 	}
@@ -206,6 +223,25 @@ const (
 	Fetch                 ReturnType = "Fetch"
 )
 
+// task types of async calls, used for NUMA placement
+var returnTaskTypes = map[ReturnType]sealtasks.TaskType{
+	DataCid:               sealtasks.TTDataCid,
+	AddPiece:              sealtasks.TTAddPiece,
+	SealPreCommit1:        sealtasks.TTPreCommit1,
+	SealPreCommit2:        sealtasks.TTPreCommit2,
+	SealCommit1:           sealtasks.TTCommit1,
+	SealCommit2:           sealtasks.TTCommit2,
+	FinalizeSector:        sealtasks.TTFinalize,
+	FinalizeReplicaUpdate: sealtasks.TTFinalizeReplicaUpdate,
+	ReplicaUpdate:         sealtasks.TTReplicaUpdate,
+	ProveReplicaUpdate1:   sealtasks.TTProveReplicaUpdate1,
+	ProveReplicaUpdate2:   sealtasks.TTProveReplicaUpdate2,
+	GenerateSectorKey:     sealtasks.TTRegenSectorKey,
+	UnsealPiece:           sealtasks.TTUnseal,
+	DownloadSector:        sealtasks.TTDownloadSector,
+	Fetch:                 sealtasks.TTFetch,
+}
+
 // in: func(WorkerReturn, context.Context, CallID, err string)
 // in: func(WorkerReturn, context.Context, CallID, ret T, err string)
 func rfunc(in interface{}) func(context.Context, storiface.CallID, storiface.WorkerReturn, interface{}, *storiface.CallError) error {
@@ -275,6 +311,13 @@ func (l *LocalWorker) asyncCall(ctx context.Context, sector storiface.SectorRef,
 	go func() {
 		defer l.running.Done()
 
+		release, err := l.numa.pin(returnTaskTypes[rt])
+		if err != nil {
+			log.Warnw("NUMA pinning failed, running task unpinned", "task", rt, "error", err)
+		} else {
+			defer release()
+		}
+
 		ctx := &wctx{
 			vals:    ctx,
 			closing: l.closing,
@@ -819,6 +862,7 @@ func (l *LocalWorker) Info(context.Context) (storiface.WorkerInfo, error) {
 			CPUs:        uint64(runtime.NumCPU()),
 			GPUs:        gpus,
 			Resources:   resEnv,
+			NUMANodes:   l.numa.nodes(),
 		},
 	}, nil
 }
@@ -30,10 +30,12 @@ type workTracker struct {
 	running  map[storiface.CallID]trackedWork
 	prepared map[uuid.UUID]trackedWork
 
-	// TODO: done, aggregate stats, queue stats, scheduler feedback
+	history *taskHistory // optional
+
+	// TODO: queue stats, scheduler feedback
 }
 
-func (wt *workTracker) onDone(ctx context.Context, callID storiface.CallID) {
+func (wt *workTracker) onDone(ctx context.Context, callID storiface.CallID, cerr *storiface.CallError) {
 	wt.lk.Lock()
 	defer wt.lk.Unlock()
 
@@ -47,6 +49,27 @@ func (wt *workTracker) onDone(ctx context.Context, callID storiface.CallID) {
 
 	took := metrics.SinceInMilliseconds(t.job.Start)
 
+	if wt.history != nil {
+		entry := storiface.TaskHistoryEntry{
+			ID:       callID,
+			Sector:   t.job.Sector,
+			Task:     t.job.Task,
+			Worker:   uuid.UUID(t.worker),
+			Hostname: t.workerHostname,
+			Start:    t.job.Start,
+			Duration: time.Since(t.job.Start),
+		}
+		if cerr != nil {
+			entry.Error = cerr.Error()
+		}
+
+		go func() {
+			if err := wt.history.record(context.Background(), entry); err != nil {
+				log.Errorf("recording task history: %+v", err)
+			}
+		}()
+	}
+
 	ctx, _ = tag.New(
 		ctx,
 		tag.Upsert(metrics.TaskType, string(t.job.Task)),
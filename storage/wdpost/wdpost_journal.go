@@ -3,6 +3,7 @@ package wdpost
 import (
 	"github.com/ipfs/go-cid"
 
+	"github.com/filecoin-project/go-address"
 	"github.com/filecoin-project/go-state-types/abi"
 	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
 	"github.com/filecoin-project/go-state-types/dline"
@@ -33,6 +34,7 @@ const (
 	evtTypeWdPoStProofs
 	evtTypeWdPoStRecoveries
 	evtTypeWdPoStFaults
+	evtTypeWdPoStSender
 )
 
 // evtCommon is a common set of attributes for Windowed PoSt journal events.
@@ -73,3 +75,13 @@ type WdPoStFaultsProcessedEvt struct {
 	Declarations []miner.FaultDeclaration
 	MessageCID   cid.Cid `json:",omitempty"`
 }
+
+// WdPoStSenderSelectedEvt is the journal event that gets recorded when the
+// sender of a Windowed PoSt message (proofs, recoveries or faults) has been
+// selected.
+type WdPoStSenderSelectedEvt struct {
+	Method    abi.MethodNum
+	Sender    address.Address
+	Available abi.TokenAmount
+	Required  abi.TokenAmount
+}
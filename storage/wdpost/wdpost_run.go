@@ -729,6 +729,15 @@ func (s *WindowPoStScheduler) prepareMessage(ctx context.Context, msg *types.Mes
 
 	msg.From = pa
 	bestReq := big.Add(msg.RequiredFunds(), msg.Value)
+	s.journal.RecordEvent(s.evtTypes[evtTypeWdPoStSender], func() interface{} {
+		return &WdPoStSenderSelectedEvt{
+			Method:    msg.Method,
+			Sender:    pa,
+			Available: avail,
+			Required:  goodFunds,
+		}
+	})
+
 	if avail.LessThan(bestReq) {
 		mff := func() (abi.TokenAmount, error) {
 			return msg.RequiredFunds(), nil
@@ -580,6 +580,10 @@ func (m *mockStorageMinerAPI) StateGetActor(ctx context.Context, actor address.A
 	}, nil
 }
 
+func (m *mockStorageMinerAPI) MpoolGetNonce(ctx context.Context, a address.Address) (uint64, error) {
+	return 0, nil
+}
+
 func (m *mockStorageMinerAPI) StateAccountKey(ctx context.Context, address address.Address, key types.TipSetKey) (address.Address, error) {
 	return address, nil
 }
@@ -45,9 +45,11 @@ type NodeAPI interface {
 	StateMinerPartitions(context.Context, address.Address, uint64, types.TipSetKey) ([]api.Partition, error)
 	StateLookupID(context.Context, address.Address, types.TipSetKey) (address.Address, error)
 	StateAccountKey(context.Context, address.Address, types.TipSetKey) (address.Address, error)
+	StateGetActor(context.Context, address.Address, types.TipSetKey) (*types.Actor, error)
 	StateSectorPartition(ctx context.Context, maddr address.Address, sectorNumber abi.SectorNumber, tok types.TipSetKey) (*lminer.SectorLocation, error)
 
 	MpoolPushMessage(context.Context, *types.Message, *api.MessageSendSpec) (*types.SignedMessage, error)
+	MpoolGetNonce(context.Context, address.Address) (uint64, error)
 
 	GasEstimateMessageGas(context.Context, *types.Message, *api.MessageSendSpec, types.TipSetKey) (*types.Message, error)
 	GasEstimateFeeCap(context.Context, *types.Message, int64, types.TipSetKey) (types.BigInt, error)
@@ -80,7 +82,7 @@ type WindowPoStScheduler struct {
 
 	actor address.Address
 
-	evtTypes [4]journal.EventType
+	evtTypes [5]journal.EventType
 	journal  journal.Journal
 
 	// failed abi.ChainEpoch // eps
@@ -121,6 +123,7 @@ func NewWindowedPoStScheduler(api NodeAPI,
 			evtTypeWdPoStProofs:     j.RegisterEventType("wdpost", "proofs_processed"),
 			evtTypeWdPoStRecoveries: j.RegisterEventType("wdpost", "recoveries_processed"),
 			evtTypeWdPoStFaults:     j.RegisterEventType("wdpost", "faults_processed"),
+			evtTypeWdPoStSender:     j.RegisterEventType("wdpost", "sender_selected"),
 		},
 		journal: j,
 	}, nil
@@ -0,0 +1,129 @@
+package wdpost
+
+import (
+	"sort"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	"github.com/filecoin-project/go-state-types/builtin"
+	"github.com/filecoin-project/go-state-types/builtin/v9/miner"
+	"github.com/filecoin-project/go-state-types/builtin/v9/util/smoothing"
+	"github.com/filecoin-project/go-state-types/dline"
+
+	"github.com/filecoin-project/lotus/api"
+	lbuiltin "github.com/filecoin-project/lotus/chain/actors/builtin"
+)
+
+// ContinuedFaultProjectionPeriod is the number of days of expected block
+// reward of its power a faulty sector is charged for each proving period it
+// remains faulty through.
+var ContinuedFaultProjectionPeriod = abi.ChainEpoch(builtin.EpochsInDay*351) / 100
+
+// SimulatedSector is a sector unavailable in a simulated outage.
+type SimulatedSector struct {
+	Number   abi.SectorNumber
+	Deadline uint64
+	RawPower abi.StoragePower
+	QAPower  abi.StoragePower
+}
+
+// FaultFee returns the fee charged for each proving period faulty power
+// remains faulty through, at the given reward and network power estimates.
+func FaultFee(rewardEstimate, networkQAPowerEstimate lbuiltin.FilterEstimate) func(qaPower abi.StoragePower) abi.TokenAmount {
+	return func(qaPower abi.StoragePower) abi.TokenAmount {
+		return miner.ExpectedRewardForPower(smoothing.FilterEstimate(rewardEstimate), smoothing.FilterEstimate(networkQAPowerEstimate),
+			qaPower, ContinuedFaultProjectionPeriod)
+	}
+}
+
+// SimulateOutage projects the outcome of the sectors being unavailable for
+// outage epochs from the current epoch of di, the current deadline.
+//
+// Sectors miss the challenge windows opening during the outage, and are marked
+// faulty at the close of the first one, losing their power. Faults aren't
+// charged when detected, but at the close of each later window the sectors
+// remain faulty through. Once available again, their recovery is declared
+// before the fault cutoff of the next window, and they are proven in it.
+// Sectors faulty for faultMaxAge are terminated.
+func SimulateOutage(di *dline.Info, sectors []SimulatedSector, outage, faultMaxAge abi.ChainEpoch, faultFee func(abi.StoragePower) abi.TokenAmount) *api.ProvingSimulation {
+	sim := &api.ProvingSimulation{
+		Height:   di.CurrentEpoch,
+		Outage:   outage,
+		Sectors:  len(sectors),
+		RawPower: big.Zero(),
+		QAPower:  big.Zero(),
+		Penalty:  big.Zero(),
+	}
+
+	byDeadline := map[uint64]*api.ProvingSimulationDeadline{}
+	for _, s := range sectors {
+		dl, ok := byDeadline[s.Deadline]
+		if !ok {
+			dl = &api.ProvingSimulationDeadline{
+				Index:    s.Deadline,
+				RawPower: big.Zero(),
+				QAPower:  big.Zero(),
+				FaultFee: big.Zero(),
+				Penalty:  big.Zero(),
+			}
+			byDeadline[s.Deadline] = dl
+		}
+		dl.Sectors++
+		dl.RawPower = big.Add(dl.RawPower, s.RawPower)
+		dl.QAPower = big.Add(dl.QAPower, s.QAPower)
+	}
+
+	end := di.CurrentEpoch + outage
+	maxFaults := int(faultMaxAge / di.WPoStProvingPeriod)
+
+	for idx, dl := range byDeadline {
+		// the first window of the deadline which hasn't closed yet
+		w := NewDeadlineInfo(di.PeriodStart, idx, di.CurrentEpoch).NextNotElapsed()
+		if w.Open >= end {
+			// available again before having to be proven
+			continue
+		}
+
+		dl.Faulted = w.Close
+		dl.FaultFee = faultFee(dl.QAPower)
+		sim.RawPower = big.Add(sim.RawPower, dl.RawPower)
+		sim.QAPower = big.Add(sim.QAPower, dl.QAPower)
+
+		for {
+			w = nextWindow(w)
+			if end < w.FaultCutoff {
+				dl.Recovered = w.Close
+				break
+			}
+
+			dl.FaultFees++
+			if dl.FaultFees == maxFaults {
+				dl.Terminated = w.Close
+				break
+			}
+		}
+
+		dl.Penalty = big.Mul(dl.FaultFee, big.NewInt(int64(dl.FaultFees)))
+		sim.Penalty = big.Add(sim.Penalty, dl.Penalty)
+		if dl.Terminated != 0 {
+			sim.Terminated += dl.Sectors
+		}
+		if done := dl.Recovered + dl.Terminated; done > sim.Recovered {
+			sim.Recovered = done
+		}
+	}
+
+	for _, dl := range byDeadline {
+		sim.Deadlines = append(sim.Deadlines, *dl)
+	}
+	sort.Slice(sim.Deadlines, func(i, j int) bool {
+		return sim.Deadlines[i].Index < sim.Deadlines[j].Index
+	})
+
+	return sim
+}
+
+// nextWindow returns the next window of the same deadline.
+func nextWindow(di *dline.Info) *dline.Info {
+	return NewDeadlineInfo(di.PeriodStart+di.WPoStProvingPeriod, di.Index, di.CurrentEpoch)
+}
@@ -0,0 +1,76 @@
+// stm: #unit
+package wdpost
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-state-types/abi"
+	"github.com/filecoin-project/go-state-types/big"
+	minertypes "github.com/filecoin-project/go-state-types/builtin/v9/miner"
+)
+
+func TestSimulateOutage(t *testing.T) {
+	pp := minertypes.WPoStProvingPeriod
+	cw := minertypes.WPoStChallengeWindow
+
+	// deadline 2 is open
+	di := NewDeadlineInfo(0, 2, 2*cw+10)
+	sectors := []SimulatedSector{
+		{Number: 1, Deadline: 1, RawPower: big.NewInt(10), QAPower: big.NewInt(100)},
+		{Number: 2, Deadline: 2, RawPower: big.NewInt(10), QAPower: big.NewInt(10)},
+		{Number: 3, Deadline: 2, RawPower: big.NewInt(10), QAPower: big.NewInt(10)},
+		{Number: 4, Deadline: 5, RawPower: big.NewInt(10), QAPower: big.NewInt(10)},
+	}
+	fee := func(qa abi.StoragePower) abi.TokenAmount { return big.Mul(qa, big.NewInt(2)) }
+
+	// an outage ending before deadline 5 opens
+	sim := SimulateOutage(di, sectors, 2*cw, minertypes.FaultMaxAge, fee)
+	require.Equal(t, 4, sim.Sectors)
+	require.Len(t, sim.Deadlines, 3)
+
+	dl1, dl2, dl5 := sim.Deadlines[0], sim.Deadlines[1], sim.Deadlines[2]
+	require.EqualValues(t, 1, dl1.Index)
+	require.Zero(t, dl1.Faulted)
+	require.Zero(t, dl5.Faulted)
+	require.Zero(t, dl5.Recovered)
+
+	require.Equal(t, 2, dl2.Sectors)
+	require.Equal(t, 3*cw, dl2.Faulted) // missed in the open window
+	require.Equal(t, 0, dl2.FaultFees)
+	require.Equal(t, pp+3*cw, dl2.Recovered)
+
+	require.Equal(t, big.NewInt(20), sim.RawPower)
+	require.Equal(t, big.NewInt(20), sim.QAPower)
+	require.True(t, sim.Penalty.IsZero())
+	require.Equal(t, pp+3*cw, sim.Recovered)
+
+	// an outage of a proving period
+	sim = SimulateOutage(di, sectors, pp, minertypes.FaultMaxAge, fee)
+	dl1, dl2 = sim.Deadlines[0], sim.Deadlines[1]
+	require.Equal(t, pp+2*cw, dl1.Faulted) // missed in the next period
+	require.Equal(t, 0, dl1.FaultFees)
+	require.Equal(t, 2*pp+2*cw, dl1.Recovered)
+	require.Equal(t, 1, dl2.FaultFees)
+	require.Equal(t, 2*pp+3*cw, dl2.Recovered)
+	require.Equal(t, big.NewInt(40), sim.RawPower)
+	require.Equal(t, big.NewInt(130), sim.QAPower)
+	require.Equal(t, big.NewInt(40), sim.Penalty)
+
+	// an outage of three proving periods, ending after the window opens
+	sim = SimulateOutage(di, sectors[1:3], 3*pp, minertypes.FaultMaxAge, fee)
+	dl2 = sim.Deadlines[0]
+	require.Equal(t, 3, dl2.FaultFees)
+	require.Equal(t, big.NewInt(40), dl2.FaultFee)
+	require.Equal(t, big.NewInt(120), sim.Penalty)
+	require.Equal(t, 4*pp+3*cw, dl2.Recovered)
+
+	// an outage longer than the maximum fault age terminates the sectors
+	sim = SimulateOutage(di, sectors[1:3], minertypes.FaultMaxAge+2*pp, minertypes.FaultMaxAge, fee)
+	dl2 = sim.Deadlines[0]
+	require.Zero(t, dl2.Recovered)
+	require.Equal(t, dl2.Faulted+minertypes.FaultMaxAge, dl2.Terminated)
+	require.Equal(t, 2, sim.Terminated)
+	require.Equal(t, big.NewInt(42*40), sim.Penalty)
+}